@@ -218,22 +218,26 @@ func (c *Client) buildArgs(config SessionConfig) ([]string, error) {
 		// Log MCP config for debugging
 		log.Printf("MCP config JSON: %s", string(mcpJSON))
 
-		// Create a temp file for MCP config
-		tmpFile, err := os.CreateTemp("", "mcp-config-*.json")
-		if err != nil {
-			return nil, fmt.Errorf("failed to create temp MCP config file: %w", err)
-		}
+		if config.Wrapper != nil {
+			args = append(args, "--mcp-config", string(mcpJSON))
+		} else {
+			// Create a temp file for MCP config
+			tmpFile, err := os.CreateTemp("", "mcp-config-*.json")
+			if err != nil {
+				return nil, fmt.Errorf("failed to create temp MCP config file: %w", err)
+			}
 
-		if _, err := tmpFile.Write(mcpJSON); err != nil {
+			if _, err := tmpFile.Write(mcpJSON); err != nil {
+				_ = tmpFile.Close()
+				return nil, fmt.Errorf("failed to write MCP config: %w", err)
+			}
 			_ = tmpFile.Close()
-			return nil, fmt.Errorf("failed to write MCP config: %w", err)
-		}
-		_ = tmpFile.Close()
 
-		log.Printf("MCP config written to: %s", tmpFile.Name())
+			log.Printf("MCP config written to: %s", tmpFile.Name())
 
-		args = append(args, "--mcp-config", tmpFile.Name())
-		// Note: temp file will be cleaned up when process exits
+			args = append(args, "--mcp-config", tmpFile.Name())
+			// Note: temp file will be cleaned up when process exits
+		}
 	}
 
 	// Permission prompt tool
@@ -310,6 +314,21 @@ func (c *Client) buildArgs(config SessionConfig) ([]string, error) {
 	return args, nil
 }
 
+// command returns the program and arguments that run claude with args,
+// applying the config's wrapper if set
+func (c *Client) command(config SessionConfig, args []string) (string, []string) {
+	if config.Wrapper == nil {
+		return c.claudePath, args
+	}
+	claudePath := config.Wrapper.ClaudePath
+	if claudePath == "" {
+		claudePath = "claude"
+	}
+	wrapped := append([]string{}, config.Wrapper.Args...)
+	wrapped = append(wrapped, claudePath)
+	return config.Wrapper.Command, append(wrapped, args...)
+}
+
 // Launch starts a new Claude session and returns immediately
 func (c *Client) Launch(config SessionConfig) (*Session, error) {
 	args, err := c.buildArgs(config)
@@ -317,8 +336,9 @@ func (c *Client) Launch(config SessionConfig) (*Session, error) {
 		return nil, err
 	}
 
-	log.Printf("Executing Claude command: %s %v", c.claudePath, args)
-	cmd := exec.Command(c.claudePath, args...)
+	name, args := c.command(config, args)
+	log.Printf("Executing Claude command: %s %v", name, args)
+	cmd := exec.Command(name, args...)
 
 	// Set environment variables if specified
 	if len(config.Env) > 0 {
@@ -363,6 +383,10 @@ func (c *Client) Launch(config SessionConfig) (*Session, error) {
 		return nil, fmt.Errorf("failed to create stderr pipe: %w", err)
 	}
 
+	// Run in its own process group, so processes Claude leaves behind can be
+	// cleaned up with it
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
 	// Start the command
 	if err := cmd.Start(); err != nil {
 		return nil, fmt.Errorf("failed to start claude: %w", err)
@@ -413,6 +437,8 @@ func (c *Client) Launch(config SessionConfig) (*Session, error) {
 		// Wait() might return before the result is available.
 		<-parseDone
 
+		session.recordExit(cmd.ProcessState)
+
 		// Extract exit code and store in result for debugging
 		if session.result != nil {
 			if waitErr != nil {
@@ -456,6 +482,51 @@ func (s *Session) Wait() (*Result, error) {
 	return s.result, nil
 }
 
+// PID returns the process ID of the Claude process, or 0 if it didn't start
+func (s *Session) PID() int {
+	if s.cmd == nil || s.cmd.Process == nil {
+		return 0
+	}
+	return s.cmd.Process.Pid
+}
+
+// ExitStatus reports how the process ended, or nil while it is running
+func (s *Session) ExitStatus() *ExitStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.exit == nil {
+		return nil
+	}
+	exit := *s.exit
+	return &exit
+}
+
+// recordExit stores the exit status once the process has been waited for
+// and kills what is left of its process group, so children it didn't wait
+// for aren't left running or unreaped
+func (s *Session) recordExit(state *os.ProcessState) {
+	exit := &ExitStatus{Code: -1}
+	if state != nil {
+		exit.Code = state.ExitCode()
+		if ws, ok := state.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+			exit.Signal = ws.Signal().String()
+		}
+	}
+	if pid := s.PID(); pid > 0 {
+		_ = syscall.Kill(-pid, syscall.SIGKILL)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	exit.Stderr = s.stderr
+	s.exit = exit
+}
+
+func (s *Session) setStderr(stderr string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stderr = stderr
+}
+
 // Kill terminates the session
 func (s *Session) Kill() error {
 	if s.cmd.Process != nil {
@@ -550,6 +621,7 @@ func (s *Session) parseStreamingJSON(stdout, stderr io.Reader) {
 
 	// Capture stderr output
 	stderrOutput := stderrBuf.String()
+	s.setStderr(stderrOutput)
 
 	// If we have a result, store stderr in it for debugging (even if result has is_error with empty message)
 	if s.result != nil {
@@ -604,6 +676,7 @@ func (s *Session) parseSingleJSON(stdout, stderr io.Reader) {
 
 	// Capture stderr output in result for debugging
 	stderrOutput := stderrBuf.String()
+	s.setStderr(stderrOutput)
 	if s.result != nil {
 		s.result.StderrOutput = stderrOutput
 	}
@@ -641,6 +714,7 @@ func (s *Session) parseTextOutput(stdout, stderr io.Reader) {
 
 	// Capture stderr output
 	stderrOutput := stderrBuf.String()
+	s.setStderr(stderrOutput)
 
 	// Store stderr in result for debugging
 	if s.result != nil {
@@ -1,7 +1,13 @@
 package claudecode
 
 import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
 	"testing"
+	"time"
 )
 
 // TestBuildArgsWithDashPrefixedQuery tests that queries starting with dashes are handled correctly
@@ -197,3 +203,97 @@ func TestBuildArgsWithComplexConfigs(t *testing.T) {
 		})
 	}
 }
+
+func TestCommandWithWrapper(t *testing.T) {
+	client := NewClientWithPath("/usr/local/bin/claude")
+	config := SessionConfig{
+		Query:     "hello",
+		MCPConfig: &MCPConfig{MCPServers: map[string]MCPServer{"test": {Command: "node"}}},
+		Wrapper: &CommandWrapper{
+			Command: "docker",
+			Args:    []string{"run", "--rm", "-i", "image"},
+		},
+	}
+
+	args, err := client.buildArgs(config)
+	if err != nil {
+		t.Fatalf("buildArgs failed: %v", err)
+	}
+	name, args := client.command(config, args)
+	if name != "docker" {
+		t.Errorf("expected docker, got %s", name)
+	}
+	if len(args) < 5 || args[4] != "claude" {
+		t.Fatalf("expected claude after wrapper args, got %v", args)
+	}
+
+	for i, arg := range args {
+		if arg == "--mcp-config" {
+			if !strings.HasPrefix(args[i+1], "{") {
+				t.Errorf("expected inline MCP config, got %s", args[i+1])
+			}
+			return
+		}
+	}
+	t.Error("--mcp-config not found in args")
+}
+
+// TestExitStatusOfKilledProcess uses a stand-in for claude that complains
+// on stderr, starts a child and is then killed
+func TestExitStatusOfKilledProcess(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "claude")
+	pidFile := filepath.Join(dir, "child.pid")
+	body := "#!/bin/sh\necho 'out of memory' >&2\nsleep 60 &\necho $! > " + pidFile + "\nkill -9 $$\n"
+	if err := os.WriteFile(script, []byte(body), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	session, err := NewClientWithPath(script).Launch(SessionConfig{Query: "hi", OutputFormat: OutputText})
+	if err != nil {
+		t.Fatalf("launch failed: %v", err)
+	}
+	if session.PID() == 0 {
+		t.Error("expected a PID once launched")
+	}
+	_, _ = session.Wait()
+
+	exit := session.ExitStatus()
+	if exit == nil {
+		t.Fatal("expected an exit status after Wait")
+	}
+	if exit.Code != -1 || exit.Signal != "killed" {
+		t.Errorf("expected death by SIGKILL, got code %d signal %q", exit.Code, exit.Signal)
+	}
+	if !strings.Contains(exit.Stderr, "out of memory") {
+		t.Errorf("expected stderr to be kept, got %q", exit.Stderr)
+	}
+
+	// The child it left behind goes with its process group
+	data, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+	deadline := time.Now().Add(2 * time.Second)
+	for alive(pid) && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if alive(pid) {
+		t.Errorf("child %d outlived its session", pid)
+	}
+}
+
+// alive reports whether pid is running; a killed process waiting to be
+// reaped by init doesn't count
+func alive(pid int) bool {
+	if syscall.Kill(pid, 0) != nil {
+		return false
+	}
+	stat, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return true
+	}
+	fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+	return len(fields) == 0 || fields[0] != "Z"
+}
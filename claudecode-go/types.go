@@ -70,6 +70,16 @@ type SessionConfig struct {
 	CustomInstructions    string
 	Verbose               bool
 	Env                   map[string]string // Environment variables to set for the Claude process
+	Wrapper               *CommandWrapper   // Runs claude through another command, e.g. a container runtime
+}
+
+// CommandWrapper runs the claude CLI through another command such as
+// `docker run ... <image>`. The wrapped environment can't see temp files the
+// client writes, so the MCP config is passed inline instead.
+type CommandWrapper struct {
+	Command    string   // Program to execute, e.g. "docker"
+	Args       []string // Arguments placed before the claude invocation
+	ClaudePath string   // Claude binary inside the wrapped environment (default "claude")
 }
 
 // StreamEvent represents a single event from the streaming JSON output
@@ -300,8 +310,17 @@ type Session struct {
 	result *Result
 
 	// Thread-safe error handling
-	mu  sync.RWMutex
-	err error
+	mu     sync.RWMutex
+	err    error
+	stderr string
+	exit   *ExitStatus
+}
+
+// ExitStatus describes how a session's process ended
+type ExitStatus struct {
+	Code   int    // -1 when the process was killed by a signal
+	Signal string // Signal that killed the process, e.g. "killed"; "" if it exited
+	Stderr string // Everything the process wrote to stderr
 }
 
 // SetError safely sets the error
@@ -0,0 +1,259 @@
+// Package activity aggregates what sessions did in each repository over a
+// period: sessions and how many failed, commits the daemon made and the
+// lines they changed, approvals, and who contributed, human or agent.
+package activity
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/humanlayer/humanlayer/hld/usage"
+	"github.com/humanlayer/humanlayer/hld/workspace"
+)
+
+// maxContributors caps the contributors listed per repository
+const maxContributors = 10
+
+// Contributor kinds
+const (
+	KindHuman = "human" // A git author, for commits the daemon didn't make
+	KindAgent = "agent" // A model, for commits the daemon made
+)
+
+// Contributor is someone, or some model, who committed to a repository
+type Contributor struct {
+	Name    string `json:"name"`
+	Email   string `json:"email,omitempty"`
+	Kind    string `json:"kind"`
+	Commits int    `json:"commits"`
+}
+
+// Approvals counts a repository's approvals by outcome
+type Approvals struct {
+	Total    int `json:"total"`
+	Approved int `json:"approved"`
+	Denied   int `json:"denied"`
+	Pending  int `json:"pending"`
+}
+
+// Repository is the activity in one repository
+type Repository struct {
+	Repository string `json:"repository"`
+	Sessions   int    `json:"sessions"`
+	Completed  int    `json:"completed"`
+	Failed     int    `json:"failed"`
+	// FailureRate is the share of finished sessions that failed
+	FailureRate  float64       `json:"failure_rate"`
+	Commits      int           `json:"commits"`
+	LinesAdded   int           `json:"lines_added"`
+	LinesDeleted int           `json:"lines_deleted"`
+	Approvals    Approvals     `json:"approvals"`
+	Contributors []Contributor `json:"contributors"`
+}
+
+// Report is the activity of a period, busiest repository first
+type Report struct {
+	Period       string       `json:"period"`
+	Start        time.Time    `json:"start"`
+	End          time.Time    `json:"end"`
+	Repositories []Repository `json:"repositories"`
+}
+
+// BuildReport aggregates activity since the start of the current period.
+// Human contributors are read from the git history of repositories on this
+// machine; remote repositories list only agents.
+func BuildReport(ctx context.Context, s store.ConversationStore, commitStore store.CommitStore, period string, now time.Time) (*Report, error) {
+	start, err := usage.PeriodStart(period, now)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := s.ListSessions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	commits, err := commitStore.ListSessionCommits(ctx, start)
+	if err != nil {
+		return nil, err
+	}
+	approvals, err := s.CountApprovals(ctx, start)
+	if err != nil {
+		return nil, err
+	}
+
+	roots := make(map[string]string)
+	repositoryOf := make(map[string]string, len(sessions))
+	local := make(map[string]bool)
+	byID := make(map[string]*store.Session, len(sessions))
+	for _, session := range sessions {
+		byID[session.ID] = session
+		if session.WorkingDir == "" {
+			continue
+		}
+		if session.SSHHost != "" {
+			repositoryOf[session.ID] = session.SSHHost + ":" + session.WorkingDir
+			continue
+		}
+		root, ok := roots[session.WorkingDir]
+		if !ok {
+			root = repositoryRoot(ctx, session.WorkingDir)
+			roots[session.WorkingDir] = root
+		}
+		repositoryOf[session.ID] = root
+		local[root] = true
+	}
+
+	repositories := make(map[string]*Repository)
+	get := func(name string) *Repository {
+		r, ok := repositories[name]
+		if !ok {
+			r = &Repository{Repository: name}
+			repositories[name] = r
+		}
+		return r
+	}
+
+	for _, session := range sessions {
+		repository, ok := repositoryOf[session.ID]
+		if !ok || session.CreatedAt.Before(start) {
+			continue
+		}
+		r := get(repository)
+		r.Sessions++
+		switch session.Status {
+		case store.SessionStatusCompleted:
+			r.Completed++
+		case store.SessionStatusFailed:
+			r.Failed++
+		}
+	}
+
+	agents := make(map[string]map[string]int)
+	daemonCommits := make(map[string]map[string]bool)
+	for _, commit := range commits {
+		repository, ok := repositoryOf[commit.SessionID]
+		if !ok {
+			repository = commit.Repository
+		}
+		r := get(repository)
+		r.Commits++
+		r.LinesAdded += commit.Additions
+		r.LinesDeleted += commit.Deletions
+
+		model := "unknown"
+		if session := byID[commit.SessionID]; session != nil {
+			if model = session.ModelID; model == "" {
+				model = session.Model
+			}
+			if model == "" {
+				model = "unknown"
+			}
+		}
+		if agents[repository] == nil {
+			agents[repository] = make(map[string]int)
+			daemonCommits[repository] = make(map[string]bool)
+		}
+		agents[repository][model]++
+		daemonCommits[repository][commit.Hash] = true
+	}
+
+	for _, count := range approvals {
+		repository, ok := repositoryOf[count.SessionID]
+		if !ok {
+			continue
+		}
+		a := &get(repository).Approvals
+		a.Total += count.Count
+		switch count.Status {
+		case store.ApprovalStatusLocalApproved:
+			a.Approved += count.Count
+		case store.ApprovalStatusLocalDenied:
+			a.Denied += count.Count
+		case store.ApprovalStatusLocalPending:
+			a.Pending += count.Count
+		}
+	}
+
+	report := &Report{Period: period, Start: start, End: now, Repositories: []Repository{}}
+	for name, r := range repositories {
+		if finished := r.Completed + r.Failed; finished > 0 {
+			r.FailureRate = float64(r.Failed) / float64(finished)
+		}
+		r.Contributors = []Contributor{}
+		for model, n := range agents[name] {
+			r.Contributors = append(r.Contributors, Contributor{Name: model, Kind: KindAgent, Commits: n})
+		}
+		if local[name] {
+			r.Contributors = append(r.Contributors, humans(ctx, name, start, daemonCommits[name])...)
+		}
+		sort.Slice(r.Contributors, func(i, j int) bool {
+			a, b := r.Contributors[i], r.Contributors[j]
+			if a.Commits != b.Commits {
+				return a.Commits > b.Commits
+			}
+			return a.Name < b.Name
+		})
+		if len(r.Contributors) > maxContributors {
+			r.Contributors = r.Contributors[:maxContributors]
+		}
+		report.Repositories = append(report.Repositories, *r)
+	}
+	sort.Slice(report.Repositories, func(i, j int) bool {
+		a, b := report.Repositories[i], report.Repositories[j]
+		if a.Sessions+a.Commits != b.Sessions+b.Commits {
+			return a.Sessions+a.Commits > b.Sessions+b.Commits
+		}
+		return a.Repository < b.Repository
+	})
+	return report, nil
+}
+
+// repositoryRoot returns the top level of the git repository containing
+// dir, or dir itself if it isn't in one
+func repositoryRoot(ctx context.Context, dir string) string {
+	if root, err := git(ctx, dir, "rev-parse", "--show-toplevel"); err == nil && root != "" {
+		return root
+	}
+	return dir
+}
+
+// humans counts the authors of the commits since start the daemon didn't make
+func humans(ctx context.Context, repository string, start time.Time, daemonCommits map[string]bool) []Contributor {
+	output, err := git(ctx, repository, "log", "--no-merges", "--since="+start.Format(time.RFC3339), "--format=%H%x1f%an%x1f%ae")
+	if err != nil {
+		return nil
+	}
+	byEmail := make(map[string]*Contributor)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) != 3 || daemonCommits[fields[0]] {
+			continue
+		}
+		email := strings.ToLower(fields[2])
+		c, ok := byEmail[email]
+		if !ok {
+			c = &Contributor{Name: fields[1], Email: fields[2], Kind: KindHuman}
+			byEmail[email] = c
+		}
+		c.Commits++
+	}
+	contributors := make([]Contributor, 0, len(byEmail))
+	for _, c := range byEmail {
+		contributors = append(contributors, *c)
+	}
+	return contributors
+}
+
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := workspace.Local{}.Command(ctx, dir, "git", args...)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,89 @@
+package activity
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gitCmd(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	require.NoError(t, err, string(out))
+	return strings.TrimSpace(string(out))
+}
+
+func commitAs(t *testing.T, dir, name, file string) string {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(file+"\n"), 0644))
+	gitCmd(t, dir, "add", file)
+	gitCmd(t, dir, "-c", "user.name="+name, "-c", "user.email="+strings.ToLower(name)+"@example.com", "commit", "-q", "-m", "add "+file)
+	return gitCmd(t, dir, "rev-parse", "HEAD")
+}
+
+func TestBuildReport(t *testing.T) {
+	ctx := context.Background()
+	repo := t.TempDir()
+	gitCmd(t, repo, "init", "-q")
+	commitAs(t, repo, "Ada", "a.txt")
+	commitAs(t, repo, "Ada", "b.txt")
+	agentCommit := commitAs(t, repo, "Ada", "c.txt")
+	commitAs(t, repo, "Grace", "d.txt")
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "web"), 0755))
+	root := gitCmd(t, repo, "rev-parse", "--show-toplevel")
+
+	s := store.NewInMemoryStore()
+	now := time.Now()
+	for _, session := range []*store.Session{
+		{ID: "s1", RunID: "r1", WorkingDir: repo, Model: "opus", Status: store.SessionStatusCompleted},
+		{ID: "s2", RunID: "r2", WorkingDir: filepath.Join(repo, "web"), Status: store.SessionStatusFailed},
+		{ID: "s3", RunID: "r3", WorkingDir: repo, Status: store.SessionStatusRunning},
+		{ID: "s4", RunID: "r4", WorkingDir: "/work/api", SSHHost: "devbox", Status: store.SessionStatusCompleted},
+	} {
+		session.CreatedAt, session.LastActivityAt = now, now
+		require.NoError(t, s.CreateSession(ctx, session))
+	}
+	require.NoError(t, s.CreateSessionCommit(ctx, &store.SessionCommit{
+		SessionID: "s1", Repository: root, Hash: agentCommit, Additions: 12, Deletions: 3,
+	}))
+	for _, id := range []string{"a1", "a2"} {
+		require.NoError(t, s.CreateApproval(ctx, &store.Approval{
+			ID: id, RunID: "r1", SessionID: "s1", Status: store.ApprovalStatusLocalPending,
+			CreatedAt: now, ToolName: "Bash", ToolInput: json.RawMessage(`{}`),
+		}))
+	}
+	require.NoError(t, s.UpdateApprovalResponse(ctx, "a2", store.ApprovalStatusLocalDenied, "no"))
+
+	report, err := BuildReport(ctx, s, s, config.CostBudgetPeriodMonth, now.Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, report.Repositories, 2)
+
+	local := report.Repositories[0]
+	assert.Equal(t, root, local.Repository, "sessions in subdirectories count toward their repository")
+	assert.Equal(t, 3, local.Sessions)
+	assert.Equal(t, 1, local.Failed)
+	assert.InDelta(t, 0.5, local.FailureRate, 0.001)
+	assert.Equal(t, 1, local.Commits)
+	assert.Equal(t, 12, local.LinesAdded)
+	assert.Equal(t, Approvals{Total: 2, Denied: 1, Pending: 1}, local.Approvals)
+	assert.Equal(t, []Contributor{
+		{Name: "Ada", Email: "ada@example.com", Kind: KindHuman, Commits: 2},
+		{Name: "Grace", Email: "grace@example.com", Kind: KindHuman, Commits: 1},
+		{Name: "opus", Kind: KindAgent, Commits: 1},
+	}, local.Contributors, "the daemon's commit counts for its model, not its author")
+
+	remote := report.Repositories[1]
+	assert.Equal(t, "devbox:/work/api", remote.Repository)
+	assert.Equal(t, 1, remote.Completed)
+	assert.Empty(t, remote.Contributors)
+}
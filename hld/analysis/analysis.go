@@ -0,0 +1,242 @@
+// Package analysis runs configured formatters (gofmt, prettier, black, ...)
+// and static analyzers (go vet, staticcheck, eslint, ruff, ...) over the
+// files a commit includes, parsing what the analyzers report into findings.
+package analysis
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/cmdstream"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/workspace"
+)
+
+// defaultTimeout bounds an analyzer or formatter without its own timeout
+const defaultTimeout = 2 * time.Minute
+
+// maxOutput caps the analyzer output kept for an error message
+const maxOutput = 2000
+
+// Finding is one issue an analyzer reported
+type Finding struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column,omitempty"`
+	Severity string `json:"severity"`
+	Rule     string `json:"rule,omitempty"`
+	Message  string `json:"message"`
+}
+
+// Result is one analyzer's run
+type Result struct {
+	Analyzer string    `json:"analyzer"`
+	Findings []Finding `json:"findings"`
+	// Error is set when the analyzer couldn't run, or failed without
+	// reporting findings
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"durationMs"`
+}
+
+// ForRepository returns the analyzers configured for dir
+func ForRepository(cfg config.AnalysisConfig, dir string) []config.AnalyzerConfig {
+	return forRepository(cfg.Repositories, dir)
+}
+
+// FormattersFor returns the formatters configured for dir
+func FormattersFor(cfg config.AnalysisConfig, dir string) []config.FormatterConfig {
+	return forRepository(cfg.Formatters, dir)
+}
+
+// forRepository picks the entry of the deepest configured directory
+// containing dir, else the one under "*"
+func forRepository[T any](repos map[string][]T, dir string) []T {
+	best := ""
+	for repo := range repos {
+		if repo == "*" || len(repo) <= len(best) {
+			continue
+		}
+		if rel, err := filepath.Rel(repo, dir); err == nil && rel != ".." && !strings.HasPrefix(rel, "../") {
+			best = repo
+		}
+	}
+	if best == "" {
+		return repos["*"]
+	}
+	return repos[best]
+}
+
+// Run runs each analyzer in dir on host over files (relative to dir),
+// skipping analyzers none of the files match. Their output is streamed as
+// part of op, which may be nil.
+func Run(ctx context.Context, host workspace.Host, dir string, analyzers []config.AnalyzerConfig, files []string, op *cmdstream.Operation) []Result {
+	var results []Result
+	for _, analyzer := range analyzers {
+		selected := Select(analyzer.Patterns, files)
+		if len(selected) == 0 {
+			continue
+		}
+		results = append(results, run(ctx, host, dir, analyzer, selected, op.Command(analyzer.Name)))
+	}
+	return results
+}
+
+// Select returns the files matching any pattern. A pattern without a slash
+// matches the file's base name, like .gitignore.
+func Select(patterns, files []string) []string {
+	if len(patterns) == 0 {
+		return files
+	}
+	var selected []string
+	for _, file := range files {
+		for _, pattern := range patterns {
+			name := file
+			if !strings.Contains(pattern, "/") {
+				name = filepath.Base(file)
+			}
+			if ok, _ := filepath.Match(pattern, name); ok {
+				selected = append(selected, file)
+				break
+			}
+		}
+	}
+	return selected
+}
+
+// timeoutOf parses a configured timeout, falling back to the default
+func timeoutOf(configured string) time.Duration {
+	if d, err := time.ParseDuration(configured); err == nil && d > 0 {
+		return d
+	}
+	return defaultTimeout
+}
+
+func run(ctx context.Context, host workspace.Host, dir string, analyzer config.AnalyzerConfig, files []string, stream *cmdstream.Command) Result {
+	timeout := timeoutOf(analyzer.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	args := append([]string{}, analyzer.Command[1:]...)
+	if analyzer.PassFiles {
+		args = append(args, files...)
+	}
+	cmd := host.Command(ctx, dir, analyzer.Command[0], args...)
+	var output bytes.Buffer
+	stream.Attach(cmd, &output)
+
+	start := time.Now()
+	err := cmd.Run()
+	stream.Finish(err)
+	result := Result{Analyzer: analyzer.Name, Findings: []Finding{}, DurationMS: time.Since(start).Milliseconds()}
+
+	severity := analyzer.Severity
+	if severity == "" {
+		severity = config.AnalyzerSeverityError
+	}
+	wanted := make(map[string]bool, len(files))
+	for _, file := range files {
+		wanted[file] = true
+	}
+	for _, finding := range Parse(output.String(), dir, severity) {
+		if wanted[finding.File] {
+			result.Findings = append(result.Findings, finding)
+		}
+	}
+
+	// Linters exit non-zero when they find something; only a failure with
+	// nothing parsed, or one we caused, is an error
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		result.Error = fmt.Sprintf("timed out after %s", timeout)
+	case err != nil && !errors.As(err, &exitErr):
+		result.Error = err.Error()
+	case err != nil && !hasFindings(output.String(), dir):
+		result.Error = fmt.Sprintf("%v: %s", err, truncate(strings.TrimSpace(output.String())))
+	}
+	return result
+}
+
+func hasFindings(output, dir string) bool {
+	return len(Parse(output, dir, config.AnalyzerSeverityError)) > 0
+}
+
+func truncate(s string) string {
+	if len(s) > maxOutput {
+		return s[:maxOutput] + "..."
+	}
+	return s
+}
+
+var (
+	findingLine = regexp.MustCompile(`^(?:vet: )?([^\s:][^:]*):(\d+)(?::(\d+))?:\s*(.+)$`)
+	// eslint -f unix ends each message with [Error/rule] or [Warning/rule]
+	eslintSuffix = regexp.MustCompile(`\s*\[(Error|Warning)(?:/([^\]]+))?\]$`)
+	// staticcheck ends each message with (SA1000)
+	checkSuffix = regexp.MustCompile(`\s*\(([A-Z]+\d+)\)$`)
+	// ruff starts each message with its code
+	codePrefix = regexp.MustCompile(`^([A-Z]+\d+)\s+`)
+	// A leading "warning:" or "error:" gives the severity
+	severityPrefix = regexp.MustCompile(`(?i)^(error|warning):\s*`)
+)
+
+// Parse reads file:line[:col]: message lines. Paths are made relative to
+// dir; severity applies where the line doesn't give one.
+func Parse(output, dir, severity string) []Finding {
+	var findings []Finding
+	for _, line := range strings.Split(output, "\n") {
+		m := findingLine.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if m == nil {
+			continue
+		}
+		file := m[1]
+		if filepath.IsAbs(file) {
+			rel, err := filepath.Rel(dir, file)
+			if err != nil || strings.HasPrefix(rel, "..") {
+				continue
+			}
+			file = rel
+		}
+		finding := Finding{File: filepath.ToSlash(filepath.Clean(file)), Severity: severity, Message: m[4]}
+		finding.Line, _ = strconv.Atoi(m[2])
+		finding.Column, _ = strconv.Atoi(m[3])
+
+		if s := eslintSuffix.FindStringSubmatch(finding.Message); s != nil {
+			finding.Severity = strings.ToLower(s[1])
+			finding.Rule = s[2]
+			finding.Message = strings.TrimSuffix(finding.Message, s[0])
+		} else if s := checkSuffix.FindStringSubmatch(finding.Message); s != nil {
+			finding.Rule = s[1]
+			finding.Message = strings.TrimSuffix(finding.Message, s[0])
+		} else if s := codePrefix.FindStringSubmatch(finding.Message); s != nil {
+			finding.Rule = s[1]
+			finding.Message = strings.TrimPrefix(finding.Message, s[0])
+		}
+		if s := severityPrefix.FindStringSubmatch(finding.Message); s != nil {
+			finding.Severity = strings.ToLower(s[1])
+			finding.Message = strings.TrimPrefix(finding.Message, s[0])
+		}
+		findings = append(findings, finding)
+	}
+	return findings
+}
+
+// Blocking reports whether any result has an error-severity finding
+func Blocking(results []Result) bool {
+	for _, result := range results {
+		for _, finding := range result.Findings {
+			if finding.Severity == config.AnalyzerSeverityError {
+				return true
+			}
+		}
+	}
+	return false
+}
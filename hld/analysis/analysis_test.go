@@ -0,0 +1,136 @@
+package analysis
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/cmdstream"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/workspace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	output := `# example.com/app
+vet: ./main.go:12:2: unreachable code
+/repo/pkg/util.go:3:1: should omit type int from declaration (ST1023)
+app/models.py:1:8: F401 [*] 'os' imported but unused
+src/index.js:4:7: 'x' is assigned a value but never used. [Warning/no-unused-vars]
+/elsewhere/other.go:1:1: outside the repository
+lib.go:9: warning: shadowed variable
+Found 1 error.
+`
+	findings := Parse(output, "/repo", config.AnalyzerSeverityError)
+	assert.Equal(t, []Finding{
+		{File: "main.go", Line: 12, Column: 2, Severity: "error", Message: "unreachable code"},
+		{File: "pkg/util.go", Line: 3, Column: 1, Severity: "error", Rule: "ST1023", Message: "should omit type int from declaration"},
+		{File: "app/models.py", Line: 1, Column: 8, Severity: "error", Rule: "F401", Message: "[*] 'os' imported but unused"},
+		{File: "src/index.js", Line: 4, Column: 7, Severity: "warning", Rule: "no-unused-vars", Message: "'x' is assigned a value but never used."},
+		{File: "lib.go", Line: 9, Severity: "warning", Message: "shadowed variable"},
+	}, findings)
+}
+
+func TestSelect(t *testing.T) {
+	files := []string{"main.go", "web/src/app.ts", "web/README.md", "scripts/run.py"}
+	assert.Equal(t, files, Select(nil, files))
+	assert.Equal(t, []string{"main.go"}, Select([]string{"*.go"}, files))
+	assert.Equal(t, []string{"web/src/app.ts", "scripts/run.py"}, Select([]string{"*.ts", "scripts/*.py"}, files))
+}
+
+func TestForRepository(t *testing.T) {
+	cfg := config.AnalysisConfig{Repositories: map[string][]config.AnalyzerConfig{
+		"*":           {{Name: "default"}},
+		"/src/app":    {{Name: "app"}},
+		"/src/app/ui": {{Name: "ui"}},
+	}}
+	assert.Equal(t, "ui", ForRepository(cfg, "/src/app/ui/web")[0].Name)
+	assert.Equal(t, "app", ForRepository(cfg, "/src/app")[0].Name)
+	assert.Equal(t, "default", ForRepository(cfg, "/src/application")[0].Name)
+	assert.Empty(t, ForRepository(config.AnalysisConfig{}, "/src/app"))
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.go"), nil, 0644))
+	analyzers := []config.AnalyzerConfig{
+		{
+			// Reports one finding per file it's given and exits 1, like a linter
+			Name:      "lint",
+			Command:   []string{"sh", "-c", `for f in "$@"; do echo "$f:1:1: bad thing"; done; echo "other.go:2:1: not committed"; exit 1`, "lint"},
+			Patterns:  []string{"*.go"},
+			PassFiles: true,
+			Severity:  config.AnalyzerSeverityWarning,
+		},
+		{Name: "python", Command: []string{"ruff", "check"}, Patterns: []string{"*.py"}},
+		{Name: "broken", Command: []string{"sh", "-c", "echo crashed >&2; exit 2"}},
+		{Name: "missing", Command: []string{"no-such-analyzer-binary"}},
+		{Name: "slow", Command: []string{"sleep", "5"}, Timeout: "50ms"},
+	}
+
+	eventBus := bus.NewEventBus()
+	events := eventBus.Subscribe(context.Background(), bus.EventFilter{Types: []bus.EventType{bus.EventCommandOutput}})
+	results := Run(context.Background(), workspace.Local{}, dir, analyzers, []string{"a.go", "b.go"}, cmdstream.New(eventBus, "op-1", "sess-1"))
+	require.Len(t, results, 4, "python has no files to analyze")
+
+	assert.Equal(t, "lint", results[0].Analyzer)
+	assert.Empty(t, results[0].Error)
+	require.Len(t, results[0].Findings, 2)
+	assert.Equal(t, Finding{File: "a.go", Line: 1, Column: 1, Severity: "warning", Message: "bad thing"}, results[0].Findings[0])
+	assert.False(t, Blocking(results[:1]), "warnings don't block")
+
+	assert.Contains(t, results[1].Error, "crashed")
+	assert.NotEmpty(t, results[2].Error)
+	assert.Contains(t, results[3].Error, "timed out")
+	assert.False(t, Blocking(results), "analyzer failures don't block")
+
+	results[0].Findings[0].Severity = config.AnalyzerSeverityError
+	assert.True(t, Blocking(results))
+
+	// Each analyzer's output was streamed, then its exit
+	var broken []map[string]interface{}
+	for len(events.Channel) > 0 {
+		event := <-events.Channel
+		assert.Equal(t, "op-1", event.Data["operation_id"])
+		if event.Data["command"] == "broken" {
+			broken = append(broken, event.Data)
+		}
+	}
+	require.Len(t, broken, 2)
+	assert.Equal(t, "stderr", broken[0]["stream"])
+	assert.Equal(t, "crashed", broken[0]["line"])
+	assert.Equal(t, true, broken[1]["done"])
+	assert.Equal(t, 2, broken[1]["exit_code"])
+}
+
+func TestFormat(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"a.go": "messy", "b.go": "tidy\n", "c.py": "messy"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	formatters := []config.FormatterConfig{
+		{
+			// Rewrites each file to "tidy"
+			Name:     "tidy",
+			Command:  []string{"sh", "-c", `for f in "$@"; do echo tidy > "$f"; done`, "tidy"},
+			Patterns: []string{"*.go"},
+		},
+		{Name: "black", Command: []string{"black"}, Patterns: []string{"*.py"}},
+		{Name: "failing", Command: []string{"sh", "-c", "echo syntax error >&2; exit 123"}, Patterns: []string{"*.go"}},
+	}
+
+	results := Format(context.Background(), workspace.Local{}, dir, formatters, []string{"a.go", "b.go", "gone.go"}, nil)
+	require.Len(t, results, 2, "black has no files to format")
+	assert.Equal(t, "tidy", results[0].Formatter)
+	assert.Equal(t, []string{"a.go"}, results[0].Changed)
+	assert.Empty(t, results[0].Error)
+	assert.Contains(t, results[1].Error, "syntax error")
+	assert.Empty(t, results[1].Changed)
+
+	content, err := os.ReadFile(filepath.Join(dir, "a.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "tidy\n", string(content))
+}
@@ -0,0 +1,88 @@
+package analysis
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/cmdstream"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/workspace"
+)
+
+// maxFormatSize caps the files compared before and after formatting
+const maxFormatSize = 4 << 20
+
+// FormatResult is one formatter's run
+type FormatResult struct {
+	Formatter string `json:"formatter"`
+	// Changed lists the files the formatter rewrote
+	Changed    []string `json:"changed"`
+	Error      string   `json:"error,omitempty"`
+	DurationMS int64    `json:"durationMs"`
+}
+
+// Format runs each formatter in dir on host over the files (relative to dir)
+// matching its patterns, in order, so later formatters see earlier ones'
+// output. Their output is streamed as part of op, which may be nil.
+func Format(ctx context.Context, host workspace.Host, dir string, formatters []config.FormatterConfig, files []string, op *cmdstream.Operation) []FormatResult {
+	var results []FormatResult
+	for _, formatter := range formatters {
+		selected := Select(formatter.Patterns, files)
+		if len(selected) == 0 {
+			continue
+		}
+		results = append(results, format(ctx, host, dir, formatter, selected, op))
+	}
+	return results
+}
+
+func format(ctx context.Context, host workspace.Host, dir string, formatter config.FormatterConfig, files []string, op *cmdstream.Operation) FormatResult {
+	timeout := timeoutOf(formatter.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// Snapshot what's there now to tell which files the formatter changed
+	before := make(map[string][]byte, len(files))
+	var present []string
+	for _, file := range files {
+		content, err := host.ReadFile(ctx, filepath.Join(dir, file), maxFormatSize)
+		if err != nil {
+			continue
+		}
+		before[file] = content
+		present = append(present, file)
+	}
+	result := FormatResult{Formatter: formatter.Name, Changed: []string{}}
+	if len(present) == 0 {
+		return result
+	}
+
+	args := append(append([]string{}, formatter.Command[1:]...), present...)
+	cmd := host.Command(ctx, dir, formatter.Command[0], args...)
+	stream := op.Command(formatter.Name)
+	var output bytes.Buffer
+	stream.Attach(cmd, &output)
+	start := time.Now()
+	err := cmd.Run()
+	stream.Finish(err)
+	result.DurationMS = time.Since(start).Milliseconds()
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		result.Error = fmt.Sprintf("timed out after %s", timeout)
+	case err != nil:
+		result.Error = fmt.Sprintf("%v: %s", err, truncate(strings.TrimSpace(output.String())))
+	}
+
+	// A failed formatter may still have rewritten some files
+	for _, file := range present {
+		after, err := host.ReadFile(context.WithoutCancel(ctx), filepath.Join(dir, file), maxFormatSize)
+		if err == nil && !bytes.Equal(before[file], after) {
+			result.Changed = append(result.Changed, file)
+		}
+	}
+	return result
+}
@@ -0,0 +1,228 @@
+// Package announce speaks pending approvals and finished sessions aloud
+// through a local text-to-speech command, for operators who keep the daemon
+// on a machine they aren't looking at.
+package announce
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+const (
+	// queueSize bounds announcements waiting to be spoken; more are dropped
+	// rather than read out long after the fact
+	queueSize = 8
+	// speakTimeout stops a hung speech command from blocking the queue
+	speakTimeout = time.Minute
+	// maxAction keeps a spoken command description short
+	maxAction = 120
+)
+
+// Speaker reads text aloud
+type Speaker interface {
+	Speak(ctx context.Context, text string) error
+}
+
+// CommandSpeaker speaks by running a command with the text as its last
+// argument
+type CommandSpeaker []string
+
+// Speak runs the command and waits for it to finish
+func (c CommandSpeaker) Speak(ctx context.Context, text string) error {
+	args := append(append([]string{}, c[1:]...), text)
+	if out, err := exec.CommandContext(ctx, c[0], args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %w: %s", c[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// DefaultCommand finds a text-to-speech command for this platform
+func DefaultCommand() ([]string, error) {
+	if runtime.GOOS == "darwin" {
+		return []string{"say"}, nil
+	}
+	candidates := [][]string{{"spd-say", "--wait"}, {"espeak-ng"}, {"espeak"}}
+	for _, candidate := range candidates {
+		if _, err := exec.LookPath(candidate[0]); err == nil {
+			return candidate, nil
+		}
+	}
+	return nil, errors.New("no text-to-speech command found; install espeak-ng or set announce.command")
+}
+
+// Announcer turns events into spoken announcements
+type Announcer struct {
+	store    store.ConversationStore
+	speaker  Speaker
+	events   map[string]bool
+	quiet    config.QuietHours
+	eventBus bus.EventBus
+	now      func() time.Time
+}
+
+// New creates an announcer speaking the given events through speaker. No
+// events means approvals only.
+func New(s store.ConversationStore, speaker Speaker, events []string, quiet config.QuietHours, eventBus bus.EventBus) *Announcer {
+	if len(events) == 0 {
+		events = []string{config.AnnounceApproval}
+	}
+	enabled := make(map[string]bool, len(events))
+	for _, event := range events {
+		enabled[event] = true
+	}
+	return &Announcer{store: s, speaker: speaker, events: enabled, quiet: quiet, eventBus: eventBus, now: time.Now}
+}
+
+// FromConfig creates the configured announcer, or returns nil if
+// announcements are off
+func FromConfig(cfg *config.Config, s store.ConversationStore, eventBus bus.EventBus) (*Announcer, error) {
+	if !cfg.Announce.Enabled {
+		return nil, nil
+	}
+	command := cfg.Announce.Command
+	if len(command) == 0 {
+		var err error
+		if command, err = DefaultCommand(); err != nil {
+			return nil, err
+		}
+	}
+	return New(s, CommandSpeaker(command), cfg.Announce.Events, cfg.Announce.QuietHours, eventBus), nil
+}
+
+// Run speaks announcements as events arrive, one at a time, until ctx is done
+func (a *Announcer) Run(ctx context.Context) {
+	sub := a.eventBus.Subscribe(ctx, bus.EventFilter{
+		Types: []bus.EventType{bus.EventNewApproval, bus.EventSessionStatusChanged},
+	})
+	queue := make(chan string, queueSize)
+	go a.speak(ctx, queue)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-sub.Channel:
+			if !ok {
+				return
+			}
+			text := a.Announcement(ctx, event)
+			if text == "" {
+				continue
+			}
+			if quiet, _ := a.quiet.Contains(a.now()); quiet {
+				continue
+			}
+			select {
+			case queue <- text:
+			default:
+				slog.Debug("dropped announcement, too many waiting", "text", text)
+			}
+		}
+	}
+}
+
+func (a *Announcer) speak(ctx context.Context, queue <-chan string) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case text := <-queue:
+			speakCtx, cancel := context.WithTimeout(ctx, speakTimeout)
+			if err := a.speaker.Speak(speakCtx, text); err != nil {
+				slog.Warn("failed to speak announcement", "error", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// Announcement returns what to say for event, or "" if it isn't announced
+func (a *Announcer) Announcement(ctx context.Context, event bus.Event) string {
+	sessionID, _ := event.Data["session_id"].(string)
+	switch event.Type {
+	case bus.EventNewApproval:
+		if !a.events[config.AnnounceApproval] {
+			return ""
+		}
+		approvalID, _ := event.Data["approval_id"].(string)
+		approval, err := a.store.GetApproval(ctx, approvalID)
+		if err != nil {
+			slog.Debug("approval to announce not found", "approval_id", approvalID, "error", err)
+			return ""
+		}
+		return fmt.Sprintf("Session %s is requesting to %s", a.sessionName(ctx, sessionID), action(approval.ToolName, approval.ToolInput))
+	case bus.EventSessionStatusChanged:
+		status, _ := event.Data["new_status"].(string)
+		switch {
+		case status == store.SessionStatusCompleted && a.events[config.AnnounceSessionCompleted]:
+			return fmt.Sprintf("Session %s finished", a.sessionName(ctx, sessionID))
+		case status == store.SessionStatusFailed && a.events[config.AnnounceSessionFailed]:
+			return fmt.Sprintf("Session %s failed", a.sessionName(ctx, sessionID))
+		}
+	}
+	return ""
+}
+
+// sessionName is how a session is spoken: its title, or the directory it
+// works in, which for worktrees usually names the task
+func (a *Announcer) sessionName(ctx context.Context, sessionID string) string {
+	if session, err := a.store.GetSession(ctx, sessionID); err == nil {
+		if session.Title != "" {
+			return session.Title
+		}
+		if session.WorkingDir != "" {
+			return filepath.Base(session.WorkingDir)
+		}
+	}
+	if len(sessionID) > 8 {
+		return sessionID[:8]
+	}
+	return sessionID
+}
+
+// action describes a tool call as a verb phrase, preferring the description
+// Claude gives its shell commands
+func action(toolName string, toolInput json.RawMessage) string {
+	var input struct {
+		Description string `json:"description"`
+		FilePath    string `json:"file_path"`
+	}
+	_ = json.Unmarshal(toolInput, &input)
+	description := strings.TrimRight(strings.TrimSpace(input.Description), ".")
+	if len(description) > maxAction {
+		description = strings.ToValidUTF8(description[:maxAction], "")
+	}
+	switch {
+	case description != "":
+		return lowerFirst(description)
+	case toolName == "Bash":
+		return "run a shell command"
+	case input.FilePath != "" && (toolName == "Edit" || toolName == "MultiEdit" || toolName == "Write"):
+		return "edit " + filepath.Base(input.FilePath)
+	}
+	return "use " + toolName
+}
+
+// lowerFirst lowercases a leading capital, unless it starts an acronym
+func lowerFirst(s string) string {
+	first, size := utf8.DecodeRuneInString(s)
+	next, _ := utf8.DecodeRuneInString(s[size:])
+	if unicode.IsUpper(next) {
+		return s
+	}
+	return string(unicode.ToLower(first)) + s[size:]
+}
@@ -0,0 +1,128 @@
+package announce
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSpeaker records what is spoken
+type fakeSpeaker struct {
+	mu     sync.Mutex
+	spoken []string
+}
+
+func (f *fakeSpeaker) Speak(ctx context.Context, text string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.spoken = append(f.spoken, text)
+	return nil
+}
+
+func (f *fakeSpeaker) said() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.spoken...)
+}
+
+func setup(t *testing.T, events []string, quiet config.QuietHours) (*Announcer, *fakeSpeaker, bus.EventBus) {
+	t.Helper()
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	require.NoError(t, s.CreateSession(ctx, &store.Session{ID: "sess-1", RunID: "run-1", WorkingDir: "/src/worktrees/payments-fix"}))
+	require.NoError(t, s.CreateApproval(ctx, &store.Approval{
+		ID: "appr-1", RunID: "run-1", SessionID: "sess-1", Status: store.ApprovalStatusLocalPending, CreatedAt: time.Now(),
+		ToolName: "Bash", ToolInput: json.RawMessage(`{"command":"make migrate","description":"Run database migration"}`),
+	}))
+	eventBus := bus.NewEventBus()
+	speaker := &fakeSpeaker{}
+	return New(s, speaker, events, quiet, eventBus), speaker, eventBus
+}
+
+func TestAnnouncement(t *testing.T) {
+	ctx := context.Background()
+	a, _, _ := setup(t, nil, config.QuietHours{})
+
+	approval := bus.Event{Type: bus.EventNewApproval, Data: map[string]interface{}{"approval_id": "appr-1", "session_id": "sess-1"}}
+	assert.Equal(t, "Session payments-fix is requesting to run database migration", a.Announcement(ctx, approval))
+
+	// Only approvals are announced by default
+	completed := bus.Event{Type: bus.EventSessionStatusChanged, Data: map[string]interface{}{"session_id": "sess-1", "new_status": "completed"}}
+	assert.Empty(t, a.Announcement(ctx, completed))
+
+	a, _, _ = setup(t, []string{config.AnnounceSessionCompleted}, config.QuietHours{})
+	assert.Equal(t, "Session payments-fix finished", a.Announcement(ctx, completed))
+	assert.Empty(t, a.Announcement(ctx, approval))
+}
+
+func TestAction(t *testing.T) {
+	assert.Equal(t, "run a shell command", action("Bash", json.RawMessage(`{"command":"ls"}`)))
+	assert.Equal(t, "edit main.go", action("Edit", json.RawMessage(`{"file_path":"/src/app/main.go"}`)))
+	assert.Equal(t, "use WebFetch", action("WebFetch", json.RawMessage(`{"url":"https://example.com"}`)))
+	assert.Equal(t, "deploy to staging", action("Bash", json.RawMessage(`{"description":"Deploy to staging."}`)))
+	assert.Equal(t, "SSH into the build box", action("Bash", json.RawMessage(`{"description":"SSH into the build box"}`)))
+}
+
+func TestQuietHours(t *testing.T) {
+	quiet := config.QuietHours{Start: "22:00", End: "07:00"}
+	at := func(clock string) time.Time {
+		t, _ := time.Parse("15:04", clock)
+		return t
+	}
+	for clock, want := range map[string]bool{"21:59": false, "22:00": true, "03:00": true, "06:59": true, "07:00": false, "12:00": false} {
+		got, err := quiet.Contains(at(clock))
+		require.NoError(t, err)
+		assert.Equal(t, want, got, clock)
+	}
+	got, err := config.QuietHours{}.Contains(at("03:00"))
+	require.NoError(t, err)
+	assert.False(t, got)
+	_, err = config.QuietHours{Start: "10pm", End: "07:00"}.Contains(at("03:00"))
+	assert.Error(t, err)
+}
+
+func TestRunSkipsQuietHours(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a, speaker, eventBus := setup(t, []string{config.AnnounceApproval, config.AnnounceSessionCompleted}, config.QuietHours{Start: "22:00", End: "07:00"})
+	var clock atomic.Pointer[time.Time]
+	night := time.Date(2025, 1, 1, 23, 0, 0, 0, time.Local)
+	clock.Store(&night)
+	// checked signals each time an announcement is held up to the clock
+	checked := make(chan struct{}, 1)
+	a.now = func() time.Time {
+		defer func() { checked <- struct{}{} }()
+		return *clock.Load()
+	}
+	waitChecked := func() {
+		t.Helper()
+		select {
+		case <-checked:
+		case <-time.After(time.Second):
+			t.Fatal("the event wasn't considered for announcement")
+		}
+	}
+	go a.Run(ctx)
+	require.Eventually(t, func() bool { return eventBus.GetSubscriberCount() == 1 }, time.Second, 10*time.Millisecond)
+
+	eventBus.Publish(bus.Event{Type: bus.EventNewApproval, Data: map[string]interface{}{"approval_id": "appr-1", "session_id": "sess-1"}})
+	waitChecked()
+
+	morning := night.Add(9 * time.Hour)
+	clock.Store(&morning)
+	eventBus.Publish(bus.Event{Type: bus.EventSessionStatusChanged, Data: map[string]interface{}{"session_id": "sess-1", "new_status": "completed"}})
+	waitChecked()
+
+	// Announcements are spoken in order, so the first is the morning's
+	require.Eventually(t, func() bool { return len(speaker.said()) > 0 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"Session payments-fix finished"}, speaker.said())
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/activity"
+	"github.com/humanlayer/humanlayer/hld/api"
+	"github.com/humanlayer/humanlayer/hld/api/mapper"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/humanlayer/humanlayer/hld/usage"
+)
+
+// Activity feed item types
+const (
+	ActivityEvent      = "event"
+	ActivityApproval   = "approval"
+	ActivityCommit     = "commit"
+	ActivityJob        = "job"
+	ActivityAnnotation = "annotation"
+)
+
+var activityTypes = []string{ActivityEvent, ActivityApproval, ActivityCommit, ActivityJob, ActivityAnnotation}
+
+// ActivityItem is one entry of a session's activity feed. Type names the
+// one other field that is set.
+type ActivityItem struct {
+	Type       string                 `json:"type"`
+	Time       time.Time              `json:"time"`
+	Event      *api.ConversationEvent `json:"event,omitempty"`
+	Approval   *api.Approval          `json:"approval,omitempty"`
+	Commit     *store.SessionCommit   `json:"commit,omitempty"`
+	Job        *store.Job             `json:"job,omitempty"`
+	Annotation *store.EventAnnotation `json:"annotation,omitempty"`
+}
+
+// ActivityHandler reports what sessions did in each repository, and
+// everything that happened in one session
+type ActivityHandler struct {
+	store       store.ConversationStore
+	commits     store.CommitStore
+	jobs        store.JobStore
+	annotations store.AnnotationStore
+	mapper      *mapper.Mapper
+}
+
+// NewActivityHandler creates a new activity handler
+func NewActivityHandler(conversationStore store.ConversationStore, commits store.CommitStore, jobs store.JobStore, annotations store.AnnotationStore) *ActivityHandler {
+	return &ActivityHandler{store: conversationStore, commits: commits, jobs: jobs, annotations: annotations, mapper: &mapper.Mapper{}}
+}
+
+// HandleGetActivity aggregates activity per repository for the current
+// ?period= (day, week or month; default month)
+func (h *ActivityHandler) HandleGetActivity(c *gin.Context) {
+	period := c.DefaultQuery("period", config.CostBudgetPeriodMonth)
+	if _, err := usage.PeriodStart(period, time.Now()); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "period must be day, week or month"})
+		return
+	}
+
+	report, err := activity.BuildReport(c.Request.Context(), h.store, h.commits, period, time.Now())
+	if err != nil {
+		slog.Error("failed to build activity report", "period", period, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build activity report"})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// HandleGetSessionActivity returns a session's conversation events,
+// approvals, commits the daemon made, jobs and annotations as one feed,
+// oldest first. Query parameters: types= to keep some item types
+// (comma-separated), since= (RFC 3339) to keep items from then on.
+func (h *ActivityHandler) HandleGetSessionActivity(c *gin.Context) {
+	ctx := c.Request.Context()
+	sessionID := c.Param("id")
+
+	types := activityTypes
+	if raw := c.Query("types"); raw != "" {
+		types = strings.Split(raw, ",")
+		for _, t := range types {
+			if !slices.Contains(activityTypes, t) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "types must be a comma-separated list of " + strings.Join(activityTypes, ", ")})
+				return
+			}
+		}
+	}
+	var since time.Time
+	if raw := c.Query("since"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "since must be an RFC 3339 timestamp"})
+			return
+		}
+		since = t
+	}
+
+	session, err := h.store.GetSession(ctx, sessionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	items, err := h.sessionActivity(ctx, session, types)
+	if err != nil {
+		slog.Error("failed to build session activity", "session_id", sessionID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build session activity"})
+		return
+	}
+	feed := make([]ActivityItem, 0, len(items))
+	for _, item := range items {
+		if !item.Time.Before(since) {
+			feed = append(feed, item)
+		}
+	}
+	// Each source is already in order; a stable sort keeps it for items
+	// recorded at the same time
+	sort.SliceStable(feed, func(i, j int) bool { return feed[i].Time.Before(feed[j].Time) })
+	c.JSON(http.StatusOK, gin.H{"data": feed})
+}
+
+// sessionActivity collects the items of the given types, unordered
+func (h *ActivityHandler) sessionActivity(ctx context.Context, session *store.Session, types []string) ([]ActivityItem, error) {
+	var items []ActivityItem
+	if slices.Contains(types, ActivityEvent) {
+		events, err := h.store.GetSessionConversation(ctx, session.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range events {
+			event := h.mapper.ConversationEventToAPI(*e)
+			items = append(items, ActivityItem{Type: ActivityEvent, Time: e.CreatedAt, Event: &event})
+		}
+	}
+	if slices.Contains(types, ActivityApproval) {
+		approvals, err := h.store.ListSessionApprovals(ctx, session.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range approvals {
+			approval := h.mapper.ApprovalToAPI(*a)
+			items = append(items, ActivityItem{Type: ActivityApproval, Time: a.CreatedAt, Approval: &approval})
+		}
+	}
+	if slices.Contains(types, ActivityCommit) {
+		commits, err := h.commits.ListSessionCommits(ctx, session.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		for _, commit := range commits {
+			if commit.SessionID == session.ID {
+				items = append(items, ActivityItem{Type: ActivityCommit, Time: commit.CreatedAt, Commit: commit})
+			}
+		}
+	}
+	if slices.Contains(types, ActivityJob) {
+		jobs, err := h.jobs.ListJobs(ctx, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, job := range jobs {
+			if job.SessionID == session.ID {
+				items = append(items, ActivityItem{Type: ActivityJob, Time: job.CreatedAt, Job: job})
+			}
+		}
+	}
+	if slices.Contains(types, ActivityAnnotation) {
+		annotations, err := h.annotations.ListEventAnnotations(ctx, store.AnnotationFilter{SessionID: session.ID})
+		if err != nil {
+			return nil, err
+		}
+		for _, annotation := range annotations {
+			items = append(items, ActivityItem{Type: ActivityAnnotation, Time: annotation.CreatedAt, Annotation: annotation})
+		}
+	}
+	return items, nil
+}
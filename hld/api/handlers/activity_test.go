@@ -0,0 +1,70 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/api/handlers"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionActivity(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	start := time.Now().Add(-time.Hour)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+
+	require.NoError(t, s.CreateSession(ctx, &store.Session{ID: "sess-1", RunID: "run-1", ClaudeSessionID: "claude-1", CreatedAt: start}))
+	require.NoError(t, s.CreateSession(ctx, &store.Session{ID: "sess-2", RunID: "run-2", ClaudeSessionID: "claude-2", CreatedAt: start}))
+	event := &store.ConversationEvent{SessionID: "sess-1", ClaudeSessionID: "claude-1", EventType: store.EventTypeMessage, Role: "user", Content: "hi", CreatedAt: at(1)}
+	require.NoError(t, s.AddConversationEvent(ctx, event))
+	require.NoError(t, s.AddConversationEvent(ctx, &store.ConversationEvent{SessionID: "sess-2", ClaudeSessionID: "claude-2", EventType: store.EventTypeMessage, CreatedAt: at(1)}))
+	require.NoError(t, s.CreateApproval(ctx, &store.Approval{ID: "appr-1", RunID: "run-1", SessionID: "sess-1", ToolName: "Bash", Status: store.ApprovalStatusLocalApproved, CreatedAt: at(4)}))
+	require.NoError(t, s.CreateSessionCommit(ctx, &store.SessionCommit{SessionID: "sess-1", Repository: "/repo", Hash: "abc123", CreatedAt: at(5)}))
+	require.NoError(t, s.CreateSessionCommit(ctx, &store.SessionCommit{SessionID: "sess-2", Repository: "/repo", Hash: "def456", CreatedAt: at(5)}))
+	require.NoError(t, s.CreateJob(ctx, &store.Job{ID: "job-1", Kind: "commit_message", SessionID: "sess-1", Status: store.JobStatusCompleted, CreatedAt: at(3)}))
+	require.NoError(t, s.CreateEventAnnotation(ctx, &store.EventAnnotation{SessionID: "sess-1", EventID: event.ID, Kind: store.AnnotationKindBookmark, CreatedAt: at(2)}))
+
+	h := handlers.NewActivityHandler(s, s, s, s)
+	router := gin.New()
+	router.GET("/api/v1/sessions/:id/activity", h.HandleGetSessionActivity)
+	get := func(query string) []handlers.ActivityItem {
+		t.Helper()
+		w := makeRequest(t, router, "GET", "/api/v1/sessions/sess-1/activity"+query, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var feed struct {
+			Data []handlers.ActivityItem `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&feed))
+		return feed.Data
+	}
+
+	feed := get("")
+	var types []string
+	for _, item := range feed {
+		types = append(types, item.Type)
+	}
+	assert.Equal(t, []string{"event", "annotation", "job", "approval", "commit"}, types)
+	assert.Equal(t, "hi", *feed[0].Event.Content)
+	assert.Equal(t, event.ID, feed[1].Annotation.EventID)
+	assert.Equal(t, "job-1", feed[2].Job.ID)
+	assert.Equal(t, "appr-1", feed[3].Approval.Id)
+	assert.Equal(t, "abc123", feed[4].Commit.Hash)
+
+	feed = get("?types=commit,approval&since=" + url.QueryEscape(at(4).Add(30*time.Second).Format(time.RFC3339)))
+	require.Len(t, feed, 1)
+	assert.Equal(t, handlers.ActivityCommit, feed[0].Type)
+
+	w := makeRequest(t, router, "GET", "/api/v1/sessions/sess-1/activity?types=event,logs", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = makeRequest(t, router, "GET", "/api/v1/sessions/missing/activity", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// maxAnnotations caps one annotation listing
+const maxAnnotations = 500
+
+// AnnotationHandler manages notes and bookmarks on conversation events
+type AnnotationHandler struct {
+	store store.AnnotationStore
+}
+
+// NewAnnotationHandler creates a new annotation handler
+func NewAnnotationHandler(annotations store.AnnotationStore) *AnnotationHandler {
+	return &AnnotationHandler{store: annotations}
+}
+
+type createAnnotationRequest struct {
+	Kind   string `json:"kind"` // note (default) or bookmark
+	Note   string `json:"note"`
+	Author string `json:"author"`
+}
+
+// HandleCreateAnnotation attaches a note or bookmark to one of the session's
+// conversation events
+func (h *AnnotationHandler) HandleCreateAnnotation(c *gin.Context) {
+	eventID, err := strconv.ParseInt(c.Param("eid"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+	var req createAnnotationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	if req.Kind == "" {
+		req.Kind = store.AnnotationKindNote
+	}
+	req.Note = strings.TrimSpace(req.Note)
+	switch {
+	case req.Kind != store.AnnotationKindNote && req.Kind != store.AnnotationKindBookmark:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kind must be note or bookmark"})
+		return
+	case req.Kind == store.AnnotationKindNote && req.Note == "":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "note is required"})
+		return
+	}
+
+	annotation := &store.EventAnnotation{
+		SessionID: c.Param("id"),
+		EventID:   eventID,
+		Kind:      req.Kind,
+		Note:      req.Note,
+		Author:    req.Author,
+	}
+	if err := h.store.CreateEventAnnotation(c.Request.Context(), annotation); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found in session"})
+			return
+		}
+		slog.Error("failed to create annotation", "session_id", annotation.SessionID, "event_id", eventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create annotation"})
+		return
+	}
+	c.JSON(http.StatusCreated, annotation)
+}
+
+// HandleListSessionAnnotations returns a session's annotations, optionally
+// filtered by kind
+func (h *AnnotationHandler) HandleListSessionAnnotations(c *gin.Context) {
+	h.list(c, store.AnnotationFilter{SessionID: c.Param("id"), Kind: c.Query("kind")})
+}
+
+// HandleListAnnotations searches annotations across sessions. Query
+// parameters: session_id, kind, q (text in the note) and limit.
+func (h *AnnotationHandler) HandleListAnnotations(c *gin.Context) {
+	filter := store.AnnotationFilter{SessionID: c.Query("session_id"), Kind: c.Query("kind"), Text: c.Query("q")}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		filter.Limit = n
+	}
+	h.list(c, filter)
+}
+
+func (h *AnnotationHandler) list(c *gin.Context, filter store.AnnotationFilter) {
+	if filter.Limit <= 0 || filter.Limit > maxAnnotations {
+		filter.Limit = maxAnnotations
+	}
+	annotations, err := h.store.ListEventAnnotations(c.Request.Context(), filter)
+	if err != nil {
+		slog.Error("failed to list annotations", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list annotations"})
+		return
+	}
+	if annotations == nil {
+		annotations = []*store.EventAnnotation{}
+	}
+	c.JSON(http.StatusOK, gin.H{"annotations": annotations})
+}
+
+// HandleDeleteAnnotation removes an annotation
+func (h *AnnotationHandler) HandleDeleteAnnotation(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid annotation ID"})
+		return
+	}
+	if err := h.store.DeleteEventAnnotation(c.Request.Context(), id); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Annotation not found"})
+			return
+		}
+		slog.Error("failed to delete annotation", "annotation_id", id, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete annotation"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/approval/replay"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// ApprovalReplayHandler exports recorded approval scenarios and replays them
+// against alternative policies
+type ApprovalReplayHandler struct {
+	store store.ConversationStore
+}
+
+// NewApprovalReplayHandler creates a new approval replay handler
+func NewApprovalReplayHandler(conversationStore store.ConversationStore) *ApprovalReplayHandler {
+	return &ApprovalReplayHandler{store: conversationStore}
+}
+
+// ReplayApprovalsRequest represents a request to replay a scenario
+type ReplayApprovalsRequest struct {
+	Scenario replay.Scenario `json:"scenario"`
+	// Policy to replay against; defaults to the scenario's recorded policy
+	Policy *replay.Policy `json:"policy,omitempty"`
+}
+
+// HandleExportScenario returns a session's approvals as a replayable scenario
+func (h *ApprovalReplayHandler) HandleExportScenario(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	if _, err := h.store.GetSession(c.Request.Context(), sessionID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	scenario, err := replay.FromSession(c.Request.Context(), h.store, sessionID)
+	if err != nil {
+		slog.Error("failed to export approval scenario", "session_id", sessionID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export approval scenario"})
+		return
+	}
+
+	c.JSON(http.StatusOK, scenario)
+}
+
+// HandleReplay replays a scenario and reports which decisions would differ
+func (h *ApprovalReplayHandler) HandleReplay(c *gin.Context) {
+	var req ReplayApprovalsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	policy := req.Scenario.Policy
+	if req.Policy != nil {
+		policy = *req.Policy
+	}
+
+	report, err := replay.Run(c.Request.Context(), &req.Scenario, policy)
+	if err != nil {
+		slog.Error("failed to replay approval scenario", "scenario", req.Scenario.Name, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to replay approval scenario"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -9,15 +9,27 @@ import (
 	"github.com/humanlayer/humanlayer/hld/api"
 	"github.com/humanlayer/humanlayer/hld/api/mapper"
 	"github.com/humanlayer/humanlayer/hld/approval"
+	"github.com/humanlayer/humanlayer/hld/artifacts"
+	"github.com/humanlayer/humanlayer/hld/moderation"
 	"github.com/humanlayer/humanlayer/hld/session"
 	"github.com/humanlayer/humanlayer/hld/store"
 	"log/slog"
+	"strings"
+	"time"
 )
 
 type ApprovalHandlers struct {
 	approvalManager approval.Manager
 	sessionManager  session.SessionManager
 	mapper          *mapper.Mapper
+
+	// For the attachments approval requests carry
+	store     store.ConversationStore
+	artifacts *artifacts.Service
+	// canned holds the canned responses decisions can start with
+	canned store.CannedResponseStore
+
+	justification *Justification
 }
 
 func NewApprovalHandlers(approvalManager approval.Manager, sessionManager session.SessionManager) *ApprovalHandlers {
@@ -28,6 +40,24 @@ func NewApprovalHandlers(approvalManager approval.Manager, sessionManager sessio
 	}
 }
 
+// SetArtifacts stores the attachments approval requests carry as artifacts.
+// Without it, requests with attachments are refused.
+func (h *ApprovalHandlers) SetArtifacts(conversationStore store.ConversationStore, service *artifacts.Service) {
+	h.store = conversationStore
+	h.artifacts = service
+}
+
+// SetCannedResponses lets decisions lead their comment with a canned
+// response. Without it, decisions naming one are refused.
+func (h *ApprovalHandlers) SetCannedResponses(responses store.CannedResponseStore) {
+	h.canned = responses
+}
+
+// SetJustification requires a comment for approving high-risk tool calls
+func (h *ApprovalHandlers) SetJustification(j *Justification) {
+	h.justification = j
+}
+
 // CreateApproval creates a new approval request
 func (h *ApprovalHandlers) CreateApproval(ctx context.Context, req api.CreateApprovalRequestObject) (api.CreateApprovalResponseObject, error) {
 	// Convert tool input to json.RawMessage
@@ -43,13 +73,36 @@ func (h *ApprovalHandlers) CreateApproval(ctx context.Context, req api.CreateApp
 		}, nil
 	}
 
-	approvalID, err := h.approvalManager.CreateApproval(
-		ctx,
-		req.Body.RunId,
-		req.Body.ToolName,
-		toolInputJSON,
-	)
+	attachments, err := h.publishAttachments(ctx, req.Body)
+	if err != nil {
+		if !errors.Is(err, approval.ErrInvalidAttachment) {
+			slog.Error("Failed to store approval attachments", "error", err, "run_id", req.Body.RunId)
+		}
+		return api.CreateApproval400JSONResponse{
+			BadRequestJSONResponse: api.BadRequestJSONResponse{
+				Error: api.ErrorDetail{
+					Code:    "HLD-3001",
+					Message: err.Error(),
+				},
+			},
+		}, nil
+	}
+
+	var approvalID string
+	if len(attachments) > 0 {
+		approvalID, err = h.approvalManager.CreateApprovalWithAttachments(ctx, req.Body.RunId, req.Body.ToolName, toolInputJSON, attachments)
+	} else {
+		approvalID, err = h.approvalManager.CreateApproval(
+			ctx,
+			req.Body.RunId,
+			req.Body.ToolName,
+			toolInputJSON,
+		)
+	}
 	if err != nil {
+		for _, id := range attachments {
+			_ = h.artifacts.Delete(ctx, id)
+		}
 		slog.Error("Failed to create approval",
 			"error", fmt.Sprintf("%v", err),
 			"run_id", req.Body.RunId,
@@ -71,6 +124,62 @@ func (h *ApprovalHandlers) CreateApproval(ctx context.Context, req api.CreateApp
 	return api.CreateApproval201JSONResponse(resp), nil
 }
 
+// publishAttachments stores the request's attachments as artifacts of the
+// run's session
+func (h *ApprovalHandlers) publishAttachments(ctx context.Context, req *api.CreateApprovalJSONRequestBody) ([]string, error) {
+	if req.Attachments == nil || len(*req.Attachments) == 0 {
+		return nil, nil
+	}
+	if h.artifacts == nil {
+		return nil, fmt.Errorf("%w: attachments aren't supported", approval.ErrInvalidAttachment)
+	}
+	sess, err := h.store.GetSessionByRunID(ctx, req.RunId)
+	if err != nil || sess == nil {
+		return nil, fmt.Errorf("%w: no session has run_id %s", approval.ErrInvalidAttachment, req.RunId)
+	}
+	uploads := make([]approval.Attachment, len(*req.Attachments))
+	for i, upload := range *req.Attachments {
+		uploads[i] = approval.Attachment{Name: upload.Name, ContentType: upload.ContentType, Data: upload.Data}
+	}
+	return approval.PublishAttachments(ctx, h.artifacts, sess.ID, uploads)
+}
+
+// withAttachments adds what each approval's request carries, with signed
+// links. Attachments whose retention ended are left out.
+func (h *ApprovalHandlers) withAttachments(ctx context.Context, approvals []api.Approval, stored []store.Approval) {
+	if h.artifacts == nil {
+		return
+	}
+	for i, a := range stored {
+		if len(a.Attachments) == 0 {
+			continue
+		}
+		attachments := []api.ApprovalAttachment{}
+		for _, id := range a.Attachments {
+			artifact, err := h.artifacts.Get(ctx, id)
+			if err != nil {
+				if !errors.Is(err, artifacts.ErrNotFound) {
+					slog.Warn("failed to get approval attachment", "approval_id", a.ID, "artifact_id", id, "error", err)
+				}
+				continue
+			}
+			link, _, err := artifactLink(h.artifacts, artifact.ID)
+			if err != nil {
+				slog.Warn("failed to sign approval attachment link", "approval_id", a.ID, "artifact_id", id, "error", err)
+				continue
+			}
+			attachments = append(attachments, api.ApprovalAttachment{
+				Id:          artifact.ID,
+				Name:        artifact.Name,
+				ContentType: artifact.ContentType,
+				Size:        artifact.Size,
+				DownloadUrl: link,
+			})
+		}
+		approvals[i].Attachments = &attachments
+	}
+}
+
 // ListApprovals retrieves approval requests with optional filtering
 func (h *ApprovalHandlers) ListApprovals(ctx context.Context, req api.ListApprovalsRequestObject) (api.ListApprovalsResponseObject, error) {
 	var approvals []*store.Approval
@@ -108,6 +217,7 @@ func (h *ApprovalHandlers) ListApprovals(ctx context.Context, req api.ListApprov
 	resp := api.ApprovalsResponse{
 		Data: h.mapper.ApprovalsToAPI(approvalsSlice),
 	}
+	h.withAttachments(ctx, resp.Data, approvalsSlice)
 	return api.ListApprovals200JSONResponse(resp), nil
 }
 
@@ -140,16 +250,62 @@ func (h *ApprovalHandlers) GetApproval(ctx context.Context, req api.GetApprovalR
 		}, nil
 	}
 
+	data := []api.Approval{h.mapper.ApprovalToAPI(*approval)}
+	h.withAttachments(ctx, data, []store.Approval{*approval})
 	resp := api.ApprovalResponse{
-		Data: h.mapper.ApprovalToAPI(*approval),
+		Data: data[0],
 	}
 	return api.GetApproval200JSONResponse(resp), nil
 }
 
+// approvalWorkingDir returns the working directory of the approval's
+// session, or "" if it can't be found
+func (h *ApprovalHandlers) approvalWorkingDir(ctx context.Context, id string) string {
+	if h.store == nil {
+		return ""
+	}
+	approval, err := h.approvalManager.GetApproval(ctx, id)
+	if err != nil {
+		return ""
+	}
+	session, err := h.store.GetSession(ctx, approval.SessionID)
+	if err != nil {
+		return ""
+	}
+	return session.WorkingDir
+}
+
 // DecideApproval approves or denies an approval request
 func (h *ApprovalHandlers) DecideApproval(ctx context.Context, req api.DecideApprovalRequestObject) (api.DecideApprovalResponseObject, error) {
+	comment := ""
+	if req.Body.Comment != nil {
+		comment = *req.Body.Comment
+	}
+
+	// A canned response's text leads the comment
+	cannedID := ""
+	if req.Body.CannedResponseId != nil {
+		cannedID = *req.Body.CannedResponseId
+	}
+	if cannedID != "" {
+		var canned *store.CannedResponse
+		var err error
+		if h.canned != nil {
+			canned, err = h.canned.GetCannedResponse(ctx, cannedID)
+		}
+		if h.canned == nil || err != nil {
+			return api.DecideApproval400JSONResponse{
+				Error: api.ErrorDetail{
+					Code:    "HLD-3001",
+					Message: "unknown canned response",
+				},
+			}, nil
+		}
+		comment = strings.TrimSpace(canned.Text + "\n\n" + comment)
+	}
+
 	// Validate comment requirement for deny
-	if req.Body.Decision == api.Deny && (req.Body.Comment == nil || *req.Body.Comment == "") {
+	if req.Body.Decision == api.Deny && comment == "" {
 		return api.DecideApproval400JSONResponse{
 			Error: api.ErrorDetail{
 				Code:    "HLD-3001",
@@ -158,9 +314,44 @@ func (h *ApprovalHandlers) DecideApproval(ctx context.Context, req api.DecideApp
 		}, nil
 	}
 
-	comment := ""
-	if req.Body.Comment != nil {
-		comment = *req.Body.Comment
+	// High-risk approvals need a justification
+	var highRisk *justified
+	if req.Body.Decision == api.Approve {
+		var err error
+		if highRisk, err = h.justification.check(ctx, string(req.Id), comment); err != nil {
+			return api.DecideApproval400JSONResponse{
+				Error: api.ErrorDetail{
+					Code:    "HLD-3001",
+					Message: err.Error(),
+				},
+			}, nil
+		}
+	}
+
+	// Constraints limit what an approval allows
+	var constraints *store.ApprovalConstraints
+	if c := req.Body.Constraints; c != nil {
+		var workingDir string
+		if c.Files != nil {
+			workingDir = h.approvalWorkingDir(ctx, string(req.Id))
+		}
+		var err error
+		if constraints, err = h.mapper.ConstraintsFromAPI(c, time.Now(), workingDir); err != nil {
+			return api.DecideApproval400JSONResponse{
+				Error: api.ErrorDetail{
+					Code:    "HLD-3001",
+					Message: err.Error(),
+				},
+			}, nil
+		}
+		if constraints != nil && (req.Body.Decision != api.Approve || h.store == nil) {
+			return api.DecideApproval400JSONResponse{
+				Error: api.ErrorDetail{
+					Code:    "HLD-3001",
+					Message: "constraints can only be given when approving",
+				},
+			}, nil
+		}
 	}
 
 	// Extract image paths (optional)
@@ -172,7 +363,12 @@ func (h *ApprovalHandlers) DecideApproval(ctx context.Context, req api.DecideApp
 	var err error
 	switch req.Body.Decision {
 	case api.Approve:
-		err = h.approvalManager.ApproveToolCall(ctx, string(req.Id), comment, imagePaths)
+		if constraints != nil {
+			err = h.store.SetApprovalConstraints(ctx, string(req.Id), constraints)
+		}
+		if err == nil {
+			err = h.approvalManager.ApproveToolCall(ctx, string(req.Id), comment, imagePaths)
+		}
 	case api.Deny:
 		err = h.approvalManager.DenyToolCall(ctx, string(req.Id), comment, imagePaths)
 	default:
@@ -203,6 +399,14 @@ func (h *ApprovalHandlers) DecideApproval(ctx context.Context, req api.DecideApp
 				},
 			}, nil
 		}
+		if errors.Is(err, moderation.ErrBlocked) {
+			return api.DecideApproval400JSONResponse{
+				Error: api.ErrorDetail{
+					Code:    "HLD-3003",
+					Message: err.Error(),
+				},
+			}, nil
+		}
 		slog.Error("Failed to decide approval",
 			"error", fmt.Sprintf("%v", err),
 			"approval_id", req.Id,
@@ -219,6 +423,13 @@ func (h *ApprovalHandlers) DecideApproval(ctx context.Context, req api.DecideApp
 		}, nil
 	}
 
+	h.justification.record(ctx, highRisk, comment)
+	if cannedID != "" {
+		if err := h.canned.RecordCannedResponseUse(ctx, cannedID); err != nil {
+			slog.Warn("failed to record canned response use", "canned_response_id", cannedID, "error", err)
+		}
+	}
+
 	resp := api.DecideApprovalResponse{}
 	resp.Data.Success = true
 	return api.DecideApproval200JSONResponse(resp), nil
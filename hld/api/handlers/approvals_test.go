@@ -2,6 +2,7 @@ package handlers_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http/httptest"
@@ -11,9 +12,12 @@ import (
 	"github.com/humanlayer/humanlayer/hld/api"
 	"github.com/humanlayer/humanlayer/hld/api/handlers"
 	"github.com/humanlayer/humanlayer/hld/approval"
+	"github.com/humanlayer/humanlayer/hld/artifacts"
+	"github.com/humanlayer/humanlayer/hld/config"
 	"github.com/humanlayer/humanlayer/hld/session"
 	"github.com/humanlayer/humanlayer/hld/store"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
 
@@ -482,3 +486,133 @@ func TestApprovalHandlers_HTTPSpecificBehavior(t *testing.T) {
 func timePtr(t time.Time) *time.Time {
 	return &t
 }
+
+func TestApprovalHandlers_Attachments(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	require.NoError(t, s.CreateSession(ctx, &store.Session{ID: "sess-1", RunID: "run-1", Status: store.SessionStatusRunning, CreatedAt: time.Now()}))
+	service := artifacts.New(s, artifacts.NewDirBlobs(t.TempDir()), nil, config.ArtifactsConfig{}, []byte("0123456789abcdef0123456789abcdef"))
+
+	h := handlers.NewApprovalHandlers(approval.NewManager(s, nil), nil)
+	h.SetArtifacts(s, service)
+	router := setupTestRouter(t, nil, h, nil)
+
+	screen := []byte("\x89PNG\r\n\x1a\nscreen")
+	attachments := []api.ApprovalAttachmentUpload{
+		{Name: "screen.png", ContentType: "image/png", Data: screen},
+		{Name: "terminal.txt", ContentType: "text/plain", Data: []byte("$ make\nok\n")},
+	}
+	w := makeRequest(t, router, "POST", "/api/v1/approvals", api.CreateApprovalRequest{
+		RunId:       "run-1",
+		ToolName:    "Bash",
+		ToolInput:   map[string]interface{}{"command": "git push"},
+		Attachments: &attachments,
+	})
+	var created api.CreateApprovalResponse
+	assertJSONResponse(t, w, 201, &created)
+
+	w = makeRequest(t, router, "GET", "/api/v1/approvals/"+created.Data.ApprovalId, nil)
+	var got api.ApprovalResponse
+	assertJSONResponse(t, w, 200, &got)
+	require.NotNil(t, got.Data.Attachments)
+	require.Len(t, *got.Data.Attachments, 2)
+	first := (*got.Data.Attachments)[0]
+	assert.Equal(t, "screen.png", first.Name)
+	assert.Equal(t, "image/png", first.ContentType)
+	assert.Equal(t, int64(len(screen)), first.Size)
+	assert.Contains(t, first.DownloadUrl, "/api/v1/artifacts/"+first.Id+"/download?")
+
+	// Listing for the session shows them too
+	w = makeRequest(t, router, "GET", "/api/v1/approvals?sessionId=sess-1", nil)
+	var list api.ApprovalsResponse
+	assertJSONResponse(t, w, 200, &list)
+	require.Len(t, list.Data, 1)
+	require.NotNil(t, list.Data[0].Attachments)
+	assert.Len(t, *list.Data[0].Attachments, 2)
+
+	// Types an approver can't view are refused, and nothing is stored
+	bad := []api.ApprovalAttachmentUpload{{Name: "tool.bin", ContentType: "application/octet-stream", Data: []byte{1}}}
+	w = makeRequest(t, router, "POST", "/api/v1/approvals", api.CreateApprovalRequest{
+		RunId: "run-1", ToolName: "Bash", ToolInput: map[string]interface{}{}, Attachments: &bad,
+	})
+	assert.Equal(t, 400, w.Code)
+	stored, err := s.ListSessionArtifacts(ctx, "sess-1")
+	require.NoError(t, err)
+	assert.Len(t, stored, 2)
+}
+
+func TestApprovalHandlers_DecideWithCannedResponse(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	require.NoError(t, s.CreateSession(ctx, &store.Session{ID: "sess-1", RunID: "run-1", Status: store.SessionStatusRunning, CreatedAt: time.Now()}))
+	require.NoError(t, s.CreateCannedResponse(ctx, &store.CannedResponse{ID: "tests-first", Title: "Tests first", Text: "Add tests before changing this."}))
+	manager := approval.NewManager(s, nil)
+	id, err := manager.CreateApproval(ctx, "run-1", "Edit", json.RawMessage(`{"file_path":"billing.go"}`))
+	require.NoError(t, err)
+
+	h := handlers.NewApprovalHandlers(manager, nil)
+	h.SetCannedResponses(s)
+	router := setupTestRouter(t, nil, h, nil)
+
+	w := makeRequest(t, router, "POST", "/api/v1/approvals/"+id+"/decide", api.DecideApprovalRequest{
+		Decision: api.Deny, CannedResponseId: stringPtr("missing"),
+	})
+	assertErrorResponse(t, w, "HLD-3001", "unknown canned response")
+
+	w = makeRequest(t, router, "POST", "/api/v1/approvals/"+id+"/decide", api.DecideApprovalRequest{
+		Decision: api.Deny, CannedResponseId: stringPtr("tests-first"), Comment: stringPtr("See billing_test.go."),
+	})
+	var resp api.DecideApprovalResponse
+	assertJSONResponse(t, w, 200, &resp)
+
+	decided, err := s.GetApproval(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "Add tests before changing this.\n\nSee billing_test.go.", decided.Comment)
+	canned, err := s.GetCannedResponse(ctx, "tests-first")
+	require.NoError(t, err)
+	assert.Equal(t, 1, canned.UseCount)
+	assert.NotNil(t, canned.LastUsedAt)
+}
+
+func TestApprovalHandlers_DecideWithConstraints(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	require.NoError(t, s.CreateSession(ctx, &store.Session{ID: "sess-1", RunID: "run-1", Status: store.SessionStatusRunning, WorkingDir: "/work", CreatedAt: time.Now()}))
+	manager := approval.NewManager(s, nil)
+	id, err := manager.CreateApproval(ctx, "run-1", "Edit", json.RawMessage(`{"file_path":"/work/billing.go"}`))
+	require.NoError(t, err)
+
+	h := handlers.NewApprovalHandlers(manager, nil)
+	h.SetArtifacts(s, nil)
+	router := setupTestRouter(t, nil, h, nil)
+
+	validFor, zero := 600, 0
+	files := []string{"billing.go", "billing_test.go"}
+	w := makeRequest(t, router, "POST", "/api/v1/approvals/"+id+"/decide", api.DecideApprovalRequest{
+		Decision: api.Deny, Comment: stringPtr("no"), Constraints: &api.ApprovalConstraints{Files: &files},
+	})
+	assertErrorResponse(t, w, "HLD-3001", "constraints can only be given when approving")
+	w = makeRequest(t, router, "POST", "/api/v1/approvals/"+id+"/decide", api.DecideApprovalRequest{
+		Decision: api.Approve, Constraints: &api.ApprovalConstraints{ValidForSeconds: &zero},
+	})
+	assertErrorResponse(t, w, "HLD-3001", "valid_for_seconds must be positive")
+	past := time.Now().Add(-time.Minute)
+	w = makeRequest(t, router, "POST", "/api/v1/approvals/"+id+"/decide", api.DecideApprovalRequest{
+		Decision: api.Approve, Constraints: &api.ApprovalConstraints{ExpiresAt: &past},
+	})
+	assertErrorResponse(t, w, "HLD-3001", "expires_at must be in the future")
+
+	before := time.Now()
+	w = makeRequest(t, router, "POST", "/api/v1/approvals/"+id+"/decide", api.DecideApprovalRequest{
+		Decision: api.Approve, Constraints: &api.ApprovalConstraints{ValidForSeconds: &validFor, Files: &files},
+	})
+	var resp api.DecideApprovalResponse
+	assertJSONResponse(t, w, 200, &resp)
+
+	decided, err := s.GetApproval(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, store.ApprovalStatusLocalApproved, decided.Status)
+	require.NotNil(t, decided.Constraints)
+	assert.Equal(t, []string{"/work/billing.go", "/work/billing_test.go"}, decided.Constraints.Files, "relative files are resolved against the session's working dir")
+	assert.WithinDuration(t, before.Add(10*time.Minute), *decided.Constraints.ExpiresAt, 5*time.Second)
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/artifacts"
+	"github.com/humanlayer/humanlayer/hld/diskguard"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// artifactsPath is where the artifact routes are mounted, for the download
+// links handed out
+const artifactsPath = "/api/v1/artifacts/"
+
+// ArtifactsHandler publishes and serves the artifacts sessions produce
+type ArtifactsHandler struct {
+	store     store.ConversationStore
+	artifacts store.ArtifactStore
+	service   *artifacts.Service
+}
+
+// NewArtifactsHandler creates a new artifacts handler
+func NewArtifactsHandler(conversationStore store.ConversationStore, artifactStore store.ArtifactStore, service *artifacts.Service) *ArtifactsHandler {
+	return &ArtifactsHandler{store: conversationStore, artifacts: artifactStore, service: service}
+}
+
+// Artifact is an artifact with a signed link to download it
+type Artifact struct {
+	*store.Artifact
+	DownloadURL string `json:"download_url"`
+	// LinkExpiresAt is when DownloadURL stops working; GET
+	// /artifacts/:id/link signs a new one
+	LinkExpiresAt time.Time `json:"link_expires_at"`
+}
+
+// artifactLink signs a download link for artifact id, returning it with
+// when it expires
+func artifactLink(service *artifacts.Service, id string) (string, time.Time, error) {
+	expires := time.Now().Add(service.LinkTTL()).Truncate(time.Second)
+	signature, err := service.Sign(id, expires)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	query := url.Values{
+		"expires":   {strconv.FormatInt(expires.Unix(), 10)},
+		"signature": {signature},
+	}
+	return artifactsPath + id + "/download?" + query.Encode(), expires, nil
+}
+
+func (h *ArtifactsHandler) withLink(artifact *store.Artifact) (Artifact, error) {
+	link, expires, err := artifactLink(h.service, artifact.ID)
+	if err != nil {
+		return Artifact{}, err
+	}
+	return Artifact{Artifact: artifact, DownloadURL: link, LinkExpiresAt: expires}, nil
+}
+
+// respondWithLink responds with artifact and a signed link to it
+func (h *ArtifactsHandler) respondWithLink(c *gin.Context, status int, artifact *store.Artifact) {
+	response, err := h.withLink(artifact)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(status, response)
+}
+
+// HandlePublish stores the request body as an artifact of the session,
+// named by the name query parameter. The kind parameter, such as binary,
+// coverage or screenshot, is free-form; the content type is the request's,
+// or detected from the content.
+func (h *ArtifactsHandler) HandlePublish(c *gin.Context) {
+	session, err := h.store.GetSession(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+	if c.Request.ContentLength > h.service.MaxSize() {
+		h.respondError(c, artifacts.ErrTooLarge)
+		return
+	}
+	contentType := c.GetHeader("Content-Type")
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType == "application/x-www-form-urlencoded" {
+		// What curl --data-binary sends unless told otherwise
+		contentType = ""
+	}
+	artifact, err := h.service.Publish(c.Request.Context(), session.ID, c.Query("name"), c.Query("kind"), contentType, c.Request.Body)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	h.respondWithLink(c, http.StatusCreated, artifact)
+}
+
+// HandleListSessionArtifacts lists the session's artifacts, oldest first,
+// each with a fresh download link
+func (h *ArtifactsHandler) HandleListSessionArtifacts(c *gin.Context) {
+	ctx := c.Request.Context()
+	if _, err := h.store.GetSession(ctx, c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+	list, err := h.artifacts.ListSessionArtifacts(ctx, c.Param("id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	data := make([]Artifact, len(list))
+	for i, artifact := range list {
+		if data[i], err = h.withLink(artifact); err != nil {
+			h.respondError(c, err)
+			return
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+// HandleGetLink returns an artifact with a new download link
+func (h *ArtifactsHandler) HandleGetLink(c *gin.Context) {
+	artifact, err := h.service.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	h.respondWithLink(c, http.StatusOK, artifact)
+}
+
+// HandleDownload serves an artifact's content to a request carrying a
+// valid, unexpired signed link
+func (h *ArtifactsHandler) HandleDownload(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.service.Verify(id, c.Query("expires"), c.Query("signature")); err != nil {
+		if errors.Is(err, artifacts.ErrLinkInvalid) || errors.Is(err, artifacts.ErrLinkExpired) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		} else {
+			h.respondError(c, err)
+		}
+		return
+	}
+	artifact, content, err := h.service.Open(c.Request.Context(), id)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	defer func() { _ = content.Close() }()
+
+	// Downloaded rather than rendered, so an HTML report can't run script
+	// against the daemon's origin
+	header := c.Writer.Header()
+	header.Set("Content-Type", artifact.ContentType)
+	header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": artifact.Name}))
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("Content-Security-Policy", "sandbox")
+	header.Set("ETag", fmt.Sprintf("%q", artifact.SHA256))
+	http.ServeContent(c.Writer, c.Request, artifact.Name, artifact.CreatedAt, content)
+}
+
+// HandleDelete removes an artifact before its retention ends
+func (h *ArtifactsHandler) HandleDelete(c *gin.Context) {
+	if err := h.service.Delete(c.Request.Context(), c.Param("id")); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *ArtifactsHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, artifacts.ErrInvalidName):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required and can't contain a path separator"})
+	case errors.Is(err, artifacts.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Artifact not found"})
+	case errors.Is(err, artifacts.ErrTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Artifact too large: the limit is %d bytes", h.service.MaxSize())})
+	case errors.Is(err, diskguard.ErrLowDiskSpace):
+		c.JSON(http.StatusInsufficientStorage, gin.H{"error": err.Error()})
+	default:
+		slog.Error("artifact operation failed", "session_id", c.Param("id"), "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Artifact operation failed"})
+	}
+}
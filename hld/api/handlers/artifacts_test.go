@@ -0,0 +1,79 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/api/handlers"
+	"github.com/humanlayer/humanlayer/hld/artifacts"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArtifacts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := store.NewInMemoryStore()
+	require.NoError(t, s.CreateSession(context.Background(), &store.Session{ID: "sess-1", RunID: "run-1", CreatedAt: time.Now()}))
+	service := artifacts.New(s, artifacts.NewDirBlobs(t.TempDir()), nil, config.ArtifactsConfig{MaxSize: 64}, []byte("0123456789abcdef0123456789abcdef"))
+	h := handlers.NewArtifactsHandler(s, s, service)
+	router := gin.New()
+	router.POST("/api/v1/sessions/:id/artifacts", h.HandlePublish)
+	router.GET("/api/v1/sessions/:id/artifacts", h.HandleListSessionArtifacts)
+	router.GET("/api/v1/artifacts/:id/link", h.HandleGetLink)
+	router.GET("/api/v1/artifacts/:id/download", h.HandleDownload)
+	router.DELETE("/api/v1/artifacts/:id", h.HandleDelete)
+	do := func(method, path, contentType, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("POST", "/api/v1/sessions/sess-1/artifacts?name=coverage.txt&kind=coverage", "text/plain", "total: 87%\n")
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var published handlers.Artifact
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&published))
+	assert.Equal(t, "coverage", published.Kind)
+	assert.Equal(t, int64(11), published.Size)
+	assert.True(t, strings.HasPrefix(published.DownloadURL, "/api/v1/artifacts/"+published.ID+"/download?"))
+
+	w = do("GET", "/api/v1/sessions/sess-1/artifacts", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Data []handlers.Artifact `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&list))
+	require.Len(t, list.Data, 1)
+
+	w = do("GET", list.Data[0].DownloadURL, "", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "total: 87%\n", w.Body.String())
+	assert.Equal(t, "text/plain", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename=coverage.txt`, w.Header().Get("Content-Disposition"))
+
+	tampered := strings.Replace(published.DownloadURL, "signature=", "signature=0", 1)
+	assert.Equal(t, http.StatusForbidden, do("GET", tampered, "", "").Code)
+	assert.Equal(t, http.StatusForbidden, do("GET", "/api/v1/artifacts/"+published.ID+"/download", "", "").Code)
+
+	w = do("GET", "/api/v1/artifacts/"+published.ID+"/link", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/api/v1/sessions/sess-1/artifacts?name=a/b", "", "x").Code)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, do("POST", "/api/v1/sessions/sess-1/artifacts?name=big", "", strings.Repeat("x", 65)).Code)
+	assert.Equal(t, http.StatusNotFound, do("POST", "/api/v1/sessions/sess-2/artifacts?name=x", "", "x").Code)
+
+	assert.Equal(t, http.StatusNoContent, do("DELETE", "/api/v1/artifacts/"+published.ID, "", "").Code)
+	assert.Equal(t, http.StatusNotFound, do("GET", list.Data[0].DownloadURL, "", "").Code)
+	assert.Equal(t, http.StatusNotFound, do("DELETE", "/api/v1/artifacts/"+published.ID, "", "").Code)
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/approval"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// BudgetHandler reports a session's resource limits and what it has used
+type BudgetHandler struct {
+	store store.ConversationStore
+}
+
+// NewBudgetHandler creates a new budget handler
+func NewBudgetHandler(conversationStore store.ConversationStore) *BudgetHandler {
+	return &BudgetHandler{store: conversationStore}
+}
+
+// BudgetResponse represents a session's budget and current usage
+type BudgetResponse struct {
+	Budget   approval.Budget      `json:"budget"`
+	Usage    approval.BudgetUsage `json:"usage"`
+	Exceeded bool                 `json:"exceeded"`
+	Reason   string               `json:"reason,omitempty"`
+}
+
+// HandleGetBudget returns the budget and usage for a session
+func (h *BudgetHandler) HandleGetBudget(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	session, err := h.store.GetSession(c.Request.Context(), sessionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	usage, err := approval.MeasureBudgetUsage(c.Request.Context(), h.store, session)
+	if err != nil {
+		slog.Error("failed to measure session budget usage", "session_id", sessionID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to measure budget usage"})
+		return
+	}
+
+	budget := approval.ParseBudget(session.Budget)
+	reason, exceeded := budget.Exceeded(usage)
+	c.JSON(http.StatusOK, BudgetResponse{
+		Budget:   budget,
+		Usage:    usage,
+		Exceeded: exceeded,
+		Reason:   reason,
+	})
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// CannedResponsesHandler manages the canned responses approvers pick from
+// when deciding
+type CannedResponsesHandler struct {
+	store store.CannedResponseStore
+}
+
+// NewCannedResponsesHandler creates a new canned responses handler
+func NewCannedResponsesHandler(responses store.CannedResponseStore) *CannedResponsesHandler {
+	return &CannedResponsesHandler{store: responses}
+}
+
+type cannedResponseRequest struct {
+	Team  string `json:"team"`
+	Title string `json:"title"`
+	Text  string `json:"text"`
+}
+
+func (r *cannedResponseRequest) valid() bool {
+	r.Team = strings.TrimSpace(r.Team)
+	r.Title = strings.TrimSpace(r.Title)
+	r.Text = strings.TrimSpace(r.Text)
+	return r.Title != "" && r.Text != ""
+}
+
+// HandleList lists canned responses, most used first, with their usage. With
+// ?team=, only that team's responses and shared ones are listed.
+func (h *CannedResponsesHandler) HandleList(c *gin.Context) {
+	responses, err := h.store.ListCannedResponses(c.Request.Context())
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	if team, ok := c.GetQuery("team"); ok {
+		filtered := []*store.CannedResponse{}
+		for _, response := range responses {
+			if response.Team == "" || response.Team == team {
+				filtered = append(filtered, response)
+			}
+		}
+		responses = filtered
+	}
+	c.JSON(http.StatusOK, gin.H{"data": responses})
+}
+
+// HandleGet returns a canned response
+func (h *CannedResponsesHandler) HandleGet(c *gin.Context) {
+	response, err := h.store.GetCannedResponse(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// HandleCreate adds a canned response
+func (h *CannedResponsesHandler) HandleCreate(c *gin.Context) {
+	var req cannedResponseRequest
+	if err := c.ShouldBindJSON(&req); err != nil || !req.valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "title and text are required"})
+		return
+	}
+	response := &store.CannedResponse{ID: uuid.New().String(), Team: req.Team, Title: req.Title, Text: req.Text}
+	if err := h.store.CreateCannedResponse(c.Request.Context(), response); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, response)
+}
+
+// HandleUpdate replaces a canned response's team, title and text, keeping
+// its usage
+func (h *CannedResponsesHandler) HandleUpdate(c *gin.Context) {
+	var req cannedResponseRequest
+	if err := c.ShouldBindJSON(&req); err != nil || !req.valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "title and text are required"})
+		return
+	}
+	response := &store.CannedResponse{ID: c.Param("id"), Team: req.Team, Title: req.Title, Text: req.Text}
+	if err := h.store.UpdateCannedResponse(c.Request.Context(), response); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// HandleDelete removes a canned response
+func (h *CannedResponsesHandler) HandleDelete(c *gin.Context) {
+	if err := h.store.DeleteCannedResponse(c.Request.Context(), c.Param("id")); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *CannedResponsesHandler) respondError(c *gin.Context, err error) {
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Canned response not found"})
+		return
+	}
+	slog.Error("canned response operation failed", "error", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Canned response operation failed"})
+}
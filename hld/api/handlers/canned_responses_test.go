@@ -0,0 +1,71 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/api/handlers"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCannedResponses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := store.NewInMemoryStore()
+	h := handlers.NewCannedResponsesHandler(s)
+	router := gin.New()
+	router.GET("/api/v1/canned-responses", h.HandleList)
+	router.POST("/api/v1/canned-responses", h.HandleCreate)
+	router.GET("/api/v1/canned-responses/:id", h.HandleGet)
+	router.PUT("/api/v1/canned-responses/:id", h.HandleUpdate)
+	router.DELETE("/api/v1/canned-responses/:id", h.HandleDelete)
+
+	create := func(team, title, text string) store.CannedResponse {
+		t.Helper()
+		w := makeRequest(t, router, "POST", "/api/v1/canned-responses", map[string]string{"team": team, "title": title, "text": text})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var response store.CannedResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		return response
+	}
+	shared := create("", "Tests first", "Needs tests first.")
+	web := create("web", "Wrong directory", "Wrong directory, use apps/web.")
+	create("payments", "Ask payments", "Ask #payments before touching billing.")
+
+	w := makeRequest(t, router, "POST", "/api/v1/canned-responses", map[string]string{"title": "No text"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	list := func(path string) []store.CannedResponse {
+		t.Helper()
+		w := makeRequest(t, router, "GET", path, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data []store.CannedResponse `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		return resp.Data
+	}
+	assert.Len(t, list("/api/v1/canned-responses"), 3)
+	webList := list("/api/v1/canned-responses?team=web")
+	require.Len(t, webList, 2, "a team sees its own and shared responses")
+
+	// Usage orders the list
+	require.NoError(t, s.RecordCannedResponseUse(t.Context(), web.ID))
+	webList = list("/api/v1/canned-responses?team=web")
+	assert.Equal(t, web.ID, webList[0].ID)
+	assert.Equal(t, 1, webList[0].UseCount)
+
+	w = makeRequest(t, router, "PUT", "/api/v1/canned-responses/"+shared.ID, map[string]string{"title": "Tests first", "text": "Add tests first."})
+	require.Equal(t, http.StatusOK, w.Code)
+	var updated store.CannedResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&updated))
+	assert.Equal(t, "Add tests first.", updated.Text)
+
+	w = makeRequest(t, router, "DELETE", "/api/v1/canned-responses/"+shared.ID, nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = makeRequest(t, router, "GET", "/api/v1/canned-responses/"+shared.ID, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
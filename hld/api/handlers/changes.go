@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/bus"
+)
+
+const (
+	defaultChangesTimeout = 30 * time.Second
+	maxChangesTimeout     = 2 * time.Minute
+)
+
+// ChangesHandler serves the long-poll alternative to the event stream
+type ChangesHandler struct {
+	changes *bus.ChangeLog
+}
+
+func NewChangesHandler(changes *bus.ChangeLog) *ChangesHandler {
+	return &ChangesHandler{changes: changes}
+}
+
+// parseChangesTimeout reads ?timeout= as a duration ("30s") or seconds ("30")
+func parseChangesTimeout(value string) (time.Duration, error) {
+	if value == "" {
+		return defaultChangesTimeout, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		seconds, convErr := strconv.Atoi(value)
+		if convErr != nil {
+			return 0, err
+		}
+		timeout = time.Duration(seconds) * time.Second
+	}
+	if timeout < 0 {
+		return 0, errors.New("timeout can't be negative")
+	}
+	return min(timeout, maxChangesTimeout), nil
+}
+
+// HandleGetChanges handles GET /changes?since=<cursor>&timeout=30s. It
+// returns as soon as an event after since matches the filters, or with no
+// changes once the timeout passes. Without since, it returns the current
+// cursor straight away.
+func (h *ChangesHandler) HandleGetChanges(c *gin.Context) {
+	timeout, err := parseChangesTimeout(c.Query("timeout"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid timeout: " + err.Error()})
+		return
+	}
+
+	since := c.Query("since")
+	if since == "" {
+		c.JSON(http.StatusOK, bus.ChangesResult{Changes: []bus.Change{}, Cursor: h.changes.Cursor()})
+		return
+	}
+
+	filter := bus.EventFilter{
+		Types:     parseEventTypes(c.QueryArray("eventTypes")),
+		SessionID: c.Query("sessionId"),
+		RunID:     c.Query("runId"),
+	}
+	result, err := h.changes.Wait(c.Request.Context(), since, filter, timeout)
+	switch {
+	case errors.Is(err, bus.ErrInvalidCursor):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		// The client went away
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, result)
+}
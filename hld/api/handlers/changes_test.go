@@ -0,0 +1,49 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/api/handlers"
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangesHandler_HandleGetChanges(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	changes := bus.NewChangeLog(10)
+	router := gin.New()
+	router.GET("/api/v1/changes", handlers.NewChangesHandler(changes).HandleGetChanges)
+
+	get := func(query string) (int, bus.ChangesResult) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/changes?"+query, nil))
+		var result bus.ChangesResult
+		_ = json.Unmarshal(w.Body.Bytes(), &result)
+		return w.Code, result
+	}
+
+	code, start := get("")
+	require.Equal(t, http.StatusOK, code)
+	assert.Empty(t, start.Changes)
+
+	changes.Record(bus.Event{Type: bus.EventNewApproval, Data: map[string]interface{}{"session_id": "sess-1"}})
+	code, result := get("since=" + url.QueryEscape(start.Cursor) + "&sessionId=sess-1&timeout=1s")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, result.Changes, 1)
+	assert.Equal(t, bus.EventNewApproval, result.Changes[0].Type)
+
+	code, result = get("since=" + url.QueryEscape(result.Cursor) + "&timeout=10ms")
+	require.Equal(t, http.StatusOK, code)
+	assert.Empty(t, result.Changes)
+
+	code, _ = get("since=bogus")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = get("since=" + url.QueryEscape(start.Cursor) + "&timeout=soon")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// ConstraintViolationsHandler serves the tool calls that broke the
+// constraints they were approved with
+type ConstraintViolationsHandler struct {
+	store store.ConstraintViolationStore
+}
+
+// NewConstraintViolationsHandler creates a new constraint violations handler
+func NewConstraintViolationsHandler(violations store.ConstraintViolationStore) *ConstraintViolationsHandler {
+	return &ConstraintViolationsHandler{store: violations}
+}
+
+// HandleList returns every violation, or a session's with the session_id
+// query parameter, oldest first
+func (h *ConstraintViolationsHandler) HandleList(c *gin.Context) {
+	h.list(c, c.Query("session_id"))
+}
+
+// HandleListSession returns a session's violations, oldest first
+func (h *ConstraintViolationsHandler) HandleListSession(c *gin.Context) {
+	h.list(c, c.Param("id"))
+}
+
+func (h *ConstraintViolationsHandler) list(c *gin.Context, sessionID string) {
+	violations, err := h.store.ListConstraintViolations(c.Request.Context(), sessionID)
+	if err != nil {
+		slog.Error("failed to list constraint violations", "session_id", sessionID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list constraint violations"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": violations})
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/contextpack"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// maxContextPackFiles caps the files a request may ask for
+const maxContextPackFiles = 50
+
+// ContextPackHandler builds context packs to attach to session launches
+type ContextPackHandler struct {
+	store   store.ConversationStore
+	packs   store.ContextPackStore
+	builder *contextpack.Builder
+}
+
+// NewContextPackHandler creates a new context pack handler
+func NewContextPackHandler(conversationStore store.ConversationStore, packs store.ContextPackStore, builder *contextpack.Builder) *ContextPackHandler {
+	return &ContextPackHandler{store: conversationStore, packs: packs, builder: builder}
+}
+
+// HandleBuildContextPack assembles and stores a pack for a query and working
+// directory. Pass its ID as context_pack_id when creating the session.
+func (h *ContextPackHandler) HandleBuildContextPack(c *gin.Context) {
+	var req contextpack.Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	if strings.TrimSpace(req.Query) == "" || req.WorkingDir == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "query and working_dir are required"})
+		return
+	}
+	if req.MaxFiles < 0 || req.MaxFiles > maxContextPackFiles {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "max_files must be between 1 and 50"})
+		return
+	}
+	for _, source := range req.Skip {
+		if source != contextpack.SourceFiles && source != contextpack.SourceSessions && source != contextpack.SourceIssues {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "skip entries must be files, sessions or issues"})
+			return
+		}
+	}
+	req.WorkingDir = expandTilde(req.WorkingDir)
+	if info, err := os.Stat(req.WorkingDir); err != nil || !info.IsDir() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "working_dir is not a directory"})
+		return
+	}
+
+	pack, err := h.builder.Build(c.Request.Context(), req)
+	if err != nil {
+		slog.Error("failed to build context pack", "working_dir", req.WorkingDir, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build context pack"})
+		return
+	}
+	c.JSON(http.StatusCreated, pack)
+}
+
+// HandleGetContextPack returns a stored pack with its assembly steps
+func (h *ContextPackHandler) HandleGetContextPack(c *gin.Context) {
+	h.respondPack(c, c.Param("id"))
+}
+
+// HandleGetSessionContextPack returns the pack a session was launched with
+func (h *ContextPackHandler) HandleGetSessionContextPack(c *gin.Context) {
+	sess, err := h.store.GetSession(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return
+		}
+		slog.Error("failed to get session", "session_id", c.Param("id"), "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get session"})
+		return
+	}
+	if sess.ContextPackID == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session has no context pack"})
+		return
+	}
+	h.respondPack(c, sess.ContextPackID)
+}
+
+func (h *ContextPackHandler) respondPack(c *gin.Context, id string) {
+	pack, err := contextpack.Load(c.Request.Context(), h.packs, id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Context pack not found"})
+			return
+		}
+		slog.Error("failed to load context pack", "pack_id", id, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get context pack"})
+		return
+	}
+	c.JSON(http.StatusOK, pack)
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/credentials"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// CredentialsHandler manages the tokens git uses for authenticated remotes.
+// Tokens go in and never come back out.
+type CredentialsHandler struct {
+	manager *credentials.Manager
+}
+
+// NewCredentialsHandler creates a new credentials handler
+func NewCredentialsHandler(manager *credentials.Manager) *CredentialsHandler {
+	return &CredentialsHandler{manager: manager}
+}
+
+// HandleListCredentials returns the stored credentials without their tokens
+func (h *CredentialsHandler) HandleListCredentials(c *gin.Context) {
+	infos, err := h.manager.List(c.Request.Context())
+	if err != nil {
+		slog.Error("failed to list credentials", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list credentials"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": infos})
+}
+
+type saveCredentialRequest struct {
+	URLPrefix string `json:"url_prefix" binding:"required"`
+	Username  string `json:"username"`
+	Token     string `json:"token" binding:"required"`
+}
+
+// HandleSaveCredential stores a token for HTTPS remotes under url_prefix
+func (h *CredentialsHandler) HandleSaveCredential(c *gin.Context) {
+	var req saveCredentialRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	if _, err := credentials.NormalizePrefix(req.URLPrefix); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	info, err := h.manager.Save(c.Request.Context(), req.URLPrefix, req.Username, req.Token)
+	if err != nil {
+		slog.Error("failed to save credential", "url_prefix", req.URLPrefix, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save credential"})
+		return
+	}
+	c.JSON(http.StatusOK, info)
+}
+
+// HandleDeleteCredential removes the token stored for ?url_prefix=
+func (h *CredentialsHandler) HandleDeleteCredential(c *gin.Context) {
+	prefix := c.Query("url_prefix")
+	if _, err := credentials.NormalizePrefix(prefix); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.manager.Delete(c.Request.Context(), prefix); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No credential for that URL prefix"})
+			return
+		}
+		slog.Error("failed to delete credential", "url_prefix", prefix, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete credential"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// HandleTestCredential checks whether git can reach a remote with the
+// credentials it would use for it
+func (h *CredentialsHandler) HandleTestCredential(c *gin.Context) {
+	var req struct {
+		URL string `json:"url" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	err := h.manager.Check(c.Request.Context(), req.URL)
+	var authErr *credentials.AuthError
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	case errors.As(err, &authErr):
+		c.JSON(http.StatusUnauthorized, authErrorBody(authErr))
+	default:
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+	}
+}
+
+// authErrorBody is the response for a git operation a remote refused for
+// lack of credentials
+func authErrorBody(err *credentials.AuthError) gin.H {
+	return gin.H{"error": err.Error(), "code": "auth_required", "remote": err.Remote, "hint": err.Hint}
+}
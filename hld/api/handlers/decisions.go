@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/humanlayer/humanlayer/hld/decisions"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// maxDecisions caps one decision listing
+const maxDecisions = 500
+
+// DecisionHandler manages the per-repository decision log
+type DecisionHandler struct {
+	store     store.ConversationStore
+	decisions store.DecisionStore
+	extractor *decisions.Extractor
+}
+
+// NewDecisionHandler creates a new decision log handler
+func NewDecisionHandler(conversationStore store.ConversationStore, decisionStore store.DecisionStore, extractor *decisions.Extractor) *DecisionHandler {
+	return &DecisionHandler{store: conversationStore, decisions: decisionStore, extractor: extractor}
+}
+
+type decisionRequest struct {
+	// Repository or WorkingDir (resolved to its repository root) on create
+	Repository   *string   `json:"repository"`
+	WorkingDir   string    `json:"working_dir"`
+	Title        *string   `json:"title"`
+	Decision     *string   `json:"decision"`
+	Rationale    *string   `json:"rationale"`
+	Alternatives *[]string `json:"alternatives"`
+	Status       *string   `json:"status"`
+}
+
+func validDecisionStatus(status string) bool {
+	switch status {
+	case store.DecisionStatusActive, store.DecisionStatusSuperseded, store.DecisionStatusRejected:
+		return true
+	}
+	return false
+}
+
+// apply copies the fields set in req onto decision
+func (req *decisionRequest) apply(decision *store.Decision) string {
+	if req.Repository != nil {
+		decision.Repository = strings.TrimSpace(*req.Repository)
+	}
+	if req.Title != nil {
+		decision.Title = strings.TrimSpace(*req.Title)
+	}
+	if req.Decision != nil {
+		decision.Decision = strings.TrimSpace(*req.Decision)
+	}
+	if req.Rationale != nil {
+		decision.Rationale = strings.TrimSpace(*req.Rationale)
+	}
+	if req.Alternatives != nil {
+		decision.Alternatives = *req.Alternatives
+	}
+	if req.Status != nil {
+		decision.Status = *req.Status
+	}
+	switch {
+	case decision.Repository == "":
+		return "repository or working_dir is required"
+	case decision.Title == "" || decision.Decision == "":
+		return "title and decision are required"
+	case !validDecisionStatus(decision.Status):
+		return "status must be active, superseded or rejected"
+	}
+	return ""
+}
+
+// HandleCreateDecision records a decision by hand
+func (h *DecisionHandler) HandleCreateDecision(c *gin.Context) {
+	var req decisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	decision := &store.Decision{ID: uuid.New().String(), Status: store.DecisionStatusActive}
+	if req.Repository == nil && req.WorkingDir != "" {
+		decision.Repository = decisions.RepoRoot(c.Request.Context(), req.WorkingDir)
+	}
+	if msg := req.apply(decision); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	if err := h.decisions.CreateDecision(c.Request.Context(), decision); err != nil {
+		slog.Error("failed to create decision", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create decision"})
+		return
+	}
+	c.JSON(http.StatusCreated, decision)
+}
+
+// HandleListDecisions lists decisions newest first. It accepts repository,
+// working_dir (resolved to its repository), session_id, status and limit.
+func (h *DecisionHandler) HandleListDecisions(c *gin.Context) {
+	filter := store.DecisionFilter{
+		Repository: c.Query("repository"),
+		SessionID:  c.Query("session_id"),
+		Status:     c.Query("status"),
+		Limit:      maxDecisions,
+	}
+	if dir := c.Query("working_dir"); dir != "" && filter.Repository == "" {
+		filter.Repository = decisions.RepoRoot(c.Request.Context(), dir)
+	}
+	if filter.Status != "" && !validDecisionStatus(filter.Status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be active, superseded or rejected"})
+		return
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxDecisions {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+			return
+		}
+		filter.Limit = limit
+	}
+
+	list, err := h.decisions.ListDecisions(c.Request.Context(), filter)
+	if err != nil {
+		slog.Error("failed to list decisions", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list decisions"})
+		return
+	}
+	if list == nil {
+		list = []*store.Decision{}
+	}
+	c.JSON(http.StatusOK, gin.H{"decisions": list})
+}
+
+// HandleGetDecision returns one decision
+func (h *DecisionHandler) HandleGetDecision(c *gin.Context) {
+	decision, err := h.decisions.GetDecision(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.respondError(c, err, "get")
+		return
+	}
+	c.JSON(http.StatusOK, decision)
+}
+
+// HandleUpdateDecision edits a decision; fields left out are unchanged
+func (h *DecisionHandler) HandleUpdateDecision(c *gin.Context) {
+	var req decisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	decision, err := h.decisions.GetDecision(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.respondError(c, err, "get")
+		return
+	}
+	if req.Repository == nil && req.WorkingDir != "" {
+		decision.Repository = decisions.RepoRoot(c.Request.Context(), req.WorkingDir)
+	}
+	if msg := req.apply(decision); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	if err := h.decisions.UpdateDecision(c.Request.Context(), decision); err != nil {
+		h.respondError(c, err, "update")
+		return
+	}
+	c.JSON(http.StatusOK, decision)
+}
+
+// HandleDeleteDecision removes a decision
+func (h *DecisionHandler) HandleDeleteDecision(c *gin.Context) {
+	if err := h.decisions.DeleteDecision(c.Request.Context(), c.Param("id")); err != nil {
+		h.respondError(c, err, "delete")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// HandleExtractDecisions mines a completed session for decisions now,
+// replacing any extracted from it before
+func (h *DecisionHandler) HandleExtractDecisions(c *gin.Context) {
+	sessionID := c.Param("id")
+	sess, err := h.store.GetSession(c.Request.Context(), sessionID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return
+		}
+		slog.Error("failed to get session", "session_id", sessionID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get session"})
+		return
+	}
+	if sess.Status != store.SessionStatusCompleted {
+		c.JSON(http.StatusConflict, gin.H{"error": "Session has not completed"})
+		return
+	}
+
+	created, err := h.extractor.Extract(c.Request.Context(), sessionID, true)
+	if err != nil {
+		slog.Error("failed to extract decisions", "session_id", sessionID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to extract decisions: " + err.Error()})
+		return
+	}
+	if created == nil {
+		created = []*store.Decision{}
+	}
+	c.JSON(http.StatusOK, gin.H{"decisions": created})
+}
+
+// respondError maps a failed store call on the :id decision to a response;
+// action names the call, such as "update"
+func (h *DecisionHandler) respondError(c *gin.Context, err error, action string) {
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Decision not found"})
+		return
+	}
+	slog.Error("failed to "+action+" decision", "decision_id", c.Param("id"), "error", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to " + action + " decision"})
+}
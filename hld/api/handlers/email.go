@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	claudecode "github.com/humanlayer/humanlayer/claudecode-go"
+	"github.com/humanlayer/humanlayer/hld/approval"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/email"
+	"github.com/humanlayer/humanlayer/hld/moderation"
+	"github.com/humanlayer/humanlayer/hld/resolution"
+	"github.com/humanlayer/humanlayer/hld/session"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// maxInboundEmail matches SendGrid's 30 MB inbound message cap
+const maxInboundEmail = 30 << 20
+
+// EmailHandler receives inbound mail from a parse service: replies to
+// approval notifications decide approvals, and other mail starts sessions
+type EmailHandler struct {
+	cfg             config.EmailConfig
+	tokens          *email.Tokens
+	approvalManager approval.Manager
+	sessions        session.SessionManager
+	justification   *Justification
+	// ledger makes each notification's token good for one reply
+	ledger *resolution.Ledger
+}
+
+// NewEmailHandler creates a new inbound email handler
+func NewEmailHandler(cfg config.EmailConfig, tokens *email.Tokens, approvalManager approval.Manager, sessions session.SessionManager) *EmailHandler {
+	if cfg.Template == "" {
+		cfg.Template = config.DefaultEmailTemplate
+	}
+	return &EmailHandler{cfg: cfg, tokens: tokens, approvalManager: approvalManager, sessions: sessions}
+}
+
+// SetJustification requires high-risk approvals by email to carry a comment
+func (h *EmailHandler) SetJustification(j *Justification) {
+	h.justification = j
+}
+
+// SetResolutionLedger refuses replayed tokens and records refused replies
+func (h *EmailHandler) SetResolutionLedger(ledger *resolution.Ledger) {
+	h.ledger = ledger
+}
+
+// EmailResult is what an inbound message did
+type EmailResult struct {
+	ApprovalID string `json:"approval_id,omitempty"`
+	Decision   string `json:"decision,omitempty"`
+	SessionID  string `json:"session_id,omitempty"`
+	RunID      string `json:"run_id,omitempty"`
+}
+
+// HandleInbound acts on a message posted by a parse service. Messages that
+// can't be acted on, such as mail from senders who aren't allowed or a reply
+// to an approval already decided, are acknowledged with the reason they
+// were ignored, since parse services retry anything else.
+func (h *EmailHandler) HandleInbound(c *gin.Context) {
+	if h.cfg.Address == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Email is not configured"})
+		return
+	}
+	if !h.authorized(c.Request) {
+		c.Header("WWW-Authenticate", `Basic realm="hld"`)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxInboundEmail))
+	if err != nil {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Message too large"})
+		return
+	}
+
+	msg, subscribeURL, err := email.Parse(c.GetHeader("Content-Type"), body)
+	switch {
+	case errors.Is(err, email.ErrNoMessage):
+		if subscribeURL != "" {
+			slog.Warn("confirm the SNS subscription for inbound email by visiting its URL", "subscribe_url", subscribeURL)
+		}
+		c.JSON(http.StatusOK, gin.H{"ignored": err.Error()})
+		return
+	case errors.Is(err, email.ErrUnsupportedFormat):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch {
+	case msg.SenderVerified != nil && !*msg.SenderVerified:
+		c.JSON(http.StatusOK, gin.H{"ignored": "sender failed SPF and DKIM"})
+		return
+	case !email.Allowed(msg.From, h.cfg.AllowedSenders):
+		slog.Info("ignored inbound email from sender who isn't allowed", "from", email.Address(msg.From))
+		c.JSON(http.StatusOK, gin.H{"ignored": "sender is not allowed"})
+		return
+	case !msg.SentTo(h.cfg.Address):
+		c.JSON(http.StatusOK, gin.H{"ignored": "message is not addressed to " + h.cfg.Address})
+		return
+	}
+
+	claim, isReply, err := h.tokens.Approval(msg.Subject)
+	switch {
+	case errors.Is(err, email.ErrInvalidToken), errors.Is(err, email.ErrExpiredToken):
+		approvalID := ""
+		if claim != nil {
+			approvalID = claim.ApprovalID
+		}
+		h.ledger.Reject(c.Request.Context(), resolution.ChannelEmail, email.Address(msg.From), approvalID, err)
+		c.JSON(http.StatusOK, gin.H{"ignored": err.Error()})
+	case err != nil:
+		slog.Error("failed to verify approval token", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify approval token"})
+	case isReply:
+		h.decide(c, msg, claim)
+	default:
+		h.launch(c, msg)
+	}
+}
+
+// authorized accepts the inbound secret as a bearer token or as the password
+// of basic auth, which parse services send from credentials in the URL
+func (h *EmailHandler) authorized(r *http.Request) bool {
+	secret := h.cfg.InboundSecret
+	if secret == "" {
+		return false
+	}
+	given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		_, given, ok = r.BasicAuth()
+	}
+	return ok && subtle.ConstantTimeCompare([]byte(given), []byte(secret)) == 1
+}
+
+func (h *EmailHandler) decide(c *gin.Context, msg *email.Message, claim *resolution.Claim) {
+	ctx := c.Request.Context()
+	approvalID := claim.ApprovalID
+	sender := email.Address(msg.From)
+	decision, comment, ok := email.ParseReply(msg.Text)
+	if !ok {
+		c.JSON(http.StatusOK, gin.H{"ignored": "reply doesn't start with approve or deny"})
+		return
+	}
+	if err := h.ledger.Redeem(ctx, resolution.ChannelEmail, sender, claim); errors.Is(err, resolution.ErrReplayed) {
+		c.JSON(http.StatusOK, gin.H{"ignored": err.Error()})
+		return
+	} else if err != nil {
+		slog.Error("failed to redeem approval token", "approval_id", approvalID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify approval token"})
+		return
+	}
+	pending, err := h.approvalManager.GetApproval(ctx, approvalID)
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		c.JSON(http.StatusOK, gin.H{"ignored": "approval not found"})
+		return
+	case err != nil:
+		slog.Error("failed to get approval for email reply", "approval_id", approvalID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get approval"})
+		return
+	case pending.Status != store.ApprovalStatusLocalPending:
+		c.JSON(http.StatusOK, gin.H{"ignored": "approval has already been decided"})
+		return
+	}
+
+	var highRisk *justified
+	if decision == email.DecisionApprove {
+		if highRisk, err = h.justification.check(ctx, approvalID, comment); err == nil {
+			err = h.approvalManager.ApproveToolCall(ctx, approvalID, comment, nil)
+		}
+	} else {
+		if comment == "" {
+			comment = "Denied by email from " + sender
+		}
+		err = h.approvalManager.DenyToolCall(ctx, approvalID, comment, nil)
+	}
+	switch {
+	case errors.Is(err, ErrJustificationRequired), errors.Is(err, moderation.ErrBlocked):
+		// A reworded reply to the same notification may decide it
+		h.ledger.Release(ctx, resolution.ChannelEmail, claim)
+		c.JSON(http.StatusOK, gin.H{"ignored": err.Error()})
+		return
+	case errors.Is(err, store.ErrAlreadyDecided):
+		c.JSON(http.StatusOK, gin.H{"ignored": err.Error()})
+		return
+	case err != nil:
+		slog.Error("failed to decide approval from email reply", "approval_id", approvalID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decide approval"})
+		return
+	}
+	h.justification.record(ctx, highRisk, comment)
+	slog.Info("decided approval by email", "approval_id", approvalID, "decision", decision, "from", sender)
+	c.JSON(http.StatusOK, EmailResult{ApprovalID: approvalID, Decision: decision})
+}
+
+func (h *EmailHandler) launch(c *gin.Context, msg *email.Message) {
+	if h.cfg.WorkingDir == "" {
+		c.JSON(http.StatusOK, gin.H{"ignored": "sessions can't be requested by email without a working_dir"})
+		return
+	}
+	// Replies carry a signed token, but new mail has only its From header,
+	// which anyone can forge
+	if msg.SenderVerified == nil && !h.cfg.AllowUnverifiedSenders {
+		slog.Info("ignored session request from unverified sender", "from", email.Address(msg.From))
+		c.JSON(http.StatusOK, gin.H{"ignored": "sender couldn't be verified; sessions need mail that passed SPF or DKIM"})
+		return
+	}
+	query := email.Body(msg.Text)
+	if query == "" {
+		c.JSON(http.StatusOK, gin.H{"ignored": "message has no text"})
+		return
+	}
+	sess, err := h.sessions.LaunchSession(c.Request.Context(), session.LaunchSessionConfig{
+		SessionConfig: claudecode.SessionConfig{
+			Query:        query,
+			WorkingDir:   h.cfg.WorkingDir,
+			OutputFormat: claudecode.OutputStreamJSON,
+		},
+		Title:    strings.TrimSpace(msg.Subject),
+		Template: h.cfg.Template,
+	}, false)
+	if err != nil {
+		slog.Error("failed to launch session from email", "from", email.Address(msg.From), "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to launch session"})
+		return
+	}
+	slog.Info("launched session from email", "session_id", sess.ID, "from", email.Address(msg.From))
+	c.JSON(http.StatusCreated, EmailResult{SessionID: sess.ID, RunID: sess.RunID})
+}
@@ -0,0 +1,178 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/api/handlers"
+	"github.com/humanlayer/humanlayer/hld/approval"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/email"
+	"github.com/humanlayer/humanlayer/hld/resolution"
+	"github.com/humanlayer/humanlayer/hld/session"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestInboundEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	sessions := session.NewMockSessionManager(ctrl)
+	fake := approval.NewFakeManager()
+	tokens := email.NewTokens(bytes.Repeat([]byte{7}, 32))
+	h := handlers.NewEmailHandler(config.EmailConfig{
+		Address:        "hld@example.com",
+		InboundSecret:  "s3cret",
+		AllowedSenders: []string{"@example.com"},
+		WorkingDir:     "/src/app",
+	}, tokens, fake, sessions)
+	resolutions := store.NewInMemoryStore()
+	h.SetResolutionLedger(resolution.NewLedger(resolutions))
+	router := gin.New()
+	router.POST("/api/v1/email/inbound", h.HandleInbound)
+
+	send := func(from, subject, text string) (int, map[string]any) {
+		raw := "From: " + from + "\r\nTo: HLD <hld@example.com>\r\nSubject: " + subject + "\r\n\r\n" + text
+		req := httptest.NewRequest("POST", "/api/v1/email/inbound", bytes.NewBufferString(raw))
+		req.Header.Set("Content-Type", "message/rfc822")
+		req.SetBasicAuth("sendgrid", "s3cret")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var resp map[string]any
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+
+	// sendVerified posts SendGrid form fields, with the SPF result it reports
+	sendVerified := func(from, subject, text string) (int, map[string]any) {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		for name, value := range map[string]string{"from": from, "to": "hld@example.com", "subject": subject, "text": text, "SPF": "pass"} {
+			require.NoError(t, form.WriteField(name, value))
+		}
+		require.NoError(t, form.Close())
+		req := httptest.NewRequest("POST", "/api/v1/email/inbound", &body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		req.SetBasicAuth("sendgrid", "s3cret")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var resp map[string]any
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+
+	t.Run("credentials", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/api/v1/email/inbound", bytes.NewBufferString("From: ada@example.com\r\n\r\nhi"))
+		req.Header.Set("Content-Type", "message/rfc822")
+		req.SetBasicAuth("sendgrid", "wrong")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("reply decides approval", func(t *testing.T) {
+		id, err := fake.CreateApproval(context.Background(), "run-1", "Bash", json.RawMessage(`{"command":"make deploy"}`))
+		require.NoError(t, err)
+		token, err := tokens.Token(id)
+		require.NoError(t, err)
+
+		code, resp := send("ada@example.com", "Re: Approval needed: app "+token, "Deny - deploy from CI instead\r\n\r\n> quoted")
+		require.Equal(t, http.StatusOK, code, resp)
+		assert.Equal(t, "deny", resp["decision"])
+		decided, err := fake.GetApproval(context.Background(), id)
+		require.NoError(t, err)
+		assert.Equal(t, store.ApprovalStatusLocalDenied, decided.Status)
+		assert.Equal(t, "deploy from CI instead", decided.Comment)
+
+		code, resp = send("ada@example.com", "Re: Approval needed: app "+token, "approve")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, resolution.ErrReplayed.Error(), resp["ignored"])
+
+		fresh, err := tokens.Token(id)
+		require.NoError(t, err)
+		_, resp = send("ada@example.com", "Re: "+fresh, "approve")
+		assert.Equal(t, "approval has already been decided", resp["ignored"])
+
+		rejected, err := resolutions.ListRejectedResolutions(context.Background(), id)
+		require.NoError(t, err)
+		require.Len(t, rejected, 1)
+		assert.Equal(t, resolution.ChannelEmail, rejected[0].Channel)
+		assert.Equal(t, "ada@example.com", rejected[0].Source)
+		assert.Equal(t, "token was already used", rejected[0].Reason)
+	})
+
+	t.Run("ignored mail", func(t *testing.T) {
+		id, err := fake.CreateApproval(context.Background(), "run-1", "Bash", json.RawMessage(`{}`))
+		require.NoError(t, err)
+		token, err := tokens.Token(id)
+		require.NoError(t, err)
+
+		_, resp := send("mallory@evil.dev", "Re: "+token, "approve")
+		assert.Equal(t, "sender is not allowed", resp["ignored"])
+		forged := token[:strings.LastIndex(token, ".")] + ".AAAAAAAAAAAAAAAAAAAAAA]"
+		_, resp = send("ada@example.com", "Re: "+forged, "approve")
+		assert.Equal(t, email.ErrInvalidToken.Error(), resp["ignored"])
+		_, resp = send("ada@example.com", "Re: "+token, "Let me think about it")
+		assert.Equal(t, "reply doesn't start with approve or deny", resp["ignored"])
+
+		pending, err := fake.GetApproval(context.Background(), id)
+		require.NoError(t, err)
+		assert.Equal(t, store.ApprovalStatusLocalPending, pending.Status)
+	})
+
+	t.Run("new mail launches session", func(t *testing.T) {
+		sessions.EXPECT().
+			LaunchSession(gomock.Any(), gomock.Any(), false).
+			DoAndReturn(func(ctx context.Context, cfg session.LaunchSessionConfig, isDraft bool) (*session.Session, error) {
+				assert.Equal(t, "Fix the flaky login test", cfg.Query)
+				assert.Equal(t, "/src/app", cfg.WorkingDir)
+				assert.Equal(t, "Flaky test", cfg.Title)
+				assert.Equal(t, "email", cfg.Template)
+				return &session.Session{ID: "sess-1", RunID: "run-2"}, nil
+			})
+
+		code, resp := sendVerified("grace@example.com", "Flaky test", "Fix the flaky login test\r\n\r\n-- \r\nGrace")
+		require.Equal(t, http.StatusCreated, code, resp)
+		assert.Equal(t, "sess-1", resp["session_id"])
+	})
+
+	t.Run("unverified sender doesn't launch session", func(t *testing.T) {
+		// No LaunchSession is expected; the controller fails the test on one
+		code, resp := send("grace@example.com", "Flaky test", "Fix the flaky login test")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "sender couldn't be verified; sessions need mail that passed SPF or DKIM", resp["ignored"])
+	})
+}
+
+func TestInboundEmailAllowUnverifiedSenders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	sessions := session.NewMockSessionManager(ctrl)
+	h := handlers.NewEmailHandler(config.EmailConfig{
+		Address:                "hld@example.com",
+		InboundSecret:          "s3cret",
+		AllowedSenders:         []string{"@example.com"},
+		AllowUnverifiedSenders: true,
+		WorkingDir:             "/src/app",
+	}, email.NewTokens(bytes.Repeat([]byte{7}, 32)), approval.NewFakeManager(), sessions)
+	router := gin.New()
+	router.POST("/api/v1/email/inbound", h.HandleInbound)
+	sessions.EXPECT().LaunchSession(gomock.Any(), gomock.Any(), false).Return(&session.Session{ID: "sess-1", RunID: "run-1"}, nil)
+
+	raw := "From: grace@example.com\r\nTo: hld@example.com\r\nSubject: Flaky test\r\n\r\nFix the flaky login test"
+	req := httptest.NewRequest("POST", "/api/v1/email/inbound", bytes.NewBufferString(raw))
+	req.Header.Set("Content-Type", "message/rfc822")
+	req.SetBasicAuth("sendgrid", "s3cret")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+}
@@ -1,36 +1,77 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/humanlayer/humanlayer/claudecode-go"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/i18n"
+	"github.com/humanlayer/humanlayer/hld/injection"
+	"github.com/humanlayer/humanlayer/hld/jobs"
+	"github.com/humanlayer/humanlayer/hld/llm"
+	"github.com/humanlayer/humanlayer/hld/prompts"
 	"github.com/humanlayer/humanlayer/hld/store"
 )
 
 // EphemeralChatHandler handles ephemeral (non-persistent) chat requests
 type EphemeralChatHandler struct {
-	store        store.ConversationStore
-	claudeClient *claudecode.Client
+	store   store.ConversationStore
+	router  *llm.Router
+	prompts *prompts.Set
+	locale  string
+	jobs    *jobs.Manager
+	// outputs keeps answers for feedback; nil skips it
+	outputs store.AIOutputStore
+	// pathMappings rewrite working dirs recorded on other machines
+	pathMappings []config.PathMapping
+	// injections scrubs session text before it's put in a prompt; nil
+	// passes it through
+	injections *injection.Guard
 }
 
-// NewEphemeralChatHandler creates a new ephemeral chat handler
+// NewEphemeralChatHandler creates a new ephemeral chat handler using the default model routes and prompts
 func NewEphemeralChatHandler(conversationStore store.ConversationStore) *EphemeralChatHandler {
-	client, err := claudecode.NewClient()
-	if err != nil {
-		slog.Warn("failed to create claude client for ephemeral chat", "error", err)
-		// Client will be nil, we'll check for this in the handler
-	}
+	return NewEphemeralChatHandlerWithRouter(conversationStore, llm.NewRouter(config.LLMConfig{}), prompts.Default(), "")
+}
+
+// NewEphemeralChatHandlerWithRouter creates a new ephemeral chat handler that
+// answers through the given model router and prompt templates, in locale
+// unless a request asks for another
+func NewEphemeralChatHandlerWithRouter(conversationStore store.ConversationStore, router *llm.Router, templates *prompts.Set, locale string) *EphemeralChatHandler {
 	return &EphemeralChatHandler{
-		store:        conversationStore,
-		claudeClient: client,
+		store:   conversationStore,
+		router:  router,
+		prompts: templates,
+		locale:  locale,
 	}
 }
 
+// SetOutputs keeps the handler's answers in outputs so they can be rated
+func (h *EphemeralChatHandler) SetOutputs(outputs store.AIOutputStore) {
+	h.outputs = outputs
+}
+
+// SetJobManager lets clients run ephemeral chat queries as background jobs by
+// passing ?async=true
+func (h *EphemeralChatHandler) SetJobManager(m *jobs.Manager) {
+	h.jobs = m
+}
+
+// SetPathMappings sets the rewrites applied to session working directories
+func (h *EphemeralChatHandler) SetPathMappings(mappings []config.PathMapping) {
+	h.pathMappings = mappings
+}
+
+// SetInjectionGuard scans the session's query, summary and recent messages
+// for prompt injections before they go into the chat prompt
+func (h *EphemeralChatHandler) SetInjectionGuard(guard *injection.Guard) {
+	h.injections = guard
+}
+
 // EphemeralChatRequest represents an ephemeral chat request
 type EphemeralChatRequest struct {
 	Message string `json:"message"`
@@ -38,15 +79,31 @@ type EphemeralChatRequest struct {
 		IncludeRecentEvents bool `json:"include_recent_events"`
 		MaxEvents           int  `json:"max_events"`
 	} `json:"context"`
+	// Locale for the answer (BCP 47, e.g. "fr"); defaults to Accept-Language,
+	// then the daemon locale
+	Locale string `json:"locale,omitempty"`
+}
+
+// EphemeralChatPromptData is the data passed to the ephemeral-chat prompt template
+type EphemeralChatPromptData struct {
+	Question           string
+	Query              string
+	Summary            string
+	WorkingDir         string
+	Status             string
+	RecentConversation []string
+	Language           string // Language to answer in, e.g. "French"; empty for English
 }
 
 // EphemeralChatResponse represents an ephemeral chat response
 type EphemeralChatResponse struct {
 	Content string `json:"content"`
+	// Pass to POST /outputs/{id}/feedback to rate the answer
+	OutputID string `json:"output_id,omitempty"`
 }
 
 // HandleEphemeralChat processes an ephemeral chat request
-// This endpoint uses Claude Code to make AI requests WITHOUT persisting to conversation history
+// This endpoint makes AI requests (via Claude Code by default) WITHOUT persisting to conversation history
 func (h *EphemeralChatHandler) HandleEphemeralChat(c *gin.Context) {
 	startTime := time.Now()
 	sessionID := c.Param("session_id")
@@ -55,13 +112,6 @@ func (h *EphemeralChatHandler) HandleEphemeralChat(c *gin.Context) {
 		"session_id", sessionID,
 		"start_time", startTime)
 
-	// Check if claude client is available
-	if h.claudeClient == nil {
-		slog.Error("claude client not available for ephemeral chat")
-		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Claude Code not available"})
-		return
-	}
-
 	// Parse request body
 	var req EphemeralChatRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -90,16 +140,17 @@ func (h *EphemeralChatHandler) HandleEphemeralChat(c *gin.Context) {
 		return
 	}
 
-	// Build context from session
-	var contextParts []string
-	contextParts = append(contextParts, fmt.Sprintf("Session Query: %s", session.Query))
-	if session.Summary != "" {
-		contextParts = append(contextParts, fmt.Sprintf("Session Summary: %s", session.Summary))
-	}
-	if session.WorkingDir != "" {
-		contextParts = append(contextParts, fmt.Sprintf("Working Directory: %s", session.WorkingDir))
+	// The question is the user's own; the rest is session text a tool
+	// result or fetched page may have planted instructions in
+	prompt := string(llm.TaskEphemeralChat)
+	data := EphemeralChatPromptData{
+		Question:   req.Message,
+		Query:      h.injections.Check(c.Request.Context(), sessionID, prompt, "query", session.Query),
+		Summary:    h.injections.Check(c.Request.Context(), sessionID, prompt, "summary", session.Summary),
+		WorkingDir: config.RemapPath(h.pathMappings, session.WorkingDir),
+		Status:     session.Status,
+		Language:   i18n.LanguageName(i18n.Resolve(req.Locale, c.GetHeader("Accept-Language"), h.locale)),
 	}
-	contextParts = append(contextParts, fmt.Sprintf("Session Status: %s", session.Status))
 
 	// Optionally include recent conversation events
 	if req.Context.IncludeRecentEvents {
@@ -112,7 +163,6 @@ func (h *EphemeralChatHandler) HandleEphemeralChat(c *gin.Context) {
 			}
 			recentEvents := events[startIdx:]
 
-			var eventSummaries []string
 			for _, event := range recentEvents {
 				if event.EventType == "message" && event.Content != "" {
 					role := "User"
@@ -124,33 +174,32 @@ func (h *EphemeralChatHandler) HandleEphemeralChat(c *gin.Context) {
 					if len(content) > 500 {
 						content = content[:500] + "..."
 					}
-					eventSummaries = append(eventSummaries, fmt.Sprintf("%s: %s", role, content))
+					content = h.injections.Check(c.Request.Context(), sessionID, prompt, "conversation", content)
+					data.RecentConversation = append(data.RecentConversation, fmt.Sprintf("%s: %s", role, content))
 				} else if event.EventType == "tool_call" && event.ToolName != "" {
-					eventSummaries = append(eventSummaries, fmt.Sprintf("Tool Call: %s", event.ToolName))
+					data.RecentConversation = append(data.RecentConversation, fmt.Sprintf("Tool Call: %s", event.ToolName))
 				}
 			}
-			if len(eventSummaries) > 0 {
-				contextParts = append(contextParts, fmt.Sprintf("\nRecent Conversation:\n%s", strings.Join(eventSummaries, "\n")))
-			}
 		}
 	}
 
-	sessionContext := strings.Join(contextParts, "\n")
-
-	// Build the query with context
-	query := fmt.Sprintf(`You are answering a clarifying question about a coding session.
-The user is reviewing a session and wants to understand what's happening before making a decision.
-Provide concise, helpful answers based on the context below.
-
-Session Context:
-%s
-
-User's Question: %s
+	// Build the query from the ephemeral-chat template
+	query, err := h.prompts.Render(prompts.EphemeralChat, data)
+	if err != nil {
+		slog.Error("failed to build ephemeral chat prompt", "session_id", sessionID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build prompt"})
+		return
+	}
 
-Important: Keep your response focused and concise. This is an ephemeral chat.`, sessionContext, req.Message)
+	if wantsJob(c, h.jobs) {
+		submitJob(c, h.jobs, string(llm.TaskEphemeralChat), sessionID, func(ctx context.Context) (any, error) {
+			return h.runEphemeralQuery(ctx, session, query)
+		})
+		return
+	}
 
-	// Launch Claude Code process for the ephemeral query
-	response, err := h.runEphemeralQuery(session, query)
+	// Run the query on the model routed for ephemeral chat
+	response, err := h.runEphemeralQuery(c.Request.Context(), session, query)
 	if err != nil {
 		slog.Error("ephemeral chat query failed",
 			"session_id", sessionID,
@@ -163,40 +212,37 @@ Important: Keep your response focused and concise. This is an ephemeral chat.`,
 		"session_id", sessionID,
 		"duration_ms", time.Since(startTime).Milliseconds())
 
-	c.JSON(http.StatusOK, EphemeralChatResponse{
-		Content: response,
-	})
+	c.JSON(http.StatusOK, response)
 }
 
-// runEphemeralQuery launches a lightweight Claude Code process to handle the query
-func (h *EphemeralChatHandler) runEphemeralQuery(session *store.Session, query string) (string, error) {
-	// Build config for ephemeral query
-	config := claudecode.SessionConfig{
-		Query:        query,
-		Model:        claudecode.ModelSonnet, // Use Sonnet for good quality/speed balance
-		OutputFormat: claudecode.OutputJSON,  // JSON for easier parsing
-		MaxTurns:     1,                      // Single turn for quick response
-		WorkingDir:   session.WorkingDir,     // Use session's working directory for context
-	}
-
-	// If session has a claude_session_id, we can fork from it for context
-	// But for ephemeral chat, we typically want a fresh context with just the summary
-	// Forking could be expensive and include full conversation history
-	// So we'll use a fresh session with the context in the query
-
-	slog.Debug("launching ephemeral claude query",
+// runEphemeralQuery sends the query to the models routed for ephemeral chat,
+// falling back in order if one is unavailable, and records the answer for
+// feedback
+func (h *EphemeralChatHandler) runEphemeralQuery(ctx context.Context, session *store.Session, query string) (*EphemeralChatResponse, error) {
+	// The session's context is included in the query rather than forking its
+	// Claude session, which could be expensive and include full history
+	workingDir := config.RemapPath(h.pathMappings, session.WorkingDir)
+	slog.Debug("launching ephemeral query",
 		"session_id", session.ID,
-		"working_dir", session.WorkingDir)
+		"working_dir", workingDir)
 
-	// Launch and wait for result
-	result, err := h.claudeClient.LaunchAndWait(config)
+	resp, err := h.router.Complete(ctx, llm.TaskEphemeralChat, llm.Request{
+		Prompt:     query,
+		WorkingDir: workingDir, // Local providers use the session's directory for context
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to run claude query: %w", err)
-	}
-
-	if result.IsError {
-		return "", fmt.Errorf("claude returned error: %s", result.Error)
+		return nil, err
 	}
 
-	return result.Result, nil
+	outputID := recordOutput(ctx, h.outputs, &store.AIOutput{
+		Kind:            store.AIOutputEphemeralChat,
+		SessionID:       session.ID,
+		Template:        prompts.EphemeralChat,
+		TemplateVersion: h.prompts.Version(prompts.EphemeralChat),
+		Provider:        resp.Provider,
+		Model:           resp.Model,
+		Prompt:          query,
+		Response:        resp.Text,
+	})
+	return &EphemeralChatResponse{Content: resp.Text, OutputID: outputID}, nil
 }
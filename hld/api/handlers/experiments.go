@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/experiment"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// ExperimentHandler launches A/B experiments and reports their outcomes
+type ExperimentHandler struct {
+	store       store.ConversationStore
+	experiments store.ExperimentStore
+	runner      *experiment.Runner
+}
+
+// NewExperimentHandler creates a new experiment handler
+func NewExperimentHandler(conversationStore store.ConversationStore, experiments store.ExperimentStore, runner *experiment.Runner) *ExperimentHandler {
+	return &ExperimentHandler{store: conversationStore, experiments: experiments, runner: runner}
+}
+
+// HandleCreateExperiment launches every variant's sessions and responds 201
+// with the experiment and its runs
+func (h *ExperimentHandler) HandleCreateExperiment(c *gin.Context) {
+	var req experiment.Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	exp, runs, err := h.runner.Start(c.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, experiment.ErrInvalidRequest) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		slog.Error("failed to start experiment", "error", err)
+		// Sessions launched before the failure keep running and stay listed
+		if exp != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "experiment_id": exp.ID})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start experiment"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"experiment": exp, "runs": runs})
+}
+
+// HandleListExperiments returns experiments newest first
+func (h *ExperimentHandler) HandleListExperiments(c *gin.Context) {
+	list, err := h.experiments.ListExperiments(c.Request.Context())
+	if err != nil {
+		slog.Error("failed to list experiments", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list experiments"})
+		return
+	}
+	if list == nil {
+		list = []*store.Experiment{}
+	}
+	c.JSON(http.StatusOK, gin.H{"experiments": list})
+}
+
+// HandleGetExperiment compares an experiment's variants: outcome, diff size,
+// test pass rate and cost
+func (h *ExperimentHandler) HandleGetExperiment(c *gin.Context) {
+	report, err := experiment.BuildReport(c.Request.Context(), h.store, h.experiments, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Experiment not found"})
+			return
+		}
+		slog.Error("failed to build experiment report", "experiment_id", c.Param("id"), "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build experiment report"})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
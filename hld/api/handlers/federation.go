@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/approval"
+	"github.com/humanlayer/humanlayer/hld/federation"
+	"github.com/humanlayer/humanlayer/hld/moderation"
+	"github.com/humanlayer/humanlayer/hld/resolution"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// FederationHandler serves the federation routes: a member shares its
+// sessions and approvals and takes decisions from the hub, and the hub
+// aggregates its members for a central console. Every route needs the
+// federation token.
+type FederationHandler struct {
+	token     string
+	hub       *federation.Hub // nil unless this daemon is the hub
+	store     store.ConversationStore
+	approvals approval.Manager
+	// justification requires and records comments for high-risk approvals
+	justification *Justification
+	// signer verifies the hub's signature on decisions
+	signer *resolution.Signer
+	// ledger refuses replayed decisions and records refused ones
+	ledger *resolution.Ledger
+}
+
+// NewFederationHandler creates a new federation handler
+func NewFederationHandler(token string, hub *federation.Hub, s store.ConversationStore, approvals approval.Manager) *FederationHandler {
+	return &FederationHandler{token: token, hub: hub, store: s, approvals: approvals, signer: federation.DecisionSigner(token)}
+}
+
+// SetResolutionLedger refuses replayed decisions and records refused ones
+func (h *FederationHandler) SetResolutionLedger(ledger *resolution.Ledger) {
+	h.ledger = ledger
+}
+
+// SetJustification requires a comment for approving high-risk tool calls
+// from the hub, as for local approvals
+func (h *FederationHandler) SetJustification(j *Justification) {
+	h.justification = j
+}
+
+// IsHub reports whether the hub routes are served
+func (h *FederationHandler) IsHub() bool {
+	return h.hub != nil
+}
+
+// Auth rejects requests without the federation token
+func (h *FederationHandler) Auth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !federation.Authorized(c.GetHeader("Authorization"), h.token) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "federation token required"})
+			return
+		}
+		c.Next()
+	}
+}
+
+type federationRegisterRequest struct {
+	Name string `json:"name" binding:"required"`
+	URL  string `json:"url" binding:"required"`
+}
+
+type federationDecideRequest struct {
+	Decision string `json:"decision" binding:"required"`
+	Comment  string `json:"comment"`
+}
+
+// HandleSnapshot returns this daemon's sessions and pending approvals
+func (h *FederationHandler) HandleSnapshot(c *gin.Context) {
+	snapshot, err := federation.TakeSnapshot(c.Request.Context(), h.store)
+	if err != nil {
+		slog.Error("failed to take federation snapshot", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to take snapshot"})
+		return
+	}
+	c.JSON(http.StatusOK, snapshot)
+}
+
+// HandleDecide decides one of this daemon's approvals for the hub. The
+// decision must carry the hub's signature for this approval and body, and
+// is applied at most once.
+func (h *FederationHandler) HandleDecide(c *gin.Context) {
+	ctx := c.Request.Context()
+	approvalID := c.Param("id")
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	claim, err := h.signer.Verify(c.GetHeader(federation.ResolutionHeader), body, time.Now())
+	if err == nil && claim.ApprovalID != approvalID {
+		err = resolution.ErrInvalid
+	}
+	if err != nil {
+		h.ledger.Reject(ctx, resolution.ChannelFederation, c.ClientIP(), approvalID, err)
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	var decision federation.Decision
+	if err := json.Unmarshal(body, &decision); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	if !decision.Approved && decision.Comment == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "comment is required when denying"})
+		return
+	}
+	var highRisk *justified
+	if decision.Approved {
+		if highRisk, err = h.justification.check(ctx, approvalID, decision.Comment); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if err := h.ledger.Redeem(ctx, resolution.ChannelFederation, c.ClientIP(), claim); errors.Is(err, resolution.ErrReplayed) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		slog.Error("failed to redeem federated decision", "approval_id", approvalID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Decision failed"})
+		return
+	}
+	if decision.Approved {
+		if err = h.approvals.ApproveToolCall(ctx, approvalID, decision.Comment, nil); err == nil {
+			h.justification.record(ctx, highRisk, decision.Comment)
+		}
+	} else {
+		err = h.approvals.DenyToolCall(ctx, approvalID, decision.Comment, nil)
+	}
+	switch {
+	case err == nil:
+		slog.Info("approval decided through federation hub", "approval_id", approvalID, "approved", decision.Approved)
+		c.Status(http.StatusNoContent)
+	case errors.Is(err, store.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Approval not found"})
+	case errors.Is(err, store.ErrAlreadyDecided):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, moderation.ErrBlocked):
+		// The hub may send the decision again with another comment
+		h.ledger.Release(ctx, resolution.ChannelFederation, claim)
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		slog.Error("federated decision failed", "approval_id", approvalID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Decision failed"})
+	}
+}
+
+// HandleRegister adds a member to the hub, or records its heartbeat
+func (h *FederationHandler) HandleRegister(c *gin.Context) {
+	var req federationRegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name and url are required"})
+		return
+	}
+	if err := h.hub.Register(req.Name, req.URL); errors.Is(err, federation.ErrMemberNameTaken) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// HandleListMembers returns the members registered with the hub
+func (h *FederationHandler) HandleListMembers(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": h.hub.Members()})
+}
+
+// federatedSession is a member's session as the hub lists it
+type federatedSession struct {
+	Member string `json:"member"`
+	federation.Session
+}
+
+// federatedApproval is a member's pending approval as the hub lists it
+type federatedApproval struct {
+	Member string `json:"member"`
+	*store.Approval
+}
+
+// unreachableMember is a member whose sessions and approvals are missing
+// from a listing
+type unreachableMember struct {
+	Member string `json:"member"`
+	Error  string `json:"error"`
+}
+
+// HandleListSessions returns every member's sessions
+func (h *FederationHandler) HandleListSessions(c *gin.Context) {
+	sessions := []federatedSession{}
+	unreachable := []unreachableMember{}
+	for _, snapshot := range h.hub.Snapshots(c.Request.Context()) {
+		if snapshot.Error != "" {
+			unreachable = append(unreachable, unreachableMember{Member: snapshot.Member.Name, Error: snapshot.Error})
+		}
+		for _, sess := range snapshot.Sessions {
+			sessions = append(sessions, federatedSession{Member: snapshot.Member.Name, Session: sess})
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": sessions, "unreachable": unreachable})
+}
+
+// HandleListApprovals returns every member's pending approvals
+func (h *FederationHandler) HandleListApprovals(c *gin.Context) {
+	approvals := []federatedApproval{}
+	unreachable := []unreachableMember{}
+	for _, snapshot := range h.hub.Snapshots(c.Request.Context()) {
+		if snapshot.Error != "" {
+			unreachable = append(unreachable, unreachableMember{Member: snapshot.Member.Name, Error: snapshot.Error})
+		}
+		for _, a := range snapshot.Approvals {
+			approvals = append(approvals, federatedApproval{Member: snapshot.Member.Name, Approval: a})
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": approvals, "unreachable": unreachable})
+}
+
+// HandleProxyDecide decides an approval on the member that owns it
+func (h *FederationHandler) HandleProxyDecide(c *gin.Context) {
+	var req federationDecideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "decision is required"})
+		return
+	}
+	decision := federation.Decision{Comment: req.Comment}
+	switch req.Decision {
+	case "approve":
+		decision.Approved = true
+	case "deny":
+		if req.Comment == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "comment is required when denying"})
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "decision must be approve or deny"})
+		return
+	}
+
+	err := h.hub.Decide(c.Request.Context(), c.Param("name"), c.Param("id"), decision)
+	var remote *federation.RemoteError
+	switch {
+	case err == nil:
+		c.Status(http.StatusNoContent)
+	case errors.Is(err, federation.ErrUnknownMember):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.As(err, &remote) && remote.Status < http.StatusInternalServerError && remote.Status != http.StatusUnauthorized:
+		// The member's answer, such as an unknown or already decided approval
+		c.JSON(remote.Status, gin.H{"error": remote.Message})
+	default:
+		slog.Warn("failed to reach federation member", "member", c.Param("name"), "error", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Member could not decide the approval: " + err.Error()})
+	}
+}
@@ -0,0 +1,199 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/api/handlers"
+	"github.com/humanlayer/humanlayer/hld/approval"
+	"github.com/humanlayer/humanlayer/hld/federation"
+	"github.com/humanlayer/humanlayer/hld/resolution"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func federationRouter(h *handlers.FederationHandler) *gin.Engine {
+	router := gin.New()
+	fed := router.Group("/api/v1/federation", h.Auth())
+	fed.GET("/snapshot", h.HandleSnapshot)
+	fed.POST("/approvals/:id/decide", h.HandleDecide)
+	if h.IsHub() {
+		fed.POST("/members", h.HandleRegister)
+		fed.GET("/members", h.HandleListMembers)
+		fed.GET("/sessions", h.HandleListSessions)
+		fed.GET("/approvals", h.HandleListApprovals)
+		fed.POST("/members/:name/approvals/:id/decide", h.HandleProxyDecide)
+	}
+	return router
+}
+
+func federationRequest(t *testing.T, router *gin.Engine, method, path, token string, body any) *httptest.ResponseRecorder {
+	var reqBody []byte
+	if body != nil {
+		var err error
+		reqBody, err = json.Marshal(body)
+		require.NoError(t, err)
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestFederationHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	const token = "shared-secret"
+
+	memberStore := store.NewInMemoryStore()
+	require.NoError(t, memberStore.CreateSession(ctx, &store.Session{ID: "sess-1", RunID: "run-1", ClaudeSessionID: "claude-1", Query: "fix the build", Status: store.SessionStatusRunning}))
+	require.NoError(t, memberStore.CreateApproval(ctx, &store.Approval{ID: "appr-1", SessionID: "sess-1", RunID: "run-1", ToolName: "Bash", Status: store.ApprovalStatusLocalPending}))
+	require.NoError(t, memberStore.CreateApproval(ctx, &store.Approval{ID: "appr-2", SessionID: "sess-1", RunID: "run-1", ToolName: "Write", Status: store.ApprovalStatusLocalPending}))
+	member := handlers.NewFederationHandler(token, nil, memberStore, approval.NewManager(memberStore, nil))
+	member.SetJustification(handlers.NewJustification(bashRisk{}, 0.7, memberStore, nil))
+	member.SetResolutionLedger(resolution.NewLedger(memberStore))
+	memberRouter := federationRouter(member)
+	memberServer := httptest.NewServer(memberRouter)
+	defer memberServer.Close()
+
+	hubStore := store.NewInMemoryStore()
+	hub := federationRouter(handlers.NewFederationHandler(token, federation.NewHub(token), hubStore, approval.NewManager(hubStore, nil)))
+
+	t.Run("requires the token", func(t *testing.T) {
+		w := makeRequest(t, hub, "GET", "/api/v1/federation/members", nil)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		w = federationRequest(t, hub, "GET", "/api/v1/federation/members", "wrong", nil)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("members can't be asked for the hub routes", func(t *testing.T) {
+		w := federationRequest(t, federationRouter(member), "GET", "/api/v1/federation/members", token, nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	w := federationRequest(t, hub, "POST", "/api/v1/federation/members", token, map[string]string{"name": "laptop", "url": "ftp://laptop"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = federationRequest(t, hub, "POST", "/api/v1/federation/members", token, map[string]string{"name": "laptop", "url": memberServer.URL})
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	w = federationRequest(t, hub, "POST", "/api/v1/federation/members", token, map[string]string{"name": "laptop", "url": "http://impostor:7777"})
+	assert.Equal(t, http.StatusConflict, w.Code, "an online member's name can't be taken over")
+
+	t.Run("aggregates sessions and approvals", func(t *testing.T) {
+		w := federationRequest(t, hub, "GET", "/api/v1/federation/sessions", token, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var sessions struct {
+			Data []struct {
+				Member string `json:"member"`
+				ID     string `json:"id"`
+				Query  string `json:"query"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&sessions))
+		require.Len(t, sessions.Data, 1)
+		assert.Equal(t, "laptop", sessions.Data[0].Member)
+		assert.Equal(t, "fix the build", sessions.Data[0].Query)
+
+		w = federationRequest(t, hub, "GET", "/api/v1/federation/approvals", token, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		var approvals struct {
+			Data []struct {
+				Member   string `json:"member"`
+				ID       string `json:"id"`
+				ToolName string `json:"tool_name"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&approvals))
+		assert.Len(t, approvals.Data, 2)
+		assert.Equal(t, "laptop", approvals.Data[0].Member)
+	})
+
+	t.Run("proxies decisions to the owning member", func(t *testing.T) {
+		w := federationRequest(t, hub, "POST", "/api/v1/federation/members/laptop/approvals/appr-2/decide", token, map[string]string{"decision": "deny"})
+		assert.Equal(t, http.StatusBadRequest, w.Code, "denials need a comment")
+		w = federationRequest(t, hub, "POST", "/api/v1/federation/members/desktop/approvals/appr-1/decide", token, map[string]string{"decision": "approve"})
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		w = federationRequest(t, hub, "POST", "/api/v1/federation/members/laptop/approvals/missing/decide", token, map[string]string{"decision": "approve"})
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = federationRequest(t, hub, "POST", "/api/v1/federation/members/laptop/approvals/appr-1/decide", token, map[string]string{"decision": "approve"})
+		assert.Equal(t, http.StatusBadRequest, w.Code, "high-risk approvals need a justification")
+		assert.Contains(t, w.Body.String(), handlers.ErrJustificationRequired.Error())
+
+		w = federationRequest(t, hub, "POST", "/api/v1/federation/members/laptop/approvals/appr-1/decide", token, map[string]string{"decision": "approve", "comment": "ok from the console"})
+		require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+		decided, err := memberStore.GetApproval(ctx, "appr-1")
+		require.NoError(t, err)
+		assert.Equal(t, store.ApprovalStatusLocalApproved, decided.Status)
+		assert.Equal(t, "ok from the console", decided.Comment)
+		events, err := memberStore.GetSessionConversation(ctx, "sess-1")
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Contains(t, events[0].Content, "with justification: ok from the console")
+
+		w = federationRequest(t, hub, "POST", "/api/v1/federation/members/laptop/approvals/appr-1/decide", token, map[string]string{"decision": "deny", "comment": "too late"})
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		w = federationRequest(t, hub, "POST", "/api/v1/federation/members/laptop/approvals/appr-2/decide", token, map[string]string{"decision": "deny", "comment": "not on main"})
+		require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+		decided, err = memberStore.GetApproval(ctx, "appr-2")
+		require.NoError(t, err)
+		assert.Equal(t, store.ApprovalStatusLocalDenied, decided.Status)
+	})
+
+	t.Run("members only take signed decisions once", func(t *testing.T) {
+		require.NoError(t, memberStore.CreateApproval(ctx, &store.Approval{ID: "appr-3", SessionID: "sess-1", RunID: "run-1", ToolName: "Write", Status: store.ApprovalStatusLocalPending}))
+		body := []byte(`{"approved":false,"comment":"not on main"}`)
+		decide := func(approvalID, signature string, body []byte) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("POST", "/api/v1/federation/approvals/"+approvalID+"/decide", bytes.NewReader(body))
+			req.Header.Set("Authorization", "Bearer "+token)
+			req.Header.Set(federation.ResolutionHeader, signature)
+			w := httptest.NewRecorder()
+			memberRouter.ServeHTTP(w, req)
+			return w
+		}
+		signature, err := federation.DecisionSigner(token).Issue("appr-3", body, time.Minute)
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusForbidden, decide("appr-3", "", body).Code, "unsigned")
+		assert.Equal(t, http.StatusForbidden, decide("appr-2", signature, body).Code, "signed for another approval")
+		assert.Equal(t, http.StatusForbidden, decide("appr-3", signature, []byte(`{"approved":true}`)).Code, "another decision")
+		require.Equal(t, http.StatusNoContent, decide("appr-3", signature, body).Code)
+		w := decide("appr-3", signature, body)
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), resolution.ErrReplayed.Error())
+
+		rejected, err := memberStore.ListRejectedResolutions(ctx, "")
+		require.NoError(t, err)
+		require.Len(t, rejected, 4)
+		assert.Equal(t, resolution.ChannelFederation, rejected[0].Channel)
+		assert.Equal(t, "token was already used", rejected[3].Reason)
+	})
+
+	t.Run("lists unreachable members", func(t *testing.T) {
+		memberServer.Close()
+		w := federationRequest(t, hub, "GET", "/api/v1/federation/approvals", token, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		var approvals struct {
+			Data        []json.RawMessage `json:"data"`
+			Unreachable []struct {
+				Member string `json:"member"`
+			} `json:"unreachable"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&approvals))
+		assert.Empty(t, approvals.Data)
+		require.Len(t, approvals.Unreachable, 1)
+		assert.Equal(t, "laptop", approvals.Unreachable[0].Member)
+
+		w = federationRequest(t, hub, "POST", "/api/v1/federation/members/laptop/approvals/appr-1/decide", token, map[string]string{"decision": "approve"})
+		assert.Equal(t, http.StatusBadGateway, w.Code)
+	})
+}
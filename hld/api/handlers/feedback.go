@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/feedback"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// maxFeedbackComment caps free-text feedback
+const maxFeedbackComment = 4000
+
+// FeedbackHandler collects ratings on commit suggestions and ephemeral chat
+// answers, and exports them for comparing prompt templates
+type FeedbackHandler struct {
+	store store.AIOutputStore
+}
+
+// NewFeedbackHandler creates a new feedback handler
+func NewFeedbackHandler(outputs store.AIOutputStore) *FeedbackHandler {
+	return &FeedbackHandler{store: outputs}
+}
+
+// recordOutput keeps a model answer for feedback and returns its ID, or ""
+// when it couldn't be stored; a failure never fails the answer itself
+func recordOutput(ctx context.Context, s store.AIOutputStore, output *store.AIOutput) string {
+	if s == nil {
+		return ""
+	}
+	if err := feedback.Record(ctx, s, output); err != nil {
+		slog.Warn("failed to record AI output for feedback", "kind", output.Kind, "error", err)
+		return ""
+	}
+	return output.ID
+}
+
+type feedbackRequest struct {
+	Rating  string `json:"rating"` // up or down
+	Comment string `json:"comment"`
+}
+
+// HandleGetOutput returns a recorded output with its redacted prompt and any feedback
+func (h *FeedbackHandler) HandleGetOutput(c *gin.Context) {
+	output, err := h.store.GetAIOutput(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Output not found"})
+			return
+		}
+		slog.Error("failed to get AI output", "output_id", c.Param("id"), "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get output"})
+		return
+	}
+	c.JSON(http.StatusOK, output)
+}
+
+// HandleSubmitFeedback rates an output up or down, with an optional comment.
+// Submitting again replaces the earlier feedback.
+func (h *FeedbackHandler) HandleSubmitFeedback(c *gin.Context) {
+	var req feedbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	if req.Rating != store.RatingUp && req.Rating != store.RatingDown {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "rating must be up or down"})
+		return
+	}
+	comment := strings.TrimSpace(req.Comment)
+	if len(comment) > maxFeedbackComment {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "comment is too long"})
+		return
+	}
+
+	id := c.Param("id")
+	if err := h.store.SetAIOutputFeedback(c.Request.Context(), id, req.Rating, feedback.Redact(comment)); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Output not found"})
+			return
+		}
+		slog.Error("failed to store feedback", "output_id", id, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store feedback"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// outputFilter reads kind, template, since (RFC 3339) and rated=true
+func outputFilter(c *gin.Context) (store.AIOutputFilter, bool) {
+	filter := store.AIOutputFilter{Kind: c.Query("kind"), Template: c.Query("template"), Rated: c.Query("rated") == "true"}
+	if since := c.Query("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "since must be an RFC 3339 timestamp"})
+			return filter, false
+		}
+		filter.Since = t
+	}
+	return filter, true
+}
+
+// HandleExportFeedback streams outputs as JSON Lines, one prompt/response
+// pair with its feedback per line. Accepts the same filters as the summary.
+func (h *FeedbackHandler) HandleExportFeedback(c *gin.Context) {
+	filter, ok := outputFilter(c)
+	if !ok {
+		return
+	}
+	outputs, err := h.store.ListAIOutputs(c.Request.Context(), filter)
+	if err != nil {
+		slog.Error("failed to list AI outputs", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export feedback"})
+		return
+	}
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", `attachment; filename="feedback.jsonl"`)
+	c.Status(http.StatusOK)
+	encoder := json.NewEncoder(c.Writer)
+	for _, output := range outputs {
+		if err := encoder.Encode(output); err != nil {
+			slog.Warn("feedback export interrupted", "error", err)
+			return
+		}
+	}
+}
+
+// HandleFeedbackSummary compares ratings per prompt template version
+func (h *FeedbackHandler) HandleFeedbackSummary(c *gin.Context) {
+	filter, ok := outputFilter(c)
+	if !ok {
+		return
+	}
+	outputs, err := h.store.ListAIOutputs(c.Request.Context(), filter)
+	if err != nil {
+		slog.Error("failed to list AI outputs", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to summarize feedback"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"templates": feedback.Summarize(outputs)})
+}
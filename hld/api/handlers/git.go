@@ -1,34 +1,175 @@
 package handlers
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"log/slog"
 	"net/http"
-	"os"
-	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/humanlayer/humanlayer/hld/analysis"
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/cmdstream"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/credentials"
+	"github.com/humanlayer/humanlayer/hld/depcheck"
+	"github.com/humanlayer/humanlayer/hld/diskguard"
+	"github.com/humanlayer/humanlayer/hld/gitcmd"
+	"github.com/humanlayer/humanlayer/hld/i18n"
+	"github.com/humanlayer/humanlayer/hld/injection"
+	"github.com/humanlayer/humanlayer/hld/jobs"
+	"github.com/humanlayer/humanlayer/hld/lfs"
+	"github.com/humanlayer/humanlayer/hld/llm"
+	"github.com/humanlayer/humanlayer/hld/migrationcheck"
+	"github.com/humanlayer/humanlayer/hld/owners"
+	"github.com/humanlayer/humanlayer/hld/prompts"
+	"github.com/humanlayer/humanlayer/hld/provenance"
 	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/humanlayer/humanlayer/hld/workspace"
 )
 
 // GitHandler handles git operations for sessions
 type GitHandler struct {
-	store      store.ConversationStore
-	httpClient *http.Client
+	store    store.ConversationStore
+	router   *llm.Router
+	prompts  *prompts.Set
+	locale   string
+	eventBus bus.EventBus
+	jobs     *jobs.Manager
+	// pathMappings rewrite working dirs recorded on other machines
+	pathMappings []config.PathMapping
+	// deps looks up added dependencies; nil when dependency checks are off
+	deps *depcheck.Checker
+	// depsGate checks dependencies before every commit
+	depsGate bool
+	// analysis configures the analyzers run before commits
+	analysis config.AnalysisConfig
+	// lfs sets which files status and commits flag for bypassing Git LFS
+	lfs config.LFSConfig
+	// provenance records the session behind each commit
+	provenance config.ProvenanceConfig
+	// diskGuard refuses commits that would fill the disk or grow the
+	// repository too much; nil skips the checks
+	diskGuard *diskguard.Guard
+	// credentials authenticate fetches; nil leaves it to git's own config
+	credentials *credentials.Manager
+	// commits records the commits made for activity reports; nil skips it
+	commits store.CommitStore
+	// outputs keeps generated commit messages for feedback; nil skips it
+	outputs store.AIOutputStore
+	// injections scrubs session text before it's put in a prompt; nil
+	// passes it through
+	injections *injection.Guard
+
+	// mutationLocks holds a *sync.Mutex per session so only one git mutation
+	// runs against a working tree at a time
+	mutationLocks sync.Map
 }
 
-// NewGitHandler creates a new git handler
+// NewGitHandler creates a new git handler using the default model routes and prompts
 func NewGitHandler(conversationStore store.ConversationStore) *GitHandler {
+	return NewGitHandlerWithRouter(conversationStore, llm.NewRouter(config.LLMConfig{}), prompts.Default(), "", nil)
+}
+
+// NewGitHandlerWithRouter creates a new git handler that generates commit
+// messages through the given model router and prompt templates, in locale
+// unless a request asks for another. Streamed generation publishes progress
+// on eventBus, and is unavailable when it is nil.
+func NewGitHandlerWithRouter(conversationStore store.ConversationStore, router *llm.Router, templates *prompts.Set, locale string, eventBus bus.EventBus) *GitHandler {
 	return &GitHandler{
-		store:      conversationStore,
-		httpClient: &http.Client{Timeout: 120 * time.Second},
+		store:    conversationStore,
+		router:   router,
+		prompts:  templates,
+		locale:   locale,
+		eventBus: eventBus,
+	}
+}
+
+// SetJobManager lets clients run commit message generation as a background
+// job by passing ?async=true
+func (h *GitHandler) SetJobManager(m *jobs.Manager) {
+	h.jobs = m
+}
+
+// SetRecords records the commits the handler makes and the commit messages
+// it generates
+func (h *GitHandler) SetRecords(commits store.CommitStore, outputs store.AIOutputStore) {
+	h.commits = commits
+	h.outputs = outputs
+}
+
+// SetPathMappings sets the rewrites applied to session working directories
+func (h *GitHandler) SetPathMappings(mappings []config.PathMapping) {
+	h.pathMappings = mappings
+}
+
+// SetDependencyChecker enables dependency findings on commit suggestions and
+// the dependency gate, which runs before every commit if gate is set and
+// otherwise only for commits asking for it
+func (h *GitHandler) SetDependencyChecker(checker *depcheck.Checker, gate bool) {
+	h.deps = checker
+	h.depsGate = gate
+}
+
+// SetAnalysis sets the analyzers that commits may run over their files
+func (h *GitHandler) SetAnalysis(cfg config.AnalysisConfig) {
+	h.analysis = cfg
+}
+
+// SetLFS sets the large-file limit and whether flagged files block commits
+func (h *GitHandler) SetLFS(cfg config.LFSConfig) {
+	h.lfs = cfg
+}
+
+// SetProvenance sets how commits record the session that made them
+func (h *GitHandler) SetProvenance(cfg config.ProvenanceConfig) {
+	h.provenance = cfg
+}
+
+// SetDiskGuard sets the free space and repository growth checks run before
+// commits stage anything
+func (h *GitHandler) SetDiskGuard(guard *diskguard.Guard) {
+	h.diskGuard = guard
+}
+
+// SetInjectionGuard scans conversation context and commit subjects for
+// prompt injections before they go into commit message prompts
+func (h *GitHandler) SetInjectionGuard(guard *injection.Guard) {
+	h.injections = guard
+}
+
+// largeFileSize is the size over which files LFS doesn't track are flagged,
+// or 0 when they aren't
+func (h *GitHandler) largeFileSize() int64 {
+	switch {
+	case h.lfs.LargeFileSize < 0:
+		return 0
+	case h.lfs.LargeFileSize == 0:
+		return lfs.DefaultLargeFileSize
 	}
+	return h.lfs.LargeFileSize
+}
+
+// repo returns the session's working directory on its host
+func (h *GitHandler) repo(session *store.Session) gitRepo {
+	return sessionRepo(session, h.pathMappings)
+}
+
+// sessionRepo locates a session's working directory. Path mappings only
+// apply to local directories; remote ones are used as recorded.
+func sessionRepo(session *store.Session, mappings []config.PathMapping) gitRepo {
+	if session.SSHHost != "" {
+		return gitRepo{host: workspace.ForSession(session), dir: session.WorkingDir}
+	}
+	return gitRepo{host: workspace.Local{}, dir: config.RemapPath(mappings, session.WorkingDir)}
 }
 
 // GitFile represents a file in git status
@@ -37,6 +178,24 @@ type GitFile struct {
 	Status  string `json:"status"`
 	OldPath string `json:"oldPath,omitempty"`
 	Diff    string `json:"diff,omitempty"`
+	// LFS describes the pointer change of a file Git LFS tracks, in place
+	// of its content
+	LFS *lfs.Change `json:"lfs,omitempty"`
+	// Similarity is the rename or copy score, as a percentage
+	Similarity int `json:"similarity,omitempty"`
+	// OldMode and NewMode are set when the file mode changed, such as a
+	// script becoming executable
+	OldMode string `json:"oldMode,omitempty"`
+	NewMode string `json:"newMode,omitempty"`
+	// Submodule is set when the path is a submodule
+	Submodule *GitSubmoduleState `json:"submodule,omitempty"`
+}
+
+// GitSubmoduleState is what changed inside a submodule
+type GitSubmoduleState struct {
+	CommitChanged    bool `json:"commitChanged"`
+	TrackedChanges   bool `json:"trackedChanges"`
+	UntrackedChanges bool `json:"untrackedChanges"`
 }
 
 // GitStatusResponse represents the response for git status
@@ -48,6 +207,11 @@ type GitStatusResponse struct {
 	HasChanges bool      `json:"hasChanges"`
 	Ahead      int       `json:"ahead,omitempty"`
 	Behind     int       `json:"behind,omitempty"`
+	// LFS is set when the repository's .gitattributes use Git LFS
+	LFS bool `json:"lfs,omitempty"`
+	// Files that would be committed as regular objects though they're
+	// large or LFS tracks them
+	LFSWarnings []lfs.Warning `json:"lfsWarnings,omitempty"`
 }
 
 // FileAction represents a file modification from the conversation
@@ -73,6 +237,17 @@ type ConversationContext struct {
 type GenerateCommitMessageRequest struct {
 	ConversationContext *ConversationContext `json:"conversationContext,omitempty"`
 	IncludeUntracked    bool                 `json:"includeUntracked"`
+	// Locale for the generated message (BCP 47, e.g. "fr"); defaults to
+	// Accept-Language, then the daemon locale
+	Locale string `json:"locale,omitempty"`
+	// Stream returns a request ID immediately and delivers progress and the
+	// result as commit_message_progress events
+	Stream bool `json:"stream,omitempty"`
+}
+
+// GenerateCommitMessageAccepted is the response to a streamed generation request
+type GenerateCommitMessageAccepted struct {
+	RequestID string `json:"requestId"`
 }
 
 // CommitMessage represents a single commit message
@@ -94,12 +269,25 @@ type CommitSuggestion struct {
 // GenerateCommitMessageResponse represents the response for commit message generation
 type GenerateCommitMessageResponse struct {
 	Suggestion CommitSuggestion `json:"suggestion"`
+	// Pass to POST /outputs/{id}/feedback to rate the suggestion
+	OutputID   string `json:"outputId,omitempty"`
 	GitContext struct {
 		RecentCommits    []string `json:"recentCommits"`
 		ChangedFileCount int      `json:"changedFileCount"`
 		AdditionsCount   int      `json:"additionsCount"`
 		DeletionsCount   int      `json:"deletionsCount"`
 	} `json:"gitContext"`
+	// Risky statements in changed database migrations
+	MigrationWarnings []migrationcheck.Warning `json:"migrationWarnings,omitempty"`
+	// Licenses and vulnerabilities of dependencies the changes add, when
+	// dependency checks are on
+	DependencyFindings []depcheck.Finding `json:"dependencyFindings,omitempty"`
+}
+
+// ReviewersResponse suggests who should review a session's changes
+type ReviewersResponse struct {
+	Base      string            `json:"base"`
+	Reviewers []owners.Reviewer `json:"reviewers"`
 }
 
 // CommitRequest represents a request to create commits
@@ -108,6 +296,20 @@ type CommitRequest struct {
 	CreateBranch   string          `json:"createBranch,omitempty"`
 	StageUntracked bool            `json:"stageUntracked"`
 	StageFiles     []string        `json:"stageFiles,omitempty"`
+	// Force commits even while the session is running; the override is
+	// recorded in the session's conversation
+	Force bool `json:"force,omitempty"`
+	// CheckDependencies refuses the commit if a dependency it adds has a
+	// known vulnerability or a denied license
+	CheckDependencies bool `json:"checkDependencies,omitempty"`
+	// Analyze runs the repository's analyzers over the files being committed
+	Analyze bool `json:"analyze,omitempty"`
+	// Format runs the repository's formatters over the files being
+	// committed first, committing their changes
+	Format bool `json:"format,omitempty"`
+	// OperationID tags the command_output events of the formatters,
+	// analyzers and git hooks the commit runs. One is generated if empty.
+	OperationID string `json:"operationId,omitempty"`
 }
 
 // CommitResponse represents the response from creating commits
@@ -116,6 +318,24 @@ type CommitResponse struct {
 	CommitHashes  []string `json:"commitHashes"`
 	BranchCreated string   `json:"branchCreated,omitempty"`
 	Error         string   `json:"error,omitempty"`
+	// OperationID tags the command output streamed while committing
+	OperationID string `json:"operationId"`
+	// Files committed, or refused, as regular objects that belong in Git LFS
+	LFSWarnings []lfs.Warning `json:"lfsWarnings,omitempty"`
+	// Findings that failed the dependency gate
+	DependencyFindings []depcheck.Finding `json:"dependencyFindings,omitempty"`
+	// Formatter results for the files being committed
+	Formatting []analysis.FormatResult `json:"formatting,omitempty"`
+	// Analyzer results for the files being committed
+	Analysis []analysis.Result `json:"analysis,omitempty"`
+	// Provenance recorded with each commit, when configured
+	Provenance []provenance.Record `json:"provenance,omitempty"`
+}
+
+// HandleGetGitMetrics reports how often each git subcommand has run, how
+// long it took and how many runs failed or timed out
+func (h *GitHandler) HandleGetGitMetrics(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"commands": gitcmd.Metrics()})
 }
 
 // HandleGetGitStatus returns git status for a session's working directory
@@ -130,28 +350,92 @@ func (h *GitHandler) HandleGetGitStatus(c *gin.Context) {
 		return
 	}
 
-	if session.WorkingDir == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Session has no working directory"})
+	repo, ok := h.selectRepo(c, session)
+	if !ok {
 		return
 	}
 
 	// Check if it's a git repository
-	if !isGitRepo(session.WorkingDir) {
+	if !isGitRepo(repo) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Not a git repository"})
 		return
 	}
 
-	status, err := getGitStatus(session.WorkingDir)
+	status, err := getGitStatus(repo)
 	if err != nil {
 		slog.Error("failed to get git status", "session_id", sessionID, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get git status"})
 		return
 	}
+	annotateLFS(c.Request.Context(), repo, status, h.largeFileSize())
 
 	c.JSON(http.StatusOK, status)
 }
 
-// HandleGenerateCommitMessage generates a commit message using Claude
+// HandleGetReviewers suggests reviewers for the session's changes since base
+// (default HEAD) from CODEOWNERS and blame of the changed lines
+func (h *GitHandler) HandleGetReviewers(c *gin.Context) {
+	sessionID := c.Param("id")
+	session, err := h.store.GetSession(c.Request.Context(), sessionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+	repo, ok := h.selectRepo(c, session)
+	if !ok {
+		return
+	}
+	if !isGitRepo(repo) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Not a git repository"})
+		return
+	}
+	base := c.DefaultQuery("base", "HEAD")
+	if strings.HasPrefix(base, "-") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid base"})
+		return
+	}
+
+	status, err := getGitStatus(repo)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to get git status: %v", err)})
+		return
+	}
+	files := changedPaths(repo, status)
+	if base != "HEAD" {
+		committed, err := repo.run("diff", "--name-only", "--diff-filter=d", base, "HEAD")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unknown base %q", base)})
+			return
+		}
+		seen := make(map[string]bool, len(files))
+		for _, file := range files {
+			seen[file] = true
+		}
+		for _, file := range strings.Split(committed, "\n") {
+			if file != "" && !seen[file] {
+				files = append(files, file)
+			}
+		}
+	}
+
+	// The person committing needn't review their own change
+	email, _ := repo.run("config", "user.email")
+	reviewers, err := owners.Suggest(c.Request.Context(), repo.host, repo.dir, base, files, []string{email})
+	if err != nil && reviewers == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		slog.Warn("failed to blame changes for reviewers", "session_id", sessionID, "error", err)
+	}
+	c.JSON(http.StatusOK, ReviewersResponse{Base: base, Reviewers: reviewers})
+}
+
+// HandleGenerateCommitMessage generates a commit message using Claude. With
+// ?async=true it runs as a job; with stream set it responds 202 with a request
+// ID straight away and reports progress, then the result, as
+// commit_message_progress events. With ?repo=all it plans commits for each
+// of the session's repositories with changes.
 func (h *GitHandler) HandleGenerateCommitMessage(c *gin.Context) {
 	sessionID := c.Param("id")
 
@@ -168,49 +452,249 @@ func (h *GitHandler) HandleGenerateCommitMessage(c *gin.Context) {
 		return
 	}
 
-	if session.WorkingDir == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Session has no working directory"})
+	locale := i18n.Resolve(req.Locale, c.GetHeader("Accept-Language"), h.locale)
+
+	if c.Query("repo") == RepoAll {
+		h.generateCommitPlans(c, session, req, locale)
 		return
 	}
 
-	// Get git status and diff
-	status, err := getGitStatus(session.WorkingDir)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get git status"})
+	repo, ok := h.selectRepo(c, session)
+	if !ok {
 		return
 	}
 
-	if !status.HasChanges {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "No changes to commit"})
+	if wantsJob(c, h.jobs) {
+		submitJob(c, h.jobs, string(llm.TaskCommitMessage), sessionID, func(ctx context.Context) (any, error) {
+			return h.generateCommitMessage(ctx, sessionID, repo, req, locale, nil)
+		})
 		return
 	}
 
+	if !req.Stream {
+		response, err := h.generateCommitMessage(c.Request.Context(), sessionID, repo, req, locale, nil)
+		if err != nil {
+			code, message := commitMessageError(err)
+			c.JSON(code, gin.H{"error": message})
+			return
+		}
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	if h.eventBus == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Streaming is not available"})
+		return
+	}
+
+	requestID := c.GetString("request-id")
+	if requestID == "" {
+		requestID = uuid.New().String()
+	}
+	publish := func(stage string, data map[string]interface{}) {
+		if data == nil {
+			data = map[string]interface{}{}
+		}
+		data["request_id"] = requestID
+		data["session_id"] = sessionID
+		data["stage"] = stage
+		h.eventBus.Publish(bus.Event{Type: bus.EventCommitMessageProgress, Data: data})
+	}
+
+	go func() {
+		// The HTTP request is already finished, so generation gets its own deadline
+		ctx, cancel := context.WithTimeout(context.Background(), commitMessageStreamTimeout)
+		defer cancel()
+
+		response, err := h.generateCommitMessage(ctx, sessionID, repo, req, locale, publish)
+		if err != nil {
+			code, message := commitMessageError(err)
+			publish(CommitProgressFailed, map[string]interface{}{"status": code, "error": message})
+			return
+		}
+		publish(CommitProgressCompleted, map[string]interface{}{"result": response})
+	}()
+
+	c.JSON(http.StatusAccepted, GenerateCommitMessageAccepted{RequestID: requestID})
+}
+
+// Stages reported by streamed commit message generation
+const (
+	CommitProgressContextGathered = "context_gathered"
+	CommitProgressDiffSummarized  = "diff_summarized"
+	CommitProgressFilesProcessed  = "files_processed"
+	CommitProgressDrafting        = "drafting"
+	CommitProgressCompleted       = "completed"
+	CommitProgressFailed          = "failed"
+)
+
+const commitMessageStreamTimeout = 5 * time.Minute
+
+// maxFileProgressEvents caps files_processed events for very large change sets
+const maxFileProgressEvents = 20
+
+var errNoChanges = errors.New("no changes to commit")
+
+// commitMessageError maps a generation failure to an HTTP status and message
+func commitMessageError(err error) (int, string) {
+	switch {
+	case errors.Is(err, errNoChanges):
+		return http.StatusBadRequest, "No changes to commit"
+	case errors.Is(err, llm.ErrInvalidJSON):
+		// The models answered, but never with a usable suggestion
+		return http.StatusBadGateway, fmt.Sprintf("Failed to generate commit message: %v", err)
+	default:
+		return http.StatusInternalServerError, fmt.Sprintf("Failed to generate commit message: %v", err)
+	}
+}
+
+// generateCommitMessage gathers git context for repo and asks the routed
+// model for a suggestion, calling progress (if set) as each stage completes
+func (h *GitHandler) generateCommitMessage(ctx context.Context, sessionID string, repo gitRepo, req GenerateCommitMessageRequest, locale string, progress func(stage string, data map[string]interface{})) (*GenerateCommitMessageResponse, error) {
+	if progress == nil {
+		progress = func(string, map[string]interface{}) {}
+	}
+
+	// Get git status and recent commits for style matching
+	status, err := getGitStatus(repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get git status: %w", err)
+	}
+	if !status.HasChanges {
+		return nil, errNoChanges
+	}
+	recentCommits := getRecentCommits(repo, 5)
+	changedFiles := len(status.Staged) + len(status.Unstaged) + len(status.Untracked)
+	progress(CommitProgressContextGathered, map[string]interface{}{
+		"branch":        status.Branch,
+		"changed_files": changedFiles,
+	})
+
 	// Get git diff
-	diff, additions, deletions := getGitDiff(session.WorkingDir)
+	diff, files := getGitDiffStat(repo)
+	progress(CommitProgressDiffSummarized, map[string]interface{}{
+		"files_total": len(files),
+	})
 
-	// Get recent commits for style matching
-	recentCommits := getRecentCommits(session.WorkingDir, 5)
+	var additions, deletions int
+	step := max(1, len(files)/maxFileProgressEvents)
+	for i, file := range files {
+		additions += file.additions
+		deletions += file.deletions
+		if done := i + 1; done%step == 0 || done == len(files) {
+			progress(CommitProgressFilesProcessed, map[string]interface{}{
+				"files_processed": done,
+				"files_total":     len(files),
+			})
+		}
+	}
 
-	// Build prompt for Claude
-	prompt := buildCommitMessagePrompt(req.ConversationContext, status, diff, recentCommits)
+	// Build prompt from the commit-message template
+	prompt, err := buildCommitMessagePrompt(h.prompts,
+		h.guardConversationContext(ctx, sessionID, req.ConversationContext), status,
+		h.injections.Check(ctx, sessionID, string(llm.TaskCommitMessage), "diff_stat", diff),
+		h.injections.CheckAll(ctx, sessionID, string(llm.TaskCommitMessage), "recent_commits", recentCommits), locale)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build prompt: %w", err)
+	}
 
-	// Call Claude API
-	suggestion, err := h.generateWithClaude(c, prompt)
+	// Generate with the model routed for commit messages
+	progress(CommitProgressDrafting, nil)
+	suggestion, outputID, err := h.generateCommitSuggestion(ctx, sessionID, prompt)
 	if err != nil {
 		slog.Error("failed to generate commit message", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate commit message"})
-		return
+		return nil, err
 	}
 
-	response := GenerateCommitMessageResponse{
+	response := &GenerateCommitMessageResponse{
 		Suggestion: *suggestion,
+		OutputID:   outputID,
 	}
 	response.GitContext.RecentCommits = recentCommits
-	response.GitContext.ChangedFileCount = len(status.Staged) + len(status.Unstaged) + len(status.Untracked)
+	response.GitContext.ChangedFileCount = changedFiles
 	response.GitContext.AdditionsCount = additions
 	response.GitContext.DeletionsCount = deletions
+	paths := changedPaths(repo, status)
+	response.MigrationWarnings = checkMigrations(ctx, repo, paths)
+	if h.deps != nil {
+		response.DependencyFindings = h.deps.Check(ctx, changedDependencies(ctx, repo, paths))
+	}
+	return response, nil
+}
 
-	c.JSON(http.StatusOK, response)
+// maxMigrationSize caps how much of a migration file is read for checking
+const maxMigrationSize = 1 << 20
+
+// changedPaths lists the files status reports as changed, other than
+// deletions, each once
+func changedPaths(repo gitRepo, status *GitStatusResponse) []string {
+	var paths []string
+	for _, file := range append(status.Staged, status.Unstaged...) {
+		if file.Status != "deleted" {
+			paths = append(paths, file.Path)
+		}
+	}
+	// Porcelain status collapses new directories to one entry, so list
+	// untracked files individually
+	if len(status.Untracked) > 0 {
+		if output, err := repo.run("ls-files", "--others", "--exclude-standard", "-z"); err == nil {
+			paths = append(paths, strings.Split(strings.TrimRight(output, "\x00"), "\x00")...)
+		}
+	}
+
+	var unique []string
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		if path != "" && !seen[path] {
+			seen[path] = true
+			unique = append(unique, path)
+		}
+	}
+	return unique
+}
+
+// checkMigrations runs the migration checks over changed migration files
+func checkMigrations(ctx context.Context, repo gitRepo, paths []string) []migrationcheck.Warning {
+	exists := func(path string) bool {
+		_, err := repo.host.ReadFile(ctx, filepath.Join(repo.dir, path), maxMigrationSize)
+		return err == nil || errors.Is(err, workspace.ErrTooLarge)
+	}
+
+	var warnings []migrationcheck.Warning
+	for _, path := range paths {
+		if !migrationcheck.IsMigration(path) {
+			continue
+		}
+		content, err := repo.host.ReadFile(ctx, filepath.Join(repo.dir, path), maxMigrationSize)
+		if err != nil {
+			slog.Debug("skipping migration check", "path", path, "error", err)
+			continue
+		}
+		warnings = append(warnings, migrationcheck.CheckFile(path, string(content), exists)...)
+	}
+	return warnings
+}
+
+// maxManifestSize caps how much of a dependency manifest is read
+const maxManifestSize = 4 << 20
+
+// changedDependencies diffs changed manifests in the working tree against HEAD
+func changedDependencies(ctx context.Context, repo gitRepo, paths []string) []depcheck.Dependency {
+	var deps []depcheck.Dependency
+	for _, path := range paths {
+		if !depcheck.IsManifest(path) {
+			continue
+		}
+		after, err := repo.host.ReadFile(ctx, filepath.Join(repo.dir, path), maxManifestSize)
+		if err != nil {
+			slog.Debug("skipping dependency check", "path", path, "error", err)
+			continue
+		}
+		// A new file, or a repository without commits, has no HEAD version
+		before, _ := repo.run("show", "HEAD:"+filepath.ToSlash(path))
+		deps = append(deps, depcheck.Diff(path, []byte(before), after)...)
+	}
+	return deps
 }
 
 // HandleCommitChanges executes git commits
@@ -235,17 +719,44 @@ func (h *GitHandler) HandleCommitChanges(c *gin.Context) {
 		return
 	}
 
-	if session.WorkingDir == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Session has no working directory"})
+	repo, ok := h.selectRepo(c, session)
+	if !ok {
+		return
+	}
+
+	unlock := h.lockForMutation(c, session, req.Force, "commit")
+	if unlock == nil {
 		return
 	}
+	defer unlock()
 
 	var response CommitResponse
 	response.Success = true
+	response.OperationID = req.OperationID
+	if response.OperationID == "" {
+		response.OperationID = uuid.New().String()
+	}
+	op := cmdstream.New(h.eventBus, response.OperationID, session.ID)
+
+	if h.diskGuard != nil {
+		if err := h.diskGuard.Check(c.Request.Context(), diskguard.Write{
+			Operation:  "commit",
+			SessionID:  session.ID,
+			Host:       repo.host,
+			Dir:        repo.dir,
+			Size:       commitGrowth(c.Request.Context(), repo, req),
+			Repository: true,
+		}); err != nil {
+			response.Success = false
+			response.Error = err.Error()
+			c.JSON(http.StatusInsufficientStorage, response)
+			return
+		}
+	}
 
 	// Create branch if requested
 	if req.CreateBranch != "" {
-		if err := createBranch(session.WorkingDir, req.CreateBranch); err != nil {
+		if err := createBranch(repo, req.CreateBranch); err != nil {
 			response.Success = false
 			response.Error = fmt.Sprintf("Failed to create branch: %v", err)
 			c.JSON(http.StatusInternalServerError, response)
@@ -256,14 +767,14 @@ func (h *GitHandler) HandleCommitChanges(c *gin.Context) {
 
 	// Stage files if requested
 	if req.StageUntracked {
-		if err := stageAllChanges(session.WorkingDir); err != nil {
+		if err := stageAllChanges(repo); err != nil {
 			response.Success = false
 			response.Error = fmt.Sprintf("Failed to stage changes: %v", err)
 			c.JSON(http.StatusInternalServerError, response)
 			return
 		}
 	} else if len(req.StageFiles) > 0 {
-		if err := stageFiles(session.WorkingDir, req.StageFiles); err != nil {
+		if err := stageFiles(repo, req.StageFiles); err != nil {
 			response.Success = false
 			response.Error = fmt.Sprintf("Failed to stage files: %v", err)
 			c.JSON(http.StatusInternalServerError, response)
@@ -271,6 +782,52 @@ func (h *GitHandler) HandleCommitChanges(c *gin.Context) {
 		}
 	}
 
+	formatters := analysis.FormattersFor(h.analysis, repo.dir)
+	if len(formatters) > 0 && (h.analysis.Format || req.Format) {
+		results, err := formatCommitFiles(c.Request.Context(), repo, req, formatters, op)
+		response.Formatting = results
+		if err != nil {
+			response.Success = false
+			response.Error = fmt.Sprintf("Failed to stage formatting changes: %v", err)
+			c.JSON(http.StatusInternalServerError, response)
+			return
+		}
+	}
+
+	// Gates see the staged files and those the commits will stage
+	paths := commitPaths(repo, req)
+	checkDeps := h.deps != nil && (h.depsGate || req.CheckDependencies)
+	analyzers := analysis.ForRepository(h.analysis, repo.dir)
+	analyze := len(analyzers) > 0 && (h.analysis.Gate || req.Analyze)
+
+	response.LFSWarnings = commitLFSWarnings(c.Request.Context(), repo, paths, h.largeFileSize())
+	if h.lfs.Block && len(response.LFSWarnings) > 0 {
+		response.Success = false
+		response.Error = fmt.Sprintf("%d files would be committed as regular git objects instead of through Git LFS", len(response.LFSWarnings))
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	if analyze {
+		response.Analysis = analysis.Run(c.Request.Context(), repo.host, repo.dir, analyzers, paths, op)
+		if h.analysis.BlockOnError && analysis.Blocking(response.Analysis) {
+			response.Success = false
+			response.Error = "Static analysis found errors in the files being committed"
+			c.JSON(http.StatusUnprocessableEntity, response)
+			return
+		}
+	}
+
+	if checkDeps {
+		if blocking := h.dependencyGate(c.Request.Context(), repo, paths); len(blocking) > 0 {
+			response.Success = false
+			response.Error = fmt.Sprintf("Dependency check failed: %d dependencies the commit adds or upgrades have known vulnerabilities or denied licenses", len(blocking))
+			response.DependencyFindings = blocking
+			c.JSON(http.StatusUnprocessableEntity, response)
+			return
+		}
+	}
+
 	// Create commits
 	for _, commit := range req.Commits {
 		// Build commit message
@@ -284,7 +841,7 @@ func (h *GitHandler) HandleCommitChanges(c *gin.Context) {
 
 		// If specific files are provided for this commit, stage them
 		if len(commit.Files) > 0 {
-			if err := stageFiles(session.WorkingDir, commit.Files); err != nil {
+			if err := stageFiles(repo, commit.Files); err != nil {
 				response.Success = false
 				response.Error = fmt.Sprintf("Failed to stage files for commit: %v", err)
 				c.JSON(http.StatusInternalServerError, response)
@@ -292,8 +849,21 @@ func (h *GitHandler) HandleCommitChanges(c *gin.Context) {
 			}
 		}
 
+		var record provenance.Record
+		if h.provenance.Mode != "" {
+			if record, err = h.signProvenance(repo, session); err != nil {
+				response.Success = false
+				response.Error = fmt.Sprintf("Failed to record provenance: %v", err)
+				c.JSON(http.StatusInternalServerError, response)
+				return
+			}
+			if h.provenance.Mode == config.ProvenanceTrailer {
+				message += "\n\n" + strings.TrimSuffix(record.Trailers(), "\n")
+			}
+		}
+
 		// Create commit
-		hash, err := createCommit(session.WorkingDir, message)
+		hash, err := createCommit(repo, message, op.Command("git commit"))
 		if err != nil {
 			response.Success = false
 			response.Error = fmt.Sprintf("Failed to create commit: %v", err)
@@ -301,126 +871,471 @@ func (h *GitHandler) HandleCommitChanges(c *gin.Context) {
 			return
 		}
 		response.CommitHashes = append(response.CommitHashes, hash)
+		h.recordCommit(c.Request.Context(), repo, session)
+
+		if h.provenance.Mode != "" {
+			if h.provenance.Mode == config.ProvenanceNote {
+				if _, err := repo.run("notes", "--ref", h.notesRef(), "add", "-f", "-m", record.Trailers(), "HEAD"); err != nil {
+					response.Success = false
+					response.Error = fmt.Sprintf("Failed to record provenance note: %v", err)
+					c.JSON(http.StatusInternalServerError, response)
+					return
+				}
+			}
+			response.Provenance = append(response.Provenance, record)
+		}
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
-// Helper functions
-
-func isGitRepo(dir string) bool {
-	gitDir := filepath.Join(dir, ".git")
-	info, err := os.Stat(gitDir)
+// recordCommit stores the commit just made at HEAD for repository activity
+// reports. Failing to record it doesn't fail the commit.
+func (h *GitHandler) recordCommit(ctx context.Context, repo gitRepo, session *store.Session) {
+	if h.commits == nil {
+		return
+	}
+	hash, err := repo.run("rev-parse", "HEAD")
 	if err != nil {
-		return false
+		slog.Warn("failed to record commit", "session_id", session.ID, "error", err)
+		return
 	}
-	return info.IsDir()
-}
-
-func runGitCommand(dir string, args ...string) (string, error) {
-	cmd := exec.Command("git", args...)
-	cmd.Dir = dir
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	err := cmd.Run()
+	root, err := repo.run("rev-parse", "--show-toplevel")
 	if err != nil {
-		return "", fmt.Errorf("%s: %s", err, stderr.String())
+		root = repo.dir
+	}
+	commit := &store.SessionCommit{SessionID: session.ID, Repository: root, Hash: hash}
+	// Binary files count no lines
+	numstat, _ := repo.run("show", "--numstat", "--format=", "HEAD")
+	for _, line := range strings.Split(numstat, "\n") {
+		var additions, deletions int
+		if _, err := fmt.Sscanf(line, "%d\t%d", &additions, &deletions); err == nil {
+			commit.Additions += additions
+			commit.Deletions += deletions
+		}
+	}
+	if err := h.commits.CreateSessionCommit(ctx, commit); err != nil {
+		slog.Warn("failed to record commit", "session_id", session.ID, "error", err)
 	}
-	return strings.TrimSpace(stdout.String()), nil
 }
 
-func getGitStatus(dir string) (*GitStatusResponse, error) {
-	status := &GitStatusResponse{
-		Staged:    []GitFile{},
-		Unstaged:  []GitFile{},
-		Untracked: []GitFile{},
+// commitPaths lists the staged files and the files the commits will stage
+func commitPaths(repo gitRepo, req CommitRequest) []string {
+	var paths []string
+	if status, err := getGitStatus(repo); err == nil {
+		for _, file := range status.Staged {
+			if file.Status != "deleted" {
+				paths = append(paths, file.Path)
+			}
+		}
+	}
+	seen := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		seen[path] = true
+	}
+	for _, commit := range req.Commits {
+		for _, path := range commit.Files {
+			if !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
 	}
+	return paths
+}
 
-	// Get current branch
-	branch, err := runGitCommand(dir, "rev-parse", "--abbrev-ref", "HEAD")
+// commitGrowth estimates the bytes a commit adds to the repository: the
+// staged files, and the working tree files it will stage in their place
+func commitGrowth(ctx context.Context, repo gitRepo, req CommitRequest) int64 {
+	status, err := getGitStatus(repo)
 	if err != nil {
-		return nil, err
+		return 0
+	}
+	var toStage []string
+	if req.StageUntracked {
+		for _, file := range status.Unstaged {
+			if file.Status != "deleted" {
+				toStage = append(toStage, file.Path)
+			}
+		}
+		// Status lists untracked directories rather than their files
+		if output, err := repo.run("ls-files", "-z", "--others", "--exclude-standard"); err == nil && output != "" {
+			toStage = append(toStage, strings.Split(output, "\x00")...)
+		}
+	} else {
+		toStage = append(toStage, req.StageFiles...)
+	}
+	for _, commit := range req.Commits {
+		toStage = append(toStage, commit.Files...)
+	}
+	staging := make(map[string]bool, len(toStage))
+	for _, path := range toStage {
+		staging[path] = true
 	}
-	status.Branch = branch
 
-	// Get ahead/behind counts
-	if upstream, _ := runGitCommand(dir, "rev-parse", "--abbrev-ref", "@{upstream}"); upstream != "" {
-		if ahead, _ := runGitCommand(dir, "rev-list", "--count", "@{upstream}..HEAD"); ahead != "" {
-			fmt.Sscanf(ahead, "%d", &status.Ahead)
+	var growth int64
+	for _, size := range lfs.FileSizes(ctx, repo.host, repo.dir, toStage) {
+		growth += size
+	}
+	var staged []string
+	for _, file := range status.Staged {
+		if file.Status != "deleted" && !staging[file.Path] {
+			staged = append(staged, ":"+file.Path)
 		}
-		if behind, _ := runGitCommand(dir, "rev-list", "--count", "HEAD..@{upstream}"); behind != "" {
-			fmt.Sscanf(behind, "%d", &status.Behind)
+	}
+	if sizes, err := lfs.ObjectSizes(ctx, repo.host, repo.dir, staged); err == nil {
+		for _, size := range sizes {
+			growth += size
 		}
 	}
+	return growth
+}
 
-	// Get porcelain status
-	output, err := runGitCommand(dir, "status", "--porcelain", "-z")
+// formatCommitFiles runs the formatters over the files being committed and
+// stages what they change in files that were already staged; the commits
+// stage their own files. Partially staged files are left alone, since
+// staging the formatting would stage their unstaged changes too.
+func formatCommitFiles(ctx context.Context, repo gitRepo, req CommitRequest, formatters []config.FormatterConfig, op *cmdstream.Operation) ([]analysis.FormatResult, error) {
+	status, err := getGitStatus(repo)
 	if err != nil {
 		return nil, err
 	}
+	unstaged := make(map[string]bool, len(status.Unstaged))
+	for _, file := range status.Unstaged {
+		unstaged[file.Path] = true
+	}
+	committed := make(map[string]bool)
+	for _, commit := range req.Commits {
+		for _, path := range commit.Files {
+			committed[path] = true
+		}
+	}
+	staged := make(map[string]bool)
+	var files []string
+	for _, file := range status.Staged {
+		if file.Status != "deleted" && (!unstaged[file.Path] || committed[file.Path]) {
+			staged[file.Path] = true
+			files = append(files, file.Path)
+		}
+	}
+	for path := range committed {
+		if !staged[path] {
+			files = append(files, path)
+		}
+	}
+	sort.Strings(files)
+
+	results := analysis.Format(ctx, repo.host, repo.dir, formatters, files, op)
+	var restage []string
+	for _, result := range results {
+		for _, path := range result.Changed {
+			if staged[path] {
+				restage = append(restage, path)
+			}
+		}
+	}
+	if len(restage) > 0 {
+		if err := stageFiles(repo, restage); err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
 
-	if output == "" {
-		return status, nil
+// annotateLFS adds the pointer changes of files LFS tracks to status, and
+// flags files that would be committed as regular objects though they're
+// large or LFS tracks them
+func annotateLFS(ctx context.Context, repo gitRepo, status *GitStatusResponse, limit int64) {
+	status.LFS = lfs.Enabled(ctx, repo.host, repo.dir)
+	checked := changedPaths(repo, status)
+	paths := append([]string{}, checked...)
+	for _, files := range [][]GitFile{status.Staged, status.Unstaged} {
+		for _, file := range files {
+			if file.Status == "deleted" {
+				paths = append(paths, file.Path)
+			}
+		}
+	}
+	tracked, err := lfs.Tracked(ctx, repo.host, repo.dir, paths)
+	if err != nil {
+		slog.Debug("skipping LFS checks", "dir", repo.dir, "error", err)
+		return
 	}
 
-	// Parse porcelain output (NUL-separated)
-	entries := strings.Split(output, "\x00")
-	for i := 0; i < len(entries); i++ {
-		entry := entries[i]
-		if len(entry) < 3 {
-			continue
+	// Staged content is read from the index, the rest from the working tree
+	staged := make(map[string]bool, len(status.Staged))
+	objects := []string{}
+	for _, file := range status.Staged {
+		if file.Status != "deleted" {
+			staged[file.Path] = true
+			objects = append(objects, ":"+file.Path)
+		}
+		if tracked[file.Path] {
+			objects = append(objects, "HEAD:"+oldPath(file))
+		}
+	}
+	var working []string
+	for _, file := range status.Unstaged {
+		if tracked[file.Path] && !staged[file.Path] {
+			objects = append(objects, ":"+file.Path)
+		}
+	}
+	for _, path := range checked {
+		if !staged[path] || tracked[path] {
+			working = append(working, path)
 		}
+	}
+	objectSizes, err := lfs.ObjectSizes(ctx, repo.host, repo.dir, objects)
+	if err != nil {
+		slog.Debug("skipping LFS checks", "dir", repo.dir, "error", err)
+		return
+	}
+	fileSizes := lfs.FileSizes(ctx, repo.host, repo.dir, working)
 
-		indexStatus := entry[0]
-		workTreeStatus := entry[1]
-		path := entry[3:]
+	pointer := func(object string) *lfs.Pointer {
+		size, ok := objectSizes[object]
+		if !ok {
+			return nil
+		}
+		if p, ok := lfs.ReadPointer(ctx, repo.host, repo.dir, object, size); ok {
+			return &p
+		}
+		return &lfs.Pointer{Size: size}
+	}
+	notPointer := make(map[string]bool)
+	for i, file := range status.Staged {
+		if !tracked[file.Path] {
+			continue
+		}
+		change := &lfs.Change{Old: pointer("HEAD:" + oldPath(file)), New: pointer(":" + file.Path)}
+		if change.New != nil && change.New.OID == "" {
+			change.NotPointer = true
+			notPointer[file.Path] = true
+		}
+		status.Staged[i].LFS = change
+	}
+	for i, file := range status.Unstaged {
+		if !tracked[file.Path] {
+			continue
+		}
+		change := &lfs.Change{Old: pointer(":" + file.Path)}
+		if size, ok := fileSizes[file.Path]; ok {
+			change.New = &lfs.Pointer{Size: size}
+		}
+		status.Unstaged[i].LFS = change
+	}
+	for i, file := range status.Untracked {
+		if size, ok := fileSizes[file.Path]; ok && tracked[file.Path] {
+			status.Untracked[i].LFS = &lfs.Change{New: &lfs.Pointer{Size: size}}
+		}
+	}
 
-		file := GitFile{Path: path}
+	sizes := make(map[string]int64, len(checked))
+	for _, path := range checked {
+		if size, ok := objectSizes[":"+path]; ok && staged[path] {
+			sizes[path] = size
+		} else if size, ok := fileSizes[path]; ok {
+			sizes[path] = size
+		}
+	}
+	status.LFSWarnings = lfs.Check(tracked, notPointer, sizes, checked, limit)
+}
 
-		// Handle renamed files
-		if indexStatus == 'R' || workTreeStatus == 'R' {
-			i++
-			if i < len(entries) {
-				file.OldPath = entries[i]
-			}
-			file.Status = "renamed"
-		}
-
-		// Staged changes
-		if indexStatus != ' ' && indexStatus != '?' {
-			stagedFile := file
-			switch indexStatus {
-			case 'A':
-				stagedFile.Status = "added"
-			case 'M':
-				stagedFile.Status = "modified"
-			case 'D':
-				stagedFile.Status = "deleted"
-			case 'R':
-				stagedFile.Status = "renamed"
-			case 'C':
-				stagedFile.Status = "copied"
-			}
-			status.Staged = append(status.Staged, stagedFile)
+// commitLFSWarnings flags the files being committed that would be stored as
+// regular objects though they're large or LFS tracks them. Staged files
+// are checked as staged; files the commits will stage, as they are now.
+func commitLFSWarnings(ctx context.Context, repo gitRepo, paths []string, limit int64) []lfs.Warning {
+	if len(paths) == 0 {
+		return nil
+	}
+	tracked, err := lfs.Tracked(ctx, repo.host, repo.dir, paths)
+	if err != nil {
+		slog.Debug("skipping LFS checks", "dir", repo.dir, "error", err)
+		return nil
+	}
+	objects := make([]string, len(paths))
+	for i, path := range paths {
+		objects[i] = ":" + path
+	}
+	objectSizes, err := lfs.ObjectSizes(ctx, repo.host, repo.dir, objects)
+	if err != nil {
+		slog.Debug("skipping LFS checks", "dir", repo.dir, "error", err)
+		return nil
+	}
+	stagedFiles := make(map[string]bool)
+	if output, err := repo.run("diff", "--cached", "--name-only", "-z"); err == nil {
+		for _, path := range strings.Split(output, "\x00") {
+			stagedFiles[path] = true
 		}
+	}
 
-		// Unstaged changes
-		if workTreeStatus != ' ' && workTreeStatus != '?' {
-			unstagedFile := file
-			switch workTreeStatus {
-			case 'M':
-				unstagedFile.Status = "modified"
-			case 'D':
-				unstagedFile.Status = "deleted"
+	sizes := make(map[string]int64, len(paths))
+	notPointer := make(map[string]bool)
+	var unstaged []string
+	for _, path := range paths {
+		size, ok := objectSizes[":"+path]
+		if !ok || !stagedFiles[path] {
+			unstaged = append(unstaged, path)
+			continue
+		}
+		sizes[path] = size
+		if tracked[path] {
+			if _, isPointer := lfs.ReadPointer(ctx, repo.host, repo.dir, ":"+path, size); !isPointer {
+				notPointer[path] = true
 			}
-			status.Unstaged = append(status.Unstaged, unstagedFile)
 		}
+	}
+	for path, size := range lfs.FileSizes(ctx, repo.host, repo.dir, unstaged) {
+		sizes[path] = size
+	}
+	return lfs.Check(tracked, notPointer, sizes, paths, limit)
+}
+
+// oldPath is where a file was before a rename
+func oldPath(file GitFile) string {
+	if file.OldPath != "" {
+		return file.OldPath
+	}
+	return file.Path
+}
+
+// dependencyGate checks the dependencies the commit adds, returning the
+// findings that block it. Lookup failures don't block.
+func (h *GitHandler) dependencyGate(ctx context.Context, repo gitRepo, paths []string) []depcheck.Finding {
+	return depcheck.Blocking(h.deps.Check(ctx, changedDependencies(ctx, repo, paths)))
+}
+
+// sessionEditing reports whether the agent may be changing files in the
+// session's working tree, so git mutations would race with it
+func sessionEditing(status string) bool {
+	switch status {
+	case store.SessionStatusStarting, store.SessionStatusRunning,
+		store.SessionStatusWaitingInput, store.SessionStatusInterrupting:
+		return true
+	}
+	return false
+}
+
+// lockForMutation takes the session's git lock, refusing with 409 if another
+// mutation holds it or the session is still editing files (unless force).
+// It returns nil after writing the error response, else a func to release the lock.
+// A forced mutation is audited only once it holds the lock.
+func (h *GitHandler) lockForMutation(c *gin.Context, session *store.Session, force bool, operation string) func() {
+	editing := sessionEditing(session.Status)
+	if editing && !force {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":          fmt.Sprintf("Session is %s; interrupt it or wait for it to finish before the %s, or pass force", session.Status, operation),
+			"session_status": session.Status,
+		})
+		return nil
+	}
+
+	value, _ := h.mutationLocks.LoadOrStore(session.ID, &sync.Mutex{})
+	lock := value.(*sync.Mutex)
+	if !lock.TryLock() {
+		c.JSON(http.StatusConflict, gin.H{"error": "Another git operation is in progress for this session"})
+		return nil
+	}
+	if editing {
+		h.recordForcedMutation(c.Request.Context(), session, operation)
+	}
+	return lock.Unlock
+}
+
+// recordForcedMutation leaves an audit entry in the session's conversation
+// when a git mutation overrides the running-session check
+func (h *GitHandler) recordForcedMutation(ctx context.Context, session *store.Session, operation string) {
+	content := fmt.Sprintf("Git %s forced by the user while the session was %s", operation, session.Status)
+	slog.Warn("forcing git mutation on active session",
+		"session_id", session.ID,
+		"operation", operation,
+		"status", session.Status)
+
+	event := &store.ConversationEvent{
+		SessionID:       session.ID,
+		ClaudeSessionID: session.ClaudeSessionID,
+		EventType:       store.EventTypeSystem,
+		Role:            "system",
+		Content:         content,
+	}
+	if err := h.store.AddConversationEvent(ctx, event); err != nil {
+		slog.Error("failed to record forced git mutation", "session_id", session.ID, "error", err)
+		return
+	}
+	if h.eventBus != nil {
+		h.eventBus.Publish(bus.Event{
+			Type: bus.EventConversationUpdated,
+			Data: map[string]interface{}{
+				"session_id":        session.ID,
+				"claude_session_id": session.ClaudeSessionID,
+				"event_type":        store.EventTypeSystem,
+				"content":           content,
+				"content_type":      "system",
+			},
+		})
+	}
+}
+
+// Helper functions
+
+// gitRepo is a working directory on the host that holds it
+type gitRepo struct {
+	host workspace.Host
+	dir  string
+}
 
-		// Untracked files
-		if indexStatus == '?' && workTreeStatus == '?' {
-			file.Status = "untracked"
-			status.Untracked = append(status.Untracked, file)
+// isGitRepo reports whether the directory is the top of a work tree. Asking
+// git rather than looking for a .git directory also accepts linked
+// worktrees, whose .git is a file.
+func isGitRepo(repo gitRepo) bool {
+	out, err := repo.run("rev-parse", "--is-inside-work-tree", "--show-prefix")
+	return err == nil && out == "true"
+}
+
+func (r gitRepo) run(args ...string) (string, error) {
+	stdout, _, err := gitcmd.Run(context.Background(), gitcmd.Cmd{Host: r.host, Dir: r.dir, Args: args})
+	return strings.TrimSpace(stdout), err
+}
+
+func getGitStatus(repo gitRepo) (*GitStatusResponse, error) {
+	status := &GitStatusResponse{
+		Staged:    []GitFile{},
+		Unstaged:  []GitFile{},
+		Untracked: []GitFile{},
+	}
+
+	// One call covers the branch and ahead/behind counts as well as files.
+	// Output isn't trimmed, since the leading field matters.
+	output, _, err := repo.exec(context.Background(), nil, nil, "status", "--porcelain=v2", "-z", "--branch")
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := parsePorcelainV2(output)
+	if err != nil {
+		return nil, err
+	}
+
+	status.Branch = parsed.Branch.Head
+	if status.Branch == "(detached)" {
+		// As git rev-parse --abbrev-ref HEAD names it
+		status.Branch = "HEAD"
+	}
+	status.Ahead, status.Behind = parsed.Branch.Ahead, parsed.Branch.Behind
+
+	for _, entry := range parsed.Entries {
+		switch entry.Kind {
+		case '?':
+			status.Untracked = append(status.Untracked, GitFile{Path: entry.Path, Status: "untracked"})
+		case 'u':
+			file := GitFile{Path: entry.Path, Status: "conflicted", Submodule: entry.Submodule}
+			status.Unstaged = append(status.Unstaged, file)
+		case '1', '2':
+			if entry.Index != '.' {
+				status.Staged = append(status.Staged, entry.gitFile(entry.Index, entry.ModeHead, entry.ModeIndex))
+			}
+			if entry.WorkTree != '.' {
+				status.Unstaged = append(status.Unstaged, entry.gitFile(entry.WorkTree, entry.ModeIndex, entry.ModeWorkTree))
+			}
 		}
 	}
 
@@ -429,21 +1344,27 @@ func getGitStatus(dir string) (*GitStatusResponse, error) {
 	return status, nil
 }
 
-func getGitDiff(dir string) (string, int, int) {
+// diffFileStat is one line of git diff --numstat
+type diffFileStat struct {
+	additions int
+	deletions int
+}
+
+// getGitDiffStat returns the (truncated) diff summary and per-file line counts
+func getGitDiffStat(repo gitRepo) (string, []diffFileStat) {
 	// Get diff for staged and unstaged changes
-	diff, _ := runGitCommand(dir, "diff", "--stat", "HEAD")
+	diff, _ := repo.run("diff", "--stat", "HEAD")
 
 	// Get line counts
-	addDel, _ := runGitCommand(dir, "diff", "--numstat", "HEAD")
-	var additions, deletions int
+	addDel, _ := repo.run("diff", "--numstat", "HEAD")
+	var files []diffFileStat
 	for _, line := range strings.Split(addDel, "\n") {
 		parts := strings.Fields(line)
 		if len(parts) >= 2 {
-			var a, d int
-			fmt.Sscanf(parts[0], "%d", &a)
-			fmt.Sscanf(parts[1], "%d", &d)
-			additions += a
-			deletions += d
+			var file diffFileStat
+			fmt.Sscanf(parts[0], "%d", &file.additions)
+			fmt.Sscanf(parts[1], "%d", &file.deletions)
+			files = append(files, file)
 		}
 	}
 
@@ -452,11 +1373,11 @@ func getGitDiff(dir string) (string, int, int) {
 		diff = diff[:5000] + "\n... (truncated)"
 	}
 
-	return diff, additions, deletions
+	return diff, files
 }
 
-func getRecentCommits(dir string, count int) []string {
-	output, err := runGitCommand(dir, "log", fmt.Sprintf("-%d", count), "--pretty=format:%s")
+func getRecentCommits(repo gitRepo, count int) []string {
+	output, err := repo.run("log", fmt.Sprintf("-%d", count), "--pretty=format:%s")
 	if err != nil {
 		return []string{}
 	}
@@ -466,232 +1387,161 @@ func getRecentCommits(dir string, count int) []string {
 	return strings.Split(output, "\n")
 }
 
-func createBranch(dir, name string) error {
-	_, err := runGitCommand(dir, "checkout", "-b", name)
+func createBranch(repo gitRepo, name string) error {
+	_, err := repo.run("checkout", "-b", name)
 	return err
 }
 
-func stageAllChanges(dir string) error {
-	_, err := runGitCommand(dir, "add", "-A")
+func stageAllChanges(repo gitRepo) error {
+	_, err := repo.run("add", "-A")
 	return err
 }
 
-func stageFiles(dir string, files []string) error {
+func stageFiles(repo gitRepo, files []string) error {
 	args := append([]string{"add"}, files...)
-	_, err := runGitCommand(dir, args...)
+	_, err := repo.run(args...)
 	return err
 }
 
-func createCommit(dir, message string) (string, error) {
-	_, err := runGitCommand(dir, "commit", "-m", message)
+// createCommit commits what's staged, streaming what git and its hooks print
+func createCommit(repo gitRepo, message string, stream *cmdstream.Command) (string, error) {
+	_, _, err := gitcmd.Run(context.Background(), gitcmd.Cmd{
+		Host:   repo.host,
+		Dir:    repo.dir,
+		Args:   []string{"commit", "-m", message},
+		Stdout: stream.Stdout(),
+		Stderr: stream.Stderr(),
+	})
+	stream.Finish(err)
 	if err != nil {
 		return "", err
 	}
 	// Get the commit hash
-	hash, err := runGitCommand(dir, "rev-parse", "HEAD")
+	hash, err := repo.run("rev-parse", "HEAD")
 	if err != nil {
 		return "", err
 	}
 	return hash[:8], nil // Return short hash
 }
 
-func buildCommitMessagePrompt(ctx *ConversationContext, status *GitStatusResponse, diff string, recentCommits []string) string {
-	var sb strings.Builder
-
-	sb.WriteString("Generate a commit message for the following changes. ")
-	sb.WriteString("You have access to the conversation context from the AI coding session that produced these changes.\n\n")
-
-	// Session context
-	if ctx != nil {
-		sb.WriteString("## Session Intent\n")
-		sb.WriteString(fmt.Sprintf("Original Request: %s\n", ctx.OriginalQuery))
-		if ctx.SessionSummary != "" {
-			sb.WriteString(fmt.Sprintf("Session Summary: %s\n", ctx.SessionSummary))
-		}
-
-		if len(ctx.KeyDecisions) > 0 {
-			sb.WriteString("\n## Key Decisions Made\n")
-			for _, d := range ctx.KeyDecisions {
-				sb.WriteString(fmt.Sprintf("- %s\n", d))
-			}
-		}
-
-		if len(ctx.UserIntents) > 0 {
-			sb.WriteString("\n## User Feedback During Session\n")
-			for _, intent := range ctx.UserIntents {
-				sb.WriteString(fmt.Sprintf("- %s\n", intent))
-			}
-		}
-
-		if len(ctx.FilesModified) > 0 {
-			sb.WriteString("\n## Files Changed (with purpose)\n")
-			for _, f := range ctx.FilesModified {
-				sb.WriteString(fmt.Sprintf("- %s (%s)", f.Path, f.Action))
-				if f.Purpose != "" {
-					sb.WriteString(fmt.Sprintf(": %s", f.Purpose))
-				}
-				sb.WriteString("\n")
-			}
-		}
-
-		if len(ctx.IssueReferences) > 0 {
-			sb.WriteString("\n## Issue References Found\n")
-			sb.WriteString(strings.Join(ctx.IssueReferences, ", "))
-			sb.WriteString("\n")
-		}
-	}
-
-	// Git context
-	sb.WriteString("\n## Git Status\n")
-	sb.WriteString(fmt.Sprintf("Branch: %s\n", status.Branch))
-	sb.WriteString(fmt.Sprintf("Staged: %d files\n", len(status.Staged)))
-	sb.WriteString(fmt.Sprintf("Unstaged: %d files\n", len(status.Unstaged)))
-	sb.WriteString(fmt.Sprintf("Untracked: %d files\n", len(status.Untracked)))
-
-	sb.WriteString("\n## Git Diff Summary\n")
-	sb.WriteString(diff)
+// CommitMessagePromptData is the data passed to the commit-message prompt template
+type CommitMessagePromptData struct {
+	Context        *ConversationContext
+	Branch         string
+	StagedCount    int
+	UnstagedCount  int
+	UntrackedCount int
+	Diff           string
+	RecentCommits  []string
+	// Language to write the message in, e.g. "French"; empty for English
+	Language string
+}
 
-	if len(recentCommits) > 0 {
-		sb.WriteString("\n\n## Recent Commits (for style consistency)\n")
-		for _, c := range recentCommits {
-			sb.WriteString(fmt.Sprintf("- %s\n", c))
-		}
+// guardConversationContext returns a copy of the client's conversation
+// context with its free text scanned for prompt injections
+func (h *GitHandler) guardConversationContext(ctx context.Context, sessionID string, cc *ConversationContext) *ConversationContext {
+	if cc == nil || h.injections == nil {
+		return cc
 	}
+	prompt := string(llm.TaskCommitMessage)
+	guarded := *cc
+	guarded.OriginalQuery = h.injections.Check(ctx, sessionID, prompt, "query", cc.OriginalQuery)
+	guarded.SessionSummary = h.injections.Check(ctx, sessionID, prompt, "summary", cc.SessionSummary)
+	guarded.UserIntents = h.injections.CheckAll(ctx, sessionID, prompt, "user_intents", cc.UserIntents)
+	guarded.KeyDecisions = h.injections.CheckAll(ctx, sessionID, prompt, "key_decisions", cc.KeyDecisions)
+	guarded.IssueReferences = h.injections.CheckAll(ctx, sessionID, prompt, "issue_references", cc.IssueReferences)
+	guarded.Scope = h.injections.Check(ctx, sessionID, prompt, "scope", cc.Scope)
+	return &guarded
+}
 
-	// Instructions
-	sb.WriteString(`
-
-## Instructions
-Generate a commit message that captures not just WHAT changed, but WHY it changed.
-
-1. Subject line (~50 chars, imperative mood, capitalized, no period):
-   - Use type prefix: feat/fix/docs/style/refactor/test/chore
-   - Include scope in parentheses if clear from files
-   - Reflect the user's original intent
-
-2. Body (optional, wrapped at 72 chars):
-   - Explain the problem solved
-   - Briefly describe the approach
-
-3. Footer (optional):
-   - Include issue references if found
-   - Note breaking changes
-
-4. Determine if changes should be:
-   - "single": Related changes with single intent
-   - "multiple": Multiple distinct tasks
-   - "branch": Major feature or breaking changes
+func buildCommitMessagePrompt(templates *prompts.Set, ctx *ConversationContext, status *GitStatusResponse, diff string, recentCommits []string, locale string) (string, error) {
+	return templates.Render(prompts.CommitMessage, CommitMessagePromptData{
+		Context:        ctx,
+		Branch:         status.Branch,
+		StagedCount:    len(status.Staged),
+		UnstagedCount:  len(status.Unstaged),
+		UntrackedCount: len(status.Untracked),
+		Diff:           diff,
+		RecentCommits:  recentCommits,
+		Language:       i18n.LanguageName(locale),
+	})
+}
 
-Respond ONLY with valid JSON (no markdown code blocks):
-{
-  "type": "single",
-  "branchName": "",
-  "reasoning": "Brief explanation",
-  "commits": [
-    {
-      "subject": "type(scope): description",
-      "body": "Optional longer description",
-      "footer": "Closes #123",
-      "files": ["file1.ts", "file2.ts"]
+// commitSuggestionSchema is the JSON Schema the model must fill in for a commit suggestion
+var commitSuggestionSchema = llm.Schema{
+	Name:        "commit_suggestion",
+	Description: "Suggest how to commit the working tree changes",
+	Definition: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "type": {"type": "string", "enum": ["single", "multiple", "branch"]},
+    "branchName": {"type": "string"},
+    "reasoning": {"type": "string"},
+    "commits": {
+      "type": "array",
+      "minItems": 1,
+      "items": {
+        "type": "object",
+        "properties": {
+          "subject": {"type": "string"},
+          "body": {"type": "string"},
+          "footer": {"type": "string"},
+          "files": {"type": "array", "items": {"type": "string"}}
+        },
+        "required": ["subject", "files"]
+      }
     }
-  ]
-}`)
-
-	return sb.String()
+  },
+  "required": ["type", "commits", "reasoning"]
+}`),
 }
 
-func (h *GitHandler) generateWithClaude(c *gin.Context, prompt string) (*CommitSuggestion, error) {
-	apiKey := os.Getenv("ANTHROPIC_API_KEY")
-	if apiKey == "" {
-		return nil, fmt.Errorf("ANTHROPIC_API_KEY not configured")
+// validate checks the parts of a suggestion the UI depends on
+func (s *CommitSuggestion) validate() error {
+	switch s.Type {
+	case "single", "multiple", "branch":
+	default:
+		return fmt.Errorf("type must be single, multiple or branch, got %q", s.Type)
 	}
-
-	payload := map[string]interface{}{
-		"model":      "claude-sonnet-4-20250514",
-		"max_tokens": 2048,
-		"system":     "You are a git commit message generator. Generate clear, conventional commit messages.",
-		"messages": []map[string]string{
-			{
-				"role":    "user",
-				"content": prompt,
-			},
-		},
+	if len(s.Commits) == 0 {
+		return fmt.Errorf("at least one commit is required")
 	}
-
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(c.Request.Context(), "POST", "https://api.anthropic.com/v1/messages", bytes.NewReader(payloadBytes))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-api-key", apiKey)
-	req.Header.Set("anthropic-version", "2023-06-01")
-
-	resp, err := h.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("API request failed: %w", err)
+	for i, commit := range s.Commits {
+		if strings.TrimSpace(commit.Subject) == "" {
+			return fmt.Errorf("commit %d has an empty subject", i+1)
+		}
 	}
-	defer func() { _ = resp.Body.Close() }()
+	return nil
+}
 
-	respBody, err := io.ReadAll(resp.Body)
+// generateCommitSuggestion asks the routed model for a suggestion and records
+// it for feedback, returning the output ID
+func (h *GitHandler) generateCommitSuggestion(ctx context.Context, sessionID, prompt string) (*CommitSuggestion, string, error) {
+	system, err := h.prompts.Render(prompts.CommitMessageSystem, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, "", err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		slog.Error("Anthropic API error", "status_code", resp.StatusCode, "response", string(respBody))
-		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
-	}
-
-	// Parse Claude response
-	var anthropicResp struct {
-		Content []struct {
-			Type string `json:"type"`
-			Text string `json:"text"`
-		} `json:"content"`
-	}
-
-	if err := json.Unmarshal(respBody, &anthropicResp); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
-	}
-
-	// Extract text
-	var text string
-	for _, content := range anthropicResp.Content {
-		if content.Type == "text" {
-			text = content.Text
-			break
-		}
-	}
-
-	// Clean up response (remove markdown code blocks if present)
-	text = strings.TrimSpace(text)
-	text = strings.TrimPrefix(text, "```json")
-	text = strings.TrimPrefix(text, "```")
-	text = strings.TrimSuffix(text, "```")
-	text = strings.TrimSpace(text)
-
-	// Parse JSON response
 	var suggestion CommitSuggestion
-	if err := json.Unmarshal([]byte(text), &suggestion); err != nil {
-		slog.Error("failed to parse commit suggestion", "error", err, "text", text)
-		// Return a default suggestion
-		return &CommitSuggestion{
-			Type:      "single",
-			Reasoning: "Failed to parse AI response, using default",
-			Commits: []CommitMessage{
-				{
-					Subject: "chore: Update files",
-					Files:   []string{},
-				},
-			},
-		}, nil
+	resp, err := h.router.CompleteJSON(ctx, llm.TaskCommitMessage, llm.Request{
+		System:    system,
+		Prompt:    prompt,
+		MaxTokens: 2048,
+		Schema:    &commitSuggestionSchema,
+	}, &suggestion, suggestion.validate)
+	if err != nil {
+		return nil, "", err
 	}
 
-	return &suggestion, nil
+	outputID := recordOutput(ctx, h.outputs, &store.AIOutput{
+		Kind:            store.AIOutputCommitMessage,
+		SessionID:       sessionID,
+		Template:        prompts.CommitMessage,
+		TemplateVersion: h.prompts.Version(prompts.CommitMessage),
+		Provider:        resp.Provider,
+		Model:           resp.Model,
+		Prompt:          system + "\n\n" + prompt,
+		Response:        resp.Text,
+	})
+	return &suggestion, outputID, nil
 }
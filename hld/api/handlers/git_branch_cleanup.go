@@ -0,0 +1,354 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/credentials"
+	"github.com/humanlayer/humanlayer/hld/provenance"
+)
+
+// sessionBranchPrefix names branches the daemon creates, such as those for
+// GitHub issues
+const sessionBranchPrefix = "humanlayer/"
+
+// defaultStaleDays is how long an unmerged session branch goes without
+// commits before it counts as abandoned
+const defaultStaleDays = 30
+
+// Why a branch is stale
+const (
+	StaleMerged    = "merged"    // Its commits are all on the default branch
+	StaleAbandoned = "abandoned" // Unmerged, with no commits for a while
+)
+
+// StaleBranch is a local branch a session created that can likely go
+type StaleBranch struct {
+	Name   string    `json:"name"`
+	Commit string    `json:"commit"`
+	Date   time.Time `json:"date"`
+	// Reason is merged or abandoned
+	Reason string `json:"reason"`
+	// Unmerged counts the commits an abandoned branch would lose
+	Unmerged int `json:"unmerged,omitempty"`
+	// Sessions made commits on the branch
+	Sessions []string `json:"sessions,omitempty"`
+	// Upstream is the remote branch deleted with it when asked
+	Upstream string `json:"upstream,omitempty"`
+}
+
+// StaleBranchesResponse lists the stale branches of a repository
+type StaleBranchesResponse struct {
+	Default string `json:"default"`
+	// OlderThanDays is the age after which unmerged branches are abandoned
+	OlderThanDays int           `json:"olderThanDays"`
+	Branches      []StaleBranch `json:"branches"`
+}
+
+// BranchCleanupRequest deletes stale branches
+type BranchCleanupRequest struct {
+	// Branches to delete; each must still be stale, or it's skipped
+	Branches []string `json:"branches" binding:"required"`
+	// Remote also deletes each branch's upstream from its remote
+	Remote bool `json:"remote,omitempty"`
+	// DryRun reports what would be deleted without deleting anything
+	DryRun bool `json:"dryRun,omitempty"`
+	// OlderThanDays overrides when unmerged branches count as abandoned
+	OlderThanDays int `json:"olderThanDays,omitempty"`
+	// Force deletes even while the session is running
+	Force bool `json:"force,omitempty"`
+}
+
+// BranchCleanupResult is what happened to one requested branch
+type BranchCleanupResult struct {
+	Branch string `json:"branch"`
+	// Deleted is set for deleted branches, or ones a dry run would delete
+	Deleted bool `json:"deleted"`
+	// Commit is where the branch was, to recreate it
+	Commit        string `json:"commit,omitempty"`
+	Upstream      string `json:"upstream,omitempty"`
+	RemoteDeleted bool   `json:"remoteDeleted,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// BranchCleanupResponse reports a cleanup
+type BranchCleanupResponse struct {
+	DryRun  bool                  `json:"dryRun"`
+	Results []BranchCleanupResult `json:"results"`
+}
+
+// HandleListStaleBranches suggests branches past sessions created that are
+// merged into the default branch or abandoned: unmerged, with no commits
+// for ?older_than_days= (default 30). A session created a branch if it's
+// named humanlayer/..., or has commits the daemon made or stamped with
+// session provenance.
+func (h *GitHandler) HandleListStaleBranches(c *gin.Context) {
+	days := defaultStaleDays
+	if value := c.Query("older_than_days"); value != "" {
+		var err error
+		if days, err = strconv.Atoi(value); err != nil || days < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "older_than_days must be a positive number"})
+			return
+		}
+	}
+	session, err := h.store.GetSession(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+	repo, ok := h.selectRepo(c, session)
+	if !ok {
+		return
+	}
+	if !isGitRepo(repo) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Not a git repository"})
+		return
+	}
+	response, err := h.staleBranches(c.Request.Context(), repo, days)
+	if err != nil {
+		if errors.Is(err, errNoDefaultBranch) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "No default branch to compare with; stale branches are those merged into origin's HEAD, main or master"})
+			return
+		}
+		slog.Error("failed to list stale branches", "session_id", session.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list stale branches"})
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// errNoDefaultBranch is returned for a repository without a default branch
+var errNoDefaultBranch = errors.New("no default branch")
+
+func (h *GitHandler) staleBranches(ctx context.Context, repo gitRepo, days int) (*StaleBranchesResponse, error) {
+	base := defaultBranch(repo)
+	if base == "" {
+		return nil, errNoDefaultBranch
+	}
+	response := &StaleBranchesResponse{Default: base, OlderThanDays: days, Branches: []StaleBranch{}}
+
+	sessionCommits, err := h.repoSessionCommits(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	inUse := checkedOutBranches(repo)
+	out, err := repo.run("for-each-ref", "--format=%(refname:short)%00%(objectname)%00%(committerdate:iso-strict)%00%(upstream:short)", "refs/heads")
+	if err != nil {
+		return nil, err
+	}
+	cutoff := time.Now().AddDate(0, 0, -days)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 4 || fields[0] == base || inUse[fields[0]] {
+			continue
+		}
+		branch := StaleBranch{Name: fields[0], Commit: fields[1], Upstream: fields[3]}
+		branch.Date, _ = time.Parse(time.RFC3339, fields[2])
+		if _, err := repo.run("merge-base", "--is-ancestor", branch.Commit, base); err == nil {
+			branch.Reason = StaleMerged
+		} else if branch.Date.Before(cutoff) {
+			branch.Reason = StaleAbandoned
+			branch.Unmerged, _ = aheadBehind(repo, base, "refs/heads/"+branch.Name)
+		} else {
+			continue
+		}
+		branch.Sessions = branchSessions(repo, base, branch, sessionCommits)
+		if len(branch.Sessions) == 0 && !strings.HasPrefix(branch.Name, sessionBranchPrefix) {
+			continue
+		}
+		response.Branches = append(response.Branches, branch)
+	}
+	return response, nil
+}
+
+// repoSessionCommits maps the commits the daemon made in repo to their sessions
+func (h *GitHandler) repoSessionCommits(ctx context.Context, repo gitRepo) (map[string]string, error) {
+	root, err := repo.run("rev-parse", "--show-toplevel")
+	if err != nil {
+		root = repo.dir
+	}
+	if h.commits == nil {
+		return map[string]string{}, nil
+	}
+	commits, err := h.commits.ListSessionCommits(ctx, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	sessions := make(map[string]string)
+	for _, commit := range commits {
+		if commit.Repository == root {
+			sessions[commit.Hash] = commit.SessionID
+		}
+	}
+	return sessions, nil
+}
+
+// branchSessions finds the sessions behind a branch's own commits. A
+// merged branch's commits are on the default branch too, so only its tip
+// is looked at, unless that's also the default branch's tip.
+func branchSessions(repo gitRepo, base string, branch StaleBranch, sessionCommits map[string]string) []string {
+	args := []string{"log", "-n", "50", "--format=%H%x1f%B%x1e", base + ".." + branch.Commit, "--"}
+	if branch.Reason == StaleMerged {
+		if tip, _ := repo.run("rev-parse", base); tip == branch.Commit {
+			return nil
+		}
+		args = []string{"log", "-1", "--format=%H%x1f%B%x1e", branch.Commit, "--"}
+	}
+	out, err := repo.run(args...)
+	if err != nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var sessions []string
+	for _, entry := range strings.Split(out, "\x1e") {
+		hash, message, ok := strings.Cut(strings.TrimLeft(entry, "\n"), "\x1f")
+		if !ok {
+			continue
+		}
+		sessionID := sessionCommits[hash]
+		if record, ok := provenance.Parse(message); ok && sessionID == "" {
+			sessionID = record.SessionID
+		}
+		if sessionID != "" && !seen[sessionID] {
+			seen[sessionID] = true
+			sessions = append(sessions, sessionID)
+		}
+	}
+	return sessions
+}
+
+// checkedOutBranches are the branches checked out in the repository's
+// worktrees, which can't be deleted
+func checkedOutBranches(repo gitRepo) map[string]bool {
+	branches := make(map[string]bool)
+	out, _ := repo.run("worktree", "list", "--porcelain")
+	for _, line := range strings.Split(out, "\n") {
+		if ref, ok := strings.CutPrefix(line, "branch refs/heads/"); ok {
+			branches[ref] = true
+		}
+	}
+	return branches
+}
+
+// HandleCleanupBranches deletes stale branches, and optionally their
+// upstreams. Each branch is checked again, so only branches that are still
+// stale are deleted; a dry run reports what would be.
+func (h *GitHandler) HandleCleanupBranches(c *gin.Context) {
+	var req BranchCleanupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	if req.OlderThanDays < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "olderThanDays must be positive"})
+		return
+	}
+	if req.OlderThanDays == 0 {
+		req.OlderThanDays = defaultStaleDays
+	}
+	session, err := h.store.GetSession(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+	repo, ok := h.selectRepo(c, session)
+	if !ok {
+		return
+	}
+	if !isGitRepo(repo) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Not a git repository"})
+		return
+	}
+	if !req.DryRun {
+		unlock := h.lockForMutation(c, session, req.Force, "branch cleanup")
+		if unlock == nil {
+			return
+		}
+		defer unlock()
+	}
+
+	ctx := c.Request.Context()
+	stale, err := h.staleBranches(ctx, repo, req.OlderThanDays)
+	if err != nil {
+		if errors.Is(err, errNoDefaultBranch) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "No default branch to compare with"})
+			return
+		}
+		slog.Error("failed to list stale branches", "session_id", session.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list stale branches"})
+		return
+	}
+	byName := make(map[string]StaleBranch, len(stale.Branches))
+	for _, branch := range stale.Branches {
+		byName[branch.Name] = branch
+	}
+
+	response := BranchCleanupResponse{DryRun: req.DryRun, Results: []BranchCleanupResult{}}
+	for _, name := range req.Branches {
+		result := BranchCleanupResult{Branch: name}
+		branch, ok := byName[name]
+		switch {
+		case !ok:
+			result.Error = "Not a stale session branch"
+		case req.DryRun:
+			result.Deleted, result.Commit = true, branch.Commit
+			if req.Remote {
+				result.Upstream = branch.Upstream
+			}
+		default:
+			result = h.deleteBranch(ctx, repo, branch, req.Remote)
+		}
+		response.Results = append(response.Results, result)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// deleteBranch deletes a stale branch, after its upstream if remote is set.
+// A branch whose upstream can't be deleted is kept, so the cleanup can be
+// retried.
+func (h *GitHandler) deleteBranch(ctx context.Context, repo gitRepo, branch StaleBranch, remote bool) BranchCleanupResult {
+	result := BranchCleanupResult{Branch: branch.Name, Commit: branch.Commit}
+	if remote && branch.Upstream != "" {
+		result.Upstream = branch.Upstream
+		if err := h.deleteUpstream(ctx, repo, branch.Name); err != nil {
+			var authErr *credentials.AuthError
+			if errors.As(err, &authErr) {
+				result.Error = authErr.Error()
+			} else {
+				result.Error = fmt.Sprintf("Failed to delete %s: %v", branch.Upstream, err)
+			}
+			return result
+		}
+		result.RemoteDeleted = true
+	}
+	// Staleness was just checked, so unmerged abandoned branches go too
+	if _, stderr, err := repo.exec(ctx, nil, nil, "branch", "-D", "--", branch.Name); err != nil {
+		result.Error = fmt.Sprintf("Failed to delete branch: %s", strings.TrimSpace(stderr))
+		return result
+	}
+	result.Deleted = true
+	return result
+}
+
+// deleteUpstream deletes the branch a local branch tracks from its remote
+func (h *GitHandler) deleteUpstream(ctx context.Context, repo gitRepo, name string) error {
+	remote, _ := repo.run("config", "branch."+name+".remote")
+	merge, _ := repo.run("config", "branch."+name+".merge")
+	if remote == "" || remote == "." || merge == "" {
+		return errNoUpstream
+	}
+	remoteURL, auth, err := h.remoteAuth(ctx, repo, remote)
+	if err != nil {
+		return err
+	}
+	args := append(auth.Args, "push", "--quiet", remote, "--delete", merge)
+	_, _, err = repo.exec(ctx, nil, auth.Env, args...)
+	return credentials.CheckAuth(err, remoteURL)
+}
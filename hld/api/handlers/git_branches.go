@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxBranches caps the branches listed, most recently committed first,
+// since each costs a rev-list for its ahead/behind counts
+const maxBranches = 200
+
+// GitBranch is a local or remote-tracking branch
+type GitBranch struct {
+	// Name is short, such as main or origin/main
+	Name    string `json:"name"`
+	Remote  bool   `json:"remote"`
+	Current bool   `json:"current"`
+	Commit  string `json:"commit"`
+	// Upstream is the branch a local branch tracks
+	Upstream string    `json:"upstream,omitempty"`
+	Subject  string    `json:"subject"`
+	Date     time.Time `json:"date"`
+	// Ahead and Behind count commits against the default branch
+	Ahead  int `json:"ahead"`
+	Behind int `json:"behind"`
+}
+
+// BranchesResponse lists a repository's branches
+type BranchesResponse struct {
+	// Current is empty when HEAD is detached
+	Current string `json:"current"`
+	// Default is the branch others are compared with: the remote's HEAD,
+	// else main or master
+	Default   string      `json:"default"`
+	Branches  []GitBranch `json:"branches"`
+	Truncated bool        `json:"truncated,omitempty"`
+}
+
+// CheckoutRequest switches a session's repository to another branch
+type CheckoutRequest struct {
+	Branch string `json:"branch" binding:"required"`
+	// Create makes the branch at StartPoint, which defaults to HEAD
+	Create     bool   `json:"create,omitempty"`
+	StartPoint string `json:"startPoint,omitempty"`
+	// Stash stashes uncommitted changes, untracked files included, before
+	// switching; without it a working tree with changes is refused
+	Stash bool `json:"stash,omitempty"`
+	// Force switches even while the session is running
+	Force bool `json:"force,omitempty"`
+}
+
+// CheckoutResponse reports a branch switch
+type CheckoutResponse struct {
+	Success  bool   `json:"success"`
+	Branch   string `json:"branch"`
+	Previous string `json:"previous,omitempty"`
+	// Stash is the commit holding the changes stashed before switching;
+	// git stash list shows it with its message
+	Stash string `json:"stash,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// HandleListBranches lists the local and remote-tracking branches of the
+// session's repository, with how far each is ahead of and behind the
+// default branch
+func (h *GitHandler) HandleListBranches(c *gin.Context) {
+	session, err := h.store.GetSession(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+	repo, ok := h.selectRepo(c, session)
+	if !ok {
+		return
+	}
+	if !isGitRepo(repo) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Not a git repository"})
+		return
+	}
+	response, err := listBranches(repo)
+	if err != nil {
+		slog.Error("failed to list branches", "session_id", session.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list branches"})
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func listBranches(repo gitRepo) (*BranchesResponse, error) {
+	out, err := repo.run("for-each-ref", "--sort=-committerdate",
+		"--format=%(refname)%00%(refname:short)%00%(objectname)%00%(upstream:short)%00%(committerdate:iso-strict)%00%(HEAD)%00%(contents:subject)",
+		"refs/heads", "refs/remotes")
+	if err != nil {
+		return nil, err
+	}
+	response := &BranchesResponse{Branches: []GitBranch{}, Default: defaultBranch(repo)}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, "\x00")
+		// A remote's HEAD is a pointer to one of its branches
+		if len(fields) != 7 || strings.HasSuffix(fields[0], "/HEAD") {
+			continue
+		}
+		if len(response.Branches) == maxBranches {
+			response.Truncated = true
+			break
+		}
+		date, _ := time.Parse(time.RFC3339, fields[4])
+		branch := GitBranch{
+			Name:     fields[1],
+			Remote:   strings.HasPrefix(fields[0], "refs/remotes/"),
+			Current:  fields[5] == "*",
+			Commit:   fields[2],
+			Upstream: fields[3],
+			Subject:  fields[6],
+			Date:     date,
+		}
+		if branch.Current {
+			response.Current = branch.Name
+		}
+		if response.Default != "" && branch.Name != response.Default {
+			branch.Ahead, branch.Behind = aheadBehind(repo, response.Default, fields[0])
+		}
+		response.Branches = append(response.Branches, branch)
+	}
+	return response, nil
+}
+
+// defaultBranch is origin's HEAD, else a local main or master
+func defaultBranch(repo gitRepo) string {
+	if head, err := repo.run("symbolic-ref", "--short", "-q", "refs/remotes/origin/HEAD"); err == nil && head != "" {
+		return head
+	}
+	for _, name := range []string{"main", "master"} {
+		if _, err := repo.run("rev-parse", "--verify", "-q", "refs/heads/"+name); err == nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// aheadBehind counts the commits ref has that base lacks, and the reverse
+func aheadBehind(repo gitRepo, base, ref string) (int, int) {
+	counts, err := repo.run("rev-list", "--left-right", "--count", ref+"..."+base)
+	if err != nil {
+		return 0, 0
+	}
+	ahead, behind, _ := strings.Cut(counts, "\t")
+	a, _ := strconv.Atoi(ahead)
+	b, _ := strconv.Atoi(behind)
+	return a, b
+}
+
+// HandleCheckout switches the session's repository to another branch. A
+// working tree with changes is refused unless the request stashes them.
+func (h *GitHandler) HandleCheckout(c *gin.Context) {
+	var req CheckoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	if strings.HasPrefix(req.Branch, "-") || strings.HasPrefix(req.StartPoint, "-") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid branch"})
+		return
+	}
+	session, err := h.store.GetSession(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+	repo, ok := h.selectRepo(c, session)
+	if !ok {
+		return
+	}
+	if !isGitRepo(repo) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Not a git repository"})
+		return
+	}
+	args, err := switchArgs(repo, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	unlock := h.lockForMutation(c, session, req.Force, "branch switch")
+	if unlock == nil {
+		return
+	}
+	defer unlock()
+
+	ctx := c.Request.Context()
+	response := CheckoutResponse{Branch: req.Branch}
+	response.Previous, _ = repo.run("symbolic-ref", "--short", "-q", "HEAD")
+	status, err := getGitStatus(repo)
+	if err != nil {
+		slog.Error("failed to get git status", "session_id", session.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get git status"})
+		return
+	}
+	if status.HasChanges {
+		if !req.Stash {
+			changed := len(status.Staged) + len(status.Unstaged) + len(status.Untracked)
+			response.Error = fmt.Sprintf("The working tree has %d changed files; commit them, or pass stash to stash them before switching", changed)
+			c.JSON(http.StatusConflict, response)
+			return
+		}
+		if response.Stash, err = stashChanges(ctx, repo, fmt.Sprintf("HumanLayer: changes on %s before switching to %s", describeHead(response.Previous), req.Branch)); err != nil {
+			response.Error = fmt.Sprintf("Failed to stash changes: %v", err)
+			c.JSON(http.StatusInternalServerError, response)
+			return
+		}
+	}
+
+	if _, stderr, err := repo.exec(ctx, nil, nil, args...); err != nil {
+		if response.Stash != "" {
+			// Nothing switched, so the changes go back where they were
+			if _, _, popErr := repo.exec(ctx, nil, nil, "stash", "pop", "--index"); popErr != nil {
+				slog.Error("failed to restore stashed changes", "session_id", session.ID, "stash", response.Stash, "error", popErr)
+			} else {
+				response.Stash = ""
+			}
+		}
+		response.Error = fmt.Sprintf("Failed to switch to %s: %s", req.Branch, strings.TrimSpace(stderr))
+		c.JSON(http.StatusConflict, response)
+		return
+	}
+	response.Success = true
+	if current, _ := repo.run("symbolic-ref", "--short", "-q", "HEAD"); current != "" {
+		response.Branch = current
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// switchArgs is the git switch for req. A remote-tracking branch such as
+// origin/feature gets a local branch tracking it.
+func switchArgs(repo gitRepo, req CheckoutRequest) ([]string, error) {
+	if _, err := repo.run("check-ref-format", "--branch", req.Branch); err != nil {
+		return nil, fmt.Errorf("invalid branch name %q", req.Branch)
+	}
+	if req.Create {
+		args := []string{"switch", "--create", req.Branch}
+		if req.StartPoint != "" {
+			args = append(args, req.StartPoint)
+		}
+		return args, nil
+	}
+	if _, err := repo.run("rev-parse", "--verify", "-q", "refs/heads/"+req.Branch); err == nil {
+		return []string{"switch", req.Branch}, nil
+	}
+	if _, err := repo.run("rev-parse", "--verify", "-q", "refs/remotes/"+req.Branch); err == nil {
+		return []string{"switch", "--track", req.Branch}, nil
+	}
+	// git switch finds a branch of the same name on a single remote
+	return []string{"switch", "--guess", req.Branch}, nil
+}
+
+// stashChanges stashes the working tree, untracked files included, and
+// returns the stash commit
+func stashChanges(ctx context.Context, repo gitRepo, message string) (string, error) {
+	args := []string{"stash", "push", "--include-untracked", "-m", message}
+	if email, _ := repo.run("config", "user.email"); email == "" {
+		args = append([]string{"-c", "user.name=HumanLayer", "-c", "user.email=humanlayer@localhost"}, args...)
+	}
+	if _, stderr, err := repo.exec(ctx, nil, nil, args...); err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr))
+	}
+	return repo.run("rev-parse", "stash@{0}")
+}
+
+func describeHead(branch string) string {
+	if branch == "" {
+		return "detached HEAD"
+	}
+	return branch
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockForMutation_AuditsOnlyWhenLocked(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	session := &store.Session{ID: "sess-1", RunID: "run-1", Status: store.SessionStatusRunning, ClaudeSessionID: "claude-1", CreatedAt: time.Now()}
+	require.NoError(t, s.CreateSession(ctx, session))
+	h := NewGitHandler(s)
+
+	// Another mutation holds the lock: the forced one is refused unaudited
+	held := &sync.Mutex{}
+	held.Lock()
+	h.mutationLocks.Store(session.ID, held)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/", nil)
+	assert.Nil(t, h.lockForMutation(c, session, true, "commit"))
+	assert.Equal(t, http.StatusConflict, w.Code)
+	events, err := s.GetSessionConversation(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	held.Unlock()
+	unlock := h.lockForMutation(c, session, true, "commit")
+	require.NotNil(t, unlock)
+	unlock()
+	events, err = s.GetSessionConversation(ctx, session.ID)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Contains(t, events[0].Content, "forced")
+}
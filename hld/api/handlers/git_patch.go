@@ -0,0 +1,387 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/humanlayer/humanlayer/hld/diskguard"
+	"github.com/humanlayer/humanlayer/hld/gitcmd"
+)
+
+// maxPatchSize caps an uploaded patch
+const maxPatchSize = 50 << 20
+
+// Patch export formats
+const (
+	PatchFormatMbox = "mbox" // One message per commit, for git am
+	PatchFormatDiff = "diff" // One diff of everything, for git apply
+)
+
+// ApplyPatchRequest is a patch to apply to a session's working directory.
+// A request whose body isn't JSON is taken as the patch itself, with the
+// options in the query string.
+type ApplyPatchRequest struct {
+	Patch string `json:"patch"`
+	// ThreeWay falls back to a 3-way merge for hunks that don't apply
+	// cleanly, leaving conflict markers where that fails. Defaults to true.
+	ThreeWay *bool `json:"threeWay,omitempty"`
+	// Check reports whether the patch applies without changing anything
+	Check bool `json:"check,omitempty"`
+	// Commit recreates the commits of an mbox patch series with git am
+	// instead of leaving the changes uncommitted
+	Commit bool `json:"commit,omitempty"`
+	// Force applies even while the session is running
+	Force bool `json:"force,omitempty"`
+}
+
+// ApplyPatchResponse reports how a patch applied
+type ApplyPatchResponse struct {
+	Success bool `json:"success"`
+	// Files the patch touches
+	Files []string `json:"files"`
+	// Files left with conflict markers by the 3-way merge
+	Conflicts []string `json:"conflicts,omitempty"`
+	// Commits created when committing an mbox series
+	CommitHashes []string `json:"commitHashes,omitempty"`
+	Error        string   `json:"error,omitempty"`
+}
+
+// HandleExportPatch returns the session's changes since base as a patch:
+// its commits, then any uncommitted changes, including untracked files, as
+// one more. base defaults to the upstream branch, else HEAD, so only
+// uncommitted changes are exported.
+func (h *GitHandler) HandleExportPatch(c *gin.Context) {
+	sessionID := c.Param("id")
+	session, err := h.store.GetSession(c.Request.Context(), sessionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+	repo, ok := h.selectRepo(c, session)
+	if !ok {
+		return
+	}
+	if !isGitRepo(repo) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Not a git repository"})
+		return
+	}
+	format := c.DefaultQuery("format", PatchFormatMbox)
+	if format != PatchFormatMbox && format != PatchFormatDiff {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unknown format %q; use mbox or diff", format)})
+		return
+	}
+	base := c.Query("base")
+	if base == "" {
+		if upstream, _ := repo.run("rev-parse", "--abbrev-ref", "@{upstream}"); upstream != "" {
+			base = upstream
+		} else {
+			base = "HEAD"
+		}
+	}
+	if strings.HasPrefix(base, "-") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid base"})
+		return
+	}
+	if _, err := repo.run("rev-parse", "--verify", "--quiet", base+"^{commit}"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unknown base %q", base)})
+		return
+	}
+
+	ctx := c.Request.Context()
+	tip, err := snapshotWorkingTree(ctx, repo, fmt.Sprintf("Uncommitted changes from HumanLayer session %s", sessionID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to snapshot uncommitted changes: %v", err)})
+		return
+	}
+
+	args := []string{"diff", "--binary", "--no-color", base, tip, "--"}
+	name, contentType := "session-"+sessionID+".patch", "text/x-diff"
+	if format == PatchFormatMbox {
+		args = []string{"format-patch", "--stdout", "--no-signature", base + ".." + tip}
+		name, contentType = "session-"+sessionID+".mbox", "application/mbox"
+	}
+	// Streamed from git, since patches with binary files can be large
+	out := &deferredResponse{c: c, start: func() {
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+		c.Header("Content-Type", contentType)
+		c.Status(http.StatusOK)
+	}}
+	err = repo.stream(ctx, out, args...)
+	switch {
+	case err != nil && !out.started:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to export patch: %v", err)})
+	case err != nil:
+		slog.Warn("patch export interrupted", "session_id", sessionID, "error", err)
+	case !out.started:
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// deferredResponse starts a response on its first write, so a failure
+// before there's anything to send can still be reported as an error
+type deferredResponse struct {
+	c       *gin.Context
+	start   func()
+	started bool
+}
+
+func (r *deferredResponse) Write(p []byte) (int, error) {
+	if !r.started {
+		r.start()
+		r.started = true
+	}
+	return r.c.Writer.Write(p)
+}
+
+// snapshotWorkingTree returns a commit, parented on HEAD but on no branch,
+// holding the working tree as git add -A would stage it, or HEAD itself if
+// nothing has changed. The real index is left alone.
+func snapshotWorkingTree(ctx context.Context, repo gitRepo, message string) (string, error) {
+	head, err := repo.run("rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	indexPath, err := repo.run("rev-parse", "--git-path", "humanlayer-patch-index-"+uuid.New().String())
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = repo.host.Command(ctx, repo.dir, "rm", "-f", indexPath).Run() }()
+
+	env := []string{"GIT_INDEX_FILE=" + indexPath}
+	for _, args := range [][]string{{"read-tree", "HEAD"}, {"add", "-A"}} {
+		if _, _, err := repo.exec(ctx, nil, env, args...); err != nil {
+			return "", err
+		}
+	}
+	tree, _, err := repo.exec(ctx, nil, env, "write-tree")
+	if err != nil {
+		return "", err
+	}
+	tree = strings.TrimSpace(tree)
+	if headTree, _ := repo.run("rev-parse", "HEAD^{tree}"); tree == headTree {
+		return head, nil
+	}
+
+	// commit-tree needs an identity even though the commit goes nowhere
+	args := []string{"commit-tree", tree, "-p", head, "-m", message}
+	if email, _ := repo.run("config", "user.email"); email == "" {
+		args = append([]string{"-c", "user.name=HumanLayer", "-c", "user.email=humanlayer@localhost"}, args...)
+	}
+	commit, _, err := repo.exec(ctx, nil, nil, args...)
+	return strings.TrimSpace(commit), err
+}
+
+// HandleApplyPatch applies an uploaded patch or mbox series to the
+// session's working directory
+func (h *GitHandler) HandleApplyPatch(c *gin.Context) {
+	sessionID := c.Param("id")
+	req, err := bindApplyPatch(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid patch upload: %v", err)})
+		return
+	}
+	if strings.TrimSpace(req.Patch) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No patch provided"})
+		return
+	}
+
+	session, err := h.store.GetSession(c.Request.Context(), sessionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+	repo, ok := h.selectRepo(c, session)
+	if !ok {
+		return
+	}
+	if !isGitRepo(repo) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Not a git repository"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	response := ApplyPatchResponse{Files: []string{}}
+	numstat, stderr, err := repo.exec(ctx, strings.NewReader(req.Patch), nil, "apply", "--numstat", "-z")
+	if err != nil {
+		response.Error = fmt.Sprintf("Invalid patch: %s", strings.TrimSpace(stderr))
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	response.Files = patchFiles(numstat)
+
+	if !req.Check {
+		unlock := h.lockForMutation(c, session, req.Force, "patch apply")
+		if unlock == nil {
+			return
+		}
+		defer unlock()
+
+		// The patch's size stands in for what it adds
+		if h.diskGuard != nil {
+			if err := h.diskGuard.Check(ctx, diskguard.Write{
+				Operation:  "patch apply",
+				SessionID:  session.ID,
+				Host:       repo.host,
+				Dir:        repo.dir,
+				Size:       int64(len(req.Patch)),
+				Repository: req.Commit,
+			}); err != nil {
+				response.Error = err.Error()
+				c.JSON(http.StatusInsufficientStorage, response)
+				return
+			}
+		}
+	}
+
+	threeWay := req.ThreeWay == nil || *req.ThreeWay
+	if req.Commit && !req.Check {
+		h.applyMailbox(c, repo, req.Patch, threeWay, response)
+		return
+	}
+
+	// --3way works through the index, staging what it applies; a plain
+	// apply only touches the working tree
+	args := []string{"apply"}
+	if req.Check {
+		args = append(args, "--check")
+	}
+	if threeWay {
+		args = append(args, "--3way")
+	}
+	_, stderr, err = repo.exec(ctx, strings.NewReader(req.Patch), nil, args...)
+	if err == nil {
+		response.Success = true
+		c.JSON(http.StatusOK, response)
+		return
+	}
+	// A 3-way merge that conflicts still applies, leaving markers
+	if !req.Check {
+		response.Conflicts = conflictedFiles(repo)
+	}
+	if len(response.Conflicts) > 0 {
+		response.Error = fmt.Sprintf("Patch applied with conflicts in %d files", len(response.Conflicts))
+		c.JSON(http.StatusConflict, response)
+		return
+	}
+	response.Error = fmt.Sprintf("Patch does not apply: %s", strings.TrimSpace(stderr))
+	c.JSON(http.StatusUnprocessableEntity, response)
+}
+
+// applyMailbox recreates the commits of an mbox series with git am. A
+// series that fails part way is aborted, leaving the branch as it was.
+func (h *GitHandler) applyMailbox(c *gin.Context, repo gitRepo, patch string, threeWay bool, response ApplyPatchResponse) {
+	ctx := c.Request.Context()
+	before, err := repo.run("rev-parse", "HEAD")
+	if err != nil {
+		response.Error = fmt.Sprintf("Failed to read HEAD: %v", err)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	args := []string{"am", "--keep-cr"}
+	if threeWay {
+		args = append(args, "--3way")
+	}
+	_, stderr, err := repo.exec(ctx, strings.NewReader(patch), nil, args...)
+	if err != nil {
+		response.Conflicts = conflictedFiles(repo)
+		_, _ = repo.run("am", "--abort")
+		if len(response.Conflicts) > 0 {
+			response.Error = fmt.Sprintf("Patch series conflicts in %d files; nothing was committed", len(response.Conflicts))
+			c.JSON(http.StatusConflict, response)
+			return
+		}
+		response.Error = fmt.Sprintf("Patch series does not apply: %s", strings.TrimSpace(stderr))
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	hashes, _ := repo.run("rev-list", "--reverse", "--abbrev-commit", before+"..HEAD")
+	if hashes != "" {
+		response.CommitHashes = strings.Split(hashes, "\n")
+	}
+	response.Success = true
+	c.JSON(http.StatusOK, response)
+}
+
+// bindApplyPatch reads a JSON request, or a raw patch body with the options
+// in the query string
+func bindApplyPatch(c *gin.Context) (ApplyPatchRequest, error) {
+	var req ApplyPatchRequest
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxPatchSize)
+	if c.ContentType() == gin.MIMEJSON {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return req, errors.New("invalid request body")
+		}
+		return req, nil
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return req, fmt.Errorf("failed to read patch: %w", err)
+	}
+	req.Patch = string(body)
+	if value := c.Query("threeWay"); value != "" {
+		threeWay := value == "true"
+		req.ThreeWay = &threeWay
+	}
+	req.Check = c.Query("check") == "true"
+	req.Commit = c.Query("commit") == "true"
+	req.Force = c.Query("force") == "true"
+	return req, nil
+}
+
+// patchFiles reads the paths from git apply --numstat -z output
+func patchFiles(numstat string) []string {
+	files := []string{}
+	seen := make(map[string]bool)
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+	fields := strings.Split(numstat, "\x00")
+	for i := 0; i < len(fields); i++ {
+		// added TAB deleted TAB path, or for renames an empty path
+		// followed by the old and new paths
+		parts := strings.SplitN(fields[i], "\t", 3)
+		if len(parts) < 3 {
+			continue
+		}
+		if parts[2] == "" && i+2 < len(fields) {
+			add(fields[i+2])
+			i += 2
+			continue
+		}
+		add(parts[2])
+	}
+	return files
+}
+
+// conflictedFiles lists the unmerged paths in the index
+func conflictedFiles(repo gitRepo) []string {
+	output, err := repo.run("diff", "--name-only", "--diff-filter=U")
+	if err != nil || output == "" {
+		return nil
+	}
+	return strings.Split(output, "\n")
+}
+
+// stream runs git with its stdout copied to w as it's produced
+func (r gitRepo) stream(ctx context.Context, w io.Writer, args ...string) error {
+	_, _, err := gitcmd.Run(ctx, gitcmd.Cmd{Host: r.host, Dir: r.dir, Args: args, Stdout: w})
+	return err
+}
+
+// exec runs git with stdin and extra environment variables, returning
+// stdout as is and stderr
+func (r gitRepo) exec(ctx context.Context, stdin io.Reader, env []string, args ...string) (string, string, error) {
+	return gitcmd.Run(ctx, gitcmd.Cmd{Host: r.host, Dir: r.dir, Args: args, Env: env, Stdin: stdin})
+}
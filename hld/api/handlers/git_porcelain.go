@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// porcelainStatus is the parsed output of
+// git status --porcelain=v2 -z --branch
+type porcelainStatus struct {
+	Branch  porcelainBranch
+	Entries []porcelainEntry
+}
+
+// porcelainBranch holds the # branch.* headers
+type porcelainBranch struct {
+	// OID is the HEAD commit, or "(initial)" before the first commit
+	OID string
+	// Head is the branch name, or "(detached)"
+	Head     string
+	Upstream string
+	Ahead    int
+	Behind   int
+}
+
+// porcelainEntry is one path git status reports
+type porcelainEntry struct {
+	// Kind is '1' (changed), '2' (renamed or copied), 'u' (unmerged),
+	// '?' (untracked) or '!' (ignored)
+	Kind byte
+	// Index and WorkTree are the XY status codes, '.' when unchanged
+	Index    byte
+	WorkTree byte
+	// Submodule is set for submodules
+	Submodule *GitSubmoduleState
+	// Octal file modes in HEAD, the index and the working tree
+	ModeHead     string
+	ModeIndex    string
+	ModeWorkTree string
+	// Similarity is the rename or copy score, as a percentage
+	Similarity int
+	Path       string
+	// OrigPath is where a renamed or copied path came from
+	OrigPath string
+}
+
+var errMalformedPorcelain = errors.New("malformed git status output")
+
+// parsePorcelainV2 parses git status --porcelain=v2 -z output. Records are
+// NUL-terminated, and a rename or copy record is followed by one more
+// holding the original path, so paths may contain anything but NUL.
+func parsePorcelainV2(out string) (porcelainStatus, error) {
+	var status porcelainStatus
+	for len(out) > 0 {
+		record, rest, ok := strings.Cut(out, "\x00")
+		if !ok {
+			return status, fmt.Errorf("%w: unterminated record %q", errMalformedPorcelain, record)
+		}
+		out = rest
+		if record == "" {
+			continue
+		}
+
+		if len(record) < 2 || record[1] != ' ' {
+			return status, fmt.Errorf("%w: %q", errMalformedPorcelain, record)
+		}
+		kind, fields := record[0], record[2:]
+		switch kind {
+		case '#':
+			if err := status.Branch.parseHeader(fields); err != nil {
+				return status, err
+			}
+			continue
+		case '?', '!':
+			status.Entries = append(status.Entries, porcelainEntry{Kind: kind, Path: fields})
+			continue
+		case '1', '2', 'u':
+		default:
+			return status, fmt.Errorf("%w: unknown record type %q", errMalformedPorcelain, kind)
+		}
+
+		// <XY> <sub> then the modes and hashes, which differ by kind
+		entry := porcelainEntry{Kind: kind}
+		var xy, sub string
+		xy, fields = nextField(fields)
+		sub, fields = nextField(fields)
+		if len(xy) != 2 || len(sub) != 4 {
+			return status, fmt.Errorf("%w: %q", errMalformedPorcelain, record)
+		}
+		entry.Index, entry.WorkTree = xy[0], xy[1]
+		if sub[0] == 'S' {
+			entry.Submodule = &GitSubmoduleState{
+				CommitChanged:    sub[1] == 'C',
+				TrackedChanges:   sub[2] == 'M',
+				UntrackedChanges: sub[3] == 'U',
+			}
+		}
+
+		switch kind {
+		case '1', '2':
+			entry.ModeHead, fields = nextField(fields)
+			entry.ModeIndex, fields = nextField(fields)
+			entry.ModeWorkTree, fields = nextField(fields)
+			_, fields = nextField(fields) // hH
+			_, fields = nextField(fields) // hI
+		case 'u':
+			for range 3 {
+				_, fields = nextField(fields) // m1 m2 m3, the stages' modes
+			}
+			entry.ModeWorkTree, fields = nextField(fields)
+			for range 3 {
+				_, fields = nextField(fields) // h1 h2 h3
+			}
+		}
+		if kind == '2' {
+			var score string
+			score, fields = nextField(fields)
+			if len(score) < 2 || (score[0] != 'R' && score[0] != 'C') {
+				return status, fmt.Errorf("%w: bad score in %q", errMalformedPorcelain, record)
+			}
+			similarity, err := strconv.Atoi(score[1:])
+			if err != nil {
+				return status, fmt.Errorf("%w: bad score in %q", errMalformedPorcelain, record)
+			}
+			entry.Similarity = similarity
+
+			entry.OrigPath, out, ok = strings.Cut(out, "\x00")
+			if !ok {
+				return status, fmt.Errorf("%w: missing original path for %q", errMalformedPorcelain, record)
+			}
+		}
+		if fields == "" || entry.ModeWorkTree == "" {
+			return status, fmt.Errorf("%w: %q", errMalformedPorcelain, record)
+		}
+		entry.Path = fields
+		status.Entries = append(status.Entries, entry)
+	}
+	return status, nil
+}
+
+// nextField splits the first space-separated field from s
+func nextField(s string) (string, string) {
+	field, rest, _ := strings.Cut(s, " ")
+	return field, rest
+}
+
+func (b *porcelainBranch) parseHeader(header string) error {
+	name, value := nextField(header)
+	switch name {
+	case "branch.oid":
+		b.OID = value
+	case "branch.head":
+		b.Head = value
+	case "branch.upstream":
+		b.Upstream = value
+	case "branch.ab":
+		ahead, behind := nextField(value)
+		var err1, err2 error
+		b.Ahead, err1 = strconv.Atoi(strings.TrimPrefix(ahead, "+"))
+		b.Behind, err2 = strconv.Atoi(strings.TrimPrefix(behind, "-"))
+		if err1 != nil || err2 != nil {
+			return fmt.Errorf("%w: %q", errMalformedPorcelain, header)
+		}
+	}
+	// Other headers, such as stash counts, aren't needed
+	return nil
+}
+
+// porcelainStatusNames names XY status codes for GitFile.Status
+var porcelainStatusNames = map[byte]string{
+	'A': "added",
+	'M': "modified",
+	'D': "deleted",
+	'R': "renamed",
+	'C': "copied",
+	'T': "typechanged",
+}
+
+// gitFile describes entry as changed by code, one side of its XY status
+func (e porcelainEntry) gitFile(code byte, modeFrom, modeTo string) GitFile {
+	file := GitFile{Path: e.Path, Status: porcelainStatusNames[code], Submodule: e.Submodule}
+	if code == 'R' || code == 'C' {
+		file.OldPath = e.OrigPath
+		file.Similarity = e.Similarity
+	}
+	if modeFrom != modeTo && modeFrom != "000000" && modeTo != "000000" {
+		file.OldMode, file.NewMode = modeFrom, modeTo
+	}
+	return file
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/humanlayer/humanlayer/hld/workspace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleHash = "0123456789abcdef0123456789abcdef01234567"
+
+func TestParsePorcelainV2(t *testing.T) {
+	h := sampleHash
+	out := strings.Join([]string{
+		"# branch.oid " + h,
+		"# branch.head feature/x",
+		"# branch.upstream origin/feature/x",
+		"# branch.ab +2 -1",
+		"1 .M N... 100644 100644 100644 " + h + " " + h + " leading space.txt",
+		"1 M. N... 100644 100755 100755 " + h + " " + h + " run.sh",
+		"2 R. N... 100644 100644 100644 " + h + " " + h + " R87 new -> name.txt", "old -> name.txt",
+		"2 C. N... 100644 100644 100644 " + h + " " + h + " C100 copy.txt", "orig.txt",
+		"u UU N... 100644 100644 100644 100644 " + h + " " + h + " " + h + " conflict.go",
+		"1 .M SCMU 160000 160000 160000 " + h + " " + h + " vendor/lib",
+		"? line\nbreak.txt",
+		"! ignored.log",
+		"",
+	}, "\x00")
+
+	status, err := parsePorcelainV2(out)
+	require.NoError(t, err)
+	assert.Equal(t, porcelainBranch{OID: h, Head: "feature/x", Upstream: "origin/feature/x", Ahead: 2, Behind: 1}, status.Branch)
+	require.Len(t, status.Entries, 8)
+
+	assert.Equal(t, "leading space.txt", status.Entries[0].Path)
+	assert.Equal(t, byte('.'), status.Entries[0].Index)
+	assert.Equal(t, byte('M'), status.Entries[0].WorkTree)
+
+	modeChange := status.Entries[1].gitFile('M', status.Entries[1].ModeHead, status.Entries[1].ModeIndex)
+	assert.Equal(t, GitFile{Path: "run.sh", Status: "modified", OldMode: "100644", NewMode: "100755"}, modeChange)
+
+	rename := status.Entries[2]
+	assert.Equal(t, "new -> name.txt", rename.Path)
+	assert.Equal(t, "old -> name.txt", rename.OrigPath)
+	assert.Equal(t, 87, rename.Similarity)
+	assert.Equal(t, "orig.txt", status.Entries[3].OrigPath)
+	assert.Equal(t, 100, status.Entries[3].Similarity)
+
+	assert.Equal(t, byte('u'), status.Entries[4].Kind)
+	assert.Equal(t, "conflict.go", status.Entries[4].Path)
+
+	assert.Equal(t, &GitSubmoduleState{CommitChanged: true, TrackedChanges: true, UntrackedChanges: true}, status.Entries[5].Submodule)
+	assert.Equal(t, "line\nbreak.txt", status.Entries[6].Path)
+	assert.Equal(t, byte('!'), status.Entries[7].Kind)
+}
+
+func TestParsePorcelainV2Malformed(t *testing.T) {
+	for _, out := range []string{
+		"1 .M N... 100644\x00",
+		"2 R. N... 100644 100644 100644 a b R50 new\x00",
+		"2 R. N... 100644 100644 100644 a b X50 new\x00old\x00",
+		"# branch.ab +x -1\x00",
+		"x what\x00",
+		"? unterminated",
+	} {
+		_, err := parsePorcelainV2(out)
+		assert.ErrorIs(t, err, errMalformedPorcelain, "%q", out)
+	}
+}
+
+func TestGetGitStatusEdgeCases(t *testing.T) {
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	write := func(name, content string, mode os.FileMode) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), mode))
+	}
+
+	git("init", "-q", "-b", "main")
+	write("a.txt", "a\n", 0644)
+	write("old -> name.txt", strings.Repeat("rename me\n", 20), 0644)
+	write("run.sh", "echo hi\n", 0644)
+	git("add", "-A")
+	git("commit", "-q", "-m", "initial")
+
+	// A working-tree-only change sorts first, so its status starts with a
+	// space that trimming the output used to eat
+	write("a.txt", "changed\n", 0644)
+	git("mv", "old -> name.txt", "new -> name.txt")
+	require.NoError(t, os.Chmod(filepath.Join(dir, "run.sh"), 0755))
+	git("add", "run.sh")
+	write("line\nbreak.txt", "x\n", 0644)
+
+	status, err := getGitStatus(gitRepo{host: workspace.Local{}, dir: dir})
+	require.NoError(t, err)
+	assert.Equal(t, "main", status.Branch)
+	assert.Equal(t, []GitFile{{Path: "a.txt", Status: "modified"}}, status.Unstaged)
+	assert.ElementsMatch(t, []GitFile{
+		{Path: "new -> name.txt", Status: "renamed", OldPath: "old -> name.txt", Similarity: 100},
+		{Path: "run.sh", Status: "modified", OldMode: "100644", NewMode: "100755"},
+	}, status.Staged)
+	assert.Equal(t, []GitFile{{Path: "line\nbreak.txt", Status: "untracked"}}, status.Untracked)
+	assert.True(t, status.HasChanges)
+}
+
+// samplePorcelain is status output for n files in a mix of states
+func samplePorcelain(n int) string {
+	var b strings.Builder
+	b.WriteString("# branch.oid " + sampleHash + "\x00# branch.head main\x00# branch.ab +1 -0\x00")
+	for i := range n {
+		switch i % 4 {
+		case 0:
+			fmt.Fprintf(&b, "1 .M N... 100644 100644 100644 %s %s src/pkg%d/file.go\x00", sampleHash, sampleHash, i)
+		case 1:
+			fmt.Fprintf(&b, "1 M. N... 100644 100644 100644 %s %s src/pkg%d/staged.go\x00", sampleHash, sampleHash, i)
+		case 2:
+			fmt.Fprintf(&b, "2 R. N... 100644 100644 100644 %s %s R95 src/pkg%d/new.go\x00src/pkg%d/old.go\x00", sampleHash, sampleHash, i, i)
+		case 3:
+			fmt.Fprintf(&b, "? src/pkg%d/untracked.go\x00", i)
+		}
+	}
+	return b.String()
+}
+
+func BenchmarkParsePorcelainV2(b *testing.B) {
+	for _, n := range []int{10, 1000} {
+		out := samplePorcelain(n)
+		b.Run(fmt.Sprintf("files=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(out)))
+			for b.Loop() {
+				if _, err := parsePorcelainV2(out); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func FuzzParsePorcelainV2(f *testing.F) {
+	f.Add(samplePorcelain(8))
+	f.Add("2 C. N... 100644 100644 100644 a b C100 copy\x00orig\x00")
+	f.Add("u UU N... 100644 100644 100644 100644 a b c conflict\x00")
+	f.Add("1 .M SC.. 160000 160000 160000 a b sub\x00")
+	f.Fuzz(func(t *testing.T, out string) {
+		status, err := parsePorcelainV2(out)
+		if err != nil {
+			return
+		}
+		for _, entry := range status.Entries {
+			if strings.Contains(entry.Path, "\x00") || strings.Contains(entry.OrigPath, "\x00") {
+				t.Errorf("path with NUL: %+v", entry)
+			}
+			if entry.Kind == '2' && entry.Similarity < 0 {
+				t.Errorf("negative similarity: %+v", entry)
+			}
+		}
+	})
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/internal/version"
+	"github.com/humanlayer/humanlayer/hld/provenance"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// maxProvenanceCommits caps the commits reported without a base
+const maxProvenanceCommits = 100
+
+// CommitProvenance is one commit's provenance and whether it verifies
+type CommitProvenance struct {
+	Hash       string             `json:"hash"`
+	Subject    string             `json:"subject"`
+	Provenance *provenance.Record `json:"provenance,omitempty"`
+	// Status is verified, unsigned, invalid or missing
+	Status string `json:"status"`
+}
+
+// ProvenanceResponse reports the provenance of a branch's commits
+type ProvenanceResponse struct {
+	Ref  string `json:"ref"`
+	Base string `json:"base,omitempty"`
+	// Commits, newest first
+	Commits []CommitProvenance `json:"commits"`
+	// Counts commits by status
+	Summary map[string]int `json:"summary"`
+}
+
+// notesRef is where provenance notes are kept
+func (h *GitHandler) notesRef() string {
+	if h.provenance.NotesRef != "" {
+		return h.provenance.NotesRef
+	}
+	return config.DefaultProvenanceNotesRef
+}
+
+// provenanceKey reads the signing key from the configured environment variable
+func (h *GitHandler) provenanceKey() []byte {
+	name := h.provenance.KeyEnv
+	if name == "" {
+		name = config.DefaultProvenanceKeyEnv
+	}
+	return []byte(os.Getenv(name))
+}
+
+// signProvenance returns the session's signed provenance for a commit of
+// what's staged
+func (h *GitHandler) signProvenance(repo gitRepo, session *store.Session) (provenance.Record, error) {
+	tree, err := repo.run("write-tree")
+	if err != nil {
+		return provenance.Record{}, err
+	}
+	return provenance.New(session, version.GetVersion()).Sign(h.provenanceKey(), tree), nil
+}
+
+// HandleGetProvenance reports the provenance of the commits on ref (default
+// HEAD) since base, verifying signatures. Without a base, the upstream
+// branch is used, else the last 100 commits are reported.
+func (h *GitHandler) HandleGetProvenance(c *gin.Context) {
+	sessionID := c.Param("id")
+	session, err := h.store.GetSession(c.Request.Context(), sessionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+	repo, ok := h.selectRepo(c, session)
+	if !ok {
+		return
+	}
+	if !isGitRepo(repo) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Not a git repository"})
+		return
+	}
+	ref := c.DefaultQuery("ref", "HEAD")
+	base := c.Query("base")
+	if base == "" && ref == "HEAD" {
+		base, _ = repo.run("rev-parse", "--abbrev-ref", "@{upstream}")
+	}
+	for _, rev := range []string{ref, base} {
+		if rev == "" {
+			continue
+		}
+		if strings.HasPrefix(rev, "-") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid revision %q", rev)})
+			return
+		}
+		if _, err := repo.run("rev-parse", "--verify", "--quiet", rev+"^{commit}"); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unknown revision %q", rev)})
+			return
+		}
+	}
+
+	args := []string{"log", "--notes=" + h.notesRef(), "--format=%H%x1f%T%x1f%s%x1f%B%x1f%N%x1e"}
+	if base != "" {
+		args = append(args, base+".."+ref)
+	} else {
+		args = append(args, fmt.Sprintf("-%d", maxProvenanceCommits), ref)
+	}
+	output, err := repo.run(args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to read commits: %v", err)})
+		return
+	}
+
+	key := h.provenanceKey()
+	response := ProvenanceResponse{Ref: ref, Base: base, Commits: []CommitProvenance{}, Summary: map[string]int{}}
+	for _, entry := range strings.Split(output, "\x1e") {
+		fields := strings.Split(strings.TrimLeft(entry, "\n"), "\x1f")
+		if len(fields) < 5 {
+			continue
+		}
+		commit := CommitProvenance{Hash: fields[0], Subject: fields[2], Status: provenance.StatusMissing}
+		// Trailers win over a note, which can be rewritten without
+		// rewriting the commit
+		record, ok := provenance.Parse(fields[3])
+		if !ok {
+			record, ok = provenance.Parse(fields[4])
+		}
+		if ok {
+			commit.Provenance = &record
+			commit.Status = record.Verify(key, fields[1])
+		}
+		response.Commits = append(response.Commits, commit)
+		response.Summary[commit.Status]++
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/llm"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// RepoAll selects every repository of a session, where an endpoint allows it
+const RepoAll = "all"
+
+// sessionRepository is one of the directories a session works in
+type sessionRepository struct {
+	name    string
+	path    string
+	primary bool
+	repo    gitRepo
+}
+
+// sessionRepositories lists a session's working directory, then its
+// additional directories. Each is named by its base name, or by its path
+// where two share a base name. Relative additional directories are taken
+// from the working directory, as claude does.
+func sessionRepositories(session *store.Session, mappings []config.PathMapping) []sessionRepository {
+	if session.WorkingDir == "" {
+		return nil
+	}
+	primary := sessionRepo(session, mappings)
+	repos := []sessionRepository{{path: session.WorkingDir, primary: true, repo: primary}}
+
+	var dirs []string
+	if session.AdditionalDirectories != "" {
+		_ = json.Unmarshal([]byte(session.AdditionalDirectories), &dirs)
+	}
+	seen := map[string]bool{session.WorkingDir: true}
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		repo := gitRepo{host: primary.host}
+		if session.SSHHost != "" {
+			if !path.IsAbs(dir) {
+				dir = path.Join(session.WorkingDir, dir)
+			}
+			repo.dir = dir
+		} else {
+			if !filepath.IsAbs(dir) {
+				dir = filepath.Join(session.WorkingDir, dir)
+			}
+			repo.dir = config.RemapPath(mappings, dir)
+		}
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		repos = append(repos, sessionRepository{path: dir, repo: repo})
+	}
+
+	names := make(map[string]int, len(repos))
+	for _, r := range repos {
+		names[path.Base(filepath.ToSlash(r.path))]++
+	}
+	for i := range repos {
+		name := path.Base(filepath.ToSlash(repos[i].path))
+		if names[name] > 1 {
+			name = repos[i].path
+		}
+		repos[i].name = name
+	}
+	return repos
+}
+
+// selectRepo picks the repository named by the repo query parameter, by
+// name or path, defaulting to the working directory. It responds with an
+// error and reports false if there's no such repository.
+func (h *GitHandler) selectRepo(c *gin.Context, session *store.Session) (gitRepo, bool) {
+	repos := sessionRepositories(session, h.pathMappings)
+	if len(repos) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Session has no working directory"})
+		return gitRepo{}, false
+	}
+	selector := c.Query("repo")
+	if selector == "" {
+		return repos[0].repo, true
+	}
+	if selector == RepoAll {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Select one repository; this endpoint doesn't accept repo=all"})
+		return gitRepo{}, false
+	}
+	for _, r := range repos {
+		if selector == r.name || selector == r.path {
+			return r.repo, true
+		}
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unknown repository %q", selector)})
+	return gitRepo{}, false
+}
+
+// RepositoryStatus is the git status of one of a session's directories
+type RepositoryStatus struct {
+	// Name selects the repository as ?repo= on the git endpoints
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Primary bool   `json:"primary"`
+	// Status is unset where the directory isn't a git repository
+	Status *GitStatusResponse `json:"status,omitempty"`
+	Error  string             `json:"error,omitempty"`
+}
+
+// RepositoriesResponse is the combined status of a session's repositories
+type RepositoriesResponse struct {
+	Repositories []RepositoryStatus `json:"repositories"`
+	// HasChanges is set when any repository has changes
+	HasChanges bool `json:"hasChanges"`
+}
+
+// HandleGetRepositories returns the git status of each of the session's
+// directories: its working directory, then its additional directories
+func (h *GitHandler) HandleGetRepositories(c *gin.Context) {
+	session, err := h.store.GetSession(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+	repos := sessionRepositories(session, h.pathMappings)
+	if len(repos) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Session has no working directory"})
+		return
+	}
+
+	response := RepositoriesResponse{Repositories: make([]RepositoryStatus, 0, len(repos))}
+	for _, r := range repos {
+		entry := RepositoryStatus{Name: r.name, Path: r.path, Primary: r.primary}
+		if !isGitRepo(r.repo) {
+			entry.Error = "Not a git repository"
+		} else if status, err := getGitStatus(r.repo); err != nil {
+			entry.Error = fmt.Sprintf("Failed to get git status: %v", err)
+		} else {
+			annotateLFS(c.Request.Context(), r.repo, status, h.largeFileSize())
+			entry.Status = status
+			response.HasChanges = response.HasChanges || status.HasChanges
+		}
+		response.Repositories = append(response.Repositories, entry)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// RepositoryCommitPlan is the commits suggested for one repository
+type RepositoryCommitPlan struct {
+	Name string                         `json:"name"`
+	Path string                         `json:"path"`
+	Plan *GenerateCommitMessageResponse `json:"plan,omitempty"`
+	// Error is set where generation failed for this repository
+	Error string `json:"error,omitempty"`
+}
+
+// CommitPlansResponse groups commit suggestions by repository. Commit each
+// with POST /sessions/{id}/git/commit?repo={name}.
+type CommitPlansResponse struct {
+	Repositories []RepositoryCommitPlan `json:"repositories"`
+}
+
+// generateCommitPlans answers a generate-commit-message request for all of
+// the session's repositories, skipping those without changes
+func (h *GitHandler) generateCommitPlans(c *gin.Context, session *store.Session, req GenerateCommitMessageRequest, locale string) {
+	if req.Stream {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Streaming isn't available with repo=all"})
+		return
+	}
+	repos := sessionRepositories(session, h.pathMappings)
+	if len(repos) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Session has no working directory"})
+		return
+	}
+
+	generate := func(ctx context.Context) (*CommitPlansResponse, error) {
+		response := &CommitPlansResponse{Repositories: []RepositoryCommitPlan{}}
+		for _, r := range repos {
+			if !isGitRepo(r.repo) {
+				continue
+			}
+			plan, err := h.generateCommitMessage(ctx, session.ID, r.repo, req, locale, nil)
+			if errors.Is(err, errNoChanges) {
+				continue
+			}
+			entry := RepositoryCommitPlan{Name: r.name, Path: r.path, Plan: plan}
+			if err != nil {
+				_, entry.Error = commitMessageError(err)
+			}
+			response.Repositories = append(response.Repositories, entry)
+		}
+		if len(response.Repositories) == 0 {
+			return nil, errNoChanges
+		}
+		return response, nil
+	}
+
+	if wantsJob(c, h.jobs) {
+		submitJob(c, h.jobs, string(llm.TaskCommitMessage), session.ID, func(ctx context.Context) (any, error) {
+			return generate(ctx)
+		})
+		return
+	}
+	response, err := generate(c.Request.Context())
+	if err != nil {
+		code, message := commitMessageError(err)
+		c.JSON(code, gin.H{"error": message})
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
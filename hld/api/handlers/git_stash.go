@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// maxStashes caps the stashes listed, newest first, since each costs a git
+// stash show for its file count
+const maxStashes = 100
+
+// GitStash is an entry of the repository's stash
+type GitStash struct {
+	// Index is n in stash@{n}; it shifts as newer stashes are pushed and
+	// older ones dropped, so mutations can pass Commit to be sure
+	Index  int    `json:"index"`
+	Ref    string `json:"ref"`
+	Commit string `json:"commit"`
+	// Branch is where the changes were stashed, empty from a detached HEAD
+	Branch    string    `json:"branch,omitempty"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"createdAt"`
+	// FileCount includes untracked files stashed with the changes
+	FileCount int `json:"fileCount"`
+}
+
+// StashesResponse lists a repository's stash
+type StashesResponse struct {
+	Stashes   []GitStash `json:"stashes"`
+	Truncated bool       `json:"truncated,omitempty"`
+}
+
+// CreateStashRequest stashes a session's uncommitted changes
+type CreateStashRequest struct {
+	// Message defaults to one naming the session and branch
+	Message string `json:"message,omitempty"`
+	// Force stashes even while the session is running
+	Force bool `json:"force,omitempty"`
+}
+
+// ApplyStashRequest applies a stash to a session's working tree
+type ApplyStashRequest struct {
+	// Commit, when set, refuses the request if the stash at the index is no
+	// longer this one
+	Commit string `json:"commit,omitempty"`
+	// Index restores what was staged as staged, as git stash apply --index
+	Index bool `json:"index,omitempty"`
+	// Force applies even while the session is running
+	Force bool `json:"force,omitempty"`
+}
+
+// StashResponse reports a stash mutation
+type StashResponse struct {
+	Success bool      `json:"success"`
+	Stash   *GitStash `json:"stash,omitempty"`
+	// Conflicts are the files applying the stash left unmerged; a pop
+	// keeps the stash when there are any
+	Conflicts []string `json:"conflicts,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// stashRepo returns the session and repository of a stash request, or
+// responds with why there are none
+func (h *GitHandler) stashRepo(c *gin.Context) (*store.Session, gitRepo, bool) {
+	session, err := h.store.GetSession(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return nil, gitRepo{}, false
+	}
+	repo, ok := h.selectRepo(c, session)
+	if !ok {
+		return nil, gitRepo{}, false
+	}
+	if !isGitRepo(repo) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Not a git repository"})
+		return nil, gitRepo{}, false
+	}
+	return session, repo, true
+}
+
+// HandleListStashes lists the stash of the session's repository, newest
+// first
+func (h *GitHandler) HandleListStashes(c *gin.Context) {
+	session, repo, ok := h.stashRepo(c)
+	if !ok {
+		return
+	}
+	stashes, err := listStashes(repo, maxStashes+1)
+	if err != nil {
+		slog.Error("failed to list stashes", "session_id", session.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list stashes"})
+		return
+	}
+	response := StashesResponse{Stashes: stashes}
+	if len(stashes) > maxStashes {
+		response.Stashes, response.Truncated = stashes[:maxStashes], true
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// listStashes returns up to limit stash entries, newest first
+func listStashes(repo gitRepo, limit int) ([]GitStash, error) {
+	out, err := repo.run("stash", "list", fmt.Sprintf("--max-count=%d", limit), "--format=%gd%x00%H%x00%ct%x00%gs")
+	if err != nil {
+		return nil, err
+	}
+	stashes := []GitStash{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(line, "\x00", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		stash := GitStash{Index: len(stashes), Ref: fields[0], Commit: fields[1]}
+		if seconds, err := strconv.ParseInt(fields[2], 10, 64); err == nil {
+			stash.CreatedAt = time.Unix(seconds, 0)
+		}
+		stash.Branch, stash.Message = parseStashSubject(fields[3])
+		stash.FileCount = stashFileCount(repo, stash.Commit)
+		stashes = append(stashes, stash)
+	}
+	return stashes, nil
+}
+
+// parseStashSubject splits a stash's reflog subject, "On main: message" for
+// a stash with a message or "WIP on main: abc1234 subject" without one,
+// into its branch and message
+func parseStashSubject(subject string) (string, string) {
+	rest, found := strings.CutPrefix(subject, "On ")
+	if !found {
+		if rest, found = strings.CutPrefix(subject, "WIP on "); !found {
+			return "", subject
+		}
+	}
+	branch, message, found := strings.Cut(rest, ": ")
+	if !found {
+		return "", subject
+	}
+	if branch == "(no branch)" {
+		branch = ""
+	}
+	return branch, message
+}
+
+func stashFileCount(repo gitRepo, commit string) int {
+	out, err := repo.run("stash", "show", "--include-untracked", "--name-only", commit)
+	if err != nil || out == "" {
+		return 0
+	}
+	return len(strings.Split(out, "\n"))
+}
+
+// stashAt returns the entry at stash@{index}
+func stashAt(repo gitRepo, index int) (*GitStash, error) {
+	stashes, err := listStashes(repo, index+1)
+	if err != nil {
+		return nil, err
+	}
+	if index >= len(stashes) {
+		return nil, nil
+	}
+	return &stashes[index], nil
+}
+
+// HandleCreateStash stashes the working tree, untracked files included
+func (h *GitHandler) HandleCreateStash(c *gin.Context) {
+	var req CreateStashRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+			return
+		}
+	}
+	session, repo, ok := h.stashRepo(c)
+	if !ok {
+		return
+	}
+	unlock := h.lockForMutation(c, session, req.Force, "stash")
+	if unlock == nil {
+		return
+	}
+	defer unlock()
+
+	status, err := getGitStatus(repo)
+	if err != nil {
+		slog.Error("failed to get git status", "session_id", session.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get git status"})
+		return
+	}
+	if !status.HasChanges {
+		c.JSON(http.StatusConflict, StashResponse{Error: "No changes to stash"})
+		return
+	}
+	if req.Message == "" {
+		branch, _ := repo.run("symbolic-ref", "--short", "-q", "HEAD")
+		req.Message = fmt.Sprintf("HumanLayer: changes of session %s on %s", session.ID, describeHead(branch))
+	}
+	if _, err := stashChanges(c.Request.Context(), repo, req.Message); err != nil {
+		slog.Error("failed to stash changes", "session_id", session.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, StashResponse{Error: fmt.Sprintf("Failed to stash changes: %v", err)})
+		return
+	}
+	stash, err := stashAt(repo, 0)
+	if err != nil || stash == nil {
+		slog.Error("failed to read new stash", "session_id", session.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, StashResponse{Error: "Failed to read the new stash"})
+		return
+	}
+	slog.Info("stashed session changes", "session_id", session.ID, "stash", stash.Commit, "files", stash.FileCount)
+	c.JSON(http.StatusCreated, StashResponse{Success: true, Stash: stash})
+}
+
+// HandleApplyStash applies a stash, keeping it
+func (h *GitHandler) HandleApplyStash(c *gin.Context) {
+	h.applyStash(c, false)
+}
+
+// HandlePopStash applies a stash and drops it, unless applying it conflicts
+func (h *GitHandler) HandlePopStash(c *gin.Context) {
+	h.applyStash(c, true)
+}
+
+func (h *GitHandler) applyStash(c *gin.Context, pop bool) {
+	var req ApplyStashRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+			return
+		}
+	}
+	operation := "stash apply"
+	if pop {
+		operation = "stash pop"
+	}
+	session, repo, stash, unlock := h.lockStash(c, req.Commit, req.Force, operation)
+	if unlock == nil {
+		return
+	}
+	defer unlock()
+
+	args := []string{"stash", "apply"}
+	if pop {
+		args[1] = "pop"
+	}
+	if req.Index {
+		args = append(args, "--index")
+	}
+	args = append(args, stash.Ref)
+	response := StashResponse{Stash: stash}
+	if stdout, stderr, err := repo.exec(c.Request.Context(), nil, nil, args...); err != nil {
+		response.Conflicts = conflictedFiles(repo)
+		response.Error = fmt.Sprintf("Failed to apply %s: %s", stash.Ref, strings.TrimSpace(stderr+"\n"+stdout))
+		slog.Warn("stash did not apply cleanly", "session_id", session.ID, "stash", stash.Commit, "conflicts", len(response.Conflicts), "error", err)
+		c.JSON(http.StatusConflict, response)
+		return
+	}
+	slog.Info("applied stash", "session_id", session.ID, "stash", stash.Commit, "dropped", pop)
+	response.Success = true
+	c.JSON(http.StatusOK, response)
+}
+
+// HandleDropStash deletes a stash. ?commit= guards against the index
+// shifting, as for apply.
+func (h *GitHandler) HandleDropStash(c *gin.Context) {
+	force, _ := strconv.ParseBool(c.Query("force"))
+	session, repo, stash, unlock := h.lockStash(c, c.Query("commit"), force, "stash drop")
+	if unlock == nil {
+		return
+	}
+	defer unlock()
+
+	if _, stderr, err := repo.exec(c.Request.Context(), nil, nil, "stash", "drop", stash.Ref); err != nil {
+		slog.Error("failed to drop stash", "session_id", session.ID, "stash", stash.Commit, "error", err)
+		c.JSON(http.StatusInternalServerError, StashResponse{Stash: stash, Error: fmt.Sprintf("Failed to drop %s: %s", stash.Ref, strings.TrimSpace(stderr))})
+		return
+	}
+	slog.Info("dropped stash", "session_id", session.ID, "stash", stash.Commit, "message", stash.Message)
+	c.JSON(http.StatusOK, StashResponse{Success: true, Stash: stash})
+}
+
+// lockStash takes the session's mutation lock and returns the stash the
+// request's :index names, or responds with why it can't and returns a nil
+// unlock
+func (h *GitHandler) lockStash(c *gin.Context, commit string, force bool, operation string) (*store.Session, gitRepo, *GitStash, func()) {
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil || index < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid stash index"})
+		return nil, gitRepo{}, nil, nil
+	}
+	session, repo, ok := h.stashRepo(c)
+	if !ok {
+		return nil, gitRepo{}, nil, nil
+	}
+	unlock := h.lockForMutation(c, session, force, operation)
+	if unlock == nil {
+		return nil, gitRepo{}, nil, nil
+	}
+
+	stash, err := stashAt(repo, index)
+	switch {
+	case err != nil:
+		slog.Error("failed to list stashes", "session_id", session.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list stashes"})
+	case stash == nil:
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("No stash at stash@{%d}", index)})
+	case commit != "" && commit != stash.Commit:
+		c.JSON(http.StatusConflict, StashResponse{Stash: stash, Error: fmt.Sprintf("stash@{%d} is no longer %s; list the stash again", index, commit)})
+	default:
+		return session, repo, stash, unlock
+	}
+	unlock()
+	return nil, gitRepo{}, nil, nil
+}
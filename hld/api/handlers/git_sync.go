@@ -0,0 +1,378 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/credentials"
+	"github.com/humanlayer/humanlayer/hld/workspace"
+)
+
+// Pull strategies
+const (
+	PullFastForward = "ff-only" // Only move the branch forward; the default
+	PullRebase      = "rebase"  // Replay local commits on the upstream
+	PullMerge       = "merge"   // Merge the upstream with a merge commit
+)
+
+// maxDivergenceCommits caps the incoming and outgoing commits listed
+const maxDivergenceCommits = 20
+
+// errNoUpstream is returned for a branch that doesn't track another
+var errNoUpstream = errors.New("branch has no upstream")
+
+// FetchRequest fetches a remote of a session's repository
+type FetchRequest struct {
+	// Remote defaults to the current branch's, else origin
+	Remote string `json:"remote,omitempty"`
+	// Prune removes remote-tracking branches deleted on the remote
+	Prune bool `json:"prune,omitempty"`
+}
+
+// FetchResponse reports where the current branch stands after a fetch
+type FetchResponse struct {
+	Remote string `json:"remote"`
+	// Divergence is unset when the branch has no upstream
+	Divergence *GitDivergence `json:"divergence,omitempty"`
+}
+
+// PullRequest brings the upstream's commits into the current branch
+type PullRequest struct {
+	// Strategy is ff-only, rebase or merge. A branch that has diverged from
+	// its upstream is only pulled with rebase or merge.
+	Strategy string `json:"strategy,omitempty"`
+	// Force pulls even while the session is running
+	Force bool `json:"force,omitempty"`
+}
+
+// PullResponse reports a pull
+type PullResponse struct {
+	Success  bool   `json:"success"`
+	Strategy string `json:"strategy"`
+	// Before and After are HEAD before and after the pull
+	Before string `json:"before"`
+	After  string `json:"after,omitempty"`
+	// Divergence is the branch against its upstream before the pull
+	Divergence *GitDivergence `json:"divergence,omitempty"`
+	// Conflicts are the files a rebase or merge stopped on; it's aborted,
+	// leaving the branch as it was
+	Conflicts []string `json:"conflicts,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// GitDivergence is how a branch and its upstream differ
+type GitDivergence struct {
+	Branch   string `json:"branch"`
+	Upstream string `json:"upstream"`
+	Ahead    int    `json:"ahead"`
+	Behind   int    `json:"behind"`
+	// MergeBase is the newest commit both have
+	MergeBase string `json:"mergeBase,omitempty"`
+	// Incoming are upstream commits the branch lacks, and Outgoing local
+	// commits the upstream lacks, newest first and at most 20 of each
+	Incoming []GitCommitSummary `json:"incoming"`
+	Outgoing []GitCommitSummary `json:"outgoing"`
+	// FastForward is set when pulling only moves the branch forward
+	FastForward bool `json:"fastForward"`
+}
+
+// GitCommitSummary identifies a commit
+type GitCommitSummary struct {
+	Hash    string    `json:"hash"`
+	Subject string    `json:"subject"`
+	Author  string    `json:"author"`
+	Date    time.Time `json:"date"`
+}
+
+// SetCredentials sets how fetches and pushes authenticate to remotes
+func (h *GitHandler) SetCredentials(m *credentials.Manager) {
+	h.credentials = m
+}
+
+// HandleFetch fetches a remote of the session's repository and reports how
+// the current branch has diverged from its upstream
+func (h *GitHandler) HandleFetch(c *gin.Context) {
+	var req FetchRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+			return
+		}
+	}
+	session, err := h.store.GetSession(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+	repo, ok := h.selectRepo(c, session)
+	if !ok {
+		return
+	}
+	if !isGitRepo(repo) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Not a git repository"})
+		return
+	}
+
+	remote := req.Remote
+	if remote == "" {
+		remote = defaultRemote(repo)
+	}
+	if !h.fetch(c, repo, remote, req.Prune) {
+		return
+	}
+	response := FetchResponse{Remote: remote}
+	divergence, err := branchDivergence(repo)
+	switch {
+	case err == nil:
+		response.Divergence = divergence
+	case !errors.Is(err, errNoUpstream):
+		slog.Error("failed to compare branch with upstream", "session_id", session.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compare branch with upstream"})
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// HandlePull fetches the current branch's upstream and brings its commits
+// in, fast-forward only unless the request picks rebase or merge
+func (h *GitHandler) HandlePull(c *gin.Context) {
+	var req PullRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+			return
+		}
+	}
+	if req.Strategy == "" {
+		req.Strategy = PullFastForward
+	}
+	if req.Strategy != PullFastForward && req.Strategy != PullRebase && req.Strategy != PullMerge {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unknown strategy %q; use ff-only, rebase or merge", req.Strategy)})
+		return
+	}
+	session, err := h.store.GetSession(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+	repo, ok := h.selectRepo(c, session)
+	if !ok {
+		return
+	}
+	if !isGitRepo(repo) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Not a git repository"})
+		return
+	}
+	if _, err := repo.run("rev-parse", "--abbrev-ref", "@{upstream}"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The current branch has no upstream to pull from; set one with git branch --set-upstream-to"})
+		return
+	}
+
+	unlock := h.lockForMutation(c, session, req.Force, "pull")
+	if unlock == nil {
+		return
+	}
+	defer unlock()
+
+	if !h.fetch(c, repo, defaultRemote(repo), false) {
+		return
+	}
+	response := PullResponse{Strategy: req.Strategy}
+	response.Before, _ = repo.run("rev-parse", "HEAD")
+	response.Divergence, err = branchDivergence(repo)
+	if err != nil {
+		slog.Error("failed to compare branch with upstream", "session_id", session.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compare branch with upstream"})
+		return
+	}
+	if response.Divergence.Behind == 0 {
+		response.Success = true
+		response.After = response.Before
+		c.JSON(http.StatusOK, response)
+		return
+	}
+	if !response.Divergence.FastForward && req.Strategy == PullFastForward {
+		response.Error = fmt.Sprintf("The branch has diverged from %s, with %d local and %d upstream commits; pull with strategy rebase or merge",
+			response.Divergence.Upstream, response.Divergence.Ahead, response.Divergence.Behind)
+		c.JSON(http.StatusConflict, response)
+		return
+	}
+
+	if err := integrateUpstream(c.Request.Context(), repo, req.Strategy); err != nil {
+		var conflict *pullConflictError
+		if errors.As(err, &conflict) {
+			response.Conflicts = conflict.files
+			response.Error = fmt.Sprintf("The %s stopped on conflicts in %d files and was aborted", req.Strategy, len(conflict.files))
+		} else {
+			response.Error = fmt.Sprintf("Pull failed: %v", err)
+		}
+		c.JSON(http.StatusConflict, response)
+		return
+	}
+	response.Success = true
+	response.After, _ = repo.run("rev-parse", "HEAD")
+	c.JSON(http.StatusOK, response)
+}
+
+// fetch fetches remote with the credentials for its URL. It responds with
+// an error and reports false if that fails.
+func (h *GitHandler) fetch(c *gin.Context, repo gitRepo, remote string, prune bool) bool {
+	if strings.HasPrefix(remote, "-") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid remote"})
+		return false
+	}
+	ctx := c.Request.Context()
+	remoteURL, auth, err := h.remoteAuth(ctx, repo, remote)
+	if errors.Is(err, errUnknownRemote) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unknown remote %q", remote)})
+		return false
+	}
+	if err != nil {
+		slog.Error("failed to look up git credentials", "remote", remoteURL, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up credentials for the remote"})
+		return false
+	}
+	args := append(auth.Args, "fetch", "--quiet")
+	if prune {
+		args = append(args, "--prune")
+	}
+	if _, _, err := repo.exec(ctx, nil, auth.Env, append(args, "--", remote)...); err != nil {
+		var authErr *credentials.AuthError
+		if errors.As(credentials.CheckAuth(err, remoteURL), &authErr) {
+			c.JSON(http.StatusUnauthorized, authErrorBody(authErr))
+			return false
+		}
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Failed to fetch %s: %v", remote, err)})
+		return false
+	}
+	return true
+}
+
+// errUnknownRemote is returned for a remote the repository doesn't have
+var errUnknownRemote = errors.New("unknown remote")
+
+// remoteAuth returns remote's URL and what git needs to authenticate to it
+func (h *GitHandler) remoteAuth(ctx context.Context, repo gitRepo, remote string) (string, credentials.Git, error) {
+	remoteURL, err := repo.run("remote", "get-url", "--", remote)
+	if err != nil {
+		return "", credentials.Git{}, errUnknownRemote
+	}
+	if h.credentials == nil {
+		return remoteURL, credentials.Git{}, nil
+	}
+	_, local := repo.host.(workspace.Local)
+	auth, err := h.credentials.ForRemote(ctx, remoteURL, local)
+	return remoteURL, auth, err
+}
+
+// defaultRemote is the current branch's remote, else origin
+func defaultRemote(repo gitRepo) string {
+	if branch, err := repo.run("symbolic-ref", "--short", "-q", "HEAD"); err == nil && branch != "" {
+		if remote, _ := repo.run("config", "branch."+branch+".remote"); remote != "" && remote != "." {
+			return remote
+		}
+	}
+	return "origin"
+}
+
+// branchDivergence compares the current branch with its upstream, as of
+// the last fetch
+func branchDivergence(repo gitRepo) (*GitDivergence, error) {
+	upstream, err := repo.run("rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}")
+	if err != nil || upstream == "" {
+		return nil, errNoUpstream
+	}
+	branch, err := repo.run("rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	counts, err := repo.run("rev-list", "--left-right", "--count", "HEAD...@{upstream}")
+	if err != nil {
+		return nil, err
+	}
+	ahead, behind, ok := strings.Cut(counts, "\t")
+	if !ok {
+		return nil, fmt.Errorf("unexpected rev-list output %q", counts)
+	}
+	divergence := &GitDivergence{Branch: branch, Upstream: upstream}
+	if divergence.Ahead, err = strconv.Atoi(ahead); err != nil {
+		return nil, err
+	}
+	if divergence.Behind, err = strconv.Atoi(behind); err != nil {
+		return nil, err
+	}
+	divergence.FastForward = divergence.Ahead == 0
+	// Unrelated histories have no merge base
+	divergence.MergeBase, _ = repo.run("merge-base", "HEAD", "@{upstream}")
+	if divergence.Incoming, err = commitSummaries(repo, "HEAD..@{upstream}"); err != nil {
+		return nil, err
+	}
+	if divergence.Outgoing, err = commitSummaries(repo, "@{upstream}..HEAD"); err != nil {
+		return nil, err
+	}
+	return divergence, nil
+}
+
+// commitSummaries lists the newest commits in revisions
+func commitSummaries(repo gitRepo, revisions string) ([]GitCommitSummary, error) {
+	out, err := repo.run("log", "-z", "--format=%H%x1f%s%x1f%an%x1f%aI", "-n", strconv.Itoa(maxDivergenceCommits), revisions, "--")
+	if err != nil {
+		return nil, err
+	}
+	commits := []GitCommitSummary{}
+	for _, record := range strings.Split(out, "\x00") {
+		fields := strings.Split(record, "\x1f")
+		if len(fields) != 4 {
+			continue
+		}
+		date, _ := time.Parse(time.RFC3339, fields[3])
+		commits = append(commits, GitCommitSummary{Hash: fields[0], Subject: fields[1], Author: fields[2], Date: date})
+	}
+	return commits, nil
+}
+
+// pullConflictError is a rebase or merge that stopped on conflicts
+type pullConflictError struct {
+	files []string
+}
+
+func (e *pullConflictError) Error() string {
+	return fmt.Sprintf("conflicts in %s", strings.Join(e.files, ", "))
+}
+
+// integrateUpstream brings the fetched upstream into the current branch.
+// A rebase or merge that stops on conflicts is aborted, so the branch is
+// never left half-integrated for the agent to stumble on.
+func integrateUpstream(ctx context.Context, repo gitRepo, strategy string) error {
+	var args, abort []string
+	switch strategy {
+	case PullFastForward:
+		args = []string{"merge", "--ff-only", "@{upstream}"}
+	case PullMerge:
+		args, abort = []string{"merge", "--no-edit", "@{upstream}"}, []string{"merge", "--abort"}
+	case PullRebase:
+		args, abort = []string{"rebase", "@{upstream}"}, []string{"rebase", "--abort"}
+	}
+	// Merge commits and rebased commits need an identity
+	if email, _ := repo.run("config", "user.email"); email == "" {
+		args = append([]string{"-c", "user.name=HumanLayer", "-c", "user.email=humanlayer@localhost"}, args...)
+	}
+	_, stderr, err := repo.exec(ctx, nil, nil, args...)
+	if err == nil {
+		return nil
+	}
+	if conflicts := conflictedFiles(repo); len(conflicts) > 0 && abort != nil {
+		if _, _, abortErr := repo.exec(ctx, nil, nil, abort...); abortErr != nil {
+			slog.Error("failed to abort conflicted pull", "dir", repo.dir, "strategy", strategy, "error", abortErr)
+		}
+		return &pullConflictError{files: conflicts}
+	}
+	return errors.New(strings.TrimSpace(stderr))
+}
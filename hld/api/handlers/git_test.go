@@ -0,0 +1,1004 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/api/handlers"
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/depcheck"
+	"github.com/humanlayer/humanlayer/hld/diskguard"
+	"github.com/humanlayer/humanlayer/hld/jobs"
+	"github.com/humanlayer/humanlayer/hld/lfs"
+	"github.com/humanlayer/humanlayer/hld/llm"
+	"github.com/humanlayer/humanlayer/hld/migrationcheck"
+	"github.com/humanlayer/humanlayer/hld/prompts"
+	"github.com/humanlayer/humanlayer/hld/provenance"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type suggestionProvider struct{}
+
+func (suggestionProvider) Complete(ctx context.Context, model string, req llm.Request) (string, error) {
+	return `{"type":"single","reasoning":"small change","commits":[{"subject":"feat: add files","files":[]}]}`, nil
+}
+
+func initGitRepo(t *testing.T, files int) string {
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	git("init", "-q")
+	git("config", "user.name", "test")
+	git("config", "user.email", "test@example.com")
+	git("commit", "-q", "--allow-empty", "-m", "initial")
+	for i := 0; i < files; i++ {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("file%d.txt", i)), []byte("hello\n"), 0644))
+	}
+	git("add", "-A")
+	return dir
+}
+
+func setupGitHandler(t *testing.T) (*store.MemoryStore, *bus.InMemoryBus, *handlers.GitHandler) {
+	gin.SetMode(gin.TestMode)
+
+	dir := initGitRepo(t, 3)
+	s := store.NewInMemoryStore()
+	require.NoError(t, s.CreateSession(context.Background(), &store.Session{
+		ID: "sess-1", RunID: "run-1", WorkingDir: dir, Status: store.SessionStatusCompleted, CreatedAt: time.Now(),
+	}))
+
+	eventBus := bus.NewInMemoryBus()
+	llmRouter := llm.NewRouterWithProviders(
+		map[string]llm.Provider{"fake": suggestionProvider{}},
+		map[llm.Task][]config.LLMRoute{llm.TaskCommitMessage: {{Provider: "fake", Model: "m"}}},
+	)
+	h := handlers.NewGitHandlerWithRouter(s, llmRouter, prompts.Default(), "", eventBus)
+	h.SetRecords(s, s)
+	return s, eventBus, h
+}
+
+func gitRouter(h *handlers.GitHandler) *gin.Engine {
+	router := gin.New()
+	router.Use(handlers.RequestIDMiddleware())
+	router.POST("/api/v1/sessions/:id/git/generate-commit-message", h.HandleGenerateCommitMessage)
+	return router
+}
+
+func TestGenerateCommitMessage_Stream(t *testing.T) {
+	_, eventBus, h := setupGitHandler(t)
+	router := gitRouter(h)
+
+	w := makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/git/generate-commit-message",
+		handlers.GenerateCommitMessageRequest{Stream: true})
+	require.Equal(t, http.StatusAccepted, w.Code)
+
+	var accepted handlers.GenerateCommitMessageAccepted
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&accepted))
+	assert.Equal(t, w.Header().Get("X-Request-ID"), accepted.RequestID)
+
+	var stages []string
+	require.Eventually(t, func() bool {
+		stages = nil
+		for _, event := range eventBus.EventsOfType(bus.EventCommitMessageProgress) {
+			stages = append(stages, event.Data["stage"].(string))
+		}
+		return len(stages) > 0 && stages[len(stages)-1] == handlers.CommitProgressCompleted
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, []string{
+		handlers.CommitProgressContextGathered,
+		handlers.CommitProgressDiffSummarized,
+		handlers.CommitProgressFilesProcessed,
+		handlers.CommitProgressFilesProcessed,
+		handlers.CommitProgressFilesProcessed,
+		handlers.CommitProgressDrafting,
+		handlers.CommitProgressCompleted,
+	}, stages)
+
+	events := eventBus.EventsOfType(bus.EventCommitMessageProgress)
+	last := events[len(events)-1]
+	assert.Equal(t, accepted.RequestID, last.Data["request_id"])
+	assert.Equal(t, "sess-1", last.Data["session_id"])
+	result := last.Data["result"].(*handlers.GenerateCommitMessageResponse)
+	assert.Equal(t, "feat: add files", result.Suggestion.Commits[0].Subject)
+	assert.Equal(t, 3, result.GitContext.AdditionsCount)
+}
+
+func TestGenerateCommitMessage_Async(t *testing.T) {
+	s, eventBus, h := setupGitHandler(t)
+	jobManager := jobs.NewManager(s, eventBus)
+	h.SetJobManager(jobManager)
+	router := gitRouter(h)
+
+	w := makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/git/generate-commit-message?async=true",
+		handlers.GenerateCommitMessageRequest{})
+	require.Equal(t, http.StatusAccepted, w.Code)
+
+	var accepted handlers.JobAccepted
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&accepted))
+	assert.Equal(t, "/api/v1/jobs/"+accepted.JobID, w.Header().Get("Location"))
+	jobManager.Wait()
+
+	jobRouter := gin.New()
+	jobRouter.GET("/api/v1/jobs/:id", handlers.NewJobHandler(s).HandleGetJob)
+	w = makeRequest(t, jobRouter, "GET", accepted.StatusURL, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var job store.Job
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&job))
+	assert.Equal(t, store.JobStatusCompleted, job.Status)
+	assert.Equal(t, "sess-1", job.SessionID)
+	var result handlers.GenerateCommitMessageResponse
+	require.NoError(t, json.Unmarshal(job.Result, &result))
+	assert.Equal(t, "feat: add files", result.Suggestion.Commits[0].Subject)
+
+	w = makeRequest(t, jobRouter, "GET", "/api/v1/jobs/missing", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestCommitChanges_SessionLock(t *testing.T) {
+	s, _, h := setupGitHandler(t)
+	ctx := context.Background()
+	router := gin.New()
+	router.POST("/api/v1/sessions/:id/git/commit", h.HandleCommitChanges)
+
+	running, claudeSessionID := store.SessionStatusRunning, "claude-1"
+	require.NoError(t, s.UpdateSession(ctx, "sess-1", store.SessionUpdate{Status: &running, ClaudeSessionID: &claudeSessionID}))
+
+	commit := handlers.CommitRequest{Commits: []handlers.CommitMessage{{Subject: "feat: add files"}}}
+	w := makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/git/commit", commit)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	commit.Force = true
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/git/commit", commit)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	events, err := s.GetSessionConversation(ctx, "sess-1")
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, store.EventTypeSystem, events[0].EventType)
+	assert.Contains(t, events[0].Content, "forced")
+}
+
+func TestGenerateCommitMessage_MigrationWarnings(t *testing.T) {
+	s, _, h := setupGitHandler(t)
+	session, err := s.GetSession(context.Background(), "sess-1")
+	require.NoError(t, err)
+	dir := filepath.Join(session.WorkingDir, "db", "migrations")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "002_drop_users.up.sql"), []byte("DROP TABLE users;\n"), 0644))
+	router := gitRouter(h)
+
+	w := makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/git/generate-commit-message",
+		handlers.GenerateCommitMessageRequest{})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var result handlers.GenerateCommitMessageResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&result))
+	require.Len(t, result.MigrationWarnings, 2)
+	assert.Equal(t, "db/migrations/002_drop_users.up.sql", result.MigrationWarnings[0].File)
+	assert.Equal(t, migrationcheck.RuleDestructive, result.MigrationWarnings[0].Rule)
+	assert.Equal(t, 1, result.MigrationWarnings[0].Line)
+	assert.Equal(t, migrationcheck.RuleMissingDown, result.MigrationWarnings[1].Rule)
+}
+
+func TestCommitChanges_DependencyGate(t *testing.T) {
+	s, _, h := setupGitHandler(t)
+	session, err := s.GetSession(context.Background(), "sess-1")
+	require.NoError(t, err)
+	manifest := `{"dependencies": {"lodash": "4.17.15", "left-pad": "1.3.0"}}`
+	require.NoError(t, os.WriteFile(filepath.Join(session.WorkingDir, "package.json"), []byte(manifest), 0644))
+
+	lookups := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/query" {
+			body, _ := io.ReadAll(r.Body)
+			if strings.Contains(string(body), "lodash") {
+				_, _ = w.Write([]byte(`{"vulns":[{"id":"GHSA-p6mc-m468-83gw"}]}`))
+				return
+			}
+		}
+		_, _ = w.Write([]byte(`{"licenses":["MIT"]}`))
+	}))
+	defer lookups.Close()
+	h.SetDependencyChecker(depcheck.New(lookups.URL, lookups.URL, nil), false)
+
+	router := gin.New()
+	router.POST("/api/v1/sessions/:id/git/commit", h.HandleCommitChanges)
+	commit := handlers.CommitRequest{
+		Commits:           []handlers.CommitMessage{{Subject: "feat: add lodash", Files: []string{"package.json"}}},
+		CheckDependencies: true,
+	}
+	w := makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/git/commit", commit)
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+
+	var response handlers.CommitResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.False(t, response.Success)
+	require.Len(t, response.DependencyFindings, 1)
+	assert.Equal(t, "lodash", response.DependencyFindings[0].Name)
+	assert.Empty(t, response.CommitHashes)
+
+	// Without the gate the commit goes ahead
+	commit.CheckDependencies = false
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/git/commit", commit)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func TestCommitChanges_Analysis(t *testing.T) {
+	s, _, h := setupGitHandler(t)
+	session, err := s.GetSession(context.Background(), "sess-1")
+	require.NoError(t, err)
+	h.SetAnalysis(config.AnalysisConfig{
+		Repositories: map[string][]config.AnalyzerConfig{
+			session.WorkingDir: {{
+				Name:      "lint",
+				Command:   []string{"sh", "-c", `for f in "$@"; do echo "$f:1:1: trailing newline"; done; exit 1`, "lint"},
+				Patterns:  []string{"file0.txt"},
+				PassFiles: true,
+			}},
+		},
+		BlockOnError: true,
+	})
+
+	router := gin.New()
+	router.POST("/api/v1/sessions/:id/git/commit", h.HandleCommitChanges)
+	commit := handlers.CommitRequest{Commits: []handlers.CommitMessage{{Subject: "feat: add files"}}}
+
+	// Analyzers only run when asked, or with the gate on
+	w := makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/git/commit",
+		handlers.CommitRequest{Commits: commit.Commits, Analyze: true})
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+	var response handlers.CommitResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	require.Len(t, response.Analysis, 1)
+	require.Len(t, response.Analysis[0].Findings, 1)
+	assert.Equal(t, "file0.txt", response.Analysis[0].Findings[0].File)
+	assert.Empty(t, response.CommitHashes)
+
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/git/commit", commit)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func TestCommitChanges_Format(t *testing.T) {
+	s, _, h := setupGitHandler(t)
+	session, err := s.GetSession(context.Background(), "sess-1")
+	require.NoError(t, err)
+	// file1.txt is partially staged, so formatting it would stage more
+	require.NoError(t, os.WriteFile(filepath.Join(session.WorkingDir, "file1.txt"), []byte("hello\nunstaged\n"), 0644))
+	h.SetAnalysis(config.AnalysisConfig{
+		Formatters: map[string][]config.FormatterConfig{
+			"*": {{Name: "upper", Command: []string{"sh", "-c", `for f in "$@"; do tr a-z A-Z < "$f" > "$f.tmp" && mv "$f.tmp" "$f"; done`, "upper"}}},
+		},
+	})
+
+	router := gin.New()
+	router.POST("/api/v1/sessions/:id/git/commit", h.HandleCommitChanges)
+	w := makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/git/commit", handlers.CommitRequest{
+		Commits: []handlers.CommitMessage{{Subject: "feat: add files"}},
+		Format:  true,
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response handlers.CommitResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	require.Len(t, response.Formatting, 1)
+	assert.Equal(t, []string{"file0.txt", "file2.txt"}, response.Formatting[0].Changed)
+
+	show := func(path string) string {
+		out, err := exec.Command("git", "-C", session.WorkingDir, "show", "HEAD:"+path).Output()
+		require.NoError(t, err)
+		return string(out)
+	}
+	assert.Equal(t, "HELLO\n", show("file0.txt"), "formatting is committed")
+	assert.Equal(t, "hello\n", show("file1.txt"), "partially staged files aren't formatted")
+}
+
+func TestCommitChanges_StreamsHookOutput(t *testing.T) {
+	s, eventBus, h := setupGitHandler(t)
+	session, err := s.GetSession(context.Background(), "sess-1")
+	require.NoError(t, err)
+	hook := filepath.Join(session.WorkingDir, ".git", "hooks", "pre-commit")
+	require.NoError(t, os.WriteFile(hook, []byte("#!/bin/sh\necho checking staged files >&2\n"), 0755))
+
+	router := gin.New()
+	router.POST("/api/v1/sessions/:id/git/commit", h.HandleCommitChanges)
+	w := makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/git/commit", handlers.CommitRequest{
+		Commits:     []handlers.CommitMessage{{Subject: "feat: add files"}},
+		OperationID: "op-42",
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response handlers.CommitResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, "op-42", response.OperationID)
+
+	var lines []string
+	var done map[string]interface{}
+	for _, event := range eventBus.EventsOfType(bus.EventCommandOutput) {
+		assert.Equal(t, "op-42", event.Data["operation_id"])
+		assert.Equal(t, "sess-1", event.Data["session_id"])
+		assert.Equal(t, "git commit", event.Data["command"])
+		if line, ok := event.Data["line"].(string); ok {
+			lines = append(lines, event.Data["stream"].(string)+": "+line)
+		} else {
+			done = event.Data
+		}
+	}
+	assert.Contains(t, lines, "stderr: checking staged files")
+	require.NotNil(t, done)
+	assert.Equal(t, 0, done["exit_code"])
+}
+
+func TestCommitChanges_DiskGuard(t *testing.T) {
+	s, eventBus, h := setupGitHandler(t)
+	session, err := s.GetSession(context.Background(), "sess-1")
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(session.WorkingDir, "build"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(session.WorkingDir, "build", "out.bin"), make([]byte, 100), 0644))
+	h.SetDiskGuard(diskguard.New(config.DiskGuardConfig{MinFree: -1, MaxRepoGrowth: 50}, eventBus))
+	events := eventBus.Subscribe(context.Background(), bus.EventFilter{Types: []bus.EventType{bus.EventDiskGuardRefused}})
+
+	router := gin.New()
+	router.POST("/api/v1/sessions/:id/git/commit", h.HandleCommitChanges)
+	commits := []handlers.CommitMessage{{Subject: "feat: add files"}}
+
+	// Staging the untracked build output would add 118 bytes
+	w := makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/git/commit",
+		handlers.CommitRequest{Commits: commits, StageUntracked: true})
+	require.Equal(t, http.StatusInsufficientStorage, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "would add 118 bytes")
+	select {
+	case event := <-events.Channel:
+		assert.Equal(t, "sess-1", event.Data["session_id"])
+		assert.Equal(t, diskguard.ReasonRepoGrowth, event.Data["reason"])
+	case <-time.After(time.Second):
+		t.Fatal("no disk_guard_refused event")
+	}
+	status, err := exec.Command("git", "-C", session.WorkingDir, "status", "--porcelain").Output()
+	require.NoError(t, err)
+	assert.Contains(t, string(status), "?? build/", "nothing was staged")
+
+	// The staged files alone are within the limit
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/git/commit", handlers.CommitRequest{Commits: commits})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func TestGetReviewers(t *testing.T) {
+	s, _, h := setupGitHandler(t)
+	session, err := s.GetSession(context.Background(), "sess-1")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(session.WorkingDir, "CODEOWNERS"), []byte("*.txt @acme/docs\nfile2.txt\n"), 0644))
+
+	router := gin.New()
+	router.GET("/api/v1/sessions/:id/git/reviewers", h.HandleGetReviewers)
+	w := makeRequest(t, router, "GET", "/api/v1/sessions/sess-1/git/reviewers", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response handlers.ReviewersResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, "HEAD", response.Base)
+	require.Len(t, response.Reviewers, 1, "new files have no blame authors")
+	assert.Equal(t, "@acme/docs", response.Reviewers[0].Owner)
+	assert.Equal(t, []string{"file0.txt", "file1.txt"}, response.Reviewers[0].Files)
+
+	w = makeRequest(t, router, "GET", "/api/v1/sessions/sess-1/git/reviewers?base=--output=x", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGitStatusAndCommit_LFS(t *testing.T) {
+	s, _, h := setupGitHandler(t)
+	session, err := s.GetSession(context.Background(), "sess-1")
+	require.NoError(t, err)
+	dir := session.WorkingDir
+	git := func(args ...string) {
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	// Pass content through unchanged whether or not git-lfs is installed,
+	// so the test stages pointers by hand
+	git("config", "filter.lfs.clean", "cat")
+	git("config", "filter.lfs.smudge", "cat")
+	git("config", "filter.lfs.process", "")
+	oid := strings.Repeat("ab", 32)
+	pointer := func(size int) string {
+		return fmt.Sprintf("version https://git-lfs.github.com/spec/v1\noid sha256:%s\nsize %d\n", oid, size)
+	}
+	write := func(path, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, path), []byte(content), 0644))
+	}
+	write(".gitattributes", "*.bin filter=lfs diff=lfs merge=lfs -text\n")
+	write("model.bin", pointer(4096))
+	git("add", ".gitattributes", "model.bin")
+	git("commit", "-q", "-m", "track models")
+
+	write("model.bin", pointer(8192))
+	write("raw.bin", "not a pointer")
+	write("data.csv", strings.Repeat("x", 200))
+	git("add", "model.bin", "raw.bin")
+	h.SetLFS(config.LFSConfig{LargeFileSize: 100, Block: true})
+
+	router := gin.New()
+	router.GET("/api/v1/sessions/:id/git/status", h.HandleGetGitStatus)
+	router.POST("/api/v1/sessions/:id/git/commit", h.HandleCommitChanges)
+	w := makeRequest(t, router, "GET", "/api/v1/sessions/sess-1/git/status", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var status handlers.GitStatusResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&status))
+	assert.True(t, status.LFS)
+
+	staged := make(map[string]handlers.GitFile)
+	for _, file := range status.Staged {
+		staged[file.Path] = file
+	}
+	model := staged["model.bin"].LFS
+	require.NotNil(t, model, "pointer changes are shown in place of content")
+	assert.Equal(t, &lfs.Pointer{OID: oid, Size: 4096}, model.Old)
+	assert.Equal(t, &lfs.Pointer{OID: oid, Size: 8192}, model.New)
+	assert.False(t, model.NotPointer)
+	require.NotNil(t, staged["raw.bin"].LFS)
+	assert.True(t, staged["raw.bin"].LFS.NotPointer)
+	assert.Nil(t, staged["file0.txt"].LFS)
+
+	require.Len(t, status.LFSWarnings, 2)
+	assert.Equal(t, "raw.bin", status.LFSWarnings[0].Path)
+	assert.Equal(t, lfs.ReasonNotPointer, status.LFSWarnings[0].Reason)
+	assert.Equal(t, "data.csv", status.LFSWarnings[1].Path)
+	assert.Equal(t, lfs.ReasonLarge, status.LFSWarnings[1].Reason)
+	assert.Equal(t, int64(200), status.LFSWarnings[1].Size)
+
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/git/commit", handlers.CommitRequest{
+		Commits: []handlers.CommitMessage{{Subject: "add data", Files: []string{"data.csv"}}},
+	})
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+	var response handlers.CommitResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.False(t, response.Success)
+	assert.Len(t, response.LFSWarnings, 2)
+
+	// Without blocking, the commit goes through with the warnings
+	h.SetLFS(config.LFSConfig{LargeFileSize: 100})
+	git("rm", "-q", "--cached", "raw.bin")
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/git/commit", handlers.CommitRequest{
+		Commits: []handlers.CommitMessage{{Subject: "add data", Files: []string{"data.csv"}}},
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	response = handlers.CommitResponse{}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	require.Len(t, response.LFSWarnings, 1)
+	assert.Equal(t, "data.csv", response.LFSWarnings[0].Path)
+}
+
+func TestExportAndApplyPatch(t *testing.T) {
+	s, _, h := setupGitHandler(t)
+	ctx := context.Background()
+	source, err := s.GetSession(ctx, "sess-1")
+	require.NoError(t, err)
+	gitIn := func(dir string, args ...string) string {
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+		return strings.TrimSpace(string(out))
+	}
+	target := t.TempDir()
+	gitIn(target, "clone", "-q", source.WorkingDir, ".")
+	gitIn(target, "config", "user.name", "test")
+	gitIn(target, "config", "user.email", "test@example.com")
+	require.NoError(t, s.CreateSession(ctx, &store.Session{
+		ID: "sess-2", RunID: "run-2", WorkingDir: target, Status: store.SessionStatusCompleted, CreatedAt: time.Now(),
+	}))
+
+	// The session commits the staged files, then leaves an edit and a new file
+	gitIn(source.WorkingDir, "commit", "-q", "-m", "add files")
+	require.NoError(t, os.WriteFile(filepath.Join(source.WorkingDir, "file0.txt"), []byte("hello\nworld\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(source.WorkingDir, "notes.md"), []byte("notes\n"), 0644))
+
+	router := gin.New()
+	router.GET("/api/v1/sessions/:id/git/patch", h.HandleExportPatch)
+	router.POST("/api/v1/sessions/:id/git/apply", h.HandleApplyPatch)
+
+	w := makeRequest(t, router, "GET", "/api/v1/sessions/sess-1/git/patch?base=HEAD~1", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "application/mbox", w.Header().Get("Content-Type"))
+	mbox := w.Body.String()
+	assert.Contains(t, mbox, "Subject: [PATCH 1/2] add files")
+	assert.Contains(t, mbox, "Subject: [PATCH 2/2] Uncommitted changes from HumanLayer session sess-1")
+	assert.Contains(t, mbox, "notes.md")
+	assert.Contains(t, gitIn(source.WorkingDir, "status", "--porcelain"), "?? notes.md", "exporting leaves the index alone")
+
+	w = makeRequest(t, router, "GET", "/api/v1/sessions/sess-1/git/patch?format=diff", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "file1.txt", "only uncommitted changes are exported by default")
+
+	// Commits are recreated from the series
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/sess-2/git/apply", handlers.ApplyPatchRequest{Patch: mbox, Commit: true})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response handlers.ApplyPatchResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.True(t, response.Success)
+	assert.Len(t, response.CommitHashes, 2)
+	assert.ElementsMatch(t, []string{"file0.txt", "file1.txt", "file2.txt", "notes.md"}, response.Files)
+	assert.Equal(t, "hello\nworld\n", gitIn(target, "show", "HEAD:file0.txt")+"\n")
+
+	// A conflicting edit is merged 3-way, leaving markers
+	gitIn(target, "reset", "-q", "--hard", "HEAD~1")
+	require.NoError(t, os.WriteFile(filepath.Join(target, "file0.txt"), []byte("hello\nthere\n"), 0644))
+	gitIn(target, "commit", "-q", "-am", "diverge")
+	w = makeRequest(t, router, "GET", "/api/v1/sessions/sess-1/git/patch?base=HEAD~1&format=diff", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	req := httptest.NewRequest("POST", "/api/v1/sessions/sess-2/git/apply", w.Body)
+	req.Header.Set("Content-Type", "text/x-diff")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	response = handlers.ApplyPatchResponse{}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, []string{"file0.txt"}, response.Conflicts)
+	content, err := os.ReadFile(filepath.Join(target, "file0.txt"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "<<<<<<<")
+
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/sess-2/git/apply", handlers.ApplyPatchRequest{Patch: "not a patch"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCommitProvenance(t *testing.T) {
+	t.Setenv("TEST_PROVENANCE_KEY", "s3cret")
+	for _, mode := range []string{config.ProvenanceTrailer, config.ProvenanceNote} {
+		t.Run(mode, func(t *testing.T) {
+			s, _, h := setupGitHandler(t)
+			session, err := s.GetSession(context.Background(), "sess-1")
+			require.NoError(t, err)
+			h.SetProvenance(config.ProvenanceConfig{Mode: mode, KeyEnv: "TEST_PROVENANCE_KEY"})
+
+			router := gin.New()
+			router.POST("/api/v1/sessions/:id/git/commit", h.HandleCommitChanges)
+			router.GET("/api/v1/sessions/:id/git/provenance", h.HandleGetProvenance)
+			w := makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/git/commit", handlers.CommitRequest{
+				Commits: []handlers.CommitMessage{{Subject: "feat: add files"}},
+			})
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			var commit handlers.CommitResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&commit))
+			require.Len(t, commit.Provenance, 1)
+			assert.Equal(t, "sess-1", commit.Provenance[0].SessionID)
+
+			message, err := exec.Command("git", "-C", session.WorkingDir, "log", "-1", "--format=%B").Output()
+			require.NoError(t, err)
+			assert.Equal(t, mode == config.ProvenanceTrailer, strings.Contains(string(message), "HumanLayer-Signature: hmac-sha256:"))
+
+			w = makeRequest(t, router, "GET", "/api/v1/sessions/sess-1/git/provenance", nil)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			var report handlers.ProvenanceResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+			require.Len(t, report.Commits, 2)
+			assert.Equal(t, provenance.StatusVerified, report.Commits[0].Status)
+			assert.Equal(t, provenance.StatusMissing, report.Commits[1].Status, "the initial commit wasn't made by a session")
+			assert.Equal(t, map[string]int{provenance.StatusVerified: 1, provenance.StatusMissing: 1}, report.Summary)
+
+			// Changing the code after signing invalidates the record
+			require.NoError(t, os.WriteFile(filepath.Join(session.WorkingDir, "file0.txt"), []byte("edited\n"), 0644))
+			amend := exec.Command("git", "-C", session.WorkingDir, "commit", "-q", "--amend", "-a", "--no-edit")
+			out, err := amend.CombinedOutput()
+			require.NoError(t, err, string(out))
+			if mode == config.ProvenanceNote {
+				out, err := exec.Command("git", "-C", session.WorkingDir, "notes", "--ref", config.DefaultProvenanceNotesRef,
+					"copy", "HEAD@{1}", "HEAD").CombinedOutput()
+				require.NoError(t, err, string(out))
+			}
+			w = makeRequest(t, router, "GET", "/api/v1/sessions/sess-1/git/provenance?base=HEAD~1", nil)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			report = handlers.ProvenanceResponse{}
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+			require.Len(t, report.Commits, 1)
+			assert.Equal(t, provenance.StatusInvalid, report.Commits[0].Status)
+		})
+	}
+}
+
+func TestMultiRepositorySession(t *testing.T) {
+	s, _, h := setupGitHandler(t)
+	api := initGitRepo(t, 1)
+	client := initGitRepo(t, 2)
+	plain := t.TempDir()
+	dirs, err := json.Marshal([]string{client, plain, client})
+	require.NoError(t, err)
+	require.NoError(t, s.CreateSession(context.Background(), &store.Session{
+		ID: "sess-multi", RunID: "run-multi", WorkingDir: api, AdditionalDirectories: string(dirs),
+		Status: store.SessionStatusCompleted, CreatedAt: time.Now(),
+	}))
+
+	router := gin.New()
+	router.GET("/api/v1/sessions/:id/git/repos", h.HandleGetRepositories)
+	router.GET("/api/v1/sessions/:id/git/status", h.HandleGetGitStatus)
+	router.POST("/api/v1/sessions/:id/git/generate-commit-message", h.HandleGenerateCommitMessage)
+	router.POST("/api/v1/sessions/:id/git/commit", h.HandleCommitChanges)
+
+	w := makeRequest(t, router, "GET", "/api/v1/sessions/sess-multi/git/repos", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var repos handlers.RepositoriesResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&repos))
+	require.Len(t, repos.Repositories, 3, "duplicate directories are listed once")
+	assert.True(t, repos.HasChanges)
+	assert.True(t, repos.Repositories[0].Primary)
+	assert.Equal(t, api, repos.Repositories[0].Path)
+	require.NotNil(t, repos.Repositories[1].Status)
+	assert.Len(t, repos.Repositories[1].Status.Staged, 2)
+	assert.Nil(t, repos.Repositories[2].Status)
+	assert.Equal(t, "Not a git repository", repos.Repositories[2].Error)
+	clientName := repos.Repositories[1].Name
+	assert.Equal(t, filepath.Base(client), clientName)
+
+	w = makeRequest(t, router, "GET", "/api/v1/sessions/sess-multi/git/status?repo="+clientName, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var status handlers.GitStatusResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&status))
+	assert.Len(t, status.Staged, 2)
+
+	w = makeRequest(t, router, "GET", "/api/v1/sessions/sess-multi/git/status?repo=nope", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = makeRequest(t, router, "GET", "/api/v1/sessions/sess-multi/git/status?repo=all", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/sess-multi/git/generate-commit-message?repo=all", handlers.GenerateCommitMessageRequest{})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var plans handlers.CommitPlansResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&plans))
+	require.Len(t, plans.Repositories, 2)
+	assert.Equal(t, api, plans.Repositories[0].Path)
+	assert.Equal(t, clientName, plans.Repositories[1].Name)
+	require.NotNil(t, plans.Repositories[1].Plan)
+	assert.Equal(t, 2, plans.Repositories[1].Plan.GitContext.ChangedFileCount)
+
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/sess-multi/git/commit?repo="+client, handlers.CommitRequest{
+		Commits: []handlers.CommitMessage{{Subject: "feat: client"}},
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	out, err := exec.Command("git", "-C", client, "log", "-1", "--format=%s").Output()
+	require.NoError(t, err)
+	assert.Equal(t, "feat: client", strings.TrimSpace(string(out)))
+	out, err = exec.Command("git", "-C", api, "log", "-1", "--format=%s").Output()
+	require.NoError(t, err)
+	assert.Equal(t, "initial", strings.TrimSpace(string(out)), "other repositories are untouched")
+}
+
+func TestFetchAndPull(t *testing.T) {
+	s, _, h := setupGitHandler(t)
+	ctx := context.Background()
+	gitIn := func(dir string, args ...string) string {
+		out, err := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+		return strings.TrimSpace(string(out))
+	}
+	commitFile := func(dir, name, content, message string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+		gitIn(dir, "add", name)
+		gitIn(dir, "commit", "-q", "-m", message)
+	}
+
+	// The session works in a clone of a shared remote that someone else pushes to
+	source, err := s.GetSession(ctx, "sess-1")
+	require.NoError(t, err)
+	gitIn(source.WorkingDir, "commit", "-q", "-m", "add files")
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	gitIn(source.WorkingDir, "clone", "-q", "--bare", source.WorkingDir, remote)
+	local, other := t.TempDir(), t.TempDir()
+	gitIn(local, "clone", "-q", remote, ".")
+	gitIn(other, "clone", "-q", remote, ".")
+	require.NoError(t, s.CreateSession(ctx, &store.Session{
+		ID: "sess-2", RunID: "run-2", WorkingDir: local, Status: store.SessionStatusCompleted, CreatedAt: time.Now(),
+	}))
+	commitFile(other, "upstream.txt", "upstream\n", "upstream change")
+	gitIn(other, "push", "-q")
+
+	router := gin.New()
+	router.POST("/api/v1/sessions/:id/git/fetch", h.HandleFetch)
+	router.POST("/api/v1/sessions/:id/git/pull", h.HandlePull)
+
+	w := makeRequest(t, router, "POST", "/api/v1/sessions/sess-2/git/fetch", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var fetched handlers.FetchResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&fetched))
+	assert.Equal(t, "origin", fetched.Remote)
+	require.NotNil(t, fetched.Divergence)
+	assert.Equal(t, 1, fetched.Divergence.Behind)
+	assert.True(t, fetched.Divergence.FastForward)
+	require.Len(t, fetched.Divergence.Incoming, 1)
+	assert.Equal(t, "upstream change", fetched.Divergence.Incoming[0].Subject)
+
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/sess-2/git/pull", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var pulled handlers.PullResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&pulled))
+	assert.True(t, pulled.Success)
+	assert.Equal(t, gitIn(other, "rev-parse", "HEAD"), pulled.After)
+
+	// Diverged: fast-forward only refuses, rebase replays the local commit
+	commitFile(other, "upstream2.txt", "more\n", "second upstream change")
+	gitIn(other, "push", "-q")
+	commitFile(local, "local.txt", "local\n", "local change")
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/sess-2/git/pull", nil)
+	require.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	pulled = handlers.PullResponse{}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&pulled))
+	assert.False(t, pulled.Success)
+	assert.Equal(t, 1, pulled.Divergence.Ahead)
+	assert.Equal(t, 1, pulled.Divergence.Behind)
+	assert.Contains(t, pulled.Error, "diverged")
+
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/sess-2/git/pull", handlers.PullRequest{Strategy: handlers.PullRebase})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "local change", gitIn(local, "log", "-1", "--format=%s"))
+	assert.Equal(t, gitIn(other, "rev-parse", "HEAD"), gitIn(local, "rev-parse", "HEAD~1"))
+
+	// A conflicting merge is aborted, leaving the branch as it was
+	commitFile(other, "file0.txt", "theirs\n", "edit file0 upstream")
+	gitIn(other, "push", "-q")
+	commitFile(local, "file0.txt", "ours\n", "edit file0 locally")
+	before := gitIn(local, "rev-parse", "HEAD")
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/sess-2/git/pull", handlers.PullRequest{Strategy: handlers.PullMerge})
+	require.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	pulled = handlers.PullResponse{}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&pulled))
+	assert.Equal(t, []string{"file0.txt"}, pulled.Conflicts)
+	assert.Equal(t, before, gitIn(local, "rev-parse", "HEAD"))
+	assert.Empty(t, gitIn(local, "status", "--porcelain"))
+
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/sess-2/git/pull", handlers.PullRequest{Strategy: "octopus"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/git/pull", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "a branch without upstream can't be pulled")
+}
+
+func TestBranchesAndCheckout(t *testing.T) {
+	s, _, h := setupGitHandler(t)
+	ctx := context.Background()
+	source, err := s.GetSession(ctx, "sess-1")
+	require.NoError(t, err)
+	dir := source.WorkingDir
+	gitIn := func(args ...string) string {
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+		return strings.TrimSpace(string(out))
+	}
+	gitIn("commit", "-q", "-m", "add files")
+	gitIn("branch", "-M", "main")
+	gitIn("switch", "-q", "-c", "feature")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "feature.txt"), []byte("feature\n"), 0644))
+	gitIn("add", "feature.txt")
+	gitIn("commit", "-q", "-m", "feature work")
+
+	router := gin.New()
+	router.GET("/api/v1/sessions/:id/git/branches", h.HandleListBranches)
+	router.POST("/api/v1/sessions/:id/git/checkout", h.HandleCheckout)
+
+	w := makeRequest(t, router, "GET", "/api/v1/sessions/sess-1/git/branches", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var branches handlers.BranchesResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&branches))
+	assert.Equal(t, "feature", branches.Current)
+	assert.Equal(t, "main", branches.Default)
+	require.Len(t, branches.Branches, 2)
+	assert.Equal(t, "feature", branches.Branches[0].Name)
+	assert.True(t, branches.Branches[0].Current)
+	assert.Equal(t, 1, branches.Branches[0].Ahead)
+	assert.Equal(t, "feature work", branches.Branches[0].Subject)
+
+	// Uncommitted changes block the switch unless they're stashed
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "file0.txt"), []byte("edited\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "scratch.txt"), []byte("new\n"), 0644))
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/git/checkout", handlers.CheckoutRequest{Branch: "main"})
+	require.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	assert.Equal(t, "feature", gitIn("branch", "--show-current"))
+
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/git/checkout", handlers.CheckoutRequest{Branch: "main", Stash: true})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response handlers.CheckoutResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, "main", response.Branch)
+	assert.Equal(t, "feature", response.Previous)
+	assert.Equal(t, gitIn("rev-parse", "stash@{0}"), response.Stash)
+	assert.Contains(t, gitIn("stash", "list"), "before switching to main")
+	assert.Empty(t, gitIn("status", "--porcelain"))
+
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/git/checkout", handlers.CheckoutRequest{Branch: "fix/typo", Create: true})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "fix/typo", gitIn("branch", "--show-current"))
+
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/git/checkout", handlers.CheckoutRequest{Branch: "missing"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/git/checkout", handlers.CheckoutRequest{Branch: "bad..name"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestStash(t *testing.T) {
+	s, _, h := setupGitHandler(t)
+	source, err := s.GetSession(context.Background(), "sess-1")
+	require.NoError(t, err)
+	dir := source.WorkingDir
+	gitIn := func(args ...string) string {
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+		return strings.TrimSpace(string(out))
+	}
+	gitIn("commit", "-q", "-m", "add files")
+	gitIn("branch", "-M", "main")
+
+	router := gin.New()
+	router.GET("/api/v1/sessions/:id/git/stash", h.HandleListStashes)
+	router.POST("/api/v1/sessions/:id/git/stash", h.HandleCreateStash)
+	router.POST("/api/v1/sessions/:id/git/stash/:index/apply", h.HandleApplyStash)
+	router.POST("/api/v1/sessions/:id/git/stash/:index/pop", h.HandlePopStash)
+	router.DELETE("/api/v1/sessions/:id/git/stash/:index", h.HandleDropStash)
+	list := func() []handlers.GitStash {
+		t.Helper()
+		w := makeRequest(t, router, "GET", "/api/v1/sessions/sess-1/git/stash", nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var response handlers.StashesResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		return response.Stashes
+	}
+	assert.Empty(t, list())
+
+	w := makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/git/stash", handlers.CreateStashRequest{})
+	assert.Equal(t, http.StatusConflict, w.Code, "nothing to stash")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "file0.txt"), []byte("edited\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "scratch.txt"), []byte("new\n"), 0644))
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/git/stash", handlers.CreateStashRequest{Message: "park edits"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created handlers.StashResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&created))
+	require.NotNil(t, created.Stash)
+	assert.Equal(t, "park edits", created.Stash.Message)
+	assert.Equal(t, "main", created.Stash.Branch)
+	assert.Equal(t, 2, created.Stash.FileCount, "untracked files count")
+	assert.Equal(t, gitIn("rev-parse", "stash@{0}"), created.Stash.Commit)
+	assert.Empty(t, gitIn("status", "--porcelain"))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "file1.txt"), []byte("edited\n"), 0644))
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/git/stash", nil)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	stashes := list()
+	require.Len(t, stashes, 2)
+	assert.Equal(t, 0, stashes[0].Index)
+	assert.Contains(t, stashes[0].Message, "session sess-1 on main")
+	assert.Equal(t, "stash@{1}", stashes[1].Ref)
+	assert.Equal(t, created.Stash.Commit, stashes[1].Commit)
+	assert.WithinDuration(t, time.Now(), stashes[1].CreatedAt, time.Minute)
+
+	// The index moved, so the old commit no longer matches stash@{0}
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/git/stash/0/pop", handlers.ApplyStashRequest{Commit: created.Stash.Commit})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/git/stash/5/apply", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/git/stash/x/apply", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/git/stash/1/pop", handlers.ApplyStashRequest{Commit: created.Stash.Commit})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	content, err := os.ReadFile(filepath.Join(dir, "file0.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "edited\n", string(content))
+	assert.FileExists(t, filepath.Join(dir, "scratch.txt"))
+	require.Len(t, list(), 1)
+
+	// file0.txt is edited in both, so applying the other stash conflicts
+	gitIn("add", "-A")
+	gitIn("commit", "-q", "-m", "keep edits")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "file1.txt"), []byte("different\n"), 0644))
+	gitIn("commit", "-q", "-am", "change file1")
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/git/stash/0/pop", nil)
+	require.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	var conflict handlers.StashResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&conflict))
+	assert.Equal(t, []string{"file1.txt"}, conflict.Conflicts)
+	require.Len(t, list(), 1, "a conflicted pop keeps the stash")
+	gitIn("reset", "-q", "--hard")
+
+	w = makeRequest(t, router, "DELETE", "/api/v1/sessions/sess-1/git/stash/0?commit="+stashes[0].Commit, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Empty(t, list())
+
+	// A running session's stash is left alone unless forced
+	running := store.SessionStatusRunning
+	require.NoError(t, s.UpdateSession(context.Background(), "sess-1", store.SessionUpdate{Status: &running}))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "file2.txt"), []byte("edited\n"), 0644))
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/git/stash", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/git/stash", handlers.CreateStashRequest{Force: true})
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+}
+
+func TestStaleBranchCleanup(t *testing.T) {
+	s, _, h := setupGitHandler(t)
+	ctx := context.Background()
+	source, err := s.GetSession(ctx, "sess-1")
+	require.NoError(t, err)
+	dir := source.WorkingDir
+	gitIn := func(env []string, args ...string) string {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(), env...)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return strings.TrimSpace(string(out))
+	}
+	old := []string{"GIT_COMMITTER_DATE=2020-01-01T00:00:00Z", "GIT_AUTHOR_DATE=2020-01-01T00:00:00Z"}
+	branchWithCommit := func(name string, env []string, message string) string {
+		gitIn(nil, "switch", "-q", "-c", name, "main")
+		require.NoError(t, os.WriteFile(filepath.Join(dir, strings.ReplaceAll(name, "/", "-")+".txt"), []byte(name), 0644))
+		gitIn(nil, "add", "-A")
+		gitIn(env, "commit", "-q", "-m", message)
+		return gitIn(nil, "rev-parse", "HEAD")
+	}
+	gitIn(nil, "commit", "-q", "-m", "add files")
+	gitIn(nil, "branch", "-M", "main")
+
+	// A session's commit, merged; a provenance-stamped commit, abandoned;
+	// a recent unmerged session branch; and a branch no session touched
+	merged := branchWithCommit("merged-work", nil, "merged work")
+	require.NoError(t, s.CreateSessionCommit(ctx, &store.SessionCommit{SessionID: "sess-1", Repository: gitIn(nil, "rev-parse", "--show-toplevel"), Hash: merged}))
+	gitIn(nil, "switch", "-q", "main")
+	gitIn(nil, "merge", "-q", "--ff-only", "merged-work")
+	gitIn(nil, "commit", "-q", "--allow-empty", "-m", "move main on")
+	branchWithCommit("abandoned", old, "old experiment\n\nHumanLayer-Session: sess-old")
+	branchWithCommit("humanlayer/issue-7", nil, "recent fix")
+	branchWithCommit("someone-elses", old, "not a session")
+	gitIn(nil, "switch", "-q", "main")
+
+	router := gin.New()
+	router.GET("/api/v1/sessions/:id/git/branches/stale", h.HandleListStaleBranches)
+	router.POST("/api/v1/sessions/:id/git/branches/cleanup", h.HandleCleanupBranches)
+
+	w := makeRequest(t, router, "GET", "/api/v1/sessions/sess-1/git/branches/stale", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var stale handlers.StaleBranchesResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&stale))
+	assert.Equal(t, "main", stale.Default)
+	reasons := map[string]string{}
+	for _, branch := range stale.Branches {
+		reasons[branch.Name] = branch.Reason
+		if branch.Name == "merged-work" {
+			assert.Equal(t, []string{"sess-1"}, branch.Sessions)
+		}
+		if branch.Name == "abandoned" {
+			assert.Equal(t, []string{"sess-old"}, branch.Sessions)
+			assert.Equal(t, 1, branch.Unmerged)
+		}
+	}
+	assert.Equal(t, map[string]string{"merged-work": handlers.StaleMerged, "abandoned": handlers.StaleAbandoned}, reasons)
+
+	request := handlers.BranchCleanupRequest{Branches: []string{"merged-work", "abandoned", "someone-elses", "main"}, DryRun: true}
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/git/branches/cleanup", request)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response handlers.BranchCleanupResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	require.Len(t, response.Results, 4)
+	assert.True(t, response.Results[0].Deleted)
+	assert.True(t, response.Results[1].Deleted)
+	assert.False(t, response.Results[2].Deleted)
+	assert.NotEmpty(t, response.Results[3].Error)
+	assert.Contains(t, gitIn(nil, "branch"), "abandoned", "a dry run deletes nothing")
+
+	request.DryRun = false
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/git/branches/cleanup", request)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	branches := gitIn(nil, "branch", "--format=%(refname:short)")
+	assert.NotContains(t, branches, "merged-work")
+	assert.NotContains(t, branches, "abandoned")
+	assert.Contains(t, branches, "someone-elses")
+	assert.Contains(t, branches, "humanlayer/issue-7")
+}
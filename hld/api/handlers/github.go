@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/github"
+)
+
+const (
+	// maxWebhookBody matches GitHub's 25 MB payload cap
+	maxWebhookBody = 25 << 20
+	// webhookLaunchTimeout bounds fetching, checkout and launch, which run
+	// after the delivery is acknowledged
+	webhookLaunchTimeout = 10 * time.Minute
+)
+
+// GitHubWebhookHandler receives GitHub webhooks that launch sessions
+type GitHubWebhookHandler struct {
+	receiver *github.Receiver
+}
+
+// NewGitHubWebhookHandler creates a new GitHub webhook handler
+func NewGitHubWebhookHandler(receiver *github.Receiver) *GitHubWebhookHandler {
+	return &GitHubWebhookHandler{receiver: receiver}
+}
+
+// HandleWebhook verifies a delivery and, if it is a fix label or review
+// command for a configured repository, launches its session in the
+// background. GitHub gets 202 before the checkout starts, since fetching can
+// outlast its 10 second delivery timeout.
+func (h *GitHubWebhookHandler) HandleWebhook(c *gin.Context) {
+	if !h.receiver.Enabled() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "GitHub webhooks are not configured"})
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookBody))
+	if err != nil {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Payload too large"})
+		return
+	}
+	if !h.receiver.Verify(c.GetHeader("X-Hub-Signature-256"), body) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid signature"})
+		return
+	}
+
+	eventType := c.GetHeader("X-GitHub-Event")
+	if eventType == "ping" {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+		return
+	}
+	trigger, reason, err := h.receiver.Parse(eventType, body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if trigger == nil {
+		c.JSON(http.StatusOK, gin.H{"ignored": reason})
+		return
+	}
+	deliveryID := c.GetHeader("X-GitHub-Delivery")
+	if deliveryID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "X-GitHub-Delivery header is required"})
+		return
+	}
+	if !h.receiver.Claim(deliveryID) {
+		c.JSON(http.StatusOK, gin.H{"ignored": "duplicate delivery"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Request.Context()), webhookLaunchTimeout)
+	go func() {
+		defer cancel()
+		if _, err := h.receiver.Launch(ctx, deliveryID, trigger); err != nil {
+			slog.Error("failed to launch session from github webhook", "repository", trigger.Repository,
+				"number", trigger.Number, "delivery_id", deliveryID, "error", err)
+		}
+	}()
+	c.JSON(http.StatusAccepted, gin.H{"trigger": trigger})
+}
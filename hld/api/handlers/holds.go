@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/approval"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// HoldsHandler serves approval holds, which park a pending approval with a
+// reason instead of deciding it
+type HoldsHandler struct {
+	holds *approval.Holds
+}
+
+// NewHoldsHandler creates a new holds handler
+func NewHoldsHandler(holds *approval.Holds) *HoldsHandler {
+	return &HoldsHandler{holds: holds}
+}
+
+// holdRequest sets when a hold ends, either as a time or as a duration from
+// now such as "2h"; with neither the approval stays held until unheld
+type holdRequest struct {
+	Reason   string     `json:"reason"`
+	ResumeAt *time.Time `json:"resume_at"`
+	For      string     `json:"for"`
+	HeldBy   string     `json:"held_by"`
+}
+
+// HandleHold parks a pending approval
+func (h *HoldsHandler) HandleHold(c *gin.Context) {
+	var req holdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	resumeAt := req.ResumeAt
+	if req.For != "" {
+		d, err := time.ParseDuration(req.For)
+		if err != nil || req.ResumeAt != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "give either resume_at or a duration in for"})
+			return
+		}
+		t := time.Now().Add(d)
+		resumeAt = &t
+	}
+	hold, err := h.holds.Hold(c.Request.Context(), c.Param("id"), req.Reason, resumeAt, req.HeldBy)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, hold)
+}
+
+// HandleUnhold puts a held approval back in the queue
+func (h *HoldsHandler) HandleUnhold(c *gin.Context) {
+	if err := h.holds.Unhold(c.Request.Context(), c.Param("id")); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// HandleList returns the holds on pending approvals, oldest first
+func (h *HoldsHandler) HandleList(c *gin.Context) {
+	holds, err := h.holds.List(c.Request.Context())
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": holds})
+}
+
+func (h *HoldsHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, approval.ErrHoldReasonRequired), errors.Is(err, approval.ErrInvalidResume):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, store.ErrAlreadyDecided):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, approval.ErrNotHeld):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, store.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Approval not found"})
+	default:
+		slog.Error("approval hold failed", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Approval hold failed"})
+	}
+}
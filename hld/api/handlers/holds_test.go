@@ -0,0 +1,58 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/api/handlers"
+	"github.com/humanlayer/humanlayer/hld/approval"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHoldsHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := store.NewInMemoryStore()
+	require.NoError(t, s.CreateApproval(context.Background(), &store.Approval{ID: "appr-1", SessionID: "sess-1", ToolName: "Bash", Status: store.ApprovalStatusLocalPending}))
+	h := handlers.NewHoldsHandler(approval.NewHolds(s, s, nil))
+	router := gin.New()
+	router.GET("/api/v1/approvals/held", h.HandleList)
+	router.POST("/api/v1/approvals/:id/hold", h.HandleHold)
+	router.DELETE("/api/v1/approvals/:id/hold", h.HandleUnhold)
+
+	w := makeRequest(t, router, "POST", "/api/v1/approvals/appr-1/hold", map[string]any{})
+	assert.Equal(t, http.StatusBadRequest, w.Code, "holds need a reason")
+	w = makeRequest(t, router, "POST", "/api/v1/approvals/appr-1/hold", map[string]any{"reason": "freeze", "for": "soon"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = makeRequest(t, router, "POST", "/api/v1/approvals/missing/hold", map[string]any{"reason": "freeze"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = makeRequest(t, router, "POST", "/api/v1/approvals/appr-1/hold", map[string]any{"reason": "change freeze", "for": "2h", "held_by": "alice"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var hold store.ApprovalHold
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&hold))
+	assert.Equal(t, "change freeze", hold.Reason)
+	assert.NotNil(t, hold.ResumeAt)
+
+	w = makeRequest(t, router, "GET", "/api/v1/approvals/held", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Data []store.ApprovalHold `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&list))
+	require.Len(t, list.Data, 1)
+	assert.Equal(t, "alice", list.Data[0].HeldBy)
+
+	w = makeRequest(t, router, "DELETE", "/api/v1/approvals/appr-1/hold", nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = makeRequest(t, router, "DELETE", "/api/v1/approvals/appr-1/hold", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	require.NoError(t, s.UpdateApprovalResponse(context.Background(), "appr-1", store.ApprovalStatusLocalApproved, ""))
+	w = makeRequest(t, router, "POST", "/api/v1/approvals/appr-1/hold", map[string]any{"reason": "freeze"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/hooks"
+)
+
+// maxHookBody bounds inbound hook payloads; alerts and form submissions are small
+const maxHookBody = 1 << 20
+
+// HookHandler receives deliveries to the configured inbound hooks
+type HookHandler struct {
+	receiver *hooks.Receiver
+}
+
+// NewHookHandler creates a new inbound hook handler
+func NewHookHandler(receiver *hooks.Receiver) *HookHandler {
+	return &HookHandler{receiver: receiver}
+}
+
+// HandleHook verifies a delivery to the named hook, maps its payload to a
+// query and launches the session. A delivery that doesn't satisfy the hook's
+// match rules is acknowledged with the reason it was ignored, so senders
+// don't retry it. An Idempotency-Key header makes redeliveries launch once.
+func (h *HookHandler) HandleHook(c *gin.Context) {
+	name := c.Param("name")
+	hook, ok := h.receiver.Hook(name)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Hook not found"})
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxHookBody))
+	if err != nil {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Payload too large"})
+		return
+	}
+	if !hooks.Verify(hook.Secret, c.GetHeader("Authorization"), c.GetHeader("X-Signature-256"), body) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		return
+	}
+
+	payload, err := hooks.Decode(c.ContentType(), body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	trigger, reason, err := hooks.Map(name, hook, payload)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	if trigger == nil {
+		c.JSON(http.StatusOK, gin.H{"ignored": reason})
+		return
+	}
+	if key := c.GetHeader("Idempotency-Key"); key != "" && !h.receiver.Claim(name, key) {
+		c.JSON(http.StatusOK, gin.H{"ignored": "duplicate delivery"})
+		return
+	}
+
+	sess, err := h.receiver.Launch(c.Request.Context(), trigger)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"session_id": sess.ID, "run_id": sess.RunID, "trigger": trigger})
+}
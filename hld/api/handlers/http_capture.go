@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/httpcapture"
+)
+
+// HTTPCaptureHandler serves the requests and responses recorded for debugging
+type HTTPCaptureHandler struct {
+	recorder *httpcapture.Recorder
+}
+
+// NewHTTPCaptureHandler creates a handler for recorder, which is nil when
+// capture is off
+func NewHTTPCaptureHandler(recorder *httpcapture.Recorder) *HTTPCaptureHandler {
+	return &HTTPCaptureHandler{recorder: recorder}
+}
+
+// HTTPCapturesResponse lists captured requests, newest first
+type HTTPCapturesResponse struct {
+	Captures []httpcapture.Capture `json:"captures"`
+}
+
+// HandleListCaptures lists captures, filtered by ?route= (the route pattern
+// or request path), ?method=, ?status=, ?request_id= and capped by ?limit=
+func (h *HTTPCaptureHandler) HandleListCaptures(c *gin.Context) {
+	if h.recorder == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "HTTP capture is not enabled; set http_capture.enabled"})
+		return
+	}
+	filter := httpcapture.Filter{
+		Route:     c.Query("route"),
+		Method:    c.Query("method"),
+		RequestID: c.Query("request_id"),
+	}
+	for name, field := range map[string]*int{"status": &filter.Status, "limit": &filter.Limit} {
+		if value := c.Query(name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": name + " must be a non-negative integer"})
+				return
+			}
+			*field = n
+		}
+	}
+	c.JSON(http.StatusOK, HTTPCapturesResponse{Captures: h.recorder.List(filter)})
+}
+
+// HandleClearCaptures drops every capture
+func (h *HTTPCaptureHandler) HandleClearCaptures(c *gin.Context) {
+	if h.recorder == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "HTTP capture is not enabled; set http_capture.enabled"})
+		return
+	}
+	h.recorder.Clear()
+	c.Status(http.StatusNoContent)
+}
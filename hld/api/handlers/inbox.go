@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/inbox"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// InboxHandler serves users' approval inboxes
+type InboxHandler struct {
+	inbox *inbox.Service
+}
+
+// NewInboxHandler creates a new inbox handler
+func NewInboxHandler(service *inbox.Service) *InboxHandler {
+	return &InboxHandler{inbox: service}
+}
+
+type inboxMarkRequest struct {
+	ApprovalIDs []string `json:"approval_ids" binding:"required"`
+}
+
+// inboxSnoozeRequest sets when a snooze ends, either as a time or as a
+// duration from now such as "2h"
+type inboxSnoozeRequest struct {
+	Until *time.Time `json:"until"`
+	For   string     `json:"for"`
+}
+
+// HandleList returns a user's inbox items, filtered by the category and
+// state query parameters, with counts per category
+func (h *InboxHandler) HandleList(c *gin.Context) {
+	ctx := c.Request.Context()
+	user := userParam(c)
+	items, err := h.inbox.List(ctx, user, inbox.Filter{Category: c.Query("category"), State: c.Query("state")})
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	counts, err := h.inbox.Counts(ctx, user)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": items, "counts": counts})
+}
+
+// HandleCounts returns a user's item counts per category
+func (h *InboxHandler) HandleCounts(c *gin.Context) {
+	counts, err := h.inbox.Counts(c.Request.Context(), userParam(c))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": counts})
+}
+
+// HandleMarkRead marks approvals read
+func (h *InboxHandler) HandleMarkRead(c *gin.Context) {
+	h.mark(c, true)
+}
+
+// HandleMarkUnread marks approvals unread
+func (h *InboxHandler) HandleMarkUnread(c *gin.Context) {
+	h.mark(c, false)
+}
+
+func (h *InboxHandler) mark(c *gin.Context, read bool) {
+	var req inboxMarkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "approval_ids is required"})
+		return
+	}
+	if err := h.inbox.MarkRead(c.Request.Context(), userParam(c), req.ApprovalIDs, read); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// HandleSnooze hides an approval from the user until a time
+func (h *InboxHandler) HandleSnooze(c *gin.Context) {
+	var req inboxSnoozeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	until := req.Until
+	if req.For != "" {
+		d, err := time.ParseDuration(req.For)
+		if err != nil || req.Until != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "give either until or a duration in for"})
+			return
+		}
+		t := time.Now().Add(d)
+		until = &t
+	}
+	if until == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "until or for is required"})
+		return
+	}
+	if err := h.inbox.Snooze(c.Request.Context(), userParam(c), c.Param("id"), *until); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"approval_id": c.Param("id"), "snoozed_until": until})
+}
+
+// HandleUnsnooze brings a snoozed approval back to the user's inbox
+func (h *InboxHandler) HandleUnsnooze(c *gin.Context) {
+	if err := h.inbox.Unsnooze(c.Request.Context(), userParam(c), c.Param("id")); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *InboxHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, inbox.ErrInvalidFilter), errors.Is(err, inbox.ErrInvalidSnooze):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, inbox.ErrNotPending):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, store.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Approval not found"})
+	default:
+		slog.Error("inbox operation failed", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Inbox operation failed"})
+	}
+}
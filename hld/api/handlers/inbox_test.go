@@ -0,0 +1,65 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/api/handlers"
+	"github.com/humanlayer/humanlayer/hld/inbox"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInbox(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	require.NoError(t, s.CreateSession(ctx, &store.Session{ID: "sess-1", RunID: "run-1"}))
+	for _, id := range []string{"a1", "a2"} {
+		require.NoError(t, s.CreateApproval(ctx, &store.Approval{
+			ID: id, RunID: "run-1", SessionID: "sess-1", Status: store.ApprovalStatusLocalPending,
+			ToolName: "Bash", ToolInput: json.RawMessage(`{}`), CreatedAt: time.Now(),
+		}))
+	}
+	h := handlers.NewInboxHandler(inbox.New(s, s, nil))
+	router := gin.New()
+	router.GET("/api/v1/users/:user/inbox", h.HandleList)
+	router.GET("/api/v1/users/:user/inbox/counts", h.HandleCounts)
+	router.POST("/api/v1/users/:user/inbox/read", h.HandleMarkRead)
+	router.POST("/api/v1/users/:user/inbox/:id/snooze", h.HandleSnooze)
+	router.DELETE("/api/v1/users/:user/inbox/:id/snooze", h.HandleUnsnooze)
+
+	w := makeRequest(t, router, "POST", "/api/v1/users/alice/inbox/read", map[string]any{"approval_ids": []string{"a1"}})
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	w = makeRequest(t, router, "POST", "/api/v1/users/alice/inbox/a2/snooze", map[string]any{"for": "1h"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = makeRequest(t, router, "GET", "/api/v1/users/alice/inbox", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Data   []inbox.Item            `json:"data"`
+		Counts map[string]inbox.Counts `json:"counts"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&list))
+	require.Len(t, list.Data, 1)
+	assert.Equal(t, "a1", list.Data[0].ID)
+	assert.True(t, list.Data[0].Read)
+	assert.Equal(t, inbox.Counts{Total: 1, Snoozed: 1}, list.Counts[inbox.CategoryApproval])
+
+	w = makeRequest(t, router, "DELETE", "/api/v1/users/alice/inbox/a2/snooze", nil)
+	require.Equal(t, http.StatusNoContent, w.Code)
+	w = makeRequest(t, router, "GET", "/api/v1/users/alice/inbox/counts", nil)
+	assert.JSONEq(t, `{"data":{"approval":{"total":2,"unread":1,"snoozed":0},"input":{"total":0,"unread":0,"snoozed":0}}}`, w.Body.String())
+
+	w = makeRequest(t, router, "GET", "/api/v1/users/alice/inbox?state=starred", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = makeRequest(t, router, "POST", "/api/v1/users/alice/inbox/a1/snooze", map[string]any{})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = makeRequest(t, router, "POST", "/api/v1/users/alice/inbox/missing/snooze", map[string]any{"for": "1h"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// InjectionsHandler serves the suspected prompt injections found in session
+// text while the daemon built its own prompts
+type InjectionsHandler struct {
+	store store.InjectionStore
+}
+
+// NewInjectionsHandler creates a new prompt injection handler
+func NewInjectionsHandler(detections store.InjectionStore) *InjectionsHandler {
+	return &InjectionsHandler{store: detections}
+}
+
+// HandleListSession returns a session's detections, oldest first
+func (h *InjectionsHandler) HandleListSession(c *gin.Context) {
+	sessionID := c.Param("id")
+	detections, err := h.store.ListInjectionDetections(c.Request.Context(), sessionID)
+	if err != nil {
+		slog.Error("failed to list prompt injections", "session_id", sessionID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list prompt injections"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": detections})
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/jobs"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// JobHandler reports the status and result of background jobs
+type JobHandler struct {
+	store store.JobStore
+}
+
+// NewJobHandler creates a new job handler
+func NewJobHandler(jobs store.JobStore) *JobHandler {
+	return &JobHandler{store: jobs}
+}
+
+// JobAccepted is the response to a request run as a background job
+type JobAccepted struct {
+	JobID     string `json:"job_id"`
+	StatusURL string `json:"status_url"`
+}
+
+// HandleGetJob returns a job's status, and its result once completed
+func (h *JobHandler) HandleGetJob(c *gin.Context) {
+	job, err := h.store.GetJob(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		slog.Error("failed to get job", "job_id", c.Param("id"), "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get job"})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// HandleListJobs returns jobs newest first, optionally filtered by ?status=
+func (h *JobHandler) HandleListJobs(c *gin.Context) {
+	list, err := h.store.ListJobs(c.Request.Context(), c.Query("status"))
+	if err != nil {
+		slog.Error("failed to list jobs", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list jobs"})
+		return
+	}
+	if list == nil {
+		list = []*store.Job{}
+	}
+	c.JSON(http.StatusOK, gin.H{"jobs": list})
+}
+
+// wantsJob reports whether the client asked (with ?async=true) for a job ID
+// instead of waiting for the result; waiting remains the default
+func wantsJob(c *gin.Context, m *jobs.Manager) bool {
+	return m != nil && c.Query("async") == "true"
+}
+
+// submitJob starts fn as a background job and responds 202 with its ID
+func submitJob(c *gin.Context, m *jobs.Manager, kind, sessionID string, fn jobs.Func) {
+	job, err := m.Submit(c.Request.Context(), kind, sessionID, fn)
+	if err != nil {
+		slog.Error("failed to submit job", "kind", kind, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start job"})
+		return
+	}
+	statusURL := "/api/v1/jobs/" + job.ID
+	c.Header("Location", statusURL)
+	c.JSON(http.StatusAccepted, JobAccepted{JobID: job.ID, StatusURL: statusURL})
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/plugin"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// ErrJustificationRequired is returned for approving a high-risk tool call
+// without a comment
+var ErrJustificationRequired = errors.New("a comment is required to approve a high-risk tool call")
+
+// RiskAssessor rates how risky a tool call is; the plugin host's risk
+// scorers implement it
+type RiskAssessor interface {
+	AssessRisk(ctx context.Context, req plugin.ToolRequest) *plugin.RiskAssessment
+}
+
+// Justification requires approvers to explain why they approve tool calls
+// whose risk score is at or above a threshold, and records their reasons in
+// the session's conversation as an audit entry
+type Justification struct {
+	assessor  RiskAssessor
+	threshold float64
+	store     store.ConversationStore
+	eventBus  bus.EventBus
+}
+
+// NewJustification creates a justification requirement for risk scores of
+// threshold and above
+func NewJustification(assessor RiskAssessor, threshold float64, s store.ConversationStore, eventBus bus.EventBus) *Justification {
+	return &Justification{assessor: assessor, threshold: threshold, store: s, eventBus: eventBus}
+}
+
+// justified is a high-risk approval being approved, kept to record its
+// justification once the decision goes through
+type justified struct {
+	approval *store.Approval
+	risk     *plugin.RiskAssessment
+}
+
+// check assesses the risk of approving an approval with comment. It returns
+// the approval when it needs a justification, and ErrJustificationRequired
+// when the comment is empty. A nil Justification requires nothing, and an
+// approval that can't be found is left for the decision to report.
+func (j *Justification) check(ctx context.Context, approvalID, comment string) (*justified, error) {
+	if j == nil {
+		return nil, nil
+	}
+	approval, err := j.store.GetApproval(ctx, approvalID)
+	if err != nil {
+		return nil, nil
+	}
+	risk := j.assessor.AssessRisk(ctx, plugin.ToolRequest{
+		SessionID:  approval.SessionID,
+		ApprovalID: approval.ID,
+		ToolName:   approval.ToolName,
+		ToolInput:  approval.ToolInput,
+	})
+	if risk == nil || risk.Score < j.threshold {
+		return nil, nil
+	}
+	if strings.TrimSpace(comment) == "" {
+		return nil, ErrJustificationRequired
+	}
+	return &justified{approval: approval, risk: risk}, nil
+}
+
+// record adds an audit entry with the justification for an approved
+// high-risk tool call to the session's conversation
+func (j *Justification) record(ctx context.Context, approved *justified, comment string) {
+	if approved == nil {
+		return
+	}
+	approval, risk := approved.approval, approved.risk
+	content := fmt.Sprintf("Approved high-risk %s call (risk %.2f", approval.ToolName, risk.Score)
+	if risk.Scorer != "" {
+		content += " from " + risk.Scorer
+	}
+	if risk.Reason != "" {
+		content += ": " + risk.Reason
+	}
+	content += ") with justification: " + comment
+	slog.Info("approved high-risk tool call",
+		"approval_id", approval.ID,
+		"session_id", approval.SessionID,
+		"tool_name", approval.ToolName,
+		"risk", risk.Score,
+		"scorer", risk.Scorer,
+		"justification", comment)
+
+	session, err := j.store.GetSession(ctx, approval.SessionID)
+	if err != nil {
+		slog.Error("failed to record approval justification", "approval_id", approval.ID, "error", err)
+		return
+	}
+	event := &store.ConversationEvent{
+		SessionID:       session.ID,
+		ClaudeSessionID: session.ClaudeSessionID,
+		EventType:       store.EventTypeSystem,
+		Role:            "system",
+		Content:         content,
+	}
+	if err := j.store.AddConversationEvent(ctx, event); err != nil {
+		slog.Error("failed to record approval justification", "approval_id", approval.ID, "error", err)
+		return
+	}
+	if j.eventBus != nil {
+		j.eventBus.Publish(bus.Event{
+			Type: bus.EventConversationUpdated,
+			Data: map[string]interface{}{
+				"session_id":        session.ID,
+				"claude_session_id": session.ClaudeSessionID,
+				"event_type":        store.EventTypeSystem,
+				"content":           content,
+				"content_type":      "system",
+			},
+		})
+	}
+}
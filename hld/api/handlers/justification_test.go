@@ -0,0 +1,69 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/api"
+	"github.com/humanlayer/humanlayer/hld/api/handlers"
+	"github.com/humanlayer/humanlayer/hld/approval"
+	"github.com/humanlayer/humanlayer/hld/plugin"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bashRisk scores Bash calls as risky and everything else as harmless
+type bashRisk struct{}
+
+func (bashRisk) AssessRisk(ctx context.Context, req plugin.ToolRequest) *plugin.RiskAssessment {
+	if req.ToolName == "Bash" {
+		return &plugin.RiskAssessment{Score: 0.9, Reason: "runs a shell command", Scorer: "shell"}
+	}
+	return &plugin.RiskAssessment{Score: 0.1, Scorer: "shell"}
+}
+
+func TestApprovalHandlers_JustificationForHighRisk(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	require.NoError(t, s.CreateSession(ctx, &store.Session{ID: "sess-1", RunID: "run-1", ClaudeSessionID: "claude-1", Status: store.SessionStatusRunning, CreatedAt: time.Now()}))
+	manager := approval.NewManager(s, nil)
+	risky, err := manager.CreateApproval(ctx, "run-1", "Bash", json.RawMessage(`{"command":"terraform apply"}`))
+	require.NoError(t, err)
+	harmless, err := manager.CreateApproval(ctx, "run-1", "Read", json.RawMessage(`{"file_path":"main.tf"}`))
+	require.NoError(t, err)
+
+	h := handlers.NewApprovalHandlers(manager, nil)
+	h.SetJustification(handlers.NewJustification(bashRisk{}, 0.7, s, nil))
+	router := setupTestRouter(t, nil, h, nil)
+
+	// A harmless call is approved without a comment
+	w := makeRequest(t, router, "POST", "/api/v1/approvals/"+harmless+"/decide", api.DecideApprovalRequest{Decision: api.Approve})
+	var resp api.DecideApprovalResponse
+	assertJSONResponse(t, w, 200, &resp)
+
+	w = makeRequest(t, router, "POST", "/api/v1/approvals/"+risky+"/decide", api.DecideApprovalRequest{Decision: api.Approve, Comment: stringPtr("  ")})
+	assertErrorResponse(t, w, "HLD-3001", handlers.ErrJustificationRequired.Error())
+	pending, err := s.GetApproval(ctx, risky)
+	require.NoError(t, err)
+	assert.Equal(t, store.ApprovalStatusLocalPending, pending.Status)
+
+	w = makeRequest(t, router, "POST", "/api/v1/approvals/"+risky+"/decide", api.DecideApprovalRequest{Decision: api.Approve, Comment: stringPtr("Staging only, plan reviewed")})
+	assertJSONResponse(t, w, 200, &resp)
+
+	// The justification is in the session's audit trail
+	events, err := s.GetSessionConversation(ctx, "sess-1")
+	require.NoError(t, err)
+	var audit []string
+	for _, event := range events {
+		if event.EventType == store.EventTypeSystem {
+			audit = append(audit, event.Content)
+		}
+	}
+	require.Len(t, audit, 1)
+	assert.True(t, strings.HasPrefix(audit[0], "Approved high-risk Bash call (risk 0.90 from shell: runs a shell command)"), audit[0])
+	assert.Contains(t, audit[0], "with justification: Staging only, plan reviewed")
+}
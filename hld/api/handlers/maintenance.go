@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/scheduler"
+)
+
+// MaintenanceHandler lists the daemon's maintenance tasks and runs them by
+// hand
+type MaintenanceHandler struct {
+	scheduler *scheduler.Scheduler
+}
+
+// NewMaintenanceHandler creates a new maintenance task handler
+func NewMaintenanceHandler(s *scheduler.Scheduler) *MaintenanceHandler {
+	return &MaintenanceHandler{scheduler: s}
+}
+
+// HandleListTasks returns every task with its schedule and last run
+func (h *MaintenanceHandler) HandleListTasks(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": h.scheduler.Tasks()})
+}
+
+// HandleGetTask returns one task
+func (h *MaintenanceHandler) HandleGetTask(c *gin.Context) {
+	task, err := h.scheduler.Task(c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+	c.JSON(http.StatusOK, task)
+}
+
+// HandleRunTask starts a run of a task now. It runs in the background; poll
+// the task for its outcome.
+func (h *MaintenanceHandler) HandleRunTask(c *gin.Context) {
+	task, err := h.scheduler.Trigger(c.Param("name"))
+	switch {
+	case err == nil:
+		c.JSON(http.StatusAccepted, task)
+	case errors.Is(err, scheduler.ErrUnknownTask):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+	case errors.Is(err, scheduler.ErrTaskRunning):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	}
+}
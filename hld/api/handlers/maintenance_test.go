@@ -0,0 +1,67 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/api/handlers"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/scheduler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenanceTasks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := scheduler.New(map[string]config.MaintenanceTaskConfig{"reap": {Disabled: true}})
+	release := make(chan struct{})
+	s.Register(scheduler.Task{Name: "reap", Description: "Drop stale holds", Interval: time.Minute, Run: func(ctx context.Context) error {
+		<-release
+		return errors.New("store unavailable")
+	}})
+	h := handlers.NewMaintenanceHandler(s)
+	router := gin.New()
+	router.GET("/api/v1/admin/tasks", h.HandleListTasks)
+	router.GET("/api/v1/admin/tasks/:name", h.HandleGetTask)
+	router.POST("/api/v1/admin/tasks/:name/run", h.HandleRunTask)
+
+	w := makeRequest(t, router, "POST", "/api/v1/admin/tasks/reap/run", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code, "before the scheduler runs")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+	require.Eventually(t, func() bool {
+		return makeRequest(t, router, "POST", "/api/v1/admin/tasks/reap/run", nil).Code == http.StatusAccepted
+	}, 2*time.Second, 5*time.Millisecond)
+	w = makeRequest(t, router, "POST", "/api/v1/admin/tasks/reap/run", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = makeRequest(t, router, "POST", "/api/v1/admin/tasks/missing/run", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	close(release)
+
+	var task scheduler.TaskStatus
+	require.Eventually(t, func() bool {
+		w := makeRequest(t, router, "GET", "/api/v1/admin/tasks/reap", nil)
+		return w.Code == http.StatusOK && json.NewDecoder(w.Body).Decode(&task) == nil && task.LastRun != nil
+	}, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, scheduler.RunFailed, task.LastRun.Status)
+	assert.Equal(t, scheduler.TriggerManual, task.LastRun.Trigger)
+	assert.Equal(t, "store unavailable", task.LastRun.Error)
+
+	w = makeRequest(t, router, "GET", "/api/v1/admin/tasks", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Data []scheduler.TaskStatus `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&list))
+	require.Len(t, list.Data, 1)
+	assert.Equal(t, "1m0s", list.Data[0].Interval)
+	assert.True(t, list.Data[0].Disabled)
+	assert.Equal(t, 1, list.Data[0].Failures)
+}
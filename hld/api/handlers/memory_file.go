@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/memoryfile"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// MemoryFileHandler proposes CLAUDE.md updates from completed sessions and
+// applies them once approved
+type MemoryFileHandler struct {
+	store    store.MemoryFileStore
+	proposer *memoryfile.Proposer
+}
+
+// NewMemoryFileHandler creates a new memory file handler
+func NewMemoryFileHandler(proposals store.MemoryFileStore, proposer *memoryfile.Proposer) *MemoryFileHandler {
+	return &MemoryFileHandler{store: proposals, proposer: proposer}
+}
+
+type proposeMemoryFileRequest struct {
+	WorkingDir string   `json:"working_dir" binding:"required"`
+	Path       string   `json:"path"`        // Relative to the repository root; defaults to CLAUDE.md
+	SessionIDs []string `json:"session_ids"` // Defaults to sessions completed since the last applied update
+}
+
+type approveMemoryFileRequest struct {
+	Commit  *bool  `json:"commit"` // Defaults to true
+	Message string `json:"message"`
+}
+
+// HandlePropose drafts a memory file update. It answers 201 with the
+// proposal and its diff, or 200 with a null proposal when the model
+// suggests no change.
+func (h *MemoryFileHandler) HandlePropose(c *gin.Context) {
+	var req proposeMemoryFileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	if req.Path != "" {
+		if err := memoryfile.ValidatePath(req.Path); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	proposal, err := h.proposer.Propose(c.Request.Context(), req.WorkingDir, req.Path, req.SessionIDs)
+	switch {
+	case errors.Is(err, memoryfile.ErrNoLessons):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, store.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	case err != nil:
+		slog.Error("failed to propose memory file update", "working_dir", req.WorkingDir, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to propose memory file update: " + err.Error()})
+		return
+	case proposal == nil:
+		c.JSON(http.StatusOK, gin.H{"proposal": nil})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"proposal": proposal})
+}
+
+// HandleListProposals lists proposals newest first, filtered by repository and status
+func (h *MemoryFileHandler) HandleListProposals(c *gin.Context) {
+	proposals, err := h.store.ListMemoryFileProposals(c.Request.Context(), c.Query("repository"), c.Query("status"))
+	if err != nil {
+		slog.Error("failed to list memory file proposals", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list proposals"})
+		return
+	}
+	if proposals == nil {
+		proposals = []*store.MemoryFileProposal{}
+	}
+	c.JSON(http.StatusOK, gin.H{"proposals": proposals})
+}
+
+// HandleGetProposal returns one proposal with its diff
+func (h *MemoryFileHandler) HandleGetProposal(c *gin.Context) {
+	proposal, err := h.store.GetMemoryFileProposal(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.respondError(c, err, "get")
+		return
+	}
+	c.JSON(http.StatusOK, proposal)
+}
+
+// HandleApprove writes the proposed file and commits it
+func (h *MemoryFileHandler) HandleApprove(c *gin.Context) {
+	var req approveMemoryFileRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+			return
+		}
+	}
+	commit := req.Commit == nil || *req.Commit
+
+	proposal, err := h.proposer.Approve(c.Request.Context(), c.Param("id"), commit, req.Message)
+	if err != nil {
+		h.respondError(c, err, "approve")
+		return
+	}
+	c.JSON(http.StatusOK, proposal)
+}
+
+// HandleReject discards a proposal
+func (h *MemoryFileHandler) HandleReject(c *gin.Context) {
+	if err := h.proposer.Reject(c.Request.Context(), c.Param("id")); err != nil {
+		h.respondError(c, err, "reject")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// respondError maps a failed call on the :id proposal to a response
+func (h *MemoryFileHandler) respondError(c *gin.Context, err error, action string) {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Proposal not found"})
+	case errors.Is(err, memoryfile.ErrNotPending), errors.Is(err, memoryfile.ErrStale):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		slog.Error("failed to "+action+" memory file proposal", "proposal_id", c.Param("id"), "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to " + action + " proposal: " + err.Error()})
+	}
+}
@@ -1,12 +1,22 @@
 package handlers
 
 import (
+	"bytes"
 	"compress/gzip"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
 	"io"
+	"net/http"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/klauspost/compress/zstd"
 )
 
 // RequestIDMiddleware adds a unique request ID to each request
@@ -22,8 +32,11 @@ func RequestIDMiddleware() gin.HandlerFunc {
 	}
 }
 
-// CompressionMiddleware provides gzip compression for responses
-// Skip compression for SSE endpoints as they need raw streaming
+// CompressionMiddleware compresses responses with zstd or gzip, whichever
+// the client accepts, preferring zstd. SSE endpoints and responses a handler
+// has already encoded are left alone. The encoder starts with the first body
+// write, so empty responses such as 204 and 304 stay empty, and handlers
+// streaming a large body can Flush what's been compressed so far.
 func CompressionMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Skip compression for SSE endpoints
@@ -32,35 +45,252 @@ func CompressionMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// Check if client accepts gzip
-		if !strings.Contains(c.GetHeader("Accept-Encoding"), "gzip") {
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" {
 			c.Next()
 			return
 		}
 
-		// Wrap the response writer
-		gz := gzip.NewWriter(c.Writer)
+		c.Header("Vary", "Accept-Encoding")
+		writer := &compressWriter{ResponseWriter: c.Writer, encoding: encoding}
+		c.Writer = writer
 		defer func() {
-			if err := gz.Close(); err != nil {
+			if err := writer.close(); err != nil {
 				// Log error but don't fail the request
 				_ = c.Error(err)
 			}
-			c.Header("Content-Length", "")
 		}()
+		c.Next()
+	}
+}
 
-		c.Header("Content-Encoding", "gzip")
-		c.Header("Vary", "Accept-Encoding")
-		c.Writer = &gzipWriter{ResponseWriter: c.Writer, Writer: gz}
+// negotiateEncoding picks zstd or gzip from an Accept-Encoding header, or
+// returns "" if the client accepts neither
+func negotiateEncoding(acceptEncoding string) string {
+	accepted := make(map[string]bool)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if weight, err := strconv.ParseFloat(q, 64); err == nil && weight == 0 {
+				continue
+			}
+		}
+		accepted[name] = true
+	}
+	switch {
+	case accepted["zstd"]:
+		return "zstd"
+	case accepted["gzip"], accepted["*"]:
+		return "gzip"
+	}
+	return ""
+}
+
+// encoder is what gzip and zstd writers have in common
+type encoder interface {
+	io.Writer
+	Flush() error
+	Close() error
+}
+
+// Encoders are pooled; zstd's in particular are costly to set up
+var (
+	gzipEncoders = sync.Pool{New: func() any { return gzip.NewWriter(io.Discard) }}
+	zstdEncoders = sync.Pool{New: func() any {
+		e, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+		return e
+	}}
+)
+
+// compressWriter compresses what's written through it once the body starts
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string
+	encoder  encoder
+	// passthrough is set when the response was already encoded
+	passthrough bool
+}
+
+func (w *compressWriter) start() {
+	if w.encoder != nil || w.passthrough {
+		return
+	}
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" {
+		w.passthrough = true
+		return
+	}
+	header.Set("Content-Encoding", w.encoding)
+	header.Del("Content-Length")
+	if w.encoding == "zstd" {
+		e := zstdEncoders.Get().(*zstd.Encoder)
+		e.Reset(w.ResponseWriter)
+		w.encoder = e
+		return
+	}
+	e := gzipEncoders.Get().(*gzip.Writer)
+	e.Reset(w.ResponseWriter)
+	w.encoder = e
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	w.start()
+	if w.passthrough {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.encoder.Write(data)
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *compressWriter) Flush() {
+	if w.encoder != nil {
+		_ = w.encoder.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// close finishes the compressed body and returns the encoder to its pool
+func (w *compressWriter) close() error {
+	if w.encoder == nil {
+		return nil
+	}
+	err := w.encoder.Close()
+	switch e := w.encoder.(type) {
+	case *zstd.Encoder:
+		e.Reset(nil)
+		zstdEncoders.Put(e)
+	case *gzip.Writer:
+		e.Reset(io.Discard)
+		gzipEncoders.Put(e)
+	}
+	w.encoder = nil
+	return err
+}
+
+// ETagMiddleware tags successful GET responses from the given routes with a
+// hash of their body, and answers a request whose If-None-Match already has
+// that tag with 304 Not Modified. It belongs before compression, so the tag
+// is of the bytes actually sent.
+func ETagMiddleware(routes ...string) gin.HandlerFunc {
+	tagged := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		tagged[route] = true
+	}
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet || !tagged[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		buffered := &bufferedWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = buffered
 		c.Next()
+		c.Writer = buffered.ResponseWriter
+
+		if buffered.status != http.StatusOK {
+			buffered.flush()
+			return
+		}
+		sum := sha256.Sum256(buffered.body.Bytes())
+		etag := `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+		c.Header("ETag", etag)
+		if !etagMatches(c.GetHeader("If-None-Match"), etag) {
+			buffered.flush()
+			return
+		}
+		header := c.Writer.Header()
+		header.Del("Content-Type")
+		header.Del("Content-Length")
+		header.Del("Content-Encoding")
+		c.Writer.WriteHeader(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+	}
+}
+
+// etagMatches reports whether an If-None-Match header lists etag, using the
+// weak comparison RFC 9110 specifies for it
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
 	}
+	return false
 }
 
-// gzipWriter wraps gin's ResponseWriter to provide gzip compression
-type gzipWriter struct {
+// bufferedWriter holds a response until the handler has finished
+type bufferedWriter struct {
 	gin.ResponseWriter
-	Writer io.Writer
+	body   bytes.Buffer
+	status int
 }
 
-func (g *gzipWriter) Write(data []byte) (int, error) {
-	return g.Writer.Write(data)
+func (w *bufferedWriter) WriteHeader(code int) { w.status = code }
+func (w *bufferedWriter) WriteHeaderNow()      {}
+func (w *bufferedWriter) Flush()               {}
+func (w *bufferedWriter) Status() int          { return w.status }
+func (w *bufferedWriter) Size() int            { return w.body.Len() }
+func (w *bufferedWriter) Written() bool        { return w.body.Len() > 0 }
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+// flush sends the held response
+func (w *bufferedWriter) flush() {
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.body.Len() == 0 {
+		w.ResponseWriter.WriteHeaderNow()
+		return
+	}
+	_, _ = w.ResponseWriter.Write(w.body.Bytes())
+}
+
+// APIPrefix is where the current API version is mounted. Within a version,
+// changes are additive only; anything that would break a client goes in a
+// new version.
+const APIPrefix = "/api/v1"
+
+// Unversioned routes such as /api/sessions still reach v1, but are
+// deprecated as of UnversionedDeprecated and go away after UnversionedSunset
+var (
+	UnversionedDeprecated = time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC)
+	UnversionedSunset     = time.Date(2027, time.April, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// versionSegment matches a version path segment, such as v1
+var versionSegment = regexp.MustCompile(`^v[0-9]+$`)
+
+// UnversionedAliases serves requests to /api/... without a version as the
+// same request to /api/v1/..., marking the response with Deprecation,
+// Sunset and a successor-version Link to the versioned path
+func UnversionedAliases(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rest, ok := strings.CutPrefix(r.URL.Path, "/api/")
+		if !ok || versionSegment.MatchString(strings.SplitN(rest, "/", 2)[0]) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		versioned := APIPrefix + "/" + rest
+		w.Header().Set("Deprecation", fmt.Sprintf("@%d", UnversionedDeprecated.Unix()))
+		w.Header().Set("Sunset", UnversionedSunset.Format(http.TimeFormat))
+		w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", versioned))
+
+		r = r.Clone(r.Context())
+		r.URL.Path = versioned
+		if r.URL.RawPath != "" {
+			r.URL.RawPath = APIPrefix + "/" + strings.TrimPrefix(r.URL.RawPath, "/api/")
+		}
+		next.ServeHTTP(w, r)
+	})
 }
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"bytes"
 	"compress/gzip"
 	"io"
 	"net/http/httptest"
@@ -8,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/zstd"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -103,6 +105,91 @@ func TestCompressionMiddleware(t *testing.T) {
 		assert.Equal(t, "Hello World!", w.Body.String())
 	})
 
+	t.Run("prefers zstd when accepted", func(t *testing.T) {
+		router := gin.New()
+		router.Use(CompressionMiddleware())
+		router.GET("/test", func(c *gin.Context) {
+			c.String(200, strings.Repeat("Hello World! ", 1000))
+		})
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("Accept-Encoding", "gzip, zstd")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, "zstd", w.Header().Get("Content-Encoding"))
+		decoder, err := zstd.NewReader(w.Body)
+		require.NoError(t, err)
+		defer decoder.Close()
+		decompressed, err := io.ReadAll(decoder)
+		require.NoError(t, err)
+		assert.Equal(t, strings.Repeat("Hello World! ", 1000), string(decompressed))
+
+		// q=0 refuses an encoding
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("Accept-Encoding", "zstd;q=0, gzip")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	})
+
+	t.Run("leaves empty and already encoded responses alone", func(t *testing.T) {
+		router := gin.New()
+		router.Use(CompressionMiddleware())
+		router.GET("/empty", func(c *gin.Context) {
+			c.Status(204)
+		})
+		router.GET("/encoded", func(c *gin.Context) {
+			c.Header("Content-Encoding", "br")
+			c.String(200, "already compressed")
+		})
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/empty", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, 204, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Zero(t, w.Body.Len())
+
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest("GET", "/encoded", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, "br", w.Header().Get("Content-Encoding"))
+		assert.Equal(t, "already compressed", w.Body.String())
+	})
+
+	t.Run("flushes streamed bodies as they're written", func(t *testing.T) {
+		router := gin.New()
+		router.Use(CompressionMiddleware())
+		w := httptest.NewRecorder()
+		var sentByFlush string
+		router.GET("/stream", func(c *gin.Context) {
+			c.Status(200)
+			_, _ = c.Writer.WriteString("first chunk")
+			c.Writer.Flush()
+			reader, err := gzip.NewReader(bytes.NewReader(w.Body.Bytes()))
+			require.NoError(t, err)
+			chunk := make([]byte, len("first chunk"))
+			_, err = io.ReadFull(reader, chunk)
+			require.NoError(t, err)
+			sentByFlush = string(chunk)
+			_, _ = c.Writer.WriteString(", second chunk")
+		})
+
+		req := httptest.NewRequest("GET", "/stream", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, "first chunk", sentByFlush)
+		reader, err := gzip.NewReader(w.Body)
+		require.NoError(t, err)
+		decompressed, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, "first chunk, second chunk", string(decompressed))
+	})
+
 	t.Run("skips compression for SSE endpoints", func(t *testing.T) {
 		router := gin.New()
 		router.Use(CompressionMiddleware())
@@ -121,3 +208,96 @@ func TestCompressionMiddleware(t *testing.T) {
 		assert.Equal(t, "data: test\n\n", w.Body.String())
 	})
 }
+
+func TestUnversionedAliases(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/v1/sessions/:id", func(c *gin.Context) {
+		c.String(200, "session "+c.Param("id"))
+	})
+	handler := UnversionedAliases(router)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/api/sessions/s1?x=1", nil))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "session s1", w.Body.String())
+	assert.Equal(t, "@1792108800", w.Header().Get("Deprecation"))
+	assert.Equal(t, "Thu, 01 Apr 2027 00:00:00 GMT", w.Header().Get("Sunset"))
+	assert.Equal(t, `</api/v1/sessions/s1>; rel="successor-version"`, w.Header().Get("Link"))
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/sessions/s1", nil))
+	assert.Equal(t, 200, w.Code)
+	assert.Empty(t, w.Header().Get("Deprecation"), "versioned routes aren't deprecated")
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/api/v2/sessions/s1", nil))
+	assert.Equal(t, 404, w.Code, "other versions aren't rewritten")
+}
+
+func TestETagMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	body := `{"status":"clean"}`
+	router := gin.New()
+	router.Use(ETagMiddleware("/status/:id"))
+	router.Use(CompressionMiddleware())
+	router.GET("/status/:id", func(c *gin.Context) {
+		if c.Param("id") == "missing" {
+			c.JSON(404, gin.H{"error": "not found"})
+			return
+		}
+		c.String(200, body)
+	})
+	router.GET("/other", func(c *gin.Context) {
+		c.String(200, "ok")
+	})
+
+	get := func(path, ifNoneMatch string, gzipped bool) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", path, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		if gzipped {
+			req.Header.Set("Accept-Encoding", "gzip")
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	first := get("/status/a", "", false)
+	require.Equal(t, 200, first.Code)
+	assert.Equal(t, body, first.Body.String())
+	etag := first.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	unchanged := get("/status/a", etag, false)
+	assert.Equal(t, 304, unchanged.Code)
+	assert.Empty(t, unchanged.Body.String())
+	assert.Equal(t, etag, unchanged.Header().Get("ETag"))
+
+	// A weak or listed tag still matches
+	assert.Equal(t, 304, get("/status/a", `"other", W/`+etag, false).Code)
+
+	body = `{"status":"dirty"}`
+	changed := get("/status/a", etag, false)
+	assert.Equal(t, 200, changed.Code)
+	assert.Equal(t, body, changed.Body.String())
+	assert.NotEqual(t, etag, changed.Header().Get("ETag"))
+
+	// Compressed responses are tagged as sent
+	compressed := get("/status/a", "", true)
+	require.Equal(t, 200, compressed.Code)
+	assert.Equal(t, "gzip", compressed.Header().Get("Content-Encoding"))
+	notModified := get("/status/a", compressed.Header().Get("ETag"), true)
+	assert.Equal(t, 304, notModified.Code)
+	assert.Empty(t, notModified.Header().Get("Content-Encoding"))
+
+	missing := get("/status/missing", "", false)
+	assert.Equal(t, 404, missing.Code)
+	assert.Empty(t, missing.Header().Get("ETag"))
+	assert.Contains(t, missing.Body.String(), "not found")
+
+	assert.Empty(t, get("/other", "", false).Header().Get("ETag"))
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// ModerationHandler serves what the content safety filter blocked, for
+// admins to review
+type ModerationHandler struct {
+	store store.ModerationStore
+}
+
+// NewModerationHandler creates a new content safety review handler
+func NewModerationHandler(blocks store.ModerationStore) *ModerationHandler {
+	return &ModerationHandler{store: blocks}
+}
+
+type reviewModerationBlockRequest struct {
+	Reviewer string `json:"reviewer"`
+	Note     string `json:"note"`
+}
+
+// HandleListBlocks returns blocked text oldest first; ?unreviewed=true
+// leaves out blocks already reviewed
+func (h *ModerationHandler) HandleListBlocks(c *gin.Context) {
+	unreviewed := false
+	if value := c.Query("unreviewed"); value != "" {
+		var err error
+		if unreviewed, err = strconv.ParseBool(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unreviewed must be true or false"})
+			return
+		}
+	}
+	blocks, err := h.store.ListModerationBlocks(c.Request.Context(), unreviewed)
+	if err != nil {
+		slog.Error("failed to list content safety blocks", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list content safety blocks"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": blocks})
+}
+
+// HandleReviewBlock marks a block reviewed
+func (h *ModerationHandler) HandleReviewBlock(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid block ID"})
+		return
+	}
+	var req reviewModerationBlockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	block, err := h.store.ReviewModerationBlock(c.Request.Context(), id, strings.TrimSpace(req.Reviewer), strings.TrimSpace(req.Note))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Block not found"})
+			return
+		}
+		slog.Error("failed to review content safety block", "id", id, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to review block"})
+		return
+	}
+	c.JSON(http.StatusOK, block)
+}
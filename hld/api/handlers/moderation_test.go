@@ -0,0 +1,70 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/api/handlers"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/moderation"
+	"github.com/humanlayer/humanlayer/hld/prompts"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModerationBlocksReview(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	filter := moderation.New(config.ContentSafetyConfig{
+		ContentSafetyPolicy: config.ContentSafetyPolicy{Enabled: true, Patterns: []string{"idiot"}},
+	}, nil, prompts.Default(), s, s)
+	for _, text := range []string{"no, idiot", "idiot proof"} {
+		err := filter.Check(ctx, moderation.Content{Kind: store.ModerationApprovalComment, SessionID: "sess-1", Text: text})
+		require.ErrorIs(t, err, moderation.ErrBlocked)
+	}
+
+	h := handlers.NewModerationHandler(s)
+	r := gin.New()
+	r.GET("/api/v1/moderation/blocks", h.HandleListBlocks)
+	r.POST("/api/v1/moderation/blocks/:id/review", h.HandleReviewBlock)
+	list := func(query string) []store.ModerationBlock {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/moderation/blocks"+query, nil))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp struct {
+			Data []store.ModerationBlock `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp.Data
+	}
+	review := func(id, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/moderation/blocks/"+id+"/review", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	blocks := list("?unreviewed=true")
+	require.Len(t, blocks, 2)
+	assert.Equal(t, "no, idiot", blocks[0].Content)
+
+	w := review("2", `{"reviewer":"ada","note":"false positive"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var reviewed store.ModerationBlock
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &reviewed))
+	assert.Equal(t, "false positive", reviewed.ReviewNote)
+	require.NotNil(t, reviewed.ReviewedAt)
+
+	assert.Len(t, list("?unreviewed=true"), 1)
+	assert.Len(t, list(""), 2)
+	assert.Equal(t, http.StatusNotFound, review("9", `{}`).Code)
+	assert.Equal(t, http.StatusBadRequest, review("x", `{}`).Code)
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/approval"
+	"github.com/humanlayer/humanlayer/hld/approval/policy"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// PolicyHandler exposes the approval policies, evaluates draft policies
+// against sample inputs and reports on shadow policy decisions
+type PolicyHandler struct {
+	active  *policy.Policy
+	shadow  *policy.Policy
+	store   store.ConversationStore
+	shadows store.ShadowDecisionStore
+}
+
+// NewPolicyHandler creates a new policy handler. active and shadow may be nil
+// when not configured.
+func NewPolicyHandler(active, shadow *policy.Policy, conversationStore store.ConversationStore, shadows store.ShadowDecisionStore) *PolicyHandler {
+	return &PolicyHandler{active: active, shadow: shadow, store: conversationStore, shadows: shadows}
+}
+
+// TestPolicyRequest represents a request to evaluate a policy
+type TestPolicyRequest struct {
+	// Rules to evaluate; defaults to the active policy
+	Rules  []policy.Rule  `json:"rules,omitempty"`
+	Inputs []policy.Input `json:"inputs"`
+}
+
+// PolicyTestResult is the decision for one sample input
+type PolicyTestResult struct {
+	Input    policy.Input    `json:"input"`
+	Decision policy.Decision `json:"decision"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// HandleGetPolicy returns the rules of the active and shadow policies
+func (h *PolicyHandler) HandleGetPolicy(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":      h.active != nil,
+		"rules":        policyRules(h.active),
+		"shadow":       h.shadow != nil,
+		"shadow_rules": policyRules(h.shadow),
+	})
+}
+
+// HandleShadowReport compares shadow policy decisions with human decisions,
+// optionally limited to one session with ?session_id=
+func (h *PolicyHandler) HandleShadowReport(c *gin.Context) {
+	report, err := approval.BuildShadowReport(c.Request.Context(), h.store, h.shadows, c.Query("session_id"))
+	if err != nil {
+		slog.Error("failed to build shadow policy report", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build shadow policy report"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"enabled": h.shadow != nil, "report": report})
+}
+
+func policyRules(p *policy.Policy) []policy.Rule {
+	if p == nil {
+		return []policy.Rule{}
+	}
+	return p.Rules()
+}
+
+// HandleTestPolicy evaluates rules against sample inputs without affecting live approvals
+func (h *PolicyHandler) HandleTestPolicy(c *gin.Context) {
+	var req TestPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if len(req.Inputs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one input is required"})
+		return
+	}
+
+	p := h.active
+	if req.Rules != nil {
+		compiled, err := policy.Compile(req.Rules)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		p = compiled
+	}
+	if p == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No rules given and no approval policy is configured"})
+		return
+	}
+
+	results := make([]PolicyTestResult, 0, len(req.Inputs))
+	for _, input := range req.Inputs {
+		decision, err := p.Evaluate(input)
+		result := PolicyTestResult{Input: input, Decision: decision}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/promptlog"
+)
+
+// PromptLogHandler serves the model calls recorded for debugging the
+// daemon's suggestions
+type PromptLogHandler struct {
+	log *promptlog.Log
+}
+
+// NewPromptLogHandler creates a handler for log, which is nil when the
+// prompt log is off
+func NewPromptLogHandler(log *promptlog.Log) *PromptLogHandler {
+	return &PromptLogHandler{log: log}
+}
+
+// PromptLogResponse lists recorded model calls, newest first
+type PromptLogResponse struct {
+	Entries []promptlog.Entry `json:"entries"`
+}
+
+const promptLogDisabled = "The prompt log is not enabled; set prompt_log.enabled"
+
+// HandleListPromptLog lists recorded calls, filtered by ?task=, ?provider=,
+// ?model= and ?failed=true, and capped by ?limit=
+func (h *PromptLogHandler) HandleListPromptLog(c *gin.Context) {
+	if h.log == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": promptLogDisabled})
+		return
+	}
+	filter := promptlog.Filter{
+		Task:     c.Query("task"),
+		Provider: c.Query("provider"),
+		Model:    c.Query("model"),
+	}
+	if value := c.Query("failed"); value != "" {
+		failed, err := strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "failed must be true or false"})
+			return
+		}
+		filter.Failed = failed
+	}
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a non-negative integer"})
+			return
+		}
+		filter.Limit = n
+	}
+	c.JSON(http.StatusOK, PromptLogResponse{Entries: h.log.List(filter)})
+}
+
+// HandleGetPromptLogEntry returns one recorded call
+func (h *PromptLogHandler) HandleGetPromptLogEntry(c *gin.Context) {
+	if h.log == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": promptLogDisabled})
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id must be a positive integer"})
+		return
+	}
+	entry, ok := h.log.Get(id)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Entry not found; it may have been dropped from the log"})
+		return
+	}
+	c.JSON(http.StatusOK, entry)
+}
+
+// HandleClearPromptLog drops every recorded call
+func (h *PromptLogHandler) HandleClearPromptLog(c *gin.Context) {
+	if h.log == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": promptLogDisabled})
+		return
+	}
+	h.log.Clear()
+	c.Status(http.StatusNoContent)
+}
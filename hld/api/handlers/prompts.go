@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/prompts"
+)
+
+// PromptHandler lists the prompt templates in use
+type PromptHandler struct {
+	templates *prompts.Set
+}
+
+// NewPromptHandler creates a new prompt handler
+func NewPromptHandler(templates *prompts.Set) *PromptHandler {
+	return &PromptHandler{templates: templates}
+}
+
+// HandleListPrompts returns each template's text, default text and whether it is overridden
+func (h *PromptHandler) HandleListPrompts(c *gin.Context) {
+	infos, err := h.templates.List()
+	if err != nil {
+		slog.Error("failed to list prompt templates", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list prompt templates"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": infos})
+}
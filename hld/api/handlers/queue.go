@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/session"
+)
+
+// QueueHandler reports and edits the session launch queue
+type QueueHandler struct {
+	sessionManager session.SessionManager
+}
+
+// NewQueueHandler creates a new launch queue handler
+func NewQueueHandler(sessionManager session.SessionManager) *QueueHandler {
+	return &QueueHandler{sessionManager: sessionManager}
+}
+
+// HandleGetQueue returns the concurrency limit, active slots and queued
+// sessions with their positions
+func (h *QueueHandler) HandleGetQueue(c *gin.Context) {
+	c.JSON(http.StatusOK, h.sessionManager.QueueStatus())
+}
+
+// HandleCancelQueued removes a session from the queue before it starts
+func (h *QueueHandler) HandleCancelQueued(c *gin.Context) {
+	sessionID := c.Param("id")
+	if err := h.sessionManager.CancelQueued(c.Request.Context(), sessionID); err != nil {
+		if errors.Is(err, session.ErrNotQueued) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session is not queued"})
+			return
+		}
+		slog.Error("failed to cancel queued session", "session_id", sessionID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel queued session"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
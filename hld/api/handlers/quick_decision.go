@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/approval"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// QuickDecisionHandler serves decisions made with an undo window, for
+// keyboard-driven triage
+type QuickDecisionHandler struct {
+	undoable      *approval.Undoable
+	justification *Justification
+}
+
+// NewQuickDecisionHandler creates a new quick decision handler
+func NewQuickDecisionHandler(undoable *approval.Undoable) *QuickDecisionHandler {
+	return &QuickDecisionHandler{undoable: undoable}
+}
+
+// SetJustification requires a comment for approving high-risk tool calls
+func (h *QuickDecisionHandler) SetJustification(j *Justification) {
+	h.justification = j
+}
+
+type quickDecisionRequest struct {
+	Decision string `json:"decision" binding:"required"`
+	Comment  string `json:"comment"`
+}
+
+type quickDecisionResponse struct {
+	*approval.PendingDecision
+	UndoSeconds int `json:"undo_seconds"`
+}
+
+// HandleDecide records a decision that is committed once the undo window
+// passes unless it is undone first
+func (h *QuickDecisionHandler) HandleDecide(c *gin.Context) {
+	var req quickDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "decision is required"})
+		return
+	}
+	var approved bool
+	switch req.Decision {
+	case "approve":
+		approved = true
+	case "deny":
+		if req.Comment == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "comment is required when denying"})
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "decision must be approve or deny"})
+		return
+	}
+
+	var highRisk *justified
+	if approved {
+		var err error
+		if highRisk, err = h.justification.check(c.Request.Context(), c.Param("id"), req.Comment); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	// The justification is recorded only if the approval survives the undo window
+	var committed func(context.Context)
+	if highRisk != nil {
+		comment := req.Comment
+		committed = func(ctx context.Context) { h.justification.record(ctx, highRisk, comment) }
+	}
+	decision, err := h.undoable.DecideThen(c.Request.Context(), c.Param("id"), approved, req.Comment, committed)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, quickDecisionResponse{
+		PendingDecision: decision,
+		UndoSeconds:     int(h.undoable.Window().Seconds()),
+	})
+}
+
+// HandleUndo retracts a decision still in its undo window, leaving the
+// approval pending
+func (h *QuickDecisionHandler) HandleUndo(c *gin.Context) {
+	decision, err := h.undoable.Undo(c.Param("id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, decision)
+}
+
+func (h *QuickDecisionHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Approval not found"})
+	case errors.Is(err, store.ErrAlreadyDecided), errors.Is(err, approval.ErrDecisionPending), errors.Is(err, approval.ErrNothingToUndo):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		slog.Error("quick decision failed", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Quick decision failed"})
+	}
+}
@@ -0,0 +1,51 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/api/handlers"
+	"github.com/humanlayer/humanlayer/hld/approval"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuickDecision(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	fake := approval.NewFakeManager()
+	id, err := fake.CreateApproval(context.Background(), "run-1", "Bash", json.RawMessage(`{"command":"ls"}`))
+	require.NoError(t, err)
+	h := handlers.NewQuickDecisionHandler(approval.NewUndoable(fake, nil, time.Minute))
+	router := gin.New()
+	router.POST("/api/v1/approvals/:id/quick", h.HandleDecide)
+	router.POST("/api/v1/approvals/:id/undo", h.HandleUndo)
+
+	w := makeRequest(t, router, "POST", "/api/v1/approvals/"+id+"/quick", map[string]any{"decision": "deny"})
+	assert.Equal(t, http.StatusBadRequest, w.Code, "denials need a comment")
+	w = makeRequest(t, router, "POST", "/api/v1/approvals/missing/quick", map[string]any{"decision": "approve"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = makeRequest(t, router, "POST", "/api/v1/approvals/"+id+"/quick", map[string]any{"decision": "approve"})
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	var resp struct {
+		ApprovalID  string    `json:"approval_id"`
+		Approved    bool      `json:"approved"`
+		CommitsAt   time.Time `json:"commits_at"`
+		UndoSeconds int       `json:"undo_seconds"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.Equal(t, id, resp.ApprovalID)
+	assert.True(t, resp.Approved)
+	assert.Equal(t, 60, resp.UndoSeconds)
+
+	w = makeRequest(t, router, "POST", "/api/v1/approvals/"+id+"/quick", map[string]any{"decision": "approve"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = makeRequest(t, router, "POST", "/api/v1/approvals/"+id+"/undo", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = makeRequest(t, router, "POST", "/api/v1/approvals/"+id+"/undo", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// ResolutionsHandler serves approval resolutions that were refused: email
+// replies, hub decisions and text replies that were tampered with, expired
+// or replayed
+type ResolutionsHandler struct {
+	store store.ResolutionStore
+}
+
+// NewResolutionsHandler creates a new resolutions handler
+func NewResolutionsHandler(resolutions store.ResolutionStore) *ResolutionsHandler {
+	return &ResolutionsHandler{store: resolutions}
+}
+
+// HandleListRejected returns every refused resolution, or an approval's
+// with the approval_id query parameter, oldest first
+func (h *ResolutionsHandler) HandleListRejected(c *gin.Context) {
+	approvalID := c.Query("approval_id")
+	rejected, err := h.store.ListRejectedResolutions(c.Request.Context(), approvalID)
+	if err != nil {
+		slog.Error("failed to list rejected resolutions", "approval_id", approvalID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list rejected resolutions"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": rejected})
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/schemas"
+)
+
+// SchemaHandler serves the JSON Schemas of the payloads clients parse
+type SchemaHandler struct{}
+
+// NewSchemaHandler creates a new schema handler
+func NewSchemaHandler() *SchemaHandler {
+	return &SchemaHandler{}
+}
+
+// HandleListSchemas names the published schemas
+func (h *SchemaHandler) HandleListSchemas(c *gin.Context) {
+	names := make([]string, len(schemas.Published))
+	for i, p := range schemas.Published {
+		names[i] = p.Name
+	}
+	c.JSON(http.StatusOK, gin.H{"data": names})
+}
+
+// HandleGetSchema returns a schema by name, with or without its
+// .schema.json suffix
+func (h *SchemaHandler) HandleGetSchema(c *gin.Context) {
+	data, err := schemas.Get(strings.TrimSuffix(c.Param("name"), ".schema.json"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Schema not found"})
+		return
+	}
+	c.Data(http.StatusOK, "application/schema+json", data)
+}
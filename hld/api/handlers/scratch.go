@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"path"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/scratch"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// ScratchHandler serves each session's scratch space: files kept outside
+// its repository, such as plans and notes
+type ScratchHandler struct {
+	store store.ConversationStore
+	space *scratch.Space
+}
+
+// NewScratchHandler creates a new scratch space handler
+func NewScratchHandler(conversationStore store.ConversationStore, space *scratch.Space) *ScratchHandler {
+	return &ScratchHandler{store: conversationStore, space: space}
+}
+
+// sessionExists responds 404 and reports false for an unknown session
+func (h *ScratchHandler) sessionExists(c *gin.Context) bool {
+	if _, err := h.store.GetSession(c.Request.Context(), c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return false
+	}
+	return true
+}
+
+// HandleGetFile returns a file from the session's scratch space, or lists
+// a directory's entries
+func (h *ScratchHandler) HandleGetFile(c *gin.Context) {
+	if !h.sessionExists(c) {
+		return
+	}
+	sessionID, name := c.Param("id"), c.Param("path")
+	f, file, err := h.space.Open(sessionID, name)
+	if errors.Is(err, scratch.ErrIsDir) || (errors.Is(err, scratch.ErrNotFound) && path.Clean("/"+name) == "/") {
+		files, err := h.space.List(sessionID, name)
+		if err != nil {
+			h.respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"path": path.Clean("/" + name)[1:], "files": files})
+		return
+	}
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	defer func() { _ = f.Close() }()
+	// ServeContent handles ranges and conditional requests, and sniffs the type
+	http.ServeContent(c.Writer, c.Request, path.Base(file.Path), file.ModifiedAt, f)
+}
+
+// HandlePutFile writes the request body to a file in the session's scratch
+// space, creating its directories
+func (h *ScratchHandler) HandlePutFile(c *gin.Context) {
+	if !h.sessionExists(c) {
+		return
+	}
+	if c.Request.ContentLength > h.space.MaxFileSize() {
+		h.respondError(c, scratch.ErrTooLarge)
+		return
+	}
+	file, err := h.space.Write(c.Param("id"), c.Param("path"), c.Request.Body)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, file)
+}
+
+// HandleDeleteFile removes a file or empty directory from the session's
+// scratch space
+func (h *ScratchHandler) HandleDeleteFile(c *gin.Context) {
+	if !h.sessionExists(c) {
+		return
+	}
+	if err := h.space.Remove(c.Param("id"), c.Param("path")); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *ScratchHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, scratch.ErrInvalidPath):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid path"})
+	case errors.Is(err, scratch.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+	case errors.Is(err, scratch.ErrIsDir):
+		c.JSON(http.StatusConflict, gin.H{"error": "Path is a directory"})
+	case errors.Is(err, scratch.ErrTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, scratch.ErrQuota):
+		c.JSON(http.StatusInsufficientStorage, gin.H{"error": err.Error()})
+	default:
+		slog.Error("scratch file operation failed", "session_id", c.Param("id"), "path", c.Param("path"), "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Scratch file operation failed"})
+	}
+}
@@ -0,0 +1,61 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/api/handlers"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/scratch"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScratchFiles(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := store.NewInMemoryStore()
+	require.NoError(t, s.CreateSession(context.Background(), &store.Session{ID: "sess-1", RunID: "run-1", CreatedAt: time.Now()}))
+	h := handlers.NewScratchHandler(s, scratch.New(t.TempDir(), config.ScratchConfig{MaxFileSize: 64}))
+	router := gin.New()
+	router.GET("/api/v1/sessions/:id/files/*path", h.HandleGetFile)
+	router.PUT("/api/v1/sessions/:id/files/*path", h.HandlePutFile)
+	router.DELETE("/api/v1/sessions/:id/files/*path", h.HandleDeleteFile)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+
+	w := do("PUT", "/api/v1/sessions/sess-1/files/notes/plan.md", "# Plan\n")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = do("GET", "/api/v1/sessions/sess-1/files/notes/plan.md", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "# Plan\n", w.Body.String())
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/markdown")
+
+	w = do("GET", "/api/v1/sessions/sess-1/files/", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var listing struct {
+		Path  string         `json:"path"`
+		Files []scratch.File `json:"files"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&listing))
+	require.Len(t, listing.Files, 1)
+	assert.Equal(t, "notes", listing.Files[0].Path)
+
+	assert.Equal(t, http.StatusBadRequest, do("PUT", "/api/v1/sessions/sess-1/files/notes/%2E%2E/%2E%2E/x", "x").Code)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, do("PUT", "/api/v1/sessions/sess-1/files/big", strings.Repeat("x", 65)).Code)
+	assert.Equal(t, http.StatusNotFound, do("GET", "/api/v1/sessions/sess-2/files/", "").Code)
+	assert.Equal(t, http.StatusNotFound, do("GET", "/api/v1/sessions/sess-1/files/missing", "").Code)
+
+	assert.Equal(t, http.StatusNoContent, do("DELETE", "/api/v1/sessions/sess-1/files/notes/plan.md", "").Code)
+	assert.Equal(t, http.StatusNotFound, do("GET", "/api/v1/sessions/sess-1/files/notes/plan.md", "").Code)
+}
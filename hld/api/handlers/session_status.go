@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// BatchStatusAliasSuffix is what follows "status" in the alias route
+// POST /sessions/status:batch
+const BatchStatusAliasSuffix = ":batch"
+
+// maxBatchStatusSessions caps the sessions asked about in one request
+const maxBatchStatusSessions = 500
+
+// SessionStatusHandler answers dashboards polling many sessions at once
+type SessionStatusHandler struct {
+	store store.ConversationStore
+}
+
+// NewSessionStatusHandler creates a new session status handler
+func NewSessionStatusHandler(conversationStore store.ConversationStore) *SessionStatusHandler {
+	return &SessionStatusHandler{store: conversationStore}
+}
+
+// BatchStatusRequest lists the sessions to report on
+type BatchStatusRequest struct {
+	SessionIDs []string `json:"session_ids" binding:"required"`
+}
+
+// SessionStatusSummary is the compact state of one session
+type SessionStatusSummary struct {
+	ID               string    `json:"id"`
+	Status           string    `json:"status"`
+	PendingApprovals int       `json:"pending_approvals"`
+	LastActivityAt   time.Time `json:"last_activity_at"`
+	// LastEventAt is when the conversation last changed; unset before the
+	// first event
+	LastEventAt *time.Time `json:"last_event_at,omitempty"`
+}
+
+// BatchStatusResponse reports sessions in the order asked for
+type BatchStatusResponse struct {
+	Sessions []SessionStatusSummary `json:"sessions"`
+	// Missing lists the IDs that aren't sessions
+	Missing []string `json:"missing"`
+}
+
+// HandleBatchStatusAlias serves POST /sessions/status:batch. gin can't route
+// an escaped colon, so the alias is registered as /sessions/status:suffix and
+// answers only when the suffix is :batch. Session IDs are UUIDs, so none can
+// start with "status" and be shadowed by it.
+func (h *SessionStatusHandler) HandleBatchStatusAlias(c *gin.Context) {
+	if c.Param("suffix") != BatchStatusAliasSuffix {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		return
+	}
+	h.HandleBatchStatus(c)
+}
+
+// HandleBatchStatus reports the status, pending approval count and last
+// event time of each session in the request
+func (h *SessionStatusHandler) HandleBatchStatus(c *gin.Context) {
+	var req BatchStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body; expected session_ids"})
+		return
+	}
+	if len(req.SessionIDs) > maxBatchStatusSessions {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("At most %d sessions per request", maxBatchStatusSessions)})
+		return
+	}
+
+	ctx := c.Request.Context()
+	response := BatchStatusResponse{Sessions: []SessionStatusSummary{}, Missing: []string{}}
+	seen := make(map[string]bool, len(req.SessionIDs))
+	var found []string
+	for _, id := range req.SessionIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		session, err := h.store.GetSession(ctx, id)
+		if err != nil || session == nil {
+			response.Missing = append(response.Missing, id)
+			continue
+		}
+		response.Sessions = append(response.Sessions, SessionStatusSummary{
+			ID:             session.ID,
+			Status:         session.Status,
+			LastActivityAt: session.LastActivityAt,
+		})
+		found = append(found, id)
+	}
+
+	lastEvents, err := h.store.GetLastEventTimes(ctx, found)
+	if err != nil {
+		slog.Error("failed to get last event times", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get last event times"})
+		return
+	}
+	pending, err := h.store.CountPendingApprovals(ctx, found)
+	if err != nil {
+		slog.Error("failed to count pending approvals", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count pending approvals"})
+		return
+	}
+	for i := range response.Sessions {
+		summary := &response.Sessions[i]
+		summary.PendingApprovals = pending[summary.ID]
+		if at, ok := lastEvents[summary.ID]; ok {
+			summary.LastEventAt = &at
+		}
+	}
+	c.JSON(http.StatusOK, response)
+}
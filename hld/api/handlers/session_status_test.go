@@ -0,0 +1,69 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/api/handlers"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	for _, id := range []string{"s1", "s2"} {
+		require.NoError(t, s.CreateSession(ctx, &store.Session{
+			ID: id, RunID: "r-" + id, ClaudeSessionID: "c-" + id, Status: store.SessionStatusRunning, CreatedAt: time.Now(),
+		}))
+	}
+	require.NoError(t, s.AddConversationEvent(ctx, &store.ConversationEvent{SessionID: "s1", ClaudeSessionID: "c-s1", EventType: store.EventTypeMessage}))
+	for i, status := range []store.ApprovalStatus{store.ApprovalStatusLocalPending, store.ApprovalStatusLocalPending, store.ApprovalStatusLocalApproved} {
+		require.NoError(t, s.CreateApproval(ctx, &store.Approval{
+			ID: fmt.Sprintf("a%d", i), RunID: "r-s1", SessionID: "s1", ToolName: "Bash", Status: status, CreatedAt: time.Now(),
+		}))
+	}
+
+	h := handlers.NewSessionStatusHandler(s)
+	router := gin.New()
+	router.POST("/api/v1/sessions/status/batch", h.HandleBatchStatus)
+	router.POST("/api/v1/sessions/status:suffix", h.HandleBatchStatusAlias)
+	router.POST("/api/v1/sessions/:id/continue", func(c *gin.Context) { c.String(http.StatusAccepted, c.Param("id")) })
+
+	w := makeRequest(t, router, "POST", "/api/v1/sessions/status/batch", handlers.BatchStatusRequest{
+		SessionIDs: []string{"s2", "nope", "s1", "s2"},
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response handlers.BatchStatusResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	require.Len(t, response.Sessions, 2, "duplicates are reported once")
+	assert.Equal(t, []string{"nope"}, response.Missing)
+
+	s2, s1 := response.Sessions[0], response.Sessions[1]
+	assert.Equal(t, "s2", s2.ID)
+	assert.Equal(t, store.SessionStatusRunning, s2.Status)
+	assert.Zero(t, s2.PendingApprovals)
+	assert.Nil(t, s2.LastEventAt)
+	assert.Equal(t, "s1", s1.ID)
+	assert.Equal(t, 2, s1.PendingApprovals)
+	require.NotNil(t, s1.LastEventAt)
+
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/status:batch", handlers.BatchStatusRequest{SessionIDs: []string{"s1"}})
+	require.Equal(t, http.StatusOK, w.Code, "the colon form is an alias")
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, 2, response.Sessions[0].PendingApprovals)
+
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/status:other", handlers.BatchStatusRequest{SessionIDs: []string{"s1"}})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/s1/continue", nil)
+	assert.Equal(t, http.StatusAccepted, w.Code, "other POST /sessions/:id routes still resolve")
+	w = makeRequest(t, router, "POST", "/api/v1/sessions/status/batch", map[string]any{})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
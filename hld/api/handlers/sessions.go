@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -22,6 +23,7 @@ import (
 	"github.com/humanlayer/humanlayer/hld/internal/version"
 	"github.com/humanlayer/humanlayer/hld/session"
 	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/humanlayer/humanlayer/hld/worktree"
 	"github.com/sahilm/fuzzy"
 	"gopkg.in/yaml.v3"
 )
@@ -34,6 +36,19 @@ type SessionHandlers struct {
 	version         string
 	config          *config.Config
 	sessionManager  session.SessionManager // Add reference to session manager for Claude status checks
+	processes       store.ProcessStore     // Process records for session details; nil if none are kept
+	worktrees       *worktree.Manager      // Worktrees for sessions launched with use_worktree; nil if unavailable
+}
+
+// SetProcessStore adds the Claude process each session ran in to its details
+func (h *SessionHandlers) SetProcessStore(processes store.ProcessStore) {
+	h.processes = processes
+}
+
+// SetWorktreeManager lets sessions launch in their own git worktree, removed
+// once they're archived
+func (h *SessionHandlers) SetWorktreeManager(m *worktree.Manager) {
+	h.worktrees = m
 }
 
 // CommandFrontmatter represents the YAML frontmatter in command files
@@ -236,6 +251,35 @@ func (h *SessionHandlers) CreateSession(ctx context.Context, req api.CreateSessi
 		}
 	}
 
+	if req.Body.AutoDenyAll != nil {
+		config.AutoDenyAll = *req.Body.AutoDenyAll
+	}
+	if req.Body.AutoDenyTools != nil {
+		config.AutoDenyTools = *req.Body.AutoDenyTools
+	}
+	if req.Body.Budget != nil {
+		budget := h.mapper.BudgetFromAPI(req.Body.Budget)
+		config.Budget = &budget
+	}
+	if req.Body.Template != nil {
+		config.Template = *req.Body.Template
+	}
+	if req.Body.Container != nil {
+		config.Container = *req.Body.Container
+	}
+	if req.Body.Devcontainer != nil {
+		config.Devcontainer = *req.Body.Devcontainer
+	}
+	if req.Body.Priority != nil {
+		config.Priority = *req.Body.Priority
+	}
+	if req.Body.IncludeDecisions != nil {
+		config.IncludeDecisions = *req.Body.IncludeDecisions
+	}
+	if req.Body.ContextPackId != nil {
+		config.ContextPackID = *req.Body.ContextPackId
+	}
+
 	// Parse model if provided
 	if req.Body.Model != nil && *req.Body.Model != "" {
 		switch *req.Body.Model {
@@ -255,11 +299,43 @@ func (h *SessionHandlers) CreateSession(ctx context.Context, req api.CreateSessi
 		config.CreateDirectoryIfNotExists = true
 	}
 
+	// Give the session a worktree of its own, launching it in the worktree's
+	// counterpart of the working directory
+	var checkout *worktree.Checkout
+	if req.Body.UseWorktree != nil && *req.Body.UseWorktree {
+		if h.worktrees == nil {
+			return createSessionBadRequest("worktrees are not available"), nil
+		}
+		if config.WorkingDir == "" {
+			return createSessionBadRequest("use_worktree requires a working_dir"), nil
+		}
+		var err error
+		checkout, err = h.worktrees.Create(ctx, expandTilde(config.WorkingDir))
+		if errors.Is(err, worktree.ErrNotRepository) {
+			return createSessionBadRequest(err.Error()), nil
+		}
+		if err != nil {
+			slog.Error("Failed to create session worktree", "error", err, "working_dir", config.WorkingDir, "operation", "CreateSession")
+			return api.CreateSession500JSONResponse{
+				InternalErrorJSONResponse: api.InternalErrorJSONResponse{
+					Error: api.ErrorDetail{
+						Code:    "HLD-1001",
+						Message: err.Error(),
+					},
+				},
+			}, nil
+		}
+		config.WorkingDir = checkout.WorkingDir
+	}
+
 	// Check for draft flag in request
 	isDraft := req.Body.Draft != nil && *req.Body.Draft
 
 	sess, err := h.manager.LaunchSession(ctx, config, isDraft)
 	if err != nil {
+		if checkout != nil {
+			h.worktrees.Discard(ctx, checkout)
+		}
 		// Check if it's a directory not found error
 		var dirNotFound *session.DirectoryNotFoundError
 		if errors.As(err, &dirNotFound) {
@@ -271,6 +347,10 @@ func (h *SessionHandlers) CreateSession(ctx context.Context, req api.CreateSessi
 				RequiresCreation: true,
 			}, nil
 		}
+		if errors.Is(err, session.ErrNoContainerImage) || errors.Is(err, session.ErrNoDevcontainer) ||
+			errors.Is(err, session.ErrDevcontainerDraft) {
+			return createSessionBadRequest(err.Error()), nil
+		}
 		slog.Error("Failed to launch session",
 			"error", fmt.Sprintf("%v", err),
 			"query", config.Query,
@@ -287,12 +367,48 @@ func (h *SessionHandlers) CreateSession(ctx context.Context, req api.CreateSessi
 		}, nil
 	}
 
+	if checkout != nil {
+		if err := h.worktrees.Attach(ctx, sess.ID, checkout); err != nil {
+			slog.Error("Failed to record session worktree; it won't be removed on archive",
+				"error", err, "session_id", sess.ID, "path", checkout.Path)
+		}
+	}
+
 	resp := api.CreateSessionResponse{}
 	resp.Data.SessionId = sess.ID
 	resp.Data.RunId = sess.RunID
 	return api.CreateSession201JSONResponse(resp), nil
 }
 
+func createSessionBadRequest(message string) api.CreateSession400JSONResponse {
+	return api.CreateSession400JSONResponse{
+		BadRequestJSONResponse: api.BadRequestJSONResponse{
+			Error: api.ErrorDetail{
+				Code:    "HLD-3001",
+				Message: message,
+			},
+		},
+	}
+}
+
+// releaseWorktree removes an archived session's worktree once no agent runs
+// in it. The archive stands if it can't be removed yet; the worktree-cleanup
+// task tries again.
+func (h *SessionHandlers) releaseWorktree(ctx context.Context, sessionID string) {
+	if h.worktrees == nil {
+		return
+	}
+	switch err := h.worktrees.Release(ctx, sessionID); {
+	case err == nil:
+	case errors.Is(err, worktree.ErrInUse):
+		slog.Info("leaving archived session's worktree to the cleanup task until no session runs in it", "session_id", sessionID)
+	case errors.Is(err, worktree.ErrUncommittedChanges):
+		slog.Info("keeping archived session's worktree until its changes are committed or discarded", "session_id", sessionID)
+	default:
+		slog.Warn("failed to remove archived session's worktree", "session_id", sessionID, "error", err)
+	}
+}
+
 // ListSessions implements GET /sessions
 func (h *SessionHandlers) ListSessions(ctx context.Context, req api.ListSessionsRequestObject) (api.ListSessionsResponseObject, error) {
 	// NEW: leavesOnly parameter (renamed from leafOnly, default true)
@@ -308,6 +424,34 @@ func (h *SessionHandlers) ListSessions(ctx context.Context, req api.ListSessions
 	}
 	// When filter is nil, return ALL sessions (no filtering)
 
+	sortBy, order := api.Updated, api.Desc
+	if req.Params.Sort != nil {
+		sortBy = *req.Params.Sort
+	}
+	if req.Params.Order != nil {
+		order = *req.Params.Order
+	}
+	if sortBy != api.Created && sortBy != api.Updated && sortBy != api.Cost {
+		return listSessionsBadRequest("sort must be created, updated or cost"), nil
+	}
+	if order != api.Asc && order != api.Desc {
+		return listSessionsBadRequest("order must be asc or desc"), nil
+	}
+	var limit int
+	if req.Params.Limit != nil {
+		limit = *req.Params.Limit
+		if limit < 1 || limit > maxSessionsPageSize {
+			return listSessionsBadRequest(fmt.Sprintf("limit must be between 1 and %d", maxSessionsPageSize)), nil
+		}
+	}
+	var fields []string
+	if req.Params.Fields != nil {
+		var err error
+		if fields, err = parseSessionFields(*req.Params.Fields); err != nil {
+			return listSessionsBadRequest(err.Error()), nil
+		}
+	}
+
 	// Get all sessions from manager
 	sessionInfos := h.manager.ListSessions()
 
@@ -355,14 +499,24 @@ func (h *SessionHandlers) ListSessions(ctx context.Context, req api.ListSessions
 		if !shouldIncludeSession(s, filterType) {
 			continue
 		}
+		if req.Params.Status != nil && !slices.Contains(*req.Params.Status, api.SessionStatus(s.Status)) {
+			continue
+		}
+		if req.Params.Label != nil && s.Template != *req.Params.Label {
+			continue
+		}
 
 		filtered = append(filtered, s)
 	}
 
-	// Sort by last activity (newest first)
-	sort.Slice(filtered, func(i, j int) bool {
-		return filtered[i].LastActivityAt.After(filtered[j].LastActivityAt)
-	})
+	var cursor string
+	if req.Params.Cursor != nil {
+		cursor = *req.Params.Cursor
+	}
+	filtered, nextCursor, err := pageSessions(filtered, sortBy, order, limit, cursor)
+	if err != nil {
+		return listSessionsBadRequest(err.Error()), nil
+	}
 
 	// Convert to API sessions
 	sessions := make([]api.Session, len(filtered))
@@ -393,6 +547,7 @@ func (h *SessionHandlers) ListSessions(ctx context.Context, req api.ListSessions
 			ProxyBaseURL:                        info.ProxyBaseURL,
 			ProxyModelOverride:                  info.ProxyModelOverride,
 			ProxyAPIKey:                         info.ProxyAPIKey,
+			Template:                            info.Template,
 		}
 
 		// Copy result data if available
@@ -416,6 +571,12 @@ func (h *SessionHandlers) ListSessions(ctx context.Context, req api.ListSessions
 			Draft:    &draftCount,
 		},
 	}
+	if nextCursor != "" {
+		resp.NextCursor = &nextCursor
+	}
+	if fields != nil {
+		return selectedSessionsResponse{response: resp, fields: fields}, nil
+	}
 	return api.ListSessions200JSONResponse(resp), nil
 }
 
@@ -469,6 +630,19 @@ func (h *SessionHandlers) GetSession(ctx context.Context, req api.GetSessionRequ
 	resp := api.SessionResponse{
 		Data: h.mapper.SessionToAPI(*session),
 	}
+	if h.processes != nil {
+		if process, err := h.processes.GetSessionProcess(ctx, session.ID); err == nil {
+			stats, running := h.manager.ProcessStats(session.ID)
+			live := &stats
+			if !running {
+				live = nil
+			}
+			apiProcess := h.mapper.ProcessToAPI(*process, live)
+			resp.Data.Process = &apiProcess
+		} else if !errors.Is(err, store.ErrNotFound) {
+			slog.Warn("failed to get session process", "session_id", session.ID, "error", err)
+		}
+	}
 	return api.GetSession200JSONResponse(resp), nil
 }
 
@@ -568,6 +742,37 @@ func (h *SessionHandlers) UpdateSession(ctx context.Context, req api.UpdateSessi
 		}
 	}
 
+	// Update auto-deny configuration if specified
+	if req.Body.AutoDenyAll != nil {
+		update.AutoDenyAll = req.Body.AutoDenyAll
+	}
+	if req.Body.AutoDenyTools != nil {
+		toolsJSON, err := json.Marshal(*req.Body.AutoDenyTools)
+		if err == nil {
+			toolsStr := string(toolsJSON)
+			update.AutoDenyTools = &toolsStr
+		} else {
+			slog.Error("Failed to marshal auto-deny tools",
+				"error", err,
+				"tools", *req.Body.AutoDenyTools)
+		}
+	}
+
+	// Update resource limits if specified; an empty budget removes them
+	if req.Body.Budget != nil {
+		budgetStr, err := approval.EncodeBudget(h.mapper.BudgetFromAPI(req.Body.Budget))
+		if err == nil {
+			update.Budget = &budgetStr
+		} else {
+			slog.Error("Failed to encode session budget", "error", err)
+		}
+	}
+
+	// Move the working directory to (or back from) a remote host
+	if req.Body.SshHost != nil {
+		update.SSHHost = req.Body.SshHost
+	}
+
 	// Update model if specified
 	if req.Body.Model != nil {
 		update.Model = req.Body.Model
@@ -655,6 +860,10 @@ func (h *SessionHandlers) UpdateSession(ctx context.Context, req api.UpdateSessi
 		}, nil
 	}
 
+	if req.Body.Archived != nil && *req.Body.Archived {
+		h.releaseWorktree(ctx, string(req.Id))
+	}
+
 	// Auto-approve pending approvals if bypass permissions was just enabled
 	if req.Body.DangerouslySkipPermissions != nil && *req.Body.DangerouslySkipPermissions {
 		// Get all pending approvals for this session
@@ -1352,6 +1561,8 @@ func (h *SessionHandlers) BulkArchiveSessions(ctx context.Context, req api.BulkA
 		err := h.store.UpdateSession(ctx, sessionID, update)
 		if err != nil {
 			failedSessions = append(failedSessions, sessionID)
+		} else if req.Body.Archived {
+			h.releaseWorktree(ctx, sessionID)
 		}
 	}
 
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/humanlayer/humanlayer/hld/api"
+	"github.com/humanlayer/humanlayer/hld/session"
+)
+
+// maxSessionsPageSize caps ?limit= on the session list
+const maxSessionsPageSize = 1000
+
+// sessionSortKey orders sessions for listing; only the field being sorted
+// by is set, with the ID breaking ties
+type sessionSortKey struct {
+	Time int64   `json:"t,omitempty"`
+	Cost float64 `json:"c,omitempty"`
+	ID   string  `json:"id"`
+}
+
+func sortKeyOf(info session.Info, by api.ListSessionsParamsSort) sessionSortKey {
+	key := sessionSortKey{ID: info.ID}
+	switch by {
+	case api.Created:
+		key.Time = info.StartTime.UnixNano()
+	case api.Cost:
+		if info.Result != nil {
+			key.Cost = info.Result.CostUSD
+		}
+	default:
+		key.Time = info.LastActivityAt.UnixNano()
+	}
+	return key
+}
+
+// compare orders k against other, ascending
+func (k sessionSortKey) compare(other sessionSortKey) int {
+	if c := cmp.Compare(k.Time, other.Time); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(k.Cost, other.Cost); c != 0 {
+		return c
+	}
+	return strings.Compare(k.ID, other.ID)
+}
+
+// sessionCursor is where a page of sessions ended. It records the sort so
+// a cursor isn't used with a different one.
+type sessionCursor struct {
+	Sort  api.ListSessionsParamsSort  `json:"s"`
+	Order api.ListSessionsParamsOrder `json:"o"`
+	After sessionSortKey              `json:"a"`
+}
+
+func (c sessionCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+func decodeSessionCursor(value string) (sessionCursor, error) {
+	var c sessionCursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || json.Unmarshal(data, &c) != nil {
+		return c, errInvalidCursor
+	}
+	return c, nil
+}
+
+// pageSessions sorts sessions and returns the page after cursor (if set)
+// of up to limit (if positive), with the cursor for the next page, if any
+func pageSessions(sessions []session.Info, by api.ListSessionsParamsSort, order api.ListSessionsParamsOrder, limit int, cursor string) ([]session.Info, string, error) {
+	direction := 1
+	if order == api.Desc {
+		direction = -1
+	}
+	slices.SortFunc(sessions, func(a, b session.Info) int {
+		return direction * sortKeyOf(a, by).compare(sortKeyOf(b, by))
+	})
+
+	if cursor != "" {
+		c, err := decodeSessionCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		if c.Sort != by || c.Order != order {
+			return nil, "", fmt.Errorf("cursor is for sort=%s&order=%s", c.Sort, c.Order)
+		}
+		start := len(sessions)
+		for i, info := range sessions {
+			if direction*sortKeyOf(info, by).compare(c.After) > 0 {
+				start = i
+				break
+			}
+		}
+		sessions = sessions[start:]
+	}
+
+	if limit <= 0 || len(sessions) <= limit {
+		return sessions, "", nil
+	}
+	page := sessions[:limit]
+	next := sessionCursor{Sort: by, Order: order, After: sortKeyOf(page[limit-1], by)}
+	return page, next.encode(), nil
+}
+
+// sessionFields are the JSON names of a session's fields, for ?fields=
+var sessionFields = func() map[string]bool {
+	fields := make(map[string]bool)
+	t := reflect.TypeOf(api.Session{})
+	for i := 0; i < t.NumField(); i++ {
+		if name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ","); name != "" && name != "-" {
+			fields[name] = true
+		}
+	}
+	return fields
+}()
+
+// parseSessionFields reads a fields= selector, which always includes the id
+func parseSessionFields(value string) ([]string, error) {
+	fields := []string{"id"}
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" || slices.Contains(fields, field) {
+			continue
+		}
+		if !sessionFields[field] {
+			return nil, fmt.Errorf("unknown session field %q", field)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// selectedSessionsResponse is a session list trimmed to selected fields,
+// which the typed response can't express
+type selectedSessionsResponse struct {
+	response api.SessionsResponse
+	fields   []string
+}
+
+func (r selectedSessionsResponse) VisitListSessionsResponse(w http.ResponseWriter) error {
+	data := make([]map[string]json.RawMessage, len(r.response.Data))
+	for i, s := range r.response.Data {
+		encoded, err := json.Marshal(s)
+		if err != nil {
+			return err
+		}
+		var all map[string]json.RawMessage
+		if err := json.Unmarshal(encoded, &all); err != nil {
+			return err
+		}
+		data[i] = make(map[string]json.RawMessage, len(r.fields))
+		for _, field := range r.fields {
+			if value, ok := all[field]; ok {
+				data[i][field] = value
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(struct {
+		Data       []map[string]json.RawMessage `json:"data"`
+		Counts     any                          `json:"counts,omitempty"`
+		NextCursor *string                      `json:"next_cursor,omitempty"`
+	}{data, r.response.Counts, r.response.NextCursor})
+}
+
+func listSessionsBadRequest(message string) api.ListSessionsResponseObject {
+	return api.ListSessions400JSONResponse{
+		BadRequestJSONResponse: api.BadRequestJSONResponse{
+			Error: api.ErrorDetail{Code: "HLD-3001", Message: message},
+		},
+	}
+}
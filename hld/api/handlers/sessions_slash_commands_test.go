@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -164,6 +165,11 @@ func (m *MockStore) GetPendingApprovals(ctx context.Context, sessionID string) (
 	return args.Get(0).([]*store.Approval), args.Error(1)
 }
 
+func (m *MockStore) ListSessionApprovals(ctx context.Context, sessionID string) ([]*store.Approval, error) {
+	args := m.Called(ctx, sessionID)
+	return args.Get(0).([]*store.Approval), args.Error(1)
+}
+
 func (m *MockStore) UpdateApprovalResponse(ctx context.Context, id string, status store.ApprovalStatus, comment string) error {
 	args := m.Called(ctx, id, status, comment)
 	return args.Error(0)
@@ -202,6 +208,42 @@ func (m *MockStore) StoreApprovalImages(ctx context.Context, approvalID string,
 	return args.Error(0)
 }
 
+func (m *MockStore) CountApprovals(ctx context.Context, since time.Time) ([]store.ApprovalCount, error) {
+	args := m.Called(ctx, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]store.ApprovalCount), args.Error(1)
+}
+
+func (m *MockStore) CountPendingApprovals(ctx context.Context, sessionIDs []string) (map[string]int, error) {
+	args := m.Called(ctx, sessionIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+func (m *MockStore) GetLastEventTimes(ctx context.Context, sessionIDs []string) (map[string]time.Time, error) {
+	args := m.Called(ctx, sessionIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]time.Time), args.Error(1)
+}
+
+func (m *MockStore) ListPendingApprovals(ctx context.Context) ([]*store.Approval, error) {
+	return nil, nil
+}
+
+func (m *MockStore) SetApprovalCommentExpansion(ctx context.Context, id string, expansion string) error {
+	return nil
+}
+
+func (m *MockStore) SetApprovalConstraints(ctx context.Context, id string, constraints *store.ApprovalConstraints) error {
+	return nil
+}
+
 func (m *MockStore) Close() error {
 	args := m.Called()
 	return args.Error(0)
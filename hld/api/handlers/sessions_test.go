@@ -3,16 +3,23 @@ package handlers_test
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/gin-gonic/gin"
 	claudecode "github.com/humanlayer/humanlayer/claudecode-go"
 	"github.com/humanlayer/humanlayer/hld/api"
 	"github.com/humanlayer/humanlayer/hld/api/handlers"
 	"github.com/humanlayer/humanlayer/hld/approval"
 	"github.com/humanlayer/humanlayer/hld/session"
 	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/humanlayer/humanlayer/hld/worktree"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
@@ -144,6 +151,82 @@ func TestSessionHandlers_CreateSession(t *testing.T) {
 	}
 }
 
+func TestSessionHandlers_CreateSessionInWorktree(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockManager := session.NewMockSessionManager(ctrl)
+	s := store.NewInMemoryStore()
+	worktreeDir := t.TempDir()
+	h := handlers.NewSessionHandlers(mockManager, s, nil)
+	h.SetWorktreeManager(worktree.NewManager(s, s, worktreeDir))
+	router := setupTestRouter(t, h, nil, nil)
+
+	repo := initGitRepo(t, 0)
+	gitRouter := gin.New()
+	gitRouter.GET("/api/v1/sessions/:id/git/status", handlers.NewGitHandler(s).HandleGetGitStatus)
+
+	var launchedIn string
+	mockManager.EXPECT().LaunchSession(gomock.Any(), gomock.Any(), false).
+		DoAndReturn(func(ctx context.Context, config session.LaunchSessionConfig, isDraft bool) (*session.Session, error) {
+			launchedIn = config.WorkingDir
+			require.NoError(t, s.CreateSession(ctx, &store.Session{
+				ID: "sess-wt", RunID: "run-wt", WorkingDir: config.WorkingDir, Status: store.SessionStatusRunning, CreatedAt: time.Now(),
+			}))
+			return &session.Session{ID: "sess-wt", RunID: "run-wt"}, nil
+		})
+	w := makeRequest(t, router, "POST", "/api/v1/sessions", api.CreateSessionRequest{
+		Query: "Fix the build", WorkingDir: stringPtr(repo), UseWorktree: boolPtr(true),
+	})
+	require.Equal(t, 201, w.Code, w.Body.String())
+	wt, err := s.GetSessionWorktree(context.Background(), "sess-wt")
+	require.NoError(t, err)
+	assert.Equal(t, wt.Path, launchedIn, "launches in the worktree")
+	assert.True(t, strings.HasPrefix(wt.Path, worktreeDir))
+
+	// Git endpoints act on the worktree, whose .git is a file
+	require.NoError(t, os.WriteFile(filepath.Join(launchedIn, "fix.go"), []byte("package main\n"), 0644))
+	w = makeRequest(t, gitRouter, "GET", "/api/v1/sessions/sess-wt/git/status", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var status handlers.GitStatusResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&status))
+	assert.Equal(t, wt.Branch, status.Branch)
+	require.Len(t, status.Untracked, 1)
+	assert.Equal(t, "fix.go", status.Untracked[0].Path)
+	require.NoError(t, os.Remove(filepath.Join(launchedIn, "fix.go")))
+
+	// Archiving a running session leaves its worktree to the cleanup task
+	mockManager.EXPECT().UpdateSessionSettings(gomock.Any(), "sess-wt", gomock.Any()).Return(nil).Times(2)
+	w = makeRequest(t, router, "PATCH", "/api/v1/sessions/sess-wt", api.UpdateSessionRequest{Archived: boolPtr(true)})
+	require.Equal(t, 200, w.Code, w.Body.String())
+	assert.DirExists(t, wt.Path)
+
+	// Once it has finished, archiving removes the worktree
+	completed, archived := store.SessionStatusCompleted, true
+	require.NoError(t, s.UpdateSession(context.Background(), "sess-wt", store.SessionUpdate{Status: &completed, Archived: &archived}))
+	w = makeRequest(t, router, "PATCH", "/api/v1/sessions/sess-wt", api.UpdateSessionRequest{Archived: boolPtr(true)})
+	require.Equal(t, 200, w.Code, w.Body.String())
+	assert.NoDirExists(t, wt.Path)
+	wt, err = s.GetSessionWorktree(context.Background(), "sess-wt")
+	require.NoError(t, err)
+	assert.NotNil(t, wt.RemovedAt)
+
+	// A failed launch doesn't leave its worktree behind
+	mockManager.EXPECT().LaunchSession(gomock.Any(), gomock.Any(), false).Return(nil, fmt.Errorf("failed to start Claude"))
+	w = makeRequest(t, router, "POST", "/api/v1/sessions", api.CreateSessionRequest{
+		Query: "Fix the build", WorkingDir: stringPtr(repo), UseWorktree: boolPtr(true),
+	})
+	assert.Equal(t, 500, w.Code)
+	entries, err := os.ReadDir(worktreeDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	w = makeRequest(t, router, "POST", "/api/v1/sessions", api.CreateSessionRequest{
+		Query: "Fix the build", WorkingDir: stringPtr(t.TempDir()), UseWorktree: boolPtr(true),
+	})
+	assert.Equal(t, 400, w.Code, "not a repository")
+	w = makeRequest(t, router, "POST", "/api/v1/sessions", api.CreateSessionRequest{Query: "Fix the build", UseWorktree: boolPtr(true)})
+	assert.Equal(t, 400, w.Code, "no working directory")
+}
+
 func TestSessionHandlers_ListSessions(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -259,6 +342,85 @@ func TestSessionHandlers_ListSessions(t *testing.T) {
 			assert.NotEqual(t, "sess-archived", s.Id)
 		}
 	})
+
+	t.Run("paginate sorted by cost", func(t *testing.T) {
+		costed := make([]session.Info, len(sessionInfos))
+		copy(costed, sessionInfos)
+		for i, cost := range []float64{0.5, 2, 1} {
+			costed[i].Result = &claudecode.Result{CostUSD: cost}
+		}
+		mockManager.EXPECT().ListSessions().Return(costed).Times(3)
+
+		var page struct {
+			Data       []api.Session `json:"data"`
+			NextCursor *string       `json:"next_cursor"`
+		}
+		w := makeRequest(t, router, "GET", "/api/v1/sessions?leavesOnly=false&sort=cost&limit=2", nil)
+		assertJSONResponse(t, w, 200, &page)
+		require.Len(t, page.Data, 2)
+		assert.Equal(t, "sess-2", page.Data[0].Id)
+		assert.Equal(t, "sess-3", page.Data[1].Id)
+		require.NotNil(t, page.NextCursor)
+
+		cursor := *page.NextCursor
+		page.NextCursor = nil
+		w = makeRequest(t, router, "GET", "/api/v1/sessions?leavesOnly=false&sort=cost&limit=2&cursor="+cursor, nil)
+		assertJSONResponse(t, w, 200, &page)
+		require.Len(t, page.Data, 1)
+		assert.Equal(t, "sess-1", page.Data[0].Id)
+		assert.Nil(t, page.NextCursor, "last page")
+
+		w = makeRequest(t, router, "GET", "/api/v1/sessions?leavesOnly=false&sort=created&cursor="+cursor, nil)
+		assert.Equal(t, 400, w.Code, "cursor from another sort")
+	})
+
+	t.Run("filter by status and label", func(t *testing.T) {
+		labelled := make([]session.Info, len(sessionInfos))
+		copy(labelled, sessionInfos)
+		labelled[2].Template = "nightly"
+		mockManager.EXPECT().ListSessions().Return(labelled).Times(2)
+
+		var resp struct {
+			Data []api.Session `json:"data"`
+		}
+		w := makeRequest(t, router, "GET", "/api/v1/sessions?leavesOnly=false&status=completed&sort=created&order=asc", nil)
+		assertJSONResponse(t, w, 200, &resp)
+		require.Len(t, resp.Data, 2)
+		assert.Equal(t, "sess-1", resp.Data[0].Id)
+		assert.Equal(t, "sess-3", resp.Data[1].Id)
+
+		w = makeRequest(t, router, "GET", "/api/v1/sessions?leavesOnly=false&label=nightly", nil)
+		assertJSONResponse(t, w, 200, &resp)
+		require.Len(t, resp.Data, 1)
+		assert.Equal(t, "sess-3", resp.Data[0].Id)
+	})
+
+	t.Run("select fields", func(t *testing.T) {
+		mockManager.EXPECT().ListSessions().Return(sessionInfos)
+
+		var resp struct {
+			Data []map[string]any `json:"data"`
+		}
+		w := makeRequest(t, router, "GET", "/api/v1/sessions?fields=title,status", nil)
+		assertJSONResponse(t, w, 200, &resp)
+		require.Len(t, resp.Data, 2)
+		for _, s := range resp.Data {
+			assert.ElementsMatch(t, []string{"id", "status"}, mapKeys(s), "empty titles are omitted")
+		}
+
+		w = makeRequest(t, router, "GET", "/api/v1/sessions?fields=nope", nil)
+		assert.Equal(t, 400, w.Code)
+		w = makeRequest(t, router, "GET", "/api/v1/sessions?limit=0", nil)
+		assert.Equal(t, 400, w.Code)
+	})
+}
+
+func mapKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
 }
 
 func TestSessionHandlers_GetSession(t *testing.T) {
@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/approval"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/moderation"
+	"github.com/humanlayer/humanlayer/hld/share"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/humanlayer/humanlayer/hld/transcript"
+)
+
+// sharedPath is where share link holders read a session, unless links are
+// served from a listener of their own
+const sharedPath = "/api/v1/shared/"
+
+// ShareHandler serves share links: creating and revoking them for a
+// session, and the read-only view, with approvals for approve links, that
+// their holders get without daemon access
+type ShareHandler struct {
+	service      *share.Service
+	store        store.ConversationStore
+	annotations  store.AnnotationStore
+	approvals    approval.Manager
+	pathMappings []config.PathMapping
+	// path prefixes the tokens in link URLs
+	path string
+	// justification requires and records comments for high-risk approvals
+	justification *Justification
+}
+
+// NewShareHandler creates a new share handler. pathMappings locate working
+// directories recorded on other machines for the diff.
+func NewShareHandler(service *share.Service, s store.ConversationStore, annotations store.AnnotationStore, approvals approval.Manager, pathMappings []config.PathMapping) *ShareHandler {
+	return &ShareHandler{service: service, store: s, annotations: annotations, approvals: approvals, pathMappings: pathMappings, path: sharedPath}
+}
+
+// SetSharedPath makes link URLs start with path, for links served somewhere
+// other than /api/v1/shared/
+func (h *ShareHandler) SetSharedPath(path string) {
+	h.path = path
+}
+
+// SetJustification requires a comment for approving high-risk tool calls
+// through a link, as for local approvals
+func (h *ShareHandler) SetJustification(j *Justification) {
+	h.justification = j
+}
+
+type createShareLinkRequest struct {
+	// Scope is read (default) or approve
+	Scope string `json:"scope"`
+	Label string `json:"label"`
+	// ExpiresIn is a Go duration string; empty uses the default lifetime
+	ExpiresIn string `json:"expires_in"`
+}
+
+// ShareLink is a link with its URL, which is left out once the link stops
+// working
+type ShareLink struct {
+	*store.ShareLink
+	URL string `json:"url,omitempty"`
+}
+
+func (h *ShareHandler) withURL(link *store.ShareLink) (ShareLink, error) {
+	if !h.service.Active(link) {
+		return ShareLink{ShareLink: link}, nil
+	}
+	token, err := h.service.Token(link)
+	if err != nil {
+		return ShareLink{}, err
+	}
+	return ShareLink{ShareLink: link, URL: h.path + token}, nil
+}
+
+// HandleCreate makes a link to a session
+func (h *ShareHandler) HandleCreate(c *gin.Context) {
+	var req createShareLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	if req.Scope == "" {
+		req.Scope = store.ShareScopeRead
+	}
+	var ttl time.Duration
+	if req.ExpiresIn != "" {
+		var err error
+		if ttl, err = time.ParseDuration(req.ExpiresIn); err != nil || ttl <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "expires_in must be a positive duration such as 24h"})
+			return
+		}
+	}
+	ctx := c.Request.Context()
+	session, err := h.store.GetSession(ctx, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	link, token, err := h.service.Create(ctx, session.ID, req.Scope, req.Label, ttl)
+	switch {
+	case errors.Is(err, share.ErrInvalidScope), errors.Is(err, share.ErrInvalidTTL):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		slog.Error("failed to create share link", "session_id", session.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create share link"})
+		return
+	}
+	slog.Info("created session share link", "session_id", session.ID, "link_id", link.ID, "scope", link.Scope, "expires_at", link.ExpiresAt)
+	c.JSON(http.StatusCreated, ShareLink{ShareLink: link, URL: h.path + token})
+}
+
+// HandleList returns a session's links, oldest first
+func (h *ShareHandler) HandleList(c *gin.Context) {
+	links, err := h.service.List(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		slog.Error("failed to list share links", "session_id", c.Param("id"), "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list share links"})
+		return
+	}
+	response := make([]ShareLink, 0, len(links))
+	for _, link := range links {
+		withURL, err := h.withURL(link)
+		if err != nil {
+			slog.Error("failed to sign share link", "link_id", link.ID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list share links"})
+			return
+		}
+		response = append(response, withURL)
+	}
+	c.JSON(http.StatusOK, gin.H{"data": response})
+}
+
+// HandleRevoke stops a link working
+func (h *ShareHandler) HandleRevoke(c *gin.Context) {
+	link, err := h.service.Revoke(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Share link not found"})
+		return
+	} else if err != nil {
+		slog.Error("failed to revoke share link", "link_id", c.Param("id"), "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke share link"})
+		return
+	}
+	slog.Info("revoked session share link", "session_id", link.SessionID, "link_id", link.ID)
+	c.JSON(http.StatusOK, ShareLink{ShareLink: link})
+}
+
+// resolve returns the link the request's token grants, or responds with why
+// there is none
+func (h *ShareHandler) resolve(c *gin.Context) (*store.ShareLink, bool) {
+	link, err := h.service.Resolve(c.Request.Context(), c.Param("token"))
+	switch {
+	case errors.Is(err, share.ErrLinkInvalid):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Share link not found"})
+	case errors.Is(err, share.ErrLinkExpired), errors.Is(err, share.ErrLinkRevoked):
+		c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+	case err != nil:
+		slog.Error("failed to resolve share link", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open share link"})
+	default:
+		return link, true
+	}
+	return nil, false
+}
+
+// HandleView renders the shared session's transcript with its diff, as
+// HTML for the browser (default) or with format=markdown
+func (h *ShareHandler) HandleView(c *gin.Context) {
+	format := c.DefaultQuery("format", transcript.FormatHTML)
+	if format != transcript.FormatMarkdown && format != transcript.FormatHTML {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be markdown or html"})
+		return
+	}
+	link, ok := h.resolve(c)
+	if !ok {
+		return
+	}
+	session, err := h.store.GetSession(c.Request.Context(), link.SessionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+	// Shared pages shouldn't be cached by proxies or leak the token onward
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("Referrer-Policy", "no-referrer")
+	writeTranscript(c, h.store, h.annotations, session.ID, format, transcript.Options{Diff: transcriptDiff(session, h.pathMappings)})
+}
+
+// HandleListApprovals returns the shared session's pending approvals
+func (h *ShareHandler) HandleListApprovals(c *gin.Context) {
+	link, ok := h.resolve(c)
+	if !ok {
+		return
+	}
+	approvals, err := h.approvals.GetPendingApprovals(c.Request.Context(), link.SessionID)
+	if err != nil {
+		slog.Error("failed to list shared approvals", "session_id", link.SessionID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list approvals"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": approvals, "can_decide": link.Scope == store.ShareScopeApprove})
+}
+
+type shareDecideRequest struct {
+	Decision string `json:"decision" binding:"required"`
+	Comment  string `json:"comment"`
+}
+
+// HandleDecide decides one of the shared session's approvals, for approve
+// links
+func (h *ShareHandler) HandleDecide(c *gin.Context) {
+	link, ok := h.resolve(c)
+	if !ok {
+		return
+	}
+	if link.Scope != store.ShareScopeApprove {
+		c.JSON(http.StatusForbidden, gin.H{"error": "this share link can't decide approvals"})
+		return
+	}
+	var req shareDecideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "decision is required"})
+		return
+	}
+	ctx := c.Request.Context()
+	approvalID := c.Param("approval_id")
+	// Approvals of other sessions are as good as missing to the link
+	if a, err := h.approvals.GetApproval(ctx, approvalID); err != nil || a.SessionID != link.SessionID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Approval not found"})
+		return
+	}
+
+	var err error
+	switch req.Decision {
+	case "approve":
+		var highRisk *justified
+		if highRisk, err = h.justification.check(ctx, approvalID, req.Comment); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err = h.approvals.ApproveToolCall(ctx, approvalID, req.Comment, nil); err == nil {
+			h.justification.record(ctx, highRisk, req.Comment)
+		}
+	case "deny":
+		if req.Comment == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "comment is required when denying"})
+			return
+		}
+		err = h.approvals.DenyToolCall(ctx, approvalID, req.Comment, nil)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "decision must be approve or deny"})
+		return
+	}
+	switch {
+	case err == nil:
+		slog.Info("approval decided through share link", "approval_id", approvalID, "link_id", link.ID, "label", link.Label, "decision", req.Decision)
+		c.Status(http.StatusNoContent)
+	case errors.Is(err, store.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Approval not found"})
+	case errors.Is(err, store.ErrAlreadyDecided):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, moderation.ErrBlocked):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		slog.Error("shared decision failed", "approval_id", approvalID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Decision failed"})
+	}
+}
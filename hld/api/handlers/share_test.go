@@ -0,0 +1,136 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/api/handlers"
+	"github.com/humanlayer/humanlayer/hld/approval"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/share"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShareLinks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	for _, id := range []string{"sess-1", "sess-2"} {
+		require.NoError(t, s.CreateSession(ctx, &store.Session{ID: id, RunID: "run-" + id, ClaudeSessionID: "claude-" + id, Query: "fix the build", CreatedAt: time.Now()}))
+	}
+	require.NoError(t, s.AddConversationEvent(ctx, &store.ConversationEvent{
+		SessionID: "sess-1", ClaudeSessionID: "claude-sess-1", EventType: store.EventTypeMessage, Role: "assistant", Content: "The build is fixed",
+	}))
+	for id, sessionID := range map[string]string{"appr-1": "sess-1", "appr-2": "sess-1", "appr-other": "sess-2"} {
+		require.NoError(t, s.CreateApproval(ctx, &store.Approval{
+			ID: id, SessionID: sessionID, RunID: "run-" + sessionID, ToolName: "Bash", Status: store.ApprovalStatusLocalPending, CreatedAt: time.Now(),
+		}))
+	}
+	service := share.New(s, config.SharingConfig{MaxTTL: "72h"}, bytes.Repeat([]byte{7}, 32))
+	h := handlers.NewShareHandler(service, s, s, approval.NewManager(s, nil), nil)
+	router := gin.New()
+	router.POST("/api/v1/sessions/:id/shares", h.HandleCreate)
+	router.GET("/api/v1/sessions/:id/shares", h.HandleList)
+	router.DELETE("/api/v1/shares/:id", h.HandleRevoke)
+	router.GET("/api/v1/shared/:token", h.HandleView)
+	router.GET("/api/v1/shared/:token/approvals", h.HandleListApprovals)
+	router.POST("/api/v1/shared/:token/approvals/:approval_id/decide", h.HandleDecide)
+
+	create := func(body map[string]string) handlers.ShareLink {
+		t.Helper()
+		w := makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/shares", body)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var link handlers.ShareLink
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&link))
+		return link
+	}
+
+	t.Run("create", func(t *testing.T) {
+		w := makeRequest(t, router, "POST", "/api/v1/sessions/missing/shares", map[string]string{})
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		w = makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/shares", map[string]string{"scope": "admin"})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w = makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/shares", map[string]string{"expires_in": "720h"})
+		assert.Equal(t, http.StatusBadRequest, w.Code, "longer than max_ttl")
+		w = makeRequest(t, router, "POST", "/api/v1/sessions/sess-1/shares", map[string]string{"expires_in": "soon"})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("read link shows the session", func(t *testing.T) {
+		link := create(map[string]string{"label": "for grace"})
+		assert.Equal(t, store.ShareScopeRead, link.Scope)
+		assert.WithinDuration(t, time.Now().Add(config.DefaultShareLinkTTL), link.ExpiresAt, 2*time.Second)
+
+		w := makeRequest(t, router, "GET", link.URL, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+		assert.Equal(t, "private, no-store", w.Header().Get("Cache-Control"))
+		assert.Contains(t, w.Body.String(), "The build is fixed")
+
+		w = makeRequest(t, router, "GET", link.URL+"/approvals", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		var approvals struct {
+			Data      []*store.Approval `json:"data"`
+			CanDecide bool              `json:"can_decide"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&approvals))
+		assert.Len(t, approvals.Data, 2, "only the shared session's approvals")
+		assert.False(t, approvals.CanDecide)
+
+		w = makeRequest(t, router, "POST", link.URL+"/approvals/appr-1/decide", map[string]string{"decision": "approve"})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = makeRequest(t, router, "GET", "/api/v1/shared/"+link.ID+".approve.9999999999.forged", nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("approve link decides the session's approvals", func(t *testing.T) {
+		link := create(map[string]string{"scope": "approve", "expires_in": "1h"})
+
+		w := makeRequest(t, router, "POST", link.URL+"/approvals/appr-other/decide", map[string]string{"decision": "approve"})
+		assert.Equal(t, http.StatusNotFound, w.Code, "another session's approval")
+		w = makeRequest(t, router, "POST", link.URL+"/approvals/appr-1/decide", map[string]string{"decision": "deny"})
+		assert.Equal(t, http.StatusBadRequest, w.Code, "denials need a comment")
+
+		w = makeRequest(t, router, "POST", link.URL+"/approvals/appr-1/decide", map[string]string{"decision": "deny", "comment": "not on main"})
+		require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+		decided, err := s.GetApproval(ctx, "appr-1")
+		require.NoError(t, err)
+		assert.Equal(t, store.ApprovalStatusLocalDenied, decided.Status)
+		assert.Equal(t, "not on main", decided.Comment)
+
+		w = makeRequest(t, router, "POST", link.URL+"/approvals/appr-1/decide", map[string]string{"decision": "approve"})
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("revoked links stop working", func(t *testing.T) {
+		link := create(map[string]string{"scope": "approve"})
+		w := makeRequest(t, router, "DELETE", "/api/v1/shares/"+link.ID, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = makeRequest(t, router, "GET", link.URL, nil)
+		assert.Equal(t, http.StatusGone, w.Code)
+		w = makeRequest(t, router, "POST", link.URL+"/approvals/appr-2/decide", map[string]string{"decision": "approve"})
+		assert.Equal(t, http.StatusGone, w.Code)
+		w = makeRequest(t, router, "DELETE", "/api/v1/shares/missing", nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = makeRequest(t, router, "GET", "/api/v1/sessions/sess-1/shares", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		var list struct {
+			Data []handlers.ShareLink `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&list))
+		require.Len(t, list.Data, 3)
+		assert.NotEmpty(t, list.Data[0].URL)
+		assert.Empty(t, list.Data[2].URL, "revoked links have no URL")
+		assert.NotNil(t, list.Data[2].RevokedAt)
+	})
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/similar"
+)
+
+const (
+	defaultSimilarLimit = 10
+	maxSimilarLimit     = 50
+)
+
+// SimilarSessionsHandler finds past sessions similar to a query by embedding
+type SimilarSessionsHandler struct {
+	index *similar.Index
+}
+
+// NewSimilarSessionsHandler creates a new similar sessions handler. index is
+// nil when no embedding model is configured.
+func NewSimilarSessionsHandler(index *similar.Index) *SimilarSessionsHandler {
+	return &SimilarSessionsHandler{index: index}
+}
+
+// HandleSimilarSessions returns the sessions most similar to ?query=, best first
+func (h *SimilarSessionsHandler) HandleSimilarSessions(c *gin.Context) {
+	if h.index == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Embeddings are not configured"})
+		return
+	}
+	query := strings.TrimSpace(c.Query("query"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "query is required"})
+		return
+	}
+	limit := defaultSimilarLimit
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxSimilarLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 50"})
+			return
+		}
+		limit = n
+	}
+
+	matches, err := h.index.Search(c.Request.Context(), query, limit)
+	if err != nil {
+		slog.Error("failed to search similar sessions", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search similar sessions"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"sessions": matches})
+}
@@ -33,6 +33,44 @@ func parseEventTypes(types []string) []bus.EventType {
 			eventTypes = append(eventTypes, bus.EventConversationUpdated)
 		case "session_settings_changed":
 			eventTypes = append(eventTypes, bus.EventSessionSettingsChanged)
+		case "tool_result_reported":
+			eventTypes = append(eventTypes, bus.EventToolResultReported)
+		case "session_budget_exceeded":
+			eventTypes = append(eventTypes, bus.EventSessionBudgetExceeded)
+		case "cost_budget_threshold":
+			eventTypes = append(eventTypes, bus.EventCostBudgetThreshold)
+		case "commit_message_progress":
+			eventTypes = append(eventTypes, bus.EventCommitMessageProgress)
+		case "job_completed":
+			eventTypes = append(eventTypes, bus.EventJobCompleted)
+		case "devcontainer_progress":
+			eventTypes = append(eventTypes, bus.EventDevcontainerProgress)
+		case "session_summary_ready":
+			eventTypes = append(eventTypes, bus.EventSessionSummaryReady)
+		case "daily_digest":
+			eventTypes = append(eventTypes, bus.EventDailyDigest)
+		case "session_queued":
+			eventTypes = append(eventTypes, bus.EventSessionQueued)
+		case "session_retry_scheduled":
+			eventTypes = append(eventTypes, bus.EventSessionRetryScheduled)
+		case "artifact_published":
+			eventTypes = append(eventTypes, bus.EventArtifactPublished)
+		case "approval_reminder":
+			eventTypes = append(eventTypes, bus.EventApprovalReminder)
+		case "approval_held":
+			eventTypes = append(eventTypes, bus.EventApprovalHeld)
+		case "approval_unheld":
+			eventTypes = append(eventTypes, bus.EventApprovalUnheld)
+		case "approval_constraint_violated":
+			eventTypes = append(eventTypes, bus.EventApprovalConstraintViolated)
+		case "cloud_approval_conflict":
+			eventTypes = append(eventTypes, bus.EventCloudApprovalConflict)
+		case "disk_guard_refused":
+			eventTypes = append(eventTypes, bus.EventDiskGuardRefused)
+		case "command_output":
+			eventTypes = append(eventTypes, bus.EventCommandOutput)
+		case "approval_decision_failed":
+			eventTypes = append(eventTypes, bus.EventApprovalDecisionFailed)
 		}
 		// Ignore unknown event types
 	}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/llm"
+	"github.com/humanlayer/humanlayer/hld/session"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// Overall statuses of the status summary
+const (
+	SummaryStatusOK       = "ok"
+	SummaryStatusDegraded = "degraded"
+)
+
+// StatusSummary is the daemon's state for a status page. It is counts and
+// timestamps only: no session IDs, queries, tool inputs or errors.
+type StatusSummary struct {
+	// Status is degraded while any model provider's last call failed
+	Status        string               `json:"status"`
+	Version       string               `json:"version"`
+	StartedAt     time.Time            `json:"started_at"`
+	UptimeSeconds int64                `json:"uptime_seconds"`
+	Queues        StatusQueues         `json:"queues"`
+	Providers     []llm.ProviderHealth `json:"providers"`
+	Approvals     StatusApprovals      `json:"approvals"`
+}
+
+// StatusQueues are the depths of the daemon's work queues
+type StatusQueues struct {
+	// Sessions waiting for one of max_concurrent_sessions, 0 meaning
+	// unlimited
+	SessionsQueued int `json:"sessions_queued"`
+	SessionsActive int `json:"sessions_active"`
+	MaxConcurrent  int `json:"max_concurrent"`
+	JobsPending    int `json:"jobs_pending"`
+	JobsRunning    int `json:"jobs_running"`
+}
+
+// StatusApprovals counts the approvals waiting for a decision
+type StatusApprovals struct {
+	Pending int `json:"pending"`
+	// Held are pending approvals parked with a reason
+	Held int `json:"held"`
+	// OldestPendingSeconds is how long the oldest pending approval has
+	// waited
+	OldestPendingSeconds int64 `json:"oldest_pending_seconds"`
+}
+
+// StatusHandler serves the status summary
+type StatusHandler struct {
+	sessionManager session.SessionManager
+	store          store.ConversationStore
+	jobs           store.JobStore
+	holds          store.HoldStore
+	router         *llm.Router
+	version        string
+	startedAt      time.Time
+	now            func() time.Time
+}
+
+// NewStatusHandler creates a new status handler, counting uptime from now
+func NewStatusHandler(sessionManager session.SessionManager, s store.ConversationStore, jobs store.JobStore, holds store.HoldStore, router *llm.Router, version string) *StatusHandler {
+	return &StatusHandler{
+		sessionManager: sessionManager,
+		store:          s,
+		jobs:           jobs,
+		holds:          holds,
+		router:         router,
+		version:        version,
+		startedAt:      time.Now(),
+		now:            time.Now,
+	}
+}
+
+// HandleGetSummary returns the status summary
+func (h *StatusHandler) HandleGetSummary(c *gin.Context) {
+	ctx := c.Request.Context()
+	now := h.now()
+	summary := StatusSummary{
+		Status:        SummaryStatusOK,
+		Version:       h.version,
+		StartedAt:     h.startedAt,
+		UptimeSeconds: int64(now.Sub(h.startedAt).Seconds()),
+		Providers:     []llm.ProviderHealth{},
+	}
+
+	queue := h.sessionManager.QueueStatus()
+	summary.Queues.SessionsQueued = len(queue.Queued)
+	summary.Queues.SessionsActive = queue.Active
+	summary.Queues.MaxConcurrent = queue.MaxConcurrent
+	for status, count := range map[string]*int{store.JobStatusPending: &summary.Queues.JobsPending, store.JobStatusRunning: &summary.Queues.JobsRunning} {
+		jobs, err := h.jobs.ListJobs(ctx, status)
+		if err != nil {
+			slog.Error("failed to count jobs for status summary", "status", status, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build status summary"})
+			return
+		}
+		*count = len(jobs)
+	}
+
+	pending, err := h.store.ListPendingApprovals(ctx)
+	if err != nil {
+		slog.Error("failed to count approvals for status summary", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build status summary"})
+		return
+	}
+	summary.Approvals.Pending = len(pending)
+	pendingIDs := make(map[string]bool, len(pending))
+	for _, a := range pending {
+		pendingIDs[a.ID] = true
+		if waited := int64(now.Sub(a.CreatedAt).Seconds()); waited > summary.Approvals.OldestPendingSeconds {
+			summary.Approvals.OldestPendingSeconds = waited
+		}
+	}
+	holds, err := h.holds.ListApprovalHolds(ctx)
+	if err != nil {
+		slog.Error("failed to count held approvals for status summary", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build status summary"})
+		return
+	}
+	// Holds of approvals decided while held linger until the next sweep
+	for _, hold := range holds {
+		if pendingIDs[hold.ApprovalID] {
+			summary.Approvals.Held++
+		}
+	}
+
+	if h.router != nil {
+		summary.Providers = h.router.Health()
+	}
+	for _, p := range summary.Providers {
+		if p.Status == llm.HealthFailing {
+			summary.Status = SummaryStatusDegraded
+		}
+	}
+	c.JSON(http.StatusOK, summary)
+}
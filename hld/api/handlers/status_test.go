@@ -0,0 +1,85 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/api/handlers"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/llm"
+	"github.com/humanlayer/humanlayer/hld/session"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+type failingProvider struct{}
+
+func (failingProvider) Complete(ctx context.Context, model string, req llm.Request) (string, error) {
+	return "", &llm.StatusError{StatusCode: http.StatusServiceUnavailable, Body: "overloaded"}
+}
+
+func TestStatusSummary(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	require.NoError(t, s.CreateSession(ctx, &store.Session{ID: "sess-secret", RunID: "run-1", Query: "rotate the prod keys", CreatedAt: time.Now()}))
+	for id, age := range map[string]time.Duration{"appr-1": time.Minute, "appr-2": time.Hour} {
+		require.NoError(t, s.CreateApproval(ctx, &store.Approval{
+			ID: id, SessionID: "sess-secret", RunID: "run-1", ToolName: "Bash", Status: store.ApprovalStatusLocalPending, CreatedAt: time.Now().Add(-age),
+		}))
+	}
+	require.NoError(t, s.CreateApproval(ctx, &store.Approval{
+		ID: "appr-done", SessionID: "sess-secret", RunID: "run-1", ToolName: "Bash", Status: store.ApprovalStatusLocalApproved, CreatedAt: time.Now().Add(-24 * time.Hour),
+	}))
+	require.NoError(t, s.HoldApproval(ctx, &store.ApprovalHold{ApprovalID: "appr-1", Reason: "waiting on review", CreatedAt: time.Now()}))
+	require.NoError(t, s.HoldApproval(ctx, &store.ApprovalHold{ApprovalID: "appr-done", Reason: "stale", CreatedAt: time.Now()}))
+	require.NoError(t, s.CreateJob(ctx, &store.Job{ID: "job-1", Kind: "review", SessionID: "sess-secret", Status: store.JobStatusRunning, CreatedAt: time.Now()}))
+
+	ctrl := gomock.NewController(t)
+	sessionManager := session.NewMockSessionManager(ctrl)
+	sessionManager.EXPECT().QueueStatus().Return(session.QueueStatus{
+		MaxConcurrent: 2, Active: 2, Queued: []session.QueuedSession{{SessionID: "sess-queued", Position: 1}},
+	}).AnyTimes()
+
+	router := llm.NewRouterWithProviders(map[string]llm.Provider{"primary": failingProvider{}, "backup": suggestionProvider{}},
+		map[llm.Task][]config.LLMRoute{llm.TaskCommitMessage: {{Provider: "primary", Model: "m"}, {Provider: "backup", Model: "m"}}})
+	h := handlers.NewStatusHandler(sessionManager, s, s, s, router, "1.2.3")
+	engine := gin.New()
+	engine.GET("/api/v1/status/summary", h.HandleGetSummary)
+
+	get := func() (handlers.StatusSummary, string) {
+		t.Helper()
+		w := makeRequest(t, engine, "GET", "/api/v1/status/summary", nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		body := w.Body.String()
+		var summary handlers.StatusSummary
+		require.NoError(t, json.Unmarshal([]byte(body), &summary))
+		return summary, body
+	}
+
+	summary, _ := get()
+	assert.Equal(t, handlers.SummaryStatusOK, summary.Status, "no provider has failed yet")
+	assert.Equal(t, "1.2.3", summary.Version)
+	assert.Equal(t, handlers.StatusQueues{SessionsQueued: 1, SessionsActive: 2, MaxConcurrent: 2, JobsRunning: 1}, summary.Queues)
+	assert.Equal(t, 2, summary.Approvals.Pending)
+	assert.Equal(t, 1, summary.Approvals.Held, "holds of decided approvals don't count")
+	assert.InDelta(t, time.Hour.Seconds(), summary.Approvals.OldestPendingSeconds, 5)
+	require.Len(t, summary.Providers, 2)
+	assert.Equal(t, llm.HealthUnknown, summary.Providers[0].Status)
+
+	_, err := router.Complete(ctx, llm.TaskCommitMessage, llm.Request{Prompt: "rotate the prod keys"})
+	require.NoError(t, err)
+	summary, body := get()
+	assert.Equal(t, handlers.SummaryStatusDegraded, summary.Status)
+	assert.Equal(t, llm.HealthOK, summary.Providers[0].Status)
+	assert.Equal(t, llm.HealthFailing, summary.Providers[1].Status)
+	for _, secret := range []string{"sess-secret", "sess-queued", "appr-1", "rotate the prod keys", "overloaded", "waiting on review"} {
+		assert.NotContains(t, body, secret)
+	}
+}
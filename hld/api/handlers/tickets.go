@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// TicketHandler exposes the issue tracker tickets linked to sessions
+type TicketHandler struct {
+	store   store.ConversationStore
+	tickets store.TicketStore
+}
+
+// NewTicketHandler creates a new ticket handler
+func NewTicketHandler(conversationStore store.ConversationStore, tickets store.TicketStore) *TicketHandler {
+	return &TicketHandler{store: conversationStore, tickets: tickets}
+}
+
+// HandleListSessionTickets returns the tickets referenced in a session's
+// query and whether each has been moved to its review status
+func (h *TicketHandler) HandleListSessionTickets(c *gin.Context) {
+	ctx := c.Request.Context()
+	sessionID := c.Param("id")
+	if _, err := h.store.GetSession(ctx, sessionID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return
+		}
+		slog.Error("failed to get session", "session_id", sessionID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get session"})
+		return
+	}
+	tickets, err := h.tickets.ListSessionTickets(ctx, sessionID)
+	if err != nil {
+		slog.Error("failed to list session tickets", "session_id", sessionID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list tickets"})
+		return
+	}
+	if tickets == nil {
+		tickets = []*store.SessionTicket{}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": tickets})
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// ToolResultHandler serves captured tool results for sessions
+type ToolResultHandler struct {
+	store   store.ConversationStore
+	results store.ToolResultStore
+}
+
+// NewToolResultHandler creates a new tool result handler
+func NewToolResultHandler(conversationStore store.ConversationStore, results store.ToolResultStore) *ToolResultHandler {
+	return &ToolResultHandler{store: conversationStore, results: results}
+}
+
+// ToolResultsResponse represents the response for listing tool results
+type ToolResultsResponse struct {
+	Data []*store.ToolResult `json:"data"`
+}
+
+// HandleListToolResults returns the captured tool results for a session
+func (h *ToolResultHandler) HandleListToolResults(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	if _, err := h.store.GetSession(c.Request.Context(), sessionID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	results, err := h.results.GetToolResults(c.Request.Context(), sessionID)
+	if err != nil {
+		slog.Error("failed to get tool results", "session_id", sessionID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get tool results"})
+		return
+	}
+	if results == nil {
+		results = []*store.ToolResult{}
+	}
+
+	c.JSON(http.StatusOK, ToolResultsResponse{Data: results})
+}
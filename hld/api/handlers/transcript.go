@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/humanlayer/humanlayer/hld/transcript"
+)
+
+// TranscriptHandler renders session conversations as shareable documents
+type TranscriptHandler struct {
+	store        store.ConversationStore
+	annotations  store.AnnotationStore
+	pathMappings []config.PathMapping
+}
+
+// NewTranscriptHandler creates a new transcript handler. pathMappings locate
+// working directories recorded on other machines when including a diff.
+func NewTranscriptHandler(conversationStore store.ConversationStore, annotations store.AnnotationStore, pathMappings []config.PathMapping) *TranscriptHandler {
+	return &TranscriptHandler{store: conversationStore, annotations: annotations, pathMappings: pathMappings}
+}
+
+// HandleGetTranscript renders a session as Markdown (default) or HTML.
+// Query parameters: format=markdown|html, redact_inputs=true to hide tool
+// inputs, include_diff=true to append the working directory's uncommitted diff.
+func (h *TranscriptHandler) HandleGetTranscript(c *gin.Context) {
+	sessionID := c.Param("id")
+	format := c.DefaultQuery("format", transcript.FormatMarkdown)
+	if format != transcript.FormatMarkdown && format != transcript.FormatHTML {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be markdown or html"})
+		return
+	}
+
+	session, err := h.store.GetSession(c.Request.Context(), sessionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	opts := transcript.Options{RedactToolInputs: c.Query("redact_inputs") == "true"}
+	if c.Query("include_diff") == "true" {
+		opts.Diff = transcriptDiff(session, h.pathMappings)
+	}
+	writeTranscript(c, h.store, h.annotations, session.ID, format, opts)
+}
+
+// transcriptDiff returns the uncommitted diff of a session's working
+// directory, or nothing when it isn't a repository
+func transcriptDiff(session *store.Session, pathMappings []config.PathMapping) string {
+	repo := sessionRepo(session, pathMappings)
+	if !isGitRepo(repo) {
+		return ""
+	}
+	// A missing HEAD (no commits yet) just leaves the diff out
+	diff, err := repo.run("diff", "HEAD")
+	if err != nil {
+		slog.Debug("failed to diff working directory for transcript", "session_id", session.ID, "error", err)
+		return ""
+	}
+	return diff
+}
+
+// writeTranscript builds a session's transcript and renders it in format
+func writeTranscript(c *gin.Context, s store.ConversationStore, annotations store.AnnotationStore, sessionID, format string, opts transcript.Options) {
+	t, err := transcript.Build(c.Request.Context(), s, annotations, sessionID, opts)
+	if err != nil {
+		slog.Error("failed to build transcript", "session_id", sessionID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build transcript"})
+		return
+	}
+
+	// Rendered straight to the response; transcripts of long sessions run
+	// to megabytes
+	write, contentType := t.WriteMarkdown, "text/markdown; charset=utf-8"
+	if format == transcript.FormatHTML {
+		write, contentType = t.WriteHTML, "text/html; charset=utf-8"
+	}
+	c.Header("Content-Type", contentType)
+	c.Status(http.StatusOK)
+	if err := write(c.Writer); err != nil {
+		slog.Warn("transcript response interrupted", "session_id", sessionID, "error", err)
+	}
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/approval"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/moderation"
+	"github.com/humanlayer/humanlayer/hld/resolution"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/humanlayer/humanlayer/hld/twilio"
+)
+
+// maxInboundSMS is far above any form Twilio posts for a message
+const maxInboundSMS = 64 << 10
+
+// textReplayWindow is how long a message's SID is remembered. Twilio signs
+// the form but not when it was sent, so a captured webhook stays valid.
+const textReplayWindow = 7 * 24 * time.Hour
+
+// TwilioHandler decides approvals from SMS and WhatsApp replies that
+// Twilio forwards
+type TwilioHandler struct {
+	cfg             config.TwilioConfig
+	codes           *twilio.Codes
+	pending         twilio.Pending
+	approvalManager approval.Manager
+	justification   *Justification
+	// ledger makes each message good for one decision
+	ledger *resolution.Ledger
+}
+
+// NewTwilioHandler creates a new Twilio webhook handler
+func NewTwilioHandler(cfg config.TwilioConfig, codes *twilio.Codes, pending twilio.Pending, approvalManager approval.Manager) *TwilioHandler {
+	if cfg.AuthTokenEnv == "" {
+		cfg.AuthTokenEnv = config.DefaultTwilioAuthTokenEnv
+	}
+	return &TwilioHandler{cfg: cfg, codes: codes, pending: pending, approvalManager: approvalManager}
+}
+
+// SetJustification requires high-risk approvals by text to carry a comment
+func (h *TwilioHandler) SetJustification(j *Justification) {
+	h.justification = j
+}
+
+// SetResolutionLedger refuses replayed messages and records them
+func (h *TwilioHandler) SetResolutionLedger(ledger *resolution.Ledger) {
+	h.ledger = ledger
+}
+
+// HandleInbound acts on a message Twilio forwards. The sender gets the
+// outcome as a reply; messages from numbers that aren't configured get none.
+func (h *TwilioHandler) HandleInbound(c *gin.Context) {
+	if h.cfg.AccountSID == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Twilio is not configured"})
+		return
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxInboundSMS)
+	if err := c.Request.ParseForm(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid form"})
+		return
+	}
+	form := c.Request.PostForm
+	if !twilio.Verify(os.Getenv(h.cfg.AuthTokenEnv), h.cfg.WebhookURL, form, c.GetHeader("X-Twilio-Signature")) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Invalid signature"})
+		return
+	}
+
+	from := form.Get("From")
+	user, ok := h.cfg.Users[from]
+	if !ok {
+		slog.Info("ignored text from number that isn't configured", "from", from)
+		h.reply(c, "")
+		return
+	}
+	decision, code, comment, ok := twilio.ParseReply(form.Get("Body"))
+	if !ok {
+		h.reply(c, "Reply YES <code> or NO <code> <reason>.")
+		return
+	}
+	h.reply(c, h.decide(c, from, form.Get("MessageSid"), user, decision, code, comment))
+}
+
+// decide resolves the approval with code, returning the reply to send
+func (h *TwilioHandler) decide(c *gin.Context, from, messageSID, user, decision, code, comment string) string {
+	ctx := c.Request.Context()
+	pending, err := h.codes.Find(ctx, h.pending, code)
+	switch {
+	case errors.Is(err, twilio.ErrUnknownCode):
+		return "No approval is waiting with code " + code + "."
+	case err != nil:
+		slog.Error("failed to find approval for text reply", "code", code, "error", err)
+		return "Something went wrong; decide " + code + " in the app."
+	}
+	claim := &resolution.Claim{ApprovalID: pending.ID, Nonce: messageSID, ExpiresAt: time.Now().Add(textReplayWindow)}
+	if messageSID == "" {
+		h.ledger.Reject(ctx, resolution.ChannelTwilio, from, pending.ID, resolution.ErrInvalid)
+		return "Something went wrong; decide " + code + " in the app."
+	}
+	if err := h.ledger.Redeem(ctx, resolution.ChannelTwilio, from, claim); errors.Is(err, resolution.ErrReplayed) {
+		return "This message was already handled."
+	} else if err != nil {
+		slog.Error("failed to redeem text reply", "approval_id", pending.ID, "error", err)
+		return "Something went wrong; decide " + code + " in the app."
+	}
+
+	var highRisk *justified
+	if decision == twilio.DecisionApprove {
+		if highRisk, err = h.justification.check(ctx, pending.ID, comment); err == nil {
+			err = h.approvalManager.ApproveToolCall(ctx, pending.ID, comment, nil)
+		}
+	} else {
+		if comment == "" {
+			comment = "Denied by text from " + user
+		}
+		err = h.approvalManager.DenyToolCall(ctx, pending.ID, comment, nil)
+	}
+	switch {
+	case errors.Is(err, ErrJustificationRequired):
+		return "This is high risk; reply YES " + code + " <why it's safe>."
+	case errors.Is(err, store.ErrAlreadyDecided):
+		return code + " has already been decided."
+	case errors.Is(err, moderation.ErrBlocked):
+		return "Your reply was blocked by the content safety filter; reword it and reply again."
+	case err != nil:
+		slog.Error("failed to decide approval from text reply", "approval_id", pending.ID, "error", err)
+		return "Something went wrong; decide " + code + " in the app."
+	}
+	h.justification.record(ctx, highRisk, comment)
+	slog.Info("decided approval by text", "approval_id", pending.ID, "decision", decision, "user", user)
+	if decision == twilio.DecisionApprove {
+		return "Approved " + pending.ToolName + " (" + code + ")."
+	}
+	return "Denied " + pending.ToolName + " (" + code + ")."
+}
+
+func (h *TwilioHandler) reply(c *gin.Context, message string) {
+	c.Data(http.StatusOK, "text/xml; charset=utf-8", twilio.TwiML(message))
+}
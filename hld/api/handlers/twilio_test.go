@@ -0,0 +1,134 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/api/handlers"
+	"github.com/humanlayer/humanlayer/hld/approval"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/resolution"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/humanlayer/humanlayer/hld/twilio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePending lists the fake manager's pending approvals the way the store
+// lists them for the daemon
+type fakePending struct{ manager *approval.FakeManager }
+
+func (p fakePending) ListPendingApprovals(ctx context.Context) ([]*store.Approval, error) {
+	var pending []*store.Approval
+	for _, a := range p.manager.Approvals() {
+		if a.Status == store.ApprovalStatusLocalPending {
+			pending = append(pending, a)
+		}
+	}
+	return pending, nil
+}
+
+func TestTwilioInbound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("TEST_TWILIO_TOKEN", "secret")
+	const webhook = "https://hld.example.com/api/v1/twilio/inbound"
+
+	fake := approval.NewFakeManager()
+	codes := twilio.NewCodes(bytes.Repeat([]byte{7}, 32))
+	h := handlers.NewTwilioHandler(config.TwilioConfig{
+		AccountSID: "AC1", AuthTokenEnv: "TEST_TWILIO_TOKEN", WebhookURL: webhook,
+		Users: map[string]string{"+15550123": "ada"},
+	}, codes, fakePending{fake}, fake)
+	resolutions := store.NewInMemoryStore()
+	ledger := resolution.NewLedger(resolutions)
+	h.SetResolutionLedger(ledger)
+	router := gin.New()
+	router.POST("/api/v1/twilio/inbound", h.HandleInbound)
+
+	sent := 0
+	sendSID := func(sid, from, body, signature string) (int, string) {
+		form := url.Values{"From": {from}, "Body": {body}, "MessageSid": {sid}}
+		if signature == "" {
+			signature = twilio.Signature("secret", webhook, form)
+		}
+		req := httptest.NewRequest("POST", "/api/v1/twilio/inbound", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("X-Twilio-Signature", signature)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code, w.Body.String()
+	}
+	send := func(from, body, signature string) (int, string) {
+		sent++
+		return sendSID(fmt.Sprintf("SM%d", sent), from, body, signature)
+	}
+
+	id, err := fake.CreateApproval(context.Background(), "run-1", "Bash", json.RawMessage(`{"command":"make deploy"}`))
+	require.NoError(t, err)
+	code, err := codes.Code(id)
+	require.NoError(t, err)
+
+	t.Run("signature", func(t *testing.T) {
+		status, _ := send("+15550123", "YES "+code, "forged")
+		assert.Equal(t, http.StatusForbidden, status)
+	})
+
+	t.Run("ignored texts", func(t *testing.T) {
+		status, body := send("+15550199", "YES "+code, "")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Contains(t, body, "<Response></Response>")
+		_, body = send("+15550123", "sure", "")
+		assert.Contains(t, body, "Reply YES &lt;code&gt; or NO &lt;code&gt; &lt;reason&gt;.")
+		_, body = send("+15550123", "YES AAAAAA", "")
+		assert.Contains(t, body, "No approval is waiting with code AAAAAA.")
+
+		pending, err := fake.GetApproval(context.Background(), id)
+		require.NoError(t, err)
+		assert.Equal(t, store.ApprovalStatusLocalPending, pending.Status)
+	})
+
+	t.Run("reply decides approval", func(t *testing.T) {
+		status, body := send("+15550123", "no "+strings.ToLower(code)+" deploy from CI", "")
+		require.Equal(t, http.StatusOK, status)
+		assert.Contains(t, body, "<Message>Denied Bash ("+code+").</Message>")
+		decided, err := fake.GetApproval(context.Background(), id)
+		require.NoError(t, err)
+		assert.Equal(t, store.ApprovalStatusLocalDenied, decided.Status)
+		assert.Equal(t, "deploy from CI", decided.Comment)
+
+		_, body = send("+15550123", "YES "+code, "")
+		assert.Contains(t, body, "No approval is waiting with code "+code+".")
+	})
+
+	t.Run("replayed message", func(t *testing.T) {
+		ctx := context.Background()
+		id, err := fake.CreateApproval(ctx, "run-1", "Bash", json.RawMessage(`{"command":"make test"}`))
+		require.NoError(t, err)
+		code, err := codes.Code(id)
+		require.NoError(t, err)
+		// The same delivery was handled before, as when a captured webhook is
+		// posted again
+		require.NoError(t, ledger.Redeem(ctx, resolution.ChannelTwilio, "+15550123", &resolution.Claim{ApprovalID: id, Nonce: "SMreplayed", ExpiresAt: time.Now().Add(time.Hour)}))
+
+		_, body := sendSID("SMreplayed", "+15550123", "YES "+code, "")
+		assert.Contains(t, body, "This message was already handled.")
+		pending, err := fake.GetApproval(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, store.ApprovalStatusLocalPending, pending.Status)
+
+		rejected, err := resolutions.ListRejectedResolutions(ctx, id)
+		require.NoError(t, err)
+		require.Len(t, rejected, 1)
+		assert.Equal(t, "+15550123", rejected[0].Source)
+		assert.Equal(t, "token was already used", rejected[0].Reason)
+	})
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/humanlayer/humanlayer/hld/usage"
+)
+
+// UsageHandler reports session spend and daemon-wide cost budgets
+type UsageHandler struct {
+	store   store.ConversationStore
+	budgets *usage.Monitor
+}
+
+// NewUsageHandler creates a new usage handler
+func NewUsageHandler(conversationStore store.ConversationStore, budgets map[string]config.CostBudget) *UsageHandler {
+	return &UsageHandler{
+		store:   conversationStore,
+		budgets: usage.NewMonitor(conversationStore, nil, budgets),
+	}
+}
+
+// HandleGetReport aggregates spend for the current ?period= (day, week or month; default month)
+func (h *UsageHandler) HandleGetReport(c *gin.Context) {
+	period := c.DefaultQuery("period", config.CostBudgetPeriodMonth)
+	if _, err := usage.PeriodStart(period, time.Now()); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "period must be day, week or month"})
+		return
+	}
+
+	report, err := usage.BuildReport(c.Request.Context(), h.store, period, time.Now())
+	if err != nil {
+		slog.Error("failed to build usage report", "period", period, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build usage report"})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// HandleGetBudgets returns spend against each configured cost budget
+func (h *UsageHandler) HandleGetBudgets(c *gin.Context) {
+	statuses, err := h.budgets.Status(c.Request.Context())
+	if err != nil {
+		slog.Error("failed to get cost budget status", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get cost budget status"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": statuses, "thresholds": usage.Thresholds})
+}
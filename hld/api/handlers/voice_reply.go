@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/approval"
+	"github.com/humanlayer/humanlayer/hld/artifacts"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// maxVoiceMemoSize is the largest clip Whisper accepts
+const maxVoiceMemoSize = 25 << 20
+
+// VoiceMemoKind is the artifact kind kept voice memos are published as
+const VoiceMemoKind = "voice_memo"
+
+// Transcriber turns a recorded clip into text
+type Transcriber interface {
+	Transcribe(ctx context.Context, filename string, audio io.Reader) (string, error)
+}
+
+// VoiceReplyHandler decides approvals from a recorded voice memo, for
+// approvers answering from a phone
+type VoiceReplyHandler struct {
+	approvalManager approval.Manager
+	artifacts       *artifacts.Service
+	transcriber     Transcriber
+	justification   *Justification
+}
+
+// NewVoiceReplyHandler creates a new voice reply handler. transcriber is nil
+// when no transcription provider is configured.
+func NewVoiceReplyHandler(approvalManager approval.Manager, service *artifacts.Service, transcriber Transcriber) *VoiceReplyHandler {
+	return &VoiceReplyHandler{approvalManager: approvalManager, artifacts: service, transcriber: transcriber}
+}
+
+// SetJustification records the transcript of high-risk approvals as their
+// justification; a transcript is never empty, so none are refused
+func (h *VoiceReplyHandler) SetJustification(j *Justification) {
+	h.justification = j
+}
+
+// VoiceReply is the outcome of a voice memo reply
+type VoiceReply struct {
+	ApprovalID string `json:"approval_id"`
+	Decision   string `json:"decision"`
+	// Transcript is what was sent as the decision's comment, before any
+	// reference to the kept audio
+	Transcript string `json:"transcript"`
+	// Audio is set when keep_audio was requested
+	Audio *Artifact `json:"audio,omitempty"`
+}
+
+// HandleVoiceReply transcribes the multipart audio file and decides the
+// approval with the transcript as its comment. decision is approve or deny;
+// with keep_audio=true the clip is also kept as an artifact of the session
+// and referenced from the comment.
+func (h *VoiceReplyHandler) HandleVoiceReply(c *gin.Context) {
+	if h.transcriber == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Transcription is not configured"})
+		return
+	}
+	ctx := c.Request.Context()
+	id := c.Param("id")
+	// Room for the form fields around the clip
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxVoiceMemoSize+1<<20)
+
+	decision := c.PostForm("decision")
+	if decision != "approve" && decision != "deny" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "decision must be approve or deny"})
+		return
+	}
+	keepAudio := false
+	if raw := c.PostForm("keep_audio"); raw != "" {
+		var err error
+		if keepAudio, err = strconv.ParseBool(raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "keep_audio must be true or false"})
+			return
+		}
+	}
+	header, err := c.FormFile("audio")
+	if maxErr := (*http.MaxBytesError)(nil); errors.As(err, &maxErr) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("audio must have between 1 and %d bytes", maxVoiceMemoSize)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "audio file is required"})
+		return
+	}
+	if header.Size == 0 || header.Size > maxVoiceMemoSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("audio must have between 1 and %d bytes", maxVoiceMemoSize)})
+		return
+	}
+
+	pending, err := h.approvalManager.GetApproval(ctx, id)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	if pending.Status != store.ApprovalStatusLocalPending {
+		h.respondError(c, store.ErrAlreadyDecided)
+		return
+	}
+
+	audio, err := header.Open()
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	defer func() { _ = audio.Close() }()
+
+	transcript, err := h.transcriber.Transcribe(ctx, header.Filename, audio)
+	if err != nil {
+		slog.Error("failed to transcribe voice memo", "approval_id", id, "error", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to transcribe audio"})
+		return
+	}
+	if transcript == "" {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "No speech was recognized in the audio"})
+		return
+	}
+
+	reply := VoiceReply{ApprovalID: id, Decision: decision, Transcript: transcript}
+	comment := transcript
+	if keepAudio {
+		if _, err := audio.Seek(0, io.SeekStart); err != nil {
+			h.respondError(c, err)
+			return
+		}
+		kept, err := h.artifacts.Publish(ctx, pending.SessionID, header.Filename, VoiceMemoKind, header.Header.Get("Content-Type"), audio)
+		if errors.Is(err, artifacts.ErrInvalidName) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "audio file name can't contain a path separator"})
+			return
+		}
+		if err != nil {
+			h.respondError(c, err)
+			return
+		}
+		link, expires, err := artifactLink(h.artifacts, kept.ID)
+		if err != nil {
+			_ = h.artifacts.Delete(ctx, kept.ID)
+			h.respondError(c, err)
+			return
+		}
+		reply.Audio = &Artifact{Artifact: kept, DownloadURL: link, LinkExpiresAt: expires}
+		comment += fmt.Sprintf("\n\n(Voice memo: artifact %s)", kept.ID)
+	}
+
+	var highRisk *justified
+	if decision == "approve" {
+		if highRisk, err = h.justification.check(ctx, id, comment); err == nil {
+			err = h.approvalManager.ApproveToolCall(ctx, id, comment, nil)
+		}
+	} else {
+		err = h.approvalManager.DenyToolCall(ctx, id, comment, nil)
+	}
+	if err != nil {
+		if reply.Audio != nil {
+			_ = h.artifacts.Delete(ctx, reply.Audio.ID)
+		}
+		h.respondError(c, err)
+		return
+	}
+	h.justification.record(ctx, highRisk, comment)
+	c.JSON(http.StatusOK, reply)
+}
+
+func (h *VoiceReplyHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Approval not found"})
+	case errors.Is(err, store.ErrAlreadyDecided):
+		c.JSON(http.StatusConflict, gin.H{"error": "Approval has already been decided"})
+	default:
+		slog.Error("voice reply failed", "approval_id", c.Param("id"), "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Voice reply failed"})
+	}
+}
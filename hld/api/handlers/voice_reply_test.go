@@ -0,0 +1,112 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/api/handlers"
+	"github.com/humanlayer/humanlayer/hld/approval"
+	"github.com/humanlayer/humanlayer/hld/artifacts"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTranscriber struct {
+	text     string
+	filename string
+	audio    []byte
+}
+
+func (f *fakeTranscriber) Transcribe(ctx context.Context, filename string, audio io.Reader) (string, error) {
+	f.filename = filename
+	f.audio, _ = io.ReadAll(audio)
+	return f.text, nil
+}
+
+func voiceRequest(t *testing.T, router *gin.Engine, approvalID string, fields map[string]string, audio []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for name, value := range fields {
+		require.NoError(t, form.WriteField(name, value))
+	}
+	if audio != nil {
+		file, err := form.CreateFormFile("audio", "memo.m4a")
+		require.NoError(t, err)
+		_, _ = file.Write(audio)
+	}
+	require.NoError(t, form.Close())
+	req := httptest.NewRequest("POST", "/api/v1/approvals/"+approvalID+"/voice", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestVoiceReply(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	require.NoError(t, s.CreateSession(ctx, &store.Session{ID: "sess-1", RunID: "run-1", Status: store.SessionStatusRunning, CreatedAt: time.Now()}))
+	service := artifacts.New(s, artifacts.NewDirBlobs(t.TempDir()), nil, config.ArtifactsConfig{}, []byte("0123456789abcdef0123456789abcdef"))
+	manager := approval.NewManager(s, nil)
+	transcriber := &fakeTranscriber{text: "Looks good, ship it"}
+
+	router := gin.New()
+	h := handlers.NewVoiceReplyHandler(manager, service, transcriber)
+	router.POST("/api/v1/approvals/:id/voice", h.HandleVoiceReply)
+
+	approvalID, err := manager.CreateApproval(ctx, "run-1", "Bash", json.RawMessage(`{"command":"git push"}`))
+	require.NoError(t, err)
+
+	w := voiceRequest(t, router, approvalID, map[string]string{"decision": "maybe"}, []byte("audio"))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = voiceRequest(t, router, approvalID, map[string]string{"decision": "approve"}, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = voiceRequest(t, router, "missing", map[string]string{"decision": "approve"}, []byte("audio"))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = voiceRequest(t, router, approvalID, map[string]string{"decision": "approve", "keep_audio": "true"}, []byte("audio"))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var reply handlers.VoiceReply
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &reply))
+	assert.Equal(t, "Looks good, ship it", reply.Transcript)
+	assert.Equal(t, "memo.m4a", transcriber.filename)
+	assert.Equal(t, []byte("audio"), transcriber.audio)
+	require.NotNil(t, reply.Audio)
+	assert.Equal(t, handlers.VoiceMemoKind, reply.Audio.Kind)
+	assert.Equal(t, "sess-1", reply.Audio.SessionID)
+	assert.Contains(t, reply.Audio.DownloadURL, "/api/v1/artifacts/"+reply.Audio.ID+"/download?")
+
+	decided, err := s.GetApproval(ctx, approvalID)
+	require.NoError(t, err)
+	assert.Equal(t, store.ApprovalStatusLocalApproved, decided.Status)
+	assert.Equal(t, "Looks good, ship it\n\n(Voice memo: artifact "+reply.Audio.ID+")", decided.Comment)
+
+	// A decided approval can't be answered again
+	w = voiceRequest(t, router, approvalID, map[string]string{"decision": "deny"}, []byte("audio"))
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Silence isn't a decision
+	second, err := manager.CreateApproval(ctx, "run-1", "Bash", json.RawMessage(`{}`))
+	require.NoError(t, err)
+	transcriber.text = ""
+	w = voiceRequest(t, router, second, map[string]string{"decision": "deny"}, []byte("audio"))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	// Without a transcriber the endpoint is unavailable
+	router = gin.New()
+	router.POST("/api/v1/approvals/:id/voice", handlers.NewVoiceReplyHandler(manager, service, nil).HandleVoiceReply)
+	w = voiceRequest(t, router, second, map[string]string{"decision": "deny"}, []byte("audio"))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
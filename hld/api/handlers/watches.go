@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// maxWatchBatch caps how many sessions one request can watch or unwatch
+const maxWatchBatch = 500
+
+// WatchesHandler manages which sessions users watch
+type WatchesHandler struct {
+	store   store.ConversationStore
+	watches store.WatchStore
+}
+
+// NewWatchesHandler creates a new watches handler
+func NewWatchesHandler(conversationStore store.ConversationStore, watches store.WatchStore) *WatchesHandler {
+	return &WatchesHandler{store: conversationStore, watches: watches}
+}
+
+type watchRequest struct {
+	SessionIDs []string `json:"session_ids"`
+}
+
+// userParam returns the user a request is for. Names match the owner
+// channels in configuration, which are case-insensitive.
+func userParam(c *gin.Context) string {
+	return strings.ToLower(strings.TrimSpace(c.Param("user")))
+}
+
+// HandleListWatches lists the sessions a user watches
+func (h *WatchesHandler) HandleListWatches(c *gin.Context) {
+	watches, err := h.watches.ListWatches(c.Request.Context(), userParam(c))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": watches})
+}
+
+// HandleWatch makes a user watch sessions, returning everything they watch
+func (h *WatchesHandler) HandleWatch(c *gin.Context) {
+	var req watchRequest
+	if err := c.ShouldBindJSON(&req); err != nil || len(req.SessionIDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "session_ids is required"})
+		return
+	}
+	if len(req.SessionIDs) > maxWatchBatch {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "too many session_ids"})
+		return
+	}
+	ctx := c.Request.Context()
+	for _, id := range req.SessionIDs {
+		if _, err := h.store.GetSession(ctx, id); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found: " + id})
+			return
+		}
+	}
+	user := userParam(c)
+	if err := h.watches.WatchSessions(ctx, user, req.SessionIDs); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	watches, err := h.watches.ListWatches(ctx, user)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": watches})
+}
+
+// HandleUnwatch stops a user watching the sessions listed, or every session
+// when none are
+func (h *WatchesHandler) HandleUnwatch(c *gin.Context) {
+	var req watchRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+	}
+	removed, err := h.watches.UnwatchSessions(c.Request.Context(), userParam(c), req.SessionIDs)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"removed": removed})
+}
+
+// HandleListWatchers lists the users watching a session
+func (h *WatchesHandler) HandleListWatchers(c *gin.Context) {
+	users, err := h.watches.ListSessionWatchers(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": users})
+}
+
+func (h *WatchesHandler) respondError(c *gin.Context, err error) {
+	slog.Error("watch operation failed", "error", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Watch operation failed"})
+}
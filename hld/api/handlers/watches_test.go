@@ -0,0 +1,63 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/api/handlers"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatches(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	for _, id := range []string{"s1", "s2"} {
+		require.NoError(t, s.CreateSession(ctx, &store.Session{ID: id, RunID: "run-" + id, CreatedAt: time.Now()}))
+	}
+	h := handlers.NewWatchesHandler(s, s)
+	router := gin.New()
+	router.GET("/api/v1/users/:user/watches", h.HandleListWatches)
+	router.POST("/api/v1/users/:user/watches", h.HandleWatch)
+	router.DELETE("/api/v1/users/:user/watches", h.HandleUnwatch)
+	router.GET("/api/v1/sessions/:id/watchers", h.HandleListWatchers)
+
+	w := makeRequest(t, router, "POST", "/api/v1/users/Alice/watches", map[string]any{"session_ids": []string{"s1", "s2"}})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var watches struct {
+		Data []store.SessionWatch `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&watches))
+	require.Len(t, watches.Data, 2)
+	assert.Equal(t, "alice", watches.Data[0].User, "user names are case-insensitive")
+
+	w = makeRequest(t, router, "POST", "/api/v1/users/alice/watches", map[string]any{"session_ids": []string{"missing"}})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = makeRequest(t, router, "POST", "/api/v1/users/alice/watches", map[string]any{})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = makeRequest(t, router, "GET", "/api/v1/sessions/s1/watchers", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var watchers struct {
+		Data []string `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&watchers))
+	assert.Equal(t, []string{"alice"}, watchers.Data)
+
+	w = makeRequest(t, router, "DELETE", "/api/v1/users/alice/watches", map[string]any{"session_ids": []string{"s1"}})
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"removed":1}`, w.Body.String())
+
+	// Without a body, every watch is removed
+	w = makeRequest(t, router, "DELETE", "/api/v1/users/alice/watches", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"removed":1}`, w.Body.String())
+	w = makeRequest(t, router, "GET", "/api/v1/users/alice/watches", nil)
+	assert.JSONEq(t, `{"data":[]}`, w.Body.String())
+}
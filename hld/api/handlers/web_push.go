@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/humanlayer/humanlayer/hld/webpush"
+)
+
+// WebPushHandler manages the browsers notified of approvals
+type WebPushHandler struct {
+	store   store.WebPushStore
+	service *webpush.Service
+}
+
+// NewWebPushHandler creates a new web push handler
+func NewWebPushHandler(subs store.WebPushStore, service *webpush.Service) *WebPushHandler {
+	return &WebPushHandler{store: subs, service: service}
+}
+
+// pushSubscriptionRequest is what PushSubscription.toJSON() returns in the
+// browser
+type pushSubscriptionRequest struct {
+	Endpoint string `json:"endpoint" binding:"required"`
+	Keys     struct {
+		P256DH string `json:"p256dh" binding:"required"`
+		Auth   string `json:"auth" binding:"required"`
+	} `json:"keys"`
+}
+
+// HandleGetPublicKey returns the VAPID key to pass to
+// pushManager.subscribe as applicationServerKey
+func (h *WebPushHandler) HandleGetPublicKey(c *gin.Context) {
+	key, err := h.service.PublicKey()
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"public_key": key})
+}
+
+// HandleSubscribe records a browser's push subscription
+func (h *WebPushHandler) HandleSubscribe(c *gin.Context) {
+	var req pushSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "endpoint, keys.p256dh and keys.auth are required"})
+		return
+	}
+	sub := &store.WebPushSubscription{
+		Endpoint:  req.Endpoint,
+		P256DH:    req.Keys.P256DH,
+		Auth:      req.Keys.Auth,
+		UserAgent: c.Request.UserAgent(),
+	}
+	if err := h.service.Subscribe(c.Request.Context(), sub); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, sub)
+}
+
+// HandleListSubscriptions lists subscribed browsers
+func (h *WebPushHandler) HandleListSubscriptions(c *gin.Context) {
+	subs, err := h.store.ListWebPushSubscriptions(c.Request.Context())
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": subs})
+}
+
+// HandleUnsubscribe removes a subscription
+func (h *WebPushHandler) HandleUnsubscribe(c *gin.Context) {
+	if err := h.store.DeleteWebPushSubscription(c.Request.Context(), c.Param("id")); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// HandleTest sends a test notification to every subscription
+func (h *WebPushHandler) HandleTest(c *gin.Context) {
+	err := h.service.Notify(c.Request.Context(), webpush.Notification{
+		Title: "HumanLayer",
+		Body:  "Push notifications are working",
+		Tag:   "test",
+		URL:   "/",
+	})
+	if err != nil {
+		slog.Warn("failed to send test web push notification", "error", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *WebPushHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, webpush.ErrInvalidSubscription):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, store.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Subscription not found"})
+	default:
+		slog.Error("web push operation failed", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Web push operation failed"})
+	}
+}
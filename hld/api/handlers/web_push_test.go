@@ -0,0 +1,75 @@
+package handlers_test
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/api/handlers"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/humanlayer/humanlayer/hld/webpush"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebPushSubscriptions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := store.NewInMemoryStore()
+	service, err := webpush.New(s, s, nil, "", []byte("0123456789abcdef0123456789abcdef"))
+	require.NoError(t, err)
+	h := handlers.NewWebPushHandler(s, service)
+	router := gin.New()
+	router.GET("/api/v1/push/public-key", h.HandleGetPublicKey)
+	router.POST("/api/v1/push/subscriptions", h.HandleSubscribe)
+	router.GET("/api/v1/push/subscriptions", h.HandleListSubscriptions)
+	router.DELETE("/api/v1/push/subscriptions/:id", h.HandleUnsubscribe)
+
+	decode := func(w *httptest.ResponseRecorder, status int, v any) {
+		t.Helper()
+		require.Equal(t, status, w.Code, w.Body.String())
+		require.NoError(t, json.NewDecoder(w.Body).Decode(v))
+	}
+
+	var key struct {
+		PublicKey string `json:"public_key"`
+	}
+	decode(makeRequest(t, router, "GET", "/api/v1/push/public-key", nil), http.StatusOK, &key)
+	raw, err := base64.RawURLEncoding.DecodeString(key.PublicKey)
+	require.NoError(t, err)
+	assert.Len(t, raw, 65, "an uncompressed P-256 point")
+
+	browserKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	subscription := map[string]any{
+		"endpoint":       "https://fcm.googleapis.com/fcm/send/abc",
+		"expirationTime": nil,
+		"keys": map[string]string{
+			"p256dh": base64.RawURLEncoding.EncodeToString(browserKey.PublicKey().Bytes()),
+			"auth":   base64.RawURLEncoding.EncodeToString([]byte("0123456789abcdef")),
+		},
+	}
+	var created store.WebPushSubscription
+	decode(makeRequest(t, router, "POST", "/api/v1/push/subscriptions", subscription), http.StatusCreated, &created)
+	assert.NotEmpty(t, created.ID)
+
+	var list struct {
+		Data []store.WebPushSubscription `json:"data"`
+	}
+	decode(makeRequest(t, router, "GET", "/api/v1/push/subscriptions", nil), http.StatusOK, &list)
+	require.Len(t, list.Data, 1)
+	assert.Equal(t, "https://fcm.googleapis.com/fcm/send/abc", list.Data[0].Endpoint)
+
+	subscription["keys"] = map[string]string{"p256dh": "bm90IGEga2V5", "auth": "c2hvcnQ"}
+	w := makeRequest(t, router, "POST", "/api/v1/push/subscriptions", subscription)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = makeRequest(t, router, "DELETE", "/api/v1/push/subscriptions/"+created.ID, nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = makeRequest(t, router, "DELETE", "/api/v1/push/subscriptions/"+created.ID, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
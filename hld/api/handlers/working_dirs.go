@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/humanlayer/humanlayer/hld/workspace"
+)
+
+// WorkingDirHandler checks that session working directories exist, on this
+// machine or on the session's SSH host
+type WorkingDirHandler struct {
+	store        store.ConversationStore
+	pathMappings []config.PathMapping
+}
+
+// NewWorkingDirHandler creates a handler that resolves working dirs through pathMappings
+func NewWorkingDirHandler(conversationStore store.ConversationStore, pathMappings []config.PathMapping) *WorkingDirHandler {
+	return &WorkingDirHandler{
+		store:        conversationStore,
+		pathMappings: pathMappings,
+	}
+}
+
+// WorkingDirStatus describes one session's working directory after remapping
+type WorkingDirStatus struct {
+	SessionID   string `json:"session_id"`
+	Title       string `json:"title,omitempty"`
+	Status      string `json:"status"`
+	SSHHost     string `json:"ssh_host,omitempty"`
+	WorkingDir  string `json:"working_dir"`
+	ResolvedDir string `json:"resolved_dir"`
+	Remapped    bool   `json:"remapped"`
+	Error       string `json:"error,omitempty"`
+}
+
+// WorkingDirValidationResponse lists sessions whose working directory can't be reached
+type WorkingDirValidationResponse struct {
+	Checked     int                `json:"checked"`
+	Unreachable []WorkingDirStatus `json:"unreachable"`
+}
+
+// HandleValidate checks every session's working directory, after path
+// mappings, and reports the ones that can't be reached
+func (h *WorkingDirHandler) HandleValidate(c *gin.Context) {
+	sessions, err := h.store.ListSessions(c.Request.Context())
+	if err != nil {
+		slog.Error("failed to list sessions for working dir validation", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list sessions"})
+		return
+	}
+
+	response := WorkingDirValidationResponse{Unreachable: []WorkingDirStatus{}}
+	checked := make(map[string]string) // host and resolved dir -> error, shared by sessions in the same dir
+	for _, session := range sessions {
+		if session.WorkingDir == "" || session.Status == store.SessionStatusDiscarded {
+			continue
+		}
+		response.Checked++
+
+		// Remote directories are checked over SSH as recorded; mappings only apply locally
+		resolved := session.WorkingDir
+		if session.SSHHost == "" {
+			resolved = config.RemapPath(h.pathMappings, session.WorkingDir)
+		}
+		key := session.SSHHost + ":" + resolved
+		problem, seen := checked[key]
+		if !seen {
+			problem = checkDir(c.Request.Context(), workspace.ForSession(session), resolved)
+			checked[key] = problem
+		}
+		if problem == "" {
+			continue
+		}
+		response.Unreachable = append(response.Unreachable, WorkingDirStatus{
+			SessionID:   session.ID,
+			Title:       session.Title,
+			Status:      session.Status,
+			SSHHost:     session.SSHHost,
+			WorkingDir:  session.WorkingDir,
+			ResolvedDir: resolved,
+			Remapped:    resolved != session.WorkingDir,
+			Error:       problem,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// checkDir returns why dir can't be used as a working directory, or "" if it can
+func checkDir(ctx context.Context, host workspace.Host, dir string) string {
+	if _, local := host.(workspace.Local); local {
+		dir = expandTilde(dir)
+	}
+	isDir, err := host.IsDir(ctx, dir)
+	switch {
+	case err != nil:
+		return err.Error()
+	case !isDir:
+		return "directory does not exist"
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/api/handlers"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateWorkingDirs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	local := t.TempDir()
+
+	s := store.NewInMemoryStore()
+	for id, dir := range map[string]string{
+		"local":    local,
+		"remapped": "/Users/alice/code/" + filepath.Base(local),
+		"missing":  "/Users/alice/code/nope",
+		"other":    "/srv/elsewhere",
+		"nodir":    "",
+	} {
+		require.NoError(t, s.CreateSession(ctx, &store.Session{
+			ID: id, RunID: id, WorkingDir: dir, Status: store.SessionStatusCompleted, CreatedAt: time.Now(),
+		}))
+	}
+
+	mappings := []config.PathMapping{
+		{From: "/Users/alice", To: "/nowhere"},
+		{From: "/Users/alice/code", To: filepath.Dir(local)},
+	}
+	router := gin.New()
+	router.GET("/api/v1/working-dirs/validate", handlers.NewWorkingDirHandler(s, mappings).HandleValidate)
+
+	w := makeRequest(t, router, "GET", "/api/v1/working-dirs/validate", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp handlers.WorkingDirValidationResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.Equal(t, 4, resp.Checked)
+
+	unreachable := map[string]handlers.WorkingDirStatus{}
+	for _, status := range resp.Unreachable {
+		unreachable[status.SessionID] = status
+	}
+	require.Len(t, unreachable, 2)
+	assert.Equal(t, filepath.Join(filepath.Dir(local), "nope"), unreachable["missing"].ResolvedDir)
+	assert.True(t, unreachable["missing"].Remapped)
+	assert.Equal(t, "/srv/elsewhere", unreachable["other"].ResolvedDir)
+	assert.False(t, unreachable["other"].Remapped)
+	assert.Equal(t, "directory does not exist", unreachable["other"].Error)
+}
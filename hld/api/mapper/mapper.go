@@ -2,10 +2,17 @@ package mapper
 
 import (
 	"encoding/json"
+	"errors"
 	claudecode "github.com/humanlayer/humanlayer/claudecode-go"
 	"github.com/humanlayer/humanlayer/hld/api"
+	"github.com/humanlayer/humanlayer/hld/approval"
+	"github.com/humanlayer/humanlayer/hld/approval/infra"
+	"github.com/humanlayer/humanlayer/hld/migrationcheck"
 	"github.com/humanlayer/humanlayer/hld/rpc"
+	"github.com/humanlayer/humanlayer/hld/session"
 	"github.com/humanlayer/humanlayer/hld/store"
+	"path/filepath"
+	"time"
 )
 
 // Mapper handles conversions between API types and domain types
@@ -93,6 +100,37 @@ func (m *Mapper) SessionToAPI(s store.Session) api.Session {
 	}
 	session.Archived = &s.Archived
 	session.Reviewed = &s.Reviewed
+	session.AutoDenyAll = &s.AutoDenyAll
+	if s.AutoDenyTools != "" && s.AutoDenyTools != "[]" {
+		var tools []string
+		if err := json.Unmarshal([]byte(s.AutoDenyTools), &tools); err == nil && len(tools) > 0 {
+			session.AutoDenyTools = &tools
+		}
+	}
+	if budget := approval.ParseBudget(s.Budget); !budget.IsZero() {
+		session.Budget = m.BudgetToAPI(budget)
+	}
+	if s.Template != "" {
+		session.Template = &s.Template
+	}
+	if s.SSHHost != "" {
+		session.SshHost = &s.SSHHost
+	}
+	if s.ContainerImage != "" {
+		session.ContainerImage = &s.ContainerImage
+	}
+	if s.CompletionSummary != "" {
+		var summary api.SessionCompletionSummary
+		if err := json.Unmarshal([]byte(s.CompletionSummary), &summary); err == nil {
+			session.CompletionSummary = &summary
+		}
+	}
+	if s.RetryOf != "" {
+		session.RetryOf = &s.RetryOf
+	}
+	if s.ContextPackID != "" {
+		session.ContextPackId = &s.ContextPackID
+	}
 
 	// Proxy configuration fields
 	session.ProxyEnabled = &s.ProxyEnabled
@@ -111,6 +149,34 @@ func (m *Mapper) SessionToAPI(s store.Session) api.Session {
 	return session
 }
 
+// ProcessToAPI converts a session's process record, overlaid with its live
+// resource sample when the process is still running
+func (m *Mapper) ProcessToAPI(p store.SessionProcess, live *session.ProcessStats) api.SessionProcess {
+	process := api.SessionProcess{
+		Pid:          p.PID,
+		StartedAt:    p.StartedAt,
+		ExitedAt:     p.ExitedAt,
+		ExitCode:     p.ExitCode,
+		RssBytes:     p.RSSBytes,
+		PeakRssBytes: p.PeakRSSBytes,
+		CpuSeconds:   p.CPUSeconds,
+	}
+	if p.ExitSignal != "" {
+		process.ExitSignal = &p.ExitSignal
+	}
+	if p.StderrTail != "" {
+		process.StderrTail = &p.StderrTail
+	}
+	if live != nil {
+		process.Running = true
+		process.RssBytes = live.RSSBytes
+		process.PeakRssBytes = live.PeakRSSBytes
+		process.CpuSeconds = live.CPUSeconds
+		process.CpuPercent = &live.CPUPercent
+	}
+	return process
+}
+
 func (m *Mapper) SessionsToAPI(sessions []store.Session) []api.Session {
 	result := make([]api.Session, len(sessions))
 	for i, s := range sessions {
@@ -143,10 +209,68 @@ func (m *Mapper) ApprovalToAPI(a store.Approval) api.Approval {
 	if a.Comment != "" {
 		approval.Comment = &a.Comment
 	}
+	if a.CommentExpansion != "" {
+		approval.CommentExpansion = &a.CommentExpansion
+	}
+	if a.Constraints != nil {
+		approval.Constraints = m.ConstraintsToAPI(a.Constraints)
+	}
+	if summary := infra.Summarize(a.ToolInput); summary != nil {
+		approval.InfraSummary = InfraSummaryToAPI(summary)
+	}
+	if warnings := migrationcheck.CheckToolInput(a.ToolName, a.ToolInput, nil); len(warnings) > 0 {
+		approval.MigrationWarnings = MigrationWarningsToAPI(warnings)
+	}
 
 	return approval
 }
 
+// InfraSummaryToAPI converts a plan or diff summary
+func InfraSummaryToAPI(s *infra.Summary) *api.InfraChangeSummary {
+	resources := make([]api.InfraResourceChange, len(s.Resources))
+	for i, rc := range s.Resources {
+		resources[i] = api.InfraResourceChange{
+			Address: rc.Address,
+			Action:  api.InfraResourceChangeAction(rc.Action),
+		}
+		if rc.Detail != "" {
+			detail := rc.Detail
+			resources[i].Detail = &detail
+		}
+	}
+	return &api.InfraChangeSummary{
+		Tool:      api.InfraChangeSummaryTool(s.Tool),
+		Add:       s.Add,
+		Change:    s.Change,
+		Replace:   s.Replace,
+		Destroy:   s.Destroy,
+		Resources: resources,
+		Summary:   s.Text,
+	}
+}
+
+// MigrationWarningsToAPI converts migration check warnings
+func MigrationWarningsToAPI(warnings []migrationcheck.Warning) *[]api.MigrationWarning {
+	result := make([]api.MigrationWarning, len(warnings))
+	for i, w := range warnings {
+		result[i] = api.MigrationWarning{
+			File:     w.File,
+			Rule:     api.MigrationWarningRule(w.Rule),
+			Severity: api.MigrationWarningSeverity(w.Severity),
+			Message:  w.Message,
+		}
+		if w.Line > 0 {
+			line := w.Line
+			result[i].Line = &line
+		}
+		if w.Statement != "" {
+			statement := w.Statement
+			result[i].Statement = &statement
+		}
+	}
+	return &result
+}
+
 func (m *Mapper) ApprovalsToAPI(approvals []store.Approval) []api.Approval {
 	result := make([]api.Approval, len(approvals))
 	for i, a := range approvals {
@@ -214,6 +338,82 @@ func (m *Mapper) ConversationEventsToAPI(events []store.ConversationEvent) []api
 	return result
 }
 
+// BudgetFromAPI converts an API session budget to an approval budget
+func (m *Mapper) BudgetFromAPI(b *api.SessionBudget) approval.Budget {
+	var budget approval.Budget
+	if b == nil {
+		return budget
+	}
+	if b.MaxToolCalls != nil {
+		budget.MaxToolCalls = *b.MaxToolCalls
+	}
+	if b.MaxCostUsd != nil {
+		budget.MaxCostUSD = *b.MaxCostUsd
+	}
+	if b.MaxDurationSeconds != nil {
+		budget.MaxDurationSeconds = *b.MaxDurationSeconds
+	}
+	return budget
+}
+
+// BudgetToAPI converts an approval budget to an API session budget
+func (m *Mapper) BudgetToAPI(b approval.Budget) *api.SessionBudget {
+	budget := &api.SessionBudget{}
+	if b.MaxToolCalls > 0 {
+		budget.MaxToolCalls = &b.MaxToolCalls
+	}
+	if b.MaxCostUSD > 0 {
+		budget.MaxCostUsd = &b.MaxCostUSD
+	}
+	if b.MaxDurationSeconds > 0 {
+		budget.MaxDurationSeconds = &b.MaxDurationSeconds
+	}
+	return budget
+}
+
+// ConstraintsFromAPI converts constraints given when approving, with a
+// validity period counted from now and relative files resolved against the
+// session's workingDir. It returns nil when nothing is limited, and an error
+// for constraints that would already be broken.
+func (m *Mapper) ConstraintsFromAPI(c *api.ApprovalConstraints, now time.Time, workingDir string) (*store.ApprovalConstraints, error) {
+	if c == nil {
+		return nil, nil
+	}
+	constraints := &store.ApprovalConstraints{ExpiresAt: c.ExpiresAt}
+	if c.ValidForSeconds != nil {
+		if *c.ValidForSeconds < 1 {
+			return nil, errors.New("valid_for_seconds must be positive")
+		}
+		expiresAt := now.Add(time.Duration(*c.ValidForSeconds) * time.Second)
+		constraints.ExpiresAt = &expiresAt
+	} else if c.ExpiresAt != nil && !c.ExpiresAt.After(now) {
+		return nil, errors.New("expires_at must be in the future")
+	}
+	if c.Files != nil {
+		constraints.Files = make([]string, len(*c.Files))
+		for i, f := range *c.Files {
+			if !filepath.IsAbs(f) && workingDir != "" {
+				f = filepath.Join(workingDir, f)
+			}
+			constraints.Files[i] = f
+		}
+	}
+	if constraints.ExpiresAt == nil && len(constraints.Files) == 0 {
+		return nil, nil
+	}
+	return constraints, nil
+}
+
+// ConstraintsToAPI converts an approval's constraints
+func (m *Mapper) ConstraintsToAPI(c *store.ApprovalConstraints) *api.ApprovalConstraints {
+	constraints := &api.ApprovalConstraints{ExpiresAt: c.ExpiresAt}
+	if len(c.Files) > 0 {
+		files := c.Files
+		constraints.Files = &files
+	}
+	return constraints
+}
+
 // Other conversions
 func (m *Mapper) MCPConfigFromAPI(config *api.MCPConfig) *claudecode.MCPConfig {
 	if config == nil {
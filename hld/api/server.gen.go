@@ -74,11 +74,28 @@ const (
 
 // Defines values for EventType.
 const (
-	ApprovalResolved       EventType = "approval_resolved"
-	ConversationUpdated    EventType = "conversation_updated"
-	NewApproval            EventType = "new_approval"
-	SessionSettingsChanged EventType = "session_settings_changed"
-	SessionStatusChanged   EventType = "session_status_changed"
+	ApprovalConstraintViolated EventType = "approval_constraint_violated"
+	ApprovalHeld               EventType = "approval_held"
+	ApprovalReminder           EventType = "approval_reminder"
+	ApprovalResolved           EventType = "approval_resolved"
+	ApprovalUnheld             EventType = "approval_unheld"
+	ArtifactPublished          EventType = "artifact_published"
+	CommandOutput              EventType = "command_output"
+	CommitMessageProgress      EventType = "commit_message_progress"
+	ConversationUpdated        EventType = "conversation_updated"
+	CostBudgetThreshold        EventType = "cost_budget_threshold"
+	DailyDigest                EventType = "daily_digest"
+	DevcontainerProgress       EventType = "devcontainer_progress"
+	DiskGuardRefused           EventType = "disk_guard_refused"
+	JobCompleted               EventType = "job_completed"
+	NewApproval                EventType = "new_approval"
+	SessionBudgetExceeded      EventType = "session_budget_exceeded"
+	SessionQueued              EventType = "session_queued"
+	SessionRetryScheduled      EventType = "session_retry_scheduled"
+	SessionSettingsChanged     EventType = "session_settings_changed"
+	SessionStatusChanged       EventType = "session_status_changed"
+	SessionSummaryReady        EventType = "session_summary_ready"
+	ToolResultReported         EventType = "tool_result_reported"
 )
 
 // Defines values for HealthResponseStatus.
@@ -87,11 +104,39 @@ const (
 	Ok       HealthResponseStatus = "ok"
 )
 
+// Defines values for InfraChangeSummaryTool.
+const (
+	Kubectl   InfraChangeSummaryTool = "kubectl"
+	Terraform InfraChangeSummaryTool = "terraform"
+)
+
+// Defines values for InfraResourceChangeAction.
+const (
+	Create  InfraResourceChangeAction = "create"
+	Delete  InfraResourceChangeAction = "delete"
+	Read    InfraResourceChangeAction = "read"
+	Replace InfraResourceChangeAction = "replace"
+	Update  InfraResourceChangeAction = "update"
+)
+
 // Defines values for InterruptSessionResponseDataStatus.
 const (
 	InterruptSessionResponseDataStatusInterrupting InterruptSessionResponseDataStatus = "interrupting"
 )
 
+// Defines values for MigrationWarningRule.
+const (
+	Destructive MigrationWarningRule = "destructive"
+	Lock        MigrationWarningRule = "lock"
+	MissingDown MigrationWarningRule = "missing_down"
+)
+
+// Defines values for MigrationWarningSeverity.
+const (
+	High   MigrationWarningSeverity = "high"
+	Medium MigrationWarningSeverity = "medium"
+)
+
 // Defines values for SessionStatus.
 const (
 	SessionStatusCompleted    SessionStatus = "completed"
@@ -100,6 +145,7 @@ const (
 	SessionStatusFailed       SessionStatus = "failed"
 	SessionStatusInterrupted  SessionStatus = "interrupted"
 	SessionStatusInterrupting SessionStatus = "interrupting"
+	SessionStatusQueued       SessionStatus = "queued"
 	SessionStatusRunning      SessionStatus = "running"
 	SessionStatusStarting     SessionStatus = "starting"
 	SessionStatusWaitingInput SessionStatus = "waiting_input"
@@ -118,6 +164,19 @@ const (
 	Normal   ListSessionsParamsFilter = "normal"
 )
 
+// Defines values for ListSessionsParamsSort.
+const (
+	Cost    ListSessionsParamsSort = "cost"
+	Created ListSessionsParamsSort = "created"
+	Updated ListSessionsParamsSort = "updated"
+)
+
+// Defines values for ListSessionsParamsOrder.
+const (
+	Asc  ListSessionsParamsOrder = "asc"
+	Desc ListSessionsParamsOrder = "desc"
+)
+
 // Agent defines model for Agent.
 type Agent struct {
 	// Description Optional description from YAML frontmatter
@@ -138,15 +197,33 @@ type AgentSource string
 
 // Approval defines model for Approval.
 type Approval struct {
+	// Attachments Files the request carries, such as a screenshot of the current screen
+	Attachments *[]ApprovalAttachment `json:"attachments,omitempty"`
+
 	// Comment Approver's comment
 	Comment *string `json:"comment,omitempty"`
 
+	// CommentExpansion AI-written guidance expanding a terse denial comment. The agent
+	// receives it after the comment, marked as AI-expanded.
+	CommentExpansion *string `json:"comment_expansion,omitempty"`
+
+	// Constraints Limits on what an approval allows, given when approving. They are sent
+	// to the agent in the response metadata, and a reported tool result that
+	// doesn't keep to them is recorded as a violation.
+	Constraints *ApprovalConstraints `json:"constraints,omitempty"`
+
 	// CreatedAt Creation timestamp
 	CreatedAt time.Time `json:"created_at"`
 
 	// Id Unique approval identifier
 	Id string `json:"id"`
 
+	// InfraSummary Summary of a terraform plan or kubectl diff found in the tool input
+	InfraSummary *InfraChangeSummary `json:"infra_summary,omitempty"`
+
+	// MigrationWarnings Risky statements in a database migration or SQL the tool call would write or run
+	MigrationWarnings *[]MigrationWarning `json:"migration_warnings,omitempty"`
+
 	// RespondedAt Response timestamp
 	RespondedAt *time.Time `json:"responded_at"`
 
@@ -166,6 +243,45 @@ type Approval struct {
 	ToolName string `json:"tool_name"`
 }
 
+// ApprovalAttachment defines model for ApprovalAttachment.
+type ApprovalAttachment struct {
+	ContentType string `json:"content_type"`
+
+	// DownloadUrl Signed link to the content, valid for the configured artifacts link_ttl
+	DownloadUrl string `json:"download_url"`
+
+	// Id Artifact ID
+	Id   string `json:"id"`
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+}
+
+// ApprovalAttachmentUpload defines model for ApprovalAttachmentUpload.
+type ApprovalAttachmentUpload struct {
+	// ContentType image/png, image/jpeg, image/gif, image/webp or text/plain
+	ContentType string `json:"content_type"`
+
+	// Data Base64-encoded content, up to 10 MiB decoded
+	Data []byte `json:"data"`
+
+	// Name File name, without a path
+	Name string `json:"name"`
+}
+
+// ApprovalConstraints Limits on what an approval allows, given when approving. They are sent
+// to the agent in the response metadata, and a reported tool result that
+// doesn't keep to them is recorded as a violation.
+type ApprovalConstraints struct {
+	// ExpiresAt When the approval stops being valid; must be in the future
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	// Files The only files the tool call may read or write. Relative paths are resolved against the session's working directory.
+	Files *[]string `json:"files,omitempty"`
+
+	// ValidForSeconds How long the approval is valid from the decision. Only read when deciding.
+	ValidForSeconds *int `json:"valid_for_seconds,omitempty"`
+}
+
 // ApprovalResponse defines model for ApprovalResponse.
 type ApprovalResponse struct {
 	Data Approval `json:"data"`
@@ -342,6 +458,10 @@ type ConversationResponse struct {
 
 // CreateApprovalRequest defines model for CreateApprovalRequest.
 type CreateApprovalRequest struct {
+	// Attachments Files showing the approver the current state, such as a screenshot
+	// or terminal capture. Stored as session artifacts.
+	Attachments *[]ApprovalAttachmentUpload `json:"attachments,omitempty"`
+
 	// RunId Run ID for the approval
 	RunId string `json:"run_id"`
 
@@ -374,6 +494,22 @@ type CreateSessionRequest struct {
 	// AutoAcceptEdits Enable auto-accept for edit tools
 	AutoAcceptEdits *bool `json:"auto_accept_edits,omitempty"`
 
+	// AutoDenyAll Launch an observation-only session that denies every approval request
+	AutoDenyAll *bool `json:"auto_deny_all,omitempty"`
+
+	// AutoDenyTools Tool names to deny automatically; entries ending in "*" match by prefix
+	AutoDenyTools *[]string `json:"auto_deny_tools,omitempty"`
+
+	// Budget Resource limits for a session and the sessions it continues from.
+	// Once any limit is exceeded, further approvals are denied. Zero or unset means unlimited.
+	Budget *SessionBudget `json:"budget,omitempty"`
+
+	// Container Run the agent in a container using the template's configured image
+	Container *bool `json:"container,omitempty"`
+
+	// ContextPackId Context pack from POST /context-packs to add to the system prompt
+	ContextPackId *string `json:"context_pack_id,omitempty"`
+
 	// CreateDirectoryIfNotExists Create the working directory if it does not exist
 	CreateDirectoryIfNotExists *bool `json:"createDirectoryIfNotExists,omitempty"`
 
@@ -386,12 +522,18 @@ type CreateSessionRequest struct {
 	// DangerouslySkipPermissionsTimeout Optional default timeout in milliseconds for dangerously skip permissions
 	DangerouslySkipPermissionsTimeout *int64 `json:"dangerously_skip_permissions_timeout"`
 
+	// Devcontainer Build the working directory's devcontainer.json and run the agent in it. Setup progress is reported as devcontainer_progress events.
+	Devcontainer *bool `json:"devcontainer,omitempty"`
+
 	// DisallowedTools Blacklist of disallowed tools
 	DisallowedTools *[]string `json:"disallowed_tools,omitempty"`
 
 	// Draft Create session in draft state without launching Claude
 	Draft *bool `json:"draft,omitempty"`
 
+	// IncludeDecisions Add the decisions recorded for the working directory's repository to the system prompt. Always on when decision_log.inject_context is set.
+	IncludeDecisions *bool `json:"include_decisions,omitempty"`
+
 	// MaxTurns Maximum conversation turns
 	MaxTurns  *int       `json:"max_turns,omitempty"`
 	McpConfig *MCPConfig `json:"mcp_config,omitempty"`
@@ -402,6 +544,9 @@ type CreateSessionRequest struct {
 	// PermissionPromptTool MCP tool for permission prompts
 	PermissionPromptTool *string `json:"permission_prompt_tool,omitempty"`
 
+	// Priority Launch queue priority. When max_concurrent_sessions are running the session waits as "queued"; higher priorities start first.
+	Priority *int `json:"priority,omitempty"`
+
 	// ProxyApiKey API key for proxy authentication
 	ProxyApiKey *string `json:"proxy_api_key,omitempty"`
 
@@ -420,9 +565,15 @@ type CreateSessionRequest struct {
 	// SystemPrompt Override system prompt
 	SystemPrompt *string `json:"system_prompt,omitempty"`
 
+	// Template Label of the saved template the session was launched from
+	Template *string `json:"template,omitempty"`
+
 	// Title Optional title for the session
 	Title *string `json:"title,omitempty"`
 
+	// UseWorktree Run the session in its own git worktree of the working directory's repository, on a new branch from HEAD. Git endpoints then act on the worktree, and it is removed once the session is archived.
+	UseWorktree *bool `json:"use_worktree,omitempty"`
+
 	// Verbose Enable verbose output
 	Verbose *bool `json:"verbose,omitempty"`
 
@@ -467,9 +618,18 @@ type DebugInfoResponse struct {
 
 // DecideApprovalRequest defines model for DecideApprovalRequest.
 type DecideApprovalRequest struct {
-	// Comment Optional comment (required for deny)
+	// CannedResponseId Canned response whose text is the comment, followed by comment
+	// if one is also given. Satisfies the comment a denial needs.
+	CannedResponseId *string `json:"canned_response_id,omitempty"`
+
+	// Comment Optional comment (required for deny, and for approving a tool call at or above approval_justification_risk)
 	Comment *string `json:"comment,omitempty"`
 
+	// Constraints Limits on what an approval allows, given when approving. They are sent
+	// to the agent in the response metadata, and a reported tool result that
+	// doesn't keep to them is recorded as a violation.
+	Constraints *ApprovalConstraints `json:"constraints,omitempty"`
+
 	// Decision Approval decision
 	Decision DecideApprovalRequestDecision `json:"decision"`
 
@@ -622,6 +782,45 @@ type HealthResponse struct {
 // HealthResponseStatus defines model for HealthResponse.Status.
 type HealthResponseStatus string
 
+// InfraChangeSummary Summary of a terraform plan or kubectl diff found in the tool input
+type InfraChangeSummary struct {
+	// Add Resources to create
+	Add int `json:"add"`
+
+	// Change Resources to update in place
+	Change int `json:"change"`
+
+	// Destroy Resources to delete
+	Destroy int `json:"destroy"`
+
+	// Replace Resources to destroy and recreate
+	Replace   int                   `json:"replace"`
+	Resources []InfraResourceChange `json:"resources"`
+
+	// Summary One-line description of the counts
+	Summary string `json:"summary"`
+
+	// Tool Tool that produced the plan or diff
+	Tool InfraChangeSummaryTool `json:"tool"`
+}
+
+// InfraChangeSummaryTool Tool that produced the plan or diff
+type InfraChangeSummaryTool string
+
+// InfraResourceChange defines model for InfraResourceChange.
+type InfraResourceChange struct {
+	Action InfraResourceChangeAction `json:"action"`
+
+	// Address Terraform resource address, or Kubernetes kind and namespace/name
+	Address string `json:"address"`
+
+	// Detail Extra detail, such as diff line counts for kubectl
+	Detail *string `json:"detail,omitempty"`
+}
+
+// InfraResourceChangeAction defines model for InfraResourceChange.Action.
+type InfraResourceChangeAction string
+
 // InterruptSessionResponse defines model for InterruptSessionResponse.
 type InterruptSessionResponse struct {
 	Data struct {
@@ -661,6 +860,27 @@ type MCPServer struct {
 	Url *string `json:"url,omitempty"`
 }
 
+// MigrationWarning defines model for MigrationWarning.
+type MigrationWarning struct {
+	// File Migration file, empty for SQL passed to a database client
+	File string `json:"file"`
+
+	// Line Line the statement starts on
+	Line     *int                     `json:"line,omitempty"`
+	Message  string                   `json:"message"`
+	Rule     MigrationWarningRule     `json:"rule"`
+	Severity MigrationWarningSeverity `json:"severity"`
+
+	// Statement The offending statement, truncated
+	Statement *string `json:"statement,omitempty"`
+}
+
+// MigrationWarningRule defines model for MigrationWarning.Rule.
+type MigrationWarningRule string
+
+// MigrationWarningSeverity defines model for MigrationWarning.Severity.
+type MigrationWarningSeverity string
+
 // RecentPath defines model for RecentPath.
 type RecentPath struct {
 	// LastUsed Last time this path was used
@@ -704,6 +924,16 @@ type Session struct {
 	// AutoAcceptEdits Whether edit tools are auto-accepted
 	AutoAcceptEdits *bool `json:"auto_accept_edits,omitempty"`
 
+	// AutoDenyAll Whether every approval request is denied automatically (observation-only session)
+	AutoDenyAll *bool `json:"auto_deny_all,omitempty"`
+
+	// AutoDenyTools Tool names denied automatically; entries ending in "*" match by prefix
+	AutoDenyTools *[]string `json:"auto_deny_tools,omitempty"`
+
+	// Budget Resource limits for a session and the sessions it continues from.
+	// Once any limit is exceeded, further approvals are denied. Zero or unset means unlimited.
+	Budget *SessionBudget `json:"budget,omitempty"`
+
 	// CacheCreationInputTokens Number of cache creation input tokens
 	CacheCreationInputTokens *int `json:"cache_creation_input_tokens"`
 
@@ -716,9 +946,18 @@ type Session struct {
 	// CompletedAt Session completion timestamp
 	CompletedAt *time.Time `json:"completed_at"`
 
+	// CompletionSummary Structured summary generated when the session finished
+	CompletionSummary *SessionCompletionSummary `json:"completion_summary,omitempty"`
+
+	// ContainerImage Container image the agent runs in; absent when it runs on the host
+	ContainerImage *string `json:"container_image,omitempty"`
+
 	// ContextLimit Context window limit for the model
 	ContextLimit *int `json:"context_limit"`
 
+	// ContextPackId ID of the context pack attached when the session was launched
+	ContextPackId *string `json:"context_pack_id,omitempty"`
+
 	// CostUsd Total cost in USD
 	CostUsd *float32 `json:"cost_usd"`
 
@@ -764,6 +1003,9 @@ type Session struct {
 	// ParentSessionId Parent session ID if this is a forked session
 	ParentSessionId *string `json:"parent_session_id,omitempty"`
 
+	// Process The Claude process the session ran in, only returned by GET /sessions/{id}. Resource figures are sampled every few seconds while it runs.
+	Process *SessionProcess `json:"process,omitempty"`
+
 	// ProxyBaseUrl Base URL of the proxy server
 	ProxyBaseUrl *string `json:"proxy_base_url,omitempty"`
 
@@ -776,18 +1018,27 @@ type Session struct {
 	// Query Initial query that started the session
 	Query string `json:"query"`
 
+	// RetryOf ID of the failed session this session automatically retries
+	RetryOf *string `json:"retry_of,omitempty"`
+
 	// Reviewed Whether session has been reviewed/checked off
 	Reviewed *bool `json:"reviewed,omitempty"`
 
 	// RunId Unique run identifier
 	RunId string `json:"run_id"`
 
+	// SshHost SSH destination holding the working directory; git commands and file reads run there
+	SshHost *string `json:"ssh_host,omitempty"`
+
 	// Status Current status of the session
 	Status SessionStatus `json:"status"`
 
 	// Summary AI-generated summary of the session
 	Summary *string `json:"summary,omitempty"`
 
+	// Template Label of the saved template the session was launched from
+	Template *string `json:"template,omitempty"`
+
 	// Title User-editable session title
 	Title *string `json:"title,omitempty"`
 
@@ -795,6 +1046,62 @@ type Session struct {
 	WorkingDir *string `json:"working_dir,omitempty"`
 }
 
+// SessionBudget Resource limits for a session and the sessions it continues from.
+// Once any limit is exceeded, further approvals are denied. Zero or unset means unlimited.
+type SessionBudget struct {
+	// MaxCostUsd Maximum cost in USD across completed runs
+	MaxCostUsd *float64 `json:"max_cost_usd,omitempty"`
+
+	// MaxDurationSeconds Maximum run time in seconds
+	MaxDurationSeconds *int `json:"max_duration_seconds,omitempty"`
+
+	// MaxToolCalls Maximum number of tool calls
+	MaxToolCalls *int `json:"max_tool_calls,omitempty"`
+}
+
+// SessionCompletionSummary Structured summary generated when the session finished
+type SessionCompletionSummary struct {
+	// Asked What the session was asked to do
+	Asked string `json:"asked"`
+
+	// Changed Changes the session made
+	Changed []string `json:"changed"`
+
+	// FollowUps Suggested next steps
+	FollowUps []string `json:"follow_ups"`
+
+	// OpenQuestions Questions left unanswered
+	OpenQuestions []string `json:"open_questions"`
+}
+
+// SessionProcess The Claude process the session ran in, only returned by GET /sessions/{id}. Resource figures are sampled every few seconds while it runs.
+type SessionProcess struct {
+	// CpuPercent Share of one core used since the previous sample, while running
+	CpuPercent *float64 `json:"cpu_percent,omitempty"`
+
+	// CpuSeconds CPU time used
+	CpuSeconds float64 `json:"cpu_seconds"`
+
+	// ExitCode Exit code; -1 when the process was killed by a signal
+	ExitCode *int `json:"exit_code,omitempty"`
+
+	// ExitSignal Signal that killed the process
+	ExitSignal *string    `json:"exit_signal,omitempty"`
+	ExitedAt   *time.Time `json:"exited_at,omitempty"`
+
+	// PeakRssBytes Highest resident memory sampled
+	PeakRssBytes int64 `json:"peak_rss_bytes"`
+	Pid          int   `json:"pid"`
+
+	// RssBytes Resident memory at the last sample
+	RssBytes  int64     `json:"rss_bytes"`
+	Running   bool      `json:"running"`
+	StartedAt time.Time `json:"started_at"`
+
+	// StderrTail The last 4 KiB the process wrote to stderr
+	StderrTail *string `json:"stderr_tail,omitempty"`
+}
+
 // SessionResponse defines model for SessionResponse.
 type SessionResponse struct {
 	Data Session `json:"data"`
@@ -822,6 +1129,9 @@ type SessionsResponse struct {
 		Normal *int `json:"normal,omitempty"`
 	} `json:"counts,omitempty"`
 	Data []Session `json:"data"`
+
+	// NextCursor Pass as cursor to fetch the next page; absent on the last page
+	NextCursor *string `json:"next_cursor,omitempty"`
 }
 
 // SlashCommand defines model for SlashCommand.
@@ -869,6 +1179,16 @@ type UpdateSessionRequest struct {
 	// AutoAcceptEdits Enable/disable auto-accept for edit tools
 	AutoAcceptEdits *bool `json:"auto_accept_edits,omitempty"`
 
+	// AutoDenyAll Enable or disable denying every approval request
+	AutoDenyAll *bool `json:"auto_deny_all,omitempty"`
+
+	// AutoDenyTools Replace the list of tools denied automatically (empty list clears it)
+	AutoDenyTools *[]string `json:"auto_deny_tools,omitempty"`
+
+	// Budget Resource limits for a session and the sessions it continues from.
+	// Once any limit is exceeded, further approvals are denied. Zero or unset means unlimited.
+	Budget *SessionBudget `json:"budget,omitempty"`
+
 	// DangerouslySkipPermissions Enable or disable dangerously skip permissions mode
 	DangerouslySkipPermissions *bool `json:"dangerously_skip_permissions,omitempty"`
 
@@ -899,6 +1219,9 @@ type UpdateSessionRequest struct {
 	// Reviewed Mark session as reviewed/checked off
 	Reviewed *bool `json:"reviewed,omitempty"`
 
+	// SshHost SSH destination (host, user@host or ssh config alias) holding the working directory; empty makes it local
+	SshHost *string `json:"ssh_host,omitempty"`
+
 	// Status Current status of the session
 	Status *SessionStatus `json:"status,omitempty"`
 
@@ -1004,11 +1327,40 @@ type ListSessionsParams struct {
 
 	// Filter Filter sessions by type
 	Filter *ListSessionsParamsFilter `form:"filter,omitempty" json:"filter,omitempty"`
+
+	// Status Only sessions with one of these statuses
+	Status *[]SessionStatus `form:"status,omitempty" json:"status,omitempty"`
+
+	// Label Only sessions launched from the template with this label
+	Label *string `form:"label,omitempty" json:"label,omitempty"`
+
+	// Sort Order of the sessions: by creation time, last activity, or cost
+	Sort *ListSessionsParamsSort `form:"sort,omitempty" json:"sort,omitempty"`
+
+	// Order Sort direction
+	Order *ListSessionsParamsOrder `form:"order,omitempty" json:"order,omitempty"`
+
+	// Limit Maximum sessions per page. When omitted, every session is returned.
+	// Counts cover every session, whatever the filters and page.
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Cursor The next_cursor of the previous page
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+
+	// Fields Comma-separated session fields to return, e.g. id,title,status.
+	// The id is always included. When omitted, every field is returned.
+	Fields *string `form:"fields,omitempty" json:"fields,omitempty"`
 }
 
 // ListSessionsParamsFilter defines parameters for ListSessions.
 type ListSessionsParamsFilter string
 
+// ListSessionsParamsSort defines parameters for ListSessions.
+type ListSessionsParamsSort string
+
+// ListSessionsParamsOrder defines parameters for ListSessions.
+type ListSessionsParamsOrder string
+
 // SearchSessionsParams defines parameters for SearchSessions.
 type SearchSessionsParams struct {
 	// Query Search query for matching against title, summary, or query fields (uses SQL LIKE)
@@ -1418,6 +1770,62 @@ func (siw *ServerInterfaceWrapper) ListSessions(c *gin.Context) {
 		return
 	}
 
+	// ------------- Optional query parameter "status" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "status", c.Request.URL.Query(), &params.Status)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter status: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "label" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "label", c.Request.URL.Query(), &params.Label)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter label: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "sort" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sort", c.Request.URL.Query(), &params.Sort)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter sort: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "order" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "order", c.Request.URL.Query(), &params.Order)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter order: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", c.Request.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter limit: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", c.Request.URL.Query(), &params.Cursor)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter cursor: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "fields" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "fields", c.Request.URL.Query(), &params.Fields)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter fields: %w", err), http.StatusBadRequest)
+		return
+	}
+
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 		if c.IsAborted() {
@@ -2281,6 +2689,15 @@ func (response ListSessions200JSONResponse) VisitListSessionsResponse(w http.Res
 	return json.NewEncoder(w).Encode(response)
 }
 
+type ListSessions400JSONResponse struct{ BadRequestJSONResponse }
+
+func (response ListSessions400JSONResponse) VisitListSessionsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
 type ListSessions500JSONResponse struct{ InternalErrorJSONResponse }
 
 func (response ListSessions500JSONResponse) VisitListSessionsResponse(w http.ResponseWriter) error {
@@ -4104,161 +4521,227 @@ func (sh *strictHandler) ValidateDirectory(ctx *gin.Context) {
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/+R9a3MbubLYX0ExqVqpihQlWV7vUSpV8VreXSWy19fy3pPkyMUCZ0ASRzPALICRzHX5",
-	"/vYUGsAMMIN5UE+fXH+yOHg0Go1Gv/F1kvC84IwwJSenXycFFjgnigj4CxeF4Dc4O0/1XymRiaCFopxN",
-	"Tiev7Td0fjaZTsgXnBcZmZxCn8WX7V+vfvrbZDqhummB1WYynTCc6wY0nUwngvxZUkHSyakSJZlOZLIh",
-	"OdazqG2hW0klKFtPvn2bTiSRknIWA+LSfGrCoHss8DJJyero+MXJyx8fBJJvurEsOJMEsPMzTj+SP0si",
-	"lf4r4UwRpizaMppgDeP8n1ID+rUG7uuECMGF6ZLqCX67OJu9ODyaTCc5kRKv9W/vqJSUrZGDDq0oyVL0",
-	"w58lEdsfDFoqQP+rIKvJ6eS/zOu9nJuvcv5WT/bRgm0WEaLwZ5zCLHoZ36aTc6aIYDh7WwN5n3WdwLpS",
-	"ojDNAGlK4IQsaKopZZkcHb/Qk9brdtMjScQNEciM+YDL7ZhgOnnP1S+8ZOn913x0eBzspSNSxhVawRQP",
-	"uJ6PRPJSJCQ6OmD89doupRC8IEJRQ73BMM1z9Tv8B2fI+xmtBM/R/3n97kL/j6kcK0XEZNo8J3rpTHf4",
-	"RL6o9tD6V6Q4KiVBKy6QbSyDA/w/sAZ6ppG6xJLMMp5gxaOTmbPc4k66P9LfOsGuZxszjcFye6K/b4ja",
-	"EIEAYESlmU4PlCEu0DrjS41GKkiiuNjqeVmZT07/MYE2k+nENJl8nkZYX82c/mEWGiK3AqvuzJf/JAmc",
-	"ZMeg21uf8Dy3NBHj6UT8IJFr4+PJfk7RLVUblOASukWQlQiCFUkXODLHG/1Nk5OiOZEK58VkOllxkevG",
-	"kxQrMtNfYsPSyA3wB6N/lgS5mwrRVONnRRtbDLeSZTiRkQ1fTztAdudvGGRWZhle6hnNZdKeqGSL2DJe",
-	"S8kTqpGGRNm6z3Sv6kptk6bhL0Pjyp67MiUrc0u2B1dYlXKITTlauzStv00nivNsQVlRGi6aptRwlA8e",
-	"JRocNdgD5xmCfsiTRaY+z9WkiTWjnogczcQKzVVezJW9wFrnACCJcwmYzF5++rZ1VBQgiHwhSanIwk07",
-	"dE6NVGH2OdicCpnBAfEBDNDWd6arG6HN1rHCY3erBTp07pv3sqKGxqEuhdD8zywQ8RVSGxKg0zK9grBU",
-	"I21qZUuSgnjAKEkjHLCeWA6vmCqSy/FLrybDQuDteFT8XGbXr0WyoTfEk/5CkLD5HjmPn0RJ9O1nW0zR",
-	"CmcSfimZ/a0msCXnGcEsPOOyUwqW3sBzf7iKlv9hTrthgvBffeo/T2vcte9yys7Nx6MBjPkgTmsUDOJw",
-	"aF/DX1eYZiRd2Ml6kbHBCpnmgN9CM+oINjRX7UVBuOrpRJZJQqQMJMGA3Vf71sSQ7dhGyS7Ed0Yyorpp",
-	"bzSlFETkWJ+ObItSGBPt5aVUaEkcFaX7z0I9QyvfjWLM2tIhSrklgiC7Q6syq5CS3hMFTeq5MwG7PdKC",
-	"vtsfLWJykD9BEdn/Lsh7WqH8foT+kUjFBTkTeKXk3egd+jrJB6hemEGNmJ5SmWCRauHI3czfD7U3lv9E",
-	"bNLi51+cT77hbEXX3UhLMlymZIFvMLXyepde9wZaasWuaoywAvEmgUlKQVJk7Urte9tOlBJFEi3wQcO2",
-	"lF4qnmNFE2z4jmns5tZ90F6OtyilqxURhnbr2fejKpiZOD6fFdeyrb8Gb7ZBGdcffdrGZseWKMpKYgmv",
-	"W3bKMn5L0oWWhCN0+9p8RvAZZVRqCXo8TeJCS6ALuZWK5ItC8LyI68GEwXEwDZFtGMNzKRXPF5RJJcpE",
-	"xQ/bG2iEgkaRsVIqB1Z/VrW4KwJy/GWhShGD8h3+ounhhghpNXRoB3yN5lp+r9kaZYqsCRjO8qRYGDIa",
-	"Er7fvflgDqbupsUParigwS6sOQLVmw+wVjAW1Z2iCATraHuI9+QWwSe9o4mlQzBiBIree36LcJqaqxRt",
-	"MEszrRQqDqfdDBhVkfuJ6fcbIgRNyRAtNY6YWcuok7Tb1WBPa2g18Ixh9edFsqFZGltygTX/6BwDOps2",
-	"XQaXst1L/wYzdpki+maDjtHJOq9eX01vIyW2yHtdSNW5ensTNcg6bXnIjoMDx8ugxakaVnbo7pUjxyrv",
-	"+pzBgdO3kRypu4MJjWdW4RsEagca7CAgz0Tf4BfG7o5cg0Hz5DjbI9GbtjA/t5T6bUEQX4XMEzp42HP+",
-	"AGvj0ch1/xdElhlYc4FD6J83lF3rmT93mkErbB0dvzjxzJGUqR9PJjFGTeVCM2VPG1phPe8p2CCmHQJQ",
-	"RQpogyUSJCGgeFQwt2Uee25gaaUkUXr+AG3M4KUk6PwM6I4RqUncUV6bbfCYrOa2XH9Fe8apYH6BTZD7",
-	"3jaUEizDWEoqFWYe1j9HWc6fJWExu/+l/YJYmS+JQJQF2+9fLC9jm9HLzLoN1cY+mHaYMim74cZXpRG6",
-	"V53kGg0dA7KiVAvn3goH/p+Xv79Hpj3Y9Wr7bDU+EPPgJD0mWPDT7DicIcBFJx+wtl3dqI8X+GOtuOjG",
-	"LQB1fobUhko3LgVuOc4iHBqCHV0FjCXgTEO3yAMZRNsX050to+DZIbWJukPA73KBfAS/h7l+GsbjkY6Q",
-	"h/Y57OJKeK9J2Nq91WO4FSpRZQd3QXNHdhMUewUSM3RTGmk43Bi5HSOS+RPdQ8QCiAbVy4oqFs4pG3OI",
-	"T15X7ZDXzinJCWYIO2uXZyj5j/nBpswxy/CWiHnG1/r7/AbD/+f5FhfFbjaUAX3w7xuqiNYBNekFmmEI",
-	"lyA4XaxopmnmVlBFzB+fH151du59PF6FxqXiC43NQi1ISpUcFk7eMmOIKRWfmZ7AN3TvavkRYwxQyJnz",
-	"xZ+v3nP19guVY2Y01AXH+5YLLZvVTn1EV4gqlHIiIQyDfDFaeQSCO1oLYHWG9qKGA8zWRPBSZtuFvKbF",
-	"wteTB5d2gUuWbCr/MDj3vRGRHtHXvBEB7KfRFfaBstDCNS9VANLfDvW/aXcACrRDtquWs3KaZVSShLPU",
-	"IKYP2ElENO5QTzzpbNgS83OGk2t38tKGWSY8fE1Ov9OpSwVeqdHk6faQMpQa07fSP+st1cjLYKc17TZp",
-	"ydvBfgsRzcs8biWqFZLDRzIZ5TwlMQuR/tkPKQJzjcGEJ/nzAgz8kjNG9OncYHpdRqX+e5qmLKuLKjCF",
-	"4F+2C1zQxTWJWKpefzhH12RrBtRNNYvbEKZsCFr3kEssyaIUESh/xpKgPz5eeINKIm5oEhj5JxulCnk6",
-	"n/OCMMFLRcQBpnNc0PnNUfe0jhWM5dhmfj2+pkKzWVR6uxVRJ2Ei2PsFt7a0LiKoo3+81drZgtXqVWI6",
-	"XxdqdrKDJfGcUUVxZq2JAVOux/6NZAXKCYKLFmH0Yas2nFkDInheBdeiA3pz+e9I38PyEa2K04miKqY0",
-	"VxwWvsfOTbUgDecHA7PetctOS+gNEUsuyWhqsO0RL5UWYWO7by9bLaxFxJ/WTdy3jPmG52ReSiLmheAg",
-	"Nt7DCBtKm7tJ1l0qkBOqO0LAGLkdZRqND9oX/zVSUI/ZTu8usJ+RZbk+Zyve56ej1bXZXtjFObIffT+W",
-	"JgHNmU2AbxhXusm20ejODEulWYxmHZGZLrBUyHxO6uBFp+7pBWr2i6yAXU93fHh8Mjs8mh29/HR0ePri",
-	"8PTw8P+OjnaMu+4+YLVxLonLf7vQHKZ7fo/ifb0kxSTn7CBdRkmJ/hUzd9G/4uvVosZyq0hDAjj56eWr",
-	"H0dZJbWEIrv19a9jxmg4yRx8emgqFU0aAYRO35ST06OX1gIjJ6fHL15VJ0lOTk+Oo9GEmnEtEl7GbE7v",
-	"jS1Q40k3kxo5PsYGrIKNg2O9q7Ah4cQOa9PggMTPWELTYZtMZ0RwdUvYFmivzkjQkjdh2yDoZHLB+bVE",
-	"Eq9IddORqAspJQmV0eDzyiFRNamFOOt5MI6Hbdw8nuO1cU1HJNgLCMwGwoUWGkjoIBFWCicbEwUBQomb",
-	"/uCKncGJQbc0y5DWpafoBmdUH98p0uyHsISnoB1aEdSIBQdXzInML6tpjKJwcMV6/bY5/mJjSV4O2eMc",
-	"lsbs/273VJXd0Li9wUHqbOx0ZcNHotzk+WJAKj3fZXZ0r753nZDZ4ZN4JW0sGFcLk3MRzYKwCSDNYX/T",
-	"nHimyQiEIOJjM5iobWgITQzI4++M3M46pZquy+ST5k3V4BBnAuFALUtG9EoZmNJuknQB/zFpOtX3KbFB",
-	"SDUkicsRAFO73evpjhRkNnXqOd4sQ20BFqMe2PszyFuKscuYClKTC9ojB+uDKTLZQEchh6xThCI8scqT",
-	"Gm+99iyVxELAlMkOaa3q/jTZTmYajBUy58cN1onsEcdzMFPKblicFKIzx33xjh2O3wUYaCYLkmghEW78",
-	"qAehyiBpk88NeER3zopxPule5OixP+mGTdRYx5M/bSdHrUfpdIFbbbTp/GbkduF5QSqDfxU0UKswJgph",
-	"kWwwW8MH39i0MFHcQXuitHZf94jJBL/QjLzDKtlEtprKIsPbD1EG+ZFkWNEbG4MHEo9pruUg+0lxtKJC",
-	"KiQJFsnGNKUrZDMHlxkJz78UyRyCi4iQ81X511/bS+h4sObRpCdZXWQd6QR0ZeQVKhGumahLLdBAOyND",
-	"BYRVymPGP6VloHOWki8xt8ibDRY4UUSggktqbNN8hWw3axdJXKPQEHr8YvriaPrix+mLV9MXP01f/C1i",
-	"CPWE+qYltCN0cil5Viq7Q4pXoICMp9fOs7SRDDb/Q2rcp+TGGQLmO26KTLiIGaH03OjPEmdUbRE0Qnsb",
-	"ut4QoXdnSZQiYZD2T6PVAJ9OHQCt/QrJJXaG9Um4ZLiQGx7VAzq86bqbc6MjrJC0Q6AurnSXGBu9ZYth",
-	"tbdPzXX7mWPKDortvUIoQChJnPXE4cyfuApxGWM8cfP666zjmAZ9/7/URKk3ozsgHg777yzbDpvhPhJV",
-	"CoY4y7aGR0wR+ZJkZUp8p2fULpfRnIZ+ieOWE8fpPqxSi03chA3E13MDCX+xrgLwA/V7DjrUurNAiIXx",
-	"LTe+pWpDA1Wrjw9E5fhKE7OOja4Y/06rMWyddz0oIkLLpOE80Mwg5IKwtT4Gxy9/hCnd30cduaskUb9S",
-	"RdesYkt2U2Kiyi800wycl8ps+tywSGlYp1Y4DtZuMAdujAiitlK3ReNIuEviy4nCYzIZzWDvXGuDDU1h",
-	"HbzZ3gv1kiUX+nQvt0iQjNxgE5MzKnKmlimGImYcTNN6XTH0/EZwpjY9OjopCEsJS+zfsbDeSMDD6ByH",
-	"JWVYbINUh+jRH2sVqFMnIGXJG3MwPrT/EmjAu9ptbC1MRtXRcFjbzKlyV5Ojg8ODo6PDq8n+DrMsxiLL",
-	"TZdsSHJdG1QG5mkG0vRkYMSMmXVIcOUkvQbT2lrg1IjSnsvsetKPzbrp4cHRweGwN8HlXLkxYocC6nWI",
-	"slB3dLXcMc6yjRnqALFhufVQwZfHsIHFs8jvbhmrneptxpsUl9Zv0mOSH/DYmxHahvl3uAAd0VRegaBP",
-	"k5ABrptW3KwVZUx0LmRVr6Ve1wzsFDMttejl1eUA8qSYmcFnXs8I5X+LI8XCHUnnXsdUIet6wmJd5hoF",
-	"JoJVqpRy53xqJGT6kE89sXW3kJBuh5iFSHFkY06GQOpAWSz+nd30UUTElBX6e2+o4Aw8CDdYUOMdGQDu",
-	"6+Ts7c9//KrlbFGSaG2HDcHpAK0OQPbbp08fkB0GnAHMyL8AG3yMg/a/Z5Yhzc7PLDvRf9iCRm27T9Rq",
-	"YggO6Y9ob6NUgZqzThHPqQluA0Ttt2I2YpsVjQOBYQlLC06ZgoCQ/jXC6KfzOdSp2XCpTl+9evXKRoTM",
-	"86SIMvjWyj+ShDDlzCvhwQK3ayk7Xa7gZQXbBmj3t1giaH0/F2qoLAxoktJGbcawrO/uEa5AmpMK7tpF",
-	"Olrxr5EUTvm5F9kPVTDD2747B4Y3pPQ2QJb5v4vmKYPW5Jo04/9ClP4YUxk1/tPfS9VtPXOqIpZIEZFT",
-	"Bhp/aip1uJjFMdYzxRXOjKIhY/YFhTNrn5LGeI6WZKW1LFwU2VZrXkat9uY6OY6uSQ91mWDGokVGYKJa",
-	"626oPLZbgLmTF68G6TGYtLHYqb+JHs7j5CCdyPivHZsdVHkZk0tVhWfKqoJDVMO6Q0S0m6IOgUZYBBHS",
-	"HXMlONmQhfO42VQgxa9JLAC05mrQzXPUQfaE7RZEghyOibc1QECU+m4A6C6dk780kcUjpo+lI8aUwx8k",
-	"onUBwWg81ajcRZuFF6025jxsttWoUmnDCZfGJ7jwzHah5Aif0S1lKb81XKiKpTNht/6m/vjTWMRyuLs6",
-	"eZT+rln6H5cBEg8PDl96K11lHApldcxnGN1Q3bkKrXevP3e/MPu/bwhDADjCWeal2FYHtS4Bgf1Ke7xU",
-	"WnwAx64M0tjGxt2TLwUVREbxcn75e40KdKuB7A3+19SA7IBoj9v4oP07U6a7NxZ5d5WSUdf/ycuRRKlZ",
-	"JBfgaCQd+Y7LjC81kzFNbRQ9uP+CgjLBwf965Yz5V5NT+L/kGTnI+Hrv6upqsiFZxvV/9v/b1WR6NUlK",
-	"Ibn4YL1oV5PT45NvY/BFViuSKHpDFu5Md/FKc8TMVwQyoylncItF6oIEghMf8M6jkawbDFyLzsCClqHL",
-	"sc3umKGeMo/VBRqv8hir+9sefuQF03OljUIMyO1YbxVV2+jRAx3HtbgDP+rNh9AaQyzA3sOWy4SIDxy9",
-	"Bn8ps8xcCF17YO6/GS9KOTuZHc2OD49fHv50+DI2j4n7HrEXpmH8ih+zF9F6FdGM9PpWD/3qKy6u6yDq",
-	"NtX1VrsYnaJhI2zrLI0Gch85ScMJkWZ+WmV6PXyihs3WgVyzasVdGRpcytnR8eHyzoka4MmVCoOvpys9",
-	"wKVtCLLCWqUwC7bRSxGv2w0lt3cR/jdYoiUhDLkh5mDzJyniq1UUsV3pApYpirKTIQ4Ujh1V29XewXVp",
-	"V1nmOY4h/fX5bE0YEcZhblo5ko5h/KPFNEkbaU6aw5QZ2SGb5Q9JxEzf1hC+5lBtGvtTvtui87zgQmGm",
-	"0Ccsoz6N5805adSPdU4S514NSse27pgefft+NWOd0r6D0ceQDdhWHsgYVQFxd0uUT8sjy9i2UwlNUiZs",
-	"jrBeH1EyZv5X11hxxR7h9YHAR1T9CR9vMdW/20R+CPUxZQmjsWyucl9P1oyW9WSfZqm/o+UWJViRtSlL",
-	"PraCbX0pV0UoPXE4kptRJ7DGh2mJ1O0xmBaHsr5BTAu0xzibObimSP8Fw+/3jR+zWj8xWWZYbt7Ubp0d",
-	"KvU7n8+YQv1eRp9JFzbxxiRFVP+eE6YM+y0yDJUhBS/XG2PxACajL0djLN1BHP1ITO5ISlIrOQ7DZ3N1",
-	"Rxb7dzgAj6Jx4GgOLTVWUSHIin4JmbLhoQu9zF1q/V+alxYsW3CpZzNb7X9PkILve0X/90Bp1xfAfm/Z",
-	"/xow923UQwA9pf99enooR0BAo3endBtG91BQBeGMd4bqD4gqdkVDu3Kk+ipqxkNT9kheqK2rnqTlBLDH",
-	"mgKflLPQ3zYvpTDetvmSsnniMouHY0A6FvRQtVDMaAh/j2b3QP5s1j5vXN+jDe3tVOV5SuVdSo4Mmwwj",
-	"adEmzBz+O2iKu3MRjqi9zUsKv1u5DQfTHWpufNdmud1sL1a73dPX2BQZO8tUb6vhByZzEnTG/aezyLyY",
-	"vZyZCWbHh8cnR4fHx49TocJbz/WMi9nBwcH3XbfiLnUqBiK5HqlsBWZaKCtoMnebeuA2ddhIEYZiietK",
-	"TYa6jiNtEXe0GXSp7eZm6dbXTYMUVHX0HsftoL36up0iXripV3U30eH/5Bs2GAzSfQfrQS5tilLPRQyR",
-	"x+miEPyGuoimobvB9UKuFzLemPhNxAu1oGyhiBb0Vcx883uhZpTBkwKFmmnuz1eatwMvZwkxmc5gz9ei",
-	"rgjjHf0gxjYuPCzca/mda0YZvSbo94Kwj8AEegqA7ZaUMhpvtgjQjtiaTmxK2w5ANaN+2+hrmIm8KT4P",
-	"7M79rETBPo8Wv//d5tJXgVmdB2VMQJe+fV12/p1Sl2NhWCPB7rTIYGZU7u4gfBXkYmtpekmq7KM9SNGR",
-	"RCG6MknZ4JYAe/P+vYL0AWMWXf1uOeJVyhtegG0dBe1LgVlK0g+dOemuhQ37o2qD/gN5uaJ3SUfvzaP0",
-	"12DyN4Ncyi7867t/fzgzpsJFsPJI/DZ4J1fcZeLhBI6Afa0UUrQvtBaFLstCc5SJjfSsZKBa0TpIyU07",
-	"2PXj28tPSEtwEPhZj2cKwiBNsUAFcmr5K5hR7OWcY4bXYCSaXrGq7Ke+U1cZv5WmEIYgOAOuZVKAtepL",
-	"cK6HSXCBlzSj+kiY4hdWJvAXZutsODi93IBTyL84NByZMFzQyenkhc0zqLLC5vAMo9TaWsJdLDeXKvqS",
-	"ArSQ9uXGlKwoswmtYJ86MBKWHbGRD1dh6jz1xoJHJ6WtMECk+pmn2xHPidYvgYZMw4orZzGpxply4yKN",
-	"MUjZhVngtoOMzpsvTpvhU7nN53CPDw/vsViD5vHvuK3HlCq2g8ZX00Bo8PKTxRlJkR3i23RyYtYXg6rC",
-	"w9x7E/jbdPJyTJfwxd1vvoOtoiz/8RlHZAqbdAhLdZ91z3mlICxAiZh/rV3e3yBs23B+jV9oXldC+jpZ",
-	"k1iMApWqLvJrCVsanuyCf+qYDsgsNIJOeET0MNVbfuZtheqd6X98jScoLrdhjB284OzcYJYp1m9D973c",
-	"/PmepDrmRUHZ90rvhSvRW6HgIagjvjc+aVTTfdYaaZQRWlcARozctgYDbgK3itUQWxsblpi+B+/rLVIe",
-	"rSw+iicdPRoQ3btdFbCy4ttzcQ+3tY1N7SCQgB/Mv9L0WydT+JUoz3fEjM4CloSlVhsxquqgROYO6edX",
-	"ojziabCF2NLrJnPvefonOeKj9tzV8IE9PxnewOrd8YfYcb0xuAnJ2O2ep1AsrFtmsu8/I1v4DWE2vL9h",
-	"AbL7b/HDM5d4ibxHEHh2AaKb0M5suTckSMIhSKDmLg8Cyogn9EFfrOrjaXqo6ABnguB0iwwtpc9zDAw2",
-	"EWe78L66IHWU530kSlByQ1Big0Ss0hSkr3re59ATaHO5WrzP5uE+ImU1nkKM7OebYAXCrjMNHkN9MO4U",
-	"w5q3KZXx6LNJ30s2nRZdUTJQNKP7IMtkg7Acswu+8/eR5JeYf/mJGcyuZGBNhi0ieA45xm74eNLRxzkl",
-	"y3I9c+aUHjFmWa4jMowp2woT1mc69SvLSmPwsFTYhKp10qtqx5NHvUaaJZWjN0hzyV1nvn16m119/Ntn",
-	"tAz2w3iCAdWjtl5AQj3bIkY0GObMGm7bY395E77r8WAGmPFFM7kV9e9qTH5s64pTREYab2+xrHSX+Atv",
-	"XYhxBuu0mWM96DCL0GlYD/QxbqQ2BXoEDTWKLD1DSbiZiX2bm3J6nWT9wTiBJIJOdVUlYyAxjiGTjGur",
-	"VZkaVU5p8rBnTKWmSpesMocjNYuMPaaquzfLyA3J0IauNxldb8CH7R3agyt2BeHAJFHSL/a03Lq4hANk",
-	"E69dNGsF5UvkYkHAswWgXbECC4i3dwW+TGC/DSIBX4Sx+YYHt1kQ6pGu367SaU98BXeWv4qZI0Psfx/3",
-	"cFDHrKor6dGz7Dg9G6hs1XkPv4GaR3QVXLoS2ZBqGN+MsI1drKZs1mPeqo3CXNHtgsAUDbWDNESdGcJU",
-	"d+q6MwWUWphVzox+NcS0zrYm0avpB6DExGr9WdLkuo7LayHPKxgxZJVtV/Orau1VtfxiJlqXWljjOigZ",
-	"6Jf/66/+96g2nljljMhGm2Zm5Q+mE5mtjO1hIN7a4DZDLP7L/j2G+6wyzjdt9pWt/gD9XLF9x9BNSciM",
-	"4CpfU16xvXAkxhE8uiwI29fXhdLtb0zpyf9uas8qjtYkhCJ2DWhQL+vYvV4q9EtWBvChHvC6KLOCN06e",
-	"XUW6OvwV1fzLLbJllWOzGsQ3ZvSHm9lshlPUkc3g7cmsysI4bedjAJZ0G+h12oiStF8hLZ3nVCk9h9v/",
-	"1xcXHmYZr8ll/8rPhLGZGV5Ursv4aKeuPOr5bWXF9HhhqrPzYE4YP7ukfV6HXC8sNcmJ1gljbRZveOoH",
-	"psVUnsvq6+N5XRpB5M/idGlmsEVvYK+8w8PISyfHxw+nmHc+RtGr+DTee4AkF0JSuHTr8KCHoWPzCqQh",
-	"QS9mvv/6mdtz3+M0sKH4XKA6Lj8vM0WLOlMTXsrBSFK2zkgdh9Ii+5/L7NoO6F0Yj0H83kzPpC4EEHQT",
-	"i25WY6zWGDRRHB++empwPlhF0NWufCZVBbCCW/kg/Xw6IOyUaDT2avk5ZkYEN21rqm7fxOPJ+wzGegLq",
-	"NhM9I3E7AAZo2yL3UQl7GJQGXaM9yXOPfSW8zFLg1EtiIU73n5X4Ldp2oHhBpLL1w+Mk/9E0qOm8SlRu",
-	"ipZLnFzrO6p+hLaUUWq3Q57pdo9J7ME8z0jzDTh64gmyzGBPIrsvbZnmoU/BaOC+EyY/mh5HEL9Na+7S",
-	"pi9ro1dF5KV5DfXfLtDF+f96C6VGKJEIJ4JLaXJZpq4MhomOtc/GUpKlWhHWmmelcV1ZXepq0tRroXq6",
-	"pwUqszr7X7fkaaiQ12ZjxQtP5xMphDUut6hZOwJSyAlLKVsfXLELmlNlHsM7PkQ5l6q2OLmHMuthG7kP",
-	"MSXfYHCsmm/xXb+zW1nR8RpTBrVhQ/yCsatGL2Rfy2p3ukwA7s/6kHivL4BFquW4+HqnVy52tIwd+Zax",
-	"l89pGIvX8eg2WbvHDp6JJ1godjj5DxTp1qWp/0pUrabvFvxUB7c+xQ6P0a6fPbhNNgDpsrf0Ro64QdzT",
-	"YVW0iJ/cDXUOufBkeZBiHNuunXWW38DboEviAidiLDDIyr83NTxWmMpdDD7PQowDISpPGwxnSwYpgZlJ",
-	"Hde04+VVmXysZzk3HVQ/kjXONR4pK8mIOA7PdGRfz7J9bU4NZsaQ5aUVTa8YZRsiqHFSK61K1e/9oQ3V",
-	"It42dpre2LG/3/PUgPC5TKhNKLqJ+b23f0Hs+lOTrIPZPOAnrvVFO9okaWOZnfnG/9+AAQezlunGEK7/",
-	"iLe7AdpGHpu0adX/A/Ta1EyqvuelBPtA1RPejYzRdmAEeli54aQ7maxoYeTpg4sv6xLpvt6D9lrIs6Xz",
-	"AVCopfMslBqjol1pdYNFOjOdZ1AhZFeytequqQ1gNeBO+kV0haiy6avZ1tQkObhir/3y9AlnkhpV0dQs",
-	"MZ02WG8LyglmlK1XZVa9CbnHuFPJGDea2LTyKkMVDPjgV27Zj1H+b1ikhvrf6nnBFvFU5wBmDE0H3+OZ",
-	"MBvChTkgBt55XTPwuzkGzEIaIHTsmagqJHaLHZcEgkVR1RRJuobiRRzhKnrIyRkowcZgA/WarpizJ6O1",
-	"wAkB4TFGj833x75XJa7znbQ+eqqrUD6vEO0/Kk5ZvXVQCupZ6LlCZ5uSxlJwBg7VPlZ+FrLvQHI2nFaZ",
-	"SsFmKJKiLVEdssKTMsqzAF7LFr8PErI8kjLP90CeKwsptr27hYhUXvlgjKmnZ1qWBte8aaR44wS14q1g",
-	"0AelmIcIt0/CMP7z1Xuu3npFR/qKbFsVtF0OwcgtKSeS/WDDKLqKl+eF6i4kbr6bh4XNE3tvXH3GgYh/",
-	"M/BTxPw/kF2l4jb/nx3o/6TxPHcSv7w6EQNxyPAie5llUbuNqXlas60qleqKuRmm3ks4xksGf1s3Qkwk",
-	"qy3q7xyU36lQ9sZDyUDqXY26CvXPZmRPouCMpBz3UP4I0oFsmKo9SnChSgEv70G9XK8w3xTJDb8FuoFf",
-	"ofCne9kOYVW7YeB1S4i3UTQn/eRTFSP+bj0zrWrJEeL5JcDi81FNuJs95JJhuZnZ6tkjqMSU8XbtvUo4",
-	"eo81MVSemNbtH917vzb2kBu6/dgDCABVLEBSjxNz8PqFKZuXfV+9mnaEuZ954yYZ589+0iDsaN3xvkjs",
-	"YG+fy2cMVTMqsmrA1E3HUNpsDnXOXD2lUhIxk16hy37ShgLxhSArIghLbCqV559pEW9QX/ERNzJaETKy",
-	"j7pd7UZ95NIBpT/Z3WoG7IbwdgXXR60PECsV+8Rawth9d22+xzIBI8jkG1SgN9U7Z6lfFrLDwekSFHGr",
-	"wiVQ0K3NoqaqUbizRVKtmqGPRFGdJVWfmKC6a6T26kme49woAg9CIA6Y5iZqVhDLXNWdzcPoEdHgAmos",
-	"2mzV6qm2uh5n18vpIDHaqVoWbUgHtSmkLi9IlbJ6tl3WN71NHW3LCpUaT1ck2SYZ8Sp3et3rHKjWQ/Fl",
-	"jtmMspnakFnGeYHa1T7rgV57Je3aF11HNdC6+9sbW18xXhndlEKvlm/0yQy2GHyrfsljO+IHeEoumqVH",
-	"kDQYtpKUeTTmhq5dOL4dwlBAe4jXYUVN6B9Dri3J+O3zt/8XAAD//7+npOlgywAA",
+	"H4sIAAAAAAACA+V9iXLbxpbor6D4XlWsPC6SLDu5Sr2qkS3fRHPl2NdyJjM3crFAokkiAgEOFkm8Ls+3",
+	"z1m6G91AY6EWy/OeK6kSgUYvp0+fPvv5PJgn600SizjPBsefBxs/9dciFyn98jebNLn2o7MAfwUim6fh",
+	"Jg+TeHA8OJHvvLPTwXAgbv31JhLwHL+Z3m7/+cOPf4HnITbd+PkK/o6hZ/gVBvB3Kv6zCFMB/eZpIYaD",
+	"bL4Sax9HybcbbJXlaRgvB1++wDuRZTCmaxIX/Ko6B/xi6s/mgVgcHD4/evHyQWbyBRtnACzoHt+/8oMP",
+	"8LHIcvw1T+IcoCjBFoVzH+c4+TPDiX4uJwd/pmmS8icBDvDL+eno+f4BzGUN8/aX+OxtCAuLl56anbcI",
+	"RRR438Fw6fY7Boue6P9OxQK++V+Tci8n/DabvMHBPshp8yJsEMIqaBRcBrw8g1WksR+9KSd5n3Ud0boC",
+	"kfthREDLU38upgB5wJTZHHYHBy3XrYb3MpFei9TjPh9wuQ0DDAe/JvlfkyIO7r/mg/1Day8VksZJ7i1o",
+	"iAdcD7xLinQunL0TxE+WcilwXjcizUPGXqub6rl6R38AlIzH3iJN1t5/nLw9x7/ifO3nAEpYaeWc4NJj",
+	"/OCjuM3rXeNTL0+8IhMw39STjTPrAP+Lj5MeIVBnfiZGUQJbkDgH47Nco074vYfvGqddjtZnGIZyfaDf",
+	"VyJfAR7RhL0w4+Gwo8iD1S2jZIZghDM8h563OG5crAfHfwyoDfzmJoNPQwfpK4nTH7xQG7h6WuXHyexP",
+	"GAlnrAh0fesBAv58tVY0317QX8NIZB6sSVEFb+6nKXw59LJivvL8zPM9+ECIOFsBziULajwv0hQhwG+Q",
+	"3uZinXWht5rjiZ4RzlwuBYb1t/gbPlxLHHbdQSL9LvNUG3Nf5evAuwnzFSyjoM8cmys/norbjR9nzhNx",
+	"cja6SUNAndhbFmHgx3DkqHmAZNr38MoUcFziELZb9jf2PgJkCDMuY0AAEV4DaMPc8xfQnMHGLYfe2k+v",
+	"YKYAXRiJOxbB+LJhujH88EO5f30A/Nr4BHtIhZ+LYOo7gPoa3+F5z0OgYTkAE+YAJ3WNjQcBfDfCN66J",
+	"hY4r+rc4BDTyFCvhhQEiMFxnaZ1tkDeCq+d4kfrTrFgDnLZdiz7Dxq9XfrwUF/ILpErhMqWFTW/8NIZ+",
+	"Hcj/Icyuth4sGpAXu/TCGPYW1uwjffB0F3i0L/5+TluYJwnsuB9F3k1SwB2NaCKwQVr0PgdvVce/89Rc",
+	"p4B5j6Bh19Qd0b1rcRFF/gyBzgxPDdYw76lrJ0+yLJmHiDe4tCrPhV9ptq9OPvkO7Oo3a+HngJljTq7e",
+	"OexX0fsoXHBrhDDs3DSMNwXf9EEQ8q333qCWDKPKFYY7Tt95Br88NPkCPNc+MhODdO2N0oU3ydebSS6Z",
+	"rBqtppm4bzIaTJJiIjWKrJsAErdiXuRiqobtukuY8+V9tjZHA9OiEeYELbC13TsGTa/dQJLBmvLHn+tb",
+	"GiQ3cZT4wbRIIwfbHy5jwJYojK+QlWBCSj0OPRg5DIizkI8X4bJADtqH0Rf+HA41fjfN86gvCTuRXzJa",
+	"NnIgdbwM/0kv9DEE8vvyqOwCfoqlSN27I4FtQUp2WQFPv034bYMfdG+FvfRwDdfXZBMvhx7/+edG6L+X",
+	"4UL9eSNmG6R5ObAlk00EF42Fn7obF/yQvNZHfgUE9+XRSMTInQXl/hYb3PKDfe9t+AruW3prkrrZNhf9",
+	"+UTkdohNHBKTkMCR9j0pJRr0h5iasXP+bi6tsm20xLZ9em1f6fYkz8N1CGgLdPFm5cME4/I2hWsnuQHW",
+	"bAmsBb4W6h3MjdiPLeC9ALqKLIg8KpJVjSWTJ+8NoGI+TnMI/cNhgRebJEWanDMByooI+HYY/zIOEpHF",
+	"3+XelRAbef7WyPoCi5OkAXMxvncdJhHdaczF2FgHHA5ALHNeZb/jKmiiapVZnmwybyaQ/tH5/slbF8CZ",
+	"zoRaxqLI4ZD3ZlQWyOQ6aC30lMTR1ltoJri83df+FlYI0jKgOd3xY++DwBVeC8KYjCANi0oi5Dn9Jexm",
+	"llMnksACm3qTpFe4Ci0QjE1E+2OQpfPJGj4cLxM87fLXFC8OfPTJ4Cdqi6oyDASpKQBkmsHGxIFjwb8k",
+	"NyCrwHwscMNWSiqKsgy+gnMW4grG3jsED4GBkA1fIAdsLePl/j6yW3G4RknnwEnvGk+CFnbrEqukE30u",
+	"+dq57DyBF5qJqLDDSrCh90rcMW9hKc9tBMkC8MSXcgdpPuIQ/vjkwEE1cNa94p1kqToq9AXFqyK6Oknn",
+	"K8BoQ7FVkR35veOW/AicElID2WLoLWBt9KSI5bPyKM7gWAk/tlnDrFHBlxkdT8zuzKODTCKLD/QnMout",
+	"5wUw9IxfHnRAzJzisARBJwy79tV+uvCB6gRTOVgrMPAa4OYE3w0SOwc0kBnfiWSAhD+HDy0llyUl6H2r",
+	"Qkh+WAfJLsh3KiLgpRtxrzemwDfABsPpAFoVUJ/eM3VhqN3bexLs6Vr5bhjDawu6MOVGIAvAO7QA6U8B",
+	"JbgnCKrYc2cEVnuEOky1P3jNJqRaIx3r3jeB3kMN8vshOmw0XP7iNPUXeXY3fKdvFWdBWJ9yp3xrB2E2",
+	"94kZ0wLdt4PtleV/JTIp4fM/nE6+Jom2GWjzyC8CMfWvYd2s5mlSWb+mlsjt6caen1fFZikM1e9tOVAA",
+	"x2GOegJqWJedizwBhjxEFhrpDjdWY+M3QJqBsw7CxQLmRLhbjr7nVH/ywO7xJLsGYxlrMEbrFODM3od1",
+	"aDZsSR7GhZCI18w7obAGkEKZwoG3J/yaRA5UUmT5YBecBK4TONBpts3gkymMvN64VebQCo8DN/RkQxec",
+	"4cZM1lOUYtJinrsP22tq5FmNXDJ+mHWs/lS3uCsA1v7tFKRA1yzf+reID9cizaRum9q1Synwdr6ZMhp1",
+	"KnBfv+eDiZ8h+xEyFWTo0pods3r9nuVL1FaVHzkBSIbfehe/ihuPXuGOziUekirDUmD8CnKeHwR8lXor",
+	"kPAjlEKlQoA7dGpW25HpHQA0DeFkdeBS5YjxWnqdpN2uBnlabWWzYecrX0+ByYgC15I3PtKPxj7oY27T",
+	"YKoo1ee2cpxGbNJgt41GHzoHa7x6Te1uHSiuRd7rQtLn6s21U92rpOUu9b9v+ZR0Gip0t1mD7H5SKpBI",
+	"eCetsFLoZD1ld7K8kE4Hl985qR1wsAGBDO+DCr1glwKlC3V+axn2+inDxHWz/vcjPEWdh0U86QMDesrV",
+	"QZoGELjqb9YbDhQlwcerMEYVmFMnUoEWOu8Mu9Xn8F2Gpo+NIQ0tfBz3mHQQwwYGqNTtrXzSXgoSPPSc",
+	"6zyPPDe0tCITTnx+T224c3RyODslvIuBJUA9tsS8OtlIXLya2nJ86z1jfwl+QpuQ7RnbAIOhTdUHxAN8",
+	"jw2of3KSHCDDscul4UK+8aDfGYApjK3tNy+WF67NaCVmzSZeNisFDRawML5O2A0HAfpMn+QSDA0dop1q",
+	"qjx37I7/9eLdrx63J71eadbT/RMydw7SYrkjF5Qdu2MEnDbSgY+GRr6FFph9oRa4EbY0KYAqHM1M9RsS",
+	"texnSLTthwqvLMJiUaauW+SBFKL1i+nOmlHyiRClirqJwe92rMlWyU1oKdyVJ4ihZxZuX5vLmCxswCei",
+	"a9bc36DNY+xdoFRLRhdlPtemTja93NEVR1oMmbOW0v8Lh19Cg7vAB/IR0JZYp9263Wngoe3zu5jdf8Vz",
+	"K5X9+WOY4DV/toNpvYqGu3HHrVwYd11lwSr+ObG46cOHmgPdg6+kGXXK1Borpsqm5nJwHJzodp7RTmkG",
+	"5mhTVSo+Qzv0X5PxqoDtjPytSCdRssT3k2uf/p6st7DS3RRHHULw7yvoCgVfRD1LHLbnhQa4KRop4THZ",
+	"IvnHp4fXFyh3Tb+/3sAv8mSK0ATBVwDUs26O7E3M2if4csRfEt3Ar/Xy69wYDQRc+naKHGfnIOd+ESNR",
+	"jb1kht6/dDGMyN6rCCdpDInvz/CWBrFanwflI90+kYZ91axAxurueEtr1Yqxnzygt4SQLIggz3U5+P5y",
+	"4EETmPNsC/AWi/DWRoNXfoZyPqoppkuQ+YvZdPr9blgwK4KlyLsuB3kKX3FjKaP4YSzSbrDjPWC5Hfie",
+	"/hoYZHUZwow3ERx48uXUmjtyHHGrH5Hxuc1BFp9fuSkaN/CwAesV37+7+OhN5IcjfM52xSDQmpBOnRgR",
+	"pVNlvT9b/Jrkb27hwPZAciZoNE7NDcALF+gYip4V5MktbsMGXLurVo4OFJM7txNODOx7UmTRdppdhZup",
+	"qY/qe7TUMSJ/W6NHD3s0NVyA5njgA+cK26YyRSE2KXJrSn/Zx3/DZh92aufJTxEF12EEVJa9IggwbZMd",
+	"OETQBjWAIQUF4nqHQ/KqCKPAjRpwIMy+xijLkI9OWj1YYQ7MoMiLDSLwEhj5jL1ypCOPb3c01Y1YHhm7",
+	"N6NTcfsqgoOk7qygosW16VWVR9qJUgVoLep9yhQqAlgCtpQhX62dvCJCWIRz9UgYaw/jecQmDvZ+6XEQ",
+	"ToLAcpgxnKIUI+zaYNyjLCRC4KJEY+8kuvG30gVMut2QyAVsyDiMkWWaSrqGW56J3L2b7Spy1IS71eSl",
+	"Rmb/kXTm6yQQLhU5PjbDRQx3KkP1kWzIwgknIxZINld+eFU41R731M3LDXFqcDZpCExlvrWwZL+BVAIz",
+	"UQhPfTL2yOkNtwdgJ0VBbeJkx7IijtVdqemsj36BcK4vB9RfcDn4yVuFS1Ruya6RpwDUT4GhCtPMRAtj",
+	"z2BRt8BEbcLplXAYGU7en3nwgkGBTZF5WaEb/9xvCqngLtFr3u3Ci+6d3m8fzo1OkScL55Z9drDK8012",
+	"PJkAyx8DeQZBbuyHE5jp5PqgeVh1u/TlO3l87B8hzGgWZgaeOTSBNBBh7TSRZpAm9C1DHozVytGs1eIq",
+	"YXnLTT462sEIdBbDPsNFx4Yg654v+/5FRBsPZFoOT/C991ughLG0/ZDTTJqgAOS9vvg39n98RIMQPJDs",
+	"nsPb1Z8BzKTonfmokVWNK8ifSTIu2FPROUyYu9Sqmjeg9y7CouGG4HjPoEHkuGi0laE+GGl7ngrRnys2",
+	"7iny8b2JPeDkPdWRgkP7pTHEi8H3QDz3ZqmP5IX43V/enJyOvZ+hO5AqNgk6F2NnKOrmXhLrjnEgdvsN",
+	"c+YY1hQ2laAS2Jpkpt1z3PcLbP8syUTvgyfbe3AWUOfh6lKuHKV7h7xc46PbtnKyStZigmryCSAm6Rnu",
+	"Yaq01RO7qWKadGZKC9MQXwMb3MuA6O60Lbimp2bHZWG8u4bnVMyK5Vm8SNq8WULNLdYXdn7myZemzIgo",
+	"gNc3R/jagaWraOsM74z8LEdqjlQ6cNEkYG/59bwMjlOHU8eHSY1MOdzh/uHRaP9gdPDi48H+8fP94/39",
+	"f/R2Unc7uLxHlxnJJF78/RyJefP4BsabiqzAhxMej4OZE5Vk1Eo15Oaf7vUi5cKYiwqbePTjix9e9rLd",
+	"IWOeNSt4P/fpo+JKouaHXYNgEs4r0VlKqYPubC+knQL+Pnz+gz5J8PPo0BmqhYQLcLJwWWZ+ZYsZwgmb",
+	"ZSo8QUGsw3ZWOTjSB0nG/JgDK6gNrQPiPmNzuI87LRdzHzjnYKqiQdx0hNqUESM3KyTdSuawolkXiRQC",
+	"Z1v18DIOF3CjsL9ZlCUcsgICK27RIhRWDxh0yaG0sRBB1hgH2xAXrK931d2zMo8CCvsi3vJ9h790vAxG",
+	"8WpzmJ+jA6o/g4tQ6wCnfxZZrknANA2zK8stdXCeJFfANvoLoRkqETxOBK+SAFucHnSTUk6SFid2bti6",
+	"TfCocSP3N1ckEsW107HnkBdYJn0Ae0p2IyFVaWFWxoxcxqdEb0D4jiKKHZFRemTowm3gIC9EACnlMf8K",
+	"Xyqp9IUehhGrYtiq+6s2W6yqt5OCUp/Ts9str5NDVHgfcsJSdnw4Feyi6qTFT+dnqnWcKjFG8+pb10mJ",
+	"McxDonm1aZygYbqIA3cSCZk/oxazhPfYCNGIWEhhQtMaqK5ktdWrnnE7Av8zauQJm65iDBcrOydfVjYg",
+	"VLW4zgu5Y0i5SZkKx3eJfQGSIiEdncuZzFUEP/Hycq+HO2IQb+rQcO6R11FtYi7sob0/pbQvrpBTl6xc",
+	"oov3TIyX46HHyVQObBpbZlip67DLNDP9jcWGYVDIGZAezWUvvj9O1nPBdPoj8/lRnTUCu8fx7Ew0IzfM",
+	"jQrOkd3+fooc9t8F6miUbYDewv1K/JLTYK+TG9TR55q8rnbOWaH83lqBg32jK1wNNNK5xRy2kaKWvTS6",
+	"2Um1SdXBDkjF1HA60PyIdkwsBUD2dJzOKftFwJHIWp875Ugxq73IUQ1lfmH6DSnjgfEFWwyn4haZGzkE",
+	"CE/ycb6CL1dJxM/X6xDEKkZdbW+AN38mM8NhzzaWmO30LDmNxxRP2JZgHEbbaRAu2SKrmrES1HiQihy+",
+	"wm0MCr5ilWvMdFPMojBb8cMSoOswDtiNTj1bichqU8TVJyU3N+WgZ4ZKlBTB1Gy0iEKi9QEwjtNl4afI",
+	"cC/gMlDAAl5oqlUh+kut7Jd8gotrQ+eit2gqdhzGMNuA2PfeeYVZEczMHHNzZJnlK2DoSHcMAjVqf7gp",
+	"sC0yNRYQPJtCY9QyuZgD2k0WxT//ub2gDzmmuc5wZprVaAgqDRfMUaLkUF5zKsAUJ630lXoSUunksoDk",
+	"iA1wd4pbl5/I65WfAnqgAh2Va6R+h4MpP5Mq1rlqZNu3Dp8Pnx8Mn78cghz5/Mfh87847FuG0Fo1cDUE",
+	"0MzgjBe53CHYCzUV4sJx7XDYKsl0Jr9lCHs4VUrRNdlxU7I5Rko5jEQ52S6Ae8+3HjXynkmDQ4gR+phb",
+	"y8KGH3uLuSaeqgnU9stGFxeVxZNwEfsbdJhrzHbRkAhCvkXhL5NdeE33xl08rXHLpt1qnTY1jtpPShKw",
+	"2d7LkZbYxrnSDiqYmQNrR+c+ykE1rrnO0pu90wP0ryVSkqtko66CDjvmIeihZRdowTRyOgyBJyejrukF",
+	"5tQ7R5h0wxrhsGbLU9JprNU+fG3KcEwcm1D4VtpL9/c7zacNgvepJWZQ/5Iao0U7tIThNjrglLS0rCyt",
+	"u02Rno0GKNo643rAFIu22pUoDzVjgJyLeInH4PDFSxpS/T5wSkLIF+Y/AzVexposyU1xMZOAPkjA0c5P",
+	"mz5hEpkx6USRcLxUnanpupDAaQtQW9QPhZt4cpVtpdvxCjt7q1ozNBDDGmizvBfKJWfs+zHDpB2RuPbZ",
+	"M7uXR3DJU3T5Tas5Dct1ucDzi/CjfNWiRRHoYyjiufztCu5yeID2jnSdhbGPrlZGwKvz6PfV25QBtBS4",
+	"bvTZGSXUfglU5rvYrW9k950KA7tb2UwJ25eDg/H++OBg/3Kwt8Mo077AUsMBcqE/nlJ5dYxT9SxuicN1",
+	"KevLwDDtKXJF4sYy9QNmpQ3r+9WgHZplUwDUeL/bWqYi71UfrkPhSFZYN77wC3IKxgCA1Ed+wwN2iTIR",
+	"XhUz6CyiGGrWKii7QxnSUsvABLK5M4sgJRSlC4bva6e9hYXFju9Z2MSpwETnoslwk6fJtqMnTrrg7AAE",
+	"VOq8qwMaht3mRMvCUvVV7ygT2j81GO+jO2NAw96+i8UoArnXSrWrkqqiscc2YH60dv/YO5AerEPvkPaM",
+	"JjD09pkDIdgMuZECdQPH2MAuEo8ImBMUc8GubQrrENsMHYVGS3gmEbI7py0NPCRc1EhV7mmJHubGlLBs",
+	"PE6V7ahfGXOlUVWz1yihc+mYk5DYh9oHp/QN80+lmr7qPa92S83fk22HCMK/AZzSWKAK9yrErGvwP3mJ",
+	"b2DkiYwJMWIwbjLy+8VLfHwjZs06UAc9vs1TNKrh2zK6iCgGYR+jGgnTavfMof/Pc290QC2z7vAPCY2h",
+	"grN7nxBhik1+Rw+KOwYZ1i+EUE1ExqQaSQvNN49hnHFn3ry7yaZ0qKzzm/PNhXSHaLG0d3hrcg91e/tb",
+	"f0PKS86oThGPnI2APDJqQaNSguPQVEophql4/xiMSIFOARm4vDKFKkx+xJ2PjC8dF/4XN1DkvB25zFw5",
+	"gF9LjxJ4W3ASYArfzPIgTJRPSSUbkTnzoSGt7+bg3OznImcEQJUe1F1TagCZK/g7vm7DCAd9sd24rsM0",
+	"icnGfu2nITs9dEzu8+D0zavffkb1ApwWZz7cFYCzA1c7ZvbLx4/vPdkNWanZl5vnRi/dU/v3kSRIo7NT",
+	"SU7whyxUUDdIONX5jHAevvSeoQurVx0VyP865CAnAtRezevV6WLo8qSlbpWDH7nUtq+Rej+eTCj//CrJ",
+	"8uMf4J/0qZ0A4jgJfP1cVVNWO/U0DjFV59DG90NPrDc5u+1hQu2Nn2XsQ2Ck3Z5HYTW1ezCb6GTc2WR/",
+	"/3AawNioqkqzcbEZZ//pzO6LF5grv2os/RxV4m92mUZ/eyeXaNgByymdppik1GfPH7hiiW4So6TWwR6W",
+	"WcW6ZWYOkUUl5NVE3A+G75DHBuzXFWWsIUfUKaYAbgjshy2XbuiqJ1TRknQehEVDOgC19IasqIuFjEzT",
+	"DYceTC6e+5U8coPTD+/eex9PXp2/8Wg7OvkFqe6k1RvTb7d5fhBzmIMyatiIR858ZFZxO/KR7x5ZFEin",
+	"jo7E0ghzH8c8W0XXob/NZPCo048YjWXdDmZoclTzLh3veqvbSyDZQ7YD+6GSlRrbd+eg/IpurD4hyXu8",
+	"deaII12lalKNCbNB+tJFAxD+wbsib7ZZKQUtoJcM2kdNXMBZUlUcWx+bVZ7kfsTqPWdsKbyVVqFMiv8z",
+	"sUDdJlrotnhoWZltjHV06FwTdnXBvoBNA5W67oqiUX5mQe4IPSo78NEatLLYobmJBszd6JApRc3/7BBx",
+	"K8Nunzw2Dvf85jDl3QKz1RBlJDYFIxmB2m1j9Y/N1uM4g649cifEbEx22LT3rCmSe+/ecdqu8XYJ0378",
+	"EGx0t5wqXy+Z6CZProQruq+8N+gzw0WM0mTIzywP7v0+Ua48CUpHsNsEKIV40+AvOJ63x/CuZFsupfd3",
+	"6IqtK3854yB6ZeaSLivOvPXKt0u26lVCpzudmO6sb/UbOY3X+kOjBk7pZMOB9c6geY7MpwZGfDHwegjD",
+	"nzx/htUEOA41lM9lMBFKE40ZzW7zqWFTdUXq34RxgAnpQyUaYZ8cGGpi5ssf+2JHV4qAs9NS02okC9Cu",
+	"zDeqEoEr5sy9UGKqGi9PfI9E47cLC/f2x/svDARZRAkVXWlYId/AXWWcNDbevZzT/XICcBkHnDg6bRt5",
+	"9/QNUtJx36zUhXZb5JVxUzIrt1XfJAFt1SXOLt6VoJDR1G2ZChD/PNkh3DcysmDvzgdaMTTTdXPq4l58",
+	"6dGLnscA724sApE7wy0pCdosSmZ4FLipjJUnbzAry7RFLz9fKt+Oy8Ex/Z3B7oyB63l2eXk5WIkoSvCP",
+	"vZ8uB0N4X6RZkr6XTlXwyeHRlz7wEiB5kgisAtwbrxg+YvyW9dmc4/TGTwN9wE0aY105Bz1vPLJ3Ths9",
+	"gWt2T0U6mp38W6qmac7OXTTNVefUUTqt373cwgn0AgwJlKjxvwbp3Xn0SPhWLe5Aj1pzBKAo6wrdNqCl",
+	"sgO4O3beEH8tsPJLJYrbwTaMMAPB6Gh0MDrcP3yx/+P+C9c47NvZYy+4oZsz6rMXziS2zjSVJTNku1kC",
+	"JK/KmNE61rWmwJWh5D05FRldvVPWAHltl4kDKrvyyHkDlLjC44c6n83D5w6QqS8oo45ecVPSgCTLRgeH",
+	"+7M75w4gay/pPqWx17X/KpMA7KyPQjIvWMYpOLy30AU7WbRxXzJHf5mFKzRyGVqMAvYWulMTpOI6FDd3",
+	"kZsx++tMACOgupiQkwpGwJOJu76DTfHbkmxj+HYDuegok5itpsRE1zmDi1/Igo9aJJpzEgUqHUgt0Okn",
+	"yiEg7UkZRzqifysKXZnKG5SKu9dSlCe3LKXY6N5wcjYC8UGk7GNa+rE0IdcHiVQsehtJRpAKF5H49nJJ",
+	"oI/lCBknCv3ROEyNzZW93Xpna4ym8IHsfvQzp7fR02Y7qJSFVO5LyvHRqghZu+5bdHKvtIajoXh1xBXu",
+	"KBy3PPmxRYOocq5Kcc91lseX8TvMUeHHWyk1IimWgSlDb1GkXJtZhXuT5MGKnbH3D2D50TwD8qvIgZ75",
+	"MEIRUzcqvrRiQ6fsPE0CXpk/SYt4wOukQJI9rTYgYbkSA16yPklhOSSWYh4OrMWGxlpuagJ0vNHCAZNw",
+	"iA3PqTqbw6aFqaFUiuKW7kv9r51JXfV/6Or+SzNu1PUUddpHVjDK7aAoSElTajL6IoxVXE9FD5xdudTa",
+	"v6/8vEYMqC15TTnDI1SwlCt8JF7KWHbV39oPxE4KQY6enxYbl4RYLJecSTxGcQb+2mQ7dY7swpRT2TpT",
+	"B/5dvfIiAaJfEcO5wAJSwQ6jVB2CCPAl1GqTsJbcQkfel3xl3USpirzINEbmDqQ+StJDjgNgv3z2if75",
+	"zUdvoujL5HMYfMGijpIocT4RJhoZYXcg9dMLcaOOFuAfJcFgRdi4hnTzTYGqibnTsnqxwr4TzotAwTQk",
+	"xWShSryzQY4kKTI5/lCOJnOBDXpRD5xBI9F4/f43JhY1A2hjf+I2xGRzzhDeWyLRgfgJvcX0yVRbggfr",
+	"Kowihj0Q+nAZ+5HTwk6DyPfO8r++dE2U/RnDWJcgv3Y6vtyGu4bvbIR/NU1B+OF8J3VPDAyEAuoPSEPc",
+	"H1wqa7ylJfIMeqVD2TBf6XBNbR74Q2VASdDwipaD9xtb4VU5vlmnkcWCnSCW5QGIN1O3S+JHNcUj72/h",
+	"KxtT0iQnMyl30Mm4bBTnIo+FMVcTcLUttE9HC+W5X1VSZZrcwbTN/DVZkB/I5K4ncXd7u8n09yyUWs/V",
+	"yHk8h4MyWBc3i/et3EEzOlirzioemfonvcRkiMg6K3d3XQHP6e+iisS1pJ5i5+sWMw95zGKGGzgCy4Rj",
+	"g3oWSy1VPbreoaFkdfjJl8lP3d3UFLX1PmI8slFbJ9wC6zDGIzWvoYe/qPu9tv5dLN7D4CfMHNW+rEB2",
+	"KbIyyoHJ7ylaWaAxFrEvZpvOUmizlbRUEd3ZWLmlG0hL83GAPlavS5/NaiyTMccmh07T5Z8y9v3Hydtz",
+	"/CvO1xSWZmci5My27MNNubHhOXpisXyMfvnI9qRJsVyxtZPEM6pJTYRkB53qB8GpkzBxLas/u+cnk7D2",
+	"rLyuYEDuwuydSe5lCFVHsvPBhKXPKS7TedUQ0+Y4rszMaTsfjzryyP0Ro9w3yR6KgcsomeEDsjyh6GyW",
+	"1qHG8Jsb2W7i6l2vuvByll349FBuVhaO3p3wy9Dgh5qVFaJ951n9RpEaqhxmU16ztlqR7nC7Z+yRKusC",
+	"kUoCvV24dKV0LTHQsshSdqWdwPeTuUq82h3X1rCghyp4wb15/rfo1GQpCKtVvStsQ283pnp60QlmI3+Y",
+	"uhKO3KWcK4P+xLaILA9ZMuIDxx/xbSWzq7MDltsTirGWWs5hkBTVZXtfww+p20ugA3hd1vc7FwlwmtiN",
+	"1L93Kweg5nSHmgDftCV+N3OrtEs9w0t/6LFplWLaSn9+aYTZ+3pG2OejFyMeAM2wRwf7h4fNxr77pDs3",
+	"1nM1StLReDz+tpOg3yXpeUdQ2yPlQPdjZGE34XyiNnWsNrXb6mcrrNOr0paQ9Tfu9TfCPcNmQ3JW+hf8",
+	"E/Efvpahb54fhX6212Wq4wOz9q8EWTga2Mk7W+aarFbMHjSbq7hBQJYq71ffrd9pNVfJIdz1blotV5y2",
+	"5M9kFXfGSzQzUtjJhcxu1sJNUUqMYEo5YFXMWdeVpb7y1Fce+4W52Ql4Nw3jaS5QWsudMeCbfBRSUHWC",
+	"VvyCLnuYJV0xbOBStZs5IZsVkWqGmdZhYUDhXstvXDPwHFfCewe06QPRJnfVojtkS+oNN1miY0doqVjv",
+	"XSZVi3Suga9iJTWG+NSxO/dTMVr73FuG+jeZhlfHLjUelD4xT8gUqMS+d8p66opU6jntRi2eH7PepDk7",
+	"TG6lcUWRaCZ0WqxnMqogR/coyudKDlLkV7J3r+wxBDEJrnYHQWEUGOtegGztnNrtxkeVzvvGdLaqhYyM",
+	"Q6+j//KMNJN3yWTbmuDPXAMnFrSS/DXBH1mSve6UTRoW1sodEfbkJ7lIVIo4f05HgDVXnN31HEVh76LY",
+	"IEUZyFhczZqV0vI4ENf1cOQPby4+eshYUmhu2R9n4vcQYzmWdSjpK+nCtAE5BpyhmMvLWEuXeKcuouQm",
+	"G8q0Jn5EVIuzh6L+Qvhr7Gbub/xZGFEtIJlJnXkCc2EyRbeap5G05pgSA+0r2zGwnfDouUyAo9OVTShK",
+	"IEORe56oaHsnE3UqW2QysADEhDCWmRZJyThmxk/2WEnUpiF1FlAuae7rhJrK5MRAv14lwbaS7k9mq8RP",
+	"J6ocNBPPOtGQ7Mqpi6tR6n83S8NaRbkwObltJ6EzxnPjZtkYEV/mREOCR9M93N+/x2IZzL2VdwTqbpM/",
+	"d+peTdWjgRRQiwLVGApmqN/gLqD9Ea/PNSsNh8krP1CXF3zyos8nZzIiiEgzLUG7sWksLVNvqQlhJQZO",
+	"WCGx7hN+OdFyy5Rkm8nn0vn2CwXWM+XPzINhIzN9d6K6+aCVR0YN4+M/mtBRVlqGDkfa60FLVyG2lHG/",
+	"kqbZlVws9BoaqFLF2093P2JtyaAfHeV3GRwdCm/zieCU1ERG7c6qQuEbSWxlOgdFd8sJ189/7RTAtG5D",
+	"s7oGkUKNDXc8Bkf7R92fqAT/D3Ju3pNgr+dNd57EmIp/n3GQCPNHcBcVbFy5VX/z3tPxKku2fB44nQnP",
+	"UfVZ1b1mzMOosB3DSQxTRPLpsE8halBP9GAdp09mmpxt7aBCOm/Ka9I+cGdk8W4/X/fA8z6VREpJw4GH",
+	"56r4tAbBQ2CFe29MUqqH+4SKJSfjIO2fXOesFiKMuEVcmFT01DZ2bhVPvwev0AbjaoV2eRL7ELSDR5tE",
+	"827rWjFS3Hmq21ZtrcNy4kAQix6Q114jUfhZ5IbBPGYZnxSCM1SzADFSJQccY9v4A/0byFMhC66ll030",
+	"bKGjr3LEe+25KpfxJPcEboxfnUnf7Z4EVJenWcbgz1lnR8XW4+79DaxaP/ff4ocnLu5aXo/ALe0yiWZE",
+	"O5V1C3QJZIO6PMhU7LonjhmcxaRf0aWovKQMBvD8iKpJeLzvwdMcA4Ymle/sT/vK8soNURUYIwXYL+sI",
+	"KyWDlZDPcLmx3R+kmFCjfTKz4CNilnLlaN7P19YKUrlOjAooRcgHo04uqBmbopWtnzgj1HzVaAFJMRR5",
+	"Ldz7oFJx9tiFwvB4eST+xeVU85UJzK5oIFXsNSR4Cj5Gbnh/1MHjHGAZ1JFSP7awMdDMwcNwfUkasDzT",
+	"gVkCkyMAFRZWZ1U76bos6+BRr5Fq7VfnDVJdctOZr5/e6qcm/DkbpoS+7UTVIXqU2j5KEQqsRSxwGnxm",
+	"mdq26CuZzS7V4Q+lsOxfn66e1ns348tjayOVINLT2IEhJeoTp4tCI2CUgSeopu3rNDA78NQuvfcYN1Id",
+	"Aw2EpmITEp+pts+IHX4nXBepEa3fs9E08+ijsjyGTM4pS6RifjdZdoSLjSihyYAemxa43Eqmk9E5ik+w",
+	"PkYXUBrBYxF5mJ8ygv9zTqqlDy30eUmxF+gCYFbtmG2Ve9HYk7n8VMSAnuULHaNFlmCa2mW8wWADYrO4",
+	"UgtH1ktfMLLdsY3EPriLSmWPR7p+m2rgfOUruLGOiUt9b0P/27iHrYI0ukCYgc9Zw+lZUYmSxnv4NRWv",
+	"CBfWpZupeDzqn3vYui5Wrn/ymLdqpcKKc7vIvwxnrWZqg4674DIdTXdmStk7R9r41y6GcOtoy8GNVbtZ",
+	"KFgvDLiNqbeUM3INeEYO0i6tbD1UWRdN0kWZXCpalRTI1PQbtZ/MOk7tZZweVcfjSsbq2Ghuxit/MJmI",
+	"t9K1hxZ7K31UGVnKguqtivsoKgP9bZ291tWPvVea7CuCzjG9cN3rTEtA45/ZPcVYFyOMAmDP9vC6yLH9",
+	"NdcQ+79cRBBQYynsWbiuATQOXJQuuK1YaNYes+bntUyvCTP1fN3o2VR2oMFeoceHa1RWMHWNyoCvjGh2",
+	"N5IRY8deQ8SYsScjHel2XI95IyhhG/rquOLsLN9SQjlMm57jGGr/T87PDcjGSYkue5dm2KGMfjNCEVRU",
+	"natKSb1KS5lbVOFnrKKKpJhVZLIG5Sai6GjeFKctSGXWKAG7S4ic4dxZrTKTb8mxg+qwdK3CSnjC5YJU",
+	"bhSZbijERjNKv+jESvmu2ZxVn0EayOQRRmKPYwqiNPMUDjlAT6UXIcdyTKxx2US6M3bKcRyNQVnz1i72",
+	"EpTuebKGbS9MuICRJOFjqdI1HVR3pg3zwe6Myfj0ix72GV7dbXoXN8ScL8XYs88Hx6IYqYFV3gOga685",
+	"dJUdHKyGmGEAIHItBS0+S8w40SCX/e/OHcoe1pf5UQZvyqjPMt+XzIcgQzddM5FxojuhJYXIgdCEBD03",
+	"MlGBjBMFBuMAYB3DNRQGQ3KhGvJBBqjgfMOAnNeiG3+bqXoTgXtbqN/KpjQSYZzCkxmNa3HSLTZjfdM/",
+	"EddP8zBDlOsMSZdtGfCcwtGllVkqZV9jDtDSbcGl07nQbx/PrFwJDXwSq3I1H4JTxDAyzz6MQHh0ePhw",
+	"mkelQFGGnFYNpNbsBIngOo/kU8qluoRg4lD6Cz8MHtPFLFGw7i3TwF9PJGPTYhWVAZaU9EpFW67hVgo3",
+	"kZlmK6bcLPESgx4UqtfQflZEV7JDgyN+DOR/VY70RPoQawbNyILNSoiVKhFEisP9H772dN5LTZcqN/ZE",
+	"VJmg4teifNvptIXYsgBfmxpz7cesY+C2JVbXRY3+6H1KfX0F7OaBnhC51QQ6cFsC91ERu3sqFbwGcTpZ",
+	"G+RrnhTAWyGlngk542DvSZFfgm0HjIfOc1np2o3yH7hBiec6201Vdp5hRntMZMePleRZx3bZ5Sm2e0xk",
+	"t8Z5QpyvzKPFYSqKGHrIrmecm7TK0zz0Keg9uW+EyPfGxx7IL5PVNKkLL0qtvkbyAuk5FZA7P/vbG0pm",
+	"jHplmX+TZTWVO5LDZTjfMQtXlEZUZwbMvEupLLocVBV3VOfbUHPlvDr5p1ry0NY4lnaxPNkYSi3UEbB1",
+	"rJpLlRIDcYkZ+PqcU5LiIT7c99ZJlpcq9XUSsCFOd1sJhnRpMRnAffWYEt4SYBjDpsyE/tLHcrQ1+JI2",
+	"vwQv5dTJ9O406TjVT0uDcC7iJdpjDoysot3KkVLxr6x891D9H5iq/xdPqfl3Z4VrtsnJxT8VTZCz2OHk",
+	"P5Arb5OkDt2WYvpu3p2l9/7X2OE+0vWTe+9mlYk06VtaXeNUJ5l0idLucGbKHirBgq6bmpdXajdWaGtv",
+	"BElvbkK4sYH5k9pdFwkszFxL98aGx/LDu4vC50mQscMH7+t6+8oElHnqx5ziBnHHCLTmAO0nOTcNWN+T",
+	"NE5U9vMejmqG6ogT5dqZ09EjnhRZRpzx8DIO4xUWXyUvHDIQxBioKysOhMjibV2nSfX97Z6n1/YMn0qF",
+	"Wp1FMzL/auyfFZzztVFWzRkPERaFcQbwtWJtqb4x/+pQ4GDERkV1U9oplXerugHqSh6ZxUGK/2PvhDNh",
+	"6vfrIiP9gP5yEaZZ7sLtwFQCPSzfcNQcXb6pQeTrR09clLZDU+7xntWAJ8vN0kQpQ+LThJ46sGhXXF2B",
+	"ADfij0eUmGlXtJXiLicLkhJwI/6iJxkXrQDKA53QiICEJ6bdFtO5hSwqcqoo/giL1oCYiXUrgFzDjetJ",
+	"rCAnCCmSwV8k6Ay12wxl66IXZoa5PRfmIyxYG/cGxyVdxNc6BzSirTr4Fs8EbwhAkQ4Iz3dSJp7+Zo5B",
+	"LGdqAbTvmdBptpvZjgtB3vCebipz+6Pc7Wv3SO1jMPdZYUNZOC9jpU/2lqk/F8Q8uvBRd/6NC3FnlXn2",
+	"wqcylfnTMtFqQojQYVxuHaWsfBJ81uCsY1JfDGZPpzZSfmqTb4tzZkqbc4kw7TS1FXkDr/BVCeWpNV9J",
+	"Fr8NFJI0ErCotD2IpwqzdG3vbi4i2ipv9TE05ExJ0uia50aodrROUM2hlDp9UIx5iHiiSpzS2QJ2442R",
+	"haytup4UQev5kZhvQT+K+DvpRtFUHnG9yZtLFfJ7qg6C145O4N0d0sQdf42gpgfSq2hq8//Ygf7/1J/n",
+	"TuyXmTiqPdCCPDYxV7JLb2Mn3xmWsaKXsRphaJR0YysZ/ZZmBBdLVmrU36pZfqNM2WsDJB2xxSXoNOif",
+	"TMk+d06nJ+Zkqm5DN+pQuJ9ujykDud5eUKQqdbHGHGhyQ3hDTylBebJQEVZ5aYbZJCF6GYfs0t2OPrrE",
+	"xDdrmanVwHAgz18tKD4d1ti72YIuWB5kpOrEdmMJF2cp68rq1HihLMGoLTG129+592bFky4zdL34KTEA",
+	"2hdgXvbjMvCamap3SXhXD6ExQwvVIP3s2V/Vb9tZTabNedva26eyGVNaII1WlTk14zHl3OOMfc1ofFHM",
+	"8OdMsDeAlS5Vu5BQKfHRBerR3vCLZxcXb/bIxZ/qfAdE1mSav0x97VPBya2XzOdFqo8DxUxSDPP338Mh",
+	"F99/f+zZ3bBnRH3Qm1U4XymGC7PNXwsMgco4/Sx7jWDKbUyvh7lYgQUO8XMsvqiKo1KWUjSFYh+I/3hO",
+	"kGobExh7bynTnodZDOfcR6WuFEUp8HFhIB1joNa/wi5dEHAnHwGt+c9j71eaKq9CVl6CKeIHPyfHXvoc",
+	"9hVwNCNrQhauYZ9Tc+gonKWACNj+HUX7R368LPDOO/bO5Z8jfcHUPgTpxeXuQlvFgO2f0I+z6OKR7RnU",
+	"RR98lO13DOx6o769Q1CXnLJExIdLRdg9EFYabhwEXt4716EzD2eZghrV6Mfe50vqmUqfxOJmqhIpUa0T",
+	"POHAoqw39BqLgoz2D+C/jweHx/v78N8/qBn2BC2gK/X1NAzoE/w9PTh8Ts3KpKn0Dn9Oj1685JGwdjKu",
+	"nV6JWzEvcjGVtOsSlotk4Ni7EmLjR3BG8Gd9AWoEtmFPZcXcXks5qC6lbbZJFMgx6J1UUtArhKHxSjv7",
+	"8hpaokEdZIx3bey98YGa8YniSr9cn5d9eI7rgPAu+ThN+ffQs5cPP88u3v34cv+A38k1e5/H4zED+m8K",
+	"zJpp5jK+OAMOwXq+r9JDHFf2pU+6ViQ9Zp7tmutTAyiMOwyooJXyNMuEke8UE6uPMqNwQztnRlXrsGqe",
+	"SEU8l6kODPeCGu9l1Qt4RD7EWeHAAVBsV3oBPXJqr8Ic7G45vXYDeFGrSPKo+btcpU++spKr776rNt9i",
+	"Gq8eaPKFyuJxNYpRYJY5aPDPUQlE/FrFBsKgG5nlCOtoW4Uoaih1Xa2B8UgY1Vgi5CsjVHPNj1Y1n+H3",
+	"xXqsB0EQNZnqJiIpcGWWwY/pSnDxnOdUM0Bmk+FmVn2J4wkXmMQKU8c/wD9V+gs5KDlU7S4m0UOmeFFh",
+	"regVpxj/rOTUZGoXR6i70kKHCzHfzkEgKytRGJ+XUb+1guiYj3kUxiPA6VGUJBuvXr2i7OjESDldv+ga",
+	"qluUn0sG3+EVTgXIuOKYXj6rQyPaYhJZzBI+skfKZe7muJVwpxQBXMn2OlyqaDLZBWNAvYsTu0IEfe8C",
+	"riwx8OXTl/8GXOJi26oDAQA=",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
@@ -0,0 +1,77 @@
+package approval
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+
+	"github.com/humanlayer/humanlayer/hld/artifacts"
+)
+
+// MaxAttachments is how many files an approval request can carry
+const MaxAttachments = 5
+
+// maxAttachmentSize bounds each attached file
+const maxAttachmentSize = 10 << 20
+
+// AttachmentKind is the artifact kind attachments are published as
+const AttachmentKind = "approval_attachment"
+
+// attachmentTypes are what an approver's client can display: screenshots,
+// and text such as a terminal capture
+var attachmentTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+	"text/plain": true,
+}
+
+// ErrInvalidAttachment is returned for attachments that are refused
+var ErrInvalidAttachment = errors.New("invalid attachment")
+
+// Attachment is a file sent with an approval request
+type Attachment struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// PublishAttachments checks attachments and stores them as artifacts of
+// sessionID, returning their IDs in order. Nothing is stored unless every
+// attachment is acceptable.
+func PublishAttachments(ctx context.Context, service *artifacts.Service, sessionID string, attachments []Attachment) ([]string, error) {
+	if len(attachments) == 0 {
+		return nil, nil
+	}
+	if len(attachments) > MaxAttachments {
+		return nil, fmt.Errorf("%w: at most %d are allowed", ErrInvalidAttachment, MaxAttachments)
+	}
+	for _, attachment := range attachments {
+		mediaType, _, err := mime.ParseMediaType(attachment.ContentType)
+		if err != nil || !attachmentTypes[mediaType] {
+			return nil, fmt.Errorf("%w: %s has type %q; images and text/plain are allowed", ErrInvalidAttachment, attachment.Name, attachment.ContentType)
+		}
+		if len(attachment.Data) == 0 || len(attachment.Data) > maxAttachmentSize {
+			return nil, fmt.Errorf("%w: %s must have between 1 and %d bytes", ErrInvalidAttachment, attachment.Name, maxAttachmentSize)
+		}
+	}
+
+	ids := make([]string, 0, len(attachments))
+	for _, attachment := range attachments {
+		artifact, err := service.Publish(ctx, sessionID, attachment.Name, AttachmentKind, attachment.ContentType, bytes.NewReader(attachment.Data))
+		if errors.Is(err, artifacts.ErrInvalidName) {
+			err = fmt.Errorf("%w: %q isn't a valid file name", ErrInvalidAttachment, attachment.Name)
+		}
+		if err != nil {
+			for _, id := range ids {
+				_ = service.Delete(ctx, id)
+			}
+			return nil, err
+		}
+		ids = append(ids, artifact.ID)
+	}
+	return ids, nil
+}
@@ -0,0 +1,48 @@
+package approval
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// ParseAutoDenyTools decodes a session's auto_deny_tools JSON array
+func ParseAutoDenyTools(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+	var tools []string
+	if err := json.Unmarshal([]byte(raw), &tools); err != nil {
+		return nil
+	}
+	return tools
+}
+
+// MatchesAutoDenyTool reports whether toolName matches one of the patterns.
+// Patterns are exact tool names, or prefixes when they end in "*"
+// (e.g. "mcp__github__*").
+func MatchesAutoDenyTool(patterns []string, toolName string) bool {
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(toolName, prefix) {
+				return true
+			}
+		} else if pattern == toolName {
+			return true
+		}
+	}
+	return false
+}
+
+// autoDenyComment returns the denial comment when the session's auto-deny
+// configuration covers toolName
+func autoDenyComment(session *store.Session, toolName string) (string, bool) {
+	if session.AutoDenyAll {
+		return "Auto-denied (observation-only session)", true
+	}
+	if MatchesAutoDenyTool(ParseAutoDenyTools(session.AutoDenyTools), toolName) {
+		return "Auto-denied (tool is on the session's auto-deny list)", true
+	}
+	return "", false
+}
@@ -0,0 +1,129 @@
+package approval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// Budget caps what a session (including the sessions it continues from) may
+// use. Once a limit is exceeded every further approval is denied. Zero means
+// unlimited.
+type Budget struct {
+	MaxToolCalls       int     `json:"max_tool_calls,omitempty"`
+	MaxCostUSD         float64 `json:"max_cost_usd,omitempty"`
+	MaxDurationSeconds int     `json:"max_duration_seconds,omitempty"`
+}
+
+// BudgetUsage is what a session has used so far
+type BudgetUsage struct {
+	ToolCalls       int     `json:"tool_calls"`
+	CostUSD         float64 `json:"cost_usd"`
+	DurationSeconds int     `json:"duration_seconds"`
+}
+
+// IsZero reports whether the budget has no limits
+func (b Budget) IsZero() bool {
+	return b.MaxToolCalls <= 0 && b.MaxCostUSD <= 0 && b.MaxDurationSeconds <= 0
+}
+
+// ParseBudget decodes a session's budget JSON object
+func ParseBudget(raw string) Budget {
+	var b Budget
+	if raw == "" {
+		return b
+	}
+	if err := json.Unmarshal([]byte(raw), &b); err != nil {
+		return Budget{}
+	}
+	return b
+}
+
+// EncodeBudget encodes a budget for storage, returning "" when it has no limits
+func EncodeBudget(b Budget) (string, error) {
+	if b.IsZero() {
+		return "", nil
+	}
+	data, err := json.Marshal(b)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal budget: %w", err)
+	}
+	return string(data), nil
+}
+
+// Exceeded returns a description of every limit the usage is over
+func (b Budget) Exceeded(u BudgetUsage) (string, bool) {
+	var over []string
+	if b.MaxToolCalls > 0 && u.ToolCalls > b.MaxToolCalls {
+		over = append(over, fmt.Sprintf("%d of %d tool calls", u.ToolCalls, b.MaxToolCalls))
+	}
+	if b.MaxCostUSD > 0 && u.CostUSD > b.MaxCostUSD {
+		over = append(over, fmt.Sprintf("$%.2f of $%.2f", u.CostUSD, b.MaxCostUSD))
+	}
+	if b.MaxDurationSeconds > 0 && u.DurationSeconds > b.MaxDurationSeconds {
+		over = append(over, fmt.Sprintf("%s of %s", time.Duration(u.DurationSeconds)*time.Second, time.Duration(b.MaxDurationSeconds)*time.Second))
+	}
+	return strings.Join(over, ", "), len(over) > 0
+}
+
+// MeasureBudgetUsage adds up tool calls, cost and run time across a session
+// and the sessions it continues from. Cost is only known for finished runs.
+func MeasureBudgetUsage(ctx context.Context, s store.ConversationStore, session *store.Session) (BudgetUsage, error) {
+	var usage BudgetUsage
+
+	events, err := s.GetSessionConversation(ctx, session.ID)
+	if err != nil {
+		return usage, fmt.Errorf("failed to get conversation: %w", err)
+	}
+	for _, event := range events {
+		if event.EventType == store.EventTypeToolCall {
+			usage.ToolCalls++
+		}
+	}
+
+	var duration time.Duration
+	current := session
+	for depth := 0; current != nil && depth < 100; depth++ {
+		if current.CostUSD != nil {
+			usage.CostUSD += *current.CostUSD
+		}
+		switch {
+		case current.DurationMS != nil:
+			duration += time.Duration(*current.DurationMS) * time.Millisecond
+		case current.CompletedAt == nil:
+			duration += time.Since(current.CreatedAt)
+		}
+
+		if current.ParentSessionID == "" {
+			break
+		}
+		parent, err := s.GetSession(ctx, current.ParentSessionID)
+		if err != nil {
+			// Parent may have been deleted; count what we have
+			break
+		}
+		current = parent
+	}
+	usage.DurationSeconds = int(duration / time.Second)
+
+	return usage, nil
+}
+
+// budgetExceeded checks the session's budget, returning why it is exceeded
+func (m *manager) budgetExceeded(ctx context.Context, session *store.Session) (string, bool) {
+	budget := ParseBudget(session.Budget)
+	if budget.IsZero() {
+		return "", false
+	}
+	usage, err := MeasureBudgetUsage(ctx, m.store, session)
+	if err != nil {
+		slog.Warn("failed to measure session budget usage", "session_id", session.ID, "error", err)
+		return "", false
+	}
+	return budget.Exceeded(usage)
+}
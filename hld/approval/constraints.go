@@ -0,0 +1,62 @@
+package approval
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// constrainedFileKeys are the tool input fields that name the file a tool
+// call reads or writes
+var constrainedFileKeys = []string{"file_path", "notebook_path", "path"}
+
+// CheckConstraints returns how a tool call run at the given time with input
+// breaks the constraints it was approved with. Only the kind and detail of
+// each violation are set. A file list can't be checked against tool calls
+// that don't name a file, such as shell commands.
+func CheckConstraints(constraints *store.ApprovalConstraints, input json.RawMessage, at time.Time) []store.ConstraintViolation {
+	if constraints == nil {
+		return nil
+	}
+	var violations []store.ConstraintViolation
+	if constraints.ExpiresAt != nil && at.After(*constraints.ExpiresAt) {
+		violations = append(violations, store.ConstraintViolation{
+			Kind:   store.ConstraintViolationExpired,
+			Detail: fmt.Sprintf("ran at %s, after the approval expired at %s", at.Format(time.RFC3339), constraints.ExpiresAt.Format(time.RFC3339)),
+		})
+	}
+	if len(constraints.Files) > 0 {
+		allowed := make([]string, len(constraints.Files))
+		for i, f := range constraints.Files {
+			allowed[i] = filepath.Clean(f)
+		}
+		for _, path := range inputFiles(input) {
+			if !slices.Contains(allowed, filepath.Clean(path)) {
+				violations = append(violations, store.ConstraintViolation{
+					Kind:   store.ConstraintViolationFile,
+					Detail: path + " is not in the approved file list",
+				})
+			}
+		}
+	}
+	return violations
+}
+
+// inputFiles returns the files a tool input names
+func inputFiles(input json.RawMessage) []string {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(input, &fields); err != nil {
+		return nil
+	}
+	var files []string
+	for _, key := range constrainedFileKeys {
+		if path, ok := fields[key].(string); ok && path != "" {
+			files = append(files, path)
+		}
+	}
+	return files
+}
@@ -0,0 +1,28 @@
+package approval
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckConstraints(t *testing.T) {
+	expiresAt := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	constraints := &store.ApprovalConstraints{ExpiresAt: &expiresAt, Files: []string{"/repo/src/main.go", "/repo/src/./main_test.go"}}
+	edit := json.RawMessage(`{"file_path":"/repo/src/main_test.go","old_string":"a","new_string":"b"}`)
+
+	assert.Empty(t, CheckConstraints(constraints, edit, expiresAt.Add(-time.Minute)))
+	assert.Empty(t, CheckConstraints(nil, edit, expiresAt.Add(time.Hour)))
+	assert.Empty(t, CheckConstraints(constraints, json.RawMessage(`{"command":"make"}`), expiresAt), "commands name no files to check")
+
+	violations := CheckConstraints(constraints, json.RawMessage(`{"file_path":"/repo/.env"}`), expiresAt.Add(time.Minute))
+	require.Len(t, violations, 2)
+	assert.Equal(t, store.ConstraintViolationExpired, violations[0].Kind)
+	assert.Equal(t, "ran at 2026-01-05T09:01:00Z, after the approval expired at 2026-01-05T09:00:00Z", violations[0].Detail)
+	assert.Equal(t, store.ConstraintViolationFile, violations[1].Kind)
+	assert.Equal(t, "/repo/.env is not in the approved file list", violations[1].Detail)
+}
@@ -0,0 +1,148 @@
+package approval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// FakeManager is an in-memory Manager for tests and embedders. It keeps
+// approvals in a map and never touches a store or event bus. New approvals
+// take InitialStatus (pending by default), so a test can make every request
+// auto-approve or auto-deny by setting it.
+type FakeManager struct {
+	InitialStatus store.ApprovalStatus
+
+	mu        sync.Mutex
+	approvals map[string]*store.Approval
+}
+
+// NewFakeManager creates an empty FakeManager
+func NewFakeManager() *FakeManager {
+	return &FakeManager{
+		InitialStatus: store.ApprovalStatusLocalPending,
+		approvals:     make(map[string]*store.Approval),
+	}
+}
+
+// CreateApproval records a new approval for the run
+func (f *FakeManager) CreateApproval(ctx context.Context, runID, toolName string, toolInput json.RawMessage) (string, error) {
+	approval := f.newApproval(runID, "", toolName, toolInput, nil, nil)
+	return approval.ID, nil
+}
+
+// CreateApprovalWithAttachments records a new approval for the run with
+// the given attachments
+func (f *FakeManager) CreateApprovalWithAttachments(ctx context.Context, runID, toolName string, toolInput json.RawMessage, attachments []string) (string, error) {
+	approval := f.newApproval(runID, "", toolName, toolInput, nil, attachments)
+	return approval.ID, nil
+}
+
+// CreateApprovalWithToolUseID records a new approval for the session and tool use
+func (f *FakeManager) CreateApprovalWithToolUseID(ctx context.Context, sessionID, toolName string, toolInput json.RawMessage, toolUseID string) (*store.Approval, error) {
+	approval := f.newApproval("", sessionID, toolName, toolInput, &toolUseID, nil)
+	copied := *approval
+	return &copied, nil
+}
+
+// GetPendingApprovals returns the session's pending approvals, oldest first
+func (f *FakeManager) GetPendingApprovals(ctx context.Context, sessionID string) ([]*store.Approval, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var pending []*store.Approval
+	for _, a := range f.approvals {
+		if a.SessionID == sessionID && a.Status == store.ApprovalStatusLocalPending {
+			copied := *a
+			pending = append(pending, &copied)
+		}
+	}
+	sort.SliceStable(pending, func(i, j int) bool { return pending[i].CreatedAt.Before(pending[j].CreatedAt) })
+	return pending, nil
+}
+
+// GetApproval returns an approval by ID
+func (f *FakeManager) GetApproval(ctx context.Context, id string) (*store.Approval, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	a, ok := f.approvals[id]
+	if !ok {
+		return nil, fmt.Errorf("failed to get approval: %w", &store.NotFoundError{Type: "approval", ID: id})
+	}
+	copied := *a
+	return &copied, nil
+}
+
+// ApproveToolCall marks a pending approval as approved
+func (f *FakeManager) ApproveToolCall(ctx context.Context, id string, comment string, imagePaths []string) error {
+	return f.decide(id, store.ApprovalStatusLocalApproved, comment)
+}
+
+// DenyToolCall marks a pending approval as denied
+func (f *FakeManager) DenyToolCall(ctx context.Context, id string, reason string, imagePaths []string) error {
+	return f.decide(id, store.ApprovalStatusLocalDenied, reason)
+}
+
+// Approvals returns every recorded approval, oldest first
+func (f *FakeManager) Approvals() []*store.Approval {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	all := make([]*store.Approval, 0, len(f.approvals))
+	for _, a := range f.approvals {
+		copied := *a
+		all = append(all, &copied)
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].CreatedAt.Before(all[j].CreatedAt) })
+	return all
+}
+
+func (f *FakeManager) newApproval(runID, sessionID, toolName string, toolInput json.RawMessage, toolUseID *string, attachments []string) *store.Approval {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	status := f.InitialStatus
+	if status == "" {
+		status = store.ApprovalStatusLocalPending
+	}
+	approval := &store.Approval{
+		ID:          "local-" + uuid.New().String(),
+		RunID:       runID,
+		SessionID:   sessionID,
+		ToolUseID:   toolUseID,
+		Status:      status,
+		CreatedAt:   time.Now(),
+		ToolName:    toolName,
+		ToolInput:   toolInput,
+		Attachments: attachments,
+	}
+	f.approvals[approval.ID] = approval
+	return approval
+}
+
+func (f *FakeManager) decide(id string, status store.ApprovalStatus, comment string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	a, ok := f.approvals[id]
+	if !ok {
+		return fmt.Errorf("failed to get approval: %w", &store.NotFoundError{Type: "approval", ID: id})
+	}
+	if a.Status != store.ApprovalStatusLocalPending {
+		return &store.AlreadyDecidedError{ID: id, Status: a.Status.String()}
+	}
+	now := time.Now()
+	a.Status = status
+	a.Comment = comment
+	a.RespondedAt = &now
+	return nil
+}
+
+var _ Manager = (*FakeManager)(nil)
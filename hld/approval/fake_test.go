@@ -0,0 +1,67 @@
+package approval
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeManager(t *testing.T) {
+	ctx := context.Background()
+	f := NewFakeManager()
+
+	a, err := f.CreateApprovalWithToolUseID(ctx, "sess-1", "Bash", json.RawMessage(`{}`), "toolu_1")
+	require.NoError(t, err)
+	assert.Equal(t, store.ApprovalStatusLocalPending, a.Status)
+
+	pending, err := f.GetPendingApprovals(ctx, "sess-1")
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+
+	require.NoError(t, f.DenyToolCall(ctx, a.ID, "no", nil))
+	var decided *store.AlreadyDecidedError
+	assert.ErrorAs(t, f.ApproveToolCall(ctx, a.ID, "", nil), &decided)
+
+	got, err := f.GetApproval(ctx, a.ID)
+	require.NoError(t, err)
+	assert.Equal(t, store.ApprovalStatusLocalDenied, got.Status)
+	assert.Equal(t, "no", got.Comment)
+
+	f.InitialStatus = store.ApprovalStatusLocalApproved
+	a, err = f.CreateApprovalWithToolUseID(ctx, "sess-1", "Read", json.RawMessage(`{}`), "toolu_2")
+	require.NoError(t, err)
+	assert.Equal(t, store.ApprovalStatusLocalApproved, a.Status)
+	assert.Len(t, f.Approvals(), 2)
+}
+
+// TestManagerWithInMemoryDependencies exercises the real manager without SQLite
+func TestManagerWithInMemoryDependencies(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	b := bus.NewInMemoryBus()
+	m := NewManager(s, b)
+
+	now := time.Now()
+	require.NoError(t, s.CreateSession(ctx, &store.Session{ID: "sess-1", RunID: "run-1", Status: store.SessionStatusRunning,
+		CreatedAt: now, LastActivityAt: now, AutoAcceptEdits: true}))
+
+	a, err := m.CreateApprovalWithToolUseID(ctx, "sess-1", "Edit", json.RawMessage(`{}`), "toolu_1")
+	require.NoError(t, err)
+	assert.Equal(t, store.ApprovalStatusLocalApproved, a.Status)
+	require.Len(t, b.EventsOfType(bus.EventApprovalResolved), 1)
+
+	a, err = m.CreateApprovalWithToolUseID(ctx, "sess-1", "Bash", json.RawMessage(`{}`), "toolu_2")
+	require.NoError(t, err)
+	require.NoError(t, m.ApproveToolCall(ctx, a.ID, "go ahead", nil))
+
+	sess, err := s.GetSession(ctx, "sess-1")
+	require.NoError(t, err)
+	assert.Equal(t, store.SessionStatusRunning, sess.Status)
+	assert.Len(t, b.EventsOfType(bus.EventApprovalResolved), 2)
+}
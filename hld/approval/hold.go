@@ -0,0 +1,171 @@
+package approval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/scheduler"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// holdCheckInterval bounds how late a hold is lifted after its resume time
+const holdCheckInterval = 30 * time.Second
+
+var (
+	// ErrHoldReasonRequired is returned for holding an approval without a reason
+	ErrHoldReasonRequired = errors.New("a reason is required to hold an approval")
+	// ErrInvalidResume is returned for a resume time that has already passed
+	ErrInvalidResume = errors.New("resume time must be in the future")
+	// ErrNotHeld is returned for unholding an approval that isn't on hold
+	ErrNotHeld = errors.New("approval is not on hold")
+)
+
+// Holds parks pending approvals. A held approval stays pending, so the
+// agent keeps waiting, but it's told why, and the approval isn't escalated
+// while it's held. Holds with a resume time are lifted at that time.
+type Holds struct {
+	store    store.ConversationStore
+	holds    store.HoldStore
+	eventBus bus.EventBus
+	now      func() time.Time
+}
+
+// NewHolds creates a hold service keeping holds in holds
+func NewHolds(s store.ConversationStore, holds store.HoldStore, eventBus bus.EventBus) *Holds {
+	return &Holds{store: s, holds: holds, eventBus: eventBus, now: time.Now}
+}
+
+// Hold parks a pending approval with a reason until resumeAt, or until it is
+// unheld when resumeAt is nil. Holding a held approval replaces its hold.
+func (h *Holds) Hold(ctx context.Context, id, reason string, resumeAt *time.Time, by string) (*store.ApprovalHold, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrHoldReasonRequired
+	}
+	if resumeAt != nil && !resumeAt.After(h.now()) {
+		return nil, ErrInvalidResume
+	}
+	approval, err := h.store.GetApproval(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if approval.Status != store.ApprovalStatusLocalPending {
+		return nil, &store.AlreadyDecidedError{ID: id, Status: approval.Status.String()}
+	}
+
+	hold := &store.ApprovalHold{
+		ApprovalID: id,
+		SessionID:  approval.SessionID,
+		Reason:     reason,
+		ResumeAt:   resumeAt,
+		HeldBy:     by,
+		CreatedAt:  h.now(),
+	}
+	if err := h.holds.HoldApproval(ctx, hold); err != nil {
+		return nil, err
+	}
+	slog.Info("held approval", "approval_id", id, "reason", reason, "resume_at", resumeAt)
+
+	data := holdEventData(approval)
+	data["reason"] = reason
+	if resumeAt != nil {
+		data["resume_at"] = resumeAt.Format(time.RFC3339)
+	}
+	h.publish(bus.EventApprovalHeld, data)
+	return hold, nil
+}
+
+// Unhold puts a held approval back in the queue
+func (h *Holds) Unhold(ctx context.Context, id string) error {
+	released, err := h.holds.ReleaseApprovalHold(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !released {
+		return ErrNotHeld
+	}
+	slog.Info("unheld approval", "approval_id", id)
+	if approval, err := h.store.GetApproval(ctx, id); err == nil {
+		h.publish(bus.EventApprovalUnheld, holdEventData(approval))
+	}
+	return nil
+}
+
+// List returns the holds on approvals that are still pending, oldest first
+func (h *Holds) List(ctx context.Context) ([]*store.ApprovalHold, error) {
+	holds, err := h.holds.ListApprovalHolds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pending := []*store.ApprovalHold{}
+	for _, hold := range holds {
+		approval, err := h.store.GetApproval(ctx, hold.ApprovalID)
+		if err == nil && approval.Status == store.ApprovalStatusLocalPending {
+			pending = append(pending, hold)
+		}
+	}
+	return pending, nil
+}
+
+// Task is the maintenance task lifting holds as their resume time comes
+// and reaping the holds of approvals decided while held
+func (h *Holds) Task() scheduler.Task {
+	return scheduler.Task{
+		Name:        "approval-holds",
+		Description: "Lift approval holds as their resume time comes and drop holds of approvals no longer pending",
+		Interval:    holdCheckInterval,
+		RunAtStart:  true,
+		Run:         h.Wake,
+	}
+}
+
+// Wake lifts the holds whose resume time has come and drops the holds of
+// approvals decided while held
+func (h *Holds) Wake(ctx context.Context) error {
+	holds, err := h.holds.ListApprovalHolds(ctx)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, hold := range holds {
+		approval, err := h.store.GetApproval(ctx, hold.ApprovalID)
+		if err != nil && !errors.Is(err, store.ErrNotFound) {
+			errs = append(errs, err)
+			continue
+		}
+		switch {
+		case approval == nil || approval.Status != store.ApprovalStatusLocalPending:
+			if _, err := h.holds.ReleaseApprovalHold(ctx, hold.ApprovalID); err != nil {
+				errs = append(errs, err)
+			}
+		case hold.ResumeAt != nil && !hold.ResumeAt.After(h.now()):
+			if err := h.Unhold(ctx, hold.ApprovalID); err != nil && !errors.Is(err, ErrNotHeld) {
+				errs = append(errs, fmt.Errorf("approval %s: %w", hold.ApprovalID, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h *Holds) publish(eventType bus.EventType, data map[string]interface{}) {
+	if h.eventBus != nil {
+		h.eventBus.Publish(bus.Event{Type: eventType, Data: data})
+	}
+}
+
+func holdEventData(approval *store.Approval) map[string]interface{} {
+	data := map[string]interface{}{
+		"approval_id": approval.ID,
+		"session_id":  approval.SessionID,
+		"tool_name":   approval.ToolName,
+	}
+	if approval.ToolUseID != nil {
+		data["tool_use_id"] = *approval.ToolUseID
+	}
+	return data
+}
@@ -0,0 +1,85 @@
+package approval
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHoldAndUnhold(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := store.NewInMemoryStore()
+	toolUseID := "toolu_1"
+	require.NoError(t, s.CreateApproval(ctx, &store.Approval{ID: "appr-1", SessionID: "sess-1", ToolName: "Bash", ToolUseID: &toolUseID, Status: store.ApprovalStatusLocalPending}))
+	eventBus := bus.NewEventBus()
+	sub := eventBus.Subscribe(ctx, bus.EventFilter{Types: []bus.EventType{bus.EventApprovalHeld, bus.EventApprovalUnheld}})
+	h := NewHolds(s, s, eventBus)
+
+	_, err := h.Hold(ctx, "appr-1", "  ", nil, "")
+	assert.ErrorIs(t, err, ErrHoldReasonRequired)
+	past := time.Now().Add(-time.Minute)
+	_, err = h.Hold(ctx, "appr-1", "waiting on the freeze", &past, "")
+	assert.ErrorIs(t, err, ErrInvalidResume)
+	_, err = h.Hold(ctx, "missing", "waiting on the freeze", nil, "")
+	assert.ErrorIs(t, err, store.ErrNotFound)
+
+	resumeAt := time.Now().Add(time.Hour)
+	hold, err := h.Hold(ctx, "appr-1", "waiting on the freeze", &resumeAt, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "sess-1", hold.SessionID)
+	event := <-sub.Channel
+	assert.Equal(t, bus.EventApprovalHeld, event.Type)
+	assert.Equal(t, "toolu_1", event.Data["tool_use_id"])
+	assert.Equal(t, "waiting on the freeze", event.Data["reason"])
+	assert.Equal(t, resumeAt.Format(time.RFC3339), event.Data["resume_at"])
+
+	held, err := h.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, held, 1)
+	assert.Equal(t, "alice", held[0].HeldBy)
+
+	require.NoError(t, h.Unhold(ctx, "appr-1"))
+	event = <-sub.Channel
+	assert.Equal(t, bus.EventApprovalUnheld, event.Type)
+	assert.ErrorIs(t, h.Unhold(ctx, "appr-1"), ErrNotHeld)
+
+	require.NoError(t, s.UpdateApprovalResponse(ctx, "appr-1", store.ApprovalStatusLocalApproved, ""))
+	_, err = h.Hold(ctx, "appr-1", "too late", nil, "")
+	assert.ErrorIs(t, err, store.ErrAlreadyDecided)
+}
+
+func TestHoldsWake(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	for _, id := range []string{"due", "later", "decided"} {
+		require.NoError(t, s.CreateApproval(ctx, &store.Approval{ID: id, SessionID: "sess-1", ToolName: "Bash", Status: store.ApprovalStatusLocalPending}))
+	}
+	h := NewHolds(s, s, nil)
+	now := time.Now()
+	h.now = func() time.Time { return now }
+	soon, later := now.Add(time.Minute), now.Add(time.Hour)
+	_, err := h.Hold(ctx, "due", "back in a minute", &soon, "")
+	require.NoError(t, err)
+	_, err = h.Hold(ctx, "later", "back in an hour", &later, "")
+	require.NoError(t, err)
+	_, err = h.Hold(ctx, "decided", "until unheld", nil, "")
+	require.NoError(t, err)
+	require.NoError(t, s.UpdateApprovalResponse(ctx, "decided", store.ApprovalStatusLocalDenied, "no"))
+
+	held, err := h.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, held, 2, "holds of decided approvals aren't listed")
+
+	now = now.Add(2 * time.Minute)
+	require.NoError(t, h.Wake(ctx))
+	holds, err := s.ListApprovalHolds(ctx)
+	require.NoError(t, err)
+	require.Len(t, holds, 1)
+	assert.Equal(t, "later", holds[0].ApprovalID)
+}
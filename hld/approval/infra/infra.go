@@ -0,0 +1,288 @@
+// Package infra summarizes infrastructure changes found in approval tool
+// input: terraform plans, in text or `terraform show -json` form, and
+// kubectl diff output. The summary counts resources to add, change, replace
+// and destroy so reviewers don't have to read the raw plan.
+package infra
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Tools a summary can come from
+const (
+	ToolTerraform = "terraform"
+	ToolKubectl   = "kubectl"
+)
+
+// Resource actions
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionReplace = "replace"
+	ActionDelete  = "delete"
+	ActionRead    = "read"
+)
+
+// maxStringScan skips tool input strings too large to be worth parsing
+const maxStringScan = 4 << 20
+
+// ResourceChange is one resource in a plan or diff
+type ResourceChange struct {
+	Address string `json:"address"` // e.g. aws_instance.web or Deployment default/web
+	Action  string `json:"action"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+// Summary counts the changes in a plan or diff
+type Summary struct {
+	Tool      string           `json:"tool"`
+	Add       int              `json:"add"`
+	Change    int              `json:"change"`
+	Replace   int              `json:"replace"`
+	Destroy   int              `json:"destroy"`
+	Resources []ResourceChange `json:"resources"`
+	Text      string           `json:"summary"`
+}
+
+// Summarize looks through a tool input for a terraform plan or kubectl diff
+// and summarizes the first one found. It returns nil if there is none.
+func Summarize(toolInput json.RawMessage) *Summary {
+	if len(toolInput) == 0 {
+		return nil
+	}
+	var value any
+	if err := json.Unmarshal(toolInput, &value); err != nil {
+		return nil
+	}
+	return summarizeValue(value)
+}
+
+func summarizeValue(value any) *Summary {
+	switch v := value.(type) {
+	case map[string]any:
+		if summary := terraformJSON(v); summary != nil {
+			return summary
+		}
+		for _, field := range v {
+			if summary := summarizeValue(field); summary != nil {
+				return summary
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if summary := summarizeValue(item); summary != nil {
+				return summary
+			}
+		}
+	case string:
+		return summarizeText(v)
+	}
+	return nil
+}
+
+// summarizeText parses plan output, or a JSON plan embedded as a string
+func summarizeText(text string) *Summary {
+	if len(text) > maxStringScan {
+		return nil
+	}
+	trimmed := strings.TrimSpace(text)
+	if strings.HasPrefix(trimmed, "{") && strings.Contains(trimmed, `"resource_changes"`) {
+		var plan map[string]any
+		if json.Unmarshal([]byte(trimmed), &plan) == nil {
+			return terraformJSON(plan)
+		}
+	}
+	if summary := TerraformPlan(text); summary != nil {
+		return summary
+	}
+	return KubectlDiff(text)
+}
+
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+var (
+	terraformResource = regexp.MustCompile(`(?m)^\s*# (\S.*?) (will be created|will be updated in-place|must be replaced|will be replaced, as requested|will be destroyed|will be read during apply)\s*$`)
+	terraformTotals   = regexp.MustCompile(`Plan: (\d+) to add, (\d+) to change, (\d+) to destroy`)
+	terraformNoChange = regexp.MustCompile(`No changes\. (Your infrastructure matches the configuration|Infrastructure is up-to-date)`)
+)
+
+var terraformActions = map[string]string{
+	"will be created":                ActionCreate,
+	"will be updated in-place":       ActionUpdate,
+	"must be replaced":               ActionReplace,
+	"will be replaced, as requested": ActionReplace,
+	"will be destroyed":              ActionDelete,
+	"will be read during apply":      ActionRead,
+}
+
+// TerraformPlan summarizes `terraform plan` output, or returns nil if text
+// isn't a plan
+func TerraformPlan(text string) *Summary {
+	text = ansiEscape.ReplaceAllString(text, "")
+	matches := terraformResource.FindAllStringSubmatch(text, -1)
+	totals := terraformTotals.FindStringSubmatch(text)
+	if len(matches) == 0 && totals == nil && !terraformNoChange.MatchString(text) {
+		return nil
+	}
+	summary := &Summary{Tool: ToolTerraform, Resources: []ResourceChange{}}
+	for _, match := range matches {
+		summary.add(ResourceChange{Address: match[1], Action: terraformActions[match[2]]})
+	}
+	// Resource headers are missing when the plan was abbreviated; fall back
+	// to the totals, where a replacement counts as an add and a destroy
+	if len(matches) == 0 && totals != nil {
+		summary.Add, _ = strconv.Atoi(totals[1])
+		summary.Change, _ = strconv.Atoi(totals[2])
+		summary.Destroy, _ = strconv.Atoi(totals[3])
+	}
+	summary.Text = summary.describe()
+	return summary
+}
+
+// terraformJSON summarizes a `terraform show -json` plan
+func terraformJSON(plan map[string]any) *Summary {
+	changes, ok := plan["resource_changes"].([]any)
+	if !ok {
+		return nil
+	}
+	summary := &Summary{Tool: ToolTerraform, Resources: []ResourceChange{}}
+	for _, item := range changes {
+		rc, _ := item.(map[string]any)
+		address, _ := rc["address"].(string)
+		change, _ := rc["change"].(map[string]any)
+		actions, _ := change["actions"].([]any)
+		var names []string
+		for _, action := range actions {
+			if name, ok := action.(string); ok {
+				names = append(names, name)
+			}
+		}
+		var action string
+		switch strings.Join(names, ",") {
+		case "create":
+			action = ActionCreate
+		case "update":
+			action = ActionUpdate
+		case "delete":
+			action = ActionDelete
+		case "delete,create", "create,delete":
+			action = ActionReplace
+		case "read":
+			action = ActionRead
+		default: // no-op
+			continue
+		}
+		summary.add(ResourceChange{Address: address, Action: action})
+	}
+	summary.Text = summary.describe()
+	return summary
+}
+
+var kubectlFile = regexp.MustCompile(`^(---|\+\+\+) (\S*/(?:LIVE|MERGED)-[^/\s]+/(\S+))`)
+
+// KubectlDiff summarizes `kubectl diff` output, or returns nil if text isn't
+// one
+func KubectlDiff(text string) *Summary {
+	type object struct {
+		name               string
+		added, removed     int
+		fromEmpty, toEmpty bool
+	}
+	var objects []*object
+	var current *object
+	for _, line := range strings.Split(text, "\n") {
+		if m := kubectlFile.FindStringSubmatch(line); m != nil {
+			if m[1] == "---" {
+				current = &object{name: m[3]}
+				objects = append(objects, current)
+			}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "diff "):
+			current = nil
+		case strings.HasPrefix(line, "@@ "):
+			current.fromEmpty = current.fromEmpty || strings.HasPrefix(line, "@@ -0,0 ")
+			current.toEmpty = current.toEmpty || strings.Contains(line, " +0,0 @@")
+		case strings.HasPrefix(line, "+"):
+			current.added++
+		case strings.HasPrefix(line, "-"):
+			current.removed++
+		}
+	}
+	if len(objects) == 0 {
+		return nil
+	}
+	summary := &Summary{Tool: ToolKubectl, Resources: []ResourceChange{}}
+	for _, obj := range objects {
+		action := ActionUpdate
+		switch {
+		case obj.fromEmpty && obj.removed == 0:
+			action = ActionCreate
+		case obj.toEmpty && obj.added == 0:
+			action = ActionDelete
+		}
+		summary.add(ResourceChange{
+			Address: kubernetesObject(obj.name),
+			Action:  action,
+			Detail:  fmt.Sprintf("+%d -%d lines", obj.added, obj.removed),
+		})
+	}
+	summary.Text = summary.describe()
+	return summary
+}
+
+// kubernetesObject turns kubectl diff's group.version.Kind.namespace.name
+// file names into "Kind namespace/name"
+func kubernetesObject(file string) string {
+	parts := strings.Split(path.Base(file), ".")
+	for i, part := range parts {
+		if part == "" || part[0] < 'A' || part[0] > 'Z' {
+			continue
+		}
+		rest := parts[i+1:]
+		if len(rest) < 2 {
+			break
+		}
+		namespace, name := rest[0], strings.Join(rest[1:], ".")
+		if namespace == "" {
+			return part + " " + name
+		}
+		return part + " " + namespace + "/" + name
+	}
+	return file
+}
+
+func (s *Summary) add(rc ResourceChange) {
+	switch rc.Action {
+	case ActionCreate:
+		s.Add++
+	case ActionUpdate:
+		s.Change++
+	case ActionReplace:
+		s.Replace++
+	case ActionDelete:
+		s.Destroy++
+	}
+	s.Resources = append(s.Resources, rc)
+}
+
+func (s *Summary) describe() string {
+	switch s.Tool {
+	case ToolKubectl:
+		return fmt.Sprintf("kubectl diff: %d to create, %d to update, %d to delete", s.Add, s.Change, s.Destroy)
+	default:
+		if s.Add+s.Change+s.Replace+s.Destroy == 0 {
+			return "Terraform plan: no changes"
+		}
+		return fmt.Sprintf("Terraform plan: %d to add, %d to change, %d to replace, %d to destroy", s.Add, s.Change, s.Replace, s.Destroy)
+	}
+}
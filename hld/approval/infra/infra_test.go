@@ -0,0 +1,106 @@
+package infra
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const terraformPlan = "Terraform will perform the following actions:\n\n" +
+	"  \x1b[1m# aws_instance.web\x1b[0m will be created\n" +
+	"  + resource \"aws_instance\" \"web\" {\n      + ami = \"ami-123\"\n    }\n\n" +
+	"  # aws_security_group.web will be updated in-place\n" +
+	"  ~ resource \"aws_security_group\" \"web\" {\n    }\n\n" +
+	"  # aws_db_instance.main must be replaced\n" +
+	"-/+ resource \"aws_db_instance\" \"main\" {\n    }\n\n" +
+	"  # module.old.aws_s3_bucket.logs[0] will be destroyed\n" +
+	"  - resource \"aws_s3_bucket\" \"logs\" {\n    }\n\n" +
+	"  # data.aws_ami.ubuntu will be read during apply\n\n" +
+	"Plan: 2 to add, 1 to change, 2 to destroy.\n"
+
+func TestTerraformPlan(t *testing.T) {
+	summary := TerraformPlan(terraformPlan)
+	require.NotNil(t, summary)
+	assert.Equal(t, ToolTerraform, summary.Tool)
+	assert.Equal(t, []int{1, 1, 1, 1}, []int{summary.Add, summary.Change, summary.Replace, summary.Destroy})
+	assert.Equal(t, "Terraform plan: 1 to add, 1 to change, 1 to replace, 1 to destroy", summary.Text)
+	assert.Equal(t, []ResourceChange{
+		{Address: "aws_instance.web", Action: ActionCreate},
+		{Address: "aws_security_group.web", Action: ActionUpdate},
+		{Address: "aws_db_instance.main", Action: ActionReplace},
+		{Address: "module.old.aws_s3_bucket.logs[0]", Action: ActionDelete},
+		{Address: "data.aws_ami.ubuntu", Action: ActionRead},
+	}, summary.Resources)
+
+	totalsOnly := TerraformPlan("...\nPlan: 3 to add, 0 to change, 1 to destroy.")
+	require.NotNil(t, totalsOnly)
+	assert.Equal(t, []int{3, 0, 1}, []int{totalsOnly.Add, totalsOnly.Change, totalsOnly.Destroy})
+
+	noChanges := TerraformPlan("No changes. Your infrastructure matches the configuration.")
+	require.NotNil(t, noChanges)
+	assert.Equal(t, "Terraform plan: no changes", noChanges.Text)
+
+	assert.Nil(t, TerraformPlan("just a # comment will be created later"))
+}
+
+func TestTerraformJSON(t *testing.T) {
+	plan := `{"format_version":"1.2","resource_changes":[
+		{"address":"aws_instance.web","change":{"actions":["create"]}},
+		{"address":"aws_db_instance.main","change":{"actions":["delete","create"]}},
+		{"address":"aws_iam_role.ci","change":{"actions":["no-op"]}}]}`
+
+	summary := Summarize(json.RawMessage(plan))
+	require.NotNil(t, summary)
+	assert.Equal(t, "Terraform plan: 1 to add, 0 to change, 1 to replace, 0 to destroy", summary.Text)
+	assert.Len(t, summary.Resources, 2)
+
+	// Embedded as a string, e.g. a file being written
+	input, err := json.Marshal(map[string]string{"file_path": "plan.json", "content": plan})
+	require.NoError(t, err)
+	assert.Equal(t, summary, Summarize(input))
+}
+
+const kubectlDiff = `diff -u -N /tmp/LIVE-1234/apps.v1.Deployment.default.web /tmp/MERGED-1234/apps.v1.Deployment.default.web
+--- /tmp/LIVE-1234/apps.v1.Deployment.default.web	2024-01-01 00:00:00
++++ /tmp/MERGED-1234/apps.v1.Deployment.default.web	2024-01-01 00:00:00
+@@ -6,7 +6,7 @@
+   generation: 3
+-  replicas: 2
++  replicas: 4
+diff -u -N /tmp/LIVE-1234/v1.ConfigMap.default.app.config /tmp/MERGED-1234/v1.ConfigMap.default.app.config
+--- /tmp/LIVE-1234/v1.ConfigMap.default.app.config	1970-01-01 00:00:00
++++ /tmp/MERGED-1234/v1.ConfigMap.default.app.config	2024-01-01 00:00:00
+@@ -0,0 +1,3 @@
++apiVersion: v1
++kind: ConfigMap
++data: {}
+diff -u -N /tmp/LIVE-1234/rbac.authorization.k8s.io.v1.ClusterRole..reader /tmp/MERGED-1234/rbac.authorization.k8s.io.v1.ClusterRole..reader
+--- /tmp/LIVE-1234/rbac.authorization.k8s.io.v1.ClusterRole..reader	2024-01-01 00:00:00
++++ /tmp/MERGED-1234/rbac.authorization.k8s.io.v1.ClusterRole..reader	1970-01-01 00:00:00
+@@ -1,2 +0,0 @@
+-kind: ClusterRole
+-rules: []
+`
+
+func TestKubectlDiff(t *testing.T) {
+	input, err := json.Marshal(map[string]string{"command": "kubectl apply -f k8s/", "diff": kubectlDiff})
+	require.NoError(t, err)
+
+	summary := Summarize(input)
+	require.NotNil(t, summary)
+	assert.Equal(t, ToolKubectl, summary.Tool)
+	assert.Equal(t, "kubectl diff: 1 to create, 1 to update, 1 to delete", summary.Text)
+	assert.Equal(t, []ResourceChange{
+		{Address: "Deployment default/web", Action: ActionUpdate, Detail: "+1 -1 lines"},
+		{Address: "ConfigMap default/app.config", Action: ActionCreate, Detail: "+3 -0 lines"},
+		{Address: "ClusterRole reader", Action: ActionDelete, Detail: "+0 -2 lines"},
+	}, summary.Resources)
+}
+
+func TestSummarizeIgnoresOtherInput(t *testing.T) {
+	assert.Nil(t, Summarize(json.RawMessage(`{"command":"ls -la"}`)))
+	assert.Nil(t, Summarize(json.RawMessage(`{"file_path":"a.diff","content":"--- a/x\n+++ b/x\n@@ -1 +1 @@\n-a\n+b\n"}`)))
+	assert.Nil(t, Summarize(nil))
+}
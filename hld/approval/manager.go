@@ -5,29 +5,83 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/humanlayer/humanlayer/hld/approval/infra"
+	"github.com/humanlayer/humanlayer/hld/approval/policy"
 	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/gitcmd"
+	"github.com/humanlayer/humanlayer/hld/migrationcheck"
 	"github.com/humanlayer/humanlayer/hld/store"
 )
 
+// PolicyRecords is where a manager with policies records what they decided:
+// shadow policy decisions and approvals a rule marked critical
+type PolicyRecords interface {
+	store.ShadowDecisionStore
+	store.EscalationStore
+}
+
 // manager manages approvals locally without HumanLayer API
 type manager struct {
 	store    store.ConversationStore
+	records  PolicyRecords // nil when the manager has no policies
 	eventBus bus.EventBus
+	policy   *policy.Policy
+	shadow   *policy.Policy
+	// headless denies whatever the policy doesn't allow instead of asking
+	headless bool
 }
 
 // NewManager creates a new local approval manager
 func NewManager(store store.ConversationStore, eventBus bus.EventBus) Manager {
+	return NewManagerWithPolicy(store, nil, eventBus, nil)
+}
+
+// NewManagerWithPolicy creates a local approval manager that evaluates
+// policy rules before the session's auto-accept settings. A nil policy
+// behaves like NewManager.
+func NewManagerWithPolicy(store store.ConversationStore, records PolicyRecords, eventBus bus.EventBus, p *policy.Policy) Manager {
+	return NewManagerWithPolicies(store, records, eventBus, p, nil)
+}
+
+// NewManagerWithPolicies creates a local approval manager with an active
+// policy and a shadow policy. The shadow policy is evaluated for every
+// approval and its decision recorded, but never acted on. Either may be nil.
+func NewManagerWithPolicies(store store.ConversationStore, records PolicyRecords, eventBus bus.EventBus, active, shadow *policy.Policy) Manager {
 	return &manager{
 		store:    store,
+		records:  records,
 		eventBus: eventBus,
+		policy:   active,
+		shadow:   shadow,
+	}
+}
+
+// NewHeadlessManager creates an approval manager for CI, where nobody is
+// there to answer: policy rules alone decide, auto-accept modes are ignored,
+// and calls no rule allows are denied rather than left pending.
+func NewHeadlessManager(store store.ConversationStore, records PolicyRecords, eventBus bus.EventBus, active, shadow *policy.Policy) Manager {
+	return &manager{
+		store:    store,
+		records:  records,
+		eventBus: eventBus,
+		policy:   active,
+		shadow:   shadow,
+		headless: true,
 	}
 }
 
 // CreateApproval creates a new local approval
 func (m *manager) CreateApproval(ctx context.Context, runID, toolName string, toolInput json.RawMessage) (string, error) {
+	return m.CreateApprovalWithAttachments(ctx, runID, toolName, toolInput, nil)
+}
+
+// CreateApprovalWithAttachments creates a new local approval whose request
+// carries the given artifacts
+func (m *manager) CreateApprovalWithAttachments(ctx context.Context, runID, toolName string, toolInput json.RawMessage, attachments []string) (string, error) {
 	// Look up session by run_id
 	session, err := m.store.GetSessionByRunID(ctx, runID)
 	if err != nil {
@@ -37,44 +91,20 @@ func (m *manager) CreateApproval(ctx context.Context, runID, toolName string, to
 		return "", fmt.Errorf("session not found for run_id: %s", runID)
 	}
 
-	// Check if auto-accept is enabled (either mode)
-	status := store.ApprovalStatusLocalPending
-	comment := ""
-
-	// Check dangerously skip permissions first (overrides edit mode)
-	if session.DangerouslySkipPermissions {
-		// Check if it has an expiry and if it's expired
-		if session.DangerouslySkipPermissionsExpiresAt != nil && time.Now().After(*session.DangerouslySkipPermissionsExpiresAt) {
-			// Expired - disable it
-			update := store.SessionUpdate{
-				DangerouslySkipPermissions:          &[]bool{false}[0],
-				DangerouslySkipPermissionsExpiresAt: &[]*time.Time{nil}[0],
-			}
-			if err := m.store.UpdateSession(ctx, session.ID, update); err != nil {
-				slog.Error("failed to disable expired dangerously skip permissions", "session_id", session.ID, "error", err)
-			}
-			// Continue with normal approval
-		} else {
-			// Dangerously skip permissions is active (no expiry or not expired)
-			status = store.ApprovalStatusLocalApproved
-			comment = "Auto-accepted (dangerous skip permissions enabled)"
-		}
-	} else if session.AutoAcceptEdits && isEditTool(toolName) {
-		// Regular auto-accept edits mode
-		status = store.ApprovalStatusLocalApproved
-		comment = "Auto-accepted (auto-accept mode enabled)"
-	}
+	// Decide whether a human needs to look at this
+	status, comment, criticalRule := m.initialDecision(ctx, session, toolName, toolInput)
 
 	// Create approval
 	approval := &store.Approval{
-		ID:        "local-" + uuid.New().String(),
-		RunID:     runID,
-		SessionID: session.ID,
-		Status:    status,
-		CreatedAt: time.Now(),
-		ToolName:  toolName,
-		ToolInput: toolInput,
-		Comment:   comment,
+		ID:          "local-" + uuid.New().String(),
+		RunID:       runID,
+		SessionID:   session.ID,
+		Status:      status,
+		CreatedAt:   time.Now(),
+		ToolName:    toolName,
+		ToolInput:   toolInput,
+		Comment:     comment,
+		Attachments: attachments,
 	}
 
 	// Store it
@@ -91,8 +121,13 @@ func (m *manager) CreateApproval(ctx context.Context, runID, toolName string, to
 			"session_id", session.ID)
 	}
 
-	// Publish event for real-time updates
-	m.publishNewApprovalEvent(approval)
+	if criticalRule != "" {
+		m.recordEscalation(ctx, approval, criticalRule)
+	}
+
+	// Record what the shadow policy would have done, then publish for real-time updates
+	shadow := m.recordShadowDecision(ctx, session, approval)
+	m.publishNewApprovalEvent(approval, shadow, criticalRule != "")
 
 	// Handle status-specific post-creation tasks
 	switch status {
@@ -112,6 +147,14 @@ func (m *manager) CreateApproval(ctx context.Context, runID, toolName string, to
 		}
 		// Publish resolved event for auto-approved (no images for auto-approved)
 		m.publishApprovalResolvedEvent(approval, true, comment, nil)
+	case store.ApprovalStatusLocalDenied:
+		// Auto-denied approvals never block the session
+		if err := m.store.UpdateApprovalStatus(ctx, approval.ID, store.ApprovalStatusDenied); err != nil {
+			slog.Warn("failed to update approval status in conversation events",
+				"error", err,
+				"approval_id", approval.ID)
+		}
+		m.publishApprovalResolvedEvent(approval, false, comment, nil)
 	}
 
 	logLevel := slog.LevelInfo
@@ -123,7 +166,8 @@ func (m *manager) CreateApproval(ctx context.Context, runID, toolName string, to
 		"session_id", session.ID,
 		"tool_name", toolName,
 		"status", status,
-		"auto_accepted", status == store.ApprovalStatusLocalApproved)
+		"auto_accepted", status == store.ApprovalStatusLocalApproved,
+		"auto_denied", status == store.ApprovalStatusLocalDenied)
 
 	return approval.ID, nil
 }
@@ -224,8 +268,8 @@ func (m *manager) DenyToolCall(ctx context.Context, id string, reason string, im
 			"approval_id", id)
 	}
 
-	// Publish event with image paths
-	m.publishApprovalResolvedEvent(approval, false, reason, imagePaths)
+	// Publish event with image paths; the agent also gets any expansion
+	m.publishApprovalResolvedEvent(approval, false, DenialMessage(reason, approval.CommentExpansion), imagePaths)
 
 	// Update session status back to running
 	if err := m.updateSessionStatus(ctx, approval.SessionID, store.SessionStatusRunning); err != nil {
@@ -242,6 +286,15 @@ func (m *manager) DenyToolCall(ctx context.Context, id string, reason string, im
 	return nil
 }
 
+// DenialMessage is a denial as the agent receives it: the approver's reason,
+// followed by any AI-expanded guidance, marked as such
+func DenialMessage(reason, expansion string) string {
+	if expansion == "" {
+		return reason
+	}
+	return reason + "\n\n[AI-expanded from the approver's comment] " + expansion
+}
+
 // correlateApproval tries to correlate an approval with a tool call
 func (m *manager) correlateApproval(ctx context.Context, approval *store.Approval) error {
 	// Find the most recent uncorrelated pending tool call
@@ -262,7 +315,7 @@ func (m *manager) correlateApproval(ctx context.Context, approval *store.Approva
 }
 
 // publishNewApprovalEvent publishes an event when a new approval is created
-func (m *manager) publishNewApprovalEvent(approval *store.Approval) {
+func (m *manager) publishNewApprovalEvent(approval *store.Approval, shadow *store.ShadowDecision, critical bool) {
 	if m.eventBus != nil {
 		event := bus.Event{
 			Type:      bus.EventNewApproval,
@@ -273,10 +326,44 @@ func (m *manager) publishNewApprovalEvent(approval *store.Approval) {
 				"tool_name":   approval.ToolName,
 			},
 		}
+		// Annotate with the shadow policy's decision so clients can show it
+		if shadow != nil {
+			event.Data["shadow_action"] = shadow.Action
+			event.Data["shadow_rule"] = shadow.Rule
+		}
+		if critical {
+			event.Data["critical"] = true
+		}
+		if len(approval.Attachments) > 0 {
+			event.Data["attachments"] = approval.Attachments
+		}
+		if summary := infra.Summarize(approval.ToolInput); summary != nil {
+			event.Data["infra_summary"] = summary
+		}
+		// The down migration may not be written yet, so don't look for it
+		if warnings := migrationcheck.CheckToolInput(approval.ToolName, approval.ToolInput, nil); len(warnings) > 0 {
+			event.Data["migration_warnings"] = warnings
+		}
 		m.eventBus.Publish(event)
 	}
 }
 
+// publishBudgetExceededEvent notifies the operator that a session hit its budget
+func (m *manager) publishBudgetExceededEvent(session *store.Session, toolName, reason string) {
+	if m.eventBus != nil {
+		m.eventBus.Publish(bus.Event{
+			Type:      bus.EventSessionBudgetExceeded,
+			Timestamp: time.Now(),
+			Data: map[string]interface{}{
+				"session_id": session.ID,
+				"run_id":     session.RunID,
+				"tool_name":  toolName,
+				"reason":     reason,
+			},
+		})
+	}
+}
+
 // publishApprovalResolvedEvent publishes an event when an approval is resolved
 func (m *manager) publishApprovalResolvedEvent(approval *store.Approval, approved bool, responseText string, imagePaths []string) {
 	if m.eventBus != nil {
@@ -294,6 +381,10 @@ func (m *manager) publishApprovalResolvedEvent(approval *store.Approval, approve
 		if len(imagePaths) > 0 {
 			eventData["image_paths"] = imagePaths
 		}
+		// Include the constraints an approval was given
+		if approved && approval.Constraints != nil {
+			eventData["constraints"] = approval.Constraints
+		}
 		event := bus.Event{
 			Type:      bus.EventApprovalResolved,
 			Timestamp: time.Now(),
@@ -314,7 +405,6 @@ func (m *manager) updateSessionStatus(ctx context.Context, sessionID, status str
 
 // CreateApprovalWithToolUseID creates an approval with tool_use_id field
 func (m *manager) CreateApprovalWithToolUseID(ctx context.Context, sessionID, toolName string, toolInput json.RawMessage, toolUseID string) (*store.Approval, error) {
-	// Check if auto-accept is enabled (either mode)
 	session, err := m.store.GetSession(ctx, sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get session: %w", err)
@@ -323,32 +413,7 @@ func (m *manager) CreateApprovalWithToolUseID(ctx context.Context, sessionID, to
 		return nil, fmt.Errorf("session not found: %s", sessionID)
 	}
 
-	status := store.ApprovalStatusLocalPending
-	comment := ""
-
-	// Check dangerously skip permissions first (overrides edit mode)
-	if session.DangerouslySkipPermissions {
-		// Check if it has an expiry and if it's expired
-		if session.DangerouslySkipPermissionsExpiresAt != nil && time.Now().After(*session.DangerouslySkipPermissionsExpiresAt) {
-			// Expired - disable it
-			update := store.SessionUpdate{
-				DangerouslySkipPermissions:          &[]bool{false}[0],
-				DangerouslySkipPermissionsExpiresAt: &[]*time.Time{nil}[0],
-			}
-			if err := m.store.UpdateSession(ctx, session.ID, update); err != nil {
-				slog.Error("failed to disable expired dangerously skip permissions", "session_id", session.ID, "error", err)
-			}
-			// Continue with normal approval
-		} else {
-			// Dangerously skip permissions is active (no expiry or not expired)
-			status = store.ApprovalStatusLocalApproved
-			comment = "Auto-accepted (dangerous skip permissions enabled)"
-		}
-	} else if session.AutoAcceptEdits && isEditTool(toolName) {
-		// Regular auto-accept edits mode
-		status = store.ApprovalStatusLocalApproved
-		comment = "Auto-accepted (auto-accept mode enabled)"
-	}
+	status, comment, criticalRule := m.initialDecision(ctx, session, toolName, toolInput)
 
 	// Create approval with tool_use_id
 	approval := &store.Approval{
@@ -368,8 +433,13 @@ func (m *manager) CreateApprovalWithToolUseID(ctx context.Context, sessionID, to
 		return nil, fmt.Errorf("failed to store approval: %w", err)
 	}
 
-	// Publish event for real-time updates
-	m.publishNewApprovalEvent(approval)
+	if criticalRule != "" {
+		m.recordEscalation(ctx, approval, criticalRule)
+	}
+
+	// Record what the shadow policy would have done, then publish for real-time updates
+	shadow := m.recordShadowDecision(ctx, session, approval)
+	m.publishNewApprovalEvent(approval, shadow, criticalRule != "")
 
 	if err := m.store.LinkConversationEventToApprovalUsingToolID(ctx, sessionID, toolUseID, approval.ID); err != nil {
 		// Log but don't fail
@@ -398,6 +468,14 @@ func (m *manager) CreateApprovalWithToolUseID(ctx context.Context, sessionID, to
 		}
 		// Publish resolved event for auto-approved (no images for auto-approved)
 		m.publishApprovalResolvedEvent(approval, true, comment, nil)
+	case store.ApprovalStatusLocalDenied:
+		// Auto-denied approvals never block the session
+		if err := m.store.UpdateApprovalStatus(ctx, approval.ID, store.ApprovalStatusDenied); err != nil {
+			slog.Warn("failed to update approval status in conversation events",
+				"error", err,
+				"approval_id", approval.ID)
+		}
+		m.publishApprovalResolvedEvent(approval, false, comment, nil)
 	}
 
 	logLevel := slog.LevelInfo
@@ -410,11 +488,170 @@ func (m *manager) CreateApprovalWithToolUseID(ctx context.Context, sessionID, to
 		"tool_name", toolName,
 		"tool_use_id", toolUseID,
 		"status", status,
-		"auto_accepted", status == store.ApprovalStatusLocalApproved)
+		"auto_accepted", status == store.ApprovalStatusLocalApproved,
+		"auto_denied", status == store.ApprovalStatusLocalDenied)
 
 	return approval, nil
 }
 
+// initialDecision decides whether a new approval is auto-denied, auto-accepted
+// or left pending for a human. For pending approvals a critical policy rule
+// asked for, it also returns that rule's name.
+func (m *manager) initialDecision(ctx context.Context, session *store.Session, toolName string, toolInput json.RawMessage) (store.ApprovalStatus, string, string) {
+	// Auto-deny takes precedence over everything else
+	if denyComment, denied := autoDenyComment(session, toolName); denied {
+		return store.ApprovalStatusLocalDenied, denyComment, ""
+	}
+
+	// Sessions over budget get nothing more approved
+	if reason, exceeded := m.budgetExceeded(ctx, session); exceeded {
+		m.publishBudgetExceededEvent(session, toolName, reason)
+		return store.ApprovalStatusLocalDenied, "Auto-denied (session budget exceeded: " + reason + ")", ""
+	}
+
+	// Policy rules override the session's auto-accept modes
+	decision := m.evaluatePolicy(ctx, session, toolName, toolInput)
+	switch decision.Action {
+	case policy.ActionAllow:
+		return store.ApprovalStatusLocalApproved, fmt.Sprintf("Auto-accepted (policy rule %q)", decision.Rule), ""
+	case policy.ActionDeny:
+		comment := fmt.Sprintf("Auto-denied (policy rule %q)", decision.Rule)
+		if decision.Reason != "" {
+			comment += ": " + decision.Reason
+		}
+		return store.ApprovalStatusLocalDenied, comment, ""
+	case policy.ActionAsk:
+		if m.headless {
+			return store.ApprovalStatusLocalDenied, fmt.Sprintf("Auto-denied (CI mode: policy rule %q asks for a human)", decision.Rule), ""
+		}
+		if decision.Critical {
+			return store.ApprovalStatusLocalPending, "", decision.Rule
+		}
+		return store.ApprovalStatusLocalPending, "", ""
+	}
+	if m.headless {
+		return store.ApprovalStatusLocalDenied, fmt.Sprintf("Auto-denied (CI mode: no policy rule allows %s)", toolName), ""
+	}
+
+	if session.DangerouslySkipPermissions {
+		// Dangerously skip permissions overrides edit mode
+		// Check if it has an expiry and if it's expired
+		if session.DangerouslySkipPermissionsExpiresAt != nil && time.Now().After(*session.DangerouslySkipPermissionsExpiresAt) {
+			// Expired - disable it
+			update := store.SessionUpdate{
+				DangerouslySkipPermissions:          &[]bool{false}[0],
+				DangerouslySkipPermissionsExpiresAt: &[]*time.Time{nil}[0],
+			}
+			if err := m.store.UpdateSession(ctx, session.ID, update); err != nil {
+				slog.Error("failed to disable expired dangerously skip permissions", "session_id", session.ID, "error", err)
+			}
+			// Continue with normal approval
+			return store.ApprovalStatusLocalPending, "", ""
+		}
+		// Dangerously skip permissions is active (no expiry or not expired)
+		return store.ApprovalStatusLocalApproved, "Auto-accepted (dangerous skip permissions enabled)", ""
+	}
+
+	if session.AutoAcceptEdits && isEditTool(toolName) {
+		// Regular auto-accept edits mode
+		return store.ApprovalStatusLocalApproved, "Auto-accepted (auto-accept mode enabled)", ""
+	}
+
+	return store.ApprovalStatusLocalPending, "", ""
+}
+
+// recordEscalation tags a pending approval as critical so it's escalated if
+// nobody answers it in time
+func (m *manager) recordEscalation(ctx context.Context, approval *store.Approval, rule string) {
+	if m.records == nil {
+		return
+	}
+	if err := m.records.CreateApprovalEscalation(ctx, &store.ApprovalEscalation{
+		ApprovalID: approval.ID,
+		SessionID:  approval.SessionID,
+		ToolName:   approval.ToolName,
+		Rule:       rule,
+		CreatedAt:  approval.CreatedAt,
+	}); err != nil {
+		slog.Warn("failed to record critical approval",
+			"error", err,
+			"approval_id", approval.ID,
+			"rule", rule)
+	}
+}
+
+// evaluatePolicy runs the active policy against a tool call
+func (m *manager) evaluatePolicy(ctx context.Context, session *store.Session, toolName string, toolInput json.RawMessage) policy.Decision {
+	if m.policy == nil {
+		return policy.Decision{}
+	}
+
+	decision, err := m.policy.Evaluate(policyInput(ctx, m.policy, session, toolName, toolInput))
+	if err != nil {
+		slog.Warn("approval policy rule failed to evaluate",
+			"session_id", session.ID,
+			"tool_name", toolName,
+			"error", err)
+	}
+	return decision
+}
+
+// recordShadowDecision evaluates the shadow policy for a new approval and
+// stores the result. It returns nil when no shadow policy is configured.
+func (m *manager) recordShadowDecision(ctx context.Context, session *store.Session, approval *store.Approval) *store.ShadowDecision {
+	if m.shadow == nil || m.records == nil {
+		return nil
+	}
+
+	decision, err := m.shadow.Evaluate(policyInput(ctx, m.shadow, session, approval.ToolName, approval.ToolInput))
+	shadow := &store.ShadowDecision{
+		ApprovalID: approval.ID,
+		SessionID:  approval.SessionID,
+		ToolName:   approval.ToolName,
+		Action:     string(decision.Action),
+		Rule:       decision.Rule,
+		Reason:     decision.Reason,
+	}
+	if err != nil {
+		shadow.Error = err.Error()
+	}
+	if err := m.records.StoreShadowDecision(ctx, shadow); err != nil {
+		slog.Warn("failed to store shadow policy decision",
+			"error", err,
+			"approval_id", approval.ID)
+	}
+	return shadow
+}
+
+// policyInput builds the evaluation context for a tool call, looking up the
+// git branch only when the policy reads it
+func policyInput(ctx context.Context, p *policy.Policy, session *store.Session, toolName string, toolInput json.RawMessage) policy.Input {
+	input := policy.Input{
+		ToolName:  toolName,
+		ToolInput: toolInput,
+		Session: policy.Session{
+			ID:         session.ID,
+			Title:      session.Title,
+			WorkingDir: session.WorkingDir,
+			Model:      session.Model,
+		},
+		Now: time.Now(),
+	}
+	if p.UsesBranch() && session.WorkingDir != "" {
+		input.Session.Branch = currentBranch(ctx, session.WorkingDir)
+	}
+	return input
+}
+
+// currentBranch returns the checked-out git branch of dir, or "" if it can't be determined
+func currentBranch(ctx context.Context, dir string) string {
+	out, _, err := gitcmd.Run(ctx, gitcmd.Cmd{Dir: dir, Args: []string{"rev-parse", "--abbrev-ref", "HEAD"}, Timeout: 2 * time.Second})
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
 // isEditTool checks if a tool name is one of the edit tools
 func isEditTool(toolName string) bool {
 	return toolName == "Edit" || toolName == "Write" || toolName == "MultiEdit"
@@ -3,9 +3,12 @@ package approval
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/humanlayer/humanlayer/hld/approval/policy"
 	"github.com/humanlayer/humanlayer/hld/bus"
 	"github.com/humanlayer/humanlayer/hld/store"
 	"github.com/stretchr/testify/assert"
@@ -261,3 +264,248 @@ func TestManager_CorrelateApproval(t *testing.T) {
 	require.NoError(t, err)
 	assert.NotEmpty(t, approvalID)
 }
+
+func TestManager_CreateApprovalWithToolUseID_AutoDeny(t *testing.T) {
+	tests := []struct {
+		name    string
+		session store.Session
+		tool    string
+		denied  bool
+	}{
+		{"deny all", store.Session{AutoDenyAll: true, DangerouslySkipPermissions: true}, "Read", true},
+		{"exact tool", store.Session{AutoDenyTools: `["Bash"]`}, "Bash", true},
+		{"prefix pattern", store.Session{AutoDenyTools: `["mcp__github__*"]`}, "mcp__github__create_issue", true},
+		{"unlisted tool", store.Session{AutoDenyTools: `["Bash"]`}, "Read", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStore := store.NewMockConversationStore(ctrl)
+			mockEventBus := bus.NewMockEventBus(ctrl)
+			manager := NewManager(mockStore, mockEventBus)
+
+			ctx := context.Background()
+			sess := tt.session
+			sess.ID = "test-session"
+			sess.RunID = "test-run"
+
+			mockStore.EXPECT().GetSession(ctx, sess.ID).Return(&sess, nil)
+			mockStore.EXPECT().CreateApproval(ctx, gomock.Any()).Return(nil)
+			mockStore.EXPECT().LinkConversationEventToApprovalUsingToolID(ctx, sess.ID, "toolu_1", gomock.Any()).Return(nil)
+
+			var events []bus.Event
+			mockEventBus.EXPECT().Publish(gomock.Any()).Do(func(event bus.Event) {
+				events = append(events, event)
+			}).AnyTimes()
+
+			if tt.denied {
+				mockStore.EXPECT().UpdateApprovalStatus(ctx, gomock.Any(), store.ApprovalStatusDenied).Return(nil)
+			} else {
+				mockStore.EXPECT().UpdateSession(ctx, sess.ID, gomock.Any()).Return(nil)
+			}
+
+			approval, err := manager.CreateApprovalWithToolUseID(ctx, sess.ID, tt.tool, json.RawMessage(`{}`), "toolu_1")
+			require.NoError(t, err)
+
+			if tt.denied {
+				assert.Equal(t, store.ApprovalStatusLocalDenied, approval.Status)
+				assert.True(t, strings.HasPrefix(approval.Comment, "Auto-denied"))
+				require.Len(t, events, 2)
+				assert.Equal(t, bus.EventApprovalResolved, events[1].Type)
+				assert.Equal(t, false, events[1].Data["approved"])
+				assert.Equal(t, "toolu_1", events[1].Data["tool_use_id"])
+			} else {
+				assert.Equal(t, store.ApprovalStatusLocalPending, approval.Status)
+				require.Len(t, events, 1)
+			}
+		})
+	}
+}
+
+func TestMatchesAutoDenyTool(t *testing.T) {
+	patterns := ParseAutoDenyTools(`["Bash", "mcp__linear__*"]`)
+	assert.True(t, MatchesAutoDenyTool(patterns, "Bash"))
+	assert.True(t, MatchesAutoDenyTool(patterns, "mcp__linear__create_issue"))
+	assert.False(t, MatchesAutoDenyTool(patterns, "BashOutput"))
+	assert.False(t, MatchesAutoDenyTool(patterns, "mcp__github__create_issue"))
+	assert.Nil(t, ParseAutoDenyTools("not json"))
+}
+
+func TestManager_PolicyRules(t *testing.T) {
+	rules, err := policy.Compile([]policy.Rule{
+		{Name: "no-rm", Expression: `tool == "Bash" && input.command.startsWith("rm ")`, Action: policy.ActionDeny, Reason: "destructive"},
+		{Name: "review-config", Expression: `tool == "Edit" && input.file_path.endsWith(".env")`, Action: policy.ActionAsk},
+		{Name: "src-writes", Expression: `tool in ["Write", "Edit"] && input.file_path.startsWith("/src/")`, Action: policy.ActionAllow},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		session store.Session
+		tool    string
+		input   string
+		status  store.ApprovalStatus
+		comment string
+	}{
+		{"deny overrides skip permissions", store.Session{DangerouslySkipPermissions: true}, "Bash", `{"command":"rm -rf /"}`, store.ApprovalStatusLocalDenied, `Auto-denied (policy rule "no-rm"): destructive`},
+		{"ask overrides auto-accept edits", store.Session{AutoAcceptEdits: true}, "Edit", `{"file_path":"/src/.env"}`, store.ApprovalStatusLocalPending, ""},
+		{"allow without auto-accept", store.Session{}, "Write", `{"file_path":"/src/main.go"}`, store.ApprovalStatusLocalApproved, `Auto-accepted (policy rule "src-writes")`},
+		{"no match falls through", store.Session{}, "Bash", `{"command":"ls"}`, store.ApprovalStatusLocalPending, ""},
+		{"auto-deny still wins", store.Session{AutoDenyAll: true}, "Write", `{"file_path":"/src/main.go"}`, store.ApprovalStatusLocalDenied, "Auto-denied (observation-only session)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := store.NewInMemoryStore()
+			sess := tt.session
+			sess.ID = "test-session"
+			sess.RunID = "test-run"
+			sess.Status = store.SessionStatusRunning
+			require.NoError(t, s.CreateSession(ctx, &sess))
+
+			manager := NewManagerWithPolicy(s, s, nil, rules)
+			id, err := manager.CreateApproval(ctx, sess.RunID, tt.tool, json.RawMessage(tt.input))
+			require.NoError(t, err)
+
+			approval, err := s.GetApproval(ctx, id)
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, approval.Status)
+			assert.Equal(t, tt.comment, approval.Comment)
+		})
+	}
+}
+
+func TestManager_CriticalApproval(t *testing.T) {
+	rules, err := policy.Compile([]policy.Rule{
+		{Name: "prod-deploy", Expression: `tool == "Bash" && input.command.contains("deploy")`, Action: policy.ActionAsk, Critical: true},
+		{Name: "review-config", Expression: `tool == "Edit"`, Action: policy.ActionAsk},
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	require.NoError(t, s.CreateSession(ctx, &store.Session{ID: "test-session", RunID: "test-run", Status: store.SessionStatusRunning}))
+	manager := NewManagerWithPolicy(s, s, nil, rules)
+
+	id, err := manager.CreateApproval(ctx, "test-run", "Bash", json.RawMessage(`{"command":"make deploy"}`))
+	require.NoError(t, err)
+	escalation, err := s.GetApprovalEscalation(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "prod-deploy", escalation.Rule)
+	assert.Equal(t, "test-session", escalation.SessionID)
+
+	id, err = manager.CreateApproval(ctx, "test-run", "Edit", json.RawMessage(`{"file_path":"a.go"}`))
+	require.NoError(t, err)
+	_, err = s.GetApprovalEscalation(ctx, id)
+	assert.True(t, errors.Is(err, store.ErrNotFound), "only critical rules escalate")
+}
+
+func TestHeadlessManager(t *testing.T) {
+	rules, err := policy.Compile([]policy.Rule{
+		{Name: "review-config", Expression: `tool == "Edit" && input.file_path.endsWith(".env")`, Action: policy.ActionAsk},
+		{Name: "src-writes", Expression: `tool in ["Write", "Edit"] && input.file_path.startsWith("/src/")`, Action: policy.ActionAllow},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		session store.Session
+		tool    string
+		input   string
+		status  store.ApprovalStatus
+		comment string
+	}{
+		{"allowed by policy", store.Session{}, "Write", `{"file_path":"/src/main.go"}`, store.ApprovalStatusLocalApproved, `Auto-accepted (policy rule "src-writes")`},
+		{"ask has nobody to ask", store.Session{}, "Edit", `{"file_path":"/src/.env"}`, store.ApprovalStatusLocalDenied, `Auto-denied (CI mode: policy rule "review-config" asks for a human)`},
+		{"no match is denied", store.Session{}, "Bash", `{"command":"ls"}`, store.ApprovalStatusLocalDenied, "Auto-denied (CI mode: no policy rule allows Bash)"},
+		{"skip permissions is ignored", store.Session{DangerouslySkipPermissions: true}, "Bash", `{"command":"ls"}`, store.ApprovalStatusLocalDenied, "Auto-denied (CI mode: no policy rule allows Bash)"},
+		{"auto-accept edits is ignored", store.Session{AutoAcceptEdits: true}, "Edit", `{"file_path":"/etc/hosts"}`, store.ApprovalStatusLocalDenied, "Auto-denied (CI mode: no policy rule allows Edit)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := store.NewInMemoryStore()
+			sess := tt.session
+			sess.ID = "test-session"
+			sess.RunID = "test-run"
+			sess.Status = store.SessionStatusRunning
+			require.NoError(t, s.CreateSession(ctx, &sess))
+
+			manager := NewHeadlessManager(s, s, nil, rules, nil)
+			id, err := manager.CreateApproval(ctx, sess.RunID, tt.tool, json.RawMessage(tt.input))
+			require.NoError(t, err)
+
+			approval, err := s.GetApproval(ctx, id)
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, approval.Status)
+			assert.Equal(t, tt.comment, approval.Comment)
+		})
+	}
+}
+
+func TestManager_SessionBudget(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+
+	cost := 4.0
+	completed := time.Now()
+	parent := store.Session{ID: "parent", RunID: "parent-run", Status: store.SessionStatusCompleted, CostUSD: &cost, CompletedAt: &completed}
+	require.NoError(t, s.CreateSession(ctx, &parent))
+
+	child := store.Session{
+		ID:              "child",
+		RunID:           "child-run",
+		ClaudeSessionID: "claude-child",
+		Status:          store.SessionStatusRunning,
+		ParentSessionID: "parent",
+		Budget:          `{"max_tool_calls":2,"max_cost_usd":5}`,
+	}
+	require.NoError(t, s.CreateSession(ctx, &child))
+
+	eventBus := bus.NewInMemoryBus()
+	manager := NewManager(s, eventBus)
+
+	createCall := func() *store.Approval {
+		require.NoError(t, s.AddConversationEvent(ctx, &store.ConversationEvent{
+			SessionID:       child.ID,
+			ClaudeSessionID: child.ClaudeSessionID,
+			EventType:       store.EventTypeToolCall,
+			ToolName:        "Bash",
+		}))
+		id, err := manager.CreateApproval(ctx, child.RunID, "Bash", json.RawMessage(`{"command":"ls"}`))
+		require.NoError(t, err)
+		a, err := s.GetApproval(ctx, id)
+		require.NoError(t, err)
+		return a
+	}
+
+	// Within budget: parent cost counts but stays under the limit
+	assert.Equal(t, store.ApprovalStatusLocalPending, createCall().Status)
+	assert.Equal(t, store.ApprovalStatusLocalPending, createCall().Status)
+
+	third := createCall()
+	assert.Equal(t, store.ApprovalStatusLocalDenied, third.Status)
+	assert.Equal(t, "Auto-denied (session budget exceeded: 3 of 2 tool calls)", third.Comment)
+
+	events := eventBus.EventsOfType(bus.EventSessionBudgetExceeded)
+	require.Len(t, events, 1)
+	assert.Equal(t, "child", events[0].Data["session_id"])
+	assert.Equal(t, "Bash", events[0].Data["tool_name"])
+
+	// Raising the limit lets the session continue
+	budget := `{"max_tool_calls":10,"max_cost_usd":5}`
+	require.NoError(t, s.UpdateSession(ctx, child.ID, store.SessionUpdate{Budget: &budget}))
+	assert.Equal(t, store.ApprovalStatusLocalPending, createCall().Status)
+
+	// Parent cost pushes the chain over its cost limit
+	extra := 2.0
+	require.NoError(t, s.UpdateSession(ctx, child.ID, store.SessionUpdate{CostUSD: &extra}))
+	over := createCall()
+	assert.Equal(t, store.ApprovalStatusLocalDenied, over.Status)
+	assert.Equal(t, "Auto-denied (session budget exceeded: $6.00 of $5.00)", over.Comment)
+}
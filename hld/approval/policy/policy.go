@@ -0,0 +1,219 @@
+// Package policy evaluates approval rules written in CEL
+// (https://github.com/google/cel-spec). Rules are checked in order against
+// the tool call and its session, and the first matching rule decides whether
+// the call is allowed, denied or left to a human.
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/cel-go/cel"
+)
+
+// Action is what a matching rule does with an approval request
+type Action string
+
+const (
+	// ActionAllow approves the request without asking
+	ActionAllow Action = "allow"
+	// ActionDeny denies the request without asking
+	ActionDeny Action = "deny"
+	// ActionAsk always asks a human, even when the session would auto-accept
+	ActionAsk Action = "ask"
+)
+
+// Rule is a single policy rule. Expression is a CEL expression that must
+// evaluate to a bool, e.g.
+//
+//	tool in ["Write", "Edit"] && input.file_path.startsWith("/src/") &&
+//	now.getHours("America/New_York") >= 9 && session.branch != "main"
+type Rule struct {
+	Name       string `json:"name"`
+	Expression string `json:"expression"`
+	Action     Action `json:"action"`
+	Reason     string `json:"reason,omitempty"`
+	// Critical marks the approvals an ask rule creates for escalation when
+	// nobody answers them in time
+	Critical bool `json:"critical,omitempty"`
+}
+
+// File is the on-disk policy format
+type File struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Session is the session context available to rules as `session`
+type Session struct {
+	ID         string `json:"id"`
+	Title      string `json:"title"`
+	WorkingDir string `json:"working_dir"`
+	Model      string `json:"model"`
+	Branch     string `json:"branch"`
+}
+
+// Input is the approval context a policy is evaluated against
+type Input struct {
+	ToolName  string          `json:"tool_name"`
+	ToolInput json.RawMessage `json:"tool_input,omitempty"`
+	Session   Session         `json:"session"`
+	Now       time.Time       `json:"now"`
+}
+
+// Decision is the outcome of evaluating a policy. Action is empty when no
+// rule matched.
+type Decision struct {
+	Action   Action `json:"action,omitempty"`
+	Rule     string `json:"rule,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	Critical bool   `json:"critical,omitempty"`
+}
+
+type compiledRule struct {
+	Rule
+	program cel.Program
+}
+
+// Policy is a compiled, ordered set of rules
+type Policy struct {
+	rules []compiledRule
+}
+
+var env *cel.Env
+
+func init() {
+	var err error
+	env, err = cel.NewEnv(
+		cel.Variable("tool", cel.StringType),
+		cel.Variable("input", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("session", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("now", cel.TimestampType),
+	)
+	if err != nil {
+		panic(fmt.Sprintf("policy: failed to create CEL environment: %v", err))
+	}
+}
+
+// Compile checks and compiles rules into a Policy
+func Compile(rules []Rule) (*Policy, error) {
+	p := &Policy{}
+	for i, rule := range rules {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule-%d", i+1)
+		}
+		switch rule.Action {
+		case ActionAllow, ActionDeny, ActionAsk:
+		default:
+			return nil, fmt.Errorf("rule %q: unknown action %q", rule.Name, rule.Action)
+		}
+		if rule.Critical && rule.Action != ActionAsk {
+			return nil, fmt.Errorf("rule %q: only ask rules can be critical", rule.Name)
+		}
+
+		ast, issues := env.Compile(rule.Expression)
+		if issues != nil && issues.Err() != nil {
+			return nil, fmt.Errorf("rule %q: %w", rule.Name, issues.Err())
+		}
+		if ast.OutputType() != cel.BoolType {
+			return nil, fmt.Errorf("rule %q: expression must evaluate to bool, got %s", rule.Name, ast.OutputType())
+		}
+		program, err := env.Program(ast)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", rule.Name, err)
+		}
+		p.rules = append(p.rules, compiledRule{Rule: rule, program: program})
+	}
+	return p, nil
+}
+
+// Load reads and compiles a policy file
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy: %w", err)
+	}
+	var file File
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse policy %s: %w", path, err)
+	}
+	p, err := Compile(file.Rules)
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Rules returns the policy's rules in evaluation order
+func (p *Policy) Rules() []Rule {
+	rules := make([]Rule, len(p.rules))
+	for i, r := range p.rules {
+		rules[i] = r.Rule
+	}
+	return rules
+}
+
+// UsesBranch reports whether any rule reads session.branch, so callers can
+// skip looking up the git branch when nothing needs it
+func (p *Policy) UsesBranch() bool {
+	for _, r := range p.rules {
+		if strings.Contains(r.Expression, "branch") {
+			return true
+		}
+	}
+	return false
+}
+
+// Evaluate returns the decision of the first matching rule. A rule that
+// fails at runtime (e.g. reads a missing input field) does not match, and
+// its error is returned alongside the decision of any later rule.
+func (p *Policy) Evaluate(in Input) (Decision, error) {
+	if p == nil {
+		return Decision{}, nil
+	}
+
+	input := map[string]interface{}{}
+	if len(in.ToolInput) > 0 {
+		if err := json.Unmarshal(in.ToolInput, &input); err != nil {
+			// Non-object input is exposed under "value"
+			var value interface{}
+			if err := json.Unmarshal(in.ToolInput, &value); err != nil {
+				return Decision{}, fmt.Errorf("invalid tool input: %w", err)
+			}
+			input = map[string]interface{}{"value": value}
+		}
+	}
+	now := in.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	activation := map[string]interface{}{
+		"tool":  in.ToolName,
+		"input": input,
+		"session": map[string]interface{}{
+			"id":          in.Session.ID,
+			"title":       in.Session.Title,
+			"working_dir": in.Session.WorkingDir,
+			"model":       in.Session.Model,
+			"branch":      in.Session.Branch,
+		},
+		"now": now,
+	}
+
+	var firstErr error
+	for _, rule := range p.rules {
+		out, _, err := rule.program.Eval(activation)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("rule %q: %w", rule.Name, err)
+			}
+			continue
+		}
+		if matched, ok := out.Value().(bool); ok && matched {
+			return Decision{Action: rule.Action, Rule: rule.Name, Reason: rule.Reason, Critical: rule.Critical}, firstErr
+		}
+	}
+	return Decision{}, firstErr
+}
@@ -0,0 +1,71 @@
+package policy
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateBusinessHoursRule(t *testing.T) {
+	p, err := Compile([]Rule{{
+		Name: "src-writes-business-hours",
+		Expression: `tool in ["Write", "Edit"] && input.file_path.startsWith("/src/") &&
+			now.getHours("UTC") >= 9 && now.getHours("UTC") < 17 && session.branch != "main"`,
+		Action: ActionAllow,
+	}})
+	require.NoError(t, err)
+
+	morning := time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC)
+	night := time.Date(2025, 1, 6, 22, 0, 0, 0, time.UTC)
+	write := json.RawMessage(`{"file_path":"/src/app.go"}`)
+
+	tests := []struct {
+		name   string
+		input  Input
+		action Action
+	}{
+		{"matches", Input{ToolName: "Write", ToolInput: write, Session: Session{Branch: "feature"}, Now: morning}, ActionAllow},
+		{"outside hours", Input{ToolName: "Write", ToolInput: write, Session: Session{Branch: "feature"}, Now: night}, ""},
+		{"main branch", Input{ToolName: "Write", ToolInput: write, Session: Session{Branch: "main"}, Now: morning}, ""},
+		{"other path", Input{ToolName: "Edit", ToolInput: json.RawMessage(`{"file_path":"/etc/hosts"}`), Session: Session{Branch: "feature"}, Now: morning}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision, err := p.Evaluate(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.action, decision.Action)
+		})
+	}
+	assert.True(t, p.UsesBranch())
+}
+
+func TestEvaluateFirstMatchWinsAndSkipsFailingRules(t *testing.T) {
+	p, err := Compile([]Rule{
+		{Name: "needs-command", Expression: `input.command == "ls"`, Action: ActionAllow},
+		{Name: "deny-bash", Expression: `tool == "Bash"`, Action: ActionDeny, Reason: "no shell"},
+		{Name: "never-reached", Expression: `true`, Action: ActionAllow},
+	})
+	require.NoError(t, err)
+
+	// The first rule errors on a missing field, so the second decides
+	decision, err := p.Evaluate(Input{ToolName: "Bash", ToolInput: json.RawMessage(`{}`)})
+	assert.Error(t, err)
+	assert.Equal(t, Decision{Action: ActionDeny, Rule: "deny-bash", Reason: "no shell"}, decision)
+}
+
+func TestCompileRejectsInvalidRules(t *testing.T) {
+	_, err := Compile([]Rule{{Name: "bad-action", Expression: `true`, Action: "maybe"}})
+	assert.ErrorContains(t, err, "unknown action")
+
+	_, err = Compile([]Rule{{Name: "not-bool", Expression: `tool`, Action: ActionAllow}})
+	assert.ErrorContains(t, err, "must evaluate to bool")
+
+	_, err = Compile([]Rule{{Name: "syntax", Expression: `tool ==`, Action: ActionAllow}})
+	assert.Error(t, err)
+
+	_, err = Compile([]Rule{{Name: "critical-allow", Expression: `true`, Action: ActionAllow, Critical: true}})
+	assert.ErrorContains(t, err, "only ask rules can be critical")
+}
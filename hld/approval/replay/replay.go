@@ -0,0 +1,276 @@
+// Package replay re-runs recorded approval scenarios through the approval
+// manager so policy changes can be checked against real decisions before
+// they ship.
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/humanlayer/humanlayer/hld/approval"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// Outcome describes how an approval request was resolved
+type Outcome string
+
+const (
+	OutcomeAutoApproved Outcome = "auto_approved"
+	OutcomeAutoDenied   Outcome = "auto_denied"
+	OutcomeApproved     Outcome = "approved"
+	OutcomeDenied       Outcome = "denied"
+	OutcomePending      Outcome = "pending"
+)
+
+// Policy is the set of session settings that decide approvals without a human
+type Policy struct {
+	AutoAcceptEdits            bool     `json:"auto_accept_edits"`
+	DangerouslySkipPermissions bool     `json:"dangerously_skip_permissions"`
+	AutoDenyAll                bool     `json:"auto_deny_all"`
+	AutoDenyTools              []string `json:"auto_deny_tools,omitempty"`
+}
+
+// Step is a single recorded approval request and its outcome
+type Step struct {
+	ToolName  string          `json:"tool_name"`
+	ToolInput json.RawMessage `json:"tool_input,omitempty"`
+	Outcome   Outcome         `json:"outcome"`
+	Comment   string          `json:"comment,omitempty"`
+}
+
+// Scenario is a recorded sequence of approval requests and decisions
+type Scenario struct {
+	Name      string `json:"name"`
+	SessionID string `json:"session_id,omitempty"`
+	Policy    Policy `json:"policy"` // Policy in effect when the scenario was recorded
+	Steps     []Step `json:"steps"`
+}
+
+// StepResult compares a recorded step with its replayed outcome
+type StepResult struct {
+	Index    int     `json:"index"`
+	ToolName string  `json:"tool_name"`
+	Recorded Outcome `json:"recorded"`
+	Replayed Outcome `json:"replayed"`
+	Comment  string  `json:"comment,omitempty"`
+	Differs  bool    `json:"differs"`
+}
+
+// Report summarizes a replay run
+type Report struct {
+	Scenario    string       `json:"scenario"`
+	Policy      Policy       `json:"policy"`
+	Steps       []StepResult `json:"steps"`
+	Differences int          `json:"differences"`
+}
+
+// LoadScenario reads a scenario from a JSON file
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario: %w", err)
+	}
+	var scenario Scenario
+	if err := json.Unmarshal(data, &scenario); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario %s: %w", path, err)
+	}
+	if scenario.Name == "" {
+		scenario.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return &scenario, nil
+}
+
+// LoadPolicy reads a policy from a JSON file
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy: %w", err)
+	}
+	var policy Policy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse policy %s: %w", path, err)
+	}
+	return &policy, nil
+}
+
+// WriteScenario encodes a scenario as indented JSON
+func WriteScenario(w io.Writer, scenario *Scenario) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(scenario)
+}
+
+// FromSession records the approvals of an existing session as a scenario
+func FromSession(ctx context.Context, s store.ConversationStore, sessionID string) (*Scenario, error) {
+	session, err := s.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	events, err := s.GetSessionConversation(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversation: %w", err)
+	}
+
+	scenario := &Scenario{
+		Name:      session.Title,
+		SessionID: sessionID,
+		Policy: Policy{
+			AutoAcceptEdits:            session.AutoAcceptEdits,
+			DangerouslySkipPermissions: session.DangerouslySkipPermissions,
+			AutoDenyAll:                session.AutoDenyAll,
+			AutoDenyTools:              approval.ParseAutoDenyTools(session.AutoDenyTools),
+		},
+	}
+	if scenario.Name == "" {
+		scenario.Name = sessionID
+	}
+
+	for _, event := range events {
+		if event.ApprovalID == "" {
+			continue
+		}
+		a, err := s.GetApproval(ctx, event.ApprovalID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get approval %s: %w", event.ApprovalID, err)
+		}
+		scenario.Steps = append(scenario.Steps, Step{
+			ToolName:  a.ToolName,
+			ToolInput: a.ToolInput,
+			Outcome:   outcomeOf(a),
+			Comment:   a.Comment,
+		})
+	}
+
+	return scenario, nil
+}
+
+// Run replays a scenario against the given policy using a throwaway in-memory store.
+// Requests the policy leaves to a human keep their recorded decision, so only
+// automatic approvals and denials can differ.
+func Run(ctx context.Context, scenario *Scenario, policy Policy) (*Report, error) {
+	s := store.NewInMemoryStore()
+
+	session, err := newReplaySession(policy)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.CreateSession(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to create replay session: %w", err)
+	}
+
+	manager := approval.NewManager(s, nil)
+	report := &Report{Scenario: scenario.Name, Policy: policy}
+
+	for i, step := range scenario.Steps {
+		input := step.ToolInput
+		if len(input) == 0 {
+			input = json.RawMessage(`{}`)
+		}
+
+		id, err := manager.CreateApproval(ctx, session.RunID, step.ToolName, input)
+		if err != nil {
+			return nil, fmt.Errorf("step %d: %w", i, err)
+		}
+		a, err := s.GetApproval(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("step %d: %w", i, err)
+		}
+
+		result := StepResult{
+			Index:    i,
+			ToolName: step.ToolName,
+			Recorded: step.Outcome,
+			Replayed: outcomeOf(a),
+			Comment:  a.Comment,
+		}
+
+		// Fall back to the recorded human decision for anything left pending
+		if result.Replayed == OutcomePending {
+			switch step.Outcome {
+			case OutcomeApproved, OutcomeAutoApproved:
+				result.Replayed = OutcomeApproved
+				err = manager.ApproveToolCall(ctx, id, step.Comment, nil)
+			case OutcomeDenied, OutcomeAutoDenied:
+				result.Replayed = OutcomeDenied
+				err = manager.DenyToolCall(ctx, id, step.Comment, nil)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("step %d: %w", i, err)
+			}
+			result.Comment = step.Comment
+		}
+
+		result.Differs = result.Replayed != step.Outcome
+		if result.Differs {
+			report.Differences++
+		}
+		report.Steps = append(report.Steps, result)
+	}
+
+	return report, nil
+}
+
+// WriteText writes a human-readable summary of the report
+func (r *Report) WriteText(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "scenario %q: %d of %d decisions differ\n", r.Scenario, r.Differences, len(r.Steps)); err != nil {
+		return err
+	}
+	for _, step := range r.Steps {
+		if !step.Differs {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "  #%d %s: %s -> %s\n", step.Index, step.ToolName, step.Recorded, step.Replayed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newReplaySession(policy Policy) (*store.Session, error) {
+	now := time.Now()
+	session := &store.Session{
+		ID:                         uuid.New().String(),
+		RunID:                      uuid.New().String(),
+		Query:                      "approval replay",
+		Status:                     store.SessionStatusRunning,
+		CreatedAt:                  now,
+		LastActivityAt:             now,
+		AutoAcceptEdits:            policy.AutoAcceptEdits,
+		DangerouslySkipPermissions: policy.DangerouslySkipPermissions,
+		AutoDenyAll:                policy.AutoDenyAll,
+	}
+	if len(policy.AutoDenyTools) > 0 {
+		tools, err := json.Marshal(policy.AutoDenyTools)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal auto-deny tools: %w", err)
+		}
+		session.AutoDenyTools = string(tools)
+	}
+	return session, nil
+}
+
+// outcomeOf classifies a stored approval
+func outcomeOf(a *store.Approval) Outcome {
+	switch a.Status {
+	case store.ApprovalStatusLocalApproved:
+		if strings.HasPrefix(a.Comment, "Auto-accepted") {
+			return OutcomeAutoApproved
+		}
+		return OutcomeApproved
+	case store.ApprovalStatusLocalDenied:
+		if strings.HasPrefix(a.Comment, "Auto-denied") {
+			return OutcomeAutoDenied
+		}
+		return OutcomeDenied
+	default:
+		return OutcomePending
+	}
+}
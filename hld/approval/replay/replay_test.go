@@ -0,0 +1,79 @@
+package replay
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunUnchangedPolicyMatchesRecording(t *testing.T) {
+	scenario, err := LoadScenario("testdata/edits.json")
+	require.NoError(t, err)
+
+	report, err := Run(context.Background(), scenario, scenario.Policy)
+	require.NoError(t, err)
+	assert.Equal(t, 0, report.Differences)
+	require.Len(t, report.Steps, 3)
+	assert.Equal(t, OutcomeAutoApproved, report.Steps[0].Replayed)
+	assert.Equal(t, OutcomeDenied, report.Steps[1].Replayed)
+}
+
+func TestRunReportsChangedDecisions(t *testing.T) {
+	scenario, err := LoadScenario("testdata/edits.json")
+	require.NoError(t, err)
+
+	report, err := Run(context.Background(), scenario, Policy{AutoDenyTools: []string{"Bash"}})
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Differences)
+	assert.Equal(t, OutcomeApproved, report.Steps[0].Replayed)
+	assert.Equal(t, OutcomeAutoDenied, report.Steps[1].Replayed)
+	assert.Equal(t, OutcomeAutoDenied, report.Steps[2].Replayed)
+
+	var out strings.Builder
+	require.NoError(t, report.WriteText(&out))
+	assert.Contains(t, out.String(), "3 of 3 decisions differ")
+	assert.Contains(t, out.String(), "#2 Bash: approved -> auto_denied")
+}
+
+// TestReplayScenarios replays every scenario in HLD_REPLAY_DIR against the
+// policy in HLD_REPLAY_POLICY (a JSON file) and fails on any difference.
+func TestReplayScenarios(t *testing.T) {
+	dir := os.Getenv("HLD_REPLAY_DIR")
+	if dir == "" {
+		t.Skip("HLD_REPLAY_DIR not set")
+	}
+
+	var policy *Policy
+	if path := os.Getenv("HLD_REPLAY_POLICY"); path != "" {
+		p, err := LoadPolicy(path)
+		require.NoError(t, err)
+		policy = p
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, paths, "no scenarios found in %s", dir)
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			scenario, err := LoadScenario(path)
+			require.NoError(t, err)
+
+			p := scenario.Policy
+			if policy != nil {
+				p = *policy
+			}
+			report, err := Run(context.Background(), scenario, p)
+			require.NoError(t, err)
+
+			var out strings.Builder
+			require.NoError(t, report.WriteText(&out))
+			t.Log(out.String())
+			assert.Zero(t, report.Differences)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package approval
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/humanlayer/humanlayer/hld/approval/policy"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// ShadowComparison pairs a shadow policy decision with the human decision
+type ShadowComparison struct {
+	ApprovalID   string               `json:"approval_id"`
+	SessionID    string               `json:"session_id"`
+	ToolName     string               `json:"tool_name"`
+	ShadowAction string               `json:"shadow_action"`
+	Rule         string               `json:"rule,omitempty"`
+	Actual       store.ApprovalStatus `json:"actual"`
+}
+
+// ShadowRuleStats counts how often a shadow rule agreed with humans
+type ShadowRuleStats struct {
+	Rule      string `json:"rule"`
+	Matched   int    `json:"matched"`
+	Agreed    int    `json:"agreed"`
+	Disagreed int    `json:"disagreed"`
+}
+
+// ShadowReport compares shadow policy decisions with human decisions.
+// Approvals decided automatically (auto-accept, auto-deny or the active
+// policy) and approvals still pending are counted but not compared.
+type ShadowReport struct {
+	Evaluated     int                `json:"evaluated"`
+	Automatic     int                `json:"automatic"`
+	Pending       int                `json:"pending"`
+	HumanDecided  int                `json:"human_decided"`
+	NoOpinion     int                `json:"no_opinion"` // Shadow policy would still have asked a human
+	Agreed        int                `json:"agreed"`
+	Disagreed     int                `json:"disagreed"`
+	Rules         []ShadowRuleStats  `json:"rules"`
+	Disagreements []ShadowComparison `json:"disagreements"`
+}
+
+// BuildShadowReport compares recorded shadow decisions with how their
+// approvals were actually resolved. An empty sessionID covers all sessions.
+func BuildShadowReport(ctx context.Context, s store.ConversationStore, shadows store.ShadowDecisionStore, sessionID string) (*ShadowReport, error) {
+	decisions, err := shadows.ListShadowDecisions(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ShadowReport{Rules: []ShadowRuleStats{}, Disagreements: []ShadowComparison{}}
+	rules := make(map[string]*ShadowRuleStats)
+
+	for _, d := range decisions {
+		report.Evaluated++
+		a, err := s.GetApproval(ctx, d.ApprovalID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get approval %s: %w", d.ApprovalID, err)
+		}
+
+		switch {
+		case a.Status == store.ApprovalStatusLocalPending:
+			report.Pending++
+			continue
+		case isAutomaticComment(a.Comment):
+			report.Automatic++
+			continue
+		}
+		report.HumanDecided++
+
+		var agrees bool
+		switch policy.Action(d.Action) {
+		case policy.ActionAllow:
+			agrees = a.Status == store.ApprovalStatusLocalApproved
+		case policy.ActionDeny:
+			agrees = a.Status == store.ApprovalStatusLocalDenied
+		default:
+			report.NoOpinion++
+			continue
+		}
+
+		stats, ok := rules[d.Rule]
+		if !ok {
+			stats = &ShadowRuleStats{Rule: d.Rule}
+			rules[d.Rule] = stats
+		}
+		stats.Matched++
+		if agrees {
+			report.Agreed++
+			stats.Agreed++
+		} else {
+			report.Disagreed++
+			stats.Disagreed++
+			report.Disagreements = append(report.Disagreements, ShadowComparison{
+				ApprovalID:   d.ApprovalID,
+				SessionID:    d.SessionID,
+				ToolName:     d.ToolName,
+				ShadowAction: d.Action,
+				Rule:         d.Rule,
+				Actual:       a.Status,
+			})
+		}
+	}
+
+	for _, stats := range rules {
+		report.Rules = append(report.Rules, *stats)
+	}
+	sort.Slice(report.Rules, func(i, j int) bool { return report.Rules[i].Rule < report.Rules[j].Rule })
+	return report, nil
+}
+
+// isAutomaticComment reports whether an approval was resolved without a human
+func isAutomaticComment(comment string) bool {
+	return strings.HasPrefix(comment, "Auto-accepted") || strings.HasPrefix(comment, "Auto-denied")
+}
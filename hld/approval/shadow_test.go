@@ -0,0 +1,74 @@
+package approval
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/humanlayer/humanlayer/hld/approval/policy"
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShadowPolicyRecordsWithoutActing(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	eventBus := bus.NewInMemoryBus()
+	require.NoError(t, s.CreateSession(ctx, &store.Session{
+		ID: "sess", RunID: "run", Status: store.SessionStatusRunning, AutoAcceptEdits: true,
+	}))
+
+	shadow, err := policy.Compile([]policy.Rule{
+		{Name: "allow-ls", Expression: `tool == "Bash" && input.command == "ls"`, Action: policy.ActionAllow},
+		{Name: "deny-bash", Expression: `tool == "Bash"`, Action: policy.ActionDeny},
+	})
+	require.NoError(t, err)
+	manager := NewManagerWithPolicies(s, s, eventBus, nil, shadow)
+
+	create := func(tool, input string) string {
+		id, err := manager.CreateApproval(ctx, "run", tool, json.RawMessage(input))
+		require.NoError(t, err)
+		return id
+	}
+
+	ls := create("Bash", `{"command":"ls"}`)
+	curl := create("Bash", `{"command":"curl example.com"}`)
+	build := create("Bash", `{"command":"make"}`)
+	create("Edit", `{"file_path":"a.go"}`) // auto-accepted
+	read := create("Read", `{"file_path":"a.go"}`)
+	create("Bash", `{"command":"ls"}`) // left pending
+
+	// The shadow policy must not decide anything on its own
+	a, err := s.GetApproval(ctx, ls)
+	require.NoError(t, err)
+	assert.Equal(t, store.ApprovalStatusLocalPending, a.Status)
+
+	newApprovals := eventBus.EventsOfType(bus.EventNewApproval)
+	require.Len(t, newApprovals, 6)
+	assert.Equal(t, "allow", newApprovals[0].Data["shadow_action"])
+	assert.Equal(t, "allow-ls", newApprovals[0].Data["shadow_rule"])
+
+	require.NoError(t, manager.ApproveToolCall(ctx, ls, "", nil))     // agrees with allow
+	require.NoError(t, manager.DenyToolCall(ctx, curl, "no", nil))    // agrees with deny
+	require.NoError(t, manager.ApproveToolCall(ctx, build, "", nil))  // disagrees with deny
+	require.NoError(t, manager.ApproveToolCall(ctx, read, "ok", nil)) // no shadow opinion
+
+	report, err := BuildShadowReport(ctx, s, s, "sess")
+	require.NoError(t, err)
+	assert.Equal(t, 6, report.Evaluated)
+	assert.Equal(t, 1, report.Automatic, "edit was auto-accepted")
+	assert.Equal(t, 1, report.Pending)
+	assert.Equal(t, 4, report.HumanDecided)
+	assert.Equal(t, 1, report.NoOpinion)
+	assert.Equal(t, 2, report.Agreed)
+	assert.Equal(t, 1, report.Disagreed)
+	require.Len(t, report.Disagreements, 1)
+	assert.Equal(t, build, report.Disagreements[0].ApprovalID)
+	assert.Equal(t, "deny-bash", report.Disagreements[0].Rule)
+	assert.Equal(t, []ShadowRuleStats{
+		{Rule: "allow-ls", Matched: 1, Agreed: 1},
+		{Rule: "deny-bash", Matched: 2, Agreed: 1, Disagreed: 1},
+	}, report.Rules)
+}
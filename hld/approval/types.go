@@ -12,6 +12,10 @@ type Manager interface {
 	// Create a new approval
 	CreateApproval(ctx context.Context, runID, toolName string, toolInput json.RawMessage) (string, error)
 
+	// Create an approval whose request carries attachments, the IDs of
+	// artifacts such as a screenshot of the current screen
+	CreateApprovalWithAttachments(ctx context.Context, runID, toolName string, toolInput json.RawMessage, attachments []string) (string, error)
+
 	// Create approval with tool_use_id (Phase 4)
 	CreateApprovalWithToolUseID(ctx context.Context, sessionID, toolName string, toolInput json.RawMessage, toolUseID string) (*store.Approval, error)
 
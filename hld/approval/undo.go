@@ -0,0 +1,158 @@
+package approval
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+var (
+	// ErrDecisionPending is returned for deciding an approval that already
+	// has a decision waiting out its undo window
+	ErrDecisionPending = errors.New("a decision is already pending for this approval")
+	// ErrNothingToUndo is returned for undoing an approval without a pending
+	// decision, including once the undo window has passed
+	ErrNothingToUndo = errors.New("no decision to undo")
+)
+
+// PendingDecision is a decision waiting out its undo window
+type PendingDecision struct {
+	ApprovalID string    `json:"approval_id"`
+	Approved   bool      `json:"approved"`
+	Comment    string    `json:"comment,omitempty"`
+	CommitsAt  time.Time `json:"commits_at"`
+
+	sessionID string
+	timer     *time.Timer
+	committed func(context.Context) // run once the manager accepts it; may be nil
+}
+
+// Undoable holds decisions back for an undo window before passing them to
+// the manager, so the agent only hears of a decision once it can no longer
+// be retracted. Held decisions are lost if the daemon stops, leaving the
+// approval pending. The decision has been answered by the time it commits,
+// so a failure to commit it is published as an approval_decision_failed
+// event.
+type Undoable struct {
+	manager  Manager
+	eventBus bus.EventBus // may be nil
+	window   time.Duration
+
+	mu      sync.Mutex
+	pending map[string]*PendingDecision
+}
+
+// NewUndoable creates an Undoable committing decisions to manager after window
+func NewUndoable(manager Manager, eventBus bus.EventBus, window time.Duration) *Undoable {
+	return &Undoable{manager: manager, eventBus: eventBus, window: window, pending: make(map[string]*PendingDecision)}
+}
+
+// Window returns how long a decision can be undone
+func (u *Undoable) Window() time.Duration {
+	return u.window
+}
+
+// Decide records a decision on a pending approval, to be committed once the
+// undo window passes
+func (u *Undoable) Decide(ctx context.Context, id string, approved bool, comment string) (*PendingDecision, error) {
+	return u.DecideThen(ctx, id, approved, comment, nil)
+}
+
+// DecideThen is Decide, calling committed once the manager has accepted the
+// decision; it isn't called if the decision is undone or fails
+func (u *Undoable) DecideThen(ctx context.Context, id string, approved bool, comment string, committed func(context.Context)) (*PendingDecision, error) {
+	approval, err := u.manager.GetApproval(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if approval.Status != store.ApprovalStatusLocalPending {
+		return nil, &store.AlreadyDecidedError{ID: id, Status: string(approval.Status)}
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if _, ok := u.pending[id]; ok {
+		return nil, ErrDecisionPending
+	}
+	decision := &PendingDecision{
+		ApprovalID: id,
+		Approved:   approved,
+		Comment:    comment,
+		CommitsAt:  time.Now().Add(u.window),
+		sessionID:  approval.SessionID,
+		committed:  committed,
+	}
+	decision.timer = time.AfterFunc(u.window, func() { u.commit(id) })
+	u.pending[id] = decision
+	copied := *decision
+	return &copied, nil
+}
+
+// Undo retracts a decision still in its undo window
+func (u *Undoable) Undo(id string) (*PendingDecision, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	decision, ok := u.pending[id]
+	if !ok || !decision.timer.Stop() {
+		return nil, ErrNothingToUndo
+	}
+	delete(u.pending, id)
+	slog.Info("undid approval decision", "approval_id", id, "approved", decision.Approved)
+	copied := *decision
+	return &copied, nil
+}
+
+// Pending returns the decision waiting on an approval, if any
+func (u *Undoable) Pending(id string) (*PendingDecision, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	decision, ok := u.pending[id]
+	if !ok {
+		return nil, false
+	}
+	copied := *decision
+	return &copied, true
+}
+
+// commit passes a decision whose undo window has passed to the manager
+func (u *Undoable) commit(id string) {
+	u.mu.Lock()
+	decision, ok := u.pending[id]
+	delete(u.pending, id)
+	u.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	// The request that made the decision is long finished
+	ctx := context.Background()
+	var err error
+	if decision.Approved {
+		err = u.manager.ApproveToolCall(ctx, id, decision.Comment, nil)
+	} else {
+		err = u.manager.DenyToolCall(ctx, id, decision.Comment, nil)
+	}
+	if errors.Is(err, store.ErrAlreadyDecided) {
+		slog.Info("approval decided elsewhere during undo window", "approval_id", id)
+	} else if err != nil {
+		slog.Error("failed to commit approval decision", "approval_id", id, "approved", decision.Approved, "error", err)
+		if u.eventBus != nil {
+			u.eventBus.Publish(bus.Event{
+				Type: bus.EventApprovalDecisionFailed,
+				Data: map[string]interface{}{
+					"approval_id": id,
+					"session_id":  decision.sessionID,
+					"approved":    decision.Approved,
+					"reason":      err.Error(),
+				},
+			})
+		}
+	} else if decision.committed != nil {
+		decision.committed(ctx)
+	}
+}
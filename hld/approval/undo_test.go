@@ -0,0 +1,116 @@
+package approval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUndoableCommitsAfterWindow(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFakeManager()
+	id, err := fake.CreateApproval(ctx, "run-1", "Bash", json.RawMessage(`{"command":"ls"}`))
+	require.NoError(t, err)
+
+	u := NewUndoable(fake, nil, 20*time.Millisecond)
+	decision, err := u.Decide(ctx, id, false, "use make instead")
+	require.NoError(t, err)
+	assert.False(t, decision.Approved)
+
+	approval, err := fake.GetApproval(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, store.ApprovalStatusLocalPending, approval.Status, "nothing is decided during the undo window")
+
+	_, err = u.Decide(ctx, id, true, "")
+	assert.ErrorIs(t, err, ErrDecisionPending)
+
+	require.Eventually(t, func() bool {
+		approval, _ := fake.GetApproval(ctx, id)
+		return approval.Status == store.ApprovalStatusLocalDenied
+	}, time.Second, 5*time.Millisecond)
+	approval, _ = fake.GetApproval(ctx, id)
+	assert.Equal(t, "use make instead", approval.Comment)
+
+	_, err = u.Undo(id)
+	assert.ErrorIs(t, err, ErrNothingToUndo)
+	_, err = u.Decide(ctx, id, true, "")
+	assert.ErrorIs(t, err, store.ErrAlreadyDecided)
+}
+
+func TestUndoableUndo(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFakeManager()
+	id, err := fake.CreateApproval(ctx, "run-1", "Bash", json.RawMessage(`{"command":"ls"}`))
+	require.NoError(t, err)
+
+	u := NewUndoable(fake, nil, 50*time.Millisecond)
+	var committed atomic.Bool
+	_, err = u.DecideThen(ctx, id, true, "", func(context.Context) { committed.Store(true) })
+	require.NoError(t, err)
+	undone, err := u.Undo(id)
+	require.NoError(t, err)
+	assert.True(t, undone.Approved)
+	_, pending := u.Pending(id)
+	assert.False(t, pending)
+
+	time.Sleep(100 * time.Millisecond)
+	approval, err := fake.GetApproval(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, store.ApprovalStatusLocalPending, approval.Status)
+	assert.False(t, committed.Load(), "an undone decision isn't committed")
+
+	// The approval can be decided again once undone
+	_, err = u.DecideThen(ctx, id, false, "not now", func(context.Context) { committed.Store(true) })
+	require.NoError(t, err)
+	require.Eventually(t, committed.Load, time.Second, 5*time.Millisecond)
+}
+
+// rejectingManager fails every decision, as moderation or a broken store would
+type rejectingManager struct {
+	*FakeManager
+	err error
+}
+
+func (m *rejectingManager) ApproveToolCall(ctx context.Context, id string, comment string, imagePaths []string) error {
+	return m.err
+}
+
+func (m *rejectingManager) DenyToolCall(ctx context.Context, id string, reason string, imagePaths []string) error {
+	return m.err
+}
+
+func TestUndoablePublishesFailedCommit(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fake := NewFakeManager()
+	id, err := fake.CreateApproval(ctx, "run-1", "Bash", json.RawMessage(`{"command":"ls"}`))
+	require.NoError(t, err)
+
+	eventBus := bus.NewEventBus()
+	sub := eventBus.Subscribe(ctx, bus.EventFilter{Types: []bus.EventType{bus.EventApprovalDecisionFailed}})
+	u := NewUndoable(&rejectingManager{FakeManager: fake, err: errors.New("comment blocked by moderation")}, eventBus, 10*time.Millisecond)
+	var committed atomic.Bool
+	_, err = u.DecideThen(ctx, id, true, "ship it", func(context.Context) { committed.Store(true) })
+	require.NoError(t, err)
+
+	select {
+	case event := <-sub.Channel:
+		assert.Equal(t, id, event.Data["approval_id"])
+		assert.Equal(t, true, event.Data["approved"])
+		assert.Equal(t, "comment blocked by moderation", event.Data["reason"])
+	case <-time.After(time.Second):
+		t.Fatal("no approval_decision_failed event")
+	}
+	assert.False(t, committed.Load(), "a failed decision isn't reported as committed")
+	approval, err := fake.GetApproval(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, store.ApprovalStatusLocalPending, approval.Status)
+}
@@ -0,0 +1,296 @@
+// Package artifacts keeps the files sessions publish, such as built
+// binaries, coverage reports and screenshots, for a retention period, and
+// signs the links they're downloaded through.
+package artifacts
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/diskguard"
+	"github.com/humanlayer/humanlayer/hld/internal/keyfile"
+	"github.com/humanlayer/humanlayer/hld/scheduler"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// Errors reported for requests the service refuses
+var (
+	ErrInvalidName = errors.New("invalid artifact name")
+	ErrNotFound    = errors.New("artifact not found")
+	ErrTooLarge    = errors.New("artifact too large")
+	ErrLinkExpired = errors.New("download link expired")
+	ErrLinkInvalid = errors.New("invalid download link signature")
+)
+
+// sweepInterval is how often expired artifacts are deleted
+const sweepInterval = time.Hour
+
+// maxNameLength bounds an artifact's file name
+const maxNameLength = 255
+
+// Dir is where artifact content is kept by default, next to the database
+func Dir(databasePath string) string {
+	return filepath.Join(filepath.Dir(databasePath), "artifacts")
+}
+
+// Service publishes, serves and expires artifacts
+type Service struct {
+	store     store.ArtifactStore
+	blobs     Blobs
+	eventBus  bus.EventBus
+	keyFile   string
+	retention time.Duration // zero keeps artifacts until they're deleted
+	maxSize   int64
+	linkTTL   time.Duration
+	now       func() time.Time
+	dir       string // where blobs are kept, for free space checks
+	// guard refuses artifacts that would fill the disk; nil skips it
+	guard *diskguard.Guard
+
+	mu  sync.Mutex
+	key []byte // loaded from keyFile on first use
+}
+
+// New creates a service storing content in blobs and signing links with
+// key, with unset limits defaulted
+func New(s store.ArtifactStore, blobs Blobs, eventBus bus.EventBus, cfg config.ArtifactsConfig, key []byte) *Service {
+	svc := &Service{
+		store:    s,
+		blobs:    blobs,
+		eventBus: eventBus,
+		key:      key,
+		maxSize:  cfg.MaxSize,
+		linkTTL:  config.DefaultArtifactLinkTTL,
+		now:      time.Now,
+	}
+	switch {
+	case cfg.RetentionDays > 0:
+		svc.retention = time.Duration(cfg.RetentionDays) * 24 * time.Hour
+	case cfg.RetentionDays == 0:
+		svc.retention = config.DefaultArtifactRetentionDays * 24 * time.Hour
+	}
+	if svc.maxSize <= 0 {
+		svc.maxSize = config.DefaultArtifactMaxSize
+	}
+	if ttl, err := time.ParseDuration(cfg.LinkTTL); err == nil && ttl > 0 {
+		svc.linkTTL = ttl
+	}
+	return svc
+}
+
+// FromConfig creates the daemon's service, with content under the
+// configured directory and the signing key in artifacts.key beside the
+// database
+func FromConfig(cfg *config.Config, s store.ArtifactStore, eventBus bus.EventBus) *Service {
+	dir := cfg.Artifacts.Dir
+	if dir == "" {
+		dir = Dir(cfg.DatabasePath)
+	}
+	svc := New(s, NewDirBlobs(dir), eventBus, cfg.Artifacts, nil)
+	svc.keyFile = filepath.Join(filepath.Dir(cfg.DatabasePath), "artifacts.key")
+	svc.dir = dir
+	return svc
+}
+
+// SetDiskGuard has publishing check the free space left for artifacts
+func (s *Service) SetDiskGuard(guard *diskguard.Guard) {
+	s.guard = guard
+}
+
+func (s *Service) signingKey() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.key == nil {
+		key, err := keyfile.Load(s.keyFile, 32)
+		if err != nil {
+			return nil, fmt.Errorf("artifacts key: %w", err)
+		}
+		s.key = key
+	}
+	return s.key, nil
+}
+
+// MaxSize is the largest artifact that can be published
+func (s *Service) MaxSize() int64 { return s.maxSize }
+
+// LinkTTL is how long a new download link stays valid
+func (s *Service) LinkTTL() time.Duration { return s.linkTTL }
+
+// Publish stores what r holds as an artifact of sessionID. An empty
+// contentType is detected from the content.
+func (s *Service) Publish(ctx context.Context, sessionID, name, kind, contentType string, r io.Reader) (*store.Artifact, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxNameLength || strings.ContainsAny(name, "/\\\x00") || name == "." || name == ".." {
+		return nil, ErrInvalidName
+	}
+	if contentType == "" {
+		buffered := bufio.NewReader(r)
+		head, _ := buffered.Peek(512)
+		contentType = http.DetectContentType(head)
+		r = buffered
+	}
+
+	if s.guard != nil {
+		// Content from a buffer has a known size; otherwise only the
+		// minimum free space is checked
+		var size int64
+		if sized, ok := r.(interface{ Len() int }); ok {
+			size = int64(sized.Len())
+		}
+		if err := s.guard.Check(ctx, diskguard.Write{Operation: "artifact", SessionID: sessionID, Dir: s.dir, Size: size}); err != nil {
+			return nil, err
+		}
+	}
+
+	now := s.now()
+	artifact := &store.Artifact{
+		ID:          uuid.New().String(),
+		SessionID:   sessionID,
+		Name:        name,
+		Kind:        kind,
+		ContentType: contentType,
+		CreatedAt:   now,
+	}
+	if s.retention > 0 {
+		expiresAt := now.Add(s.retention)
+		artifact.ExpiresAt = &expiresAt
+	}
+	var err error
+	if artifact.Size, artifact.SHA256, err = s.blobs.Put(artifact.ID, r, s.maxSize); err != nil {
+		return nil, err
+	}
+	if err := s.store.CreateArtifact(ctx, artifact); err != nil {
+		_ = s.blobs.Delete(artifact.ID)
+		return nil, err
+	}
+
+	if s.eventBus != nil {
+		s.eventBus.Publish(bus.Event{
+			Type: bus.EventArtifactPublished,
+			Data: map[string]interface{}{
+				"session_id":   sessionID,
+				"artifact_id":  artifact.ID,
+				"name":         artifact.Name,
+				"kind":         artifact.Kind,
+				"content_type": artifact.ContentType,
+				"size":         artifact.Size,
+			},
+		})
+	}
+	return artifact, nil
+}
+
+// Get returns an artifact's record
+func (s *Service) Get(ctx context.Context, id string) (*store.Artifact, error) {
+	artifact, err := s.store.GetArtifact(ctx, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	return artifact, err
+}
+
+// Open returns an artifact with its content, which the caller closes
+func (s *Service) Open(ctx context.Context, id string) (*store.Artifact, io.ReadSeekCloser, error) {
+	artifact, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	content, err := s.blobs.Open(artifact.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return artifact, content, nil
+}
+
+// Delete removes an artifact and its content
+func (s *Service) Delete(ctx context.Context, id string) error {
+	err := s.store.DeleteArtifact(ctx, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return s.blobs.Delete(id)
+}
+
+// Sign returns the signature of a link to id valid until expires
+func (s *Service) Sign(id string, expires time.Time) (string, error) {
+	key, err := s.signingKey()
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	_, _ = fmt.Fprintf(mac, "%s\n%d", id, expires.Unix())
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// Verify checks a link's signature and that it hasn't expired; expires is
+// in Unix seconds, as the link carries it
+func (s *Service) Verify(id, expires, signature string) error {
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return ErrLinkInvalid
+	}
+	want, err := s.Sign(id, time.Unix(unix, 0))
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(want), []byte(signature)) {
+		return ErrLinkInvalid
+	}
+	if s.now().Unix() > unix {
+		return ErrLinkExpired
+	}
+	return nil
+}
+
+// Task is the maintenance task deleting artifacts as their retention ends
+func (s *Service) Task() scheduler.Task {
+	return scheduler.Task{
+		Name:        "artifact-retention",
+		Description: "Delete published artifacts whose retention has ended",
+		Interval:    sweepInterval,
+		RunAtStart:  true,
+		Run: func(ctx context.Context) error {
+			n, err := s.Sweep(ctx)
+			if n > 0 {
+				slog.Info("deleted expired artifacts", "count", n)
+			}
+			return err
+		},
+	}
+}
+
+// Sweep deletes the artifacts whose retention has ended, returning how many
+func (s *Service) Sweep(ctx context.Context) (int, error) {
+	expired, err := s.store.ListExpiredArtifacts(ctx, s.now())
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for _, artifact := range expired {
+		if err := s.Delete(ctx, artifact.ID); err != nil && !errors.Is(err, ErrNotFound) {
+			slog.Warn("failed to delete expired artifact", "artifact_id", artifact.ID, "error", err)
+			continue
+		}
+		deleted++
+	}
+	return deleted, nil
+}
@@ -0,0 +1,145 @@
+package artifacts
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/diskguard"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+func newService(t *testing.T, cfg config.ArtifactsConfig) (*Service, store.Store) {
+	t.Helper()
+	s := store.NewInMemoryStore()
+	return New(s, NewDirBlobs(t.TempDir()), nil, cfg, []byte("0123456789abcdef0123456789abcdef")), s
+}
+
+func TestPublishAndOpen(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newService(t, config.ArtifactsConfig{})
+
+	artifact, err := svc.Publish(ctx, "sess-1", "report.html", "coverage", "", strings.NewReader("<html><body>87%</body></html>"))
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if artifact.ContentType != "text/html; charset=utf-8" {
+		t.Errorf("content type = %q, want it detected as HTML", artifact.ContentType)
+	}
+	if artifact.Size != 29 || len(artifact.SHA256) != 64 {
+		t.Errorf("size %d, sha256 %q", artifact.Size, artifact.SHA256)
+	}
+	if artifact.ExpiresAt == nil || artifact.ExpiresAt.Sub(artifact.CreatedAt) != 30*24*time.Hour {
+		t.Errorf("expires at %v, want 30 days after creation", artifact.ExpiresAt)
+	}
+
+	_, content, err := svc.Open(ctx, artifact.ID)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	body, _ := io.ReadAll(content)
+	_ = content.Close()
+	if string(body) != "<html><body>87%</body></html>" {
+		t.Errorf("content = %q", body)
+	}
+
+	if err := svc.Delete(ctx, artifact.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, _, err := svc.Open(ctx, artifact.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Open after delete = %v, want ErrNotFound", err)
+	}
+}
+
+func TestPublishRefuses(t *testing.T) {
+	ctx := context.Background()
+	svc, s := newService(t, config.ArtifactsConfig{MaxSize: 4})
+
+	for _, name := range []string{"", "..", "dir/file", strings.Repeat("a", 300)} {
+		if _, err := svc.Publish(ctx, "sess-1", name, "", "text/plain", strings.NewReader("ok")); !errors.Is(err, ErrInvalidName) {
+			t.Errorf("Publish(%q) = %v, want ErrInvalidName", name, err)
+		}
+	}
+	if _, err := svc.Publish(ctx, "sess-1", "big.bin", "", "", strings.NewReader("too big")); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("oversized Publish = %v, want ErrTooLarge", err)
+	}
+	svc.dir = t.TempDir()
+	svc.SetDiskGuard(diskguard.New(config.DiskGuardConfig{MinFree: 1 << 60}, nil))
+	if _, err := svc.Publish(ctx, "sess-1", "ok.txt", "", "", strings.NewReader("ok")); !errors.Is(err, diskguard.ErrLowDiskSpace) {
+		t.Errorf("Publish to a full disk = %v, want ErrLowDiskSpace", err)
+	}
+	if artifacts, _ := s.ListSessionArtifacts(ctx, "sess-1"); len(artifacts) != 0 {
+		t.Errorf("refused artifacts were recorded: %v", artifacts)
+	}
+}
+
+func TestSignedLinks(t *testing.T) {
+	svc, _ := newService(t, config.ArtifactsConfig{})
+	now := time.Unix(1700000000, 0)
+	svc.now = func() time.Time { return now }
+
+	expires := now.Add(time.Hour)
+	signature, err := svc.Sign("a1", expires)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unix := strconv.FormatInt(expires.Unix(), 10)
+	if err := svc.Verify("a1", unix, signature); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if err := svc.Verify("a2", unix, signature); !errors.Is(err, ErrLinkInvalid) {
+		t.Errorf("link for another artifact = %v, want ErrLinkInvalid", err)
+	}
+	if err := svc.Verify("a1", strconv.FormatInt(expires.Unix()+3600, 10), signature); !errors.Is(err, ErrLinkInvalid) {
+		t.Errorf("extended expiry = %v, want ErrLinkInvalid", err)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if err := svc.Verify("a1", unix, signature); !errors.Is(err, ErrLinkExpired) {
+		t.Errorf("expired link = %v, want ErrLinkExpired", err)
+	}
+}
+
+func TestSweep(t *testing.T) {
+	ctx := context.Background()
+	svc, s := newService(t, config.ArtifactsConfig{RetentionDays: 1})
+	now := time.Now()
+	svc.now = func() time.Time { return now }
+
+	old, err := svc.Publish(ctx, "sess-1", "old.txt", "", "", strings.NewReader("old"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(36 * time.Hour)
+	fresh, err := svc.Publish(ctx, "sess-1", "fresh.txt", "", "", strings.NewReader("fresh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := svc.Sweep(ctx); err != nil || n != 1 {
+		t.Fatalf("Sweep = %d, %v; want 1 deleted", n, err)
+	}
+	artifacts, _ := s.ListSessionArtifacts(ctx, "sess-1")
+	if len(artifacts) != 1 || artifacts[0].ID != fresh.ID {
+		t.Errorf("remaining artifacts = %v, want only %s", artifacts, fresh.ID)
+	}
+	if _, err := svc.blobs.Open(old.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expired content still stored: %v", err)
+	}
+}
+
+func TestRetentionDisabled(t *testing.T) {
+	svc, _ := newService(t, config.ArtifactsConfig{RetentionDays: -1})
+	artifact, err := svc.Publish(context.Background(), "sess-1", "keep.txt", "", "", strings.NewReader("keep"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if artifact.ExpiresAt != nil {
+		t.Errorf("expires at %v, want never", artifact.ExpiresAt)
+	}
+}
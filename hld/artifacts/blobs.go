@@ -0,0 +1,97 @@
+package artifacts
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Blobs stores artifact content by key
+type Blobs interface {
+	// Put stores what r holds under key, failing with ErrTooLarge past limit
+	// bytes, and returns its size and SHA-256
+	Put(key string, r io.Reader, limit int64) (int64, string, error)
+	Open(key string) (io.ReadSeekCloser, error)
+	// Delete removes key; a missing key isn't an error
+	Delete(key string) error
+}
+
+// DirBlobs keeps blobs as files in a directory, fanned out by the first two
+// characters of their keys
+type DirBlobs struct {
+	dir string
+}
+
+// NewDirBlobs stores blobs under dir, which is created on the first Put
+func NewDirBlobs(dir string) *DirBlobs {
+	return &DirBlobs{dir: dir}
+}
+
+func (b *DirBlobs) path(key string) (string, error) {
+	if len(key) < 3 || strings.ContainsAny(key, `/\.`) {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(b.dir, key[:2], key), nil
+}
+
+// Put writes to a temporary file renamed into place once complete, so a
+// failed upload leaves nothing behind
+func (b *DirBlobs) Put(key string, r io.Reader, limit int64) (int64, string, error) {
+	path, err := b.path(key)
+	if err != nil {
+		return 0, "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return 0, "", err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return 0, "", err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), io.LimitReader(r, limit+1))
+	if err == nil && size > limit {
+		err = fmt.Errorf("%w: the limit is %d bytes", ErrTooLarge, limit)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, "", err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func (b *DirBlobs) Open(key string) (io.ReadSeekCloser, error) {
+	path, err := b.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (b *DirBlobs) Delete(key string) error {
+	path, err := b.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultChangeLogSize is how many recent events a change log keeps
+const DefaultChangeLogSize = 1000
+
+// Change is an event with its position in the change log
+type Change struct {
+	Seq uint64 `json:"seq"`
+	Event
+}
+
+// ChangeLog keeps the most recent events with sequence numbers so clients
+// that can't hold a stream open can ask for what happened since a cursor.
+// Cursors carry the daemon's start time, so one from before a restart is
+// recognised as stale.
+type ChangeLog struct {
+	epoch string
+
+	mu      sync.Mutex
+	changes []Change
+	lastSeq uint64
+	// changed is closed and replaced whenever an event is recorded
+	changed chan struct{}
+}
+
+// NewChangeLog creates a change log keeping up to size events
+func NewChangeLog(size int) *ChangeLog {
+	if size <= 0 {
+		size = DefaultChangeLogSize
+	}
+	return &ChangeLog{
+		epoch:   strconv.FormatInt(time.Now().UnixNano(), 36),
+		changes: make([]Change, 0, size),
+		changed: make(chan struct{}),
+	}
+}
+
+// Follow records every event published on eventBus until ctx is done. It
+// records from within Publish rather than through a subscription, which
+// drops events when it falls behind and would leave gaps in the log that
+// Wait can't see.
+func (l *ChangeLog) Follow(ctx context.Context, eventBus EventBus) {
+	eventBus.OnPublish(func(event Event) {
+		if ctx.Err() == nil {
+			l.Record(event)
+		}
+	})
+}
+
+// Record appends an event, dropping the oldest if the log is full
+func (l *ChangeLog) Record(event Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lastSeq++
+	if len(l.changes) == cap(l.changes) {
+		copy(l.changes, l.changes[1:])
+		l.changes = l.changes[:len(l.changes)-1]
+	}
+	l.changes = append(l.changes, Change{Seq: l.lastSeq, Event: event})
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// Cursor returns a cursor for the current end of the log
+func (l *ChangeLog) Cursor() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.cursor(l.lastSeq)
+}
+
+func (l *ChangeLog) cursor(seq uint64) string {
+	return fmt.Sprintf("%s-%d", l.epoch, seq)
+}
+
+// ErrInvalidCursor is returned for a cursor this package didn't make
+var ErrInvalidCursor = errors.New("invalid change cursor")
+
+// ChangesResult is what a wait for changes found
+type ChangesResult struct {
+	Changes []Change `json:"changes"`
+	// Cursor is where the next wait should start
+	Cursor string `json:"cursor"`
+	// Reset is set when changes after the given cursor were dropped, or it's
+	// from before a restart; clients should refetch their state
+	Reset bool `json:"reset,omitempty"`
+}
+
+// Wait returns the changes after cursor that match filter, waiting up to
+// timeout for one if there are none yet
+func (l *ChangeLog) Wait(ctx context.Context, cursor string, filter EventFilter, timeout time.Duration) (ChangesResult, error) {
+	epoch, seqText, ok := strings.Cut(cursor, "-")
+	seq, err := strconv.ParseUint(seqText, 10, 64)
+	if !ok || err != nil {
+		return ChangesResult{}, ErrInvalidCursor
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		l.mu.Lock()
+		if epoch != l.epoch || seq > l.lastSeq || (len(l.changes) > 0 && seq+1 < l.changes[0].Seq) {
+			result := ChangesResult{Changes: []Change{}, Cursor: l.cursor(l.lastSeq), Reset: true}
+			l.mu.Unlock()
+			return result, nil
+		}
+		result := ChangesResult{Changes: []Change{}, Cursor: l.cursor(l.lastSeq)}
+		for _, change := range l.changes {
+			if change.Seq > seq && matchesFilter(change.Event, filter) {
+				result.Changes = append(result.Changes, change)
+			}
+		}
+		last, changed := l.lastSeq, l.changed
+		l.mu.Unlock()
+
+		if len(result.Changes) > 0 {
+			return result, nil
+		}
+		// Nothing relevant yet; anything skipped needn't be scanned again
+		seq = last
+
+		select {
+		case <-changed:
+		case <-timer.C:
+			return result, nil
+		case <-ctx.Done():
+			return result, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestChangeLog_Wait(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns changes after the cursor", func(t *testing.T) {
+		log := NewChangeLog(10)
+		cursor := log.Cursor()
+		log.Record(Event{Type: EventNewApproval, Data: map[string]interface{}{"session_id": "a"}})
+		log.Record(Event{Type: EventSessionStatusChanged, Data: map[string]interface{}{"session_id": "b"}})
+
+		result, err := log.Wait(ctx, cursor, EventFilter{SessionID: "b"}, time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Changes) != 1 || result.Changes[0].Type != EventSessionStatusChanged || result.Changes[0].Seq != 2 {
+			t.Fatalf("unexpected changes: %+v", result.Changes)
+		}
+		if result.Cursor != log.Cursor() || result.Reset {
+			t.Errorf("expected the current cursor without reset, got %+v", result)
+		}
+	})
+
+	t.Run("waits for a matching event", func(t *testing.T) {
+		log := NewChangeLog(10)
+		cursor := log.Cursor()
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			log.Record(Event{Type: EventNewApproval})
+			time.Sleep(20 * time.Millisecond)
+			log.Record(Event{Type: EventApprovalResolved})
+		}()
+
+		start := time.Now()
+		result, err := log.Wait(ctx, cursor, EventFilter{Types: []EventType{EventApprovalResolved}}, 5*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Changes) != 1 || result.Changes[0].Type != EventApprovalResolved {
+			t.Fatalf("unexpected changes: %+v", result.Changes)
+		}
+		if time.Since(start) > 2*time.Second {
+			t.Error("wait didn't return when the event arrived")
+		}
+	})
+
+	t.Run("times out with the latest cursor", func(t *testing.T) {
+		log := NewChangeLog(10)
+		cursor := log.Cursor()
+		log.Record(Event{Type: EventNewApproval})
+
+		result, err := log.Wait(ctx, cursor, EventFilter{Types: []EventType{EventJobCompleted}}, 20*time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Changes) != 0 || result.Cursor != log.Cursor() {
+			t.Errorf("expected no changes at the current cursor, got %+v", result)
+		}
+	})
+
+	t.Run("resets when changes were dropped", func(t *testing.T) {
+		log := NewChangeLog(2)
+		cursor := log.Cursor()
+		for range 3 {
+			log.Record(Event{Type: EventNewApproval})
+		}
+		result, err := log.Wait(ctx, cursor, EventFilter{}, time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.Reset || len(result.Changes) != 0 {
+			t.Errorf("expected a reset, got %+v", result)
+		}
+	})
+
+	t.Run("resets for a cursor from before a restart", func(t *testing.T) {
+		earlier := NewChangeLog(10)
+		earlier.Record(Event{Type: EventNewApproval})
+		log := NewChangeLog(10)
+		log.epoch = "other"
+
+		result, err := log.Wait(ctx, earlier.Cursor(), EventFilter{}, time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.Reset {
+			t.Errorf("expected a reset, got %+v", result)
+		}
+	})
+
+	t.Run("rejects malformed cursors", func(t *testing.T) {
+		log := NewChangeLog(10)
+		if _, err := log.Wait(ctx, "nope", EventFilter{}, time.Second); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("expected ErrInvalidCursor, got %v", err)
+		}
+	})
+}
+
+func TestChangeLog_Follow(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	eventBus := NewEventBus()
+	log := NewChangeLog(DefaultChangeLogSize)
+	log.Follow(ctx, eventBus)
+	cursor := log.Cursor()
+
+	// More than a subscriber's buffer, published faster than one could read
+	for i := 0; i < 300; i++ {
+		eventBus.Publish(Event{Type: EventConversationUpdated, Data: map[string]interface{}{"session_id": "a"}})
+	}
+	result, err := log.Wait(ctx, cursor, EventFilter{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Changes) != 300 || result.Reset {
+		t.Fatalf("expected all 300 changes without reset, got %d (reset %v)", len(result.Changes), result.Reset)
+	}
+
+	cancel()
+	eventBus.Publish(Event{Type: EventConversationUpdated})
+	if got := log.Cursor(); got != result.Cursor {
+		t.Errorf("recorded an event after ctx was done: cursor %s, want %s", got, result.Cursor)
+	}
+}
@@ -12,6 +12,7 @@ import (
 // eventBus is the concrete implementation of EventBus
 type eventBus struct {
 	subscribers map[string]*Subscriber
+	hooks       []func(Event)
 	mu          sync.RWMutex
 	bufferSize  int
 }
@@ -91,6 +92,10 @@ func (eb *eventBus) Publish(event Event) {
 
 	event.Timestamp = time.Now()
 
+	for _, hook := range eb.hooks {
+		hook(event)
+	}
+
 	slog.Debug("publishing event",
 		"type", event.Type,
 		"data", event.Data,
@@ -99,7 +104,7 @@ func (eb *eventBus) Publish(event Event) {
 
 	matchedCount := 0
 	for _, sub := range eb.subscribers {
-		if eb.matchesFilter(event, sub.Filter) {
+		if matchesFilter(event, sub.Filter) {
 			matchedCount++
 			select {
 			case sub.Channel <- event:
@@ -125,7 +130,7 @@ func (eb *eventBus) Publish(event Event) {
 }
 
 // matchesFilter checks if an event matches a subscriber's filter
-func (eb *eventBus) matchesFilter(event Event, filter EventFilter) bool {
+func matchesFilter(event Event, filter EventFilter) bool {
 	// Check event type filter
 	if len(filter.Types) > 0 {
 		matched := false
@@ -178,6 +183,13 @@ func (eb *eventBus) matchesFilter(event Event, filter EventFilter) bool {
 	return true
 }
 
+// OnPublish calls hook with every event published from now on
+func (eb *eventBus) OnPublish(hook func(Event)) {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+	eb.hooks = append(eb.hooks, hook)
+}
+
 // GetSubscriberCount returns the current number of subscribers
 func (eb *eventBus) GetSubscriberCount() int {
 	eb.mu.RLock()
@@ -0,0 +1,62 @@
+package bus
+
+import (
+	"sync"
+	"time"
+)
+
+// InMemoryBus is an EventBus that delivers events to subscribers like the
+// default bus and also records every published event, so tests can assert on
+// what was emitted without subscribing first.
+type InMemoryBus struct {
+	EventBus
+
+	mu     sync.Mutex
+	events []Event
+}
+
+// NewInMemoryBus creates a recording event bus
+func NewInMemoryBus() *InMemoryBus {
+	return &InMemoryBus{EventBus: NewEventBus()}
+}
+
+// Publish records the event and forwards it to subscribers
+func (b *InMemoryBus) Publish(event Event) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	b.mu.Lock()
+	b.events = append(b.events, event)
+	b.mu.Unlock()
+
+	b.EventBus.Publish(event)
+}
+
+// Events returns every event published so far, oldest first
+func (b *InMemoryBus) Events() []Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]Event(nil), b.events...)
+}
+
+// EventsOfType returns the published events of the given type, oldest first
+func (b *InMemoryBus) EventsOfType(eventType EventType) []Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var matched []Event
+	for _, event := range b.events {
+		if event.Type == eventType {
+			matched = append(matched, event)
+		}
+	}
+	return matched
+}
+
+// Reset discards the recorded events
+func (b *InMemoryBus) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.events = nil
+}
@@ -0,0 +1,35 @@
+package bus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryBus_RecordsAndDelivers(t *testing.T) {
+	b := NewInMemoryBus()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sub := b.Subscribe(ctx, EventFilter{Types: []EventType{EventNewApproval}})
+
+	b.Publish(Event{Type: EventNewApproval, Data: map[string]interface{}{"approval_id": "a1"}})
+	b.Publish(Event{Type: EventSessionStatusChanged})
+
+	select {
+	case event := <-sub.Channel:
+		assert.Equal(t, "a1", event.Data["approval_id"])
+	case <-time.After(time.Second):
+		t.Fatal("subscriber did not receive event")
+	}
+
+	require.Len(t, b.Events(), 2)
+	require.Len(t, b.EventsOfType(EventSessionStatusChanged), 1)
+	assert.False(t, b.Events()[0].Timestamp.IsZero())
+
+	b.Reset()
+	assert.Empty(t, b.Events())
+}
@@ -21,6 +21,66 @@ const (
 	// Data includes: session_id, run_id, changed settings, and optional "reason" field
 	// For dangerous skip permissions expiry: reason="expired", expired_at=timestamp
 	EventSessionSettingsChanged EventType = "session_settings_changed"
+	// EventToolResultReported indicates the output of an executed tool call was captured
+	// Data includes: session_id, tool_use_id, approval_id (if linked), tool_name, is_error, source
+	EventToolResultReported EventType = "tool_result_reported"
+	// EventSessionBudgetExceeded indicates an approval was denied because the session is over its budget
+	// Data includes: session_id, run_id, tool_name, reason
+	EventSessionBudgetExceeded EventType = "session_budget_exceeded"
+	// EventCostBudgetThreshold indicates daemon-wide spend crossed 50, 80 or 100% of a cost budget
+	// Data includes: budget, period, period_start, threshold, spent_usd, limit_usd
+	EventCostBudgetThreshold EventType = "cost_budget_threshold"
+	// EventCommitMessageProgress reports a stage of streamed commit message generation
+	// Data includes: request_id, session_id, stage, and stage details (files_processed,
+	// files_total, result on "completed", status and error on "failed")
+	EventCommitMessageProgress EventType = "commit_message_progress"
+	// EventJobCompleted indicates a background job finished, failed or was interrupted
+	// Data includes: job_id, kind, status, session_id (if any), error (if any)
+	EventJobCompleted EventType = "job_completed"
+	// EventDevcontainerProgress reports a stage of devcontainer setup before a session launches
+	// Data includes: session_id, stage, detail
+	EventDevcontainerProgress EventType = "devcontainer_progress"
+	// EventSessionSummaryReady indicates a finished session's completion summary was generated
+	// Data includes: session_id, run_id, status, summary (asked, changed, open_questions, follow_ups)
+	EventSessionSummaryReady EventType = "session_summary_ready"
+	// EventDailyDigest carries one recipient's daily digest for one workspace
+	// Data includes: recipient, plugins, workspace, start, end, sessions, completed, failed,
+	// interrupted, approvals, approved, denied, cost_usd, failures (session_id, title, error)
+	EventDailyDigest EventType = "daily_digest"
+	// EventSessionQueued indicates a launch is waiting for one of max_concurrent_sessions;
+	// it starts with a session_status_changed from queued to starting
+	// Data includes: session_id, run_id, priority, position
+	EventSessionQueued EventType = "session_queued"
+	// EventSessionRetryScheduled indicates a transiently failed session will be retried
+	// Data includes: session_id, run_id, attempt, max_attempts, mode, delay_ms, error
+	EventSessionRetryScheduled EventType = "session_retry_scheduled"
+	// EventArtifactPublished indicates a session published an artifact
+	// Data includes: session_id, artifact_id, name, kind, content_type, size
+	EventArtifactPublished EventType = "artifact_published"
+	// EventApprovalReminder indicates a user's snooze of a still-pending approval ran out
+	// Data includes: approval_id, session_id, tool_name, recipient
+	EventApprovalReminder EventType = "approval_reminder"
+	// EventApprovalHeld indicates an approver parked a pending approval
+	// Data includes: approval_id, session_id, tool_use_id, tool_name, reason, resume_at
+	EventApprovalHeld EventType = "approval_held"
+	// EventApprovalUnheld indicates a parked approval is back in the queue
+	// Data includes: approval_id, session_id, tool_use_id, tool_name
+	EventApprovalUnheld EventType = "approval_unheld"
+	// EventApprovalConstraintViolated indicates a reported tool call broke the constraints it was approved with
+	// Data includes: approval_id, session_id, tool_use_id, kind, detail
+	EventApprovalConstraintViolated EventType = "approval_constraint_violated"
+	// EventCloudApprovalConflict indicates a mirrored approval was decided differently in HumanLayer cloud
+	// Data includes: approval_id, session_id, local_approved, remote_approved, remote_comment
+	EventCloudApprovalConflict EventType = "cloud_approval_conflict"
+	// EventDiskGuardRefused indicates a write was refused for low disk space or repository growth
+	// Data includes: operation, session_id, dir, reason, size_bytes, min_free_bytes, free_bytes, max_repo_growth_bytes
+	EventDiskGuardRefused EventType = "disk_guard_refused"
+	// EventCommandOutput carries a line written by a command the daemon runs, or that it exited
+	// Data includes: operation_id, session_id, command, seq, and stream and line, or done, exit_code and error
+	EventCommandOutput EventType = "command_output"
+	// EventApprovalDecisionFailed indicates a decision made with an undo window couldn't be applied once the window passed
+	// Data includes: approval_id, session_id, approved, reason
+	EventApprovalDecisionFailed EventType = "approval_decision_failed"
 )
 
 // SessionSettingsChangeReason represents reasons for session settings changes
@@ -64,4 +124,8 @@ type EventBus interface {
 	Publish(event Event)
 	// GetSubscriberCount returns the current number of subscribers
 	GetSubscriberCount() int
+	// OnPublish calls hook with every event published from now on,
+	// synchronously and before subscribers get it, so unlike a subscriber it
+	// can't fall behind and miss events. hook mustn't publish.
+	OnPublish(hook func(Event))
 }
@@ -0,0 +1,292 @@
+// Package ci implements `hld ci run`: it launches a session in CI mode,
+// where the approval policy alone decides every tool call, waits for it to
+// finish, and writes JUnit and JSON reports of the actions it took.
+package ci
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/client"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/daemon"
+	"github.com/humanlayer/humanlayer/hld/rpc"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// Exit statuses of hld ci run
+const (
+	ExitSuccess = 0 // The session completed
+	ExitFailed  = 1 // The session failed or was interrupted
+	ExitDenied  = 2 // The session completed, but the policy denied tool calls (with -fail-on-deny)
+	ExitTimeout = 3 // The session didn't finish in time and was interrupted
+	ExitError   = 4 // The session couldn't be run
+)
+
+const (
+	pollInterval = time.Second
+	// interruptGrace is how long a timed-out session gets to stop
+	interruptGrace = 30 * time.Second
+)
+
+const usage = `Usage: hld ci run [flags] [query]
+
+Launches a session with headless approvals, waits for it to finish and
+reports the actions it took. Tool calls the approval policy doesn't allow
+are denied. Exit status: 0 completed, 1 failed, 2 denied tool calls (with
+-fail-on-deny), 3 timed out, 4 error.
+`
+
+// Main runs the ci subcommand with args (after "ci") and returns the exit status
+func Main(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] != "run" {
+		fmt.Fprint(stderr, usage)
+		return ExitError
+	}
+	code, err := run(ctx, args[1:], stdout, stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "hld ci: %v\n", err)
+	}
+	return code
+}
+
+type options struct {
+	query      string
+	workingDir string
+	title      string
+	model      string
+	maxTurns   int
+	timeout    time.Duration
+	policy     string
+	socket     string
+	junit      string
+	json       string
+	failOnDeny bool
+	verbose    bool
+}
+
+func parseFlags(args []string, stderr io.Writer) (*options, error) {
+	opts := &options{}
+	fs := flag.NewFlagSet("hld ci run", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage+"\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&opts.query, "query", "", "task for the agent; trailing arguments are used when unset")
+	fs.StringVar(&opts.workingDir, "working-dir", "", "directory the session works in (default: current directory)")
+	fs.StringVar(&opts.title, "title", "", "session title")
+	fs.StringVar(&opts.model, "model", "", "opus, sonnet or haiku")
+	fs.IntVar(&opts.maxTurns, "max-turns", 0, "limit on agent turns")
+	fs.DurationVar(&opts.timeout, "timeout", 30*time.Minute, "interrupt the session after this long")
+	fs.StringVar(&opts.policy, "policy", "", "approval policy file (default: approval_policy_path from the config)")
+	fs.StringVar(&opts.socket, "socket", "", "use a daemon already running in CI mode at this socket instead of starting one")
+	fs.StringVar(&opts.junit, "junit", "", "write a JUnit XML report to this file")
+	fs.StringVar(&opts.json, "json", "", "write a JSON report to this file, or - for stdout")
+	fs.BoolVar(&opts.failOnDeny, "fail-on-deny", false, "exit 2 when the policy denied any tool call")
+	fs.BoolVar(&opts.verbose, "verbose", false, "show daemon logs")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if opts.query == "" {
+		opts.query = strings.Join(fs.Args(), " ")
+	}
+	if strings.TrimSpace(opts.query) == "" {
+		return nil, errors.New("a query is required")
+	}
+	if opts.workingDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		opts.workingDir = cwd
+	}
+	abs, err := filepath.Abs(opts.workingDir)
+	if err != nil {
+		return nil, err
+	}
+	opts.workingDir = abs
+	return opts, nil
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) (int, error) {
+	opts, err := parseFlags(args, stderr)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitSuccess, nil
+		}
+		return ExitError, err
+	}
+
+	level := slog.LevelWarn
+	if opts.verbose {
+		level = slog.LevelInfo
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(stderr, &slog.HandlerOptions{Level: level})))
+
+	socket := opts.socket
+	if socket == "" {
+		var stop func()
+		socket, stop, err = startDaemon(ctx, opts.policy)
+		if err != nil {
+			return ExitError, err
+		}
+		defer stop()
+	} else if opts.policy != "" {
+		return ExitError, errors.New("-policy can't be used with -socket; the running daemon's policy applies")
+	}
+
+	c, err := client.Connect(socket, 50, 100*time.Millisecond)
+	if err != nil {
+		return ExitError, err
+	}
+	defer func() { _ = c.Close() }()
+
+	launched, err := c.LaunchSession(rpc.LaunchSessionRequest{
+		Query:      opts.query,
+		Title:      opts.title,
+		Model:      opts.model,
+		WorkingDir: opts.workingDir,
+		MaxTurns:   opts.maxTurns,
+	})
+	if err != nil {
+		return ExitError, fmt.Errorf("failed to launch session: %w", err)
+	}
+	fmt.Fprintf(stderr, "hld ci: session %s started in %s\n", launched.SessionID, opts.workingDir)
+
+	state, timedOut, err := wait(ctx, c, launched.SessionID, opts.timeout)
+	if err != nil {
+		return ExitError, err
+	}
+	conversation, err := c.GetConversation(launched.SessionID)
+	if err != nil {
+		return ExitError, fmt.Errorf("failed to get conversation: %w", err)
+	}
+
+	report := BuildReport(state, conversation.Events)
+	report.TimedOut = timedOut
+	report.ExitCode = exitCode(report, opts.failOnDeny)
+	if err := writeReports(opts, report, stdout); err != nil {
+		return ExitError, err
+	}
+	fmt.Fprintf(stderr, "hld ci: session %s %s: %d tool calls, %d denied\n",
+		report.SessionID, report.Status, len(report.Actions), report.Denied)
+	return report.ExitCode, nil
+}
+
+// startDaemon runs a daemon in CI mode with its own socket and database in a
+// temporary directory, returning the socket and a function stopping it
+func startDaemon(ctx context.Context, policyPath string) (string, func(), error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return "", nil, err
+	}
+	cfg.CI.Enabled = true
+	if policyPath != "" {
+		cfg.ApprovalPolicyPath = policyPath
+	}
+	dir, err := os.MkdirTemp("", "hld-ci-")
+	if err != nil {
+		return "", nil, err
+	}
+	cfg.SocketPath = filepath.Join(dir, "daemon.sock")
+	cfg.DatabasePath = filepath.Join(dir, "daemon.db")
+	cfg.HTTPDisabled = true
+
+	d, err := daemon.NewWithConfig(cfg)
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return "", nil, err
+	}
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() { done <- d.Run(runCtx) }()
+	stop := func() {
+		cancel()
+		if err := <-done; err != nil {
+			slog.Warn("ci daemon stopped with error", "error", err)
+		}
+		_ = os.RemoveAll(dir)
+	}
+	return cfg.SocketPath, stop, nil
+}
+
+func finished(status string) bool {
+	switch status {
+	case store.SessionStatusCompleted, store.SessionStatusFailed, store.SessionStatusInterrupted:
+		return true
+	}
+	return false
+}
+
+// wait polls until the session finishes, interrupting it after timeout
+func wait(ctx context.Context, c client.Client, sessionID string, timeout time.Duration) (rpc.SessionState, bool, error) {
+	deadline := time.Now().Add(timeout)
+	timedOut := false
+	for {
+		resp, err := c.GetSessionState(sessionID)
+		if err != nil {
+			return rpc.SessionState{}, timedOut, fmt.Errorf("failed to get session state: %w", err)
+		}
+		if finished(resp.Session.Status) {
+			return resp.Session, timedOut, nil
+		}
+		if !timedOut && time.Now().After(deadline) {
+			timedOut = true
+			deadline = time.Now().Add(interruptGrace)
+			if err := c.InterruptSession(sessionID); err != nil {
+				return resp.Session, timedOut, fmt.Errorf("failed to interrupt timed out session: %w", err)
+			}
+		} else if timedOut && time.Now().After(deadline) {
+			return resp.Session, timedOut, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			_ = c.InterruptSession(sessionID)
+			return resp.Session, timedOut, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+func writeReports(opts *options, report *Report, stdout io.Writer) error {
+	if opts.junit != "" {
+		f, err := os.Create(opts.junit)
+		if err != nil {
+			return fmt.Errorf("failed to write JUnit report: %w", err)
+		}
+		err = WriteJUnit(f, report)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write JUnit report: %w", err)
+		}
+	}
+	if opts.json != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		data = append(data, '\n')
+		if opts.json == "-" {
+			_, err = stdout.Write(data)
+		} else {
+			err = os.WriteFile(opts.json, data, 0644)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write JSON report: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package ci
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/humanlayer/humanlayer/hld/rpc"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func conversation() []rpc.ConversationEvent {
+	return []rpc.ConversationEvent{
+		{EventType: store.EventTypeMessage, Role: "user", Content: "Fix the tests"},
+		{EventType: store.EventTypeToolCall, ToolID: "t1", ToolName: "Read", ToolInputJSON: `{"file_path":"/repo/main.go"}`},
+		{EventType: store.EventTypeToolResult, ToolResultForID: "t1", ToolResultContent: "package main"},
+		{EventType: store.EventTypeToolCall, ToolID: "t2", ToolName: "Bash", ToolInputJSON: `{"command":"rm -rf build"}`, ApprovalStatus: "denied"},
+		{EventType: store.EventTypeToolResult, ToolResultForID: "t2", ToolResultContent: "Auto-denied (CI mode: no policy rule allows Bash)"},
+		{EventType: store.EventTypeToolCall, ToolID: "t3", ToolName: "Edit", ToolInputJSON: `{"file_path":"/repo/main_test.go"}`, ApprovalStatus: "approved"},
+		{EventType: store.EventTypeMessage, Role: "assistant", Content: "Fixed the failing assertion."},
+	}
+}
+
+func TestBuildReport(t *testing.T) {
+	report := BuildReport(rpc.SessionState{ID: "s1", Status: store.SessionStatusCompleted, Query: "Fix the tests", DurationMS: 1500}, conversation())
+
+	require.Len(t, report.Actions, 3)
+	assert.Equal(t, ApprovalNone, report.Actions[0].Approval)
+	assert.Equal(t, "package main", report.Actions[0].Result)
+	assert.Equal(t, ApprovalDenied, report.Actions[1].Approval)
+	assert.JSONEq(t, `{"command":"rm -rf build"}`, string(report.Actions[1].Input))
+	assert.Equal(t, ApprovalApproved, report.Actions[2].Approval)
+	assert.Equal(t, 1, report.Denied)
+	assert.Equal(t, "Fixed the failing assertion.", report.Result)
+
+	_, err := json.Marshal(report)
+	require.NoError(t, err)
+}
+
+func TestExitCode(t *testing.T) {
+	completed := &Report{Status: store.SessionStatusCompleted}
+	denied := &Report{Status: store.SessionStatusCompleted, Denied: 1}
+	assert.Equal(t, ExitSuccess, exitCode(completed, true))
+	assert.Equal(t, ExitSuccess, exitCode(denied, false))
+	assert.Equal(t, ExitDenied, exitCode(denied, true))
+	assert.Equal(t, ExitFailed, exitCode(&Report{Status: store.SessionStatusFailed}, false))
+	assert.Equal(t, ExitTimeout, exitCode(&Report{Status: store.SessionStatusInterrupted, TimedOut: true}, false))
+}
+
+func TestWriteJUnit(t *testing.T) {
+	report := BuildReport(rpc.SessionState{ID: "s1", Status: store.SessionStatusCompleted, Query: "Fix the tests", DurationMS: 1500}, conversation())
+	var buf bytes.Buffer
+	require.NoError(t, WriteJUnit(&buf, report))
+
+	var suites junitSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &suites))
+	require.Len(t, suites.Suites, 1)
+	suite := suites.Suites[0]
+	assert.Equal(t, 4, suite.Tests)
+	assert.Equal(t, 1, suite.Failures)
+	assert.Equal(t, "1.500", suite.Time)
+	assert.Equal(t, "Fix the tests", suite.Cases[0].Name)
+	assert.Nil(t, suite.Cases[0].Failure)
+	assert.Equal(t, "2 Bash rm -rf build", suite.Cases[2].Name)
+	require.NotNil(t, suite.Cases[2].Failure)
+	assert.Contains(t, suite.Cases[2].Failure.Text, "no policy rule allows Bash")
+}
+
+func TestParseFlags(t *testing.T) {
+	opts, err := parseFlags([]string{"-timeout", "5m", "-fail-on-deny", "fix", "the", "build"}, &bytes.Buffer{})
+	require.NoError(t, err)
+	assert.Equal(t, "fix the build", opts.query)
+	assert.True(t, opts.failOnDeny)
+	assert.NotEmpty(t, opts.workingDir)
+
+	_, err = parseFlags([]string{"-timeout", "5m"}, &bytes.Buffer{})
+	assert.Error(t, err, "a query is required")
+}
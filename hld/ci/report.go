@@ -0,0 +1,223 @@
+package ci
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/rpc"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// maxResultLength caps tool results and the final message in reports
+const maxResultLength = 2000
+
+// Approval outcomes of an action; ApprovalNone means the tool ran without
+// asking, as read-only tools do
+const (
+	ApprovalApproved = "approved"
+	ApprovalDenied   = "denied"
+	ApprovalPending  = "pending"
+	ApprovalNone     = "none"
+)
+
+// Action is one tool call the agent made
+type Action struct {
+	Tool     string          `json:"tool"`
+	Input    json.RawMessage `json:"input,omitempty"`
+	Approval string          `json:"approval"`
+	Result   string          `json:"result,omitempty"`
+	At       string          `json:"at"`
+}
+
+// Report describes a CI session and every action it took
+type Report struct {
+	SessionID  string   `json:"session_id"`
+	Status     string   `json:"status"`
+	Query      string   `json:"query"`
+	Title      string   `json:"title,omitempty"`
+	WorkingDir string   `json:"working_dir"`
+	Error      string   `json:"error,omitempty"`
+	TimedOut   bool     `json:"timed_out,omitempty"`
+	Result     string   `json:"result,omitempty"` // The agent's final message
+	DurationMs int      `json:"duration_ms"`
+	CostUSD    float64  `json:"cost_usd"`
+	Actions    []Action `json:"actions"`
+	Denied     int      `json:"denied"`
+	ExitCode   int      `json:"exit_code"`
+}
+
+func truncate(text string) string {
+	if len(text) <= maxResultLength {
+		return text
+	}
+	return text[:maxResultLength] + "\n[truncated]"
+}
+
+// BuildReport assembles a report from the session's final state and its
+// conversation
+func BuildReport(state rpc.SessionState, events []rpc.ConversationEvent) *Report {
+	report := &Report{
+		SessionID:  state.ID,
+		Status:     state.Status,
+		Query:      state.Query,
+		Title:      state.Title,
+		WorkingDir: state.WorkingDir,
+		Error:      state.ErrorMessage,
+		DurationMs: state.DurationMS,
+		CostUSD:    state.CostUSD,
+		Actions:    []Action{},
+	}
+
+	results := make(map[string]string)
+	for _, event := range events {
+		if event.EventType == store.EventTypeToolResult {
+			results[event.ToolResultForID] = event.ToolResultContent
+		}
+	}
+	for _, event := range events {
+		switch event.EventType {
+		case store.EventTypeToolCall:
+			action := Action{
+				Tool:     event.ToolName,
+				Approval: ApprovalNone,
+				Result:   truncate(results[event.ToolID]),
+				At:       event.CreatedAt,
+			}
+			if json.Valid([]byte(event.ToolInputJSON)) {
+				action.Input = json.RawMessage(event.ToolInputJSON)
+			}
+			switch event.ApprovalStatus {
+			case store.ApprovalStatusApproved:
+				action.Approval = ApprovalApproved
+			case store.ApprovalStatusDenied:
+				action.Approval = ApprovalDenied
+				report.Denied++
+			case store.ApprovalStatusPending:
+				action.Approval = ApprovalPending
+			}
+			report.Actions = append(report.Actions, action)
+		case store.EventTypeMessage:
+			if event.Role == "assistant" && event.Content != "" {
+				report.Result = truncate(event.Content)
+			}
+		}
+	}
+	return report
+}
+
+// exitCode maps a finished report to the command's exit status
+func exitCode(report *Report, failOnDeny bool) int {
+	switch {
+	case report.TimedOut:
+		return ExitTimeout
+	case report.Status != store.SessionStatusCompleted:
+		return ExitFailed
+	case failOnDeny && report.Denied > 0:
+		return ExitDenied
+	default:
+		return ExitSuccess
+	}
+}
+
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name      string      `xml:"name,attr"`
+	Tests     int         `xml:"tests,attr"`
+	Failures  int         `xml:"failures,attr"`
+	Time      string      `xml:"time,attr"`
+	Timestamp string      `xml:"timestamp,attr"`
+	Cases     []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report as a JUnit XML suite: one case for the session
+// outcome and one per action, with denied actions as failures
+func WriteJUnit(w io.Writer, report *Report) error {
+	name := report.Title
+	if name == "" {
+		name = report.Query
+	}
+	if len(name) > 120 {
+		name = name[:120] + "..."
+	}
+
+	session := junitCase{ClassName: "hld.session", Name: name, SystemOut: report.Result}
+	if report.Status != store.SessionStatusCompleted || report.TimedOut {
+		message := "session " + report.Status
+		if report.TimedOut {
+			message = "session timed out"
+		}
+		session.Failure = &junitFailure{Message: message, Text: report.Error}
+	}
+	suite := junitSuite{
+		Name:      "hld ci " + report.SessionID,
+		Time:      fmt.Sprintf("%.3f", float64(report.DurationMs)/1000),
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Cases:     []junitCase{session},
+	}
+	for i, action := range report.Actions {
+		c := junitCase{
+			ClassName: "hld.tool." + action.Tool,
+			Name:      fmt.Sprintf("%d %s %s", i+1, action.Tool, summarizeInput(action.Input)),
+			SystemOut: action.Result,
+		}
+		if action.Approval == ApprovalDenied {
+			c.Failure = &junitFailure{Message: "denied by approval policy", Text: action.Result}
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+	suite.Tests = len(suite.Cases)
+	for _, c := range suite.Cases {
+		if c.Failure != nil {
+			suite.Failures++
+		}
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(junitSuites{Suites: []junitSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// summarizeInput picks the most telling field of a tool input for a test name
+func summarizeInput(input json.RawMessage) string {
+	var fields map[string]any
+	if json.Unmarshal(input, &fields) != nil {
+		return ""
+	}
+	for _, key := range []string{"command", "file_path", "path", "pattern", "url"} {
+		if value, ok := fields[key].(string); ok {
+			value = strings.Join(strings.Fields(value), " ")
+			if len(value) > 80 {
+				value = value[:80] + "..."
+			}
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,132 @@
+package cloud
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const requestTimeout = 30 * time.Second
+
+// ErrAlreadyResponded is returned for responding to a function call that
+// was already answered in the cloud
+var ErrAlreadyResponded = errors.New("function call already has a response")
+
+// FunctionCall is an approval request in the HumanLayer API
+type FunctionCall struct {
+	RunID  string              `json:"run_id"`
+	CallID string              `json:"call_id"`
+	Spec   FunctionCallSpec    `json:"spec"`
+	Status *FunctionCallStatus `json:"status,omitempty"`
+}
+
+// FunctionCallSpec is the call to approve and who is asked
+type FunctionCallSpec struct {
+	Fn      string          `json:"fn"`
+	Kwargs  json.RawMessage `json:"kwargs"`
+	Channel *ContactChannel `json:"channel,omitempty"`
+}
+
+// FunctionCallStatus is the answer to a function call, if it has one
+type FunctionCallStatus struct {
+	RequestedAt *time.Time `json:"requested_at,omitempty"`
+	RespondedAt *time.Time `json:"responded_at,omitempty"`
+	Approved    *bool      `json:"approved,omitempty"`
+	Comment     string     `json:"comment,omitempty"`
+}
+
+// Responded reports whether the call was approved or denied
+func (s *FunctionCallStatus) Responded() bool {
+	return s != nil && s.Approved != nil
+}
+
+// ContactChannel routes a function call to a person
+type ContactChannel struct {
+	Slack *SlackChannel `json:"slack,omitempty"`
+	Email *EmailChannel `json:"email,omitempty"`
+}
+
+// SlackChannel is a Slack channel or user
+type SlackChannel struct {
+	ChannelOrUserID string `json:"channel_or_user_id"`
+}
+
+// EmailChannel is an email address
+type EmailChannel struct {
+	Address string `json:"address"`
+}
+
+// Client calls the HumanLayer API
+type Client struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+}
+
+// NewClient creates a client for the API at baseURL, such as
+// https://api.humanlayer.dev/humanlayer/v1
+func NewClient(baseURL, apiKey string) *Client {
+	return &Client{baseURL: strings.TrimRight(baseURL, "/"), apiKey: apiKey, http: &http.Client{Timeout: requestTimeout}}
+}
+
+// CreateFunctionCall asks the call's contact channel for approval
+func (c *Client) CreateFunctionCall(ctx context.Context, call FunctionCall) error {
+	return c.do(ctx, http.MethodPost, "/function_calls", call, nil)
+}
+
+// GetFunctionCall fetches a function call with its status
+func (c *Client) GetFunctionCall(ctx context.Context, callID string) (*FunctionCall, error) {
+	var call FunctionCall
+	if err := c.do(ctx, http.MethodGet, "/function_calls/"+url.PathEscape(callID), nil, &call); err != nil {
+		return nil, err
+	}
+	return &call, nil
+}
+
+// Respond answers a function call, returning ErrAlreadyResponded if it was
+// answered in the cloud first
+func (c *Client) Respond(ctx context.Context, callID string, status FunctionCallStatus) error {
+	return c.do(ctx, http.MethodPost, "/agent/function_calls/"+url.PathEscape(callID)+"/respond", status, nil)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusConflict {
+		return ErrAlreadyResponded
+	}
+	if resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(detail)))
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+// Package cloud mirrors approvals to HumanLayer cloud, so hosted contact
+// channels such as Slack and email can decide them. Approvals can still be
+// decided locally; whichever decision comes first is the one the agent gets.
+package cloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/approval"
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// remoteDenial is the comment for denials made in the cloud without one,
+// since denials need a comment
+const remoteDenial = "Denied in HumanLayer cloud"
+
+// API is the part of the HumanLayer API the relay uses
+type API interface {
+	CreateFunctionCall(ctx context.Context, call FunctionCall) error
+	GetFunctionCall(ctx context.Context, callID string) (*FunctionCall, error)
+	Respond(ctx context.Context, callID string, status FunctionCallStatus) error
+}
+
+// Relay mirrors pending approvals to the cloud and applies decisions made
+// there. Decisions made locally are sent to the cloud so its channels stop
+// asking. When an approval is decided both ways, the local decision stands,
+// and a disagreement is recorded as a conflict.
+type Relay struct {
+	api       API
+	store     store.ConversationStore
+	mirrors   store.CloudMirrorStore
+	approvals approval.Manager
+	eventBus  bus.EventBus
+	channel   *ContactChannel
+	interval  time.Duration
+}
+
+// New creates a relay checking the cloud for decisions every interval
+func New(api API, s store.ConversationStore, mirrors store.CloudMirrorStore, approvals approval.Manager, eventBus bus.EventBus, channel *ContactChannel, interval time.Duration) *Relay {
+	return &Relay{api: api, store: s, mirrors: mirrors, approvals: approvals, eventBus: eventBus, channel: channel, interval: interval}
+}
+
+// FromConfig creates the configured relay, or returns nil if it is off
+func FromConfig(cfg *config.Config, s store.ConversationStore, mirrors store.CloudMirrorStore, approvals approval.Manager, eventBus bus.EventBus) *Relay {
+	rc := cfg.CloudRelay
+	if !rc.Enabled || cfg.APIKey == "" {
+		return nil
+	}
+	var channel *ContactChannel
+	if rc.SlackChannel != "" || rc.Email != "" {
+		channel = &ContactChannel{}
+		if rc.SlackChannel != "" {
+			channel.Slack = &SlackChannel{ChannelOrUserID: rc.SlackChannel}
+		}
+		if rc.Email != "" {
+			channel.Email = &EmailChannel{Address: rc.Email}
+		}
+	}
+	interval := time.Duration(rc.PollSeconds) * time.Second
+	if rc.PollSeconds == 0 {
+		interval = config.DefaultCloudRelayPollSeconds * time.Second
+	}
+	return New(NewClient(cfg.APIBaseURL, cfg.APIKey), s, mirrors, approvals, eventBus, channel, interval)
+}
+
+// Run mirrors new approvals and syncs decisions until ctx is done
+func (r *Relay) Run(ctx context.Context) {
+	sub := r.eventBus.Subscribe(ctx, bus.EventFilter{
+		Types: []bus.EventType{bus.EventNewApproval, bus.EventApprovalResolved},
+	})
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	// Catch up on approvals decided while stopped
+	r.poll(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.poll(ctx)
+		case event, ok := <-sub.Channel:
+			if !ok {
+				return
+			}
+			approvalID, _ := event.Data["approval_id"].(string)
+			var err error
+			if event.Type == bus.EventNewApproval {
+				err = r.Mirror(ctx, approvalID)
+			} else {
+				err = r.syncApproval(ctx, approvalID)
+			}
+			if err != nil {
+				slog.Warn("cloud relay failed", "approval_id", approvalID, "error", err)
+			}
+		}
+	}
+}
+
+func (r *Relay) poll(ctx context.Context) {
+	if err := r.Sync(ctx); err != nil {
+		slog.Warn("failed to sync approvals with HumanLayer cloud", "error", err)
+	}
+}
+
+// Mirror sends a pending approval to the cloud
+func (r *Relay) Mirror(ctx context.Context, approvalID string) error {
+	pending, err := r.store.GetApproval(ctx, approvalID)
+	if err != nil {
+		return err
+	}
+	if pending.Status != store.ApprovalStatusLocalPending {
+		return nil
+	}
+	call := FunctionCall{
+		RunID:  pending.RunID,
+		CallID: pending.ID,
+		Spec:   FunctionCallSpec{Fn: pending.ToolName, Kwargs: pending.ToolInput, Channel: r.channel},
+	}
+	if err := r.api.CreateFunctionCall(ctx, call); err != nil {
+		return fmt.Errorf("failed to mirror approval: %w", err)
+	}
+	slog.Info("mirrored approval to HumanLayer cloud", "approval_id", pending.ID, "tool_name", pending.ToolName)
+	return r.mirrors.CreateCloudMirror(ctx, &store.CloudMirror{
+		ApprovalID: pending.ID,
+		CallID:     call.CallID,
+		CreatedAt:  time.Now(),
+	})
+}
+
+// Sync applies decisions made in the cloud and sends local decisions there,
+// for every mirror not yet settled
+func (r *Relay) Sync(ctx context.Context) error {
+	mirrors, err := r.mirrors.ListOpenCloudMirrors(ctx)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, mirror := range mirrors {
+		if err := r.sync(ctx, mirror); err != nil {
+			errs = append(errs, fmt.Errorf("approval %s: %w", mirror.ApprovalID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// syncApproval syncs an approval's mirror, if it has an open one
+func (r *Relay) syncApproval(ctx context.Context, approvalID string) error {
+	mirror, err := r.mirrors.GetCloudMirror(ctx, approvalID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil
+	}
+	if err != nil || mirror.State != store.CloudMirrorOpen {
+		return err
+	}
+	return r.sync(ctx, mirror)
+}
+
+func (r *Relay) sync(ctx context.Context, mirror *store.CloudMirror) error {
+	local, err := r.store.GetApproval(ctx, mirror.ApprovalID)
+	if err != nil {
+		return err
+	}
+	if local.Status == store.ApprovalStatusLocalPending {
+		call, err := r.api.GetFunctionCall(ctx, mirror.CallID)
+		if err != nil {
+			return err
+		}
+		if !call.Status.Responded() {
+			return nil
+		}
+		err = r.apply(ctx, local.ID, call.Status)
+		if err == nil {
+			_, err = r.mirrors.SettleCloudMirror(ctx, mirror.ApprovalID, store.CloudMirrorSettled, call.Status.Approved, call.Status.Comment)
+			return err
+		}
+		if !errors.Is(err, store.ErrAlreadyDecided) {
+			return err
+		}
+		// Decided locally in the meantime
+		if local, err = r.store.GetApproval(ctx, mirror.ApprovalID); err != nil {
+			return err
+		}
+	}
+
+	approved := local.Status == store.ApprovalStatusLocalApproved
+	err = r.api.Respond(ctx, mirror.CallID, FunctionCallStatus{Approved: &approved, Comment: local.Comment})
+	if err == nil {
+		_, err = r.mirrors.SettleCloudMirror(ctx, mirror.ApprovalID, store.CloudMirrorSettled, nil, "")
+		return err
+	}
+	if !errors.Is(err, ErrAlreadyResponded) {
+		return err
+	}
+	call, err := r.api.GetFunctionCall(ctx, mirror.CallID)
+	if err != nil {
+		return err
+	}
+	return r.settleBoth(ctx, local, mirror, call.Status)
+}
+
+// apply decides a local approval as it was decided in the cloud
+func (r *Relay) apply(ctx context.Context, approvalID string, status *FunctionCallStatus) error {
+	if *status.Approved {
+		return r.approvals.ApproveToolCall(ctx, approvalID, status.Comment, nil)
+	}
+	comment := status.Comment
+	if comment == "" {
+		comment = remoteDenial
+	}
+	return r.approvals.DenyToolCall(ctx, approvalID, comment, nil)
+}
+
+// settleBoth settles a mirror decided both locally and in the cloud. The
+// local decision stands; a different cloud decision is a conflict.
+func (r *Relay) settleBoth(ctx context.Context, local *store.Approval, mirror *store.CloudMirror, status *FunctionCallStatus) error {
+	approved := local.Status == store.ApprovalStatusLocalApproved
+	state := store.CloudMirrorSettled
+	if status.Responded() && *status.Approved != approved {
+		state = store.CloudMirrorConflict
+	}
+	var remoteApproved *bool
+	var remoteComment string
+	if status != nil {
+		remoteApproved, remoteComment = status.Approved, status.Comment
+	}
+	settled, err := r.mirrors.SettleCloudMirror(ctx, mirror.ApprovalID, state, remoteApproved, remoteComment)
+	if err != nil || !settled || state != store.CloudMirrorConflict {
+		return err
+	}
+	slog.Warn("approval decided differently in HumanLayer cloud; the local decision stands",
+		"approval_id", local.ID,
+		"local_approved", approved,
+		"remote_approved", *status.Approved,
+		"remote_comment", status.Comment)
+	if r.eventBus != nil {
+		r.eventBus.Publish(bus.Event{
+			Type: bus.EventCloudApprovalConflict,
+			Data: map[string]interface{}{
+				"approval_id":     local.ID,
+				"session_id":      local.SessionID,
+				"local_approved":  approved,
+				"remote_approved": *status.Approved,
+				"remote_comment":  status.Comment,
+			},
+		})
+	}
+	return nil
+}
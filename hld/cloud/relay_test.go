@@ -0,0 +1,189 @@
+package cloud
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/approval"
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAPI keeps function calls in memory; a call answered in the cloud
+// rejects later responses like the real API does
+type fakeAPI struct {
+	mu    sync.Mutex
+	calls map[string]*FunctionCall
+}
+
+func (f *fakeAPI) CreateFunctionCall(ctx context.Context, call FunctionCall) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls[call.CallID] = &call
+	return nil
+}
+
+func (f *fakeAPI) GetFunctionCall(ctx context.Context, callID string) (*FunctionCall, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	call := *f.calls[callID]
+	return &call, nil
+}
+
+func (f *fakeAPI) Respond(ctx context.Context, callID string, status FunctionCallStatus) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.calls[callID].Status.Responded() {
+		return ErrAlreadyResponded
+	}
+	f.calls[callID].Status = &status
+	return nil
+}
+
+func (f *fakeAPI) answer(callID string, approved bool, comment string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls[callID].Status = &FunctionCallStatus{Approved: &approved, Comment: comment}
+}
+
+func setupRelay(t *testing.T, ids ...string) (*Relay, *fakeAPI, store.Store, bus.EventBus) {
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	for _, id := range ids {
+		require.NoError(t, s.CreateApproval(ctx, &store.Approval{
+			ID: id, RunID: "run-1", SessionID: "sess-1", ToolName: "Bash",
+			ToolInput: json.RawMessage(`{"command":"make deploy"}`), Status: store.ApprovalStatusLocalPending, CreatedAt: time.Now(),
+		}))
+	}
+	eventBus := bus.NewEventBus()
+	api := &fakeAPI{calls: map[string]*FunctionCall{}}
+	channel := &ContactChannel{Slack: &SlackChannel{ChannelOrUserID: "C123"}}
+	relay := New(api, s, s, approval.NewManager(s, eventBus), eventBus, channel, time.Second)
+	for _, id := range ids {
+		require.NoError(t, relay.Mirror(ctx, id))
+	}
+	return relay, api, s, eventBus
+}
+
+func TestRelayAppliesCloudDecisions(t *testing.T) {
+	ctx := context.Background()
+	relay, api, s, _ := setupRelay(t, "appr-1", "appr-2")
+	assert.Equal(t, "Bash", api.calls["appr-1"].Spec.Fn)
+	assert.Equal(t, "C123", api.calls["appr-1"].Spec.Channel.Slack.ChannelOrUserID)
+
+	// Nothing happens until someone answers
+	require.NoError(t, relay.Sync(ctx))
+	pending, err := s.GetApproval(ctx, "appr-1")
+	require.NoError(t, err)
+	assert.Equal(t, store.ApprovalStatusLocalPending, pending.Status)
+
+	api.answer("appr-1", true, "ship it")
+	api.answer("appr-2", false, "")
+	require.NoError(t, relay.Sync(ctx))
+
+	approved, err := s.GetApproval(ctx, "appr-1")
+	require.NoError(t, err)
+	assert.Equal(t, store.ApprovalStatusLocalApproved, approved.Status)
+	assert.Equal(t, "ship it", approved.Comment)
+	denied, err := s.GetApproval(ctx, "appr-2")
+	require.NoError(t, err)
+	assert.Equal(t, store.ApprovalStatusLocalDenied, denied.Status)
+	assert.Equal(t, remoteDenial, denied.Comment)
+
+	mirror, err := s.GetCloudMirror(ctx, "appr-1")
+	require.NoError(t, err)
+	assert.Equal(t, store.CloudMirrorSettled, mirror.State)
+	open, err := s.ListOpenCloudMirrors(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, open)
+}
+
+func TestRelaySendsLocalDecisions(t *testing.T) {
+	ctx := context.Background()
+	relay, api, s, _ := setupRelay(t, "appr-1")
+	require.NoError(t, s.UpdateApprovalResponse(ctx, "appr-1", store.ApprovalStatusLocalDenied, "use staging"))
+
+	require.NoError(t, relay.syncApproval(ctx, "appr-1"))
+	status := api.calls["appr-1"].Status
+	require.True(t, status.Responded())
+	assert.False(t, *status.Approved)
+	assert.Equal(t, "use staging", status.Comment)
+	mirror, err := s.GetCloudMirror(ctx, "appr-1")
+	require.NoError(t, err)
+	assert.Equal(t, store.CloudMirrorSettled, mirror.State)
+}
+
+func TestRelayConflict(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	relay, api, s, eventBus := setupRelay(t, "appr-1", "appr-2")
+	sub := eventBus.Subscribe(ctx, bus.EventFilter{Types: []bus.EventType{bus.EventCloudApprovalConflict}})
+
+	// Both decided before the relay caught up
+	require.NoError(t, s.UpdateApprovalResponse(ctx, "appr-1", store.ApprovalStatusLocalApproved, ""))
+	api.answer("appr-1", false, "not during the freeze")
+	require.NoError(t, s.UpdateApprovalResponse(ctx, "appr-2", store.ApprovalStatusLocalApproved, ""))
+	api.answer("appr-2", true, "")
+	require.NoError(t, relay.Sync(ctx))
+
+	local, err := s.GetApproval(ctx, "appr-1")
+	require.NoError(t, err)
+	assert.Equal(t, store.ApprovalStatusLocalApproved, local.Status, "the local decision stands")
+	mirror, err := s.GetCloudMirror(ctx, "appr-1")
+	require.NoError(t, err)
+	assert.Equal(t, store.CloudMirrorConflict, mirror.State)
+	require.NotNil(t, mirror.RemoteApproved)
+	assert.False(t, *mirror.RemoteApproved)
+	assert.Equal(t, "not during the freeze", mirror.RemoteComment)
+
+	agreed, err := s.GetCloudMirror(ctx, "appr-2")
+	require.NoError(t, err)
+	assert.Equal(t, store.CloudMirrorSettled, agreed.State)
+
+	event := <-sub.Channel
+	assert.Equal(t, "appr-1", event.Data["approval_id"])
+	assert.Equal(t, true, event.Data["local_approved"])
+	assert.Equal(t, false, event.Data["remote_approved"])
+}
+
+func TestClient(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+r.Header.Get("Authorization"))
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/humanlayer/v1/function_calls/appr-1":
+			_, _ = w.Write([]byte(`{"run_id":"run-1","call_id":"appr-1","spec":{"fn":"Bash","kwargs":{}},"status":{"approved":true,"comment":"ok"}}`))
+		case "/humanlayer/v1/agent/function_calls/appr-1/respond":
+			w.WriteHeader(http.StatusConflict)
+		default:
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client := NewClient(server.URL+"/humanlayer/v1/", "hl-key")
+	require.NoError(t, client.CreateFunctionCall(ctx, FunctionCall{RunID: "run-1", CallID: "appr-1", Spec: FunctionCallSpec{Fn: "Bash", Kwargs: json.RawMessage(`{}`)}}))
+	call, err := client.GetFunctionCall(ctx, "appr-1")
+	require.NoError(t, err)
+	assert.True(t, call.Status.Responded())
+	assert.Equal(t, "ok", call.Status.Comment)
+	approved := false
+	assert.ErrorIs(t, client.Respond(ctx, "appr-1", FunctionCallStatus{Approved: &approved}), ErrAlreadyResponded)
+
+	assert.Equal(t, []string{
+		"POST /humanlayer/v1/function_calls Bearer hl-key",
+		"GET /humanlayer/v1/function_calls/appr-1 Bearer hl-key",
+		"POST /humanlayer/v1/agent/function_calls/appr-1/respond Bearer hl-key",
+	}, requests)
+}
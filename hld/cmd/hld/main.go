@@ -3,17 +3,48 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/humanlayer/humanlayer/hld/ci"
+	"github.com/humanlayer/humanlayer/hld/config"
 	"github.com/humanlayer/humanlayer/hld/daemon"
+	"github.com/humanlayer/humanlayer/hld/discovery"
+	"github.com/humanlayer/humanlayer/hld/instance"
 )
 
 func main() {
+	// hld ci run launches one session with headless approvals and exits
+	if len(os.Args) > 1 && os.Args[1] == "ci" {
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		code := ci.Main(ctx, os.Args[2:], os.Stdout, os.Stderr)
+		stop()
+		os.Exit(code)
+	}
+
+	// hld discover lists the daemons advertised over mDNS
+	if len(os.Args) > 1 && os.Args[1] == "discover" {
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		code := discovery.Main(ctx, os.Args[2:], os.Stdout, os.Stderr)
+		stop()
+		os.Exit(code)
+	}
+
+	// hld status reports the daemon running against the configured database
+	if len(os.Args) > 1 && os.Args[1] == "status" {
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		code := instance.Main(ctx, os.Args[2:], os.Stdout, os.Stderr)
+		stop()
+		os.Exit(code)
+	}
+
 	// Parse command line flags
 	debug := flag.Bool("debug", false, "Enable debug logging")
+	takeover := flag.Bool("takeover", false, "Stop the daemon already using this database, then start")
 	flag.Parse()
 
 	// Set up structured logging
@@ -37,6 +68,13 @@ func main() {
 		slog.Debug("hld daemon starting with no PATH environment variable")
 	}
 
+	if *takeover {
+		if err := takeOver(); err != nil {
+			slog.Error("failed to take over from running daemon", "error", err)
+			os.Exit(1)
+		}
+	}
+
 	// Create daemon instance
 	d, err := daemon.New()
 	if err != nil {
@@ -59,3 +97,15 @@ func main() {
 	slog.Info("shutting down gracefully, press Ctrl+C again to force")
 	slog.Info("daemon shutdown complete")
 }
+
+// takeOver stops the daemon holding the configured database's lock, giving
+// it time to stop its sessions
+func takeOver() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return instance.Takeover(ctx, instance.Path(cfg))
+}
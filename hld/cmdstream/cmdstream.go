@@ -0,0 +1,198 @@
+// Package cmdstream publishes what commands the daemon runs write, a line at
+// a time, as command_output events, so clients can show hooks, analyzers
+// and test commands live instead of waiting for their final output.
+package cmdstream
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+)
+
+// maxLine bounds a published line; longer lines are split
+const maxLine = 4096
+
+// Streams a line can come from
+const (
+	Stdout = "stdout"
+	Stderr = "stderr"
+)
+
+// Operation groups the commands run for one request, such as a commit
+// running formatters, analyzers and git hooks. A nil Operation streams
+// nothing.
+type Operation struct {
+	ID        string
+	SessionID string
+	eventBus  bus.EventBus
+}
+
+// New creates an operation publishing on eventBus, or nil if eventBus is
+func New(eventBus bus.EventBus, id, sessionID string) *Operation {
+	if eventBus == nil {
+		return nil
+	}
+	return &Operation{ID: id, SessionID: sessionID, eventBus: eventBus}
+}
+
+// Command starts streaming a command of the operation, named for clients
+func (o *Operation) Command(name string) *Command {
+	if o == nil {
+		return nil
+	}
+	c := &Command{op: o, name: name}
+	c.stdout = &lineWriter{command: c, stream: Stdout}
+	c.stderr = &lineWriter{command: c, stream: Stderr}
+	return c
+}
+
+// Command streams one command's output
+type Command struct {
+	op     *Operation
+	name   string
+	stdout *lineWriter
+	stderr *lineWriter
+
+	mu  sync.Mutex
+	seq int // orders lines across both streams
+}
+
+// Stdout is where the command's standard output is written
+func (c *Command) Stdout() io.Writer {
+	if c == nil {
+		return io.Discard
+	}
+	return c.stdout
+}
+
+// Stderr is where the command's standard error is written
+func (c *Command) Stderr() io.Writer {
+	if c == nil {
+		return io.Discard
+	}
+	return c.stderr
+}
+
+// Attach sends cmd's stdout and stderr to output, interleaved as they're
+// written, and streams them
+func (c *Command) Attach(cmd *exec.Cmd, output io.Writer) {
+	if c == nil {
+		cmd.Stdout, cmd.Stderr = output, output
+		return
+	}
+	// The two streams are copied concurrently once they differ
+	shared := &lockedWriter{w: output}
+	cmd.Stdout = io.MultiWriter(shared, c.stdout)
+	cmd.Stderr = io.MultiWriter(shared, c.stderr)
+}
+
+// Finish publishes any unterminated last lines and that the command exited
+// with err
+func (c *Command) Finish(err error) {
+	if c == nil {
+		return
+	}
+	c.stdout.flush()
+	c.stderr.flush()
+	data := c.data()
+	data["done"] = true
+	data["exit_code"] = 0
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			data["exit_code"] = exitErr.ExitCode()
+		} else {
+			data["exit_code"] = -1
+		}
+		data["error"] = err.Error()
+	}
+	c.publish(data)
+}
+
+func (c *Command) data() map[string]interface{} {
+	data := map[string]interface{}{
+		"operation_id": c.op.ID,
+		"command":      c.name,
+	}
+	if c.op.SessionID != "" {
+		data["session_id"] = c.op.SessionID
+	}
+	return data
+}
+
+func (c *Command) publishLine(stream, line string) {
+	data := c.data()
+	data["stream"] = stream
+	data["line"] = line
+	c.publish(data)
+}
+
+func (c *Command) publish(data map[string]interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seq++
+	data["seq"] = c.seq
+	c.op.eventBus.Publish(bus.Event{Type: bus.EventCommandOutput, Timestamp: time.Now(), Data: data})
+}
+
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
+
+// lineWriter publishes each complete line written to it
+type lineWriter struct {
+	command *Command
+	stream  string
+	mu      sync.Mutex
+	partial []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		w.emit(w.partial[:i])
+		w.partial = w.partial[i+1:]
+	}
+	for len(w.partial) >= maxLine {
+		w.command.publishLine(w.stream, string(w.partial[:maxLine]))
+		w.partial = w.partial[maxLine:]
+	}
+	return len(p), nil
+}
+
+func (w *lineWriter) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.partial) > 0 {
+		w.emit(w.partial)
+		w.partial = nil
+	}
+}
+
+// emit publishes a line, split if it's over maxLine
+func (w *lineWriter) emit(line []byte) {
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	for len(line) > maxLine {
+		w.command.publishLine(w.stream, string(line[:maxLine]))
+		line = line[maxLine:]
+	}
+	w.command.publishLine(w.stream, string(line))
+}
@@ -0,0 +1,85 @@
+package cmdstream
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func drain(events *bus.Subscriber) []map[string]interface{} {
+	var data []map[string]interface{}
+	for len(events.Channel) > 0 {
+		data = append(data, (<-events.Channel).Data)
+	}
+	return data
+}
+
+func TestCommandStreamsLines(t *testing.T) {
+	eventBus := bus.NewEventBus()
+	events := eventBus.Subscribe(context.Background(), bus.EventFilter{SessionID: "sess-1"})
+	stream := New(eventBus, "op-1", "sess-1").Command("pre-commit")
+
+	_, _ = stream.Stdout().Write([]byte("check yaml...Passed\r\ntrailing "))
+	_, _ = stream.Stderr().Write([]byte("warning: slow\n"))
+	_, _ = stream.Stdout().Write([]byte("whitespace...Failed\nno newline"))
+	_, _ = stream.Stdout().Write([]byte(strings.Repeat("x", maxLine+10) + "\n"))
+	stream.Finish(nil)
+
+	data := drain(events)
+	require.Len(t, data, 6)
+	lines := []string{}
+	for i, d := range data[:5] {
+		assert.Equal(t, "op-1", d["operation_id"])
+		assert.Equal(t, "pre-commit", d["command"])
+		assert.Equal(t, i+1, d["seq"])
+		lines = append(lines, d["stream"].(string)+": "+d["line"].(string)[:min(len(d["line"].(string)), 30)])
+	}
+	assert.Equal(t, []string{
+		"stdout: check yaml...Passed",
+		"stderr: warning: slow",
+		"stdout: trailing whitespace...Failed",
+		"stdout: " + "no newline" + strings.Repeat("x", 20),
+		"stdout: " + strings.Repeat("x", 20),
+	}, lines, "long lines are split")
+	assert.Len(t, data[3]["line"], maxLine)
+	assert.Equal(t, true, data[5]["done"])
+	assert.Equal(t, 0, data[5]["exit_code"])
+}
+
+func TestAttach(t *testing.T) {
+	eventBus := bus.NewEventBus()
+	events := eventBus.Subscribe(context.Background(), bus.EventFilter{})
+	stream := New(eventBus, "op-1", "").Command("test")
+
+	cmd := exec.Command("sh", "-c", "echo out; echo err >&2; exit 3")
+	var output bytes.Buffer
+	stream.Attach(cmd, &output)
+	err := cmd.Run()
+	stream.Finish(err)
+
+	assert.ElementsMatch(t, []string{"out", "err"}, strings.Fields(output.String()))
+	data := drain(events)
+	require.Len(t, data, 3)
+	assert.NotContains(t, data[0], "session_id")
+	assert.Equal(t, 3, data[2]["exit_code"])
+	assert.Contains(t, data[2]["error"], "exit status 3")
+}
+
+func TestNilOperation(t *testing.T) {
+	stream := New(nil, "op-1", "sess-1").Command("test")
+	assert.Nil(t, stream)
+
+	cmd := exec.Command("sh", "-c", "echo out; echo err >&2")
+	var output bytes.Buffer
+	stream.Attach(cmd, &output)
+	err := cmd.Run()
+	stream.Finish(err)
+	require.NoError(t, err)
+	assert.Equal(t, "out\nerr\n", output.String())
+}
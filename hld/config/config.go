@@ -2,10 +2,16 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/humanlayer/humanlayer/hld/i18n"
 	"github.com/spf13/viper"
 )
 
@@ -41,8 +47,1036 @@ type Config struct {
 	HTTPPort int    `mapstructure:"http_port"`
 	HTTPHost string `mapstructure:"http_host"`
 
+	// HTTPDisabled skips the daemon's own HTTP listener, for embedders that
+	// mount the API routes on their own server
+	HTTPDisabled bool `mapstructure:"http_disabled"`
+
 	// Claude configuration
 	ClaudePath string `mapstructure:"claude_path"`
+
+	// Downstream MCP servers whose tools are re-exposed through the daemon's MCP endpoint
+	MCPDownstreamServers map[string]MCPDownstreamServer `mapstructure:"mcp_downstream_servers"`
+
+	// Path to a CEL approval policy file evaluated before session auto-accept settings
+	ApprovalPolicyPath string `mapstructure:"approval_policy_path"`
+	// Path to a policy evaluated in shadow mode: decisions are recorded for comparison but never applied
+	ShadowApprovalPolicyPath string `mapstructure:"shadow_approval_policy_path"`
+
+	// Resource limits applied to new sessions that don't set their own
+	DefaultSessionBudget SessionBudget `mapstructure:"default_session_budget"`
+	// Sessions allowed to run at once; further launches wait in a priority queue. 0 is unlimited
+	MaxConcurrentSessions int `mapstructure:"max_concurrent_sessions"`
+	// Daemon-wide spending limits keyed by name; crossing 50/80/100% publishes an alert
+	CostBudgets map[string]CostBudget `mapstructure:"cost_budgets"`
+
+	// Daily digests of session activity, keyed by recipient
+	Digests map[string]DigestConfig `mapstructure:"digests"`
+
+	// Schedules of the daemon's maintenance tasks, such as artifact retention, keyed by task name
+	Maintenance map[string]MaintenanceTaskConfig `mapstructure:"maintenance"`
+
+	// Automatic retries of sessions that fail transiently, keyed by template label;
+	// "*" applies to sessions whose template has no entry
+	RetryPolicies map[string]RetryPolicy `mapstructure:"retry_policies"`
+
+	// What happens on startup to sessions the previous daemon left active
+	Recovery RecoveryConfig `mapstructure:"recovery"`
+
+	// Model routing for the daemon's own LLM calls (commit messages, ephemeral chat, ...)
+	LLM LLMConfig `mapstructure:"llm"`
+
+	// Decisions mined from completed sessions into a per-repository log
+	DecisionLog DecisionLogConfig `mapstructure:"decision_log"`
+
+	// Embeddings of session summaries and key messages, for finding similar
+	// past sessions; off unless a model is set
+	Embeddings EmbeddingsConfig `mapstructure:"embeddings"`
+
+	// Speech-to-text for voice memo responses to approvals; off unless a
+	// provider is set
+	Transcription TranscriptionConfig `mapstructure:"transcription"`
+
+	// Default language (BCP 47, e.g. "fr") for generated text and notifications
+	Locale string `mapstructure:"locale"`
+
+	// Directory of <name>.tmpl files overriding the built-in prompt templates
+	PromptsDir string `mapstructure:"prompts_dir"`
+
+	// SessionSummariesDisabled stops the daemon from asking the LLM for a
+	// structured summary when each session finishes
+	SessionSummariesDisabled bool `mapstructure:"session_summaries_disabled"`
+
+	// External plugin processes providing notification channels, risk scorers or redaction rules
+	Plugins map[string]PluginConfig `mapstructure:"plugins"`
+
+	// Rewrites for session working directories recorded on another machine
+	PathMappings []PathMapping `mapstructure:"path_mappings"`
+
+	// Container runtime settings for sessions launched with container isolation
+	Containers ContainerConfig `mapstructure:"containers"`
+
+	// Issue trackers whose tickets, referenced in session queries, are added to
+	// the session's context and moved on when it opens a pull request
+	Trackers map[string]TrackerConfig `mapstructure:"trackers"`
+
+	// CI mode for pipelines: approvals are decided by the approval policy
+	// alone and anything it doesn't allow is denied, since nobody can answer
+	CI CIConfig `mapstructure:"ci"`
+
+	// Webhook receiver launching sessions from labelled issues and PR comments;
+	// off unless a webhook secret is set
+	GitHub GitHubConfig `mapstructure:"github"`
+
+	// Paging for approvals a critical policy rule asked for that go
+	// unanswered; off unless a provider is set
+	Escalation EscalationConfig `mapstructure:"escalation"`
+
+	// License and vulnerability lookups for dependencies a change adds,
+	// reported with commit suggestions and optionally gating commits
+	DependencyCheck DependencyCheckConfig `mapstructure:"dependency_check"`
+
+	// Static analyzers run on the files a commit includes
+	Analysis AnalysisConfig `mapstructure:"analysis"`
+
+	// Approval routing and review requests for paths a CODEOWNERS file assigns
+	Owners OwnersConfig `mapstructure:"owners"`
+
+	// Large-file checks for repositories using Git LFS, or that should
+	LFS LFSConfig `mapstructure:"lfs"`
+
+	// Signed records of the session behind each commit
+	Provenance ProvenanceConfig `mapstructure:"provenance"`
+
+	// Sampled request and response bodies kept for debugging API clients
+	HTTPCapture HTTPCaptureConfig `mapstructure:"http_capture"`
+
+	// Redacted prompts and raw responses of the daemon's own model calls,
+	// kept for debugging its suggestions
+	PromptLog PromptLogConfig `mapstructure:"prompt_log"`
+
+	// How instructions hidden in session text are handled before it's
+	// embedded in the daemon's own prompts
+	PromptInjection PromptInjectionConfig `mapstructure:"prompt_injection"`
+
+	// Moderation of comments sent to agents and AI text sent to
+	// notification channels
+	ContentSafety ContentSafetyConfig `mapstructure:"content_safety"`
+
+	// How git authenticates to remotes for fetches and pushes
+	GitCredentials GitCredentialsConfig `mapstructure:"git_credentials"`
+
+	// Size limits of the per-session scratch space
+	Scratch ScratchConfig `mapstructure:"scratch"`
+
+	// Free space and repository growth that staging, commits and artifacts
+	// must respect
+	DiskGuard DiskGuardConfig `mapstructure:"disk_guard"`
+
+	// Storage and retention of the artifacts sessions publish
+	Artifacts ArtifactsConfig `mapstructure:"artifacts"`
+
+	// How long links sharing a session with people without daemon access
+	// stay valid
+	Sharing SharingConfig `mapstructure:"sharing"`
+
+	// Spoken announcements of pending approvals and finished sessions, for a
+	// daemon running on a machine nobody watches
+	Announce AnnounceConfig `mapstructure:"announce"`
+
+	// Browser push notifications of approvals for the web UI
+	WebPush WebPushConfig `mapstructure:"web_push"`
+
+	// Do-not-disturb, batching and severity thresholds for notifier plugins,
+	// keyed by plugin name; "*" applies to plugins without their own entry
+	Notifications map[string]NotificationPreferences `mapstructure:"notifications"`
+
+	// How long a quick decision can be undone before the agent hears of it;
+	// 0 means DefaultApprovalUndoSeconds
+	ApprovalUndoSeconds int `mapstructure:"approval_undo_seconds"`
+
+	// ExpandDenials has the summarization model expand terse denial comments
+	// into guidance for the agent
+	ExpandDenials bool `mapstructure:"expand_denials"`
+
+	// Risk score, from 0 to 1, at and above which approving a tool call
+	// needs a comment; 0 turns the requirement off
+	ApprovalJustificationRisk float64 `mapstructure:"approval_justification_risk"`
+
+	// Mirrors approvals to HumanLayer cloud contact channels with api_key,
+	// taking decisions made there back; off unless enabled
+	CloudRelay CloudRelayConfig `mapstructure:"cloud_relay"`
+
+	// Joins daemons into a federation: members register with a hub that
+	// shows their sessions and approvals and can decide them; off unless a
+	// role is set
+	Federation FederationConfig `mapstructure:"federation"`
+
+	// Advertises the daemon over mDNS so clients on this machine or the
+	// local network can find it; off unless enabled
+	MDNS MDNSConfig `mapstructure:"mdns"`
+
+	// Inbound webhooks at /hooks/<name> that launch sessions from alerts,
+	// form submissions and other automations, keyed by name
+	Hooks map[string]HookConfig `mapstructure:"hooks"`
+
+	// Approval notifications by email, answered by replying, and sessions
+	// requested by mail; off unless an address is set
+	Email EmailConfig `mapstructure:"email"`
+
+	// Critical approvals sent by SMS or WhatsApp through Twilio, answered
+	// by replying with their code; off unless an account is set
+	Twilio TwilioConfig `mapstructure:"twilio"`
+}
+
+// Container network policies. Any other value names a runtime network.
+const (
+	ContainerNetworkBridge = "bridge" // Runtime default; outbound access only
+	ContainerNetworkNone   = "none"   // No network; the agent can't reach the model API without a proxy
+	ContainerNetworkHost   = "host"   // Share the host network
+)
+
+// ContainerConfig describes how container-isolated sessions are run. The
+// working directory is bind-mounted at the same path inside the container.
+type ContainerConfig struct {
+	Runtime string `mapstructure:"runtime" json:"runtime,omitempty"` // "docker" (default) or "podman"
+	// Image used when the session's template has no entry in TemplateImages
+	Image          string            `mapstructure:"image" json:"image,omitempty"`
+	TemplateImages map[string]string `mapstructure:"template_images" json:"template_images,omitempty"`
+	Network        string            `mapstructure:"network" json:"network,omitempty"`
+	// Extra bind mounts in the runtime's -v syntax, e.g. "~/.claude:/root/.claude"
+	Mounts []string `mapstructure:"mounts" json:"mounts,omitempty"`
+}
+
+// ImageFor returns the image for sessions launched from template, or "" if
+// no image is configured
+func (c ContainerConfig) ImageFor(template string) string {
+	if image, ok := c.TemplateImages[template]; ok && template != "" {
+		return image
+	}
+	return c.Image
+}
+
+// PathMapping maps paths under From (e.g. /Users/alice/code) to the same
+// relative path under To (e.g. /home/bob/code)
+type PathMapping struct {
+	From string `mapstructure:"from" json:"from"`
+	To   string `mapstructure:"to" json:"to"`
+}
+
+// SessionBudget caps tool calls, cost and run time for a session. Zero means unlimited.
+type SessionBudget struct {
+	MaxToolCalls       int     `mapstructure:"max_tool_calls" json:"max_tool_calls,omitempty"`
+	MaxCostUSD         float64 `mapstructure:"max_cost_usd" json:"max_cost_usd,omitempty"`
+	MaxDurationSeconds int     `mapstructure:"max_duration_seconds" json:"max_duration_seconds,omitempty"`
+}
+
+// Periods a cost budget can cover. Periods are calendar-aligned in local time.
+const (
+	CostBudgetPeriodDay   = "day"
+	CostBudgetPeriodWeek  = "week"
+	CostBudgetPeriodMonth = "month"
+)
+
+// CostBudget limits total session spend over a period
+type CostBudget struct {
+	Period   string  `mapstructure:"period" json:"period"`
+	LimitUSD float64 `mapstructure:"limit_usd" json:"limit_usd"`
+	// Only count sessions whose working directory is under this path (optional)
+	WorkingDir string `mapstructure:"working_dir" json:"working_dir,omitempty"`
+}
+
+// DefaultDigestTime is when digests are sent if a recipient doesn't choose
+const DefaultDigestTime = "09:00"
+
+// DigestConfig is one recipient's daily digest preferences
+type DigestConfig struct {
+	// Local time of day the digest is sent, as "HH:MM"
+	Time string `mapstructure:"time" json:"time,omitempty"`
+	// Working directories to report on, one message each; empty groups every session by its working directory
+	Workspaces []string `mapstructure:"workspaces" json:"workspaces,omitempty"`
+	// Notifier plugins the digest is delivered through; empty means all of them
+	Plugins []string `mapstructure:"plugins" json:"plugins,omitempty"`
+	// Send a digest even for a day without activity
+	SendEmpty bool `mapstructure:"send_empty" json:"send_empty,omitempty"`
+}
+
+// MaintenanceTaskConfig overrides a maintenance task's schedule
+type MaintenanceTaskConfig struct {
+	// Time between runs as a Go duration such as "30m"; empty keeps the task's default
+	Interval string `mapstructure:"interval" json:"interval,omitempty"`
+	// Stop running the task on its schedule; it can still be run by hand
+	Disabled bool `mapstructure:"disabled" json:"disabled,omitempty"`
+}
+
+// SendTime returns the digest's time of day, applying the default
+func (d DigestConfig) SendTime() (hour, minute int, err error) {
+	value := d.Time
+	if value == "" {
+		value = DefaultDigestTime
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid time %q, want HH:MM", value)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// Retry modes
+const (
+	RetryModeResume = "resume" // Continue the failed conversation where it stopped
+	RetryModeFresh  = "fresh"  // Launch the original query again as a new conversation
+)
+
+// DefaultRetryBackoff is the wait before a first retry when a policy sets none
+const DefaultRetryBackoff = 30 * time.Second
+
+// RetryPolicy decides whether and how a failed session is retried
+type RetryPolicy struct {
+	// Retries after the original attempt; 0 disables retrying
+	MaxAttempts int `mapstructure:"max_attempts" json:"max_attempts"`
+	// Wait before the first retry as a Go duration; doubles with each further attempt
+	Backoff string `mapstructure:"backoff" json:"backoff,omitempty"`
+	// "resume" (default) or "fresh"; resume falls back to fresh when the
+	// failed session has no conversation to continue
+	Mode string `mapstructure:"mode" json:"mode,omitempty"`
+	// Extra case-insensitive error substrings treated as transient
+	Match []string `mapstructure:"match" json:"match,omitempty"`
+	// Also retry sessions whose Claude process was killed by a signal, such
+	// as by the OOM killer, whatever the error
+	OnCrash bool `mapstructure:"on_crash" json:"on_crash,omitempty"`
+}
+
+// Delay returns the wait before the given retry attempt, starting at 1
+func (p RetryPolicy) Delay(attempt int) (time.Duration, error) {
+	backoff := DefaultRetryBackoff
+	if p.Backoff != "" {
+		d, err := time.ParseDuration(p.Backoff)
+		if err != nil || d < 0 {
+			return 0, fmt.Errorf("invalid backoff %q", p.Backoff)
+		}
+		backoff = d
+	}
+	for i := 1; i < attempt; i++ {
+		backoff *= 2
+	}
+	return backoff, nil
+}
+
+// DefaultRecoveryMaxAge bounds how long a session may have been idle before
+// the crash and still be resumed automatically
+const DefaultRecoveryMaxAge = time.Hour
+
+// RecoveryConfig decides whether sessions interrupted by a daemon crash or
+// restart are continued once it is back
+type RecoveryConfig struct {
+	AutoResume bool `mapstructure:"auto_resume" json:"auto_resume"`
+	// Sessions idle longer than this Go duration before the crash stay interrupted
+	MaxAge string `mapstructure:"max_age" json:"max_age,omitempty"`
+	// Only resume sessions with these template labels; empty resumes any
+	Templates []string `mapstructure:"templates" json:"templates,omitempty"`
+}
+
+// MaxAgeDuration returns MaxAge, or DefaultRecoveryMaxAge when unset
+func (r RecoveryConfig) MaxAgeDuration() (time.Duration, error) {
+	if r.MaxAge == "" {
+		return DefaultRecoveryMaxAge, nil
+	}
+	d, err := time.ParseDuration(r.MaxAge)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid max_age %q", r.MaxAge)
+	}
+	return d, nil
+}
+
+// LLM provider types
+const (
+	LLMProviderAnthropic  = "anthropic"   // Anthropic Messages API
+	LLMProviderOpenAI     = "openai"      // OpenAI-compatible chat completions (OpenRouter, vLLM, ...)
+	LLMProviderClaudeCode = "claude_code" // Local Claude Code CLI
+)
+
+// LLMConfig configures providers and the ordered models tried for each task type.
+// Providers and routes given here are merged over the built-in defaults.
+type LLMConfig struct {
+	Providers map[string]LLMProviderConfig `mapstructure:"providers" json:"providers,omitempty"`
+	Routes    map[string][]LLMRoute        `mapstructure:"routes" json:"routes,omitempty"`
+}
+
+// LLMProviderConfig describes how to reach a provider
+type LLMProviderConfig struct {
+	Type      string `mapstructure:"type" json:"type"`
+	BaseURL   string `mapstructure:"base_url" json:"base_url,omitempty"`
+	APIKeyEnv string `mapstructure:"api_key_env" json:"api_key_env,omitempty"` // Environment variable holding the API key
+}
+
+// LLMRoute is one model to try for a task; later routes are fallbacks
+type LLMRoute struct {
+	Provider string `mapstructure:"provider" json:"provider"`
+	Model    string `mapstructure:"model" json:"model"`
+}
+
+// DecisionLogConfig controls the decision log
+type DecisionLogConfig struct {
+	// Extract decisions from each session that completes
+	Enabled bool `mapstructure:"enabled" json:"enabled,omitempty"`
+	// Add the repository's active decisions to every new session's system prompt
+	InjectContext bool `mapstructure:"inject_context" json:"inject_context,omitempty"`
+}
+
+// TranscriptionConfig names the speech-to-text model. Provider is an llm
+// provider of type "openai" whose API serves /v1/audio/transcriptions, such
+// as OpenAI's Whisper or a self-hosted Whisper server.
+type TranscriptionConfig struct {
+	Provider string `mapstructure:"provider" json:"provider"`
+	// Model defaults to whisper-1
+	Model string `mapstructure:"model" json:"model,omitempty"`
+	// Language is an ISO 639-1 hint such as "en"; empty detects it
+	Language string `mapstructure:"language" json:"language,omitempty"`
+}
+
+// EmbeddingsConfig names the model that embeds session text. Provider is an
+// llm provider of type "openai" whose API serves /v1/embeddings, such as
+// OpenAI itself or a local Ollama.
+type EmbeddingsConfig struct {
+	Provider string `mapstructure:"provider" json:"provider"`
+	Model    string `mapstructure:"model" json:"model"`
+}
+
+// Issue tracker types
+const (
+	TrackerLinear = "linear"
+	TrackerAsana  = "asana"
+)
+
+// TrackerConfig describes an issue tracker account
+type TrackerConfig struct {
+	Type      string `mapstructure:"type" json:"type"`
+	APIKeyEnv string `mapstructure:"api_key_env" json:"api_key_env"` // Environment variable holding the API key
+	BaseURL   string `mapstructure:"base_url" json:"base_url,omitempty"`
+	// Linear team keys whose identifiers (e.g. ENG-123) are recognized; empty
+	// recognizes any, which may also match other trackers' keys
+	TeamKeys []string `mapstructure:"team_keys" json:"team_keys,omitempty"`
+	// Status (Linear workflow state or Asana section) a ticket moves to when
+	// a session referencing it completes after creating a pull request
+	ReviewStatus string `mapstructure:"review_status" json:"review_status,omitempty"`
+}
+
+// CIConfig configures CI mode
+type CIConfig struct {
+	// Enabled requires approval_policy_path. Sessions' allowed_tools, auto-accept
+	// and skip-permissions settings are ignored so every tool call meets the policy.
+	Enabled bool `mapstructure:"enabled" json:"enabled,omitempty"`
+}
+
+// Escalation providers
+const (
+	EscalationPagerDuty = "pagerduty"
+	EscalationOpsgenie  = "opsgenie"
+
+	DefaultEscalationSLASeconds = 900
+)
+
+// DefaultCloudRelayPollSeconds is how often the cloud relay checks for
+// decisions made in HumanLayer cloud
+const DefaultCloudRelayPollSeconds = 5
+
+// CloudRelayConfig configures mirroring approvals to HumanLayer cloud
+type CloudRelayConfig struct {
+	Enabled     bool `mapstructure:"enabled" json:"enabled,omitempty"`
+	PollSeconds int  `mapstructure:"poll_seconds" json:"poll_seconds,omitempty"`
+	// Contact channel for mirrored approvals; the project's default channel
+	// is used if neither is set
+	SlackChannel string `mapstructure:"slack_channel" json:"slack_channel,omitempty"` // Channel or user ID
+	Email        string `mapstructure:"email" json:"email,omitempty"`
+}
+
+// Federation roles
+const (
+	FederationHub    = "hub"
+	FederationMember = "member"
+)
+
+// FederationConfig configures this daemon's place in a federation
+type FederationConfig struct {
+	Role string `mapstructure:"role" json:"role,omitempty"`
+	// TokenEnv names the environment variable holding the token shared by
+	// the hub and its members
+	TokenEnv string `mapstructure:"token_env" json:"token_env,omitempty"`
+	// Members only
+	HubURL       string `mapstructure:"hub_url" json:"hub_url,omitempty"`             // Base URL of the hub's REST API
+	Name         string `mapstructure:"name" json:"name,omitempty"`                   // Defaults to the hostname
+	AdvertiseURL string `mapstructure:"advertise_url" json:"advertise_url,omitempty"` // Where the hub reaches this daemon
+}
+
+// MDNSConfig configures advertising the daemon over mDNS
+type MDNSConfig struct {
+	Enabled bool   `mapstructure:"enabled" json:"enabled,omitempty"`
+	Name    string `mapstructure:"name" json:"name,omitempty"` // Instance name; defaults to the hostname
+}
+
+// EscalationConfig configures incidents for unanswered critical approvals
+type EscalationConfig struct {
+	Provider string `mapstructure:"provider" json:"provider,omitempty"`
+	// KeyEnv names the environment variable holding the PagerDuty Events v2
+	// routing key or the Opsgenie API key
+	KeyEnv  string `mapstructure:"key_env" json:"key_env,omitempty"`
+	BaseURL string `mapstructure:"base_url" json:"base_url,omitempty"` // e.g. Opsgenie's EU endpoint
+	// SLASeconds is how long a critical approval may wait before paging
+	SLASeconds int `mapstructure:"sla_seconds" json:"sla_seconds,omitempty"`
+	// UIURL, if set, adds a link to the session in the web UI
+	UIURL string `mapstructure:"ui_url" json:"ui_url,omitempty"`
+}
+
+// DependencyCheckConfig configures dependency lookups. Lookups send the
+// names and versions of added dependencies to OSV and deps.dev.
+type DependencyCheckConfig struct {
+	Enabled    bool   `mapstructure:"enabled" json:"enabled,omitempty"`
+	OSVURL     string `mapstructure:"osv_url" json:"osv_url,omitempty"`           // Defaults to https://api.osv.dev
+	DepsDevURL string `mapstructure:"deps_dev_url" json:"deps_dev_url,omitempty"` // Defaults to https://api.deps.dev
+	// DeniedLicenses are SPDX identifiers that fail the commit gate
+	DeniedLicenses []string `mapstructure:"denied_licenses" json:"denied_licenses,omitempty"`
+	// Gate checks every commit, not only those asking with checkDependencies
+	Gate bool `mapstructure:"gate" json:"gate,omitempty"`
+}
+
+// Analyzer finding severities
+const (
+	AnalyzerSeverityError   = "error"
+	AnalyzerSeverityWarning = "warning"
+)
+
+// AnalysisConfig configures the analyzers run before commits
+type AnalysisConfig struct {
+	// Repositories maps a repository directory to the analyzers run for
+	// sessions inside it; "*" applies to repositories with no entry
+	Repositories map[string][]AnalyzerConfig `mapstructure:"repositories" json:"repositories,omitempty"`
+	// Gate runs the analyzers before every commit, not only those asking with analyze
+	Gate bool `mapstructure:"gate" json:"gate,omitempty"`
+	// BlockOnError refuses commits with error-severity findings
+	BlockOnError bool `mapstructure:"block_on_error" json:"block_on_error,omitempty"`
+	// Formatters maps repository directories, as Repositories does, to the
+	// formatters run over committed files before the analyzers
+	Formatters map[string][]FormatterConfig `mapstructure:"formatters" json:"formatters,omitempty"`
+	// Format runs the formatters before every commit, not only those asking with format
+	Format bool `mapstructure:"format" json:"format,omitempty"`
+}
+
+// FormatterConfig is one formatter, run in place with the selected files
+// appended to its command (e.g. ["gofmt", "-w"] or ["black", "-q"])
+type FormatterConfig struct {
+	Name     string   `mapstructure:"name" json:"name"`
+	Command  []string `mapstructure:"command" json:"command"`
+	Patterns []string `mapstructure:"patterns" json:"patterns,omitempty"` // As for analyzers
+	Timeout  string   `mapstructure:"timeout" json:"timeout,omitempty"`   // Defaults to 2m
+}
+
+// AnalyzerConfig is one analyzer. Its output is read as file:line[:col]:
+// message lines, the format go vet, staticcheck, ruff and eslint -f unix print.
+type AnalyzerConfig struct {
+	Name    string   `mapstructure:"name" json:"name"`
+	Command []string `mapstructure:"command" json:"command"`
+	// Patterns are globs (e.g. "*.go") selecting the files the analyzer
+	// covers; it is skipped when the commit has none. Empty covers every file.
+	Patterns []string `mapstructure:"patterns" json:"patterns,omitempty"`
+	// PassFiles appends the selected files to the command; otherwise it
+	// analyzes what it likes (e.g. ./...) and findings elsewhere are dropped
+	PassFiles bool `mapstructure:"pass_files" json:"pass_files,omitempty"`
+	// Severity of findings whose output doesn't say; defaults to error
+	Severity string `mapstructure:"severity" json:"severity,omitempty"`
+	Timeout  string `mapstructure:"timeout" json:"timeout,omitempty"` // Defaults to 2m
+}
+
+// OwnersConfig configures what's done with CODEOWNERS owners
+type OwnersConfig struct {
+	// Channels maps a CODEOWNERS owner (@org/team, @user or an email) to
+	// notifier plugins. Those plugins only get approvals for files the owner owns.
+	Channels map[string][]string `mapstructure:"channels" json:"channels,omitempty"`
+	// RequestReviewers asks the owners of files in a GitHub pull request a
+	// session opens to review it, and comments with blame-based suggestions
+	RequestReviewers bool `mapstructure:"request_reviewers" json:"request_reviewers,omitempty"`
+}
+
+// LFSConfig sets which files git status and commits flag for not going
+// through Git LFS
+type LFSConfig struct {
+	// LargeFileSize is the size in bytes over which a file LFS doesn't track
+	// is flagged. Zero uses the default of 10 MiB; negative turns the check off.
+	LargeFileSize int64 `mapstructure:"large_file_size" json:"large_file_size,omitempty"`
+	// Block refuses commits that include flagged files instead of warning
+	Block bool `mapstructure:"block" json:"block,omitempty"`
+}
+
+// Where commit provenance is recorded
+const (
+	ProvenanceTrailer = "trailer" // Trailers in the commit message
+	ProvenanceNote    = "note"    // A git note, leaving the message alone
+)
+
+// Provenance defaults
+const (
+	DefaultProvenanceNotesRef = "refs/notes/humanlayer"
+	DefaultProvenanceKeyEnv   = "HUMANLAYER_PROVENANCE_KEY"
+)
+
+// ProvenanceConfig records the session, model, prompt hash and daemon
+// version behind each commit the daemon makes
+type ProvenanceConfig struct {
+	// Mode is ProvenanceTrailer or ProvenanceNote; empty records nothing
+	Mode string `mapstructure:"mode" json:"mode,omitempty"`
+	// NotesRef holds the notes in note mode
+	NotesRef string `mapstructure:"notes_ref" json:"notes_ref,omitempty"`
+	// KeyEnv names the environment variable holding the HMAC signing key.
+	// Without a key, records are unsigned.
+	KeyEnv string `mapstructure:"key_env" json:"key_env,omitempty"`
+}
+
+// HTTP capture defaults
+const (
+	DefaultHTTPCaptureBufferSize  = 200
+	DefaultHTTPCaptureMaxBodySize = 64 << 10
+)
+
+// HTTPCaptureConfig records request and response bodies for matching routes
+// in memory, for GET /api/v1/debug/http-captures
+type HTTPCaptureConfig struct {
+	Enabled bool `mapstructure:"enabled" json:"enabled,omitempty"`
+	// Routes are path.Match patterns against the route, such as
+	// /api/v1/sessions/:id, or the request path. Empty captures every route.
+	Routes []string `mapstructure:"routes" json:"routes,omitempty"`
+	// SampleRate is the share of matching requests captured, from 0 to 1.
+	// Zero captures all of them.
+	SampleRate float64 `mapstructure:"sample_rate" json:"sample_rate,omitempty"`
+	// BufferSize is how many captures are kept; zero uses the default of 200
+	BufferSize int `mapstructure:"buffer_size" json:"buffer_size,omitempty"`
+	// MaxBodySize truncates each captured body, in bytes; zero uses the
+	// default of 64 KiB
+	MaxBodySize int `mapstructure:"max_body_size" json:"max_body_size,omitempty"`
+	// RedactFields adds JSON keys whose values are redacted, on top of
+	// those that look like credentials
+	RedactFields []string `mapstructure:"redact_fields" json:"redact_fields,omitempty"`
+}
+
+// Prompt log defaults
+const (
+	DefaultPromptLogBufferSize  = 100
+	DefaultPromptLogMaxTextSize = 256 << 10
+)
+
+// PromptLogConfig records the prompts the daemon sends to models and the
+// raw text they answer in memory, for GET /api/v1/debug/prompt-log
+type PromptLogConfig struct {
+	Enabled bool `mapstructure:"enabled" json:"enabled,omitempty"`
+	// Tasks are the LLM tasks recorded, such as commit-message. Empty
+	// records commit-message and ephemeral-chat.
+	Tasks []string `mapstructure:"tasks" json:"tasks,omitempty"`
+	// BufferSize is how many calls are kept; zero uses the default of 100
+	BufferSize int `mapstructure:"buffer_size" json:"buffer_size,omitempty"`
+	// MaxTextSize truncates each prompt and response, in bytes; zero uses
+	// the default of 256 KiB
+	MaxTextSize int `mapstructure:"max_text_size" json:"max_text_size,omitempty"`
+	// RedactPatterns are regular expressions whose matches are redacted, on
+	// top of text that looks like a credential
+	RedactPatterns []string `mapstructure:"redact_patterns" json:"redact_patterns,omitempty"`
+}
+
+// Prompt injection modes
+const (
+	PromptInjectionStrip = "strip" // Remove suspicious lines and record them
+	PromptInjectionFlag  = "flag"  // Only record suspicious lines
+	PromptInjectionOff   = "off"
+)
+
+// PromptInjectionConfig scans conversation text, commit subjects and other
+// content for instructions aimed at the model before it's put in a prompt
+type PromptInjectionConfig struct {
+	// Mode is strip (the default), flag or off
+	Mode string `mapstructure:"mode" json:"mode,omitempty"`
+	// Patterns are extra regular expressions treated as injections, matched
+	// case-insensitively
+	Patterns []string `mapstructure:"patterns" json:"patterns,omitempty"`
+}
+
+// DefaultContentSafetyCategories are what the moderation model is asked to
+// block when a policy names none
+var DefaultContentSafetyCategories = []string{"harassment", "hate", "sexual", "violence", "self_harm"}
+
+// ContentSafetyPolicy sets how text is checked before it's sent to an agent
+// or a notification channel
+type ContentSafetyPolicy struct {
+	Enabled bool `mapstructure:"enabled" json:"enabled,omitempty"`
+	// Patterns are regular expressions, matched case-insensitively, that
+	// block any text they match
+	Patterns []string `mapstructure:"patterns" json:"patterns,omitempty"`
+	// Model also asks the summarization model route whether the text falls
+	// into one of Categories
+	Model      bool     `mapstructure:"model" json:"model,omitempty"`
+	Categories []string `mapstructure:"categories" json:"categories,omitempty"`
+	// FailClosed blocks text the model couldn't check; by default it's sent
+	FailClosed bool `mapstructure:"fail_closed" json:"fail_closed,omitempty"`
+}
+
+// ContentSafetyWorkspace replaces the default policy for sessions under Path
+type ContentSafetyWorkspace struct {
+	Path                string `mapstructure:"path" json:"path"`
+	ContentSafetyPolicy `mapstructure:",squash"`
+}
+
+// ContentSafetyConfig is the default content safety policy and the
+// workspaces that set their own. The workspace with the longest path
+// containing a session's working directory applies.
+type ContentSafetyConfig struct {
+	ContentSafetyPolicy `mapstructure:",squash"`
+	Workspaces          []ContentSafetyWorkspace `mapstructure:"workspaces" json:"workspaces,omitempty"`
+}
+
+// GitCredentialsConfig sets how git authenticates to remotes, on top of
+// tokens stored through /api/v1/credentials
+type GitCredentialsConfig struct {
+	// Helpers are git credential helpers consulted after the user's own,
+	// such as "!gh auth git-credential" or "cache --timeout=3600"
+	Helpers []string `mapstructure:"helpers" json:"helpers,omitempty"`
+	// KeyFile holds the key stored tokens are encrypted with. Empty uses
+	// credentials.key beside the database; it's created on first use.
+	KeyFile string `mapstructure:"key_file" json:"key_file,omitempty"`
+	// SSHAuthSock is the SSH agent git uses for SSH remotes, in place of
+	// the daemon's SSH_AUTH_SOCK
+	SSHAuthSock string `mapstructure:"ssh_auth_sock" json:"ssh_auth_sock,omitempty"`
+	// ForwardAgent forwards the SSH agent to remote working directories,
+	// so git there can use the daemon's keys
+	ForwardAgent bool `mapstructure:"forward_agent" json:"forward_agent,omitempty"`
+}
+
+// Scratch space defaults
+const (
+	DefaultScratchMaxFileSize    = 10 << 20
+	DefaultScratchMaxSessionSize = 100 << 20
+)
+
+// ScratchConfig caps the files sessions keep outside their repositories
+type ScratchConfig struct {
+	// MaxFileSize caps one file, 10 MiB by default
+	MaxFileSize int64 `mapstructure:"max_file_size" json:"max_file_size,omitempty"`
+	// MaxSessionSize caps all of a session's files, 100 MiB by default
+	MaxSessionSize int64 `mapstructure:"max_session_size" json:"max_session_size,omitempty"`
+}
+
+// DefaultDiskGuardMinFree is the free space writes must leave by default
+const DefaultDiskGuardMinFree = 512 << 20
+
+// DiskGuardConfig sets the thresholds writes are refused past
+type DiskGuardConfig struct {
+	// MinFree is the space in bytes that staging, commits and artifacts must
+	// leave free. Zero uses the default of 512 MiB; negative turns the check off.
+	MinFree int64 `mapstructure:"min_free" json:"min_free,omitempty"`
+	// MaxRepoGrowth caps the bytes one commit adds to a repository. Zero
+	// turns the check off.
+	MaxRepoGrowth int64 `mapstructure:"max_repo_growth" json:"max_repo_growth,omitempty"`
+}
+
+// Artifact defaults
+const (
+	DefaultArtifactRetentionDays = 30
+	DefaultArtifactMaxSize       = 1 << 30
+	DefaultArtifactLinkTTL       = time.Hour
+)
+
+// ArtifactsConfig sets where published artifacts are kept and for how long
+type ArtifactsConfig struct {
+	// Dir holds artifact content. Empty uses artifacts beside the database.
+	Dir string `mapstructure:"dir" json:"dir,omitempty"`
+	// RetentionDays is how long artifacts are kept, 30 by default; -1
+	// keeps them until they're deleted
+	RetentionDays int `mapstructure:"retention_days" json:"retention_days,omitempty"`
+	// MaxSize caps one artifact, 1 GiB by default
+	MaxSize int64 `mapstructure:"max_size" json:"max_size,omitempty"`
+	// LinkTTL is how long download links stay valid, as a Go duration
+	// string (default "1h")
+	LinkTTL string `mapstructure:"link_ttl" json:"link_ttl,omitempty"`
+}
+
+// Share link defaults
+const (
+	DefaultShareLinkTTL    = 24 * time.Hour
+	DefaultShareLinkMaxTTL = 7 * 24 * time.Hour
+)
+
+// SharingConfig bounds how long session share links stay valid and where
+// link holders reach them
+type SharingConfig struct {
+	// DefaultTTL is how long a link is valid when its creator doesn't say,
+	// as a Go duration string (default "24h")
+	DefaultTTL string `mapstructure:"default_ttl" json:"default_ttl,omitempty"`
+	// MaxTTL is the longest a link can be valid (default "168h")
+	MaxTTL string `mapstructure:"max_ttl" json:"max_ttl,omitempty"`
+	// Listen is a host:port serving only the link holders' /shared routes,
+	// so they can be exposed without the unauthenticated API. When set,
+	// the API no longer serves /api/v1/shared.
+	Listen string `mapstructure:"listen" json:"listen,omitempty"`
+}
+
+// Events the announcer can speak
+const (
+	AnnounceApproval         = "approval"
+	AnnounceSessionCompleted = "session_completed"
+	AnnounceSessionFailed    = "session_failed"
+)
+
+// AnnounceConfig configures spoken announcements through a local
+// text-to-speech command
+type AnnounceConfig struct {
+	Enabled bool `mapstructure:"enabled" json:"enabled,omitempty"`
+	// Command speaks the text passed as its last argument. Empty uses say
+	// on macOS, and spd-say, espeak-ng or espeak elsewhere.
+	Command []string `mapstructure:"command" json:"command,omitempty"`
+	// Events to announce; empty announces approvals only
+	Events []string `mapstructure:"events" json:"events,omitempty"`
+	// QuietHours silences announcements between two local times
+	QuietHours QuietHours `mapstructure:"quiet_hours" json:"quiet_hours,omitempty"`
+}
+
+// QuietHours is a daily span of local time, as "HH:MM"; it can cross
+// midnight, such as 22:00 to 07:00
+type QuietHours struct {
+	Start string `mapstructure:"start" json:"start,omitempty"`
+	End   string `mapstructure:"end" json:"end,omitempty"`
+}
+
+// Contains reports whether t falls within the quiet hours. Unset quiet
+// hours contain nothing.
+func (q QuietHours) Contains(t time.Time) (bool, error) {
+	if q.Start == "" && q.End == "" {
+		return false, nil
+	}
+	start, err := time.Parse("15:04", q.Start)
+	if err != nil {
+		return false, fmt.Errorf("invalid start %q, want HH:MM", q.Start)
+	}
+	end, err := time.Parse("15:04", q.End)
+	if err != nil {
+		return false, fmt.Errorf("invalid end %q, want HH:MM", q.End)
+	}
+	from := start.Hour()*60 + start.Minute()
+	to := end.Hour()*60 + end.Minute()
+	now := t.Hour()*60 + t.Minute()
+	if from <= to {
+		return now >= from && now < to, nil
+	}
+	return now >= from || now < to, nil
+}
+
+// Notification severities, lowest first
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// SeverityRank orders severities, returning -1 for unknown ones
+func SeverityRank(severity string) int {
+	switch severity {
+	case SeverityInfo:
+		return 0
+	case SeverityWarning:
+		return 1
+	case SeverityCritical:
+		return 2
+	}
+	return -1
+}
+
+// NotificationPreferences control when a notifier plugin, usually one
+// person's or team's channel, is sent notifications
+type NotificationPreferences struct {
+	// MuteWindows hold notifications until the window ends, then send them
+	// grouped; critical notifications still go out
+	MuteWindows []QuietHours `mapstructure:"mute_windows" json:"mute_windows,omitempty"`
+	// Batch sends at most one message per period, as a Go duration such as
+	// "5m"; notifications in between are grouped into the next message
+	Batch string `mapstructure:"batch" json:"batch,omitempty"`
+	// MinSeverity drops notifications below info (default), warning or
+	// critical
+	MinSeverity string `mapstructure:"min_severity" json:"min_severity,omitempty"`
+}
+
+// WebPushConfig configures Web Push notifications
+type WebPushConfig struct {
+	// Subject is a mailto: or https: contact push services can reach the
+	// operator at; Apple's push service requires one
+	Subject string `mapstructure:"subject" json:"subject,omitempty"`
+}
+
+// Quick decision undo window defaults
+const (
+	DefaultApprovalUndoSeconds = 5
+	// MaxApprovalUndoSeconds keeps the agent from waiting long on a decision
+	// already made
+	MaxApprovalUndoSeconds = 60
+)
+
+// GitHub webhook defaults
+const (
+	DefaultGitHubFixLabel      = "humanlayer:fix"
+	DefaultGitHubReviewCommand = "/hl review"
+	DefaultGitHubTokenEnv      = "GITHUB_TOKEN"
+	DefaultGitHubAPIBaseURL    = "https://api.github.com"
+)
+
+// GitHubConfig configures the GitHub webhook receiver
+type GitHubConfig struct {
+	// Secret shared with GitHub for verifying webhook signatures
+	WebhookSecret string `mapstructure:"webhook_secret" json:"webhook_secret,omitempty"`
+	// Environment variable holding the token used to post status comments
+	TokenEnv   string `mapstructure:"token_env" json:"token_env,omitempty"`
+	APIBaseURL string `mapstructure:"api_base_url" json:"api_base_url,omitempty"` // For GitHub Enterprise
+	// Issue label that launches a fix session
+	FixLabel string `mapstructure:"fix_label" json:"fix_label,omitempty"`
+	// Pull request comment that launches a review session; text after it is
+	// passed to the session as extra instructions
+	ReviewCommand string `mapstructure:"review_command" json:"review_command,omitempty"`
+	// Local clones keyed by "owner/name"; events from other repositories are ignored
+	Repositories map[string]GitHubRepository `mapstructure:"repositories" json:"repositories,omitempty"`
+}
+
+// GitHubRepository maps a GitHub repository to a local clone. Sessions run in
+// worktrees of the clone, checked out at the issue's default branch or the
+// pull request's head.
+type GitHubRepository struct {
+	WorkingDir string `mapstructure:"working_dir" json:"working_dir"`
+	// Template label for launched sessions, selecting retry policies and
+	// container images; defaults to "github-fix" or "github-review"
+	Template string `mapstructure:"template" json:"template,omitempty"`
+}
+
+// Email defaults
+const (
+	DefaultSMTPPort        = 587
+	DefaultSMTPPasswordEnv = "HUMANLAYER_SMTP_PASSWORD"
+	DefaultEmailTemplate   = "email"
+	DefaultEmailTokenTTL   = 72 // hours
+)
+
+// EmailConfig connects the daemon to email. Approval notifications are sent
+// over SMTP with a signed token in the subject, so a reply can approve or
+// deny. Inbound mail arrives from a parse service (SendGrid Inbound Parse,
+// or Amazon SES through SNS) posting to /email/inbound: replies resolve
+// approvals and other mail to Address starts a session.
+type EmailConfig struct {
+	// Address notifications are sent from and inbound mail must be sent to
+	Address string `mapstructure:"address" json:"address,omitempty"`
+	// Recipients of approval notifications; none turns notifications off
+	NotifyTo        []string `mapstructure:"notify_to" json:"notify_to,omitempty"`
+	SMTPHost        string   `mapstructure:"smtp_host" json:"smtp_host,omitempty"`
+	SMTPPort        int      `mapstructure:"smtp_port" json:"smtp_port,omitempty"`
+	SMTPUsername    string   `mapstructure:"smtp_username" json:"smtp_username,omitempty"`
+	SMTPPasswordEnv string   `mapstructure:"smtp_password_env" json:"smtp_password_env,omitempty"`
+	// Secret the parse service authenticates with, as the password of basic
+	// auth in the webhook URL or as a bearer token
+	InboundSecret string `mapstructure:"inbound_secret" json:"inbound_secret,omitempty"`
+	// Senders whose mail is acted on: addresses, or "@example.com" for a
+	// whole domain. Mail from anyone else is ignored.
+	AllowedSenders []string `mapstructure:"allowed_senders" json:"allowed_senders,omitempty"`
+	// Start sessions from mail the parse service reported no SPF or DKIM
+	// result for. Only the From header vouches for such mail, so leave this
+	// off unless the parse service can't check senders.
+	AllowUnverifiedSenders bool `mapstructure:"allow_unverified_senders" json:"allow_unverified_senders,omitempty"`
+	// Where sessions requested by mail run; without one, mail only answers
+	// approvals
+	WorkingDir string `mapstructure:"working_dir" json:"working_dir,omitempty"`
+	// Template label for sessions requested by mail; defaults to "email"
+	Template string `mapstructure:"template" json:"template,omitempty"`
+	// Hours a notification's token can be replied to; defaults to 72
+	TokenTTLHours int `mapstructure:"token_ttl_hours" json:"token_ttl_hours,omitempty"`
+}
+
+// Twilio defaults
+const (
+	DefaultTwilioAuthTokenEnv = "TWILIO_AUTH_TOKEN"
+	DefaultTwilioAPIBaseURL   = "https://api.twilio.com"
+)
+
+// TwilioConfig sends approvals as text messages with a short code, and
+// decides them from "YES <code>" and "NO <code> <reason>" replies
+type TwilioConfig struct {
+	AccountSID string `mapstructure:"account_sid" json:"account_sid,omitempty"`
+	// Environment variable holding the auth token, which also signs the
+	// webhooks Twilio sends
+	AuthTokenEnv string `mapstructure:"auth_token_env" json:"auth_token_env,omitempty"`
+	// Number messages are sent from, such as "+15550100"
+	From string `mapstructure:"from" json:"from,omitempty"`
+	// WhatsApp sender, such as "whatsapp:+15550100", for users whose number
+	// has the whatsapp: prefix
+	WhatsAppFrom string `mapstructure:"whatsapp_from" json:"whatsapp_from,omitempty"`
+	// Approvers by number ("+15550123" or "whatsapp:+15550123") to their
+	// name; only they are messaged, and only their replies are acted on
+	Users map[string]string `mapstructure:"users" json:"users,omitempty"`
+	// Send every approval, not only those a critical policy rule asked for
+	AllApprovals bool `mapstructure:"all_approvals" json:"all_approvals,omitempty"`
+	// Public URL of /api/v1/twilio/inbound as configured in Twilio, which
+	// its webhook signatures cover
+	WebhookURL string `mapstructure:"webhook_url" json:"webhook_url,omitempty"`
+	APIBaseURL string `mapstructure:"api_base_url" json:"api_base_url,omitempty"`
+}
+
+// hookName keeps hook names usable as a URL path segment
+var hookName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// HookConfig maps deliveries to an inbound webhook onto sessions. Payloads
+// are JSON or form-encoded; fields are addressed by dotted paths such as
+// "alert.labels.service" or "items.0.name".
+type HookConfig struct {
+	// Secret callers send as a bearer token, or use to sign the body in an
+	// X-Signature-256 header of the form "sha256=<hex HMAC-SHA256>"
+	Secret     string `mapstructure:"secret" json:"secret"`
+	WorkingDir string `mapstructure:"working_dir" json:"working_dir"`
+	// Payload field used as the query
+	QueryField string `mapstructure:"query_field" json:"query_field,omitempty"`
+	// text/template rendered with the payload as the query, for queries
+	// combining several fields; takes precedence over query_field
+	Query string `mapstructure:"query" json:"query,omitempty"`
+	// text/template rendered with the payload as the session title;
+	// defaults to the hook name
+	Title string `mapstructure:"title" json:"title,omitempty"`
+	// Template label for launched sessions, selecting retry policies and
+	// container images; defaults to "hook-<name>"
+	Template string `mapstructure:"template" json:"template,omitempty"`
+	Model    string `mapstructure:"model" json:"model,omitempty"`
+	// Payload fields that must have these values, keyed by path; other
+	// deliveries are acknowledged and ignored
+	Match map[string]string `mapstructure:"match" json:"match,omitempty"`
+}
+
+// Plugin capabilities an external plugin process can declare
+const (
+	PluginCapabilityNotify    = "notify"
+	PluginCapabilityScoreRisk = "score_risk"
+	PluginCapabilityRedact    = "redact"
+)
+
+// PluginConfig describes an external plugin process. The daemon runs Command
+// once per call, writing a JSON request to stdin and reading a JSON response
+// from stdout.
+type PluginConfig struct {
+	Command      string            `mapstructure:"command" json:"command"`
+	Args         []string          `mapstructure:"args" json:"args,omitempty"`
+	Env          map[string]string `mapstructure:"env" json:"env,omitempty"`
+	Capabilities []string          `mapstructure:"capabilities" json:"capabilities"`
+	// Timeout per call as a Go duration string (default "10s")
+	Timeout string `mapstructure:"timeout" json:"timeout,omitempty"`
+}
+
+// MCPDownstreamServer describes an MCP server the daemon connects to as a client.
+// Either Command (stdio) or URL (streamable HTTP) must be set.
+type MCPDownstreamServer struct {
+	Command string            `mapstructure:"command" json:"command,omitempty"`
+	Args    []string          `mapstructure:"args" json:"args,omitempty"`
+	Env     map[string]string `mapstructure:"env" json:"env,omitempty"`
+	URL     string            `mapstructure:"url" json:"url,omitempty"`
+	Headers map[string]string `mapstructure:"headers" json:"headers,omitempty"`
+
+	// DefaultPolicy applies to tools without an entry in ToolPolicies: "approve" (default), "allow" or "deny"
+	DefaultPolicy string `mapstructure:"default_policy" json:"default_policy,omitempty"`
+	// ToolPolicies overrides the policy for individual downstream tools, keyed by tool name
+	ToolPolicies map[string]string `mapstructure:"tool_policies" json:"tool_policies,omitempty"`
 }
 
 // Load loads configuration with priority: flags > env vars > config file > defaults
@@ -71,7 +1105,17 @@ func Load() (*Config, error) {
 	_ = v.BindEnv("version_override", "HUMANLAYER_DAEMON_VERSION_OVERRIDE")
 	_ = v.BindEnv("http_port", "HUMANLAYER_DAEMON_HTTP_PORT")
 	_ = v.BindEnv("http_host", "HUMANLAYER_DAEMON_HTTP_HOST")
+	_ = v.BindEnv("http_disabled", "HUMANLAYER_DAEMON_HTTP_DISABLED")
 	_ = v.BindEnv("claude_path", "HUMANLAYER_CLAUDE_PATH")
+	_ = v.BindEnv("approval_policy_path", "HUMANLAYER_APPROVAL_POLICY_PATH")
+	_ = v.BindEnv("shadow_approval_policy_path", "HUMANLAYER_SHADOW_APPROVAL_POLICY_PATH")
+	_ = v.BindEnv("prompts_dir", "HUMANLAYER_PROMPTS_DIR")
+	_ = v.BindEnv("locale", "HUMANLAYER_LOCALE")
+	_ = v.BindEnv("github.webhook_secret", "HUMANLAYER_GITHUB_WEBHOOK_SECRET")
+	_ = v.BindEnv("email.inbound_secret", "HUMANLAYER_EMAIL_INBOUND_SECRET")
+	_ = v.BindEnv("ci.enabled", "HUMANLAYER_CI_MODE")
+	_ = v.BindEnv("mdns.enabled", "HUMANLAYER_MDNS")
+	_ = v.BindEnv("recovery.auto_resume", "HUMANLAYER_RECOVERY_AUTO_RESUME")
 
 	// Set defaults
 	setDefaults(v)
@@ -93,6 +1137,40 @@ func Load() (*Config, error) {
 	config.SocketPath = expandHome(config.SocketPath)
 	config.DatabasePath = expandHome(config.DatabasePath)
 	config.ClaudePath = expandHome(config.ClaudePath)
+	config.ApprovalPolicyPath = expandHome(config.ApprovalPolicyPath)
+	config.ShadowApprovalPolicyPath = expandHome(config.ShadowApprovalPolicyPath)
+	config.PromptsDir = expandHome(config.PromptsDir)
+	config.GitCredentials.KeyFile = expandHome(config.GitCredentials.KeyFile)
+	config.GitCredentials.SSHAuthSock = expandHome(config.GitCredentials.SSHAuthSock)
+	config.Artifacts.Dir = expandHome(config.Artifacts.Dir)
+	for name, budget := range config.CostBudgets {
+		budget.WorkingDir = expandHome(budget.WorkingDir)
+		config.CostBudgets[name] = budget
+	}
+	for name, digest := range config.Digests {
+		for i, dir := range digest.Workspaces {
+			digest.Workspaces[i] = expandHome(dir)
+		}
+		config.Digests[name] = digest
+	}
+	for i := range config.PathMappings {
+		config.PathMappings[i].To = expandHome(config.PathMappings[i].To)
+	}
+	for i := range config.ContentSafety.Workspaces {
+		config.ContentSafety.Workspaces[i].Path = expandHome(config.ContentSafety.Workspaces[i].Path)
+	}
+	for i, mount := range config.Containers.Mounts {
+		config.Containers.Mounts[i] = expandHome(mount)
+	}
+	for name, repo := range config.GitHub.Repositories {
+		repo.WorkingDir = expandHome(repo.WorkingDir)
+		config.GitHub.Repositories[name] = repo
+	}
+	for name, hook := range config.Hooks {
+		hook.WorkingDir = expandHome(hook.WorkingDir)
+		config.Hooks[name] = hook
+	}
+	config.Email.WorkingDir = expandHome(config.Email.WorkingDir)
 
 	return &config, nil
 }
@@ -112,6 +1190,7 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("http_port", port)
 	v.SetDefault("http_host", "127.0.0.1")
 	v.SetDefault("claude_path", DefaultClaudePath)
+	v.SetDefault("prompts_dir", filepath.Join(getDefaultConfigDir(), "prompts"))
 }
 
 // getDefaultConfigDir returns the default configuration directory
@@ -141,6 +1220,30 @@ func expandHome(path string) string {
 	return path
 }
 
+// RemapPath rewrites path with the mapping whose From is its longest prefix,
+// matching whole path components. Paths no mapping covers are returned unchanged.
+func RemapPath(mappings []PathMapping, path string) string {
+	if path == "" {
+		return path
+	}
+	cleaned := filepath.Clean(path)
+	best := -1
+	for i, m := range mappings {
+		from := filepath.Clean(m.From)
+		if cleaned != from && !strings.HasPrefix(cleaned, strings.TrimSuffix(from, string(filepath.Separator))+string(filepath.Separator)) {
+			continue
+		}
+		if best == -1 || len(from) > len(filepath.Clean(mappings[best].From)) {
+			best = i
+		}
+	}
+	if best == -1 {
+		return path
+	}
+	rest := strings.TrimPrefix(cleaned, filepath.Clean(mappings[best].From))
+	return filepath.Join(mappings[best].To, rest)
+}
+
 // Validate validates that configuration is valid
 func (c *Config) Validate() error {
 	// For Phase 1, we don't require API key yet
@@ -148,6 +1251,359 @@ func (c *Config) Validate() error {
 	if c.SocketPath == "" {
 		return fmt.Errorf("socket path cannot be empty")
 	}
+	for name, server := range c.MCPDownstreamServers {
+		if server.Command == "" && server.URL == "" {
+			return fmt.Errorf("mcp downstream server %q must set command or url", name)
+		}
+		if strings.Contains(name, "__") {
+			return fmt.Errorf("mcp downstream server name %q must not contain \"__\"", name)
+		}
+	}
+	if err := i18n.Validate(c.Locale); err != nil {
+		return err
+	}
+	if c.MaxConcurrentSessions < 0 {
+		return fmt.Errorf("max_concurrent_sessions must not be negative")
+	}
+	for name, budget := range c.CostBudgets {
+		switch budget.Period {
+		case CostBudgetPeriodDay, CostBudgetPeriodWeek, CostBudgetPeriodMonth:
+		default:
+			return fmt.Errorf("cost budget %q has unknown period %q", name, budget.Period)
+		}
+		if budget.LimitUSD <= 0 {
+			return fmt.Errorf("cost budget %q must set a positive limit_usd", name)
+		}
+	}
+	for template, policy := range c.RetryPolicies {
+		if policy.MaxAttempts < 0 {
+			return fmt.Errorf("retry policy %q: max_attempts must not be negative", template)
+		}
+		if _, err := policy.Delay(1); err != nil {
+			return fmt.Errorf("retry policy %q: %w", template, err)
+		}
+		if policy.Mode != "" && policy.Mode != RetryModeResume && policy.Mode != RetryModeFresh {
+			return fmt.Errorf("retry policy %q has unknown mode %q", template, policy.Mode)
+		}
+	}
+	if _, err := c.Recovery.MaxAgeDuration(); err != nil {
+		return fmt.Errorf("recovery: %w", err)
+	}
+	for name, digest := range c.Digests {
+		if _, _, err := digest.SendTime(); err != nil {
+			return fmt.Errorf("digest %q: %w", name, err)
+		}
+	}
+	for name, task := range c.Maintenance {
+		if task.Interval == "" {
+			continue
+		}
+		if interval, err := time.ParseDuration(task.Interval); err != nil || interval <= 0 {
+			return fmt.Errorf("maintenance task %q: interval must be a positive duration such as 1h", name)
+		}
+	}
+	for name, provider := range c.LLM.Providers {
+		switch provider.Type {
+		case LLMProviderAnthropic, LLMProviderOpenAI, LLMProviderClaudeCode:
+		default:
+			return fmt.Errorf("llm provider %q has unknown type %q", name, provider.Type)
+		}
+		if provider.Type == LLMProviderOpenAI && provider.BaseURL == "" {
+			return fmt.Errorf("llm provider %q must set base_url", name)
+		}
+	}
+	for task, routes := range c.LLM.Routes {
+		for _, route := range routes {
+			if route.Provider == "" || route.Model == "" {
+				return fmt.Errorf("llm route for %q must set provider and model", task)
+			}
+		}
+	}
+	if c.Embeddings.Model != "" && c.Embeddings.Provider == "" {
+		return fmt.Errorf("embeddings must set provider")
+	}
+	if (c.Transcription.Model != "" || c.Transcription.Language != "") && c.Transcription.Provider == "" {
+		return fmt.Errorf("transcription must set provider")
+	}
+	for _, mapping := range c.PathMappings {
+		if !filepath.IsAbs(mapping.From) || !filepath.IsAbs(mapping.To) {
+			return fmt.Errorf("path mapping %q -> %q must use absolute paths", mapping.From, mapping.To)
+		}
+	}
+	for name, tracker := range c.Trackers {
+		if tracker.Type != TrackerLinear && tracker.Type != TrackerAsana {
+			return fmt.Errorf("tracker %q has unknown type %q", name, tracker.Type)
+		}
+		if tracker.APIKeyEnv == "" {
+			return fmt.Errorf("tracker %q must set api_key_env", name)
+		}
+	}
+	switch c.Escalation.Provider {
+	case "":
+	case EscalationPagerDuty, EscalationOpsgenie:
+		if c.Escalation.KeyEnv == "" {
+			return fmt.Errorf("escalation provider %s requires key_env", c.Escalation.Provider)
+		}
+		if c.Escalation.SLASeconds < 0 {
+			return fmt.Errorf("escalation sla_seconds must not be negative")
+		}
+	default:
+		return fmt.Errorf("unknown escalation provider %q", c.Escalation.Provider)
+	}
+	for repo, analyzers := range c.Analysis.Repositories {
+		if repo != "*" && !filepath.IsAbs(repo) {
+			return fmt.Errorf("analysis repository %q must be an absolute path or *", repo)
+		}
+		for _, analyzer := range analyzers {
+			if analyzer.Name == "" || len(analyzer.Command) == 0 {
+				return fmt.Errorf("analyzers for %q must set name and command", repo)
+			}
+			switch analyzer.Severity {
+			case "", AnalyzerSeverityError, AnalyzerSeverityWarning:
+			default:
+				return fmt.Errorf("analyzer %q has unknown severity %q", analyzer.Name, analyzer.Severity)
+			}
+			if analyzer.Timeout != "" {
+				if _, err := time.ParseDuration(analyzer.Timeout); err != nil {
+					return fmt.Errorf("analyzer %q has invalid timeout: %w", analyzer.Name, err)
+				}
+			}
+		}
+	}
+	for repo, formatters := range c.Analysis.Formatters {
+		if repo != "*" && !filepath.IsAbs(repo) {
+			return fmt.Errorf("formatter repository %q must be an absolute path or *", repo)
+		}
+		for _, formatter := range formatters {
+			if formatter.Name == "" || len(formatter.Command) == 0 {
+				return fmt.Errorf("formatters for %q must set name and command", repo)
+			}
+			if formatter.Timeout != "" {
+				if _, err := time.ParseDuration(formatter.Timeout); err != nil {
+					return fmt.Errorf("formatter %q has invalid timeout: %w", formatter.Name, err)
+				}
+			}
+		}
+	}
+	switch c.Provenance.Mode {
+	case "", ProvenanceTrailer, ProvenanceNote:
+	default:
+		return fmt.Errorf("provenance mode must be %q or %q, got %q", ProvenanceTrailer, ProvenanceNote, c.Provenance.Mode)
+	}
+	if c.Provenance.NotesRef != "" && !strings.HasPrefix(c.Provenance.NotesRef, "refs/notes/") {
+		return fmt.Errorf("provenance notes_ref must be under refs/notes/, got %q", c.Provenance.NotesRef)
+	}
+	if c.HTTPCapture.SampleRate < 0 || c.HTTPCapture.SampleRate > 1 {
+		return fmt.Errorf("http_capture sample_rate must be between 0 and 1, got %v", c.HTTPCapture.SampleRate)
+	}
+	if c.HTTPCapture.BufferSize < 0 || c.HTTPCapture.MaxBodySize < 0 {
+		return fmt.Errorf("http_capture buffer_size and max_body_size can't be negative")
+	}
+	for _, route := range c.HTTPCapture.Routes {
+		if _, err := path.Match(route, ""); err != nil {
+			return fmt.Errorf("http_capture route %q is not a valid pattern: %w", route, err)
+		}
+	}
+	if c.PromptLog.BufferSize < 0 || c.PromptLog.MaxTextSize < 0 {
+		return fmt.Errorf("prompt_log buffer_size and max_text_size can't be negative")
+	}
+	for _, pattern := range c.PromptLog.RedactPatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("prompt_log redact pattern %q is invalid: %w", pattern, err)
+		}
+	}
+	switch c.PromptInjection.Mode {
+	case "", PromptInjectionStrip, PromptInjectionFlag, PromptInjectionOff:
+	default:
+		return fmt.Errorf("prompt_injection mode must be %q, %q or %q, got %q",
+			PromptInjectionStrip, PromptInjectionFlag, PromptInjectionOff, c.PromptInjection.Mode)
+	}
+	for _, pattern := range c.PromptInjection.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("prompt_injection pattern %q is invalid: %w", pattern, err)
+		}
+	}
+	policies := []ContentSafetyPolicy{c.ContentSafety.ContentSafetyPolicy}
+	for _, workspace := range c.ContentSafety.Workspaces {
+		if !filepath.IsAbs(workspace.Path) {
+			return fmt.Errorf("content_safety workspace path must be absolute, got %q", workspace.Path)
+		}
+		policies = append(policies, workspace.ContentSafetyPolicy)
+	}
+	for _, policy := range policies {
+		for _, pattern := range policy.Patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("content_safety pattern %q is invalid: %w", pattern, err)
+			}
+		}
+	}
+	for _, helper := range c.GitCredentials.Helpers {
+		if strings.TrimSpace(helper) == "" {
+			return fmt.Errorf("git_credentials helpers can't be empty")
+		}
+	}
+	if c.Scratch.MaxFileSize < 0 || c.Scratch.MaxSessionSize < 0 {
+		return fmt.Errorf("scratch max_file_size and max_session_size can't be negative")
+	}
+	if c.DiskGuard.MaxRepoGrowth < 0 {
+		return fmt.Errorf("disk_guard max_repo_growth can't be negative")
+	}
+	if c.Artifacts.RetentionDays < -1 || c.Artifacts.MaxSize < 0 {
+		return fmt.Errorf("artifacts retention_days must be -1 or more and max_size can't be negative")
+	}
+	if c.Artifacts.LinkTTL != "" {
+		if ttl, err := time.ParseDuration(c.Artifacts.LinkTTL); err != nil || ttl <= 0 {
+			return fmt.Errorf("artifacts link_ttl %q is not a positive duration", c.Artifacts.LinkTTL)
+		}
+	}
+	if c.Sharing.Listen != "" {
+		if _, _, err := net.SplitHostPort(c.Sharing.Listen); err != nil {
+			return fmt.Errorf("sharing listen %q is not a host:port: %w", c.Sharing.Listen, err)
+		}
+	}
+	for name, value := range map[string]string{"default_ttl": c.Sharing.DefaultTTL, "max_ttl": c.Sharing.MaxTTL} {
+		if value == "" {
+			continue
+		}
+		if ttl, err := time.ParseDuration(value); err != nil || ttl <= 0 {
+			return fmt.Errorf("sharing %s %q is not a positive duration", name, value)
+		}
+	}
+	for _, event := range c.Announce.Events {
+		switch event {
+		case AnnounceApproval, AnnounceSessionCompleted, AnnounceSessionFailed:
+		default:
+			return fmt.Errorf("announce has unknown event %q", event)
+		}
+	}
+	if _, err := c.Announce.QuietHours.Contains(time.Now()); err != nil {
+		return fmt.Errorf("announce quiet_hours: %w", err)
+	}
+	for name, prefs := range c.Notifications {
+		for _, window := range prefs.MuteWindows {
+			if _, err := window.Contains(time.Now()); err != nil || window.Start == "" {
+				return fmt.Errorf("notifications %q: mute window %s-%s must have HH:MM start and end", name, window.Start, window.End)
+			}
+		}
+		if prefs.Batch != "" {
+			if batch, err := time.ParseDuration(prefs.Batch); err != nil || batch <= 0 {
+				return fmt.Errorf("notifications %q: batch %q is not a positive duration", name, prefs.Batch)
+			}
+		}
+		if prefs.MinSeverity != "" && SeverityRank(prefs.MinSeverity) < 0 {
+			return fmt.Errorf("notifications %q: unknown min_severity %q", name, prefs.MinSeverity)
+		}
+	}
+	if subject := c.WebPush.Subject; subject != "" && !strings.HasPrefix(subject, "mailto:") && !strings.HasPrefix(subject, "https://") {
+		return fmt.Errorf("web_push subject must be a mailto: or https: URL")
+	}
+	if c.ApprovalUndoSeconds < 0 || c.ApprovalUndoSeconds > MaxApprovalUndoSeconds {
+		return fmt.Errorf("approval_undo_seconds must be between 0 and %d", MaxApprovalUndoSeconds)
+	}
+	if c.ApprovalJustificationRisk < 0 || c.ApprovalJustificationRisk > 1 {
+		return fmt.Errorf("approval_justification_risk must be between 0 and 1")
+	}
+	if c.CloudRelay.Enabled && c.APIKey == "" {
+		return fmt.Errorf("cloud_relay requires api_key or HUMANLAYER_API_KEY")
+	}
+	if c.CloudRelay.PollSeconds < 0 {
+		return fmt.Errorf("cloud_relay poll_seconds must not be negative")
+	}
+	switch c.Federation.Role {
+	case "":
+	case FederationHub, FederationMember:
+		if c.Federation.TokenEnv == "" {
+			return fmt.Errorf("federation requires token_env")
+		}
+		if c.Federation.Role == FederationMember && (c.Federation.HubURL == "" || c.Federation.AdvertiseURL == "") {
+			return fmt.Errorf("federation members require hub_url and advertise_url")
+		}
+	default:
+		return fmt.Errorf("unknown federation role %q", c.Federation.Role)
+	}
+	if c.CI.Enabled && c.ApprovalPolicyPath == "" {
+		return fmt.Errorf("ci mode requires approval_policy_path")
+	}
+	for name, repo := range c.GitHub.Repositories {
+		if strings.Count(name, "/") != 1 {
+			return fmt.Errorf("github repository %q must be owner/name", name)
+		}
+		if !filepath.IsAbs(repo.WorkingDir) {
+			return fmt.Errorf("github repository %q must set an absolute working_dir", name)
+		}
+	}
+	for name, hook := range c.Hooks {
+		if !hookName.MatchString(name) {
+			return fmt.Errorf("hook name %q may only contain letters, digits, '-' and '_'", name)
+		}
+		if hook.Secret == "" {
+			return fmt.Errorf("hook %q must set a secret", name)
+		}
+		if !filepath.IsAbs(hook.WorkingDir) {
+			return fmt.Errorf("hook %q must set an absolute working_dir", name)
+		}
+		if hook.Query == "" && hook.QueryField == "" {
+			return fmt.Errorf("hook %q must set query or query_field", name)
+		}
+	}
+	if c.Email.Address != "" {
+		if c.Email.InboundSecret == "" {
+			return fmt.Errorf("email requires inbound_secret")
+		}
+		if len(c.Email.AllowedSenders) == 0 {
+			return fmt.Errorf("email requires allowed_senders")
+		}
+		if len(c.Email.NotifyTo) > 0 && c.Email.SMTPHost == "" {
+			return fmt.Errorf("email notifications require smtp_host")
+		}
+		if c.Email.WorkingDir != "" && !filepath.IsAbs(c.Email.WorkingDir) {
+			return fmt.Errorf("email working_dir must be absolute")
+		}
+		if c.Email.TokenTTLHours < 0 {
+			return fmt.Errorf("email token_ttl_hours can't be negative")
+		}
+	}
+	if c.Twilio.AccountSID != "" {
+		if c.Twilio.From == "" && c.Twilio.WhatsAppFrom == "" {
+			return fmt.Errorf("twilio requires from or whatsapp_from")
+		}
+		if c.Twilio.WebhookURL == "" {
+			return fmt.Errorf("twilio requires webhook_url")
+		}
+		for number := range c.Twilio.Users {
+			if strings.HasPrefix(number, "whatsapp:") && c.Twilio.WhatsAppFrom == "" {
+				return fmt.Errorf("twilio user %q needs whatsapp_from", number)
+			}
+			if !strings.HasPrefix(number, "whatsapp:") && c.Twilio.From == "" {
+				return fmt.Errorf("twilio user %q needs from", number)
+			}
+		}
+	}
+	switch c.Containers.Runtime {
+	case "", "docker", "podman":
+	default:
+		return fmt.Errorf("unknown container runtime %q", c.Containers.Runtime)
+	}
+	for name, plugin := range c.Plugins {
+		if plugin.Command == "" {
+			return fmt.Errorf("plugin %q must set command", name)
+		}
+		if len(plugin.Capabilities) == 0 {
+			return fmt.Errorf("plugin %q must declare at least one capability", name)
+		}
+		for _, capability := range plugin.Capabilities {
+			switch capability {
+			case PluginCapabilityNotify, PluginCapabilityScoreRisk, PluginCapabilityRedact:
+			default:
+				return fmt.Errorf("plugin %q has unknown capability %q", name, capability)
+			}
+		}
+		if plugin.Timeout != "" {
+			if _, err := time.ParseDuration(plugin.Timeout); err != nil {
+				return fmt.Errorf("plugin %q has invalid timeout: %w", name, err)
+			}
+		}
+	}
 	return nil
 }
 
@@ -177,7 +1633,160 @@ func Save(cfg *Config) error {
 	v.Set("version_override", cfg.VersionOverride)
 	v.Set("http_port", cfg.HTTPPort)
 	v.Set("http_host", cfg.HTTPHost)
+	if cfg.HTTPDisabled {
+		v.Set("http_disabled", cfg.HTTPDisabled)
+	}
 	v.Set("claude_path", cfg.ClaudePath)
+	if cfg.ApprovalPolicyPath != "" {
+		v.Set("approval_policy_path", cfg.ApprovalPolicyPath)
+	}
+	if cfg.ShadowApprovalPolicyPath != "" {
+		v.Set("shadow_approval_policy_path", cfg.ShadowApprovalPolicyPath)
+	}
+	if cfg.Locale != "" {
+		v.Set("locale", cfg.Locale)
+	}
+	if cfg.PromptsDir != "" {
+		v.Set("prompts_dir", cfg.PromptsDir)
+	}
+	if cfg.SessionSummariesDisabled {
+		v.Set("session_summaries_disabled", cfg.SessionSummariesDisabled)
+	}
+	if len(cfg.MCPDownstreamServers) > 0 {
+		v.Set("mcp_downstream_servers", cfg.MCPDownstreamServers)
+	}
+	if cfg.DefaultSessionBudget != (SessionBudget{}) {
+		v.Set("default_session_budget", cfg.DefaultSessionBudget)
+	}
+	if cfg.MaxConcurrentSessions != 0 {
+		v.Set("max_concurrent_sessions", cfg.MaxConcurrentSessions)
+	}
+	if len(cfg.CostBudgets) > 0 {
+		v.Set("cost_budgets", cfg.CostBudgets)
+	}
+	if len(cfg.Digests) > 0 {
+		v.Set("digests", cfg.Digests)
+	}
+	if len(cfg.Maintenance) > 0 {
+		v.Set("maintenance", cfg.Maintenance)
+	}
+	if len(cfg.RetryPolicies) > 0 {
+		v.Set("retry_policies", cfg.RetryPolicies)
+	}
+	if cfg.Recovery.AutoResume {
+		v.Set("recovery", cfg.Recovery)
+	}
+	if len(cfg.LLM.Providers) > 0 || len(cfg.LLM.Routes) > 0 {
+		v.Set("llm", cfg.LLM)
+	}
+	if cfg.DecisionLog != (DecisionLogConfig{}) {
+		v.Set("decision_log", cfg.DecisionLog)
+	}
+	if cfg.Embeddings.Model != "" {
+		v.Set("embeddings", cfg.Embeddings)
+	}
+	if cfg.Transcription != (TranscriptionConfig{}) {
+		v.Set("transcription", cfg.Transcription)
+	}
+	if len(cfg.Plugins) > 0 {
+		v.Set("plugins", cfg.Plugins)
+	}
+	if len(cfg.PathMappings) > 0 {
+		v.Set("path_mappings", cfg.PathMappings)
+	}
+	if cfg.Containers.Image != "" || len(cfg.Containers.TemplateImages) > 0 {
+		v.Set("containers", cfg.Containers)
+	}
+	if len(cfg.Trackers) > 0 {
+		v.Set("trackers", cfg.Trackers)
+	}
+	if cfg.CI.Enabled {
+		v.Set("ci", cfg.CI)
+	}
+	if cfg.Escalation.Provider != "" {
+		v.Set("escalation", cfg.Escalation)
+	}
+	if cfg.GitHub.WebhookSecret != "" || len(cfg.GitHub.Repositories) > 0 {
+		v.Set("github", cfg.GitHub)
+	}
+	if cfg.DependencyCheck.Enabled {
+		v.Set("dependency_check", cfg.DependencyCheck)
+	}
+	if len(cfg.Owners.Channels) > 0 || cfg.Owners.RequestReviewers {
+		v.Set("owners", cfg.Owners)
+	}
+	if len(cfg.Analysis.Repositories) > 0 || len(cfg.Analysis.Formatters) > 0 {
+		v.Set("analysis", cfg.Analysis)
+	}
+	if cfg.LFS.LargeFileSize != 0 || cfg.LFS.Block {
+		v.Set("lfs", cfg.LFS)
+	}
+	if cfg.Provenance.Mode != "" {
+		v.Set("provenance", cfg.Provenance)
+	}
+	if cfg.HTTPCapture.Enabled {
+		v.Set("http_capture", cfg.HTTPCapture)
+	}
+	if cfg.PromptLog.Enabled {
+		v.Set("prompt_log", cfg.PromptLog)
+	}
+	if cfg.PromptInjection.Mode != "" || len(cfg.PromptInjection.Patterns) > 0 {
+		v.Set("prompt_injection", cfg.PromptInjection)
+	}
+	if cfg.ContentSafety.Enabled || len(cfg.ContentSafety.Workspaces) > 0 {
+		v.Set("content_safety", cfg.ContentSafety)
+	}
+	if len(cfg.GitCredentials.Helpers) > 0 || cfg.GitCredentials.KeyFile != "" || cfg.GitCredentials.SSHAuthSock != "" || cfg.GitCredentials.ForwardAgent {
+		v.Set("git_credentials", cfg.GitCredentials)
+	}
+	if cfg.Scratch.MaxFileSize != 0 || cfg.Scratch.MaxSessionSize != 0 {
+		v.Set("scratch", cfg.Scratch)
+	}
+	if cfg.DiskGuard != (DiskGuardConfig{}) {
+		v.Set("disk_guard", cfg.DiskGuard)
+	}
+	if cfg.Artifacts != (ArtifactsConfig{}) {
+		v.Set("artifacts", cfg.Artifacts)
+	}
+	if cfg.Sharing != (SharingConfig{}) {
+		v.Set("sharing", cfg.Sharing)
+	}
+	if cfg.Announce.Enabled {
+		v.Set("announce", cfg.Announce)
+	}
+	if cfg.WebPush.Subject != "" {
+		v.Set("web_push", cfg.WebPush)
+	}
+	if len(cfg.Notifications) > 0 {
+		v.Set("notifications", cfg.Notifications)
+	}
+	if cfg.ApprovalUndoSeconds != 0 {
+		v.Set("approval_undo_seconds", cfg.ApprovalUndoSeconds)
+	}
+	if cfg.ExpandDenials {
+		v.Set("expand_denials", cfg.ExpandDenials)
+	}
+	if cfg.ApprovalJustificationRisk != 0 {
+		v.Set("approval_justification_risk", cfg.ApprovalJustificationRisk)
+	}
+	if cfg.CloudRelay.Enabled {
+		v.Set("cloud_relay", cfg.CloudRelay)
+	}
+	if cfg.Federation.Role != "" {
+		v.Set("federation", cfg.Federation)
+	}
+	if cfg.MDNS.Enabled {
+		v.Set("mdns", cfg.MDNS)
+	}
+	if len(cfg.Hooks) > 0 {
+		v.Set("hooks", cfg.Hooks)
+	}
+	if cfg.Email.Address != "" {
+		v.Set("email", cfg.Email)
+	}
+	if cfg.Twilio.AccountSID != "" {
+		v.Set("twilio", cfg.Twilio)
+	}
 
 	// Set config file path explicitly
 	configFile := filepath.Join(configDir, "humanlayer.json")
@@ -0,0 +1,19 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemapPath(t *testing.T) {
+	mappings := []PathMapping{
+		{From: "/Users/alice", To: "/home/bob"},
+		{From: "/Users/alice/code/", To: "/src"},
+	}
+	assert.Equal(t, "/src/repo", RemapPath(mappings, "/Users/alice/code/repo"))
+	assert.Equal(t, "/home/bob/notes", RemapPath(mappings, "/Users/alice/notes"))
+	assert.Equal(t, "/home/bob", RemapPath(mappings, "/Users/alice"))
+	assert.Equal(t, "/Users/alicex/repo", RemapPath(mappings, "/Users/alicex/repo"))
+	assert.Equal(t, "", RemapPath(mappings, ""))
+}
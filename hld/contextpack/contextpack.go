@@ -0,0 +1,476 @@
+// Package contextpack assembles context for a session launch: files
+// relevant to the query (by grep and import graph), related past sessions
+// and open issues. Every step is recorded in the pack so users can see how
+// it was built.
+package contextpack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/humanlayer/humanlayer/hld/similar"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// Sources a request can skip
+const (
+	SourceFiles    = "files"
+	SourceSessions = "sessions"
+	SourceIssues   = "issues"
+)
+
+const (
+	defaultMaxFiles = 15
+	maxKeywords     = 8
+	maxSessions     = 5
+	maxIssues       = 5
+	// importRoots is how many of the best grep matches have their imports followed
+	importRoots = 5
+	// relatedSessionAge bounds how far back keyword matching looks for sessions
+	relatedSessionAge = 90 * 24 * time.Hour
+	commandTimeout    = 15 * time.Second
+)
+
+// Request describes the launch a pack is built for
+type Request struct {
+	Query      string   `json:"query"`
+	WorkingDir string   `json:"working_dir"`
+	MaxFiles   int      `json:"max_files,omitempty"`
+	Skip       []string `json:"skip,omitempty"` // files, sessions or issues
+}
+
+// File is a file in the working directory relevant to the query
+type File struct {
+	Path   string `json:"path"` // Relative to the working directory
+	Reason string `json:"reason"`
+	Score  int    `json:"score"`
+}
+
+// RelatedSession is a past session that worked on something similar
+type RelatedSession struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title,omitempty"`
+	Query     string    `json:"query"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	Reason    string    `json:"reason"`
+}
+
+// Issue is an open issue in the repository's GitHub project
+type Issue struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	URL    string `json:"url"`
+}
+
+// Step records one assembly step for transparency
+type Step struct {
+	Name       string `json:"name"`
+	Detail     string `json:"detail"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Pack is the assembled context
+type Pack struct {
+	ID         string           `json:"id"`
+	Query      string           `json:"query"`
+	WorkingDir string           `json:"working_dir"`
+	Keywords   []string         `json:"keywords"`
+	Files      []File           `json:"files"`
+	Sessions   []RelatedSession `json:"sessions"`
+	Issues     []Issue          `json:"issues"`
+	Steps      []Step           `json:"steps"`
+	CreatedAt  time.Time        `json:"created_at"`
+}
+
+// Builder assembles and stores context packs
+type Builder struct {
+	store store.ConversationStore
+	packs store.ContextPackStore
+	// index finds related sessions by embedding; nil falls back to keywords
+	index *similar.Index
+}
+
+// NewBuilder creates a context pack builder. index may be nil.
+func NewBuilder(s store.ConversationStore, packs store.ContextPackStore, index *similar.Index) *Builder {
+	return &Builder{store: s, packs: packs, index: index}
+}
+
+// step runs fn as a named step, recording its detail, duration and error
+func (p *Pack) step(name string, fn func() (string, error)) {
+	start := time.Now()
+	detail, err := fn()
+	step := Step{Name: name, Detail: detail, DurationMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		step.Error = err.Error()
+	}
+	p.Steps = append(p.Steps, step)
+	slog.Info("context pack step", "pack_id", p.ID, "step", name, "detail", detail, "error", step.Error, "duration_ms", step.DurationMs)
+}
+
+// Build assembles a pack for req and stores it. Failing steps are recorded
+// in the pack rather than failing the build.
+func (b *Builder) Build(ctx context.Context, req Request) (*Pack, error) {
+	if strings.TrimSpace(req.Query) == "" || req.WorkingDir == "" {
+		return nil, fmt.Errorf("query and working_dir are required")
+	}
+	maxFiles := req.MaxFiles
+	if maxFiles <= 0 {
+		maxFiles = defaultMaxFiles
+	}
+	skip := make(map[string]bool)
+	for _, source := range req.Skip {
+		skip[source] = true
+	}
+
+	pack := &Pack{
+		ID:         uuid.New().String(),
+		Query:      req.Query,
+		WorkingDir: req.WorkingDir,
+		Files:      []File{},
+		Sessions:   []RelatedSession{},
+		Issues:     []Issue{},
+		CreatedAt:  time.Now(),
+	}
+	pack.step("keywords", func() (string, error) {
+		pack.Keywords = Keywords(req.Query)
+		return strings.Join(pack.Keywords, ", "), nil
+	})
+
+	if !skip[SourceFiles] {
+		var scores map[string]*File
+		pack.step("grep", func() (string, error) {
+			var err error
+			scores, err = grepFiles(ctx, req.WorkingDir, pack.Keywords)
+			return fmt.Sprintf("%d files matched", len(scores)), err
+		})
+		ranked := rank(scores)
+		pack.step("imports", func() (string, error) {
+			added := followImports(req.WorkingDir, ranked, scores)
+			return fmt.Sprintf("%d files added from imports", added), nil
+		})
+		pack.Files = rank(scores)
+		if len(pack.Files) > maxFiles {
+			pack.Files = pack.Files[:maxFiles]
+		}
+	}
+	if !skip[SourceSessions] {
+		pack.step("sessions", func() (string, error) {
+			var err error
+			pack.Sessions, err = b.relatedSessions(ctx, req, pack.Keywords)
+			return fmt.Sprintf("%d related sessions", len(pack.Sessions)), err
+		})
+	}
+	if !skip[SourceIssues] {
+		pack.step("issues", func() (string, error) {
+			var detail string
+			var err error
+			pack.Issues, detail, err = openIssues(ctx, req.WorkingDir, pack.Keywords)
+			return detail, err
+		})
+	}
+
+	content, err := json.Marshal(pack)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode context pack: %w", err)
+	}
+	if err := b.packs.CreateContextPack(ctx, &store.ContextPack{
+		ID:         pack.ID,
+		Query:      pack.Query,
+		WorkingDir: pack.WorkingDir,
+		Content:    content,
+		CreatedAt:  pack.CreatedAt,
+	}); err != nil {
+		return nil, err
+	}
+	return pack, nil
+}
+
+// Load returns a stored pack
+func Load(ctx context.Context, s store.ContextPackStore, id string) (*Pack, error) {
+	stored, err := s.GetContextPack(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var pack Pack
+	if err := json.Unmarshal(stored.Content, &pack); err != nil {
+		return nil, fmt.Errorf("failed to decode context pack: %w", err)
+	}
+	return &pack, nil
+}
+
+var (
+	wordPattern = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_./-]*[A-Za-z0-9_]`)
+	stopwords   = map[string]bool{
+		"the": true, "and": true, "for": true, "with": true, "this": true, "that": true, "from": true,
+		"into": true, "when": true, "then": true, "should": true, "would": true, "could": true, "make": true,
+		"add": true, "fix": true, "use": true, "using": true, "please": true, "can": true, "you": true,
+		"are": true, "not": true, "all": true, "any": true, "its": true, "our": true, "have": true, "has": true,
+		"what": true, "why": true, "how": true, "does": true, "need": true, "also": true, "some": true,
+		"there": true, "where": true, "which": true, "able": true, "change": true, "update": true,
+	}
+)
+
+// Keywords picks the words of a query worth searching for: identifiers and
+// paths first, then other words, longest first
+func Keywords(query string) []string {
+	seen := make(map[string]bool)
+	var identifiers, words []string
+	for _, word := range wordPattern.FindAllString(query, -1) {
+		lower := strings.ToLower(word)
+		if len(word) < 3 || stopwords[lower] || seen[lower] {
+			continue
+		}
+		seen[lower] = true
+		if strings.ContainsAny(word, "_./") || word != lower && word != strings.ToUpper(word[:1])+lower[1:] {
+			identifiers = append(identifiers, word)
+		} else {
+			words = append(words, lower)
+		}
+	}
+	sort.SliceStable(words, func(i, j int) bool { return len(words[i]) > len(words[j]) })
+	keywords := append(identifiers, words...)
+	if len(keywords) > maxKeywords {
+		keywords = keywords[:maxKeywords]
+	}
+	return keywords
+}
+
+func run(ctx context.Context, dir, name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return string(out), fmt.Errorf("%s %s: %w: %s", name, args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+// grepFiles scores tracked files by how many keywords they mention, and
+// more for keywords in their path
+func grepFiles(ctx context.Context, dir string, keywords []string) (map[string]*File, error) {
+	scores := make(map[string]*File)
+	if len(keywords) == 0 {
+		return scores, nil
+	}
+	listing, err := run(ctx, dir, "git", "ls-files")
+	if err != nil {
+		return scores, err
+	}
+	matched := make(map[string][]string)
+	for _, path := range strings.Split(strings.TrimSpace(listing), "\n") {
+		lower := strings.ToLower(filepath.Base(path))
+		for _, keyword := range keywords {
+			if strings.Contains(lower, strings.ToLower(filepath.Base(keyword))) {
+				file := score(scores, path)
+				file.Score += 15
+				matched[path] = append(matched[path], "name matches "+keyword)
+			}
+		}
+	}
+	for _, keyword := range keywords {
+		// Exit status 1 means no matches
+		out, _ := run(ctx, dir, "git", "grep", "-I", "-i", "-c", "-F", "-e", keyword)
+		for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+			i := strings.LastIndex(line, ":")
+			if i <= 0 {
+				continue
+			}
+			count, err := strconv.Atoi(line[i+1:])
+			if err != nil {
+				continue
+			}
+			path := line[:i]
+			file := score(scores, path)
+			file.Score += 10 + min(count, 5)
+			matched[path] = append(matched[path], "mentions "+keyword)
+		}
+	}
+	for path, reasons := range matched {
+		scores[path].Reason = strings.Join(reasons, ", ")
+	}
+	return scores, nil
+}
+
+func score(scores map[string]*File, path string) *File {
+	file, ok := scores[path]
+	if !ok {
+		file = &File{Path: path}
+		scores[path] = file
+	}
+	return file
+}
+
+// rank orders files best first
+func rank(scores map[string]*File) []File {
+	files := make([]File, 0, len(scores))
+	for _, file := range scores {
+		files = append(files, *file)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].Score != files[j].Score {
+			return files[i].Score > files[j].Score
+		}
+		return files[i].Path < files[j].Path
+	})
+	return files
+}
+
+// followImports adds the local files imported by the best matches, scored
+// just below the file that imports them, and returns how many were added
+func followImports(dir string, ranked []File, scores map[string]*File) int {
+	added := 0
+	for i, file := range ranked {
+		if i >= importRoots {
+			break
+		}
+		for _, imported := range localImports(dir, file.Path) {
+			if _, ok := scores[imported]; ok {
+				continue
+			}
+			scores[imported] = &File{Path: imported, Reason: "imported by " + file.Path, Score: file.Score / 2}
+			added++
+		}
+	}
+	return added
+}
+
+// relatedSessions finds past sessions in the same working directory tree,
+// by embedding when available and otherwise by shared keywords
+func (b *Builder) relatedSessions(ctx context.Context, req Request, keywords []string) ([]RelatedSession, error) {
+	related := []RelatedSession{}
+	if b.index != nil {
+		matches, err := b.index.Search(ctx, req.Query, maxSessions)
+		if err != nil {
+			return related, err
+		}
+		for _, match := range matches {
+			related = append(related, RelatedSession{
+				ID: match.SessionID, Title: match.Title, Query: match.Query, Status: match.Status,
+				CreatedAt: match.CreatedAt, Reason: fmt.Sprintf("similar (score %.2f)", match.Score),
+			})
+		}
+		return related, nil
+	}
+
+	sessions, err := b.store.ListSessions(ctx)
+	if err != nil {
+		return related, err
+	}
+	type scored struct {
+		session *store.Session
+		matched []string
+	}
+	var candidates []scored
+	cutoff := time.Now().Add(-relatedSessionAge)
+	for _, sess := range sessions {
+		if sess.CreatedAt.Before(cutoff) || !sameTree(sess.WorkingDir, req.WorkingDir) {
+			continue
+		}
+		text := strings.ToLower(sess.Title + " " + sess.Query + " " + sess.CompletionSummary)
+		var matched []string
+		for _, keyword := range keywords {
+			if strings.Contains(text, strings.ToLower(keyword)) {
+				matched = append(matched, keyword)
+			}
+		}
+		if len(matched) > 0 {
+			candidates = append(candidates, scored{sess, matched})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if len(candidates[i].matched) != len(candidates[j].matched) {
+			return len(candidates[i].matched) > len(candidates[j].matched)
+		}
+		return candidates[i].session.CreatedAt.After(candidates[j].session.CreatedAt)
+	})
+	for i, c := range candidates {
+		if i >= maxSessions {
+			break
+		}
+		related = append(related, RelatedSession{
+			ID: c.session.ID, Title: c.session.Title, Query: c.session.Query, Status: c.session.Status,
+			CreatedAt: c.session.CreatedAt, Reason: "mentions " + strings.Join(c.matched, ", "),
+		})
+	}
+	return related, nil
+}
+
+// sameTree reports whether one directory contains the other
+func sameTree(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	a, b = filepath.Clean(a), filepath.Clean(b)
+	sep := string(filepath.Separator)
+	return a == b || strings.HasPrefix(a, b+sep) || strings.HasPrefix(b, a+sep)
+}
+
+// openIssues searches the repository's open GitHub issues with the gh CLI.
+// A missing or unauthenticated gh skips the step.
+func openIssues(ctx context.Context, dir string, keywords []string) ([]Issue, string, error) {
+	issues := []Issue{}
+	if len(keywords) == 0 {
+		return issues, "no keywords to search for", nil
+	}
+	if _, err := exec.LookPath("gh"); err != nil {
+		return issues, "gh CLI not installed; skipped", nil
+	}
+	out, err := run(ctx, dir, "gh", "issue", "list", "--state", "open", "--limit", strconv.Itoa(maxIssues),
+		"--search", strings.Join(keywords, " OR "), "--json", "number,title,url")
+	if err != nil {
+		return issues, "gh issue list failed", err
+	}
+	if err := json.Unmarshal([]byte(out), &issues); err != nil {
+		return []Issue{}, "gh issue list failed", fmt.Errorf("failed to parse gh output: %w", err)
+	}
+	return issues, fmt.Sprintf("%d open issues", len(issues)), nil
+}
+
+// Render formats the pack for a session's system prompt
+func (p *Pack) Render() string {
+	var b strings.Builder
+	b.WriteString("Context gathered for this task before the session started. Use it as a starting point; it may be incomplete.\n")
+	if len(p.Files) > 0 {
+		b.WriteString("\nRelevant files:\n")
+		for _, file := range p.Files {
+			fmt.Fprintf(&b, "- %s (%s)\n", file.Path, file.Reason)
+		}
+	}
+	if len(p.Sessions) > 0 {
+		b.WriteString("\nRelated past sessions:\n")
+		for _, sess := range p.Sessions {
+			title := sess.Title
+			if title == "" {
+				title = sess.Query
+			}
+			if len(title) > 200 {
+				title = title[:200] + "..."
+			}
+			fmt.Fprintf(&b, "- %s [%s, %s]\n", title, sess.Status, sess.CreatedAt.Format("2006-01-02"))
+		}
+	}
+	if len(p.Issues) > 0 {
+		b.WriteString("\nOpen issues:\n")
+		for _, issue := range p.Issues {
+			fmt.Fprintf(&b, "- #%d %s (%s)\n", issue.Number, issue.Title, issue.URL)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,109 @@
+package contextpack
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func initRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for path, content := range files {
+		full := filepath.Join(dir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0644))
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test"},
+		{"add", "."},
+		{"commit", "-q", "-m", "initial"},
+	} {
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	return dir
+}
+
+func TestKeywords(t *testing.T) {
+	assert.Equal(t, []string{"parseConfig", "config/loader.go", "timeout", "broken"},
+		Keywords("Fix the broken timeout in parseConfig and config/loader.go"))
+	assert.Empty(t, Keywords("fix it"))
+}
+
+func TestBuild(t *testing.T) {
+	dir := initRepo(t, map[string]string{
+		"go.mod":            "module example.com/app\n\ngo 1.24\n",
+		"main.go":           "package main\n\nimport \"example.com/app/retry\"\n\nfunc main() { retry.Backoff() }\n",
+		"retry/retry.go":    "package retry\n\nimport \"example.com/app/clock\"\n\n// Backoff waits before the next attempt\nfunc Backoff() { clock.Sleep() }\n",
+		"clock/clock.go":    "package clock\n\nfunc Sleep() {}\n",
+		"web/api.ts":        "import { get } from './http'\n\nexport const backoff = () => get('/retry')\n",
+		"web/http.ts":       "export const get = (path: string) => fetch(path)\n",
+		"docs/unrelated.md": "Nothing to see here\n",
+	})
+
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	for _, sess := range []*store.Session{
+		{ID: "related", RunID: "r1", Query: "Tune the retry backoff", WorkingDir: dir, Status: store.SessionStatusCompleted},
+		{ID: "other-repo", RunID: "r2", Query: "Tune the retry backoff", WorkingDir: "/elsewhere", Status: store.SessionStatusCompleted},
+		{ID: "unrelated", RunID: "r3", Query: "Write docs", WorkingDir: dir, Status: store.SessionStatusCompleted},
+	} {
+		sess.CreatedAt = time.Now()
+		sess.LastActivityAt = time.Now()
+		require.NoError(t, s.CreateSession(ctx, sess))
+	}
+
+	pack, err := NewBuilder(s, s, nil).Build(ctx, Request{
+		Query: "Make retry backoff configurable", WorkingDir: dir, Skip: []string{SourceIssues},
+	})
+	require.NoError(t, err)
+
+	paths := make(map[string]string)
+	for _, file := range pack.Files {
+		paths[file.Path] = file.Reason
+	}
+	assert.Contains(t, paths["retry/retry.go"], "name matches retry")
+	assert.Contains(t, paths["main.go"], "mentions retry")
+	assert.Equal(t, "imported by retry/retry.go", paths["clock/clock.go"])
+	assert.Equal(t, "imported by web/api.ts", paths["web/http.ts"])
+	assert.NotContains(t, paths, "docs/unrelated.md")
+
+	require.Len(t, pack.Sessions, 1)
+	assert.Equal(t, "related", pack.Sessions[0].ID)
+
+	var steps []string
+	for _, step := range pack.Steps {
+		steps = append(steps, step.Name)
+		assert.Empty(t, step.Error, step.Name)
+	}
+	assert.Equal(t, []string{"keywords", "grep", "imports", "sessions"}, steps)
+
+	loaded, err := Load(ctx, s, pack.ID)
+	require.NoError(t, err)
+	assert.Equal(t, pack.Files, loaded.Files)
+
+	rendered := loaded.Render()
+	assert.True(t, strings.Contains(rendered, "- retry/retry.go ("), rendered)
+	assert.Contains(t, rendered, "Tune the retry backoff [completed")
+}
+
+func TestBuildOutsideGitRepo(t *testing.T) {
+	s := store.NewInMemoryStore()
+	pack, err := NewBuilder(s, s, nil).Build(context.Background(), Request{
+		Query: "retry backoff", WorkingDir: t.TempDir(), Skip: []string{SourceIssues},
+	})
+	require.NoError(t, err, "a failing step is recorded, not returned")
+	assert.Empty(t, pack.Files)
+	assert.NotEmpty(t, pack.Steps[1].Error)
+}
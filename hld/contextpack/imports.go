@@ -0,0 +1,125 @@
+package contextpack
+
+import (
+	"bufio"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxFilesPerPackage caps how many files of an imported Go package are added
+const maxFilesPerPackage = 3
+
+var (
+	jsImportPattern = regexp.MustCompile(`(?:from\s+|require\(\s*|import\(\s*|import\s+)['"](\.{1,2}/[^'"]+)['"]`)
+	jsExtensions    = []string{"", ".ts", ".tsx", ".js", ".jsx", "/index.ts", "/index.tsx", "/index.js"}
+)
+
+// localImports returns the files in dir imported by path that belong to the
+// same project, relative to dir. Go and JavaScript/TypeScript are followed.
+func localImports(dir, path string) []string {
+	abs := filepath.Join(dir, path)
+	var imported []string
+	switch filepath.Ext(path) {
+	case ".go":
+		imported = goImports(abs)
+	case ".ts", ".tsx", ".js", ".jsx", ".mjs":
+		imported = jsImports(abs)
+	}
+
+	var result []string
+	for _, file := range imported {
+		rel, err := filepath.Rel(dir, file)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		result = append(result, filepath.ToSlash(rel))
+	}
+	return result
+}
+
+// goImports resolves imports under the file's module to the package's
+// non-test source files
+func goImports(file string) []string {
+	parsed, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.ImportsOnly)
+	if err != nil {
+		return nil
+	}
+	moduleDir, modulePath := findModule(filepath.Dir(file))
+	if modulePath == "" {
+		return nil
+	}
+
+	var files []string
+	for _, spec := range parsed.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil || (importPath != modulePath && !strings.HasPrefix(importPath, modulePath+"/")) {
+			continue
+		}
+		pkgDir := filepath.Join(moduleDir, filepath.FromSlash(strings.TrimPrefix(importPath, modulePath)))
+		entries, err := os.ReadDir(pkgDir)
+		if err != nil {
+			continue
+		}
+		added := 0
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+				continue
+			}
+			files = append(files, filepath.Join(pkgDir, name))
+			added++
+			if added >= maxFilesPerPackage {
+				break
+			}
+		}
+	}
+	return files
+}
+
+// findModule walks up from dir to the nearest go.mod and returns its
+// directory and module path
+func findModule(dir string) (string, string) {
+	for {
+		f, err := os.Open(filepath.Join(dir, "go.mod"))
+		if err == nil {
+			defer f.Close()
+			scanner := bufio.NewScanner(f)
+			for scanner.Scan() {
+				line := strings.TrimSpace(scanner.Text())
+				if rest, ok := strings.CutPrefix(line, "module "); ok {
+					return dir, strings.Trim(strings.TrimSpace(rest), `"`)
+				}
+			}
+			return dir, ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ""
+		}
+		dir = parent
+	}
+}
+
+// jsImports resolves relative imports to existing files
+func jsImports(file string) []string {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, match := range jsImportPattern.FindAllStringSubmatch(string(content), -1) {
+		base := filepath.Join(filepath.Dir(file), filepath.FromSlash(match[1]))
+		for _, ext := range jsExtensions {
+			if info, err := os.Stat(base + filepath.FromSlash(ext)); err == nil && !info.IsDir() {
+				files = append(files, base+filepath.FromSlash(ext))
+				break
+			}
+		}
+	}
+	return files
+}
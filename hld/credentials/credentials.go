@@ -0,0 +1,305 @@
+// Package credentials authenticates git to remotes, with tokens stored
+// encrypted per remote URL prefix, git credential helpers and the SSH agent,
+// and explains failures that come down to missing credentials.
+package credentials
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/gitcmd"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// DefaultUsername is sent with a token stored without a username; GitHub
+// and GitLab accept any username alongside a token
+const DefaultUsername = "x-access-token"
+
+// tokenHelper is a git credential helper answering with the token passed in
+// its environment, so the token never appears on a command line or on disk.
+// It only answers for URLs under HLD_GIT_URL_PREFIX: a fetch can reach other
+// hosts, through submodules or redirects, that mustn't be sent the token.
+const tokenHelper = `!f() { test "$1" = get || return 0; ` +
+	`while IFS= read -r line && test -n "$line"; do case "$line" in ` +
+	`protocol=*) p=${line#protocol=};; host=*) h=${line#host=};; path=*) r=/${line#path=};; esac; done; ` +
+	`u=$p://$(printf %s "$h" | tr A-Z a-z)${r%.git}; ` +
+	`case "$u" in "$HLD_GIT_URL_PREFIX"|"$HLD_GIT_URL_PREFIX"/*) ` +
+	`printf 'username=%s\npassword=%s\n' "$HLD_GIT_USERNAME" "$HLD_GIT_TOKEN";; esac; }; f`
+
+// checkTimeout bounds Check's ls-remote
+const checkTimeout = 30 * time.Second
+
+// Manager stores tokens and works out how git authenticates to a remote
+type Manager struct {
+	store   store.CredentialStore
+	cfg     config.GitCredentialsConfig
+	keyFile string
+
+	mu     sync.Mutex
+	sealer *sealer // loaded on first use
+}
+
+// NewManager creates a manager. Without a key_file, the key lives beside
+// the database at databasePath.
+func NewManager(s store.CredentialStore, cfg config.GitCredentialsConfig, databasePath string) *Manager {
+	keyFile := cfg.KeyFile
+	if keyFile == "" {
+		keyFile = filepath.Join(filepath.Dir(databasePath), "credentials.key")
+	}
+	return &Manager{store: s, cfg: cfg, keyFile: keyFile}
+}
+
+func (m *Manager) loadSealer() (*sealer, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.sealer == nil {
+		key, err := loadKey(m.keyFile)
+		if err != nil {
+			return nil, err
+		}
+		if m.sealer, err = newSealer(key); err != nil {
+			return nil, err
+		}
+	}
+	return m.sealer, nil
+}
+
+// Info is a stored credential without its token
+type Info struct {
+	URLPrefix string    `json:"url_prefix"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func infoOf(credential *store.RemoteCredential) Info {
+	return Info{URLPrefix: credential.URLPrefix, Username: credential.Username, CreatedAt: credential.CreatedAt, UpdatedAt: credential.UpdatedAt}
+}
+
+// NormalizePrefix turns a URL prefix such as github.com/acme into the form
+// it's stored and matched in, https://github.com/acme
+func NormalizePrefix(prefix string) (string, error) {
+	raw := strings.TrimSpace(prefix)
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return "", fmt.Errorf("%q isn't an HTTPS URL prefix", prefix)
+	}
+	if u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("URL prefix %q can only have a host and path", prefix)
+	}
+	return u.Scheme + "://" + strings.ToLower(u.Host) + strings.TrimSuffix(u.Path, "/"), nil
+}
+
+// Save stores token for remotes under urlPrefix, replacing any token there
+func (m *Manager) Save(ctx context.Context, urlPrefix, username, token string) (*Info, error) {
+	prefix, err := NormalizePrefix(urlPrefix)
+	if err != nil {
+		return nil, err
+	}
+	if token == "" {
+		return nil, errors.New("token is required")
+	}
+	sealer, err := m.loadSealer()
+	if err != nil {
+		return nil, err
+	}
+	secret, err := sealer.seal(token, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt token: %w", err)
+	}
+	credential := &store.RemoteCredential{URLPrefix: prefix, Username: username, Secret: secret}
+	if err := m.store.UpsertRemoteCredential(ctx, credential); err != nil {
+		return nil, err
+	}
+	info := infoOf(credential)
+	return &info, nil
+}
+
+// List returns the stored credentials, without their tokens
+func (m *Manager) List(ctx context.Context) ([]Info, error) {
+	credentials, err := m.store.ListRemoteCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]Info, len(credentials))
+	for i, credential := range credentials {
+		infos[i] = infoOf(credential)
+	}
+	return infos, nil
+}
+
+// Delete removes the token stored for urlPrefix
+func (m *Manager) Delete(ctx context.Context, urlPrefix string) error {
+	prefix, err := NormalizePrefix(urlPrefix)
+	if err != nil {
+		return err
+	}
+	return m.store.DeleteRemoteCredential(ctx, prefix)
+}
+
+// Git is what a git command reaching a remote needs: options to pass before
+// the subcommand, and environment variables
+type Git struct {
+	Args []string
+	Env  []string
+}
+
+// ForRemote returns how git authenticates to remoteURL. Stored tokens are
+// only used when git runs on this machine (local); on a remote host they'd
+// be visible on its command line.
+func (m *Manager) ForRemote(ctx context.Context, remoteURL string, local bool) (Git, error) {
+	var git Git
+	if local && isHTTP(remoteURL) {
+		credential, err := m.match(ctx, remoteURL)
+		if err != nil {
+			return git, err
+		}
+		if credential != nil {
+			sealer, err := m.loadSealer()
+			if err != nil {
+				return git, err
+			}
+			token, err := sealer.open(credential.Secret, credential.URLPrefix)
+			if err != nil {
+				return git, fmt.Errorf("token for %s: %w", credential.URLPrefix, err)
+			}
+			username := credential.Username
+			if username == "" {
+				username = DefaultUsername
+			}
+			// The empty helper clears the user's own, which might offer a
+			// stale password first
+			git.Args = append(git.Args, "-c", "credential.helper=", "-c", "credential.helper="+tokenHelper)
+			if prefixHasPath(credential.URLPrefix) {
+				// git only tells helpers the path when asked to
+				git.Args = append(git.Args, "-c", "credential.useHttpPath=true")
+			}
+			git.Env = append(git.Env, "HLD_GIT_URL_PREFIX="+credential.URLPrefix, "HLD_GIT_USERNAME="+username, "HLD_GIT_TOKEN="+token)
+		}
+	}
+	for _, helper := range m.cfg.Helpers {
+		git.Args = append(git.Args, "-c", "credential.helper="+helper)
+	}
+	if local && m.cfg.SSHAuthSock != "" {
+		git.Env = append(git.Env, "SSH_AUTH_SOCK="+m.cfg.SSHAuthSock)
+	}
+	return git, nil
+}
+
+// match finds the stored credential with the longest prefix of remoteURL,
+// ignoring its userinfo and .git suffix
+func (m *Manager) match(ctx context.Context, remoteURL string) (*store.RemoteCredential, error) {
+	u, err := url.Parse(remoteURL)
+	if err != nil {
+		return nil, nil
+	}
+	target := u.Scheme + "://" + strings.ToLower(u.Host) + strings.TrimSuffix(u.Path, ".git")
+	credentials, err := m.store.ListRemoteCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var best *store.RemoteCredential
+	for _, credential := range credentials {
+		prefix := credential.URLPrefix
+		if target != prefix && !strings.HasPrefix(target, prefix+"/") {
+			continue
+		}
+		if best == nil || len(prefix) > len(best.URLPrefix) {
+			best = credential
+		}
+	}
+	return best, nil
+}
+
+// Check runs git ls-remote against remoteURL with its credentials, to find
+// out whether they work before a fetch or push needs them
+func (m *Manager) Check(ctx context.Context, remoteURL string) error {
+	git, err := m.ForRemote(ctx, remoteURL, true)
+	if err != nil {
+		return err
+	}
+	args := append(git.Args, "ls-remote", "--heads", "--", remoteURL)
+	_, _, err = gitcmd.Run(ctx, gitcmd.Cmd{Args: args, Env: git.Env, Timeout: checkTimeout})
+	return CheckAuth(err, remoteURL)
+}
+
+// prefixHasPath reports whether a normalized prefix names more than a host
+func prefixHasPath(prefix string) bool {
+	return strings.Contains(strings.TrimPrefix(strings.TrimPrefix(prefix, "https://"), "http://"), "/")
+}
+
+func isHTTP(remoteURL string) bool {
+	return strings.HasPrefix(remoteURL, "https://") || strings.HasPrefix(remoteURL, "http://")
+}
+
+// ErrAuthRequired matches errors from remotes that need credentials git
+// didn't have, or rejected the ones it had
+var ErrAuthRequired = errors.New("remote needs authentication")
+
+// AuthError explains a git failure that came down to credentials
+type AuthError struct {
+	Remote string
+	// Hint says how to provide credentials for Remote
+	Hint string
+	Err  error
+}
+
+func (e *AuthError) Error() string {
+	return fmt.Sprintf("%s needs authentication: %s", e.Remote, e.Hint)
+}
+
+func (e *AuthError) Unwrap() error { return e.Err }
+
+func (e *AuthError) Is(target error) bool { return target == ErrAuthRequired }
+
+// authFailures are what git, its HTTP transport and ssh print when
+// credentials are missing or wrong
+var authFailures = []string{
+	"authentication failed",
+	"could not read username",
+	"could not read password",
+	"terminal prompts disabled",
+	"invalid username or password",
+	"permission denied (publickey",
+	"the requested url returned error: 401",
+	"the requested url returned error: 403",
+}
+
+// CheckAuth returns an *AuthError in place of err when git failed to reach
+// remoteURL for lack of credentials, and err otherwise
+func CheckAuth(err error, remoteURL string) error {
+	if err == nil {
+		return nil
+	}
+	message := err.Error()
+	var gitErr *gitcmd.Error
+	if errors.As(err, &gitErr) {
+		message = gitErr.Stderr
+	}
+	message = strings.ToLower(message)
+
+	if strings.Contains(message, "host key verification failed") {
+		return &AuthError{Remote: remoteURL, Hint: "the SSH host key isn't trusted yet; connect once with ssh to add it to known_hosts", Err: err}
+	}
+	for _, failure := range authFailures {
+		if !strings.Contains(message, failure) {
+			continue
+		}
+		hint := "store a token for it with PUT /api/v1/credentials, or configure git_credentials.helpers"
+		if !isHTTP(remoteURL) {
+			hint = "add a key for it to the SSH agent; git_credentials.ssh_auth_sock sets which agent the daemon uses"
+		}
+		return &AuthError{Remote: remoteURL, Hint: hint, Err: err}
+	}
+	return err
+}
@@ -0,0 +1,222 @@
+package credentials
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/gitcmd"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+func newTestManager(t *testing.T, cfg config.GitCredentialsConfig) *Manager {
+	t.Helper()
+	cfg.KeyFile = filepath.Join(t.TempDir(), "keys", "credentials.key")
+	return NewManager(store.NewInMemoryStore(), cfg, "")
+}
+
+func TestSealer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.key")
+	key, err := loadKey(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("key file mode %v, want 0600", info.Mode().Perm())
+	}
+	again, err := loadKey(path)
+	if err != nil || string(again) != string(key) {
+		t.Fatalf("reloading the key gave a different one (%v)", err)
+	}
+
+	s, err := newSealer(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := s.seal("ghp_secret", "https://github.com/acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(sealed), "ghp_secret") {
+		t.Error("token stored in plaintext")
+	}
+	if token, err := s.open(sealed, "https://github.com/acme"); err != nil || token != "ghp_secret" {
+		t.Errorf("open = %q, %v", token, err)
+	}
+	if _, err := s.open(sealed, "https://github.com/evil"); err == nil {
+		t.Error("a token opened under another remote's prefix")
+	}
+}
+
+func TestNormalizePrefix(t *testing.T) {
+	for in, want := range map[string]string{
+		"github.com/acme/":        "https://github.com/acme",
+		"https://GitHub.com/acme": "https://github.com/acme",
+		"http://git.internal":     "http://git.internal",
+	} {
+		if got, err := NormalizePrefix(in); err != nil || got != want {
+			t.Errorf("NormalizePrefix(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"ssh://git@github.com/acme", "https://user:pw@github.com", "", "https://github.com/acme?x=1"} {
+		if _, err := NormalizePrefix(in); err == nil {
+			t.Errorf("NormalizePrefix(%q) should fail", in)
+		}
+	}
+}
+
+func TestForRemote(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t, config.GitCredentialsConfig{Helpers: []string{"osxkeychain"}, SSHAuthSock: "/tmp/agent.sock"})
+	if _, err := m.Save(ctx, "github.com/acme", "", "org-token"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Save(ctx, "github.com/acme/secret-repo", "bot", "repo-token"); err != nil {
+		t.Fatal(err)
+	}
+
+	git, err := m.ForRemote(ctx, "https://me@github.com/acme/secret-repo.git", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(git.Env, "HLD_GIT_TOKEN=repo-token") || !slices.Contains(git.Env, "HLD_GIT_USERNAME=bot") {
+		t.Errorf("expected the longest matching prefix's token, got %v", git.Env)
+	}
+	if !slices.Contains(git.Env, "SSH_AUTH_SOCK=/tmp/agent.sock") {
+		t.Errorf("expected the configured agent socket, got %v", git.Env)
+	}
+	if git.Args[len(git.Args)-1] != "credential.helper=osxkeychain" {
+		t.Errorf("expected the configured helper last, got %v", git.Args)
+	}
+
+	git, _ = m.ForRemote(ctx, "https://github.com/acme/other", true)
+	if !slices.Contains(git.Env, "HLD_GIT_TOKEN=org-token") || !slices.Contains(git.Env, "HLD_GIT_USERNAME="+DefaultUsername) {
+		t.Errorf("expected the org token, got %v", git.Env)
+	}
+
+	// Prefixes end at a path segment
+	git, _ = m.ForRemote(ctx, "https://github.com/acme-corp/repo", true)
+	if slices.ContainsFunc(git.Env, func(v string) bool { return strings.HasPrefix(v, "HLD_GIT_TOKEN=") }) {
+		t.Errorf("token leaked to a remote outside its prefix: %v", git.Env)
+	}
+
+	// Tokens stay off remote hosts' command lines
+	git, _ = m.ForRemote(ctx, "https://github.com/acme/repo", false)
+	if len(git.Env) != 0 || slices.Contains(git.Args, "credential.helper=") {
+		t.Errorf("expected only helpers for a remote host, got %+v", git)
+	}
+
+	infos, err := m.List(ctx)
+	if err != nil || len(infos) != 2 {
+		t.Fatalf("List = %v, %v", infos, err)
+	}
+	if err := m.Delete(ctx, "https://github.com/acme/secret-repo/"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Delete(ctx, "github.com/acme/secret-repo"); err == nil {
+		t.Error("deleting a missing credential should fail")
+	}
+}
+
+// TestTokenHelper runs git's credential fill through the helper ForRemote
+// configures, the way a fetch would
+func TestTokenHelper(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t, config.GitCredentialsConfig{})
+	if _, err := m.Save(ctx, "git.example.com", "ci", "s3cret"); err != nil {
+		t.Fatal(err)
+	}
+	git, err := m.ForRemote(ctx, "https://git.example.com/team/repo.git", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, _, err := gitcmd.Run(ctx, gitcmd.Cmd{
+		Dir:   t.TempDir(),
+		Args:  append(git.Args, "credential", "fill"),
+		Env:   git.Env,
+		Stdin: strings.NewReader("protocol=https\nhost=git.example.com\npath=team/repo.git\n\n"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "username=ci\n") || !strings.Contains(out, "password=s3cret\n") {
+		t.Errorf("unexpected credential fill output %q", out)
+	}
+}
+
+// TestTokenHelperScope checks the helper keeps the token from hosts and
+// paths outside its prefix, such as a submodule's remote
+func TestTokenHelperScope(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t, config.GitCredentialsConfig{})
+	if _, err := m.Save(ctx, "github.com/acme/app", "ci", "s3cret"); err != nil {
+		t.Fatal(err)
+	}
+	git, err := m.ForRemote(ctx, "https://github.com/acme/app.git", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fill := func(host, path string) string {
+		out, _, _ := gitcmd.Run(ctx, gitcmd.Cmd{
+			Dir:   t.TempDir(),
+			Args:  append(git.Args, "credential", "fill"),
+			Env:   append(git.Env, "GIT_TERMINAL_PROMPT=0", "GIT_ASKPASS=", "SSH_ASKPASS="),
+			Stdin: strings.NewReader("protocol=https\nhost=" + host + "\npath=" + path + "\n\n"),
+		})
+		return out
+	}
+
+	if out := fill("GitHub.com", "acme/app.git"); !strings.Contains(out, "password=s3cret\n") {
+		t.Errorf("expected the token for its own remote, got %q", out)
+	}
+	for _, remote := range [][2]string{
+		{"evil.example.com", "acme/app.git"},
+		{"github.com", "other/lib.git"},
+		{"github.com", "acme/app-fork.git"},
+	} {
+		if out := fill(remote[0], remote[1]); strings.Contains(out, "s3cret") {
+			t.Errorf("token sent to %s/%s: %q", remote[0], remote[1], out)
+		}
+	}
+}
+
+func TestCheckAuth(t *testing.T) {
+	https := "https://github.com/acme/repo.git"
+	ssh := "git@github.com:acme/repo.git"
+	for _, tc := range []struct {
+		remote, stderr, hint string
+	}{
+		{https, "fatal: could not read Username for 'https://github.com': terminal prompts disabled", "PUT /api/v1/credentials"},
+		{https, "remote: Invalid username or password.\nfatal: Authentication failed for 'https://github.com/acme/repo.git/'", "PUT /api/v1/credentials"},
+		{https, "fatal: unable to access '...': The requested URL returned error: 403", "PUT /api/v1/credentials"},
+		{ssh, "git@github.com: Permission denied (publickey).", "SSH agent"},
+		{ssh, "Host key verification failed.", "known_hosts"},
+	} {
+		err := CheckAuth(&gitcmd.Error{Subcommand: "fetch", Err: errors.New("exit status 128"), Stderr: tc.stderr}, tc.remote)
+		var authErr *AuthError
+		if !errors.As(err, &authErr) || !errors.Is(err, ErrAuthRequired) {
+			t.Errorf("%q: expected an auth error, got %v", tc.stderr, err)
+			continue
+		}
+		if !strings.Contains(authErr.Hint, tc.hint) {
+			t.Errorf("%q: hint %q should mention %q", tc.stderr, authErr.Hint, tc.hint)
+		}
+	}
+
+	other := &gitcmd.Error{Subcommand: "fetch", Err: errors.New("exit status 128"), Stderr: "fatal: couldn't find remote ref nope"}
+	if err := CheckAuth(other, https); err != other {
+		t.Errorf("unrelated failures should pass through, got %v", err)
+	}
+	if CheckAuth(nil, https) != nil {
+		t.Error("nil should stay nil")
+	}
+}
@@ -0,0 +1,62 @@
+package credentials
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/humanlayer/humanlayer/hld/internal/keyfile"
+)
+
+// keySize is an AES-256 key
+const keySize = 32
+
+// loadKey reads the credentials key, creating it on first use
+func loadKey(path string) ([]byte, error) {
+	key, err := keyfile.Load(path, keySize)
+	if err != nil {
+		return nil, fmt.Errorf("credentials key: %w", err)
+	}
+	return key, nil
+}
+
+// sealer encrypts tokens with AES-GCM, prefixing each with its nonce
+type sealer struct {
+	aead cipher.AEAD
+}
+
+func newSealer(key []byte) (*sealer, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &sealer{aead: aead}, nil
+}
+
+// seal encrypts plaintext, binding it to label so a ciphertext can't be
+// moved to another remote
+func (s *sealer) seal(plaintext, label string) ([]byte, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return s.aead.Seal(nonce, nonce, []byte(plaintext), []byte(label)), nil
+}
+
+func (s *sealer) open(ciphertext []byte, label string) (string, error) {
+	if len(ciphertext) < s.aead.NonceSize() {
+		return "", errors.New("stored token is corrupt")
+	}
+	nonce, sealed := ciphertext[:s.aead.NonceSize()], ciphertext[s.aead.NonceSize():]
+	plaintext, err := s.aead.Open(nil, nonce, sealed, []byte(label))
+	if err != nil {
+		return "", errors.New("stored token can't be decrypted; was the credentials key replaced?")
+	}
+	return string(plaintext), nil
+}
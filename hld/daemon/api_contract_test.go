@@ -0,0 +1,158 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/api"
+	"github.com/humanlayer/humanlayer/hld/api/handlers"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/internal/testutil"
+)
+
+// contractPath records the v1 API surface. Regenerate it with
+// HLD_UPDATE_API_CONTRACT=1 after an additive change.
+const contractPath = "testdata/api_v1_contract.json"
+
+type apiSchema struct {
+	// Properties maps each property to its type, or the schema it refers to
+	Properties map[string]string `json:"properties"`
+	Required   []string          `json:"required,omitempty"`
+}
+
+type apiContract struct {
+	Routes  []string             `json:"routes"`
+	Schemas map[string]apiSchema `json:"schemas"`
+}
+
+func currentContract(t *testing.T) apiContract {
+	d, err := NewWithConfig(&config.Config{
+		SocketPath:   testutil.SocketPath(t, "contract"),
+		DatabasePath: ":memory:",
+		HTTPDisabled: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create daemon: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	d.RegisterRoutes(ctx, router.Group(handlers.APIPrefix))
+	contract := apiContract{Schemas: make(map[string]apiSchema)}
+	for _, route := range router.Routes() {
+		contract.Routes = append(contract.Routes, route.Method+" "+route.Path)
+	}
+	sort.Strings(contract.Routes)
+
+	swagger, err := api.GetSwagger()
+	if err != nil {
+		t.Fatalf("failed to load OpenAPI spec: %v", err)
+	}
+	for name, ref := range swagger.Components.Schemas {
+		schema := apiSchema{Properties: make(map[string]string), Required: ref.Value.Required}
+		for property, propertyRef := range ref.Value.Properties {
+			schema.Properties[property] = schemaType(propertyRef)
+		}
+		sort.Strings(schema.Required)
+		contract.Schemas[name] = schema
+	}
+	return contract
+}
+
+func schemaType(ref *openapi3.SchemaRef) string {
+	if ref.Ref != "" {
+		return ref.Ref[strings.LastIndex(ref.Ref, "/")+1:]
+	}
+	if ref.Value == nil || ref.Value.Type == nil {
+		return "any"
+	}
+	if ref.Value.Type.Is("array") && ref.Value.Items != nil {
+		return "array<" + schemaType(ref.Value.Items) + ">"
+	}
+	return strings.Join(ref.Value.Type.Slice(), "|")
+}
+
+// TestAPIContract enforces the v1 compatibility policy: routes, schemas and
+// properties may be added but not removed, property types don't change,
+// and request schemas gain no required properties. Additions must be
+// recorded in the contract so they're deliberate.
+func TestAPIContract(t *testing.T) {
+	current := currentContract(t)
+	if os.Getenv("HLD_UPDATE_API_CONTRACT") != "" {
+		var data bytes.Buffer
+		encoder := json.NewEncoder(&data)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(current); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(contractPath, data.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	data, err := os.ReadFile(contractPath)
+	if err != nil {
+		t.Fatalf("failed to read contract: %v", err)
+	}
+	var recorded apiContract
+	if err := json.Unmarshal(data, &recorded); err != nil {
+		t.Fatalf("failed to parse contract: %v", err)
+	}
+
+	for _, route := range recorded.Routes {
+		if !slices.Contains(current.Routes, route) {
+			t.Errorf("%s was removed; breaking changes need a new API version", route)
+		}
+	}
+	for _, route := range current.Routes {
+		if !slices.Contains(recorded.Routes, route) {
+			t.Errorf("%s isn't in %s; rerun with HLD_UPDATE_API_CONTRACT=1", route, contractPath)
+		}
+	}
+
+	for name, old := range recorded.Schemas {
+		schema, ok := current.Schemas[name]
+		if !ok {
+			t.Errorf("schema %s was removed; breaking changes need a new API version", name)
+			continue
+		}
+		for property, oldType := range old.Properties {
+			newType, ok := schema.Properties[property]
+			switch {
+			case !ok:
+				t.Errorf("%s.%s was removed; breaking changes need a new API version", name, property)
+			case newType != oldType:
+				t.Errorf("%s.%s changed type from %s to %s; breaking changes need a new API version", name, property, oldType, newType)
+			}
+		}
+		if strings.HasSuffix(name, "Request") {
+			for _, property := range schema.Required {
+				if !slices.Contains(old.Required, property) {
+					t.Errorf("%s.%s became required; breaking changes need a new API version", name, property)
+				}
+			}
+		}
+		for property := range schema.Properties {
+			if _, ok := old.Properties[property]; !ok {
+				t.Errorf("%s.%s isn't in %s; rerun with HLD_UPDATE_API_CONTRACT=1", name, property, contractPath)
+			}
+		}
+	}
+	for name := range current.Schemas {
+		if _, ok := recorded.Schemas[name]; !ok {
+			t.Errorf("schema %s isn't in %s; rerun with HLD_UPDATE_API_CONTRACT=1", name, contractPath)
+		}
+	}
+}
@@ -2,21 +2,53 @@ package daemon
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/humanlayer/humanlayer/hld/announce"
 	"github.com/humanlayer/humanlayer/hld/approval"
+	"github.com/humanlayer/humanlayer/hld/approval/policy"
+	"github.com/humanlayer/humanlayer/hld/artifacts"
 	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/cloud"
 	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/decisions"
+	"github.com/humanlayer/humanlayer/hld/denial"
+	"github.com/humanlayer/humanlayer/hld/digest"
+	"github.com/humanlayer/humanlayer/hld/discovery"
+	"github.com/humanlayer/humanlayer/hld/email"
+	"github.com/humanlayer/humanlayer/hld/escalation"
+	"github.com/humanlayer/humanlayer/hld/experiment"
+	"github.com/humanlayer/humanlayer/hld/federation"
+	"github.com/humanlayer/humanlayer/hld/github"
+	"github.com/humanlayer/humanlayer/hld/inbox"
+	"github.com/humanlayer/humanlayer/hld/instance"
+	"github.com/humanlayer/humanlayer/hld/internal/version"
+	"github.com/humanlayer/humanlayer/hld/jobs"
+	"github.com/humanlayer/humanlayer/hld/llm"
+	"github.com/humanlayer/humanlayer/hld/moderation"
+	"github.com/humanlayer/humanlayer/hld/plugin"
+	"github.com/humanlayer/humanlayer/hld/prompts"
+	"github.com/humanlayer/humanlayer/hld/recovery"
+	"github.com/humanlayer/humanlayer/hld/retry"
 	"github.com/humanlayer/humanlayer/hld/rpc"
+	"github.com/humanlayer/humanlayer/hld/scheduler"
 	"github.com/humanlayer/humanlayer/hld/session"
+	"github.com/humanlayer/humanlayer/hld/similar"
 	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/humanlayer/humanlayer/hld/summary"
+	"github.com/humanlayer/humanlayer/hld/tracker"
+	"github.com/humanlayer/humanlayer/hld/twilio"
+	"github.com/humanlayer/humanlayer/hld/usage"
+	"github.com/humanlayer/humanlayer/hld/webpush"
 )
 
 const (
@@ -59,11 +91,21 @@ type Daemon struct {
 	sessions          session.SessionManager
 	approvals         approval.Manager
 	eventBus          bus.EventBus
-	store             store.ConversationStore
+	store             store.Store
 	permissionMonitor *session.PermissionMonitor
+	plugins           *plugin.Host
+	jobs              *jobs.Manager
+	maintenance       *scheduler.Scheduler
+	lock              *instance.Lock // Nil for daemons not built by NewWithConfig
+
+	// Background lifecycle used by Start and Stop
+	lifecycleMu sync.Mutex
+	ready       chan struct{}
+	stop        chan struct{}
+	done        chan error
 }
 
-// New creates a new daemon instance
+// New creates a new daemon instance from the user's configuration
 func New() (*Daemon, error) {
 	// Load configuration
 	cfg, err := config.Load()
@@ -71,6 +113,13 @@ func New() (*Daemon, error) {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
+	return NewWithConfig(cfg)
+}
+
+// NewWithConfig creates a new daemon instance with the given configuration.
+// Programs embedding the daemon use this instead of New so they control
+// where the socket, database and HTTP listener live.
+func NewWithConfig(cfg *config.Config) (*Daemon, error) {
 	// Validate configuration
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
@@ -92,13 +141,38 @@ func New() (*Daemon, error) {
 		return nil, fmt.Errorf("failed to create socket directory: %w", err)
 	}
 
+	// Only one daemon may use a store at a time
+	lock, err := instance.Acquire(instance.Path(cfg), instance.Info{
+		PID:       os.Getpid(),
+		Socket:    socketPath,
+		Database:  cfg.DatabasePath,
+		Version:   daemonVersion(cfg),
+		StartedAt: time.Now(),
+	})
+	var held *instance.HeldError
+	if errors.As(err, &held) {
+		return nil, fmt.Errorf("%w: %s; stop it, start with --takeover to replace it, "+
+			"or give this daemon its own HUMANLAYER_DATABASE_PATH and HUMANLAYER_DAEMON_SOCKET",
+			ErrDaemonAlreadyRunning, held.Info.Describe())
+	}
+	if err != nil {
+		return nil, err
+	}
+	created := false
+	defer func() {
+		if !created {
+			lock.Release()
+		}
+	}()
+
 	// Check if socket already exists (another daemon running)
 	if _, err := os.Stat(socketPath); err == nil {
 		// Try to connect to see if it's alive
 		conn, err := net.Dial("unix", socketPath)
 		if err == nil {
 			_ = conn.Close()
-			return nil, fmt.Errorf("%w at %s", ErrDaemonAlreadyRunning, socketPath)
+			return nil, fmt.Errorf("%w at %s with another database; give this daemon its own HUMANLAYER_DAEMON_SOCKET",
+				ErrDaemonAlreadyRunning, socketPath)
 		}
 		// Socket exists but can't connect, remove stale socket
 		slog.Info("removing stale socket file", "path", socketPath)
@@ -122,24 +196,80 @@ func New() (*Daemon, error) {
 		_ = conversationStore.Close()
 		return nil, fmt.Errorf("failed to create session manager: %w", err)
 	}
+	sessionManager.SetContextStores(conversationStore, conversationStore, conversationStore)
+	sessionManager.SetProcessStore(conversationStore)
+
+	// Load the approval policy, if one is configured
+	var approvalPolicy *policy.Policy
+	if cfg.ApprovalPolicyPath != "" {
+		approvalPolicy, err = policy.Load(cfg.ApprovalPolicyPath)
+		if err != nil {
+			_ = conversationStore.Close()
+			return nil, err
+		}
+		slog.Info("loaded approval policy", "path", cfg.ApprovalPolicyPath, "rules", len(approvalPolicy.Rules()))
+	}
+	var shadowPolicy *policy.Policy
+	if cfg.ShadowApprovalPolicyPath != "" {
+		shadowPolicy, err = policy.Load(cfg.ShadowApprovalPolicyPath)
+		if err != nil {
+			_ = conversationStore.Close()
+			return nil, err
+		}
+		slog.Info("loaded shadow approval policy", "path", cfg.ShadowApprovalPolicyPath, "rules", len(shadowPolicy.Rules()))
+	}
 
-	// Always create local approval manager
-	slog.Info("creating local approval manager")
-	approvalManager := approval.NewManager(conversationStore, eventBus)
+	// Always create local approval manager; in CI mode nobody answers, so
+	// the policy decides alone
+	var approvalManager approval.Manager
+	if cfg.CI.Enabled {
+		slog.Info("creating headless approval manager for CI mode", "policy", cfg.ApprovalPolicyPath)
+		approvalManager = approval.NewHeadlessManager(conversationStore, conversationStore, eventBus, approvalPolicy, shadowPolicy)
+	} else {
+		slog.Info("creating local approval manager")
+		approvalManager = approval.NewManagerWithPolicies(conversationStore, conversationStore, eventBus, approvalPolicy, shadowPolicy)
+	}
+	// The content safety filter sits under the denial expander, so it sees
+	// the expansion along with the comment
+	contentFilter := moderation.New(cfg.ContentSafety, llm.NewRouter(cfg.LLM), prompts.NewSet(cfg.PromptsDir), conversationStore, conversationStore)
+	if contentFilter != nil {
+		approvalManager = moderation.NewManager(approvalManager, contentFilter, conversationStore)
+	}
+	if cfg.ExpandDenials {
+		approvalManager = denial.NewExpander(approvalManager, conversationStore, conversationStore, llm.NewRouter(cfg.LLM), prompts.NewSet(cfg.PromptsDir))
+	}
 	slog.Debug("local approval manager created successfully")
 
-	// Create HTTP server (always enabled, port 0 means dynamic allocation)
-	slog.Info("creating HTTP server", "port", cfg.HTTPPort)
-	httpServer := NewHTTPServer(cfg, sessionManager, approvalManager, conversationStore, eventBus)
+	// Create HTTP server (port 0 means dynamic allocation). It is built even when
+	// the listener is disabled so embedders can mount its routes.
+	slog.Info("creating HTTP server", "port", cfg.HTTPPort, "listener_disabled", cfg.HTTPDisabled)
+	jobManager := jobs.NewManager(conversationStore, eventBus)
+	maintenance := scheduler.New(cfg.Maintenance)
+	httpServer := NewHTTPServer(cfg, sessionManager, approvalManager, approvalPolicy, shadowPolicy, conversationStore, eventBus, jobManager, maintenance)
+
+	// Load compiled-in and external plugins
+	pluginHost := plugin.NewHostWithLocale(cfg.Plugins, conversationStore, cfg.Locale)
+	pluginHost.SetOwnerChannels(cfg.Owners.Channels)
+	pluginHost.SetWatches(conversationStore)
+	pluginHost.SetNotificationPreferences(cfg.Notifications)
+	if contentFilter != nil {
+		pluginHost.SetModerator(contentFilter)
+	}
+	httpServer.SetRiskAssessor(pluginHost)
 
+	created = true
 	return &Daemon{
-		config:     cfg,
-		socketPath: socketPath,
-		sessions:   sessionManager,
-		approvals:  approvalManager,
-		eventBus:   eventBus,
-		store:      conversationStore,
-		httpServer: httpServer,
+		config:      cfg,
+		socketPath:  socketPath,
+		sessions:    sessionManager,
+		approvals:   approvalManager,
+		eventBus:    eventBus,
+		store:       conversationStore,
+		httpServer:  httpServer,
+		plugins:     pluginHost,
+		jobs:        jobManager,
+		maintenance: maintenance,
+		lock:        lock,
 	}, nil
 }
 
@@ -178,6 +308,9 @@ func (d *Daemon) Run(ctx context.Context) error {
 				slog.Warn("failed to close store", "error", err)
 			}
 		}
+		if d.lock != nil {
+			d.lock.Release()
+		}
 		cleanupDuration := time.Since(cleanupStart)
 		var totalShutdownDuration time.Duration
 		if !shutdownStart.IsZero() {
@@ -196,10 +329,14 @@ func (d *Daemon) Run(ctx context.Context) error {
 		d.rpcServer = rpc.NewServer()
 	}
 
-	// Mark orphaned sessions as failed (from previous daemon run)
-	if err := d.markOrphanedSessionsAsFailed(ctx); err != nil {
-		slog.Warn("failed to mark orphaned sessions as failed", "error", err)
-		// Don't fail startup for this
+	// Reconcile sessions and approvals the previous daemon left active
+	recoverer, recovered := d.recoverState(ctx)
+
+	// Jobs running when the previous daemon stopped can't be resumed
+	if d.jobs != nil {
+		if err := d.jobs.Recover(ctx); err != nil {
+			slog.Warn("failed to mark interrupted jobs", "error", err)
+		}
 	}
 
 	// Create and start dangerous skip permissions monitor
@@ -212,6 +349,166 @@ func (d *Daemon) Run(ctx context.Context) error {
 	}()
 	slog.Info("started dangerous skip permissions expiry monitor")
 
+	// Feed daemon events to plugin notification channels
+	if d.plugins != nil && !d.plugins.Empty() {
+		go d.plugins.Run(ctx, d.eventBus)
+	}
+
+	// Alert when daemon-wide spend crosses cost budget thresholds
+	if len(d.config.CostBudgets) > 0 {
+		go usage.NewMonitor(d.store, d.eventBus, d.config.CostBudgets).Run(ctx)
+		slog.Info("started cost budget monitor", "budgets", len(d.config.CostBudgets))
+	}
+
+	// Periodic maintenance runs under one scheduler, listed at /admin/tasks
+	if d.maintenance == nil {
+		d.maintenance = scheduler.New(d.config.Maintenance)
+	}
+
+	// Send daily digests of session activity
+	if len(d.config.Digests) > 0 {
+		d.maintenance.Register(digest.NewScheduler(d.store, d.eventBus, d.config.Digests).Task())
+		slog.Info("scheduled daily digests", "recipients", len(d.config.Digests))
+	}
+
+	// Measure diffs and run tests for experiment sessions as they finish
+	if d.eventBus != nil {
+		go experiment.NewRunner(d.store, d.sessions, d.eventBus, experiment.WorktreeDir(d.config.DatabasePath)).Run(ctx)
+	}
+
+	// Summarize sessions as they finish
+	if d.eventBus != nil && !d.config.SessionSummariesDisabled {
+		templates := prompts.NewSet(d.config.PromptsDir)
+		generator := summary.NewGenerator(d.store, llm.NewRouter(d.config.LLM), templates, d.config.Locale, d.eventBus)
+		go generator.Run(ctx)
+		d.maintenance.Register(generator.Task())
+	}
+
+	// Record the decisions made in sessions as they complete
+	if d.eventBus != nil && d.config.DecisionLog.Enabled {
+		templates := prompts.NewSet(d.config.PromptsDir)
+		go decisions.NewExtractor(d.store, d.store, llm.NewRouter(d.config.LLM), templates, d.config.Locale, d.eventBus).Run(ctx)
+		slog.Info("started decision log extractor")
+	}
+
+	// Embed finished sessions for similar session search
+	if d.eventBus != nil {
+		index, err := similar.FromConfig(d.config, d.store, d.store, d.eventBus)
+		if err != nil {
+			slog.Warn("similar session search disabled", "error", err)
+		} else if index != nil {
+			go index.Run(ctx)
+			slog.Info("started session embedding index", "model", d.config.Embeddings.Model)
+		}
+	}
+
+	// Relaunch sessions that fail transiently, per template retry policy
+	if d.eventBus != nil && len(d.config.RetryPolicies) > 0 {
+		retrier := retry.NewRetrier(d.store, d.sessions, d.eventBus, d.config.RetryPolicies)
+		retrier.SetProcessStore(d.store)
+		go retrier.Run(ctx)
+	}
+
+	// Comment on GitHub issues and pull requests when their sessions finish
+	if d.eventBus != nil && d.config.GitHub.WebhookSecret != "" {
+		go github.NewReporter(d.store, d.store, d.config.GitHub, d.eventBus).Run(ctx)
+		slog.Info("started github status reporter", "repositories", len(d.config.GitHub.Repositories))
+	}
+
+	// Ask code owners to review pull requests sessions open
+	if d.eventBus != nil && d.config.Owners.RequestReviewers {
+		go github.NewReviewRequester(d.store, d.config.GitHub, d.eventBus).Run(ctx)
+		slog.Info("started pull request review requests")
+	}
+
+	// Move referenced tickets to review when sessions open pull requests
+	if d.eventBus != nil {
+		if trackers := tracker.New(d.config.Trackers); trackers != nil {
+			go tracker.NewSyncer(d.store, d.store, trackers, d.eventBus).Run(ctx)
+			slog.Info("started ticket status sync", "trackers", len(d.config.Trackers))
+		}
+	}
+
+	// Page on-call when critical approvals go unanswered
+	if d.eventBus != nil {
+		escalator, err := escalation.FromConfig(d.config, d.store, d.store, d.store, d.eventBus)
+		if err != nil {
+			slog.Warn("approval escalation disabled", "error", err)
+		} else if escalator != nil {
+			go escalator.Run(ctx)
+			slog.Info("started approval escalation", "provider", d.config.Escalation.Provider)
+		}
+	}
+
+	// Let HumanLayer cloud contact channels decide approvals too
+	if d.store != nil && d.eventBus != nil {
+		if relay := cloud.FromConfig(d.config, d.store, d.store, d.approvals, d.eventBus); relay != nil {
+			go relay.Run(ctx)
+			slog.Info("started HumanLayer cloud relay", "api_base_url", d.config.APIBaseURL)
+		}
+	}
+
+	// Keep this daemon registered with its federation hub
+	if fc := d.config.Federation; fc.Role == config.FederationMember {
+		if token := os.Getenv(fc.TokenEnv); token != "" {
+			name := fc.Name
+			if name == "" {
+				name, _ = os.Hostname()
+			}
+			go federation.NewRegistrar(fc.HubURL, token, name, fc.AdvertiseURL).Run(ctx)
+			slog.Info("started federation registration", "hub_url", fc.HubURL, "name", name)
+		}
+	}
+
+	// Read pending approvals aloud on the daemon's machine
+	if d.eventBus != nil {
+		announcer, err := announce.FromConfig(d.config, d.store, d.eventBus)
+		if err != nil {
+			slog.Warn("announcements disabled", "error", err)
+		} else if announcer != nil {
+			go announcer.Run(ctx)
+			slog.Info("started spoken announcements")
+		}
+	}
+
+	// Push approvals to browsers that subscribed
+	if d.store != nil && d.eventBus != nil {
+		go webpush.FromConfig(d.config, d.store, d.store, d.eventBus).Run(ctx)
+	}
+
+	// Email approvals to recipients who can answer by replying
+	if d.store != nil && d.eventBus != nil {
+		if notifier := email.FromConfig(d.config, d.store, d.eventBus, email.TokensFromConfig(d.config)); notifier != nil {
+			go notifier.Run(ctx)
+			slog.Info("started email approval notifications", "recipients", len(d.config.Email.NotifyTo))
+		}
+	}
+
+	// Text critical approvals to users who can answer by replying
+	if d.store != nil && d.eventBus != nil {
+		if notifier := twilio.FromConfig(d.config, d.store, d.eventBus, twilio.CodesFromConfig(d.config.DatabasePath)); notifier != nil {
+			go notifier.Run(ctx)
+			slog.Info("started Twilio approval messages", "users", len(d.config.Twilio.Users))
+		}
+	}
+
+	// Remind users of approvals they snoozed
+	if d.store != nil && d.eventBus != nil {
+		d.maintenance.Register(inbox.New(d.store, d.store, d.eventBus).Task())
+	}
+
+	// Lift approval holds as their resume time comes
+	if d.store != nil && d.eventBus != nil {
+		d.maintenance.Register(approval.NewHolds(d.store, d.store, d.eventBus).Task())
+	}
+
+	// Delete published artifacts as their retention ends
+	if d.store != nil {
+		d.maintenance.Register(artifacts.FromConfig(d.config, d.store, d.eventBus).Task())
+	}
+
+	go d.maintenance.Run(ctx)
+
 	// Register subscription handlers
 	subscriptionHandlers := rpc.NewSubscriptionHandlers(d.eventBus)
 	d.rpcServer.SetSubscriptionHandlers(subscriptionHandlers)
@@ -226,7 +523,7 @@ func (d *Daemon) Run(ctx context.Context) error {
 	approvalHandlers.Register(d.rpcServer)
 
 	// Start HTTP server if enabled
-	if d.httpServer != nil {
+	if d.httpServer != nil && !d.config.HTTPDisabled {
 		httpCtx, httpCancel := context.WithCancel(ctx)
 		defer httpCancel()
 
@@ -237,10 +534,30 @@ func (d *Daemon) Run(ctx context.Context) error {
 		}()
 	}
 
-	slog.Info("daemon started", "socket", d.socketPath, "http_enabled", d.httpServer != nil)
+	// Record where the REST API listens for hld status and later daemons
+	if d.lock != nil {
+		go d.recordAPI(ctx)
+	}
+
+	// Continue interrupted sessions once approvals can be served
+	if recovered != nil && len(recovered.Resumable) > 0 {
+		go func() {
+			if _, ok := d.httpPort(ctx); ok {
+				recoverer.Resume(ctx, recovered)
+			}
+		}()
+	}
+
+	// Let clients on this machine or the local network find the daemon
+	if d.config.MDNS.Enabled {
+		go d.advertise(ctx)
+	}
+
+	slog.Info("daemon started", "socket", d.socketPath, "http_enabled", d.httpServer != nil && !d.config.HTTPDisabled)
 
 	// Accept connections until context is cancelled
 	go d.acceptConnections(ctx)
+	d.markReady()
 
 	// Wait for shutdown signal
 	<-ctx.Done()
@@ -304,6 +621,79 @@ func (d *Daemon) Run(ctx context.Context) error {
 	return nil
 }
 
+// httpPort waits for the HTTP server to bind, returning its port, 0 when
+// the listener is disabled, or false if ctx ends first
+func (d *Daemon) httpPort(ctx context.Context) (int, bool) {
+	if d.httpServer == nil || d.config.HTTPDisabled {
+		return 0, true
+	}
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		if port := d.httpServer.Port(); port != 0 {
+			return port, true
+		}
+		select {
+		case <-ctx.Done():
+			return 0, false
+		case <-ticker.C:
+		}
+	}
+}
+
+// recordAPI writes the REST API's URL to the instance lock once it listens
+func (d *Daemon) recordAPI(ctx context.Context) {
+	port, ok := d.httpPort(ctx)
+	if !ok || port == 0 {
+		return
+	}
+	host := d.config.HTTPHost
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "127.0.0.1"
+	}
+	url := "http://" + net.JoinHostPort(host, strconv.Itoa(port)) + discovery.APIPrefix
+	if err := d.lock.SetAPI(url); err != nil {
+		slog.Warn("failed to record API address in lock file", "error", err)
+	}
+}
+
+// daemonVersion is the version the daemon reports, after any override
+func daemonVersion(cfg *config.Config) string {
+	if cfg.VersionOverride != "" {
+		return cfg.VersionOverride
+	}
+	return version.GetVersion()
+}
+
+// advertise announces the daemon over mDNS once its HTTP port is known,
+// until ctx is cancelled
+func (d *Daemon) advertise(ctx context.Context) {
+	port, ok := d.httpPort(ctx)
+	if !ok {
+		return
+	}
+
+	name := d.config.MDNS.Name
+	if name == "" {
+		name, _ = os.Hostname()
+	}
+	addrs := discovery.InterfaceAddrs()
+	if ip := net.ParseIP(d.config.HTTPHost); ip != nil && !ip.IsUnspecified() {
+		// Only reachable where it listens, e.g. loopback for local clients
+		addrs = []net.IP{ip}
+	}
+	advertiser := discovery.NewAdvertiser(discovery.Daemon{
+		Instance: name,
+		Port:     port,
+		Addrs:    addrs,
+		Version:  daemonVersion(d.config),
+		Socket:   d.socketPath,
+	})
+	if err := advertiser.Run(ctx); err != nil {
+		slog.Warn("mDNS advertisement stopped", "error", err)
+	}
+}
+
 // acceptConnections handles incoming client connections
 func (d *Daemon) acceptConnections(ctx context.Context) {
 	for {
@@ -338,53 +728,24 @@ func (d *Daemon) handleConnection(ctx context.Context, conn net.Conn) {
 	slog.Debug("client disconnected", "remote", conn.RemoteAddr())
 }
 
-// markOrphanedSessionsAsFailed marks any sessions that were running or waiting
-// when the daemon restarted as failed. Sessions with status interrupting, interrupted,
-// completed, or failed are left as-is.
-func (d *Daemon) markOrphanedSessionsAsFailed(ctx context.Context) error {
+// recoverState marks what the previous daemon left active as stopped,
+// returning what the recovery policy resumes once the daemon is serving.
+// Failures are logged rather than stopping startup.
+func (d *Daemon) recoverState(ctx context.Context) (*recovery.Recoverer, *recovery.Report) {
 	if d.store == nil {
-		return nil
+		return nil, nil
 	}
-
-	// Get all sessions from the database
-	sessions, err := d.store.ListSessions(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to list sessions: %w", err)
-	}
-
-	orphanedCount := 0
-	for _, session := range sessions {
-		// Mark only truly orphaned sessions as failed (running, waiting_input, starting).
-		// Sessions with status interrupting, interrupted, completed, or failed are left as-is
-		// to allow interrupted sessions to be resumed after daemon restart.
-		if session.Status == store.SessionStatusRunning ||
-			session.Status == store.SessionStatusWaitingInput ||
-			session.Status == store.SessionStatusStarting {
-			failedStatus := store.SessionStatusFailed
-			errorMsg := "daemon restarted while session was active"
-			now := time.Now()
-			update := store.SessionUpdate{
-				Status:       &failedStatus,
-				CompletedAt:  &now,
-				ErrorMessage: &errorMsg,
-			}
-
-			if err := d.store.UpdateSession(ctx, session.ID, update); err != nil {
-				slog.Error("failed to mark orphaned session as failed",
-					"session_id", session.ID,
-					"error", err)
-				// Continue with other sessions
-			} else {
-				orphanedCount++
-			}
-		}
+	var cfg config.RecoveryConfig
+	if d.config != nil {
+		cfg = d.config.Recovery
 	}
-
-	if orphanedCount > 0 {
-		slog.Info("marked orphaned sessions as failed", "count", orphanedCount)
+	recoverer := recovery.NewRecoverer(d.store, d.sessions, d.eventBus, cfg)
+	report, err := recoverer.Recover(ctx)
+	if err != nil {
+		slog.Warn("failed to recover state from previous daemon", "error", err)
+		return nil, nil
 	}
-
-	return nil
+	return recoverer, report
 }
 
 // expandPath expands ~ to the user's home directory
@@ -2,6 +2,7 @@ package daemon
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/humanlayer/humanlayer/hld/store"
@@ -12,7 +13,7 @@ func TestDaemon_MarkOrphanedSessions(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStore := store.NewMockConversationStore(ctrl)
+	mockStore := store.NewMockStore(ctrl)
 
 	// Set up sessions with various statuses
 	sessions := []*store.Session{
@@ -28,6 +29,10 @@ func TestDaemon_MarkOrphanedSessions(t *testing.T) {
 			ID:     "sess-starting",
 			Status: store.SessionStatusStarting,
 		},
+		{
+			ID:     "sess-queued",
+			Status: store.SessionStatusQueued, // Never ran, so fails
+		},
 		{
 			ID:     "sess-completed",
 			Status: store.SessionStatusCompleted, // Should NOT be marked as failed
@@ -38,7 +43,7 @@ func TestDaemon_MarkOrphanedSessions(t *testing.T) {
 		},
 		{
 			ID:     "sess-interrupting",
-			Status: store.SessionStatusInterrupting, // Should NOT be marked as failed
+			Status: store.SessionStatusInterrupting, // Its process is gone too
 		},
 		{
 			ID:     "sess-interrupted",
@@ -46,24 +51,28 @@ func TestDaemon_MarkOrphanedSessions(t *testing.T) {
 		},
 	}
 
-	// Expect ListSessions to be called
+	mockStore.EXPECT().ListPendingApprovals(gomock.Any()).Return(nil, nil)
 	mockStore.EXPECT().ListSessions(gomock.Any()).Return(sessions, nil)
 
 	// Expect UpdateSession for orphaned sessions only
+	expected := map[string]string{
+		"sess-running":      store.SessionStatusInterrupted,
+		"sess-waiting":      store.SessionStatusInterrupted,
+		"sess-starting":     store.SessionStatusInterrupted,
+		"sess-interrupting": store.SessionStatusInterrupted,
+		"sess-queued":       store.SessionStatusFailed,
+	}
 	for _, sess := range sessions {
-		if sess.Status == store.SessionStatusRunning ||
-			sess.Status == store.SessionStatusWaitingInput ||
-			sess.Status == store.SessionStatusStarting {
-
+		if want, ok := expected[sess.ID]; ok {
 			mockStore.EXPECT().
 				UpdateSession(gomock.Any(), sess.ID, gomock.Any()).
 				DoAndReturn(func(ctx context.Context, id string, update store.SessionUpdate) error {
 					// Verify the update
-					if update.Status == nil || *update.Status != store.SessionStatusFailed {
-						t.Errorf("expected status update to failed, got %v", update.Status)
+					if update.Status == nil || *update.Status != want {
+						t.Errorf("expected status update to %s, got %v", want, update.Status)
 					}
-					if update.ErrorMessage == nil || *update.ErrorMessage != "daemon restarted while session was active" {
-						t.Errorf("expected error message about daemon restart, got %v", update.ErrorMessage)
+					if update.ErrorMessage == nil || !strings.Contains(*update.ErrorMessage, "daemon stopped") {
+						t.Errorf("expected error message about daemon stopping, got %v", update.ErrorMessage)
 					}
 					if update.CompletedAt == nil {
 						t.Error("expected CompletedAt to be set")
@@ -78,10 +87,15 @@ func TestDaemon_MarkOrphanedSessions(t *testing.T) {
 		store: mockStore,
 	}
 
-	// Call markOrphanedSessionsAsFailed
-	err := d.markOrphanedSessionsAsFailed(context.Background())
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
+	_, report := d.recoverState(context.Background())
+	if report == nil {
+		t.Fatal("expected a recovery report")
+	}
+	if len(report.Interrupted) != 4 || len(report.Failed) != 1 {
+		t.Errorf("expected 4 interrupted and 1 failed session, got %v and %v", report.Interrupted, report.Failed)
+	}
+	if len(report.Resumable) != 0 {
+		t.Errorf("expected nothing resumed without a policy, got %v", report.Resumable)
 	}
 
 	// Expectations are verified by gomock
@@ -93,8 +107,7 @@ func TestDaemon_MarkOrphanedSessions_NoStore(t *testing.T) {
 		store: nil,
 	}
 
-	err := d.markOrphanedSessionsAsFailed(context.Background())
-	if err != nil {
-		t.Fatalf("expected no error with nil store, got: %v", err)
+	if _, report := d.recoverState(context.Background()); report != nil {
+		t.Fatalf("expected no recovery without a store, got: %v", report)
 	}
 }
@@ -0,0 +1,131 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/api/handlers"
+	"github.com/humanlayer/humanlayer/hld/approval"
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/plugin"
+	"github.com/humanlayer/humanlayer/hld/session"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// Start runs the daemon in the background and returns once it is accepting
+// connections. The daemon shuts down when ctx is cancelled or Stop is called.
+// A daemon cannot be restarted after it stops because its store is closed.
+func (d *Daemon) Start(ctx context.Context) error {
+	d.lifecycleMu.Lock()
+	if d.done != nil {
+		d.lifecycleMu.Unlock()
+		return errors.New("daemon already started")
+	}
+	ready := make(chan struct{})
+	stop := make(chan struct{})
+	done := make(chan error, 1)
+	d.ready, d.stop, d.done = ready, stop, done
+	d.lifecycleMu.Unlock()
+
+	go func() {
+		runCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go func() {
+			select {
+			case <-stop:
+				cancel()
+			case <-runCtx.Done():
+			}
+		}()
+		done <- d.Run(runCtx)
+	}()
+
+	select {
+	case <-ready:
+		return nil
+	case err := <-done:
+		// Run failed before it was ready; keep the error for Stop as well
+		done <- err
+		return err
+	}
+}
+
+// Stop shuts down a daemon started with Start and waits for it to finish
+func (d *Daemon) Stop() error {
+	d.lifecycleMu.Lock()
+	stop, done := d.stop, d.done
+	d.stop = nil
+	d.lifecycleMu.Unlock()
+
+	if done == nil {
+		return ErrDaemonNotStarted
+	}
+	if stop != nil {
+		close(stop)
+	}
+	err := <-done
+	done <- err // Later calls to Stop return the same result
+	return err
+}
+
+// markReady signals Start that Run is serving
+func (d *Daemon) markReady() {
+	d.lifecycleMu.Lock()
+	defer d.lifecycleMu.Unlock()
+	if d.ready != nil {
+		close(d.ready)
+		d.ready = nil
+	}
+}
+
+// RegisterRoutes registers the daemon's REST, SSE and MCP routes on a router
+// group the caller mounts at /api/v1, e.g. engine.Group("/api/v1"). Sessions
+// reach the MCP endpoint over HTTP, so callers serving the routes themselves
+// should report their port with Sessions().SetHTTPPort.
+func (d *Daemon) RegisterRoutes(ctx context.Context, v1 gin.IRouter) {
+	v1.Use(handlers.RequestIDMiddleware())
+	d.httpServer.RegisterRoutes(ctx, v1)
+}
+
+// Handler returns an http.Handler serving the daemon's API under /api/v1,
+// suitable for mounting on an http.ServeMux with mux.Handle("/api/v1/", h)
+func (d *Daemon) Handler(ctx context.Context) http.Handler {
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.Use(handlers.CompressionMiddleware())
+	d.RegisterRoutes(ctx, router.Group("/api/v1"))
+	return router
+}
+
+// Config returns the daemon's configuration
+func (d *Daemon) Config() *config.Config {
+	return d.config
+}
+
+// Sessions returns the session manager
+func (d *Daemon) Sessions() session.SessionManager {
+	return d.sessions
+}
+
+// Approvals returns the approval manager
+func (d *Daemon) Approvals() approval.Manager {
+	return d.approvals
+}
+
+// Store returns the conversation store
+func (d *Daemon) Store() store.ConversationStore {
+	return d.store
+}
+
+// Plugins returns the plugin host. Embedding programs can Add their own plugins before Start.
+func (d *Daemon) Plugins() *plugin.Host {
+	return d.plugins
+}
+
+// EventBus returns the event bus
+func (d *Daemon) EventBus() bus.EventBus {
+	return d.eventBus
+}
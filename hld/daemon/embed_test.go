@@ -0,0 +1,66 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/internal/testutil"
+)
+
+func TestEmbeddedDaemonStartStop(t *testing.T) {
+	socketPath := testutil.SocketPath(t, "embed")
+
+	d, err := NewWithConfig(&config.Config{
+		SocketPath:   socketPath,
+		DatabasePath: ":memory:",
+		HTTPDisabled: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create daemon: %v", err)
+	}
+	if d.Sessions() == nil || d.Approvals() == nil || d.Store() == nil || d.EventBus() == nil {
+		t.Fatal("expected services to be available before Start")
+	}
+
+	if err := d.Stop(); !errors.Is(err, ErrDaemonNotStarted) {
+		t.Fatalf("expected ErrDaemonNotStarted, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := d.Start(ctx); err != nil {
+		t.Fatalf("failed to start daemon: %v", err)
+	}
+	if err := d.Start(ctx); err == nil {
+		t.Fatal("expected second Start to fail")
+	}
+
+	// Start returns once the socket is accepting connections
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		t.Fatalf("failed to connect to daemon: %v", err)
+	}
+	_ = conn.Close()
+
+	// Routes can be served from the embedding program's own mux
+	mux := http.NewServeMux()
+	mux.Handle("/api/v1/", d.Handler(ctx))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/health", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected health to return 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if err := d.Stop(); err != nil {
+		t.Fatalf("failed to stop daemon: %v", err)
+	}
+	if _, err := net.Dial("unix", socketPath); err == nil {
+		t.Fatal("expected socket to be closed after Stop")
+	}
+}
@@ -4,3 +4,6 @@ import "errors"
 
 // ErrDaemonAlreadyRunning is returned when attempting to start a daemon when one is already running
 var ErrDaemonAlreadyRunning = errors.New("daemon already running")
+
+// ErrDaemonNotStarted is returned when stopping a daemon that was not started with Start
+var ErrDaemonNotStarted = errors.New("daemon not started")
@@ -2,12 +2,14 @@ package daemon
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net"
 	"net/http"
 	"os"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -15,11 +17,40 @@ import (
 	"github.com/humanlayer/humanlayer/hld/api"
 	"github.com/humanlayer/humanlayer/hld/api/handlers"
 	"github.com/humanlayer/humanlayer/hld/approval"
+	"github.com/humanlayer/humanlayer/hld/approval/policy"
+	"github.com/humanlayer/humanlayer/hld/artifacts"
 	"github.com/humanlayer/humanlayer/hld/bus"
 	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/contextpack"
+	"github.com/humanlayer/humanlayer/hld/credentials"
+	"github.com/humanlayer/humanlayer/hld/decisions"
+	"github.com/humanlayer/humanlayer/hld/depcheck"
+	"github.com/humanlayer/humanlayer/hld/diskguard"
+	"github.com/humanlayer/humanlayer/hld/email"
+	"github.com/humanlayer/humanlayer/hld/experiment"
+	"github.com/humanlayer/humanlayer/hld/federation"
+	"github.com/humanlayer/humanlayer/hld/github"
+	"github.com/humanlayer/humanlayer/hld/hooks"
+	"github.com/humanlayer/humanlayer/hld/httpcapture"
+	"github.com/humanlayer/humanlayer/hld/inbox"
+	"github.com/humanlayer/humanlayer/hld/injection"
+	"github.com/humanlayer/humanlayer/hld/jobs"
+	"github.com/humanlayer/humanlayer/hld/llm"
 	"github.com/humanlayer/humanlayer/hld/mcp"
+	"github.com/humanlayer/humanlayer/hld/memoryfile"
+	"github.com/humanlayer/humanlayer/hld/promptlog"
+	"github.com/humanlayer/humanlayer/hld/prompts"
+	"github.com/humanlayer/humanlayer/hld/resolution"
+	"github.com/humanlayer/humanlayer/hld/scheduler"
+	"github.com/humanlayer/humanlayer/hld/scratch"
 	"github.com/humanlayer/humanlayer/hld/session"
+	"github.com/humanlayer/humanlayer/hld/share"
+	"github.com/humanlayer/humanlayer/hld/similar"
 	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/humanlayer/humanlayer/hld/twilio"
+	"github.com/humanlayer/humanlayer/hld/webpush"
+	"github.com/humanlayer/humanlayer/hld/workspace"
+	"github.com/humanlayer/humanlayer/hld/worktree"
 )
 
 // getHTTPShutdownTimeout returns the timeout for HTTP server graceful shutdown
@@ -45,17 +76,67 @@ type HTTPServer struct {
 	approvalHandlers     *handlers.ApprovalHandlers
 	fileHandlers         *handlers.FileHandlers
 	sseHandler           *handlers.SSEHandler
+	changesHandler       *handlers.ChangesHandler
+	changeLog            *bus.ChangeLog
 	proxyHandler         *handlers.ProxyHandler
 	configHandler        *handlers.ConfigHandler
 	settingsHandlers     *handlers.SettingsHandlers
 	agentHandlers        *handlers.AgentHandlers
 	ephemeralChatHandler *handlers.EphemeralChatHandler
 	gitHandler           *handlers.GitHandler
+	toolResultHandler    *handlers.ToolResultHandler
+	replayHandler        *handlers.ApprovalReplayHandler
+	policyHandler        *handlers.PolicyHandler
+	budgetHandler        *handlers.BudgetHandler
+	usageHandler         *handlers.UsageHandler
+	activityHandler      *handlers.ActivityHandler
+	schemaHandler        *handlers.SchemaHandler
+	httpCaptureHandler   *handlers.HTTPCaptureHandler
+	promptLogHandler     *handlers.PromptLogHandler
+	sessionStatusHandler *handlers.SessionStatusHandler
+	promptHandler        *handlers.PromptHandler
+	jobHandler           *handlers.JobHandler
+	workingDirHandler    *handlers.WorkingDirHandler
+	transcriptHandler    *handlers.TranscriptHandler
+	shareHandler         *handlers.ShareHandler
+	experimentHandler    *handlers.ExperimentHandler
+	queueHandler         *handlers.QueueHandler
+	statusHandler        *handlers.StatusHandler
+	maintenanceHandler   *handlers.MaintenanceHandler
+	annotationHandler    *handlers.AnnotationHandler
+	feedbackHandler      *handlers.FeedbackHandler
+	similarHandler       *handlers.SimilarSessionsHandler
+	decisionHandler      *handlers.DecisionHandler
+	memoryFileHandler    *handlers.MemoryFileHandler
+	contextPackHandler   *handlers.ContextPackHandler
+	githubHandler        *handlers.GitHubWebhookHandler
+	hookHandler          *handlers.HookHandler
+	emailHandler         *handlers.EmailHandler
+	twilioHandler        *handlers.TwilioHandler
+	ticketHandler        *handlers.TicketHandler
+	credentialsHandler   *handlers.CredentialsHandler
+	scratchHandler       *handlers.ScratchHandler
+	artifactsHandler     *handlers.ArtifactsHandler
+	voiceReplyHandler    *handlers.VoiceReplyHandler
+	webPushHandler       *handlers.WebPushHandler
+	watchesHandler       *handlers.WatchesHandler
+	inboxHandler         *handlers.InboxHandler
+	quickDecisionHandler *handlers.QuickDecisionHandler
+	cannedHandler        *handlers.CannedResponsesHandler
+	holdsHandler         *handlers.HoldsHandler
+	violationsHandler    *handlers.ConstraintViolationsHandler
+	injectionsHandler    *handlers.InjectionsHandler
+	moderationHandler    *handlers.ModerationHandler
+	resolutionsHandler   *handlers.ResolutionsHandler
+	federationHandler    *handlers.FederationHandler // nil unless federated
 	approvalManager      approval.Manager
+	conversationStore    store.Store
 	eventBus             bus.EventBus
 
-	serverMu sync.Mutex
-	server   *http.Server
+	serverMu    sync.Mutex
+	server      *http.Server
+	shareServer *http.Server // Serves share links on sharing.listen, if set
+	port        int          // Bound port; 0 until the server starts
 }
 
 // NewHTTPServer creates a new HTTP server instance
@@ -63,8 +144,12 @@ func NewHTTPServer(
 	cfg *config.Config,
 	sessionManager session.SessionManager,
 	approvalManager approval.Manager,
-	conversationStore store.ConversationStore,
+	approvalPolicy *policy.Policy,
+	shadowPolicy *policy.Policy,
+	conversationStore store.Store,
 	eventBus bus.EventBus,
+	jobManager *jobs.Manager,
+	maintenance *scheduler.Scheduler,
 ) *HTTPServer {
 	// Set Gin mode to release
 	gin.SetMode(gin.ReleaseMode)
@@ -77,7 +162,17 @@ func NewHTTPServer(
 		SkipPaths: []string{"/api/v1/health"}, // Skip health check logs
 	}))
 	router.Use(handlers.RequestIDMiddleware())
+	// Polled status endpoints answer unchanged responses with 304
+	router.Use(handlers.ETagMiddleware(
+		handlers.APIPrefix+"/sessions/:id",
+		handlers.APIPrefix+"/sessions/:id/git/status",
+		handlers.APIPrefix+"/sessions/:id/git/repos",
+	))
 	router.Use(handlers.CompressionMiddleware())
+	captureRecorder := httpcapture.New(cfg.HTTPCapture)
+	if captureRecorder != nil {
+		router.Use(captureRecorder.Middleware())
+	}
 
 	// Add CORS middleware for browser clients
 	router.Use(cors.New(cors.Config{
@@ -91,15 +186,121 @@ func NewHTTPServer(
 
 	// Create handlers
 	sessionHandlers := handlers.NewSessionHandlersWithConfig(sessionManager, conversationStore, approvalManager, cfg)
+	sessionHandlers.SetProcessStore(conversationStore)
+	// Sessions launched with use_worktree get a worktree of their own,
+	// removed once they're archived
+	worktrees := worktree.NewManager(conversationStore, conversationStore, worktree.Dir(cfg.DatabasePath))
+	sessionHandlers.SetWorktreeManager(worktrees)
+	maintenance.Register(worktrees.Task())
 	approvalHandlers := handlers.NewApprovalHandlers(approvalManager, sessionManager)
+	approvalHandlers.SetCannedResponses(conversationStore)
 	fileHandlers := handlers.NewFileHandlers()
 	sseHandler := handlers.NewSSEHandler(eventBus)
+	changeLog := bus.NewChangeLog(bus.DefaultChangeLogSize)
+	changesHandler := handlers.NewChangesHandler(changeLog)
 	proxyHandler := handlers.NewProxyHandler(sessionManager, conversationStore)
 	configHandler := handlers.NewConfigHandler()
 	settingsHandlers := handlers.NewSettingsHandlers(conversationStore)
 	agentHandlers := handlers.NewAgentHandlers()
-	ephemeralChatHandler := handlers.NewEphemeralChatHandler(conversationStore)
-	gitHandler := handlers.NewGitHandler(conversationStore)
+	llmRouter := llm.NewRouter(cfg.LLM)
+	promptLog := promptlog.New(cfg.PromptLog)
+	if promptLog != nil {
+		llmRouter.SetObserver(promptLog)
+	}
+	promptLogHandler := handlers.NewPromptLogHandler(promptLog)
+	promptTemplates := prompts.NewSet(cfg.PromptsDir)
+	ephemeralChatHandler := handlers.NewEphemeralChatHandlerWithRouter(conversationStore, llmRouter, promptTemplates, cfg.Locale)
+	gitHandler := handlers.NewGitHandlerWithRouter(conversationStore, llmRouter, promptTemplates, cfg.Locale, eventBus)
+	ephemeralChatHandler.SetOutputs(conversationStore)
+	ephemeralChatHandler.SetJobManager(jobManager)
+	ephemeralChatHandler.SetPathMappings(cfg.PathMappings)
+	injectionGuard := injection.NewGuard(cfg.PromptInjection, conversationStore)
+	ephemeralChatHandler.SetInjectionGuard(injectionGuard)
+	gitHandler.SetInjectionGuard(injectionGuard)
+	gitHandler.SetRecords(conversationStore, conversationStore)
+	gitHandler.SetJobManager(jobManager)
+	gitHandler.SetPathMappings(cfg.PathMappings)
+	gitHandler.SetDependencyChecker(depcheck.FromConfig(cfg.DependencyCheck), cfg.DependencyCheck.Gate)
+	gitHandler.SetAnalysis(cfg.Analysis)
+	gitHandler.SetLFS(cfg.LFS)
+	gitHandler.SetProvenance(cfg.Provenance)
+	diskGuard := diskguard.New(cfg.DiskGuard, eventBus)
+	gitHandler.SetDiskGuard(diskGuard)
+	toolResultHandler := handlers.NewToolResultHandler(conversationStore, conversationStore)
+	replayHandler := handlers.NewApprovalReplayHandler(conversationStore)
+	policyHandler := handlers.NewPolicyHandler(approvalPolicy, shadowPolicy, conversationStore, conversationStore)
+	budgetHandler := handlers.NewBudgetHandler(conversationStore)
+	usageHandler := handlers.NewUsageHandler(conversationStore, cfg.CostBudgets)
+	activityHandler := handlers.NewActivityHandler(conversationStore, conversationStore, conversationStore, conversationStore)
+	httpCaptureHandler := handlers.NewHTTPCaptureHandler(captureRecorder)
+	sessionStatusHandler := handlers.NewSessionStatusHandler(conversationStore)
+	promptHandler := handlers.NewPromptHandler(promptTemplates)
+	jobHandler := handlers.NewJobHandler(conversationStore)
+	workingDirHandler := handlers.NewWorkingDirHandler(conversationStore, cfg.PathMappings)
+	transcriptHandler := handlers.NewTranscriptHandler(conversationStore, conversationStore, cfg.PathMappings)
+	shareHandler := handlers.NewShareHandler(share.FromConfig(cfg, conversationStore), conversationStore, conversationStore, approvalManager, cfg.PathMappings)
+	if cfg.Sharing.Listen != "" {
+		shareHandler.SetSharedPath("/shared/")
+	}
+	experimentRunner := experiment.NewRunner(conversationStore, sessionManager, eventBus, experiment.WorktreeDir(cfg.DatabasePath))
+	experimentHandler := handlers.NewExperimentHandler(conversationStore, conversationStore, experimentRunner)
+	queueHandler := handlers.NewQueueHandler(sessionManager)
+	annotationHandler := handlers.NewAnnotationHandler(conversationStore)
+	feedbackHandler := handlers.NewFeedbackHandler(conversationStore)
+	// A misconfigured embeddings provider is reported when the daemon starts indexing
+	similarIndex, _ := similar.FromConfig(cfg, conversationStore, conversationStore, eventBus)
+	similarHandler := handlers.NewSimilarSessionsHandler(similarIndex)
+	decisionExtractor := decisions.NewExtractor(conversationStore, conversationStore, llmRouter, promptTemplates, cfg.Locale, eventBus)
+	decisionHandler := handlers.NewDecisionHandler(conversationStore, conversationStore, decisionExtractor)
+	memoryFileProposer := memoryfile.NewProposer(conversationStore, conversationStore, conversationStore, llmRouter, promptTemplates, cfg.Locale)
+	memoryFileHandler := handlers.NewMemoryFileHandler(conversationStore, memoryFileProposer)
+	contextPackHandler := handlers.NewContextPackHandler(conversationStore, conversationStore, contextpack.NewBuilder(conversationStore, conversationStore, similarIndex))
+	githubReceiver := github.NewReceiver(conversationStore, sessionManager, promptTemplates, cfg.GitHub, github.WorktreeDir(cfg.DatabasePath))
+	githubHandler := handlers.NewGitHubWebhookHandler(githubReceiver)
+	hookHandler := handlers.NewHookHandler(hooks.NewReceiver(sessionManager, cfg.Hooks))
+	emailHandler := handlers.NewEmailHandler(cfg.Email, email.TokensFromConfig(cfg), approvalManager, sessionManager)
+	twilioHandler := handlers.NewTwilioHandler(cfg.Twilio, twilio.CodesFromConfig(cfg.DatabasePath), conversationStore, approvalManager)
+	resolutionLedger := resolution.NewLedger(conversationStore)
+	emailHandler.SetResolutionLedger(resolutionLedger)
+	twilioHandler.SetResolutionLedger(resolutionLedger)
+	ticketHandler := handlers.NewTicketHandler(conversationStore, conversationStore)
+	workspace.SetSSHAgent(cfg.GitCredentials.SSHAuthSock, cfg.GitCredentials.ForwardAgent)
+	credentialManager := credentials.NewManager(conversationStore, cfg.GitCredentials, cfg.DatabasePath)
+	gitHandler.SetCredentials(credentialManager)
+	credentialsHandler := handlers.NewCredentialsHandler(credentialManager)
+	scratchHandler := handlers.NewScratchHandler(conversationStore, scratch.New(scratch.Dir(cfg.DatabasePath), cfg.Scratch))
+	artifactService := artifacts.FromConfig(cfg, conversationStore, eventBus)
+	artifactService.SetDiskGuard(diskGuard)
+	approvalHandlers.SetArtifacts(conversationStore, artifactService)
+	artifactsHandler := handlers.NewArtifactsHandler(conversationStore, conversationStore, artifactService)
+	var transcriber handlers.Transcriber
+	if cfg.Transcription.Provider != "" {
+		if t, err := llm.NewTranscriber(cfg.LLM, cfg.Transcription); err != nil {
+			slog.Warn("voice replies disabled", "error", err)
+		} else {
+			transcriber = t
+		}
+	}
+	voiceReplyHandler := handlers.NewVoiceReplyHandler(approvalManager, artifactService, transcriber)
+	undoSeconds := cfg.ApprovalUndoSeconds
+	if undoSeconds == 0 {
+		undoSeconds = config.DefaultApprovalUndoSeconds
+	}
+	quickDecisionHandler := handlers.NewQuickDecisionHandler(approval.NewUndoable(approvalManager, eventBus, time.Duration(undoSeconds)*time.Second))
+	webPushHandler := handlers.NewWebPushHandler(conversationStore, webpush.FromConfig(cfg, conversationStore, conversationStore, eventBus))
+	var federationHandler *handlers.FederationHandler
+	if fc := cfg.Federation; fc.Role != "" {
+		if token := os.Getenv(fc.TokenEnv); token == "" {
+			slog.Warn("federation disabled: token not set", "token_env", fc.TokenEnv)
+		} else {
+			var hub *federation.Hub
+			if fc.Role == config.FederationHub {
+				hub = federation.NewHub(token)
+			}
+			federationHandler = handlers.NewFederationHandler(token, hub, conversationStore, approvalManager)
+			federationHandler.SetResolutionLedger(resolutionLedger)
+		}
+	}
 
 	return &HTTPServer{
 		config:               cfg,
@@ -109,62 +310,92 @@ func NewHTTPServer(
 		approvalHandlers:     approvalHandlers,
 		fileHandlers:         fileHandlers,
 		sseHandler:           sseHandler,
+		changesHandler:       changesHandler,
+		changeLog:            changeLog,
 		proxyHandler:         proxyHandler,
 		configHandler:        configHandler,
 		settingsHandlers:     settingsHandlers,
 		agentHandlers:        agentHandlers,
 		ephemeralChatHandler: ephemeralChatHandler,
 		gitHandler:           gitHandler,
+		toolResultHandler:    toolResultHandler,
+		replayHandler:        replayHandler,
+		policyHandler:        policyHandler,
+		budgetHandler:        budgetHandler,
+		usageHandler:         usageHandler,
+		activityHandler:      activityHandler,
+		schemaHandler:        handlers.NewSchemaHandler(),
+		httpCaptureHandler:   httpCaptureHandler,
+		promptLogHandler:     promptLogHandler,
+		sessionStatusHandler: sessionStatusHandler,
+		promptHandler:        promptHandler,
+		jobHandler:           jobHandler,
+		workingDirHandler:    workingDirHandler,
+		transcriptHandler:    transcriptHandler,
+		shareHandler:         shareHandler,
+		experimentHandler:    experimentHandler,
+		queueHandler:         queueHandler,
+		maintenanceHandler:   handlers.NewMaintenanceHandler(maintenance),
+		statusHandler:        handlers.NewStatusHandler(sessionManager, conversationStore, conversationStore, conversationStore, llmRouter, daemonVersion(cfg)),
+		annotationHandler:    annotationHandler,
+		feedbackHandler:      feedbackHandler,
+		similarHandler:       similarHandler,
+		decisionHandler:      decisionHandler,
+		memoryFileHandler:    memoryFileHandler,
+		contextPackHandler:   contextPackHandler,
+		githubHandler:        githubHandler,
+		hookHandler:          hookHandler,
+		emailHandler:         emailHandler,
+		twilioHandler:        twilioHandler,
+		ticketHandler:        ticketHandler,
+		credentialsHandler:   credentialsHandler,
+		scratchHandler:       scratchHandler,
+		artifactsHandler:     artifactsHandler,
+		voiceReplyHandler:    voiceReplyHandler,
+		webPushHandler:       webPushHandler,
+		watchesHandler:       handlers.NewWatchesHandler(conversationStore, conversationStore),
+		inboxHandler:         handlers.NewInboxHandler(inbox.New(conversationStore, conversationStore, eventBus)),
+		quickDecisionHandler: quickDecisionHandler,
+		cannedHandler:        handlers.NewCannedResponsesHandler(conversationStore),
+		holdsHandler:         handlers.NewHoldsHandler(approval.NewHolds(conversationStore, conversationStore, eventBus)),
+		violationsHandler:    handlers.NewConstraintViolationsHandler(conversationStore),
+		injectionsHandler:    handlers.NewInjectionsHandler(conversationStore),
+		moderationHandler:    handlers.NewModerationHandler(conversationStore),
+		resolutionsHandler:   handlers.NewResolutionsHandler(conversationStore),
+		federationHandler:    federationHandler,
 		approvalManager:      approvalManager,
+		conversationStore:    conversationStore,
 		eventBus:             eventBus,
 	}
 }
 
 // Start starts the HTTP server
 func (s *HTTPServer) Start(ctx context.Context) error {
-	// Create server implementation combining all handlers
-	serverImpl := handlers.NewServerImpl(s.sessionHandlers, s.approvalHandlers, s.fileHandlers, s.sseHandler, s.settingsHandlers, s.agentHandlers)
-
-	// Create strict handler with middleware
-	strictHandler := api.NewStrictHandler(serverImpl, nil)
-
-	// Create API v1 route group
-	v1 := s.router.Group("/api/v1")
-
-	// Register OpenAPI handlers under the v1 group
-	api.RegisterHandlers(v1, strictHandler)
-
-	// Register SSE endpoint directly (not part of strict interface)
-	v1.GET("/stream/events", s.sseHandler.StreamEvents)
-
-	// Register proxy endpoint directly (not part of strict interface)
-	v1.POST("/anthropic_proxy/:session_id/v1/messages", s.proxyHandler.ProxyAnthropicRequest)
-
-	// Register ephemeral chat endpoint (non-persistent AI queries)
-	v1.POST("/ephemeral-chat/:session_id", s.ephemeralChatHandler.HandleEphemeralChat)
-
-	// Register git endpoints (commit functionality) - use :id to match existing session routes
-	v1.GET("/sessions/:id/git/status", s.gitHandler.HandleGetGitStatus)
-	v1.POST("/sessions/:id/git/generate-commit-message", s.gitHandler.HandleGenerateCommitMessage)
-	v1.POST("/sessions/:id/git/commit", s.gitHandler.HandleCommitChanges)
-
-	// Register config status endpoint
-	v1.GET("/config/status", s.configHandler.GetConfigStatus)
-
-	// MCP endpoint (Phase 5: with event-driven approvals)
-	mcpServer := mcp.NewMCPServer(s.approvalManager, s.eventBus)
-	mcpServer.Start(ctx) // Start background processes with context
-	v1.Any("/mcp", func(c *gin.Context) {
-		mcpServer.ServeHTTP(c.Writer, c.Request)
-	})
+	// Register all API routes under the v1 group
+	s.RegisterRoutes(ctx, s.router.Group(handlers.APIPrefix))
 
 	// Create listener first to handle port 0
 	addr := fmt.Sprintf("%s:%d", s.config.HTTPHost, s.config.HTTPPort)
 	listener, err := net.Listen("tcp", addr)
+	if errors.Is(err, syscall.EADDRINUSE) {
+		return fmt.Errorf("port %d on %s is already in use, by another daemon (see hld status) or another program; "+
+			"set HUMANLAYER_DAEMON_HTTP_PORT to a free port, or 0 to pick one: %w", s.config.HTTPPort, s.config.HTTPHost, err)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to listen on %s: %w", addr, err)
 	}
 
+	// Share links get a listener of their own, so they can be exposed
+	// without the API
+	var shareListener net.Listener
+	if shareAddr := s.config.Sharing.Listen; shareAddr != "" {
+		if shareListener, err = net.Listen("tcp", shareAddr); err != nil {
+			_ = listener.Close()
+			return fmt.Errorf("failed to listen on %s for share links: %w", shareAddr, err)
+		}
+		slog.Info("Serving share links", "address", shareListener.Addr().String())
+	}
+
 	// Get actual port after binding
 	actualAddr := listener.Addr().(*net.TCPAddr)
 	actualPort := actualAddr.Port
@@ -183,13 +414,29 @@ func (s *HTTPServer) Start(ctx context.Context) error {
 
 	// Create HTTP server with BaseContext so request contexts are cancelled on shutdown
 	s.serverMu.Lock()
+	s.port = actualPort
 	s.server = &http.Server{
-		Handler: s.router,
+		// Unversioned paths are deprecated aliases of v1
+		Handler: handlers.UnversionedAliases(s.router),
 		BaseContext: func(_ net.Listener) context.Context {
 			return ctx
 		},
 	}
 	server := s.server // Capture for goroutine
+	if shareListener != nil {
+		s.shareServer = &http.Server{
+			Handler: s.shareRouter(),
+			BaseContext: func(_ net.Listener) context.Context {
+				return ctx
+			},
+		}
+		shareServer := s.shareServer
+		go func() {
+			if err := shareServer.Serve(shareListener); err != nil && err != http.ErrServerClosed {
+				slog.Error("share link server error", "error", err)
+			}
+		}()
+	}
 	s.serverMu.Unlock()
 
 	// Start server in goroutine
@@ -206,10 +453,317 @@ func (s *HTTPServer) Start(ctx context.Context) error {
 	return nil
 }
 
+// Port returns the port the server listens on, or 0 before it starts
+func (s *HTTPServer) Port() int {
+	s.serverMu.Lock()
+	defer s.serverMu.Unlock()
+	return s.port
+}
+
+// registerSharedRoutes registers the routes share link holders use
+func (s *HTTPServer) registerSharedRoutes(shared gin.IRouter) {
+	shared.GET("/:token", s.shareHandler.HandleView)
+	shared.GET("/:token/approvals", s.shareHandler.HandleListApprovals)
+	shared.POST("/:token/approvals/:approval_id/decide", s.shareHandler.HandleDecide)
+}
+
+// shareRouter returns a router serving only the share link routes, at
+// /shared, for the sharing.listen listener. Nothing else of the API, nor its
+// CORS policy, is reachable through it.
+func (s *HTTPServer) shareRouter() *gin.Engine {
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.Use(handlers.RequestIDMiddleware())
+	s.registerSharedRoutes(router.Group("/shared"))
+	return router
+}
+
+// SetRiskAssessor requires a comment for approving tool calls the assessor
+// scores at or above approval_justification_risk
+func (s *HTTPServer) SetRiskAssessor(assessor handlers.RiskAssessor) {
+	if s.config.ApprovalJustificationRisk <= 0 {
+		return
+	}
+	j := handlers.NewJustification(assessor, s.config.ApprovalJustificationRisk, s.conversationStore, s.eventBus)
+	s.approvalHandlers.SetJustification(j)
+	s.quickDecisionHandler.SetJustification(j)
+	s.voiceReplyHandler.SetJustification(j)
+	s.emailHandler.SetJustification(j)
+	s.twilioHandler.SetJustification(j)
+	s.shareHandler.SetJustification(j)
+	if s.federationHandler != nil {
+		s.federationHandler.SetJustification(j)
+	}
+}
+
+// RegisterRoutes registers the REST, SSE and MCP routes on v1, which is
+// expected to be mounted at /api/v1. Background work started for the MCP
+// endpoint stops when ctx is cancelled.
+func (s *HTTPServer) RegisterRoutes(ctx context.Context, v1 gin.IRouter) {
+	// Create server implementation combining all handlers
+	serverImpl := handlers.NewServerImpl(s.sessionHandlers, s.approvalHandlers, s.fileHandlers, s.sseHandler, s.settingsHandlers, s.agentHandlers)
+
+	// Create strict handler with middleware
+	strictHandler := api.NewStrictHandler(serverImpl, nil)
+
+	// Register OpenAPI handlers under the v1 group
+	api.RegisterHandlers(v1, strictHandler)
+
+	// Batch status for dashboards, with POST /sessions/status:batch as an alias
+	v1.POST("/sessions/status/batch", s.sessionStatusHandler.HandleBatchStatus)
+	v1.POST("/sessions/status:suffix", s.sessionStatusHandler.HandleBatchStatusAlias)
+
+	// Register SSE endpoint directly (not part of strict interface)
+	v1.GET("/stream/events", s.sseHandler.StreamEvents)
+
+	// Long-poll for clients that can't hold the stream open
+	s.changeLog.Follow(ctx, s.eventBus)
+	v1.GET("/changes", s.changesHandler.HandleGetChanges)
+
+	// Register proxy endpoint directly (not part of strict interface)
+	v1.POST("/anthropic_proxy/:session_id/v1/messages", s.proxyHandler.ProxyAnthropicRequest)
+
+	// Register ephemeral chat endpoint (non-persistent AI queries)
+	v1.POST("/ephemeral-chat/:session_id", s.ephemeralChatHandler.HandleEphemeralChat)
+
+	// Register git endpoints (commit functionality) - use :id to match existing session routes
+	v1.GET("/sessions/:id/git/status", s.gitHandler.HandleGetGitStatus)
+	v1.GET("/sessions/:id/git/repos", s.gitHandler.HandleGetRepositories)
+	v1.POST("/sessions/:id/git/generate-commit-message", s.gitHandler.HandleGenerateCommitMessage)
+	v1.POST("/sessions/:id/git/commit", s.gitHandler.HandleCommitChanges)
+	v1.GET("/sessions/:id/git/reviewers", s.gitHandler.HandleGetReviewers)
+	v1.GET("/sessions/:id/git/patch", s.gitHandler.HandleExportPatch)
+	v1.POST("/sessions/:id/git/apply", s.gitHandler.HandleApplyPatch)
+	v1.POST("/sessions/:id/git/fetch", s.gitHandler.HandleFetch)
+	v1.POST("/sessions/:id/git/pull", s.gitHandler.HandlePull)
+	v1.GET("/sessions/:id/git/branches", s.gitHandler.HandleListBranches)
+	v1.GET("/sessions/:id/git/branches/stale", s.gitHandler.HandleListStaleBranches)
+	v1.POST("/sessions/:id/git/branches/cleanup", s.gitHandler.HandleCleanupBranches)
+	v1.POST("/sessions/:id/git/checkout", s.gitHandler.HandleCheckout)
+	v1.GET("/sessions/:id/git/stash", s.gitHandler.HandleListStashes)
+	v1.POST("/sessions/:id/git/stash", s.gitHandler.HandleCreateStash)
+	v1.POST("/sessions/:id/git/stash/:index/apply", s.gitHandler.HandleApplyStash)
+	v1.POST("/sessions/:id/git/stash/:index/pop", s.gitHandler.HandlePopStash)
+	v1.DELETE("/sessions/:id/git/stash/:index", s.gitHandler.HandleDropStash)
+	v1.GET("/sessions/:id/git/provenance", s.gitHandler.HandleGetProvenance)
+
+	// Register tool result capture endpoint
+	v1.GET("/sessions/:id/tool-results", s.toolResultHandler.HandleListToolResults)
+
+	// Register transcript rendering endpoint
+	v1.GET("/sessions/:id/transcript", s.transcriptHandler.HandleGetTranscript)
+
+	// Register share link endpoints (read-only session views, optionally
+	// with approval rights, for people without daemon access)
+	v1.POST("/sessions/:id/shares", s.shareHandler.HandleCreate)
+	v1.GET("/sessions/:id/shares", s.shareHandler.HandleList)
+	v1.DELETE("/shares/:id", s.shareHandler.HandleRevoke)
+	if s.config.Sharing.Listen == "" {
+		s.registerSharedRoutes(v1.Group("/shared"))
+	}
+
+	// Register conversation event annotation endpoints (notes and bookmarks)
+	v1.POST("/sessions/:id/events/:eid/annotations", s.annotationHandler.HandleCreateAnnotation)
+	v1.GET("/sessions/:id/annotations", s.annotationHandler.HandleListSessionAnnotations)
+	v1.GET("/annotations", s.annotationHandler.HandleListAnnotations)
+	v1.DELETE("/annotations/:id", s.annotationHandler.HandleDeleteAnnotation)
+
+	// Register feedback endpoints (ratings on commit suggestions and chat answers)
+	v1.GET("/outputs/:id", s.feedbackHandler.HandleGetOutput)
+	v1.POST("/outputs/:id/feedback", s.feedbackHandler.HandleSubmitFeedback)
+	v1.GET("/feedback/export", s.feedbackHandler.HandleExportFeedback)
+	v1.GET("/feedback/summary", s.feedbackHandler.HandleFeedbackSummary)
+
+	// Register similar session search endpoints (embeddings of past sessions)
+	v1.GET("/sessions/similar", s.similarHandler.HandleSimilarSessions)
+
+	// Register decision log endpoints (durable decisions per repository)
+	v1.GET("/decisions", s.decisionHandler.HandleListDecisions)
+	v1.POST("/decisions", s.decisionHandler.HandleCreateDecision)
+	v1.GET("/decisions/:id", s.decisionHandler.HandleGetDecision)
+	v1.PATCH("/decisions/:id", s.decisionHandler.HandleUpdateDecision)
+	v1.DELETE("/decisions/:id", s.decisionHandler.HandleDeleteDecision)
+	v1.POST("/sessions/:id/decisions/extract", s.decisionHandler.HandleExtractDecisions)
+
+	// Register memory file endpoints (CLAUDE.md updates approved before commit)
+	v1.POST("/memory-file/proposals", s.memoryFileHandler.HandlePropose)
+	v1.GET("/memory-file/proposals", s.memoryFileHandler.HandleListProposals)
+	v1.GET("/memory-file/proposals/:id", s.memoryFileHandler.HandleGetProposal)
+	v1.POST("/memory-file/proposals/:id/approve", s.memoryFileHandler.HandleApprove)
+	v1.POST("/memory-file/proposals/:id/reject", s.memoryFileHandler.HandleReject)
+
+	// Register context pack endpoints (files, sessions and issues gathered for a launch)
+	v1.POST("/context-packs", s.contextPackHandler.HandleBuildContextPack)
+	v1.GET("/context-packs/:id", s.contextPackHandler.HandleGetContextPack)
+	v1.GET("/sessions/:id/context-pack", s.contextPackHandler.HandleGetSessionContextPack)
+
+	// Register GitHub webhook endpoint (sessions from fix labels and review comments)
+	v1.POST("/github/webhook", s.githubHandler.HandleWebhook)
+
+	// Register inbound hook endpoint (sessions from alerts, forms and other automations)
+	v1.POST("/hooks/:name", s.hookHandler.HandleHook)
+
+	// Register inbound email endpoint (approval replies and sessions requested by mail)
+	v1.POST("/email/inbound", s.emailHandler.HandleInbound)
+
+	// Register Twilio webhook endpoint (SMS and WhatsApp approval replies)
+	v1.POST("/twilio/inbound", s.twilioHandler.HandleInbound)
+
+	// Register ticket endpoints (Linear and Asana tickets referenced in session queries)
+	v1.GET("/sessions/:id/tickets", s.ticketHandler.HandleListSessionTickets)
+
+	// Register scratch space endpoints (session files kept outside the repository)
+	v1.GET("/sessions/:id/files/*path", s.scratchHandler.HandleGetFile)
+	v1.PUT("/sessions/:id/files/*path", s.scratchHandler.HandlePutFile)
+	v1.DELETE("/sessions/:id/files/*path", s.scratchHandler.HandleDeleteFile)
+
+	// Register artifact endpoints (published session outputs with signed download links)
+	v1.POST("/sessions/:id/artifacts", s.artifactsHandler.HandlePublish)
+	v1.GET("/sessions/:id/artifacts", s.artifactsHandler.HandleListSessionArtifacts)
+	v1.GET("/artifacts/:id/link", s.artifactsHandler.HandleGetLink)
+	v1.GET("/artifacts/:id/download", s.artifactsHandler.HandleDownload)
+	v1.DELETE("/artifacts/:id", s.artifactsHandler.HandleDelete)
+	v1.POST("/approvals/:id/voice", s.voiceReplyHandler.HandleVoiceReply)
+	v1.POST("/approvals/:id/quick", s.quickDecisionHandler.HandleDecide)
+	v1.POST("/approvals/:id/undo", s.quickDecisionHandler.HandleUndo)
+	v1.GET("/approvals/held", s.holdsHandler.HandleList)
+	v1.POST("/approvals/:id/hold", s.holdsHandler.HandleHold)
+	v1.DELETE("/approvals/:id/hold", s.holdsHandler.HandleUnhold)
+	v1.GET("/approvals/violations", s.violationsHandler.HandleList)
+	v1.GET("/approvals/rejected-resolutions", s.resolutionsHandler.HandleListRejected)
+	v1.GET("/sessions/:id/constraint-violations", s.violationsHandler.HandleListSession)
+	v1.GET("/sessions/:id/injections", s.injectionsHandler.HandleListSession)
+	v1.GET("/moderation/blocks", s.moderationHandler.HandleListBlocks)
+	v1.POST("/moderation/blocks/:id/review", s.moderationHandler.HandleReviewBlock)
+	v1.GET("/canned-responses", s.cannedHandler.HandleList)
+	v1.POST("/canned-responses", s.cannedHandler.HandleCreate)
+	v1.GET("/canned-responses/:id", s.cannedHandler.HandleGet)
+	v1.PUT("/canned-responses/:id", s.cannedHandler.HandleUpdate)
+	v1.DELETE("/canned-responses/:id", s.cannedHandler.HandleDelete)
+	v1.GET("/push/public-key", s.webPushHandler.HandleGetPublicKey)
+	v1.POST("/push/subscriptions", s.webPushHandler.HandleSubscribe)
+	v1.GET("/push/subscriptions", s.webPushHandler.HandleListSubscriptions)
+	v1.DELETE("/push/subscriptions/:id", s.webPushHandler.HandleUnsubscribe)
+	v1.POST("/push/test", s.webPushHandler.HandleTest)
+	v1.GET("/users/:user/watches", s.watchesHandler.HandleListWatches)
+	v1.POST("/users/:user/watches", s.watchesHandler.HandleWatch)
+	v1.DELETE("/users/:user/watches", s.watchesHandler.HandleUnwatch)
+	v1.GET("/sessions/:id/watchers", s.watchesHandler.HandleListWatchers)
+	v1.GET("/users/:user/inbox", s.inboxHandler.HandleList)
+	v1.GET("/users/:user/inbox/counts", s.inboxHandler.HandleCounts)
+	v1.POST("/users/:user/inbox/read", s.inboxHandler.HandleMarkRead)
+	v1.POST("/users/:user/inbox/unread", s.inboxHandler.HandleMarkUnread)
+	v1.POST("/users/:user/inbox/:id/snooze", s.inboxHandler.HandleSnooze)
+	v1.DELETE("/users/:user/inbox/:id/snooze", s.inboxHandler.HandleUnsnooze)
+
+	// Register federation endpoints; all of them need the federation token
+	if s.federationHandler != nil {
+		fed := v1.Group("/federation", s.federationHandler.Auth())
+		fed.GET("/snapshot", s.federationHandler.HandleSnapshot)
+		fed.POST("/approvals/:id/decide", s.federationHandler.HandleDecide)
+		if s.federationHandler.IsHub() {
+			fed.POST("/members", s.federationHandler.HandleRegister)
+			fed.GET("/members", s.federationHandler.HandleListMembers)
+			fed.GET("/sessions", s.federationHandler.HandleListSessions)
+			fed.GET("/approvals", s.federationHandler.HandleListApprovals)
+			fed.POST("/members/:name/approvals/:id/decide", s.federationHandler.HandleProxyDecide)
+		}
+	}
+
+	// Register git credential endpoints (tokens for authenticated HTTPS remotes)
+	v1.GET("/credentials", s.credentialsHandler.HandleListCredentials)
+	v1.PUT("/credentials", s.credentialsHandler.HandleSaveCredential)
+	v1.DELETE("/credentials", s.credentialsHandler.HandleDeleteCredential)
+	v1.POST("/credentials/test", s.credentialsHandler.HandleTestCredential)
+
+	// Register experiment endpoints (one query launched with several variants)
+	v1.POST("/experiments", s.experimentHandler.HandleCreateExperiment)
+	v1.GET("/experiments", s.experimentHandler.HandleListExperiments)
+	v1.GET("/experiments/:id", s.experimentHandler.HandleGetExperiment)
+
+	// Register launch queue endpoints (sessions waiting for max_concurrent_sessions)
+	v1.GET("/queue", s.queueHandler.HandleGetQueue)
+	v1.DELETE("/queue/:id", s.queueHandler.HandleCancelQueued)
+
+	// Register the status page summary (counts only, no session content)
+	v1.GET("/status/summary", s.statusHandler.HandleGetSummary)
+
+	// Register maintenance task endpoints (schedules, last runs, manual runs)
+	v1.GET("/admin/tasks", s.maintenanceHandler.HandleListTasks)
+	v1.GET("/admin/tasks/:name", s.maintenanceHandler.HandleGetTask)
+	v1.POST("/admin/tasks/:name/run", s.maintenanceHandler.HandleRunTask)
+
+	// Register approval replay endpoints
+	v1.GET("/sessions/:id/approval-scenario", s.replayHandler.HandleExportScenario)
+	v1.POST("/approvals/replay", s.replayHandler.HandleReplay)
+
+	// Register approval policy endpoints
+	v1.GET("/policies", s.policyHandler.HandleGetPolicy)
+	v1.POST("/policies/test", s.policyHandler.HandleTestPolicy)
+	v1.GET("/policies/shadow/report", s.policyHandler.HandleShadowReport)
+
+	// Register session budget usage endpoint
+	v1.GET("/sessions/:id/budget", s.budgetHandler.HandleGetBudget)
+
+	// Register usage reporting endpoints
+	v1.GET("/usage/report", s.usageHandler.HandleGetReport)
+	v1.GET("/usage/budgets", s.usageHandler.HandleGetBudgets)
+
+	// Register activity endpoints
+	v1.GET("/repos/activity", s.activityHandler.HandleGetActivity)
+	v1.GET("/sessions/:id/activity", s.activityHandler.HandleGetSessionActivity)
+
+	// Register JSON Schema endpoints
+	v1.GET("/schemas", s.schemaHandler.HandleListSchemas)
+	v1.GET("/schemas/:name", s.schemaHandler.HandleGetSchema)
+
+	// Register HTTP capture endpoints for debugging
+	v1.GET("/debug/http-captures", s.httpCaptureHandler.HandleListCaptures)
+	v1.DELETE("/debug/http-captures", s.httpCaptureHandler.HandleClearCaptures)
+	v1.GET("/debug/git-commands", s.gitHandler.HandleGetGitMetrics)
+
+	// Register prompt log endpoints (the daemon's own model calls, redacted)
+	v1.GET("/debug/prompt-log", s.promptLogHandler.HandleListPromptLog)
+	v1.GET("/debug/prompt-log/:id", s.promptLogHandler.HandleGetPromptLogEntry)
+	v1.DELETE("/debug/prompt-log", s.promptLogHandler.HandleClearPromptLog)
+
+	// Register prompt template listing endpoint
+	v1.GET("/prompts", s.promptHandler.HandleListPrompts)
+
+	// Background jobs started with ?async=true
+	v1.GET("/jobs", s.jobHandler.HandleListJobs)
+	v1.GET("/jobs/:id", s.jobHandler.HandleGetJob)
+
+	// Sessions whose working directory is missing on this machine
+	v1.GET("/working-dirs/validate", s.workingDirHandler.HandleValidate)
+
+	// Register config status endpoint
+	v1.GET("/config/status", s.configHandler.GetConfigStatus)
+
+	// MCP endpoint (Phase 5: with event-driven approvals)
+	mcpServer := mcp.NewMCPServer(s.approvalManager, s.eventBus)
+	mcpServer.SetStore(s.conversationStore, s.conversationStore, s.conversationStore)
+	mcpServer.Start(ctx) // Start background processes with context
+
+	// Re-expose tools from configured downstream MCP servers behind the approval layer
+	if len(s.config.MCPDownstreamServers) > 0 {
+		downstream := mcp.NewDownstreamManager(s.config.MCPDownstreamServers)
+		downstream.Connect(ctx)
+		mcpServer.RegisterDownstream(downstream)
+		go func() {
+			<-ctx.Done()
+			downstream.Close()
+		}()
+	}
+	v1.Any("/mcp", func(c *gin.Context) {
+		mcpServer.ServeHTTP(c.Writer, c.Request)
+	})
+}
+
 // Shutdown gracefully shuts down the HTTP server
 func (s *HTTPServer) Shutdown() error {
 	s.serverMu.Lock()
-	server := s.server
+	server, shareServer := s.server, s.shareServer
 	s.serverMu.Unlock()
 
 	if server == nil {
@@ -221,5 +775,10 @@ func (s *HTTPServer) Shutdown() error {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
+	if shareServer != nil {
+		if err := shareServer.Shutdown(ctx); err != nil {
+			slog.Warn("failed to shut down share link server", "error", err)
+		}
+	}
 	return server.Shutdown(ctx)
 }
@@ -0,0 +1,71 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/api/handlers"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/internal/testutil"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+func TestShareListenerServesOnlyShareLinks(t *testing.T) {
+	d, err := NewWithConfig(&config.Config{
+		SocketPath:   testutil.SocketPath(t, "share-listen"),
+		DatabasePath: testutil.DatabasePath(t, "share-listen"),
+		HTTPDisabled: true,
+		Sharing:      config.SharingConfig{Listen: "127.0.0.1:0"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create daemon: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := d.store.CreateSession(ctx, &store.Session{ID: "sess-1", RunID: "run-1", ClaudeSessionID: "claude-1", Query: "fix the build", CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	apiRouter := gin.New()
+	d.RegisterRoutes(ctx, apiRouter.Group(handlers.APIPrefix))
+	shareRouter := d.httpServer.shareRouter()
+	serve := func(router http.Handler, method, path string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve(apiRouter, "POST", "/api/v1/sessions/sess-1/shares", `{}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("creating a link: got %d: %s", w.Code, w.Body.String())
+	}
+	var link handlers.ShareLink
+	if err := json.NewDecoder(w.Body).Decode(&link); err != nil {
+		t.Fatalf("failed to decode link: %v", err)
+	}
+	if !strings.HasPrefix(link.URL, "/shared/") {
+		t.Fatalf("link URL %q should point at the share listener", link.URL)
+	}
+
+	if w := serve(shareRouter, "GET", link.URL, ""); w.Code != http.StatusOK {
+		t.Errorf("share listener: got %d for the link: %s", w.Code, w.Body.String())
+	}
+	if w := serve(apiRouter, "GET", "/api/v1"+link.URL, ""); w.Code != http.StatusNotFound {
+		t.Errorf("API: got %d for the link, want 404", w.Code)
+	}
+	for _, path := range []string{"/api/v1/sessions", "/api/v1/sessions/sess-1", "/api/v1/health"} {
+		if w := serve(shareRouter, "GET", path, ""); w.Code != http.StatusNotFound {
+			t.Errorf("share listener: got %d for %s, want 404", w.Code, path)
+		}
+	}
+}
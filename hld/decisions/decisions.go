@@ -0,0 +1,292 @@
+// Package decisions mines completed sessions for durable decisions ("we chose
+// library X because ...") into a per-repository log, and renders a
+// repository's log as context for new sessions.
+package decisions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/gitcmd"
+	"github.com/humanlayer/humanlayer/hld/i18n"
+	"github.com/humanlayer/humanlayer/hld/llm"
+	"github.com/humanlayer/humanlayer/hld/prompts"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/humanlayer/humanlayer/hld/summary"
+)
+
+// maxConversationLines keeps the prompt bounded; decisions are usually
+// explained near the end of a session
+const maxConversationLines = 150
+
+// maxMessageLength truncates individual messages in the prompt
+const maxMessageLength = 1500
+
+// maxContextDecisions caps the decisions added to a new session's prompt
+const maxContextDecisions = 30
+
+// extractTimeout bounds one extraction, including model fallbacks
+const extractTimeout = 3 * time.Minute
+
+var decisionsSchema = llm.Schema{
+	Name:        "decision_log",
+	Description: "Record durable decisions made in a coding agent session",
+	Definition: []byte(`{
+  "type": "object",
+  "properties": {
+    "decisions": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "properties": {
+          "title": {"type": "string"},
+          "decision": {"type": "string"},
+          "rationale": {"type": "string"},
+          "alternatives": {"type": "array", "items": {"type": "string"}}
+        },
+        "required": ["title", "decision", "rationale", "alternatives"]
+      }
+    }
+  },
+  "required": ["decisions"]
+}`),
+}
+
+type extracted struct {
+	Decisions []struct {
+		Title        string   `json:"title"`
+		Decision     string   `json:"decision"`
+		Rationale    string   `json:"rationale"`
+		Alternatives []string `json:"alternatives"`
+	} `json:"decisions"`
+}
+
+func (e *extracted) validate() error {
+	for _, d := range e.Decisions {
+		if strings.TrimSpace(d.Title) == "" || strings.TrimSpace(d.Decision) == "" {
+			return errors.New("every decision needs a title and decision")
+		}
+	}
+	return nil
+}
+
+// promptData are the variables of the decision-log template
+type promptData struct {
+	Query        string
+	Repository   string
+	Summary      string
+	Result       string
+	Conversation []string
+	Existing     []string
+	Language     string
+}
+
+// RepoRoot returns the root of the git repository containing dir, or dir
+// itself when it isn't in one, so decisions apply across a repository's
+// subdirectories
+func RepoRoot(ctx context.Context, dir string) string {
+	if dir == "" {
+		return ""
+	}
+	out, _, err := gitcmd.Run(ctx, gitcmd.Cmd{Dir: dir, Args: []string{"rev-parse", "--show-toplevel"}})
+	if err != nil {
+		return filepath.Clean(dir)
+	}
+	return strings.TrimSpace(out)
+}
+
+// Extractor records the decisions of sessions as they complete
+type Extractor struct {
+	store     store.ConversationStore
+	decisions store.DecisionStore
+	router    *llm.Router
+	templates *prompts.Set
+	locale    string
+	eventBus  bus.EventBus
+
+	inFlight sync.Map // session ID -> struct{}
+}
+
+// NewExtractor creates a decision extractor
+func NewExtractor(s store.ConversationStore, decisions store.DecisionStore, router *llm.Router, templates *prompts.Set, locale string, eventBus bus.EventBus) *Extractor {
+	return &Extractor{store: s, decisions: decisions, router: router, templates: templates, locale: locale, eventBus: eventBus}
+}
+
+// Run extracts decisions from sessions as they complete until ctx is cancelled
+func (x *Extractor) Run(ctx context.Context) {
+	sub := x.eventBus.Subscribe(ctx, bus.EventFilter{
+		Types: []bus.EventType{bus.EventSessionStatusChanged},
+	})
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-sub.Channel:
+			if !ok {
+				return
+			}
+			status, _ := event.Data["new_status"].(string)
+			sessionID, _ := event.Data["session_id"].(string)
+			if status != store.SessionStatusCompleted || sessionID == "" {
+				continue
+			}
+			go func() {
+				if _, err := x.Extract(ctx, sessionID, false); err != nil {
+					slog.Warn("failed to extract decisions", "session_id", sessionID, "error", err)
+				}
+			}()
+		}
+	}
+}
+
+// Extract asks the model for a completed session's decisions and adds them
+// to its repository's log. Sessions that already have decisions are skipped
+// unless force is set, which replaces them.
+func (x *Extractor) Extract(ctx context.Context, sessionID string, force bool) ([]*store.Decision, error) {
+	if _, busy := x.inFlight.LoadOrStore(sessionID, struct{}{}); busy {
+		return nil, fmt.Errorf("decisions are already being extracted for session %s", sessionID)
+	}
+	defer x.inFlight.Delete(sessionID)
+
+	ctx, cancel := context.WithTimeout(ctx, extractTimeout)
+	defer cancel()
+
+	sess, err := x.store.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := x.decisions.ListDecisions(ctx, store.DecisionFilter{SessionID: sessionID})
+	if err != nil {
+		return nil, err
+	}
+	if len(previous) > 0 && !force {
+		return nil, nil
+	}
+	events, err := x.store.GetSessionConversation(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversation: %w", err)
+	}
+	repository := RepoRoot(ctx, sess.WorkingDir)
+	existing, err := x.decisions.ListDecisions(ctx, store.DecisionFilter{Repository: repository, Status: store.DecisionStatusActive})
+	if err != nil {
+		return nil, err
+	}
+
+	data := promptData{
+		Query:        sess.Query,
+		Repository:   repository,
+		Result:       truncate(sess.ResultContent),
+		Conversation: conversationLines(events),
+		Language:     i18n.LanguageName(x.locale),
+	}
+	if s, err := summary.Decode(sess.CompletionSummary); err == nil && s != nil {
+		data.Summary = s.Asked + "\n" + strings.Join(s.Changed, "\n")
+	}
+	for _, d := range existing {
+		if d.SessionID != sessionID {
+			data.Existing = append(data.Existing, "- "+d.Title+": "+d.Decision)
+		}
+	}
+	prompt, err := x.templates.Render(prompts.DecisionLog, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var result extracted
+	if _, err := x.router.CompleteJSON(ctx, llm.TaskSummarization, llm.Request{Prompt: prompt, Schema: &decisionsSchema}, &result, result.validate); err != nil {
+		return nil, err
+	}
+
+	for _, d := range previous {
+		if err := x.decisions.DeleteDecision(ctx, d.ID); err != nil && !errors.Is(err, store.ErrNotFound) {
+			return nil, err
+		}
+	}
+	var created []*store.Decision
+	for _, d := range result.Decisions {
+		decision := &store.Decision{
+			ID:           uuid.New().String(),
+			Repository:   repository,
+			SessionID:    sessionID,
+			Title:        strings.TrimSpace(d.Title),
+			Decision:     strings.TrimSpace(d.Decision),
+			Rationale:    strings.TrimSpace(d.Rationale),
+			Alternatives: d.Alternatives,
+			Status:       store.DecisionStatusActive,
+		}
+		if err := x.decisions.CreateDecision(ctx, decision); err != nil {
+			return nil, err
+		}
+		created = append(created, decision)
+	}
+	if len(created) > 0 {
+		slog.Info("recorded session decisions", "session_id", sessionID, "repository", repository, "decisions", len(created))
+	}
+	return created, nil
+}
+
+// conversationLines flattens user and assistant messages for the prompt
+func conversationLines(events []*store.ConversationEvent) []string {
+	var lines []string
+	for _, event := range events {
+		if event.EventType != store.EventTypeMessage {
+			continue
+		}
+		content := strings.TrimSpace(event.Content)
+		if content == "" {
+			continue
+		}
+		role := "User"
+		if event.Role == "assistant" {
+			role = "Assistant"
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", role, truncate(content)))
+	}
+	if len(lines) > maxConversationLines {
+		lines = append([]string{"..."}, lines[len(lines)-maxConversationLines:]...)
+	}
+	return lines
+}
+
+func truncate(s string) string {
+	if len(s) > maxMessageLength {
+		return s[:maxMessageLength] + "..."
+	}
+	return s
+}
+
+// Context renders the active decisions of the repository containing dir for
+// a session's system prompt, or "" when there are none
+func Context(ctx context.Context, s store.DecisionStore, dir string) (string, error) {
+	repository := RepoRoot(ctx, dir)
+	if repository == "" {
+		return "", nil
+	}
+	decisions, err := s.ListDecisions(ctx, store.DecisionFilter{
+		Repository: repository,
+		Status:     store.DecisionStatusActive,
+		Limit:      maxContextDecisions,
+	})
+	if err != nil || len(decisions) == 0 {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString("Decisions previously made in this repository. Follow them unless the user asks otherwise:\n")
+	for _, d := range decisions {
+		fmt.Fprintf(&b, "- %s: %s", d.Title, d.Decision)
+		if d.Rationale != "" {
+			fmt.Fprintf(&b, " (%s)", d.Rationale)
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimRight(b.String(), "\n"), nil
+}
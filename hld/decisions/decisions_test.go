@@ -0,0 +1,87 @@
+package decisions
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/llm"
+	"github.com/humanlayer/humanlayer/hld/prompts"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newExtractor(s store.Store, provider llm.Provider) *Extractor {
+	router := llm.NewRouterWithProviders(map[string]llm.Provider{"p": provider}, map[llm.Task][]config.LLMRoute{
+		llm.TaskSummarization: {{Provider: "p", Model: "m"}},
+	})
+	return NewExtractor(s, s, router, prompts.Default(), "", bus.NewEventBus())
+}
+
+func TestExtract(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	s := store.NewInMemoryStore()
+	require.NoError(t, s.CreateSession(ctx, &store.Session{
+		ID: "sess-1", RunID: "run-1", ClaudeSessionID: "claude-1", Query: "Add config parsing",
+		WorkingDir: dir, Status: store.SessionStatusCompleted, CreatedAt: time.Now(),
+	}))
+	require.NoError(t, s.AddConversationEvent(ctx, &store.ConversationEvent{
+		SessionID: "sess-1", ClaudeSessionID: "claude-1", EventType: store.EventTypeMessage, Role: "assistant",
+		Content: "I'll use viper because the daemon already depends on it.",
+	}))
+	require.NoError(t, s.CreateDecision(ctx, &store.Decision{
+		ID: "old", Repository: dir, Title: "Logging", Decision: "Use slog", Status: store.DecisionStatusActive,
+	}))
+
+	provider := &llm.FakeProvider{Response: `{"decisions":[{"title":"Config library","decision":"Use viper","rationale":"Already a dependency","alternatives":["koanf"]}]}`}
+	x := newExtractor(s, provider)
+
+	created, err := x.Extract(ctx, "sess-1", false)
+	require.NoError(t, err)
+	require.Len(t, created, 1)
+	assert.Equal(t, dir, created[0].Repository)
+	assert.Equal(t, "sess-1", created[0].SessionID)
+	assert.Equal(t, []string{"koanf"}, created[0].Alternatives)
+	require.Len(t, provider.Prompts(), 1)
+	assert.Contains(t, provider.Prompts()[0], "Assistant: I'll use viper")
+	assert.Contains(t, provider.Prompts()[0], "- Logging: Use slog", "existing decisions are listed so they aren't repeated")
+
+	// Already extracted: skipped unless forced, and forcing replaces
+	created, err = x.Extract(ctx, "sess-1", false)
+	require.NoError(t, err)
+	assert.Nil(t, created)
+	assert.Len(t, provider.Prompts(), 1)
+
+	_, err = x.Extract(ctx, "sess-1", true)
+	require.NoError(t, err)
+	fromSession, err := s.ListDecisions(ctx, store.DecisionFilter{SessionID: "sess-1"})
+	require.NoError(t, err)
+	assert.Len(t, fromSession, 1)
+}
+
+func TestContext(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	s := store.NewInMemoryStore()
+
+	text, err := Context(ctx, s, dir)
+	require.NoError(t, err)
+	assert.Empty(t, text)
+
+	require.NoError(t, s.CreateDecision(ctx, &store.Decision{
+		ID: "a", Repository: dir, Title: "Config library", Decision: "Use viper", Rationale: "Already a dependency",
+		Status: store.DecisionStatusActive,
+	}))
+	require.NoError(t, s.CreateDecision(ctx, &store.Decision{
+		ID: "b", Repository: dir, Title: "ORM", Decision: "Use gorm", Status: store.DecisionStatusSuperseded,
+	}))
+
+	text, err = Context(ctx, s, dir)
+	require.NoError(t, err)
+	assert.Contains(t, text, "- Config library: Use viper (Already a dependency)")
+	assert.NotContains(t, text, "gorm", "only active decisions are given to sessions")
+}
@@ -0,0 +1,128 @@
+// Package denial expands terse denial comments ("no", "wrong dir") into
+// actionable guidance for the agent. The approver's comment is kept as
+// written, and the expansion is stored alongside it and sent to the agent
+// marked as AI-expanded.
+package denial
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/approval"
+	"github.com/humanlayer/humanlayer/hld/feedback"
+	"github.com/humanlayer/humanlayer/hld/llm"
+	"github.com/humanlayer/humanlayer/hld/prompts"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// maxTerseWords is the longest comment that gets expanded; longer comments
+// already say what the approver wants
+const maxTerseWords = 12
+
+// maxInputLength truncates the tool input in the prompt
+const maxInputLength = 2000
+
+// expandTimeout bounds one expansion, including model fallbacks. The agent
+// is waiting on the denial, so a slow model is given up on and the comment
+// sent as written.
+const expandTimeout = 20 * time.Second
+
+// promptData are the variables of the denial-expansion template
+type promptData struct {
+	Reason    string
+	ToolName  string
+	ToolInput string
+	Query     string
+}
+
+// Expander is an approval manager that expands terse denial comments before
+// passing denials on
+type Expander struct {
+	approval.Manager
+	store     store.ConversationStore
+	outputs   store.AIOutputStore
+	router    *llm.Router
+	templates *prompts.Set
+}
+
+// NewExpander wraps manager so that its denials with terse comments are
+// expanded, recording each expansion in outputs for feedback
+func NewExpander(manager approval.Manager, s store.ConversationStore, outputs store.AIOutputStore, router *llm.Router, templates *prompts.Set) *Expander {
+	return &Expander{Manager: manager, store: s, outputs: outputs, router: router, templates: templates}
+}
+
+// Terse reports whether a denial comment is short enough to expand
+func Terse(reason string) bool {
+	words := len(strings.Fields(reason))
+	return words > 0 && words <= maxTerseWords
+}
+
+// DenyToolCall denies a tool call, first expanding a terse reason. If the
+// expansion fails the denial goes ahead with the reason as written.
+func (e *Expander) DenyToolCall(ctx context.Context, id string, reason string, imagePaths []string) error {
+	if Terse(reason) {
+		if err := e.expand(ctx, id, reason); err != nil {
+			slog.Warn("failed to expand denial comment", "approval_id", id, "error", err)
+		}
+	}
+	return e.Manager.DenyToolCall(ctx, id, reason, imagePaths)
+}
+
+// expand asks the model for guidance and stores it on the pending approval
+func (e *Expander) expand(ctx context.Context, id, reason string) error {
+	ctx, cancel := context.WithTimeout(ctx, expandTimeout)
+	defer cancel()
+
+	a, err := e.store.GetApproval(ctx, id)
+	if err != nil {
+		return err
+	}
+	if a.Status != store.ApprovalStatusLocalPending {
+		// The denial will fail; don't pay for an expansion
+		return nil
+	}
+	data := promptData{Reason: reason, ToolName: a.ToolName, ToolInput: truncate(string(a.ToolInput))}
+	if session, err := e.store.GetSession(ctx, a.SessionID); err == nil {
+		data.Query = session.Query
+	}
+
+	prompt, err := e.templates.Render(prompts.DenialExpansion, data)
+	if err != nil {
+		return err
+	}
+	resp, err := e.router.Complete(ctx, llm.TaskSummarization, llm.Request{Prompt: prompt, MaxTokens: 512})
+	if err != nil {
+		return err
+	}
+	expansion := strings.TrimSpace(resp.Text)
+	if expansion == "" {
+		return fmt.Errorf("model returned an empty expansion")
+	}
+	if err := e.store.SetApprovalCommentExpansion(ctx, id, expansion); err != nil {
+		return err
+	}
+
+	if err := feedback.Record(ctx, e.outputs, &store.AIOutput{
+		Kind:            store.AIOutputDenialExpansion,
+		SessionID:       a.SessionID,
+		Template:        prompts.DenialExpansion,
+		TemplateVersion: e.templates.Version(prompts.DenialExpansion),
+		Provider:        resp.Provider,
+		Model:           resp.Model,
+		Prompt:          prompt,
+		Response:        expansion,
+	}); err != nil {
+		slog.Warn("failed to record AI output for feedback", "kind", store.AIOutputDenialExpansion, "error", err)
+	}
+	return nil
+}
+
+func truncate(s string) string {
+	if len(s) <= maxInputLength {
+		return s
+	}
+	return s[:maxInputLength] + "..."
+}
@@ -0,0 +1,97 @@
+package denial
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/approval"
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/llm"
+	"github.com/humanlayer/humanlayer/hld/prompts"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setup(t *testing.T, provider *llm.FakeProvider) (*Expander, store.Store, *bus.Subscriber) {
+	t.Helper()
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	require.NoError(t, s.CreateSession(ctx, &store.Session{
+		ID: "sess-1", RunID: "run-1", Query: "Add a settings page", Status: store.SessionStatusWaitingInput, CreatedAt: time.Now(),
+	}))
+	for _, id := range []string{"appr-1", "appr-2"} {
+		require.NoError(t, s.CreateApproval(ctx, &store.Approval{
+			ID: id, RunID: "run-1", SessionID: "sess-1", Status: store.ApprovalStatusLocalPending, CreatedAt: time.Now(),
+			ToolName: "Write", ToolInput: []byte(`{"file_path":"/work/src/settings.tsx"}`),
+		}))
+	}
+	router := llm.NewRouterWithProviders(map[string]llm.Provider{"p": provider}, map[llm.Task][]config.LLMRoute{
+		llm.TaskSummarization: {{Provider: "p", Model: "m"}},
+	})
+	eventBus := bus.NewEventBus()
+	sub := eventBus.Subscribe(ctx, bus.EventFilter{Types: []bus.EventType{bus.EventApprovalResolved}})
+	return NewExpander(approval.NewManager(s, eventBus), s, s, router, prompts.Default()), s, sub
+}
+
+func resolvedText(t *testing.T, sub *bus.Subscriber) string {
+	t.Helper()
+	select {
+	case event := <-sub.Channel:
+		text, _ := event.Data["response_text"].(string)
+		return text
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for approval_resolved")
+		return ""
+	}
+}
+
+func TestExpanderExpandsTerseDenials(t *testing.T) {
+	ctx := context.Background()
+	provider := &llm.FakeProvider{Response: "The approver denied this because pages live in apps/web; consider writing apps/web/src/settings.tsx instead."}
+	e, s, sub := setup(t, provider)
+
+	require.NoError(t, e.DenyToolCall(ctx, "appr-1", "wrong dir", nil))
+	require.Len(t, provider.Prompts(), 1)
+	assert.Contains(t, provider.Prompts()[0], "Approver's comment: wrong dir")
+	assert.Contains(t, provider.Prompts()[0], "The agent's task: Add a settings page")
+
+	a, err := s.GetApproval(ctx, "appr-1")
+	require.NoError(t, err)
+	assert.Equal(t, "wrong dir", a.Comment, "the approver's comment is kept as written")
+	assert.Equal(t, provider.Response, a.CommentExpansion)
+	assert.Equal(t, "wrong dir\n\n[AI-expanded from the approver's comment] "+provider.Response, resolvedText(t, sub))
+
+	outputs, err := s.ListAIOutputs(ctx, store.AIOutputFilter{Kind: store.AIOutputDenialExpansion})
+	require.NoError(t, err)
+	assert.Len(t, outputs, 1)
+
+	// A detailed comment is sent as written
+	long := "Please don't add pages under src; this repository keeps every page in apps/web, so write it there."
+	require.NoError(t, e.DenyToolCall(ctx, "appr-2", long, nil))
+	assert.Len(t, provider.Prompts(), 1)
+	assert.Equal(t, long, resolvedText(t, sub))
+}
+
+func TestExpanderFallsBackToComment(t *testing.T) {
+	ctx := context.Background()
+	e, s, sub := setup(t, &llm.FakeProvider{Err: errors.New("overloaded")})
+
+	require.NoError(t, e.DenyToolCall(ctx, "appr-1", "no", nil))
+	a, err := s.GetApproval(ctx, "appr-1")
+	require.NoError(t, err)
+	assert.Equal(t, store.ApprovalStatusLocalDenied, a.Status)
+	assert.Empty(t, a.CommentExpansion)
+	assert.Equal(t, "no", resolvedText(t, sub))
+}
+
+func TestTerse(t *testing.T) {
+	assert.True(t, Terse("no"))
+	assert.True(t, Terse("wrong dir, use apps/web"))
+	assert.False(t, Terse(""))
+	assert.False(t, Terse(strings.Repeat("word ", 13)))
+}
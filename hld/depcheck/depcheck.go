@@ -0,0 +1,246 @@
+// Package depcheck finds the dependencies a change adds to a go.mod,
+// package.json or requirements.txt, and looks up their licenses on deps.dev
+// and their known vulnerabilities on OSV.
+package depcheck
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/config"
+)
+
+// Default lookup endpoints
+const (
+	DefaultOSVURL     = "https://api.osv.dev"
+	DefaultDepsDevURL = "https://api.deps.dev"
+)
+
+const (
+	// maxLookups caps the dependencies looked up for one change
+	maxLookups = 50
+	// parallelLookups is how many dependencies are looked up at once
+	parallelLookups = 8
+)
+
+// Vulnerability is an OSV advisory affecting a dependency's version
+type Vulnerability struct {
+	ID       string   `json:"id"`
+	Summary  string   `json:"summary,omitempty"`
+	Aliases  []string `json:"aliases,omitempty"`
+	Severity string   `json:"severity,omitempty"` // As the advisory database rates it, where it does
+}
+
+// Finding is what the lookups found for one dependency
+type Finding struct {
+	Dependency
+	Licenses []string `json:"licenses,omitempty"`
+	// DeniedLicenses are the licenses that matched the deny list
+	DeniedLicenses  []string        `json:"deniedLicenses,omitempty"`
+	Vulnerabilities []Vulnerability `json:"vulnerabilities,omitempty"`
+	// Error explains a lookup that failed or was skipped
+	Error string `json:"error,omitempty"`
+}
+
+// Blocking reports whether the finding should stop a gated commit
+func (f Finding) Blocking() bool {
+	return len(f.Vulnerabilities) > 0 || len(f.DeniedLicenses) > 0
+}
+
+// Blocking returns the findings that should stop a gated commit
+func Blocking(findings []Finding) []Finding {
+	var blocking []Finding
+	for _, f := range findings {
+		if f.Blocking() {
+			blocking = append(blocking, f)
+		}
+	}
+	return blocking
+}
+
+// Checker looks up dependencies' licenses and vulnerabilities
+type Checker struct {
+	client     *http.Client
+	osvURL     string
+	depsDevURL string
+	denied     map[string]bool // Lower-cased SPDX identifiers
+}
+
+// New creates a checker; empty URLs use the public endpoints
+func New(osvURL, depsDevURL string, deniedLicenses []string) *Checker {
+	if osvURL == "" {
+		osvURL = DefaultOSVURL
+	}
+	if depsDevURL == "" {
+		depsDevURL = DefaultDepsDevURL
+	}
+	denied := make(map[string]bool, len(deniedLicenses))
+	for _, license := range deniedLicenses {
+		denied[strings.ToLower(license)] = true
+	}
+	return &Checker{
+		client:     &http.Client{Timeout: 10 * time.Second},
+		osvURL:     strings.TrimRight(osvURL, "/"),
+		depsDevURL: strings.TrimRight(depsDevURL, "/"),
+		denied:     denied,
+	}
+}
+
+// FromConfig creates the configured checker, or nil if checks are off
+func FromConfig(cfg config.DependencyCheckConfig) *Checker {
+	if !cfg.Enabled {
+		return nil
+	}
+	return New(cfg.OSVURL, cfg.DepsDevURL, cfg.DeniedLicenses)
+}
+
+// Check looks up each dependency. Lookup failures are reported on the
+// finding rather than failing the check.
+func (c *Checker) Check(ctx context.Context, deps []Dependency) []Finding {
+	findings := make([]Finding, len(deps))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, parallelLookups)
+	for i, dep := range deps {
+		findings[i].Dependency = dep
+		switch {
+		case i >= maxLookups:
+			findings[i].Error = fmt.Sprintf("not checked: a change may add at most %d dependencies to check", maxLookups)
+			continue
+		case dep.Version == "":
+			findings[i].Error = "not checked: no pinned version"
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(f *Finding) {
+			defer func() { <-sem; wg.Done() }()
+			c.lookup(ctx, f)
+		}(&findings[i])
+	}
+	wg.Wait()
+	return findings
+}
+
+func (c *Checker) lookup(ctx context.Context, f *Finding) {
+	var errs []string
+	vulns, err := c.vulnerabilities(ctx, f.Dependency)
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("vulnerability lookup: %v", err))
+	}
+	f.Vulnerabilities = vulns
+
+	licenses, err := c.licenses(ctx, f.Dependency)
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("license lookup: %v", err))
+	}
+	f.Licenses = licenses
+	for _, license := range licenses {
+		if c.isDenied(license) {
+			f.DeniedLicenses = append(f.DeniedLicenses, license)
+		}
+	}
+	f.Error = strings.Join(errs, "; ")
+}
+
+// isDenied reports whether an SPDX expression is denied: for
+// "A OR B", only when every alternative includes a denied license
+func (c *Checker) isDenied(expression string) bool {
+	if len(c.denied) == 0 {
+		return false
+	}
+	expression = strings.NewReplacer("(", " ", ")", " ").Replace(expression)
+	for _, alternative := range strings.Split(expression, " OR ") {
+		denied := false
+		for _, id := range strings.Fields(alternative) {
+			if c.denied[strings.ToLower(id)] {
+				denied = true
+				break
+			}
+		}
+		if !denied {
+			return false
+		}
+	}
+	return true
+}
+
+// vulnerabilities queries OSV for advisories affecting the version
+func (c *Checker) vulnerabilities(ctx context.Context, dep Dependency) ([]Vulnerability, error) {
+	// OSV's Go versions are semver without the v
+	query := map[string]interface{}{
+		"package": map[string]string{"name": dep.Name, "ecosystem": dep.Ecosystem},
+		"version": strings.TrimPrefix(dep.Version, "v"),
+	}
+	var result struct {
+		Vulns []struct {
+			ID               string   `json:"id"`
+			Summary          string   `json:"summary"`
+			Aliases          []string `json:"aliases"`
+			DatabaseSpecific struct {
+				Severity string `json:"severity"`
+			} `json:"database_specific"`
+		} `json:"vulns"`
+	}
+	if err := c.do(ctx, http.MethodPost, c.osvURL+"/v1/query", query, &result); err != nil {
+		return nil, err
+	}
+	vulns := make([]Vulnerability, len(result.Vulns))
+	for i, v := range result.Vulns {
+		vulns[i] = Vulnerability{ID: v.ID, Summary: v.Summary, Aliases: v.Aliases, Severity: v.DatabaseSpecific.Severity}
+	}
+	return vulns, nil
+}
+
+var depsDevSystems = map[string]string{
+	EcosystemGo:   "go",
+	EcosystemNPM:  "npm",
+	EcosystemPyPI: "pypi",
+}
+
+// licenses asks deps.dev for the version's SPDX license expressions
+func (c *Checker) licenses(ctx context.Context, dep Dependency) ([]string, error) {
+	endpoint := fmt.Sprintf("%s/v3/systems/%s/packages/%s/versions/%s", c.depsDevURL,
+		depsDevSystems[dep.Ecosystem], url.PathEscape(dep.Name), url.PathEscape(dep.Version))
+	var result struct {
+		Licenses []string `json:"licenses"`
+	}
+	if err := c.do(ctx, http.MethodGet, endpoint, nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Licenses, nil
+}
+
+func (c *Checker) do(ctx context.Context, method, endpoint string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", endpoint, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
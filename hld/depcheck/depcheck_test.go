@@ -0,0 +1,136 @@
+package depcheck
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff_GoMod(t *testing.T) {
+	before := `module example.com/app
+
+go 1.24
+
+require github.com/pkg/errors v0.9.1
+
+require (
+	github.com/stretchr/testify v1.8.0
+	golang.org/x/text v0.3.0 // indirect
+)
+`
+	after := `module example.com/app
+
+go 1.24
+
+require github.com/pkg/errors v0.9.1
+
+require (
+	github.com/stretchr/testify v1.9.0
+	golang.org/x/text v0.3.0 // indirect
+	github.com/google/uuid v1.6.0
+)
+`
+	deps := Diff("go.mod", []byte(before), []byte(after))
+	assert.Equal(t, []Dependency{
+		{Ecosystem: EcosystemGo, Name: "github.com/google/uuid", Version: "v1.6.0", Change: ChangeAdded, File: "go.mod"},
+		{Ecosystem: EcosystemGo, Name: "github.com/stretchr/testify", Version: "v1.9.0", OldVersion: "v1.8.0", Change: ChangeUpgraded, File: "go.mod"},
+	}, deps)
+}
+
+func TestDiff_PackageJSON(t *testing.T) {
+	before := `{"dependencies": {"react": "^18.2.0"}}`
+	after := `{"dependencies": {"react": "^18.2.0", "lodash": "~4.17.21", "next": "latest"}, "devDependencies": {"vitest": "1.6.0"}}`
+	deps := Diff("web/package.json", []byte(before), []byte(after))
+	require.Len(t, deps, 3)
+	assert.Equal(t, "lodash", deps[0].Name)
+	assert.Equal(t, "4.17.21", deps[0].Version)
+	assert.Equal(t, "next", deps[1].Name)
+	assert.Empty(t, deps[1].Version, "tags have no version to look up")
+	assert.Equal(t, "vitest", deps[2].Name)
+	assert.Equal(t, EcosystemNPM, deps[2].Ecosystem)
+}
+
+func TestDiff_Requirements(t *testing.T) {
+	after := "# pinned\nrequests==2.31.0\nDjango_REST-framework[extra] == 3.15.1 ; python_version > '3.8'\nflask>=2\n-r base.txt\n"
+	deps := Diff("requirements-dev.txt", nil, []byte(after))
+	assert.Equal(t, []Dependency{
+		{Ecosystem: EcosystemPyPI, Name: "django-rest-framework", Version: "3.15.1", Change: ChangeAdded, File: "requirements-dev.txt"},
+		{Ecosystem: EcosystemPyPI, Name: "flask", Change: ChangeAdded, File: "requirements-dev.txt"},
+		{Ecosystem: EcosystemPyPI, Name: "requests", Version: "2.31.0", Change: ChangeAdded, File: "requirements-dev.txt"},
+	}, deps)
+
+	assert.Nil(t, Diff("Cargo.toml", nil, []byte("[dependencies]")))
+}
+
+func newLookupServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/query":
+			var query struct {
+				Package struct{ Name, Ecosystem string }
+				Version string
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&query))
+			if query.Package.Name == "lodash" && query.Version == "4.17.15" {
+				_, _ = w.Write([]byte(`{"vulns":[{"id":"GHSA-p6mc-m468-83gw","summary":"Prototype pollution","aliases":["CVE-2020-8203"],"database_specific":{"severity":"HIGH"}}]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{}`))
+		case strings.HasPrefix(r.URL.Path, "/v3/systems/npm/packages/"):
+			switch r.URL.Path {
+			case "/v3/systems/npm/packages/copyleft/versions/1.0.0":
+				_, _ = w.Write([]byte(`{"licenses":["GPL-3.0-only"]}`))
+			case "/v3/systems/npm/packages/dual/versions/1.0.0":
+				_, _ = w.Write([]byte(`{"licenses":["(MIT OR GPL-3.0-only)"]}`))
+			case "/v3/systems/npm/packages/missing/versions/1.0.0":
+				http.NotFound(w, r)
+			default:
+				_, _ = w.Write([]byte(`{"licenses":["MIT"]}`))
+			}
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+}
+
+func TestChecker_Check(t *testing.T) {
+	server := newLookupServer(t)
+	defer server.Close()
+	checker := New(server.URL, server.URL+"/", []string{"gpl-3.0-only"})
+
+	dep := func(name, version string) Dependency {
+		return Dependency{Ecosystem: EcosystemNPM, Name: name, Version: version, Change: ChangeAdded, File: "package.json"}
+	}
+	findings := checker.Check(context.Background(), []Dependency{
+		dep("lodash", "4.17.15"),
+		dep("copyleft", "1.0.0"),
+		dep("dual", "1.0.0"),
+		dep("missing", "1.0.0"),
+		dep("next", ""),
+	})
+	require.Len(t, findings, 5)
+
+	lodash := findings[0]
+	require.Len(t, lodash.Vulnerabilities, 1)
+	assert.Equal(t, "GHSA-p6mc-m468-83gw", lodash.Vulnerabilities[0].ID)
+	assert.Equal(t, "HIGH", lodash.Vulnerabilities[0].Severity)
+	assert.Equal(t, []string{"MIT"}, lodash.Licenses)
+	assert.True(t, lodash.Blocking())
+
+	assert.Equal(t, []string{"GPL-3.0-only"}, findings[1].DeniedLicenses)
+	assert.Empty(t, findings[2].DeniedLicenses, "MIT may be chosen instead")
+	assert.Contains(t, findings[3].Error, "license lookup")
+	assert.False(t, findings[3].Blocking(), "lookup failures don't block")
+	assert.Equal(t, "not checked: no pinned version", findings[4].Error)
+
+	blocking := Blocking(findings)
+	require.Len(t, blocking, 2)
+	assert.Equal(t, "lodash", blocking[0].Name)
+	assert.Equal(t, "copyleft", blocking[1].Name)
+}
@@ -0,0 +1,165 @@
+package depcheck
+
+import (
+	"bufio"
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Ecosystems, named as OSV names them
+const (
+	EcosystemGo   = "Go"
+	EcosystemNPM  = "npm"
+	EcosystemPyPI = "PyPI"
+)
+
+// Changes to a dependency
+const (
+	ChangeAdded    = "added"
+	ChangeUpgraded = "upgraded" // Any version change, up or down
+)
+
+// Dependency is a dependency a manifest change adds or moves to a new version
+type Dependency struct {
+	Ecosystem  string `json:"ecosystem"`
+	Name       string `json:"name"`
+	Version    string `json:"version,omitempty"` // Empty when the manifest doesn't pin one
+	OldVersion string `json:"oldVersion,omitempty"`
+	Change     string `json:"change"`
+	File       string `json:"file"`
+}
+
+// IsManifest reports whether path is a manifest this package understands
+func IsManifest(path string) bool {
+	return ecosystem(path) != ""
+}
+
+func ecosystem(path string) string {
+	base := filepath.Base(path)
+	switch {
+	case base == "go.mod":
+		return EcosystemGo
+	case base == "package.json":
+		return EcosystemNPM
+	case base == "requirements.txt", strings.HasPrefix(base, "requirements") && strings.HasSuffix(base, ".txt"):
+		return EcosystemPyPI
+	}
+	return ""
+}
+
+// Diff returns the dependencies added or changed between two versions of a
+// manifest. before is empty for a new file. Removed dependencies aren't
+// reported: they can't bring in a license or vulnerability.
+func Diff(path string, before, after []byte) []Dependency {
+	eco := ecosystem(path)
+	if eco == "" {
+		return nil
+	}
+	parse := map[string]func([]byte) map[string]string{
+		EcosystemGo:   parseGoMod,
+		EcosystemNPM:  parsePackageJSON,
+		EcosystemPyPI: parseRequirements,
+	}[eco]
+	old, current := parse(before), parse(after)
+
+	var deps []Dependency
+	for name, version := range current {
+		oldVersion, existed := old[name]
+		switch {
+		case !existed:
+			deps = append(deps, Dependency{Ecosystem: eco, Name: name, Version: version, Change: ChangeAdded, File: path})
+		case oldVersion != version:
+			deps = append(deps, Dependency{Ecosystem: eco, Name: name, Version: version, OldVersion: oldVersion, Change: ChangeUpgraded, File: path})
+		}
+	}
+	sort.Slice(deps, func(i, j int) bool { return deps[i].Name < deps[j].Name })
+	return deps
+}
+
+var goRequire = regexp.MustCompile(`^(\S+)\s+(v\S+)`)
+
+// parseGoMod reads the require directives of a go.mod, single-line and block
+func parseGoMod(content []byte) map[string]string {
+	deps := make(map[string]string)
+	inBlock := false
+	scanner := bufio.NewScanner(strings.NewReader(string(content)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		switch {
+		case inBlock && line == ")":
+			inBlock = false
+			continue
+		case line == "require (":
+			inBlock = true
+			continue
+		case strings.HasPrefix(line, "require "):
+			line = strings.TrimSpace(strings.TrimPrefix(line, "require "))
+		case !inBlock:
+			continue
+		}
+		if m := goRequire.FindStringSubmatch(line); m != nil {
+			deps[m[1]] = m[2]
+		}
+	}
+	return deps
+}
+
+// parsePackageJSON reads every dependency section of a package.json. Caret
+// and tilde ranges resolve to the version they start from.
+func parsePackageJSON(content []byte) map[string]string {
+	var manifest map[string]json.RawMessage
+	if json.Unmarshal(content, &manifest) != nil {
+		return nil
+	}
+	deps := make(map[string]string)
+	for _, section := range []string{"dependencies", "devDependencies", "optionalDependencies", "peerDependencies"} {
+		var entries map[string]string
+		if json.Unmarshal(manifest[section], &entries) != nil {
+			continue
+		}
+		for name, spec := range entries {
+			deps[name] = npmVersion(spec)
+		}
+	}
+	return deps
+}
+
+var npmPinned = regexp.MustCompile(`^[\^~=v]*\s*(\d+\.\d+\.\d+[-+\w.]*)$`)
+
+// npmVersion returns the version a simple spec (1.2.3, ^1.2.3, ~1.2.3)
+// starts from, or "" for ranges, tags, URLs and workspaces
+func npmVersion(spec string) string {
+	if m := npmPinned.FindStringSubmatch(strings.TrimSpace(spec)); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+var (
+	pipRequirement = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(\[[^\]]*\])?\s*(?:(==|===)\s*([^\s;,#]+))?`)
+	pipSeparators  = regexp.MustCompile(`[-_.]+`)
+)
+
+// parseRequirements reads a requirements.txt; only == pins carry a version
+func parseRequirements(content []byte) map[string]string {
+	deps := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(string(content)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") {
+			continue
+		}
+		if m := pipRequirement.FindStringSubmatch(line); m != nil {
+			// PEP 503 normalization, so Foo_Bar and foo-bar are the same package
+			name := strings.ToLower(pipSeparators.ReplaceAllString(m[1], "-"))
+			deps[name] = m[4]
+		}
+	}
+	return deps
+}
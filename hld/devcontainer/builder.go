@@ -0,0 +1,197 @@
+package devcontainer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Setup stages reported to a Progress callback
+const (
+	StageCached     = "cached"      // A previously prepared image matches the configuration
+	StagePulling    = "pulling"     // Pulling the configured image
+	StageBuilding   = "building"    // Building the Dockerfile
+	StagePostCreate = "post_create" // Running postCreateCommand
+	StageReady      = "ready"       // The image is ready for the agent
+	StageFailed     = "failed"      // Setup failed; detail holds the error
+)
+
+// imageRepository names the images Prepare creates
+const imageRepository = "hld-devcontainer"
+
+// Progress receives each setup stage with a human-readable detail
+type Progress func(stage, detail string)
+
+// Builder prepares devcontainer images with a container runtime CLI
+type Builder struct {
+	runtime string
+	run     func(ctx context.Context, name string, args ...string) ([]byte, error)
+}
+
+// NewBuilder creates a builder using runtime ("docker" or "podman")
+func NewBuilder(runtime string) *Builder {
+	return &Builder{runtime: runtime, run: runCommand}
+}
+
+func runCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if err != nil {
+		return out, fmt.Errorf("%s %s: %w: %s", name, args[0], err, lastLines(out, 5))
+	}
+	return out, nil
+}
+
+// Prepare returns an image with the devcontainer's toolchain, building it on
+// first use. postCreateCommand runs once with workingDir mounted at its host
+// path and the result is committed, so later sessions start immediately.
+// Images are keyed by the configuration's content and reused until it changes.
+func (b *Builder) Prepare(ctx context.Context, cfg *Config, workingDir string, progress Progress) (string, error) {
+	if progress == nil {
+		progress = func(string, string) {}
+	}
+	tag, err := imageTag(cfg)
+	if err != nil {
+		return "", err
+	}
+	if _, err := b.run(ctx, b.runtime, "image", "inspect", tag); err == nil {
+		progress(StageCached, tag)
+		progress(StageReady, tag)
+		return tag, nil
+	}
+
+	base, err := b.baseImage(ctx, cfg, tag, progress)
+	if err != nil {
+		return "", err
+	}
+
+	setup := imageRepository + "-setup-" + uuid.New().String()[:8]
+	args := []string{"create", "--name", setup, "-v", workingDir + ":" + workingDir, "-w", workingDir}
+	for _, key := range sortedKeys(cfg.ContainerEnv) {
+		args = append(args, "-e", key+"="+cfg.ContainerEnv[key])
+	}
+	if cfg.ContainerUser != "" {
+		args = append(args, "--user", cfg.ContainerUser)
+	}
+	args = append(args, "--entrypoint", "/bin/sh", base, "-c", "sleep infinity")
+	if _, err := b.run(ctx, b.runtime, args...); err != nil {
+		return "", err
+	}
+	defer func() {
+		_, _ = b.run(context.WithoutCancel(ctx), b.runtime, "rm", "-f", setup)
+	}()
+	if _, err := b.run(ctx, b.runtime, "start", setup); err != nil {
+		return "", err
+	}
+
+	for _, argv := range cfg.PostCreateCommand {
+		progress(StagePostCreate, strings.Join(argv, " "))
+		execArgs := append([]string{"exec", "-w", workingDir}, userArgs(cfg)...)
+		if _, err := b.run(ctx, b.runtime, append(append(execArgs, setup), argv...)...); err != nil {
+			return "", fmt.Errorf("postCreateCommand failed: %w", err)
+		}
+	}
+
+	commitArgs := []string{"commit"}
+	for _, key := range sortedKeys(cfg.ContainerEnv) {
+		commitArgs = append(commitArgs, "--change", fmt.Sprintf("ENV %s=%s", key, cfg.ContainerEnv[key]))
+	}
+	if user := agentUser(cfg); user != "" {
+		commitArgs = append(commitArgs, "--change", "USER "+user)
+	}
+	// The agent command replaces the placeholder entrypoint used during setup
+	commitArgs = append(commitArgs, "--change", `ENTRYPOINT []`, "--change", `CMD []`, setup, tag)
+	if _, err := b.run(ctx, b.runtime, commitArgs...); err != nil {
+		return "", err
+	}
+	progress(StageReady, tag)
+	return tag, nil
+}
+
+// baseImage pulls or builds the image the setup container starts from
+func (b *Builder) baseImage(ctx context.Context, cfg *Config, tag string, progress Progress) (string, error) {
+	if cfg.Build == nil || cfg.Build.Dockerfile == "" {
+		progress(StagePulling, cfg.Image)
+		if _, err := b.run(ctx, b.runtime, "pull", cfg.Image); err != nil {
+			// Locally built images can't be pulled but are usable as-is
+			if _, inspectErr := b.run(ctx, b.runtime, "image", "inspect", cfg.Image); inspectErr != nil {
+				return "", err
+			}
+		}
+		return cfg.Image, nil
+	}
+
+	dir := filepath.Dir(cfg.path)
+	dockerfile := filepath.Join(dir, cfg.Build.Dockerfile)
+	buildContext := dir
+	if cfg.Build.Context != "" {
+		buildContext = filepath.Join(dir, cfg.Build.Context)
+	}
+	base := tag + "-base"
+	progress(StageBuilding, dockerfile)
+	args := []string{"build", "-t", base, "-f", dockerfile}
+	for _, key := range sortedKeys(cfg.Build.Args) {
+		args = append(args, "--build-arg", key+"="+cfg.Build.Args[key])
+	}
+	if cfg.Build.Target != "" {
+		args = append(args, "--target", cfg.Build.Target)
+	}
+	if _, err := b.run(ctx, b.runtime, append(args, buildContext)...); err != nil {
+		return "", err
+	}
+	return base, nil
+}
+
+// imageTag derives a tag from devcontainer.json and its Dockerfile, so an
+// edit to either produces a fresh image
+func imageTag(cfg *Config) (string, error) {
+	h := sha256.New()
+	h.Write(cfg.raw)
+	if cfg.Build != nil && cfg.Build.Dockerfile != "" {
+		dockerfile, err := os.ReadFile(filepath.Join(filepath.Dir(cfg.path), cfg.Build.Dockerfile))
+		if err != nil {
+			return "", fmt.Errorf("failed to read Dockerfile: %w", err)
+		}
+		h.Write(dockerfile)
+	}
+	return imageRepository + ":" + hex.EncodeToString(h.Sum(nil))[:12], nil
+}
+
+// agentUser is the user the agent runs as; remoteUser wins as in the spec
+func agentUser(cfg *Config) string {
+	if cfg.RemoteUser != "" {
+		return cfg.RemoteUser
+	}
+	return cfg.ContainerUser
+}
+
+func userArgs(cfg *Config) []string {
+	if user := agentUser(cfg); user != "" {
+		return []string{"--user", user}
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func lastLines(out []byte, n int) string {
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,162 @@
+// Package devcontainer reads a repository's devcontainer.json and prepares a
+// container image from it that session agents can run in.
+package devcontainer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrNotFound is returned when a directory has no devcontainer.json
+var ErrNotFound = errors.New("no devcontainer.json found")
+
+// configPaths are checked in order, relative to the working directory
+var configPaths = []string{
+	filepath.Join(".devcontainer", "devcontainer.json"),
+	".devcontainer.json",
+}
+
+// Config is the subset of devcontainer.json the daemon understands.
+// Features, forwarded ports and lifecycle hooks other than postCreateCommand
+// are ignored.
+type Config struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
+	Build *Build `json:"build"`
+	// Pre-"build" spellings still found in older repositories
+	DockerFile string `json:"dockerFile"`
+	Context    string `json:"context"`
+
+	ContainerEnv      map[string]string `json:"containerEnv"`
+	ContainerUser     string            `json:"containerUser"`
+	RemoteUser        string            `json:"remoteUser"`
+	PostCreateCommand Command           `json:"postCreateCommand"`
+
+	path string
+	raw  []byte
+}
+
+// Build describes an image built from a Dockerfile
+type Build struct {
+	Dockerfile string            `json:"dockerfile"`
+	Context    string            `json:"context"`
+	Args       map[string]string `json:"args"`
+	Target     string            `json:"target"`
+}
+
+// Command is a lifecycle command. The spec allows a shell string, an argv
+// array or an object of named commands; each becomes one argv here.
+type Command [][]string
+
+// UnmarshalJSON accepts every form of lifecycle command
+func (c *Command) UnmarshalJSON(data []byte) error {
+	var shell string
+	if err := json.Unmarshal(data, &shell); err == nil {
+		if shell != "" {
+			*c = Command{{"/bin/sh", "-c", shell}}
+		}
+		return nil
+	}
+	var argv []string
+	if err := json.Unmarshal(data, &argv); err == nil {
+		if len(argv) > 0 {
+			*c = Command{argv}
+		}
+		return nil
+	}
+	var named map[string]json.RawMessage
+	if err := json.Unmarshal(data, &named); err != nil {
+		return fmt.Errorf("lifecycle command must be a string, array or object")
+	}
+	names := sortedKeys(named)
+	for _, name := range names {
+		var sub Command
+		if err := sub.UnmarshalJSON(named[name]); err != nil {
+			return fmt.Errorf("lifecycle command %q: %w", name, err)
+		}
+		*c = append(*c, sub...)
+	}
+	return nil
+}
+
+// Find returns the devcontainer.json for dir, or ErrNotFound
+func Find(dir string) (string, error) {
+	for _, rel := range configPaths {
+		path := filepath.Join(dir, rel)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		}
+	}
+	return "", ErrNotFound
+}
+
+// Load parses the devcontainer.json at path. Comments and trailing commas
+// are allowed, as in the editors that read these files.
+func Load(path string) (*Config, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read devcontainer.json: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(standardize(raw), &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if cfg.Build == nil && cfg.DockerFile != "" {
+		cfg.Build = &Build{Dockerfile: cfg.DockerFile, Context: cfg.Context}
+	}
+	if cfg.Image == "" && (cfg.Build == nil || cfg.Build.Dockerfile == "") {
+		return nil, fmt.Errorf("%s must set image or build.dockerfile; docker compose configurations are not supported", path)
+	}
+	cfg.path = path
+	cfg.raw = raw
+	return &cfg, nil
+}
+
+// standardize turns JSON with comments into plain JSON, removing // and
+// /* */ comments and trailing commas outside of strings
+func standardize(src []byte) []byte {
+	out := make([]byte, 0, len(src))
+	inString := false
+	for i := 0; i < len(src); i++ {
+		ch := src[i]
+		switch {
+		case inString:
+			out = append(out, ch)
+			if ch == '\\' && i+1 < len(src) {
+				i++
+				out = append(out, src[i])
+			} else if ch == '"' {
+				inString = false
+			}
+		case ch == '"':
+			inString = true
+			out = append(out, ch)
+		case ch == '/' && i+1 < len(src) && src[i+1] == '/':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			if i < len(src) {
+				out = append(out, '\n')
+			}
+		case ch == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := bytes.Index(src[i+2:], []byte("*/"))
+			if end < 0 {
+				return out
+			}
+			i += end + 3
+		case ch == '}' || ch == ']':
+			trimmed := bytes.TrimRight(out, " \t\r\n")
+			if len(trimmed) > 0 && trimmed[len(trimmed)-1] == ',' {
+				out = append(trimmed[:len(trimmed)-1], out[len(trimmed):]...)
+			}
+			out = append(out, ch)
+		default:
+			out = append(out, ch)
+		}
+	}
+	return out
+}
@@ -0,0 +1,113 @@
+package devcontainer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, dir, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, ".devcontainer", "devcontainer.json")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	_, err := Find(dir)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	writeConfig(t, dir, `{
+		// Toolchain for the repo
+		"name": "go // not a comment",
+		"image": "mcr.microsoft.com/devcontainers/go:1.24",
+		/* block
+		   comment */
+		"containerEnv": {"GOFLAGS": "-mod=mod",},
+		"postCreateCommand": {"deps": "go mod download", "tools": ["make", "tools"]},
+	}`)
+
+	path, err := Find(dir)
+	require.NoError(t, err)
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "go // not a comment", cfg.Name)
+	assert.Equal(t, "mcr.microsoft.com/devcontainers/go:1.24", cfg.Image)
+	assert.Equal(t, map[string]string{"GOFLAGS": "-mod=mod"}, cfg.ContainerEnv)
+	assert.Equal(t, Command{{"/bin/sh", "-c", "go mod download"}, {"make", "tools"}}, cfg.PostCreateCommand)
+}
+
+func TestLoadRequiresImageOrDockerfile(t *testing.T) {
+	dir := t.TempDir()
+	path := writeConfig(t, dir, `{"dockerComposeFile": "compose.yml"}`)
+	_, err := Load(path)
+	assert.ErrorContains(t, err, "must set image or build.dockerfile")
+
+	path = writeConfig(t, dir, `{"dockerFile": "Dockerfile", "context": ".."}`)
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, &Build{Dockerfile: "Dockerfile", Context: ".."}, cfg.Build)
+}
+
+type fakeRuntime struct {
+	calls    []string
+	existing map[string]bool
+}
+
+func (f *fakeRuntime) run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	f.calls = append(f.calls, strings.Join(args, " "))
+	if args[0] == "image" && !f.existing[args[2]] {
+		return nil, errors.New("no such image")
+	}
+	return nil, nil
+}
+
+func TestPrepare(t *testing.T) {
+	repo := t.TempDir()
+	path := writeConfig(t, repo, `{
+		"build": {"dockerfile": "Dockerfile", "args": {"VARIANT": "3.12"}},
+		"remoteUser": "vscode",
+		"postCreateCommand": "pip install -e ."
+	}`)
+	require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(path), "Dockerfile"), []byte("FROM python\n"), 0644))
+	cfg, err := Load(path)
+	require.NoError(t, err)
+
+	fake := &fakeRuntime{existing: map[string]bool{}}
+	b := &Builder{runtime: "docker", run: fake.run}
+	var stages []string
+	tag, err := b.Prepare(context.Background(), cfg, repo, func(stage, detail string) {
+		stages = append(stages, stage)
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(tag, "hld-devcontainer:"))
+	assert.Equal(t, []string{StageBuilding, StagePostCreate, StageReady}, stages)
+
+	devcontainerDir := filepath.Dir(path)
+	require.Len(t, fake.calls, 7)
+	assert.Equal(t, "build -t "+tag+"-base -f "+filepath.Join(devcontainerDir, "Dockerfile")+" --build-arg VARIANT=3.12 "+devcontainerDir, fake.calls[1])
+	assert.Contains(t, fake.calls[2], "-v "+repo+":"+repo)
+	assert.Contains(t, fake.calls[4], "exec -w "+repo+" --user vscode")
+	assert.Contains(t, fake.calls[4], "/bin/sh -c pip install -e .")
+	assert.Contains(t, fake.calls[5], "--change USER vscode")
+	assert.True(t, strings.HasSuffix(fake.calls[5], " "+tag))
+	assert.True(t, strings.HasPrefix(fake.calls[6], "rm -f"))
+
+	// A second launch reuses the committed image
+	fake.existing[tag] = true
+	stages = nil
+	again, err := b.Prepare(context.Background(), cfg, repo, func(stage, detail string) {
+		stages = append(stages, stage)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, tag, again)
+	assert.Equal(t, []string{StageCached, StageReady}, stages)
+}
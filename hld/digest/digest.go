@@ -0,0 +1,148 @@
+// Package digest compiles a day's session activity per workspace and sends
+// it to each configured recipient at their chosen time of day.
+package digest
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// maxFailures caps the failed sessions listed in one digest
+const maxFailures = 5
+
+// Failure is a failed session worth calling out in a digest
+type Failure struct {
+	SessionID string `json:"session_id"`
+	Title     string `json:"title"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Workspace is the activity in one working directory over a digest period
+type Workspace struct {
+	WorkingDir  string    `json:"workspace"`
+	Sessions    int       `json:"sessions"`
+	Completed   int       `json:"completed"`
+	Failed      int       `json:"failed"`
+	Interrupted int       `json:"interrupted"`
+	Approvals   int       `json:"approvals"`
+	Approved    int       `json:"approved"`
+	Denied      int       `json:"denied"`
+	CostUSD     float64   `json:"cost_usd"`
+	Failures    []Failure `json:"failures,omitempty"`
+}
+
+// Empty reports whether the workspace had no activity
+func (w *Workspace) Empty() bool {
+	return w.Sessions == 0 && w.Approvals == 0
+}
+
+// Build compiles the activity of sessions active between start and end.
+// With workspaces set, sessions are grouped under the workspace containing
+// their working directory and others are left out; every listed workspace
+// is returned, even without activity. Otherwise sessions are grouped by
+// their own working directory.
+func Build(ctx context.Context, s store.ConversationStore, start, end time.Time, workspaces []string) ([]*Workspace, error) {
+	sessions, err := s.ListSessions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	groups := make(map[string]*Workspace)
+	for _, dir := range workspaces {
+		groups[dir] = &Workspace{WorkingDir: dir}
+	}
+
+	for _, session := range sessions {
+		if !activeBetween(session, start, end) {
+			continue
+		}
+		key := session.WorkingDir
+		if len(workspaces) > 0 {
+			if key = containing(workspaces, session.WorkingDir); key == "" {
+				continue
+			}
+		}
+		w, ok := groups[key]
+		if !ok {
+			w = &Workspace{WorkingDir: key}
+			groups[key] = w
+		}
+		if err := w.add(ctx, s, session, start, end); err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([]*Workspace, 0, len(groups))
+	for _, w := range groups {
+		result = append(result, w)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].WorkingDir < result[j].WorkingDir })
+	return result, nil
+}
+
+func (w *Workspace) add(ctx context.Context, s store.ConversationStore, session *store.Session, start, end time.Time) error {
+	w.Sessions++
+	if session.CostUSD != nil {
+		w.CostUSD += *session.CostUSD
+	}
+	switch session.Status {
+	case store.SessionStatusCompleted:
+		w.Completed++
+	case store.SessionStatusInterrupted:
+		w.Interrupted++
+	case store.SessionStatusFailed:
+		w.Failed++
+		if len(w.Failures) < maxFailures {
+			title := session.Title
+			if title == "" {
+				title = session.Summary
+			}
+			w.Failures = append(w.Failures, Failure{SessionID: session.ID, Title: title, Error: session.ErrorMessage})
+		}
+	}
+
+	events, err := s.GetSessionConversation(ctx, session.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get conversation for session %s: %w", session.ID, err)
+	}
+	for _, event := range events {
+		if event.ApprovalID == "" || event.CreatedAt.Before(start) || !event.CreatedAt.Before(end) {
+			continue
+		}
+		w.Approvals++
+		switch event.ApprovalStatus {
+		case store.ApprovalStatusApproved:
+			w.Approved++
+		case store.ApprovalStatusDenied:
+			w.Denied++
+		}
+	}
+	return nil
+}
+
+// activeBetween reports whether a session was created or last active in [start, end)
+func activeBetween(session *store.Session, start, end time.Time) bool {
+	in := func(t time.Time) bool { return !t.Before(start) && t.Before(end) }
+	return in(session.CreatedAt) || in(session.LastActivityAt)
+}
+
+// containing returns the deepest workspace containing path, or ""
+func containing(workspaces []string, path string) string {
+	best := ""
+	for _, dir := range workspaces {
+		rel, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(path))
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if len(dir) > len(best) {
+			best = dir
+		}
+	}
+	return best
+}
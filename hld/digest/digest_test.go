@@ -0,0 +1,101 @@
+package digest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var day = time.Date(2025, 3, 10, 9, 0, 0, 0, time.Local)
+
+func newStore(t *testing.T) *store.MemoryStore {
+	t.Helper()
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	cost := 1.5
+	sessions := []*store.Session{
+		{ID: "a", ClaudeSessionID: "claude-a", WorkingDir: "/work/app", Status: store.SessionStatusCompleted, CostUSD: &cost},
+		{ID: "b", ClaudeSessionID: "claude-b", WorkingDir: "/work/app/api", Status: store.SessionStatusFailed, Title: "Migrate db", ErrorMessage: "exit 1"},
+		{ID: "c", ClaudeSessionID: "claude-c", WorkingDir: "/work/site", Status: store.SessionStatusCompleted},
+	}
+	for _, session := range sessions {
+		session.RunID = "run-" + session.ID
+		session.CreatedAt = day.Add(-2 * time.Hour)
+		session.LastActivityAt = session.CreatedAt
+		require.NoError(t, s.CreateSession(ctx, session))
+	}
+	// A session from last week is left out
+	require.NoError(t, s.CreateSession(ctx, &store.Session{
+		ID: "old", RunID: "run-old", WorkingDir: "/work/app", Status: store.SessionStatusFailed,
+		CreatedAt: day.AddDate(0, 0, -7), LastActivityAt: day.AddDate(0, 0, -7),
+	}))
+
+	for _, status := range []string{store.ApprovalStatusApproved, store.ApprovalStatusDenied} {
+		require.NoError(t, s.AddConversationEvent(ctx, &store.ConversationEvent{
+			SessionID: "a", ClaudeSessionID: "claude-a", EventType: store.EventTypeToolCall,
+			ApprovalID: "appr-" + status, ApprovalStatus: status, CreatedAt: day.Add(-time.Hour),
+		}))
+	}
+	return s
+}
+
+func TestBuild(t *testing.T) {
+	s := newStore(t)
+
+	all, err := Build(context.Background(), s, day.AddDate(0, 0, -1), day, nil)
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, "/work/app", all[0].WorkingDir)
+	assert.Equal(t, 1, all[0].Sessions)
+
+	grouped, err := Build(context.Background(), s, day.AddDate(0, 0, -1), day, []string{"/work/app", "/work/empty"})
+	require.NoError(t, err)
+	require.Len(t, grouped, 2)
+	app := grouped[0]
+	assert.Equal(t, 2, app.Sessions)
+	assert.Equal(t, 1, app.Completed)
+	assert.Equal(t, 1, app.Failed)
+	assert.Equal(t, 2, app.Approvals)
+	assert.Equal(t, 1, app.Denied)
+	assert.Equal(t, 1.5, app.CostUSD)
+	assert.Equal(t, []Failure{{SessionID: "b", Title: "Migrate db", Error: "exit 1"}}, app.Failures)
+	assert.True(t, grouped[1].Empty())
+}
+
+func TestSchedulerSendsOncePerDay(t *testing.T) {
+	ctx := context.Background()
+	eventBus := bus.NewEventBus()
+	sub := eventBus.Subscribe(ctx, bus.EventFilter{Types: []bus.EventType{bus.EventDailyDigest}})
+
+	s := NewScheduler(newStore(t), eventBus, map[string]config.DigestConfig{
+		"alice": {Workspaces: []string{"/work/app", "/work/empty"}, Plugins: []string{"slack"}},
+	})
+	s.sent["alice"] = day.AddDate(0, 0, -1)
+
+	s.now = func() time.Time { return day.Add(-time.Minute) }
+	require.NoError(t, s.Check(ctx))
+	s.now = func() time.Time { return day.Add(time.Minute) }
+	require.NoError(t, s.Check(ctx))
+	require.NoError(t, s.Check(ctx))
+
+	select {
+	case event := <-sub.Channel:
+		assert.Equal(t, "alice", event.Data["recipient"])
+		assert.Equal(t, "/work/app", event.Data["workspace"])
+		assert.Equal(t, []string{"slack"}, event.Data["plugins"])
+		assert.Equal(t, 2, event.Data["sessions"])
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for digest")
+	}
+	select {
+	case event := <-sub.Channel:
+		t.Fatalf("unexpected digest for %v", event.Data["workspace"])
+	case <-time.After(50 * time.Millisecond):
+	}
+}
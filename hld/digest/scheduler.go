@@ -0,0 +1,150 @@
+package digest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/scheduler"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// checkInterval is how often the scheduler looks for digests that are due
+const checkInterval = time.Minute
+
+// Scheduler publishes an EventDailyDigest per workspace for each recipient
+// once a day, at the recipient's configured time
+type Scheduler struct {
+	store    store.ConversationStore
+	eventBus bus.EventBus
+	digests  map[string]config.DigestConfig
+	now      func() time.Time
+
+	mu   sync.Mutex
+	sent map[string]time.Time // recipient -> end of the last period sent
+}
+
+// NewScheduler creates a digest scheduler
+func NewScheduler(s store.ConversationStore, eventBus bus.EventBus, digests map[string]config.DigestConfig) *Scheduler {
+	return &Scheduler{
+		store:    s,
+		eventBus: eventBus,
+		digests:  digests,
+		now:      time.Now,
+		sent:     make(map[string]time.Time),
+	}
+}
+
+// Task is the maintenance task sending digests as they come due. A digest
+// whose time passed before the task was made is not sent late.
+func (s *Scheduler) Task() scheduler.Task {
+	s.Prime()
+	return scheduler.Task{
+		Name:        "digests",
+		Description: "Send daily digests of session activity as they come due",
+		Interval:    checkInterval,
+		Run:         s.Check,
+	}
+}
+
+// Prime marks the digests whose time has already passed today as sent, so
+// Check doesn't send them late
+func (s *Scheduler) Prime() {
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for recipient, digest := range s.digests {
+		if due, err := lastDue(digest, now); err == nil {
+			s.sent[recipient] = due
+		}
+	}
+}
+
+// Check sends every digest whose time has come since it was last sent,
+// returning the failures
+func (s *Scheduler) Check(ctx context.Context) error {
+	now := s.now()
+	recipients := make([]string, 0, len(s.digests))
+	for recipient := range s.digests {
+		recipients = append(recipients, recipient)
+	}
+	sort.Strings(recipients)
+
+	var errs []error
+	for _, recipient := range recipients {
+		digest := s.digests[recipient]
+		due, err := lastDue(digest, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("digest time of %s: %w", recipient, err))
+			continue
+		}
+		s.mu.Lock()
+		alreadySent := !due.After(s.sent[recipient])
+		s.mu.Unlock()
+		if alreadySent {
+			continue
+		}
+		if err := s.Send(ctx, recipient, digest, due.AddDate(0, 0, -1), due); err != nil {
+			slog.Warn("failed to send daily digest", "recipient", recipient, "error", err)
+			errs = append(errs, fmt.Errorf("digest for %s: %w", recipient, err))
+			continue
+		}
+		s.mu.Lock()
+		s.sent[recipient] = due
+		s.mu.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+// Send publishes a recipient's digest for the period between start and end,
+// one event per workspace
+func (s *Scheduler) Send(ctx context.Context, recipient string, digest config.DigestConfig, start, end time.Time) error {
+	workspaces, err := Build(ctx, s.store, start, end, digest.Workspaces)
+	if err != nil {
+		return err
+	}
+	for _, w := range workspaces {
+		if w.Empty() && !digest.SendEmpty {
+			continue
+		}
+		s.eventBus.Publish(bus.Event{
+			Type: bus.EventDailyDigest,
+			Data: map[string]interface{}{
+				"recipient":   recipient,
+				"plugins":     digest.Plugins,
+				"workspace":   w.WorkingDir,
+				"start":       start,
+				"end":         end,
+				"sessions":    w.Sessions,
+				"completed":   w.Completed,
+				"failed":      w.Failed,
+				"interrupted": w.Interrupted,
+				"approvals":   w.Approvals,
+				"approved":    w.Approved,
+				"denied":      w.Denied,
+				"cost_usd":    w.CostUSD,
+				"failures":    w.Failures,
+			},
+		})
+	}
+	return nil
+}
+
+// lastDue returns the most recent time at or before now the digest was due
+func lastDue(digest config.DigestConfig, now time.Time) (time.Time, error) {
+	hour, minute, err := digest.SendTime()
+	if err != nil {
+		return time.Time{}, err
+	}
+	due := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+	if due.After(now) {
+		due = due.AddDate(0, 0, -1)
+	}
+	return due, nil
+}
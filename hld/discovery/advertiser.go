@@ -0,0 +1,211 @@
+package discovery
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// announceInterval separates the unsolicited announcements sent on start
+const announceInterval = time.Second
+
+// Advertiser answers mDNS queries for one daemon
+type Advertiser struct {
+	daemon   Daemon
+	instance dnsmessage.Name
+	host     dnsmessage.Name
+	service  dnsmessage.Name
+}
+
+// NewAdvertiser creates an advertiser for d. Host defaults to this machine's.
+func NewAdvertiser(d Daemon) *Advertiser {
+	if d.Host == "" {
+		d.Host = LocalHost()
+	}
+	if d.API == "" {
+		d.API = APIPrefix
+	}
+	return &Advertiser{
+		daemon:   d,
+		instance: mustName(instanceName(d.Instance)),
+		host:     mustName(fqdn(d.Host)),
+		service:  mustName(Service),
+	}
+}
+
+// Run answers queries on the mDNS group until ctx is cancelled, announcing
+// the daemon when it starts and withdrawing it when it stops
+func (a *Advertiser) Run(ctx context.Context) error {
+	conn, err := net.ListenMulticastUDP("udp4", nil, mdnsGroup)
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		a.send(conn, a.announcement(0), mdnsGroup)
+		_ = conn.Close()
+	}()
+	slog.Info("advertising daemon over mDNS", "instance", a.daemon.Instance, "host", a.daemon.Host, "port", a.daemon.Port)
+
+	go func() {
+		for i := 0; i < 2; i++ {
+			a.send(conn, a.announcement(serviceTTL), mdnsGroup)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(announceInterval):
+			}
+		}
+	}()
+
+	buf := make([]byte, 9000)
+	for {
+		n, src, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		var query dnsmessage.Message
+		if err := query.Unpack(buf[:n]); err != nil || query.Response {
+			continue
+		}
+		response, unicast := a.answer(&query, src.Port != mdnsGroup.Port)
+		if response == nil {
+			continue
+		}
+		if unicast {
+			a.send(conn, response, src)
+		} else {
+			a.send(conn, response, mdnsGroup)
+		}
+	}
+}
+
+func (a *Advertiser) send(conn *net.UDPConn, msg *dnsmessage.Message, to *net.UDPAddr) {
+	packet, err := msg.Pack()
+	if err == nil {
+		_, err = conn.WriteToUDP(packet, to)
+	}
+	if err != nil {
+		slog.Debug("failed to send mDNS response", "to", to, "error", err)
+	}
+}
+
+// announcement is an unsolicited response with all the daemon's records;
+// a ttl of 0 withdraws them
+func (a *Advertiser) announcement(ttl uint32) *dnsmessage.Message {
+	msg := &dnsmessage.Message{Header: dnsmessage.Header{Response: true, Authoritative: true}}
+	msg.Answers = append(msg.Answers, a.ptr(ttl), a.srv(ttl), a.txt(ttl))
+	msg.Answers = append(msg.Answers, a.addrs(ttl)...)
+	return msg
+}
+
+// answer returns the response to a query, or nil if it asks about nothing
+// this daemon has, and whether to send it directly to the querier. Queries
+// from a port other than 5353 (legacy) get a direct reply echoing them, as
+// do questions asking for one.
+func (a *Advertiser) answer(query *dnsmessage.Message, legacy bool) (*dnsmessage.Message, bool) {
+	msg := &dnsmessage.Message{Header: dnsmessage.Header{Response: true, Authoritative: true}}
+	unicast := legacy
+	seen := map[string]bool{}
+	add := func(section *[]dnsmessage.Resource, rs ...dnsmessage.Resource) {
+		for _, r := range rs {
+			key := r.Header.Name.String() + r.Header.Type.String()
+			if r.Header.Type == dnsmessage.TypeA {
+				key += string(r.Body.(*dnsmessage.AResource).A[:])
+			}
+			if !seen[key] {
+				seen[key] = true
+				*section = append(*section, r)
+			}
+		}
+	}
+
+	for _, q := range query.Questions {
+		if q.Class&unicastResponse != 0 {
+			unicast = true
+		}
+		anyType := q.Type == dnsmessage.TypeALL
+		switch q.Name.String() {
+		case servicesName:
+			if anyType || q.Type == dnsmessage.TypePTR {
+				add(&msg.Answers, dnsmessage.Resource{
+					Header: dnsmessage.ResourceHeader{Name: mustName(servicesName), Type: dnsmessage.TypePTR, Class: dnsmessage.ClassINET, TTL: serviceTTL},
+					Body:   &dnsmessage.PTRResource{PTR: a.service},
+				})
+			}
+		case a.service.String():
+			if anyType || q.Type == dnsmessage.TypePTR {
+				add(&msg.Answers, a.ptr(serviceTTL))
+				add(&msg.Additionals, a.srv(hostTTL), a.txt(serviceTTL))
+				add(&msg.Additionals, a.addrs(hostTTL)...)
+			}
+		case a.instance.String():
+			if anyType || q.Type == dnsmessage.TypeSRV {
+				add(&msg.Answers, a.srv(hostTTL))
+				add(&msg.Additionals, a.addrs(hostTTL)...)
+			}
+			if anyType || q.Type == dnsmessage.TypeTXT {
+				add(&msg.Answers, a.txt(serviceTTL))
+			}
+		case a.host.String():
+			if anyType || q.Type == dnsmessage.TypeA {
+				add(&msg.Answers, a.addrs(hostTTL)...)
+			}
+		}
+	}
+	if len(msg.Answers) == 0 {
+		return nil, false
+	}
+	if legacy {
+		msg.ID = query.ID
+		msg.Questions = query.Questions
+		for i := range msg.Questions {
+			msg.Questions[i].Class &^= unicastResponse
+		}
+	}
+	return msg, unicast
+}
+
+func (a *Advertiser) ptr(ttl uint32) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: a.service, Type: dnsmessage.TypePTR, Class: dnsmessage.ClassINET, TTL: ttl},
+		Body:   &dnsmessage.PTRResource{PTR: a.instance},
+	}
+}
+
+func (a *Advertiser) srv(ttl uint32) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: a.instance, Type: dnsmessage.TypeSRV, Class: dnsmessage.ClassINET, TTL: ttl},
+		Body:   &dnsmessage.SRVResource{Target: a.host, Port: uint16(a.daemon.Port)},
+	}
+}
+
+func (a *Advertiser) txt(ttl uint32) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: a.instance, Type: dnsmessage.TypeTXT, Class: dnsmessage.ClassINET, TTL: ttl},
+		Body:   &dnsmessage.TXTResource{TXT: a.daemon.txt()},
+	}
+}
+
+func (a *Advertiser) addrs(ttl uint32) []dnsmessage.Resource {
+	var rs []dnsmessage.Resource
+	for _, ip := range a.daemon.Addrs {
+		ip4 := ip.To4()
+		if ip4 == nil {
+			continue
+		}
+		var addr [4]byte
+		copy(addr[:], ip4)
+		rs = append(rs, dnsmessage.Resource{
+			Header: dnsmessage.ResourceHeader{Name: a.host, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: ttl},
+			Body:   &dnsmessage.AResource{A: addr},
+		})
+	}
+	return rs
+}
@@ -0,0 +1,182 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// DefaultBrowseTimeout is how long Browse waits for answers by default
+const DefaultBrowseTimeout = 2 * time.Second
+
+// Browse asks the local network for advertised daemons and returns those
+// that answer within timeout, by instance name
+func Browse(ctx context.Context, timeout time.Duration) ([]Daemon, error) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4zero})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	query, err := browseQuery().Pack()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.WriteToUDP(query, mdnsGroup); err != nil {
+		return nil, fmt.Errorf("failed to send mDNS query: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = conn.SetReadDeadline(deadline)
+
+	found := newCollector()
+	buf := make([]byte, 9000)
+	for {
+		n, _, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			// The deadline ends browsing
+			break
+		}
+		var msg dnsmessage.Message
+		if err := msg.Unpack(buf[:n]); err != nil || !msg.Response {
+			continue
+		}
+		found.add(&msg)
+	}
+	return found.daemons(), nil
+}
+
+// browseQuery asks for the instances of Service, with replies sent directly
+func browseQuery() *dnsmessage.Message {
+	return &dnsmessage.Message{
+		Questions: []dnsmessage.Question{{
+			Name:  mustName(Service),
+			Type:  dnsmessage.TypePTR,
+			Class: dnsmessage.ClassINET | unicastResponse,
+		}},
+	}
+}
+
+// collector assembles daemons from the records in mDNS responses, which
+// may arrive split across several
+type collector struct {
+	instances map[string]string // lower-cased name to name as advertised
+	srv       map[string]*dnsmessage.SRVResource
+	txt       map[string][]string
+	addrs     map[string][]net.IP
+}
+
+func newCollector() *collector {
+	return &collector{
+		instances: map[string]string{},
+		srv:       map[string]*dnsmessage.SRVResource{},
+		txt:       map[string][]string{},
+		addrs:     map[string][]net.IP{},
+	}
+}
+
+func (c *collector) add(msg *dnsmessage.Message) {
+	records := append(append([]dnsmessage.Resource{}, msg.Answers...), msg.Additionals...)
+	for _, r := range records {
+		name := strings.ToLower(r.Header.Name.String())
+		switch body := r.Body.(type) {
+		case *dnsmessage.PTRResource:
+			if name == Service {
+				c.instances[strings.ToLower(body.PTR.String())] = body.PTR.String()
+			}
+		case *dnsmessage.SRVResource:
+			c.srv[name] = body
+		case *dnsmessage.TXTResource:
+			c.txt[name] = body.TXT
+		case *dnsmessage.AResource:
+			ip := net.IP(body.A[:])
+			known := false
+			for _, addr := range c.addrs[name] {
+				known = known || addr.Equal(ip)
+			}
+			if !known {
+				c.addrs[name] = append(c.addrs[name], ip)
+			}
+		}
+	}
+}
+
+// daemons returns the instances whose service record arrived
+func (c *collector) daemons() []Daemon {
+	daemons := []Daemon{}
+	for name, advertised := range c.instances {
+		srv, ok := c.srv[name]
+		if !ok {
+			continue
+		}
+		host := strings.ToLower(srv.Target.String())
+		d := Daemon{
+			Instance: advertised[:len(advertised)-len(Service)-1],
+			Host:     strings.TrimSuffix(host, "."),
+			Port:     int(srv.Port),
+			Addrs:    c.addrs[host],
+		}
+		d.parseTXT(c.txt[name])
+		daemons = append(daemons, d)
+	}
+	sort.Slice(daemons, func(i, j int) bool { return daemons[i].Instance < daemons[j].Instance })
+	return daemons
+}
+
+const usage = `usage: hld discover [flags]
+
+Lists the daemons advertised over mDNS on this machine and the local network.
+
+  -timeout duration   how long to wait for answers (default 2s)
+  -json               print the daemons as JSON
+`
+
+// Main runs hld discover, returning the process exit code
+func Main(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("hld discover", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { fmt.Fprint(stderr, usage) }
+	timeout := fs.Duration("timeout", DefaultBrowseTimeout, "")
+	asJSON := fs.Bool("json", false, "")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	daemons, err := Browse(ctx, *timeout)
+	if err != nil {
+		fmt.Fprintf(stderr, "hld discover: %v\n", err)
+		return 1
+	}
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(daemons)
+		return 0
+	}
+	if len(daemons) == 0 {
+		fmt.Fprintln(stdout, "No daemons found")
+		return 0
+	}
+	for _, d := range daemons {
+		fmt.Fprintf(stdout, "%s\t%s\tversion %s", d.Instance, d.Host, d.Version)
+		if url := d.URL(); url != "" {
+			fmt.Fprintf(stdout, "\t%s", url)
+		}
+		if d.Socket != "" {
+			fmt.Fprintf(stdout, "\tsocket %s", d.Socket)
+		}
+		fmt.Fprintln(stdout)
+	}
+	return 0
+}
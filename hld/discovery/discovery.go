@@ -0,0 +1,146 @@
+// Package discovery advertises the daemon over multicast DNS (DNS-SD), so
+// the desktop app and CLI can find running daemons on this machine or the
+// local network without configuring a port or socket path, and browses for
+// the daemons others advertise.
+package discovery
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// Service is the DNS-SD service type daemons are advertised under
+const Service = "_humanlayer._tcp.local."
+
+// servicesName lists the service types on the network, for generic browsers
+const servicesName = "_services._dns-sd._udp.local."
+
+// APIPrefix is advertised so clients don't assume where the REST API lives
+const APIPrefix = "/api/v1"
+
+// Record TTLs in seconds, as RFC 6762 recommends: records naming the host
+// expire sooner than those naming the service
+const (
+	hostTTL    = 120
+	serviceTTL = 4500
+)
+
+var (
+	mdnsGroup = &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: 5353}
+	// unicastResponse is the top bit of a question's class, asking for a
+	// direct reply rather than a multicast one
+	unicastResponse dnsmessage.Class = 1 << 15
+)
+
+// Daemon is a daemon as it is advertised
+type Daemon struct {
+	Instance string   `json:"instance"`
+	Host     string   `json:"host"`           // e.g. alice-laptop.local
+	Port     int      `json:"port,omitempty"` // REST API; 0 when the HTTP listener is disabled
+	Addrs    []net.IP `json:"addrs,omitempty"`
+	Version  string   `json:"version,omitempty"`
+	// Socket is the daemon's RPC socket, only reachable on Host itself
+	Socket string `json:"socket,omitempty"`
+	API    string `json:"api,omitempty"` // Path prefix of the REST API
+}
+
+// URL returns the base URL of the daemon's REST API, or "" without one
+func (d Daemon) URL() string {
+	if d.Port == 0 {
+		return ""
+	}
+	host := strings.TrimSuffix(d.Host, ".")
+	if len(d.Addrs) > 0 {
+		host = d.Addrs[0].String()
+	}
+	return "http://" + net.JoinHostPort(host, strconv.Itoa(d.Port)) + d.API
+}
+
+// LocalHost returns this machine's mDNS host name, e.g. alice-laptop.local
+func LocalHost() string {
+	name, err := os.Hostname()
+	if err != nil || name == "" {
+		name = "localhost"
+	}
+	name, _, _ = strings.Cut(name, ".")
+	return name + ".local"
+}
+
+// InterfaceAddrs returns the IPv4 addresses of the interfaces that are up
+// and can multicast, which LAN clients can reach the daemon on
+func InterfaceAddrs() []net.IP {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return nil
+	}
+	var addrs []net.IP
+	for _, iface := range interfaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagMulticast == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		ifaceAddrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range ifaceAddrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.To4() != nil {
+				addrs = append(addrs, ipNet.IP.To4())
+			}
+		}
+	}
+	return addrs
+}
+
+// instanceName is the DNS name of a daemon instance under Service. Dots
+// would split the instance label, so they are replaced.
+func instanceName(instance string) string {
+	return strings.ReplaceAll(instance, ".", "-") + "." + Service
+}
+
+func fqdn(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
+
+func mustName(name string) dnsmessage.Name {
+	n, err := dnsmessage.NewName(name)
+	if err != nil {
+		// Names are built from the hostname and instance, both bounded
+		panic(fmt.Sprintf("invalid DNS name %q: %v", name, err))
+	}
+	return n
+}
+
+// txt returns a daemon's TXT record strings
+func (d Daemon) txt() []string {
+	txt := []string{"txtvers=1", "api=" + APIPrefix}
+	if d.Version != "" {
+		txt = append(txt, "version="+d.Version)
+	}
+	if d.Socket != "" {
+		txt = append(txt, "socket="+d.Socket)
+	}
+	return txt
+}
+
+// parseTXT sets a daemon's fields from its TXT record strings
+func (d *Daemon) parseTXT(txt []string) {
+	for _, entry := range txt {
+		key, value, _ := strings.Cut(entry, "=")
+		switch key {
+		case "version":
+			d.Version = value
+		case "socket":
+			d.Socket = value
+		case "api":
+			d.API = value
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package discovery
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestBrowseFindsAdvertisedDaemon(t *testing.T) {
+	a := NewAdvertiser(Daemon{
+		Instance: "alice.laptop",
+		Host:     "alice-laptop.local",
+		Port:     7777,
+		Addrs:    []net.IP{net.IPv4(192, 168, 1, 20)},
+		Version:  "0.9.0",
+		Socket:   "/home/alice/.humanlayer/daemon.sock",
+	})
+
+	// A browser on an ephemeral port gets a direct reply echoing its query
+	query := browseQuery()
+	query.ID = 42
+	response, unicast := a.answer(query, true)
+	require.NotNil(t, response)
+	assert.True(t, unicast)
+	assert.Equal(t, uint16(42), response.ID)
+	require.Len(t, response.Questions, 1)
+
+	// The reply survives the wire
+	packet, err := response.Pack()
+	require.NoError(t, err)
+	var received dnsmessage.Message
+	require.NoError(t, received.Unpack(packet))
+
+	found := newCollector()
+	found.add(&received)
+	daemons := found.daemons()
+	require.Len(t, daemons, 1)
+	d := daemons[0]
+	assert.Equal(t, "alice-laptop", d.Instance, "dots can't be part of an instance label")
+	assert.Equal(t, "alice-laptop.local", d.Host)
+	assert.Equal(t, 7777, d.Port)
+	assert.Equal(t, "0.9.0", d.Version)
+	assert.Equal(t, "/home/alice/.humanlayer/daemon.sock", d.Socket)
+	assert.Equal(t, "http://192.168.1.20:7777/api/v1", d.URL())
+}
+
+func TestAdvertiserAnswers(t *testing.T) {
+	a := NewAdvertiser(Daemon{Instance: "ci", Host: "runner.local", Addrs: []net.IP{net.IPv4(10, 0, 0, 5)}})
+	question := func(name string, qtype dnsmessage.Type) *dnsmessage.Message {
+		return &dnsmessage.Message{Questions: []dnsmessage.Question{{Name: mustName(name), Type: qtype, Class: dnsmessage.ClassINET}}}
+	}
+
+	response, unicast := a.answer(question(Service, dnsmessage.TypePTR), false)
+	require.NotNil(t, response)
+	assert.False(t, unicast, "multicast queries get multicast answers")
+	assert.Empty(t, response.Questions)
+
+	response, _ = a.answer(question(servicesName, dnsmessage.TypePTR), false)
+	require.Len(t, response.Answers, 1)
+	assert.Equal(t, Service, response.Answers[0].Body.(*dnsmessage.PTRResource).PTR.String())
+
+	response, _ = a.answer(question("runner.local.", dnsmessage.TypeA), false)
+	require.Len(t, response.Answers, 1)
+	assert.Equal(t, [4]byte{10, 0, 0, 5}, response.Answers[0].Body.(*dnsmessage.AResource).A)
+
+	response, _ = a.answer(question("ci."+Service, dnsmessage.TypeTXT), false)
+	require.Len(t, response.Answers, 1)
+	assert.Contains(t, response.Answers[0].Body.(*dnsmessage.TXTResource).TXT, "api=/api/v1")
+
+	response, _ = a.answer(question("_http._tcp.local.", dnsmessage.TypePTR), false)
+	assert.Nil(t, response, "other services are left to their responders")
+
+	// Without an HTTP listener only the socket is advertised
+	assert.Empty(t, Daemon{Host: "runner.local", Socket: "/tmp/hld.sock"}.URL())
+}
@@ -0,0 +1,167 @@
+// Package diskguard refuses writes that would leave a disk nearly full or
+// grow a repository by more than a limit, so a runaway agent can't fill the
+// machine it works on. Staging, commits and artifacts are checked before
+// anything is written.
+package diskguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/workspace"
+)
+
+// Errors for refused writes
+var (
+	ErrLowDiskSpace = errors.New("not enough free disk space")
+	ErrRepoGrowth   = errors.New("repository growth limit exceeded")
+)
+
+// Reasons a write is refused, as published on the event
+const (
+	ReasonLowDiskSpace = "low_disk_space"
+	ReasonRepoGrowth   = "repo_growth"
+)
+
+// Write is an operation about to write to disk
+type Write struct {
+	// Operation names what is writing, such as "commit" or "artifact"
+	Operation string
+	SessionID string
+	// Host holds Dir; nil is the daemon's machine
+	Host workspace.Host
+	// Dir is where the write lands. It needn't exist yet.
+	Dir string
+	// Size is how many bytes are written, when known
+	Size int64
+	// Repository counts Size toward the repository growth limit
+	Repository bool
+}
+
+// Guard checks writes against the configured thresholds
+type Guard struct {
+	minFree       int64 // zero turns the free space check off
+	maxRepoGrowth int64 // zero turns the growth check off
+	eventBus      bus.EventBus
+	// freeSpace returns the bytes available on dir's filesystem
+	freeSpace func(ctx context.Context, host workspace.Host, dir string) (int64, error)
+}
+
+// New creates a guard publishing refusals on eventBus, which may be nil
+func New(cfg config.DiskGuardConfig, eventBus bus.EventBus) *Guard {
+	g := &Guard{
+		minFree:       cfg.MinFree,
+		maxRepoGrowth: cfg.MaxRepoGrowth,
+		eventBus:      eventBus,
+		freeSpace:     FreeSpace,
+	}
+	switch {
+	case g.minFree == 0:
+		g.minFree = config.DefaultDiskGuardMinFree
+	case g.minFree < 0:
+		g.minFree = 0
+	}
+	return g
+}
+
+// Check refuses w, publishing why, when it would grow a repository past
+// the limit or leave less than the minimum free. Free space that can't be
+// measured doesn't refuse anything.
+func (g *Guard) Check(ctx context.Context, w Write) error {
+	if w.Repository && g.maxRepoGrowth > 0 && w.Size > g.maxRepoGrowth {
+		g.refuse(w, ReasonRepoGrowth, nil)
+		return fmt.Errorf("%w: %s would add %d bytes to the repository; the limit is %d",
+			ErrRepoGrowth, w.Operation, w.Size, g.maxRepoGrowth)
+	}
+	if g.minFree == 0 {
+		return nil
+	}
+	host := w.Host
+	if host == nil {
+		host = workspace.Local{}
+	}
+	free, err := g.freeSpace(ctx, host, w.Dir)
+	if err != nil {
+		slog.Debug("skipping free disk space check", "dir", w.Dir, "error", err)
+		return nil
+	}
+	if free-w.Size < g.minFree {
+		g.refuse(w, ReasonLowDiskSpace, map[string]interface{}{"free_bytes": free})
+		return fmt.Errorf("%w: %s would leave %d bytes free on the disk holding %s; at least %d must stay free",
+			ErrLowDiskSpace, w.Operation, max(free-w.Size, 0), w.Dir, g.minFree)
+	}
+	return nil
+}
+
+func (g *Guard) refuse(w Write, reason string, extra map[string]interface{}) {
+	slog.Warn("refused write", "operation", w.Operation, "session_id", w.SessionID, "dir", w.Dir, "reason", reason, "size", w.Size)
+	if g.eventBus == nil {
+		return
+	}
+	data := map[string]interface{}{
+		"operation":      w.Operation,
+		"dir":            w.Dir,
+		"reason":         reason,
+		"size_bytes":     w.Size,
+		"min_free_bytes": g.minFree,
+	}
+	if w.SessionID != "" {
+		data["session_id"] = w.SessionID
+	}
+	if g.maxRepoGrowth > 0 {
+		data["max_repo_growth_bytes"] = g.maxRepoGrowth
+	}
+	for k, v := range extra {
+		data[k] = v
+	}
+	g.eventBus.Publish(bus.Event{Type: bus.EventDiskGuardRefused, Timestamp: time.Now(), Data: data})
+}
+
+// FreeSpace returns the bytes available to unprivileged users on the
+// filesystem holding dir, or its nearest existing parent, with df
+func FreeSpace(ctx context.Context, host workspace.Host, dir string) (int64, error) {
+	for {
+		cmd := host.Command(ctx, "", "df", "-Pk", "--", dir)
+		output, err := cmd.Output()
+		if err == nil {
+			return parseDF(string(output))
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return 0, fmt.Errorf("df %s: %w", dir, err)
+		}
+		dir = parent
+	}
+}
+
+// parseDF reads the available KiB from POSIX df -Pk output
+func parseDF(output string) (int64, error) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) < 2 {
+		return 0, fmt.Errorf("unexpected df output %q", output)
+	}
+	// The filesystem name and mount point may contain spaces; available is
+	// the column before the capacity percentage
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) < 6 {
+		return 0, fmt.Errorf("unexpected df output %q", output)
+	}
+	for i := len(fields) - 1; i >= 3; i-- {
+		if strings.HasSuffix(fields[i], "%") {
+			kib, err := strconv.ParseInt(fields[i-1], 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("unexpected df output %q", output)
+			}
+			return kib * 1024, nil
+		}
+	}
+	return 0, fmt.Errorf("unexpected df output %q", output)
+}
@@ -0,0 +1,71 @@
+package diskguard
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/workspace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	ctx := context.Background()
+	eventBus := bus.NewEventBus()
+	events := eventBus.Subscribe(ctx, bus.EventFilter{Types: []bus.EventType{bus.EventDiskGuardRefused}})
+	g := New(config.DiskGuardConfig{MinFree: 1000, MaxRepoGrowth: 500}, eventBus)
+	g.freeSpace = func(context.Context, workspace.Host, string) (int64, error) { return 1200, nil }
+
+	assert.NoError(t, g.Check(ctx, Write{Operation: "commit", Dir: "/repo", Size: 200, Repository: true}))
+	assert.NoError(t, g.Check(ctx, Write{Operation: "artifact", Dir: "/artifacts", Size: 100}))
+
+	err := g.Check(ctx, Write{Operation: "commit", SessionID: "sess-1", Dir: "/repo", Size: 600, Repository: true})
+	assert.ErrorIs(t, err, ErrRepoGrowth)
+	err = g.Check(ctx, Write{Operation: "artifact", SessionID: "sess-1", Dir: "/artifacts", Size: 600})
+	assert.ErrorIs(t, err, ErrLowDiskSpace, "growth only limits repositories, but free space still runs out")
+	assert.EqualError(t, err, "not enough free disk space: artifact would leave 600 bytes free on the disk holding /artifacts; at least 1000 must stay free")
+
+	var reasons []interface{}
+	for i := 0; i < 2; i++ {
+		select {
+		case event := <-events.Channel:
+			assert.Equal(t, "sess-1", event.Data["session_id"])
+			reasons = append(reasons, event.Data["reason"])
+		case <-time.After(time.Second):
+			t.Fatal("missing disk_guard_refused event")
+		}
+	}
+	assert.Equal(t, []interface{}{ReasonRepoGrowth, ReasonLowDiskSpace}, reasons)
+}
+
+func TestCheckDisabled(t *testing.T) {
+	g := New(config.DiskGuardConfig{MinFree: -1}, nil)
+	g.freeSpace = func(context.Context, workspace.Host, string) (int64, error) { return 0, nil }
+	assert.NoError(t, g.Check(context.Background(), Write{Operation: "commit", Size: 1 << 40, Repository: true}))
+
+	assert.Equal(t, int64(config.DefaultDiskGuardMinFree), New(config.DiskGuardConfig{}, nil).minFree)
+}
+
+func TestFreeSpace(t *testing.T) {
+	// A directory that doesn't exist yet is measured on its parent's disk
+	free, err := FreeSpace(context.Background(), workspace.Local{}, filepath.Join(t.TempDir(), "artifacts", "ab"))
+	require.NoError(t, err)
+	assert.Positive(t, free)
+}
+
+func TestParseDF(t *testing.T) {
+	free, err := parseDF("Filesystem     1024-blocks      Used Available Capacity Mounted on\n/dev/nvme0n1p2   490617784 301234567 164391234      65% /\n")
+	require.NoError(t, err)
+	assert.Equal(t, int64(164391234*1024), free)
+
+	free, err = parseDF("Filesystem 1024-blocks Used Available Capacity Mounted on\nmap auto_home 0 0 0 100% /System/Volumes/Data/home\n")
+	require.NoError(t, err)
+	assert.Zero(t, free)
+
+	_, err = parseDF("df: /nope: No such file or directory\n")
+	assert.Error(t, err)
+}
@@ -0,0 +1,392 @@
+// Package email lets approvers and requesters who live in email use the
+// daemon. Approval notifications go out over SMTP with a signed, expiring
+// token in the subject; the first reply starting with "approve" or "deny"
+// decides the approval.
+// Other mail to the configured address starts a session from its body.
+// Inbound mail arrives from a parse service posting to the daemon, as
+// SendGrid's form fields, an SES notification through SNS, or raw MIME.
+package email
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/resolution"
+)
+
+// maxTextPart bounds the text read from one MIME part
+const maxTextPart = 1 << 20
+
+var (
+	// ErrNoMessage is returned for inbound requests without a message, such
+	// as SNS subscription confirmations
+	ErrNoMessage = errors.New("request carries no message")
+	// ErrUnsupportedFormat is returned for inbound requests in a format no
+	// parse service sends
+	ErrUnsupportedFormat = errors.New("unsupported inbound format")
+	// ErrInvalidToken is returned for approval tokens the daemon didn't sign
+	ErrInvalidToken = errors.New("approval token signature is invalid")
+	// ErrExpiredToken is returned for approval tokens past their expiry
+	ErrExpiredToken = errors.New("approval token has expired")
+)
+
+// Message is an inbound email
+type Message struct {
+	From    string   `json:"from"`
+	To      []string `json:"to"`
+	Subject string   `json:"subject"`
+	Text    string   `json:"-"`
+	// SenderVerified is false when the parse service reported the sender
+	// failing both SPF and DKIM, true when either passed, and nil when it
+	// reported neither
+	SenderVerified *bool `json:"sender_verified,omitempty"`
+}
+
+// Parse reads the message in an inbound request: SendGrid Inbound Parse
+// form fields (or its raw "email" field), an SNS notification of an SES
+// receipt with the content included, or a raw message/rfc822 body. An SNS
+// subscription confirmation returns ErrNoMessage with its SubscribeURL.
+func Parse(contentType string, body []byte) (*Message, string, error) {
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "message/rfc822":
+		msg, err := ParseMIME(body)
+		return msg, "", err
+	case mediaType == "multipart/form-data":
+		msg, err := parseSendGrid(body, params["boundary"])
+		return msg, "", err
+	case mediaType == "application/json" || mediaType == "text/plain":
+		// SNS posts JSON labelled text/plain
+		return parseSNS(body)
+	default:
+		return nil, "", fmt.Errorf("%w %q", ErrUnsupportedFormat, mediaType)
+	}
+}
+
+func parseSendGrid(body []byte, boundary string) (*Message, error) {
+	form, err := multipart.NewReader(bytes.NewReader(body), boundary).ReadForm(int64(len(body)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid form: %w", err)
+	}
+	defer func() { _ = form.RemoveAll() }()
+	field := func(name string) string {
+		if values := form.Value[name]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	var msg *Message
+	if raw := field("email"); raw != "" {
+		if msg, err = ParseMIME([]byte(raw)); err != nil {
+			return nil, err
+		}
+	} else {
+		msg = &Message{From: field("from"), To: addresses(field("to")), Subject: field("subject"), Text: field("text")}
+		if msg.From == "" {
+			return nil, errors.New("form has no from field")
+		}
+	}
+	spf := strings.EqualFold(field("SPF"), "pass")
+	dkim := strings.Contains(strings.ToLower(field("dkim")), "pass")
+	if field("SPF") != "" || field("dkim") != "" {
+		verified := spf || dkim
+		msg.SenderVerified = &verified
+	}
+	return msg, nil
+}
+
+type snsEnvelope struct {
+	Type         string `json:"Type"`
+	Message      string `json:"Message"`
+	SubscribeURL string `json:"SubscribeURL"`
+}
+
+type sesNotification struct {
+	NotificationType string `json:"notificationType"`
+	Content          string `json:"content"`
+	Receipt          struct {
+		SPFVerdict  struct{ Status string } `json:"spfVerdict"`
+		DKIMVerdict struct{ Status string } `json:"dkimVerdict"`
+		Action      struct {
+			Encoding string `json:"encoding"`
+		} `json:"action"`
+	} `json:"receipt"`
+}
+
+func parseSNS(body []byte) (*Message, string, error) {
+	var envelope snsEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, "", fmt.Errorf("invalid SNS message: %w", err)
+	}
+	switch envelope.Type {
+	case "SubscriptionConfirmation":
+		return nil, envelope.SubscribeURL, ErrNoMessage
+	case "Notification":
+	default:
+		return nil, "", fmt.Errorf("%w: SNS message type %q", ErrUnsupportedFormat, envelope.Type)
+	}
+
+	var notification sesNotification
+	if err := json.Unmarshal([]byte(envelope.Message), &notification); err != nil {
+		return nil, "", fmt.Errorf("invalid SES notification: %w", err)
+	}
+	if notification.NotificationType != "Received" || notification.Content == "" {
+		return nil, "", fmt.Errorf("%w: SES notification has no content; use an SNS action", ErrNoMessage)
+	}
+	content := []byte(notification.Content)
+	if strings.EqualFold(notification.Receipt.Action.Encoding, "BASE64") {
+		var err error
+		if content, err = base64.StdEncoding.DecodeString(notification.Content); err != nil {
+			return nil, "", fmt.Errorf("invalid SES content: %w", err)
+		}
+	}
+	msg, err := ParseMIME(content)
+	if err != nil {
+		return nil, "", err
+	}
+	spf, dkim := notification.Receipt.SPFVerdict.Status, notification.Receipt.DKIMVerdict.Status
+	if spf != "" || dkim != "" {
+		verified := spf == "PASS" || dkim == "PASS"
+		msg.SenderVerified = &verified
+	}
+	return msg, "", nil
+}
+
+// ParseMIME reads a raw message, keeping its first text/plain part
+func ParseMIME(raw []byte) (*Message, error) {
+	m, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid message: %w", err)
+	}
+	decoder := new(mime.WordDecoder)
+	subject, err := decoder.DecodeHeader(m.Header.Get("Subject"))
+	if err != nil {
+		subject = m.Header.Get("Subject")
+	}
+	text, err := textPart(m.Header.Get("Content-Type"), m.Header.Get("Content-Transfer-Encoding"), m.Body)
+	if err != nil {
+		return nil, err
+	}
+	to := addresses(m.Header.Get("To"))
+	to = append(to, addresses(m.Header.Get("Cc"))...)
+	return &Message{From: m.Header.Get("From"), To: to, Subject: subject, Text: text}, nil
+}
+
+// textPart finds the first text/plain part of a body, descending into
+// multipart bodies
+func textPart(contentType, encoding string, body io.Reader) (string, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if contentType == "" || err != nil {
+		mediaType = "text/plain"
+	}
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextRawPart()
+			if errors.Is(err, io.EOF) {
+				return "", nil
+			}
+			if err != nil {
+				return "", fmt.Errorf("invalid multipart message: %w", err)
+			}
+			text, err := textPart(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part)
+			if err != nil || text != "" {
+				return text, err
+			}
+		}
+	}
+	if mediaType != "text/plain" {
+		return "", nil
+	}
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, newlineStripper{body})
+	}
+	data, err := io.ReadAll(io.LimitReader(body, maxTextPart))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode message text: %w", err)
+	}
+	return strings.ReplaceAll(string(data), "\r\n", "\n"), nil
+}
+
+// newlineStripper drops the line breaks base64 bodies are wrapped with
+type newlineStripper struct{ r io.Reader }
+
+func (n newlineStripper) Read(p []byte) (int, error) {
+	count, err := n.r.Read(p)
+	out := p[:0]
+	for _, b := range p[:count] {
+		if b != '\r' && b != '\n' {
+			out = append(out, b)
+		}
+	}
+	return len(out), err
+}
+
+func addresses(header string) []string {
+	list, err := mail.ParseAddressList(header)
+	if err != nil {
+		if header = strings.TrimSpace(header); header != "" {
+			return []string{header}
+		}
+		return nil
+	}
+	out := make([]string, len(list))
+	for i, a := range list {
+		out[i] = a.Address
+	}
+	return out
+}
+
+// Address returns the bare, lowercased address in a From or To value
+func Address(value string) string {
+	if a, err := mail.ParseAddress(value); err == nil {
+		value = a.Address
+	}
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+// Allowed reports whether from matches one of allowed: an address, or
+// "@example.com" for a domain
+func Allowed(from string, allowed []string) bool {
+	address := Address(from)
+	if address == "" {
+		return false
+	}
+	for _, entry := range allowed {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == address || (strings.HasPrefix(entry, "@") && strings.HasSuffix(address, entry)) {
+			return true
+		}
+	}
+	return false
+}
+
+// SentTo reports whether the message was addressed to address
+func (m *Message) SentTo(address string) bool {
+	address = Address(address)
+	for _, to := range m.To {
+		if Address(to) == address {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenPattern finds the approval token in a subject, which mail clients
+// keep when replying: [hld:<resolution token>]
+var tokenPattern = regexp.MustCompile(`\[hld:([A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[0-9]+\.[A-Za-z0-9_-]+)\]`)
+
+// Tokens sign approval IDs with a nonce and expiry, so only replies to the
+// daemon's own notifications can decide approvals, and only for a while.
+// The key is kept in a file beside the database.
+type Tokens struct {
+	signer *resolution.Signer
+	ttl    time.Duration
+}
+
+// NewTokens creates tokens signed with key
+func NewTokens(key []byte) *Tokens {
+	return &Tokens{signer: resolution.NewSigner(key), ttl: config.DefaultEmailTokenTTL * time.Hour}
+}
+
+// TokensFromConfig creates the daemon's tokens, with the key in email.key
+// beside the database
+func TokensFromConfig(cfg *config.Config) *Tokens {
+	ttl := time.Duration(cfg.Email.TokenTTLHours) * time.Hour
+	if ttl == 0 {
+		ttl = config.DefaultEmailTokenTTL * time.Hour
+	}
+	return &Tokens{signer: resolution.SignerFromFile(filepath.Join(filepath.Dir(cfg.DatabasePath), "email.key")), ttl: ttl}
+}
+
+// Token returns the subject token for an approval
+func (t *Tokens) Token(approvalID string) (string, error) {
+	token, err := t.signer.Issue(approvalID, nil, t.ttl)
+	if err != nil {
+		return "", err
+	}
+	return "[hld:" + token + "]", nil
+}
+
+// Approval returns what a subject's token vouches for. found is false when
+// the subject has no token; an error means its signature is wrong or it has
+// expired. An expired token's claim is returned with ErrExpiredToken.
+func (t *Tokens) Approval(subject string) (claim *resolution.Claim, found bool, err error) {
+	match := tokenPattern.FindStringSubmatch(subject)
+	if match == nil {
+		return nil, false, nil
+	}
+	claim, err = t.signer.Verify(match[1], nil, time.Now())
+	switch {
+	case errors.Is(err, resolution.ErrInvalid):
+		return nil, true, ErrInvalidToken
+	case errors.Is(err, resolution.ErrExpired):
+		return claim, true, ErrExpiredToken
+	}
+	return claim, true, err
+}
+
+// Reply decisions
+const (
+	DecisionApprove = "approve"
+	DecisionDeny    = "deny"
+)
+
+var replyWords = map[string]string{
+	"approve": DecisionApprove, "approved": DecisionApprove, "yes": DecisionApprove, "lgtm": DecisionApprove,
+	"deny": DecisionDeny, "denied": DecisionDeny, "no": DecisionDeny, "reject": DecisionDeny,
+}
+
+// ParseReply reads the decision from the first word of a reply, with the
+// rest of the new text as the comment. Quoted text and signatures are left
+// out. ok is false when the reply doesn't start with a decision.
+func ParseReply(text string) (decision, comment string, ok bool) {
+	body := Body(text)
+	first, rest, _ := strings.Cut(body, "\n")
+	word, remainder, _ := strings.Cut(strings.TrimSpace(first), " ")
+	decision, ok = replyWords[strings.ToLower(strings.TrimRight(word, ".,:;!"))]
+	if !ok {
+		return "", "", false
+	}
+	comment = strings.TrimSpace(strings.TrimSpace(remainder) + "\n" + rest)
+	comment = strings.TrimLeft(comment, "-–—:, ")
+	return decision, strings.TrimSpace(comment), true
+}
+
+// quoteHeader matches the line mail clients put above quoted text, such as
+// "On Tue, 3 Jun 2025 at 10:00, Ada <ada@example.com> wrote:"
+var quoteHeader = regexp.MustCompile(`(?i)^(on .+ wrote:|-+ ?original message ?-+|from: .+)$`)
+
+// Body returns a message's new text: everything before quoted text or a
+// "-- " signature separator
+func Body(text string) string {
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxTextPart)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t")
+		if strings.HasPrefix(line, ">") || line == "--" || quoteHeader.MatchString(strings.TrimSpace(line)) {
+			break
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,202 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"mime/multipart"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const rawReply = "From: Ada <Ada@Example.com>\r\n" +
+	"To: hld@example.com\r\n" +
+	"Subject: =?utf-8?q?Re:_Approval_needed:_app_[hld:local-1.sig]?=\r\n" +
+	"Content-Type: multipart/alternative; boundary=b\r\n" +
+	"\r\n" +
+	"--b\r\n" +
+	"Content-Type: text/plain; charset=utf-8\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"Deny, use the staging datab=\r\nase\r\n" +
+	"\r\n" +
+	"On Tue, 3 Jun 2025 at 10:00, hld <hld@example.com> wrote:\r\n" +
+	"> A session is waiting\r\n" +
+	"--b\r\n" +
+	"Content-Type: text/html\r\n" +
+	"\r\n" +
+	"<p>Deny</p>\r\n" +
+	"--b--\r\n"
+
+func TestParseMIME(t *testing.T) {
+	msg, err := ParseMIME([]byte(rawReply))
+	require.NoError(t, err)
+	assert.Equal(t, "Ada <Ada@Example.com>", msg.From)
+	assert.Equal(t, []string{"hld@example.com"}, msg.To)
+	assert.Equal(t, "Re: Approval needed: app [hld:local-1.sig]", msg.Subject)
+	assert.True(t, strings.HasPrefix(msg.Text, "Deny, use the staging database\n"), msg.Text)
+	assert.Nil(t, msg.SenderVerified)
+
+	b64 := "From: ada@example.com\r\nContent-Transfer-Encoding: base64\r\n\r\n" +
+		base64.StdEncoding.EncodeToString([]byte("fix the flaky test"))[:12] + "\r\n" +
+		base64.StdEncoding.EncodeToString([]byte("fix the flaky test"))[12:] + "\r\n"
+	msg, err = ParseMIME([]byte(b64))
+	require.NoError(t, err)
+	assert.Equal(t, "fix the flaky test", msg.Text)
+}
+
+func TestParseSendGrid(t *testing.T) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for name, value := range map[string]string{
+		"from": "Ada <ada@example.com>", "to": "hld@example.com", "subject": "Flaky test",
+		"text": "Fix the flaky test", "SPF": "fail", "dkim": "{@example.com : pass}",
+	} {
+		require.NoError(t, w.WriteField(name, value))
+	}
+	require.NoError(t, w.Close())
+
+	msg, _, err := Parse(w.FormDataContentType(), body.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, "Flaky test", msg.Subject)
+	assert.Equal(t, "Fix the flaky test", msg.Text)
+	assert.True(t, msg.SentTo("HLD@example.com"))
+	require.NotNil(t, msg.SenderVerified)
+	assert.True(t, *msg.SenderVerified, "DKIM passing is enough")
+}
+
+func TestParseSNS(t *testing.T) {
+	notification, err := json.Marshal(map[string]any{
+		"notificationType": "Received",
+		"content":          base64.StdEncoding.EncodeToString([]byte(rawReply)),
+		"receipt": map[string]any{
+			"spfVerdict":  map[string]string{"status": "FAIL"},
+			"dkimVerdict": map[string]string{"status": "FAIL"},
+			"action":      map[string]string{"type": "SNS", "encoding": "BASE64"},
+		},
+	})
+	require.NoError(t, err)
+	envelope, err := json.Marshal(map[string]string{"Type": "Notification", "Message": string(notification)})
+	require.NoError(t, err)
+
+	msg, _, err := Parse("text/plain; charset=UTF-8", envelope)
+	require.NoError(t, err)
+	assert.Equal(t, "hld@example.com", msg.To[0])
+	require.NotNil(t, msg.SenderVerified)
+	assert.False(t, *msg.SenderVerified)
+
+	confirmation := []byte(`{"Type":"SubscriptionConfirmation","SubscribeURL":"https://sns.example.com/confirm"}`)
+	_, subscribeURL, err := Parse("text/plain", confirmation)
+	assert.ErrorIs(t, err, ErrNoMessage)
+	assert.Equal(t, "https://sns.example.com/confirm", subscribeURL)
+
+	_, _, err = Parse("application/xml", nil)
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestAllowed(t *testing.T) {
+	allowed := []string{"ada@example.com", "@acme.dev"}
+	assert.True(t, Allowed("Ada <ADA@example.com>", allowed))
+	assert.True(t, Allowed("grace@acme.dev", allowed))
+	assert.False(t, Allowed("mallory@notacme.dev", allowed))
+	assert.False(t, Allowed("bob@example.com", allowed))
+	assert.False(t, Allowed("", allowed))
+}
+
+func TestTokens(t *testing.T) {
+	tokens := NewTokens(bytes.Repeat([]byte{1}, 32))
+	token, err := tokens.Token("local-1")
+	require.NoError(t, err)
+	assert.Regexp(t, `^\[hld:local-1\.[A-Za-z0-9_-]{16}\.[0-9]+\.[A-Za-z0-9_-]{22}\]$`, token)
+
+	claim, found, err := tokens.Approval("Re: Re: Approval needed: app " + token)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "local-1", claim.ApprovalID)
+	assert.WithinDuration(t, time.Now().Add(config.DefaultEmailTokenTTL*time.Hour), claim.ExpiresAt, time.Minute)
+
+	other, err := tokens.Token("local-1")
+	require.NoError(t, err)
+	assert.NotEqual(t, token, other, "each notification gets its own nonce")
+
+	_, found, err = tokens.Approval("Re: Approval needed " + strings.Replace(token, "local-1", "local-2", 1))
+	assert.True(t, found)
+	assert.ErrorIs(t, err, ErrInvalidToken, "a token can't be moved to another approval")
+
+	_, found, err = tokens.Approval("Fix the flaky test")
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	expired := &Tokens{signer: tokens.signer, ttl: -time.Minute}
+	token, err = expired.Token("local-1")
+	require.NoError(t, err)
+	claim, _, err = tokens.Approval(token)
+	assert.ErrorIs(t, err, ErrExpiredToken)
+	assert.Equal(t, "local-1", claim.ApprovalID)
+}
+
+func TestParseReply(t *testing.T) {
+	for _, tc := range []struct {
+		text, decision, comment string
+		ok                      bool
+	}{
+		{"Approve\n\n-- \nAda", DecisionApprove, "", true},
+		{"LGTM.", DecisionApprove, "", true},
+		{"yes - go ahead\n> quoted", DecisionApprove, "go ahead", true},
+		{"Deny, use the staging database\nand rerun.\n\nOn Tue wrote:\n> x", DecisionDeny, "use the staging database\nand rerun.", true},
+		{"Maybe later", "", "", false},
+		{"\n\n", "", "", false},
+	} {
+		decision, comment, ok := ParseReply(tc.text)
+		assert.Equal(t, tc.ok, ok, tc.text)
+		assert.Equal(t, tc.decision, decision, tc.text)
+		assert.Equal(t, tc.comment, comment, tc.text)
+	}
+}
+
+func TestNotify(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	require.NoError(t, s.CreateSession(ctx, &store.Session{ID: "sess-1", RunID: "run-1", Title: "Fix flaky test", WorkingDir: "/src/app"}))
+	require.NoError(t, s.CreateApproval(ctx, &store.Approval{
+		ID: "local-1", RunID: "run-1", SessionID: "sess-1", Status: store.ApprovalStatusLocalPending,
+		ToolName: "Bash", ToolInput: json.RawMessage(`{"command":"rm -rf build"}`), CreatedAt: time.Now(),
+	}))
+
+	var sent struct {
+		addr, from string
+		to         []string
+		msg        []byte
+	}
+	send := func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+		sent.addr, sent.from, sent.to, sent.msg = addr, from, to, msg
+		return nil
+	}
+	tokens := NewTokens(bytes.Repeat([]byte{1}, 32))
+	n := NewNotifier(s, nil, tokens, config.EmailConfig{
+		Address: "hld@example.com", NotifyTo: []string{"ada@example.com"}, SMTPHost: "smtp.example.com",
+	}, send)
+	require.NoError(t, n.Notify(ctx, "local-1"))
+
+	assert.Equal(t, "smtp.example.com:587", sent.addr)
+	assert.Equal(t, "hld@example.com", sent.from)
+	assert.Equal(t, []string{"ada@example.com"}, sent.to)
+
+	msg, err := ParseMIME(sent.msg)
+	require.NoError(t, err)
+	claim, found, err := tokens.Approval(msg.Subject)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "local-1", claim.ApprovalID)
+	assert.True(t, strings.HasPrefix(msg.Subject, "Approval needed: Fix flaky test [hld:"), msg.Subject)
+	assert.Contains(t, msg.Text, `"command": "rm -rf build"`)
+	assert.Contains(t, msg.Text, "Working directory: /src/app")
+}
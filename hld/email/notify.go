@@ -0,0 +1,158 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// maxInput keeps the tool input quoted in a notification readable
+const maxInput = 4000
+
+// SendFunc sends a message over SMTP; smtp.SendMail in the daemon
+type SendFunc func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+
+// Notifier emails approval notifications that can be answered by replying
+type Notifier struct {
+	store    store.ConversationStore
+	eventBus bus.EventBus
+	tokens   *Tokens
+	cfg      config.EmailConfig
+	send     SendFunc
+	now      func() time.Time
+}
+
+// NewNotifier creates a notifier sending with send
+func NewNotifier(s store.ConversationStore, eventBus bus.EventBus, tokens *Tokens, cfg config.EmailConfig, send SendFunc) *Notifier {
+	if cfg.SMTPPort == 0 {
+		cfg.SMTPPort = config.DefaultSMTPPort
+	}
+	if cfg.SMTPPasswordEnv == "" {
+		cfg.SMTPPasswordEnv = config.DefaultSMTPPasswordEnv
+	}
+	return &Notifier{store: s, eventBus: eventBus, tokens: tokens, cfg: cfg, send: send, now: time.Now}
+}
+
+// FromConfig creates the daemon's notifier, or nil when email notifications
+// aren't configured
+func FromConfig(cfg *config.Config, s store.ConversationStore, eventBus bus.EventBus, tokens *Tokens) *Notifier {
+	if cfg.Email.Address == "" || len(cfg.Email.NotifyTo) == 0 {
+		return nil
+	}
+	return NewNotifier(s, eventBus, tokens, cfg.Email, smtp.SendMail)
+}
+
+// Run emails each new approval, and again when a snooze of one ends, until
+// ctx is done
+func (n *Notifier) Run(ctx context.Context) {
+	sub := n.eventBus.Subscribe(ctx, bus.EventFilter{
+		Types: []bus.EventType{bus.EventNewApproval, bus.EventApprovalReminder},
+	})
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-sub.Channel:
+			if !ok {
+				return
+			}
+			approvalID, _ := event.Data["approval_id"].(string)
+			go func() {
+				if err := n.Notify(ctx, approvalID); err != nil {
+					slog.Warn("failed to email approval notification", "approval_id", approvalID, "error", err)
+				}
+			}()
+		}
+	}
+}
+
+// Notify emails a pending approval to the notification recipients
+func (n *Notifier) Notify(ctx context.Context, approvalID string) error {
+	msg, err := n.Compose(ctx, approvalID)
+	if err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if n.cfg.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", n.cfg.SMTPUsername, os.Getenv(n.cfg.SMTPPasswordEnv), n.cfg.SMTPHost)
+	}
+	addr := net.JoinHostPort(n.cfg.SMTPHost, strconv.Itoa(n.cfg.SMTPPort))
+	if err := n.send(addr, auth, n.cfg.Address, n.cfg.NotifyTo, msg); err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	return nil
+}
+
+// Compose builds the notification for an approval, with its signed token
+// in the subject
+func (n *Notifier) Compose(ctx context.Context, approvalID string) ([]byte, error) {
+	approval, err := n.store.GetApproval(ctx, approvalID)
+	if err != nil {
+		return nil, err
+	}
+	token, err := n.tokens.Token(approval.ID)
+	if err != nil {
+		return nil, err
+	}
+	name := approval.SessionID
+	session, err := n.store.GetSession(ctx, approval.SessionID)
+	if err == nil {
+		switch {
+		case session.Title != "":
+			name = session.Title
+		case session.Summary != "":
+			name = session.Summary
+		case session.WorkingDir != "":
+			name = filepath.Base(session.WorkingDir)
+		}
+	}
+
+	var input bytes.Buffer
+	if json.Indent(&input, approval.ToolInput, "", "  ") != nil {
+		input.Reset()
+		input.Write(approval.ToolInput)
+	}
+	quoted := input.String()
+	if len(quoted) > maxInput {
+		quoted = strings.ToValidUTF8(quoted[:maxInput], "") + "\n…"
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "A session is waiting for approval to run %s.\n\n", approval.ToolName)
+	fmt.Fprintf(&body, "Session: %s (%s)\n", name, approval.SessionID)
+	if session != nil && session.WorkingDir != "" {
+		fmt.Fprintf(&body, "Working directory: %s\n", session.WorkingDir)
+	}
+	fmt.Fprintf(&body, "\n%s\n\n", quoted)
+	body.WriteString("Reply with \"approve\" or \"deny\" as the first word, followed by an optional comment.\n")
+	body.WriteString("Keep the subject as it is; its token identifies this approval.\n")
+
+	subject := fmt.Sprintf("Approval needed: %s %s", name, token)
+	domain := n.cfg.Address[strings.LastIndex(n.cfg.Address, "@")+1:]
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", n.cfg.Address)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.cfg.NotifyTo, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", n.now().Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "Message-ID: <%s@%s>\r\n", uuid.New().String(), domain)
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(body.String(), "\n", "\r\n"))
+	return msg.Bytes(), nil
+}
@@ -0,0 +1,271 @@
+// Package escalation pages someone when an approval a critical policy rule
+// asked for goes unanswered past its SLA. The incident links back to the
+// approval and is resolved as soon as the approval is decided.
+package escalation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+const (
+	requestTimeout = 30 * time.Second
+	// maxCheckInterval bounds how late past its SLA an approval is paged
+	maxCheckInterval = 30 * time.Second
+	// maxToolInput keeps incident details readable
+	maxToolInput = 1000
+)
+
+// Link is a deep link attached to an incident
+type Link struct {
+	Text string
+	URL  string
+}
+
+// Incident is raised for one unanswered approval
+type Incident struct {
+	Key     string // Deduplicates retries and identifies the incident to resolve
+	Summary string
+	Details map[string]string
+	Links   []Link
+}
+
+// Pager raises and resolves incidents with an on-call provider
+type Pager interface {
+	Trigger(ctx context.Context, incident Incident) error
+	Resolve(ctx context.Context, key, note string) error
+}
+
+// Escalator watches critical approvals
+type Escalator struct {
+	store       store.ConversationStore
+	escalations store.EscalationStore
+	holds       store.HoldStore
+	pager       Pager
+	sla         time.Duration
+	apiURL      string // Daemon REST API, for links to the approval
+	uiURL       string // Web UI, for a link to the session; may be empty
+	eventBus    bus.EventBus
+	now         func() time.Time
+}
+
+// New creates an escalator paging through pager once approvals are sla old
+func New(s store.ConversationStore, escalations store.EscalationStore, holds store.HoldStore, pager Pager, sla time.Duration, apiURL, uiURL string, eventBus bus.EventBus) *Escalator {
+	return &Escalator{
+		store:       s,
+		escalations: escalations,
+		holds:       holds,
+		pager:       pager,
+		sla:         sla,
+		apiURL:      strings.TrimRight(apiURL, "/"),
+		uiURL:       strings.TrimRight(uiURL, "/"),
+		eventBus:    eventBus,
+		now:         time.Now,
+	}
+}
+
+// FromConfig creates the configured escalator, or returns nil if escalation
+// is off
+func FromConfig(cfg *config.Config, s store.ConversationStore, escalations store.EscalationStore, holds store.HoldStore, eventBus bus.EventBus) (*Escalator, error) {
+	ec := cfg.Escalation
+	if ec.Provider == "" {
+		return nil, nil
+	}
+	key := os.Getenv(ec.KeyEnv)
+	if key == "" {
+		return nil, fmt.Errorf("escalation key variable %s is not set", ec.KeyEnv)
+	}
+	var pager Pager
+	switch ec.Provider {
+	case config.EscalationPagerDuty:
+		pager = NewPagerDuty(ec.BaseURL, key)
+	case config.EscalationOpsgenie:
+		pager = NewOpsgenie(ec.BaseURL, key)
+	default:
+		return nil, fmt.Errorf("unknown escalation provider %q", ec.Provider)
+	}
+	sla := time.Duration(ec.SLASeconds) * time.Second
+	if ec.SLASeconds == 0 {
+		sla = config.DefaultEscalationSLASeconds * time.Second
+	}
+	host := cfg.HTTPHost
+	if host == "" || host == "0.0.0.0" {
+		host = "127.0.0.1"
+	}
+	apiURL := fmt.Sprintf("http://%s:%d/api/v1", host, cfg.HTTPPort)
+	return New(s, escalations, holds, pager, sla, apiURL, ec.UIURL, eventBus), nil
+}
+
+// Run checks for overdue approvals and resolves incidents as approvals are
+// decided, until ctx is done
+func (e *Escalator) Run(ctx context.Context) {
+	sub := e.eventBus.Subscribe(ctx, bus.EventFilter{
+		Types: []bus.EventType{bus.EventApprovalResolved},
+	})
+	interval := min(max(e.sla/4, time.Second), maxCheckInterval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// Catch up on approvals that went overdue or were decided while stopped
+	e.check(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.check(ctx)
+		case event, ok := <-sub.Channel:
+			if !ok {
+				return
+			}
+			approvalID, _ := event.Data["approval_id"].(string)
+			if err := e.Settle(ctx, approvalID); err != nil {
+				slog.Warn("failed to resolve approval escalation", "approval_id", approvalID, "error", err)
+			}
+		}
+	}
+}
+
+func (e *Escalator) check(ctx context.Context) {
+	if err := e.Check(ctx); err != nil {
+		slog.Warn("failed to check critical approvals", "error", err)
+	}
+}
+
+// Check pages for critical approvals past the SLA, unless they are on hold,
+// and settles escalations whose approvals have been decided
+func (e *Escalator) Check(ctx context.Context) error {
+	open, err := e.escalations.ListOpenApprovalEscalations(ctx)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, escalation := range open {
+		approval, err := e.store.GetApproval(ctx, escalation.ApprovalID)
+		if err != nil && !errors.Is(err, store.ErrNotFound) {
+			errs = append(errs, err)
+			continue
+		}
+		if approval == nil || approval.Status != store.ApprovalStatusLocalPending {
+			if err := e.Settle(ctx, escalation.ApprovalID); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if escalation.TriggeredAt == nil && e.now().Sub(escalation.CreatedAt) >= e.sla {
+			// A held approval is waiting on purpose; it's paged once unheld
+			if held, err := e.held(ctx, escalation.ApprovalID); err != nil || held {
+				if err != nil {
+					errs = append(errs, err)
+				}
+				continue
+			}
+			if err := e.trigger(ctx, escalation, approval); err != nil {
+				errs = append(errs, fmt.Errorf("approval %s: %w", escalation.ApprovalID, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// held reports whether an approval is on hold
+func (e *Escalator) held(ctx context.Context, approvalID string) (bool, error) {
+	_, err := e.holds.GetApprovalHold(ctx, approvalID)
+	if errors.Is(err, store.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// trigger raises the incident for an overdue approval
+func (e *Escalator) trigger(ctx context.Context, escalation *store.ApprovalEscalation, approval *store.Approval) error {
+	session, err := e.store.GetSession(ctx, escalation.SessionID)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return err
+	}
+	if err := e.pager.Trigger(ctx, e.incident(escalation, approval, session)); err != nil {
+		return err
+	}
+	if _, err := e.escalations.MarkApprovalEscalationTriggered(ctx, escalation.ApprovalID); err != nil {
+		return err
+	}
+	slog.Info("escalated unanswered critical approval",
+		"approval_id", escalation.ApprovalID,
+		"session_id", escalation.SessionID,
+		"rule", escalation.Rule)
+	return nil
+}
+
+// Settle closes the escalation for a decided approval, resolving its
+// incident if one was raised. Approvals that aren't critical are ignored.
+func (e *Escalator) Settle(ctx context.Context, approvalID string) error {
+	escalation, err := e.escalations.GetApprovalEscalation(ctx, approvalID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	settled, err := e.escalations.MarkApprovalEscalationResolved(ctx, approvalID)
+	if err != nil || !settled || escalation.TriggeredAt == nil {
+		return err
+	}
+	note := "Approval was decided"
+	if approval, err := e.store.GetApproval(ctx, approvalID); err == nil {
+		note = fmt.Sprintf("Approval was %s", approval.Status)
+	}
+	return e.pager.Resolve(ctx, incidentKey(approvalID), note)
+}
+
+func incidentKey(approvalID string) string {
+	return "humanlayer-approval-" + approvalID
+}
+
+// incident describes an overdue approval
+func (e *Escalator) incident(escalation *store.ApprovalEscalation, approval *store.Approval, session *store.Session) Incident {
+	waiting := e.now().Sub(escalation.CreatedAt).Round(time.Minute)
+	name := escalation.SessionID
+	details := map[string]string{
+		"approval_id": approval.ID,
+		"session_id":  escalation.SessionID,
+		"tool":        approval.ToolName,
+		"rule":        escalation.Rule,
+		"waiting":     waiting.String(),
+	}
+	if session != nil {
+		if session.Title != "" {
+			name = session.Title
+		} else if session.Summary != "" {
+			name = session.Summary
+		}
+		details["working_dir"] = session.WorkingDir
+	}
+	input := string(approval.ToolInput)
+	if len(input) > maxToolInput {
+		input = input[:maxToolInput] + "..."
+	}
+	details["tool_input"] = input
+
+	links := []Link{
+		{Text: "Approval", URL: e.apiURL + "/approvals/" + approval.ID},
+		{Text: "Session", URL: e.apiURL + "/sessions/" + escalation.SessionID},
+	}
+	if e.uiURL != "" {
+		links = append([]Link{{Text: "Open in HumanLayer", URL: e.uiURL + "/#/sessions/" + escalation.SessionID}}, links...)
+	}
+	return Incident{
+		Key:     incidentKey(approval.ID),
+		Summary: fmt.Sprintf("Critical approval unanswered for %s: %s in %s", waiting, approval.ToolName, name),
+		Details: details,
+		Links:   links,
+	}
+}
@@ -0,0 +1,159 @@
+package escalation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePager records incidents
+type fakePager struct {
+	triggered []Incident
+	resolved  []string
+}
+
+func (p *fakePager) Trigger(ctx context.Context, incident Incident) error {
+	p.triggered = append(p.triggered, incident)
+	return nil
+}
+
+func (p *fakePager) Resolve(ctx context.Context, key, note string) error {
+	p.resolved = append(p.resolved, key)
+	return nil
+}
+
+func setup(t *testing.T) (*Escalator, *fakePager, store.Store, *time.Time) {
+	t.Helper()
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	created := time.Now()
+	require.NoError(t, s.CreateSession(ctx, &store.Session{ID: "sess-1", RunID: "run-1", Title: "Release", WorkingDir: "/src/app"}))
+	require.NoError(t, s.CreateApproval(ctx, &store.Approval{
+		ID: "appr-1", RunID: "run-1", SessionID: "sess-1", Status: store.ApprovalStatusLocalPending,
+		CreatedAt: created, ToolName: "Bash", ToolInput: json.RawMessage(`{"command":"make deploy"}`),
+	}))
+	require.NoError(t, s.CreateApprovalEscalation(ctx, &store.ApprovalEscalation{
+		ApprovalID: "appr-1", SessionID: "sess-1", ToolName: "Bash", Rule: "prod-deploy", CreatedAt: created,
+	}))
+
+	pager := &fakePager{}
+	e := New(s, s, s, pager, 15*time.Minute, "http://127.0.0.1:7777/api/v1", "http://localhost:1420", nil)
+	now := created
+	e.now = func() time.Time { return now }
+	return e, pager, s, &now
+}
+
+func TestEscalateAndResolve(t *testing.T) {
+	ctx := context.Background()
+	e, pager, s, now := setup(t)
+
+	require.NoError(t, e.Check(ctx))
+	assert.Empty(t, pager.triggered, "within the SLA")
+
+	*now = now.Add(16 * time.Minute)
+	require.NoError(t, e.Check(ctx))
+	require.Len(t, pager.triggered, 1)
+	incident := pager.triggered[0]
+	assert.Equal(t, "humanlayer-approval-appr-1", incident.Key)
+	assert.Equal(t, "Critical approval unanswered for 16m0s: Bash in Release", incident.Summary)
+	assert.Equal(t, "prod-deploy", incident.Details["rule"])
+	assert.Equal(t, "http://localhost:1420/#/sessions/sess-1", incident.Links[0].URL)
+	assert.Equal(t, "http://127.0.0.1:7777/api/v1/approvals/appr-1", incident.Links[1].URL)
+
+	require.NoError(t, e.Check(ctx))
+	assert.Len(t, pager.triggered, 1, "paged once")
+
+	require.NoError(t, s.UpdateApprovalResponse(ctx, "appr-1", store.ApprovalStatusLocalApproved, ""))
+	require.NoError(t, e.Settle(ctx, "appr-1"))
+	require.NoError(t, e.Settle(ctx, "appr-1"))
+	assert.Equal(t, []string{"humanlayer-approval-appr-1"}, pager.resolved)
+
+	open, err := s.ListOpenApprovalEscalations(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, open)
+}
+
+func TestDecidedBeforeSLA(t *testing.T) {
+	ctx := context.Background()
+	e, pager, s, now := setup(t)
+
+	require.NoError(t, s.UpdateApprovalResponse(ctx, "appr-1", store.ApprovalStatusLocalDenied, "no"))
+	*now = now.Add(time.Hour)
+	require.NoError(t, e.Check(ctx))
+	assert.Empty(t, pager.triggered)
+	assert.Empty(t, pager.resolved, "nothing was raised")
+
+	escalation, err := s.GetApprovalEscalation(ctx, "appr-1")
+	require.NoError(t, err)
+	assert.NotNil(t, escalation.ResolvedAt)
+}
+
+func TestHeldApprovalNotPaged(t *testing.T) {
+	ctx := context.Background()
+	e, pager, s, now := setup(t)
+
+	require.NoError(t, s.HoldApproval(ctx, &store.ApprovalHold{ApprovalID: "appr-1", SessionID: "sess-1", Reason: "Waiting on the change freeze", CreatedAt: *now}))
+	*now = now.Add(time.Hour)
+	require.NoError(t, e.Check(ctx))
+	assert.Empty(t, pager.triggered, "held past the SLA")
+
+	_, err := s.ReleaseApprovalHold(ctx, "appr-1")
+	require.NoError(t, err)
+	require.NoError(t, e.Check(ctx))
+	assert.Len(t, pager.triggered, 1, "paged once unheld")
+}
+
+func TestPagerDuty(t *testing.T) {
+	var events []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/enqueue", r.URL.Path)
+		var event map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		events = append(events, event)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	p := NewPagerDuty(server.URL, "routing-key")
+	ctx := context.Background()
+	require.NoError(t, p.Trigger(ctx, Incident{Key: "k1", Summary: "help", Links: []Link{{Text: "Approval", URL: "http://x"}}}))
+	require.NoError(t, p.Resolve(ctx, "k1", "approved"))
+
+	require.Len(t, events, 2)
+	assert.Equal(t, "trigger", events[0]["event_action"])
+	assert.Equal(t, "routing-key", events[0]["routing_key"])
+	assert.Equal(t, "critical", events[0]["payload"].(map[string]any)["severity"])
+	assert.Equal(t, "http://x", events[0]["links"].([]any)[0].(map[string]any)["href"])
+	assert.Equal(t, map[string]any{"routing_key": "routing-key", "event_action": "resolve", "dedup_key": "k1"}, events[1])
+}
+
+func TestOpsgenie(t *testing.T) {
+	var paths []string
+	var alert map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GenieKey api-key", r.Header.Get("Authorization"))
+		paths = append(paths, r.URL.RequestURI())
+		if r.URL.Path == "/v2/alerts" {
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&alert))
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	o := NewOpsgenie(server.URL, "api-key")
+	ctx := context.Background()
+	require.NoError(t, o.Trigger(ctx, Incident{Key: "k1", Summary: "help", Links: []Link{{Text: "Approval", URL: "http://x"}}}))
+	require.NoError(t, o.Resolve(ctx, "k1", "approved"))
+
+	assert.Equal(t, []string{"/v2/alerts", "/v2/alerts/k1/close?identifierType=alias"}, paths)
+	assert.Equal(t, "k1", alert["alias"])
+	assert.Equal(t, "P1", alert["priority"])
+	assert.Equal(t, "Approval: http://x\n", alert["description"])
+}
@@ -0,0 +1,144 @@
+package escalation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Provider endpoints
+const (
+	DefaultPagerDutyURL = "https://events.pagerduty.com"
+	DefaultOpsgenieURL  = "https://api.opsgenie.com"
+)
+
+// PagerDuty sends incidents through the Events API v2
+type PagerDuty struct {
+	baseURL    string
+	routingKey string
+	http       *http.Client
+}
+
+// NewPagerDuty creates a pager for an Events API v2 integration's routing key
+func NewPagerDuty(baseURL, routingKey string) *PagerDuty {
+	if baseURL == "" {
+		baseURL = DefaultPagerDutyURL
+	}
+	return &PagerDuty{baseURL: strings.TrimRight(baseURL, "/"), routingKey: routingKey, http: &http.Client{Timeout: requestTimeout}}
+}
+
+type pagerDutyLink struct {
+	Href string `json:"href"`
+	Text string `json:"text"`
+}
+
+// Trigger raises a critical incident, deduplicated by the incident key
+func (p *PagerDuty) Trigger(ctx context.Context, incident Incident) error {
+	links := make([]pagerDutyLink, 0, len(incident.Links))
+	for _, link := range incident.Links {
+		links = append(links, pagerDutyLink{Href: link.URL, Text: link.Text})
+	}
+	return p.enqueue(ctx, map[string]any{
+		"routing_key":  p.routingKey,
+		"event_action": "trigger",
+		"dedup_key":    incident.Key,
+		"payload": map[string]any{
+			"summary":        incident.Summary,
+			"source":         "humanlayer",
+			"severity":       "critical",
+			"custom_details": incident.Details,
+		},
+		"links": links,
+	})
+}
+
+// Resolve resolves the incident with the key
+func (p *PagerDuty) Resolve(ctx context.Context, key, note string) error {
+	return p.enqueue(ctx, map[string]any{
+		"routing_key":  p.routingKey,
+		"event_action": "resolve",
+		"dedup_key":    key,
+	})
+}
+
+func (p *PagerDuty) enqueue(ctx context.Context, event map[string]any) error {
+	return post(ctx, p.http, p.baseURL+"/v2/enqueue", nil, event)
+}
+
+// Opsgenie sends incidents as alerts through the Alert API
+type Opsgenie struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+}
+
+// NewOpsgenie creates a pager for an Opsgenie API integration key
+func NewOpsgenie(baseURL, apiKey string) *Opsgenie {
+	if baseURL == "" {
+		baseURL = DefaultOpsgenieURL
+	}
+	return &Opsgenie{baseURL: strings.TrimRight(baseURL, "/"), apiKey: apiKey, http: &http.Client{Timeout: requestTimeout}}
+}
+
+// Trigger creates a P1 alert whose alias is the incident key. Opsgenie has
+// no link field, so links are listed in the description.
+func (o *Opsgenie) Trigger(ctx context.Context, incident Incident) error {
+	var description strings.Builder
+	for _, link := range incident.Links {
+		fmt.Fprintf(&description, "%s: %s\n", link.Text, link.URL)
+	}
+	return post(ctx, o.http, o.baseURL+"/v2/alerts", o.headers(), map[string]any{
+		"message":     truncate(incident.Summary, 130),
+		"alias":       incident.Key,
+		"description": description.String(),
+		"details":     incident.Details,
+		"priority":    "P1",
+		"source":      "humanlayer",
+	})
+}
+
+// Resolve closes the alert with the key as alias
+func (o *Opsgenie) Resolve(ctx context.Context, key, note string) error {
+	return post(ctx, o.http, o.baseURL+"/v2/alerts/"+url.PathEscape(key)+"/close?identifierType=alias", o.headers(),
+		map[string]any{"source": "humanlayer", "note": note})
+}
+
+func (o *Opsgenie) headers() map[string]string {
+	return map[string]string{"Authorization": "GenieKey " + o.apiKey}
+}
+
+// truncate shortens s to n bytes, Opsgenie's limit for alert messages
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
+
+func post(ctx context.Context, client *http.Client, endpoint string, headers map[string]string, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("POST %s: %s", req.URL.Path, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,329 @@
+// Package experiment launches one query as several sessions with varied
+// parameters, each in its own git worktree, and compares their outcomes.
+package experiment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	claudecode "github.com/humanlayer/humanlayer/claudecode-go"
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/cmdstream"
+	"github.com/humanlayer/humanlayer/hld/gitcmd"
+	"github.com/humanlayer/humanlayer/hld/session"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// maxSessions caps the sessions one experiment may launch
+const maxSessions = 20
+
+// testTimeout bounds the test command run after each session
+const testTimeout = 10 * time.Minute
+
+// maxTestOutput keeps the tail of the test command's output
+const maxTestOutput = 4000
+
+// ErrInvalidRequest wraps every validation failure of a Request
+var ErrInvalidRequest = errors.New("invalid experiment")
+
+// Variant is one combination of parameters under test
+type Variant struct {
+	Label                      string   `json:"label"`
+	Model                      string   `json:"model,omitempty"` // opus, sonnet or haiku
+	Template                   string   `json:"template,omitempty"`
+	AppendSystemPrompt         string   `json:"append_system_prompt,omitempty"`
+	AutoAcceptEdits            bool     `json:"auto_accept_edits,omitempty"`
+	DangerouslySkipPermissions bool     `json:"dangerously_skip_permissions,omitempty"`
+	AutoDenyTools              []string `json:"auto_deny_tools,omitempty"`
+}
+
+// Request describes an experiment to launch
+type Request struct {
+	Name       string    `json:"name,omitempty"`
+	Query      string    `json:"query"`
+	WorkingDir string    `json:"working_dir"`
+	Variants   []Variant `json:"variants"`
+	// Sessions launched per variant; defaults to 1
+	RunsPerVariant int `json:"runs_per_variant,omitempty"`
+	// Shell command run in each worktree once its session finishes; exit
+	// status 0 counts as passing
+	TestCommand string `json:"test_command,omitempty"`
+}
+
+var models = map[string]claudecode.Model{
+	"opus":   claudecode.ModelOpus,
+	"sonnet": claudecode.ModelSonnet,
+	"haiku":  claudecode.ModelHaiku,
+}
+
+func (r *Request) validate() error {
+	if strings.TrimSpace(r.Query) == "" {
+		return fmt.Errorf("%w: query is required", ErrInvalidRequest)
+	}
+	if r.WorkingDir == "" {
+		return fmt.Errorf("%w: working_dir is required", ErrInvalidRequest)
+	}
+	if r.RunsPerVariant <= 0 {
+		r.RunsPerVariant = 1
+	}
+	if len(r.Variants) == 0 {
+		return fmt.Errorf("%w: at least one variant is required", ErrInvalidRequest)
+	}
+	if total := len(r.Variants) * r.RunsPerVariant; total < 2 || total > maxSessions {
+		return fmt.Errorf("%w: an experiment launches between 2 and %d sessions, got %d", ErrInvalidRequest, maxSessions, total)
+	}
+	labels := make(map[string]bool)
+	for _, v := range r.Variants {
+		if v.Label == "" {
+			return fmt.Errorf("%w: every variant needs a label", ErrInvalidRequest)
+		}
+		if labels[v.Label] {
+			return fmt.Errorf("%w: duplicate variant label %q", ErrInvalidRequest, v.Label)
+		}
+		labels[v.Label] = true
+		if _, ok := models[v.Model]; v.Model != "" && !ok {
+			return fmt.Errorf("%w: variant %q has unknown model %q", ErrInvalidRequest, v.Label, v.Model)
+		}
+	}
+	return nil
+}
+
+// WorktreeDir is where experiment worktrees are kept, next to the database
+func WorktreeDir(databasePath string) string {
+	return filepath.Join(filepath.Dir(databasePath), "experiments")
+}
+
+// Runner launches experiments and evaluates their sessions as they finish
+type Runner struct {
+	store    store.ExperimentStore
+	sessions session.SessionManager
+	eventBus bus.EventBus
+	dir      string // worktrees are created under dir/<experiment id>
+
+	inFlight sync.Map // session ID -> struct{}
+}
+
+// NewRunner creates an experiment runner that keeps worktrees under dir
+func NewRunner(s store.ExperimentStore, sessions session.SessionManager, eventBus bus.EventBus, dir string) *Runner {
+	return &Runner{store: s, sessions: sessions, eventBus: eventBus, dir: dir}
+}
+
+// Start records an experiment and launches its sessions. Each session works
+// in a detached worktree of the working directory's HEAD, so uncommitted
+// changes are not included.
+func (r *Runner) Start(ctx context.Context, req Request) (*store.Experiment, []*store.ExperimentRun, error) {
+	if err := req.validate(); err != nil {
+		return nil, nil, err
+	}
+	workingDir, err := filepath.Abs(req.WorkingDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	base, err := git(ctx, workingDir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: working_dir must be a git repository with at least one commit", ErrInvalidRequest)
+	}
+
+	variants, err := json.Marshal(req.Variants)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode variants: %w", err)
+	}
+	experiment := &store.Experiment{
+		ID:          uuid.New().String(),
+		Name:        req.Name,
+		Query:       req.Query,
+		WorkingDir:  workingDir,
+		BaseCommit:  strings.TrimSpace(base),
+		TestCommand: req.TestCommand,
+		Variants:    variants,
+	}
+	if err := r.store.CreateExperiment(ctx, experiment); err != nil {
+		return nil, nil, err
+	}
+
+	var runs []*store.ExperimentRun
+	for _, variant := range req.Variants {
+		for i := 1; i <= req.RunsPerVariant; i++ {
+			run, err := r.launch(ctx, experiment, variant, i)
+			if err != nil {
+				return experiment, runs, fmt.Errorf("failed to launch variant %q: %w", variant.Label, err)
+			}
+			runs = append(runs, run)
+		}
+	}
+	slog.Info("started experiment", "experiment_id", experiment.ID, "sessions", len(runs))
+	return experiment, runs, nil
+}
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func (r *Runner) launch(ctx context.Context, experiment *store.Experiment, variant Variant, n int) (*store.ExperimentRun, error) {
+	worktree := filepath.Join(r.dir, experiment.ID, fmt.Sprintf("%s-%d", unsafeChars.ReplaceAllString(variant.Label, "_"), n))
+	if err := os.MkdirAll(filepath.Dir(worktree), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create worktree directory: %w", err)
+	}
+	if _, err := git(ctx, experiment.WorkingDir, "worktree", "add", "--detach", worktree, experiment.BaseCommit); err != nil {
+		return nil, fmt.Errorf("failed to create worktree: %w", err)
+	}
+
+	name := experiment.Name
+	if name == "" {
+		name = "Experiment"
+	}
+	config := session.LaunchSessionConfig{
+		SessionConfig: claudecode.SessionConfig{
+			Query:              experiment.Query,
+			Model:              models[variant.Model],
+			WorkingDir:         worktree,
+			AppendSystemPrompt: variant.AppendSystemPrompt,
+			OutputFormat:       claudecode.OutputStreamJSON,
+		},
+		Title:                      fmt.Sprintf("%s: %s #%d", name, variant.Label, n),
+		Template:                   variant.Template,
+		AutoAcceptEdits:            variant.AutoAcceptEdits,
+		DangerouslySkipPermissions: variant.DangerouslySkipPermissions,
+		AutoDenyTools:              variant.AutoDenyTools,
+	}
+	sess, err := r.sessions.LaunchSession(ctx, config, false)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &store.ExperimentRun{
+		SessionID:    sess.ID,
+		ExperimentID: experiment.ID,
+		Variant:      variant.Label,
+		Worktree:     worktree,
+	}
+	if err := r.store.CreateExperimentRun(ctx, run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// Run evaluates experiment sessions as they finish until ctx is cancelled
+func (r *Runner) Run(ctx context.Context) {
+	sub := r.eventBus.Subscribe(ctx, bus.EventFilter{
+		Types: []bus.EventType{bus.EventSessionStatusChanged},
+	})
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-sub.Channel:
+			if !ok {
+				return
+			}
+			status, _ := event.Data["new_status"].(string)
+			sessionID, _ := event.Data["session_id"].(string)
+			if sessionID == "" || (status != store.SessionStatusCompleted && status != store.SessionStatusFailed &&
+				status != store.SessionStatusInterrupted) {
+				continue
+			}
+			if _, busy := r.inFlight.LoadOrStore(sessionID, struct{}{}); busy {
+				continue
+			}
+			go func() {
+				defer r.inFlight.Delete(sessionID)
+				if err := r.Evaluate(ctx, sessionID); err != nil {
+					slog.Warn("failed to evaluate experiment session", "session_id", sessionID, "error", err)
+				}
+			}()
+		}
+	}
+}
+
+// Evaluate measures the diff a finished experiment session left in its
+// worktree and runs the test command there. Sessions that aren't part of an
+// experiment, or were already evaluated, are ignored.
+func (r *Runner) Evaluate(ctx context.Context, sessionID string) error {
+	run, err := r.store.GetExperimentRun(ctx, sessionID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if run.EvaluatedAt != nil {
+		return nil
+	}
+	experiment, err := r.store.GetExperiment(ctx, run.ExperimentID)
+	if err != nil {
+		return err
+	}
+
+	// Stage everything so new files count toward the diff
+	if _, err := git(ctx, run.Worktree, "add", "-A"); err != nil {
+		return err
+	}
+	numstat, err := git(ctx, run.Worktree, "diff", "--cached", "--numstat", experiment.BaseCommit)
+	if err != nil {
+		return err
+	}
+	files, added, removed := parseNumstat(numstat)
+	run.FilesChanged, run.LinesAdded, run.LinesRemoved = &files, &added, &removed
+
+	if experiment.TestCommand != "" {
+		// Output is streamed under the experiment's ID
+		stream := cmdstream.New(r.eventBus, experiment.ID, sessionID).Command(experiment.TestCommand)
+		passed, output := runTests(ctx, run.Worktree, experiment.TestCommand, stream)
+		run.TestsPassed = &passed
+		run.TestOutput = output
+	}
+	now := time.Now()
+	run.EvaluatedAt = &now
+	return r.store.UpdateExperimentRun(ctx, run)
+}
+
+// parseNumstat totals `git diff --numstat` output; binary files count as
+// changed without lines
+func parseNumstat(out string) (files, added, removed int) {
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) < 3 {
+			continue
+		}
+		files++
+		if n, err := strconv.Atoi(fields[0]); err == nil {
+			added += n
+		}
+		if n, err := strconv.Atoi(fields[1]); err == nil {
+			removed += n
+		}
+	}
+	return files, added, removed
+}
+
+func runTests(ctx context.Context, dir, command string, stream *cmdstream.Command) (bool, string) {
+	ctx, cancel := context.WithTimeout(ctx, testTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = dir
+	var output bytes.Buffer
+	stream.Attach(cmd, &output)
+	err := cmd.Run()
+	stream.Finish(err)
+	out := output.Bytes()
+	if len(out) > maxTestOutput {
+		out = out[len(out)-maxTestOutput:]
+	}
+	return err == nil, string(out)
+}
+
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	out, _, err := gitcmd.Run(ctx, gitcmd.Cmd{Dir: dir, Args: args})
+	return out, err
+}
@@ -0,0 +1,104 @@
+package experiment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/session"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSessions records launches as sessions in the store instead of running Claude
+type fakeSessions struct {
+	session.SessionManager
+	store    *store.MemoryStore
+	launched []session.LaunchSessionConfig
+}
+
+func (f *fakeSessions) LaunchSession(ctx context.Context, config session.LaunchSessionConfig, isDraft bool) (*session.Session, error) {
+	f.launched = append(f.launched, config)
+	id := fmt.Sprintf("sess-%d", len(f.launched))
+	err := f.store.CreateSession(ctx, &store.Session{
+		ID: id, RunID: "run-" + id, Query: config.Query, WorkingDir: config.WorkingDir,
+		Status: store.SessionStatusRunning, CreatedAt: time.Now(),
+	})
+	return &session.Session{ID: id}, err
+}
+
+func initRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test"},
+		{"commit", "-q", "--allow-empty", "-m", "initial"},
+	} {
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	return dir
+}
+
+func TestValidate(t *testing.T) {
+	r := NewRunner(store.NewInMemoryStore(), nil, nil, t.TempDir())
+	for name, req := range map[string]Request{
+		"single session":  {Query: "q", WorkingDir: "/tmp", Variants: []Variant{{Label: "a"}}},
+		"duplicate label": {Query: "q", WorkingDir: "/tmp", Variants: []Variant{{Label: "a"}, {Label: "a"}}},
+		"unknown model":   {Query: "q", WorkingDir: "/tmp", Variants: []Variant{{Label: "a"}, {Label: "b", Model: "gpt"}}},
+		"not a repo":      {Query: "q", WorkingDir: t.TempDir(), Variants: []Variant{{Label: "a"}, {Label: "b"}}},
+	} {
+		_, _, err := r.Start(context.Background(), req)
+		assert.True(t, errors.Is(err, ErrInvalidRequest), "%s: %v", name, err)
+	}
+}
+
+func TestExperiment(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	sessions := &fakeSessions{store: s}
+	r := NewRunner(s, sessions, bus.NewEventBus(), t.TempDir())
+
+	exp, runs, err := r.Start(ctx, Request{
+		Query:       "Add a greeting",
+		WorkingDir:  initRepo(t),
+		Variants:    []Variant{{Label: "opus", Model: "opus"}, {Label: "haiku", Model: "haiku", AutoAcceptEdits: true}},
+		TestCommand: "test -f hello.txt",
+	})
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Equal(t, "Experiment: haiku #1", sessions.launched[1].Title)
+	assert.True(t, sessions.launched[1].AutoAcceptEdits)
+
+	// Only the opus session leaves a file behind, so only its tests pass
+	require.NoError(t, os.WriteFile(filepath.Join(runs[0].Worktree, "hello.txt"), []byte("hi\nthere\n"), 0644))
+	cost := 0.25
+	for _, run := range runs {
+		status := store.SessionStatusCompleted
+		require.NoError(t, s.UpdateSession(ctx, run.SessionID, store.SessionUpdate{Status: &status, CostUSD: &cost}))
+		require.NoError(t, r.Evaluate(ctx, run.SessionID))
+	}
+
+	report, err := BuildReport(ctx, s, s, exp.ID)
+	require.NoError(t, err)
+	require.Len(t, report.Results, 2)
+	opus, haiku := report.Results[0], report.Results[1]
+	assert.Equal(t, "opus", opus.Variant)
+	assert.Equal(t, 1, opus.Completed)
+	assert.Equal(t, 1.0, opus.AvgFilesChanged)
+	assert.Equal(t, 2.0, opus.AvgLinesAdded)
+	assert.Equal(t, 1.0, *opus.TestPassRate)
+	assert.Equal(t, 0.25, opus.AvgCostUSD)
+	assert.Equal(t, 0.0, haiku.AvgFilesChanged)
+	assert.Equal(t, 0.0, *haiku.TestPassRate)
+	assert.Len(t, report.Runs, 2)
+}
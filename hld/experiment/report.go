@@ -0,0 +1,136 @@
+package experiment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// RunResult is one session's outcome within a report
+type RunResult struct {
+	*store.ExperimentRun
+	Status     string   `json:"status"`
+	CostUSD    *float64 `json:"cost_usd,omitempty"`
+	DurationMS *int     `json:"duration_ms,omitempty"`
+}
+
+// VariantResult compares a variant's sessions. Averages only count sessions
+// that have been evaluated; TestPassRate is nil until a test has run.
+type VariantResult struct {
+	Variant         string   `json:"variant"`
+	Runs            int      `json:"runs"`
+	Completed       int      `json:"completed"`
+	Failed          int      `json:"failed"`
+	Evaluated       int      `json:"evaluated"`
+	TestsRun        int      `json:"tests_run"`
+	TestsPassed     int      `json:"tests_passed"`
+	TestPassRate    *float64 `json:"test_pass_rate,omitempty"`
+	AvgFilesChanged float64  `json:"avg_files_changed"`
+	AvgLinesAdded   float64  `json:"avg_lines_added"`
+	AvgLinesRemoved float64  `json:"avg_lines_removed"`
+	TotalCostUSD    float64  `json:"total_cost_usd"`
+	AvgCostUSD      float64  `json:"avg_cost_usd"`
+}
+
+// Report is an experiment with its per-variant comparison
+type Report struct {
+	*store.Experiment
+	Variants []Variant       `json:"variants"`
+	Results  []VariantResult `json:"results"`
+	Runs     []RunResult     `json:"runs"`
+}
+
+// BuildReport compares the outcomes of an experiment's variants
+func BuildReport(ctx context.Context, s store.ConversationStore, experiments store.ExperimentStore, id string) (*Report, error) {
+	experiment, err := experiments.GetExperiment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{Experiment: experiment, Runs: []RunResult{}}
+	if err := json.Unmarshal(experiment.Variants, &report.Variants); err != nil {
+		return nil, fmt.Errorf("failed to decode variants: %w", err)
+	}
+
+	runs, err := experiments.ListExperimentRuns(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[string]*VariantResult)
+	for _, v := range report.Variants {
+		results[v.Label] = &VariantResult{Variant: v.Label}
+	}
+
+	for _, run := range runs {
+		result := RunResult{ExperimentRun: run}
+		if sess, err := s.GetSession(ctx, run.SessionID); err == nil {
+			result.Status = sess.Status
+			result.CostUSD = sess.CostUSD
+			result.DurationMS = sess.DurationMS
+		}
+		report.Runs = append(report.Runs, result)
+
+		v, ok := results[run.Variant]
+		if !ok {
+			continue
+		}
+		v.add(result)
+	}
+
+	for _, variant := range report.Variants {
+		report.Results = append(report.Results, results[variant.Label].finish())
+	}
+	return report, nil
+}
+
+func (v *VariantResult) add(run RunResult) {
+	v.Runs++
+	switch run.Status {
+	case store.SessionStatusCompleted:
+		v.Completed++
+	case store.SessionStatusFailed:
+		v.Failed++
+	}
+	if run.CostUSD != nil {
+		v.TotalCostUSD += *run.CostUSD
+	}
+	if run.EvaluatedAt == nil {
+		return
+	}
+	v.Evaluated++
+	v.AvgFilesChanged += float64(deref(run.FilesChanged))
+	v.AvgLinesAdded += float64(deref(run.LinesAdded))
+	v.AvgLinesRemoved += float64(deref(run.LinesRemoved))
+	if run.TestsPassed != nil {
+		v.TestsRun++
+		if *run.TestsPassed {
+			v.TestsPassed++
+		}
+	}
+}
+
+// finish turns the sums collected by add into averages
+func (v *VariantResult) finish() VariantResult {
+	if v.Evaluated > 0 {
+		n := float64(v.Evaluated)
+		v.AvgFilesChanged /= n
+		v.AvgLinesAdded /= n
+		v.AvgLinesRemoved /= n
+	}
+	if v.TestsRun > 0 {
+		rate := float64(v.TestsPassed) / float64(v.TestsRun)
+		v.TestPassRate = &rate
+	}
+	if v.Runs > 0 {
+		v.AvgCostUSD = v.TotalCostUSD / float64(v.Runs)
+	}
+	return *v
+}
+
+func deref(n *int) int {
+	if n == nil {
+		return 0
+	}
+	return *n
+}
@@ -0,0 +1,211 @@
+// Package federation joins daemons into a federation: member daemons, one
+// per developer machine, register with a hub daemon, which shows their
+// sessions and pending approvals in one place and can decide approvals on
+// the member that owns them. Every request between them carries the
+// federation's shared token.
+package federation
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/resolution"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+const (
+	requestTimeout = 10 * time.Second
+	// HeartbeatInterval is how often members register with the hub
+	HeartbeatInterval = 30 * time.Second
+	// staleAfter is how long after its last heartbeat a member is shown as
+	// offline
+	staleAfter = 3 * HeartbeatInterval
+	// decisionTTL is how long a member accepts a decision signed by the hub
+	decisionTTL = 5 * time.Minute
+)
+
+// ResolutionHeader carries the hub's signature of a decision, binding it to
+// the approval and making it good for one use
+const ResolutionHeader = "X-HLD-Resolution"
+
+var (
+	// ErrUnknownMember is returned for a member that hasn't registered
+	ErrUnknownMember = errors.New("unknown federation member")
+	// ErrInvalidMemberURL is returned for registering a member without an
+	// http or https URL
+	ErrInvalidMemberURL = errors.New("member url must be an http or https URL")
+	// ErrMemberNameTaken is returned for registering a name an online member
+	// holds at another URL, which would redirect its decisions
+	ErrMemberNameTaken = errors.New("federation member name is registered to another url")
+)
+
+// Member is a daemon registered with the hub
+type Member struct {
+	Name     string    `json:"name"`
+	URL      string    `json:"url"` // Base URL of the member's REST API
+	LastSeen time.Time `json:"last_seen"`
+	Online   bool      `json:"online"`
+}
+
+// Session is the part of a session the hub shows
+type Session struct {
+	ID             string    `json:"id"`
+	Title          string    `json:"title,omitempty"`
+	Summary        string    `json:"summary,omitempty"`
+	Query          string    `json:"query"`
+	Status         string    `json:"status"`
+	Model          string    `json:"model,omitempty"`
+	WorkingDir     string    `json:"working_dir,omitempty"`
+	CostUSD        float64   `json:"cost_usd,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	LastActivityAt time.Time `json:"last_activity_at"`
+}
+
+// Snapshot is what a member shares with the hub
+type Snapshot struct {
+	Sessions  []Session         `json:"sessions"`
+	Approvals []*store.Approval `json:"approvals"` // Pending approvals
+}
+
+// MemberSnapshot is a member's snapshot as the hub fetched it
+type MemberSnapshot struct {
+	Member Member `json:"member"`
+	Snapshot
+	Error string `json:"error,omitempty"` // Why the member couldn't be reached
+}
+
+// Decision is an approval decision proxied from the hub to a member
+type Decision struct {
+	Approved bool   `json:"approved"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+// TakeSnapshot collects a member's sessions and pending approvals
+func TakeSnapshot(ctx context.Context, s store.ConversationStore) (*Snapshot, error) {
+	sessions, err := s.ListSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	approvals, err := s.ListPendingApprovals(ctx)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &Snapshot{Sessions: make([]Session, 0, len(sessions)), Approvals: approvals}
+	for _, sess := range sessions {
+		var cost float64
+		if sess.CostUSD != nil {
+			cost = *sess.CostUSD
+		}
+		snapshot.Sessions = append(snapshot.Sessions, Session{
+			ID:             sess.ID,
+			Title:          sess.Title,
+			Summary:        sess.Summary,
+			Query:          sess.Query,
+			Status:         sess.Status,
+			Model:          sess.Model,
+			WorkingDir:     sess.WorkingDir,
+			CostUSD:        cost,
+			CreatedAt:      sess.CreatedAt,
+			LastActivityAt: sess.LastActivityAt,
+		})
+	}
+	return snapshot, nil
+}
+
+// DecisionSigner signs and verifies decisions sent between daemons that
+// share token
+func DecisionSigner(token string) *resolution.Signer {
+	return resolution.NewSigner([]byte(token))
+}
+
+// Authorized reports whether an Authorization header carries the token
+func Authorized(header, token string) bool {
+	presented, ok := strings.CutPrefix(header, "Bearer ")
+	return ok && token != "" && subtle.ConstantTimeCompare([]byte(presented), []byte(token)) == 1
+}
+
+// client makes token-authenticated requests to another daemon's
+// federation routes
+type client struct {
+	token string
+	http  *http.Client
+}
+
+func newClient(token string) *client {
+	return &client{token: token, http: &http.Client{Timeout: requestTimeout}}
+}
+
+// do sends body to baseURL's /api/v1/federation path and decodes the
+// response into out
+func (c *client) do(ctx context.Context, method, baseURL, path string, body, out any) error {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	return c.send(ctx, method, baseURL, path, data, nil, out)
+}
+
+// send is do for an encoded body, with extra headers
+func (c *client) send(ctx context.Context, method, baseURL, path string, data []byte, header http.Header, out any) error {
+	var reader io.Reader
+	if data != nil {
+		reader = bytes.NewReader(data)
+	}
+	endpoint := strings.TrimRight(baseURL, "/") + "/api/v1/federation" + path
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if data != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode >= 300 {
+		var failure struct {
+			Error string `json:"error"`
+		}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&failure)
+		return &RemoteError{Status: resp.StatusCode, Message: failure.Error}
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
+
+// RemoteError is an error response from another daemon
+type RemoteError struct {
+	Status  int
+	Message string
+}
+
+func (e *RemoteError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("federation request failed: %d %s", e.Status, http.StatusText(e.Status))
+	}
+	return fmt.Sprintf("federation request failed: %d %s", e.Status, e.Message)
+}
+
+func approvalPath(id string) string {
+	return "/approvals/" + url.PathEscape(id) + "/decide"
+}
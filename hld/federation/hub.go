@@ -0,0 +1,130 @@
+package federation
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/resolution"
+)
+
+// Hub keeps the members that registered with it and fetches their
+// sessions and approvals on request. Members are kept in memory; they
+// register again within a heartbeat after the hub restarts.
+type Hub struct {
+	client *client
+	signer *resolution.Signer
+	now    func() time.Time
+
+	mu      sync.Mutex
+	members map[string]*Member
+}
+
+// NewHub creates a hub accepting members that present token
+func NewHub(token string) *Hub {
+	return &Hub{client: newClient(token), signer: DecisionSigner(token), now: time.Now, members: make(map[string]*Member)}
+}
+
+// Register adds a member, or records a heartbeat from one. A name can only
+// move to another URL once its member has gone offline.
+func (h *Hub) Register(name, memberURL string) error {
+	u, err := url.Parse(memberURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidMemberURL
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if existing, ok := h.members[name]; !ok || existing.URL != memberURL {
+		if ok && h.now().Sub(existing.LastSeen) < staleAfter {
+			slog.Warn("refused federation member takeover", "name", name, "url", existing.URL, "new_url", memberURL)
+			return ErrMemberNameTaken
+		}
+		slog.Info("federation member registered", "name", name, "url", memberURL)
+	}
+	h.members[name] = &Member{Name: name, URL: memberURL, LastSeen: h.now()}
+	return nil
+}
+
+// Members returns the registered members by name
+func (h *Hub) Members() []Member {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	members := make([]Member, 0, len(h.members))
+	for _, m := range h.members {
+		member := *m
+		member.Online = h.now().Sub(m.LastSeen) < staleAfter
+		members = append(members, member)
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].Name < members[j].Name })
+	return members
+}
+
+func (h *Hub) member(name string) (Member, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	m, ok := h.members[name]
+	if !ok {
+		return Member{}, ErrUnknownMember
+	}
+	return *m, nil
+}
+
+// Snapshots fetches every online member's sessions and pending approvals.
+// Members that can't be reached are included with the error.
+func (h *Hub) Snapshots(ctx context.Context) []MemberSnapshot {
+	members := h.Members()
+	snapshots := make([]MemberSnapshot, len(members))
+	var wg sync.WaitGroup
+	for i, member := range members {
+		snapshots[i] = MemberSnapshot{Member: member, Snapshot: Snapshot{Sessions: []Session{}}}
+		if !member.Online {
+			snapshots[i].Error = "member is offline"
+			continue
+		}
+		wg.Add(1)
+		go func(i int, member Member) {
+			defer wg.Done()
+			var snapshot Snapshot
+			if err := h.client.do(ctx, http.MethodGet, member.URL, "/snapshot", nil, &snapshot); err != nil {
+				slog.Warn("failed to fetch federation member snapshot", "member", member.Name, "error", err)
+				snapshots[i].Error = err.Error()
+				return
+			}
+			snapshots[i].Snapshot = snapshot
+		}(i, member)
+	}
+	wg.Wait()
+	return snapshots
+}
+
+// Decide decides an approval on the member that owns it. The decision is
+// signed for that approval, so the member can't be made to apply it twice or
+// to another approval.
+func (h *Hub) Decide(ctx context.Context, memberName, approvalID string, decision Decision) error {
+	member, err := h.member(memberName)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(decision)
+	if err != nil {
+		return err
+	}
+	token, err := h.signer.Issue(approvalID, body, decisionTTL)
+	if err != nil {
+		return err
+	}
+	header := http.Header{ResolutionHeader: {token}}
+	if err := h.client.send(ctx, http.MethodPost, member.URL, approvalPath(approvalID), body, header, nil); err != nil {
+		return err
+	}
+	slog.Info("decided approval on federation member",
+		"member", memberName,
+		"approval_id", approvalID,
+		"approved", decision.Approved)
+	return nil
+}
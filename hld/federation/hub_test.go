@@ -0,0 +1,56 @@
+package federation
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorized(t *testing.T) {
+	assert.True(t, Authorized("Bearer secret", "secret"))
+	assert.False(t, Authorized("Bearer other", "secret"))
+	assert.False(t, Authorized("secret", "secret"))
+	assert.False(t, Authorized("Bearer ", ""), "an empty token authorizes nothing")
+}
+
+func TestHubSkipsStaleMembers(t *testing.T) {
+	var fetched int
+	member := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched++
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		assert.Equal(t, "/api/v1/federation/snapshot", r.URL.Path)
+		_, _ = w.Write([]byte(`{"sessions":[{"id":"sess-1","query":"q","status":"running"}],"approvals":[]}`))
+	}))
+	defer member.Close()
+
+	now := time.Now()
+	hub := NewHub("secret")
+	hub.now = func() time.Time { return now }
+	require.ErrorIs(t, hub.Register("laptop", "laptop:8080"), ErrInvalidMemberURL)
+	require.NoError(t, hub.Register("laptop", member.URL))
+
+	snapshots := hub.Snapshots(context.Background())
+	require.Len(t, snapshots, 1)
+	assert.Empty(t, snapshots[0].Error)
+	require.Len(t, snapshots[0].Sessions, 1)
+	assert.Equal(t, "sess-1", snapshots[0].Sessions[0].ID)
+
+	require.ErrorIs(t, hub.Register("laptop", "http://elsewhere:8080"), ErrMemberNameTaken, "an online member's name can't move")
+
+	now = now.Add(staleAfter)
+	assert.False(t, hub.Members()[0].Online)
+	snapshots = hub.Snapshots(context.Background())
+	assert.Equal(t, "member is offline", snapshots[0].Error)
+	assert.Equal(t, 1, fetched, "offline members aren't asked")
+
+	err := hub.Decide(context.Background(), "desktop", "appr-1", Decision{Approved: true})
+	assert.ErrorIs(t, err, ErrUnknownMember)
+
+	require.NoError(t, hub.Register("laptop", "http://elsewhere:8080"), "an offline member's name can move")
+	assert.Equal(t, "http://elsewhere:8080", hub.Members()[0].URL)
+}
@@ -0,0 +1,51 @@
+package federation
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Registrar keeps a member daemon registered with its hub
+type Registrar struct {
+	client *client
+	hubURL string
+	name   string
+	url    string // Where the hub reaches this daemon
+}
+
+// NewRegistrar creates a registrar announcing the daemon at memberURL to the
+// hub as name
+func NewRegistrar(hubURL, token, name, memberURL string) *Registrar {
+	return &Registrar{client: newClient(token), hubURL: hubURL, name: name, url: memberURL}
+}
+
+// Run registers with the hub every heartbeat until ctx is done
+func (r *Registrar) Run(ctx context.Context) {
+	ticker := time.NewTicker(HeartbeatInterval)
+	defer ticker.Stop()
+	registered := false
+	for {
+		err := r.Register(ctx)
+		switch {
+		case err != nil && registered:
+			slog.Warn("lost federation hub", "hub_url", r.hubURL, "error", err)
+		case err != nil:
+			slog.Debug("failed to register with federation hub", "hub_url", r.hubURL, "error", err)
+		case !registered:
+			slog.Info("registered with federation hub", "hub_url", r.hubURL, "name", r.name)
+		}
+		registered = err == nil
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Register announces the daemon to the hub
+func (r *Registrar) Register(ctx context.Context) error {
+	return r.client.do(ctx, http.MethodPost, r.hubURL, "/members", map[string]string{"name": r.name, "url": r.url}, nil)
+}
@@ -0,0 +1,115 @@
+// Package feedback keeps the daemon's model answers, with secrets redacted
+// from their prompts, so users can rate them and prompt templates can be
+// compared by how well their answers were received.
+package feedback
+
+import (
+	"context"
+	"regexp"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// maxTextLength caps the stored prompt and response; commit message prompts
+// carry whole diffs
+const maxTextLength = 64 * 1024
+
+// redacted replaces anything that looks like a credential
+const redacted = "[REDACTED]"
+
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`),
+	regexp.MustCompile(`\b(?:sk|pk|rk)-[A-Za-z0-9_-]{16,}`),                      // Anthropic, OpenAI, Stripe style keys
+	regexp.MustCompile(`\b(?:ghp|gho|ghu|ghs|ghr|github_pat)_[A-Za-z0-9_]{20,}`), // GitHub tokens
+	regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`),                        // Slack tokens
+	regexp.MustCompile(`\b(?:AKIA|ASIA)[A-Z0-9]{16}\b`),                          // AWS access key IDs
+	regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/=-]{16,}`),
+	regexp.MustCompile(`(?i)\b[a-z_]*(?:password|passwd|secret|token|api_?key)\b\s*[:=]\s*["']?[^\s"',;]{4,}`),
+}
+
+// Redact replaces credentials and keys in text
+func Redact(text string) string {
+	for _, pattern := range secretPatterns {
+		text = pattern.ReplaceAllString(text, redacted)
+	}
+	return text
+}
+
+func truncate(text string) string {
+	if len(text) <= maxTextLength {
+		return text
+	}
+	return text[:maxTextLength] + "\n[truncated]"
+}
+
+// Record redacts and stores an output, assigning its ID
+func Record(ctx context.Context, s store.AIOutputStore, output *store.AIOutput) error {
+	output.ID = uuid.New().String()
+	output.Prompt = truncate(Redact(output.Prompt))
+	output.Response = truncate(Redact(output.Response))
+	return s.CreateAIOutput(ctx, output)
+}
+
+// TemplateStats compares the ratings of answers from one template version
+type TemplateStats struct {
+	Kind            string   `json:"kind"`
+	Template        string   `json:"template"`
+	TemplateVersion string   `json:"template_version"`
+	Outputs         int      `json:"outputs"`
+	Rated           int      `json:"rated"`
+	Up              int      `json:"up"`
+	Down            int      `json:"down"`
+	Comments        int      `json:"comments"`
+	UpRate          *float64 `json:"up_rate,omitempty"` // share of rated outputs rated up
+}
+
+// Summarize groups outputs by template version, best rated first
+func Summarize(outputs []*store.AIOutput) []TemplateStats {
+	type key struct{ kind, template, version string }
+	groups := make(map[key]*TemplateStats)
+	for _, output := range outputs {
+		k := key{output.Kind, output.Template, output.TemplateVersion}
+		stats, ok := groups[k]
+		if !ok {
+			stats = &TemplateStats{Kind: k.kind, Template: k.template, TemplateVersion: k.version}
+			groups[k] = stats
+		}
+		stats.Outputs++
+		switch output.Rating {
+		case store.RatingUp:
+			stats.Rated++
+			stats.Up++
+		case store.RatingDown:
+			stats.Rated++
+			stats.Down++
+		}
+		if output.Comment != "" {
+			stats.Comments++
+		}
+	}
+
+	result := make([]TemplateStats, 0, len(groups))
+	for _, stats := range groups {
+		if stats.Rated > 0 {
+			rate := float64(stats.Up) / float64(stats.Rated)
+			stats.UpRate = &rate
+		}
+		result = append(result, *stats)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if (a.UpRate == nil) != (b.UpRate == nil) {
+			return a.UpRate != nil
+		}
+		if a.UpRate != nil && *a.UpRate != *b.UpRate {
+			return *a.UpRate > *b.UpRate
+		}
+		return a.TemplateVersion < b.TemplateVersion
+	})
+	return result
+}
@@ -0,0 +1,199 @@
+// Package gitcmd runs git for the daemon: under a context and a timeout, in
+// a minimal environment where git can't prompt or localise its output, and
+// keeping duration metrics per subcommand.
+package gitcmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/workspace"
+)
+
+// DefaultTimeout bounds a git command that doesn't set its own
+const DefaultTimeout = 2 * time.Minute
+
+// waitDelay is how long a killed command's children, such as hooks or
+// credential helpers, may hold its output open
+const waitDelay = 500 * time.Millisecond
+
+// lockedEnv is set for every command. Prompts would hang the daemon, output
+// is parsed so it stays untranslated, and optional locks are skipped so
+// polling status doesn't contend with an agent's own git commands.
+var lockedEnv = []string{"GIT_TERMINAL_PROMPT=0", "LC_ALL=C", "GIT_OPTIONAL_LOCKS=0"}
+
+// inheritedEnv is what local commands keep of the daemon's environment:
+// enough to find git, the user's config and identity, and to authenticate
+// over SSH
+var inheritedEnv = []string{
+	"PATH", "HOME", "USER", "TMPDIR", "XDG_CONFIG_HOME", "SYSTEMROOT",
+	"SSH_AUTH_SOCK", "GIT_SSH_COMMAND",
+	"GIT_AUTHOR_NAME", "GIT_AUTHOR_EMAIL", "GIT_COMMITTER_NAME", "GIT_COMMITTER_EMAIL",
+}
+
+// ErrTimeout is wrapped by the error of a command that ran out of time
+var ErrTimeout = errors.New("timed out")
+
+// Cmd is one git invocation
+type Cmd struct {
+	// Host runs the command; nil means this machine
+	Host workspace.Host
+	Dir  string
+	Args []string
+	// Env adds variables, such as GIT_INDEX_FILE
+	Env   []string
+	Stdin io.Reader
+	// Stdout receives output as it's produced; when nil, Run returns it
+	Stdout io.Writer
+	// Stderr also receives error output as it's produced, such as what
+	// hooks print
+	Stderr io.Writer
+	// Timeout defaults to DefaultTimeout
+	Timeout time.Duration
+}
+
+// Error is a failed git command
+type Error struct {
+	Subcommand string
+	Err        error
+	Stderr     string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("git %s: %v: %s", e.Subcommand, e.Err, strings.TrimSpace(e.Stderr))
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Env returns the environment for a local git command with extra added
+func Env(extra ...string) []string {
+	env := make([]string, 0, len(inheritedEnv)+len(lockedEnv)+len(extra))
+	for _, name := range inheritedEnv {
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+value)
+		}
+	}
+	env = append(env, lockedEnv...)
+	return append(env, extra...)
+}
+
+// Run runs c, returning stdout (unless c.Stdout is set) and stderr
+func Run(ctx context.Context, c Cmd) (string, string, error) {
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	host := c.Host
+	if host == nil {
+		host = workspace.Local{}
+	}
+	var cmd *exec.Cmd
+	if _, local := host.(workspace.Local); local {
+		cmd = host.Command(ctx, c.Dir, "git", c.Args...)
+		cmd.Env = Env(c.Env...)
+	} else {
+		// A remote shell has its own environment; pin what matters there
+		args := append(append(append([]string{}, lockedEnv...), c.Env...), "git")
+		cmd = host.Command(ctx, c.Dir, "env", append(args, c.Args...)...)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = c.Stdin
+	cmd.Stdout = &stdout
+	if c.Stdout != nil {
+		cmd.Stdout = c.Stdout
+	}
+	cmd.Stderr = &stderr
+	if c.Stderr != nil {
+		cmd.Stderr = io.MultiWriter(&stderr, c.Stderr)
+	}
+	cmd.WaitDelay = waitDelay
+
+	subcommand := Subcommand(c.Args)
+	start := time.Now()
+	err := cmd.Run()
+	timedOut := err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded)
+	metrics.record(subcommand, time.Since(start), err != nil, timedOut)
+	if err != nil {
+		if timedOut {
+			err = fmt.Errorf("%w after %s", ErrTimeout, timeout)
+		}
+		return "", stderr.String(), &Error{Subcommand: subcommand, Err: err, Stderr: stderr.String()}
+	}
+	return stdout.String(), stderr.String(), nil
+}
+
+// Subcommand finds the git subcommand in args, skipping global options
+func Subcommand(args []string) string {
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "-c" || arg == "-C" || arg == "--git-dir" || arg == "--work-tree":
+			i++
+		case strings.HasPrefix(arg, "-"):
+		default:
+			return arg
+		}
+	}
+	return ""
+}
+
+// Stats summarise the runs of one git subcommand
+type Stats struct {
+	Subcommand string `json:"subcommand"`
+	Count      int64  `json:"count"`
+	Errors     int64  `json:"errors"`
+	Timeouts   int64  `json:"timeouts"`
+	TotalMS    int64  `json:"total_ms"`
+	MaxMS      int64  `json:"max_ms"`
+}
+
+type registry struct {
+	mu    sync.Mutex
+	stats map[string]*Stats
+}
+
+var metrics = &registry{stats: make(map[string]*Stats)}
+
+func (r *registry) record(subcommand string, duration time.Duration, failed, timedOut bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.stats[subcommand]
+	if !ok {
+		s = &Stats{Subcommand: subcommand}
+		r.stats[subcommand] = s
+	}
+	ms := duration.Milliseconds()
+	s.Count++
+	s.TotalMS += ms
+	s.MaxMS = max(s.MaxMS, ms)
+	if failed {
+		s.Errors++
+	}
+	if timedOut {
+		s.Timeouts++
+	}
+}
+
+// Metrics returns the stats of every subcommand run so far, by name
+func Metrics() []Stats {
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	result := make([]Stats, 0, len(metrics.stats))
+	for _, s := range metrics.stats {
+		result = append(result, *s)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Subcommand < result[j].Subcommand })
+	return result
+}
@@ -0,0 +1,115 @@
+package gitcmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunEnvironment(t *testing.T) {
+	t.Setenv("HLD_TEST_SECRET", "leak")
+	t.Setenv("LC_ALL", "de_DE.UTF-8")
+	t.Setenv("GIT_AUTHOR_NAME", "Ada")
+
+	// A git alias running a shell command shows the environment git gets
+	out, _, err := Run(context.Background(), Cmd{
+		Dir:  t.TempDir(),
+		Args: []string{"-c", "alias.printenv=!env", "printenv"},
+		Env:  []string{"GIT_INDEX_FILE=/tmp/index"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := strings.Split(out, "\n")
+	for _, want := range []string{"GIT_TERMINAL_PROMPT=0", "LC_ALL=C", "GIT_AUTHOR_NAME=Ada", "GIT_INDEX_FILE=/tmp/index"} {
+		if !contains(env, want) {
+			t.Errorf("expected %s in the environment", want)
+		}
+	}
+	if strings.Contains(out, "HLD_TEST_SECRET") {
+		t.Error("the daemon's environment leaked into git")
+	}
+	if contains(env, "LC_ALL=de_DE.UTF-8") {
+		t.Error("the locale wasn't locked")
+	}
+}
+
+func contains(lines []string, line string) bool {
+	for _, l := range lines {
+		if l == line {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRunTimeout(t *testing.T) {
+	_, _, err := Run(context.Background(), Cmd{
+		Dir:     t.TempDir(),
+		Args:    []string{"-c", "alias.hang=!sleep 5", "hang"},
+		Timeout: 50 * time.Millisecond,
+	})
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("expected ErrTimeout, got %v", err)
+	}
+
+	var stats *Stats
+	for _, s := range Metrics() {
+		if s.Subcommand == "hang" {
+			stats = &s
+		}
+	}
+	if stats == nil || stats.Count != 1 || stats.Errors != 1 || stats.Timeouts != 1 {
+		t.Errorf("unexpected metrics for the timed out command: %+v", stats)
+	}
+}
+
+func TestRunErrorAndMetrics(t *testing.T) {
+	dir := t.TempDir()
+	if _, _, err := Run(context.Background(), Cmd{Dir: dir, Args: []string{"init", "-q"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout strings.Builder
+	if _, _, err := Run(context.Background(), Cmd{Dir: dir, Args: []string{"status", "--porcelain"}, Stdout: &stdout}); err != nil {
+		t.Fatal(err)
+	}
+	if stdout.String() != "?? a.txt\n" {
+		t.Errorf("unexpected streamed output %q", stdout.String())
+	}
+
+	_, _, err := Run(context.Background(), Cmd{Dir: dir, Args: []string{"-C", dir, "rev-parse", "--verify", "nope"}})
+	var gitErr *Error
+	if !errors.As(err, &gitErr) || gitErr.Subcommand != "rev-parse" || gitErr.Stderr == "" {
+		t.Fatalf("expected a rev-parse error with stderr, got %v", err)
+	}
+
+	for _, s := range Metrics() {
+		if s.Subcommand == "rev-parse" && s.Errors == 0 {
+			t.Errorf("failed rev-parse wasn't counted: %+v", s)
+		}
+		if s.Subcommand == "status" && s.Count == 0 {
+			t.Errorf("status wasn't counted: %+v", s)
+		}
+	}
+}
+
+func TestSubcommand(t *testing.T) {
+	for args, want := range map[string]string{
+		"status --porcelain":         "status",
+		"-c core.x=y -C /tmp commit": "commit",
+		"--no-pager log -1":          "log",
+		"--version":                  "",
+	} {
+		if got := Subcommand(strings.Fields(args)); got != want {
+			t.Errorf("Subcommand(%q) = %q, want %q", args, got, want)
+		}
+	}
+}
@@ -0,0 +1,440 @@
+// Package github launches sessions from GitHub webhooks: an issue labelled
+// for a fix, or a pull request comment asking for a review. Each session runs
+// in a worktree of a configured local clone, and its outcome is posted back
+// as a comment.
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	claudecode "github.com/humanlayer/humanlayer/claudecode-go"
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/gitcmd"
+	"github.com/humanlayer/humanlayer/hld/prompts"
+	"github.com/humanlayer/humanlayer/hld/session"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+const (
+	// maxCommentResult keeps comments under GitHub's 65536 character limit
+	maxCommentResult = 60000
+	requestTimeout   = 30 * time.Second
+)
+
+// ErrUnknownRepository is returned for events from repositories without a local clone configured
+var ErrUnknownRepository = errors.New("repository is not configured")
+
+// errNoToken means comments can't be posted
+var errNoToken = errors.New("no GitHub token configured")
+
+// WorktreeDir is where webhook session worktrees are kept, next to the database
+func WorktreeDir(databasePath string) string {
+	return filepath.Join(filepath.Dir(databasePath), "github")
+}
+
+// Client posts to the GitHub REST API
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// NewClient creates a client using the token in cfg's token environment variable
+func NewClient(cfg config.GitHubConfig) *Client {
+	baseURL := cfg.APIBaseURL
+	if baseURL == "" {
+		baseURL = config.DefaultGitHubAPIBaseURL
+	}
+	tokenEnv := cfg.TokenEnv
+	if tokenEnv == "" {
+		tokenEnv = config.DefaultGitHubTokenEnv
+	}
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   os.Getenv(tokenEnv),
+		http:    &http.Client{Timeout: requestTimeout},
+	}
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("github %s %s: %s", method, path, resp.Status)
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
+
+// Comment posts a comment on an issue or pull request
+func (c *Client) Comment(ctx context.Context, repository string, number int, body string) error {
+	if c.token == "" {
+		return errNoToken
+	}
+	return c.do(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/issues/%d/comments", repository, number), map[string]string{"body": body}, nil)
+}
+
+// PullRequestBase returns the branch a pull request merges into
+func (c *Client) PullRequestBase(ctx context.Context, repository string, number int) (string, error) {
+	var pr struct {
+		Base struct {
+			Ref string `json:"ref"`
+		} `json:"base"`
+	}
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/pulls/%d", repository, number), nil, &pr); err != nil {
+		return "", err
+	}
+	return pr.Base.Ref, nil
+}
+
+// Receiver turns verified webhook deliveries into sessions
+type Receiver struct {
+	store     store.GitHubTriggerStore
+	sessions  session.SessionManager
+	templates *prompts.Set
+	client    *Client
+	cfg       config.GitHubConfig
+	dir       string // worktrees are created under dir/<owner>_<name>
+
+	deliveries sync.Map // delivery ID -> struct{}, so redeliveries launch once
+}
+
+// NewReceiver creates a receiver keeping worktrees under dir
+func NewReceiver(s store.GitHubTriggerStore, sessions session.SessionManager, templates *prompts.Set, cfg config.GitHubConfig, dir string) *Receiver {
+	if cfg.FixLabel == "" {
+		cfg.FixLabel = config.DefaultGitHubFixLabel
+	}
+	if cfg.ReviewCommand == "" {
+		cfg.ReviewCommand = config.DefaultGitHubReviewCommand
+	}
+	return &Receiver{store: s, sessions: sessions, templates: templates, client: NewClient(cfg), cfg: cfg, dir: dir}
+}
+
+// Enabled reports whether a webhook secret is configured
+func (r *Receiver) Enabled() bool {
+	return r.cfg.WebhookSecret != ""
+}
+
+// Verify checks a delivery's X-Hub-Signature-256 header
+func (r *Receiver) Verify(signature string, body []byte) bool {
+	return VerifySignature(r.cfg.WebhookSecret, signature, body)
+}
+
+// Parse returns the delivery's trigger, or nil with the reason it was ignored
+func (r *Receiver) Parse(eventType string, body []byte) (*Trigger, string, error) {
+	trigger, reason, err := Parse(eventType, body, r.cfg.FixLabel, r.cfg.ReviewCommand)
+	if err != nil || trigger == nil {
+		return nil, reason, err
+	}
+	if _, ok := r.repository(trigger.Repository); !ok {
+		return nil, "repository is not configured", nil
+	}
+	return trigger, "", nil
+}
+
+// Claim returns false if the delivery was already received
+func (r *Receiver) Claim(deliveryID string) bool {
+	_, dup := r.deliveries.LoadOrStore(deliveryID, struct{}{})
+	return !dup
+}
+
+// repository looks up a clone; viper lowercases map keys, and GitHub names
+// are case-insensitive
+func (r *Receiver) repository(name string) (config.GitHubRepository, bool) {
+	repo, ok := r.cfg.Repositories[strings.ToLower(name)]
+	return repo, ok
+}
+
+// Launch checks out the trigger's branch in a worktree, starts its session
+// and comments on the issue or pull request. A failure to start is also
+// reported as a comment.
+func (r *Receiver) Launch(ctx context.Context, deliveryID string, trigger *Trigger) (*session.Session, error) {
+	sess, worktree, err := r.launch(ctx, deliveryID, trigger)
+	if err != nil {
+		r.comment(ctx, trigger, fmt.Sprintf("Couldn't start a HumanLayer session: %s", err))
+		return nil, err
+	}
+
+	var message string
+	if trigger.Kind == store.GitHubTriggerFix {
+		message = fmt.Sprintf("Started HumanLayer session `%s` to fix this issue on branch `%s`.", sess.ID, fixBranch(trigger.Number))
+	} else {
+		message = fmt.Sprintf("Started HumanLayer session `%s` to review this pull request.", sess.ID)
+	}
+	r.comment(ctx, trigger, message)
+	slog.Info("launched session from github webhook", "session_id", sess.ID, "repository", trigger.Repository,
+		"number", trigger.Number, "kind", trigger.Kind, "worktree", worktree, "delivery_id", deliveryID)
+	return sess, nil
+}
+
+func (r *Receiver) launch(ctx context.Context, deliveryID string, trigger *Trigger) (*session.Session, string, error) {
+	repo, ok := r.repository(trigger.Repository)
+	if !ok {
+		return nil, "", ErrUnknownRepository
+	}
+
+	var query, worktree, template string
+	var err error
+	switch trigger.Kind {
+	case store.GitHubTriggerFix:
+		worktree, err = r.checkoutFix(ctx, repo.WorkingDir, trigger)
+		if err != nil {
+			return nil, "", err
+		}
+		query, err = r.templates.Render(prompts.GitHubFix, map[string]any{
+			"Repository": trigger.Repository, "Number": trigger.Number, "Title": trigger.Title,
+			"Body": trigger.Body, "URL": trigger.URL, "Branch": fixBranch(trigger.Number),
+		})
+		template = "github-fix"
+	case store.GitHubTriggerReview:
+		base, baseErr := r.client.PullRequestBase(ctx, trigger.Repository, trigger.Number)
+		if baseErr != nil {
+			slog.Warn("failed to look up pull request base, assuming the default branch", "repository", trigger.Repository, "number", trigger.Number, "error", baseErr)
+			base = trigger.DefaultBranch
+		}
+		worktree, err = r.checkoutReview(ctx, repo.WorkingDir, trigger)
+		if err != nil {
+			return nil, "", err
+		}
+		query, err = r.templates.Render(prompts.GitHubReview, map[string]any{
+			"Repository": trigger.Repository, "Number": trigger.Number, "Title": trigger.Title,
+			"Body": trigger.Body, "URL": trigger.URL, "BaseBranch": base, "Instructions": trigger.Instructions,
+		})
+		template = "github-review"
+	default:
+		return nil, "", fmt.Errorf("unknown trigger kind %q", trigger.Kind)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if repo.Template != "" {
+		template = repo.Template
+	}
+
+	sess, err := r.sessions.LaunchSession(ctx, session.LaunchSessionConfig{
+		SessionConfig: claudecode.SessionConfig{
+			Query:        query,
+			WorkingDir:   worktree,
+			OutputFormat: claudecode.OutputStreamJSON,
+		},
+		Title:    fmt.Sprintf("%s#%d: %s", trigger.Repository, trigger.Number, trigger.Title),
+		Template: template,
+	}, false)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to launch session: %w", err)
+	}
+	if err := r.store.CreateGitHubTrigger(ctx, &store.GitHubTrigger{
+		DeliveryID: deliveryID,
+		SessionID:  sess.ID,
+		Repository: trigger.Repository,
+		Number:     trigger.Number,
+		Kind:       trigger.Kind,
+		Worktree:   worktree,
+	}); err != nil {
+		// The session still runs; only its outcome won't be reported
+		slog.Error("failed to record github trigger", "session_id", sess.ID, "error", err)
+	}
+	return sess, worktree, nil
+}
+
+func fixBranch(number int) string {
+	return fmt.Sprintf("humanlayer/issue-%d", number)
+}
+
+func (r *Receiver) worktreePath(trigger *Trigger, name string) string {
+	return filepath.Join(r.dir, strings.ReplaceAll(strings.ToLower(trigger.Repository), "/", "_"), name)
+}
+
+// checkoutFix creates a worktree on the issue's branch, starting from the
+// default branch. An existing worktree is reused so earlier work is kept.
+func (r *Receiver) checkoutFix(ctx context.Context, clone string, trigger *Trigger) (string, error) {
+	worktree := r.worktreePath(trigger, fmt.Sprintf("issue-%d", trigger.Number))
+	if _, err := os.Stat(worktree); err == nil {
+		return worktree, nil
+	}
+	commit, err := fetch(ctx, clone, trigger.DefaultBranch)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(worktree), 0755); err != nil {
+		return "", fmt.Errorf("failed to create worktree directory: %w", err)
+	}
+	branch := fixBranch(trigger.Number)
+	args := []string{"worktree", "add", "-b", branch, worktree, commit}
+	if _, err := git(ctx, clone, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch); err == nil {
+		args = []string{"worktree", "add", worktree, branch}
+	}
+	if _, err := git(ctx, clone, args...); err != nil {
+		return "", fmt.Errorf("failed to create worktree: %w", err)
+	}
+	return worktree, nil
+}
+
+// checkoutReview puts a detached worktree at the pull request's current head
+func (r *Receiver) checkoutReview(ctx context.Context, clone string, trigger *Trigger) (string, error) {
+	commit, err := fetch(ctx, clone, fmt.Sprintf("pull/%d/head", trigger.Number))
+	if err != nil {
+		return "", err
+	}
+	worktree := r.worktreePath(trigger, fmt.Sprintf("pr-%d", trigger.Number))
+	if _, err := os.Stat(worktree); err == nil {
+		if _, err := git(ctx, worktree, "checkout", "--detach", "--force", commit); err != nil {
+			return "", fmt.Errorf("failed to update worktree: %w", err)
+		}
+		return worktree, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(worktree), 0755); err != nil {
+		return "", fmt.Errorf("failed to create worktree directory: %w", err)
+	}
+	if _, err := git(ctx, clone, "worktree", "add", "--detach", worktree, commit); err != nil {
+		return "", fmt.Errorf("failed to create worktree: %w", err)
+	}
+	return worktree, nil
+}
+
+// fetch fetches ref from origin and returns its commit
+func fetch(ctx context.Context, clone, ref string) (string, error) {
+	if _, err := git(ctx, clone, "fetch", "--quiet", "origin", ref); err != nil {
+		return "", fmt.Errorf("failed to fetch %s: %w", ref, err)
+	}
+	out, err := git(ctx, clone, "rev-parse", "FETCH_HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	out, _, err := gitcmd.Run(ctx, gitcmd.Cmd{Dir: dir, Args: args})
+	return out, err
+}
+
+func (r *Receiver) comment(ctx context.Context, trigger *Trigger, body string) {
+	if err := r.client.Comment(ctx, trigger.Repository, trigger.Number, body); err != nil {
+		slog.Warn("failed to comment on github", "repository", trigger.Repository, "number", trigger.Number, "error", err)
+	}
+}
+
+// Reporter comments on the issue or pull request when a webhook-launched
+// session finishes
+type Reporter struct {
+	store    store.ConversationStore
+	triggers store.GitHubTriggerStore
+	client   *Client
+	eventBus bus.EventBus
+}
+
+// NewReporter creates a reporter posting with cfg's token
+func NewReporter(s store.ConversationStore, triggers store.GitHubTriggerStore, cfg config.GitHubConfig, eventBus bus.EventBus) *Reporter {
+	return &Reporter{store: s, triggers: triggers, client: NewClient(cfg), eventBus: eventBus}
+}
+
+// Run reports sessions as they finish until ctx is cancelled
+func (r *Reporter) Run(ctx context.Context) {
+	sub := r.eventBus.Subscribe(ctx, bus.EventFilter{
+		Types: []bus.EventType{bus.EventSessionStatusChanged},
+	})
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-sub.Channel:
+			if !ok {
+				return
+			}
+			status, _ := event.Data["new_status"].(string)
+			sessionID, _ := event.Data["session_id"].(string)
+			switch status {
+			case store.SessionStatusCompleted, store.SessionStatusFailed, store.SessionStatusInterrupted:
+			default:
+				continue
+			}
+			go func() {
+				if err := r.Report(ctx, sessionID); err != nil {
+					slog.Warn("failed to report session to github", "session_id", sessionID, "error", err)
+				}
+			}()
+		}
+	}
+}
+
+// Report posts a finished session's outcome, once. Sessions not launched by
+// a webhook are ignored.
+func (r *Reporter) Report(ctx context.Context, sessionID string) error {
+	trigger, err := r.triggers.GetGitHubTriggerBySession(ctx, sessionID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if trigger.ReportedAt != nil {
+		return nil
+	}
+	sess, err := r.store.GetSession(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	marked, err := r.triggers.MarkGitHubTriggerReported(ctx, sessionID)
+	if err != nil || !marked {
+		return err
+	}
+	return r.client.Comment(ctx, trigger.Repository, trigger.Number, outcome(sess))
+}
+
+// outcome describes a finished session for a comment
+func outcome(sess *store.Session) string {
+	switch sess.Status {
+	case store.SessionStatusCompleted:
+		result := strings.TrimSpace(sess.ResultContent)
+		if len(result) > maxCommentResult {
+			result = result[:maxCommentResult] + "\n\n[truncated]"
+		}
+		if result == "" {
+			return fmt.Sprintf("HumanLayer session `%s` completed.", sess.ID)
+		}
+		return fmt.Sprintf("HumanLayer session `%s` completed.\n\n%s", sess.ID, result)
+	case store.SessionStatusFailed:
+		return fmt.Sprintf("HumanLayer session `%s` failed: %s", sess.ID, sess.ErrorMessage)
+	default:
+		return fmt.Sprintf("HumanLayer session `%s` was %s.", sess.ID, sess.Status)
+	}
+}
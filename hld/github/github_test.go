@@ -0,0 +1,271 @@
+package github
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/owners"
+	"github.com/humanlayer/humanlayer/hld/prompts"
+	"github.com/humanlayer/humanlayer/hld/session"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"action":"labeled"}`)
+	assert.True(t, VerifySignature("s3cret", sign("s3cret", body), body))
+	assert.False(t, VerifySignature("s3cret", sign("other", body), body))
+	assert.False(t, VerifySignature("s3cret", sign("s3cret", body), []byte(`{}`)))
+	assert.False(t, VerifySignature("s3cret", "sha1=abc", body))
+	assert.False(t, VerifySignature("", sign("", body), body), "an empty secret never verifies")
+}
+
+func issueEvent(label string) []byte {
+	return []byte(fmt.Sprintf(`{"action":"labeled","label":{"name":%q},
+		"issue":{"number":12,"title":"Crash on empty config","body":"Steps...","html_url":"https://github.com/acme/app/issues/12","state":"open"},
+		"repository":{"full_name":"Acme/App","default_branch":"main"},"sender":{"login":"alice"}}`, label))
+}
+
+func commentEvent(body, association string) []byte {
+	return []byte(fmt.Sprintf(`{"action":"created","comment":{"body":%q,"author_association":%q},
+		"issue":{"number":7,"title":"Add retries","html_url":"https://github.com/acme/app/pull/7","state":"open","pull_request":{"url":"x"}},
+		"repository":{"full_name":"acme/app","default_branch":"main"},"sender":{"login":"bob"}}`, body, association))
+}
+
+func TestParse(t *testing.T) {
+	trigger, _, err := Parse("issues", issueEvent("humanlayer:fix"), "humanlayer:fix", "/hl review")
+	require.NoError(t, err)
+	require.NotNil(t, trigger)
+	assert.Equal(t, store.GitHubTriggerFix, trigger.Kind)
+	assert.Equal(t, 12, trigger.Number)
+	assert.Equal(t, "main", trigger.DefaultBranch)
+
+	trigger, _, err = Parse("issue_comment", commentEvent("/hl review focus on error handling\nthanks", "MEMBER"), "humanlayer:fix", "/hl review")
+	require.NoError(t, err)
+	require.NotNil(t, trigger)
+	assert.Equal(t, store.GitHubTriggerReview, trigger.Kind)
+	assert.Equal(t, "focus on error handling", trigger.Instructions)
+
+	for name, tc := range map[string]struct {
+		event string
+		body  []byte
+	}{
+		"other label":     {"issues", issueEvent("bug")},
+		"not the command": {"issue_comment", commentEvent("looks good", "MEMBER")},
+		"longer word":     {"issue_comment", commentEvent("/hl reviewed", "MEMBER")},
+		"outsider":        {"issue_comment", commentEvent("/hl review", "NONE")},
+		"other event":     {"push", []byte(`{"ref":"refs/heads/main"}`)},
+	} {
+		trigger, reason, err := Parse(tc.event, tc.body, "humanlayer:fix", "/hl review")
+		require.NoError(t, err, name)
+		assert.Nil(t, trigger, name)
+		assert.NotEmpty(t, reason, name)
+	}
+
+	_, _, err = Parse("issues", []byte(`not json`), "humanlayer:fix", "/hl review")
+	assert.Error(t, err)
+}
+
+// fakeSessions records launches as sessions in the store
+type fakeSessions struct {
+	session.SessionManager
+	store    *store.MemoryStore
+	launched []session.LaunchSessionConfig
+}
+
+func (f *fakeSessions) LaunchSession(ctx context.Context, config session.LaunchSessionConfig, isDraft bool) (*session.Session, error) {
+	f.launched = append(f.launched, config)
+	id := fmt.Sprintf("sess-%d", len(f.launched))
+	err := f.store.CreateSession(ctx, &store.Session{
+		ID: id, RunID: "run-" + id, Query: config.Query, WorkingDir: config.WorkingDir, Template: config.Template,
+		Status: store.SessionStatusRunning, CreatedAt: time.Now(),
+	})
+	return &session.Session{ID: id}, err
+}
+
+// fakeGitHub serves the comments and pull request APIs
+type fakeGitHub struct {
+	mu       sync.Mutex
+	comments []string
+}
+
+func (f *fakeGitHub) server(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/comments"):
+			var body struct{ Body string }
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			f.mu.Lock()
+			f.comments = append(f.comments, r.URL.Path+": "+body.Body)
+			f.mu.Unlock()
+			w.WriteHeader(http.StatusCreated)
+		case r.URL.Path == "/repos/acme/app/pulls/7":
+			_, _ = w.Write([]byte(`{"base":{"ref":"develop"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func gitCmd(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	require.NoError(t, err, string(out))
+	return strings.TrimSpace(string(out))
+}
+
+// initClone creates an origin with a main branch and a pull request head,
+// and a clone of it
+func initClone(t *testing.T) string {
+	t.Helper()
+	origin := t.TempDir()
+	gitCmd(t, origin, "init", "-q", "-b", "main")
+	gitCmd(t, origin, "config", "user.email", "test@example.com")
+	gitCmd(t, origin, "config", "user.name", "Test")
+	require.NoError(t, os.WriteFile(filepath.Join(origin, "README.md"), []byte("app\n"), 0644))
+	gitCmd(t, origin, "add", ".")
+	gitCmd(t, origin, "commit", "-q", "-m", "initial")
+	gitCmd(t, origin, "checkout", "-q", "-b", "feature")
+	require.NoError(t, os.WriteFile(filepath.Join(origin, "retry.go"), []byte("package app\n"), 0644))
+	gitCmd(t, origin, "add", ".")
+	gitCmd(t, origin, "commit", "-q", "-m", "add retries")
+	gitCmd(t, origin, "update-ref", "refs/pull/7/head", "HEAD")
+	gitCmd(t, origin, "checkout", "-q", "main")
+
+	clone := filepath.Join(t.TempDir(), "app")
+	out, err := exec.Command("git", "clone", "-q", origin, clone).CombinedOutput()
+	require.NoError(t, err, string(out))
+	return clone
+}
+
+func newReceiver(t *testing.T) (*Receiver, *fakeSessions, *fakeGitHub, *store.MemoryStore) {
+	t.Setenv("TEST_GITHUB_TOKEN", "test-token")
+	s := store.NewInMemoryStore()
+	sessions := &fakeSessions{store: s}
+	gh := &fakeGitHub{}
+	cfg := config.GitHubConfig{
+		WebhookSecret: "s3cret",
+		TokenEnv:      "TEST_GITHUB_TOKEN",
+		APIBaseURL:    gh.server(t).URL,
+		Repositories:  map[string]config.GitHubRepository{"acme/app": {WorkingDir: initClone(t)}},
+	}
+	return NewReceiver(s, sessions, prompts.Default(), cfg, t.TempDir()), sessions, gh, s
+}
+
+func TestLaunchFix(t *testing.T) {
+	ctx := context.Background()
+	r, sessions, gh, s := newReceiver(t)
+
+	trigger, _, err := r.Parse("issues", issueEvent("humanlayer:fix"))
+	require.NoError(t, err)
+	require.NotNil(t, trigger, "repository names match case-insensitively")
+	sess, err := r.Launch(ctx, "delivery-1", trigger)
+	require.NoError(t, err)
+
+	require.Len(t, sessions.launched, 1)
+	launched := sessions.launched[0]
+	assert.Equal(t, "github-fix", launched.Template)
+	assert.Contains(t, launched.Query, "Fix GitHub issue #12 in Acme/App: Crash on empty config")
+	assert.Equal(t, "humanlayer/issue-12", gitCmd(t, launched.WorkingDir, "rev-parse", "--abbrev-ref", "HEAD"))
+
+	require.Len(t, gh.comments, 1)
+	assert.Contains(t, gh.comments[0], "/repos/Acme/App/issues/12/comments: Started HumanLayer session `sess-1`")
+
+	trigger2, err := s.GetGitHubTriggerBySession(ctx, sess.ID)
+	require.NoError(t, err)
+	assert.Equal(t, launched.WorkingDir, trigger2.Worktree)
+
+	// A second label reuses the worktree and its branch
+	_, err = r.Launch(ctx, "delivery-2", trigger)
+	require.NoError(t, err)
+	assert.Equal(t, launched.WorkingDir, sessions.launched[1].WorkingDir)
+}
+
+func TestLaunchReviewAndReport(t *testing.T) {
+	ctx := context.Background()
+	r, sessions, gh, s := newReceiver(t)
+
+	trigger, _, err := r.Parse("issue_comment", commentEvent("/hl review check the tests", "OWNER"))
+	require.NoError(t, err)
+	require.NotNil(t, trigger)
+	sess, err := r.Launch(ctx, "delivery-1", trigger)
+	require.NoError(t, err)
+
+	launched := sessions.launched[0]
+	assert.Equal(t, "github-review", launched.Template)
+	assert.Contains(t, launched.Query, "origin/develop")
+	assert.Contains(t, launched.Query, "The reviewer asked: check the tests")
+	assert.FileExists(t, filepath.Join(launched.WorkingDir, "retry.go"), "the pull request head is checked out")
+
+	status, result := store.SessionStatusCompleted, "Two findings."
+	require.NoError(t, s.UpdateSession(ctx, sess.ID, store.SessionUpdate{Status: &status, ResultContent: &result}))
+	reporter := &Reporter{store: s, triggers: s, client: r.client}
+	require.NoError(t, reporter.Report(ctx, sess.ID))
+	require.NoError(t, reporter.Report(ctx, sess.ID), "reporting twice is a no-op")
+	require.NoError(t, reporter.Report(ctx, "not-from-github"))
+
+	require.Len(t, gh.comments, 2)
+	assert.Equal(t, "/repos/acme/app/issues/7/comments: HumanLayer session `sess-1` completed.\n\nTwo findings.", gh.comments[1])
+}
+
+func TestLaunchUnknownRepository(t *testing.T) {
+	r, sessions, gh, _ := newReceiver(t)
+	trigger, reason, err := r.Parse("issues", []byte(`{"action":"labeled","label":{"name":"humanlayer:fix"},
+		"issue":{"number":1,"title":"x"},"repository":{"full_name":"acme/other"}}`))
+	require.NoError(t, err)
+	assert.Nil(t, trigger)
+	assert.Equal(t, "repository is not configured", reason)
+
+	assert.True(t, r.Claim("d1"))
+	assert.False(t, r.Claim("d1"))
+	assert.Empty(t, sessions.launched)
+	assert.Empty(t, gh.comments)
+}
+
+func TestReviewerRequests(t *testing.T) {
+	repository, number, ok := ParsePullRequestURL("https://github.com/acme/app/pull/42")
+	require.True(t, ok)
+	assert.Equal(t, "acme/app", repository)
+	assert.Equal(t, 42, number)
+	_, _, ok = ParsePullRequestURL("https://github.com/acme/app/issues/42")
+	assert.False(t, ok)
+
+	reviewers := []owners.Reviewer{
+		{Owner: "@acme/payments", Reason: owners.ReasonCodeowners, Files: []string{"a.go", "b.go", "c.go", "d.go"}},
+		{Owner: "@Alice", Reason: owners.ReasonCodeowners, Files: []string{"a.go"}},
+		{Owner: "@bob", Reason: owners.ReasonCodeowners, Files: []string{"a.go"}},
+		{Owner: "docs@acme.com", Reason: owners.ReasonCodeowners, Files: []string{"README.md"}},
+		{Name: "Grace", Email: "grace@example.com", Reason: owners.ReasonBlame, Files: []string{"a.go"}, Lines: 3},
+	}
+	users, teams := requestable(reviewers, "alice")
+	assert.Equal(t, []string{"bob"}, users, "the author and email owners can't be requested")
+	assert.Equal(t, []string{"payments"}, teams)
+
+	comment := reviewerComment("sess-1", reviewers)
+	assert.Contains(t, comment, "- @acme/payments owns `a.go`, `b.go`, `c.go` and 1 more\n")
+	assert.Contains(t, comment, "- Grace last changed 3 of the modified lines in `a.go`\n")
+}
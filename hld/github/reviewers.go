@@ -0,0 +1,230 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/owners"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/humanlayer/humanlayer/hld/tracker"
+	"github.com/humanlayer/humanlayer/hld/workspace"
+)
+
+// maxPullRequestFiles is as many files as GitHub lists for a pull request
+const maxPullRequestFiles = 3000
+
+var pullRequestPath = regexp.MustCompile(`/([\w.-]+/[\w.-]+)/pull/(\d+)$`)
+
+// ParsePullRequestURL returns the repository (owner/name) and number of a
+// pull request URL
+func ParsePullRequestURL(url string) (string, int, bool) {
+	m := pullRequestPath.FindStringSubmatch(url)
+	if m == nil {
+		return "", 0, false
+	}
+	number, err := strconv.Atoi(m[2])
+	return m[1], number, err == nil
+}
+
+// pullRequest is the part of a pull request the review request needs
+type pullRequest struct {
+	User struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+func (c *Client) pullRequest(ctx context.Context, repository string, number int) (*pullRequest, error) {
+	var pr pullRequest
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/pulls/%d", repository, number), nil, &pr); err != nil {
+		return nil, err
+	}
+	return &pr, nil
+}
+
+// PullRequestFiles lists the paths a pull request changes
+func (c *Client) PullRequestFiles(ctx context.Context, repository string, number int) ([]string, error) {
+	var paths []string
+	for page := 1; len(paths) < maxPullRequestFiles; page++ {
+		var files []struct {
+			Filename string `json:"filename"`
+			Status   string `json:"status"`
+		}
+		path := fmt.Sprintf("/repos/%s/pulls/%d/files?per_page=100&page=%d", repository, number, page)
+		if err := c.do(ctx, http.MethodGet, path, nil, &files); err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if file.Status != "removed" {
+				paths = append(paths, file.Filename)
+			}
+		}
+		if len(files) < 100 {
+			break
+		}
+	}
+	return paths, nil
+}
+
+// RequestReviewers asks users (logins) and teams (slugs) to review a pull request
+func (c *Client) RequestReviewers(ctx context.Context, repository string, number int, users, teams []string) error {
+	if c.token == "" {
+		return errNoToken
+	}
+	body := map[string][]string{"reviewers": users, "team_reviewers": teams}
+	return c.do(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/pulls/%d/requested_reviewers", repository, number), body, nil)
+}
+
+// ReviewRequester asks the CODEOWNERS owners of the files in a pull request
+// a session opens to review it, and comments with the authors of the lines
+// it changes
+type ReviewRequester struct {
+	store    store.ConversationStore
+	client   *Client
+	eventBus bus.EventBus
+
+	requested sync.Map // pull request URL -> struct{}, so continued sessions don't ask twice
+}
+
+// NewReviewRequester creates a requester posting with cfg's token
+func NewReviewRequester(s store.ConversationStore, cfg config.GitHubConfig, eventBus bus.EventBus) *ReviewRequester {
+	return &ReviewRequester{store: s, client: NewClient(cfg), eventBus: eventBus}
+}
+
+// Run requests reviews as sessions complete until ctx is cancelled
+func (r *ReviewRequester) Run(ctx context.Context) {
+	sub := r.eventBus.Subscribe(ctx, bus.EventFilter{
+		Types: []bus.EventType{bus.EventSessionStatusChanged},
+	})
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-sub.Channel:
+			if !ok {
+				return
+			}
+			if status, _ := event.Data["new_status"].(string); status != store.SessionStatusCompleted {
+				continue
+			}
+			sessionID, _ := event.Data["session_id"].(string)
+			go func() {
+				if err := r.Request(ctx, sessionID); err != nil {
+					slog.Warn("failed to request pull request reviewers", "session_id", sessionID, "error", err)
+				}
+			}()
+		}
+	}
+}
+
+// Request asks for reviews of the pull request the session opened, if any
+func (r *ReviewRequester) Request(ctx context.Context, sessionID string) error {
+	sess, err := r.store.GetSession(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	prURL, err := tracker.PullRequestURL(ctx, r.store, sess)
+	if err != nil || prURL == "" {
+		return err
+	}
+	repository, number, ok := ParsePullRequestURL(prURL)
+	if !ok {
+		return nil
+	}
+	if _, done := r.requested.LoadOrStore(prURL, struct{}{}); done {
+		return nil
+	}
+
+	pr, err := r.client.pullRequest(ctx, repository, number)
+	if err != nil {
+		return err
+	}
+	files, err := r.client.PullRequestFiles(ctx, repository, number)
+	if err != nil {
+		return err
+	}
+	host := workspace.ForSession(sess)
+	root, err := owners.Root(ctx, host, sess.WorkingDir)
+	if err != nil {
+		return err
+	}
+	base := "origin/" + pr.Base.Ref
+	if mergeBase, err := owners.MergeBase(ctx, host, root, base); err == nil {
+		base = mergeBase
+	}
+	reviewers, err := owners.Suggest(ctx, host, root, base, files, nil)
+	if err != nil {
+		// CODEOWNERS suggestions are still worth requesting without blame
+		slog.Warn("failed to blame pull request changes", "pull_request", prURL, "error", err)
+	}
+	if len(reviewers) == 0 {
+		return nil
+	}
+
+	users, teams := requestable(reviewers, pr.User.Login)
+	if len(users) > 0 || len(teams) > 0 {
+		if err := r.client.RequestReviewers(ctx, repository, number, users, teams); err != nil {
+			return err
+		}
+		slog.Info("requested pull request reviewers", "pull_request", prURL, "users", users, "teams", teams)
+	}
+	return r.client.Comment(ctx, repository, number, reviewerComment(sess.ID, reviewers))
+}
+
+// requestable splits CODEOWNERS owners into user logins and team slugs,
+// leaving out the pull request's author, who can't review it
+func requestable(reviewers []owners.Reviewer, author string) (users, teams []string) {
+	for _, reviewer := range reviewers {
+		owner, ok := strings.CutPrefix(reviewer.Owner, "@")
+		if reviewer.Reason != owners.ReasonCodeowners || !ok {
+			continue
+		}
+		if _, team, isTeam := strings.Cut(owner, "/"); isTeam {
+			teams = append(teams, team)
+		} else if !strings.EqualFold(owner, author) {
+			users = append(users, owner)
+		}
+	}
+	return users, teams
+}
+
+// reviewerComment lists the suggested reviewers and why
+func reviewerComment(sessionID string, reviewers []owners.Reviewer) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Suggested reviewers for the changes from HumanLayer session `%s`:\n\n", sessionID)
+	for _, reviewer := range reviewers {
+		switch reviewer.Reason {
+		case owners.ReasonCodeowners:
+			fmt.Fprintf(&b, "- %s owns %s\n", reviewer.Owner, fileList(reviewer.Files))
+		case owners.ReasonBlame:
+			fmt.Fprintf(&b, "- %s last changed %d of the modified lines in %s\n", reviewer.Name, reviewer.Lines, fileList(reviewer.Files))
+		}
+	}
+	return b.String()
+}
+
+func fileList(files []string) string {
+	const shown = 3
+	quoted := make([]string, 0, shown)
+	for i, file := range files {
+		if i == shown {
+			break
+		}
+		quoted = append(quoted, "`"+file+"`")
+	}
+	list := strings.Join(quoted, ", ")
+	if len(files) > shown {
+		list += fmt.Sprintf(" and %d more", len(files)-shown)
+	}
+	return list
+}
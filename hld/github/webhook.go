@@ -0,0 +1,125 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// associations allowed to request a review by comment. Anyone can comment on
+// a public pull request, so outsiders must not be able to start sessions.
+var associations = map[string]bool{"OWNER": true, "MEMBER": true, "COLLABORATOR": true}
+
+// Trigger is a webhook event that should launch a session
+type Trigger struct {
+	Kind          string `json:"kind"` // store.GitHubTriggerFix or store.GitHubTriggerReview
+	Repository    string `json:"repository"`
+	Number        int    `json:"number"`
+	Title         string `json:"title"`
+	Body          string `json:"-"`
+	URL           string `json:"url"`
+	DefaultBranch string `json:"-"`
+	Instructions  string `json:"-"` // Text after the review command
+	Sender        string `json:"sender"`
+}
+
+type payload struct {
+	Action string `json:"action"`
+	Label  *struct {
+		Name string `json:"name"`
+	} `json:"label"`
+	Issue *struct {
+		Number      int    `json:"number"`
+		Title       string `json:"title"`
+		Body        string `json:"body"`
+		HTMLURL     string `json:"html_url"`
+		State       string `json:"state"`
+		PullRequest *struct {
+			URL string `json:"url"`
+		} `json:"pull_request"`
+	} `json:"issue"`
+	Comment *struct {
+		Body              string `json:"body"`
+		AuthorAssociation string `json:"author_association"`
+	} `json:"comment"`
+	Repository struct {
+		FullName      string `json:"full_name"`
+		DefaultBranch string `json:"default_branch"`
+	} `json:"repository"`
+	Sender struct {
+		Login string `json:"login"`
+	} `json:"sender"`
+}
+
+// VerifySignature checks an X-Hub-Signature-256 header against the body
+func VerifySignature(secret, header string, body []byte) bool {
+	signature, ok := strings.CutPrefix(header, "sha256=")
+	if !ok || secret == "" {
+		return false
+	}
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// Parse returns the trigger in a webhook delivery, or nil with the reason it
+// was ignored. eventType is the X-GitHub-Event header.
+func Parse(eventType string, body []byte, fixLabel, reviewCommand string) (*Trigger, string, error) {
+	var p payload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, "", fmt.Errorf("invalid payload: %w", err)
+	}
+	if p.Issue == nil {
+		return nil, "event has no issue", nil
+	}
+	trigger := &Trigger{
+		Repository:    p.Repository.FullName,
+		Number:        p.Issue.Number,
+		Title:         p.Issue.Title,
+		Body:          p.Issue.Body,
+		URL:           p.Issue.HTMLURL,
+		DefaultBranch: p.Repository.DefaultBranch,
+		Sender:        p.Sender.Login,
+	}
+
+	switch eventType {
+	case "issues":
+		if p.Action != "labeled" || p.Label == nil || p.Label.Name != fixLabel {
+			return nil, "not the fix label being added", nil
+		}
+		if p.Issue.PullRequest != nil {
+			return nil, "fix label on a pull request", nil
+		}
+		trigger.Kind = store.GitHubTriggerFix
+		return trigger, "", nil
+	case "issue_comment":
+		if p.Action != "created" || p.Comment == nil || p.Issue.PullRequest == nil {
+			return nil, "not a new pull request comment", nil
+		}
+		line, _, _ := strings.Cut(strings.TrimSpace(p.Comment.Body), "\n")
+		rest, ok := strings.CutPrefix(line, reviewCommand)
+		if !ok || (rest != "" && rest[0] != ' ') {
+			return nil, "comment is not the review command", nil
+		}
+		if !associations[p.Comment.AuthorAssociation] {
+			return nil, "commenter is not a collaborator", nil
+		}
+		if p.Issue.State == "closed" {
+			return nil, "pull request is closed", nil
+		}
+		trigger.Kind = store.GitHubTriggerReview
+		trigger.Instructions = strings.TrimSpace(rest)
+		return trigger, "", nil
+	default:
+		return nil, "unhandled event " + eventType, nil
+	}
+}
@@ -0,0 +1,249 @@
+// Package hooks launches sessions from generic inbound webhooks. Each hook
+// is configured with a secret, the working directory its sessions run in and
+// rules mapping payload fields to the query, so monitoring alerts, form
+// submissions and other automations can start sessions without a bespoke
+// integration.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"mime"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+
+	claudecode "github.com/humanlayer/humanlayer/claudecode-go"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/session"
+)
+
+// ErrUnknownHook is returned for names without a configured hook
+var ErrUnknownHook = errors.New("hook is not configured")
+
+// Trigger is a delivery that should launch a session
+type Trigger struct {
+	Hook  string `json:"hook"`
+	Query string `json:"-"`
+	Title string `json:"title"`
+}
+
+// Receiver turns verified deliveries into sessions
+type Receiver struct {
+	sessions session.SessionManager
+	hooks    map[string]config.HookConfig
+
+	deliveries sync.Map // hook name + idempotency key -> struct{}
+}
+
+// NewReceiver creates a receiver for the configured hooks
+func NewReceiver(sessions session.SessionManager, hooks map[string]config.HookConfig) *Receiver {
+	return &Receiver{sessions: sessions, hooks: hooks}
+}
+
+// Hook looks up a hook; viper lowercases map keys, so names are
+// case-insensitive
+func (r *Receiver) Hook(name string) (config.HookConfig, bool) {
+	hook, ok := r.hooks[strings.ToLower(name)]
+	return hook, ok
+}
+
+// Verify checks a delivery's credentials: the secret as a bearer token in
+// authorization, or an HMAC-SHA256 signature of the body as "sha256=<hex>"
+func Verify(secret, authorization, signature string, body []byte) bool {
+	if secret == "" {
+		return false
+	}
+	if token, ok := strings.CutPrefix(authorization, "Bearer "); ok {
+		return subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+	}
+	hexSum, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(hexSum)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// Decode parses a JSON or form-encoded payload. Form fields with one value
+// decode to strings, repeated fields to lists.
+func Decode(contentType string, body []byte) (map[string]any, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "application/x-www-form-urlencoded" {
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, fmt.Errorf("invalid form payload: %w", err)
+		}
+		payload := make(map[string]any, len(values))
+		for key, vals := range values {
+			if len(vals) == 1 {
+				payload[key] = vals[0]
+				continue
+			}
+			list := make([]any, len(vals))
+			for i, v := range vals {
+				list[i] = v
+			}
+			payload[key] = list
+		}
+		return payload, nil
+	}
+
+	var payload map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, fmt.Errorf("invalid payload: payload must be a JSON object: %w", err)
+	}
+	return payload, nil
+}
+
+// Field returns the value at a dotted path such as "alert.labels.service";
+// numeric segments index lists
+func Field(payload any, path string) (any, bool) {
+	value := payload
+	for _, segment := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]any:
+			next, ok := v[segment]
+			if !ok {
+				return nil, false
+			}
+			value = next
+		case []any:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			value = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// text formats a payload value for a query or comparison: strings as they
+// are, anything else as JSON
+func text(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
+
+// Map applies a hook's rules to a payload, returning the trigger or nil with
+// the reason the delivery was ignored. An error means the payload lacks what
+// the hook needs.
+func Map(name string, hook config.HookConfig, payload map[string]any) (*Trigger, string, error) {
+	for _, path := range slices.Sorted(maps.Keys(hook.Match)) {
+		want := hook.Match[path]
+		got, ok := Field(payload, path)
+		if !ok || text(got) != want {
+			return nil, fmt.Sprintf("%s is not %q", path, want), nil
+		}
+	}
+
+	var query string
+	switch {
+	case hook.Query != "":
+		var err error
+		if query, err = render(name+" query", hook.Query, payload); err != nil {
+			return nil, "", err
+		}
+	default:
+		value, ok := Field(payload, hook.QueryField)
+		if !ok {
+			return nil, "", fmt.Errorf("payload has no field %q", hook.QueryField)
+		}
+		query = text(value)
+	}
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, "", errors.New("query is empty")
+	}
+
+	title := name
+	if hook.Title != "" {
+		rendered, err := render(name+" title", hook.Title, payload)
+		if err != nil {
+			return nil, "", err
+		}
+		if rendered = strings.TrimSpace(rendered); rendered != "" {
+			title = rendered
+		}
+	}
+	return &Trigger{Hook: name, Query: query, Title: title}, "", nil
+}
+
+func render(name, source string, payload map[string]any) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s template: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, payload); err != nil {
+		return "", fmt.Errorf("failed to render %s template: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// Claim returns false if a delivery with the same idempotency key was
+// already received by the hook
+func (r *Receiver) Claim(name, key string) bool {
+	_, dup := r.deliveries.LoadOrStore(strings.ToLower(name)+"\x00"+key, struct{}{})
+	return !dup
+}
+
+// Launch starts the trigger's session in the hook's working directory
+func (r *Receiver) Launch(ctx context.Context, trigger *Trigger) (*session.Session, error) {
+	hook, ok := r.Hook(trigger.Hook)
+	if !ok {
+		return nil, ErrUnknownHook
+	}
+	label := hook.Template
+	if label == "" {
+		label = "hook-" + strings.ToLower(trigger.Hook)
+	}
+
+	sess, err := r.sessions.LaunchSession(ctx, session.LaunchSessionConfig{
+		SessionConfig: claudecode.SessionConfig{
+			Query:        trigger.Query,
+			WorkingDir:   hook.WorkingDir,
+			Model:        claudecode.Model(hook.Model),
+			OutputFormat: claudecode.OutputStreamJSON,
+		},
+		Title:    trigger.Title,
+		Template: label,
+	}, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to launch session: %w", err)
+	}
+	slog.Info("launched session from hook", "hook", trigger.Hook, "session_id", sess.ID)
+	return sess, nil
+}
@@ -0,0 +1,145 @@
+package hooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"alert":"disk full"}`)
+	assert.True(t, Verify("s3cret", "Bearer s3cret", "", body))
+	assert.False(t, Verify("s3cret", "Bearer wrong", sign("s3cret", body), body), "a wrong token isn't rescued by a signature")
+	assert.True(t, Verify("s3cret", "", sign("s3cret", body), body))
+	assert.False(t, Verify("s3cret", "", sign("s3cret", body), []byte(`{}`)))
+	assert.False(t, Verify("s3cret", "", "sha256=zz", body))
+	assert.False(t, Verify("", "Bearer ", "", body), "hooks without a secret accept nothing")
+}
+
+func TestDecode(t *testing.T) {
+	payload, err := Decode("application/json", []byte(`{"alert":{"count":3}}`))
+	require.NoError(t, err)
+	value, ok := Field(payload, "alert.count")
+	require.True(t, ok)
+	assert.Equal(t, "3", text(value), "numbers keep their JSON form")
+
+	payload, err = Decode("application/x-www-form-urlencoded; charset=utf-8", []byte("name=Ada&tag=a&tag=b"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"name": "Ada", "tag": []any{"a", "b"}}, payload)
+
+	_, err = Decode("application/json", []byte(`["not an object"]`))
+	assert.Error(t, err)
+}
+
+func TestField(t *testing.T) {
+	payload := map[string]any{"items": []any{map[string]any{"name": "first"}}}
+	value, ok := Field(payload, "items.0.name")
+	assert.True(t, ok)
+	assert.Equal(t, "first", value)
+
+	for _, path := range []string{"items.1.name", "items.x", "items.0.name.more", "missing"} {
+		_, ok := Field(payload, path)
+		assert.False(t, ok, path)
+	}
+}
+
+func TestMap(t *testing.T) {
+	payload := map[string]any{
+		"status": "firing",
+		"alert":  map[string]any{"name": "DiskFull", "description": "/var is 98% full", "labels": map[string]any{"host": "db-1"}},
+	}
+
+	t.Run("query field", func(t *testing.T) {
+		trigger, reason, err := Map("alerts", config.HookConfig{QueryField: "alert.description"}, payload)
+		require.NoError(t, err)
+		require.NotNil(t, trigger, reason)
+		assert.Equal(t, &Trigger{Hook: "alerts", Query: "/var is 98% full", Title: "alerts"}, trigger)
+	})
+
+	t.Run("query and title templates", func(t *testing.T) {
+		trigger, _, err := Map("alerts", config.HookConfig{
+			Query: "Investigate {{.alert.name}} on {{.alert.labels.host}}: {{.alert.description}}",
+			Title: "{{.alert.name}}",
+		}, payload)
+		require.NoError(t, err)
+		assert.Equal(t, "Investigate DiskFull on db-1: /var is 98% full", trigger.Query)
+		assert.Equal(t, "DiskFull", trigger.Title)
+	})
+
+	t.Run("match rules", func(t *testing.T) {
+		hook := config.HookConfig{QueryField: "alert.description", Match: map[string]string{"status": "resolved"}}
+		trigger, reason, err := Map("alerts", hook, payload)
+		require.NoError(t, err)
+		assert.Nil(t, trigger)
+		assert.Equal(t, `status is not "resolved"`, reason)
+	})
+
+	t.Run("missing fields", func(t *testing.T) {
+		_, _, err := Map("alerts", config.HookConfig{QueryField: "alert.runbook"}, payload)
+		assert.ErrorContains(t, err, `payload has no field "alert.runbook"`)
+
+		_, _, err = Map("alerts", config.HookConfig{Query: "{{.runbook}}"}, payload)
+		assert.ErrorContains(t, err, "failed to render alerts query template")
+
+		_, _, err = Map("alerts", config.HookConfig{QueryField: "alert.labels.missing"}, map[string]any{"alert": map[string]any{"labels": map[string]any{"missing": "  "}}})
+		assert.ErrorContains(t, err, "query is empty")
+	})
+}
+
+// fakeSessions records launches
+type fakeSessions struct {
+	session.SessionManager
+	launched []session.LaunchSessionConfig
+}
+
+func (f *fakeSessions) LaunchSession(ctx context.Context, cfg session.LaunchSessionConfig, isDraft bool) (*session.Session, error) {
+	f.launched = append(f.launched, cfg)
+	return &session.Session{ID: "sess-1", RunID: "run-1"}, nil
+}
+
+func TestReceiver(t *testing.T) {
+	sessions := &fakeSessions{}
+	r := NewReceiver(sessions, map[string]config.HookConfig{
+		"alerts": {Secret: "s3cret", WorkingDir: "/srv/app", QueryField: "q", Model: "opus"},
+		"forms":  {Secret: "s3cret", WorkingDir: "/srv/site", QueryField: "q", Template: "support"},
+	})
+
+	_, ok := r.Hook("Alerts")
+	assert.True(t, ok, "names are case-insensitive")
+	_, ok = r.Hook("other")
+	assert.False(t, ok)
+
+	assert.True(t, r.Claim("alerts", "key-1"))
+	assert.False(t, r.Claim("alerts", "key-1"))
+	assert.True(t, r.Claim("forms", "key-1"), "keys are per hook")
+
+	sess, err := r.Launch(context.Background(), &Trigger{Hook: "alerts", Query: "fix it", Title: "DiskFull"})
+	require.NoError(t, err)
+	assert.Equal(t, "sess-1", sess.ID)
+	_, err = r.Launch(context.Background(), &Trigger{Hook: "forms", Query: "reply"})
+	require.NoError(t, err)
+
+	require.Len(t, sessions.launched, 2)
+	assert.Equal(t, "fix it", sessions.launched[0].Query)
+	assert.Equal(t, "/srv/app", sessions.launched[0].WorkingDir)
+	assert.Equal(t, "opus", string(sessions.launched[0].Model))
+	assert.Equal(t, "DiskFull", sessions.launched[0].Title)
+	assert.Equal(t, "hook-alerts", sessions.launched[0].Template)
+	assert.Equal(t, "support", sessions.launched[1].Template)
+
+	_, err = r.Launch(context.Background(), &Trigger{Hook: "other"})
+	assert.ErrorIs(t, err, ErrUnknownHook)
+}
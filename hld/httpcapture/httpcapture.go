@@ -0,0 +1,327 @@
+// Package httpcapture keeps a sample of the daemon's HTTP requests and
+// responses in memory, bodies included, so a client and the daemon that
+// disagree about a payload can be compared. Credentials are redacted before
+// anything is stored.
+package httpcapture
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/config"
+)
+
+// Redacted replaces redacted values
+const Redacted = "[redacted]"
+
+// selfPath is the endpoint serving captures, which is never captured itself
+const selfPath = "/api/v1/debug/http-captures"
+
+// sensitive matches header names and JSON keys that hold credentials, once
+// lowercased with separators removed. Only endings count, so input_tokens
+// and token_env are kept.
+var sensitive = regexp.MustCompile(`(password|passwd|secret|token|apikey|authorization|cookie|credentials?|privatekey)$`)
+
+// Capture is one recorded request and its response
+type Capture struct {
+	ID         uint64    `json:"id"`
+	Time       time.Time `json:"time"`
+	RequestID  string    `json:"request_id,omitempty"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	Route      string    `json:"route,omitempty"`
+	Query      string    `json:"query,omitempty"`
+	Status     int       `json:"status"`
+	DurationMS int64     `json:"duration_ms"`
+
+	RequestHeaders map[string]string `json:"request_headers"`
+	RequestBody    string            `json:"request_body,omitempty"`
+	// RequestTruncated is set when the body was longer than max_body_size
+	RequestTruncated bool `json:"request_truncated,omitempty"`
+
+	ResponseHeaders   map[string]string `json:"response_headers"`
+	ResponseBody      string            `json:"response_body,omitempty"`
+	ResponseTruncated bool              `json:"response_truncated,omitempty"`
+}
+
+// Filter selects captures; zero fields match everything
+type Filter struct {
+	// Route matches the route pattern or the request path
+	Route     string
+	Method    string
+	Status    int
+	RequestID string
+	// Limit caps how many captures are returned
+	Limit int
+}
+
+// Recorder holds the most recent captures in a ring buffer
+type Recorder struct {
+	routes      []string
+	sampleRate  float64
+	maxBodySize int
+	redact      map[string]bool
+
+	mu       sync.Mutex
+	captures []Capture
+	next     int
+	full     bool
+	lastID   uint64
+
+	// sample returns a number in [0, 1); replaced in tests
+	sample func() float64
+}
+
+// New returns a recorder for cfg, or nil if capture isn't enabled
+func New(cfg config.HTTPCaptureConfig) *Recorder {
+	if !cfg.Enabled {
+		return nil
+	}
+	size := cfg.BufferSize
+	if size == 0 {
+		size = config.DefaultHTTPCaptureBufferSize
+	}
+	r := &Recorder{
+		routes:      cfg.Routes,
+		sampleRate:  cfg.SampleRate,
+		maxBodySize: cfg.MaxBodySize,
+		redact:      make(map[string]bool, len(cfg.RedactFields)),
+		captures:    make([]Capture, size),
+		sample:      rand.Float64,
+	}
+	if r.sampleRate == 0 {
+		r.sampleRate = 1
+	}
+	if r.maxBodySize == 0 {
+		r.maxBodySize = config.DefaultHTTPCaptureMaxBodySize
+	}
+	for _, field := range cfg.RedactFields {
+		r.redact[strings.ToLower(field)] = true
+	}
+	return r
+}
+
+// matches reports whether a request to route or requestPath is captured
+func (r *Recorder) matches(route, requestPath string) bool {
+	if strings.HasPrefix(requestPath, selfPath) {
+		return false
+	}
+	if len(r.routes) == 0 {
+		return true
+	}
+	for _, pattern := range r.routes {
+		if ok, _ := path.Match(pattern, route); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, requestPath); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Middleware records sampled requests to matching routes. It belongs after
+// any compression middleware, so it sees bodies as handlers write them.
+func (r *Recorder) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !r.matches(c.FullPath(), c.Request.URL.Path) || r.sample() >= r.sampleRate {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		request := &limitedBuffer{limit: r.maxBodySize}
+		if c.Request.Body != nil {
+			c.Request.Body = &teeReadCloser{ReadCloser: c.Request.Body, copy: request}
+		}
+		response := &limitedBuffer{limit: r.maxBodySize}
+		c.Writer = &captureWriter{ResponseWriter: c.Writer, copy: response}
+
+		c.Next()
+
+		capture := Capture{
+			Time:              start,
+			RequestID:         c.GetString("request-id"),
+			Method:            c.Request.Method,
+			Path:              c.Request.URL.Path,
+			Route:             c.FullPath(),
+			Query:             c.Request.URL.RawQuery,
+			Status:            c.Writer.Status(),
+			DurationMS:        time.Since(start).Milliseconds(),
+			RequestHeaders:    r.headers(c.Request.Header),
+			RequestBody:       r.body(request),
+			RequestTruncated:  request.truncated,
+			ResponseHeaders:   r.headers(c.Writer.Header()),
+			ResponseBody:      r.body(response),
+			ResponseTruncated: response.truncated,
+		}
+		r.add(capture)
+	}
+}
+
+func (r *Recorder) add(capture Capture) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastID++
+	capture.ID = r.lastID
+	r.captures[r.next] = capture
+	r.next = (r.next + 1) % len(r.captures)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// List returns the captures matching filter, newest first
+func (r *Recorder) List(filter Filter) []Capture {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := r.next
+	if r.full {
+		n = len(r.captures)
+	}
+	result := []Capture{}
+	for i := 0; i < n; i++ {
+		capture := r.captures[(r.next-1-i+len(r.captures))%len(r.captures)]
+		if filter.Route != "" && filter.Route != capture.Route && filter.Route != capture.Path {
+			continue
+		}
+		if filter.Method != "" && !strings.EqualFold(filter.Method, capture.Method) {
+			continue
+		}
+		if filter.Status != 0 && filter.Status != capture.Status {
+			continue
+		}
+		if filter.RequestID != "" && filter.RequestID != capture.RequestID {
+			continue
+		}
+		result = append(result, capture)
+		if filter.Limit > 0 && len(result) == filter.Limit {
+			break
+		}
+	}
+	return result
+}
+
+// Clear drops every capture
+func (r *Recorder) Clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	clear(r.captures)
+	r.next = 0
+	r.full = false
+}
+
+// isSensitive reports whether a header name or JSON key holds a credential
+func (r *Recorder) isSensitive(key string) bool {
+	key = strings.ToLower(key)
+	if r.redact[key] {
+		return true
+	}
+	return sensitive.MatchString(strings.NewReplacer("_", "", "-", "").Replace(key))
+}
+
+func (r *Recorder) headers(header http.Header) map[string]string {
+	headers := make(map[string]string, len(header))
+	for name, values := range header {
+		value := strings.Join(values, ", ")
+		if r.isSensitive(name) {
+			value = Redacted
+		}
+		headers[name] = value
+	}
+	return headers
+}
+
+// quotedField matches "key": "value" pairs, for bodies that aren't valid
+// JSON because they were truncated
+var quotedField = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"(\s*:\s*)"(?:[^"\\]|\\.)*"?`)
+
+// body renders a captured body with credentials redacted
+func (r *Recorder) body(b *limitedBuffer) string {
+	if b.Len() == 0 {
+		return ""
+	}
+	var value any
+	if !b.truncated && json.Unmarshal(b.Bytes(), &value) == nil {
+		if redacted, err := json.Marshal(r.redactJSON(value)); err == nil {
+			return string(redacted)
+		}
+	}
+	return quotedField.ReplaceAllStringFunc(b.String(), func(field string) string {
+		m := quotedField.FindStringSubmatch(field)
+		if !r.isSensitive(m[1]) {
+			return field
+		}
+		return `"` + m[1] + `"` + m[2] + `"` + Redacted + `"`
+	})
+}
+
+func (r *Recorder) redactJSON(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if r.isSensitive(key) {
+				v[key] = Redacted
+			} else {
+				v[key] = r.redactJSON(field)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = r.redactJSON(item)
+		}
+	}
+	return value
+}
+
+// limitedBuffer keeps the first limit bytes written to it
+type limitedBuffer struct {
+	bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) keep(p []byte) {
+	if room := b.limit - b.Len(); room < len(p) {
+		p = p[:max(room, 0)]
+		b.truncated = true
+	}
+	b.Write(p)
+}
+
+// teeReadCloser copies what the handler reads of the request body
+type teeReadCloser struct {
+	io.ReadCloser
+	copy *limitedBuffer
+}
+
+func (t *teeReadCloser) Read(p []byte) (int, error) {
+	n, err := t.ReadCloser.Read(p)
+	t.copy.keep(p[:n])
+	return n, err
+}
+
+// captureWriter copies the response body as it's written
+type captureWriter struct {
+	gin.ResponseWriter
+	copy *limitedBuffer
+}
+
+func (w *captureWriter) Write(data []byte) (int, error) {
+	w.copy.keep(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *captureWriter) WriteString(s string) (int, error) {
+	// Through Write, which wrapping writers such as gzip's override
+	return w.Write([]byte(s))
+}
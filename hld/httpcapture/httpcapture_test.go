@@ -0,0 +1,91 @@
+package httpcapture
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRouter(r *Recorder) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(r.Middleware())
+	router.POST("/api/v1/sessions/:id", func(c *gin.Context) {
+		var body map[string]any
+		_ = c.ShouldBindJSON(&body)
+		c.JSON(http.StatusCreated, gin.H{"github_token": "ghp_x", "input_tokens": 12, "echo": body})
+	})
+	router.GET("/api/v1/health", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	return router
+}
+
+func do(router *gin.Engine, method, path, body string) {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer abc")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func TestMiddlewareRedactsAndFilters(t *testing.T) {
+	r := New(config.HTTPCaptureConfig{
+		Enabled:      true,
+		Routes:       []string{"/api/v1/sessions/:id"},
+		RedactFields: []string{"query"},
+	})
+	router := newRouter(r)
+
+	do(router, "POST", "/api/v1/sessions/s1?x=1", `{"query":"private","nested":{"password":"hunter2","model":"opus"}}`)
+	do(router, "GET", "/api/v1/health", "")
+
+	captures := r.List(Filter{})
+	require.Len(t, captures, 1, "only matching routes are captured")
+	capture := captures[0]
+	assert.Equal(t, "/api/v1/sessions/:id", capture.Route)
+	assert.Equal(t, "/api/v1/sessions/s1", capture.Path)
+	assert.Equal(t, "x=1", capture.Query)
+	assert.Equal(t, http.StatusCreated, capture.Status)
+	assert.Equal(t, Redacted, capture.RequestHeaders["Authorization"])
+	assert.Equal(t, "application/json", capture.RequestHeaders["Content-Type"])
+	assert.JSONEq(t, `{"query":"[redacted]","nested":{"password":"[redacted]","model":"opus"}}`, capture.RequestBody)
+	assert.JSONEq(t, `{"github_token":"[redacted]","input_tokens":12,"echo":{"query":"[redacted]","nested":{"password":"[redacted]","model":"opus"}}}`, capture.ResponseBody)
+
+	assert.Len(t, r.List(Filter{Route: "/api/v1/sessions/s1", Method: "post", Status: 201}), 1)
+	assert.Empty(t, r.List(Filter{Status: 500}))
+}
+
+func TestRingBufferAndSampling(t *testing.T) {
+	r := New(config.HTTPCaptureConfig{Enabled: true, BufferSize: 2, SampleRate: 0.5, MaxBodySize: 16})
+	samples := []float64{0.1, 0.9, 0.2, 0.3}
+	r.sample = func() float64 {
+		s := samples[0]
+		samples = samples[1:]
+		return s
+	}
+	router := newRouter(r)
+	for _, id := range []string{"a", "b", "c", "d"} {
+		do(router, "POST", "/api/v1/sessions/"+id, `{"api_key":"k","padding":"xxxxxxxxxxxxxxxx"}`)
+	}
+
+	captures := r.List(Filter{})
+	require.Len(t, captures, 2)
+	assert.Equal(t, "/api/v1/sessions/d", captures[0].Path, "newest first")
+	assert.Equal(t, "/api/v1/sessions/c", captures[1].Path, "b wasn't sampled and a was evicted")
+	assert.True(t, captures[0].RequestTruncated)
+	assert.Equal(t, `{"api_key":"[redacted]","`, captures[0].RequestBody)
+	assert.Len(t, r.List(Filter{Limit: 1}), 1)
+
+	r.Clear()
+	assert.Empty(t, r.List(Filter{}))
+}
+
+func TestNewDisabled(t *testing.T) {
+	assert.Nil(t, New(config.HTTPCaptureConfig{}))
+}
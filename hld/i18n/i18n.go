@@ -0,0 +1,234 @@
+// Package i18n resolves locales and localizes the short user-visible strings
+// the daemon produces itself, such as notification titles. Model output is
+// localized separately by naming the language in prompts (see LanguageName).
+package i18n
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/language/display"
+)
+
+// Message keys
+const (
+	ApprovalRequestedTitle   = "approval_requested_title"
+	ApprovalRequestedMessage = "approval_requested_message"
+	ApprovalApprovedTitle    = "approval_approved_title"
+	ApprovalDeniedTitle      = "approval_denied_title"
+	ApprovalResolvedMessage  = "approval_resolved_message"
+	SessionStatusTitle       = "session_status_title"
+	SessionStatusMessage     = "session_status_message"
+	CostBudgetTitle          = "cost_budget_title"
+	CostBudgetMessage        = "cost_budget_message"
+	SessionSummaryTitle      = "session_summary_title"
+	SessionSummaryMessage    = "session_summary_message"
+	SessionSummaryWithheld   = "session_summary_withheld"
+	DigestTitle              = "digest_title"
+	DigestMessage            = "digest_message"
+	BatchTitle               = "batch_title"
+	ApprovalReminderTitle    = "approval_reminder_title"
+)
+
+// catalog holds fmt-style messages per supported language. English is the
+// fallback for missing languages and keys.
+var catalog = map[language.Tag]map[string]string{
+	language.English: {
+		ApprovalRequestedTitle:   "Approval needed",
+		ApprovalRequestedMessage: "%s is waiting for your approval",
+		ApprovalApprovedTitle:    "Approved",
+		ApprovalDeniedTitle:      "Denied",
+		ApprovalResolvedMessage:  "%s was %s",
+		SessionStatusTitle:       "Session %s",
+		SessionStatusMessage:     "Session %s is now %s",
+		CostBudgetTitle:          "Cost budget alert",
+		CostBudgetMessage:        "%s has reached %d%% of its limit ($%.2f of $%.2f)",
+		SessionSummaryTitle:      "Session summary",
+		SessionSummaryMessage:    "%s (%d changes, %d open questions)",
+		SessionSummaryWithheld:   "The summary of session %s was withheld by the content safety filter",
+		DigestTitle:              "Daily digest: %s",
+		DigestMessage:            "%d sessions (%d completed, %d failed), %d approvals (%d denied), $%.2f spent",
+		BatchTitle:               "%d notifications",
+		ApprovalReminderTitle:    "Approval reminder",
+	},
+	language.Spanish: {
+		ApprovalRequestedTitle:   "Aprobación necesaria",
+		ApprovalRequestedMessage: "%s está esperando tu aprobación",
+		ApprovalApprovedTitle:    "Aprobado",
+		ApprovalDeniedTitle:      "Denegado",
+		ApprovalResolvedMessage:  "%s fue %s",
+		SessionStatusTitle:       "Sesión %s",
+		SessionStatusMessage:     "La sesión %s ahora está %s",
+		CostBudgetTitle:          "Alerta de presupuesto",
+		CostBudgetMessage:        "%s ha alcanzado el %d%% de su límite ($%.2f de $%.2f)",
+		SessionSummaryTitle:      "Resumen de la sesión",
+		SessionSummaryMessage:    "%s (%d cambios, %d preguntas abiertas)",
+		SessionSummaryWithheld:   "El resumen de la sesión %s fue retenido por el filtro de seguridad de contenido",
+		DigestTitle:              "Resumen diario: %s",
+		DigestMessage:            "%d sesiones (%d completadas, %d fallidas), %d aprobaciones (%d denegadas), $%.2f gastados",
+		BatchTitle:               "%d notificaciones",
+		ApprovalReminderTitle:    "Recordatorio de aprobación",
+	},
+	language.French: {
+		ApprovalRequestedTitle:   "Approbation requise",
+		ApprovalRequestedMessage: "%s attend votre approbation",
+		ApprovalApprovedTitle:    "Approuvé",
+		ApprovalDeniedTitle:      "Refusé",
+		ApprovalResolvedMessage:  "%s a été %s",
+		SessionStatusTitle:       "Session %s",
+		SessionStatusMessage:     "La session %s est maintenant %s",
+		CostBudgetTitle:          "Alerte de budget",
+		CostBudgetMessage:        "%s a atteint %d %% de sa limite (%.2f $ sur %.2f $)",
+		SessionSummaryTitle:      "Résumé de la session",
+		SessionSummaryMessage:    "%s (%d modifications, %d questions ouvertes)",
+		SessionSummaryWithheld:   "Le résumé de la session %s a été retenu par le filtre de sécurité du contenu",
+		DigestTitle:              "Résumé quotidien : %s",
+		DigestMessage:            "%d sessions (%d terminées, %d en échec), %d approbations (%d refusées), %.2f $ dépensés",
+		BatchTitle:               "%d notifications",
+		ApprovalReminderTitle:    "Rappel d'approbation",
+	},
+	language.German: {
+		ApprovalRequestedTitle:   "Genehmigung erforderlich",
+		ApprovalRequestedMessage: "%s wartet auf deine Genehmigung",
+		ApprovalApprovedTitle:    "Genehmigt",
+		ApprovalDeniedTitle:      "Abgelehnt",
+		ApprovalResolvedMessage:  "%s wurde %s",
+		SessionStatusTitle:       "Sitzung %s",
+		SessionStatusMessage:     "Sitzung %s ist jetzt %s",
+		CostBudgetTitle:          "Budgetwarnung",
+		CostBudgetMessage:        "%s hat %d %% des Limits erreicht (%.2f $ von %.2f $)",
+		SessionSummaryTitle:      "Sitzungszusammenfassung",
+		SessionSummaryMessage:    "%s (%d Änderungen, %d offene Fragen)",
+		SessionSummaryWithheld:   "Die Zusammenfassung der Sitzung %s wurde vom Inhaltsfilter zurückgehalten",
+		DigestTitle:              "Tägliche Übersicht: %s",
+		DigestMessage:            "%d Sitzungen (%d abgeschlossen, %d fehlgeschlagen), %d Genehmigungen (%d abgelehnt), %.2f $ ausgegeben",
+		BatchTitle:               "%d Benachrichtigungen",
+		ApprovalReminderTitle:    "Erinnerung an Genehmigung",
+	},
+	language.Japanese: {
+		ApprovalRequestedTitle:   "承認が必要です",
+		ApprovalRequestedMessage: "%s が承認を待っています",
+		ApprovalApprovedTitle:    "承認済み",
+		ApprovalDeniedTitle:      "拒否",
+		ApprovalResolvedMessage:  "%s は%sされました",
+		SessionStatusTitle:       "セッション %s",
+		SessionStatusMessage:     "セッション %s は %s になりました",
+		CostBudgetTitle:          "予算アラート",
+		CostBudgetMessage:        "%s が上限の %d%% に達しました ($%.2f / $%.2f)",
+		SessionSummaryTitle:      "セッションの要約",
+		SessionSummaryMessage:    "%s (変更 %d 件、未解決の質問 %d 件)",
+		SessionSummaryWithheld:   "セッション %s の要約はコンテンツ安全フィルターにより保留されました",
+		DigestTitle:              "デイリーダイジェスト: %s",
+		DigestMessage:            "セッション %d 件 (完了 %d 件、失敗 %d 件)、承認 %d 件 (拒否 %d 件)、$%.2f 使用",
+		BatchTitle:               "通知 %d 件",
+		ApprovalReminderTitle:    "承認のリマインダー",
+	},
+}
+
+// words localizes the single words substituted into messages, such as
+// approval decisions and session statuses
+var words = map[language.Tag]map[string]string{
+	language.Spanish: {
+		"approved": "aprobado", "denied": "denegado",
+		"running": "en ejecución", "waiting_input": "esperando respuesta", "completed": "completada", "failed": "fallida", "interrupted": "interrumpida",
+	},
+	language.French: {
+		"approved": "approuvé", "denied": "refusé",
+		"running": "en cours", "waiting_input": "en attente", "completed": "terminée", "failed": "en échec", "interrupted": "interrompue",
+	},
+	language.German: {
+		"approved": "genehmigt", "denied": "abgelehnt",
+		"running": "aktiv", "waiting_input": "wartend", "completed": "abgeschlossen", "failed": "fehlgeschlagen", "interrupted": "unterbrochen",
+	},
+	language.Japanese: {
+		"approved": "承認", "denied": "拒否",
+		"running": "実行中", "waiting_input": "入力待ち", "completed": "完了", "failed": "失敗", "interrupted": "中断",
+	},
+}
+
+// supported lists the catalog's languages; the first is the fallback
+var supported = []language.Tag{
+	language.English,
+	language.Spanish,
+	language.French,
+	language.German,
+	language.Japanese,
+}
+
+var matcher = language.NewMatcher(supported)
+
+// Validate reports whether locale is a well-formed BCP 47 tag such as "fr" or "pt-BR"
+func Validate(locale string) error {
+	if locale == "" {
+		return nil
+	}
+	if _, err := language.Parse(locale); err != nil {
+		return fmt.Errorf("invalid locale %q: %w", locale, err)
+	}
+	return nil
+}
+
+// Resolve picks the first usable locale: the explicit one, then the first
+// entry of an Accept-Language header, then the fallback
+func Resolve(explicit, acceptLanguage, fallback string) string {
+	if explicit != "" && Validate(explicit) == nil {
+		return explicit
+	}
+	if acceptLanguage != "" {
+		if tags, _, err := language.ParseAcceptLanguage(acceptLanguage); err == nil && len(tags) > 0 && tags[0] != language.Und {
+			return tags[0].String()
+		}
+	}
+	return fallback
+}
+
+// LanguageName returns the English name of the locale's language for use in
+// prompts ("French", "Brazilian Portuguese"), or "" for English or no locale
+func LanguageName(locale string) string {
+	if locale == "" {
+		return ""
+	}
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return ""
+	}
+	if base, _ := tag.Base(); base.String() == "en" {
+		return ""
+	}
+	return display.English.Tags().Name(tag)
+}
+
+// T formats the message for key in the closest supported language
+func T(locale, key string, args ...any) string {
+	tag := match(locale)
+	format, ok := catalog[tag][key]
+	if !ok {
+		format = catalog[language.English][key]
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Word localizes a single substituted word, returning it unchanged when there
+// is no translation
+func Word(locale, word string) string {
+	if translated, ok := words[match(locale)][strings.ToLower(word)]; ok {
+		return translated
+	}
+	return word
+}
+
+func match(locale string) language.Tag {
+	if locale == "" {
+		return language.English
+	}
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return language.English
+	}
+	_, index, confidence := matcher.Match(tag)
+	if confidence == language.No {
+		return language.English
+	}
+	return supported[index]
+}
@@ -0,0 +1,30 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolve(t *testing.T) {
+	assert.Equal(t, "fr", Resolve("fr", "de-DE,de;q=0.9", "es"))
+	assert.Equal(t, "de-DE", Resolve("", "de-DE,de;q=0.9", "es"))
+	assert.Equal(t, "es", Resolve("not a locale!", "", "es"))
+	assert.Equal(t, "", Resolve("", "", ""))
+}
+
+func TestLanguageName(t *testing.T) {
+	assert.Equal(t, "", LanguageName(""))
+	assert.Equal(t, "", LanguageName("en-GB"))
+	assert.Equal(t, "French", LanguageName("fr"))
+	assert.Equal(t, "Brazilian Portuguese", LanguageName("pt-BR"))
+}
+
+func TestT(t *testing.T) {
+	assert.Equal(t, "Bash is waiting for your approval", T("", ApprovalRequestedMessage, "Bash"))
+	assert.Equal(t, "Bash attend votre approbation", T("fr-CA", ApprovalRequestedMessage, "Bash"))
+	assert.Equal(t, "Bash wurde abgelehnt", T("de", ApprovalResolvedMessage, "Bash", Word("de", "denied")))
+	// Unsupported languages fall back to English
+	assert.Equal(t, "Approval needed", T("ko", ApprovalRequestedTitle))
+	assert.Equal(t, "denied", Word("ko", "denied"))
+}
@@ -0,0 +1,269 @@
+// Package inbox presents pending approvals to each user as an email-like
+// inbox: items are read or unread per user, can be snoozed until a time, and
+// are counted by category. A snoozed approval still pending when its snooze
+// runs out comes back unread and notifies again.
+package inbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/scheduler"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// checkInterval bounds how late a reminder for an ended snooze is sent
+const checkInterval = 30 * time.Second
+
+// Categories of inbox item
+const (
+	// CategoryApproval is a tool call waiting to be approved or denied
+	CategoryApproval = "approval"
+	// CategoryInput is a question or plan the agent put to the user
+	CategoryInput = "input"
+)
+
+// States to filter items by
+const (
+	StateInbox   = "inbox" // everything not snoozed
+	StateUnread  = "unread"
+	StateRead    = "read"
+	StateSnoozed = "snoozed"
+	StateAll     = "all"
+)
+
+// inputTools ask the user something rather than act, so their approvals are
+// input requests
+var inputTools = []string{"AskUserQuestion", "ExitPlanMode"}
+
+var (
+	// ErrNotPending is returned for snoozing an approval already decided
+	ErrNotPending = errors.New("approval is not pending")
+	// ErrInvalidFilter is returned for an unknown category or state
+	ErrInvalidFilter = errors.New("invalid inbox filter")
+	// ErrInvalidSnooze is returned for a snooze that has already ended
+	ErrInvalidSnooze = errors.New("snooze must end in the future")
+)
+
+// Item is a pending approval as one user sees it
+type Item struct {
+	*store.Approval
+	Category     string     `json:"category"`
+	Read         bool       `json:"read"`
+	ReadAt       *time.Time `json:"read_at,omitempty"`
+	SnoozedUntil *time.Time `json:"snoozed_until,omitempty"`
+}
+
+// Counts tallies a category's items
+type Counts struct {
+	Total   int `json:"total"`
+	Unread  int `json:"unread"`
+	Snoozed int `json:"snoozed"`
+}
+
+// Filter selects inbox items; empty fields match everything, except State,
+// which defaults to StateInbox
+type Filter struct {
+	Category string
+	State    string
+}
+
+// Service keeps users' inboxes and sends reminders as snoozes end
+type Service struct {
+	store    store.ConversationStore
+	states   store.InboxStore
+	eventBus bus.EventBus
+	now      func() time.Time
+}
+
+// New creates an inbox service keeping users' read and snooze state in states
+func New(s store.ConversationStore, states store.InboxStore, eventBus bus.EventBus) *Service {
+	return &Service{store: s, states: states, eventBus: eventBus, now: time.Now}
+}
+
+// Category returns an approval's inbox category
+func Category(approval *store.Approval) string {
+	if slices.Contains(inputTools, approval.ToolName) {
+		return CategoryInput
+	}
+	return CategoryApproval
+}
+
+// items returns every pending approval as user sees it, oldest first
+func (s *Service) items(ctx context.Context, user string) ([]Item, error) {
+	approvals, err := s.store.ListPendingApprovals(ctx)
+	if err != nil {
+		return nil, err
+	}
+	states, err := s.states.ListInboxStates(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	byApproval := make(map[string]*store.InboxState, len(states))
+	for _, state := range states {
+		byApproval[state.ApprovalID] = state
+	}
+
+	now := s.now()
+	items := make([]Item, len(approvals))
+	for i, approval := range approvals {
+		items[i] = Item{Approval: approval, Category: Category(approval)}
+		if state := byApproval[approval.ID]; state != nil {
+			items[i].Read = state.ReadAt != nil
+			items[i].ReadAt = state.ReadAt
+			if state.SnoozedUntil != nil && state.SnoozedUntil.After(now) {
+				items[i].SnoozedUntil = state.SnoozedUntil
+			}
+		}
+	}
+	return items, nil
+}
+
+// List returns a user's inbox items matching filter, oldest first
+func (s *Service) List(ctx context.Context, user string, filter Filter) ([]Item, error) {
+	if filter.Category != "" && filter.Category != CategoryApproval && filter.Category != CategoryInput {
+		return nil, fmt.Errorf("%w: unknown category %q", ErrInvalidFilter, filter.Category)
+	}
+	if filter.State == "" {
+		filter.State = StateInbox
+	}
+	var matches func(Item) bool
+	switch filter.State {
+	case StateInbox:
+		matches = func(item Item) bool { return item.SnoozedUntil == nil }
+	case StateUnread:
+		matches = func(item Item) bool { return item.SnoozedUntil == nil && !item.Read }
+	case StateRead:
+		matches = func(item Item) bool { return item.SnoozedUntil == nil && item.Read }
+	case StateSnoozed:
+		matches = func(item Item) bool { return item.SnoozedUntil != nil }
+	case StateAll:
+		matches = func(Item) bool { return true }
+	default:
+		return nil, fmt.Errorf("%w: unknown state %q", ErrInvalidFilter, filter.State)
+	}
+
+	items, err := s.items(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	filtered := []Item{}
+	for _, item := range items {
+		if (filter.Category == "" || item.Category == filter.Category) && matches(item) {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered, nil
+}
+
+// Counts tallies a user's items by category. Snoozed items count only as
+// snoozed, not towards total or unread.
+func (s *Service) Counts(ctx context.Context, user string) (map[string]Counts, error) {
+	items, err := s.items(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	counts := map[string]Counts{CategoryApproval: {}, CategoryInput: {}}
+	for _, item := range items {
+		c := counts[item.Category]
+		switch {
+		case item.SnoozedUntil != nil:
+			c.Snoozed++
+		case item.Read:
+			c.Total++
+		default:
+			c.Total++
+			c.Unread++
+		}
+		counts[item.Category] = c
+	}
+	return counts, nil
+}
+
+// MarkRead marks approvals read or unread for a user
+func (s *Service) MarkRead(ctx context.Context, user string, approvalIDs []string, read bool) error {
+	return s.states.MarkInboxRead(ctx, user, approvalIDs, read)
+}
+
+// Snooze hides a pending approval from a user until a time, when they are
+// reminded of it if it's still pending
+func (s *Service) Snooze(ctx context.Context, user, approvalID string, until time.Time) error {
+	if !until.After(s.now()) {
+		return ErrInvalidSnooze
+	}
+	approval, err := s.store.GetApproval(ctx, approvalID)
+	if err != nil {
+		return err
+	}
+	if approval.Status != store.ApprovalStatusLocalPending {
+		return ErrNotPending
+	}
+	return s.states.SnoozeInboxItem(ctx, user, approvalID, &until)
+}
+
+// Unsnooze brings a snoozed approval back to a user's inbox
+func (s *Service) Unsnooze(ctx context.Context, user, approvalID string) error {
+	return s.states.SnoozeInboxItem(ctx, user, approvalID, nil)
+}
+
+// Task is the maintenance task sending reminders as snoozes end
+func (s *Service) Task() scheduler.Task {
+	return scheduler.Task{
+		Name:        "snooze-reminders",
+		Description: "Return approvals to users' inboxes as their snoozes end",
+		Interval:    checkInterval,
+		RunAtStart:  true,
+		Run:         s.Wake,
+	}
+}
+
+// Wake returns approvals whose snooze has ended to their users' inboxes as
+// unread, publishing a reminder for those still pending
+func (s *Service) Wake(ctx context.Context) error {
+	due, err := s.states.ListDueSnoozes(ctx, s.now())
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, state := range due {
+		if err := s.wake(ctx, state); err != nil {
+			errs = append(errs, fmt.Errorf("approval %s for %s: %w", state.ApprovalID, state.User, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Service) wake(ctx context.Context, state *store.InboxState) error {
+	if err := s.states.SnoozeInboxItem(ctx, state.User, state.ApprovalID, nil); err != nil {
+		return err
+	}
+	approval, err := s.store.GetApproval(ctx, state.ApprovalID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if approval.Status != store.ApprovalStatusLocalPending {
+		return nil
+	}
+	if err := s.states.MarkInboxRead(ctx, state.User, []string{approval.ID}, false); err != nil {
+		return err
+	}
+	if s.eventBus != nil {
+		s.eventBus.Publish(bus.Event{
+			Type: bus.EventApprovalReminder,
+			Data: map[string]interface{}{
+				"approval_id": approval.ID,
+				"session_id":  approval.SessionID,
+				"tool_name":   approval.ToolName,
+				"recipient":   state.User,
+			},
+		})
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package inbox
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newInbox(t *testing.T) (*Service, store.Store, bus.EventBus) {
+	t.Helper()
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	require.NoError(t, s.CreateSession(ctx, &store.Session{ID: "sess-1", RunID: "run-1"}))
+	created := time.Now().Add(-time.Hour)
+	for i, tool := range []string{"Bash", "Edit", "AskUserQuestion"} {
+		require.NoError(t, s.CreateApproval(ctx, &store.Approval{
+			ID: "appr-" + tool, RunID: "run-1", SessionID: "sess-1", Status: store.ApprovalStatusLocalPending,
+			ToolName: tool, ToolInput: json.RawMessage(`{}`), CreatedAt: created.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+	require.NoError(t, s.CreateApproval(ctx, &store.Approval{
+		ID: "appr-done", RunID: "run-1", SessionID: "sess-1", Status: store.ApprovalStatusLocalApproved,
+		ToolName: "Bash", ToolInput: json.RawMessage(`{}`), CreatedAt: created,
+	}))
+	eventBus := bus.NewEventBus()
+	return New(s, s, eventBus), s, eventBus
+}
+
+func ids(items []Item) []string {
+	out := make([]string, len(items))
+	for i, item := range items {
+		out[i] = item.ID
+	}
+	return out
+}
+
+func TestListAndCounts(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _ := newInbox(t)
+
+	require.NoError(t, svc.MarkRead(ctx, "alice", []string{"appr-Bash"}, true))
+	require.NoError(t, svc.Snooze(ctx, "alice", "appr-Edit", time.Now().Add(time.Hour)))
+
+	items, err := svc.List(ctx, "alice", Filter{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"appr-Bash", "appr-AskUserQuestion"}, ids(items))
+	assert.True(t, items[0].Read)
+	assert.Equal(t, CategoryInput, items[1].Category)
+
+	items, err = svc.List(ctx, "alice", Filter{State: StateUnread})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"appr-AskUserQuestion"}, ids(items))
+	items, err = svc.List(ctx, "alice", Filter{State: StateSnoozed})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"appr-Edit"}, ids(items))
+	items, err = svc.List(ctx, "alice", Filter{Category: CategoryApproval, State: StateAll})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"appr-Bash", "appr-Edit"}, ids(items))
+
+	// Read state is per user
+	items, err = svc.List(ctx, "bob", Filter{State: StateUnread})
+	require.NoError(t, err)
+	assert.Len(t, items, 3)
+
+	counts, err := svc.Counts(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, Counts{Total: 1, Unread: 0, Snoozed: 1}, counts[CategoryApproval])
+	assert.Equal(t, Counts{Total: 1, Unread: 1}, counts[CategoryInput])
+
+	_, err = svc.List(ctx, "alice", Filter{State: "starred"})
+	assert.ErrorIs(t, err, ErrInvalidFilter)
+}
+
+func TestSnoozeRefusesDecidedAndPast(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _ := newInbox(t)
+	assert.ErrorIs(t, svc.Snooze(ctx, "alice", "appr-done", time.Now().Add(time.Hour)), ErrNotPending)
+	assert.ErrorIs(t, svc.Snooze(ctx, "alice", "appr-Bash", time.Now().Add(-time.Minute)), ErrInvalidSnooze)
+	assert.ErrorIs(t, svc.Snooze(ctx, "alice", "missing", time.Now().Add(time.Hour)), store.ErrNotFound)
+}
+
+func TestWakeRemindsOfPendingApprovals(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	svc, s, eventBus := newInbox(t)
+	sub := eventBus.Subscribe(ctx, bus.EventFilter{Types: []bus.EventType{bus.EventApprovalReminder}})
+
+	require.NoError(t, svc.MarkRead(ctx, "alice", []string{"appr-Bash"}, true))
+	require.NoError(t, svc.Snooze(ctx, "alice", "appr-Bash", time.Now().Add(time.Hour)))
+	require.NoError(t, svc.Snooze(ctx, "alice", "appr-Edit", time.Now().Add(time.Hour)))
+	require.NoError(t, s.UpdateApprovalResponse(ctx, "appr-Edit", store.ApprovalStatusLocalApproved, ""))
+
+	svc.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	require.NoError(t, svc.Wake(ctx))
+
+	select {
+	case event := <-sub.Channel:
+		assert.Equal(t, "appr-Bash", event.Data["approval_id"])
+		assert.Equal(t, "alice", event.Data["recipient"])
+	case <-time.After(time.Second):
+		t.Fatal("no reminder published")
+	}
+	select {
+	case event := <-sub.Channel:
+		t.Fatalf("unexpected reminder for %v", event.Data["approval_id"])
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	items, err := svc.List(ctx, "alice", Filter{State: StateUnread})
+	require.NoError(t, err)
+	assert.Contains(t, ids(items), "appr-Bash", "a woken approval comes back unread")
+	due, err := s.ListDueSnoozes(ctx, time.Now().Add(3*time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, due)
+}
@@ -0,0 +1,176 @@
+// Package injection looks for prompt injections in text the daemon embeds in
+// its own prompts, such as conversation messages, commit subjects and issue
+// text. Lines that read like instructions to the model are stripped or
+// flagged, and each detection is recorded on the session.
+package injection
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// Removed replaces each stripped line
+const Removed = "[removed: possible prompt injection]"
+
+// maxExcerpt caps the line kept with a detection
+const maxExcerpt = 200
+
+// Rules a detection can name
+const (
+	RuleIgnoreInstructions = "ignore_instructions"
+	RuleRoleOverride       = "role_override"
+	RuleNewInstructions    = "new_instructions"
+	RuleChatMarkup         = "chat_markup"
+	RulePromptExfiltration = "prompt_exfiltration"
+	RuleHiddenCharacters   = "hidden_characters"
+	RuleCustom             = "custom"
+)
+
+type rule struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// rules match one line at a time. They aim at text addressed to a model,
+// so ordinary prose about instructions ("see the instructions above") isn't
+// caught.
+var rules = []rule{
+	{RuleIgnoreInstructions, regexp.MustCompile(`(?i)\b(?:ignore|disregard|forget|override)\s+(?:all\s+|any\s+|the\s+)?(?:of\s+)?(?:your\s+|the\s+)?(?:previous|prior|above|earlier|preceding|original|system)\s+(?:instructions|prompts?|rules|directions|guidelines|context)`)},
+	{RuleIgnoreInstructions, regexp.MustCompile(`(?i)\b(?:ignore|disregard|forget)\s+(?:everything|all)\s+(?:you\s+were\s+told|above|before\s+this)`)},
+	{RuleRoleOverride, regexp.MustCompile(`(?i)\byou\s+are\s+now\s+(?:a|an|the|in|my)\b`)},
+	{RuleRoleOverride, regexp.MustCompile(`(?i)\bfrom\s+now\s+on,?\s+you\s+(?:will|must|are|should)\b`)},
+	{RuleNewInstructions, regexp.MustCompile(`(?i)^\W*(?:new|updated|real|actual|important)\s+(?:system\s+)?instructions?\s*:`)},
+	{RuleChatMarkup, regexp.MustCompile(`(?i)<\|(?:im_start|im_end|system|user|assistant|endoftext)\|>|\[/?INST\]|<</?SYS>>|</?(?:system|system_prompt|instructions)>`)},
+	{RulePromptExfiltration, regexp.MustCompile(`(?i)\b(?:reveal|print|repeat|output|show\s+me)\s+(?:your|the)\s+(?:system\s+prompt|hidden\s+prompt|initial\s+instructions|instructions\s+above)`)},
+}
+
+// hidden matches characters that render as nothing but a model still reads:
+// zero-width characters, bidi controls and Unicode tag characters
+var hidden = regexp.MustCompile(`[\x{200B}-\x{200F}\x{202A}-\x{202E}\x{2060}-\x{2064}\x{2066}-\x{2069}\x{FEFF}\x{E0000}-\x{E007F}]`)
+
+// Finding is one suspicious line
+type Finding struct {
+	Rule    string `json:"rule"`
+	Excerpt string `json:"excerpt"`
+}
+
+// Scanner checks text against the built-in rules and any configured patterns
+type Scanner struct {
+	rules []rule
+}
+
+// NewScanner creates a scanner with extra patterns, which are matched
+// case-insensitively; patterns that don't compile are skipped, since config
+// validation reports them
+func NewScanner(patterns []string) *Scanner {
+	s := &Scanner{rules: rules}
+	if len(patterns) > 0 {
+		s.rules = append([]rule(nil), rules...)
+		for _, pattern := range patterns {
+			if re, err := regexp.Compile("(?i)" + pattern); err == nil {
+				s.rules = append(s.rules, rule{RuleCustom, re})
+			}
+		}
+	}
+	return s
+}
+
+// Scan returns text with hidden characters removed and suspicious lines
+// replaced by Removed, and what it found. A line is reported once, under
+// the first rule it matches.
+func (s *Scanner) Scan(text string) (string, []Finding) {
+	var findings []Finding
+	if hidden.MatchString(text) {
+		findings = append(findings, Finding{Rule: RuleHiddenCharacters, Excerpt: excerpt(hidden.ReplaceAllString(text, "·"))})
+		text = hidden.ReplaceAllString(text, "")
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		for _, r := range s.rules {
+			if r.pattern.MatchString(line) {
+				findings = append(findings, Finding{Rule: r.name, Excerpt: excerpt(line)})
+				lines[i] = Removed
+				break
+			}
+		}
+	}
+	return strings.Join(lines, "\n"), findings
+}
+
+func excerpt(line string) string {
+	line = strings.TrimSpace(line)
+	if len(line) > maxExcerpt {
+		line = strings.ToValidUTF8(line[:maxExcerpt], "") + "…"
+	}
+	return line
+}
+
+// Guard scans text for a prompt and records what it finds on the session.
+// A nil Guard passes text through unchanged.
+type Guard struct {
+	scanner    *Scanner
+	detections store.InjectionStore
+	strip      bool
+}
+
+// NewGuard creates a guard for cfg recording to detections, or nil when
+// scanning is off
+func NewGuard(cfg config.PromptInjectionConfig, detections store.InjectionStore) *Guard {
+	if cfg.Mode == config.PromptInjectionOff {
+		return nil
+	}
+	return &Guard{
+		scanner:    NewScanner(cfg.Patterns),
+		detections: detections,
+		strip:      cfg.Mode != config.PromptInjectionFlag,
+	}
+}
+
+// Check scans text from field of a session before it goes into prompt,
+// returning the text to embed: scrubbed when stripping, as it was when only
+// flagging
+func (g *Guard) Check(ctx context.Context, sessionID, prompt, field, text string) string {
+	if g == nil || text == "" {
+		return text
+	}
+	scrubbed, findings := g.scanner.Scan(text)
+	for _, finding := range findings {
+		slog.Warn("possible prompt injection in session text",
+			"session_id", sessionID, "prompt", prompt, "field", field, "rule", finding.Rule, "stripped", g.strip)
+		if g.detections == nil {
+			continue
+		}
+		err := g.detections.RecordInjectionDetection(ctx, &store.InjectionDetection{
+			SessionID: sessionID,
+			Prompt:    prompt,
+			Field:     field,
+			Rule:      finding.Rule,
+			Excerpt:   finding.Excerpt,
+			Stripped:  g.strip,
+		})
+		if err != nil {
+			slog.Warn("failed to record prompt injection", "session_id", sessionID, "error", err)
+		}
+	}
+	if !g.strip {
+		return text
+	}
+	return scrubbed
+}
+
+// CheckAll checks each of texts, returning a new slice
+func (g *Guard) CheckAll(ctx context.Context, sessionID, prompt, field string, texts []string) []string {
+	if g == nil || texts == nil {
+		return texts
+	}
+	checked := make([]string, len(texts))
+	for i, text := range texts {
+		checked[i] = g.Check(ctx, sessionID, prompt, field, text)
+	}
+	return checked
+}
@@ -0,0 +1,196 @@
+// Package instance keeps one daemon per store. A running daemon holds an
+// exclusive lock on a file beside its database that records its PID and
+// addresses, so a second daemon can say which one is in the way and
+// hld status can report it.
+package instance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/config"
+)
+
+// Info describes a running daemon
+type Info struct {
+	PID       int       `json:"pid"`
+	Socket    string    `json:"socket"`
+	API       string    `json:"api,omitempty"` // Base URL of the REST API, once it listens
+	Database  string    `json:"database"`
+	Version   string    `json:"version,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// HeldError is returned by Acquire when another daemon holds the lock
+type HeldError struct {
+	Path string
+	Info Info // Zero if the holder hasn't written it yet
+}
+
+func (e *HeldError) Error() string {
+	return "lock " + e.Path + " is held by " + e.Info.Describe()
+}
+
+// Describe names the daemon for error messages, e.g.
+// "pid 4242 at http://127.0.0.1:7777/api/v1 (socket ~/.humanlayer/daemon.sock)"
+func (i Info) Describe() string {
+	if i.PID == 0 {
+		return "another daemon"
+	}
+	s := fmt.Sprintf("pid %d", i.PID)
+	if i.API != "" {
+		s += " at " + i.API
+	}
+	if i.Socket != "" {
+		s += " (socket " + i.Socket + ")"
+	}
+	return s
+}
+
+// Path returns the lock file for cfg's store. In-memory stores aren't
+// shared, so the lock sits beside the socket instead.
+func Path(cfg *config.Config) string {
+	if cfg.DatabasePath == "" || strings.HasPrefix(cfg.DatabasePath, ":memory:") {
+		return cfg.SocketPath + ".lock"
+	}
+	return cfg.DatabasePath + ".lock"
+}
+
+// Lock is a held instance lock
+type Lock struct {
+	path string
+	file *os.File
+	info Info
+}
+
+// Acquire takes the lock at path and records info in it, returning a
+// *HeldError if a running daemon already has it
+func Acquire(path string, info Info) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		_ = f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			held := &HeldError{Path: path}
+			held.Info, _ = readInfo(path)
+			return nil, held
+		}
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+	l := &Lock{path: path, file: f, info: info}
+	if err := l.write(); err != nil {
+		l.Release()
+		return nil, err
+	}
+	return l, nil
+}
+
+// SetAPI records the REST API's base URL once the listener is bound
+func (l *Lock) SetAPI(url string) error {
+	l.info.API = url
+	return l.write()
+}
+
+// Release unlocks and removes the lock file
+func (l *Lock) Release() {
+	// Removing first keeps a waiting daemon from locking the old file
+	_ = os.Remove(l.path)
+	_ = syscall.Flock(int(l.file.Fd()), syscall.LOCK_UN)
+	_ = l.file.Close()
+}
+
+func (l *Lock) write() error {
+	data, err := json.MarshalIndent(l.info, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := l.file.Truncate(0); err != nil {
+		return fmt.Errorf("failed to write lock file: %w", err)
+	}
+	if _, err := l.file.WriteAt(append(data, '\n'), 0); err != nil {
+		return fmt.Errorf("failed to write lock file: %w", err)
+	}
+	return nil
+}
+
+// Read returns the daemon recorded at path and whether it still holds the
+// lock. A file left by a daemon that crashed reads as not held.
+func Read(path string) (Info, bool, error) {
+	info, err := readInfo(path)
+	if err != nil {
+		return Info{}, false, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return Info{}, false, err
+	}
+	defer func() { _ = f.Close() }()
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_SH|syscall.LOCK_NB); err != nil {
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return info, true, nil
+		}
+		return info, false, err
+	}
+	_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	return info, false, nil
+}
+
+func readInfo(path string) (Info, error) {
+	var info Info
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return info, err
+	}
+	if len(data) == 0 {
+		// Locked but not yet written
+		return info, nil
+	}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return info, fmt.Errorf("invalid lock file %s: %w", path, err)
+	}
+	return info, nil
+}
+
+// Takeover asks the daemon holding the lock at path to shut down and waits
+// until it lets go, or ctx ends. It returns nil if nothing holds the lock.
+func Takeover(ctx context.Context, path string) error {
+	info, held, err := Read(path)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && !held) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.PID == 0 || info.PID == os.Getpid() {
+		return fmt.Errorf("can't tell which process holds %s", path)
+	}
+	if err := syscall.Kill(info.PID, syscall.SIGTERM); err != nil {
+		return fmt.Errorf("failed to stop pid %d: %w", info.PID, err)
+	}
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		_, held, err := Read(path)
+		if errors.Is(err, os.ErrNotExist) || (err == nil && !held) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("pid %d did not shut down: %w", info.PID, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
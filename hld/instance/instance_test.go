@@ -0,0 +1,69 @@
+package instance
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockRefusesSecondDaemon(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daemon.db.lock")
+	first, err := Acquire(path, Info{PID: os.Getpid(), Socket: "/tmp/hld.sock", Database: "daemon.db", StartedAt: time.Now()})
+	require.NoError(t, err)
+	require.NoError(t, first.SetAPI("http://127.0.0.1:7777/api/v1"))
+
+	_, err = Acquire(path, Info{PID: 1})
+	var held *HeldError
+	require.True(t, errors.As(err, &held))
+	assert.Equal(t, os.Getpid(), held.Info.PID)
+	assert.Contains(t, err.Error(), "at http://127.0.0.1:7777/api/v1 (socket /tmp/hld.sock)")
+
+	info, isHeld, err := Read(path)
+	require.NoError(t, err)
+	assert.True(t, isHeld)
+	assert.Equal(t, "daemon.db", info.Database)
+
+	first.Release()
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	second, err := Acquire(path, Info{PID: os.Getpid()})
+	require.NoError(t, err, "the lock is free once released")
+	second.Release()
+}
+
+func TestCheckIgnoresStaleLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daemon.db.lock")
+
+	status, err := Check(context.Background(), path)
+	require.NoError(t, err)
+	assert.False(t, status.Running)
+
+	// A daemon that crashed leaves its file but not its lock
+	require.NoError(t, os.WriteFile(path, []byte(`{"pid": 99999, "socket": "/tmp/gone.sock"}`), 0600))
+	status, err = Check(context.Background(), path)
+	require.NoError(t, err)
+	assert.False(t, status.Running)
+	require.NoError(t, Takeover(context.Background(), path), "nothing to take over")
+
+	lock, err := Acquire(path, Info{PID: os.Getpid(), Socket: "/tmp/hld.sock"})
+	require.NoError(t, err)
+	defer lock.Release()
+	status, err = Check(context.Background(), path)
+	require.NoError(t, err)
+	assert.True(t, status.Running)
+	assert.Equal(t, "/tmp/hld.sock", status.Socket)
+	assert.Nil(t, status.Responding, "no API recorded to probe")
+}
+
+func TestPath(t *testing.T) {
+	assert.Equal(t, "/data/daemon.db.lock", Path(&config.Config{DatabasePath: "/data/daemon.db", SocketPath: "/run/hld.sock"}))
+	assert.Equal(t, "/run/hld.sock.lock", Path(&config.Config{DatabasePath: ":memory:", SocketPath: "/run/hld.sock"}))
+}
@@ -0,0 +1,130 @@
+package instance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/config"
+)
+
+// Exit codes for hld status, following the LSB convention for init scripts
+const (
+	ExitRunning    = 0
+	ExitError      = 1
+	ExitNotRunning = 3
+)
+
+// Status is what hld status reports
+type Status struct {
+	Running bool   `json:"running"`
+	Lock    string `json:"lock"`
+	Info
+	// Responding is whether the REST API answered a health check
+	Responding *bool `json:"responding,omitempty"`
+}
+
+const statusUsage = `usage: hld status [flags]
+
+Reports the daemon running against the configured database, if any.
+Exits 0 when one is running and 3 when none is.
+
+  -json   print the status as JSON
+`
+
+// Main runs hld status, returning the process exit code
+func Main(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("hld status", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { fmt.Fprint(stderr, statusUsage) }
+	asJSON := fs.Bool("json", false, "")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(stderr, "hld status: %v\n", err)
+		return ExitError
+	}
+	status, err := Check(ctx, Path(cfg))
+	if err != nil {
+		fmt.Fprintf(stderr, "hld status: %v\n", err)
+		return ExitError
+	}
+
+	code := ExitNotRunning
+	if status.Running {
+		code = ExitRunning
+	}
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(status)
+		return code
+	}
+	if !status.Running {
+		fmt.Fprintln(stdout, "No daemon running")
+		return code
+	}
+	fmt.Fprintf(stdout, "Daemon running (pid %d", status.PID)
+	if status.Version != "" {
+		fmt.Fprintf(stdout, ", version %s", status.Version)
+	}
+	fmt.Fprintf(stdout, ", up %s)\n", time.Since(status.StartedAt).Round(time.Second))
+	fmt.Fprintf(stdout, "  socket:   %s\n", status.Socket)
+	if status.API != "" {
+		state := "responding"
+		if status.Responding != nil && !*status.Responding {
+			state = "not responding"
+		}
+		fmt.Fprintf(stdout, "  api:      %s (%s)\n", status.API, state)
+	}
+	fmt.Fprintf(stdout, "  database: %s\n", status.Database)
+	fmt.Fprintf(stdout, "  lock:     %s\n", status.Lock)
+	return code
+}
+
+// Check reports the daemon holding the lock at path, probing its REST API
+func Check(ctx context.Context, path string) (*Status, error) {
+	status := &Status{Lock: path}
+	info, held, err := Read(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return status, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !held {
+		// Left behind by a daemon that didn't shut down cleanly
+		return status, nil
+	}
+	status.Running = true
+	status.Info = info
+	if info.API != "" {
+		responding := healthy(ctx, info.API)
+		status.Responding = &responding
+	}
+	return status, nil
+}
+
+func healthy(ctx context.Context, api string) bool {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, api+"/health", nil)
+	if err != nil {
+		return false
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
+}
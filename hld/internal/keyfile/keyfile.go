@@ -0,0 +1,47 @@
+// Package keyfile keeps the daemon's secret keys in files beside its
+// database, generating each on first use.
+package keyfile
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Load reads the size-byte key in path, creating it if there isn't one yet.
+// The file is only readable by the daemon's user.
+func Load(path string, size int) ([]byte, error) {
+	key, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		key = make([]byte, size)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("failed to generate key: %w", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return nil, fmt.Errorf("failed to create key directory: %w", err)
+		}
+		// O_EXCL so a key another process just wrote isn't replaced
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if errors.Is(err, fs.ErrExist) {
+			return Load(path, size)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to create key: %w", err)
+		}
+		defer func() { _ = file.Close() }()
+		if _, err := file.Write(key); err != nil {
+			return nil, fmt.Errorf("failed to write key: %w", err)
+		}
+		return key, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %w", err)
+	}
+	if len(key) != size {
+		return nil, fmt.Errorf("key %s must be %d bytes, got %d", path, size, len(key))
+	}
+	return key, nil
+}
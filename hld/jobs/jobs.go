@@ -0,0 +1,146 @@
+// Package jobs runs long operations, such as LLM calls, in the background so
+// HTTP requests can return a job ID immediately. Jobs are persisted in the
+// conversation store and publish a job_completed event when they finish.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// DefaultTimeout bounds how long a single job may run
+const DefaultTimeout = 10 * time.Minute
+
+// Func does a job's work. Its result is stored as JSON.
+type Func func(ctx context.Context) (any, error)
+
+// Manager submits jobs and records their outcome
+type Manager struct {
+	store    store.JobStore
+	eventBus bus.EventBus
+	timeout  time.Duration
+	wg       sync.WaitGroup
+}
+
+// NewManager creates a job manager. eventBus may be nil.
+func NewManager(jobStore store.JobStore, eventBus bus.EventBus) *Manager {
+	return &Manager{
+		store:    jobStore,
+		eventBus: eventBus,
+		timeout:  DefaultTimeout,
+	}
+}
+
+// Submit records a pending job and starts fn in the background
+func (m *Manager) Submit(ctx context.Context, kind, sessionID string, fn Func) (*store.Job, error) {
+	job := &store.Job{
+		ID:        uuid.New().String(),
+		Kind:      kind,
+		SessionID: sessionID,
+		Status:    store.JobStatusPending,
+		CreatedAt: time.Now(),
+	}
+	if err := m.store.CreateJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	m.wg.Add(1)
+	go func(job store.Job) {
+		defer m.wg.Done()
+		m.run(&job, fn)
+	}(*job)
+	return job, nil
+}
+
+// run executes fn and saves its result. The job outlives the request that
+// submitted it, so it runs on its own context.
+func (m *Manager) run(job *store.Job, fn Func) {
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+
+	started := time.Now()
+	job.Status = store.JobStatusRunning
+	job.StartedAt = &started
+	if err := m.store.UpdateJob(ctx, job); err != nil {
+		slog.Warn("failed to mark job running", "job_id", job.ID, "error", err)
+	}
+
+	result, err := fn(ctx)
+	if err == nil {
+		job.Result, err = json.Marshal(result)
+		if err != nil {
+			err = fmt.Errorf("failed to encode result: %w", err)
+		}
+	}
+	completed := time.Now()
+	job.CompletedAt = &completed
+	if err != nil {
+		job.Status = store.JobStatusFailed
+		job.Error = err.Error()
+		job.Result = nil
+	} else {
+		job.Status = store.JobStatusCompleted
+	}
+
+	// Save even if the job's own deadline has passed
+	if err := m.store.UpdateJob(context.Background(), job); err != nil {
+		slog.Error("failed to save job result", "job_id", job.ID, "error", err)
+	}
+	slog.Info("job finished", "job_id", job.ID, "kind", job.Kind, "status", job.Status,
+		"duration_ms", completed.Sub(started).Milliseconds())
+	m.publish(job)
+}
+
+func (m *Manager) publish(job *store.Job) {
+	if m.eventBus == nil {
+		return
+	}
+	data := map[string]interface{}{
+		"job_id": job.ID,
+		"kind":   job.Kind,
+		"status": job.Status,
+	}
+	if job.SessionID != "" {
+		data["session_id"] = job.SessionID
+	}
+	if job.Error != "" {
+		data["error"] = job.Error
+	}
+	m.eventBus.Publish(bus.Event{Type: bus.EventJobCompleted, Data: data})
+}
+
+// Recover marks jobs left pending or running by a previous daemon run as
+// interrupted, since their work died with that process
+func (m *Manager) Recover(ctx context.Context) error {
+	for _, status := range []string{store.JobStatusPending, store.JobStatusRunning} {
+		jobs, err := m.store.ListJobs(ctx, status)
+		if err != nil {
+			return err
+		}
+		for _, job := range jobs {
+			now := time.Now()
+			job.Status = store.JobStatusInterrupted
+			job.Error = "daemon restarted before the job finished"
+			job.CompletedAt = &now
+			if err := m.store.UpdateJob(ctx, job); err != nil {
+				return err
+			}
+			slog.Info("marked interrupted job", "job_id", job.ID, "kind", job.Kind)
+			m.publish(job)
+		}
+	}
+	return nil
+}
+
+// Wait blocks until every submitted job has finished
+func (m *Manager) Wait() {
+	m.wg.Wait()
+}
@@ -0,0 +1,76 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubmit(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	eventBus := bus.NewInMemoryBus()
+	m := NewManager(s, eventBus)
+
+	ok, err := m.Submit(ctx, "review", "sess-1", func(ctx context.Context) (any, error) {
+		return map[string]string{"verdict": "lgtm"}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, store.JobStatusPending, ok.Status)
+
+	failed, err := m.Submit(ctx, "review", "", func(ctx context.Context) (any, error) {
+		return nil, errors.New("model unavailable")
+	})
+	require.NoError(t, err)
+	m.Wait()
+
+	job, err := s.GetJob(ctx, ok.ID)
+	require.NoError(t, err)
+	assert.Equal(t, store.JobStatusCompleted, job.Status)
+	assert.JSONEq(t, `{"verdict":"lgtm"}`, string(job.Result))
+	assert.NotNil(t, job.StartedAt)
+	assert.NotNil(t, job.CompletedAt)
+
+	job, err = s.GetJob(ctx, failed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, store.JobStatusFailed, job.Status)
+	assert.Equal(t, "model unavailable", job.Error)
+	assert.Empty(t, job.Result)
+
+	events := eventBus.EventsOfType(bus.EventJobCompleted)
+	require.Len(t, events, 2)
+	byID := map[interface{}]bus.Event{}
+	for _, e := range events {
+		byID[e.Data["job_id"]] = e
+	}
+	assert.Equal(t, "sess-1", byID[ok.ID].Data["session_id"])
+	assert.Equal(t, store.JobStatusFailed, byID[failed.ID].Data["status"])
+}
+
+func TestRecover(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	eventBus := bus.NewInMemoryBus()
+
+	require.NoError(t, s.CreateJob(ctx, &store.Job{ID: "a", Kind: "review", Status: store.JobStatusRunning}))
+	require.NoError(t, s.CreateJob(ctx, &store.Job{ID: "b", Kind: "review", Status: store.JobStatusPending}))
+	require.NoError(t, s.CreateJob(ctx, &store.Job{ID: "c", Kind: "review", Status: store.JobStatusCompleted}))
+
+	require.NoError(t, NewManager(s, eventBus).Recover(ctx))
+
+	for id, want := range map[string]string{
+		"a": store.JobStatusInterrupted,
+		"b": store.JobStatusInterrupted,
+		"c": store.JobStatusCompleted,
+	} {
+		job, err := s.GetJob(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, want, job.Status, id)
+	}
+	assert.Len(t, eventBus.EventsOfType(bus.EventJobCompleted), 2)
+}
@@ -0,0 +1,234 @@
+// Package lfs understands Git LFS in a working tree: which files
+// .gitattributes routes through the LFS filter, the pointer files git stores
+// for them, and which large files would be committed as regular objects.
+package lfs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/humanlayer/humanlayer/hld/workspace"
+)
+
+// DefaultLargeFileSize is the size over which a file LFS doesn't track is flagged
+const DefaultLargeFileSize = 10 << 20
+
+// MaxPointerSize is the largest a pointer file can be, per the LFS spec
+const MaxPointerSize = 1024
+
+// Reasons a file is flagged
+const (
+	ReasonLarge      = "large_file"  // Over the size limit and not tracked by LFS
+	ReasonNotPointer = "not_pointer" // Tracked by LFS but staged as content, usually because git-lfs isn't installed
+)
+
+// Pointer identifies the content LFS stores for a file
+type Pointer struct {
+	// OID is the sha256 of the content. It's empty for working tree
+	// content, which isn't hashed.
+	OID  string `json:"oid,omitempty"`
+	Size int64  `json:"size"`
+}
+
+// Change is how a file LFS tracks changes
+type Change struct {
+	// Old and New are unset where the file doesn't exist
+	Old *Pointer `json:"old,omitempty"`
+	New *Pointer `json:"new,omitempty"`
+	// NotPointer is set when the staged content is the file itself rather
+	// than a pointer, so it would be committed as a regular object
+	NotPointer bool `json:"notPointer,omitempty"`
+}
+
+// Warning flags a file that would be committed as a regular object but
+// shouldn't be
+type Warning struct {
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+var (
+	pointerVersion = regexp.MustCompile(`^version https://(?:git-lfs\.github\.com/spec/v1|hawser\.github\.com/spec/v1)$`)
+	pointerOID     = regexp.MustCompile(`^oid sha256:([0-9a-f]{64})$`)
+	pointerSize    = regexp.MustCompile(`^size (\d+)$`)
+)
+
+// ParsePointer reads a pointer file, reporting false if content isn't one
+func ParsePointer(content []byte) (Pointer, bool) {
+	if len(content) > MaxPointerSize {
+		return Pointer{}, false
+	}
+	lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+	if len(lines) < 3 || !pointerVersion.MatchString(lines[0]) {
+		return Pointer{}, false
+	}
+	var p Pointer
+	for _, line := range lines[1:] {
+		if m := pointerOID.FindStringSubmatch(line); m != nil {
+			p.OID = m[1]
+		} else if m := pointerSize.FindStringSubmatch(line); m != nil {
+			p.Size, _ = strconv.ParseInt(m[1], 10, 64)
+		}
+	}
+	return p, p.OID != ""
+}
+
+// Enabled reports whether any .gitattributes file in the repository at dir,
+// committed or not, routes files through LFS
+func Enabled(ctx context.Context, host workspace.Host, dir string) bool {
+	// git grep exits 1 when nothing matches
+	_, err := run(ctx, host, dir, nil, "grep", "-q", "--untracked", "-e", "filter=lfs", "--", ":(glob)**/.gitattributes")
+	return err == nil
+}
+
+// Tracked returns which of paths, relative to dir, .gitattributes assigns
+// to the LFS filter
+func Tracked(ctx context.Context, host workspace.Host, dir string, paths []string) (map[string]bool, error) {
+	tracked := make(map[string]bool)
+	if len(paths) == 0 {
+		return tracked, nil
+	}
+	output, err := run(ctx, host, dir, nil, append([]string{"check-attr", "-z", "filter", "--"}, paths...)...)
+	if err != nil {
+		return nil, err
+	}
+	// Each entry is path NUL attribute NUL value NUL
+	fields := strings.Split(output, "\x00")
+	for i := 0; i+2 < len(fields); i += 3 {
+		if fields[i+2] == "lfs" {
+			tracked[fields[i]] = true
+		}
+	}
+	return tracked, nil
+}
+
+// ObjectSizes returns the sizes of objects named like HEAD:path or :path
+// (the index), leaving out those that don't exist
+func ObjectSizes(ctx context.Context, host workspace.Host, dir string, objects []string) (map[string]int64, error) {
+	sizes := make(map[string]int64)
+	var input bytes.Buffer
+	var asked []string
+	for _, object := range objects {
+		// The batch protocol is line based
+		if !strings.ContainsAny(object, "\n") {
+			input.WriteString(object + "\n")
+			asked = append(asked, object)
+		}
+	}
+	if len(asked) == 0 {
+		return sizes, nil
+	}
+	output, err := run(ctx, host, dir, &input, "cat-file", "--batch-check=%(objectsize)")
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for i := 0; scanner.Scan() && i < len(asked); i++ {
+		// Missing objects are reported as "<name> missing"
+		if size, err := strconv.ParseInt(scanner.Text(), 10, 64); err == nil {
+			sizes[asked[i]] = size
+		}
+	}
+	return sizes, nil
+}
+
+// ReadPointer reads object, of the given size, as a pointer file
+func ReadPointer(ctx context.Context, host workspace.Host, dir, object string, size int64) (Pointer, bool) {
+	if size > MaxPointerSize {
+		return Pointer{}, false
+	}
+	content, err := run(ctx, host, dir, nil, "cat-file", "blob", object)
+	if err != nil {
+		return Pointer{}, false
+	}
+	return ParsePointer([]byte(content))
+}
+
+var wcLine = regexp.MustCompile(`^\s*(\d+) (.+)$`)
+
+// FileSizes returns the sizes of the working tree files at paths, relative
+// to dir, leaving out those that don't exist
+func FileSizes(ctx context.Context, host workspace.Host, dir string, paths []string) map[string]int64 {
+	sizes := make(map[string]int64)
+	if len(paths) == 0 {
+		return sizes
+	}
+	wanted := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		wanted[path] = true
+	}
+	// wc fails if any path is missing, but still counts the rest
+	cmd := host.Command(ctx, dir, "wc", append([]string{"-c", "--"}, paths...)...)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	_ = cmd.Run()
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		m := wcLine.FindStringSubmatch(line)
+		if m == nil || !wanted[m[2]] {
+			continue
+		}
+		if size, err := strconv.ParseInt(m[1], 10, 64); err == nil {
+			sizes[m[2]] = size
+		}
+	}
+	return sizes
+}
+
+// Check flags the files among paths that would be committed as regular
+// objects though they shouldn't be: those over limit that LFS doesn't
+// track, and those it tracks whose staged content isn't a pointer. sizes
+// gives the size of the content each path would commit.
+func Check(tracked map[string]bool, notPointer map[string]bool, sizes map[string]int64, paths []string, limit int64) []Warning {
+	var warnings []Warning
+	for _, path := range paths {
+		size, ok := sizes[path]
+		switch {
+		case notPointer[path]:
+			warnings = append(warnings, Warning{
+				Path: path, Size: size, Reason: ReasonNotPointer,
+				Message: "Tracked by Git LFS in .gitattributes but staged as regular content; is git-lfs installed?",
+			})
+		case ok && !tracked[path] && limit > 0 && size > limit:
+			warnings = append(warnings, Warning{
+				Path: path, Size: size, Reason: ReasonLarge,
+				Message: fmt.Sprintf("%s is over the %s limit and not tracked by Git LFS; add it to .gitattributes with git lfs track", FormatSize(size), FormatSize(limit)),
+			})
+		}
+	}
+	return warnings
+}
+
+// FormatSize renders a byte count for people, e.g. 12.5 MiB
+func FormatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+func run(ctx context.Context, host workspace.Host, dir string, stdin *bytes.Buffer, args ...string) (string, error) {
+	cmd := host.Command(ctx, dir, "git", args...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
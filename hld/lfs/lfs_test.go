@@ -0,0 +1,49 @@
+package lfs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const oid = "4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393"
+
+func TestParsePointer(t *testing.T) {
+	p, ok := ParsePointer([]byte("version https://git-lfs.github.com/spec/v1\noid sha256:" + oid + "\nsize 12345\n"))
+	assert.True(t, ok)
+	assert.Equal(t, Pointer{OID: oid, Size: 12345}, p)
+
+	for _, content := range []string{
+		"",
+		"hello world\n",
+		"version https://git-lfs.github.com/spec/v1\nsize 12345\n",
+		"version https://example.com/spec\noid sha256:" + oid + "\nsize 1\n",
+		"version https://git-lfs.github.com/spec/v1\noid sha256:" + oid + "\nsize 1\n" + strings.Repeat("x", MaxPointerSize),
+	} {
+		_, ok := ParsePointer([]byte(content))
+		assert.False(t, ok, content)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tracked := map[string]bool{"model.bin": true, "art.psd": true}
+	notPointer := map[string]bool{"art.psd": true}
+	sizes := map[string]int64{"data.csv": 20 << 20, "small.txt": 10, "model.bin": 50 << 20, "art.psd": 3 << 20}
+	warnings := Check(tracked, notPointer, sizes, []string{"data.csv", "small.txt", "model.bin", "art.psd", "gone.txt"}, 10<<20)
+
+	assert.Len(t, warnings, 2)
+	assert.Equal(t, "data.csv", warnings[0].Path)
+	assert.Equal(t, ReasonLarge, warnings[0].Reason)
+	assert.Contains(t, warnings[0].Message, "20.0 MiB is over the 10.0 MiB limit")
+	assert.Equal(t, "art.psd", warnings[1].Path)
+	assert.Equal(t, ReasonNotPointer, warnings[1].Reason)
+
+	assert.Len(t, Check(tracked, nil, sizes, []string{"data.csv"}, 0), 0, "a zero limit turns the size check off")
+}
+
+func TestFormatSize(t *testing.T) {
+	assert.Equal(t, "512 B", FormatSize(512))
+	assert.Equal(t, "1.5 KiB", FormatSize(1536))
+	assert.Equal(t, "2.0 GiB", FormatSize(2<<30))
+}
@@ -0,0 +1,118 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const defaultAnthropicBaseURL = "https://api.anthropic.com"
+
+// AnthropicProvider calls the Anthropic Messages API
+type AnthropicProvider struct {
+	baseURL    string
+	apiKeyEnv  string
+	httpClient *http.Client
+}
+
+// NewAnthropicProvider creates an Anthropic provider reading its key from apiKeyEnv
+func NewAnthropicProvider(baseURL, apiKeyEnv string, httpClient *http.Client) *AnthropicProvider {
+	if baseURL == "" {
+		baseURL = defaultAnthropicBaseURL
+	}
+	if apiKeyEnv == "" {
+		apiKeyEnv = "ANTHROPIC_API_KEY"
+	}
+	return &AnthropicProvider{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		apiKeyEnv:  apiKeyEnv,
+		httpClient: httpClient,
+	}
+}
+
+// Complete sends a single user message and returns the first text block
+func (p *AnthropicProvider) Complete(ctx context.Context, model string, req Request) (string, error) {
+	apiKey := os.Getenv(p.apiKeyEnv)
+	if apiKey == "" {
+		return "", fmt.Errorf("%w: %s not set", ErrNotConfigured, p.apiKeyEnv)
+	}
+
+	maxTokens := req.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = defaultMaxTokens
+	}
+	payload := map[string]interface{}{
+		"model":      model,
+		"max_tokens": maxTokens,
+		"messages": []map[string]string{
+			{"role": "user", "content": req.Prompt},
+		},
+	}
+	if req.System != "" {
+		payload["system"] = req.System
+	}
+	if req.Schema != nil {
+		// Forcing a single tool call makes the model emit arguments matching the schema
+		payload["tools"] = []map[string]interface{}{{
+			"name":         req.Schema.Name,
+			"description":  req.Schema.Description,
+			"input_schema": req.Schema.Definition,
+		}}
+		payload["tool_choice"] = map[string]string{"type": "tool", "name": req.Schema.Name}
+	}
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/v1/messages", bytes.NewReader(payloadBytes))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-api-key", apiKey)
+	httpReq.Header.Set("anthropic-version", "2023-06-01")
+
+	resp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("API request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", &StatusError{StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+
+	var anthropicResp struct {
+		Content []struct {
+			Type  string          `json:"type"`
+			Text  string          `json:"text"`
+			Input json.RawMessage `json:"input"`
+		} `json:"content"`
+	}
+	if err := json.Unmarshal(respBody, &anthropicResp); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	for _, content := range anthropicResp.Content {
+		if req.Schema != nil && content.Type == "tool_use" {
+			return string(content.Input), nil
+		}
+		if req.Schema == nil && content.Type == "text" {
+			return content.Text, nil
+		}
+	}
+	if req.Schema != nil {
+		return "", fmt.Errorf("response contained no tool call")
+	}
+	return "", fmt.Errorf("response contained no text")
+}
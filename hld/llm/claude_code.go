@@ -0,0 +1,65 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/humanlayer/humanlayer/claudecode-go"
+)
+
+// ClaudeCodeProvider runs a single-turn query through the local Claude Code
+// CLI. It needs no API key but is slower than calling an API directly.
+type ClaudeCodeProvider struct {
+	once   sync.Once
+	client *claudecode.Client
+	err    error
+}
+
+// NewClaudeCodeProvider creates a provider that finds the claude binary on first use
+func NewClaudeCodeProvider() *ClaudeCodeProvider {
+	return &ClaudeCodeProvider{}
+}
+
+// Complete launches Claude Code with the prompt and waits for its result.
+// model is a Claude Code model alias such as "sonnet" or "haiku".
+func (p *ClaudeCodeProvider) Complete(ctx context.Context, model string, req Request) (string, error) {
+	p.once.Do(func() {
+		p.client, p.err = claudecode.NewClient()
+		if p.err != nil {
+			slog.Warn("failed to create claude client for llm provider", "error", p.err)
+		}
+	})
+	if p.err != nil {
+		return "", fmt.Errorf("%w: %v", ErrNotConfigured, p.err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	query := req.Prompt
+	if req.System != "" {
+		query = req.System + "\n\n" + req.Prompt
+	}
+	if req.Schema != nil {
+		// The CLI has no structured output mode, so the schema goes in the prompt
+		query += "\n\nRespond with only a JSON object matching this JSON Schema:\n" + string(req.Schema.Definition)
+	}
+
+	result, err := p.client.LaunchAndWait(claudecode.SessionConfig{
+		Query:        query,
+		Model:        claudecode.Model(model),
+		OutputFormat: claudecode.OutputJSON,
+		MaxTurns:     1,
+		WorkingDir:   req.WorkingDir,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to run claude query: %w", err)
+	}
+	if result.IsError {
+		return "", fmt.Errorf("claude returned error: %s", result.Error)
+	}
+	return result.Result, nil
+}
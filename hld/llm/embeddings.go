@@ -0,0 +1,108 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/config"
+)
+
+// Embedder turns texts into vectors with one embedding model
+type Embedder struct {
+	provider *OpenAIProvider
+	model    string
+}
+
+// NewEmbedder creates an embedder for cfg, looking its provider up among the
+// default and configured LLM providers. Only OpenAI-compatible providers
+// serve embeddings.
+func NewEmbedder(llmConfig config.LLMConfig, cfg config.EmbeddingsConfig) (*Embedder, error) {
+	provider, ok := llmConfig.Providers[cfg.Provider]
+	if !ok {
+		provider, ok = DefaultProviders[cfg.Provider]
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown embeddings provider %q", cfg.Provider)
+	}
+	if provider.Type != config.LLMProviderOpenAI {
+		return nil, fmt.Errorf("embeddings provider %q must have type %q", cfg.Provider, config.LLMProviderOpenAI)
+	}
+	httpClient := &http.Client{Timeout: 60 * time.Second}
+	return &Embedder{provider: NewOpenAIProvider(provider.BaseURL, provider.APIKeyEnv, httpClient), model: cfg.Model}, nil
+}
+
+// Model names the embedding model; vectors from different models can't be compared
+func (e *Embedder) Model() string {
+	return e.model
+}
+
+// Embed returns one vector per text, in order
+func (e *Embedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return e.provider.Embed(ctx, e.model, texts)
+}
+
+// Embed calls the /v1/embeddings endpoint
+func (p *OpenAIProvider) Embed(ctx context.Context, model string, texts []string) ([][]float32, error) {
+	var apiKey string
+	if p.apiKeyEnv != "" {
+		apiKey = os.Getenv(p.apiKeyEnv)
+		if apiKey == "" {
+			return nil, fmt.Errorf("%w: %s not set", ErrNotConfigured, p.apiKeyEnv)
+		}
+	}
+
+	payloadBytes, err := json.Marshal(map[string]interface{}{"model": model, "input": texts})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/v1/embeddings", bytes.NewReader(payloadBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("API request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+
+	var embeddingsResp struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(respBody, &embeddingsResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(embeddingsResp.Data) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(embeddingsResp.Data))
+	}
+	sort.Slice(embeddingsResp.Data, func(i, j int) bool {
+		return embeddingsResp.Data[i].Index < embeddingsResp.Data[j].Index
+	})
+	vectors := make([][]float32, len(texts))
+	for i, data := range embeddingsResp.Data {
+		vectors[i] = data.Embedding
+	}
+	return vectors, nil
+}
@@ -0,0 +1,45 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbedder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/embeddings", r.URL.Path)
+		var body struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "nomic-embed-text", body.Model)
+		assert.Equal(t, []string{"a", "b"}, body.Input)
+		// Out of order, as some servers answer
+		_, _ = w.Write([]byte(`{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}]}`))
+	}))
+	defer server.Close()
+
+	llmConfig := config.LLMConfig{Providers: map[string]config.LLMProviderConfig{
+		"ollama": {Type: config.LLMProviderOpenAI, BaseURL: server.URL + "/v1"},
+	}}
+	embedder, err := NewEmbedder(llmConfig, config.EmbeddingsConfig{Provider: "ollama", Model: "nomic-embed-text"})
+	require.NoError(t, err)
+	assert.Equal(t, "nomic-embed-text", embedder.Model())
+
+	vectors, err := embedder.Embed(context.Background(), []string{"a", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float32{{1, 0}, {0, 1}}, vectors)
+
+	_, err = NewEmbedder(llmConfig, config.EmbeddingsConfig{Provider: "anthropic", Model: "x"})
+	assert.Error(t, err, "Anthropic has no embeddings API")
+	_, err = NewEmbedder(llmConfig, config.EmbeddingsConfig{Provider: "missing", Model: "x"})
+	assert.Error(t, err)
+}
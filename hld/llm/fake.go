@@ -0,0 +1,32 @@
+package llm
+
+import (
+	"context"
+	"sync"
+)
+
+// FakeProvider is a Provider for tests and embedders. It answers every
+// request with Response, or fails with Err, and records the prompts it was
+// sent.
+type FakeProvider struct {
+	Response string
+	Err      error
+
+	mu      sync.Mutex
+	prompts []string
+}
+
+// Complete records the prompt and returns Response and Err
+func (p *FakeProvider) Complete(ctx context.Context, model string, req Request) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.prompts = append(p.prompts, req.Prompt)
+	return p.Response, p.Err
+}
+
+// Prompts returns the prompts sent so far, oldest first
+func (p *FakeProvider) Prompts() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.prompts...)
+}
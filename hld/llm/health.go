@@ -0,0 +1,77 @@
+package llm
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Provider health statuses
+const (
+	// HealthUnknown is a provider not called since the daemon started
+	HealthUnknown = "unknown"
+	// HealthOK is a provider whose last call succeeded
+	HealthOK = "ok"
+	// HealthFailing is a provider whose last call failed
+	HealthFailing = "failing"
+	// HealthNotConfigured is a provider missing credentials or a binary
+	HealthNotConfigured = "not_configured"
+)
+
+// ProviderHealth is how a provider's calls have gone since the daemon
+// started. It carries no errors, which can quote prompts or responses.
+type ProviderHealth struct {
+	Name                string     `json:"name"`
+	Status              string     `json:"status"`
+	LastSuccess         *time.Time `json:"last_success,omitempty"`
+	LastFailure         *time.Time `json:"last_failure,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+}
+
+// healthTracker records the outcome of each provider call
+type healthTracker struct {
+	mu        sync.Mutex
+	providers map[string]*ProviderHealth
+}
+
+func (t *healthTracker) record(provider string, at time.Time, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.providers == nil {
+		t.providers = make(map[string]*ProviderHealth)
+	}
+	h, ok := t.providers[provider]
+	if !ok {
+		h = &ProviderHealth{Name: provider}
+		t.providers[provider] = h
+	}
+	switch {
+	case err == nil:
+		h.Status = HealthOK
+		h.LastSuccess = &at
+		h.ConsecutiveFailures = 0
+	case errors.Is(err, ErrNotConfigured):
+		h.Status = HealthNotConfigured
+	default:
+		h.Status = HealthFailing
+		h.LastFailure = &at
+		h.ConsecutiveFailures++
+	}
+}
+
+// Health returns the health of every provider, by name
+func (r *Router) Health() []ProviderHealth {
+	r.health.mu.Lock()
+	defer r.health.mu.Unlock()
+	health := make([]ProviderHealth, 0, len(r.providers))
+	for name := range r.providers {
+		if h, ok := r.health.providers[name]; ok {
+			health = append(health, *h)
+		} else {
+			health = append(health, ProviderHealth{Name: name, Status: HealthUnknown})
+		}
+	}
+	sort.Slice(health, func(i, j int) bool { return health[i].Name < health[j].Name })
+	return health
+}
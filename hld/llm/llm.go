@@ -0,0 +1,79 @@
+// Package llm is the daemon's provider layer for its own model calls, such as
+// commit message generation and ephemeral chat. A Router picks the model for
+// each task type and falls back to the next configured model when a call fails.
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Task identifies what a completion is for, so it can be routed to a suitable model
+type Task string
+
+const (
+	TaskCommitMessage Task = "commit-message"
+	TaskEphemeralChat Task = "ephemeral-chat"
+	TaskSummarization Task = "summarization"
+	TaskReview        Task = "review"
+)
+
+// Request is a single-turn completion request
+type Request struct {
+	System    string
+	Prompt    string
+	MaxTokens int
+	// Schema, when set, asks for a JSON object matching it (see Router.CompleteJSON)
+	Schema *Schema
+	// WorkingDir gives providers that run locally (Claude Code) a directory for context
+	WorkingDir string
+}
+
+// Response is the text a provider returned, and which model produced it
+type Response struct {
+	Text     string `json:"text"`
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+}
+
+// Provider completes prompts with a given model
+type Provider interface {
+	Complete(ctx context.Context, model string, req Request) (string, error)
+}
+
+// Exchange is one provider call: the request exactly as sent, and the raw
+// text or error that came back
+type Exchange struct {
+	Task     Task
+	Provider string
+	Model    string
+	System   string
+	Prompt   string
+	Response string
+	Error    string
+	Duration time.Duration
+	Time     time.Time
+}
+
+// Observer sees each provider call a Router makes
+type Observer interface {
+	Observe(ctx context.Context, exchange Exchange)
+}
+
+// ErrNotConfigured is returned by providers missing credentials or a binary.
+// The router treats it like any other failure and tries the next model.
+var ErrNotConfigured = errors.New("provider not configured")
+
+// StatusError is returned when a provider's API answers with a non-2xx status
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("API returned status %d", e.StatusCode)
+}
+
+const defaultMaxTokens = 2048
@@ -0,0 +1,110 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// OpenAIProvider calls an OpenAI-compatible chat completions API such as OpenRouter
+type OpenAIProvider struct {
+	baseURL    string
+	apiKeyEnv  string
+	httpClient *http.Client
+}
+
+// NewOpenAIProvider creates a provider for the API at baseURL (with or without
+// a trailing /v1), reading its key from apiKeyEnv
+func NewOpenAIProvider(baseURL, apiKeyEnv string, httpClient *http.Client) *OpenAIProvider {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	baseURL = strings.TrimSuffix(baseURL, "/v1")
+	return &OpenAIProvider{
+		baseURL:    baseURL,
+		apiKeyEnv:  apiKeyEnv,
+		httpClient: httpClient,
+	}
+}
+
+// Complete sends a chat completion and returns the first choice's content
+func (p *OpenAIProvider) Complete(ctx context.Context, model string, req Request) (string, error) {
+	var apiKey string
+	if p.apiKeyEnv != "" {
+		apiKey = os.Getenv(p.apiKeyEnv)
+		if apiKey == "" {
+			return "", fmt.Errorf("%w: %s not set", ErrNotConfigured, p.apiKeyEnv)
+		}
+	}
+
+	maxTokens := req.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = defaultMaxTokens
+	}
+	messages := []map[string]string{}
+	if req.System != "" {
+		messages = append(messages, map[string]string{"role": "system", "content": req.System})
+	}
+	messages = append(messages, map[string]string{"role": "user", "content": req.Prompt})
+
+	payload := map[string]interface{}{
+		"model":      model,
+		"max_tokens": maxTokens,
+		"messages":   messages,
+	}
+	if req.Schema != nil {
+		payload["response_format"] = map[string]interface{}{
+			"type": "json_schema",
+			"json_schema": map[string]interface{}{
+				"name":        req.Schema.Name,
+				"description": req.Schema.Description,
+				"schema":      req.Schema.Definition,
+			},
+		}
+	}
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/v1/chat/completions", bytes.NewReader(payloadBytes))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("API request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", &StatusError{StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+
+	var openAIResp struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(respBody, &openAIResp); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(openAIResp.Choices) == 0 {
+		return "", fmt.Errorf("response contained no choices")
+	}
+	return openAIResp.Choices[0].Message.Content, nil
+}
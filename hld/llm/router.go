@@ -0,0 +1,179 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/config"
+)
+
+// DefaultProviders are always available; config may override or add to them
+var DefaultProviders = map[string]config.LLMProviderConfig{
+	"anthropic":   {Type: config.LLMProviderAnthropic, APIKeyEnv: "ANTHROPIC_API_KEY"},
+	"openrouter":  {Type: config.LLMProviderOpenAI, BaseURL: "https://openrouter.ai/api", APIKeyEnv: "OPENROUTER_API_KEY"},
+	"claude_code": {Type: config.LLMProviderClaudeCode},
+}
+
+// DefaultRoutes send cheap tasks to Haiku and review to Sonnet, falling back
+// to OpenRouter and then the local Claude Code CLI
+var DefaultRoutes = map[Task][]config.LLMRoute{
+	TaskCommitMessage: {
+		{Provider: "anthropic", Model: "claude-sonnet-4-20250514"},
+		{Provider: "openrouter", Model: "anthropic/claude-sonnet-4"},
+	},
+	TaskEphemeralChat: {
+		{Provider: "claude_code", Model: "sonnet"},
+		{Provider: "anthropic", Model: "claude-sonnet-4-20250514"},
+	},
+	TaskSummarization: {
+		{Provider: "anthropic", Model: "claude-3-5-haiku-latest"},
+		{Provider: "openrouter", Model: "anthropic/claude-3.5-haiku"},
+		{Provider: "claude_code", Model: "haiku"},
+	},
+	TaskReview: {
+		{Provider: "anthropic", Model: "claude-sonnet-4-20250514"},
+		{Provider: "openrouter", Model: "anthropic/claude-sonnet-4"},
+		{Provider: "claude_code", Model: "sonnet"},
+	},
+}
+
+// Router completes requests with the models configured for each task, trying
+// each in order until one succeeds
+type Router struct {
+	providers map[string]Provider
+	routes    map[Task][]config.LLMRoute
+	observer  Observer
+	health    healthTracker
+}
+
+// NewRouter creates a router from the defaults merged with cfg
+func NewRouter(cfg config.LLMConfig) *Router {
+	httpClient := &http.Client{Timeout: 120 * time.Second}
+
+	providerConfigs := make(map[string]config.LLMProviderConfig, len(DefaultProviders)+len(cfg.Providers))
+	for name, p := range DefaultProviders {
+		providerConfigs[name] = p
+	}
+	for name, p := range cfg.Providers {
+		providerConfigs[name] = p
+	}
+
+	r := &Router{
+		providers: make(map[string]Provider, len(providerConfigs)),
+		routes:    make(map[Task][]config.LLMRoute, len(DefaultRoutes)+len(cfg.Routes)),
+	}
+	for name, p := range providerConfigs {
+		switch p.Type {
+		case config.LLMProviderAnthropic:
+			r.providers[name] = NewAnthropicProvider(p.BaseURL, p.APIKeyEnv, httpClient)
+		case config.LLMProviderOpenAI:
+			r.providers[name] = NewOpenAIProvider(p.BaseURL, p.APIKeyEnv, httpClient)
+		case config.LLMProviderClaudeCode:
+			r.providers[name] = NewClaudeCodeProvider()
+		}
+	}
+	for task, routes := range DefaultRoutes {
+		r.routes[task] = routes
+	}
+	for task, routes := range cfg.Routes {
+		r.routes[Task(task)] = routes
+	}
+	return r
+}
+
+// NewRouterWithProviders creates a router from explicit providers and routes
+func NewRouterWithProviders(providers map[string]Provider, routes map[Task][]config.LLMRoute) *Router {
+	return &Router{providers: providers, routes: routes}
+}
+
+// SetObserver shows every provider call to o, including failed attempts and
+// retries
+func (r *Router) SetObserver(o Observer) {
+	r.observer = o
+}
+
+// Routes returns the models tried for a task, in order
+func (r *Router) Routes(task Task) []config.LLMRoute {
+	return r.routes[task]
+}
+
+// Complete tries each model routed for the task until one succeeds. If all
+// fail, the returned error joins every attempt's error.
+func (r *Router) Complete(ctx context.Context, task Task, req Request) (*Response, error) {
+	return r.run(ctx, task, func(provider Provider, route config.LLMRoute) (string, error) {
+		return r.complete(ctx, task, provider, route, req)
+	})
+}
+
+// complete calls a provider, showing the exchange to the observer
+func (r *Router) complete(ctx context.Context, task Task, provider Provider, route config.LLMRoute, req Request) (string, error) {
+	start := time.Now()
+	text, err := provider.Complete(ctx, route.Model, req)
+	r.health.record(route.Provider, start, err)
+	if r.observer != nil {
+		exchange := Exchange{
+			Task:     task,
+			Provider: route.Provider,
+			Model:    route.Model,
+			System:   req.System,
+			Prompt:   req.Prompt,
+			Response: text,
+			Duration: time.Since(start),
+			Time:     start,
+		}
+		if err != nil {
+			exchange.Error = err.Error()
+		}
+		r.observer.Observe(ctx, exchange)
+	}
+	return text, err
+}
+
+// run calls attempt with each routed model in order until one succeeds
+func (r *Router) run(ctx context.Context, task Task, attempt func(Provider, config.LLMRoute) (string, error)) (*Response, error) {
+	routes := r.routes[task]
+	if len(routes) == 0 {
+		return nil, fmt.Errorf("no models configured for task %q", task)
+	}
+
+	var errs []error
+	for i, route := range routes {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		provider, ok := r.providers[route.Provider]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s/%s: unknown provider", route.Provider, route.Model))
+			continue
+		}
+
+		text, err := attempt(provider, route)
+		if err == nil {
+			if i > 0 {
+				slog.Info("llm request served by fallback model",
+					"task", task,
+					"provider", route.Provider,
+					"model", route.Model,
+					"attempt", i+1)
+			}
+			return &Response{Text: text, Provider: route.Provider, Model: route.Model}, nil
+		}
+
+		if errors.Is(err, ErrNotConfigured) {
+			slog.Debug("skipping unconfigured llm provider", "task", task, "provider", route.Provider, "error", err)
+		} else {
+			logAttrs := []any{"task", task, "provider", route.Provider, "model", route.Model, "error", err}
+			var statusErr *StatusError
+			if errors.As(err, &statusErr) {
+				logAttrs = append(logAttrs, "response", statusErr.Body)
+			}
+			slog.Warn("llm request failed", logAttrs...)
+		}
+		errs = append(errs, fmt.Errorf("%s/%s: %w", route.Provider, route.Model, err))
+	}
+	return nil, fmt.Errorf("all models failed for task %q: %w", task, errors.Join(errs...))
+}
@@ -0,0 +1,174 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProvider struct {
+	calls []string
+	err   error
+}
+
+func (p *fakeProvider) Complete(ctx context.Context, model string, req Request) (string, error) {
+	p.calls = append(p.calls, model)
+	if p.err != nil {
+		return "", p.err
+	}
+	return "from " + model, nil
+}
+
+func TestRouterFallback(t *testing.T) {
+	down := &fakeProvider{err: &StatusError{StatusCode: http.StatusServiceUnavailable}}
+	missing := &fakeProvider{err: ErrNotConfigured}
+	backup := &fakeProvider{}
+
+	r := NewRouterWithProviders(map[string]Provider{
+		"primary": down,
+		"nokey":   missing,
+		"backup":  backup,
+	}, map[Task][]config.LLMRoute{
+		TaskReview: {
+			{Provider: "primary", Model: "sonnet"},
+			{Provider: "unknown", Model: "x"},
+			{Provider: "nokey", Model: "gpt"},
+			{Provider: "backup", Model: "haiku"},
+		},
+		TaskSummarization: {
+			{Provider: "primary", Model: "sonnet"},
+			{Provider: "nokey", Model: "gpt"},
+		},
+	})
+
+	resp, err := r.Complete(context.Background(), TaskReview, Request{Prompt: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, &Response{Text: "from haiku", Provider: "backup", Model: "haiku"}, resp)
+	assert.Equal(t, []string{"sonnet"}, down.calls)
+
+	_, err = r.Complete(context.Background(), TaskSummarization, Request{Prompt: "hi"})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrNotConfigured))
+	var statusErr *StatusError
+	assert.True(t, errors.As(err, &statusErr))
+
+	_, err = r.Complete(context.Background(), TaskCommitMessage, Request{Prompt: "hi"})
+	assert.ErrorContains(t, err, "no models configured")
+}
+
+type recordingObserver struct{ exchanges []Exchange }
+
+func (o *recordingObserver) Observe(ctx context.Context, exchange Exchange) {
+	o.exchanges = append(o.exchanges, exchange)
+}
+
+func TestRouterObserver(t *testing.T) {
+	r := NewRouterWithProviders(map[string]Provider{
+		"primary": &fakeProvider{err: &StatusError{StatusCode: http.StatusServiceUnavailable}},
+		"backup":  &fakeProvider{},
+	}, map[Task][]config.LLMRoute{
+		TaskCommitMessage: {{Provider: "primary", Model: "sonnet"}, {Provider: "backup", Model: "haiku"}},
+	})
+	observer := &recordingObserver{}
+	r.SetObserver(observer)
+
+	_, err := r.Complete(context.Background(), TaskCommitMessage, Request{System: "be brief", Prompt: "diff"})
+	require.NoError(t, err)
+	require.Len(t, observer.exchanges, 2)
+	failed, served := observer.exchanges[0], observer.exchanges[1]
+	assert.Equal(t, "primary", failed.Provider)
+	assert.Equal(t, "API returned status 503", failed.Error)
+	assert.Equal(t, TaskCommitMessage, served.Task)
+	assert.Equal(t, "haiku", served.Model)
+	assert.Equal(t, "be brief", served.System)
+	assert.Equal(t, "diff", served.Prompt)
+	assert.Equal(t, "from haiku", served.Response)
+	assert.Empty(t, served.Error)
+}
+
+func TestRouterHealth(t *testing.T) {
+	primary := &fakeProvider{err: &StatusError{StatusCode: http.StatusServiceUnavailable}}
+	r := NewRouterWithProviders(map[string]Provider{
+		"primary": primary,
+		"nokey":   &fakeProvider{err: ErrNotConfigured},
+		"backup":  &fakeProvider{},
+		"idle":    &fakeProvider{},
+	}, map[Task][]config.LLMRoute{
+		TaskCommitMessage: {{Provider: "primary", Model: "sonnet"}, {Provider: "nokey", Model: "gpt"}, {Provider: "backup", Model: "haiku"}},
+	})
+	for i := 0; i < 2; i++ {
+		_, err := r.Complete(context.Background(), TaskCommitMessage, Request{Prompt: "diff"})
+		require.NoError(t, err)
+	}
+
+	health := r.Health()
+	require.Len(t, health, 4)
+	assert.Equal(t, []string{"backup", "idle", "nokey", "primary"}, []string{health[0].Name, health[1].Name, health[2].Name, health[3].Name})
+	assert.Equal(t, HealthOK, health[0].Status)
+	assert.NotNil(t, health[0].LastSuccess)
+	assert.Equal(t, HealthUnknown, health[1].Status)
+	assert.Equal(t, HealthNotConfigured, health[2].Status)
+	assert.Nil(t, health[2].LastFailure, "a missing key isn't an outage")
+	assert.Equal(t, HealthFailing, health[3].Status)
+	assert.Equal(t, 2, health[3].ConsecutiveFailures)
+
+	primary.err = nil
+	_, err := r.Complete(context.Background(), TaskCommitMessage, Request{Prompt: "diff"})
+	require.NoError(t, err)
+	recovered := r.Health()[3]
+	assert.Equal(t, HealthOK, recovered.Status)
+	assert.Zero(t, recovered.ConsecutiveFailures)
+	assert.NotNil(t, recovered.LastFailure)
+}
+
+func TestNewRouterMergesConfig(t *testing.T) {
+	r := NewRouter(config.LLMConfig{
+		Routes: map[string][]config.LLMRoute{
+			string(TaskCommitMessage): {{Provider: "anthropic", Model: "claude-3-5-haiku-latest"}},
+		},
+	})
+	assert.Equal(t, []config.LLMRoute{{Provider: "anthropic", Model: "claude-3-5-haiku-latest"}}, r.Routes(TaskCommitMessage))
+	assert.Equal(t, DefaultRoutes[TaskReview], r.Routes(TaskReview))
+}
+
+func TestProviders(t *testing.T) {
+	t.Setenv("TEST_LLM_KEY", "secret")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		switch r.URL.Path {
+		case "/v1/messages":
+			assert.Equal(t, "secret", r.Header.Get("x-api-key"))
+			assert.Equal(t, "be brief", body["system"])
+			_, _ = w.Write([]byte(`{"content":[{"type":"text","text":"anthropic says hi"}]}`))
+		case "/v1/chat/completions":
+			assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+			assert.Len(t, body["messages"], 2)
+			_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"openai says hi"}}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	req := Request{System: "be brief", Prompt: "hi"}
+
+	text, err := NewAnthropicProvider(server.URL, "TEST_LLM_KEY", server.Client()).Complete(context.Background(), "m", req)
+	require.NoError(t, err)
+	assert.Equal(t, "anthropic says hi", text)
+
+	text, err = NewOpenAIProvider(server.URL+"/v1", "TEST_LLM_KEY", server.Client()).Complete(context.Background(), "m", req)
+	require.NoError(t, err)
+	assert.Equal(t, "openai says hi", text)
+
+	_, err = NewAnthropicProvider(server.URL, "TEST_LLM_MISSING_KEY", server.Client()).Complete(context.Background(), "m", req)
+	assert.ErrorIs(t, err, ErrNotConfigured)
+}
@@ -0,0 +1,83 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/humanlayer/humanlayer/hld/config"
+)
+
+// Schema constrains a response to a JSON object matching a JSON Schema.
+// Providers that support it enforce the schema natively (Anthropic forced
+// tool use, OpenAI response_format); others only see it in the prompt.
+type Schema struct {
+	Name        string
+	Description string
+	Definition  json.RawMessage
+}
+
+// ErrInvalidJSON is returned when a model's response does not parse into the
+// requested type or fails validation
+var ErrInvalidJSON = errors.New("response was not valid JSON for the schema")
+
+// jsonAttemptsPerModel is how many times each model is asked before falling
+// back to the next one; retries include the previous parse error
+const jsonAttemptsPerModel = 2
+
+// CompleteJSON requests a response matching req.Schema and decodes it into
+// out. Responses that fail to decode, or that validate rejects, are retried
+// with the error fed back to the model, then the next routed model is tried.
+// validate may be nil.
+func (r *Router) CompleteJSON(ctx context.Context, task Task, req Request, out any, validate func() error) (*Response, error) {
+	if req.Schema == nil {
+		return nil, fmt.Errorf("CompleteJSON requires a schema")
+	}
+
+	return r.run(ctx, task, func(provider Provider, route config.LLMRoute) (string, error) {
+		attemptReq := req
+		var lastErr error
+		for attempt := 0; attempt < jsonAttemptsPerModel; attempt++ {
+			text, err := r.complete(ctx, task, provider, route, attemptReq)
+			if err != nil {
+				return "", err
+			}
+
+			lastErr = decodeJSON(text, out, validate)
+			if lastErr == nil {
+				return text, nil
+			}
+			attemptReq.Prompt = req.Prompt + fmt.Sprintf(
+				"\n\nYour previous response could not be used (%v). Respond with only a JSON object matching the %s schema.",
+				lastErr, req.Schema.Name)
+		}
+		return "", lastErr
+	})
+}
+
+// decodeJSON parses a model response, tolerating markdown code fences
+func decodeJSON(text string, out any, validate func() error) error {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "```") {
+		text = strings.TrimPrefix(text, "```json")
+		text = strings.TrimPrefix(text, "```")
+		text = strings.TrimSuffix(text, "```")
+		text = strings.TrimSpace(text)
+	}
+	// Clear anything a previous attempt decoded
+	if v := reflect.ValueOf(out); v.Kind() == reflect.Pointer && !v.IsNil() {
+		v.Elem().SetZero()
+	}
+	if err := json.Unmarshal([]byte(text), out); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+	}
+	if validate != nil {
+		if err := validate(); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedProvider returns its responses in order, recording each prompt
+type scriptedProvider struct {
+	responses []string
+	prompts   []string
+}
+
+func (p *scriptedProvider) Complete(ctx context.Context, model string, req Request) (string, error) {
+	p.prompts = append(p.prompts, req.Prompt)
+	if len(p.responses) == 0 {
+		return "", errors.New("no more responses")
+	}
+	text := p.responses[0]
+	p.responses = p.responses[1:]
+	return text, nil
+}
+
+var testSchema = &Schema{
+	Name:       "greeting",
+	Definition: json.RawMessage(`{"type":"object","properties":{"greeting":{"type":"string"}},"required":["greeting"]}`),
+}
+
+type greeting struct {
+	Greeting string `json:"greeting"`
+}
+
+func TestCompleteJSON(t *testing.T) {
+	t.Run("retries with the parse error", func(t *testing.T) {
+		p := &scriptedProvider{responses: []string{"Sure! Here it is", "```json\n{\"greeting\":\"hi\"}\n```"}}
+		r := NewRouterWithProviders(map[string]Provider{"p": p}, map[Task][]config.LLMRoute{
+			TaskReview: {{Provider: "p", Model: "m"}},
+		})
+
+		var out greeting
+		resp, err := r.CompleteJSON(context.Background(), TaskReview, Request{Prompt: "greet", Schema: testSchema}, &out, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "hi", out.Greeting)
+		assert.Equal(t, "m", resp.Model)
+		require.Len(t, p.prompts, 2)
+		assert.Equal(t, "greet", p.prompts[0])
+		assert.Contains(t, p.prompts[1], "could not be used")
+	})
+
+	t.Run("falls back when validation keeps failing", func(t *testing.T) {
+		bad := &scriptedProvider{responses: []string{`{"greeting":""}`, `{"greeting":""}`}}
+		good := &scriptedProvider{responses: []string{`{"greeting":"hello"}`}}
+		r := NewRouterWithProviders(map[string]Provider{"bad": bad, "good": good}, map[Task][]config.LLMRoute{
+			TaskReview: {{Provider: "bad", Model: "a"}, {Provider: "good", Model: "b"}},
+		})
+
+		var out greeting
+		validate := func() error {
+			if out.Greeting == "" {
+				return errors.New("greeting is empty")
+			}
+			return nil
+		}
+		resp, err := r.CompleteJSON(context.Background(), TaskReview, Request{Prompt: "greet", Schema: testSchema}, &out, validate)
+		require.NoError(t, err)
+		assert.Equal(t, "hello", out.Greeting)
+		assert.Equal(t, "good", resp.Provider)
+		assert.Len(t, bad.prompts, jsonAttemptsPerModel)
+	})
+
+	t.Run("reports invalid JSON when every model fails", func(t *testing.T) {
+		p := &scriptedProvider{responses: []string{"nope", "still nope"}}
+		r := NewRouterWithProviders(map[string]Provider{"p": p}, map[Task][]config.LLMRoute{
+			TaskReview: {{Provider: "p", Model: "m"}},
+		})
+
+		var out greeting
+		_, err := r.CompleteJSON(context.Background(), TaskReview, Request{Prompt: "greet", Schema: testSchema}, &out, nil)
+		assert.ErrorIs(t, err, ErrInvalidJSON)
+	})
+}
+
+func TestAnthropicForcedToolUse(t *testing.T) {
+	t.Setenv("TEST_LLM_KEY", "secret")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, map[string]interface{}{"type": "tool", "name": "greeting"}, body["tool_choice"])
+		assert.Len(t, body["tools"], 1)
+		_, _ = w.Write([]byte(`{"content":[{"type":"text","text":"ignored"},{"type":"tool_use","name":"greeting","input":{"greeting":"hi"}}]}`))
+	}))
+	defer server.Close()
+
+	text, err := NewAnthropicProvider(server.URL, "TEST_LLM_KEY", server.Client()).
+		Complete(context.Background(), "m", Request{Prompt: "greet", Schema: testSchema})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"greeting":"hi"}`, text)
+}
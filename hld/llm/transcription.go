@@ -0,0 +1,119 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/config"
+)
+
+// DefaultTranscriptionModel is OpenAI's Whisper model
+const DefaultTranscriptionModel = "whisper-1"
+
+// Transcriber turns speech into text with one transcription model
+type Transcriber struct {
+	provider *OpenAIProvider
+	model    string
+	language string
+}
+
+// NewTranscriber creates a transcriber for cfg, looking its provider up
+// among the default and configured LLM providers. Only OpenAI-compatible
+// providers serve transcriptions.
+func NewTranscriber(llmConfig config.LLMConfig, cfg config.TranscriptionConfig) (*Transcriber, error) {
+	provider, ok := llmConfig.Providers[cfg.Provider]
+	if !ok {
+		provider, ok = DefaultProviders[cfg.Provider]
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown transcription provider %q", cfg.Provider)
+	}
+	if provider.Type != config.LLMProviderOpenAI {
+		return nil, fmt.Errorf("transcription provider %q must have type %q", cfg.Provider, config.LLMProviderOpenAI)
+	}
+	model := cfg.Model
+	if model == "" {
+		model = DefaultTranscriptionModel
+	}
+	// Long clips take a while on a CPU-bound local server
+	httpClient := &http.Client{Timeout: 2 * time.Minute}
+	return &Transcriber{provider: NewOpenAIProvider(provider.BaseURL, provider.APIKeyEnv, httpClient), model: model, language: cfg.Language}, nil
+}
+
+// Transcribe returns the text spoken in audio; filename's extension tells
+// the server its format
+func (t *Transcriber) Transcribe(ctx context.Context, filename string, audio io.Reader) (string, error) {
+	return t.provider.Transcribe(ctx, t.model, t.language, filename, audio)
+}
+
+// Transcribe calls the /v1/audio/transcriptions endpoint
+func (p *OpenAIProvider) Transcribe(ctx context.Context, model, language, filename string, audio io.Reader) (string, error) {
+	var apiKey string
+	if p.apiKeyEnv != "" {
+		apiKey = os.Getenv(p.apiKeyEnv)
+		if apiKey == "" {
+			return "", fmt.Errorf("%w: %s not set", ErrNotConfigured, p.apiKeyEnv)
+		}
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	fields := map[string]string{"model": model, "response_format": "json"}
+	if language != "" {
+		fields["language"] = language
+	}
+	for name, value := range fields {
+		if err := form.WriteField(name, value); err != nil {
+			return "", fmt.Errorf("failed to build request: %w", err)
+		}
+	}
+	file, err := form.CreateFormFile("file", filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to build request: %w", err)
+	}
+	if _, err := io.Copy(file, audio); err != nil {
+		return "", fmt.Errorf("failed to read audio: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return "", fmt.Errorf("failed to build request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/v1/audio/transcriptions", &body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", form.FormDataContentType())
+	if apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("API request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", &StatusError{StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+
+	var transcription struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(respBody, &transcription); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	return strings.TrimSpace(transcription.Text), nil
+}
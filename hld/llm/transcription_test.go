@@ -0,0 +1,45 @@
+package llm
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranscriber(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/audio/transcriptions", r.URL.Path)
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		assert.Equal(t, "whisper-1", r.FormValue("model"))
+		assert.Equal(t, "en", r.FormValue("language"))
+		file, header, err := r.FormFile("file")
+		require.NoError(t, err)
+		audio, _ := io.ReadAll(file)
+		assert.Equal(t, "memo.m4a", header.Filename)
+		assert.Equal(t, "audio", string(audio))
+		_, _ = w.Write([]byte(`{"text":" Approve it. "}`))
+	}))
+	defer server.Close()
+
+	llmConfig := config.LLMConfig{Providers: map[string]config.LLMProviderConfig{
+		"whisper": {Type: config.LLMProviderOpenAI, BaseURL: server.URL + "/v1"},
+	}}
+	transcriber, err := NewTranscriber(llmConfig, config.TranscriptionConfig{Provider: "whisper", Language: "en"})
+	require.NoError(t, err)
+
+	text, err := transcriber.Transcribe(context.Background(), "memo.m4a", strings.NewReader("audio"))
+	require.NoError(t, err)
+	assert.Equal(t, "Approve it.", text)
+
+	_, err = NewTranscriber(llmConfig, config.TranscriptionConfig{Provider: "anthropic"})
+	assert.Error(t, err, "Anthropic has no transcription API")
+	_, err = NewTranscriber(llmConfig, config.TranscriptionConfig{Provider: "missing"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,371 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ToolNamespaceSeparator joins a downstream server name and tool name
+const ToolNamespaceSeparator = "__"
+
+// ToolPolicy controls how calls to a downstream tool are gated
+type ToolPolicy string
+
+const (
+	// ToolPolicyApprove requires a human approval before the call is forwarded
+	ToolPolicyApprove ToolPolicy = "approve"
+	// ToolPolicyAllow forwards the call without asking
+	ToolPolicyAllow ToolPolicy = "allow"
+	// ToolPolicyDeny rejects the call without forwarding it
+	ToolPolicyDeny ToolPolicy = "deny"
+)
+
+// DownstreamClient is the subset of the MCP client used to talk to a downstream server
+type DownstreamClient interface {
+	Initialize(ctx context.Context, request mcp.InitializeRequest) (*mcp.InitializeResult, error)
+	ListTools(ctx context.Context, request mcp.ListToolsRequest) (*mcp.ListToolsResult, error)
+	CallTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
+	Close() error
+}
+
+// downstream tracks a connected downstream MCP server
+type downstream struct {
+	name   string
+	config config.MCPDownstreamServer
+	client DownstreamClient
+	tools  []mcp.Tool
+}
+
+// DownstreamManager connects to downstream MCP servers and exposes their tools
+// through the daemon's MCP server with the approval layer in between
+type DownstreamManager struct {
+	configs map[string]config.MCPDownstreamServer
+	dial    func(ctx context.Context, name string, cfg config.MCPDownstreamServer) (DownstreamClient, error)
+
+	mu      sync.RWMutex
+	servers map[string]*downstream
+}
+
+// NewDownstreamManager creates a manager for the given downstream server configurations
+func NewDownstreamManager(configs map[string]config.MCPDownstreamServer) *DownstreamManager {
+	return &DownstreamManager{
+		configs: configs,
+		dial:    dialDownstream,
+		servers: make(map[string]*downstream),
+	}
+}
+
+// NewDownstreamManagerWithClients creates a manager backed by already constructed clients.
+// This is primarily useful for tests and for embedding in-process MCP servers.
+func NewDownstreamManagerWithClients(configs map[string]config.MCPDownstreamServer, clients map[string]DownstreamClient) *DownstreamManager {
+	m := NewDownstreamManager(configs)
+	m.dial = func(_ context.Context, name string, _ config.MCPDownstreamServer) (DownstreamClient, error) {
+		c, ok := clients[name]
+		if !ok {
+			return nil, fmt.Errorf("no client registered for %s", name)
+		}
+		return c, nil
+	}
+	return m
+}
+
+// dialDownstream creates and starts a real MCP client for the configuration
+func dialDownstream(ctx context.Context, name string, cfg config.MCPDownstreamServer) (DownstreamClient, error) {
+	if cfg.URL != "" {
+		c, err := client.NewStreamableHttpClient(cfg.URL, transport.WithHTTPHeaders(cfg.Headers))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+		}
+		if err := c.Start(ctx); err != nil {
+			return nil, fmt.Errorf("failed to start HTTP client: %w", err)
+		}
+		return c, nil
+	}
+
+	env := make([]string, 0, len(cfg.Env))
+	for k, v := range cfg.Env {
+		env = append(env, k+"="+v)
+	}
+	c, err := client.NewStdioMCPClient(cfg.Command, env, cfg.Args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start stdio client: %w", err)
+	}
+	return c, nil
+}
+
+// Connect initializes every configured downstream server and caches its tool list.
+// Servers that fail to connect are logged and skipped so one bad server doesn't
+// prevent the daemon from starting.
+func (m *DownstreamManager) Connect(ctx context.Context) {
+	names := make([]string, 0, len(m.configs))
+	for name := range m.configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		cfg := m.configs[name]
+		ds, err := m.connectOne(ctx, name, cfg)
+		if err != nil {
+			slog.Error("failed to connect to downstream MCP server", "server", name, "error", err)
+			continue
+		}
+
+		m.mu.Lock()
+		m.servers[name] = ds
+		m.mu.Unlock()
+
+		slog.Info("connected to downstream MCP server", "server", name, "tool_count", len(ds.tools))
+	}
+}
+
+func (m *DownstreamManager) connectOne(ctx context.Context, name string, cfg config.MCPDownstreamServer) (*downstream, error) {
+	c, err := m.dial(ctx, name, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	initReq := mcp.InitializeRequest{}
+	initReq.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initReq.Params.ClientInfo = mcp.Implementation{Name: "humanlayer-daemon", Version: "1.0.0"}
+	if _, err := c.Initialize(ctx, initReq); err != nil {
+		_ = c.Close()
+		return nil, fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	result, err := c.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		_ = c.Close()
+		return nil, fmt.Errorf("failed to list tools: %w", err)
+	}
+
+	return &downstream{
+		name:   name,
+		config: cfg,
+		client: c,
+		tools:  result.Tools,
+	}, nil
+}
+
+// Close closes all downstream connections
+func (m *DownstreamManager) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for name, ds := range m.servers {
+		if err := ds.client.Close(); err != nil {
+			slog.Warn("failed to close downstream MCP client", "server", name, "error", err)
+		}
+	}
+	m.servers = make(map[string]*downstream)
+}
+
+// PolicyFor returns the configured policy for a downstream tool
+func (m *DownstreamManager) PolicyFor(serverName, toolName string) ToolPolicy {
+	cfg, ok := m.configs[serverName]
+	if !ok {
+		return ToolPolicyDeny
+	}
+	// Viper lowercases map keys, so match tool names case-insensitively
+	for name, policy := range cfg.ToolPolicies {
+		if strings.EqualFold(name, toolName) {
+			return parseToolPolicy(policy)
+		}
+	}
+	return parseToolPolicy(cfg.DefaultPolicy)
+}
+
+func parseToolPolicy(s string) ToolPolicy {
+	switch ToolPolicy(strings.ToLower(s)) {
+	case ToolPolicyAllow:
+		return ToolPolicyAllow
+	case ToolPolicyDeny:
+		return ToolPolicyDeny
+	default:
+		return ToolPolicyApprove
+	}
+}
+
+// NamespacedToolName returns the name a downstream tool is exposed under
+func NamespacedToolName(serverName, toolName string) string {
+	return serverName + ToolNamespaceSeparator + toolName
+}
+
+// ParseNamespacedToolName splits an exposed tool name into server and tool names
+func ParseNamespacedToolName(name string) (serverName, toolName string, ok bool) {
+	serverName, toolName, ok = strings.Cut(name, ToolNamespaceSeparator)
+	if !ok || serverName == "" || toolName == "" {
+		return "", "", false
+	}
+	return serverName, toolName, true
+}
+
+// DownstreamHooks are invoked around each forwarded downstream tool call
+type DownstreamHooks struct {
+	// Gate decides whether a call with the approve policy may proceed
+	Gate func(ctx context.Context, toolUseID, serverName, toolName string, args map[string]any) (approved bool, comment string, err error)
+	// Result observes the outcome of a forwarded call; may be nil
+	Result func(ctx context.Context, toolUseID, serverName, toolName string, result *mcp.CallToolResult)
+}
+
+// ServerTools returns the namespaced downstream tools, skipping tools whose policy is deny
+func (m *DownstreamManager) ServerTools(hooks DownstreamHooks) []server.ServerTool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	names := make([]string, 0, len(m.servers))
+	for name := range m.servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var tools []server.ServerTool
+	for _, name := range names {
+		ds := m.servers[name]
+		for _, tool := range ds.tools {
+			if m.PolicyFor(ds.name, tool.Name) == ToolPolicyDeny {
+				continue
+			}
+			exposed := tool
+			exposed.Name = NamespacedToolName(ds.name, tool.Name)
+			if exposed.Description != "" {
+				exposed.Description = fmt.Sprintf("[%s] %s", ds.name, tool.Description)
+			}
+			tools = append(tools, server.ServerTool{
+				Tool:    exposed,
+				Handler: m.handlerFor(ds, tool.Name, hooks),
+			})
+		}
+	}
+	return tools
+}
+
+// handlerFor builds the MCP handler that gates and forwards a downstream tool call
+func (m *DownstreamManager) handlerFor(ds *downstream, toolName string, hooks DownstreamHooks) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := request.GetArguments()
+		// Downstream calls are not tool_use blocks in the conversation, so mint an ID
+		toolUseID := "mcp-" + uuid.New().String()
+
+		switch m.PolicyFor(ds.name, toolName) {
+		case ToolPolicyDeny:
+			return mcp.NewToolResultError(fmt.Sprintf("tool %s is denied by policy", NamespacedToolName(ds.name, toolName))), nil
+		case ToolPolicyApprove:
+			approved, comment, err := hooks.Gate(ctx, toolUseID, ds.name, toolName, args)
+			if err != nil {
+				return nil, err
+			}
+			if !approved {
+				msg := "Denied by reviewer"
+				if comment != "" {
+					msg = fmt.Sprintf("Denied by reviewer: %s", comment)
+				}
+				return mcp.NewToolResultError(msg), nil
+			}
+		}
+
+		forward := mcp.CallToolRequest{}
+		forward.Params.Name = toolName
+		forward.Params.Arguments = args
+		result, err := ds.client.CallTool(ctx, forward)
+		if err != nil {
+			slog.Error("downstream MCP tool call failed",
+				"server", ds.name,
+				"tool", toolName,
+				"error", err)
+			result = mcp.NewToolResultError(fmt.Sprintf("downstream call failed: %v", err))
+		}
+		if hooks.Result != nil {
+			hooks.Result(ctx, toolUseID, ds.name, toolName, result)
+		}
+		return result, nil
+	}
+}
+
+// RegisterDownstream exposes the manager's tools on the MCP server. Calls with the
+// approve policy create a local approval and block until a decision arrives.
+func (s *MCPServer) RegisterDownstream(m *DownstreamManager) {
+	tools := m.ServerTools(DownstreamHooks{
+		Gate:   s.gateDownstreamCall,
+		Result: s.recordDownstreamResult,
+	})
+	if len(tools) == 0 {
+		return
+	}
+	s.mcpServer.AddTools(tools...)
+	slog.Info("registered downstream MCP tools", "count", len(tools))
+}
+
+// gateDownstreamCall creates an approval for a downstream tool call and waits for the decision
+func (s *MCPServer) gateDownstreamCall(ctx context.Context, toolUseID, serverName, toolName string, args map[string]any) (bool, string, error) {
+	sessionID, _ := ctx.Value(sessionIDKey).(string)
+	if sessionID == "" {
+		return false, "", fmt.Errorf("missing session_id in context")
+	}
+
+	inputJSON, err := json.Marshal(args)
+	if err != nil {
+		return false, "", fmt.Errorf("failed to marshal input: %w", err)
+	}
+
+	decisionChan := make(chan ApprovalDecision, 1)
+	s.pendingApprovals.Store(toolUseID, decisionChan)
+	defer s.pendingApprovals.Delete(toolUseID)
+
+	approval, err := s.approvalManager.CreateApprovalWithToolUseID(ctx, sessionID, NamespacedToolName(serverName, toolName), inputJSON, toolUseID)
+	if err != nil {
+		return false, "", fmt.Errorf("failed to create approval: %w", err)
+	}
+	if approval.Status == "approved" {
+		return true, approval.Comment, nil
+	}
+	if approval.Status == "denied" {
+		return false, approval.Comment, nil
+	}
+
+	select {
+	case decision := <-decisionChan:
+		return decision.Approved, decision.Comment, nil
+	case <-ctx.Done():
+		return false, "", ctx.Err()
+	}
+}
+
+// recordDownstreamResult stores the output of a forwarded downstream call
+func (s *MCPServer) recordDownstreamResult(ctx context.Context, toolUseID, serverName, toolName string, result *mcp.CallToolResult) {
+	sessionID, _ := ctx.Value(sessionIDKey).(string)
+	if sessionID == "" {
+		return
+	}
+	s.recordToolResult(ctx, &store.ToolResult{
+		SessionID: sessionID,
+		ToolUseID: toolUseID,
+		ToolName:  NamespacedToolName(serverName, toolName),
+		Content:   toolResultText(result),
+		IsError:   result.IsError,
+		Source:    store.ToolResultSourceDownstream,
+	})
+}
+
+// toolResultText flattens the text content of a tool result
+func toolResultText(result *mcp.CallToolResult) string {
+	var parts []string
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			parts = append(parts, text.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
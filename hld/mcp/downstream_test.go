@@ -0,0 +1,160 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/humanlayer/humanlayer/hld/approval"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// newEchoServer creates an in-process MCP server with read and write tools
+func newEchoServer() *server.MCPServer {
+	s := server.NewMCPServer("echo", "1.0.0", server.WithToolCapabilities(true))
+	for _, name := range []string{"read_file", "write_file"} {
+		s.AddTool(mcp.NewTool(name, mcp.WithDescription(name)),
+			func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultText("called " + req.Params.Name), nil
+			})
+	}
+	return s
+}
+
+func newTestDownstream(t *testing.T, cfg config.MCPDownstreamServer) *DownstreamManager {
+	t.Helper()
+	c, err := client.NewInProcessClient(newEchoServer())
+	require.NoError(t, err)
+	require.NoError(t, c.Start(context.Background()))
+
+	configs := map[string]config.MCPDownstreamServer{"fs": cfg}
+	m := NewDownstreamManagerWithClients(configs, map[string]DownstreamClient{"fs": c})
+	m.Connect(context.Background())
+	t.Cleanup(m.Close)
+	return m
+}
+
+func callTool(t *testing.T, s *MCPServer, sessionID, name string) *mcp.CallToolResult {
+	t.Helper()
+	c, err := client.NewInProcessClient(s.mcpServer)
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), sessionIDKey, sessionID)
+	require.NoError(t, c.Start(ctx))
+
+	initReq := mcp.InitializeRequest{}
+	initReq.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	_, err = c.Initialize(ctx, initReq)
+	require.NoError(t, err)
+
+	req := mcp.CallToolRequest{}
+	req.Params.Name = name
+	req.Params.Arguments = map[string]any{"path": "README.md"}
+	result, err := c.CallTool(ctx, req)
+	require.NoError(t, err)
+	return result
+}
+
+func resultText(t *testing.T, result *mcp.CallToolResult) string {
+	t.Helper()
+	require.Len(t, result.Content, 1)
+	text, ok := result.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	return text.Text
+}
+
+func TestParseNamespacedToolName(t *testing.T) {
+	serverName, toolName, ok := ParseNamespacedToolName(NamespacedToolName("fs", "read_file"))
+	assert.True(t, ok)
+	assert.Equal(t, "fs", serverName)
+	assert.Equal(t, "read_file", toolName)
+
+	_, _, ok = ParseNamespacedToolName("request_approval")
+	assert.False(t, ok)
+}
+
+func TestDownstreamPolicyFor(t *testing.T) {
+	m := NewDownstreamManager(map[string]config.MCPDownstreamServer{
+		"fs": {
+			Command:       "fs-server",
+			DefaultPolicy: "allow",
+			ToolPolicies:  map[string]string{"write_file": "approve", "rm": "deny"},
+		},
+	})
+
+	assert.Equal(t, ToolPolicyAllow, m.PolicyFor("fs", "read_file"))
+	assert.Equal(t, ToolPolicyApprove, m.PolicyFor("fs", "Write_File"))
+	assert.Equal(t, ToolPolicyDeny, m.PolicyFor("fs", "rm"))
+	assert.Equal(t, ToolPolicyDeny, m.PolicyFor("unknown", "read_file"))
+}
+
+func TestDownstreamToolsAreNamespacedAndFiltered(t *testing.T) {
+	m := newTestDownstream(t, config.MCPDownstreamServer{
+		Command:      "fs-server",
+		ToolPolicies: map[string]string{"write_file": "deny"},
+	})
+
+	tools := m.ServerTools(DownstreamHooks{})
+	require.Len(t, tools, 1)
+	assert.Equal(t, "fs__read_file", tools[0].Tool.Name)
+	assert.Equal(t, "[fs] read_file", tools[0].Tool.Description)
+}
+
+func TestDownstreamAllowForwardsWithoutApproval(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockApprovals := approval.NewMockManager(ctrl)
+
+	s := NewMCPServer(mockApprovals, nil)
+	s.RegisterDownstream(newTestDownstream(t, config.MCPDownstreamServer{Command: "fs-server", DefaultPolicy: "allow"}))
+
+	result := callTool(t, s, "sess-1", "fs__read_file")
+	assert.False(t, result.IsError)
+	assert.Equal(t, "called read_file", resultText(t, result))
+}
+
+func TestDownstreamApproveCreatesApproval(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockApprovals := approval.NewMockManager(ctrl)
+
+	mockApprovals.EXPECT().
+		CreateApprovalWithToolUseID(gomock.Any(), "sess-1", "fs__write_file", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, sessionID, toolName string, input json.RawMessage, toolUseID string) (*store.Approval, error) {
+			assert.JSONEq(t, `{"path":"README.md"}`, string(input))
+			assert.Contains(t, toolUseID, "mcp-")
+			return &store.Approval{ID: "local-1", SessionID: sessionID, Status: store.ApprovalStatusLocalApproved}, nil
+		})
+
+	s := NewMCPServer(mockApprovals, nil)
+	s.RegisterDownstream(newTestDownstream(t, config.MCPDownstreamServer{Command: "fs-server"}))
+
+	result := callTool(t, s, "sess-1", "fs__write_file")
+	assert.False(t, result.IsError)
+	assert.Equal(t, "called write_file", resultText(t, result))
+}
+
+func TestDownstreamDeniedDecisionIsNotForwarded(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockApprovals := approval.NewMockManager(ctrl)
+
+	s := NewMCPServer(mockApprovals, nil)
+	mockApprovals.EXPECT().
+		CreateApprovalWithToolUseID(gomock.Any(), "sess-1", "fs__write_file", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, sessionID, _ string, _ json.RawMessage, toolUseID string) (*store.Approval, error) {
+			ch, ok := s.pendingApprovals.Load(toolUseID)
+			require.True(t, ok)
+			ch.(chan ApprovalDecision) <- ApprovalDecision{Approved: false, Comment: "not now"}
+			return &store.Approval{ID: "local-1", SessionID: sessionID, Status: store.ApprovalStatusLocalPending}, nil
+		})
+
+	s.RegisterDownstream(newTestDownstream(t, config.MCPDownstreamServer{Command: "fs-server"}))
+
+	result := callTool(t, s, "sess-1", "fs__write_file")
+	assert.True(t, result.IsError)
+	assert.Equal(t, "Denied by reviewer: not now", resultText(t, result))
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/humanlayer/humanlayer/hld/approval"
 	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/store"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
@@ -28,11 +29,16 @@ const (
 
 // ApprovalDecision represents the outcome of an approval request
 type ApprovalDecision struct {
-	Approved   bool
-	Comment    string
-	ImagePaths []string
+	Approved    bool
+	Comment     string
+	ImagePaths  []string
+	Constraints *store.ApprovalConstraints
 }
 
+// constraintsMetaKey is the response metadata key carrying the constraints
+// an approval was given
+const constraintsMetaKey = "humanlayer.dev/constraints"
+
 // EncodedImage represents a base64-encoded image
 type EncodedImage struct {
 	MimeType string `json:"mime_type"`
@@ -45,8 +51,12 @@ type MCPServer struct {
 	httpServer       *server.StreamableHTTPServer
 	approvalManager  approval.Manager
 	eventBus         bus.EventBus
+	store            store.ConversationStore
+	results          store.ToolResultStore
+	violations       store.ConstraintViolationStore
 	autoDenyAll      bool
 	pendingApprovals sync.Map // map[string]chan ApprovalDecision
+	heldApprovals    sync.Map // map[string]chan string, hold messages by tool_use_id
 }
 
 // NewMCPServer creates the full MCP server implementation
@@ -86,6 +96,31 @@ func NewMCPServer(approvalManager approval.Manager, eventBus bus.EventBus) *MCPS
 		s.handleRequestApproval,
 	)
 
+	// Add report_tool_result tool so executed tool outputs reach the audit trail
+	s.mcpServer.AddTool(
+		mcp.NewTool("report_tool_result",
+			mcp.WithDescription("Report the output of a tool call after it was executed"),
+			mcp.WithString("tool_use_id",
+				mcp.Description("The tool_use_id of the executed tool call"),
+				mcp.Required(),
+			),
+			mcp.WithString("content",
+				mcp.Description("The output produced by the tool"),
+				mcp.Required(),
+			),
+			mcp.WithString("tool_name",
+				mcp.Description("The name of the executed tool"),
+			),
+			mcp.WithBoolean("is_error",
+				mcp.Description("Whether the tool call failed"),
+			),
+			mcp.WithObject("input",
+				mcp.Description("The input the tool was run with, checked against the constraints it was approved with"),
+			),
+		),
+		s.handleReportToolResult,
+	)
+
 	// Create HTTP server (stateless for now)
 	s.httpServer = server.NewStreamableHTTPServer(
 		s.mcpServer,
@@ -96,6 +131,14 @@ func NewMCPServer(approvalManager approval.Manager, eventBus bus.EventBus) *MCPS
 	return s
 }
 
+// SetStore sets the stores used to capture tool results and the approval
+// constraints they break
+func (s *MCPServer) SetStore(conversationStore store.ConversationStore, results store.ToolResultStore, violations store.ConstraintViolationStore) {
+	s.store = conversationStore
+	s.results = results
+	s.violations = violations
+}
+
 // Start initializes the MCP server's background processes
 func (s *MCPServer) Start(ctx context.Context) {
 	if s.eventBus != nil {
@@ -173,36 +216,12 @@ func (s *MCPServer) handleRequestApproval(ctx context.Context, request mcp.CallT
 		}, nil
 	}
 
-	// Register for event-driven approval resolution
-	decisionChan := make(chan ApprovalDecision, 1)
-	s.pendingApprovals.Store(toolUseID, decisionChan)
-	defer s.pendingApprovals.Delete(toolUseID)
-
-	// Wait for approval decision
-	select {
-	case decision := <-decisionChan:
+	// Check if the session's auto-deny configuration rejected it
+	if approval.Status == "denied" {
 		responseData := map[string]interface{}{
 			"behavior": "deny",
-			"message":  decision.Comment,
-		}
-		if decision.Approved {
-			responseData = map[string]interface{}{
-				"behavior":     "allow",
-				"updatedInput": input,
-			}
+			"message":  approval.Comment,
 		}
-
-		// Include encoded images in the response if present
-		if len(decision.ImagePaths) > 0 {
-			images := encodeImages(decision.ImagePaths)
-			if len(images) > 0 {
-				responseData["images"] = images
-				slog.Info("Including images in MCP response",
-					"tool_use_id", toolUseID,
-					"image_count", len(images))
-			}
-		}
-
 		responseJSON, _ := json.Marshal(responseData)
 
 		return &mcp.CallToolResult{
@@ -213,13 +232,174 @@ func (s *MCPServer) handleRequestApproval(ctx context.Context, request mcp.CallT
 				},
 			},
 		}, nil
+	}
+
+	// Register for event-driven approval resolution
+	decisionChan := make(chan ApprovalDecision, 1)
+	s.pendingApprovals.Store(toolUseID, decisionChan)
+	defer s.pendingApprovals.Delete(toolUseID)
+
+	// Tell the agent when the approver puts the request on hold
+	heldChan := make(chan string, 1)
+	s.heldApprovals.Store(toolUseID, heldChan)
+	defer s.heldApprovals.Delete(toolUseID)
+
+	// Wait for approval decision
+	progress := 0.0
+	for {
+		select {
+		case message := <-heldChan:
+			progress++
+			s.notifyHeld(ctx, request, progress, message)
+
+		case decision := <-decisionChan:
+			responseData := map[string]interface{}{
+				"behavior": "deny",
+				"message":  decision.Comment,
+			}
+			if decision.Approved {
+				responseData = map[string]interface{}{
+					"behavior":     "allow",
+					"updatedInput": input,
+				}
+			}
+
+			// Include encoded images in the response if present
+			if len(decision.ImagePaths) > 0 {
+				images := encodeImages(decision.ImagePaths)
+				if len(images) > 0 {
+					responseData["images"] = images
+					slog.Info("Including images in MCP response",
+						"tool_use_id", toolUseID,
+						"image_count", len(images))
+				}
+			}
+
+			responseJSON, _ := json.Marshal(responseData)
+
+			result := &mcp.CallToolResult{
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: "text",
+						Text: string(responseJSON),
+					},
+				},
+			}
+			if decision.Approved && decision.Constraints != nil {
+				result.Meta = mcp.NewMetaFromMap(map[string]any{constraintsMetaKey: decision.Constraints})
+			}
+			return result, nil
 
-	// For the moment, we don't timeout approvals, but in the future
-	// may choose to add a timeout or determine otherwise for resumed sessions
-	// case <-time.After(5 * time.Minute):
-	// 	return nil, fmt.Errorf("approval timeout")
-	case <-ctx.Done():
-		return nil, ctx.Err()
+		// For the moment, we don't timeout approvals, but in the future
+		// may choose to add a timeout or determine otherwise for resumed sessions
+		// case <-time.After(5 * time.Minute):
+		// 	return nil, fmt.Errorf("approval timeout")
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (s *MCPServer) handleReportToolResult(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	sessionID, _ := ctx.Value(sessionIDKey).(string)
+	if sessionID == "" {
+		return nil, fmt.Errorf("missing session_id in context")
+	}
+
+	toolUseID := request.GetString("tool_use_id", "")
+	if toolUseID == "" {
+		return mcp.NewToolResultError("tool_use_id is required"), nil
+	}
+
+	result := &store.ToolResult{
+		SessionID: sessionID,
+		ToolUseID: toolUseID,
+		ToolName:  request.GetString("tool_name", ""),
+		Content:   request.GetString("content", ""),
+		IsError:   request.GetBool("is_error", false),
+		Source:    store.ToolResultSourceReported,
+	}
+	if !s.recordToolResult(ctx, result) {
+		return mcp.NewToolResultError("failed to record tool result"), nil
+	}
+	s.checkConstraints(ctx, result, request.GetArguments()["input"])
+
+	return mcp.NewToolResultText("recorded"), nil
+}
+
+// recordToolResult stores a tool result and publishes an event; returns false if it could not be stored
+func (s *MCPServer) recordToolResult(ctx context.Context, result *store.ToolResult) bool {
+	if s.results == nil {
+		slog.Debug("no store configured, dropping tool result", "tool_use_id", result.ToolUseID)
+		return false
+	}
+
+	if err := s.results.StoreToolResult(ctx, result); err != nil {
+		slog.Error("failed to store tool result",
+			"session_id", result.SessionID,
+			"tool_use_id", result.ToolUseID,
+			"error", err)
+		return false
+	}
+
+	if s.eventBus != nil {
+		s.eventBus.Publish(bus.Event{
+			Type: bus.EventToolResultReported,
+			Data: map[string]interface{}{
+				"session_id":  result.SessionID,
+				"tool_use_id": result.ToolUseID,
+				"approval_id": result.ApprovalID,
+				"tool_name":   result.ToolName,
+				"is_error":    result.IsError,
+				"source":      result.Source,
+			},
+		})
+	}
+	return true
+}
+
+// checkConstraints records how a reported tool call broke the constraints
+// it was approved with. Without a reported input, the approved input is
+// checked.
+func (s *MCPServer) checkConstraints(ctx context.Context, result *store.ToolResult, input any) {
+	if result.ApprovalID == "" {
+		return
+	}
+	approved, err := s.store.GetApproval(ctx, result.ApprovalID)
+	if err != nil || approved.Constraints == nil {
+		return
+	}
+	ranWith := approved.ToolInput
+	if input != nil {
+		if encoded, err := json.Marshal(input); err == nil {
+			ranWith = encoded
+		}
+	}
+	for _, violation := range approval.CheckConstraints(approved.Constraints, ranWith, result.CreatedAt) {
+		violation.ApprovalID = approved.ID
+		violation.SessionID = result.SessionID
+		violation.ToolUseID = result.ToolUseID
+		if err := s.violations.RecordConstraintViolation(ctx, &violation); err != nil {
+			slog.Error("failed to record constraint violation", "approval_id", approved.ID, "error", err)
+			continue
+		}
+		slog.Warn("tool call broke its approval constraints",
+			"approval_id", approved.ID,
+			"tool_use_id", result.ToolUseID,
+			"kind", violation.Kind,
+			"detail", violation.Detail)
+		if s.eventBus != nil {
+			s.eventBus.Publish(bus.Event{
+				Type: bus.EventApprovalConstraintViolated,
+				Data: map[string]interface{}{
+					"approval_id": approved.ID,
+					"session_id":  result.SessionID,
+					"tool_use_id": result.ToolUseID,
+					"kind":        violation.Kind,
+					"detail":      violation.Detail,
+				},
+			})
+		}
 	}
 }
 
@@ -241,10 +421,10 @@ func (s *MCPServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	s.httpServer.ServeHTTP(w, r)
 }
 
-// listenForApprovalDecisions listens for approval resolution events and notifies waiting handlers
+// listenForApprovalDecisions listens for approval resolution and hold events and notifies waiting handlers
 func (s *MCPServer) listenForApprovalDecisions(ctx context.Context) {
 	sub := s.eventBus.Subscribe(ctx, bus.EventFilter{
-		Types: []bus.EventType{bus.EventApprovalResolved},
+		Types: []bus.EventType{bus.EventApprovalResolved, bus.EventApprovalHeld},
 	})
 
 	for {
@@ -257,9 +437,15 @@ func (s *MCPServer) listenForApprovalDecisions(ctx context.Context) {
 				slog.Info("MCP approval listener channel closed")
 				return
 			}
+			if event.Type == bus.EventApprovalHeld {
+				s.forwardHold(event)
+				continue
+			}
+
 			toolUseID, _ := event.Data["tool_use_id"].(string)
 			approved, _ := event.Data["approved"].(bool)
 			comment, _ := event.Data["response_text"].(string)
+			constraints, _ := event.Data["constraints"].(*store.ApprovalConstraints)
 
 			// Extract image paths if present
 			var imagePaths []string
@@ -283,9 +469,10 @@ func (s *MCPServer) listenForApprovalDecisions(ctx context.Context) {
 			if ch, ok := s.pendingApprovals.Load(toolUseID); ok {
 				select {
 				case ch.(chan ApprovalDecision) <- ApprovalDecision{
-					Approved:   approved,
-					Comment:    comment,
-					ImagePaths: imagePaths,
+					Approved:    approved,
+					Comment:     comment,
+					ImagePaths:  imagePaths,
+					Constraints: constraints,
 				}:
 					slog.Info("Sent approval decision", "tool_use_id", toolUseID, "approved", approved, "image_count", len(imagePaths))
 				default:
@@ -337,3 +524,39 @@ func detectMimeType(path string) string {
 		return ""
 	}
 }
+
+// forwardHold passes a hold on an approval to the handler waiting on it
+func (s *MCPServer) forwardHold(event bus.Event) {
+	toolUseID, _ := event.Data["tool_use_id"].(string)
+	ch, ok := s.heldApprovals.Load(toolUseID)
+	if toolUseID == "" || !ok {
+		return
+	}
+	reason, _ := event.Data["reason"].(string)
+	message := "The approver put this request on hold: " + reason
+	if resumeAt, _ := event.Data["resume_at"].(string); resumeAt != "" {
+		message += fmt.Sprintf(" (until %s)", resumeAt)
+	}
+	message += ". It is still pending."
+	select {
+	case ch.(chan string) <- message:
+	default:
+		slog.Warn("hold channel full", "tool_use_id", toolUseID)
+	}
+}
+
+// notifyHeld tells the agent its approval request is on hold, as a progress
+// notification. Clients that didn't ask for progress aren't told.
+func (s *MCPServer) notifyHeld(ctx context.Context, request mcp.CallToolRequest, progress float64, message string) {
+	if request.Params.Meta == nil || request.Params.Meta.ProgressToken == nil {
+		return
+	}
+	err := s.mcpServer.SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+		"progressToken": request.Params.Meta.ProgressToken,
+		"progress":      progress,
+		"message":       message,
+	})
+	if err != nil {
+		slog.Debug("failed to send hold notification", "error", err)
+	}
+}
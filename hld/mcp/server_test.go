@@ -0,0 +1,179 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/approval"
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRequestApprovalSurvivesHold(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), sessionIDKey, "sess-1"))
+	defer cancel()
+	ctrl := gomock.NewController(t)
+	mockApprovals := approval.NewMockManager(ctrl)
+	eventBus := bus.NewEventBus()
+	s := NewMCPServer(mockApprovals, eventBus)
+	s.Start(ctx)
+
+	toolUseID := "toolu_1"
+	mockApprovals.EXPECT().
+		CreateApprovalWithToolUseID(gomock.Any(), "sess-1", "Bash", gomock.Any(), toolUseID).
+		Return(&store.Approval{ID: "appr-1", SessionID: "sess-1", ToolUseID: &toolUseID, Status: store.ApprovalStatusLocalPending}, nil)
+
+	c, err := client.NewInProcessClient(s.mcpServer)
+	require.NoError(t, err)
+	require.NoError(t, c.Start(ctx))
+	initReq := mcp.InitializeRequest{}
+	initReq.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	_, err = c.Initialize(ctx, initReq)
+	require.NoError(t, err)
+
+	go func() {
+		// Hold the approval once the handler waits on it, then approve it
+		for {
+			if _, ok := s.heldApprovals.Load(toolUseID); ok && eventBus.GetSubscriberCount() > 0 {
+				break
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		eventBus.Publish(bus.Event{Type: bus.EventApprovalHeld, Data: map[string]interface{}{
+			"approval_id": "appr-1", "tool_use_id": toolUseID, "reason": "Waiting on the change freeze", "resume_at": "2026-01-05T09:00:00Z",
+		}})
+		eventBus.Publish(bus.Event{Type: bus.EventApprovalResolved, Data: map[string]interface{}{
+			"approval_id": "appr-1", "tool_use_id": toolUseID, "approved": true,
+		}})
+	}()
+
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "request_approval"
+	req.Params.Arguments = map[string]any{"tool_name": "Bash", "input": map[string]any{"command": "make deploy"}, "tool_use_id": toolUseID}
+	req.Params.Meta = &mcp.Meta{ProgressToken: "progress-1"}
+	result, err := c.CallTool(ctx, req)
+	require.NoError(t, err)
+
+	var response map[string]any
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &response))
+	assert.Equal(t, "allow", response["behavior"])
+}
+
+func TestForwardHold(t *testing.T) {
+	s := NewMCPServer(nil, nil)
+	ch := make(chan string, 1)
+	s.heldApprovals.Store("toolu_1", ch)
+
+	s.forwardHold(bus.Event{Type: bus.EventApprovalHeld, Data: map[string]interface{}{
+		"approval_id": "appr-1", "tool_use_id": "toolu_1", "reason": "Waiting on the change freeze", "resume_at": "2026-01-05T09:00:00Z",
+	}})
+	assert.Equal(t, "The approver put this request on hold: Waiting on the change freeze (until 2026-01-05T09:00:00Z). It is still pending.", <-ch)
+
+	s.forwardHold(bus.Event{Type: bus.EventApprovalHeld, Data: map[string]interface{}{
+		"approval_id": "appr-1", "tool_use_id": "toolu_1", "reason": "Ask the DBA",
+	}})
+	assert.Equal(t, "The approver put this request on hold: Ask the DBA. It is still pending.", <-ch)
+
+	// Holds of approvals nobody waits on are dropped
+	s.forwardHold(bus.Event{Type: bus.EventApprovalHeld, Data: map[string]interface{}{"tool_use_id": "toolu_2", "reason": "later"}})
+	assert.Empty(t, ch)
+}
+
+func TestRequestApprovalSendsConstraints(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), sessionIDKey, "sess-1"))
+	defer cancel()
+	ctrl := gomock.NewController(t)
+	mockApprovals := approval.NewMockManager(ctrl)
+	eventBus := bus.NewEventBus()
+	s := NewMCPServer(mockApprovals, eventBus)
+	s.Start(ctx)
+
+	toolUseID := "toolu_1"
+	mockApprovals.EXPECT().
+		CreateApprovalWithToolUseID(gomock.Any(), "sess-1", "Edit", gomock.Any(), toolUseID).
+		Return(&store.Approval{ID: "appr-1", SessionID: "sess-1", ToolUseID: &toolUseID, Status: store.ApprovalStatusLocalPending}, nil)
+
+	c, err := client.NewInProcessClient(s.mcpServer)
+	require.NoError(t, err)
+	require.NoError(t, c.Start(ctx))
+	initReq := mcp.InitializeRequest{}
+	initReq.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	_, err = c.Initialize(ctx, initReq)
+	require.NoError(t, err)
+
+	expiresAt := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	go func() {
+		for {
+			if _, ok := s.pendingApprovals.Load(toolUseID); ok && eventBus.GetSubscriberCount() > 0 {
+				break
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		eventBus.Publish(bus.Event{Type: bus.EventApprovalResolved, Data: map[string]interface{}{
+			"approval_id": "appr-1", "tool_use_id": toolUseID, "approved": true,
+			"constraints": &store.ApprovalConstraints{ExpiresAt: &expiresAt, Files: []string{"main.go"}},
+		}})
+	}()
+
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "request_approval"
+	req.Params.Arguments = map[string]any{"tool_name": "Edit", "input": map[string]any{"file_path": "main.go"}, "tool_use_id": toolUseID}
+	result, err := c.CallTool(ctx, req)
+	require.NoError(t, err)
+
+	require.NotNil(t, result.Meta)
+	encoded, err := json.Marshal(result.Meta.AdditionalFields[constraintsMetaKey])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"expires_at":"2026-01-05T09:00:00Z","files":["main.go"]}`, string(encoded))
+}
+
+func TestReportToolResultRecordsViolations(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), sessionIDKey, "sess-1"))
+	defer cancel()
+	conversationStore := store.NewInMemoryStore()
+	toolUseID := "toolu_1"
+	require.NoError(t, conversationStore.CreateApproval(ctx, &store.Approval{
+		ID: "appr-1", SessionID: "sess-1", ToolUseID: &toolUseID, ToolName: "Edit",
+		ToolInput: json.RawMessage(`{"file_path":"main.go"}`), Status: store.ApprovalStatusLocalPending,
+	}))
+	require.NoError(t, conversationStore.SetApprovalConstraints(ctx, "appr-1", &store.ApprovalConstraints{Files: []string{"main.go"}}))
+	eventBus := bus.NewEventBus()
+	sub := eventBus.Subscribe(ctx, bus.EventFilter{Types: []bus.EventType{bus.EventApprovalConstraintViolated}})
+	s := NewMCPServer(nil, eventBus)
+	s.SetStore(conversationStore, conversationStore, conversationStore)
+
+	c, err := client.NewInProcessClient(s.mcpServer)
+	require.NoError(t, err)
+	require.NoError(t, c.Start(ctx))
+	initReq := mcp.InitializeRequest{}
+	initReq.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	_, err = c.Initialize(ctx, initReq)
+	require.NoError(t, err)
+
+	report := func(input map[string]any) {
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "report_tool_result"
+		req.Params.Arguments = map[string]any{"tool_use_id": toolUseID, "tool_name": "Edit", "content": "ok", "input": input}
+		result, err := c.CallTool(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, "recorded", resultText(t, result))
+	}
+	report(map[string]any{"file_path": "main.go"})
+	report(map[string]any{"file_path": "secrets.env"})
+
+	violations, err := conversationStore.ListConstraintViolations(ctx, "sess-1")
+	require.NoError(t, err)
+	require.Len(t, violations, 1)
+	assert.Equal(t, "appr-1", violations[0].ApprovalID)
+	assert.Equal(t, store.ConstraintViolationFile, violations[0].Kind)
+	assert.Equal(t, "secrets.env is not in the approved file list", violations[0].Detail)
+	event := <-sub.Channel
+	assert.Equal(t, "toolu_1", event.Data["tool_use_id"])
+}
@@ -0,0 +1,397 @@
+// Package memoryfile proposes updates to a repository's agent memory file
+// (CLAUDE.md) from the lessons of completed sessions. A proposal is only a
+// diff until a person approves it; approving writes and commits the file.
+package memoryfile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/humanlayer/humanlayer/hld/decisions"
+	"github.com/humanlayer/humanlayer/hld/gitcmd"
+	"github.com/humanlayer/humanlayer/hld/i18n"
+	"github.com/humanlayer/humanlayer/hld/llm"
+	"github.com/humanlayer/humanlayer/hld/prompts"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/humanlayer/humanlayer/hld/summary"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// DefaultPath is the memory file Claude Code reads at the repository root
+const DefaultPath = "CLAUDE.md"
+
+// maxSessions caps the sessions whose lessons go into one proposal
+const maxSessions = 20
+
+// maxCommands caps the shell commands listed per session
+const maxCommands = 10
+
+// maxFileSize refuses memory files too large to send to the model
+const maxFileSize = 128 * 1024
+
+// maxResultLength truncates a session's final message in its lesson
+const maxResultLength = 1000
+
+// proposeTimeout bounds one proposal, including model fallbacks
+const proposeTimeout = 3 * time.Minute
+
+var (
+	// ErrStale is returned when approving a proposal whose file changed since it was made
+	ErrStale = errors.New("memory file changed since the proposal was made")
+	// ErrNoLessons is returned when there are no completed sessions to learn from
+	ErrNoLessons = errors.New("no completed sessions to learn from")
+	// ErrNotPending is returned when approving or rejecting a resolved proposal
+	ErrNotPending = errors.New("proposal is not pending")
+)
+
+var proposalSchema = llm.Schema{
+	Name:        "memory_file_update",
+	Description: "The updated agent memory file",
+	Definition: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "content": {"type": "string"},
+    "rationale": {"type": "string"}
+  },
+  "required": ["content", "rationale"]
+}`),
+}
+
+type update struct {
+	Content   string `json:"content"`
+	Rationale string `json:"rationale"`
+}
+
+func (u *update) validate() error {
+	if strings.TrimSpace(u.Content) == "" {
+		return errors.New("content must not be empty")
+	}
+	return nil
+}
+
+// promptData are the variables of the memory-file template
+type promptData struct {
+	Repository string
+	Path       string
+	Current    string
+	Lessons    []string
+	Decisions  []string
+	Language   string
+}
+
+// ValidatePath checks that path names a file inside the repository
+func ValidatePath(path string) error {
+	if path == "" || filepath.IsAbs(path) {
+		return fmt.Errorf("path must be relative to the repository root")
+	}
+	clean := filepath.Clean(path)
+	if clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) || strings.HasPrefix(clean, ".git"+string(filepath.Separator)) {
+		return fmt.Errorf("path must stay inside the repository")
+	}
+	return nil
+}
+
+// Proposer drafts, applies and rejects memory file updates
+type Proposer struct {
+	store     store.ConversationStore
+	proposals store.MemoryFileStore
+	decisions store.DecisionStore
+	router    *llm.Router
+	templates *prompts.Set
+	locale    string
+}
+
+// NewProposer creates a memory file proposer that learns from the decision log
+func NewProposer(s store.ConversationStore, proposals store.MemoryFileStore, decisions store.DecisionStore, router *llm.Router, templates *prompts.Set, locale string) *Proposer {
+	return &Proposer{store: s, proposals: proposals, decisions: decisions, router: router, templates: templates, locale: locale}
+}
+
+// Propose drafts an update to the memory file at path in the repository
+// containing workingDir. It learns from sessionIDs, or when none are given,
+// from the repository's sessions completed since the last applied proposal.
+// It returns nil without error when the model suggests no change.
+func (p *Proposer) Propose(ctx context.Context, workingDir, path string, sessionIDs []string) (*store.MemoryFileProposal, error) {
+	if path == "" {
+		path = DefaultPath
+	}
+	if err := ValidatePath(path); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, proposeTimeout)
+	defer cancel()
+
+	repository := decisions.RepoRoot(ctx, workingDir)
+	current, err := readFile(filepath.Join(repository, path))
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := p.lessonSessions(ctx, repository, sessionIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return nil, ErrNoLessons
+	}
+
+	data := promptData{Repository: repository, Path: path, Current: current, Language: i18n.LanguageName(p.locale)}
+	ids := make([]string, 0, len(sessions))
+	for _, sess := range sessions {
+		events, err := p.store.GetSessionConversation(ctx, sess.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get conversation: %w", err)
+		}
+		data.Lessons = append(data.Lessons, lesson(sess, events))
+		ids = append(ids, sess.ID)
+	}
+	recorded, err := p.decisions.ListDecisions(ctx, store.DecisionFilter{Repository: repository, Status: store.DecisionStatusActive})
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range recorded {
+		data.Decisions = append(data.Decisions, "- "+d.Title+": "+d.Decision)
+	}
+	prompt, err := p.templates.Render(prompts.MemoryFile, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var result update
+	if _, err := p.router.CompleteJSON(ctx, llm.TaskSummarization, llm.Request{Prompt: prompt, Schema: &proposalSchema, MaxTokens: 8192}, &result, result.validate); err != nil {
+		return nil, err
+	}
+	proposed := strings.TrimRight(result.Content, "\n") + "\n"
+	if proposed == current {
+		return nil, nil
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(current),
+		B:        difflib.SplitLines(proposed),
+		FromFile: "a/" + path,
+		ToFile:   "b/" + path,
+		Context:  3,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff memory file: %w", err)
+	}
+	proposal := &store.MemoryFileProposal{
+		ID:              uuid.New().String(),
+		Repository:      repository,
+		Path:            path,
+		BaseContent:     current,
+		ProposedContent: proposed,
+		Diff:            diff,
+		Rationale:       strings.TrimSpace(result.Rationale),
+		SessionIDs:      ids,
+		Status:          store.ProposalStatusPending,
+	}
+	if err := p.proposals.CreateMemoryFileProposal(ctx, proposal); err != nil {
+		return nil, err
+	}
+	return proposal, nil
+}
+
+// lessonSessions returns the completed sessions to learn from, newest first
+func (p *Proposer) lessonSessions(ctx context.Context, repository string, sessionIDs []string) ([]*store.Session, error) {
+	var sessions []*store.Session
+	if len(sessionIDs) > 0 {
+		for _, id := range sessionIDs {
+			sess, err := p.store.GetSession(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			if sess.Status == store.SessionStatusCompleted {
+				sessions = append(sessions, sess)
+			}
+		}
+		return sessions, nil
+	}
+
+	var since time.Time
+	applied, err := p.proposals.ListMemoryFileProposals(ctx, repository, store.ProposalStatusApplied)
+	if err != nil {
+		return nil, err
+	}
+	if len(applied) > 0 {
+		since = applied[0].CreatedAt
+	}
+	all, err := p.store.ListSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	roots := make(map[string]string) // working dir -> repository root
+	for _, sess := range all {
+		if sess.Status != store.SessionStatusCompleted || sess.CompletedAt == nil || !sess.CompletedAt.After(since) {
+			continue
+		}
+		root, ok := roots[sess.WorkingDir]
+		if !ok {
+			root = decisions.RepoRoot(ctx, sess.WorkingDir)
+			roots[sess.WorkingDir] = root
+		}
+		if root == repository {
+			sessions = append(sessions, sess)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].CompletedAt.After(*sessions[j].CompletedAt) })
+	if len(sessions) > maxSessions {
+		sessions = sessions[:maxSessions]
+	}
+	return sessions, nil
+}
+
+// lesson describes one session for the prompt: its summary when there is
+// one, and the shell commands it ran
+func lesson(sess *store.Session, events []*store.ConversationEvent) string {
+	var b strings.Builder
+	if s, err := summary.Decode(sess.CompletionSummary); err == nil && s != nil {
+		fmt.Fprintf(&b, "Asked: %s\n", s.Asked)
+		if len(s.Changed) > 0 {
+			fmt.Fprintf(&b, "Changed: %s\n", strings.Join(s.Changed, "; "))
+		}
+		if len(s.FollowUps) > 0 {
+			fmt.Fprintf(&b, "Follow-ups: %s\n", strings.Join(s.FollowUps, "; "))
+		}
+	} else {
+		fmt.Fprintf(&b, "Asked: %s\n", sess.Query)
+		if result := strings.TrimSpace(sess.ResultContent); result != "" {
+			if len(result) > maxResultLength {
+				result = result[:maxResultLength] + "..."
+			}
+			fmt.Fprintf(&b, "Result: %s\n", result)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var commands []string
+	for i := len(events) - 1; i >= 0 && len(commands) < maxCommands; i-- {
+		event := events[i]
+		if event.EventType != store.EventTypeToolCall || event.ToolName != "Bash" || !event.IsCompleted ||
+			event.ApprovalStatus == string(store.ApprovalStatusLocalDenied) {
+			continue
+		}
+		var input struct {
+			Command string `json:"command"`
+		}
+		if json.Unmarshal([]byte(event.ToolInputJSON), &input) != nil || input.Command == "" || seen[input.Command] {
+			continue
+		}
+		seen[input.Command] = true
+		commands = append(commands, input.Command)
+	}
+	if len(commands) > 0 {
+		b.WriteString("Commands run:\n")
+		for i := len(commands) - 1; i >= 0; i-- {
+			fmt.Fprintf(&b, "  $ %s\n", commands[i])
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// readFile returns a file's content, or "" if it doesn't exist
+func readFile(path string) (string, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if info.Size() > maxFileSize {
+		return "", fmt.Errorf("%s is larger than %d bytes", path, maxFileSize)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// Approve writes a pending proposal to the repository and, when commit is
+// set, commits just that file with message. It fails with ErrStale if the
+// file changed since the proposal was made.
+func (p *Proposer) Approve(ctx context.Context, id string, commit bool, message string) (*store.MemoryFileProposal, error) {
+	proposal, err := p.proposals.GetMemoryFileProposal(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if proposal.Status != store.ProposalStatusPending {
+		return nil, ErrNotPending
+	}
+	path := filepath.Join(proposal.Repository, proposal.Path)
+	current, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if current != proposal.BaseContent {
+		return nil, ErrStale
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory for %s: %w", proposal.Path, err)
+	}
+	if err := os.WriteFile(path, []byte(proposal.ProposedContent), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", proposal.Path, err)
+	}
+
+	var commitHash string
+	if commit {
+		if message == "" {
+			message = "Update " + proposal.Path + " with lessons from recent sessions"
+		}
+		commitHash, err = commitFile(ctx, proposal.Repository, proposal.Path, message)
+		if err != nil {
+			// Leave the working tree as it was
+			if proposal.BaseContent == "" {
+				_ = os.Remove(path)
+			} else {
+				_ = os.WriteFile(path, []byte(proposal.BaseContent), 0644)
+			}
+			return nil, err
+		}
+	}
+	if err := p.proposals.ResolveMemoryFileProposal(ctx, id, store.ProposalStatusApplied, commitHash); err != nil {
+		return nil, err
+	}
+	return p.proposals.GetMemoryFileProposal(ctx, id)
+}
+
+// Reject marks a pending proposal as rejected without touching the repository
+func (p *Proposer) Reject(ctx context.Context, id string) error {
+	proposal, err := p.proposals.GetMemoryFileProposal(ctx, id)
+	if err != nil {
+		return err
+	}
+	if proposal.Status != store.ProposalStatusPending {
+		return ErrNotPending
+	}
+	return p.proposals.ResolveMemoryFileProposal(ctx, id, store.ProposalStatusRejected, "")
+}
+
+// commitFile commits only path, leaving anything else staged as it was
+func commitFile(ctx context.Context, repository, path, message string) (string, error) {
+	if _, err := git(ctx, repository, "add", "--", path); err != nil {
+		return "", err
+	}
+	if _, err := git(ctx, repository, "commit", "-m", message, "--", path); err != nil {
+		return "", err
+	}
+	hash, err := git(ctx, repository, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(hash), nil
+}
+
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	out, _, err := gitcmd.Run(ctx, gitcmd.Cmd{Dir: dir, Args: args})
+	return out, err
+}
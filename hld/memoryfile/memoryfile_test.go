@@ -0,0 +1,127 @@
+package memoryfile
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/llm"
+	"github.com/humanlayer/humanlayer/hld/prompts"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func initRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "CLAUDE.md"), []byte("# Project\n\nUse Go.\n"), 0644))
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test"},
+		{"add", "CLAUDE.md"},
+		{"commit", "-q", "-m", "initial"},
+	} {
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	return dir
+}
+
+func newProposer(t *testing.T, dir string, response string) (*Proposer, *llm.FakeProvider) {
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	completedAt := time.Now()
+	require.NoError(t, s.CreateSession(ctx, &store.Session{
+		ID: "sess-1", RunID: "run-1", ClaudeSessionID: "claude-1", Query: "Fix the build", WorkingDir: dir,
+		Status: store.SessionStatusCompleted, CreatedAt: time.Now(), CompletedAt: &completedAt,
+	}))
+	require.NoError(t, s.AddConversationEvent(ctx, &store.ConversationEvent{
+		SessionID: "sess-1", ClaudeSessionID: "claude-1", EventType: store.EventTypeToolCall, ToolID: "t1",
+		ToolName: "Bash", ToolInputJSON: `{"command":"make check"}`, IsCompleted: true,
+	}))
+	provider := &llm.FakeProvider{Response: response}
+	router := llm.NewRouterWithProviders(map[string]llm.Provider{"p": provider}, map[llm.Task][]config.LLMRoute{
+		llm.TaskSummarization: {{Provider: "p", Model: "m"}},
+	})
+	return NewProposer(s, s, s, router, prompts.Default(), ""), provider
+}
+
+func TestProposeAndApprove(t *testing.T) {
+	ctx := context.Background()
+	dir := initRepo(t)
+	p, provider := newProposer(t, dir, `{"content":"# Project\n\nUse Go.\nRun `+"`make check`"+` before committing.","rationale":"Sessions verify with make check"}`)
+
+	proposal, err := p.Propose(ctx, dir, "", nil)
+	require.NoError(t, err)
+	require.NotNil(t, proposal)
+	assert.Equal(t, []string{"sess-1"}, proposal.SessionIDs)
+	assert.Contains(t, proposal.Diff, "+Run `make check` before committing.")
+	assert.Equal(t, store.ProposalStatusPending, proposal.Status)
+	require.Len(t, provider.Prompts(), 1)
+	assert.Contains(t, provider.Prompts()[0], "$ make check")
+	assert.Contains(t, provider.Prompts()[0], "Use Go.")
+
+	// Nothing is written until approved
+	content, err := os.ReadFile(filepath.Join(dir, "CLAUDE.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Project\n\nUse Go.\n", string(content))
+
+	applied, err := p.Approve(ctx, proposal.ID, true, "")
+	require.NoError(t, err)
+	assert.Equal(t, store.ProposalStatusApplied, applied.Status)
+	assert.NotEmpty(t, applied.CommitHash)
+	content, err = os.ReadFile(filepath.Join(dir, "CLAUDE.md"))
+	require.NoError(t, err)
+	assert.Equal(t, proposal.ProposedContent, string(content))
+	out, err := exec.Command("git", "-C", dir, "log", "-1", "--format=%s").Output()
+	require.NoError(t, err)
+	assert.Equal(t, "Update CLAUDE.md with lessons from recent sessions", strings.TrimSpace(string(out)))
+
+	_, err = p.Approve(ctx, proposal.ID, true, "")
+	assert.True(t, errors.Is(err, ErrNotPending))
+
+	// Sessions already learned from aren't used again
+	_, err = p.Propose(ctx, dir, "", nil)
+	assert.True(t, errors.Is(err, ErrNoLessons))
+}
+
+func TestApproveStale(t *testing.T) {
+	ctx := context.Background()
+	dir := initRepo(t)
+	p, _ := newProposer(t, dir, `{"content":"# Project\n\nUse Go 1.24.","rationale":"Version"}`)
+
+	proposal, err := p.Propose(ctx, dir, "", nil)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "CLAUDE.md"), []byte("# Edited by hand\n"), 0644))
+
+	_, err = p.Approve(ctx, proposal.ID, true, "")
+	assert.True(t, errors.Is(err, ErrStale))
+
+	require.NoError(t, p.Reject(ctx, proposal.ID))
+	assert.True(t, errors.Is(p.Reject(ctx, proposal.ID), ErrNotPending))
+}
+
+func TestProposeNoChange(t *testing.T) {
+	dir := initRepo(t)
+	p, _ := newProposer(t, dir, `{"content":"# Project\n\nUse Go.","rationale":"Nothing to add"}`)
+
+	proposal, err := p.Propose(context.Background(), dir, "", nil)
+	require.NoError(t, err)
+	assert.Nil(t, proposal)
+}
+
+func TestValidatePath(t *testing.T) {
+	assert.NoError(t, ValidatePath("CLAUDE.md"))
+	assert.NoError(t, ValidatePath("docs/AGENTS.md"))
+	assert.Error(t, ValidatePath("/etc/passwd"))
+	assert.Error(t, ValidatePath("../CLAUDE.md"))
+	assert.Error(t, ValidatePath(".git/config"))
+}
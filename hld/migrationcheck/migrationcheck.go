@@ -0,0 +1,307 @@
+// Package migrationcheck statically checks database migrations for changes
+// that deserve a second look before they're committed or run: destructive
+// statements, DDL that holds long table locks, and up migrations without a
+// way back down. The checks are heuristics over the SQL text and the
+// conventions of common migration tools, not a SQL parser.
+package migrationcheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Rules a warning can come from
+const (
+	RuleDestructive = "destructive"
+	RuleLock        = "lock"
+	RuleMissingDown = "missing_down"
+)
+
+// Severities
+const (
+	SeverityHigh   = "high"
+	SeverityMedium = "medium"
+)
+
+// maxStatement caps the statement quoted in a warning
+const maxStatement = 200
+
+// Warning is one finding in a migration
+type Warning struct {
+	File      string `json:"file"`
+	Line      int    `json:"line,omitempty"`
+	Rule      string `json:"rule"`
+	Severity  string `json:"severity"`
+	Message   string `json:"message"`
+	Statement string `json:"statement,omitempty"`
+}
+
+var migrationDirs = map[string]bool{"migrations": true, "migration": true, "migrate": true, "versions": true}
+
+var codeExts = map[string]bool{".go": true, ".py": true, ".rb": true, ".js": true, ".ts": true, ".php": true, ".java": true, ".kt": true}
+
+// IsMigration reports whether path looks like a migration: any .sql file,
+// or a source file in a migrations directory
+func IsMigration(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".sql" {
+		return true
+	}
+	if !codeExts[ext] {
+		return false
+	}
+	for _, dir := range strings.Split(filepath.ToSlash(filepath.Dir(path)), "/") {
+		if migrationDirs[strings.ToLower(dir)] {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckFile checks a whole migration file. exists reports whether another
+// file exists, for finding a matching down migration; it may be nil. Down
+// migrations are expected to drop what the up migration created, so they
+// aren't checked.
+func CheckFile(path, content string, exists func(path string) bool) []Warning {
+	if isDown(path) {
+		return nil
+	}
+	warnings := checkText(path, upSection(content))
+	if w := missingDown(path, content, exists); w != nil {
+		warnings = append(warnings, *w)
+	}
+	return warnings
+}
+
+// isDown reports whether path is a down migration
+func isDown(path string) bool {
+	base := filepath.Base(path)
+	return strings.HasSuffix(base, ".down.sql") || base == "down.sql"
+}
+
+// checkText checks SQL, or migration code, without file-level checks
+func checkText(path, text string) []Warning {
+	if isDown(path) {
+		return nil
+	}
+	if strings.EqualFold(filepath.Ext(path), ".sql") {
+		return CheckSQL(path, text)
+	}
+	return checkCode(path, text)
+}
+
+type rule struct {
+	pattern  *regexp.Regexp
+	unless   *regexp.Regexp // the statement is fine if this matches
+	rule     string
+	severity string
+	message  string
+}
+
+func re(pattern string) *regexp.Regexp { return regexp.MustCompile(`(?is)` + pattern) }
+
+var sqlRules = []rule{
+	{pattern: re(`\bDROP\s+(TABLE|SCHEMA|DATABASE)\b`), rule: RuleDestructive, severity: SeverityHigh,
+		message: "Drops a table, schema or database and its data"},
+	{pattern: re(`\bALTER\s+TABLE\b.*\bDROP\s+COLUMN\b`), rule: RuleDestructive, severity: SeverityHigh,
+		message: "Drops a column and its data"},
+	{pattern: re(`\bTRUNCATE\b`), rule: RuleDestructive, severity: SeverityHigh,
+		message: "Deletes every row in the table"},
+	{pattern: re(`\bDELETE\s+FROM\b`), unless: re(`\bWHERE\b`), rule: RuleDestructive, severity: SeverityHigh,
+		message: "Deletes every row: there is no WHERE clause"},
+	{pattern: re(`\bUPDATE\s+\S+\s+SET\b`), unless: re(`\bWHERE\b`), rule: RuleDestructive, severity: SeverityHigh,
+		message: "Updates every row: there is no WHERE clause"},
+	{pattern: re(`\bRENAME\s+(COLUMN\b|TO\b)|\bALTER\s+TABLE\s+\S+\s+RENAME\b`), rule: RuleDestructive, severity: SeverityMedium,
+		message: "Renames a table or column; code still using the old name breaks until it's deployed"},
+	{pattern: re(`\bDROP\s+(VIEW|MATERIALIZED\s+VIEW|TYPE|FUNCTION|TRIGGER|SEQUENCE)\b`), rule: RuleDestructive, severity: SeverityMedium,
+		message: "Drops a database object that other code may depend on"},
+	{pattern: re(`\bCREATE\s+(UNIQUE\s+)?INDEX\b`), unless: re(`\bCONCURRENTLY\b`), rule: RuleLock, severity: SeverityMedium,
+		message: "Blocks writes to the table while the index builds; on Postgres use CREATE INDEX CONCURRENTLY"},
+	{pattern: re(`\bADD\s+(COLUMN\s+)?\S+\s+[^,;]*\bNOT\s+NULL\b`), unless: re(`\bDEFAULT\b|\bADD\s+CONSTRAINT\b`), rule: RuleLock, severity: SeverityHigh,
+		message: "Adds a NOT NULL column without a default, which fails on tables that have rows"},
+	{pattern: re(`\bALTER\s+COLUMN\s+\S+\s+(SET\s+DATA\s+)?TYPE\b|\bMODIFY\s+(COLUMN\s+)?\S+`), rule: RuleLock, severity: SeverityMedium,
+		message: "Changes a column type, which can rewrite the table under an exclusive lock"},
+	{pattern: re(`\bSET\s+NOT\s+NULL\b`), rule: RuleLock, severity: SeverityMedium,
+		message: "SET NOT NULL scans the whole table under an exclusive lock"},
+	{pattern: re(`\bADD\s+(CONSTRAINT\s+\S+\s+)?(FOREIGN\s+KEY|CHECK)\b`), unless: re(`\bNOT\s+VALID\b`), rule: RuleLock, severity: SeverityMedium,
+		message: "Validates every existing row while holding a lock; add it NOT VALID and VALIDATE CONSTRAINT separately"},
+	{pattern: re(`\bVACUUM\s+FULL\b|^\s*CLUSTER\b|\bLOCK\s+TABLE\b`), rule: RuleLock, severity: SeverityMedium,
+		message: "Takes an exclusive lock on the table"},
+	{pattern: re(`\bREINDEX\b`), unless: re(`\bCONCURRENTLY\b`), rule: RuleLock, severity: SeverityMedium,
+		message: "Blocks writes while the index rebuilds; on Postgres use REINDEX CONCURRENTLY"},
+}
+
+// Migration tool helpers for the same changes, for code migrations
+var codeRules = []rule{
+	{pattern: re(`\b(drop_table|op\.drop_table|dropTable|dropTableIfExists|Schema::drop|Schema::dropIfExists)\b`), rule: RuleDestructive, severity: SeverityHigh,
+		message: "Drops a table and its data"},
+	{pattern: re(`\b(remove_column|op\.drop_column|dropColumn|dropColumns)\b`), rule: RuleDestructive, severity: SeverityHigh,
+		message: "Drops a column and its data"},
+	{pattern: re(`\b(rename_column|rename_table|op\.alter_column\([^)]*new_column_name|renameColumn|renameTable)\b`), rule: RuleDestructive, severity: SeverityMedium,
+		message: "Renames a table or column; code still using the old name breaks until it's deployed"},
+	{pattern: re(`\bDROP\s+(TABLE|SCHEMA|DATABASE|COLUMN)\b|\bTRUNCATE\s+(TABLE\s+)?\w`), rule: RuleDestructive, severity: SeverityHigh,
+		message: "Runs SQL that drops or empties a table or column"},
+	{pattern: re(`\bCREATE\s+(UNIQUE\s+)?INDEX\b`), unless: re(`\bCONCURRENTLY\b`), rule: RuleLock, severity: SeverityMedium,
+		message: "Blocks writes to the table while the index builds; on Postgres use CREATE INDEX CONCURRENTLY"},
+}
+
+var (
+	lineComment  = regexp.MustCompile(`--[^\n]*`)
+	blockComment = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	whitespace   = regexp.MustCompile(`\s+`)
+)
+
+// CheckSQL checks each statement in SQL text
+func CheckSQL(file, text string) []Warning {
+	// Blank out comments, keeping newlines so line numbers still line up
+	text = blockComment.ReplaceAllStringFunc(text, func(s string) string {
+		return strings.Repeat("\n", strings.Count(s, "\n"))
+	})
+	text = lineComment.ReplaceAllString(text, "")
+	return checkStatements(file, text)
+}
+
+// checkStatements checks each ;-separated statement in text
+func checkStatements(file, text string) []Warning {
+	var warnings []Warning
+	line := 1
+	for _, statement := range strings.Split(text, ";") {
+		start := line + strings.Count(statement[:len(statement)-len(strings.TrimLeft(statement, " \t\r\n"))], "\n")
+		line += strings.Count(statement, "\n")
+		statement = strings.TrimSpace(statement)
+		if statement == "" {
+			continue
+		}
+		warnings = append(warnings, match(sqlRules, file, start, statement)...)
+	}
+	return warnings
+}
+
+// checkCode checks code migrations line by line
+func checkCode(file, text string) []Warning {
+	var warnings []Warning
+	for i, line := range strings.Split(text, "\n") {
+		warnings = append(warnings, match(codeRules, file, i+1, strings.TrimSpace(line))...)
+	}
+	return warnings
+}
+
+func match(rules []rule, file string, line int, statement string) []Warning {
+	var warnings []Warning
+	for _, r := range rules {
+		if !r.pattern.MatchString(statement) || (r.unless != nil && r.unless.MatchString(statement)) {
+			continue
+		}
+		quoted := whitespace.ReplaceAllString(statement, " ")
+		if len(quoted) > maxStatement {
+			quoted = quoted[:maxStatement] + "..."
+		}
+		warnings = append(warnings, Warning{
+			File: file, Line: line, Rule: r.rule, Severity: r.severity, Message: r.message, Statement: quoted,
+		})
+	}
+	return warnings
+}
+
+var (
+	gooseUp    = regexp.MustCompile(`(?m)^\s*--\s*\+goose\s+Up\b`)
+	gooseDown  = regexp.MustCompile(`(?m)^\s*--\s*\+goose\s+Down\b`)
+	dbmateUp   = regexp.MustCompile(`(?m)^\s*--\s*migrate:up\b`)
+	dbmateDown = regexp.MustCompile(`(?m)^\s*--\s*migrate:down\b`)
+	railsUp    = regexp.MustCompile(`(?m)^\s*def\s+up\b`)
+	railsDown  = regexp.MustCompile(`(?m)^\s*def\s+(down|change)\b`)
+	alembicUp  = regexp.MustCompile(`(?m)^def\s+upgrade\(`)
+	// A downgrade whose body is only pass (or a docstring and pass)
+	alembicNoDown = regexp.MustCompile(`(?m)^def\s+downgrade\([^)]*\)[^:]*:\s*(?:(?:"""[\s\S]*?"""|'''[\s\S]*?''')\s*)?(?:#[^\n]*\s*)*pass\s*$`)
+	knexUp        = regexp.MustCompile(`(?m)\bexports\.up\b|\bexport\s+(async\s+)?function\s+up\b`)
+	knexDown      = regexp.MustCompile(`(?m)\bexports\.down\b|\bexport\s+(async\s+)?function\s+down\b`)
+)
+
+// missingDown reports an up migration with no way to reverse it, following
+// the conventions of golang-migrate, diesel, goose, dbmate, Rails, Alembic
+// and Knex
+func missingDown(path, content string, exists func(string) bool) *Warning {
+	warn := func(message string) *Warning {
+		return &Warning{File: path, Rule: RuleMissingDown, Severity: SeverityMedium, Message: message}
+	}
+	base := filepath.Base(path)
+	switch {
+	case strings.HasSuffix(base, ".up.sql"):
+		down := strings.TrimSuffix(path, ".up.sql") + ".down.sql"
+		if exists != nil && !exists(down) {
+			return warn(fmt.Sprintf("No down migration: expected %s", filepath.Base(down)))
+		}
+	case base == "up.sql":
+		down := filepath.Join(filepath.Dir(path), "down.sql")
+		if exists != nil && !exists(down) {
+			return warn("No down migration: expected down.sql next to up.sql")
+		}
+	case gooseUp.MatchString(content) && !gooseDown.MatchString(content):
+		return warn("No -- +goose Down section")
+	case dbmateUp.MatchString(content) && !dbmateDown.MatchString(content):
+		return warn("No -- migrate:down section")
+	case railsUp.MatchString(content) && !railsDown.MatchString(content):
+		return warn("Defines up without down")
+	case alembicUp.MatchString(content) && alembicNoDown.MatchString(content):
+		return warn("downgrade() does nothing")
+	case knexUp.MatchString(content) && !knexDown.MatchString(content):
+		return warn("Exports up without down")
+	}
+	return nil
+}
+
+// upSection drops the down section of a goose or dbmate migration
+func upSection(content string) string {
+	for _, marker := range []*regexp.Regexp{gooseDown, dbmateDown} {
+		if loc := marker.FindStringIndex(content); loc != nil {
+			return content[:loc[0]]
+		}
+	}
+	return content
+}
+
+var sqlClient = regexp.MustCompile(`\b(psql|mysql|mariadb|sqlite3|sqlcmd|clickhouse-client|cockroach\s+sql)\b`)
+
+// CheckToolInput checks what a tool call would write to a migration, or the
+// SQL a shell command passes to a database client
+func CheckToolInput(toolName string, toolInput json.RawMessage, exists func(path string) bool) []Warning {
+	var input struct {
+		FilePath  string `json:"file_path"`
+		Content   string `json:"content"`
+		NewString string `json:"new_string"`
+		Edits     []struct {
+			NewString string `json:"new_string"`
+		} `json:"edits"`
+		Command string `json:"command"`
+	}
+	if json.Unmarshal(toolInput, &input) != nil {
+		return nil
+	}
+	switch toolName {
+	case "Write":
+		if IsMigration(input.FilePath) {
+			return CheckFile(input.FilePath, input.Content, exists)
+		}
+	case "Edit":
+		if IsMigration(input.FilePath) {
+			return checkText(input.FilePath, input.NewString)
+		}
+	case "MultiEdit":
+		if IsMigration(input.FilePath) {
+			var warnings []Warning
+			for _, edit := range input.Edits {
+				warnings = append(warnings, checkText(input.FilePath, edit.NewString)...)
+			}
+			return warnings
+		}
+	case "Bash":
+		if sqlClient.MatchString(input.Command) {
+			// Not CheckSQL: -- starts a command-line flag here, not a comment
+			return checkStatements("", input.Command)
+		}
+	}
+	return nil
+}
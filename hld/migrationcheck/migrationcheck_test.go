@@ -0,0 +1,106 @@
+package migrationcheck
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rules(warnings []Warning) []string {
+	var out []string
+	for _, w := range warnings {
+		out = append(out, w.Rule+":"+w.Statement)
+	}
+	return out
+}
+
+func TestIsMigration(t *testing.T) {
+	assert.True(t, IsMigration("db/schema.sql"))
+	assert.True(t, IsMigration("db/migrate/20240101_add_users.rb"))
+	assert.True(t, IsMigration("alembic/versions/abc_add.py"))
+	assert.True(t, IsMigration("internal/migrations/00001_init.go"))
+	assert.False(t, IsMigration("internal/migrations/README.md"))
+	assert.False(t, IsMigration("cmd/main.go"))
+}
+
+func TestCheckSQL(t *testing.T) {
+	sql := `-- drop the old table; it's unused
+DROP TABLE legacy_users;
+
+/* multi-line
+   comment */
+ALTER TABLE users DROP COLUMN nickname;
+ALTER TABLE users ALTER COLUMN nickname DROP DEFAULT;
+DELETE FROM sessions;
+DELETE FROM sessions WHERE expired;
+UPDATE users SET active = true;
+CREATE INDEX idx_users_email ON users (email);
+CREATE INDEX CONCURRENTLY idx_users_name ON users (name);
+ALTER TABLE users ADD COLUMN plan text NOT NULL;
+ALTER TABLE users ADD COLUMN tier text NOT NULL DEFAULT 'free';
+ALTER TABLE orders ADD CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id);
+ALTER TABLE orders ADD CONSTRAINT fk_item FOREIGN KEY (item_id) REFERENCES items (id) NOT VALID;
+`
+	warnings := CheckSQL("001.sql", sql)
+	assert.Equal(t, []string{
+		"destructive:DROP TABLE legacy_users",
+		"destructive:ALTER TABLE users DROP COLUMN nickname",
+		"destructive:DELETE FROM sessions",
+		"destructive:UPDATE users SET active = true",
+		"lock:CREATE INDEX idx_users_email ON users (email)",
+		"lock:ALTER TABLE users ADD COLUMN plan text NOT NULL",
+		"lock:ALTER TABLE orders ADD CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id)",
+	}, rules(warnings))
+	require.NotEmpty(t, warnings)
+	assert.Equal(t, 2, warnings[0].Line)
+	assert.Equal(t, 6, warnings[1].Line)
+	assert.Equal(t, SeverityHigh, warnings[0].Severity)
+}
+
+func TestMissingDown(t *testing.T) {
+	exists := func(path string) bool { return path == "db/002_b.down.sql" }
+
+	w := CheckFile("db/001_a.up.sql", "CREATE TABLE a (id int);", exists)
+	require.Len(t, w, 1)
+	assert.Equal(t, RuleMissingDown, w[0].Rule)
+	assert.Contains(t, w[0].Message, "001_a.down.sql")
+	assert.Empty(t, CheckFile("db/002_b.up.sql", "CREATE TABLE b (id int);", exists))
+
+	assert.Len(t, CheckFile("m/001.sql", "-- +goose Up\nCREATE TABLE a (id int);\n", nil), 1)
+	assert.Empty(t, CheckFile("m/001.sql", "-- +goose Up\nCREATE TABLE a (id int);\n-- +goose Down\nDROP TABLE a;\n", nil),
+		"the down section may drop what the up section created")
+	assert.Empty(t, CheckFile("db/001_a.down.sql", "DROP TABLE a;", nil))
+	assert.Len(t, CheckFile("db/migrate/1_add.rb", "class Add < ActiveRecord::Migration[7.0]\n  def up\n    add_column :users, :x, :string\n  end\nend\n", nil), 1)
+	assert.Empty(t, CheckFile("db/migrate/1_add.rb", "  def change\n    add_column :users, :x, :string\n  end\n", nil))
+	assert.Len(t, CheckFile("alembic/versions/a.py", "def upgrade():\n    op.add_column('users', sa.Column('x'))\n\n\ndef downgrade():\n    pass\n", nil), 1)
+}
+
+func TestCheckCode(t *testing.T) {
+	code := "def change\n  remove_column :users, :nickname\n  rename_column :users, :name, :full_name\nend\n"
+	assert.Equal(t, []string{
+		"destructive:remove_column :users, :nickname",
+		"destructive:rename_column :users, :name, :full_name",
+	}, rules(CheckFile("db/migrate/2_cleanup.rb", code, nil)))
+}
+
+func TestCheckToolInput(t *testing.T) {
+	input := func(v any) json.RawMessage {
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+		return data
+	}
+
+	w := CheckToolInput("Write", input(map[string]string{"file_path": "/repo/db/3.up.sql", "content": "TRUNCATE events;"}), func(string) bool { return true })
+	assert.Equal(t, []string{"destructive:TRUNCATE events"}, rules(w))
+
+	w = CheckToolInput("Edit", input(map[string]string{"file_path": "/repo/db/3.up.sql", "old_string": "x", "new_string": "DROP TABLE t;"}), nil)
+	assert.Len(t, w, 1, "edits aren't checked for a down migration")
+
+	w = CheckToolInput("Bash", input(map[string]string{"command": `psql --dbname app -c "DELETE FROM jobs"`}), nil)
+	assert.Equal(t, []string{`destructive:psql --dbname app -c "DELETE FROM jobs"`}, rules(w))
+
+	assert.Empty(t, CheckToolInput("Write", input(map[string]string{"file_path": "/repo/main.go", "content": "DROP TABLE x"}), nil))
+	assert.Empty(t, CheckToolInput("Bash", input(map[string]string{"command": "grep 'DROP TABLE' -r ."}), nil))
+}
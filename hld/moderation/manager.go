@@ -0,0 +1,71 @@
+package moderation
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/humanlayer/humanlayer/hld/approval"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// Manager is an approval manager that checks decision comments before they
+// reach the agent
+type Manager struct {
+	approval.Manager
+	filter *Filter
+	store  store.ConversationStore
+}
+
+// NewManager wraps manager so that decisions with blocked comments are
+// refused. Wrap it before the denial expander so expansions are checked too.
+func NewManager(manager approval.Manager, filter *Filter, s store.ConversationStore) *Manager {
+	return &Manager{Manager: manager, filter: filter, store: s}
+}
+
+// ApproveToolCall approves a tool call unless its comment is blocked
+func (m *Manager) ApproveToolCall(ctx context.Context, id string, comment string, imagePaths []string) error {
+	if err := m.check(ctx, id, comment); err != nil {
+		return err
+	}
+	return m.Manager.ApproveToolCall(ctx, id, comment, imagePaths)
+}
+
+// DenyToolCall denies a tool call unless its reason is blocked. A blocked
+// expansion of the reason is dropped, and the reason sent as written.
+func (m *Manager) DenyToolCall(ctx context.Context, id string, reason string, imagePaths []string) error {
+	if err := m.check(ctx, id, reason); err != nil {
+		return err
+	}
+	if a, err := m.store.GetApproval(ctx, id); err == nil && a.CommentExpansion != "" && a.Status == store.ApprovalStatusLocalPending {
+		err := m.filter.Check(ctx, Content{
+			Kind:       store.ModerationDenialExpansion,
+			SessionID:  a.SessionID,
+			ApprovalID: id,
+			Text:       a.CommentExpansion,
+		})
+		if err != nil {
+			if err := m.store.SetApprovalCommentExpansion(ctx, id, ""); err != nil {
+				slog.Warn("failed to drop blocked denial expansion", "approval_id", id, "error", err)
+			}
+		}
+	}
+	return m.Manager.DenyToolCall(ctx, id, reason, imagePaths)
+}
+
+// check checks a decision comment. Approvals that can't be loaded are left
+// for the wrapped manager to report.
+func (m *Manager) check(ctx context.Context, id, comment string) error {
+	if comment == "" {
+		return nil
+	}
+	a, err := m.store.GetApproval(ctx, id)
+	if err != nil || a.Status != store.ApprovalStatusLocalPending {
+		return nil
+	}
+	return m.filter.Check(ctx, Content{
+		Kind:       store.ModerationApprovalComment,
+		SessionID:  a.SessionID,
+		ApprovalID: id,
+		Text:       comment,
+	})
+}
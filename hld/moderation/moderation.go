@@ -0,0 +1,262 @@
+// Package moderation is the content safety filter. It checks text before
+// the daemon sends it somewhere it can't be taken back: approvers' comments
+// and canned responses going to agents, and AI-written text going to
+// notification channels. Text is blocked when it matches a configured
+// pattern or, if the policy asks, when the moderation model flags it. Each
+// block is recorded for admins to review.
+package moderation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/feedback"
+	"github.com/humanlayer/humanlayer/hld/llm"
+	"github.com/humanlayer/humanlayer/hld/prompts"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// Where a block came from
+const (
+	SourcePattern = "pattern"
+	SourceModel   = "model"
+)
+
+// checkTimeout bounds one model check, including fallbacks. An approver or
+// a notification is waiting on it.
+const checkTimeout = 20 * time.Second
+
+// maxContent truncates the text kept with a block
+const maxContent = 4000
+
+// ErrBlocked is wrapped by the error returned for blocked text
+var ErrBlocked = errors.New("blocked by the content safety filter")
+
+// BlockedError says why text was blocked
+type BlockedError struct {
+	Kind   string
+	Source string
+	Reason string
+}
+
+func (e *BlockedError) Error() string {
+	return fmt.Sprintf("%s %s: %s", strings.ReplaceAll(e.Kind, "_", " "), ErrBlocked, e.Reason)
+}
+
+func (e *BlockedError) Unwrap() error {
+	return ErrBlocked
+}
+
+// Content is text to check and what it belongs to
+type Content struct {
+	// Kind is one of the store.Moderation* kinds
+	Kind       string
+	SessionID  string
+	ApprovalID string
+	// WorkingDir picks the workspace policy; when empty it's looked up from
+	// the session
+	WorkingDir string
+	Text       string
+}
+
+var moderationSchema = llm.Schema{
+	Name:        "moderation",
+	Description: "Decide whether text is safe to send",
+	Definition: []byte(`{
+  "type": "object",
+  "properties": {
+    "flagged": {"type": "boolean"},
+    "categories": {"type": "array", "items": {"type": "string"}},
+    "reason": {"type": "string"}
+  },
+  "required": ["flagged", "categories", "reason"]
+}`),
+}
+
+type verdict struct {
+	Flagged    bool     `json:"flagged"`
+	Categories []string `json:"categories"`
+	Reason     string   `json:"reason"`
+}
+
+// promptData are the variables of the moderation template
+type promptData struct {
+	Kind       string
+	Text       string
+	Categories []string
+}
+
+type pattern struct {
+	source string
+	re     *regexp.Regexp
+}
+
+// policy is a compiled config.ContentSafetyPolicy
+type policy struct {
+	enabled    bool
+	patterns   []pattern
+	model      bool
+	categories []string
+	failClosed bool
+}
+
+func compile(p config.ContentSafetyPolicy) policy {
+	compiled := policy{enabled: p.Enabled, model: p.Model, categories: p.Categories, failClosed: p.FailClosed}
+	if len(compiled.categories) == 0 {
+		compiled.categories = config.DefaultContentSafetyCategories
+	}
+	for _, source := range p.Patterns {
+		if re, err := regexp.Compile("(?i)" + source); err == nil {
+			compiled.patterns = append(compiled.patterns, pattern{source, re})
+		}
+	}
+	return compiled
+}
+
+type workspace struct {
+	path   string
+	policy policy
+}
+
+// Filter checks text against the policy for its workspace. A nil Filter
+// lets everything through.
+type Filter struct {
+	defaults   policy
+	workspaces []workspace
+	router     *llm.Router
+	templates  *prompts.Set
+	blocks     store.ModerationStore
+	sessions   store.ConversationStore
+}
+
+// New creates a filter for cfg, or nil when no policy is enabled. Patterns
+// are checked by config validation; invalid ones are skipped.
+func New(cfg config.ContentSafetyConfig, router *llm.Router, templates *prompts.Set, blocks store.ModerationStore, sessions store.ConversationStore) *Filter {
+	f := &Filter{
+		defaults:  compile(cfg.ContentSafetyPolicy),
+		router:    router,
+		templates: templates,
+		blocks:    blocks,
+		sessions:  sessions,
+	}
+	enabled := f.defaults.enabled
+	for _, w := range cfg.Workspaces {
+		f.workspaces = append(f.workspaces, workspace{path: filepath.Clean(w.Path), policy: compile(w.ContentSafetyPolicy)})
+		enabled = enabled || w.Enabled
+	}
+	if !enabled {
+		return nil
+	}
+	return f
+}
+
+// policyFor returns the policy of the innermost workspace containing dir
+func (f *Filter) policyFor(dir string) policy {
+	best, bestLen := f.defaults, -1
+	if dir == "" {
+		return best
+	}
+	dir = filepath.Clean(dir)
+	for _, w := range f.workspaces {
+		if (dir == w.path || strings.HasPrefix(dir, w.path+string(filepath.Separator))) && len(w.path) > bestLen {
+			best, bestLen = w.policy, len(w.path)
+		}
+	}
+	return best
+}
+
+// Check returns a *BlockedError if content may not be sent, after recording
+// the block. Model failures let the text through unless the policy fails
+// closed.
+func (f *Filter) Check(ctx context.Context, content Content) error {
+	if f == nil || strings.TrimSpace(content.Text) == "" {
+		return nil
+	}
+	if content.WorkingDir == "" && content.SessionID != "" && f.sessions != nil {
+		if session, err := f.sessions.GetSession(ctx, content.SessionID); err == nil {
+			content.WorkingDir = session.WorkingDir
+		}
+	}
+	p := f.policyFor(content.WorkingDir)
+	if !p.enabled {
+		return nil
+	}
+
+	for _, pattern := range p.patterns {
+		if pattern.re.MatchString(content.Text) {
+			return f.block(ctx, content, SourcePattern, fmt.Sprintf("matched %q", pattern.source), nil)
+		}
+	}
+	if !p.model || f.router == nil {
+		return nil
+	}
+
+	v, err := f.ask(ctx, content, p.categories)
+	if err != nil {
+		slog.Warn("content safety model check failed", "kind", content.Kind, "session_id", content.SessionID,
+			"fail_closed", p.failClosed, "error", err)
+		if p.failClosed {
+			return f.block(ctx, content, SourceModel, "the moderation model could not check the text", nil)
+		}
+		return nil
+	}
+	if v.Flagged {
+		return f.block(ctx, content, SourceModel, v.Reason, v.Categories)
+	}
+	return nil
+}
+
+// ask has the moderation model judge the text
+func (f *Filter) ask(ctx context.Context, content Content, categories []string) (*verdict, error) {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	prompt, err := f.templates.Render(prompts.Moderation, promptData{
+		Kind:       strings.ReplaceAll(content.Kind, "_", " "),
+		Text:       content.Text,
+		Categories: categories,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var v verdict
+	if _, err := f.router.CompleteJSON(ctx, llm.TaskSummarization, llm.Request{Prompt: prompt, Schema: &moderationSchema, MaxTokens: 256}, &v, nil); err != nil {
+		return nil, err
+	}
+	if v.Flagged && strings.TrimSpace(v.Reason) == "" {
+		v.Reason = "flagged as " + strings.Join(v.Categories, ", ")
+	}
+	return &v, nil
+}
+
+// block records blocked content and returns the error for it
+func (f *Filter) block(ctx context.Context, content Content, source, reason string, categories []string) error {
+	slog.Warn("content safety filter blocked text", "kind", content.Kind, "session_id", content.SessionID,
+		"approval_id", content.ApprovalID, "source", source, "reason", reason)
+	if f.blocks != nil {
+		text := feedback.Redact(content.Text)
+		if len(text) > maxContent {
+			text = strings.ToValidUTF8(text[:maxContent], "") + "…"
+		}
+		err := f.blocks.RecordModerationBlock(ctx, &store.ModerationBlock{
+			Kind:       content.Kind,
+			SessionID:  content.SessionID,
+			ApprovalID: content.ApprovalID,
+			Content:    text,
+			Source:     source,
+			Reason:     reason,
+			Categories: categories,
+		})
+		if err != nil {
+			slog.Warn("failed to record content safety block", "kind", content.Kind, "error", err)
+		}
+	}
+	return &BlockedError{Kind: content.Kind, Source: source, Reason: reason}
+}
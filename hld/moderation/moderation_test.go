@@ -0,0 +1,140 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/approval"
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/llm"
+	"github.com/humanlayer/humanlayer/hld/prompts"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setup(t *testing.T, cfg config.ContentSafetyConfig, provider *llm.FakeProvider) (*Filter, store.Store) {
+	t.Helper()
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	for id, dir := range map[string]string{"sess-app": "/work/app", "sess-docs": "/work/app/docs", "sess-other": "/work/other"} {
+		require.NoError(t, s.CreateSession(ctx, &store.Session{ID: id, RunID: "run-" + id, WorkingDir: dir, CreatedAt: time.Now()}))
+	}
+	router := llm.NewRouterWithProviders(map[string]llm.Provider{"p": provider}, map[llm.Task][]config.LLMRoute{
+		llm.TaskSummarization: {{Provider: "p", Model: "m"}},
+	})
+	return New(cfg, router, prompts.Default(), s, s), s
+}
+
+func TestNewDisabled(t *testing.T) {
+	f, _ := setup(t, config.ContentSafetyConfig{
+		ContentSafetyPolicy: config.ContentSafetyPolicy{Patterns: []string{"idiot"}},
+	}, &llm.FakeProvider{})
+	assert.Nil(t, f)
+	assert.NoError(t, f.Check(context.Background(), Content{Kind: store.ModerationApprovalComment, Text: "idiot"}))
+}
+
+func TestCheckPatternsByWorkspace(t *testing.T) {
+	ctx := context.Background()
+	provider := &llm.FakeProvider{}
+	f, s := setup(t, config.ContentSafetyConfig{
+		ContentSafetyPolicy: config.ContentSafetyPolicy{Enabled: true, Patterns: []string{`\bidiot\b`}},
+		Workspaces: []config.ContentSafetyWorkspace{
+			{Path: "/work/app", ContentSafetyPolicy: config.ContentSafetyPolicy{Enabled: true, Patterns: []string{"project falcon"}}},
+			{Path: "/work/app/docs", ContentSafetyPolicy: config.ContentSafetyPolicy{Enabled: false}},
+		},
+	}, provider)
+	require.NotNil(t, f)
+
+	check := func(sessionID, text string) error {
+		return f.Check(ctx, Content{Kind: store.ModerationApprovalComment, SessionID: sessionID, ApprovalID: "appr-1", Text: text})
+	}
+	err := check("sess-other", "No, you IDIOT")
+	var blocked *BlockedError
+	require.ErrorAs(t, err, &blocked)
+	assert.ErrorIs(t, err, ErrBlocked)
+	assert.Equal(t, SourcePattern, blocked.Source)
+	assert.Equal(t, `matched "\\bidiot\\b"`, blocked.Reason)
+
+	assert.NoError(t, check("sess-app", "No, you idiot"), "the workspace policy replaces the default")
+	assert.Error(t, check("sess-app", "Don't mention Project Falcon to the agent"))
+	assert.NoError(t, check("sess-docs", "Project Falcon"), "the innermost workspace wins")
+	assert.NoError(t, check("sess-other", "use the staging database"))
+	assert.Empty(t, provider.Prompts(), "the model is only asked when the policy says so")
+
+	blocks, err := s.ListModerationBlocks(ctx, true)
+	require.NoError(t, err)
+	require.Len(t, blocks, 2)
+	assert.Equal(t, "sess-other", blocks[0].SessionID)
+	assert.Equal(t, "appr-1", blocks[0].ApprovalID)
+	assert.Equal(t, "No, you IDIOT", blocks[0].Content)
+	assert.Equal(t, SourcePattern, blocks[0].Source)
+}
+
+func TestCheckModel(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.ContentSafetyConfig{ContentSafetyPolicy: config.ContentSafetyPolicy{Enabled: true, Model: true}}
+	content := Content{Kind: store.ModerationSessionSummary, SessionID: "sess-app", Text: "Fixed the parser"}
+
+	provider := &llm.FakeProvider{Response: `{"flagged":true,"categories":["harassment"],"reason":"Insults the reader"}`}
+	f, s := setup(t, cfg, provider)
+	err := f.Check(ctx, content)
+	var blocked *BlockedError
+	require.ErrorAs(t, err, &blocked)
+	assert.Equal(t, SourceModel, blocked.Source)
+	assert.Equal(t, "session summary blocked by the content safety filter: Insults the reader", err.Error())
+	require.Len(t, provider.Prompts(), 1)
+	assert.Contains(t, provider.Prompts()[0], "Check whether this session summary is safe to send")
+	assert.Contains(t, provider.Prompts()[0], "harassment, hate, sexual, violence, self_harm")
+	blocks, err := s.ListModerationBlocks(ctx, false)
+	require.NoError(t, err)
+	require.Len(t, blocks, 1)
+	assert.Equal(t, []string{"harassment"}, blocks[0].Categories)
+
+	f, _ = setup(t, cfg, &llm.FakeProvider{Response: `{"flagged":false,"categories":[],"reason":""}`})
+	assert.NoError(t, f.Check(ctx, content))
+
+	f, _ = setup(t, cfg, &llm.FakeProvider{Err: errors.New("overloaded")})
+	assert.NoError(t, f.Check(ctx, content), "model failures fail open by default")
+
+	cfg.FailClosed = true
+	f, _ = setup(t, cfg, &llm.FakeProvider{Err: errors.New("overloaded")})
+	assert.ErrorIs(t, f.Check(ctx, content), ErrBlocked)
+}
+
+func TestManager(t *testing.T) {
+	ctx := context.Background()
+	f, s := setup(t, config.ContentSafetyConfig{
+		ContentSafetyPolicy: config.ContentSafetyPolicy{Enabled: true, Patterns: []string{"idiot", "you moron"}},
+	}, &llm.FakeProvider{})
+	for _, id := range []string{"appr-1", "appr-2"} {
+		require.NoError(t, s.CreateApproval(ctx, &store.Approval{
+			ID: id, RunID: "run-sess-app", SessionID: "sess-app", Status: store.ApprovalStatusLocalPending,
+			ToolName: "Bash", ToolInput: []byte(`{"command":"make deploy"}`), CreatedAt: time.Now(),
+		}))
+	}
+	m := NewManager(approval.NewManager(s, bus.NewEventBus()), f, s)
+
+	assert.ErrorIs(t, m.ApproveToolCall(ctx, "appr-1", "fine, idiot", nil), ErrBlocked)
+	a, err := s.GetApproval(ctx, "appr-1")
+	require.NoError(t, err)
+	assert.Equal(t, store.ApprovalStatusLocalPending, a.Status, "a blocked decision isn't made")
+	require.NoError(t, m.ApproveToolCall(ctx, "appr-1", "fine", nil))
+
+	// A blocked expansion is dropped and the comment sent as written
+	require.NoError(t, s.SetApprovalCommentExpansion(ctx, "appr-2", "The approver denied this because you moron"))
+	require.NoError(t, m.DenyToolCall(ctx, "appr-2", "not on a Friday", nil))
+	a, err = s.GetApproval(ctx, "appr-2")
+	require.NoError(t, err)
+	assert.Equal(t, store.ApprovalStatusLocalDenied, a.Status)
+	assert.Empty(t, a.CommentExpansion)
+
+	blocks, err := s.ListModerationBlocks(ctx, false)
+	require.NoError(t, err)
+	require.Len(t, blocks, 2)
+	assert.Equal(t, store.ModerationApprovalComment, blocks[0].Kind)
+	assert.Equal(t, store.ModerationDenialExpansion, blocks[1].Kind)
+}
@@ -0,0 +1,134 @@
+// Package owners works out who owns the files a session changes, from the
+// repository's CODEOWNERS file and from git blame of the changed lines, to
+// suggest reviewers and route approvals to the owning team.
+package owners
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/humanlayer/humanlayer/hld/workspace"
+)
+
+// Locations GitHub looks for a CODEOWNERS file in, in order
+var Locations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// maxCodeownersSize is GitHub's limit; larger files are ignored there too
+const maxCodeownersSize = 3 << 20
+
+// Codeowners is a parsed CODEOWNERS file
+type Codeowners struct {
+	rules []rule
+}
+
+type rule struct {
+	pattern *regexp.Regexp
+	owners  []string // Empty: the path is explicitly unowned
+}
+
+// Parse reads CODEOWNERS content. Lines that aren't valid patterns are skipped.
+func Parse(content string) *Codeowners {
+	c := &Codeowners{}
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		pattern, err := compile(fields[0])
+		if err != nil {
+			continue
+		}
+		c.rules = append(c.rules, rule{pattern: pattern, owners: fields[1:]})
+	}
+	return c
+}
+
+// Owners returns the owners of a repository-relative path. As on GitHub,
+// the last matching rule wins.
+func (c *Codeowners) Owners(path string) []string {
+	if c == nil {
+		return nil
+	}
+	path = strings.TrimPrefix(filepath.ToSlash(path), "/")
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if c.rules[i].pattern.MatchString(path) {
+			if len(c.rules[i].owners) == 0 {
+				return nil
+			}
+			return c.rules[i].owners
+		}
+	}
+	return nil
+}
+
+// Load reads the CODEOWNERS file of the repository rooted at root. It
+// returns nil, without an error, if the repository has none.
+func Load(ctx context.Context, host workspace.Host, root string) (*Codeowners, error) {
+	for _, location := range Locations {
+		content, err := host.ReadFile(ctx, filepath.Join(root, location), maxCodeownersSize)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return Parse(string(content)), nil
+	}
+	return nil, nil
+}
+
+// compile turns a CODEOWNERS pattern, which follows .gitignore rules, into a
+// regular expression over slash-separated repository-relative paths
+func compile(pattern string) (*regexp.Regexp, error) {
+	anchored := strings.HasPrefix(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	// A slash anywhere but the end anchors the pattern to the root
+	if strings.Contains(pattern, "/") {
+		anchored = true
+	}
+
+	var b strings.Builder
+	if anchored {
+		b.WriteString("^")
+	} else {
+		b.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch ch := pattern[i]; {
+		case ch == '*' && strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case ch == '*' && strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case ch == '*':
+			b.WriteString("[^/]*")
+		case ch == '?':
+			b.WriteString("[^/]")
+		case ch == '\\' && i+1 < len(pattern):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	// A pattern naming a directory owns everything in it
+	if dirOnly {
+		b.WriteString("/.*$")
+	} else {
+		b.WriteString("(?:/.*)?$")
+	}
+	return regexp.Compile(b.String())
+}
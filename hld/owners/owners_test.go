@@ -0,0 +1,97 @@
+package owners
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/humanlayer/humanlayer/hld/workspace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeownersOwners(t *testing.T) {
+	c := Parse(`# Default owners
+*                   @acme/core
+*.js                @acme/frontend # trailing comment
+/docs/              docs@acme.com
+api/**/handlers     @acme/api
+apps/               @alice
+/build/logs/
+**/generated/*.go   @acme/codegen
+`)
+	tests := map[string][]string{
+		"main.go":                           {"@acme/core"},
+		"web/src/app.js":                    {"@acme/frontend"},
+		"docs/guide.md":                     {"docs@acme.com"},
+		"web/docs/guide.md":                 {"@acme/core"},
+		"api/v1/handlers/users.go":          {"@acme/api"},
+		"api/handlers/users.go":             {"@acme/api"},
+		"apps/web/index.html":               {"@alice"},
+		"services/apps/main.go":             {"@alice"},
+		"build/logs/out.txt":                nil,
+		"pkg/generated/types.go":            {"@acme/codegen"},
+		"pkg/generated/nested/types.go":     {"@acme/core"},
+		"pkg/generated/types.go.orig":       {"@acme/core"},
+		"/docs/absolute-looking-path.md":    {"docs@acme.com"},
+		"apps":                              {"@acme/core"},
+		"web/src/app.json":                  {"@acme/core"},
+		"api/internal/v2/handlers/admin.go": {"@acme/api"},
+	}
+	for path, want := range tests {
+		assert.Equal(t, want, c.Owners(path), path)
+	}
+
+	var none *Codeowners
+	assert.Nil(t, none.Owners("main.go"))
+}
+
+func gitCmd(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	require.NoError(t, err, string(out))
+}
+
+func commitAs(t *testing.T, dir, name, file, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, file)), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(content), 0644))
+	gitCmd(t, dir, "add", file)
+	gitCmd(t, dir, "-c", "user.name="+name, "-c", "user.email="+strings.ToLower(name)+"@example.com", "commit", "-q", "-m", "edit "+file)
+}
+
+func TestSuggest(t *testing.T) {
+	dir := t.TempDir()
+	gitCmd(t, dir, "init", "-q")
+	commitAs(t, dir, "Ada", ".github/CODEOWNERS", "/api/ @acme/api\n")
+	commitAs(t, dir, "Ada", "api/users.go", "one\ntwo\nthree\n")
+	commitAs(t, dir, "Grace", "api/users.go", "one\nTWO\nTHREE\n")
+	commitAs(t, dir, "Linus", "README.md", "readme\n")
+
+	// Uncommitted changes: two of Grace's lines, one of Ada's, and a new file
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "api/users.go"), []byte("ONE\n2\n3\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "NOTES.md"), []byte("new\n"), 0644))
+
+	ctx := context.Background()
+	root, err := Root(ctx, workspace.Local{}, filepath.Join(dir, "api"))
+	require.NoError(t, err)
+	reviewers, err := Suggest(ctx, workspace.Local{}, root, "HEAD", []string{"api/users.go", "NOTES.md"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []Reviewer{
+		{Owner: "@acme/api", Reason: ReasonCodeowners, Files: []string{"api/users.go"}},
+		{Name: "Grace", Email: "grace@example.com", Reason: ReasonBlame, Files: []string{"api/users.go"}, Lines: 2},
+		{Name: "Ada", Email: "ada@example.com", Reason: ReasonBlame, Files: []string{"api/users.go"}, Lines: 1},
+	}, reviewers)
+
+	reviewers, err = Suggest(ctx, workspace.Local{}, root, "HEAD", []string{"api/users.go"}, []string{"Grace@example.com"})
+	require.NoError(t, err)
+	require.Len(t, reviewers, 2, "the excluded author is left out")
+	assert.Equal(t, "Ada", reviewers[1].Name)
+
+	codeowners, err := Load(ctx, workspace.Local{}, t.TempDir())
+	require.NoError(t, err)
+	assert.Nil(t, codeowners, "a repository without CODEOWNERS has no owners")
+}
@@ -0,0 +1,173 @@
+package owners
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/humanlayer/humanlayer/hld/workspace"
+)
+
+// Reasons a reviewer is suggested
+const (
+	ReasonCodeowners = "codeowners"
+	ReasonBlame      = "blame"
+)
+
+const (
+	// maxBlameFiles caps the files blamed for one suggestion
+	maxBlameFiles = 50
+	// maxBlameReviewers caps the blame authors suggested
+	maxBlameReviewers = 5
+)
+
+// Reviewer is someone suggested to review a change
+type Reviewer struct {
+	// Owner is a CODEOWNERS owner: @user, @org/team or an email address
+	Owner string `json:"owner,omitempty"`
+	// Name and Email identify a blame author
+	Name   string   `json:"name,omitempty"`
+	Email  string   `json:"email,omitempty"`
+	Reason string   `json:"reason"`
+	Files  []string `json:"files"`
+	// Lines counts the changed lines a blame author last touched
+	Lines int `json:"lines,omitempty"`
+}
+
+// Root returns the top level of the git repository containing dir
+func Root(ctx context.Context, host workspace.Host, dir string) (string, error) {
+	return git(ctx, host, dir, "rev-parse", "--show-toplevel")
+}
+
+// Suggest suggests reviewers for files, relative to root, changed since
+// base: their CODEOWNERS owners, then the authors of the lines being
+// changed, most lines first. Authors whose email is in exclude, such as
+// whoever is committing, are left out.
+func Suggest(ctx context.Context, host workspace.Host, root, base string, files, exclude []string) ([]Reviewer, error) {
+	codeowners, err := Load(ctx, host, root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CODEOWNERS: %w", err)
+	}
+	reviewers := []Reviewer{}
+	byOwner := make(map[string]int)
+	for _, file := range files {
+		for _, owner := range codeowners.Owners(file) {
+			i, ok := byOwner[owner]
+			if !ok {
+				i = len(reviewers)
+				byOwner[owner] = i
+				reviewers = append(reviewers, Reviewer{Owner: owner, Reason: ReasonCodeowners})
+			}
+			reviewers[i].Files = append(reviewers[i].Files, file)
+		}
+	}
+
+	excluded := make(map[string]bool, len(exclude))
+	for _, email := range exclude {
+		excluded[strings.ToLower(email)] = true
+	}
+	authors, err := blame(ctx, host, root, base, files)
+	if err != nil {
+		return reviewers, err
+	}
+	count := 0
+	for _, author := range authors {
+		if _, owner := byOwner[author.Email]; owner || excluded[strings.ToLower(author.Email)] {
+			continue
+		}
+		if count++; count > maxBlameReviewers {
+			break
+		}
+		reviewers = append(reviewers, author)
+	}
+	return reviewers, nil
+}
+
+var hunkHeader = regexp.MustCompile(`(?m)^@@ -(\d+)(?:,(\d+))? \+`)
+
+// blame finds who last touched the lines changed in each file since base
+func blame(ctx context.Context, host workspace.Host, root, base string, files []string) ([]Reviewer, error) {
+	byEmail := make(map[string]*Reviewer)
+	for i, file := range files {
+		if i >= maxBlameFiles {
+			break
+		}
+		diff, err := git(ctx, host, root, "diff", "-U0", "--no-color", base, "--", file)
+		if err != nil {
+			return nil, err
+		}
+		args := []string{"blame", "--line-porcelain"}
+		for _, m := range hunkHeader.FindAllStringSubmatch(diff, -1) {
+			start, _ := strconv.Atoi(m[1])
+			length := 1
+			if m[2] != "" {
+				length, _ = strconv.Atoi(m[2])
+			}
+			// Pure additions replace no lines, so have nobody to blame
+			if length > 0 {
+				args = append(args, "-L", fmt.Sprintf("%d,+%d", start, length))
+			}
+		}
+		if len(args) == 2 {
+			continue
+		}
+		output, err := git(ctx, host, root, append(args, base, "--", file)...)
+		if err != nil {
+			// New files have no history at base
+			continue
+		}
+		var name string
+		for _, line := range strings.Split(output, "\n") {
+			switch {
+			case strings.HasPrefix(line, "author "):
+				name = strings.TrimPrefix(line, "author ")
+			case strings.HasPrefix(line, "author-mail "):
+				email := strings.Trim(strings.TrimPrefix(line, "author-mail "), "<>")
+				if email == "not.committed.yet" {
+					continue
+				}
+				r, ok := byEmail[email]
+				if !ok {
+					r = &Reviewer{Name: name, Email: email, Reason: ReasonBlame}
+					byEmail[email] = r
+				}
+				r.Lines++
+				if len(r.Files) == 0 || r.Files[len(r.Files)-1] != file {
+					r.Files = append(r.Files, file)
+				}
+			}
+		}
+	}
+
+	authors := make([]Reviewer, 0, len(byEmail))
+	for _, r := range byEmail {
+		authors = append(authors, *r)
+	}
+	sort.Slice(authors, func(i, j int) bool {
+		if authors[i].Lines != authors[j].Lines {
+			return authors[i].Lines > authors[j].Lines
+		}
+		return authors[i].Email < authors[j].Email
+	})
+	return authors, nil
+}
+
+func git(ctx context.Context, host workspace.Host, dir string, args ...string) (string, error) {
+	cmd := host.Command(ctx, dir, "git", args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// MergeBase returns the commit HEAD forked from ref at
+func MergeBase(ctx context.Context, host workspace.Host, dir, ref string) (string, error) {
+	return git(ctx, host, dir, "merge-base", "HEAD", ref)
+}
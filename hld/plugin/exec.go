@@ -0,0 +1,120 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/config"
+)
+
+const defaultExecTimeout = 10 * time.Second
+
+// execRequest is written to an external plugin's stdin
+type execRequest struct {
+	Method string      `json:"method"`
+	Params interface{} `json:"params"`
+}
+
+// execResponse is read from an external plugin's stdout
+type execResponse struct {
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// ExecPlugin runs an external process for each call. The process reads one
+// JSON request ({"method": ..., "params": ...}) from stdin and writes one JSON
+// response ({"result": ...} or {"error": "..."}) to stdout. Methods match the
+// configured capabilities: "notify", "score_risk" and "redact".
+type ExecPlugin struct {
+	name    string
+	config  config.PluginConfig
+	timeout time.Duration
+}
+
+// NewExecPlugin creates a plugin backed by an external command
+func NewExecPlugin(name string, cfg config.PluginConfig) *ExecPlugin {
+	timeout := defaultExecTimeout
+	if cfg.Timeout != "" {
+		if d, err := time.ParseDuration(cfg.Timeout); err == nil {
+			timeout = d
+		}
+	}
+	return &ExecPlugin{name: name, config: cfg, timeout: timeout}
+}
+
+// Name returns the configured plugin name
+func (p *ExecPlugin) Name() string {
+	return p.name
+}
+
+// Supports reports whether the plugin declared the capability
+func (p *ExecPlugin) Supports(capability string) bool {
+	return slices.Contains(p.config.Capabilities, capability)
+}
+
+// Notify sends the notification to the plugin process
+func (p *ExecPlugin) Notify(ctx context.Context, n Notification) error {
+	return p.call(ctx, config.PluginCapabilityNotify, n, nil)
+}
+
+// ScoreRisk asks the plugin process to score a tool call
+func (p *ExecPlugin) ScoreRisk(ctx context.Context, req ToolRequest) (*RiskAssessment, error) {
+	var assessment RiskAssessment
+	if err := p.call(ctx, config.PluginCapabilityScoreRisk, req, &assessment); err != nil {
+		return nil, err
+	}
+	return &assessment, nil
+}
+
+// Redact asks the plugin process to rewrite a tool input
+func (p *ExecPlugin) Redact(ctx context.Context, req ToolRequest) (json.RawMessage, error) {
+	var redacted json.RawMessage
+	if err := p.call(ctx, config.PluginCapabilityRedact, req, &redacted); err != nil {
+		return nil, err
+	}
+	return redacted, nil
+}
+
+func (p *ExecPlugin) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	input, err := json.Marshal(execRequest{Method: method, Params: params})
+	if err != nil {
+		return fmt.Errorf("failed to encode plugin request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, p.config.Command, p.config.Args...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Env = os.Environ()
+	for k, v := range p.config.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("plugin %s %s failed: %w: %s", p.name, method, err, bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	var resp execResponse
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return fmt.Errorf("plugin %s returned invalid response: %w", p.name, err)
+	}
+	if resp.Error != "" {
+		return fmt.Errorf("plugin %s %s: %s", p.name, method, resp.Error)
+	}
+	if result != nil && len(resp.Result) > 0 {
+		if err := json.Unmarshal(resp.Result, result); err != nil {
+			return fmt.Errorf("plugin %s returned invalid %s result: %w", p.name, method, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,356 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/digest"
+	"github.com/humanlayer/humanlayer/hld/i18n"
+	"github.com/humanlayer/humanlayer/hld/moderation"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/humanlayer/humanlayer/hld/summary"
+)
+
+// capabilityChecker is implemented by plugins whose roles are declared in
+// configuration rather than by the methods they implement
+type capabilityChecker interface {
+	Supports(capability string) bool
+}
+
+// Host holds the daemon's active plugins and feeds them daemon events
+type Host struct {
+	store     store.ConversationStore
+	watches   store.WatchStore
+	locale    string
+	notifiers []Notifier
+	scorers   []RiskScorer
+	redactors []Redactor
+	// ownerChannels maps lower-cased CODEOWNERS owners to notifier names;
+	// routed holds those notifiers, which only get their owners' approvals
+	ownerChannels map[string][]string
+	routed        map[string]bool
+	// gates hold back notifications as notifiers' preferences ask
+	gates map[string]*gate
+	// moderator checks AI-written text before it's posted
+	moderator Moderator
+}
+
+// Moderator checks text before it's sent, returning an error if it may not be
+type Moderator interface {
+	Check(ctx context.Context, content moderation.Content) error
+}
+
+// NewHost creates a host with every compiled-in plugin plus the external
+// plugins from configuration
+func NewHost(plugins map[string]config.PluginConfig, s store.ConversationStore) *Host {
+	return NewHostWithLocale(plugins, s, "")
+}
+
+// NewHostWithLocale creates a host whose notification text is localized for locale
+func NewHostWithLocale(plugins map[string]config.PluginConfig, s store.ConversationStore, locale string) *Host {
+	h := &Host{store: s, locale: locale}
+	for _, p := range Registered() {
+		h.Add(p)
+	}
+
+	names := make([]string, 0, len(plugins))
+	for name := range plugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		h.Add(NewExecPlugin(name, plugins[name]))
+	}
+	return h
+}
+
+// Add activates a plugin in every role it supports
+func (h *Host) Add(p Plugin) {
+	supports := func(capability string) bool { return true }
+	if c, ok := p.(capabilityChecker); ok {
+		supports = c.Supports
+	}
+
+	if n, ok := p.(Notifier); ok && supports(config.PluginCapabilityNotify) {
+		h.notifiers = append(h.notifiers, n)
+	}
+	if s, ok := p.(RiskScorer); ok && supports(config.PluginCapabilityScoreRisk) {
+		h.scorers = append(h.scorers, s)
+	}
+	if r, ok := p.(Redactor); ok && supports(config.PluginCapabilityRedact) {
+		h.redactors = append(h.redactors, r)
+	}
+	slog.Info("plugin loaded", "name", p.Name())
+}
+
+// SetOwnerChannels routes approvals for files a CODEOWNERS owner owns to
+// that owner's notifiers, which then get no other approvals or events
+func (h *Host) SetOwnerChannels(channels map[string][]string) {
+	h.ownerChannels = make(map[string][]string, len(channels))
+	h.routed = make(map[string]bool)
+	for owner, plugins := range channels {
+		h.ownerChannels[strings.ToLower(owner)] = plugins
+		for _, name := range plugins {
+			h.routed[name] = true
+		}
+	}
+}
+
+// SetWatches notifies users watching a session of its approvals and completion
+func (h *Host) SetWatches(watches store.WatchStore) {
+	h.watches = watches
+}
+
+// SetModerator checks AI-written text in notifications before delivery
+func (h *Host) SetModerator(moderator Moderator) {
+	h.moderator = moderator
+}
+
+// Empty reports whether no plugin is active
+func (h *Host) Empty() bool {
+	return len(h.notifiers) == 0 && len(h.scorers) == 0 && len(h.redactors) == 0
+}
+
+// AssessRisk returns the highest score from all risk scorers, or nil when
+// there are none. Scorers that fail are logged and skipped.
+func (h *Host) AssessRisk(ctx context.Context, req ToolRequest) *RiskAssessment {
+	var highest *RiskAssessment
+	for _, scorer := range h.scorers {
+		assessment, err := scorer.ScoreRisk(ctx, req)
+		if err != nil {
+			slog.Warn("risk scorer failed", "plugin", scorer.Name(), "tool_name", req.ToolName, "error", err)
+			continue
+		}
+		if assessment == nil {
+			continue
+		}
+		if assessment.Scorer == "" {
+			assessment.Scorer = scorer.Name()
+		}
+		if highest == nil || assessment.Score > highest.Score {
+			highest = assessment
+		}
+	}
+	return highest
+}
+
+// RedactInput passes tool input through every redactor in turn. A failing
+// redactor drops the input entirely rather than risk leaking it.
+func (h *Host) RedactInput(ctx context.Context, req ToolRequest) json.RawMessage {
+	input := req.ToolInput
+	for _, redactor := range h.redactors {
+		req.ToolInput = input
+		redacted, err := redactor.Redact(ctx, req)
+		if err != nil {
+			slog.Warn("redactor failed, withholding tool input", "plugin", redactor.Name(), "tool_name", req.ToolName, "error", err)
+			return nil
+		}
+		input = redacted
+	}
+	return input
+}
+
+// Run forwards approval and session events to the notifiers until ctx is cancelled
+func (h *Host) Run(ctx context.Context, eventBus bus.EventBus) {
+	if len(h.notifiers) == 0 {
+		return
+	}
+
+	sub := eventBus.Subscribe(ctx, bus.EventFilter{
+		Types: []bus.EventType{bus.EventNewApproval, bus.EventApprovalResolved, bus.EventSessionStatusChanged, bus.EventCostBudgetThreshold, bus.EventSessionSummaryReady, bus.EventDailyDigest, bus.EventApprovalReminder},
+	})
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("plugin host shutting down")
+			return
+		case event, ok := <-sub.Channel:
+			if !ok {
+				return
+			}
+			n := h.buildNotification(ctx, event)
+			for _, notifier := range h.notifiers {
+				if h.deliversTo(event, n, notifier) {
+					h.deliver(ctx, notifier, n)
+				}
+			}
+		}
+	}
+}
+
+// deliversTo reports whether an event goes to a notifier. Events may name
+// the plugins they are meant for in their "plugins" data, and owner channels
+// only get approvals for their owners' files, events from the sessions their
+// owners watch and notifications meant for their owners.
+func (h *Host) deliversTo(event bus.Event, n Notification, notifier Notifier) bool {
+	if plugins, _ := event.Data["plugins"].([]string); len(plugins) > 0 {
+		return slices.Contains(plugins, notifier.Name())
+	}
+	if !h.routed[notifier.Name()] {
+		return true
+	}
+	for _, owner := range slices.Concat(n.Owners, n.Watchers, []string{n.Recipient}) {
+		if slices.Contains(h.ownerChannels[strings.ToLower(owner)], notifier.Name()) {
+			return true
+		}
+	}
+	return false
+}
+
+// buildNotification turns a bus event into a localized notification
+func (h *Host) buildNotification(ctx context.Context, event bus.Event) Notification {
+	n := h.approvalNotification(ctx, event)
+	if len(h.ownerChannels) > 0 && watched(event) {
+		n.Watchers = h.sessionWatchers(ctx, n.SessionID)
+	}
+	n.Severity = severity(event)
+	h.describe(&n)
+	h.moderate(ctx, &n)
+	return n
+}
+
+// moderate withholds a session summary the moderator blocks; the channel is
+// told there was one without its text
+func (h *Host) moderate(ctx context.Context, n *Notification) {
+	s, ok := n.Data["summary"].(*summary.Summary)
+	if !ok || h.moderator == nil || n.Event != bus.EventSessionSummaryReady {
+		return
+	}
+	text := strings.Join(slices.Concat([]string{s.Asked}, s.Changed, s.OpenQuestions, s.FollowUps), "\n")
+	err := h.moderator.Check(ctx, moderation.Content{Kind: store.ModerationSessionSummary, SessionID: n.SessionID, Text: text})
+	if err == nil {
+		return
+	}
+	// The event's data is shared with other subscribers
+	data := make(map[string]interface{}, len(n.Data))
+	for k, v := range n.Data {
+		if k != "summary" {
+			data[k] = v
+		}
+	}
+	n.Data = data
+	n.Message = i18n.T(h.locale, i18n.SessionSummaryWithheld, n.SessionID)
+}
+
+// watched reports whether watchers hear of an event: their sessions'
+// approvals and completion
+func watched(event bus.Event) bool {
+	switch event.Type {
+	case bus.EventNewApproval:
+		return true
+	case bus.EventSessionStatusChanged:
+		status, _ := event.Data["new_status"].(string)
+		return status == store.SessionStatusCompleted || status == store.SessionStatusFailed || status == store.SessionStatusInterrupted
+	}
+	return false
+}
+
+func (h *Host) sessionWatchers(ctx context.Context, sessionID string) []string {
+	if sessionID == "" || h.watches == nil {
+		return nil
+	}
+	watchers, err := h.watches.ListSessionWatchers(ctx, sessionID)
+	if err != nil {
+		slog.Warn("failed to load session watchers", "session_id", sessionID, "error", err)
+		return nil
+	}
+	return watchers
+}
+
+// approvalNotification copies the event's identifiers into a notification,
+// attaching the redacted tool input and risk score for approval events
+func (h *Host) approvalNotification(ctx context.Context, event bus.Event) Notification {
+	n := Notification{Event: event.Type, Data: event.Data}
+	n.SessionID, _ = event.Data["session_id"].(string)
+	n.ApprovalID, _ = event.Data["approval_id"].(string)
+	n.ToolName, _ = event.Data["tool_name"].(string)
+	n.Recipient, _ = event.Data["recipient"].(string)
+
+	if n.ApprovalID == "" || h.store == nil {
+		return n
+	}
+	approval, err := h.store.GetApproval(ctx, n.ApprovalID)
+	if err != nil {
+		slog.Warn("failed to load approval for plugin notification", "approval_id", n.ApprovalID, "error", err)
+		return n
+	}
+
+	req := ToolRequest{
+		SessionID:  approval.SessionID,
+		ApprovalID: approval.ID,
+		ToolName:   approval.ToolName,
+		ToolInput:  approval.ToolInput,
+	}
+	n.SessionID = approval.SessionID
+	n.ToolName = approval.ToolName
+	n.ToolInput = h.RedactInput(ctx, req)
+	if event.Type == bus.EventNewApproval {
+		n.Risk = h.AssessRisk(ctx, req)
+	}
+	if len(h.ownerChannels) > 0 {
+		n.Owners = h.approvalOwners(ctx, approval)
+	}
+	return n
+}
+
+// describe fills in the notification's localized title and message
+func (h *Host) describe(n *Notification) {
+	switch n.Event {
+	case bus.EventNewApproval:
+		n.Title = i18n.T(h.locale, i18n.ApprovalRequestedTitle)
+		n.Message = i18n.T(h.locale, i18n.ApprovalRequestedMessage, n.ToolName)
+	case bus.EventApprovalReminder:
+		n.Title = i18n.T(h.locale, i18n.ApprovalReminderTitle)
+		n.Message = i18n.T(h.locale, i18n.ApprovalRequestedMessage, n.ToolName)
+	case bus.EventApprovalResolved:
+		approved, _ := n.Data["approved"].(bool)
+		n.Title = i18n.T(h.locale, i18n.ApprovalDeniedTitle)
+		decision := i18n.Word(h.locale, "denied")
+		if approved {
+			n.Title = i18n.T(h.locale, i18n.ApprovalApprovedTitle)
+			decision = i18n.Word(h.locale, "approved")
+		}
+		n.Message = i18n.T(h.locale, i18n.ApprovalResolvedMessage, n.ToolName, decision)
+	case bus.EventSessionStatusChanged:
+		status, _ := n.Data["new_status"].(string)
+		status = i18n.Word(h.locale, status)
+		n.Title = i18n.T(h.locale, i18n.SessionStatusTitle, status)
+		n.Message = i18n.T(h.locale, i18n.SessionStatusMessage, n.SessionID, status)
+	case bus.EventCostBudgetThreshold:
+		name, _ := n.Data["budget"].(string)
+		threshold, _ := n.Data["threshold"].(int)
+		spent, _ := n.Data["spent_usd"].(float64)
+		limit, _ := n.Data["limit_usd"].(float64)
+		n.Title = i18n.T(h.locale, i18n.CostBudgetTitle)
+		n.Message = i18n.T(h.locale, i18n.CostBudgetMessage, name, threshold, spent, limit)
+	case bus.EventDailyDigest:
+		workspace, _ := n.Data["workspace"].(string)
+		sessions, _ := n.Data["sessions"].(int)
+		completed, _ := n.Data["completed"].(int)
+		failed, _ := n.Data["failed"].(int)
+		approvals, _ := n.Data["approvals"].(int)
+		denied, _ := n.Data["denied"].(int)
+		cost, _ := n.Data["cost_usd"].(float64)
+		n.Title = i18n.T(h.locale, i18n.DigestTitle, workspace)
+		n.Message = i18n.T(h.locale, i18n.DigestMessage, sessions, completed, failed, approvals, denied, cost)
+		failures, _ := n.Data["failures"].([]digest.Failure)
+		for _, f := range failures {
+			n.Message += "\n- " + f.Title
+			if f.Error != "" {
+				n.Message += ": " + f.Error
+			}
+		}
+	case bus.EventSessionSummaryReady:
+		n.Title = i18n.T(h.locale, i18n.SessionSummaryTitle)
+		if s, ok := n.Data["summary"].(*summary.Summary); ok {
+			n.Message = i18n.T(h.locale, i18n.SessionSummaryMessage, s.Asked, len(s.Changed), len(s.OpenQuestions))
+		}
+	}
+}
@@ -0,0 +1,314 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/approval"
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/digest"
+	"github.com/humanlayer/humanlayer/hld/moderation"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/humanlayer/humanlayer/hld/summary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testNotifier struct {
+	notifications chan Notification
+}
+
+func (n *testNotifier) Name() string { return "test-notifier" }
+
+func (n *testNotifier) Notify(ctx context.Context, notification Notification) error {
+	n.notifications <- notification
+	return nil
+}
+
+type testScorer struct {
+	name  string
+	score float64
+	err   error
+}
+
+func (s *testScorer) Name() string { return s.name }
+
+func (s *testScorer) ScoreRisk(ctx context.Context, req ToolRequest) (*RiskAssessment, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &RiskAssessment{Score: s.score, Reason: req.ToolName}, nil
+}
+
+type secretRedactor struct{}
+
+func (secretRedactor) Name() string { return "secret-redactor" }
+
+func (secretRedactor) Redact(ctx context.Context, req ToolRequest) (json.RawMessage, error) {
+	return json.RawMessage(`{"command":"[redacted]"}`), nil
+}
+
+func TestHostAssessRiskPicksHighestScore(t *testing.T) {
+	h := NewHost(nil, nil)
+	h.Add(&testScorer{name: "low", score: 0.2})
+	h.Add(&testScorer{name: "broken", err: errors.New("boom")})
+	h.Add(&testScorer{name: "high", score: 0.8})
+
+	risk := h.AssessRisk(context.Background(), ToolRequest{ToolName: "Bash"})
+	require.NotNil(t, risk)
+	assert.Equal(t, 0.8, risk.Score)
+	assert.Equal(t, "high", risk.Scorer)
+}
+
+func TestHostNotifiesWithRedactedInputAndRisk(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := store.NewInMemoryStore()
+	eventBus := bus.NewEventBus()
+	require.NoError(t, s.CreateSession(ctx, &store.Session{
+		ID: "sess-1", RunID: "run-1", Status: store.SessionStatusRunning,
+		CreatedAt: time.Now(), LastActivityAt: time.Now(),
+	}))
+
+	notifier := &testNotifier{notifications: make(chan Notification, 4)}
+	h := NewHost(nil, s)
+	h.Add(notifier)
+	h.Add(&testScorer{name: "scorer", score: 0.9})
+	h.Add(secretRedactor{})
+
+	go h.Run(ctx, eventBus)
+	require.Eventually(t, func() bool { return eventBus.GetSubscriberCount() == 1 }, time.Second, 10*time.Millisecond)
+
+	manager := approval.NewManager(s, eventBus)
+	_, err := manager.CreateApproval(ctx, "run-1", "Bash", json.RawMessage(`{"command":"echo $TOKEN"}`))
+	require.NoError(t, err)
+
+	select {
+	case n := <-notifier.notifications:
+		assert.Equal(t, bus.EventNewApproval, n.Event)
+		assert.Equal(t, "sess-1", n.SessionID)
+		assert.Equal(t, "Bash", n.ToolName)
+		assert.JSONEq(t, `{"command":"[redacted]"}`, string(n.ToolInput))
+		require.NotNil(t, n.Risk)
+		assert.Equal(t, 0.9, n.Risk.Score)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for notification")
+	}
+}
+
+func TestHostLocalizesNotifications(t *testing.T) {
+	h := NewHostWithLocale(nil, nil, "fr")
+
+	n := h.buildNotification(context.Background(), bus.Event{
+		Type: bus.EventApprovalResolved,
+		Data: map[string]interface{}{"session_id": "sess-1", "tool_name": "Bash", "approved": false},
+	})
+	assert.Equal(t, "Refusé", n.Title)
+	assert.Equal(t, "Bash a été refusé", n.Message)
+
+	n = NewHost(nil, nil).buildNotification(context.Background(), bus.Event{
+		Type: bus.EventSessionStatusChanged,
+		Data: map[string]interface{}{"session_id": "sess-1", "new_status": "completed"},
+	})
+	assert.Equal(t, "Session completed", n.Title)
+	assert.Equal(t, "Session sess-1 is now completed", n.Message)
+
+	n = NewHost(nil, nil).buildNotification(context.Background(), bus.Event{
+		Type: bus.EventSessionSummaryReady,
+		Data: map[string]interface{}{"session_id": "sess-1", "summary": &summary.Summary{Asked: "Fix the parser", Changed: []string{"parse.go"}}},
+	})
+	assert.Equal(t, "Session summary", n.Title)
+	assert.Equal(t, "Fix the parser (1 changes, 0 open questions)", n.Message)
+
+	n = NewHost(nil, nil).buildNotification(context.Background(), bus.Event{
+		Type: bus.EventDailyDigest,
+		Data: map[string]interface{}{
+			"recipient": "alice", "workspace": "/work/app", "sessions": 2, "completed": 1, "failed": 1, "cost_usd": 1.5,
+			"failures": []digest.Failure{{SessionID: "b", Title: "Migrate db", Error: "exit 1"}},
+		},
+	})
+	assert.Equal(t, "alice", n.Recipient)
+	assert.Equal(t, "Daily digest: /work/app", n.Title)
+	assert.Equal(t, "2 sessions (1 completed, 1 failed), 0 approvals (0 denied), $1.50 spent\n- Migrate db: exit 1", n.Message)
+}
+
+// wordModerator blocks text containing a word
+type wordModerator string
+
+func (m wordModerator) Check(ctx context.Context, content moderation.Content) error {
+	if strings.Contains(content.Text, string(m)) {
+		return &moderation.BlockedError{Kind: content.Kind, Source: moderation.SourcePattern, Reason: string(m)}
+	}
+	return nil
+}
+
+func TestHostWithholdsBlockedSummaries(t *testing.T) {
+	h := NewHost(nil, nil)
+	h.SetModerator(wordModerator("falcon"))
+	data := map[string]interface{}{
+		"session_id": "sess-1",
+		"summary":    &summary.Summary{Asked: "Fix the parser", Changed: []string{"parse.go"}, FollowUps: []string{"Tell falcon"}},
+	}
+	n := h.buildNotification(context.Background(), bus.Event{Type: bus.EventSessionSummaryReady, Data: data})
+	assert.Equal(t, "Session summary", n.Title)
+	assert.Equal(t, "The summary of session sess-1 was withheld by the content safety filter", n.Message)
+	assert.NotContains(t, n.Data, "summary")
+	assert.Contains(t, data, "summary", "the event's data is left alone")
+
+	n = h.buildNotification(context.Background(), bus.Event{
+		Type: bus.EventSessionSummaryReady,
+		Data: map[string]interface{}{"session_id": "sess-2", "summary": &summary.Summary{Asked: "Fix the lexer"}},
+	})
+	assert.Equal(t, "Fix the lexer (0 changes, 0 open questions)", n.Message)
+}
+
+func TestExecPlugin(t *testing.T) {
+	p := NewExecPlugin("script", config.PluginConfig{
+		Command:      "sh",
+		Args:         []string{"-c", `cat >/dev/null; echo '{"result":{"score":0.7,"reason":"from script"}}'`},
+		Capabilities: []string{config.PluginCapabilityScoreRisk},
+	})
+
+	h := NewHost(nil, nil)
+	h.Add(p)
+	assert.Empty(t, h.notifiers, "undeclared capabilities should not be activated")
+	assert.Empty(t, h.redactors, "undeclared capabilities should not be activated")
+
+	risk := h.AssessRisk(context.Background(), ToolRequest{ToolName: "Bash"})
+	require.NotNil(t, risk)
+	assert.Equal(t, 0.7, risk.Score)
+	assert.Equal(t, "from script", risk.Reason)
+	assert.Equal(t, "script", risk.Scorer)
+
+	failing := NewExecPlugin("failing", config.PluginConfig{
+		Command:      "sh",
+		Args:         []string{"-c", `cat >/dev/null; echo '{"error":"nope"}'`},
+		Capabilities: []string{config.PluginCapabilityRedact},
+	})
+	_, err := failing.Redact(context.Background(), ToolRequest{ToolName: "Bash"})
+	assert.ErrorContains(t, err, "nope")
+}
+
+type namedNotifier struct {
+	testNotifier
+	name string
+}
+
+func (n *namedNotifier) Name() string { return n.name }
+
+func TestHostRoutesOwnedApprovals(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir := t.TempDir()
+	out, err := exec.Command("git", "init", "-q", dir).CombinedOutput()
+	require.NoError(t, err, string(out))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "CODEOWNERS"), []byte("/billing/ @acme/payments\n"), 0644))
+
+	s := store.NewInMemoryStore()
+	eventBus := bus.NewEventBus()
+	require.NoError(t, s.CreateSession(ctx, &store.Session{
+		ID: "sess-1", RunID: "run-1", WorkingDir: dir, Status: store.SessionStatusRunning,
+		CreatedAt: time.Now(), LastActivityAt: time.Now(),
+	}))
+
+	general := &namedNotifier{testNotifier{make(chan Notification, 4)}, "general"}
+	payments := &namedNotifier{testNotifier{make(chan Notification, 4)}, "payments-channel"}
+	h := NewHost(nil, s)
+	h.Add(general)
+	h.Add(payments)
+	h.SetOwnerChannels(map[string][]string{"@ACME/payments": {"payments-channel"}})
+
+	go h.Run(ctx, eventBus)
+	require.Eventually(t, func() bool { return eventBus.GetSubscriberCount() == 1 }, time.Second, 10*time.Millisecond)
+
+	manager := approval.NewManager(s, eventBus)
+	_, err = manager.CreateApproval(ctx, "run-1", "Write", json.RawMessage(`{"file_path":"`+dir+`/README.md","content":"x"}`))
+	require.NoError(t, err)
+	_, err = manager.CreateApproval(ctx, "run-1", "Edit", json.RawMessage(`{"file_path":"billing/charge.go","new_string":"x"}`))
+	require.NoError(t, err)
+
+	receive := func(n *namedNotifier) Notification {
+		select {
+		case notification := <-n.notifications:
+			return notification
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for %s", n.name)
+			return Notification{}
+		}
+	}
+	assert.Equal(t, "Write", receive(general).ToolName)
+	assert.Equal(t, "Edit", receive(general).ToolName)
+	owned := receive(payments)
+	assert.Equal(t, "Edit", owned.ToolName, "owner channels only get their owners' approvals")
+	assert.Equal(t, []string{"@acme/payments"}, owned.Owners)
+	select {
+	case n := <-payments.notifications:
+		t.Fatalf("unexpected notification for %s", n.ToolName)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestHostRoutesWatchedSessions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := store.NewInMemoryStore()
+	eventBus := bus.NewEventBus()
+	for _, id := range []string{"watched", "other"} {
+		require.NoError(t, s.CreateSession(ctx, &store.Session{
+			ID: id, RunID: "run-" + id, Status: store.SessionStatusRunning,
+			CreatedAt: time.Now(), LastActivityAt: time.Now(),
+		}))
+	}
+	require.NoError(t, s.WatchSessions(ctx, "alice", []string{"watched"}))
+
+	alice := &namedNotifier{testNotifier{make(chan Notification, 4)}, "alice-slack"}
+	h := NewHost(nil, s)
+	h.SetWatches(s)
+	h.Add(alice)
+	h.SetOwnerChannels(map[string][]string{"Alice": {"alice-slack"}})
+
+	go h.Run(ctx, eventBus)
+	require.Eventually(t, func() bool { return eventBus.GetSubscriberCount() == 1 }, time.Second, 10*time.Millisecond)
+
+	manager := approval.NewManager(s, eventBus)
+	_, err := manager.CreateApproval(ctx, "run-other", "Bash", json.RawMessage(`{"command":"ls"}`))
+	require.NoError(t, err)
+	_, err = manager.CreateApproval(ctx, "run-watched", "Bash", json.RawMessage(`{"command":"make"}`))
+	require.NoError(t, err)
+	eventBus.Publish(bus.Event{Type: bus.EventSessionStatusChanged, Data: map[string]interface{}{
+		"session_id": "watched", "old_status": "running", "new_status": "waiting_input",
+	}})
+	eventBus.Publish(bus.Event{Type: bus.EventSessionStatusChanged, Data: map[string]interface{}{
+		"session_id": "watched", "old_status": "running", "new_status": "completed",
+	}})
+
+	// Notifications are delivered concurrently, so may arrive in any order
+	got := make(map[bus.EventType]Notification)
+	for len(got) < 2 {
+		select {
+		case n := <-alice.notifications:
+			got[n.Event] = n
+		case <-time.After(2 * time.Second):
+			t.Fatalf("got %d notifications, want 2", len(got))
+		}
+	}
+	assert.Equal(t, "watched", got[bus.EventNewApproval].SessionID)
+	assert.Equal(t, []string{"alice"}, got[bus.EventNewApproval].Watchers)
+	assert.Equal(t, "completed", got[bus.EventSessionStatusChanged].Data["new_status"])
+	select {
+	case n := <-alice.notifications:
+		t.Fatalf("unexpected %s notification for %s", n.Event, n.SessionID)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
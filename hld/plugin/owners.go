@@ -0,0 +1,55 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"github.com/humanlayer/humanlayer/hld/owners"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/humanlayer/humanlayer/hld/workspace"
+)
+
+// approvalOwners returns the CODEOWNERS owners of the file an approval
+// would write, if any
+func (h *Host) approvalOwners(ctx context.Context, approval *store.Approval) []string {
+	var input struct {
+		FilePath     string `json:"file_path"`
+		NotebookPath string `json:"notebook_path"`
+	}
+	if json.Unmarshal(approval.ToolInput, &input) != nil {
+		return nil
+	}
+	path := input.FilePath
+	if path == "" {
+		path = input.NotebookPath
+	}
+	if path == "" {
+		return nil
+	}
+
+	session, err := h.store.GetSession(ctx, approval.SessionID)
+	if err != nil || session.WorkingDir == "" {
+		return nil
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(session.WorkingDir, path)
+	}
+	host := workspace.ForSession(session)
+	root, err := owners.Root(ctx, host, session.WorkingDir)
+	if err != nil {
+		return nil
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return nil
+	}
+	codeowners, err := owners.Load(ctx, host, root)
+	if err != nil {
+		slog.Warn("failed to read CODEOWNERS", "root", root, "error", err)
+		return nil
+	}
+	return codeowners.Owners(rel)
+}
@@ -0,0 +1,115 @@
+// Package plugin lets users add notification channels, risk scorers and
+// redaction rules without forking the daemon. Go plugins are compiled in and
+// call Register from an init function; anything else runs as an external
+// process configured under "plugins" in humanlayer.json.
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+)
+
+// Plugin is implemented by every plugin. A plugin takes on a role by also
+// implementing Notifier, RiskScorer or Redactor.
+type Plugin interface {
+	Name() string
+}
+
+// Notifier delivers daemon events to an external channel (chat, pager, email, ...)
+type Notifier interface {
+	Plugin
+	Notify(ctx context.Context, n Notification) error
+}
+
+// RiskScorer rates how risky a tool call is
+type RiskScorer interface {
+	Plugin
+	ScoreRisk(ctx context.Context, req ToolRequest) (*RiskAssessment, error)
+}
+
+// Redactor rewrites tool input before it leaves the daemon through a Notifier
+type Redactor interface {
+	Plugin
+	Redact(ctx context.Context, req ToolRequest) (json.RawMessage, error)
+}
+
+// ToolRequest is the tool call an approval was created for
+type ToolRequest struct {
+	SessionID  string          `json:"session_id"`
+	ApprovalID string          `json:"approval_id"`
+	ToolName   string          `json:"tool_name"`
+	ToolInput  json.RawMessage `json:"tool_input,omitempty"`
+}
+
+// RiskAssessment is a risk score between 0 (harmless) and 1 (dangerous)
+type RiskAssessment struct {
+	Score  float64 `json:"score"`
+	Reason string  `json:"reason,omitempty"`
+	Scorer string  `json:"scorer,omitempty"`
+}
+
+// Notification is what a Notifier receives for each daemon event. Tool input
+// has already been through every Redactor.
+type Notification struct {
+	Event      bus.EventType `json:"event"`
+	SessionID  string        `json:"session_id,omitempty"`
+	ApprovalID string        `json:"approval_id,omitempty"`
+	// Recipient names who a personal notification, such as a daily digest, is for
+	Recipient string          `json:"recipient,omitempty"`
+	ToolName  string          `json:"tool_name,omitempty"`
+	ToolInput json.RawMessage `json:"tool_input,omitempty"`
+	Risk      *RiskAssessment `json:"risk,omitempty"`
+	// Owners are the CODEOWNERS owners of the file an approval is for, when
+	// owner channels are configured
+	Owners []string `json:"owners,omitempty"`
+	// Watchers are the users watching the session, for its approvals and
+	// completion
+	Watchers []string               `json:"watchers,omitempty"`
+	Data     map[string]interface{} `json:"data,omitempty"`
+	// Title and Message are ready-to-display text in the daemon's locale
+	Title   string `json:"title,omitempty"`
+	Message string `json:"message,omitempty"`
+	// Severity is info, warning or critical
+	Severity string `json:"severity,omitempty"`
+	// Batch holds the notifications a notification_batch groups
+	Batch []Notification `json:"batch,omitempty"`
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = make(map[string]Plugin)
+)
+
+// Register makes a compiled-in plugin available to the daemon. It is meant
+// to be called from init and panics if the name is empty or already taken.
+func Register(p Plugin) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	name := p.Name()
+	if name == "" {
+		panic("plugin: Register called with empty plugin name")
+	}
+	if _, dup := registry[name]; dup {
+		panic(fmt.Sprintf("plugin: Register called twice for plugin %q", name))
+	}
+	registry[name] = p
+}
+
+// Registered returns the compiled-in plugins sorted by name
+func Registered() []Plugin {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	plugins := make([]Plugin, 0, len(registry))
+	for _, p := range registry {
+		plugins = append(plugins, p)
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name() < plugins[j].Name() })
+	return plugins
+}
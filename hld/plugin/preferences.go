@@ -0,0 +1,185 @@
+package plugin
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/i18n"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// EventBatch is the event of a notification grouping several held back by
+// batching or a mute window; they are in its Batch
+const EventBatch bus.EventType = "notification_batch"
+
+// muteRecheck is how often held notifications are retried while muted
+const muteRecheck = time.Minute
+
+// gate applies one notifier's preferences, holding back what it shouldn't
+// send yet
+type gate struct {
+	prefs       config.NotificationPreferences
+	batch       time.Duration
+	minSeverity int
+	now         func() time.Time
+
+	mu       sync.Mutex
+	lastSent time.Time
+	pending  []Notification
+	timer    *time.Timer
+}
+
+func newGate(prefs config.NotificationPreferences) *gate {
+	g := &gate{prefs: prefs, minSeverity: max(config.SeverityRank(prefs.MinSeverity), 0), now: time.Now}
+	g.batch, _ = time.ParseDuration(prefs.Batch)
+	return g
+}
+
+// SetNotificationPreferences applies do-not-disturb, batching and severity
+// preferences to notifiers by name, with "*" covering the rest
+func (h *Host) SetNotificationPreferences(prefs map[string]config.NotificationPreferences) {
+	h.gates = make(map[string]*gate)
+	for _, notifier := range h.notifiers {
+		p, ok := prefs[notifier.Name()]
+		if !ok {
+			p, ok = prefs["*"]
+		}
+		if ok {
+			h.gates[notifier.Name()] = newGate(p)
+		}
+	}
+}
+
+// deliver sends n to notifier now, later, or not at all, as its preferences say
+func (h *Host) deliver(ctx context.Context, notifier Notifier, n Notification) {
+	send := func(n Notification) {
+		if err := notifier.Notify(ctx, n); err != nil {
+			slog.Warn("plugin notification failed", "plugin", notifier.Name(), "event", n.Event, "error", err)
+		}
+	}
+	g := h.gates[notifier.Name()]
+	if g == nil {
+		go send(n)
+		return
+	}
+	g.offer(n, func(held []Notification) { send(h.group(held)) })
+}
+
+// offer sends n at once unless it's below the threshold, which drops it, or
+// a mute window or the batch period is in effect, which holds it. Critical
+// notifications are always sent at once.
+func (g *gate) offer(n Notification, send func([]Notification)) {
+	// Notifications without a severity count as info
+	severity := max(config.SeverityRank(n.Severity), 0)
+	if severity < g.minSeverity {
+		slog.Debug("notification below threshold", "event", n.Event, "severity", n.Severity)
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := g.now()
+	if severity < config.SeverityRank(config.SeverityCritical) && (g.muted(now) || now.Sub(g.lastSent) < g.batch) {
+		g.pending = append(g.pending, n)
+		g.schedule(now, send)
+		return
+	}
+	g.lastSent = now
+	go send([]Notification{n})
+}
+
+func (g *gate) muted(now time.Time) bool {
+	for _, window := range g.prefs.MuteWindows {
+		if muted, _ := window.Contains(now); muted {
+			return true
+		}
+	}
+	return false
+}
+
+// schedule arranges for held notifications to be flushed once the batch
+// period ends, rechecking mute windows every minute. g.mu must be held.
+func (g *gate) schedule(now time.Time, send func([]Notification)) {
+	if g.timer != nil {
+		return
+	}
+	wait := g.lastSent.Add(g.batch).Sub(now)
+	if g.muted(now) {
+		wait = max(wait, muteRecheck)
+	}
+	g.timer = time.AfterFunc(max(wait, 0), func() { g.flush(send) })
+}
+
+// flush sends held notifications as one message, or waits longer if still
+// muted
+func (g *gate) flush(send func([]Notification)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.timer = nil
+	if len(g.pending) == 0 {
+		return
+	}
+	now := g.now()
+	if g.muted(now) {
+		g.schedule(now, send)
+		return
+	}
+	held := g.pending
+	g.pending = nil
+	g.lastSent = now
+	go send(held)
+}
+
+// group returns a single notification as is, and several as one batch
+// listing each
+func (h *Host) group(held []Notification) Notification {
+	if len(held) == 1 {
+		return held[0]
+	}
+	n := Notification{
+		Event:    EventBatch,
+		Batch:    held,
+		Severity: config.SeverityInfo,
+		Title:    i18n.T(h.locale, i18n.BatchTitle, len(held)),
+	}
+	lines := make([]string, len(held))
+	for i, item := range held {
+		lines[i] = "- " + item.Title
+		if item.Message != "" {
+			lines[i] += ": " + item.Message
+		}
+		if config.SeverityRank(item.Severity) > config.SeverityRank(n.Severity) {
+			n.Severity = item.Severity
+		}
+	}
+	n.Message = strings.Join(lines, "\n")
+	return n
+}
+
+// severity rates an event for notification thresholds
+func severity(event bus.Event) string {
+	switch event.Type {
+	case bus.EventNewApproval:
+		if critical, _ := event.Data["critical"].(bool); critical {
+			return config.SeverityCritical
+		}
+		return config.SeverityWarning
+	case bus.EventApprovalReminder:
+		return config.SeverityWarning
+	case bus.EventSessionStatusChanged:
+		if status, _ := event.Data["new_status"].(string); status == store.SessionStatusFailed {
+			return config.SeverityWarning
+		}
+	case bus.EventCostBudgetThreshold:
+		if threshold, _ := event.Data["threshold"].(int); threshold >= 100 {
+			return config.SeverityCritical
+		}
+		return config.SeverityWarning
+	}
+	return config.SeverityInfo
+}
@@ -0,0 +1,120 @@
+package plugin
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder collects what a gate sends
+type recorder struct {
+	mu   sync.Mutex
+	sent [][]Notification
+	done chan struct{}
+}
+
+func newRecorder() *recorder { return &recorder{done: make(chan struct{}, 10)} }
+
+func (r *recorder) send(held []Notification) {
+	r.mu.Lock()
+	r.sent = append(r.sent, held)
+	r.mu.Unlock()
+	r.done <- struct{}{}
+}
+
+func (r *recorder) wait(t *testing.T) []Notification {
+	t.Helper()
+	select {
+	case <-r.done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("nothing sent")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.sent[len(r.sent)-1]
+}
+
+func TestGateDropsBelowThreshold(t *testing.T) {
+	g := newGate(config.NotificationPreferences{MinSeverity: config.SeverityWarning})
+	r := newRecorder()
+	g.offer(Notification{Event: bus.EventSessionStatusChanged, Severity: config.SeverityInfo}, r.send)
+	g.offer(Notification{Event: bus.EventNewApproval, Severity: config.SeverityWarning}, r.send)
+	assert.Equal(t, bus.EventNewApproval, r.wait(t)[0].Event)
+	select {
+	case <-r.done:
+		t.Fatal("info notification was sent")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestGateBatches(t *testing.T) {
+	g := newGate(config.NotificationPreferences{Batch: "100ms"})
+	r := newRecorder()
+	g.offer(Notification{Title: "first"}, r.send)
+	assert.Len(t, r.wait(t), 1)
+
+	g.offer(Notification{Title: "second"}, r.send)
+	g.offer(Notification{Title: "third"}, r.send)
+	held := r.wait(t)
+	require.Len(t, held, 2)
+	assert.Equal(t, "second", held[0].Title)
+	assert.Equal(t, "third", held[1].Title)
+}
+
+func TestGateMuteWindow(t *testing.T) {
+	night := time.Date(2026, 1, 5, 23, 0, 0, 0, time.Local)
+	g := newGate(config.NotificationPreferences{MuteWindows: []config.QuietHours{{Start: "22:00", End: "07:00"}}})
+	g.now = func() time.Time { return night }
+	r := newRecorder()
+
+	g.offer(Notification{Title: "held", Severity: config.SeverityWarning}, r.send)
+	g.offer(Notification{Title: "urgent", Severity: config.SeverityCritical}, r.send)
+	assert.Equal(t, "urgent", r.wait(t)[0].Title, "critical notifications skip mute windows")
+
+	// Still muted, so flushing keeps holding
+	g.flush(r.send)
+	g.mu.Lock()
+	assert.Len(t, g.pending, 1)
+	g.timer.Stop()
+	g.mu.Unlock()
+
+	g.now = func() time.Time { return night.Add(9 * time.Hour) }
+	g.flush(r.send)
+	assert.Equal(t, "held", r.wait(t)[0].Title)
+}
+
+func TestHostGroupsBatch(t *testing.T) {
+	h := NewHost(nil, nil)
+	n := h.group([]Notification{
+		{Title: "Approval needed", Message: "Bash: make deploy", Severity: config.SeverityWarning},
+		{Title: "Session completed", Severity: config.SeverityInfo},
+	})
+	assert.Equal(t, EventBatch, n.Event)
+	assert.Equal(t, "2 notifications", n.Title)
+	assert.Equal(t, "- Approval needed: Bash: make deploy\n- Session completed", n.Message)
+	assert.Equal(t, config.SeverityWarning, n.Severity)
+	assert.Len(t, n.Batch, 2)
+}
+
+func TestHostAppliesPreferencesByName(t *testing.T) {
+	h := NewHost(nil, nil)
+	notifier := &testNotifier{notifications: make(chan Notification, 1)}
+	h.Add(notifier)
+	h.SetNotificationPreferences(map[string]config.NotificationPreferences{
+		"*": {MinSeverity: config.SeverityCritical},
+	})
+	h.deliver(context.Background(), notifier, Notification{Severity: config.SeverityWarning})
+	h.deliver(context.Background(), notifier, Notification{Title: "down", Severity: config.SeverityCritical})
+	select {
+	case n := <-notifier.notifications:
+		assert.Equal(t, "down", n.Title)
+	case <-time.After(2 * time.Second):
+		t.Fatal("critical notification not delivered")
+	}
+}
@@ -0,0 +1,201 @@
+// Package promptlog keeps the most recent prompts the daemon sent to models
+// and the raw text they answered in memory, so a surprising commit message
+// or chat answer can be traced back to exactly what the model saw.
+// Credentials are redacted before anything is stored.
+package promptlog
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/feedback"
+	"github.com/humanlayer/humanlayer/hld/llm"
+)
+
+// DefaultTasks are recorded when the config names none
+var DefaultTasks = []llm.Task{llm.TaskCommitMessage, llm.TaskEphemeralChat}
+
+// redacted replaces matches of the configured redact patterns, the same
+// marker feedback.Redact uses for credentials
+const redacted = "[REDACTED]"
+
+// Entry is one recorded model call
+type Entry struct {
+	ID         uint64    `json:"id"`
+	Time       time.Time `json:"time"`
+	Task       string    `json:"task"`
+	Provider   string    `json:"provider"`
+	Model      string    `json:"model"`
+	DurationMS int64     `json:"duration_ms"`
+	// Error is set when the call failed, and the router tried the next model
+	Error    string `json:"error,omitempty"`
+	System   string `json:"system,omitempty"`
+	Prompt   string `json:"prompt,omitempty"`
+	Response string `json:"response,omitempty"`
+	// Truncated is set when the prompt or response was longer than
+	// max_text_size
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Filter selects entries; zero fields match everything
+type Filter struct {
+	Task     string
+	Provider string
+	Model    string
+	// Only failed calls
+	Failed bool
+	// Limit caps how many entries are returned
+	Limit int
+}
+
+// Log holds the most recent entries in a ring buffer
+type Log struct {
+	tasks       []llm.Task
+	maxTextSize int
+	patterns    []*regexp.Regexp
+
+	mu      sync.Mutex
+	entries []Entry
+	next    int
+	full    bool
+	lastID  uint64
+}
+
+// New returns a log for cfg, or nil if the prompt log isn't enabled.
+// Patterns are checked by config validation; invalid ones are skipped.
+func New(cfg config.PromptLogConfig) *Log {
+	if !cfg.Enabled {
+		return nil
+	}
+	size := cfg.BufferSize
+	if size == 0 {
+		size = config.DefaultPromptLogBufferSize
+	}
+	l := &Log{
+		tasks:       DefaultTasks,
+		maxTextSize: cfg.MaxTextSize,
+		entries:     make([]Entry, size),
+	}
+	if l.maxTextSize == 0 {
+		l.maxTextSize = config.DefaultPromptLogMaxTextSize
+	}
+	if len(cfg.Tasks) > 0 {
+		l.tasks = make([]llm.Task, len(cfg.Tasks))
+		for i, task := range cfg.Tasks {
+			l.tasks[i] = llm.Task(task)
+		}
+	}
+	for _, pattern := range cfg.RedactPatterns {
+		if re, err := regexp.Compile(pattern); err == nil {
+			l.patterns = append(l.patterns, re)
+		}
+	}
+	return l
+}
+
+// Observe records a model call for a logged task
+func (l *Log) Observe(ctx context.Context, exchange llm.Exchange) {
+	if !slices.Contains(l.tasks, exchange.Task) {
+		return
+	}
+	entry := Entry{
+		Time:       exchange.Time,
+		Task:       string(exchange.Task),
+		Provider:   exchange.Provider,
+		Model:      exchange.Model,
+		DurationMS: exchange.Duration.Milliseconds(),
+		Error:      l.redact(exchange.Error),
+	}
+	var truncated [3]bool
+	entry.System, truncated[0] = l.text(exchange.System)
+	entry.Prompt, truncated[1] = l.text(exchange.Prompt)
+	entry.Response, truncated[2] = l.text(exchange.Response)
+	entry.Truncated = truncated[0] || truncated[1] || truncated[2]
+	l.add(entry)
+}
+
+// text redacts and truncates a prompt or response
+func (l *Log) text(s string) (string, bool) {
+	s = l.redact(s)
+	if len(s) <= l.maxTextSize {
+		return s, false
+	}
+	return strings.ToValidUTF8(s[:l.maxTextSize], ""), true
+}
+
+func (l *Log) redact(s string) string {
+	s = feedback.Redact(s)
+	for _, pattern := range l.patterns {
+		s = pattern.ReplaceAllString(s, redacted)
+	}
+	return s
+}
+
+func (l *Log) add(entry Entry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lastID++
+	entry.ID = l.lastID
+	l.entries[l.next] = entry
+	l.next = (l.next + 1) % len(l.entries)
+	if l.next == 0 {
+		l.full = true
+	}
+}
+
+// List returns the entries matching filter, newest first
+func (l *Log) List(filter Filter) []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n := l.next
+	if l.full {
+		n = len(l.entries)
+	}
+	result := []Entry{}
+	for i := 0; i < n; i++ {
+		entry := l.entries[(l.next-1-i+len(l.entries))%len(l.entries)]
+		if filter.Task != "" && filter.Task != entry.Task {
+			continue
+		}
+		if filter.Provider != "" && filter.Provider != entry.Provider {
+			continue
+		}
+		if filter.Model != "" && filter.Model != entry.Model {
+			continue
+		}
+		if filter.Failed && entry.Error == "" {
+			continue
+		}
+		result = append(result, entry)
+		if filter.Limit > 0 && len(result) == filter.Limit {
+			break
+		}
+	}
+	return result
+}
+
+// Get returns an entry by ID, if it's still kept
+func (l *Log) Get(id uint64) (Entry, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, entry := range l.entries {
+		if entry.ID == id && id != 0 {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Clear drops every entry
+func (l *Log) Clear() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	clear(l.entries)
+	l.next = 0
+	l.full = false
+}
@@ -0,0 +1,79 @@
+package promptlog
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDisabled(t *testing.T) {
+	assert.Nil(t, New(config.PromptLogConfig{}))
+}
+
+func TestObserve(t *testing.T) {
+	l := New(config.PromptLogConfig{
+		Enabled:        true,
+		MaxTextSize:    64,
+		RedactPatterns: []string{`customer-\d+`},
+	})
+	ctx := context.Background()
+	l.Observe(ctx, llm.Exchange{
+		Task: llm.TaskCommitMessage, Provider: "anthropic", Model: "sonnet",
+		System:   "Write a commit message",
+		Prompt:   "+API_KEY=sk-ant-REDACTED for customer-42",
+		Response: "Add key",
+		Duration: 1500 * time.Millisecond, Time: time.Now(),
+	})
+	l.Observe(ctx, llm.Exchange{Task: llm.TaskSummarization, Prompt: "not logged by default"})
+	l.Observe(ctx, llm.Exchange{
+		Task: llm.TaskEphemeralChat, Provider: "claude_code", Model: "sonnet",
+		Prompt: strings.Repeat("x", 100), Error: "provider not configured",
+	})
+
+	entries := l.List(Filter{})
+	require.Len(t, entries, 2)
+	chat, commit := entries[0], entries[1]
+
+	assert.Equal(t, uint64(1), commit.ID)
+	assert.Equal(t, "commit-message", commit.Task)
+	assert.Equal(t, int64(1500), commit.DurationMS)
+	assert.Equal(t, "Write a commit message", commit.System)
+	assert.NotContains(t, commit.Prompt, "sk-ant-")
+	assert.NotContains(t, commit.Prompt, "customer-42")
+	assert.Equal(t, "Add key", commit.Response)
+	assert.False(t, commit.Truncated)
+
+	assert.True(t, chat.Truncated)
+	assert.Len(t, chat.Prompt, 64)
+
+	assert.Equal(t, []Entry{chat}, l.List(Filter{Failed: true}))
+	assert.Equal(t, []Entry{commit}, l.List(Filter{Provider: "anthropic"}))
+	got, ok := l.Get(commit.ID)
+	assert.True(t, ok)
+	assert.Equal(t, commit, got)
+
+	l.Clear()
+	assert.Empty(t, l.List(Filter{}))
+	_, ok = l.Get(commit.ID)
+	assert.False(t, ok)
+}
+
+func TestRing(t *testing.T) {
+	l := New(config.PromptLogConfig{Enabled: true, BufferSize: 2, Tasks: []string{"review"}})
+	for _, model := range []string{"a", "b", "c"} {
+		l.Observe(context.Background(), llm.Exchange{Task: llm.TaskReview, Model: model})
+	}
+	l.Observe(context.Background(), llm.Exchange{Task: llm.TaskCommitMessage, Model: "d"})
+
+	entries := l.List(Filter{})
+	require.Len(t, entries, 2)
+	assert.Equal(t, "c", entries[0].Model)
+	assert.Equal(t, "b", entries[1].Model)
+	assert.Len(t, l.List(Filter{Limit: 1}), 1)
+}
@@ -0,0 +1,165 @@
+// Package prompts renders the text/template prompts the daemon sends to
+// models. Built-in templates can be overridden per name by placing a
+// <name>.tmpl file in the prompts directory; overrides are read on every
+// render, so edits take effect without restarting the daemon.
+package prompts
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Names of the built-in templates
+const (
+	CommitMessage       = "commit-message"
+	CommitMessageSystem = "commit-message-system"
+	EphemeralChat       = "ephemeral-chat"
+	SessionSummary      = "session-summary"
+	DecisionLog         = "decision-log"
+	MemoryFile          = "memory-file"
+	GitHubFix           = "github-fix"
+	GitHubReview        = "github-review"
+	DenialExpansion     = "denial-expansion"
+	Moderation          = "moderation"
+)
+
+//go:embed templates/*.tmpl
+var defaults embed.FS
+
+var funcs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+}
+
+// Info describes a template and where it is loaded from
+type Info struct {
+	Name string `json:"name"`
+	// Source is "default" or the path of the override file
+	Source string `json:"source"`
+	// Text is the template text in use; its leading comment documents the variables
+	Text    string `json:"text"`
+	Default string `json:"default"`
+}
+
+// Set renders named templates, preferring overrides in a directory
+type Set struct {
+	dir string
+}
+
+// NewSet creates a template set reading overrides from dir. An empty dir uses
+// only the built-in templates.
+func NewSet(dir string) *Set {
+	return &Set{dir: dir}
+}
+
+// Default returns a set using only the built-in templates
+func Default() *Set {
+	return &Set{}
+}
+
+// Render executes the named template with data. If an override fails to
+// parse or execute, the built-in template is used instead.
+func (s *Set) Render(name string, data any) (string, error) {
+	text, source, err := s.load(name)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := execute(name, text, data)
+	if err != nil && source != "default" {
+		slog.Warn("prompt template override failed, using default", "template", name, "path", source, "error", err)
+		text, err = defaultText(name)
+		if err != nil {
+			return "", err
+		}
+		out, err = execute(name, text, data)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(out, "\n"), nil
+}
+
+// Version identifies the text currently used for the named template: a short
+// hash, so answers from different overrides can be told apart
+func (s *Set) Version(name string) string {
+	text, _, err := s.load(name)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:6])
+}
+
+// List describes every built-in template and any override in effect
+func (s *Set) List() ([]Info, error) {
+	entries, err := fs.ReadDir(defaults, "templates")
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]Info, 0, len(entries))
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".tmpl")
+		def, err := defaultText(name)
+		if err != nil {
+			return nil, err
+		}
+		text, source, err := s.load(name)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, Info{Name: name, Source: source, Text: text, Default: def})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos, nil
+}
+
+// load returns the override text for name if one exists, else the default
+func (s *Set) load(name string) (text, source string, err error) {
+	if s.dir != "" {
+		path := filepath.Join(s.dir, name+".tmpl")
+		data, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			return string(data), path, nil
+		case !errors.Is(err, fs.ErrNotExist):
+			slog.Warn("failed to read prompt template override", "path", path, "error", err)
+		}
+	}
+	text, err = defaultText(name)
+	return text, "default", err
+}
+
+func defaultText(name string) (string, error) {
+	data, err := defaults.ReadFile("templates/" + name + ".tmpl")
+	if err != nil {
+		return "", fmt.Errorf("unknown prompt template %q", name)
+	}
+	return string(data), nil
+}
+
+func execute(name, text string, data any) (string, error) {
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse prompt template %q: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt template %q: %w", name, err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,70 @@
+package prompts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type chatData struct {
+	Question           string
+	Query              string
+	Summary            string
+	WorkingDir         string
+	Status             string
+	RecentConversation []string
+	Language           string
+}
+
+func TestRenderDefault(t *testing.T) {
+	out, err := Default().Render(EphemeralChat, chatData{
+		Question:           "why?",
+		Query:              "fix the bug",
+		Status:             "running",
+		RecentConversation: []string{"User: hi", "Tool Call: Bash"},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, out, "Session Query: fix the bug\nSession Status: running\n\nRecent Conversation:\nUser: hi\nTool Call: Bash\n\nUser's Question: why?")
+	assert.NotContains(t, out, "Variables:", "template comments must not be rendered")
+	assert.NotContains(t, out, "Working Directory")
+
+	out, err = Default().Render(EphemeralChat, chatData{Question: "why?", Language: "French"})
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(out, "This is an ephemeral chat.\nAnswer in French."), out)
+
+	_, err = Default().Render("missing", nil)
+	assert.Error(t, err)
+}
+
+func TestRenderOverride(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, EphemeralChat+".tmpl"), []byte("Réponds en français : {{ .Question | upper }}\n"), 0644))
+	// A broken override falls back to the default
+	require.NoError(t, os.WriteFile(filepath.Join(dir, CommitMessageSystem+".tmpl"), []byte("{{ .Nope "), 0644))
+
+	set := NewSet(dir)
+
+	out, err := set.Render(EphemeralChat, chatData{Question: "why?"})
+	require.NoError(t, err)
+	assert.Equal(t, "Réponds en français : WHY?", out)
+
+	out, err = set.Render(CommitMessageSystem, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "You are a git commit message generator. Generate clear, conventional commit messages.", out)
+
+	infos, err := set.List()
+	require.NoError(t, err)
+	require.Len(t, infos, 10)
+	assert.Equal(t, CommitMessage, infos[0].Name)
+	assert.Equal(t, "default", infos[0].Source)
+	assert.Equal(t, EphemeralChat, infos[4].Name)
+	assert.Equal(t, filepath.Join(dir, EphemeralChat+".tmpl"), infos[4].Source)
+	assert.Contains(t, infos[3].Default, "Variables:")
+
+	assert.NotEqual(t, Default().Version(EphemeralChat), set.Version(EphemeralChat), "an override is a different version")
+	assert.Equal(t, Default().Version(CommitMessage), set.Version(CommitMessage))
+}
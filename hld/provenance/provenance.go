@@ -0,0 +1,137 @@
+// Package provenance records which session, model and prompt produced a
+// commit, as commit message trailers or a git note, signed so the record
+// can't be forged or moved to different code without the key.
+package provenance
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// Trailer keys, also used as the lines of a note
+const (
+	KeySession       = "HumanLayer-Session"
+	KeyModel         = "HumanLayer-Model"
+	KeyPromptSHA256  = "HumanLayer-Prompt-SHA256"
+	KeyDaemonVersion = "HumanLayer-Daemon-Version"
+	KeySignature     = "HumanLayer-Signature"
+)
+
+// signaturePrefix names the signature scheme
+const signaturePrefix = "hmac-sha256:"
+
+// Verification results
+const (
+	StatusVerified = "verified" // Signed, and the signature matches the record and the commit's tree
+	StatusUnsigned = "unsigned" // Not signed, or no key is configured to check it
+	StatusInvalid  = "invalid"  // The signature doesn't match; the record or code changed after signing
+	StatusMissing  = "missing"  // No provenance; not made by a session
+)
+
+// Record is the provenance of one commit
+type Record struct {
+	SessionID     string `json:"sessionId"`
+	Model         string `json:"model,omitempty"`
+	PromptSHA256  string `json:"promptSha256,omitempty"`
+	DaemonVersion string `json:"daemonVersion,omitempty"`
+	Signature     string `json:"signature,omitempty"`
+}
+
+// New returns the unsigned provenance of a commit made for session
+func New(session *store.Session, daemonVersion string) Record {
+	model := session.ModelID
+	if model == "" {
+		model = session.Model
+	}
+	sum := sha256.Sum256([]byte(session.Query))
+	return Record{
+		SessionID:     session.ID,
+		Model:         model,
+		PromptSHA256:  hex.EncodeToString(sum[:]),
+		DaemonVersion: daemonVersion,
+	}
+}
+
+// payload is what's signed: the record, and the tree it describes
+func (r Record) payload(tree string) []byte {
+	return []byte(fmt.Sprintf("session %s\nmodel %s\nprompt-sha256 %s\ndaemon-version %s\ntree %s\n",
+		r.SessionID, r.Model, r.PromptSHA256, r.DaemonVersion, tree))
+}
+
+func (r Record) mac(key []byte, tree string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(r.payload(tree))
+	return mac.Sum(nil)
+}
+
+// Sign signs the record for a commit of tree. Without a key the record is
+// left unsigned.
+func (r Record) Sign(key []byte, tree string) Record {
+	r.Signature = ""
+	if len(key) > 0 {
+		r.Signature = signaturePrefix + hex.EncodeToString(r.mac(key, tree))
+	}
+	return r
+}
+
+// Verify checks the record's signature against a commit of tree
+func (r Record) Verify(key []byte, tree string) string {
+	signature, ok := strings.CutPrefix(r.Signature, signaturePrefix)
+	if !ok || len(key) == 0 {
+		return StatusUnsigned
+	}
+	got, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(got, r.mac(key, tree)) {
+		return StatusInvalid
+	}
+	return StatusVerified
+}
+
+// Trailers renders the record as "Key: value" lines, for a commit message
+// or a note
+func (r Record) Trailers() string {
+	var b strings.Builder
+	for _, field := range []struct{ key, value string }{
+		{KeySession, r.SessionID},
+		{KeyModel, r.Model},
+		{KeyPromptSHA256, r.PromptSHA256},
+		{KeyDaemonVersion, r.DaemonVersion},
+		{KeySignature, r.Signature},
+	} {
+		if field.value != "" {
+			fmt.Fprintf(&b, "%s: %s\n", field.key, field.value)
+		}
+	}
+	return b.String()
+}
+
+// Parse reads a record from a commit message's trailers or a note,
+// reporting false if there is none. Where a key repeats, the last wins.
+func Parse(text string) (Record, bool) {
+	var r Record
+	for _, line := range strings.Split(text, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case KeySession:
+			r.SessionID = value
+		case KeyModel:
+			r.Model = value
+		case KeyPromptSHA256:
+			r.PromptSHA256 = value
+		case KeyDaemonVersion:
+			r.DaemonVersion = value
+		case KeySignature:
+			r.Signature = value
+		}
+	}
+	return r, r.SessionID != ""
+}
@@ -0,0 +1,40 @@
+package provenance
+
+import (
+	"testing"
+
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	key := []byte("s3cret")
+	tree := "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+	record := New(&store.Session{ID: "sess-1", Model: "opus", Query: "fix the bug"}, "1.2.3").Sign(key, tree)
+	assert.Equal(t, "opus", record.Model)
+	assert.Equal(t, "1.2.3", record.DaemonVersion)
+	assert.Len(t, record.PromptSHA256, 64)
+	assert.Equal(t, StatusVerified, record.Verify(key, tree))
+
+	assert.Equal(t, StatusInvalid, record.Verify(key, "other-tree"), "the signature covers the code")
+	assert.Equal(t, StatusInvalid, record.Verify([]byte("other"), tree))
+	tampered := record
+	tampered.SessionID = "sess-2"
+	assert.Equal(t, StatusInvalid, tampered.Verify(key, tree))
+	assert.Equal(t, StatusUnsigned, record.Verify(nil, tree), "without a key nothing can be checked")
+	assert.Equal(t, StatusUnsigned, record.Sign(nil, tree).Verify(key, tree))
+}
+
+func TestTrailersRoundTrip(t *testing.T) {
+	record := Record{SessionID: "sess-1", Model: "opus", PromptSHA256: "abc", DaemonVersion: "1.2.3", Signature: "hmac-sha256:00"}
+	message := "Fix the bug\n\nLonger explanation.\n\n" + record.Trailers()
+	assert.Contains(t, message, "HumanLayer-Session: sess-1\nHumanLayer-Model: opus\n")
+
+	parsed, ok := Parse(message)
+	require.True(t, ok)
+	assert.Equal(t, record, parsed)
+
+	_, ok = Parse("Fix the bug\n\nSigned-off-by: Ada <ada@example.com>\n")
+	assert.False(t, ok)
+}
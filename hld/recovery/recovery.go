@@ -0,0 +1,181 @@
+// Package recovery reconciles the store with reality when the daemon
+// starts. A daemon that crashed or was killed leaves sessions marked active
+// whose Claude processes died with it, and approvals nobody is waiting on.
+package recovery
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/session"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// Messages recorded on what recovery changes
+const (
+	interruptedMessage = "daemon stopped while the session was active"
+	queuedMessage      = "daemon stopped before the queued session started"
+	approvalComment    = "daemon stopped before the approval was decided"
+	resumeQuery        = "The session was interrupted because the HumanLayer daemon stopped. Continue the task from where you left off."
+)
+
+// Report is what Recover found and changed
+type Report struct {
+	Interrupted []string // Sessions that were active, now interrupted
+	Failed      []string // Queued sessions, which can't be continued
+	Approvals   []string // Pending approvals denied
+	Resumable   []string // Interrupted sessions the policy resumes
+}
+
+// Recoverer reconciles sessions and approvals left by a previous daemon
+type Recoverer struct {
+	store    store.ConversationStore
+	sessions session.SessionManager
+	eventBus bus.EventBus
+	config   config.RecoveryConfig
+	now      func() time.Time
+}
+
+// NewRecoverer creates a recoverer. sessions and eventBus may be nil, in
+// which case nothing is resumed or published.
+func NewRecoverer(s store.ConversationStore, sessions session.SessionManager, eventBus bus.EventBus, cfg config.RecoveryConfig) *Recoverer {
+	return &Recoverer{store: s, sessions: sessions, eventBus: eventBus, config: cfg, now: time.Now}
+}
+
+// Recover must run before any session is launched: every session it finds
+// active belongs to a process that is gone. Active sessions become
+// interrupted, so they can be continued; queued ones, which never ran,
+// fail. Pending approvals are denied, since the tool calls waiting on them
+// died with their sessions.
+func (r *Recoverer) Recover(ctx context.Context) (*Report, error) {
+	report := &Report{}
+
+	approvals, err := r.store.ListPendingApprovals(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending approvals: %w", err)
+	}
+	for _, approval := range approvals {
+		if err := r.denyApproval(ctx, approval); err != nil {
+			slog.Error("failed to deny orphaned approval", "approval_id", approval.ID, "error", err)
+			continue
+		}
+		report.Approvals = append(report.Approvals, approval.ID)
+	}
+
+	sessions, err := r.store.ListSessions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	now := r.now()
+	for _, sess := range sessions {
+		var status, message string
+		switch sess.Status {
+		case store.SessionStatusRunning, store.SessionStatusWaitingInput,
+			store.SessionStatusStarting, store.SessionStatusInterrupting:
+			status, message = store.SessionStatusInterrupted, interruptedMessage
+		case store.SessionStatusQueued:
+			status, message = store.SessionStatusFailed, queuedMessage
+		default:
+			continue
+		}
+		if err := r.store.UpdateSession(ctx, sess.ID, store.SessionUpdate{
+			Status:       &status,
+			CompletedAt:  &now,
+			ErrorMessage: &message,
+		}); err != nil {
+			slog.Error("failed to recover session", "session_id", sess.ID, "error", err)
+			continue
+		}
+		r.publish(bus.EventSessionStatusChanged, map[string]interface{}{
+			"session_id": sess.ID,
+			"run_id":     sess.RunID,
+			"old_status": sess.Status,
+			"new_status": status,
+			"recovered":  true,
+		})
+		if status == store.SessionStatusFailed {
+			report.Failed = append(report.Failed, sess.ID)
+			continue
+		}
+		report.Interrupted = append(report.Interrupted, sess.ID)
+		if r.resumable(sess, now) {
+			report.Resumable = append(report.Resumable, sess.ID)
+		}
+	}
+
+	if n := len(report.Interrupted) + len(report.Failed) + len(report.Approvals); n > 0 {
+		slog.Info("recovered state left by previous daemon",
+			"interrupted", len(report.Interrupted),
+			"failed", len(report.Failed),
+			"denied_approvals", len(report.Approvals),
+			"resumable", len(report.Resumable))
+	}
+	return report, nil
+}
+
+// resumable reports whether the policy continues an interrupted session
+func (r *Recoverer) resumable(sess *store.Session, now time.Time) bool {
+	if !r.config.AutoResume || sess.ClaudeSessionID == "" {
+		return false
+	}
+	if len(r.config.Templates) > 0 && !slices.Contains(r.config.Templates, sess.Template) {
+		return false
+	}
+	maxAge, err := r.config.MaxAgeDuration()
+	if err != nil {
+		return false
+	}
+	return now.Sub(sess.LastActivityAt) <= maxAge
+}
+
+// Resume continues the sessions in the report that the policy resumes. It
+// needs the daemon serving, since resumed sessions request approvals.
+func (r *Recoverer) Resume(ctx context.Context, report *Report) {
+	if r.sessions == nil {
+		return
+	}
+	for _, id := range report.Resumable {
+		resumed, err := r.sessions.ContinueSession(ctx, session.ContinueSessionConfig{
+			ParentSessionID: id,
+			Query:           resumeQuery,
+		})
+		if err != nil {
+			slog.Warn("failed to resume interrupted session", "session_id", id, "error", err)
+			continue
+		}
+		slog.Info("resumed interrupted session", "session_id", id, "new_session_id", resumed.ID)
+	}
+}
+
+func (r *Recoverer) denyApproval(ctx context.Context, approval *store.Approval) error {
+	if err := r.store.UpdateApprovalResponse(ctx, approval.ID, store.ApprovalStatusLocalDenied, approvalComment); err != nil {
+		return err
+	}
+	if err := r.store.UpdateApprovalStatus(ctx, approval.ID, store.ApprovalStatusDenied); err != nil {
+		slog.Warn("failed to update approval status in conversation events", "approval_id", approval.ID, "error", err)
+	}
+	data := map[string]interface{}{
+		"approval_id":   approval.ID,
+		"session_id":    approval.SessionID,
+		"approved":      false,
+		"response_text": approvalComment,
+		"recovered":     true,
+	}
+	if approval.ToolUseID != nil {
+		data["tool_use_id"] = *approval.ToolUseID
+	}
+	r.publish(bus.EventApprovalResolved, data)
+	return nil
+}
+
+func (r *Recoverer) publish(eventType bus.EventType, data map[string]interface{}) {
+	if r.eventBus == nil {
+		return
+	}
+	r.eventBus.Publish(bus.Event{Type: eventType, Timestamp: time.Now(), Data: data})
+}
@@ -0,0 +1,101 @@
+package recovery
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/session"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSessions records resumes instead of running Claude
+type fakeSessions struct {
+	session.SessionManager
+	continued []session.ContinueSessionConfig
+}
+
+func (f *fakeSessions) ContinueSession(ctx context.Context, req session.ContinueSessionConfig) (*session.Session, error) {
+	f.continued = append(f.continued, req)
+	return &session.Session{ID: "resumed-" + req.ParentSessionID}, nil
+}
+
+func TestRecover(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	s := store.NewInMemoryStore()
+	for _, sess := range []*store.Session{
+		{ID: "recent", RunID: "run-recent", ClaudeSessionID: "claude-1", Template: "ci", Status: store.SessionStatusRunning, LastActivityAt: now.Add(-time.Minute)},
+		{ID: "stale", RunID: "run-stale", ClaudeSessionID: "claude-2", Template: "ci", Status: store.SessionStatusWaitingInput, LastActivityAt: now.Add(-3 * time.Hour)},
+		{ID: "other-template", RunID: "run-other", ClaudeSessionID: "claude-3", Template: "review", Status: store.SessionStatusRunning, LastActivityAt: now},
+		{ID: "queued", RunID: "run-queued", Status: store.SessionStatusQueued, LastActivityAt: now},
+		{ID: "done", RunID: "run-done", Status: store.SessionStatusCompleted, LastActivityAt: now},
+	} {
+		sess.CreatedAt = now
+		require.NoError(t, s.CreateSession(ctx, sess))
+	}
+	require.NoError(t, s.CreateApproval(ctx, &store.Approval{
+		ID: "approval-1", RunID: "run-stale", SessionID: "stale", ToolName: "Bash", Status: store.ApprovalStatusLocalPending, CreatedAt: now,
+	}))
+
+	eventBus := bus.NewEventBus()
+	events := eventBus.Subscribe(ctx, bus.EventFilter{})
+	sessions := &fakeSessions{}
+	r := NewRecoverer(s, sessions, eventBus, config.RecoveryConfig{AutoResume: true, Templates: []string{"ci"}})
+
+	report, err := r.Recover(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"recent", "stale", "other-template"}, report.Interrupted)
+	assert.Equal(t, []string{"queued"}, report.Failed)
+	assert.Equal(t, []string{"approval-1"}, report.Approvals)
+	assert.Equal(t, []string{"recent"}, report.Resumable, "stale and other-template sessions stay interrupted")
+
+	recent, err := s.GetSession(ctx, "recent")
+	require.NoError(t, err)
+	assert.Equal(t, store.SessionStatusInterrupted, recent.Status)
+	assert.Equal(t, interruptedMessage, recent.ErrorMessage)
+	queued, err := s.GetSession(ctx, "queued")
+	require.NoError(t, err)
+	assert.Equal(t, store.SessionStatusFailed, queued.Status)
+	done, err := s.GetSession(ctx, "done")
+	require.NoError(t, err)
+	assert.Equal(t, store.SessionStatusCompleted, done.Status)
+
+	approval, err := s.GetApproval(ctx, "approval-1")
+	require.NoError(t, err)
+	assert.Equal(t, store.ApprovalStatusLocalDenied, approval.Status)
+	assert.Equal(t, approvalComment, approval.Comment)
+
+	// One approval and four session events
+	counts := map[bus.EventType]int{}
+	for i := 0; i < 5; i++ {
+		select {
+		case event := <-events.Channel:
+			counts[event.Type]++
+		case <-time.After(time.Second):
+			t.Fatal("missing recovery event")
+		}
+	}
+	assert.Equal(t, map[bus.EventType]int{bus.EventApprovalResolved: 1, bus.EventSessionStatusChanged: 4}, counts)
+
+	r.Resume(ctx, report)
+	require.Len(t, sessions.continued, 1)
+	assert.Equal(t, "recent", sessions.continued[0].ParentSessionID)
+}
+
+func TestRecoverWithoutAutoResume(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	require.NoError(t, s.CreateSession(ctx, &store.Session{
+		ID: "sess", RunID: "run", ClaudeSessionID: "claude", Status: store.SessionStatusRunning, CreatedAt: time.Now(), LastActivityAt: time.Now(),
+	}))
+
+	report, err := NewRecoverer(s, nil, nil, config.RecoveryConfig{}).Recover(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sess"}, report.Interrupted)
+	assert.Empty(t, report.Resumable)
+}
@@ -0,0 +1,197 @@
+// Package resolution binds approval resolutions that arrive from outside
+// the daemon, such as email replies and decisions forwarded by a federation
+// hub, to the approval they decide. A resolution token carries the approval
+// ID, a random nonce and an expiry, signed with HMAC-SHA256 together with a
+// hash of the request it came with. A token is good for one resolution;
+// tampered, expired and replayed resolutions are refused, logged and
+// recorded.
+package resolution
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/internal/keyfile"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// Channels resolutions arrive on
+const (
+	ChannelEmail      = "email"
+	ChannelFederation = "federation"
+	ChannelTwilio     = "twilio"
+)
+
+var (
+	// ErrRejected is wrapped by every reason a resolution is refused
+	ErrRejected = errors.New("resolution rejected")
+	// ErrInvalid is returned for malformed tokens and bad signatures
+	ErrInvalid = fmt.Errorf("%w: signature is invalid", ErrRejected)
+	// ErrExpired is returned for tokens past their expiry
+	ErrExpired = fmt.Errorf("%w: token has expired", ErrRejected)
+	// ErrReplayed is returned for tokens that were already used
+	ErrReplayed = fmt.Errorf("%w: token was already used", ErrRejected)
+)
+
+// nonceSize is the random part of a token, in bytes
+const nonceSize = 12
+
+// Claim is what a valid token vouches for
+type Claim struct {
+	ApprovalID string
+	Nonce      string
+	ExpiresAt  time.Time
+}
+
+// Signer issues and verifies tokens with one key
+type Signer struct {
+	keyFile string
+
+	mu  sync.Mutex
+	key []byte // loaded from keyFile on first use
+}
+
+// NewSigner creates a signer with key
+func NewSigner(key []byte) *Signer {
+	return &Signer{key: key}
+}
+
+// SignerFromFile creates a signer whose 32-byte key is kept in path,
+// generated on first use
+func SignerFromFile(path string) *Signer {
+	return &Signer{keyFile: path}
+}
+
+func (s *Signer) loadKey() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.key == nil {
+		key, err := keyfile.Load(s.keyFile, 32)
+		if err != nil {
+			return nil, fmt.Errorf("resolution key: %w", err)
+		}
+		s.key = key
+	}
+	return s.key, nil
+}
+
+// Issue returns a token for approvalID, good until ttl from now, for a
+// request carrying payload (nil when the request can't be known ahead, as
+// with an email reply). Tokens read "<approval id>.<nonce>.<expiry>.<mac>".
+func (s *Signer) Issue(approvalID string, payload []byte, ttl time.Duration) (string, error) {
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	claim := strings.Join([]string{
+		approvalID,
+		base64.RawURLEncoding.EncodeToString(nonce),
+		strconv.FormatInt(time.Now().Add(ttl).Unix(), 10),
+	}, ".")
+	mac, err := s.mac(claim, payload)
+	if err != nil {
+		return "", err
+	}
+	return claim + "." + mac, nil
+}
+
+// Verify checks a token's signature for payload and its expiry at now. An
+// expired token's claim is returned with ErrExpired, so the refusal can
+// name the approval.
+func (s *Signer) Verify(token string, payload []byte, now time.Time) (*Claim, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 || parts[0] == "" || parts[1] == "" {
+		return nil, ErrInvalid
+	}
+	expiry, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return nil, ErrInvalid
+	}
+	want, err := s.mac(strings.Join(parts[:3], "."), payload)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal([]byte(want), []byte(parts[3])) {
+		return nil, ErrInvalid
+	}
+	claim := &Claim{ApprovalID: parts[0], Nonce: parts[1], ExpiresAt: time.Unix(expiry, 0)}
+	if !now.Before(claim.ExpiresAt) {
+		return claim, ErrExpired
+	}
+	return claim, nil
+}
+
+func (s *Signer) mac(claim string, payload []byte) (string, error) {
+	key, err := s.loadKey()
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256(payload)
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("resolution:" + claim + "\n"))
+	mac.Write(digest[:])
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:16]), nil
+}
+
+// Ledger makes claims single-use and records refusals. A nil Ledger only
+// logs them.
+type Ledger struct {
+	store store.ResolutionStore
+}
+
+// NewLedger creates a ledger keeping used nonces and refusals in s
+func NewLedger(s store.ResolutionStore) *Ledger {
+	return &Ledger{store: s}
+}
+
+// Redeem uses a claim's nonce, refusing the resolution with ErrReplayed if
+// it was used before. source says who sent it, for the record.
+func (l *Ledger) Redeem(ctx context.Context, channel, source string, claim *Claim) error {
+	if l == nil {
+		return nil
+	}
+	err := l.store.UseResolutionNonce(ctx, channel+":"+claim.Nonce, claim.ApprovalID, claim.ExpiresAt)
+	if errors.Is(err, store.ErrNonceUsed) {
+		l.Reject(ctx, channel, source, claim.ApprovalID, ErrReplayed)
+		return ErrReplayed
+	}
+	return err
+}
+
+// Release lets a redeemed claim be used again, when its resolution was
+// refused before deciding anything and may be retried
+func (l *Ledger) Release(ctx context.Context, channel string, claim *Claim) {
+	if l == nil {
+		return
+	}
+	if err := l.store.ReleaseResolutionNonce(ctx, channel+":"+claim.Nonce); err != nil {
+		slog.Warn("failed to release resolution nonce", "channel", channel, "approval_id", claim.ApprovalID, "error", err)
+	}
+}
+
+// Reject logs and records a refused resolution
+func (l *Ledger) Reject(ctx context.Context, channel, source, approvalID string, reason error) {
+	slog.Warn("rejected approval resolution", "channel", channel, "source", source, "approval_id", approvalID, "reason", reason)
+	if l == nil {
+		return
+	}
+	err := l.store.RecordRejectedResolution(ctx, &store.RejectedResolution{
+		ApprovalID: approvalID,
+		Channel:    channel,
+		Source:     source,
+		Reason:     strings.TrimPrefix(reason.Error(), ErrRejected.Error()+": "),
+	})
+	if err != nil {
+		slog.Warn("failed to record rejected resolution", "channel", channel, "error", err)
+	}
+}
@@ -0,0 +1,91 @@
+package resolution
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigner(t *testing.T) {
+	signer := NewSigner(bytes.Repeat([]byte{7}, 32))
+	payload := []byte(`{"approved":true}`)
+	now := time.Now()
+
+	token, err := signer.Issue("appr-1", payload, time.Hour)
+	require.NoError(t, err)
+	claim, err := signer.Verify(token, payload, now)
+	require.NoError(t, err)
+	assert.Equal(t, "appr-1", claim.ApprovalID)
+	assert.NotEmpty(t, claim.Nonce)
+	assert.WithinDuration(t, now.Add(time.Hour), claim.ExpiresAt, 2*time.Second)
+
+	again, err := signer.Issue("appr-1", payload, time.Hour)
+	require.NoError(t, err)
+	assert.NotEqual(t, token, again, "each token has its own nonce")
+
+	parts := strings.Split(token, ".")
+	for name, forged := range map[string]string{
+		"other approval": "appr-2." + strings.Join(parts[1:], "."),
+		"later expiry":   strings.Join([]string{parts[0], parts[1], "9999999999", parts[3]}, "."),
+		"bad mac":        strings.Join(parts[:3], ".") + ".AAAAAAAAAAAAAAAAAAAAAA",
+		"truncated":      strings.Join(parts[:3], "."),
+		"empty":          "",
+	} {
+		_, err := signer.Verify(forged, payload, now)
+		assert.ErrorIs(t, err, ErrInvalid, name)
+	}
+	_, err = signer.Verify(token, []byte(`{"approved":false}`), now)
+	assert.ErrorIs(t, err, ErrInvalid, "a token is bound to its payload")
+	_, err = NewSigner(bytes.Repeat([]byte{8}, 32)).Verify(token, payload, now)
+	assert.ErrorIs(t, err, ErrInvalid, "a token is bound to its key")
+
+	claim, err = signer.Verify(token, payload, now.Add(2*time.Hour))
+	assert.ErrorIs(t, err, ErrExpired)
+	assert.ErrorIs(t, err, ErrRejected)
+	require.NotNil(t, claim, "an expired claim still names its approval")
+	assert.Equal(t, "appr-1", claim.ApprovalID)
+}
+
+func TestSignerFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "resolution.key")
+	token, err := SignerFromFile(path).Issue("appr-1", nil, time.Hour)
+	require.NoError(t, err)
+	_, err = SignerFromFile(path).Verify(token, nil, time.Now())
+	assert.NoError(t, err, "the key is kept across restarts")
+}
+
+func TestLedger(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	ledger := NewLedger(s)
+	claim := &Claim{ApprovalID: "appr-1", Nonce: "n1", ExpiresAt: time.Now().Add(time.Hour)}
+
+	require.NoError(t, ledger.Redeem(ctx, ChannelEmail, "ada@example.com", claim))
+	assert.ErrorIs(t, ledger.Redeem(ctx, ChannelEmail, "mallory@example.com", claim), ErrReplayed)
+	assert.NoError(t, ledger.Redeem(ctx, ChannelFederation, "10.0.0.2", claim), "nonces are per channel")
+
+	ledger.Release(ctx, ChannelEmail, claim)
+	assert.NoError(t, ledger.Redeem(ctx, ChannelEmail, "ada@example.com", claim), "a released claim can be used again")
+
+	ledger.Reject(ctx, ChannelEmail, "eve@example.com", "appr-2", ErrExpired)
+	rejected, err := s.ListRejectedResolutions(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, rejected, 2)
+	assert.Equal(t, "appr-1", rejected[0].ApprovalID)
+	assert.Equal(t, "mallory@example.com", rejected[0].Source)
+	assert.Equal(t, "token was already used", rejected[0].Reason)
+	assert.Equal(t, "token has expired", rejected[1].Reason)
+
+	var nilLedger *Ledger
+	assert.NoError(t, nilLedger.Redeem(ctx, ChannelEmail, "", claim))
+	assert.NoError(t, nilLedger.Redeem(ctx, ChannelEmail, "", claim), "a nil ledger doesn't track nonces")
+	nilLedger.Release(ctx, ChannelEmail, claim)
+	nilLedger.Reject(ctx, ChannelEmail, "", "appr-1", ErrInvalid)
+}
@@ -0,0 +1,280 @@
+// Package retry relaunches sessions that fail because of transient errors,
+// such as an overloaded API, according to per-template retry policies.
+package retry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	claudecode "github.com/humanlayer/humanlayer/claudecode-go"
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/session"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// DefaultTemplate is the policy key for sessions whose template has no entry
+const DefaultTemplate = "*"
+
+// resumeQuery is sent when continuing a conversation that failed
+const resumeQuery = "The previous attempt stopped because of a transient error. Continue the task from where you left off."
+
+// maxLineage bounds how far back a retry chain is followed
+const maxLineage = 100
+
+// transientErrors are case-insensitive substrings of error messages worth
+// retrying: API overload and rate limits, server errors and dropped connections
+var transientErrors = []string{
+	"overloaded",
+	"rate limit",
+	"rate_limit",
+	"api error: 429",
+	"api error: 5", // Claude CLI's "API Error: 5xx ..."
+	"500 internal server error",
+	"502 bad gateway",
+	"503 service unavailable",
+	"504 gateway timeout",
+	"econnreset",
+	"etimedout",
+	"econnrefused",
+	"socket hang up",
+	"connection reset",
+	"request timed out",
+}
+
+// IsTransient reports whether a session error is worth retrying. extra adds
+// substrings on top of the built-in ones.
+func IsTransient(errorMessage string, extra []string) bool {
+	msg := strings.ToLower(errorMessage)
+	if msg == "" {
+		return false
+	}
+	for _, pattern := range append(transientErrors, extra...) {
+		if pattern != "" && strings.Contains(msg, strings.ToLower(pattern)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Retrier watches for failed sessions and relaunches the transient failures
+type Retrier struct {
+	store    store.ConversationStore
+	sessions session.SessionManager
+	eventBus bus.EventBus
+	policies map[string]config.RetryPolicy
+	// processes tells crashed sessions apart for on_crash policies; nil if not kept
+	processes store.ProcessStore
+
+	scheduled sync.Map // failed session ID -> struct{}
+	sleep     func(ctx context.Context, d time.Duration) error
+}
+
+// NewRetrier creates a retrier applying policies keyed by template label
+func NewRetrier(s store.ConversationStore, sessions session.SessionManager, eventBus bus.EventBus, policies map[string]config.RetryPolicy) *Retrier {
+	return &Retrier{store: s, sessions: sessions, eventBus: eventBus, policies: policies, sleep: sleep}
+}
+
+// SetProcessStore lets on_crash policies see how sessions' processes exited
+func (r *Retrier) SetProcessStore(processes store.ProcessStore) {
+	r.processes = processes
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Run retries sessions as they fail until ctx is cancelled
+func (r *Retrier) Run(ctx context.Context) {
+	sub := r.eventBus.Subscribe(ctx, bus.EventFilter{
+		Types: []bus.EventType{bus.EventSessionStatusChanged},
+	})
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-sub.Channel:
+			if !ok {
+				return
+			}
+			status, _ := event.Data["new_status"].(string)
+			sessionID, _ := event.Data["session_id"].(string)
+			if status != store.SessionStatusFailed || sessionID == "" {
+				continue
+			}
+			go func() {
+				if _, err := r.Retry(ctx, sessionID); err != nil {
+					slog.Warn("failed to retry session", "session_id", sessionID, "error", err)
+				}
+			}()
+		}
+	}
+}
+
+// policyFor returns the policy for a template, falling back to the default
+func (r *Retrier) policyFor(template string) (config.RetryPolicy, bool) {
+	if policy, ok := r.policies[template]; ok {
+		return policy, policy.MaxAttempts > 0
+	}
+	policy, ok := r.policies[DefaultTemplate]
+	return policy, ok && policy.MaxAttempts > 0
+}
+
+// Retry waits out the policy's backoff and relaunches a failed session,
+// returning the new session. It returns nil without error when the session
+// isn't eligible: no policy, a non-transient error, attempts used up, or a
+// retry already scheduled.
+func (r *Retrier) Retry(ctx context.Context, sessionID string) (*session.Session, error) {
+	failed, err := r.store.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if failed.Status != store.SessionStatusFailed {
+		return nil, nil
+	}
+	policy, ok := r.policyFor(failed.Template)
+	if !ok || !(IsTransient(failed.ErrorMessage, policy.Match) || policy.OnCrash && r.crashed(ctx, sessionID)) {
+		return nil, nil
+	}
+	attempt, original, err := r.lineage(ctx, failed)
+	if err != nil {
+		return nil, err
+	}
+	if attempt > policy.MaxAttempts {
+		slog.Info("session retries exhausted", "session_id", sessionID, "attempts", policy.MaxAttempts)
+		return nil, nil
+	}
+	if _, dup := r.scheduled.LoadOrStore(sessionID, struct{}{}); dup {
+		return nil, nil
+	}
+
+	delay, err := policy.Delay(attempt)
+	if err != nil {
+		return nil, err
+	}
+	mode := policy.Mode
+	if mode == "" {
+		mode = config.RetryModeResume
+	}
+	if mode == config.RetryModeResume && failed.ClaudeSessionID == "" {
+		mode = config.RetryModeFresh
+	}
+
+	slog.Info("scheduling session retry", "session_id", sessionID, "attempt", attempt, "mode", mode, "delay", delay)
+	r.eventBus.Publish(bus.Event{
+		Type: bus.EventSessionRetryScheduled,
+		Data: map[string]interface{}{
+			"session_id":   sessionID,
+			"run_id":       failed.RunID,
+			"attempt":      attempt,
+			"max_attempts": policy.MaxAttempts,
+			"mode":         mode,
+			"delay_ms":     delay.Milliseconds(),
+			"error":        failed.ErrorMessage,
+		},
+	})
+	if err := r.sleep(ctx, delay); err != nil {
+		return nil, err
+	}
+
+	if mode == config.RetryModeResume {
+		return r.sessions.ContinueSession(ctx, session.ContinueSessionConfig{
+			ParentSessionID: sessionID,
+			Query:           resumeQuery,
+			RetryOf:         sessionID,
+		})
+	}
+	// Earlier resumed retries only carry the resume prompt, so start over
+	// from the original session's query
+	launch, err := freshConfig(original)
+	if err != nil {
+		return nil, err
+	}
+	launch.RetryOf = sessionID
+	return r.sessions.LaunchSession(ctx, launch, false)
+}
+
+// crashed reports whether a session's process was killed by a signal
+func (r *Retrier) crashed(ctx context.Context, sessionID string) bool {
+	if r.processes == nil {
+		return false
+	}
+	process, err := r.processes.GetSessionProcess(ctx, sessionID)
+	return err == nil && process.ExitSignal != ""
+}
+
+// lineage follows a failed session's retry chain back to the session that
+// started it. attempt numbers the retry the failed session would get: 1 for
+// an original session, one more for each retry before it.
+func (r *Retrier) lineage(ctx context.Context, failed *store.Session) (attempt int, original *store.Session, err error) {
+	attempt, original = 1, failed
+	for original.RetryOf != "" && attempt <= maxLineage {
+		previous, err := r.store.GetSession(ctx, original.RetryOf)
+		if errors.Is(err, store.ErrNotFound) {
+			break
+		}
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to follow retry lineage: %w", err)
+		}
+		attempt++
+		original = previous
+	}
+	return attempt, original, nil
+}
+
+// freshConfig rebuilds the launch of a session
+func freshConfig(sess *store.Session) (session.LaunchSessionConfig, error) {
+	config := session.LaunchSessionConfig{
+		SessionConfig: claudecode.SessionConfig{
+			Query:                sess.Query,
+			Model:                claudecode.Model(sess.Model),
+			WorkingDir:           sess.WorkingDir,
+			SystemPrompt:         sess.SystemPrompt,
+			AppendSystemPrompt:   sess.AppendSystemPrompt,
+			CustomInstructions:   sess.CustomInstructions,
+			PermissionPromptTool: sess.PermissionPromptTool,
+			MaxTurns:             sess.MaxTurns,
+			OutputFormat:         claudecode.OutputStreamJSON,
+		},
+		Title:                      sess.Title,
+		Template:                   sess.Template,
+		AutoAcceptEdits:            sess.AutoAcceptEdits,
+		DangerouslySkipPermissions: sess.DangerouslySkipPermissions,
+		AutoDenyAll:                sess.AutoDenyAll,
+		Container:                  sess.ContainerImage != "",
+		ProxyEnabled:               sess.ProxyEnabled,
+		ProxyBaseURL:               sess.ProxyBaseURL,
+		ProxyModelOverride:         sess.ProxyModelOverride,
+		ProxyAPIKey:                sess.ProxyAPIKey,
+	}
+	for field, value := range map[string]struct {
+		raw  string
+		dest *[]string
+	}{
+		"auto_deny_tools":        {sess.AutoDenyTools, &config.AutoDenyTools},
+		"additional_directories": {sess.AdditionalDirectories, &config.AdditionalDirectories},
+		"allowed_tools":          {sess.AllowedTools, &config.AllowedTools},
+		"disallowed_tools":       {sess.DisallowedTools, &config.DisallowedTools},
+	} {
+		if value.raw == "" {
+			continue
+		}
+		if err := json.Unmarshal([]byte(value.raw), value.dest); err != nil {
+			return config, fmt.Errorf("failed to decode %s: %w", field, err)
+		}
+	}
+	return config, nil
+}
@@ -0,0 +1,173 @@
+package retry
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/session"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSessions records retries as sessions in the store instead of running Claude
+type fakeSessions struct {
+	session.SessionManager
+	store     *store.MemoryStore
+	launched  []session.LaunchSessionConfig
+	continued []session.ContinueSessionConfig
+}
+
+func (f *fakeSessions) create(ctx context.Context, query, template, retryOf string) (*session.Session, error) {
+	id := fmt.Sprintf("retry-%d", len(f.launched)+len(f.continued))
+	err := f.store.CreateSession(ctx, &store.Session{
+		ID: id, RunID: "run-" + id, Query: query, Template: template, Status: store.SessionStatusRunning,
+		RetryOf: retryOf, CreatedAt: time.Now(),
+	})
+	return &session.Session{ID: id}, err
+}
+
+func (f *fakeSessions) LaunchSession(ctx context.Context, config session.LaunchSessionConfig, isDraft bool) (*session.Session, error) {
+	f.launched = append(f.launched, config)
+	return f.create(ctx, config.Query, config.Template, config.RetryOf)
+}
+
+func (f *fakeSessions) ContinueSession(ctx context.Context, req session.ContinueSessionConfig) (*session.Session, error) {
+	f.continued = append(f.continued, req)
+	parent, err := f.store.GetSession(ctx, req.ParentSessionID)
+	if err != nil {
+		return nil, err
+	}
+	return f.create(ctx, req.Query, parent.Template, req.RetryOf)
+}
+
+func newRetrier(s *store.MemoryStore, policies map[string]config.RetryPolicy) (*Retrier, *fakeSessions, *[]time.Duration) {
+	sessions := &fakeSessions{store: s}
+	r := NewRetrier(s, sessions, bus.NewEventBus(), policies)
+	var waits []time.Duration
+	r.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	return r, sessions, &waits
+}
+
+func fail(t *testing.T, s *store.MemoryStore, id string) {
+	t.Helper()
+	status, msg := store.SessionStatusFailed, "API Error: 529 overloaded_error"
+	require.NoError(t, s.UpdateSession(context.Background(), id, store.SessionUpdate{Status: &status, ErrorMessage: &msg}))
+}
+
+func TestIsTransient(t *testing.T) {
+	assert.True(t, IsTransient(`API Error: 529 {"type":"error","error":{"type":"overloaded_error"}}`, nil))
+	assert.True(t, IsTransient("API Error: 503 Service Unavailable", nil))
+	assert.True(t, IsTransient("read ECONNRESET", nil))
+	assert.True(t, IsTransient("flaky MCP server crashed", []string{"flaky mcp"}))
+	assert.False(t, IsTransient("daemon restarted while session was active", nil))
+	assert.False(t, IsTransient("", nil))
+}
+
+func TestRetryFreshUntilExhausted(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	require.NoError(t, s.CreateSession(ctx, &store.Session{
+		ID: "orig", RunID: "run-orig", Query: "fix the tests", Template: "ci", WorkingDir: "/repo",
+		AutoDenyTools: `["Bash"]`, Status: store.SessionStatusRunning, CreatedAt: time.Now(),
+	}))
+	fail(t, s, "orig")
+
+	r, sessions, waits := newRetrier(s, map[string]config.RetryPolicy{
+		"ci": {MaxAttempts: 2, Backoff: "1s", Mode: config.RetryModeFresh},
+	})
+
+	first, err := r.Retry(ctx, "orig")
+	require.NoError(t, err)
+	require.NotNil(t, first)
+	assert.Equal(t, "fix the tests", sessions.launched[0].Query)
+	assert.Equal(t, []string{"Bash"}, sessions.launched[0].AutoDenyTools)
+	assert.Equal(t, "orig", sessions.launched[0].RetryOf)
+
+	// Retrying the same failure twice only launches once
+	again, err := r.Retry(ctx, "orig")
+	require.NoError(t, err)
+	assert.Nil(t, again)
+
+	fail(t, s, first.ID)
+	second, err := r.Retry(ctx, first.ID)
+	require.NoError(t, err)
+	require.NotNil(t, second)
+	assert.Equal(t, first.ID, sessions.launched[1].RetryOf)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, *waits)
+
+	fail(t, s, second.ID)
+	third, err := r.Retry(ctx, second.ID)
+	require.NoError(t, err)
+	assert.Nil(t, third, "max_attempts reached")
+	assert.Len(t, sessions.launched, 2)
+}
+
+func TestRetryResumeAndPolicySelection(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	for _, sess := range []*store.Session{
+		{ID: "resumable", ClaudeSessionID: "claude-1", Template: "other"},
+		{ID: "fresh", Template: "other"},
+		{ID: "disabled", ClaudeSessionID: "claude-2", Template: "off"},
+	} {
+		sess.Query, sess.Status, sess.CreatedAt = "q", store.SessionStatusRunning, time.Now()
+		require.NoError(t, s.CreateSession(ctx, sess))
+		fail(t, s, sess.ID)
+	}
+	r, sessions, _ := newRetrier(s, map[string]config.RetryPolicy{
+		DefaultTemplate: {MaxAttempts: 1},
+		"off":           {MaxAttempts: 0},
+	})
+
+	_, err := r.Retry(ctx, "resumable")
+	require.NoError(t, err)
+	require.Len(t, sessions.continued, 1)
+	assert.Equal(t, "resumable", sessions.continued[0].ParentSessionID)
+
+	// Without a Claude conversation to continue, resume falls back to fresh
+	_, err = r.Retry(ctx, "fresh")
+	require.NoError(t, err)
+	assert.Len(t, sessions.launched, 1)
+
+	retried, err := r.Retry(ctx, "disabled")
+	require.NoError(t, err)
+	assert.Nil(t, retried)
+}
+
+func TestRetryCrashedProcess(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	status, msg := store.SessionStatusFailed, "claude process failed: signal: killed"
+	for _, id := range []string{"oom", "exited"} {
+		require.NoError(t, s.CreateSession(ctx, &store.Session{
+			ID: id, ClaudeSessionID: "claude-" + id, Query: "q", Template: "ci", Status: store.SessionStatusRunning, CreatedAt: time.Now(),
+		}))
+		require.NoError(t, s.UpdateSession(ctx, id, store.SessionUpdate{Status: &status, ErrorMessage: &msg}))
+	}
+	killed, exitedWith := -1, 1
+	require.NoError(t, s.RecordSessionProcess(ctx, &store.SessionProcess{SessionID: "oom", PID: 10, ExitCode: &killed, ExitSignal: "killed"}))
+	require.NoError(t, s.RecordSessionProcess(ctx, &store.SessionProcess{SessionID: "exited", PID: 11, ExitCode: &exitedWith}))
+
+	r, sessions, _ := newRetrier(s, map[string]config.RetryPolicy{"ci": {MaxAttempts: 1, OnCrash: true}})
+	retried, err := r.Retry(ctx, "oom")
+	require.NoError(t, err)
+	assert.Nil(t, retried, "crashes aren't told apart without process records")
+
+	r.SetProcessStore(s)
+	_, err = r.Retry(ctx, "oom")
+	require.NoError(t, err)
+	require.Len(t, sessions.continued, 1)
+	assert.Equal(t, "oom", sessions.continued[0].ParentSessionID)
+
+	retried, err = r.Retry(ctx, "exited")
+	require.NoError(t, err)
+	assert.Nil(t, retried, "a process that exited on its own didn't crash")
+}
@@ -0,0 +1,261 @@
+// Package scheduler runs the daemon's periodic maintenance, such as deleting
+// expired artifacts or sending digests, each task on its own interval. It
+// remembers how each task's last run went and lets a task be run by hand,
+// for the admin API.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/config"
+)
+
+var (
+	// ErrUnknownTask is returned for triggering a task that isn't registered
+	ErrUnknownTask = errors.New("unknown maintenance task")
+	// ErrTaskRunning is returned for triggering a task that is already running
+	ErrTaskRunning = errors.New("maintenance task is already running")
+	// ErrNotStarted is returned for triggering a task before Run
+	ErrNotStarted = errors.New("scheduler is not running")
+)
+
+// How a run was started
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+// Run outcomes
+const (
+	RunOK     = "ok"
+	RunFailed = "failed"
+)
+
+// Task is a piece of maintenance run every Interval
+type Task struct {
+	Name        string
+	Description string
+	Interval    time.Duration
+	// RunAtStart runs the task as the scheduler starts rather than one
+	// interval later
+	RunAtStart bool
+	Run        func(ctx context.Context) error
+}
+
+// TaskRun is one run of a task
+type TaskRun struct {
+	Trigger    string    `json:"trigger"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMS int64     `json:"duration_ms"`
+	Status     string    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// TaskStatus is a task's schedule and how its runs have gone since the
+// daemon started
+type TaskStatus struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Interval is a Go duration such as "1h0m0s"
+	Interval string `json:"interval"`
+	// Disabled tasks only run by hand
+	Disabled bool       `json:"disabled"`
+	Running  bool       `json:"running"`
+	NextRun  *time.Time `json:"next_run,omitempty"`
+	LastRun  *TaskRun   `json:"last_run,omitempty"`
+	Runs     int        `json:"runs"`
+	Failures int        `json:"failures"`
+}
+
+type entry struct {
+	task   Task
+	status TaskStatus
+}
+
+// Scheduler runs registered tasks on their intervals
+type Scheduler struct {
+	overrides map[string]config.MaintenanceTaskConfig
+	now       func() time.Time
+
+	mu      sync.Mutex
+	tasks   map[string]*entry
+	ctx     context.Context // set by Run, for runs started by hand
+	running sync.WaitGroup
+}
+
+// New creates a scheduler, with task schedules overridden by the
+// maintenance config
+func New(overrides map[string]config.MaintenanceTaskConfig) *Scheduler {
+	return &Scheduler{overrides: overrides, now: time.Now, tasks: make(map[string]*entry)}
+}
+
+// Register adds a task, applying its configured interval. Tasks registered
+// after Run starts aren't scheduled.
+func (s *Scheduler) Register(task Task) {
+	override := s.overrides[task.Name]
+	if interval, err := time.ParseDuration(override.Interval); err == nil && interval > 0 {
+		task.Interval = interval
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tasks[task.Name] = &entry{
+		task: task,
+		status: TaskStatus{
+			Name:        task.Name,
+			Description: task.Description,
+			Interval:    task.Interval.String(),
+			Disabled:    override.Disabled,
+		},
+	}
+}
+
+// Run runs each enabled task on its interval until ctx is cancelled, then
+// waits for runs in progress to stop
+func (s *Scheduler) Run(ctx context.Context) {
+	s.mu.Lock()
+	s.ctx = ctx
+	var scheduled []*entry
+	for _, e := range s.tasks {
+		if !e.status.Disabled {
+			scheduled = append(scheduled, e)
+		}
+	}
+	s.mu.Unlock()
+
+	var loops sync.WaitGroup
+	for _, e := range scheduled {
+		loops.Add(1)
+		go func() {
+			defer loops.Done()
+			s.loop(ctx, e)
+		}()
+	}
+	<-ctx.Done()
+	loops.Wait()
+	s.mu.Lock()
+	s.ctx = nil
+	s.mu.Unlock()
+	s.running.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, e *entry) {
+	delay := e.task.Interval
+	if e.task.RunAtStart {
+		delay = 0
+	}
+	for {
+		next := s.now().Add(delay)
+		s.mu.Lock()
+		e.status.NextRun = &next
+		s.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		// A run started by hand that's still going stands in for this one
+		if s.start(e) {
+			s.run(ctx, e, TriggerSchedule)
+		}
+		delay = e.task.Interval
+	}
+}
+
+// start marks a task running, reporting false if it already was
+func (s *Scheduler) start(e *entry) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e.status.Running {
+		return false
+	}
+	e.status.Running = true
+	return true
+}
+
+func (s *Scheduler) run(ctx context.Context, e *entry, trigger string) {
+	started := s.now()
+	err := safeRun(ctx, e.task.Run)
+	run := &TaskRun{Trigger: trigger, StartedAt: started, DurationMS: s.now().Sub(started).Milliseconds(), Status: RunOK}
+	if err != nil {
+		run.Status, run.Error = RunFailed, err.Error()
+		slog.Warn("maintenance task failed", "task", e.task.Name, "trigger", trigger, "duration_ms", run.DurationMS, "error", err)
+	} else {
+		slog.Debug("maintenance task finished", "task", e.task.Name, "trigger", trigger, "duration_ms", run.DurationMS)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e.status.Running = false
+	e.status.LastRun = run
+	e.status.Runs++
+	if err != nil {
+		e.status.Failures++
+	}
+}
+
+// safeRun keeps a panicking task from taking the daemon down
+func safeRun(ctx context.Context, fn func(context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return fn(ctx)
+}
+
+// Trigger starts a run of a task now, in the background, disabled tasks
+// included, and returns the task's status as it starts
+func (s *Scheduler) Trigger(name string) (TaskStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.tasks[name]
+	switch {
+	case !ok:
+		return TaskStatus{}, ErrUnknownTask
+	case s.ctx == nil || s.ctx.Err() != nil:
+		return TaskStatus{}, ErrNotStarted
+	case e.status.Running:
+		return e.status, ErrTaskRunning
+	}
+	e.status.Running = true
+	slog.Info("maintenance task triggered by hand", "task", name)
+	ctx := s.ctx
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+		s.run(ctx, e, TriggerManual)
+	}()
+	return e.status, nil
+}
+
+// Tasks returns the status of every task, by name
+func (s *Scheduler) Tasks() []TaskStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	statuses := make([]TaskStatus, 0, len(s.tasks))
+	for _, e := range s.tasks {
+		statuses = append(statuses, e.status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// Task returns the status of one task
+func (s *Scheduler) Task(name string) (TaskStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.tasks[name]
+	if !ok {
+		return TaskStatus{}, ErrUnknownTask
+	}
+	return e.status, nil
+}
@@ -0,0 +1,115 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduler(t *testing.T) {
+	s := New(map[string]config.MaintenanceTaskConfig{
+		"fast":     {Interval: "10ms"},
+		"disabled": {Disabled: true},
+	})
+	var fastRuns, failingRuns, disabledRuns atomic.Int32
+	s.Register(Task{Name: "fast", Interval: time.Hour, Run: func(ctx context.Context) error {
+		fastRuns.Add(1)
+		return nil
+	}})
+	s.Register(Task{Name: "failing", Interval: time.Hour, RunAtStart: true, Run: func(ctx context.Context) error {
+		failingRuns.Add(1)
+		return errors.New("store unavailable")
+	}})
+	s.Register(Task{Name: "disabled", Interval: 10 * time.Millisecond, Run: func(ctx context.Context) error {
+		disabledRuns.Add(1)
+		return nil
+	}})
+	release := make(chan struct{})
+	s.Register(Task{Name: "slow", Interval: time.Hour, Run: func(ctx context.Context) error {
+		<-release
+		return nil
+	}})
+
+	_, err := s.Trigger("fast")
+	assert.ErrorIs(t, err, ErrNotStarted)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool { return fastRuns.Load() >= 2 }, 2*time.Second, 5*time.Millisecond, "the configured interval applies")
+	require.Eventually(t, func() bool { return failingRuns.Load() == 1 }, 2*time.Second, 5*time.Millisecond, "runs at start")
+
+	failing, err := s.Task("failing")
+	require.NoError(t, err)
+	require.NotNil(t, failing.LastRun)
+	assert.Equal(t, RunFailed, failing.LastRun.Status)
+	assert.Equal(t, TriggerSchedule, failing.LastRun.Trigger)
+	assert.Equal(t, "store unavailable", failing.LastRun.Error)
+	assert.Equal(t, 1, failing.Failures)
+	require.NotNil(t, failing.NextRun)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), *failing.NextRun, time.Minute)
+
+	// Disabled tasks never run on their own, but can be run by hand
+	assert.Zero(t, disabledRuns.Load())
+	_, err = s.Trigger("disabled")
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return disabledRuns.Load() == 1 }, 2*time.Second, 5*time.Millisecond)
+	require.Eventually(t, func() bool {
+		status, _ := s.Task("disabled")
+		return status.LastRun != nil
+	}, 2*time.Second, 5*time.Millisecond)
+	disabled, _ := s.Task("disabled")
+	assert.True(t, disabled.Disabled)
+	assert.Equal(t, TriggerManual, disabled.LastRun.Trigger)
+	assert.Equal(t, RunOK, disabled.LastRun.Status)
+
+	status, err := s.Trigger("slow")
+	require.NoError(t, err)
+	assert.True(t, status.Running)
+	_, err = s.Trigger("slow")
+	assert.ErrorIs(t, err, ErrTaskRunning, "runs don't overlap")
+	_, err = s.Trigger("missing")
+	assert.ErrorIs(t, err, ErrUnknownTask)
+
+	tasks := s.Tasks()
+	require.Len(t, tasks, 4)
+	assert.Equal(t, "disabled", tasks[0].Name)
+	assert.Equal(t, "10ms", tasks[2].Interval)
+
+	close(release)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("scheduler didn't stop")
+	}
+	_, err = s.Trigger("fast")
+	assert.ErrorIs(t, err, ErrNotStarted)
+}
+
+func TestSchedulerRecoversPanics(t *testing.T) {
+	s := New(nil)
+	s.Register(Task{Name: "panics", Interval: time.Hour, RunAtStart: true, Run: func(ctx context.Context) error {
+		panic("boom")
+	}})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	require.Eventually(t, func() bool {
+		status, _ := s.Task("panics")
+		return status.LastRun != nil
+	}, 2*time.Second, 5*time.Millisecond)
+	status, _ := s.Task("panics")
+	assert.Equal(t, "panic: boom", status.LastRun.Error)
+}
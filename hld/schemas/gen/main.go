@@ -0,0 +1,31 @@
+// Command gen writes the published JSON Schemas and the models generated
+// from them. It runs from the schemas package directory, via go generate.
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/humanlayer/humanlayer/hld/api"
+	"github.com/humanlayer/humanlayer/hld/schemas"
+)
+
+func main() {
+	spec, err := api.GetSwagger()
+	if err != nil {
+		log.Fatalf("failed to load OpenAPI spec: %v", err)
+	}
+	files, err := schemas.Generate(spec)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for path, data := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
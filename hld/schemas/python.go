@@ -0,0 +1,181 @@
+package schemas
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// pythonReserved are names a pydantic model field can't take
+var pythonReserved = []string{
+	"and", "as", "class", "copy", "def", "dict", "from", "global", "import", "in", "is",
+	"json", "lambda", "not", "or", "pass", "return", "schema", "validate", "with", "yield",
+}
+
+// Python generates pydantic models for the JSON payloads, with Literal
+// aliases for enumerations. Unknown fields are kept, so payloads from a
+// newer daemon still parse.
+func Python(defs []Def) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString(`# Code generated by hld/schemas; DO NOT EDIT.
+"""Models of the daemon's conversation events, approvals and sessions."""
+
+from __future__ import annotations
+
+from datetime import datetime
+from typing import Any, Literal
+
+from pydantic import BaseModel, ConfigDict
+
+
+class _Model(BaseModel):
+    model_config = ConfigDict(extra="allow", protected_namespaces=())
+`)
+	names := make([]string, 0, len(defs))
+	for _, def := range defs {
+		names = append(names, def.Name)
+		if isEnum(def.Schema) {
+			b.WriteString("\n\n")
+			pyComment(&b, "", def.Schema.Description)
+			fmt.Fprintf(&b, "%s = Literal[%s]\n", def.Name, pyList(def.Schema.Enum))
+			continue
+		}
+		if def.Schema.Type != "object" {
+			return nil, fmt.Errorf("%s: unsupported top-level type %q", def.Name, def.Schema.Type)
+		}
+		fmt.Fprintf(&b, "\n\nclass %s(_Model):\n", def.Name)
+		if doc := def.Schema.Description; doc != "" {
+			fmt.Fprintf(&b, "    %s\n\n", pyDocstring(doc, "    "))
+		}
+		for _, name := range fields(def.Schema) {
+			if slices.Contains(pythonReserved, name) {
+				return nil, fmt.Errorf("%s.%s: reserved name in Python", def.Name, name)
+			}
+			prop := def.Schema.Props[name]
+			typ, err := pyType(def.Name, prop)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", def.Name, name, err)
+			}
+			pyComment(&b, "    ", prop.Description)
+			switch {
+			case slices.Contains(def.Schema.Required, name):
+				fmt.Fprintf(&b, "    %s: %s\n", name, typ)
+			case prop.Default != nil:
+				fmt.Fprintf(&b, "    %s: %s = %s\n", name, typ, pyLiteral(prop.Default))
+			default:
+				if !strings.HasSuffix(typ, "| None") {
+					typ += " | None"
+				}
+				fmt.Fprintf(&b, "    %s: %s = None\n", name, typ)
+			}
+		}
+	}
+	b.WriteString("\n\n__all__ = [\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "    %q,\n", name)
+	}
+	b.WriteString("]\n")
+	return b.Bytes(), nil
+}
+
+func pyType(self string, s *Schema) (string, error) {
+	var typ string
+	switch {
+	case s.Ref == "#":
+		typ = self
+	case s.Ref != "":
+		typ = DefName(s.Ref)
+	case len(s.Enum) > 0:
+		var values []any
+		for _, v := range s.Enum {
+			if v != nil {
+				values = append(values, v)
+			}
+		}
+		typ = "Literal[" + pyList(values) + "]"
+	case s.Type == "string" && s.Format == "date-time":
+		typ = "datetime"
+	case s.Type == "string":
+		typ = "str"
+	case s.Type == "integer":
+		typ = "int"
+	case s.Type == "number":
+		typ = "float"
+	case s.Type == "boolean":
+		typ = "bool"
+	case s.Type == "array":
+		if s.Items == nil {
+			typ = "list[Any]"
+			break
+		}
+		item, err := pyType(self, s.Items)
+		if err != nil {
+			return "", err
+		}
+		typ = "list[" + item + "]"
+	case s.Type == "object" && len(s.Props) == 0:
+		value := "Any"
+		if ap, ok := s.AdditionalProperties.(*Schema); ok {
+			var err error
+			if value, err = pyType(self, ap); err != nil {
+				return "", err
+			}
+		}
+		typ = "dict[str, " + value + "]"
+	default:
+		return "", fmt.Errorf("unsupported schema of type %q", s.Type)
+	}
+	if s.Nullable {
+		typ += " | None"
+	}
+	return typ, nil
+}
+
+func pyList(values []any) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = pyLiteral(v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func pyLiteral(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "None"
+	case bool:
+		if v {
+			return "True"
+		}
+		return "False"
+	case string:
+		return strconv.Quote(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func pyComment(b *bytes.Buffer, indent, text string) {
+	if text == "" {
+		return
+	}
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Fprintf(b, "%s# %s\n", indent, line)
+	}
+}
+
+func pyDocstring(text, indent string) string {
+	text = strings.ReplaceAll(text, `"""`, `\"\"\"`)
+	if !strings.Contains(text, "\n") {
+		return `"""` + text + `"""`
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines[1:] {
+		if line != "" {
+			lines[i+1] = indent + line
+		}
+	}
+	return `"""` + strings.Join(lines, "\n") + "\n" + indent + `"""`
+}
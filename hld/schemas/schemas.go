@@ -0,0 +1,302 @@
+// Package schemas publishes JSON Schemas for the payloads clients parse
+// most, conversation events, approvals and sessions, and generates the
+// TypeScript and Python models in sdk/models from them. The schemas are
+// derived from the OpenAPI spec, so they can't drift from what the daemon
+// serves; run `go generate ./schemas` after changing it.
+package schemas
+
+//go:generate go run ./gen
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Draft is the JSON Schema dialect the schemas are written in
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Published are the schemas published, each named for its file
+// (<name>.schema.json) and built from the OpenAPI component of that title
+var Published = []struct {
+	Name      string
+	Component string
+}{
+	{"conversation_event", "ConversationEvent"},
+	{"approval", "Approval"},
+	{"session", "Session"},
+}
+
+// Generated files, relative to this package's directory
+const (
+	TypeScriptFile = "../sdk/models/typescript/src/index.ts"
+	PythonFile     = "../sdk/models/python/hld_models/models.py"
+)
+
+//go:embed *.schema.json
+var files embed.FS
+
+// Get returns a published schema by name
+func Get(name string) ([]byte, error) {
+	return files.ReadFile(name + ".schema.json")
+}
+
+// Schema is a JSON Schema, limited to the keywords the spec uses
+type Schema struct {
+	Schema      string `json:"$schema,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Ref         string `json:"$ref,omitempty"`
+	// Type is written as [Type, "null"] when Nullable
+	Type     string             `json:"-"`
+	Nullable bool               `json:"-"`
+	Format   string             `json:"format,omitempty"`
+	Enum     []any              `json:"enum,omitempty"`
+	Default  any                `json:"default,omitempty"`
+	Minimum  *float64           `json:"minimum,omitempty"`
+	Items    *Schema            `json:"items,omitempty"`
+	Required []string           `json:"required,omitempty"`
+	Props    map[string]*Schema `json:"properties,omitempty"`
+	// AdditionalProperties is true or a *Schema
+	AdditionalProperties any                `json:"additionalProperties,omitempty"`
+	Examples             []any              `json:"examples,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// MarshalJSON writes nullable types the JSON Schema way
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	type plain Schema
+	out := struct {
+		Type any `json:"type,omitempty"`
+		*plain
+	}{plain: (*plain)(s)}
+	if s.Type != "" {
+		out.Type = s.Type
+		if s.Nullable {
+			out.Type = []string{s.Type, "null"}
+		}
+	}
+	return json.Marshal(out)
+}
+
+// DefName returns the name of the definition a $ref points to
+func DefName(ref string) string {
+	return strings.TrimPrefix(ref, "#/$defs/")
+}
+
+// Build converts an OpenAPI component to a JSON Schema, with the components
+// it references under $defs
+func Build(spec *openapi3.T, component string) (*Schema, error) {
+	ref, ok := spec.Components.Schemas[component]
+	if !ok {
+		return nil, fmt.Errorf("no %s schema in the spec", component)
+	}
+	c := converter{spec: spec, defs: make(map[string]*Schema), root: component}
+	root, err := c.convert(ref.Value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", component, err)
+	}
+	root.Schema = Draft
+	root.Title = component
+	if len(c.defs) > 0 {
+		root.Defs = c.defs
+	}
+	return root, nil
+}
+
+type converter struct {
+	spec *openapi3.T
+	defs map[string]*Schema
+	root string
+}
+
+func (c *converter) convert(s *openapi3.Schema) (*Schema, error) {
+	out := &Schema{
+		Description: strings.TrimSpace(s.Description),
+		Format:      s.Format,
+		Enum:        s.Enum,
+		Default:     s.Default,
+		Minimum:     s.Min,
+		Nullable:    s.Nullable,
+	}
+	if types := s.Type.Slice(); len(types) == 1 {
+		out.Type = types[0]
+	} else if len(types) > 1 {
+		return nil, fmt.Errorf("unsupported multiple types %v", types)
+	}
+	// OpenAPI 3.0 allows null in a nullable enum without listing it
+	if s.Nullable && len(s.Enum) > 0 {
+		out.Enum = append(append([]any{}, s.Enum...), nil)
+	}
+	if s.Example != nil {
+		out.Examples = []any{s.Example}
+	}
+	if s.Items != nil {
+		items, err := c.ref(s.Items)
+		if err != nil {
+			return nil, err
+		}
+		out.Items = items
+	}
+	if len(s.Properties) > 0 {
+		out.Props = make(map[string]*Schema, len(s.Properties))
+		for name, prop := range s.Properties {
+			converted, err := c.ref(prop)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			out.Props[name] = converted
+		}
+		out.Required = s.Required
+	}
+	switch ap := s.AdditionalProperties; {
+	case ap.Schema != nil:
+		converted, err := c.ref(ap.Schema)
+		if err != nil {
+			return nil, err
+		}
+		out.AdditionalProperties = converted
+	case ap.Has != nil && *ap.Has:
+		out.AdditionalProperties = true
+	}
+	return out, nil
+}
+
+// ref converts a schema that may reference a component, adding the
+// component to the definitions the first time it's seen
+func (c *converter) ref(ref *openapi3.SchemaRef) (*Schema, error) {
+	if ref.Ref == "" {
+		return c.convert(ref.Value)
+	}
+	name := ref.Ref[strings.LastIndex(ref.Ref, "/")+1:]
+	if name == c.root {
+		return &Schema{Ref: "#"}, nil
+	}
+	if _, seen := c.defs[name]; !seen {
+		c.defs[name] = nil // guards against cycles
+		def, err := c.convert(ref.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		c.defs[name] = def
+	}
+	return &Schema{Ref: "#/$defs/" + name}, nil
+}
+
+// Generate builds the published schemas and the models generated from
+// them, keyed by path relative to this package's directory
+func Generate(spec *openapi3.T) (map[string][]byte, error) {
+	out := make(map[string][]byte)
+	var roots []*Schema
+	for _, p := range Published {
+		schema, err := Build(spec, p.Component)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		out[p.Name+".schema.json"] = append(data, '\n')
+		roots = append(roots, schema)
+	}
+	defs := collect(roots)
+	ts, err := TypeScript(defs)
+	if err != nil {
+		return nil, err
+	}
+	py, err := Python(defs)
+	if err != nil {
+		return nil, err
+	}
+	out[TypeScriptFile] = ts
+	out[PythonFile] = py
+	return out, nil
+}
+
+// Def is a named type to generate
+type Def struct {
+	Name   string
+	Schema *Schema
+}
+
+// collect orders the roots and their definitions so each comes after the
+// definitions it references
+func collect(roots []*Schema) []Def {
+	all := make(map[string]*Schema)
+	for _, root := range roots {
+		all[root.Title] = root
+		for name, def := range root.Defs {
+			all[name] = def
+		}
+	}
+	var ordered []Def
+	done := make(map[string]bool)
+	var visit func(name string)
+	visit = func(name string) {
+		if done[name] {
+			return
+		}
+		done[name] = true
+		for _, dep := range refs(all[name]) {
+			visit(dep)
+		}
+		ordered = append(ordered, Def{Name: name, Schema: all[name]})
+	}
+	for _, root := range roots {
+		visit(root.Title)
+	}
+	return ordered
+}
+
+// refs returns the definitions s references directly, sorted
+func refs(s *Schema) []string {
+	seen := make(map[string]bool)
+	var walk func(s *Schema)
+	walk = func(s *Schema) {
+		if s == nil {
+			return
+		}
+		if s.Ref != "" && s.Ref != "#" {
+			seen[DefName(s.Ref)] = true
+		}
+		walk(s.Items)
+		for _, prop := range s.Props {
+			walk(prop)
+		}
+		if ap, ok := s.AdditionalProperties.(*Schema); ok {
+			walk(ap)
+		}
+	}
+	walk(s)
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// fields returns s's properties, required ones first in the order the spec
+// lists them, then the rest by name
+func fields(s *Schema) []string {
+	names := append([]string{}, s.Required...)
+	var optional []string
+	for name := range s.Props {
+		if !slices.Contains(s.Required, name) {
+			optional = append(optional, name)
+		}
+	}
+	sort.Strings(optional)
+	return append(names, optional...)
+}
+
+// isEnum reports whether a definition is a bare enumeration of strings
+func isEnum(s *Schema) bool {
+	return s.Type == "string" && len(s.Enum) > 0 && len(s.Props) == 0
+}
@@ -0,0 +1,56 @@
+package schemas
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/humanlayer/humanlayer/hld/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratedFilesUpToDate(t *testing.T) {
+	spec, err := api.GetSwagger()
+	require.NoError(t, err)
+	files, err := Generate(spec)
+	require.NoError(t, err)
+	for path, want := range files {
+		got, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, string(want), string(got), "%s is stale; run go generate ./schemas", path)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	spec, err := api.GetSwagger()
+	require.NoError(t, err)
+	schema, err := Build(spec, "ConversationEvent")
+	require.NoError(t, err)
+	data, err := json.Marshal(schema)
+	require.NoError(t, err)
+
+	var doc struct {
+		Schema     string `json:"$schema"`
+		Properties map[string]struct {
+			Type any   `json:"type"`
+			Enum []any `json:"enum"`
+		} `json:"properties"`
+	}
+	require.NoError(t, json.Unmarshal(data, &doc))
+	assert.Equal(t, Draft, doc.Schema)
+	status := doc.Properties["approval_status"]
+	assert.Equal(t, []any{"string", "null"}, status.Type)
+	assert.Contains(t, status.Enum, nil, "a nullable enum lists null")
+	assert.Equal(t, "integer", doc.Properties["id"].Type)
+
+	session, err := Build(spec, "Session")
+	require.NoError(t, err)
+	assert.Equal(t, "#/$defs/SessionStatus", session.Props["status"].Ref)
+	assert.Contains(t, session.Defs, "SessionStatus")
+
+	for _, p := range Published {
+		_, err := Get(p.Name)
+		assert.NoError(t, err)
+	}
+}
@@ -0,0 +1,156 @@
+package schemas
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// TypeScript generates interfaces for the JSON payloads, with string
+// literal unions for enumerations. Timestamps stay RFC 3339 strings.
+func TypeScript(defs []Def) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("// Code generated by hld/schemas; DO NOT EDIT.\n")
+	for _, def := range defs {
+		b.WriteString("\n")
+		tsComment(&b, "", def.Schema.Description)
+		if isEnum(def.Schema) {
+			fmt.Fprintf(&b, "export type %s = %s;\n\n", def.Name, tsUnion(def.Schema.Enum))
+			fmt.Fprintf(&b, "export const %sValues: readonly %s[] = [%s];\n", lowerFirst(def.Name), def.Name, tsList(def.Schema.Enum))
+			continue
+		}
+		if def.Schema.Type != "object" {
+			return nil, fmt.Errorf("%s: unsupported top-level type %q", def.Name, def.Schema.Type)
+		}
+		fmt.Fprintf(&b, "export interface %s {\n", def.Name)
+		for _, name := range fields(def.Schema) {
+			prop := def.Schema.Props[name]
+			typ, err := tsType(def.Name, prop)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", def.Name, name, err)
+			}
+			tsComment(&b, "  ", propDoc(prop))
+			optional := "?"
+			if slices.Contains(def.Schema.Required, name) {
+				optional = ""
+			}
+			fmt.Fprintf(&b, "  %s%s: %s;\n", name, optional, typ)
+		}
+		b.WriteString("}\n")
+	}
+	return b.Bytes(), nil
+}
+
+func tsType(self string, s *Schema) (string, error) {
+	var typ string
+	switch {
+	case s.Ref == "#":
+		typ = self
+	case s.Ref != "":
+		typ = DefName(s.Ref)
+	case len(s.Enum) > 0:
+		return tsUnion(s.Enum), nil // includes null when nullable
+	case s.Type == "string":
+		typ = "string"
+	case s.Type == "integer" || s.Type == "number":
+		typ = "number"
+	case s.Type == "boolean":
+		typ = "boolean"
+	case s.Type == "array":
+		if s.Items == nil {
+			typ = "unknown[]"
+			break
+		}
+		item, err := tsType(self, s.Items)
+		if err != nil {
+			return "", err
+		}
+		if strings.Contains(item, " ") {
+			item = "(" + item + ")"
+		}
+		typ = item + "[]"
+	case s.Type == "object" && len(s.Props) == 0:
+		value := "unknown"
+		if ap, ok := s.AdditionalProperties.(*Schema); ok {
+			var err error
+			if value, err = tsType(self, ap); err != nil {
+				return "", err
+			}
+		}
+		typ = "Record<string, " + value + ">"
+	default:
+		return "", fmt.Errorf("unsupported schema of type %q", s.Type)
+	}
+	if s.Nullable {
+		typ += " | null"
+	}
+	return typ, nil
+}
+
+func tsUnion(values []any) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = tsLiteral(v)
+	}
+	return strings.Join(parts, " | ")
+}
+
+func tsList(values []any) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = tsLiteral(v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func tsLiteral(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", `\'`) + "'"
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func tsComment(b *bytes.Buffer, indent, text string) {
+	if text == "" {
+		return
+	}
+	lines := strings.Split(text, "\n")
+	if len(lines) == 1 {
+		fmt.Fprintf(b, "%s/** %s */\n", indent, strings.ReplaceAll(text, "*/", "*\\/"))
+		return
+	}
+	fmt.Fprintf(b, "%s/**\n", indent)
+	for _, line := range lines {
+		fmt.Fprintf(b, "%s * %s\n", indent, strings.ReplaceAll(line, "*/", "*\\/"))
+	}
+	fmt.Fprintf(b, "%s */\n", indent)
+}
+
+// propDoc is a property's description, noting its format and default
+func propDoc(s *Schema) string {
+	doc := s.Description
+	var notes []string
+	if s.Format == "date-time" {
+		notes = append(notes, "RFC 3339 timestamp")
+	}
+	if s.Default != nil {
+		notes = append(notes, fmt.Sprintf("defaults to %v", s.Default))
+	}
+	if len(notes) == 0 {
+		return doc
+	}
+	note := strings.Join(notes, "; ")
+	if doc == "" {
+		return strings.ToUpper(note[:1]) + note[1:]
+	}
+	return doc + " (" + note + ")"
+}
+
+func lowerFirst(s string) string {
+	return strings.ToLower(s[:1]) + s[1:]
+}
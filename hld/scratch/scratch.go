@@ -0,0 +1,311 @@
+// Package scratch keeps a directory of files per session outside its git
+// repository, for plans, notes and intermediate artifacts. Paths are
+// resolved inside the session's directory only, and sizes are capped per
+// file and per session.
+package scratch
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/config"
+)
+
+// Errors reported for requests the scratch space refuses
+var (
+	ErrInvalidPath = errors.New("invalid path")
+	ErrNotFound    = errors.New("not found")
+	ErrIsDir       = errors.New("is a directory")
+	ErrTooLarge    = errors.New("file too large")
+	ErrQuota       = errors.New("session scratch space full")
+)
+
+// Dir is where scratch space is kept, next to the database
+func Dir(databasePath string) string {
+	return filepath.Join(filepath.Dir(databasePath), "scratch")
+}
+
+// File describes a file or directory in a session's scratch space
+type File struct {
+	// Path is relative to the session's scratch directory, with slashes
+	Path       string    `json:"path"`
+	Size       int64     `json:"size"`
+	IsDir      bool      `json:"isDir,omitempty"`
+	ModifiedAt time.Time `json:"modifiedAt"`
+}
+
+// Space is the scratch directories of all sessions
+type Space struct {
+	dir            string
+	maxFileSize    int64
+	maxSessionSize int64
+
+	// mu serialises writes, so concurrent writes can't both fit the quota
+	mu sync.Mutex
+}
+
+// New creates the scratch space under dir, with unset limits defaulted
+func New(dir string, cfg config.ScratchConfig) *Space {
+	s := &Space{dir: dir, maxFileSize: cfg.MaxFileSize, maxSessionSize: cfg.MaxSessionSize}
+	if s.maxFileSize <= 0 {
+		s.maxFileSize = config.DefaultScratchMaxFileSize
+	}
+	if s.maxSessionSize <= 0 {
+		s.maxSessionSize = config.DefaultScratchMaxSessionSize
+	}
+	return s
+}
+
+// MaxFileSize is the largest file that can be written
+func (s *Space) MaxFileSize() int64 { return s.maxFileSize }
+
+// clean validates a path relative to a session's directory, returning it
+// in slash form; "" is the directory itself
+func clean(name string) (string, error) {
+	name = strings.Trim(name, "/")
+	if name == "" {
+		return "", nil
+	}
+	if strings.ContainsAny(name, "\x00\\") {
+		return "", ErrInvalidPath
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == "" || part == "." || part == ".." {
+			return "", ErrInvalidPath
+		}
+	}
+	return path.Clean(name), nil
+}
+
+// root opens sessionID's directory, creating it if create is set. Opening
+// through os.Root keeps symlinks from leading out of it.
+func (s *Space) root(sessionID string, create bool) (*os.Root, error) {
+	if sessionID == "" || sessionID != filepath.Base(sessionID) || strings.HasPrefix(sessionID, ".") {
+		return nil, ErrInvalidPath
+	}
+	dir := filepath.Join(s.dir, sessionID)
+	if create {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, err
+		}
+	}
+	root, err := os.OpenRoot(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return root, err
+}
+
+func stat(root *os.Root, name string) (*File, error) {
+	info, err := root.Stat(nameOrDot(name))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return fileOf(name, info), nil
+}
+
+func fileOf(name string, info fs.FileInfo) *File {
+	file := &File{Path: name, IsDir: info.IsDir(), ModifiedAt: info.ModTime()}
+	if !file.IsDir {
+		file.Size = info.Size()
+	}
+	return file
+}
+
+// List returns the entries of a directory in sessionID's scratch space, by
+// name. A session without scratch files has an empty root.
+func (s *Space) List(sessionID, dir string) ([]File, error) {
+	dir, err := clean(dir)
+	if err != nil {
+		return nil, err
+	}
+	root, err := s.root(sessionID, false)
+	if errors.Is(err, ErrNotFound) && dir == "" {
+		return []File{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = root.Close() }()
+
+	f, err := root.Open(nameOrDot(dir))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	defer func() { _ = f.Close() }()
+	entries, err := f.ReadDir(-1)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]File, 0, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, *fileOf(path.Join(dir, entry.Name()), info))
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+// Open opens a file in sessionID's scratch space for reading
+func (s *Space) Open(sessionID, name string) (*os.File, *File, error) {
+	name, err := clean(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	root, err := s.root(sessionID, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = root.Close() }()
+	f, err := root.Open(nameOrDot(name))
+	if err != nil {
+		return nil, nil, notFound(err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, err
+	}
+	if info.IsDir() {
+		_ = f.Close()
+		return nil, nil, ErrIsDir
+	}
+	return f, fileOf(name, info), nil
+}
+
+// Write replaces name in sessionID's scratch space with what r holds,
+// creating parent directories
+func (s *Space) Write(sessionID, name string, r io.Reader) (*File, error) {
+	name, err := clean(name)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		return nil, ErrIsDir
+	}
+	// Read first, so a body that's too large leaves the old file alone
+	content, err := io.ReadAll(io.LimitReader(r, s.maxFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > s.maxFileSize {
+		return nil, fmt.Errorf("%w: the limit is %d bytes", ErrTooLarge, s.maxFileSize)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	root, err := s.root(sessionID, true)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = root.Close() }()
+
+	used, err := usage(root)
+	if err != nil {
+		return nil, err
+	}
+	if existing, err := root.Lstat(name); err == nil {
+		if existing.IsDir() {
+			return nil, ErrIsDir
+		}
+		used -= existing.Size()
+	}
+	if used+int64(len(content)) > s.maxSessionSize {
+		return nil, fmt.Errorf("%w: %d of %d bytes used", ErrQuota, used, s.maxSessionSize)
+	}
+
+	if err := mkdirAll(root, path.Dir(name)); err != nil {
+		return nil, err
+	}
+	f, err := root.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Write(content); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	return stat(root, name)
+}
+
+// Remove deletes a file, or an empty directory, in sessionID's scratch space
+func (s *Space) Remove(sessionID, name string) error {
+	name, err := clean(name)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return ErrInvalidPath
+	}
+	root, err := s.root(sessionID, false)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = root.Close() }()
+	return notFound(root.Remove(name))
+}
+
+// usage totals the size of the files under root
+func usage(root *os.Root) (int64, error) {
+	var total int64
+	err := fs.WalkDir(root.FS(), ".", func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.Type().IsRegular() {
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			total += info.Size()
+		}
+		return nil
+	})
+	return total, err
+}
+
+// mkdirAll creates dir and its parents inside root
+func mkdirAll(root *os.Root, dir string) error {
+	if dir == "." {
+		return nil
+	}
+	current := ""
+	for _, part := range strings.Split(dir, "/") {
+		current = path.Join(current, part)
+		err := root.Mkdir(current, 0700)
+		if err != nil && !errors.Is(err, fs.ErrExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+func nameOrDot(name string) string {
+	if name == "" {
+		return "."
+	}
+	return name
+}
+
+// notFound maps a missing file to ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,111 @@
+package scratch
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/humanlayer/humanlayer/hld/config"
+)
+
+func TestWriteReadList(t *testing.T) {
+	space := New(t.TempDir(), config.ScratchConfig{})
+	if files, err := space.List("sess-1", ""); err != nil || len(files) != 0 {
+		t.Fatalf("a new session should have no files, got %v, %v", files, err)
+	}
+
+	file, err := space.Write("sess-1", "/plans/step 1.md", strings.NewReader("# Plan\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Path != "plans/step 1.md" || file.Size != 7 {
+		t.Errorf("unexpected file %+v", file)
+	}
+
+	f, info, err := space.Open("sess-1", "plans/step 1.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := io.ReadAll(f)
+	_ = f.Close()
+	if string(content) != "# Plan\n" || info.Size != 7 {
+		t.Errorf("read back %q, %+v", content, info)
+	}
+
+	files, err := space.List("sess-1", "")
+	if err != nil || len(files) != 1 || files[0].Path != "plans" || !files[0].IsDir {
+		t.Errorf("unexpected listing %+v, %v", files, err)
+	}
+	if _, _, err := space.Open("sess-1", "plans"); !errors.Is(err, ErrIsDir) {
+		t.Errorf("opening a directory: %v", err)
+	}
+	if _, _, err := space.Open("sess-2", "plans/step 1.md"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("sessions should not see each other's files: %v", err)
+	}
+
+	if err := space.Remove("sess-1", "plans/step 1.md"); err != nil {
+		t.Fatal(err)
+	}
+	if err := space.Remove("sess-1", "plans/step 1.md"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("removing twice: %v", err)
+	}
+}
+
+func TestTraversal(t *testing.T) {
+	dir := t.TempDir()
+	space := New(filepath.Join(dir, "scratch"), config.ScratchConfig{})
+	if err := os.WriteFile(filepath.Join(dir, "secret"), []byte("secret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"../secret", "a/../../secret", "a//b", "./a", "a\\b", "nul\x00"} {
+		if _, err := space.Write("sess-1", name, strings.NewReader("x")); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("Write(%q) = %v, want ErrInvalidPath", name, err)
+		}
+	}
+	for _, session := range []string{"..", "../sess", ".hidden", ""} {
+		if _, err := space.List(session, ""); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("List(%q) = %v, want ErrInvalidPath", session, err)
+		}
+	}
+
+	// A symlink planted in the directory can't lead out of it
+	if _, err := space.Write("sess-1", "a.txt", strings.NewReader("x")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(dir, "secret"), filepath.Join(dir, "scratch", "sess-1", "link")); err != nil {
+		t.Fatal(err)
+	}
+	if f, _, err := space.Open("sess-1", "link"); err == nil {
+		_ = f.Close()
+		t.Error("opened a file outside the scratch space through a symlink")
+	}
+	if _, err := space.Write("sess-1", "link", strings.NewReader("overwrite")); err == nil {
+		t.Error("wrote outside the scratch space through a symlink")
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "secret")); string(content) != "secret" {
+		t.Errorf("file outside the scratch space changed: %q", content)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	space := New(t.TempDir(), config.ScratchConfig{MaxFileSize: 10, MaxSessionSize: 15})
+	if _, err := space.Write("sess-1", "big", strings.NewReader(strings.Repeat("x", 11))); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("expected ErrTooLarge, got %v", err)
+	}
+	if _, err := space.Write("sess-1", "a", strings.NewReader(strings.Repeat("x", 10))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := space.Write("sess-1", "b", strings.NewReader(strings.Repeat("x", 6))); !errors.Is(err, ErrQuota) {
+		t.Errorf("expected ErrQuota, got %v", err)
+	}
+	// Replacing a file only counts the difference
+	if _, err := space.Write("sess-1", "a", strings.NewReader(strings.Repeat("y", 9))); err != nil {
+		t.Errorf("replacing a file within the quota: %v", err)
+	}
+	if _, err := space.Write("sess-2", "b", strings.NewReader(strings.Repeat("x", 10))); err != nil {
+		t.Errorf("quotas are per session: %v", err)
+	}
+}
@@ -0,0 +1,4 @@
+"""Typed models of HumanLayer Daemon conversation events, approvals and sessions."""
+
+from hld_models.models import *
+from hld_models.models import __all__  # noqa: F401
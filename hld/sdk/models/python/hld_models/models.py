@@ -0,0 +1,296 @@
+# Code generated by hld/schemas; DO NOT EDIT.
+"""Models of the daemon's conversation events, approvals and sessions."""
+
+from __future__ import annotations
+
+from datetime import datetime
+from typing import Any, Literal
+
+from pydantic import BaseModel, ConfigDict
+
+
+class _Model(BaseModel):
+    model_config = ConfigDict(extra="allow", protected_namespaces=())
+
+
+class ConversationEvent(_Model):
+    id: int
+    session_id: str
+    # Sequence number in conversation
+    sequence: int
+    # Type of conversation event
+    event_type: Literal["message", "tool_call", "tool_result", "system", "thinking"]
+    created_at: datetime
+    # Associated approval ID
+    approval_id: str | None = None
+    # Approval status for tool calls
+    approval_status: Literal["pending", "approved", "denied", "resolved"] | None = None
+    claude_session_id: str | None = None
+    # Message content
+    content: str | None = None
+    # Whether tool call has received result
+    is_completed: bool = False
+    # Parent tool use ID for nested calls
+    parent_tool_use_id: str | None = None
+    # Message role (for message events)
+    role: Literal["user", "assistant", "system"] | None = None
+    # Tool invocation ID (for tool events)
+    tool_id: str | None = None
+    # JSON string of tool input (for tool_call events)
+    tool_input_json: str | None = None
+    # Tool name (for tool_call events)
+    tool_name: str | None = None
+    # Tool result content
+    tool_result_content: str | None = None
+    # Tool call ID this result is for
+    tool_result_for_id: str | None = None
+
+
+class ApprovalAttachment(_Model):
+    # Artifact ID
+    id: str
+    name: str
+    content_type: str
+    size: int
+    # Signed link to the content, valid for the configured artifacts link_ttl
+    download_url: str
+
+
+class ApprovalConstraints(_Model):
+    """Limits on what an approval allows, given when approving. They are sent
+    to the agent in the response metadata, and a reported tool result that
+    doesn't keep to them is recorded as a violation.
+    """
+
+    # When the approval stops being valid; must be in the future
+    expires_at: datetime | None = None
+    # The only files the tool call may read or write. Relative paths are resolved against the session's working directory.
+    files: list[str] | None = None
+    # How long the approval is valid from the decision. Only read when deciding.
+    valid_for_seconds: int | None = None
+
+
+# Current status of the approval
+ApprovalStatus = Literal["pending", "approved", "denied"]
+
+
+class InfraResourceChange(_Model):
+    # Terraform resource address, or Kubernetes kind and namespace/name
+    address: str
+    action: Literal["create", "update", "replace", "delete", "read"]
+    # Extra detail, such as diff line counts for kubectl
+    detail: str | None = None
+
+
+class InfraChangeSummary(_Model):
+    """Summary of a terraform plan or kubectl diff found in the tool input"""
+
+    # Tool that produced the plan or diff
+    tool: Literal["terraform", "kubectl"]
+    # Resources to create
+    add: int
+    # Resources to update in place
+    change: int
+    # Resources to destroy and recreate
+    replace: int
+    # Resources to delete
+    destroy: int
+    resources: list[InfraResourceChange]
+    # One-line description of the counts
+    summary: str
+
+
+class MigrationWarning(_Model):
+    # Migration file, empty for SQL passed to a database client
+    file: str
+    rule: Literal["destructive", "lock", "missing_down"]
+    severity: Literal["high", "medium"]
+    message: str
+    # Line the statement starts on
+    line: int | None = None
+    # The offending statement, truncated
+    statement: str | None = None
+
+
+class Approval(_Model):
+    # Unique approval identifier
+    id: str
+    # Associated run ID
+    run_id: str
+    # Associated session ID
+    session_id: str
+    status: ApprovalStatus
+    # Creation timestamp
+    created_at: datetime
+    # Tool requesting approval
+    tool_name: str
+    # Tool input parameters
+    tool_input: dict[str, Any]
+    # Files the request carries, such as a screenshot of the current screen
+    attachments: list[ApprovalAttachment] | None = None
+    # Approver's comment
+    comment: str | None = None
+    # AI-written guidance expanding a terse denial comment. The agent
+    # receives it after the comment, marked as AI-expanded.
+    comment_expansion: str | None = None
+    constraints: ApprovalConstraints | None = None
+    infra_summary: InfraChangeSummary | None = None
+    # Risky statements in a database migration or SQL the tool call would write or run
+    migration_warnings: list[MigrationWarning] | None = None
+    # Response timestamp
+    responded_at: datetime | None = None
+
+
+class SessionBudget(_Model):
+    """Resource limits for a session and the sessions it continues from.
+    Once any limit is exceeded, further approvals are denied. Zero or unset means unlimited.
+    """
+
+    # Maximum cost in USD across completed runs
+    max_cost_usd: float | None = None
+    # Maximum run time in seconds
+    max_duration_seconds: int | None = None
+    # Maximum number of tool calls
+    max_tool_calls: int | None = None
+
+
+class SessionCompletionSummary(_Model):
+    """Structured summary generated when the session finished"""
+
+    # What the session was asked to do
+    asked: str
+    # Changes the session made
+    changed: list[str]
+    # Questions left unanswered
+    open_questions: list[str]
+    # Suggested next steps
+    follow_ups: list[str]
+
+
+class SessionProcess(_Model):
+    """The Claude process the session ran in, only returned by GET /sessions/{id}. Resource figures are sampled every few seconds while it runs."""
+
+    pid: int
+    running: bool
+    started_at: datetime
+    # Resident memory at the last sample
+    rss_bytes: int
+    # Highest resident memory sampled
+    peak_rss_bytes: int
+    # CPU time used
+    cpu_seconds: float
+    # Share of one core used since the previous sample, while running
+    cpu_percent: float | None = None
+    # Exit code; -1 when the process was killed by a signal
+    exit_code: int | None = None
+    # Signal that killed the process
+    exit_signal: str | None = None
+    exited_at: datetime | None = None
+    # The last 4 KiB the process wrote to stderr
+    stderr_tail: str | None = None
+
+
+# Current status of the session
+SessionStatus = Literal["draft", "queued", "starting", "running", "completed", "failed", "interrupting", "interrupted", "waiting_input", "discarded"]
+
+
+class Session(_Model):
+    # Unique session identifier
+    id: str
+    # Unique run identifier
+    run_id: str
+    status: SessionStatus
+    # Initial query that started the session
+    query: str
+    # Session creation timestamp
+    created_at: datetime
+    # Last activity timestamp
+    last_activity_at: datetime
+    # Additional directories Claude can access
+    additional_directories: list[str] | None = None
+    # Whether session is archived
+    archived: bool = False
+    # Whether edit tools are auto-accepted
+    auto_accept_edits: bool = False
+    # Whether every approval request is denied automatically (observation-only session)
+    auto_deny_all: bool = False
+    # Tool names denied automatically; entries ending in "*" match by prefix
+    auto_deny_tools: list[str] | None = None
+    budget: SessionBudget | None = None
+    # Number of cache creation input tokens
+    cache_creation_input_tokens: int | None = None
+    # Number of cache read input tokens
+    cache_read_input_tokens: int | None = None
+    # Claude's internal session ID
+    claude_session_id: str | None = None
+    # Session completion timestamp
+    completed_at: datetime | None = None
+    completion_summary: SessionCompletionSummary | None = None
+    # Container image the agent runs in; absent when it runs on the host
+    container_image: str | None = None
+    # Context window limit for the model
+    context_limit: int | None = None
+    # ID of the context pack attached when the session was launched
+    context_pack_id: str | None = None
+    # Total cost in USD
+    cost_usd: float | None = None
+    # When true, all tool calls are automatically approved without user consent
+    dangerously_skip_permissions: bool = False
+    # ISO timestamp when dangerously skip permissions mode expires (optional)
+    dangerously_skip_permissions_expires_at: datetime | None = None
+    # Session duration in milliseconds
+    duration_ms: int | None = None
+    # JSON blob of editor state for draft sessions
+    editor_state: str | None = None
+    # Total tokens counting toward context window limit
+    effective_context_tokens: int | None = None
+    # Error message if session failed
+    error_message: str | None = None
+    # Number of input tokens
+    input_tokens: int | None = None
+    # Model used for this session
+    model: str | None = None
+    # Full model identifier
+    model_id: str | None = None
+    # Number of output tokens
+    output_tokens: int | None = None
+    # Parent session ID if this is a forked session
+    parent_session_id: str | None = None
+    process: SessionProcess | None = None
+    # Base URL of the proxy server
+    proxy_base_url: str | None = None
+    # Whether proxy is enabled for this session
+    proxy_enabled: bool = False
+    # Model to use with the proxy
+    proxy_model_override: str | None = None
+    # ID of the failed session this session automatically retries
+    retry_of: str | None = None
+    # Whether session has been reviewed/checked off
+    reviewed: bool = False
+    # SSH destination holding the working directory; git commands and file reads run there
+    ssh_host: str | None = None
+    # AI-generated summary of the session
+    summary: str | None = None
+    # Label of the saved template the session was launched from
+    template: str | None = None
+    # User-editable session title
+    title: str | None = None
+    # Working directory for the session
+    working_dir: str | None = None
+
+
+__all__ = [
+    "ConversationEvent",
+    "ApprovalAttachment",
+    "ApprovalConstraints",
+    "ApprovalStatus",
+    "InfraResourceChange",
+    "InfraChangeSummary",
+    "MigrationWarning",
+    "Approval",
+    "SessionBudget",
+    "SessionCompletionSummary",
+    "SessionProcess",
+    "SessionStatus",
+    "Session",
+]
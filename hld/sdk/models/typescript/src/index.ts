@@ -0,0 +1,271 @@
+// Code generated by hld/schemas; DO NOT EDIT.
+
+export interface ConversationEvent {
+  id: number;
+  session_id: string;
+  /** Sequence number in conversation */
+  sequence: number;
+  /** Type of conversation event */
+  event_type: 'message' | 'tool_call' | 'tool_result' | 'system' | 'thinking';
+  /** RFC 3339 timestamp */
+  created_at: string;
+  /** Associated approval ID */
+  approval_id?: string | null;
+  /** Approval status for tool calls */
+  approval_status?: 'pending' | 'approved' | 'denied' | 'resolved' | null;
+  claude_session_id?: string;
+  /** Message content */
+  content?: string;
+  /** Whether tool call has received result (defaults to false) */
+  is_completed?: boolean;
+  /** Parent tool use ID for nested calls */
+  parent_tool_use_id?: string;
+  /** Message role (for message events) */
+  role?: 'user' | 'assistant' | 'system';
+  /** Tool invocation ID (for tool events) */
+  tool_id?: string;
+  /** JSON string of tool input (for tool_call events) */
+  tool_input_json?: string;
+  /** Tool name (for tool_call events) */
+  tool_name?: string;
+  /** Tool result content */
+  tool_result_content?: string;
+  /** Tool call ID this result is for */
+  tool_result_for_id?: string;
+}
+
+export interface ApprovalAttachment {
+  /** Artifact ID */
+  id: string;
+  name: string;
+  content_type: string;
+  size: number;
+  /** Signed link to the content, valid for the configured artifacts link_ttl */
+  download_url: string;
+}
+
+/**
+ * Limits on what an approval allows, given when approving. They are sent
+ * to the agent in the response metadata, and a reported tool result that
+ * doesn't keep to them is recorded as a violation.
+ */
+export interface ApprovalConstraints {
+  /** When the approval stops being valid; must be in the future (RFC 3339 timestamp) */
+  expires_at?: string;
+  /** The only files the tool call may read or write. Relative paths are resolved against the session's working directory. */
+  files?: string[];
+  /** How long the approval is valid from the decision. Only read when deciding. */
+  valid_for_seconds?: number;
+}
+
+/** Current status of the approval */
+export type ApprovalStatus = 'pending' | 'approved' | 'denied';
+
+export const approvalStatusValues: readonly ApprovalStatus[] = ['pending', 'approved', 'denied'];
+
+export interface InfraResourceChange {
+  /** Terraform resource address, or Kubernetes kind and namespace/name */
+  address: string;
+  action: 'create' | 'update' | 'replace' | 'delete' | 'read';
+  /** Extra detail, such as diff line counts for kubectl */
+  detail?: string;
+}
+
+/** Summary of a terraform plan or kubectl diff found in the tool input */
+export interface InfraChangeSummary {
+  /** Tool that produced the plan or diff */
+  tool: 'terraform' | 'kubectl';
+  /** Resources to create */
+  add: number;
+  /** Resources to update in place */
+  change: number;
+  /** Resources to destroy and recreate */
+  replace: number;
+  /** Resources to delete */
+  destroy: number;
+  resources: InfraResourceChange[];
+  /** One-line description of the counts */
+  summary: string;
+}
+
+export interface MigrationWarning {
+  /** Migration file, empty for SQL passed to a database client */
+  file: string;
+  rule: 'destructive' | 'lock' | 'missing_down';
+  severity: 'high' | 'medium';
+  message: string;
+  /** Line the statement starts on */
+  line?: number;
+  /** The offending statement, truncated */
+  statement?: string;
+}
+
+export interface Approval {
+  /** Unique approval identifier */
+  id: string;
+  /** Associated run ID */
+  run_id: string;
+  /** Associated session ID */
+  session_id: string;
+  status: ApprovalStatus;
+  /** Creation timestamp (RFC 3339 timestamp) */
+  created_at: string;
+  /** Tool requesting approval */
+  tool_name: string;
+  /** Tool input parameters */
+  tool_input: Record<string, unknown>;
+  /** Files the request carries, such as a screenshot of the current screen */
+  attachments?: ApprovalAttachment[];
+  /** Approver's comment */
+  comment?: string;
+  /**
+   * AI-written guidance expanding a terse denial comment. The agent
+   * receives it after the comment, marked as AI-expanded.
+   */
+  comment_expansion?: string;
+  constraints?: ApprovalConstraints;
+  infra_summary?: InfraChangeSummary;
+  /** Risky statements in a database migration or SQL the tool call would write or run */
+  migration_warnings?: MigrationWarning[];
+  /** Response timestamp (RFC 3339 timestamp) */
+  responded_at?: string | null;
+}
+
+/**
+ * Resource limits for a session and the sessions it continues from.
+ * Once any limit is exceeded, further approvals are denied. Zero or unset means unlimited.
+ */
+export interface SessionBudget {
+  /** Maximum cost in USD across completed runs */
+  max_cost_usd?: number;
+  /** Maximum run time in seconds */
+  max_duration_seconds?: number;
+  /** Maximum number of tool calls */
+  max_tool_calls?: number;
+}
+
+/** Structured summary generated when the session finished */
+export interface SessionCompletionSummary {
+  /** What the session was asked to do */
+  asked: string;
+  /** Changes the session made */
+  changed: string[];
+  /** Questions left unanswered */
+  open_questions: string[];
+  /** Suggested next steps */
+  follow_ups: string[];
+}
+
+/** The Claude process the session ran in, only returned by GET /sessions/{id}. Resource figures are sampled every few seconds while it runs. */
+export interface SessionProcess {
+  pid: number;
+  running: boolean;
+  /** RFC 3339 timestamp */
+  started_at: string;
+  /** Resident memory at the last sample */
+  rss_bytes: number;
+  /** Highest resident memory sampled */
+  peak_rss_bytes: number;
+  /** CPU time used */
+  cpu_seconds: number;
+  /** Share of one core used since the previous sample, while running */
+  cpu_percent?: number;
+  /** Exit code; -1 when the process was killed by a signal */
+  exit_code?: number;
+  /** Signal that killed the process */
+  exit_signal?: string;
+  /** RFC 3339 timestamp */
+  exited_at?: string;
+  /** The last 4 KiB the process wrote to stderr */
+  stderr_tail?: string;
+}
+
+/** Current status of the session */
+export type SessionStatus = 'draft' | 'queued' | 'starting' | 'running' | 'completed' | 'failed' | 'interrupting' | 'interrupted' | 'waiting_input' | 'discarded';
+
+export const sessionStatusValues: readonly SessionStatus[] = ['draft', 'queued', 'starting', 'running', 'completed', 'failed', 'interrupting', 'interrupted', 'waiting_input', 'discarded'];
+
+export interface Session {
+  /** Unique session identifier */
+  id: string;
+  /** Unique run identifier */
+  run_id: string;
+  status: SessionStatus;
+  /** Initial query that started the session */
+  query: string;
+  /** Session creation timestamp (RFC 3339 timestamp) */
+  created_at: string;
+  /** Last activity timestamp (RFC 3339 timestamp) */
+  last_activity_at: string;
+  /** Additional directories Claude can access */
+  additional_directories?: string[];
+  /** Whether session is archived (defaults to false) */
+  archived?: boolean;
+  /** Whether edit tools are auto-accepted (defaults to false) */
+  auto_accept_edits?: boolean;
+  /** Whether every approval request is denied automatically (observation-only session) (defaults to false) */
+  auto_deny_all?: boolean;
+  /** Tool names denied automatically; entries ending in "*" match by prefix */
+  auto_deny_tools?: string[];
+  budget?: SessionBudget;
+  /** Number of cache creation input tokens */
+  cache_creation_input_tokens?: number | null;
+  /** Number of cache read input tokens */
+  cache_read_input_tokens?: number | null;
+  /** Claude's internal session ID */
+  claude_session_id?: string;
+  /** Session completion timestamp (RFC 3339 timestamp) */
+  completed_at?: string | null;
+  completion_summary?: SessionCompletionSummary;
+  /** Container image the agent runs in; absent when it runs on the host */
+  container_image?: string;
+  /** Context window limit for the model */
+  context_limit?: number | null;
+  /** ID of the context pack attached when the session was launched */
+  context_pack_id?: string;
+  /** Total cost in USD */
+  cost_usd?: number | null;
+  /** When true, all tool calls are automatically approved without user consent (defaults to false) */
+  dangerously_skip_permissions?: boolean;
+  /** ISO timestamp when dangerously skip permissions mode expires (optional) (RFC 3339 timestamp) */
+  dangerously_skip_permissions_expires_at?: string | null;
+  /** Session duration in milliseconds */
+  duration_ms?: number | null;
+  /** JSON blob of editor state for draft sessions */
+  editor_state?: string | null;
+  /** Total tokens counting toward context window limit */
+  effective_context_tokens?: number | null;
+  /** Error message if session failed */
+  error_message?: string;
+  /** Number of input tokens */
+  input_tokens?: number | null;
+  /** Model used for this session */
+  model?: string;
+  /** Full model identifier */
+  model_id?: string;
+  /** Number of output tokens */
+  output_tokens?: number | null;
+  /** Parent session ID if this is a forked session */
+  parent_session_id?: string;
+  process?: SessionProcess;
+  /** Base URL of the proxy server */
+  proxy_base_url?: string;
+  /** Whether proxy is enabled for this session (defaults to false) */
+  proxy_enabled?: boolean;
+  /** Model to use with the proxy */
+  proxy_model_override?: string;
+  /** ID of the failed session this session automatically retries */
+  retry_of?: string;
+  /** Whether session has been reviewed/checked off (defaults to false) */
+  reviewed?: boolean;
+  /** SSH destination holding the working directory; git commands and file reads run there */
+  ssh_host?: string;
+  /** AI-generated summary of the session */
+  summary?: string;
+  /** Label of the saved template the session was launched from */
+  template?: string;
+  /** User-editable session title */
+  title?: string;
+  /** Working directory for the session */
+  working_dir?: string;
+}
@@ -0,0 +1,43 @@
+from hld_sdk.client import (
+    ContinuedSession,
+    CreatedSession,
+    Event,
+    HLDClient,
+    HLDError,
+    RetryPolicy,
+    default_base_url,
+)
+from hld_sdk.git import (
+    ApplyPatchResponse,
+    BranchesResponse,
+    CheckoutResponse,
+    CommitMessage,
+    CommitResponse,
+    FetchResponse,
+    GitBranch,
+    GitDivergence,
+    GitFile,
+    GitStatus,
+    PullResponse,
+)
+
+__all__ = [
+    "ApplyPatchResponse",
+    "BranchesResponse",
+    "CheckoutResponse",
+    "CommitMessage",
+    "CommitResponse",
+    "ContinuedSession",
+    "CreatedSession",
+    "Event",
+    "FetchResponse",
+    "GitBranch",
+    "GitDivergence",
+    "GitFile",
+    "GitStatus",
+    "HLDClient",
+    "HLDError",
+    "PullResponse",
+    "RetryPolicy",
+    "default_base_url",
+]
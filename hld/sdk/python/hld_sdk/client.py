@@ -0,0 +1,356 @@
+"""Client for the HumanLayer Daemon REST API."""
+
+from __future__ import annotations
+
+import json
+import os
+import random
+import time
+from collections.abc import Iterator
+from dataclasses import dataclass
+from datetime import datetime
+from email.utils import parsedate_to_datetime
+from typing import Any, Literal
+from urllib.parse import quote
+
+import requests
+from hld_models import Approval, ConversationEvent, Session
+from pydantic import BaseModel, ConfigDict
+
+from hld_sdk.git import (
+    ApplyPatchResponse,
+    BranchesResponse,
+    CheckoutResponse,
+    CommitMessage,
+    CommitResponse,
+    FetchResponse,
+    GitStatus,
+    PullResponse,
+)
+
+DEFAULT_PORT = 7777
+
+# Statuses worth trying again: the daemon restarting, or a proxy in front of it
+RETRY_STATUSES = frozenset({429, 502, 503, 504})
+
+# Only requests that can safely run twice are retried. A POST such as an
+# approval decision may have reached the daemon before the connection failed.
+IDEMPOTENT_METHODS = frozenset({"GET", "HEAD", "OPTIONS", "PUT", "DELETE"})
+
+
+class HLDError(Exception):
+    """The daemon answered with an error status."""
+
+    def __init__(self, status: int, body: Any):
+        self.status = status
+        self.body = body
+        super().__init__(_error_message(status, body))
+
+
+def _error_message(status: int, body: Any) -> str:
+    if isinstance(body, dict):
+        error = body.get("error")
+        # Spec'd endpoints nest the message; the rest send a string
+        if isinstance(error, dict) and error.get("message"):
+            return str(error["message"])
+        if isinstance(error, str) and error:
+            return error
+    return f"request failed with status {status}"
+
+
+@dataclass
+class RetryPolicy:
+    """How idempotent requests that fail to connect or get a transient status are retried."""
+
+    # Attempts after the first; 0 turns retries off
+    retries: int = 3
+    # Delay before the first retry in seconds, doubled for each one after
+    min_delay: float = 0.25
+    # Longest delay between attempts, including one asked for by Retry-After
+    max_delay: float = 5.0
+
+    def delay(self, attempt: int, response: requests.Response | None = None) -> float:
+        wait = min(self.min_delay * 2 ** (attempt - 1), self.max_delay)
+        if response is not None:
+            after = _retry_after(response)
+            if after is not None:
+                return min(after, self.max_delay)
+        # Jitter keeps scripts started together from retrying in lockstep
+        return wait * (0.5 + random.random() / 2)  # noqa: S311
+
+
+def _retry_after(response: requests.Response) -> float | None:
+    header = response.headers.get("Retry-After")
+    if not header:
+        return None
+    try:
+        return max(0.0, float(header))
+    except ValueError:
+        pass
+    try:
+        return max(0.0, (parsedate_to_datetime(header) - datetime.now().astimezone()).total_seconds())
+    except (TypeError, ValueError):
+        return None
+
+
+class Event(BaseModel):
+    """An event from the daemon's event stream."""
+
+    model_config = ConfigDict(extra="allow")
+
+    type: str
+    timestamp: datetime
+    data: dict[str, Any] = {}
+
+
+class CreatedSession(BaseModel):
+    session_id: str
+    run_id: str
+
+
+class ContinuedSession(BaseModel):
+    session_id: str
+    run_id: str
+    claude_session_id: str
+    parent_session_id: str
+
+
+def default_base_url() -> str:
+    """HUMANLAYER_DAEMON_URL, else the daemon on this machine at HUMANLAYER_DAEMON_HTTP_PORT."""
+    if url := os.environ.get("HUMANLAYER_DAEMON_URL"):
+        return url.rstrip("/") + "/api/v1"
+    port = os.environ.get("HUMANLAYER_DAEMON_HTTP_PORT", str(DEFAULT_PORT))
+    return f"http://127.0.0.1:{port}/api/v1"
+
+
+class HLDClient:
+    """Typed methods for the daemon's sessions, approvals, git operations and event stream.
+
+    token is sent as a bearer token, for daemons behind an authenticating
+    proxy or federation peers. Idempotent requests are retried per retry,
+    three times by default; RetryPolicy(retries=0) turns retries off.
+    """
+
+    def __init__(
+        self,
+        base_url: str | None = None,
+        *,
+        token: str | None = None,
+        headers: dict[str, str] | None = None,
+        retry: RetryPolicy | None = None,
+        timeout: float = 30.0,
+        session: requests.Session | None = None,
+    ):
+        self.base_url = (base_url or default_base_url()).rstrip("/")
+        self.retry = retry or RetryPolicy()
+        self.timeout = timeout
+        self.http = session or requests.Session()
+        self.http.headers.update({"Accept": "application/json", "X-Client": "hld-sdk-python"})
+        if headers:
+            self.http.headers.update(headers)
+        if token:
+            self.http.headers["Authorization"] = f"Bearer {token}"
+
+    # Sessions
+
+    def list_sessions(self, **params: Any) -> list[Session]:
+        """Lists sessions; params are the query parameters, such as status or limit."""
+        return [Session.model_validate(s) for s in self._json("GET", "/sessions", params=params)["data"]]
+
+    def get_session(self, session_id: str) -> Session:
+        return Session.model_validate(self._json("GET", f"/sessions/{_seg(session_id)}")["data"])
+
+    def create_session(self, query: str, **options: Any) -> CreatedSession:
+        """Launches a session; options are the other CreateSessionRequest fields, such as working_dir."""
+        body = {"query": query, **options}
+        return CreatedSession.model_validate(self._json("POST", "/sessions", json=body)["data"])
+
+    def continue_session(self, session_id: str, query: str, **options: Any) -> ContinuedSession:
+        body = {"query": query, **options}
+        data = self._json("POST", f"/sessions/{_seg(session_id)}/continue", json=body)["data"]
+        return ContinuedSession.model_validate(data)
+
+    def interrupt_session(self, session_id: str) -> None:
+        self._request("POST", f"/sessions/{_seg(session_id)}/interrupt")
+
+    def get_messages(self, session_id: str) -> list[ConversationEvent]:
+        data = self._json("GET", f"/sessions/{_seg(session_id)}/messages")["data"]
+        return [ConversationEvent.model_validate(e) for e in data]
+
+    # Approvals
+
+    def list_approvals(self, session_id: str | None = None) -> list[Approval]:
+        """Lists pending approvals, of one session or all of them."""
+        params = {"sessionId": session_id} if session_id else None
+        return [Approval.model_validate(a) for a in self._json("GET", "/approvals", params=params)["data"]]
+
+    def get_approval(self, approval_id: str) -> Approval:
+        return Approval.model_validate(self._json("GET", f"/approvals/{_seg(approval_id)}")["data"])
+
+    def decide_approval(
+        self,
+        approval_id: str,
+        decision: Literal["approve", "deny"],
+        comment: str | None = None,
+        **options: Any,
+    ) -> None:
+        """Approves or denies; a denial needs a comment. options are the other
+        DecideApprovalRequest fields, such as constraints."""
+        body = {"decision": decision, **options}
+        if comment is not None:
+            body["comment"] = comment
+        self._request("POST", f"/approvals/{_seg(approval_id)}/decide", json=body)
+
+    def approve(self, approval_id: str, comment: str | None = None, **options: Any) -> None:
+        self.decide_approval(approval_id, "approve", comment, **options)
+
+    def deny(self, approval_id: str, comment: str, **options: Any) -> None:
+        self.decide_approval(approval_id, "deny", comment, **options)
+
+    # Git operations on a session's working directory. Mutations refuse a
+    # running session unless forced; the refusal raises HLDError with status 409.
+
+    def git_status(self, session_id: str) -> GitStatus:
+        return GitStatus.model_validate(self._json("GET", f"/sessions/{_seg(session_id)}/git/status"))
+
+    def commit(
+        self,
+        session_id: str,
+        commits: list[CommitMessage],
+        *,
+        stage_untracked: bool = False,
+        **options: Any,
+    ) -> CommitResponse:
+        """Creates commits; options are the other CommitRequest fields, such as createBranch or force."""
+        body = {
+            "commits": [c.model_dump(exclude_none=True) for c in commits],
+            "stageUntracked": stage_untracked,
+            **options,
+        }
+        return CommitResponse.model_validate(
+            self._json("POST", f"/sessions/{_seg(session_id)}/git/commit", json=body)
+        )
+
+    def export_patch(self, session_id: str, base: str | None = None, fmt: Literal["mbox", "diff"] = "mbox") -> str:
+        """Exports the session's changes since base (default: the upstream branch, else HEAD)."""
+        params = {"format": fmt}
+        if base:
+            params["base"] = base
+        return self._request("GET", f"/sessions/{_seg(session_id)}/git/patch", params=params).text
+
+    def apply_patch(self, session_id: str, patch: str, **options: Any) -> ApplyPatchResponse:
+        """Applies a patch; options are threeWay, check, commit and force."""
+        body = {"patch": patch, **options}
+        return ApplyPatchResponse.model_validate(
+            self._json("POST", f"/sessions/{_seg(session_id)}/git/apply", json=body)
+        )
+
+    def list_branches(self, session_id: str) -> BranchesResponse:
+        return BranchesResponse.model_validate(self._json("GET", f"/sessions/{_seg(session_id)}/git/branches"))
+
+    def checkout(self, session_id: str, branch: str, **options: Any) -> CheckoutResponse:
+        """Switches branch; options are create, startPoint, stash and force."""
+        body = {"branch": branch, **options}
+        return CheckoutResponse.model_validate(
+            self._json("POST", f"/sessions/{_seg(session_id)}/git/checkout", json=body)
+        )
+
+    def fetch(self, session_id: str, remote: str | None = None, prune: bool = False) -> FetchResponse:
+        body: dict[str, Any] = {"prune": prune}
+        if remote:
+            body["remote"] = remote
+        return FetchResponse.model_validate(self._json("POST", f"/sessions/{_seg(session_id)}/git/fetch", json=body))
+
+    def pull(
+        self,
+        session_id: str,
+        strategy: Literal["ff-only", "rebase", "merge"] | None = None,
+        force: bool = False,
+    ) -> PullResponse:
+        body: dict[str, Any] = {"force": force}
+        if strategy:
+            body["strategy"] = strategy
+        return PullResponse.model_validate(self._json("POST", f"/sessions/{_seg(session_id)}/git/pull", json=body))
+
+    # Event stream
+
+    def events(
+        self,
+        event_types: list[str] | None = None,
+        session_id: str | None = None,
+        run_id: str | None = None,
+        reconnect: bool = True,
+    ) -> Iterator[Event]:
+        """Yields events as the daemon publishes them, reconnecting after the
+        connection drops unless reconnect is False. Events published while
+        disconnected are missed."""
+        params: dict[str, Any] = {}
+        if event_types:
+            params["eventTypes"] = event_types
+        if session_id:
+            params["sessionId"] = session_id
+        if run_id:
+            params["runId"] = run_id
+        attempt = 0
+        while True:
+            try:
+                response = self._request(
+                    "GET", "/stream/events", params=params, stream=True, headers={"Accept": "text/event-stream"}
+                )
+                attempt = 0
+                with response:
+                    yield from _parse_events(response.iter_lines(decode_unicode=True))
+            except requests.ConnectionError:
+                if not reconnect:
+                    raise
+            if not reconnect:
+                return
+            attempt += 1
+            time.sleep(self.retry.delay(attempt))
+
+    # Requests
+
+    def _json(self, method: str, path: str, **kwargs: Any) -> Any:
+        return self._request(method, path, **kwargs).json()
+
+    def _request(self, method: str, path: str, **kwargs: Any) -> requests.Response:
+        """Sends a request, retrying idempotent ones, and raises HLDError for error statuses."""
+        kwargs.setdefault("timeout", None if kwargs.get("stream") else self.timeout)
+        attempts = self.retry.retries + 1 if method in IDEMPOTENT_METHODS else 1
+        attempt = 0
+        while True:
+            attempt += 1
+            try:
+                response = self.http.request(method, self.base_url + path, **kwargs)
+            except (requests.ConnectionError, requests.Timeout):
+                if attempt >= attempts:
+                    raise
+                time.sleep(self.retry.delay(attempt))
+                continue
+            if response.status_code in RETRY_STATUSES and attempt < attempts:
+                time.sleep(self.retry.delay(attempt, response))
+                continue
+            if response.status_code >= 400:
+                try:
+                    body = response.json()
+                except ValueError:
+                    body = response.text
+                raise HLDError(response.status_code, body)
+            return response
+
+
+def _seg(value: str) -> str:
+    return quote(value, safe="")
+
+
+def _parse_events(lines: Iterator[str]) -> Iterator[Event]:
+    """Parses server-sent events; each carries one JSON event in its data lines."""
+    data: list[str] = []
+    for line in lines:
+        if line == "":
+            if data:
+                yield Event.model_validate(json.loads("\n".join(data)))
+                data = []
+        elif line.startswith("data:"):
+            data.append(line[5:].lstrip(" "))
+        # Comments (keepalives) and other fields are ignored
@@ -0,0 +1,126 @@
+import json
+from typing import Any
+
+import pytest
+import requests
+
+from hld_sdk import CommitMessage, HLDClient, HLDError, RetryPolicy
+from hld_sdk.client import _parse_events
+
+
+def response(status: int, body: Any = None, headers: dict[str, str] | None = None) -> requests.Response:
+    r = requests.Response()
+    r.status_code = status
+    r._content = json.dumps(body).encode() if body is not None else b""
+    r.headers.update(headers or {})
+    return r
+
+
+class FakeSession(requests.Session):
+    """Answers requests from a queue and records them."""
+
+    def __init__(self, *answers: requests.Response | Exception):
+        super().__init__()
+        self.answers = list(answers)
+        self.calls: list[tuple[str, str, dict[str, Any]]] = []
+
+    def request(self, method: str, url: str, **kwargs: Any) -> requests.Response:  # type: ignore[override]
+        self.calls.append((method, url, kwargs))
+        answer = self.answers.pop(0)
+        if isinstance(answer, Exception):
+            raise answer
+        return answer
+
+
+@pytest.fixture(autouse=True)
+def no_sleep(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setattr("hld_sdk.client.time.sleep", lambda _: None)
+
+
+def test_token_sent_as_bearer() -> None:
+    http = FakeSession(response(200, {"data": []}))
+    client = HLDClient("http://daemon/api/v1", token="secret", session=http)
+    client.list_approvals()
+    assert http.headers["Authorization"] == "Bearer secret"
+
+
+def test_default_base_url_uses_port_env(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.delenv("HUMANLAYER_DAEMON_URL", raising=False)
+    monkeypatch.setenv("HUMANLAYER_DAEMON_HTTP_PORT", "7778")
+    assert HLDClient(session=FakeSession()).base_url == "http://127.0.0.1:7778/api/v1"
+
+
+def test_get_retried_on_transient_status() -> None:
+    http = FakeSession(
+        response(503, {"error": "restarting"}),
+        requests.ConnectionError(),
+        response(200, {"data": []}),
+    )
+    client = HLDClient("http://daemon/api/v1", session=http)
+    assert client.list_approvals(session_id="s1") == []
+    assert len(http.calls) == 3
+    assert http.calls[0][2]["params"] == {"sessionId": "s1"}
+
+
+def test_post_not_retried() -> None:
+    http = FakeSession(response(503, {"error": "restarting"}), response(200, {}))
+    client = HLDClient("http://daemon/api/v1", session=http)
+    with pytest.raises(HLDError) as e:
+        client.approve("a1")
+    assert e.value.status == 503
+    assert len(http.calls) == 1
+
+
+def test_retries_give_up() -> None:
+    http = FakeSession(*[response(502) for _ in range(3)])
+    client = HLDClient("http://daemon/api/v1", retry=RetryPolicy(retries=2), session=http)
+    with pytest.raises(HLDError):
+        client.get_session("s1")
+    assert len(http.calls) == 3
+
+
+def test_retry_after_capped() -> None:
+    policy = RetryPolicy(max_delay=2.0)
+    assert policy.delay(1, response(429, headers={"Retry-After": "1"})) == 1.0
+    assert policy.delay(1, response(429, headers={"Retry-After": "60"})) == 2.0
+
+
+def test_error_message() -> None:
+    http = FakeSession(
+        response(409, {"error": "session is running; pass force to commit anyway"}),
+        response(404, {"error": {"code": "HLD-3001", "message": "Session not found"}}),
+    )
+    client = HLDClient("http://daemon/api/v1", session=http)
+    with pytest.raises(HLDError, match="session is running"):
+        client.commit("s1", [CommitMessage(subject="Fix", files=["a.go"])])
+    with pytest.raises(HLDError, match="Session not found"):
+        client.git_status("s1")
+
+
+def test_commit_body() -> None:
+    http = FakeSession(response(200, {"success": True, "commitHashes": ["abc"], "operationId": "op"}))
+    client = HLDClient("http://daemon/api/v1", session=http)
+    result = client.commit("s/1", [CommitMessage(subject="Fix", files=["a.go"])], createBranch="fix")
+    method, url, kwargs = http.calls[0]
+    assert (method, url) == ("POST", "http://daemon/api/v1/sessions/s%2F1/git/commit")
+    assert kwargs["json"] == {
+        "commits": [{"subject": "Fix", "files": ["a.go"]}],
+        "stageUntracked": False,
+        "createBranch": "fix",
+    }
+    assert result.commitHashes == ["abc"]
+
+
+def test_parse_events() -> None:
+    lines = [
+        ": keepalive",
+        "",
+        'data: {"type": "new_approval", "timestamp": "2025-01-01T00:00:00Z",',
+        'data:  "data": {"approval_id": "a1"}}',
+        "",
+        'data: {"type": "session_status_changed", "timestamp": "2025-01-01T00:00:01Z", "data": {}}',
+        "",
+    ]
+    events = list(_parse_events(iter(lines)))
+    assert [e.type for e in events] == ["new_approval", "session_status_changed"]
+    assert events[0].data == {"approval_id": "a1"}
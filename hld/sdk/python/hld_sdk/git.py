@@ -0,0 +1,103 @@
+"""Models of the git endpoints under /sessions/{id}/git, which are served
+outside the OpenAPI spec. Fields the SDK doesn't model are kept."""
+
+from __future__ import annotations
+
+from pydantic import BaseModel, ConfigDict
+
+
+class _Model(BaseModel):
+    model_config = ConfigDict(extra="allow")
+
+
+class GitFile(_Model):
+    path: str
+    status: str
+    oldPath: str | None = None
+    diff: str | None = None
+    similarity: int | None = None
+    oldMode: str | None = None
+    newMode: str | None = None
+
+
+class GitStatus(_Model):
+    staged: list[GitFile] = []
+    unstaged: list[GitFile] = []
+    untracked: list[GitFile] = []
+    branch: str
+    hasChanges: bool
+    ahead: int | None = None
+    behind: int | None = None
+    lfs: bool | None = None
+
+
+class CommitMessage(_Model):
+    subject: str
+    body: str | None = None
+    footer: str | None = None
+    files: list[str] = []
+
+
+class CommitResponse(_Model):
+    success: bool
+    commitHashes: list[str] = []
+    branchCreated: str | None = None
+    error: str | None = None
+    operationId: str | None = None
+
+
+class ApplyPatchResponse(_Model):
+    success: bool
+    files: list[str] = []
+    conflicts: list[str] | None = None
+    commitHashes: list[str] | None = None
+    error: str | None = None
+
+
+class GitBranch(_Model):
+    name: str
+    remote: bool
+    current: bool
+    commit: str
+    upstream: str | None = None
+    subject: str
+    date: str
+    ahead: int = 0
+    behind: int = 0
+
+
+class BranchesResponse(_Model):
+    current: str
+    default: str
+    branches: list[GitBranch] = []
+    truncated: bool | None = None
+
+
+class CheckoutResponse(_Model):
+    success: bool
+    branch: str
+    previous: str | None = None
+    stash: str | None = None
+    error: str | None = None
+
+
+class GitDivergence(_Model):
+    branch: str
+    upstream: str
+    ahead: int
+    behind: int
+
+
+class FetchResponse(_Model):
+    remote: str
+    divergence: GitDivergence | None = None
+
+
+class PullResponse(_Model):
+    success: bool
+    strategy: str
+    before: str
+    after: str | None = None
+    divergence: GitDivergence | None = None
+    conflicts: list[str] | None = None
+    error: str | None = None
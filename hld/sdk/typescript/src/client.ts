@@ -23,16 +23,47 @@ import {
     FuzzySearchFilesResponse,
     DiscoverAgents200Response
 } from './generated';
+import { FetchAPI } from './generated/runtime';
 import { createErrorInterceptor } from './middleware';
+import { createRetryingFetch, RetryOptions } from './retry';
+import {
+    GitStatus,
+    CommitRequest,
+    CommitResponse,
+    PatchFormat,
+    ApplyPatchRequest,
+    ApplyPatchResponse,
+    BranchesResponse,
+    CheckoutRequest,
+    CheckoutResponse,
+    FetchResponse,
+    PullRequest,
+    PullResponse
+} from './git';
 
 export interface HLDClientOptions {
     baseUrl?: string;
     port?: number;
     headers?: Record<string, string>;
+    // Bearer token sent with every request, for daemons behind an
+    // authenticating proxy or federation peers
+    token?: string;
+    // Retries of idempotent requests that fail to connect or get a
+    // transient status; false turns them off
+    retry?: RetryOptions | false;
     // New option for error handling
     onFetchError?: (error: Error, context: { url: string; method?: string }) => void;
 }
 
+// HLDApiError is thrown by the methods that call endpoints outside the
+// OpenAPI spec when the daemon answers with an error status
+export class HLDApiError extends Error {
+    constructor(public status: number, public body: any) {
+        super(body?.error || body?.message || `Request failed with status ${status}`);
+        this.name = 'HLDApiError';
+    }
+}
+
 export interface SSEEventHandlers {
     onMessage?: (event: any) => void;
     onError?: (error: Error) => void;
@@ -54,15 +85,23 @@ export class HLDClient {
     private agentsApi: AgentsApi;
     private baseUrl: string;
     private headers?: Record<string, string>;
+    private fetchApi: FetchAPI;
     private sseConnections: Map<string, EventSourceLike> = new Map();
 
     constructor(options: HLDClientOptions = {}) {
         this.baseUrl = options.baseUrl || `http://127.0.0.1:${options.port || 7777}/api/v1`;
         this.headers = options.headers;
+        if (options.token) {
+            this.headers = { ...this.headers, Authorization: `Bearer ${options.token}` };
+        }
+        this.fetchApi = options.retry === false
+            ? (input, init) => fetch(input, init)
+            : createRetryingFetch(options.retry);
 
         const config = new Configuration({
             basePath: this.baseUrl,
             headers: this.headers,
+            fetchApi: this.fetchApi,
             // Add error interceptor middleware
             middleware: [
                 createErrorInterceptor({
@@ -300,7 +339,8 @@ export class HLDClient {
     async health(): Promise<{ status: string; version: string }> {
         const systemApi = new SystemApi(new Configuration({
             basePath: this.baseUrl,
-            headers: this.headers
+            headers: this.headers,
+            fetchApi: this.fetchApi
         }));
         const response = await systemApi.getHealth();
         return response;
@@ -362,6 +402,70 @@ export class HLDClient {
         return response;
     }
 
+    // Git operations on a session's working directory. Mutations refuse a
+    // running session unless forced; the refusal is thrown as an HLDApiError
+    // with status 409.
+    async getGitStatus(sessionId: string): Promise<GitStatus> {
+        return this.request('GET', `/sessions/${encodeURIComponent(sessionId)}/git/status`);
+    }
+
+    async commitChanges(sessionId: string, request: CommitRequest): Promise<CommitResponse> {
+        return this.request('POST', `/sessions/${encodeURIComponent(sessionId)}/git/commit`, request);
+    }
+
+    // Exports the session's changes since base (default: the upstream
+    // branch, else HEAD) as a patch
+    async exportPatch(sessionId: string, params: { base?: string; format?: PatchFormat } = {}): Promise<string> {
+        const query = new URLSearchParams();
+        if (params.base) query.append('base', params.base);
+        if (params.format) query.append('format', params.format);
+        const response = await this.send('GET', `/sessions/${encodeURIComponent(sessionId)}/git/patch${query.toString() ? '?' + query : ''}`);
+        return response.text();
+    }
+
+    async applyPatch(sessionId: string, request: ApplyPatchRequest): Promise<ApplyPatchResponse> {
+        return this.request('POST', `/sessions/${encodeURIComponent(sessionId)}/git/apply`, request);
+    }
+
+    async listBranches(sessionId: string): Promise<BranchesResponse> {
+        return this.request('GET', `/sessions/${encodeURIComponent(sessionId)}/git/branches`);
+    }
+
+    async checkout(sessionId: string, request: CheckoutRequest): Promise<CheckoutResponse> {
+        return this.request('POST', `/sessions/${encodeURIComponent(sessionId)}/git/checkout`, request);
+    }
+
+    async fetchRemote(sessionId: string, params: { remote?: string; prune?: boolean } = {}): Promise<FetchResponse> {
+        return this.request('POST', `/sessions/${encodeURIComponent(sessionId)}/git/fetch`, params);
+    }
+
+    async pull(sessionId: string, request: PullRequest = {}): Promise<PullResponse> {
+        return this.request('POST', `/sessions/${encodeURIComponent(sessionId)}/git/pull`, request);
+    }
+
+    // request sends a JSON request and parses the JSON response
+    private async request<T>(method: string, path: string, body?: unknown): Promise<T> {
+        const response = await this.send(method, path, body);
+        return response.json() as Promise<T>;
+    }
+
+    // send makes a request with the client's headers and retries, throwing
+    // an HLDApiError for error statuses
+    private async send(method: string, path: string, body?: unknown): Promise<Response> {
+        const headers: Record<string, string> = { ...this.headers };
+        if (body !== undefined) headers['Content-Type'] = 'application/json';
+        const response = await this.fetchApi(`${this.baseUrl}${path}`, {
+            method,
+            headers,
+            body: body === undefined ? undefined : JSON.stringify(body),
+        });
+        if (!response.ok) {
+            const errorBody = await response.json().catch(() => ({ error: response.statusText }));
+            throw new HLDApiError(response.status, errorBody);
+        }
+        return response;
+    }
+
     // Server-Sent Events using eventsource polyfill
     async subscribeToEvents(
         params: {
@@ -399,8 +503,11 @@ export class HLDClient {
             // Node.js environment with polyfill - supports headers
             // Dynamic import to avoid bundling in browser
             const { EventSource: EventSourcePolyfill } = require('eventsource');
+            const headers = this.headers;
             eventSource = new EventSourcePolyfill(url, {
-                headers: this.headers,
+                // eventsource v4 takes headers, such as the token, through fetch
+                fetch: (input: RequestInfo | URL, init?: RequestInit) =>
+                    fetch(input, { ...init, headers: { ...(init?.headers as Record<string, string>), ...headers } }),
                 withCredentials: false
             });
         }
@@ -33,6 +33,7 @@ import type {
   RecentPathsResponse,
   SessionResponse,
   SessionSearchResponse,
+  SessionStatus,
   SessionsResponse,
   SlashCommandsResponse,
   SnapshotsResponse,
@@ -75,6 +76,8 @@ import {
     SessionResponseToJSON,
     SessionSearchResponseFromJSON,
     SessionSearchResponseToJSON,
+    SessionStatusFromJSON,
+    SessionStatusToJSON,
     SessionsResponseFromJSON,
     SessionsResponseToJSON,
     SlashCommandsResponseFromJSON,
@@ -151,6 +154,13 @@ export interface LaunchDraftSessionOperationRequest {
 export interface ListSessionsRequest {
     leavesOnly?: boolean;
     filter?: ListSessionsFilterEnum;
+    status?: Array<SessionStatus>;
+    label?: string;
+    sort?: ListSessionsSortEnum;
+    order?: ListSessionsOrderEnum;
+    limit?: number;
+    cursor?: string;
+    fields?: string;
 }
 
 export interface SearchSessionsRequest {
@@ -418,6 +428,13 @@ export interface SessionsApiInterface {
      * @summary List sessions
      * @param {boolean} [leavesOnly] Return only leaf sessions (sessions with no children)
      * @param {'normal' | 'archived' | 'draft'} [filter] Filter sessions by type
+     * @param {Array<SessionStatus>} [status] Only sessions with one of these statuses
+     * @param {string} [label] Only sessions launched from the template with this label
+     * @param {'created' | 'updated' | 'cost'} [sort] Order of the sessions: by creation time, last activity, or cost 
+     * @param {'asc' | 'desc'} [order] Sort direction
+     * @param {number} [limit] Maximum sessions per page. When omitted, every session is returned. Counts cover every session, whatever the filters and page. 
+     * @param {string} [cursor] The next_cursor of the previous page
+     * @param {string} [fields] Comma-separated session fields to return, e.g. id,title,status. The id is always included. When omitted, every field is returned. 
      * @param {*} [options] Override http request option.
      * @throws {RequiredError}
      * @memberof SessionsApiInterface
@@ -1099,6 +1116,34 @@ export class SessionsApi extends runtime.BaseAPI implements SessionsApiInterface
             queryParameters['filter'] = requestParameters['filter'];
         }
 
+        if (requestParameters['status'] != null) {
+            queryParameters['status'] = requestParameters['status'];
+        }
+
+        if (requestParameters['label'] != null) {
+            queryParameters['label'] = requestParameters['label'];
+        }
+
+        if (requestParameters['sort'] != null) {
+            queryParameters['sort'] = requestParameters['sort'];
+        }
+
+        if (requestParameters['order'] != null) {
+            queryParameters['order'] = requestParameters['order'];
+        }
+
+        if (requestParameters['limit'] != null) {
+            queryParameters['limit'] = requestParameters['limit'];
+        }
+
+        if (requestParameters['cursor'] != null) {
+            queryParameters['cursor'] = requestParameters['cursor'];
+        }
+
+        if (requestParameters['fields'] != null) {
+            queryParameters['fields'] = requestParameters['fields'];
+        }
+
         const headerParameters: runtime.HTTPHeaders = {};
 
 
@@ -1222,3 +1267,20 @@ export const ListSessionsFilterEnum = {
     Draft: 'draft'
 } as const;
 export type ListSessionsFilterEnum = typeof ListSessionsFilterEnum[keyof typeof ListSessionsFilterEnum];
+/**
+ * @export
+ */
+export const ListSessionsSortEnum = {
+    Created: 'created',
+    Updated: 'updated',
+    Cost: 'cost'
+} as const;
+export type ListSessionsSortEnum = typeof ListSessionsSortEnum[keyof typeof ListSessionsSortEnum];
+/**
+ * @export
+ */
+export const ListSessionsOrderEnum = {
+    Asc: 'asc',
+    Desc: 'desc'
+} as const;
+export type ListSessionsOrderEnum = typeof ListSessionsOrderEnum[keyof typeof ListSessionsOrderEnum];
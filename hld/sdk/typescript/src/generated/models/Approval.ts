@@ -13,7 +13,35 @@
  */
 
 import { mapValues } from '../runtime';
+import type { ApprovalAttachment } from './ApprovalAttachment';
+import type { ApprovalConstraints } from './ApprovalConstraints';
+import {
+    ApprovalConstraintsFromJSON,
+    ApprovalConstraintsFromJSONTyped,
+    ApprovalConstraintsToJSON,
+    ApprovalConstraintsToJSONTyped,
+} from './ApprovalConstraints';
+import {
+    ApprovalAttachmentFromJSON,
+    ApprovalAttachmentFromJSONTyped,
+    ApprovalAttachmentToJSON,
+    ApprovalAttachmentToJSONTyped,
+} from './ApprovalAttachment';
 import type { ApprovalStatus } from './ApprovalStatus';
+import type { InfraChangeSummary } from './InfraChangeSummary';
+import type { MigrationWarning } from './MigrationWarning';
+import {
+    MigrationWarningFromJSON,
+    MigrationWarningFromJSONTyped,
+    MigrationWarningToJSON,
+    MigrationWarningToJSONTyped,
+} from './MigrationWarning';
+import {
+    InfraChangeSummaryFromJSON,
+    InfraChangeSummaryFromJSONTyped,
+    InfraChangeSummaryToJSON,
+    InfraChangeSummaryToJSONTyped,
+} from './InfraChangeSummary';
 import {
     ApprovalStatusFromJSON,
     ApprovalStatusFromJSONTyped,
@@ -81,6 +109,36 @@ export interface Approval {
      * @memberof Approval
      */
     comment?: string;
+    /**
+     * AI-written guidance expanding a terse denial comment. The agent receives it after the comment, marked as AI-expanded.
+     * @type {string}
+     * @memberof Approval
+     */
+    commentExpansion?: string;
+    /**
+     * 
+     * @type {InfraChangeSummary}
+     * @memberof Approval
+     */
+    infraSummary?: InfraChangeSummary;
+    /**
+     * Risky statements in a database migration or SQL the tool call would write or run
+     * @type {Array<MigrationWarning>}
+     * @memberof Approval
+     */
+    migrationWarnings?: Array<MigrationWarning>;
+    /**
+     * Files the request carries, such as a screenshot of the current screen
+     * @type {Array<ApprovalAttachment>}
+     * @memberof Approval
+     */
+    attachments?: Array<ApprovalAttachment>;
+    /**
+     * 
+     * @type {ApprovalConstraints}
+     * @memberof Approval
+     */
+    constraints?: ApprovalConstraints;
 }
 
 
@@ -118,6 +176,11 @@ export function ApprovalFromJSONTyped(json: any, ignoreDiscriminator: boolean):
         'toolName': json['tool_name'],
         'toolInput': json['tool_input'],
         'comment': json['comment'] == null ? undefined : json['comment'],
+        'commentExpansion': json['comment_expansion'] == null ? undefined : json['comment_expansion'],
+        'infraSummary': json['infra_summary'] == null ? undefined : InfraChangeSummaryFromJSON(json['infra_summary']),
+        'migrationWarnings': json['migration_warnings'] == null ? undefined : ((json['migration_warnings'] as Array<any>).map(MigrationWarningFromJSON)),
+        'attachments': json['attachments'] == null ? undefined : ((json['attachments'] as Array<any>).map(ApprovalAttachmentFromJSON)),
+        'constraints': json['constraints'] == null ? undefined : ApprovalConstraintsFromJSON(json['constraints']),
     };
 }
 
@@ -141,6 +204,11 @@ export function ApprovalToJSONTyped(value?: Approval | null, ignoreDiscriminator
         'tool_name': value['toolName'],
         'tool_input': value['toolInput'],
         'comment': value['comment'],
+        'comment_expansion': value['commentExpansion'],
+        'infra_summary': InfraChangeSummaryToJSON(value['infraSummary']),
+        'migration_warnings': value['migrationWarnings'] == null ? undefined : ((value['migrationWarnings'] as Array<any>).map(MigrationWarningToJSON)),
+        'attachments': value['attachments'] == null ? undefined : ((value['attachments'] as Array<any>).map(ApprovalAttachmentToJSON)),
+        'constraints': ApprovalConstraintsToJSON(value['constraints']),
     };
 }
 
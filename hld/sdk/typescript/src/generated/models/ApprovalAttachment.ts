@@ -0,0 +1,102 @@
+/* tslint:disable */
+/* eslint-disable */
+/**
+ * HumanLayer Daemon REST API
+ * REST API for HumanLayer daemon operations, providing session management, approval workflows, and real-time event streaming capabilities. 
+ *
+ * The version of the OpenAPI document: 1.0.0
+ * 
+ *
+ * NOTE: This class is auto generated by OpenAPI Generator (https://openapi-generator.tech).
+ * https://openapi-generator.tech
+ * Do not edit the class manually.
+ */
+
+import { mapValues } from '../runtime';
+/**
+ * 
+ * @export
+ * @interface ApprovalAttachment
+ */
+export interface ApprovalAttachment {
+    /**
+     * Artifact ID
+     * @type {string}
+     * @memberof ApprovalAttachment
+     */
+    id: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof ApprovalAttachment
+     */
+    name: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof ApprovalAttachment
+     */
+    contentType: string;
+    /**
+     * 
+     * @type {number}
+     * @memberof ApprovalAttachment
+     */
+    size: number;
+    /**
+     * Signed link to the content, valid for the configured artifacts link_ttl
+     * @type {string}
+     * @memberof ApprovalAttachment
+     */
+    downloadUrl: string;
+}
+
+/**
+ * Check if a given object implements the ApprovalAttachment interface.
+ */
+export function instanceOfApprovalAttachment(value: object): value is ApprovalAttachment {
+    if (!('id' in value) || value['id'] === undefined) return false;
+    if (!('name' in value) || value['name'] === undefined) return false;
+    if (!('contentType' in value) || value['contentType'] === undefined) return false;
+    if (!('size' in value) || value['size'] === undefined) return false;
+    if (!('downloadUrl' in value) || value['downloadUrl'] === undefined) return false;
+    return true;
+}
+
+export function ApprovalAttachmentFromJSON(json: any): ApprovalAttachment {
+    return ApprovalAttachmentFromJSONTyped(json, false);
+}
+
+export function ApprovalAttachmentFromJSONTyped(json: any, ignoreDiscriminator: boolean): ApprovalAttachment {
+    if (json == null) {
+        return json;
+    }
+    return {
+        
+        'id': json['id'],
+        'name': json['name'],
+        'contentType': json['content_type'],
+        'size': json['size'],
+        'downloadUrl': json['download_url'],
+    };
+}
+
+export function ApprovalAttachmentToJSON(json: any): ApprovalAttachment {
+    return ApprovalAttachmentToJSONTyped(json, false);
+}
+
+export function ApprovalAttachmentToJSONTyped(value?: ApprovalAttachment | null, ignoreDiscriminator: boolean = false): any {
+    if (value == null) {
+        return value;
+    }
+
+    return {
+        
+        'id': value['id'],
+        'name': value['name'],
+        'content_type': value['contentType'],
+        'size': value['size'],
+        'download_url': value['downloadUrl'],
+    };
+}
+
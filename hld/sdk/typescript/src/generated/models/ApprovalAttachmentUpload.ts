@@ -0,0 +1,84 @@
+/* tslint:disable */
+/* eslint-disable */
+/**
+ * HumanLayer Daemon REST API
+ * REST API for HumanLayer daemon operations, providing session management, approval workflows, and real-time event streaming capabilities. 
+ *
+ * The version of the OpenAPI document: 1.0.0
+ * 
+ *
+ * NOTE: This class is auto generated by OpenAPI Generator (https://openapi-generator.tech).
+ * https://openapi-generator.tech
+ * Do not edit the class manually.
+ */
+
+import { mapValues } from '../runtime';
+/**
+ * 
+ * @export
+ * @interface ApprovalAttachmentUpload
+ */
+export interface ApprovalAttachmentUpload {
+    /**
+     * File name, without a path
+     * @type {string}
+     * @memberof ApprovalAttachmentUpload
+     */
+    name: string;
+    /**
+     * image/png, image/jpeg, image/gif, image/webp or text/plain
+     * @type {string}
+     * @memberof ApprovalAttachmentUpload
+     */
+    contentType: string;
+    /**
+     * Base64-encoded content, up to 10 MiB decoded
+     * @type {string}
+     * @memberof ApprovalAttachmentUpload
+     */
+    data: string;
+}
+
+/**
+ * Check if a given object implements the ApprovalAttachmentUpload interface.
+ */
+export function instanceOfApprovalAttachmentUpload(value: object): value is ApprovalAttachmentUpload {
+    if (!('name' in value) || value['name'] === undefined) return false;
+    if (!('contentType' in value) || value['contentType'] === undefined) return false;
+    if (!('data' in value) || value['data'] === undefined) return false;
+    return true;
+}
+
+export function ApprovalAttachmentUploadFromJSON(json: any): ApprovalAttachmentUpload {
+    return ApprovalAttachmentUploadFromJSONTyped(json, false);
+}
+
+export function ApprovalAttachmentUploadFromJSONTyped(json: any, ignoreDiscriminator: boolean): ApprovalAttachmentUpload {
+    if (json == null) {
+        return json;
+    }
+    return {
+        
+        'name': json['name'],
+        'contentType': json['content_type'],
+        'data': json['data'],
+    };
+}
+
+export function ApprovalAttachmentUploadToJSON(json: any): ApprovalAttachmentUpload {
+    return ApprovalAttachmentUploadToJSONTyped(json, false);
+}
+
+export function ApprovalAttachmentUploadToJSONTyped(value?: ApprovalAttachmentUpload | null, ignoreDiscriminator: boolean = false): any {
+    if (value == null) {
+        return value;
+    }
+
+    return {
+        
+        'name': value['name'],
+        'content_type': value['contentType'],
+        'data': value['data'],
+    };
+}
+
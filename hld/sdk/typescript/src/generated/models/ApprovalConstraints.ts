@@ -0,0 +1,83 @@
+/* tslint:disable */
+/* eslint-disable */
+/**
+ * HumanLayer Daemon REST API
+ * REST API for HumanLayer daemon operations, providing session management, approval workflows, and real-time event streaming capabilities. 
+ *
+ * The version of the OpenAPI document: 1.0.0
+ * 
+ *
+ * NOTE: This class is auto generated by OpenAPI Generator (https://openapi-generator.tech).
+ * https://openapi-generator.tech
+ * Do not edit the class manually.
+ */
+
+import { mapValues } from '../runtime';
+/**
+ * Limits on what an approval allows, given when approving. They are sent
+ * to the agent in the response metadata, and a reported tool result that
+ * doesn't keep to them is recorded as a violation.
+ * @export
+ * @interface ApprovalConstraints
+ */
+export interface ApprovalConstraints {
+    /**
+     * How long the approval is valid from the decision. Only read when deciding.
+     * @type {number}
+     * @memberof ApprovalConstraints
+     */
+    validForSeconds?: number;
+    /**
+     * When the approval stops being valid; must be in the future
+     * @type {Date}
+     * @memberof ApprovalConstraints
+     */
+    expiresAt?: Date;
+    /**
+     * The only files the tool call may read or write. Relative paths are resolved against the session's working directory.
+     * @type {Array<string>}
+     * @memberof ApprovalConstraints
+     */
+    files?: Array<string>;
+}
+
+/**
+ * Check if a given object implements the ApprovalConstraints interface.
+ */
+export function instanceOfApprovalConstraints(value: object): value is ApprovalConstraints {
+    return true;
+}
+
+export function ApprovalConstraintsFromJSON(json: any): ApprovalConstraints {
+    return ApprovalConstraintsFromJSONTyped(json, false);
+}
+
+export function ApprovalConstraintsFromJSONTyped(json: any, ignoreDiscriminator: boolean): ApprovalConstraints {
+    if (json == null) {
+        return json;
+    }
+    return {
+        
+        'validForSeconds': json['valid_for_seconds'] == null ? undefined : json['valid_for_seconds'],
+        'expiresAt': json['expires_at'] == null ? undefined : (new Date(json['expires_at'])),
+        'files': json['files'] == null ? undefined : json['files'],
+    };
+}
+
+export function ApprovalConstraintsToJSON(json: any): ApprovalConstraints {
+    return ApprovalConstraintsToJSONTyped(json, false);
+}
+
+export function ApprovalConstraintsToJSONTyped(value?: ApprovalConstraints | null, ignoreDiscriminator: boolean = false): any {
+    if (value == null) {
+        return value;
+    }
+
+    return {
+        
+        'valid_for_seconds': value['validForSeconds'],
+        'expires_at': value['expiresAt'] == null ? undefined : ((value['expiresAt']).toISOString()),
+        'files': value['files'],
+    };
+}
+
@@ -13,6 +13,14 @@
  */
 
 import { mapValues } from '../runtime';
+import type { ApprovalAttachmentUpload } from './ApprovalAttachmentUpload';
+import {
+    ApprovalAttachmentUploadFromJSON,
+    ApprovalAttachmentUploadFromJSONTyped,
+    ApprovalAttachmentUploadToJSON,
+    ApprovalAttachmentUploadToJSONTyped,
+} from './ApprovalAttachmentUpload';
+
 /**
  * 
  * @export
@@ -37,6 +45,12 @@ export interface CreateApprovalRequest {
      * @memberof CreateApprovalRequest
      */
     toolInput: { [key: string]: any; };
+    /**
+     * Files showing the approver the current state, such as a screenshot or terminal capture. Stored as session artifacts.
+     * @type {Array<ApprovalAttachmentUpload>}
+     * @memberof CreateApprovalRequest
+     */
+    attachments?: Array<ApprovalAttachmentUpload>;
 }
 
 /**
@@ -62,6 +76,7 @@ export function CreateApprovalRequestFromJSONTyped(json: any, ignoreDiscriminato
         'runId': json['run_id'],
         'toolName': json['tool_name'],
         'toolInput': json['tool_input'],
+        'attachments': json['attachments'] == null ? undefined : ((json['attachments'] as Array<any>).map(ApprovalAttachmentUploadFromJSON)),
     };
 }
 
@@ -79,6 +94,7 @@ export function CreateApprovalRequestToJSONTyped(value?: CreateApprovalRequest |
         'run_id': value['runId'],
         'tool_name': value['toolName'],
         'tool_input': value['toolInput'],
+        'attachments': value['attachments'] == null ? undefined : ((value['attachments'] as Array<any>).map(ApprovalAttachmentUploadToJSON)),
     };
 }
 
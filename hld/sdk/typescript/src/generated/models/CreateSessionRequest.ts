@@ -13,6 +13,13 @@
  */
 
 import { mapValues } from '../runtime';
+import type { SessionBudget } from './SessionBudget';
+import {
+    SessionBudgetFromJSON,
+    SessionBudgetFromJSONTyped,
+    SessionBudgetToJSON,
+    SessionBudgetToJSONTyped,
+} from './SessionBudget';
 import type { MCPConfig } from './MCPConfig';
 import {
     MCPConfigFromJSON,
@@ -63,6 +70,12 @@ export interface CreateSessionRequest {
      * @memberof CreateSessionRequest
      */
     workingDir?: string;
+    /**
+     * Run the session in its own git worktree of the working directory's repository, on a new branch from HEAD. Git endpoints then act on the worktree, and it is removed once the session is archived.
+     * @type {boolean}
+     * @memberof CreateSessionRequest
+     */
+    useWorktree?: boolean;
     /**
      * Maximum conversation turns
      * @type {number}
@@ -123,6 +136,60 @@ export interface CreateSessionRequest {
      * @memberof CreateSessionRequest
      */
     dangerouslySkipPermissionsTimeout?: number;
+    /**
+     * Launch an observation-only session that denies every approval request
+     * @type {boolean}
+     * @memberof CreateSessionRequest
+     */
+    autoDenyAll?: boolean;
+    /**
+     * Tool names to deny automatically; entries ending in "*" match by prefix
+     * @type {Array<string>}
+     * @memberof CreateSessionRequest
+     */
+    autoDenyTools?: Array<string>;
+    /**
+     * 
+     * @type {SessionBudget}
+     * @memberof CreateSessionRequest
+     */
+    budget?: SessionBudget;
+    /**
+     * Label of the saved template the session was launched from
+     * @type {string}
+     * @memberof CreateSessionRequest
+     */
+    template?: string;
+    /**
+     * Run the agent in a container using the template's configured image
+     * @type {boolean}
+     * @memberof CreateSessionRequest
+     */
+    container?: boolean;
+    /**
+     * Build the working directory's devcontainer.json and run the agent in it. Setup progress is reported as devcontainer_progress events.
+     * @type {boolean}
+     * @memberof CreateSessionRequest
+     */
+    devcontainer?: boolean;
+    /**
+     * Launch queue priority. When max_concurrent_sessions are running the session waits as "queued"; higher priorities start first.
+     * @type {number}
+     * @memberof CreateSessionRequest
+     */
+    priority?: number;
+    /**
+     * Add the decisions recorded for the working directory's repository to the system prompt. Always on when decision_log.inject_context is set.
+     * @type {boolean}
+     * @memberof CreateSessionRequest
+     */
+    includeDecisions?: boolean;
+    /**
+     * Context pack from POST /context-packs to add to the system prompt
+     * @type {string}
+     * @memberof CreateSessionRequest
+     */
+    contextPackId?: string;
     /**
      * Enable verbose output
      * @type {boolean}
@@ -203,6 +270,7 @@ export function CreateSessionRequestFromJSONTyped(json: any, ignoreDiscriminator
         'mcpConfig': json['mcp_config'] == null ? undefined : MCPConfigFromJSON(json['mcp_config']),
         'permissionPromptTool': json['permission_prompt_tool'] == null ? undefined : json['permission_prompt_tool'],
         'workingDir': json['working_dir'] == null ? undefined : json['working_dir'],
+        'useWorktree': json['use_worktree'] == null ? undefined : json['use_worktree'],
         'maxTurns': json['max_turns'] == null ? undefined : json['max_turns'],
         'systemPrompt': json['system_prompt'] == null ? undefined : json['system_prompt'],
         'appendSystemPrompt': json['append_system_prompt'] == null ? undefined : json['append_system_prompt'],
@@ -213,6 +281,15 @@ export function CreateSessionRequestFromJSONTyped(json: any, ignoreDiscriminator
         'autoAcceptEdits': json['auto_accept_edits'] == null ? undefined : json['auto_accept_edits'],
         'dangerouslySkipPermissions': json['dangerously_skip_permissions'] == null ? undefined : json['dangerously_skip_permissions'],
         'dangerouslySkipPermissionsTimeout': json['dangerously_skip_permissions_timeout'] == null ? undefined : json['dangerously_skip_permissions_timeout'],
+        'autoDenyAll': json['auto_deny_all'] == null ? undefined : json['auto_deny_all'],
+        'autoDenyTools': json['auto_deny_tools'] == null ? undefined : json['auto_deny_tools'],
+        'budget': json['budget'] == null ? undefined : SessionBudgetFromJSON(json['budget']),
+        'template': json['template'] == null ? undefined : json['template'],
+        'container': json['container'] == null ? undefined : json['container'],
+        'devcontainer': json['devcontainer'] == null ? undefined : json['devcontainer'],
+        'priority': json['priority'] == null ? undefined : json['priority'],
+        'includeDecisions': json['include_decisions'] == null ? undefined : json['include_decisions'],
+        'contextPackId': json['context_pack_id'] == null ? undefined : json['context_pack_id'],
         'verbose': json['verbose'] == null ? undefined : json['verbose'],
         'proxyEnabled': json['proxy_enabled'] == null ? undefined : json['proxy_enabled'],
         'proxyBaseUrl': json['proxy_base_url'] == null ? undefined : json['proxy_base_url'],
@@ -240,6 +317,7 @@ export function CreateSessionRequestToJSONTyped(value?: CreateSessionRequest | n
         'mcp_config': MCPConfigToJSON(value['mcpConfig']),
         'permission_prompt_tool': value['permissionPromptTool'],
         'working_dir': value['workingDir'],
+        'use_worktree': value['useWorktree'],
         'max_turns': value['maxTurns'],
         'system_prompt': value['systemPrompt'],
         'append_system_prompt': value['appendSystemPrompt'],
@@ -250,6 +328,15 @@ export function CreateSessionRequestToJSONTyped(value?: CreateSessionRequest | n
         'auto_accept_edits': value['autoAcceptEdits'],
         'dangerously_skip_permissions': value['dangerouslySkipPermissions'],
         'dangerously_skip_permissions_timeout': value['dangerouslySkipPermissionsTimeout'],
+        'auto_deny_all': value['autoDenyAll'],
+        'auto_deny_tools': value['autoDenyTools'],
+        'budget': SessionBudgetToJSON(value['budget']),
+        'template': value['template'],
+        'container': value['container'],
+        'devcontainer': value['devcontainer'],
+        'priority': value['priority'],
+        'include_decisions': value['includeDecisions'],
+        'context_pack_id': value['contextPackId'],
         'verbose': value['verbose'],
         'proxy_enabled': value['proxyEnabled'],
         'proxy_base_url': value['proxyBaseUrl'],
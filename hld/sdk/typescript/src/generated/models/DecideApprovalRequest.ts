@@ -13,6 +13,14 @@
  */
 
 import { mapValues } from '../runtime';
+import type { ApprovalConstraints } from './ApprovalConstraints';
+import {
+    ApprovalConstraintsFromJSON,
+    ApprovalConstraintsFromJSONTyped,
+    ApprovalConstraintsToJSON,
+    ApprovalConstraintsToJSONTyped,
+} from './ApprovalConstraints';
+
 /**
  * 
  * @export
@@ -26,7 +34,7 @@ export interface DecideApprovalRequest {
      */
     decision: DecideApprovalRequestDecisionEnum;
     /**
-     * Optional comment (required for deny)
+     * Optional comment (required for deny, and for approving a tool call at or above approval_justification_risk)
      * @type {string}
      * @memberof DecideApprovalRequest
      */
@@ -40,6 +48,18 @@ export interface DecideApprovalRequest {
      * @memberof DecideApprovalRequest
      */
     imagePaths?: Array<string>;
+    /**
+     * Canned response whose text is the comment, followed by comment if one is also given. Satisfies the comment a denial needs.
+     * @type {string}
+     * @memberof DecideApprovalRequest
+     */
+    cannedResponseId?: string;
+    /**
+     * 
+     * @type {ApprovalConstraints}
+     * @memberof DecideApprovalRequest
+     */
+    constraints?: ApprovalConstraints;
 }
 
 
@@ -74,6 +94,8 @@ export function DecideApprovalRequestFromJSONTyped(json: any, ignoreDiscriminato
         'decision': json['decision'],
         'comment': json['comment'] == null ? undefined : json['comment'],
         'imagePaths': json['image_paths'] == null ? undefined : json['image_paths'],
+        'cannedResponseId': json['canned_response_id'] == null ? undefined : json['canned_response_id'],
+        'constraints': json['constraints'] == null ? undefined : ApprovalConstraintsFromJSON(json['constraints']),
     };
 }
 
@@ -91,6 +113,8 @@ export function DecideApprovalRequestToJSONTyped(value?: DecideApprovalRequest |
         'decision': value['decision'],
         'comment': value['comment'],
         'image_paths': value['imagePaths'],
+        'canned_response_id': value['cannedResponseId'],
+        'constraints': ApprovalConstraintsToJSON(value['constraints']),
     };
 }
 
@@ -22,7 +22,26 @@ export const EventType = {
     ApprovalResolved: 'approval_resolved',
     SessionStatusChanged: 'session_status_changed',
     ConversationUpdated: 'conversation_updated',
-    SessionSettingsChanged: 'session_settings_changed'
+    SessionSettingsChanged: 'session_settings_changed',
+    ToolResultReported: 'tool_result_reported',
+    SessionBudgetExceeded: 'session_budget_exceeded',
+    CostBudgetThreshold: 'cost_budget_threshold',
+    CommitMessageProgress: 'commit_message_progress',
+    JobCompleted: 'job_completed',
+    DevcontainerProgress: 'devcontainer_progress',
+    SessionSummaryReady: 'session_summary_ready',
+    DailyDigest: 'daily_digest',
+    SessionQueued: 'session_queued',
+    SessionRetryScheduled: 'session_retry_scheduled',
+    ArtifactPublished: 'artifact_published',
+    ApprovalReminder: 'approval_reminder',
+    ApprovalHeld: 'approval_held',
+    ApprovalUnheld: 'approval_unheld',
+    ApprovalConstraintViolated: 'approval_constraint_violated',
+    CloudApprovalConflict: 'cloud_approval_conflict',
+    DiskGuardRefused: 'disk_guard_refused',
+    CommandOutput: 'command_output',
+    ApprovalDecisionFailed: 'approval_decision_failed'
 } as const;
 export type EventType = typeof EventType[keyof typeof EventType];
 
@@ -0,0 +1,139 @@
+/* tslint:disable */
+/* eslint-disable */
+/**
+ * HumanLayer Daemon REST API
+ * REST API for HumanLayer daemon operations, providing session management, approval workflows, and real-time event streaming capabilities. 
+ *
+ * The version of the OpenAPI document: 1.0.0
+ * 
+ *
+ * NOTE: This class is auto generated by OpenAPI Generator (https://openapi-generator.tech).
+ * https://openapi-generator.tech
+ * Do not edit the class manually.
+ */
+
+import { mapValues } from '../runtime';
+import type { InfraResourceChange } from './InfraResourceChange';
+import {
+    InfraResourceChangeFromJSON,
+    InfraResourceChangeFromJSONTyped,
+    InfraResourceChangeToJSON,
+    InfraResourceChangeToJSONTyped,
+} from './InfraResourceChange';
+
+/**
+ * Summary of a terraform plan or kubectl diff found in the tool input
+ * @export
+ * @interface InfraChangeSummary
+ */
+export interface InfraChangeSummary {
+    /**
+     * Tool that produced the plan or diff
+     * @type {string}
+     * @memberof InfraChangeSummary
+     */
+    tool: InfraChangeSummaryToolEnum;
+    /**
+     * Resources to create
+     * @type {number}
+     * @memberof InfraChangeSummary
+     */
+    add: number;
+    /**
+     * Resources to update in place
+     * @type {number}
+     * @memberof InfraChangeSummary
+     */
+    change: number;
+    /**
+     * Resources to destroy and recreate
+     * @type {number}
+     * @memberof InfraChangeSummary
+     */
+    replace: number;
+    /**
+     * Resources to delete
+     * @type {number}
+     * @memberof InfraChangeSummary
+     */
+    destroy: number;
+    /**
+     * 
+     * @type {Array<InfraResourceChange>}
+     * @memberof InfraChangeSummary
+     */
+    resources: Array<InfraResourceChange>;
+    /**
+     * One-line description of the counts
+     * @type {string}
+     * @memberof InfraChangeSummary
+     */
+    summary: string;
+}
+
+
+/**
+ * @export
+ */
+export const InfraChangeSummaryToolEnum = {
+    Terraform: 'terraform',
+    Kubectl: 'kubectl'
+} as const;
+export type InfraChangeSummaryToolEnum = typeof InfraChangeSummaryToolEnum[keyof typeof InfraChangeSummaryToolEnum];
+
+
+/**
+ * Check if a given object implements the InfraChangeSummary interface.
+ */
+export function instanceOfInfraChangeSummary(value: object): value is InfraChangeSummary {
+    if (!('tool' in value) || value['tool'] === undefined) return false;
+    if (!('add' in value) || value['add'] === undefined) return false;
+    if (!('change' in value) || value['change'] === undefined) return false;
+    if (!('replace' in value) || value['replace'] === undefined) return false;
+    if (!('destroy' in value) || value['destroy'] === undefined) return false;
+    if (!('resources' in value) || value['resources'] === undefined) return false;
+    if (!('summary' in value) || value['summary'] === undefined) return false;
+    return true;
+}
+
+export function InfraChangeSummaryFromJSON(json: any): InfraChangeSummary {
+    return InfraChangeSummaryFromJSONTyped(json, false);
+}
+
+export function InfraChangeSummaryFromJSONTyped(json: any, ignoreDiscriminator: boolean): InfraChangeSummary {
+    if (json == null) {
+        return json;
+    }
+    return {
+        
+        'tool': json['tool'],
+        'add': json['add'],
+        'change': json['change'],
+        'replace': json['replace'],
+        'destroy': json['destroy'],
+        'resources': ((json['resources'] as Array<any>).map(InfraResourceChangeFromJSON)),
+        'summary': json['summary'],
+    };
+}
+
+export function InfraChangeSummaryToJSON(json: any): InfraChangeSummary {
+    return InfraChangeSummaryToJSONTyped(json, false);
+}
+
+export function InfraChangeSummaryToJSONTyped(value?: InfraChangeSummary | null, ignoreDiscriminator: boolean = false): any {
+    if (value == null) {
+        return value;
+    }
+
+    return {
+        
+        'tool': value['tool'],
+        'add': value['add'],
+        'change': value['change'],
+        'replace': value['replace'],
+        'destroy': value['destroy'],
+        'resources': ((value['resources'] as Array<any>).map(InfraResourceChangeToJSON)),
+        'summary': value['summary'],
+    };
+}
+
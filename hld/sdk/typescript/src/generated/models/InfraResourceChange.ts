@@ -0,0 +1,97 @@
+/* tslint:disable */
+/* eslint-disable */
+/**
+ * HumanLayer Daemon REST API
+ * REST API for HumanLayer daemon operations, providing session management, approval workflows, and real-time event streaming capabilities. 
+ *
+ * The version of the OpenAPI document: 1.0.0
+ * 
+ *
+ * NOTE: This class is auto generated by OpenAPI Generator (https://openapi-generator.tech).
+ * https://openapi-generator.tech
+ * Do not edit the class manually.
+ */
+
+import { mapValues } from '../runtime';
+/**
+ * 
+ * @export
+ * @interface InfraResourceChange
+ */
+export interface InfraResourceChange {
+    /**
+     * Terraform resource address, or Kubernetes kind and namespace/name
+     * @type {string}
+     * @memberof InfraResourceChange
+     */
+    address: string;
+    /**
+     * 
+     * @type {string}
+     * @memberof InfraResourceChange
+     */
+    action: InfraResourceChangeActionEnum;
+    /**
+     * Extra detail, such as diff line counts for kubectl
+     * @type {string}
+     * @memberof InfraResourceChange
+     */
+    detail?: string;
+}
+
+
+/**
+ * @export
+ */
+export const InfraResourceChangeActionEnum = {
+    Create: 'create',
+    Update: 'update',
+    Replace: 'replace',
+    Delete: 'delete',
+    Read: 'read'
+} as const;
+export type InfraResourceChangeActionEnum = typeof InfraResourceChangeActionEnum[keyof typeof InfraResourceChangeActionEnum];
+
+
+/**
+ * Check if a given object implements the InfraResourceChange interface.
+ */
+export function instanceOfInfraResourceChange(value: object): value is InfraResourceChange {
+    if (!('address' in value) || value['address'] === undefined) return false;
+    if (!('action' in value) || value['action'] === undefined) return false;
+    return true;
+}
+
+export function InfraResourceChangeFromJSON(json: any): InfraResourceChange {
+    return InfraResourceChangeFromJSONTyped(json, false);
+}
+
+export function InfraResourceChangeFromJSONTyped(json: any, ignoreDiscriminator: boolean): InfraResourceChange {
+    if (json == null) {
+        return json;
+    }
+    return {
+        
+        'address': json['address'],
+        'action': json['action'],
+        'detail': json['detail'] == null ? undefined : json['detail'],
+    };
+}
+
+export function InfraResourceChangeToJSON(json: any): InfraResourceChange {
+    return InfraResourceChangeToJSONTyped(json, false);
+}
+
+export function InfraResourceChangeToJSONTyped(value?: InfraResourceChange | null, ignoreDiscriminator: boolean = false): any {
+    if (value == null) {
+        return value;
+    }
+
+    return {
+        
+        'address': value['address'],
+        'action': value['action'],
+        'detail': value['detail'],
+    };
+}
+
@@ -0,0 +1,130 @@
+/* tslint:disable */
+/* eslint-disable */
+/**
+ * HumanLayer Daemon REST API
+ * REST API for HumanLayer daemon operations, providing session management, approval workflows, and real-time event streaming capabilities. 
+ *
+ * The version of the OpenAPI document: 1.0.0
+ * 
+ *
+ * NOTE: This class is auto generated by OpenAPI Generator (https://openapi-generator.tech).
+ * https://openapi-generator.tech
+ * Do not edit the class manually.
+ */
+
+import { mapValues } from '../runtime';
+/**
+ * 
+ * @export
+ * @interface MigrationWarning
+ */
+export interface MigrationWarning {
+    /**
+     * Migration file, empty for SQL passed to a database client
+     * @type {string}
+     * @memberof MigrationWarning
+     */
+    file: string;
+    /**
+     * Line the statement starts on
+     * @type {number}
+     * @memberof MigrationWarning
+     */
+    line?: number;
+    /**
+     * 
+     * @type {string}
+     * @memberof MigrationWarning
+     */
+    rule: MigrationWarningRuleEnum;
+    /**
+     * 
+     * @type {string}
+     * @memberof MigrationWarning
+     */
+    severity: MigrationWarningSeverityEnum;
+    /**
+     * 
+     * @type {string}
+     * @memberof MigrationWarning
+     */
+    message: string;
+    /**
+     * The offending statement, truncated
+     * @type {string}
+     * @memberof MigrationWarning
+     */
+    statement?: string;
+}
+
+
+/**
+ * @export
+ */
+export const MigrationWarningRuleEnum = {
+    Destructive: 'destructive',
+    Lock: 'lock',
+    MissingDown: 'missing_down'
+} as const;
+export type MigrationWarningRuleEnum = typeof MigrationWarningRuleEnum[keyof typeof MigrationWarningRuleEnum];
+
+/**
+ * @export
+ */
+export const MigrationWarningSeverityEnum = {
+    High: 'high',
+    Medium: 'medium'
+} as const;
+export type MigrationWarningSeverityEnum = typeof MigrationWarningSeverityEnum[keyof typeof MigrationWarningSeverityEnum];
+
+
+/**
+ * Check if a given object implements the MigrationWarning interface.
+ */
+export function instanceOfMigrationWarning(value: object): value is MigrationWarning {
+    if (!('file' in value) || value['file'] === undefined) return false;
+    if (!('rule' in value) || value['rule'] === undefined) return false;
+    if (!('severity' in value) || value['severity'] === undefined) return false;
+    if (!('message' in value) || value['message'] === undefined) return false;
+    return true;
+}
+
+export function MigrationWarningFromJSON(json: any): MigrationWarning {
+    return MigrationWarningFromJSONTyped(json, false);
+}
+
+export function MigrationWarningFromJSONTyped(json: any, ignoreDiscriminator: boolean): MigrationWarning {
+    if (json == null) {
+        return json;
+    }
+    return {
+        
+        'file': json['file'],
+        'line': json['line'] == null ? undefined : json['line'],
+        'rule': json['rule'],
+        'severity': json['severity'],
+        'message': json['message'],
+        'statement': json['statement'] == null ? undefined : json['statement'],
+    };
+}
+
+export function MigrationWarningToJSON(json: any): MigrationWarning {
+    return MigrationWarningToJSONTyped(json, false);
+}
+
+export function MigrationWarningToJSONTyped(value?: MigrationWarning | null, ignoreDiscriminator: boolean = false): any {
+    if (value == null) {
+        return value;
+    }
+
+    return {
+        
+        'file': value['file'],
+        'line': value['line'],
+        'rule': value['rule'],
+        'severity': value['severity'],
+        'message': value['message'],
+        'statement': value['statement'],
+    };
+}
+
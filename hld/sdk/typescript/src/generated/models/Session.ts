@@ -13,6 +13,27 @@
  */
 
 import { mapValues } from '../runtime';
+import type { SessionBudget } from './SessionBudget';
+import type { SessionProcess } from './SessionProcess';
+import {
+    SessionProcessFromJSON,
+    SessionProcessFromJSONTyped,
+    SessionProcessToJSON,
+    SessionProcessToJSONTyped,
+} from './SessionProcess';
+import type { SessionCompletionSummary } from './SessionCompletionSummary';
+import {
+    SessionCompletionSummaryFromJSON,
+    SessionCompletionSummaryFromJSONTyped,
+    SessionCompletionSummaryToJSON,
+    SessionCompletionSummaryToJSONTyped,
+} from './SessionCompletionSummary';
+import {
+    SessionBudgetFromJSON,
+    SessionBudgetFromJSONTyped,
+    SessionBudgetToJSON,
+    SessionBudgetToJSONTyped,
+} from './SessionBudget';
 import type { SessionStatus } from './SessionStatus';
 import {
     SessionStatusFromJSON,
@@ -189,6 +210,66 @@ export interface Session {
      * @memberof Session
      */
     dangerouslySkipPermissionsExpiresAt?: Date;
+    /**
+     * Whether every approval request is denied automatically (observation-only session)
+     * @type {boolean}
+     * @memberof Session
+     */
+    autoDenyAll?: boolean;
+    /**
+     * Tool names denied automatically; entries ending in "*" match by prefix
+     * @type {Array<string>}
+     * @memberof Session
+     */
+    autoDenyTools?: Array<string>;
+    /**
+     * 
+     * @type {SessionBudget}
+     * @memberof Session
+     */
+    budget?: SessionBudget;
+    /**
+     * Label of the saved template the session was launched from
+     * @type {string}
+     * @memberof Session
+     */
+    template?: string;
+    /**
+     * SSH destination holding the working directory; git commands and file reads run there
+     * @type {string}
+     * @memberof Session
+     */
+    sshHost?: string;
+    /**
+     * Container image the agent runs in; absent when it runs on the host
+     * @type {string}
+     * @memberof Session
+     */
+    containerImage?: string;
+    /**
+     * ID of the failed session this session automatically retries
+     * @type {string}
+     * @memberof Session
+     */
+    retryOf?: string;
+    /**
+     * ID of the context pack attached when the session was launched
+     * @type {string}
+     * @memberof Session
+     */
+    contextPackId?: string;
+    /**
+     * 
+     * @type {SessionCompletionSummary}
+     * @memberof Session
+     */
+    completionSummary?: SessionCompletionSummary;
+    /**
+     * 
+     * @type {SessionProcess}
+     * @memberof Session
+     */
+    process?: SessionProcess;
     /**
      * Whether session is archived
      * @type {boolean}
@@ -279,6 +360,16 @@ export function SessionFromJSONTyped(json: any, ignoreDiscriminator: boolean): S
         'autoAcceptEdits': json['auto_accept_edits'] == null ? undefined : json['auto_accept_edits'],
         'dangerouslySkipPermissions': json['dangerously_skip_permissions'] == null ? undefined : json['dangerously_skip_permissions'],
         'dangerouslySkipPermissionsExpiresAt': json['dangerously_skip_permissions_expires_at'] == null ? undefined : (new Date(json['dangerously_skip_permissions_expires_at'])),
+        'autoDenyAll': json['auto_deny_all'] == null ? undefined : json['auto_deny_all'],
+        'autoDenyTools': json['auto_deny_tools'] == null ? undefined : json['auto_deny_tools'],
+        'budget': json['budget'] == null ? undefined : SessionBudgetFromJSON(json['budget']),
+        'template': json['template'] == null ? undefined : json['template'],
+        'sshHost': json['ssh_host'] == null ? undefined : json['ssh_host'],
+        'containerImage': json['container_image'] == null ? undefined : json['container_image'],
+        'retryOf': json['retry_of'] == null ? undefined : json['retry_of'],
+        'contextPackId': json['context_pack_id'] == null ? undefined : json['context_pack_id'],
+        'completionSummary': json['completion_summary'] == null ? undefined : SessionCompletionSummaryFromJSON(json['completion_summary']),
+        'process': json['process'] == null ? undefined : SessionProcessFromJSON(json['process']),
         'archived': json['archived'] == null ? undefined : json['archived'],
         'reviewed': json['reviewed'] == null ? undefined : json['reviewed'],
         'proxyEnabled': json['proxy_enabled'] == null ? undefined : json['proxy_enabled'],
@@ -326,6 +417,16 @@ export function SessionToJSONTyped(value?: Session | null, ignoreDiscriminator:
         'auto_accept_edits': value['autoAcceptEdits'],
         'dangerously_skip_permissions': value['dangerouslySkipPermissions'],
         'dangerously_skip_permissions_expires_at': value['dangerouslySkipPermissionsExpiresAt'] == null ? undefined : ((value['dangerouslySkipPermissionsExpiresAt']).toISOString()),
+        'auto_deny_all': value['autoDenyAll'],
+        'auto_deny_tools': value['autoDenyTools'],
+        'budget': SessionBudgetToJSON(value['budget']),
+        'template': value['template'],
+        'ssh_host': value['sshHost'],
+        'container_image': value['containerImage'],
+        'retry_of': value['retryOf'],
+        'context_pack_id': value['contextPackId'],
+        'completion_summary': SessionCompletionSummaryToJSON(value['completionSummary']),
+        'process': SessionProcessToJSON(value['process']),
         'archived': value['archived'],
         'reviewed': value['reviewed'],
         'proxy_enabled': value['proxyEnabled'],
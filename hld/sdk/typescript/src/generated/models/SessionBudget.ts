@@ -0,0 +1,81 @@
+/* tslint:disable */
+/* eslint-disable */
+/**
+ * HumanLayer Daemon REST API
+ * REST API for HumanLayer daemon operations, providing session management, approval workflows, and real-time event streaming capabilities. 
+ *
+ * The version of the OpenAPI document: 1.0.0
+ * 
+ *
+ * NOTE: This class is auto generated by OpenAPI Generator (https://openapi-generator.tech).
+ * https://openapi-generator.tech
+ * Do not edit the class manually.
+ */
+
+import { mapValues } from '../runtime';
+/**
+ * Resource limits for a session and the sessions continued from it. Omitted or zero limits are unlimited.
+ * @export
+ * @interface SessionBudget
+ */
+export interface SessionBudget {
+    /**
+     * Maximum number of tool calls
+     * @type {number}
+     * @memberof SessionBudget
+     */
+    maxToolCalls?: number;
+    /**
+     * Maximum total cost in USD
+     * @type {number}
+     * @memberof SessionBudget
+     */
+    maxCostUsd?: number;
+    /**
+     * Maximum total run time in seconds
+     * @type {number}
+     * @memberof SessionBudget
+     */
+    maxDurationSeconds?: number;
+}
+
+/**
+ * Check if a given object implements the SessionBudget interface.
+ */
+export function instanceOfSessionBudget(value: object): value is SessionBudget {
+    return true;
+}
+
+export function SessionBudgetFromJSON(json: any): SessionBudget {
+    return SessionBudgetFromJSONTyped(json, false);
+}
+
+export function SessionBudgetFromJSONTyped(json: any, ignoreDiscriminator: boolean): SessionBudget {
+    if (json == null) {
+        return json;
+    }
+    return {
+        
+        'maxToolCalls': json['max_tool_calls'] == null ? undefined : json['max_tool_calls'],
+        'maxCostUsd': json['max_cost_usd'] == null ? undefined : json['max_cost_usd'],
+        'maxDurationSeconds': json['max_duration_seconds'] == null ? undefined : json['max_duration_seconds'],
+    };
+}
+
+export function SessionBudgetToJSON(json: any): SessionBudget {
+    return SessionBudgetToJSONTyped(json, false);
+}
+
+export function SessionBudgetToJSONTyped(value?: SessionBudget | null, ignoreDiscriminator: boolean = false): any {
+    if (value == null) {
+        return value;
+    }
+
+    return {
+        
+        'max_tool_calls': value['maxToolCalls'],
+        'max_cost_usd': value['maxCostUsd'],
+        'max_duration_seconds': value['maxDurationSeconds'],
+    };
+}
+
@@ -0,0 +1,93 @@
+/* tslint:disable */
+/* eslint-disable */
+/**
+ * HumanLayer Daemon REST API
+ * REST API for HumanLayer daemon operations, providing session management, approval workflows, and real-time event streaming capabilities. 
+ *
+ * The version of the OpenAPI document: 1.0.0
+ * 
+ *
+ * NOTE: This class is auto generated by OpenAPI Generator (https://openapi-generator.tech).
+ * https://openapi-generator.tech
+ * Do not edit the class manually.
+ */
+
+import { mapValues } from '../runtime';
+/**
+ * Structured summary generated when the session finished
+ * @export
+ * @interface SessionCompletionSummary
+ */
+export interface SessionCompletionSummary {
+    /**
+     * What the session was asked to do
+     * @type {string}
+     * @memberof SessionCompletionSummary
+     */
+    asked: string;
+    /**
+     * Changes the session made
+     * @type {Array<string>}
+     * @memberof SessionCompletionSummary
+     */
+    changed: Array<string>;
+    /**
+     * Questions left unanswered
+     * @type {Array<string>}
+     * @memberof SessionCompletionSummary
+     */
+    openQuestions: Array<string>;
+    /**
+     * Suggested next steps
+     * @type {Array<string>}
+     * @memberof SessionCompletionSummary
+     */
+    followUps: Array<string>;
+}
+
+/**
+ * Check if a given object implements the SessionCompletionSummary interface.
+ */
+export function instanceOfSessionCompletionSummary(value: object): value is SessionCompletionSummary {
+    if (!('asked' in value) || value['asked'] === undefined) return false;
+    if (!('changed' in value) || value['changed'] === undefined) return false;
+    if (!('openQuestions' in value) || value['openQuestions'] === undefined) return false;
+    if (!('followUps' in value) || value['followUps'] === undefined) return false;
+    return true;
+}
+
+export function SessionCompletionSummaryFromJSON(json: any): SessionCompletionSummary {
+    return SessionCompletionSummaryFromJSONTyped(json, false);
+}
+
+export function SessionCompletionSummaryFromJSONTyped(json: any, ignoreDiscriminator: boolean): SessionCompletionSummary {
+    if (json == null) {
+        return json;
+    }
+    return {
+        
+        'asked': json['asked'],
+        'changed': json['changed'],
+        'openQuestions': json['open_questions'],
+        'followUps': json['follow_ups'],
+    };
+}
+
+export function SessionCompletionSummaryToJSON(json: any): SessionCompletionSummary {
+    return SessionCompletionSummaryToJSONTyped(json, false);
+}
+
+export function SessionCompletionSummaryToJSONTyped(value?: SessionCompletionSummary | null, ignoreDiscriminator: boolean = false): any {
+    if (value == null) {
+        return value;
+    }
+
+    return {
+        
+        'asked': value['asked'],
+        'changed': value['changed'],
+        'open_questions': value['openQuestions'],
+        'follow_ups': value['followUps'],
+    };
+}
+
@@ -0,0 +1,150 @@
+/* tslint:disable */
+/* eslint-disable */
+/**
+ * HumanLayer Daemon REST API
+ * REST API for HumanLayer daemon operations, providing session management, approval workflows, and real-time event streaming capabilities. 
+ *
+ * The version of the OpenAPI document: 1.0.0
+ * 
+ *
+ * NOTE: This class is auto generated by OpenAPI Generator (https://openapi-generator.tech).
+ * https://openapi-generator.tech
+ * Do not edit the class manually.
+ */
+import { mapValues } from '../runtime';
+/**
+ * The Claude process the session ran in, only returned by GET /sessions/{id}. Resource figures are sampled every few seconds while it runs.
+ * @export
+ * @interface SessionProcess
+ */
+export interface SessionProcess {
+    /**
+     * 
+     * @type {number}
+     * @memberof SessionProcess
+     */
+    pid: number;
+    /**
+     * 
+     * @type {boolean}
+     * @memberof SessionProcess
+     */
+    running: boolean;
+    /**
+     * 
+     * @type {Date}
+     * @memberof SessionProcess
+     */
+    startedAt: Date;
+    /**
+     * 
+     * @type {Date}
+     * @memberof SessionProcess
+     */
+    exitedAt?: Date;
+    /**
+     * Exit code; -1 when the process was killed by a signal
+     * @type {number}
+     * @memberof SessionProcess
+     */
+    exitCode?: number;
+    /**
+     * Signal that killed the process
+     * @type {string}
+     * @memberof SessionProcess
+     */
+    exitSignal?: string;
+    /**
+     * The last 4 KiB the process wrote to stderr
+     * @type {string}
+     * @memberof SessionProcess
+     */
+    stderrTail?: string;
+    /**
+     * Resident memory at the last sample
+     * @type {number}
+     * @memberof SessionProcess
+     */
+    rssBytes: number;
+    /**
+     * Highest resident memory sampled
+     * @type {number}
+     * @memberof SessionProcess
+     */
+    peakRssBytes: number;
+    /**
+     * CPU time used
+     * @type {number}
+     * @memberof SessionProcess
+     */
+    cpuSeconds: number;
+    /**
+     * Share of one core used since the previous sample, while running
+     * @type {number}
+     * @memberof SessionProcess
+     */
+    cpuPercent?: number;
+}
+
+/**
+ * Check if a given object implements the SessionProcess interface.
+ */
+export function instanceOfSessionProcess(value: object): value is SessionProcess {
+    if (!('pid' in value) || value['pid'] === undefined) return false;
+    if (!('running' in value) || value['running'] === undefined) return false;
+    if (!('startedAt' in value) || value['startedAt'] === undefined) return false;
+    if (!('rssBytes' in value) || value['rssBytes'] === undefined) return false;
+    if (!('peakRssBytes' in value) || value['peakRssBytes'] === undefined) return false;
+    if (!('cpuSeconds' in value) || value['cpuSeconds'] === undefined) return false;
+    return true;
+}
+
+export function SessionProcessFromJSON(json: any): SessionProcess {
+    return SessionProcessFromJSONTyped(json, false);
+}
+
+export function SessionProcessFromJSONTyped(json: any, ignoreDiscriminator: boolean): SessionProcess {
+    if (json == null) {
+        return json;
+    }
+    return {
+        
+        'pid': json['pid'],
+        'running': json['running'],
+        'startedAt': (new Date(json['started_at'])),
+        'exitedAt': json['exited_at'] == null ? undefined : (new Date(json['exited_at'])),
+        'exitCode': json['exit_code'] == null ? undefined : json['exit_code'],
+        'exitSignal': json['exit_signal'] == null ? undefined : json['exit_signal'],
+        'stderrTail': json['stderr_tail'] == null ? undefined : json['stderr_tail'],
+        'rssBytes': json['rss_bytes'],
+        'peakRssBytes': json['peak_rss_bytes'],
+        'cpuSeconds': json['cpu_seconds'],
+        'cpuPercent': json['cpu_percent'] == null ? undefined : json['cpu_percent'],
+    };
+}
+
+export function SessionProcessToJSON(json: any): SessionProcess {
+    return SessionProcessToJSONTyped(json, false);
+}
+
+export function SessionProcessToJSONTyped(value?: SessionProcess | null, ignoreDiscriminator: boolean = false): any {
+    if (value == null) {
+        return value;
+    }
+
+    return {
+        
+        'pid': value['pid'],
+        'running': value['running'],
+        'started_at': ((value['startedAt']).toISOString()),
+        'exited_at': value['exitedAt'] == null ? undefined : ((value['exitedAt']).toISOString()),
+        'exit_code': value['exitCode'],
+        'exit_signal': value['exitSignal'],
+        'stderr_tail': value['stderrTail'],
+        'rss_bytes': value['rssBytes'],
+        'peak_rss_bytes': value['peakRssBytes'],
+        'cpu_seconds': value['cpuSeconds'],
+        'cpu_percent': value['cpuPercent'],
+    };
+}
+
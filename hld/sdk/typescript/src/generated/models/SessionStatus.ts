@@ -19,6 +19,7 @@
  */
 export const SessionStatus = {
     Draft: 'draft',
+    Queued: 'queued',
     Starting: 'starting',
     Running: 'running',
     Completed: 'completed',
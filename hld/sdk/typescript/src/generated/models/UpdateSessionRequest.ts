@@ -13,6 +13,13 @@
  */
 
 import { mapValues } from '../runtime';
+import type { SessionBudget } from './SessionBudget';
+import {
+    SessionBudgetFromJSON,
+    SessionBudgetFromJSONTyped,
+    SessionBudgetToJSON,
+    SessionBudgetToJSONTyped,
+} from './SessionBudget';
 import type { SessionStatus } from './SessionStatus';
 import {
     SessionStatusFromJSON,
@@ -45,6 +52,30 @@ export interface UpdateSessionRequest {
      * @memberof UpdateSessionRequest
      */
     dangerouslySkipPermissionsTimeoutMs?: number;
+    /**
+     * Enable or disable denying every approval request
+     * @type {boolean}
+     * @memberof UpdateSessionRequest
+     */
+    autoDenyAll?: boolean;
+    /**
+     * Replace the list of tools denied automatically (empty list clears it)
+     * @type {Array<string>}
+     * @memberof UpdateSessionRequest
+     */
+    autoDenyTools?: Array<string>;
+    /**
+     * 
+     * @type {SessionBudget}
+     * @memberof UpdateSessionRequest
+     */
+    budget?: SessionBudget;
+    /**
+     * SSH destination (host, user@host or ssh config alias) holding the working directory; empty makes it local
+     * @type {string}
+     * @memberof UpdateSessionRequest
+     */
+    sshHost?: string;
     /**
      * Archive/unarchive the session
      * @type {boolean}
@@ -147,6 +178,10 @@ export function UpdateSessionRequestFromJSONTyped(json: any, ignoreDiscriminator
         'autoAcceptEdits': json['auto_accept_edits'] == null ? undefined : json['auto_accept_edits'],
         'dangerouslySkipPermissions': json['dangerously_skip_permissions'] == null ? undefined : json['dangerously_skip_permissions'],
         'dangerouslySkipPermissionsTimeoutMs': json['dangerously_skip_permissions_timeout_ms'] == null ? undefined : json['dangerously_skip_permissions_timeout_ms'],
+        'autoDenyAll': json['auto_deny_all'] == null ? undefined : json['auto_deny_all'],
+        'autoDenyTools': json['auto_deny_tools'] == null ? undefined : json['auto_deny_tools'],
+        'budget': json['budget'] == null ? undefined : SessionBudgetFromJSON(json['budget']),
+        'sshHost': json['ssh_host'] == null ? undefined : json['ssh_host'],
         'archived': json['archived'] == null ? undefined : json['archived'],
         'reviewed': json['reviewed'] == null ? undefined : json['reviewed'],
         'title': json['title'] == null ? undefined : json['title'],
@@ -177,6 +212,10 @@ export function UpdateSessionRequestToJSONTyped(value?: UpdateSessionRequest | n
         'auto_accept_edits': value['autoAcceptEdits'],
         'dangerously_skip_permissions': value['dangerouslySkipPermissions'],
         'dangerously_skip_permissions_timeout_ms': value['dangerouslySkipPermissionsTimeoutMs'],
+        'auto_deny_all': value['autoDenyAll'],
+        'auto_deny_tools': value['autoDenyTools'],
+        'budget': SessionBudgetToJSON(value['budget']),
+        'ssh_host': value['sshHost'],
         'archived': value['archived'],
         'reviewed': value['reviewed'],
         'title': value['title'],
@@ -2,6 +2,9 @@
 /* eslint-disable */
 export * from './Agent';
 export * from './Approval';
+export * from './ApprovalAttachment';
+export * from './ApprovalConstraints';
+export * from './ApprovalAttachmentUpload';
 export * from './ApprovalResponse';
 export * from './ApprovalStatus';
 export * from './ApprovalsResponse';
@@ -46,15 +49,21 @@ export * from './FuzzySearchFilesResponse';
 export * from './HealthResponse';
 export * from './HealthResponseDependencies';
 export * from './HealthResponseDependenciesClaude';
+export * from './InfraChangeSummary';
+export * from './InfraResourceChange';
 export * from './InterruptSessionResponse';
 export * from './InterruptSessionResponseData';
 export * from './LaunchDraftSessionRequest';
 export * from './MCPConfig';
 export * from './MCPServer';
+export * from './MigrationWarning';
 export * from './RecentPath';
 export * from './RecentPathsResponse';
 export * from './SearchMetadata';
 export * from './Session';
+export * from './SessionBudget';
+export * from './SessionCompletionSummary';
+export * from './SessionProcess';
 export * from './SessionResponse';
 export * from './SessionSearchResponse';
 export * from './SessionStatus';
@@ -0,0 +1,137 @@
+// Types of the git endpoints under /sessions/{id}/git, which are served
+// outside the OpenAPI spec. Fields the SDK doesn't model are still present
+// on the parsed JSON.
+
+export interface GitFile {
+  path: string
+  status: string
+  oldPath?: string
+  diff?: string
+  similarity?: number
+  oldMode?: string
+  newMode?: string
+}
+
+export interface GitStatus {
+  staged: GitFile[]
+  unstaged: GitFile[]
+  untracked: GitFile[]
+  branch: string
+  hasChanges: boolean
+  ahead?: number
+  behind?: number
+  lfs?: boolean
+}
+
+export interface CommitMessage {
+  subject: string
+  body?: string
+  footer?: string
+  files: string[]
+}
+
+export interface CommitRequest {
+  commits: CommitMessage[]
+  createBranch?: string
+  stageUntracked?: boolean
+  stageFiles?: string[]
+  // Commit even while the session is running
+  force?: boolean
+  checkDependencies?: boolean
+  analyze?: boolean
+  format?: boolean
+  // Tags the command_output events the commit's hooks and tools stream
+  operationId?: string
+}
+
+export interface CommitResponse {
+  success: boolean
+  commitHashes: string[]
+  branchCreated?: string
+  error?: string
+  operationId: string
+}
+
+export type PatchFormat = 'mbox' | 'diff'
+
+export interface ApplyPatchRequest {
+  patch: string
+  // Falls back to a 3-way merge for hunks that don't apply; defaults to true
+  threeWay?: boolean
+  // Reports whether the patch applies without changing anything
+  check?: boolean
+  // Recreates the commits of an mbox series with git am
+  commit?: boolean
+  force?: boolean
+}
+
+export interface ApplyPatchResponse {
+  success: boolean
+  files: string[]
+  conflicts?: string[]
+  commitHashes?: string[]
+  error?: string
+}
+
+export interface GitBranch {
+  name: string
+  remote: boolean
+  current: boolean
+  commit: string
+  upstream?: string
+  subject: string
+  date: string
+  ahead: number
+  behind: number
+}
+
+export interface BranchesResponse {
+  current: string
+  default: string
+  branches: GitBranch[]
+  truncated?: boolean
+}
+
+export interface CheckoutRequest {
+  branch: string
+  create?: boolean
+  startPoint?: string
+  // Stashes uncommitted changes before switching
+  stash?: boolean
+  force?: boolean
+}
+
+export interface CheckoutResponse {
+  success: boolean
+  branch: string
+  previous?: string
+  stash?: string
+  error?: string
+}
+
+export interface GitDivergence {
+  branch: string
+  upstream: string
+  ahead: number
+  behind: number
+}
+
+export interface FetchResponse {
+  remote: string
+  divergence?: GitDivergence
+}
+
+export interface PullRequest {
+  strategy?: 'ff-only' | 'rebase' | 'merge'
+  force?: boolean
+}
+
+export interface PullResponse {
+  success: boolean
+  strategy: string
+  before: string
+  after?: string
+  divergence?: GitDivergence
+  conflicts?: string[]
+  error?: string
+}
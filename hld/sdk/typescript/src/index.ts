@@ -1,6 +1,9 @@
-export { HLDClient, HLDClientOptions, SSEEventHandlers } from './client';
+export { HLDClient, HLDClientOptions, HLDApiError, SSEEventHandlers } from './client';
 export * from './generated';
+export * from './git';
 
 // Export middleware utilities
 export { createErrorInterceptor } from './middleware';
 export type { ErrorInterceptorOptions } from './middleware';
+export { createRetryingFetch } from './retry';
+export type { RetryOptions } from './retry';
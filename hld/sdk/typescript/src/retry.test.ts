@@ -0,0 +1,60 @@
+import { describe, it, expect, mock } from 'bun:test'
+import { createRetryingFetch } from './retry'
+
+const response = (status: number, headers: Record<string, string> = {}) =>
+  new Response('{}', { status, headers })
+
+describe('createRetryingFetch', () => {
+  it('retries idempotent requests on transient statuses', async () => {
+    const statuses = [503, 502, 200]
+    const fetchImpl = mock(async () => response(statuses.shift()!))
+    const retrying = createRetryingFetch({ minDelayMs: 1 }, fetchImpl as any)
+
+    const result = await retrying('http://localhost:7777/api/v1/sessions', { method: 'GET' })
+
+    expect(result.status).toBe(200)
+    expect(fetchImpl).toHaveBeenCalledTimes(3)
+  })
+
+  it('retries connection failures and gives up after the configured retries', async () => {
+    const fetchImpl = mock(async () => {
+      throw new TypeError('fetch failed')
+    })
+    const retrying = createRetryingFetch({ retries: 2, minDelayMs: 1 }, fetchImpl as any)
+
+    await expect(retrying('http://localhost:7777/api/v1/sessions')).rejects.toThrow('fetch failed')
+    expect(fetchImpl).toHaveBeenCalledTimes(3)
+  })
+
+  it('does not retry POST requests', async () => {
+    const fetchImpl = mock(async () => response(503))
+    const retrying = createRetryingFetch({ minDelayMs: 1 }, fetchImpl as any)
+
+    const result = await retrying('http://localhost:7777/api/v1/approvals/a/decide', { method: 'POST' })
+
+    expect(result.status).toBe(503)
+    expect(fetchImpl).toHaveBeenCalledTimes(1)
+  })
+
+  it('returns client errors without retrying', async () => {
+    const fetchImpl = mock(async () => response(404))
+    const retrying = createRetryingFetch({ minDelayMs: 1 }, fetchImpl as any)
+
+    const result = await retrying('http://localhost:7777/api/v1/sessions/missing')
+
+    expect(result.status).toBe(404)
+    expect(fetchImpl).toHaveBeenCalledTimes(1)
+  })
+
+  it('waits as long as Retry-After asks, up to the maximum delay', async () => {
+    const statuses = [429, 200]
+    const fetchImpl = mock(async () => response(statuses.shift()!, { 'Retry-After': '60' }))
+    const retrying = createRetryingFetch({ minDelayMs: 1, maxDelayMs: 10 }, fetchImpl as any)
+
+    const started = Date.now()
+    const result = await retrying('http://localhost:7777/api/v1/sessions')
+
+    expect(result.status).toBe(200)
+    expect(Date.now() - started).toBeLessThan(1000)
+  })
+})
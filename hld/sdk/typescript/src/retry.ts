@@ -0,0 +1,57 @@
+import { FetchAPI } from './generated/runtime'
+
+export interface RetryOptions {
+  // Attempts after the first; 0 turns retries off. Defaults to 3.
+  retries?: number
+  // Delay before the first retry, doubled for each one after. Defaults to 250ms.
+  minDelayMs?: number
+  // Longest delay between attempts, including one asked for by Retry-After. Defaults to 5s.
+  maxDelayMs?: number
+}
+
+// Statuses worth trying again: the daemon restarting, or a proxy in front of it
+const RETRY_STATUSES = [429, 502, 503, 504]
+
+// Only requests that can safely run twice are retried. A POST such as an
+// approval decision may have reached the daemon before the connection failed.
+const IDEMPOTENT_METHODS = ['GET', 'HEAD', 'OPTIONS', 'PUT', 'DELETE']
+
+const sleep = (ms: number) => new Promise(resolve => setTimeout(resolve, ms))
+
+// retryAfterMs reads a Retry-After header given in seconds or as a date
+function retryAfterMs(response: Response): number | undefined {
+  const header = response.headers.get('Retry-After')
+  if (!header) return undefined
+  const seconds = Number(header)
+  if (!Number.isNaN(seconds)) return seconds * 1000
+  const date = Date.parse(header)
+  return Number.isNaN(date) ? undefined : Math.max(0, date - Date.now())
+}
+
+// createRetryingFetch wraps fetch to retry idempotent requests that fail to
+// connect or get a transient status, backing off exponentially
+export function createRetryingFetch(options: RetryOptions = {}, fetchImpl: FetchAPI = fetch): FetchAPI {
+  const { retries = 3, minDelayMs = 250, maxDelayMs = 5000 } = options
+
+  return async (input, init) => {
+    const method = (init?.method || 'GET').toUpperCase()
+    const attempts = IDEMPOTENT_METHODS.includes(method) ? retries + 1 : 1
+
+    for (let attempt = 1; ; attempt++) {
+      const delay = Math.min(minDelayMs * 2 ** (attempt - 1), maxDelayMs)
+      let response: Response
+      try {
+        response = await fetchImpl(input, init)
+      } catch (error) {
+        // Aborted requests were cancelled by the caller
+        if (attempt >= attempts || init?.signal?.aborted) throw error
+        await sleep(delay)
+        continue
+      }
+      if (attempt >= attempts || !RETRY_STATUSES.includes(response.status)) {
+        return response
+      }
+      await sleep(Math.min(retryAfterMs(response) ?? delay, maxDelayMs))
+    }
+  }
+}
@@ -59,5 +59,15 @@ func (w *ClaudeSessionWrapper) GetEvents() <-chan claudecode.StreamEvent {
 	return w.session.Events
 }
 
+// PID returns the process ID of the Claude process
+func (w *ClaudeSessionWrapper) PID() int {
+	return w.session.PID()
+}
+
+// ExitStatus reports how the Claude process ended, or nil while it runs
+func (w *ClaudeSessionWrapper) ExitStatus() *claudecode.ExitStatus {
+	return w.session.ExitStatus()
+}
+
 // Ensure ClaudeSessionWrapper implements ClaudeSession
 var _ ClaudeSession = (*ClaudeSessionWrapper)(nil)
@@ -0,0 +1,197 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	claudecode "github.com/humanlayer/humanlayer/claudecode-go"
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/devcontainer"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// ErrNoContainerImage is returned when a session asks for container isolation
+// but neither its template nor the daemon default has an image configured
+var ErrNoContainerImage = errors.New("no container image configured for session template")
+
+// Errors for launches that request a devcontainer
+var (
+	ErrNoDevcontainer    = errors.New("working directory has no devcontainer.json")
+	ErrDevcontainerDraft = errors.New("devcontainer setup requires an immediate launch, not a draft")
+)
+
+const defaultContainerRuntime = "docker"
+
+// containerName names a session's container so it can be removed if the
+// runtime client dies before the container exits
+func containerName(sessionID string) string {
+	return "hld-" + sessionID
+}
+
+func (m *Manager) containerRuntime() string {
+	if m.containers.Runtime != "" {
+		return m.containers.Runtime
+	}
+	return defaultContainerRuntime
+}
+
+// resolveContainerImage returns the image a new session runs in, or "" when
+// it runs on the host
+func (m *Manager) resolveContainerImage(config LaunchSessionConfig) (string, error) {
+	if !config.Container {
+		return "", nil
+	}
+	image := m.containers.ImageFor(config.Template)
+	if image == "" {
+		return "", ErrNoContainerImage
+	}
+	return image, nil
+}
+
+// findDevcontainer returns the devcontainer.json a launch asked for, or ""
+func findDevcontainer(config LaunchSessionConfig, workingDir string, isDraft bool) (string, error) {
+	if !config.Devcontainer {
+		return "", nil
+	}
+	if isDraft {
+		return "", ErrDevcontainerDraft
+	}
+	path, err := devcontainer.Find(workingDir)
+	if errors.Is(err, devcontainer.ErrNotFound) {
+		return "", ErrNoDevcontainer
+	}
+	return path, err
+}
+
+// launchInDevcontainer prepares the devcontainer image and then launches the
+// already-stored session in it. Setup can take minutes on first use, so it
+// runs in the background and reports each stage on the event bus.
+func (m *Manager) launchInDevcontainer(ctx context.Context, sessionID, runID string, config LaunchSessionConfig, path string) {
+	progress := func(stage, detail string) {
+		if m.eventBus == nil {
+			return
+		}
+		m.eventBus.Publish(bus.Event{
+			Type: bus.EventDevcontainerProgress,
+			Data: map[string]interface{}{
+				"session_id": sessionID,
+				"stage":      stage,
+				"detail":     detail,
+			},
+		})
+	}
+
+	image, err := m.prepareDevcontainer(ctx, config.WorkingDir, path, progress)
+	if err != nil {
+		slog.Error("devcontainer setup failed", "session_id", sessionID, "path", path, "error", err)
+		progress(devcontainer.StageFailed, err.Error())
+		m.updateSessionStatus(ctx, sessionID, StatusFailed, "devcontainer setup failed: "+err.Error())
+		m.releaseSlot(sessionID)
+		return
+	}
+	if err := m.store.UpdateSession(ctx, sessionID, store.SessionUpdate{ContainerImage: &image}); err != nil {
+		slog.Error("failed to record devcontainer image", "session_id", sessionID, "error", err)
+	}
+	if err := m.launchDraftWithConfig(ctx, sessionID, runID, config, image); err != nil {
+		slog.Error("failed to launch session in devcontainer", "session_id", sessionID, "error", err)
+		m.releaseSlot(sessionID)
+	}
+}
+
+func (m *Manager) prepareDevcontainer(ctx context.Context, workingDir, path string, progress devcontainer.Progress) (string, error) {
+	cfg, err := devcontainer.Load(path)
+	if err != nil {
+		return "", err
+	}
+	return devcontainer.NewBuilder(m.containerRuntime()).Prepare(ctx, cfg, absDir(workingDir), progress)
+}
+
+// wrapInContainer makes claudeConfig run inside image. Directories are
+// bind-mounted at their host paths so tool calls, file snapshots and git
+// operations all see the same files, and the daemon socket is mounted for
+// the approvals MCP server. Call it after claudeConfig.Env is final.
+func (m *Manager) wrapInContainer(sessionID, image string, claudeConfig *claudecode.SessionConfig) {
+	if image == "" {
+		return
+	}
+
+	args := []string{"run", "--rm", "-i", "--name", containerName(sessionID)}
+	if m.containers.Network != "" {
+		args = append(args, "--network", m.containers.Network)
+	}
+	dirs := append([]string{claudeConfig.WorkingDir}, claudeConfig.AdditionalDirectories...)
+	for _, dir := range dirs {
+		if dir = absDir(dir); dir != "" {
+			args = append(args, "-v", dir+":"+dir)
+		}
+	}
+	if m.socketPath != "" {
+		args = append(args, "-v", m.socketPath+":"+m.socketPath)
+	}
+	for _, mount := range m.containers.Mounts {
+		args = append(args, "-v", mount)
+	}
+	if dir := absDir(claudeConfig.WorkingDir); dir != "" {
+		args = append(args, "-w", dir)
+	}
+
+	// Pass variables by name so their values come from the runtime client's
+	// environment rather than appearing in its argument list
+	keys := make([]string, 0, len(claudeConfig.Env))
+	for key := range claudeConfig.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		args = append(args, "-e", key)
+	}
+
+	claudeConfig.Wrapper = &claudecode.CommandWrapper{
+		Command: m.containerRuntime(),
+		Args:    append(args, image),
+	}
+	slog.Info("running session in container",
+		"session_id", sessionID,
+		"image", image,
+		"runtime", m.containerRuntime(),
+		"network", m.containers.Network)
+}
+
+// removeContainer force-removes a session's container. --rm covers normal
+// exits; this catches containers left behind when the client was killed.
+func (m *Manager) removeContainer(sessionID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, m.containerRuntime(), "rm", "-f", containerName(sessionID))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		// Usually means the container already exited and was removed
+		slog.Debug("session container not removed",
+			"session_id", sessionID,
+			"error", err,
+			"output", strings.TrimSpace(string(out)))
+	}
+}
+
+// absDir expands ~ and makes dir absolute, matching how the claude client
+// resolves its working directory
+func absDir(dir string) string {
+	if dir == "" {
+		return ""
+	}
+	if dir == "~" || strings.HasPrefix(dir, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			dir = filepath.Join(home, dir[1:])
+		}
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		return abs
+	}
+	return dir
+}
@@ -0,0 +1,111 @@
+package session
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	claudecode "github.com/humanlayer/humanlayer/claudecode-go"
+	hldconfig "github.com/humanlayer/humanlayer/hld/config"
+)
+
+func TestResolveContainerImage(t *testing.T) {
+	m := &Manager{containers: hldconfig.ContainerConfig{
+		Image:          "agent:latest",
+		TemplateImages: map[string]string{"python": "agent-python:3.12"},
+	}}
+
+	tests := []struct {
+		name   string
+		config LaunchSessionConfig
+		want   string
+	}{
+		{"not requested", LaunchSessionConfig{Template: "python"}, ""},
+		{"template image", LaunchSessionConfig{Template: "python", Container: true}, "agent-python:3.12"},
+		{"default image", LaunchSessionConfig{Template: "go", Container: true}, "agent:latest"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.resolveContainerImage(tt.config)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	m.containers = hldconfig.ContainerConfig{}
+	if _, err := m.resolveContainerImage(LaunchSessionConfig{Container: true}); !errors.Is(err, ErrNoContainerImage) {
+		t.Errorf("expected ErrNoContainerImage, got %v", err)
+	}
+}
+
+func TestWrapInContainer(t *testing.T) {
+	m := &Manager{
+		socketPath: "/home/me/.humanlayer/daemon.sock",
+		containers: hldconfig.ContainerConfig{
+			Runtime: "podman",
+			Network: hldconfig.ContainerNetworkNone,
+			Mounts:  []string{"/opt/cache:/cache:ro"},
+		},
+	}
+	config := claudecode.SessionConfig{
+		WorkingDir:            "/work/repo",
+		AdditionalDirectories: []string{"/work/shared"},
+		Env:                   map[string]string{"ANTHROPIC_API_KEY": "secret", "A_FLAG": "1"},
+	}
+
+	m.wrapInContainer("sess-1", "agent:latest", &config)
+	if config.Wrapper == nil {
+		t.Fatal("expected a wrapper")
+	}
+	if config.Wrapper.Command != "podman" {
+		t.Errorf("expected podman, got %s", config.Wrapper.Command)
+	}
+
+	got := strings.Join(config.Wrapper.Args, " ")
+	want := "run --rm -i --name hld-sess-1 --network none" +
+		" -v /work/repo:/work/repo -v /work/shared:/work/shared" +
+		" -v /home/me/.humanlayer/daemon.sock:/home/me/.humanlayer/daemon.sock" +
+		" -v /opt/cache:/cache:ro -w /work/repo" +
+		" -e ANTHROPIC_API_KEY -e A_FLAG agent:latest"
+	if got != want {
+		t.Errorf("unexpected args:\n got: %s\nwant: %s", got, want)
+	}
+	if strings.Contains(got, "secret") {
+		t.Error("environment values must not appear in the runtime arguments")
+	}
+
+	host := claudecode.SessionConfig{WorkingDir: "/work/repo"}
+	m.wrapInContainer("sess-2", "", &host)
+	if host.Wrapper != nil {
+		t.Error("expected no wrapper without an image")
+	}
+}
+
+func TestFindDevcontainer(t *testing.T) {
+	dir := t.TempDir()
+	config := LaunchSessionConfig{Devcontainer: true}
+
+	if _, err := findDevcontainer(config, dir, false); !errors.Is(err, ErrNoDevcontainer) {
+		t.Errorf("expected ErrNoDevcontainer, got %v", err)
+	}
+
+	path := filepath.Join(dir, ".devcontainer.json")
+	if err := os.WriteFile(path, []byte(`{"image": "node:22"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := findDevcontainer(config, dir, false); err != nil || got != path {
+		t.Errorf("expected %s, got %q (%v)", path, got, err)
+	}
+	if _, err := findDevcontainer(config, dir, true); !errors.Is(err, ErrDevcontainerDraft) {
+		t.Errorf("expected ErrDevcontainerDraft, got %v", err)
+	}
+	if got, _ := findDevcontainer(LaunchSessionConfig{}, dir, false); got != "" {
+		t.Errorf("expected no devcontainer when not requested, got %s", got)
+	}
+}
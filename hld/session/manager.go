@@ -3,6 +3,7 @@ package session
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -13,11 +14,23 @@ import (
 
 	"github.com/google/uuid"
 	claudecode "github.com/humanlayer/humanlayer/claudecode-go"
+	"github.com/humanlayer/humanlayer/hld/approval"
 	"github.com/humanlayer/humanlayer/hld/bus"
 	hldconfig "github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/contextpack"
+	"github.com/humanlayer/humanlayer/hld/decisions"
 	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/humanlayer/humanlayer/hld/tracker"
+	"github.com/humanlayer/humanlayer/hld/workspace"
 )
 
+// AggregatorMCPServerName is the MCP server name sessions use to reach downstream tools
+// re-exposed by the daemon
+const AggregatorMCPServerName = "humanlayer"
+
+// ticketLookupTimeout bounds how long a launch waits on issue trackers
+const ticketLookupTimeout = 15 * time.Second
+
 // Manager handles the lifecycle of Claude Code sessions
 type Manager struct {
 	activeProcesses    map[string]ClaudeSession // Maps session ID to active Claude process
@@ -32,6 +45,34 @@ type Manager struct {
 	pendingQueries     sync.Map // map[sessionID]query - stores queries waiting for Claude session ID
 	socketPath         string   // Daemon socket path for MCP servers
 	httpPort           int      // HTTP server port for proxy endpoint
+	mcpAggregator      bool     // Whether downstream MCP tools are exposed via the daemon's MCP endpoint
+	defaultBudget      approval.Budget
+	pathMappings       []hldconfig.PathMapping // Rewrites working dirs recorded on other machines
+	containers         hldconfig.ContainerConfig
+	injectDecisions    bool                   // Give every new session its repository's decision log
+	ciMode             bool                   // Strip settings that let tool calls skip the approval policy
+	trackers           *tracker.Set           // Issue trackers whose referenced tickets are added to context; nil if none
+	decisions          store.DecisionStore    // Decision log given to sessions; nil if none is kept
+	contextPacks       store.ContextPackStore // Packs sessions can be launched with; nil if none are kept
+	tickets            store.TicketStore      // Where tickets referenced at launch are linked; nil if none is kept
+	processes          store.ProcessStore     // Where supervised processes are recorded; nil if they aren't
+
+	// Resource samples of running processes; see supervisor.go
+	statsMu sync.Mutex
+	stats   map[string]*ProcessStats
+
+	// Launch queue; see queue.go
+	maxConcurrent int
+	queueMu       sync.Mutex
+	queue         []*queuedLaunch
+	queueSeq      uint64
+	slots         map[string]struct{} // sessions holding a concurrency slot
+}
+
+// resolveWorkingDir applies the configured path mappings to a stored working
+// directory, for sessions recorded on another machine
+func (m *Manager) resolveWorkingDir(dir string) string {
+	return hldconfig.RemapPath(m.pathMappings, dir)
 }
 
 // Compile-time check that Manager implements SessionManager
@@ -50,6 +91,7 @@ func NewManager(eventBus bus.EventBus, store store.ConversationStore, socketPath
 		eventBus:        eventBus,
 		store:           store,
 		socketPath:      socketPath,
+		slots:           make(map[string]struct{}),
 	}
 
 	// Try to initialize Claude client but don't fail if unavailable
@@ -76,6 +118,19 @@ func NewManagerWithConfig(eventBus bus.EventBus, store store.ConversationStore,
 		store:           store,
 		socketPath:      socketPath,
 		claudePath:      cfg.ClaudePath, // Use configured Claude path
+		mcpAggregator:   len(cfg.MCPDownstreamServers) > 0,
+		defaultBudget: approval.Budget{
+			MaxToolCalls:       cfg.DefaultSessionBudget.MaxToolCalls,
+			MaxCostUSD:         cfg.DefaultSessionBudget.MaxCostUSD,
+			MaxDurationSeconds: cfg.DefaultSessionBudget.MaxDurationSeconds,
+		},
+		pathMappings:    cfg.PathMappings,
+		containers:      cfg.Containers,
+		injectDecisions: cfg.DecisionLog.InjectContext,
+		ciMode:          cfg.CI.Enabled,
+		trackers:        tracker.New(cfg.Trackers),
+		maxConcurrent:   cfg.MaxConcurrentSessions,
+		slots:           make(map[string]struct{}),
 	}
 
 	// Try to initialize Claude client but don't fail if unavailable
@@ -96,6 +151,105 @@ func (m *Manager) SetApprovalReconciler(reconciler ApprovalReconciler) {
 	m.approvalReconciler = reconciler
 }
 
+// SetContextStores gives the manager the decision log it adds to new
+// sessions, the context packs they can be launched with and the store that
+// links the tickets they reference
+func (m *Manager) SetContextStores(decisions store.DecisionStore, contextPacks store.ContextPackStore, tickets store.TicketStore) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.decisions = decisions
+	m.contextPacks = contextPacks
+	m.tickets = tickets
+}
+
+// appendDecisionLog adds the decisions recorded for the session's repository
+// to its system prompt. A failure is logged rather than failing the launch.
+func (m *Manager) appendDecisionLog(ctx context.Context, config *claudecode.SessionConfig) {
+	m.mu.RLock()
+	decisionStore := m.decisions
+	m.mu.RUnlock()
+	if decisionStore == nil {
+		return
+	}
+	text, err := decisions.Context(ctx, decisionStore, config.WorkingDir)
+	if err != nil {
+		slog.Warn("failed to load decision log", "working_dir", config.WorkingDir, "error", err)
+		return
+	}
+	if text == "" {
+		return
+	}
+	if config.AppendSystemPrompt != "" {
+		text = config.AppendSystemPrompt + "\n\n" + text
+	}
+	config.AppendSystemPrompt = text
+}
+
+// appendTickets adds the tickets referenced in the query to the system
+// prompt and returns them so they can be linked to the session. Trackers
+// that can't be reached are logged rather than failing the launch.
+func (m *Manager) appendTickets(ctx context.Context, config *claudecode.SessionConfig) []tracker.Linked {
+	if m.trackers == nil || config.Query == "" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, ticketLookupTimeout)
+	defer cancel()
+	linked := m.trackers.Resolve(ctx, config.Query)
+	if text := tracker.Render(linked); text != "" {
+		if config.AppendSystemPrompt != "" {
+			text = config.AppendSystemPrompt + "\n\n" + text
+		}
+		config.AppendSystemPrompt = text
+	}
+	return linked
+}
+
+// applyCIMode removes the settings that would let a tool call run without
+// meeting the approval policy: pre-allowed tools bypass the permission
+// prompt, and the auto-accept modes would approve calls the policy doesn't
+func applyCIMode(config *LaunchSessionConfig) {
+	if len(config.AllowedTools) > 0 || config.AutoAcceptEdits || config.DangerouslySkipPermissions {
+		slog.Info("CI mode: ignoring allowed tools and auto-accept settings",
+			"allowed_tools", config.AllowedTools,
+			"auto_accept_edits", config.AutoAcceptEdits,
+			"dangerously_skip_permissions", config.DangerouslySkipPermissions)
+	}
+	config.AllowedTools = nil
+	config.AutoAcceptEdits = false
+	config.DangerouslySkipPermissions = false
+	config.DangerouslySkipPermissionsTimeout = nil
+}
+
+// injectAggregatorMCPServer points the session at the daemon's HTTP MCP endpoint when
+// downstream MCP servers are configured. The daemon gates those tools itself, so they
+// are pre-allowed to avoid a second prompt through the permission tool.
+func (m *Manager) injectAggregatorMCPServer(config *claudecode.SessionConfig) {
+	if !m.mcpAggregator || config.MCPConfig == nil {
+		return
+	}
+
+	m.mu.RLock()
+	httpPort := m.httpPort
+	m.mu.RUnlock()
+	if httpPort == 0 {
+		slog.Warn("HTTP port not set, skipping MCP aggregator injection")
+		return
+	}
+
+	config.MCPConfig.MCPServers[AggregatorMCPServerName] = claudecode.MCPServer{
+		Type: "http",
+		URL:  fmt.Sprintf("http://localhost:%d/api/v1/mcp", httpPort),
+	}
+
+	allowed := "mcp__" + AggregatorMCPServerName
+	for _, tool := range config.AllowedTools {
+		if tool == allowed {
+			return
+		}
+	}
+	config.AllowedTools = append(config.AllowedTools, allowed)
+}
+
 // SetHTTPPort sets the HTTP port for the proxy endpoint
 func (m *Manager) SetHTTPPort(port int) {
 	m.mu.Lock()
@@ -189,6 +343,10 @@ func (m *Manager) LaunchSession(ctx context.Context, config LaunchSessionConfig,
 	sessionID := uuid.New().String()
 	runID := uuid.New().String()
 
+	if m.ciMode {
+		applyCIMode(&config)
+	}
+
 	// Extract the Claude config (without daemon-level settings)
 	claudeConfig := config.SessionConfig
 
@@ -211,6 +369,7 @@ func (m *Manager) LaunchSession(ctx context.Context, config LaunchSessionConfig,
 	slog.Debug("injected codelayer MCP server",
 		"session_id", sessionID,
 		"socket_path", m.socketPath)
+	m.injectAggregatorMCPServer(&claudeConfig)
 
 	// Add HUMANLAYER_RUN_ID and HUMANLAYER_DAEMON_SOCKET to MCP server environment
 	// For HTTP servers, inject session ID header
@@ -324,6 +483,27 @@ func (m *Manager) LaunchSession(ctx context.Context, config LaunchSessionConfig,
 		}
 	}
 
+	if config.IncludeDecisions || m.injectDecisions {
+		m.appendDecisionLog(ctx, &claudeConfig)
+	}
+	if config.ContextPackID != "" {
+		m.mu.RLock()
+		packs := m.contextPacks
+		m.mu.RUnlock()
+		if packs == nil {
+			return nil, fmt.Errorf("context pack %s: context packs are not kept", config.ContextPackID)
+		}
+		pack, err := contextpack.Load(ctx, packs, config.ContextPackID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load context pack %s: %w", config.ContextPackID, err)
+		}
+		if claudeConfig.AppendSystemPrompt != "" {
+			claudeConfig.AppendSystemPrompt += "\n\n"
+		}
+		claudeConfig.AppendSystemPrompt += pack.Render()
+	}
+	tickets := m.appendTickets(ctx, &claudeConfig)
+
 	// Create session record directly in database
 	startTime := time.Now()
 
@@ -356,6 +536,41 @@ func (m *Manager) LaunchSession(ctx context.Context, config LaunchSessionConfig,
 		}
 	}
 
+	// Handle auto-deny configuration from config
+	dbSession.AutoDenyAll = config.AutoDenyAll
+	if len(config.AutoDenyTools) > 0 {
+		autoDenyJSON, err := json.Marshal(config.AutoDenyTools)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal auto-deny tools: %w", err)
+		}
+		dbSession.AutoDenyTools = string(autoDenyJSON)
+	}
+
+	// Handle resource limits, falling back to the daemon default
+	budget := m.defaultBudget
+	if config.Budget != nil {
+		budget = *config.Budget
+	}
+	budgetJSON, err := approval.EncodeBudget(budget)
+	if err != nil {
+		return nil, err
+	}
+	dbSession.Budget = budgetJSON
+	dbSession.Template = config.Template
+	dbSession.RetryOf = config.RetryOf
+	dbSession.ContextPackID = config.ContextPackID
+	devcontainerPath, err := findDevcontainer(config, claudeConfig.WorkingDir, isDraft)
+	if err != nil {
+		return nil, err
+	}
+	var containerImage string
+	if devcontainerPath == "" {
+		if containerImage, err = m.resolveContainerImage(config); err != nil {
+			return nil, err
+		}
+	}
+	dbSession.ContainerImage = containerImage
+
 	// Handle proxy configuration from config
 	if config.ProxyEnabled {
 		dbSession.ProxyEnabled = config.ProxyEnabled
@@ -364,10 +579,26 @@ func (m *Manager) LaunchSession(ctx context.Context, config LaunchSessionConfig,
 		dbSession.ProxyAPIKey = config.ProxyAPIKey
 	}
 
+	// Wait in the launch queue when max_concurrent_sessions are already running
+	queued := !isDraft && !m.acquireSlot(sessionID, false)
+	if queued {
+		dbSession.Status = store.SessionStatusQueued
+	}
+
 	if err := m.store.CreateSession(ctx, dbSession); err != nil {
+		m.releaseSlot(sessionID)
 		return nil, fmt.Errorf("failed to store session in database: %w", err)
 	}
 
+	m.mu.RLock()
+	ticketStore := m.tickets
+	m.mu.RUnlock()
+	if ticketStore != nil {
+		if err := tracker.Link(ctx, ticketStore, sessionID, tickets); err != nil {
+			slog.Warn("failed to link tickets to session", "session_id", sessionID, "error", err)
+		}
+	}
+
 	// Store MCP servers if configured
 	if claudeConfig.MCPConfig != nil && len(claudeConfig.MCPConfig.MCPServers) > 0 {
 		servers, err := store.MCPServersFromConfig(sessionID, claudeConfig.MCPConfig.MCPServers)
@@ -450,6 +681,40 @@ func (m *Manager) LaunchSession(ctx context.Context, config LaunchSessionConfig,
 		"mcp_servers", mcpServerCount,
 		"mcp_servers_detail", mcpServersDetail)
 
+	if queued {
+		config.SessionConfig = claudeConfig
+		m.enqueue(&queuedLaunch{
+			ctx:              context.WithoutCancel(ctx),
+			sessionID:        sessionID,
+			runID:            runID,
+			config:           config,
+			containerImage:   containerImage,
+			devcontainerPath: devcontainerPath,
+			queuedAt:         startTime,
+		})
+		return &Session{
+			ID:        sessionID,
+			RunID:     runID,
+			Status:    StatusQueued,
+			StartTime: startTime,
+			Config:    claudeConfig,
+		}, nil
+	}
+
+	if devcontainerPath != "" {
+		config.SessionConfig = claudeConfig
+		go m.launchInDevcontainer(context.WithoutCancel(ctx), sessionID, runID, config, devcontainerPath)
+		return &Session{
+			ID:        sessionID,
+			RunID:     runID,
+			Status:    StatusStarting,
+			StartTime: startTime,
+			Config:    claudeConfig,
+		}, nil
+	}
+
+	m.wrapInContainer(sessionID, containerImage, &claudeConfig)
+
 	// Launch Claude session (without daemon-level settings)
 	claudeSession, err := client.Launch(claudeConfig)
 	if err != nil {
@@ -458,6 +723,7 @@ func (m *Manager) LaunchSession(ctx context.Context, config LaunchSessionConfig,
 			"error", err,
 			"config", fmt.Sprintf("%+v", claudeConfig))
 		m.updateSessionStatus(ctx, sessionID, StatusFailed, err.Error())
+		m.releaseSlot(sessionID)
 		return nil, fmt.Errorf("failed to launch Claude session: %w", err)
 	}
 
@@ -546,6 +812,10 @@ func (m *Manager) LaunchSession(ctx context.Context, config LaunchSessionConfig,
 
 // monitorSession tracks the lifecycle of a Claude session
 func (m *Manager) monitorSession(ctx context.Context, sessionID, runID string, claudeSession ClaudeSession, startTime time.Time, config claudecode.SessionConfig) {
+	// Record the process and sample its resource use while it runs
+	stopSupervising := m.supervise(ctx, sessionID, claudeSession)
+	defer stopSupervising()
+
 	// Get the session ID from the Claude session once available
 	var claudeSessionID string
 
@@ -634,6 +904,10 @@ eventLoop:
 
 	// Wait for session to complete
 	result, err := claudeSession.Wait()
+	stopSupervising()
+	if config.Wrapper != nil {
+		m.removeContainer(sessionID)
+	}
 
 	// Check if context was cancelled before updating database
 	if ctx.Err() != nil {
@@ -678,6 +952,7 @@ eventLoop:
 			"error", err.Error(),
 			"duration", endTime.Sub(startTime))
 		m.updateSessionStatus(ctx, sessionID, StatusFailed, err.Error())
+		m.publishFailed(sessionID, runID)
 	} else if result != nil && result.IsError {
 		// Construct a meaningful error message from available info
 		errorMsg := result.Error
@@ -708,6 +983,7 @@ eventLoop:
 			"num_turns", result.NumTurns,
 			"duration", endTime.Sub(startTime))
 		m.updateSessionStatus(ctx, sessionID, StatusFailed, errorMsg)
+		m.publishFailed(sessionID, runID)
 	} else {
 		// No longer updating in-memory session
 
@@ -778,6 +1054,8 @@ eventLoop:
 
 	// Clean up any pending queries that weren't injected
 	m.pendingQueries.Delete(sessionID)
+
+	m.releaseSlot(sessionID)
 }
 
 // updateSessionStatus updates the status of a session in the database
@@ -810,6 +1088,23 @@ func (m *Manager) updateSessionStatus(ctx context.Context, sessionID string, sta
 	// This would require a database read. For now, we'll skip the event.
 }
 
+// publishFailed announces that a running session's process failed, so
+// subscribers such as notifications and summaries see the terminal state
+func (m *Manager) publishFailed(sessionID, runID string) {
+	if m.eventBus == nil {
+		return
+	}
+	m.eventBus.Publish(bus.Event{
+		Type: bus.EventSessionStatusChanged,
+		Data: map[string]interface{}{
+			"session_id": sessionID,
+			"run_id":     runID,
+			"old_status": string(StatusRunning),
+			"new_status": string(StatusFailed),
+		},
+	})
+}
+
 // GetSessionInfo returns session info from the database by ID
 func (m *Manager) GetSessionInfo(sessionID string) (*Info, error) {
 	ctx := context.Background()
@@ -1384,46 +1679,39 @@ func (m *Manager) captureFileSnapshot(ctx context.Context, sessionID, toolID, to
 			return
 		}
 
+		// The file may live on a remote host for sessions with an SSH destination
+		host := workspace.ForSession(session)
+		workingDir := session.WorkingDir
+		if session.SSHHost == "" {
+			workingDir = m.resolveWorkingDir(session.WorkingDir)
+		}
+
 		// Construct full path for reading
-		var fullPath string
-		if filepath.IsAbs(filePath) {
-			// Path is already absolute
-			fullPath = filePath
-		} else {
+		fullPath := filePath
+		if !filepath.IsAbs(filePath) {
 			// Path is relative, join with working directory
-			fullPath = filepath.Join(session.WorkingDir, filePath)
-
-			// Verify the constructed path exists
-			if _, err := os.Stat(fullPath); err != nil {
-				slog.Error("constructed file path does not exist",
-					"working_dir", session.WorkingDir,
-					"file_path", filePath,
-					"full_path", fullPath,
-					"error", err)
-				return
-			}
+			fullPath = filepath.Join(workingDir, filePath)
 		}
 
 		// Read file with size limit (10MB)
 		const maxFileSize = 10 * 1024 * 1024
-		fileInfo, err := os.Stat(fullPath)
-		if err != nil {
-			slog.Error("failed to stat file for snapshot", "path", fullPath, "error", err)
-			return
-		}
-
-		if fileInfo.Size() > maxFileSize {
-			slog.Warn("file too large for snapshot, using partial content", "path", fullPath, "size", fileInfo.Size())
+		fileBytes, err := host.ReadFile(ctx, fullPath, maxFileSize)
+		switch {
+		case errors.Is(err, workspace.ErrTooLarge):
+			slog.Warn("file too large for snapshot, using partial content", "path", fullPath)
 			// Store partial content from tool result as fallback
 			content = parseReadToolContent(toolResultContent)
-		} else {
-			fileBytes, err := os.ReadFile(fullPath)
-			if err != nil {
-				slog.Error("failed to read file for snapshot", "path", fullPath, "error", err)
-				return
-			}
+		case err != nil:
+			slog.Error("failed to read file for snapshot",
+				"working_dir", workingDir,
+				"file_path", filePath,
+				"full_path", fullPath,
+				"ssh_host", session.SSHHost,
+				"error", err)
+			return
+		default:
 			content = string(fileBytes)
-			slog.Debug("read full file content from filesystem", "path", fullPath)
+			slog.Debug("read full file content", "path", fullPath, "ssh_host", session.SSHHost)
 		}
 	}
 
@@ -1524,7 +1812,7 @@ func (m *Manager) ContinueSession(ctx context.Context, req ContinueSessionConfig
 		ForkSession:          true,                          // Enable fork instead of resume
 		OutputFormat:         claudecode.OutputStreamJSON,   // Always use streaming JSON
 		Model:                claudecode.Model(parentSession.Model),
-		WorkingDir:           parentSession.WorkingDir,
+		WorkingDir:           m.resolveWorkingDir(parentSession.WorkingDir),
 		SystemPrompt:         parentSession.SystemPrompt,
 		AppendSystemPrompt:   parentSession.AppendSystemPrompt,
 		CustomInstructions:   parentSession.CustomInstructions,
@@ -1619,6 +1907,9 @@ func (m *Manager) ContinueSession(ctx context.Context, req ContinueSessionConfig
 	if len(req.AllowedTools) > 0 {
 		config.AllowedTools = req.AllowedTools
 	}
+	if m.ciMode {
+		config.AllowedTools = nil
+	}
 	if len(req.DisallowedTools) > 0 {
 		config.DisallowedTools = req.DisallowedTools
 	}
@@ -1652,6 +1943,16 @@ func (m *Manager) ContinueSession(ctx context.Context, req ContinueSessionConfig
 		dbSession.DangerouslySkipPermissionsExpiresAt = nil
 	}
 
+	// Inherit auto-deny configuration from parent
+	dbSession.AutoDenyAll = parentSession.AutoDenyAll
+	dbSession.AutoDenyTools = parentSession.AutoDenyTools
+
+	// Inherit resource limits from parent; usage is measured across the whole chain
+	dbSession.Budget = parentSession.Budget
+	dbSession.Template = parentSession.Template
+	dbSession.ContainerImage = parentSession.ContainerImage
+	dbSession.RetryOf = req.RetryOf
+
 	// Inherit title from parent session
 	dbSession.Title = parentSession.Title
 	// Explicitly ensure inherited values are stored (in case NewSessionFromConfig didn't capture them)
@@ -1720,6 +2021,7 @@ func (m *Manager) ContinueSession(ctx context.Context, req ContinueSessionConfig
 		"session_id", sessionID,
 		"parent_session_id", req.ParentSessionID,
 		"socket_path", m.socketPath)
+	m.injectAggregatorMCPServer(&config)
 
 	if config.MCPConfig != nil {
 		for name, server := range config.MCPConfig.MCPServers {
@@ -1809,8 +2111,13 @@ func (m *Manager) ContinueSession(ctx context.Context, req ContinueSessionConfig
 		"proxy_base_url", dbSession.ProxyBaseURL,
 		"proxy_model", dbSession.ProxyModelOverride)
 
+	m.wrapInContainer(sessionID, dbSession.ContainerImage, &config)
+
+	// Continuing a conversation counts toward the limit but never waits
+	m.acquireSlot(sessionID, true)
 	claudeSession, err := client.Launch(config)
 	if err != nil {
+		m.releaseSlot(sessionID)
 		slog.Error("failed to resume Claude session from failed parent",
 			"session_id", sessionID,
 			"parent_session_id", req.ParentSessionID,
@@ -1947,8 +2254,9 @@ func (m *Manager) InterruptSession(ctx context.Context, sessionID string) error
 	return nil
 }
 
-// launchDraftWithConfig launches a draft session using the existing launch flow
-func (m *Manager) launchDraftWithConfig(ctx context.Context, sessionID, runID string, config LaunchSessionConfig) error {
+// launchDraftWithConfig launches a draft session using the existing launch flow.
+// Sessions waiting on devcontainer setup are launched through it too.
+func (m *Manager) launchDraftWithConfig(ctx context.Context, sessionID, runID string, config LaunchSessionConfig, containerImage string) error {
 	// Get Claude client (will attempt initialization if needed)
 	client, err := m.getClaudeClient()
 	if err != nil {
@@ -1973,6 +2281,7 @@ func (m *Manager) launchDraftWithConfig(ctx context.Context, sessionID, runID st
 			"HUMANLAYER_DAEMON_SOCKET": m.socketPath,
 		},
 	}
+	m.injectAggregatorMCPServer(&claudeConfig)
 
 	// Add HUMANLAYER_RUN_ID and HUMANLAYER_DAEMON_SOCKET to MCP server environment
 	// For HTTP servers, inject session ID header
@@ -2030,6 +2339,7 @@ func (m *Manager) launchDraftWithConfig(ctx context.Context, sessionID, runID st
 		"query", claudeConfig.Query,
 		"working_dir", claudeConfig.WorkingDir)
 
+	m.wrapInContainer(sessionID, containerImage, &claudeConfig)
 	claudeSession, err := client.Launch(claudeConfig)
 	if err != nil {
 		slog.Error("failed to launch Claude session from draft",
@@ -2124,7 +2434,7 @@ func (m *Manager) LaunchDraftSession(ctx context.Context, sessionID string, prom
 	// This keeps the session in draft state if validation fails
 	if sess.WorkingDir != "" {
 		// Expand ~ if present
-		workingDir := sess.WorkingDir
+		workingDir := m.resolveWorkingDir(sess.WorkingDir)
 		if strings.HasPrefix(workingDir, "~") {
 			home, err := os.UserHomeDir()
 			if err != nil {
@@ -2194,7 +2504,7 @@ func (m *Manager) LaunchDraftSession(ctx context.Context, sessionID string, prom
 	claudeConfig := claudecode.SessionConfig{
 		Query:                prompt, // Use the provided prompt
 		OutputFormat:         claudecode.OutputStreamJSON,
-		WorkingDir:           sess.WorkingDir,
+		WorkingDir:           m.resolveWorkingDir(sess.WorkingDir),
 		SystemPrompt:         sess.SystemPrompt,
 		AppendSystemPrompt:   sess.AppendSystemPrompt,
 		CustomInstructions:   sess.CustomInstructions,
@@ -2293,7 +2603,13 @@ func (m *Manager) LaunchDraftSession(ctx context.Context, sessionID string, prom
 
 	// Actually launch the session using the existing flow
 	// We need to launch it properly with Claude, not just update the database
-	return m.launchDraftWithConfig(ctx, sessionID, sess.RunID, launchConfig)
+	// Launching a draft counts toward the limit but never waits
+	m.acquireSlot(sessionID, true)
+	if err := m.launchDraftWithConfig(ctx, sessionID, sess.RunID, launchConfig, sess.ContainerImage); err != nil {
+		m.releaseSlot(sessionID)
+		return err
+	}
+	return nil
 }
 
 // injectQueryAsFirstEvent adds the user's query as the first conversation event
@@ -2488,17 +2804,27 @@ func (m *Manager) UpdateSessionSettings(ctx context.Context, sessionID string, u
 		return err
 	}
 
-	// If auto-accept edits was updated, publish the settings changed event
+	// If auto-accept, auto-deny or budget settings were updated, publish the settings changed event
+	data := map[string]interface{}{
+		"session_id": sessionID,
+	}
 	if updates.AutoAcceptEdits != nil {
-		if m.eventBus != nil {
-			m.eventBus.Publish(bus.Event{
-				Type: bus.EventSessionSettingsChanged,
-				Data: map[string]interface{}{
-					"session_id":        sessionID,
-					"auto_accept_edits": *updates.AutoAcceptEdits,
-				},
-			})
-		}
+		data["auto_accept_edits"] = *updates.AutoAcceptEdits
+	}
+	if updates.AutoDenyAll != nil {
+		data["auto_deny_all"] = *updates.AutoDenyAll
+	}
+	if updates.AutoDenyTools != nil {
+		data["auto_deny_tools"] = approval.ParseAutoDenyTools(*updates.AutoDenyTools)
+	}
+	if updates.Budget != nil {
+		data["budget"] = approval.ParseBudget(*updates.Budget)
+	}
+	if len(data) > 1 && m.eventBus != nil {
+		m.eventBus.Publish(bus.Event{
+			Type: bus.EventSessionSettingsChanged,
+			Data: data,
+		})
 	}
 
 	return nil
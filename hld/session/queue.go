@@ -0,0 +1,213 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+)
+
+// ErrNotQueued is returned when cancelling a session that isn't waiting in
+// the launch queue
+var ErrNotQueued = errors.New("session is not queued")
+
+// queuedLaunch is a stored session waiting for a free slot
+type queuedLaunch struct {
+	ctx              context.Context
+	sessionID        string
+	runID            string
+	config           LaunchSessionConfig
+	containerImage   string
+	devcontainerPath string
+	queuedAt         time.Time
+	seq              uint64
+}
+
+// QueuedSession is a session's place in the launch queue
+type QueuedSession struct {
+	SessionID string    `json:"session_id"`
+	Priority  int       `json:"priority"`
+	Position  int       `json:"position"` // 1 is next to start
+	QueuedAt  time.Time `json:"queued_at"`
+}
+
+// QueueStatus reports the concurrency limit and the sessions waiting for it
+type QueueStatus struct {
+	MaxConcurrent int             `json:"max_concurrent"` // 0 means unlimited
+	Active        int             `json:"active"`
+	Queued        []QueuedSession `json:"queued"`
+}
+
+// acquireSlot claims one of the max_concurrent_sessions slots for a session,
+// reporting false when all are taken. force claims a slot regardless, for
+// launches that can't wait such as continuing a conversation.
+func (m *Manager) acquireSlot(sessionID string, force bool) bool {
+	if m.maxConcurrent <= 0 {
+		return true
+	}
+	m.queueMu.Lock()
+	defer m.queueMu.Unlock()
+	if !force && (len(m.slots) >= m.maxConcurrent || len(m.queue) > 0) {
+		return false
+	}
+	m.slots[sessionID] = struct{}{}
+	return true
+}
+
+// releaseSlot frees a session's slot, if it held one, and starts the next
+// queued sessions that now fit
+func (m *Manager) releaseSlot(sessionID string) {
+	if m.maxConcurrent <= 0 {
+		return
+	}
+	m.queueMu.Lock()
+	if _, held := m.slots[sessionID]; !held {
+		m.queueMu.Unlock()
+		return
+	}
+	delete(m.slots, sessionID)
+	next := m.dequeueLocked()
+	m.queueMu.Unlock()
+
+	for _, q := range next {
+		go m.startQueued(q)
+	}
+}
+
+// dequeueLocked hands free slots to the front of the queue. queueMu must be
+// held.
+func (m *Manager) dequeueLocked() []*queuedLaunch {
+	var next []*queuedLaunch
+	for len(m.queue) > 0 && len(m.slots) < m.maxConcurrent {
+		q := m.queue[0]
+		m.queue = m.queue[1:]
+		m.slots[q.sessionID] = struct{}{}
+		next = append(next, q)
+	}
+	return next
+}
+
+// enqueue adds a launch to the queue, ordered by priority and then arrival,
+// and returns its position
+func (m *Manager) enqueue(q *queuedLaunch) int {
+	m.queueMu.Lock()
+	m.queueSeq++
+	q.seq = m.queueSeq
+	m.queue = append(m.queue, q)
+	sort.SliceStable(m.queue, func(i, j int) bool {
+		if m.queue[i].config.Priority != m.queue[j].config.Priority {
+			return m.queue[i].config.Priority > m.queue[j].config.Priority
+		}
+		return m.queue[i].seq < m.queue[j].seq
+	})
+	position := 0
+	for i, queued := range m.queue {
+		if queued == q {
+			position = i + 1
+		}
+	}
+	// A slot may have freed up since acquireSlot failed
+	next := m.dequeueLocked()
+	m.queueMu.Unlock()
+
+	slog.Info("queued session launch", "session_id", q.sessionID, "priority", q.config.Priority, "position", position)
+	if m.eventBus != nil {
+		m.eventBus.Publish(bus.Event{
+			Type: bus.EventSessionQueued,
+			Data: map[string]interface{}{
+				"session_id": q.sessionID,
+				"run_id":     q.runID,
+				"priority":   q.config.Priority,
+				"position":   position,
+			},
+		})
+	}
+	for _, started := range next {
+		go m.startQueued(started)
+	}
+	return position
+}
+
+// startQueued launches a session that has been given a slot
+func (m *Manager) startQueued(q *queuedLaunch) {
+	waited := time.Since(q.queuedAt)
+	m.updateSessionStatus(q.ctx, q.sessionID, StatusStarting, "")
+	if m.eventBus != nil {
+		m.eventBus.Publish(bus.Event{
+			Type: bus.EventSessionStatusChanged,
+			Data: map[string]interface{}{
+				"session_id": q.sessionID,
+				"run_id":     q.runID,
+				"old_status": string(StatusQueued),
+				"new_status": string(StatusStarting),
+				"queued_ms":  waited.Milliseconds(),
+			},
+		})
+	}
+	slog.Info("starting queued session", "session_id", q.sessionID, "waited", waited)
+
+	if q.devcontainerPath != "" {
+		m.launchInDevcontainer(q.ctx, q.sessionID, q.runID, q.config, q.devcontainerPath)
+		return
+	}
+	if err := m.launchDraftWithConfig(q.ctx, q.sessionID, q.runID, q.config, q.containerImage); err != nil {
+		slog.Error("failed to launch queued session", "session_id", q.sessionID, "error", err)
+		m.releaseSlot(q.sessionID)
+	}
+}
+
+// CancelQueued removes a session from the launch queue and marks it failed
+func (m *Manager) CancelQueued(ctx context.Context, sessionID string) error {
+	m.queueMu.Lock()
+	var removed *queuedLaunch
+	for i, q := range m.queue {
+		if q.sessionID == sessionID {
+			m.queue = append(m.queue[:i], m.queue[i+1:]...)
+			removed = q
+			break
+		}
+	}
+	m.queueMu.Unlock()
+	if removed == nil {
+		return ErrNotQueued
+	}
+
+	m.updateSessionStatus(ctx, sessionID, StatusFailed, "removed from the launch queue")
+	if m.eventBus != nil {
+		m.eventBus.Publish(bus.Event{
+			Type: bus.EventSessionStatusChanged,
+			Data: map[string]interface{}{
+				"session_id": sessionID,
+				"run_id":     removed.runID,
+				"old_status": string(StatusQueued),
+				"new_status": string(StatusFailed),
+			},
+		})
+	}
+	return nil
+}
+
+// QueueStatus returns the queued sessions in the order they will start
+func (m *Manager) QueueStatus() QueueStatus {
+	m.queueMu.Lock()
+	defer m.queueMu.Unlock()
+
+	status := QueueStatus{MaxConcurrent: m.maxConcurrent, Active: len(m.slots), Queued: []QueuedSession{}}
+	if m.maxConcurrent <= 0 {
+		m.mu.RLock()
+		status.Active = len(m.activeProcesses)
+		m.mu.RUnlock()
+	}
+	for i, q := range m.queue {
+		status.Queued = append(status.Queued, QueuedSession{
+			SessionID: q.sessionID,
+			Priority:  q.config.Priority,
+			Position:  i + 1,
+			QueuedAt:  q.queuedAt,
+		})
+	}
+	return status
+}
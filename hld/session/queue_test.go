@@ -0,0 +1,113 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+func newQueueManager(maxConcurrent int) *Manager {
+	return &Manager{
+		activeProcesses: make(map[string]ClaudeSession),
+		store:           store.NewInMemoryStore(),
+		maxConcurrent:   maxConcurrent,
+		slots:           make(map[string]struct{}),
+	}
+}
+
+func queued(id string, priority int) *queuedLaunch {
+	return &queuedLaunch{ctx: context.Background(), sessionID: id, runID: "run-" + id, config: LaunchSessionConfig{Priority: priority}}
+}
+
+func TestAcquireSlot(t *testing.T) {
+	unlimited := newQueueManager(0)
+	for _, id := range []string{"a", "b", "c"} {
+		if !unlimited.acquireSlot(id, false) {
+			t.Fatalf("unlimited manager refused %s", id)
+		}
+	}
+
+	m := newQueueManager(1)
+	if !m.acquireSlot("a", false) {
+		t.Fatal("first session should get a slot")
+	}
+	if m.acquireSlot("b", false) {
+		t.Fatal("second session should wait when the limit is reached")
+	}
+	if !m.acquireSlot("c", true) {
+		t.Fatal("forced acquire should always succeed")
+	}
+	if got := m.QueueStatus().Active; got != 2 {
+		t.Errorf("active = %d, want 2", got)
+	}
+}
+
+func TestEnqueueOrdersByPriority(t *testing.T) {
+	m := newQueueManager(1)
+	m.acquireSlot("running", false)
+
+	if pos := m.enqueue(queued("low", 0)); pos != 1 {
+		t.Errorf("low position = %d, want 1", pos)
+	}
+	if pos := m.enqueue(queued("high", 5)); pos != 1 {
+		t.Errorf("high position = %d, want 1", pos)
+	}
+	if pos := m.enqueue(queued("low2", 0)); pos != 3 {
+		t.Errorf("low2 position = %d, want 3", pos)
+	}
+	// Anything queued makes new launches wait their turn, even with a free slot
+	if m.acquireSlot("late", false) {
+		t.Error("launch should not jump the queue")
+	}
+
+	status := m.QueueStatus()
+	var order []string
+	for _, q := range status.Queued {
+		order = append(order, q.SessionID)
+	}
+	if want := []string{"high", "low", "low2"}; !slices.Equal(order, want) {
+		t.Errorf("queue order = %v, want %v", order, want)
+	}
+
+	// Freeing the slot hands it to the front of the queue
+	m.queueMu.Lock()
+	delete(m.slots, "running")
+	next := m.dequeueLocked()
+	m.queueMu.Unlock()
+	if len(next) != 1 || next[0].sessionID != "high" {
+		t.Fatalf("dequeued %v, want high", next)
+	}
+	if _, ok := m.slots["high"]; !ok {
+		t.Error("dequeued session should hold a slot")
+	}
+}
+
+func TestCancelQueued(t *testing.T) {
+	ctx := context.Background()
+	m := newQueueManager(1)
+	m.acquireSlot("running", false)
+	if err := m.store.CreateSession(ctx, &store.Session{ID: "waiting", RunID: "run-waiting", Status: store.SessionStatusQueued}); err != nil {
+		t.Fatal(err)
+	}
+	m.enqueue(queued("waiting", 0))
+
+	if err := m.CancelQueued(ctx, "waiting"); err != nil {
+		t.Fatalf("CancelQueued: %v", err)
+	}
+	if len(m.QueueStatus().Queued) != 0 {
+		t.Error("session should have left the queue")
+	}
+	sess, err := m.store.GetSession(ctx, "waiting")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sess.Status != store.SessionStatusFailed {
+		t.Errorf("status = %s, want failed", sess.Status)
+	}
+	if err := m.CancelQueued(ctx, "waiting"); !errors.Is(err, ErrNotQueued) {
+		t.Errorf("second cancel = %v, want ErrNotQueued", err)
+	}
+}
@@ -0,0 +1,238 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	claudecode "github.com/humanlayer/humanlayer/claudecode-go"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// sampleInterval is how often a running Claude process's resources are sampled
+const sampleInterval = 5 * time.Second
+
+// stderrTailBytes bounds the stderr kept on a session's process record
+const stderrTailBytes = 4096
+
+// clockTicks is the kernel's USER_HZ, which /proc reports CPU time in. It
+// is 100 on every architecture Linux supports today.
+const clockTicks = 100
+
+// ProcessStats is the last resource sample of a running Claude process
+type ProcessStats struct {
+	PID          int
+	RSSBytes     int64
+	PeakRSSBytes int64
+	CPUSeconds   float64
+	// CPUPercent is the share of one core used since the previous sample
+	CPUPercent float64
+	SampledAt  time.Time
+}
+
+// supervisedProcess is a Claude session backed by a local process. Sessions
+// without one, such as test doubles, aren't supervised.
+type supervisedProcess interface {
+	PID() int
+	ExitStatus() *claudecode.ExitStatus
+}
+
+// SetProcessStore gives the manager somewhere to record the processes
+// sessions run in
+func (m *Manager) SetProcessStore(processes store.ProcessStore) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.processes = processes
+}
+
+// ProcessStats returns the latest sample of a running session's process
+func (m *Manager) ProcessStats(sessionID string) (ProcessStats, bool) {
+	m.statsMu.Lock()
+	defer m.statsMu.Unlock()
+	stats, ok := m.stats[sessionID]
+	if !ok {
+		return ProcessStats{}, false
+	}
+	return *stats, true
+}
+
+// supervise records the start of a session's process and samples its
+// resource use until stop is called, which records how it exited
+func (m *Manager) supervise(ctx context.Context, sessionID string, claudeSession ClaudeSession) (stop func()) {
+	proc, ok := claudeSession.(supervisedProcess)
+	if !ok || proc.PID() == 0 {
+		return func() {}
+	}
+	m.mu.RLock()
+	processes := m.processes
+	m.mu.RUnlock()
+
+	record := &store.SessionProcess{SessionID: sessionID, PID: proc.PID(), StartedAt: time.Now()}
+	if processes != nil {
+		if err := processes.RecordSessionProcess(ctx, record); err != nil {
+			slog.Warn("failed to record session process", "session_id", sessionID, "error", err)
+		}
+	}
+	m.statsMu.Lock()
+	if m.stats == nil {
+		m.stats = make(map[string]*ProcessStats)
+	}
+	m.stats[sessionID] = &ProcessStats{PID: record.PID, SampledAt: record.StartedAt}
+	m.statsMu.Unlock()
+
+	done := make(chan struct{})
+	sampled := make(chan struct{})
+	go func() {
+		defer close(sampled)
+		ticker := time.NewTicker(sampleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				m.sample(sessionID, record.PID)
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { m.finishSupervising(ctx, proc, record, processes, done, sampled) }) }
+}
+
+// finishSupervising stops sampling and records how the process exited. If
+// monitoring stopped before the process did, as on shutdown, only the
+// resource figures are kept.
+func (m *Manager) finishSupervising(ctx context.Context, proc supervisedProcess, record *store.SessionProcess, processes store.ProcessStore, done, sampled chan struct{}) {
+	close(done)
+	<-sampled
+	m.statsMu.Lock()
+	if stats, ok := m.stats[record.SessionID]; ok {
+		record.RSSBytes = stats.RSSBytes
+		record.PeakRSSBytes = stats.PeakRSSBytes
+		record.CPUSeconds = stats.CPUSeconds
+	}
+	delete(m.stats, record.SessionID)
+	m.statsMu.Unlock()
+
+	if exit := proc.ExitStatus(); exit != nil {
+		now := time.Now()
+		record.ExitedAt = &now
+		record.ExitCode = &exit.Code
+		record.ExitSignal = exit.Signal
+		record.StderrTail = tail(exit.Stderr, stderrTailBytes)
+		if exit.Signal != "" {
+			slog.Warn("claude process killed by signal", "session_id", record.SessionID, "pid", record.PID, "signal", exit.Signal)
+		}
+	}
+	if processes == nil {
+		return
+	}
+	// The session's context is cancelled on shutdown
+	if err := processes.RecordSessionProcess(context.WithoutCancel(ctx), record); err != nil {
+		slog.Warn("failed to record session process exit", "session_id", record.SessionID, "error", err)
+	}
+}
+
+// sample updates a process's resource figures
+func (m *Manager) sample(sessionID string, pid int) {
+	rss, cpu, err := sampleProcess(pid)
+	if err != nil {
+		slog.Debug("failed to sample claude process", "session_id", sessionID, "pid", pid, "error", err)
+		return
+	}
+	now := time.Now()
+	m.statsMu.Lock()
+	defer m.statsMu.Unlock()
+	stats, ok := m.stats[sessionID]
+	if !ok {
+		return
+	}
+	if elapsed := now.Sub(stats.SampledAt).Seconds(); elapsed > 0 && stats.CPUSeconds > 0 {
+		stats.CPUPercent = (cpu - stats.CPUSeconds) / elapsed * 100
+	}
+	stats.RSSBytes = rss
+	stats.PeakRSSBytes = max(stats.PeakRSSBytes, rss)
+	stats.CPUSeconds = cpu
+	stats.SampledAt = now
+}
+
+// sampleProcess returns a process's resident memory in bytes and CPU time
+// in seconds, from /proc where there is one and ps elsewhere
+func sampleProcess(pid int) (rssBytes int64, cpuSeconds float64, err error) {
+	if stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid)); err == nil {
+		return parseProcStat(string(stat))
+	}
+	out, err := exec.Command("ps", "-o", "rss=", "-o", "time=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return 0, 0, err
+	}
+	return parsePS(string(out))
+}
+
+// parseProcStat reads RSS and CPU time from /proc/<pid>/stat
+func parseProcStat(stat string) (int64, float64, error) {
+	// The command name may contain spaces, so fields start after its ')'
+	fields := strings.Fields(stat[strings.LastIndexByte(stat, ')')+1:])
+	if len(fields) < 22 {
+		return 0, 0, fmt.Errorf("short /proc stat line")
+	}
+	// Fields from state (3rd in proc(5)) on: utime is 14th, stime 15th, rss 24th
+	utime, err1 := strconv.ParseInt(fields[11], 10, 64)
+	stime, err2 := strconv.ParseInt(fields[12], 10, 64)
+	pages, err3 := strconv.ParseInt(fields[21], 10, 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return 0, 0, fmt.Errorf("malformed /proc stat line")
+	}
+	return pages * int64(os.Getpagesize()), float64(utime+stime) / clockTicks, nil
+}
+
+// parsePS reads `ps -o rss= -o time=` output: RSS in KiB and CPU time as
+// [[dd-]hh:]mm:ss[.ss]
+func parsePS(out string) (int64, float64, error) {
+	fields := strings.Fields(out)
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("unexpected ps output %q", out)
+	}
+	kib, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("unexpected ps rss %q", fields[0])
+	}
+	var seconds float64
+	clock := fields[1]
+	if days, rest, ok := strings.Cut(clock, "-"); ok {
+		d, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, 0, fmt.Errorf("unexpected ps time %q", fields[1])
+		}
+		seconds, clock = float64(d)*86400, rest
+	}
+	var parts float64
+	for _, part := range strings.Split(clock, ":") {
+		v, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("unexpected ps time %q", fields[1])
+		}
+		parts = parts*60 + v
+	}
+	return kib * 1024, seconds + parts, nil
+}
+
+// tail returns at most n bytes from the end of s, starting on a line
+// boundary when one is near
+func tail(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	s = s[len(s)-n:]
+	if i := strings.IndexByte(s, '\n'); i >= 0 && i < n/4 {
+		s = s[i+1:]
+	}
+	return s
+}
@@ -0,0 +1,85 @@
+package session
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	claudecode "github.com/humanlayer/humanlayer/claudecode-go"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProcess is a Claude session whose process is the test binary
+type fakeProcess struct {
+	ClaudeSession
+	exit *claudecode.ExitStatus
+}
+
+func (f *fakeProcess) PID() int                           { return os.Getpid() }
+func (f *fakeProcess) ExitStatus() *claudecode.ExitStatus { return f.exit }
+
+func TestSuperviseRecordsExit(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	m := &Manager{}
+	m.SetProcessStore(s)
+
+	proc := &fakeProcess{}
+	stop := m.supervise(ctx, "sess-1", proc)
+	record, err := s.GetSessionProcess(ctx, "sess-1")
+	require.NoError(t, err)
+	assert.Equal(t, os.Getpid(), record.PID)
+	assert.Nil(t, record.ExitedAt)
+
+	m.sample("sess-1", os.Getpid())
+	stats, running := m.ProcessStats("sess-1")
+	require.True(t, running)
+	assert.Positive(t, stats.RSSBytes)
+	assert.Equal(t, stats.RSSBytes, stats.PeakRSSBytes)
+
+	proc.exit = &claudecode.ExitStatus{Code: -1, Signal: "killed", Stderr: strings.Repeat("noise\n", 2000) + "FATAL ERROR: heap out of memory\n"}
+	stop()
+	stop()
+
+	_, running = m.ProcessStats("sess-1")
+	assert.False(t, running)
+	record, err = s.GetSessionProcess(ctx, "sess-1")
+	require.NoError(t, err)
+	require.NotNil(t, record.ExitCode)
+	assert.Equal(t, -1, *record.ExitCode)
+	assert.Equal(t, "killed", record.ExitSignal)
+	assert.NotNil(t, record.ExitedAt)
+	assert.Equal(t, stats.PeakRSSBytes, record.PeakRSSBytes)
+	assert.LessOrEqual(t, len(record.StderrTail), stderrTailBytes)
+	assert.True(t, strings.HasPrefix(record.StderrTail, "noise\n"), "the tail starts on a line")
+	assert.True(t, strings.HasSuffix(record.StderrTail, "heap out of memory\n"))
+}
+
+func TestSuperviseSkipsSessionsWithoutProcess(t *testing.T) {
+	s := store.NewInMemoryStore()
+	m := &Manager{}
+	m.SetProcessStore(s)
+	m.supervise(context.Background(), "sess-1", NewMockClaudeSession(nil))()
+	_, err := s.GetSessionProcess(context.Background(), "sess-1")
+	assert.ErrorIs(t, err, store.ErrNotFound)
+}
+
+func TestParseProcessSamples(t *testing.T) {
+	stat := "4242 (claude (node)) S 1 4242 4242 0 -1 4194560 5000 0 0 0 250 50 0 0 20 0 11 0 100 1073741824 25600 18446744073709551615"
+	rss, cpu, err := parseProcStat(stat)
+	require.NoError(t, err)
+	assert.Equal(t, int64(25600*os.Getpagesize()), rss)
+	assert.Equal(t, 3.0, cpu)
+
+	rss, cpu, err = parsePS(" 204800   1:02.50\n")
+	require.NoError(t, err)
+	assert.Equal(t, int64(204800*1024), rss)
+	assert.Equal(t, 62.5, cpu)
+
+	_, cpu, err = parsePS("1024 2-01:00:00")
+	require.NoError(t, err)
+	assert.Equal(t, float64(2*86400+3600), cpu)
+}
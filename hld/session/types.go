@@ -5,6 +5,7 @@ import (
 	"time"
 
 	claudecode "github.com/humanlayer/humanlayer/claudecode-go"
+	"github.com/humanlayer/humanlayer/hld/approval"
 	"github.com/humanlayer/humanlayer/hld/store"
 )
 
@@ -17,7 +18,8 @@ type ApprovalReconciler interface {
 type Status string
 
 const (
-	StatusDraft        Status = "draft" // Session in configuration state
+	StatusDraft        Status = "draft"  // Session in configuration state
+	StatusQueued       Status = "queued" // Waiting in the launch queue for a free slot
 	StatusStarting     Status = "starting"
 	StatusRunning      Status = "running"
 	StatusCompleted    Status = "completed"
@@ -68,6 +70,10 @@ type Info struct {
 	ProxyBaseURL                        string             `json:"proxy_base_url,omitempty"`
 	ProxyModelOverride                  string             `json:"proxy_model_override,omitempty"`
 	ProxyAPIKey                         string             `json:"proxy_api_key,omitempty"`
+	AutoDenyAll                         bool               `json:"auto_deny_all"`
+	AutoDenyTools                       []string           `json:"auto_deny_tools,omitempty"`
+	Budget                              *approval.Budget   `json:"budget,omitempty"`
+	Template                            string             `json:"template,omitempty"`
 }
 
 // LaunchSessionConfig contains the configuration for launching a new session
@@ -79,6 +85,29 @@ type LaunchSessionConfig struct {
 	DangerouslySkipPermissions        bool   // Whether to auto-approve all tools
 	DangerouslySkipPermissionsTimeout *int64 // Optional timeout in milliseconds
 	CreateDirectoryIfNotExists        bool   // Create working directory if it doesn't exist
+	// Auto-deny configuration
+	AutoDenyAll   bool     // Deny every approval request (observation-only session)
+	AutoDenyTools []string // Tool names (or "prefix*" patterns) to deny automatically
+	// Resource limits; nil uses the daemon's default session budget
+	Budget *approval.Budget
+	// Saved template the session was launched from, used for usage reporting
+	// and to pick the container image
+	Template string
+	// Run the agent in a container built from the template's image
+	Container bool
+	// Build the working directory's devcontainer.json and run the agent in it;
+	// takes precedence over Container
+	Devcontainer bool
+	// Launch queue priority; higher starts first once max_concurrent_sessions
+	// is reached
+	Priority int
+	// Failed session this launch automatically retries
+	RetryOf string
+	// Add the repository's decision log to the system prompt, even when
+	// decision_log.inject_context is off
+	IncludeDecisions bool
+	// Context pack built for this launch, added to the system prompt
+	ContextPackID string
 	// Proxy configuration
 	ProxyEnabled       bool   // Whether proxy is enabled
 	ProxyBaseURL       string // Proxy base URL
@@ -103,6 +132,7 @@ type ContinueSessionConfig struct {
 	ProxyBaseURL          string                // Proxy base URL
 	ProxyModelOverride    string                // Model to use with proxy
 	ProxyAPIKey           string                // API key for proxy service
+	RetryOf               string                // Failed session this continuation automatically retries
 }
 
 // DirectoryNotFoundError indicates a directory doesn't exist and needs creation
@@ -158,6 +188,15 @@ type SessionManager interface {
 
 	// GetClaudeVersion returns the Claude binary version if available
 	GetClaudeVersion() (string, error)
+
+	// QueueStatus returns the concurrency limit and the sessions waiting for a slot
+	QueueStatus() QueueStatus
+
+	// CancelQueued removes a session from the launch queue and marks it failed
+	CancelQueued(ctx context.Context, sessionID string) error
+
+	// ProcessStats returns the latest resource sample of a running session's process
+	ProcessStats(sessionID string) (ProcessStats, bool)
 }
 
 // ReadToolResult represents the JSON structure of a Read tool result
@@ -198,9 +237,16 @@ func SessionToInfo(s store.Session) Info {
 		ProxyBaseURL:                        s.ProxyBaseURL,
 		ProxyModelOverride:                  s.ProxyModelOverride,
 		ProxyAPIKey:                         s.ProxyAPIKey,
+		AutoDenyAll:                         s.AutoDenyAll,
+		AutoDenyTools:                       approval.ParseAutoDenyTools(s.AutoDenyTools),
+		Template:                            s.Template,
 		// Note: CLICommand is not stored in database, it's a build-time constant
 	}
 
+	if budget := approval.ParseBudget(s.Budget); !budget.IsZero() {
+		info.Budget = &budget
+	}
+
 	if s.CompletedAt != nil {
 		info.EndTime = s.CompletedAt
 	}
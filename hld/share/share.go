@@ -0,0 +1,192 @@
+// Package share makes links that show one session to people without access
+// to the daemon. A link's token names the link, its scope and its expiry,
+// signed with a key beside the database; the link itself is kept in the
+// store so it can be listed and revoked. A read link shows the transcript,
+// diff and pending approvals, and an approve link can also decide them.
+package share
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/internal/keyfile"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+var (
+	// ErrLinkInvalid is returned for tokens that don't name a link or whose
+	// signature is wrong
+	ErrLinkInvalid = errors.New("invalid share link")
+	// ErrLinkExpired is returned for links past their expiry
+	ErrLinkExpired = errors.New("share link expired")
+	// ErrLinkRevoked is returned for links that were revoked
+	ErrLinkRevoked = errors.New("share link revoked")
+	// ErrInvalidScope is returned for creating a link with an unknown scope
+	ErrInvalidScope = errors.New("scope must be read or approve")
+	// ErrInvalidTTL is returned for creating a link valid for longer than
+	// the configured maximum, or not at all
+	ErrInvalidTTL = errors.New("share link lifetime is out of range")
+)
+
+// Service creates, checks and revokes share links
+type Service struct {
+	store      store.ShareLinkStore
+	keyFile    string
+	defaultTTL time.Duration
+	maxTTL     time.Duration
+	now        func() time.Time
+
+	mu  sync.Mutex
+	key []byte // loaded from keyFile on first use
+}
+
+// New creates a service signing tokens with key, with unset lifetimes
+// defaulted
+func New(s store.ShareLinkStore, cfg config.SharingConfig, key []byte) *Service {
+	svc := &Service{
+		store:      s,
+		key:        key,
+		defaultTTL: config.DefaultShareLinkTTL,
+		maxTTL:     config.DefaultShareLinkMaxTTL,
+		now:        time.Now,
+	}
+	if ttl, err := time.ParseDuration(cfg.DefaultTTL); err == nil && ttl > 0 {
+		svc.defaultTTL = ttl
+	}
+	if ttl, err := time.ParseDuration(cfg.MaxTTL); err == nil && ttl > 0 {
+		svc.maxTTL = ttl
+	}
+	if svc.defaultTTL > svc.maxTTL {
+		svc.defaultTTL = svc.maxTTL
+	}
+	return svc
+}
+
+// FromConfig creates the daemon's service, with the signing key in
+// share.key beside the database
+func FromConfig(cfg *config.Config, s store.ShareLinkStore) *Service {
+	svc := New(s, cfg.Sharing, nil)
+	svc.keyFile = filepath.Join(filepath.Dir(cfg.DatabasePath), "share.key")
+	return svc
+}
+
+func (s *Service) signingKey() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.key == nil {
+		key, err := keyfile.Load(s.keyFile, 32)
+		if err != nil {
+			return nil, fmt.Errorf("share key: %w", err)
+		}
+		s.key = key
+	}
+	return s.key, nil
+}
+
+// Create makes a link to a session valid for ttl, or the default lifetime
+// when ttl is zero, and returns it with its token
+func (s *Service) Create(ctx context.Context, sessionID, scope, label string, ttl time.Duration) (*store.ShareLink, string, error) {
+	if scope != store.ShareScopeRead && scope != store.ShareScopeApprove {
+		return nil, "", ErrInvalidScope
+	}
+	if ttl == 0 {
+		ttl = s.defaultTTL
+	}
+	if ttl < 0 || ttl > s.maxTTL {
+		return nil, "", fmt.Errorf("%w: at most %s", ErrInvalidTTL, s.maxTTL)
+	}
+	now := s.now()
+	link := &store.ShareLink{
+		ID:        uuid.New().String(),
+		SessionID: sessionID,
+		Scope:     scope,
+		Label:     label,
+		CreatedAt: now,
+		// Whole seconds, as the token carries it
+		ExpiresAt: now.Add(ttl).Truncate(time.Second),
+	}
+	token, err := s.Token(link)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := s.store.CreateShareLink(ctx, link); err != nil {
+		return nil, "", err
+	}
+	return link, token, nil
+}
+
+// Token returns the token of a link. Tokens read
+// "<link id>.<scope>.<expiry>.<signature>".
+func (s *Service) Token(link *store.ShareLink) (string, error) {
+	claim := strings.Join([]string{link.ID, link.Scope, strconv.FormatInt(link.ExpiresAt.Unix(), 10)}, ".")
+	mac, err := s.sign(claim, link.SessionID)
+	if err != nil {
+		return "", err
+	}
+	return claim + "." + mac, nil
+}
+
+func (s *Service) sign(claim, sessionID string) (string, error) {
+	key, err := s.signingKey()
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	_, _ = fmt.Fprintf(mac, "share:%s\n%s", claim, sessionID)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// Resolve returns the link a token grants, if it's still valid
+func (s *Service) Resolve(ctx context.Context, token string) (*store.ShareLink, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 {
+		return nil, ErrLinkInvalid
+	}
+	link, err := s.store.GetShareLink(ctx, parts[0])
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrLinkInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	want, err := s.Token(link)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal([]byte(want), []byte(token)) {
+		return nil, ErrLinkInvalid
+	}
+	switch {
+	case link.RevokedAt != nil:
+		return nil, ErrLinkRevoked
+	case !s.now().Before(link.ExpiresAt):
+		return nil, ErrLinkExpired
+	}
+	return link, nil
+}
+
+// Active reports whether a link still works, ignoring its signature
+func (s *Service) Active(link *store.ShareLink) bool {
+	return link.RevokedAt == nil && s.now().Before(link.ExpiresAt)
+}
+
+// List returns a session's links, oldest first
+func (s *Service) List(ctx context.Context, sessionID string) ([]*store.ShareLink, error) {
+	return s.store.ListShareLinks(ctx, sessionID)
+}
+
+// Revoke stops a link working
+func (s *Service) Revoke(ctx context.Context, id string) (*store.ShareLink, error) {
+	return s.store.RevokeShareLink(ctx, id)
+}
@@ -0,0 +1,70 @@
+package share
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinks(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	svc := New(s, config.SharingConfig{MaxTTL: "48h"}, bytes.Repeat([]byte{7}, 32))
+	now := time.Now()
+	svc.now = func() time.Time { return now }
+
+	link, token, err := svc.Create(ctx, "sess-1", store.ShareScopeRead, "for grace", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "sess-1", link.SessionID)
+	assert.WithinDuration(t, now.Add(config.DefaultShareLinkTTL), link.ExpiresAt, time.Second)
+	assert.True(t, strings.HasPrefix(token, link.ID+".read."), token)
+
+	resolved, err := svc.Resolve(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, link.ID, resolved.ID)
+	again, err := svc.Token(resolved)
+	require.NoError(t, err)
+	assert.Equal(t, token, again, "a link's token can be shown again")
+
+	parts := strings.Split(token, ".")
+	for name, forged := range map[string]string{
+		"wider scope":  strings.Join([]string{parts[0], store.ShareScopeApprove, parts[2], parts[3]}, "."),
+		"later expiry": strings.Join([]string{parts[0], parts[1], "9999999999", parts[3]}, "."),
+		"unknown link": strings.Join(append([]string{"missing"}, parts[1:]...), "."),
+		"truncated":    strings.Join(parts[:3], "."),
+	} {
+		_, err := svc.Resolve(ctx, forged)
+		assert.ErrorIs(t, err, ErrLinkInvalid, name)
+	}
+	_, err = New(s, config.SharingConfig{}, bytes.Repeat([]byte{8}, 32)).Resolve(ctx, token)
+	assert.ErrorIs(t, err, ErrLinkInvalid, "tokens are bound to the key")
+
+	_, _, err = svc.Create(ctx, "sess-1", "admin", "", 0)
+	assert.ErrorIs(t, err, ErrInvalidScope)
+	_, _, err = svc.Create(ctx, "sess-1", store.ShareScopeApprove, "", 72*time.Hour)
+	assert.ErrorIs(t, err, ErrInvalidTTL)
+
+	_, approveToken, err := svc.Create(ctx, "sess-1", store.ShareScopeApprove, "", time.Hour)
+	require.NoError(t, err)
+	now = now.Add(2 * time.Hour)
+	_, err = svc.Resolve(ctx, approveToken)
+	assert.ErrorIs(t, err, ErrLinkExpired)
+
+	_, err = svc.Revoke(ctx, link.ID)
+	require.NoError(t, err)
+	_, err = svc.Resolve(ctx, token)
+	assert.ErrorIs(t, err, ErrLinkRevoked)
+
+	links, err := svc.List(ctx, "sess-1")
+	require.NoError(t, err)
+	require.Len(t, links, 2)
+	assert.False(t, svc.Active(links[0]))
+	assert.False(t, svc.Active(links[1]))
+}
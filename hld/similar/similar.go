@@ -0,0 +1,328 @@
+// Package similar embeds session summaries and key messages so that past
+// sessions can be found by meaning ("have we solved this before?") rather
+// than by keyword.
+package similar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/llm"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/humanlayer/humanlayer/hld/summary"
+)
+
+// maxChunkLength truncates each embedded text; embedding models have small
+// input limits and the start of a message says what it is about
+const maxChunkLength = 2000
+
+// maxMessages caps the user messages embedded per session, after the query
+const maxMessages = 10
+
+// snippetLength is how much of the matched text a search result shows
+const snippetLength = 300
+
+// indexTimeout bounds embedding one session
+const indexTimeout = time.Minute
+
+// Embedder turns texts into vectors; llm.Embedder is the real one
+type Embedder interface {
+	Model() string
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// terminalStatuses are the statuses after which a session is indexed
+var terminalStatuses = map[string]bool{
+	store.SessionStatusCompleted:   true,
+	store.SessionStatusFailed:      true,
+	store.SessionStatusInterrupted: true,
+}
+
+// Index embeds finished sessions and searches them
+type Index struct {
+	store      store.ConversationStore
+	embeddings store.EmbeddingStore
+	embedder   Embedder
+	eventBus   bus.EventBus
+	// waitForSummary indexes sessions once their completion summary is
+	// ready rather than as soon as they finish
+	waitForSummary bool
+
+	inFlight sync.Map // session ID -> struct{}
+}
+
+// NewIndex creates an index over s, keeping its vectors in embeddings
+func NewIndex(s store.ConversationStore, embeddings store.EmbeddingStore, embedder Embedder, eventBus bus.EventBus, waitForSummary bool) *Index {
+	return &Index{store: s, embeddings: embeddings, embedder: embedder, eventBus: eventBus, waitForSummary: waitForSummary}
+}
+
+// FromConfig creates the index configured in cfg, or returns nil when no
+// embedding model is configured
+func FromConfig(cfg *config.Config, s store.ConversationStore, embeddings store.EmbeddingStore, eventBus bus.EventBus) (*Index, error) {
+	if cfg.Embeddings.Model == "" {
+		return nil, nil
+	}
+	embedder, err := llm.NewEmbedder(cfg.LLM, cfg.Embeddings)
+	if err != nil {
+		return nil, err
+	}
+	return NewIndex(s, embeddings, embedder, eventBus, !cfg.SessionSummariesDisabled), nil
+}
+
+// Run indexes finished sessions that have no embeddings yet, then each
+// session as it finishes, until ctx is cancelled
+func (x *Index) Run(ctx context.Context) {
+	trigger := bus.EventSessionStatusChanged
+	if x.waitForSummary {
+		trigger = bus.EventSessionSummaryReady
+	}
+	sub := x.eventBus.Subscribe(ctx, bus.EventFilter{Types: []bus.EventType{trigger}})
+
+	go x.backfill(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-sub.Channel:
+			if !ok {
+				return
+			}
+			sessionID, _ := event.Data["session_id"].(string)
+			if sessionID == "" {
+				continue
+			}
+			if event.Type == bus.EventSessionStatusChanged {
+				if status, _ := event.Data["new_status"].(string); !terminalStatuses[status] {
+					continue
+				}
+			}
+			go x.indexLogged(ctx, sessionID)
+		}
+	}
+}
+
+func (x *Index) indexLogged(ctx context.Context, sessionID string) {
+	if _, busy := x.inFlight.LoadOrStore(sessionID, struct{}{}); busy {
+		return
+	}
+	defer x.inFlight.Delete(sessionID)
+	if err := x.IndexSession(ctx, sessionID); err != nil {
+		slog.Warn("failed to embed session", "session_id", sessionID, "error", err)
+	}
+}
+
+// backfill indexes finished sessions missing from the index, one at a time.
+// Sessions whose summary never arrived are indexed without it.
+func (x *Index) backfill(ctx context.Context) {
+	embeddings, err := x.embeddings.ListSessionEmbeddings(ctx, x.embedder.Model())
+	if err != nil {
+		slog.Warn("failed to list session embeddings", "error", err)
+		return
+	}
+	indexed := make(map[string]bool)
+	for _, e := range embeddings {
+		indexed[e.SessionID] = true
+	}
+	sessions, err := x.store.ListSessions(ctx)
+	if err != nil {
+		slog.Warn("failed to list sessions for embedding", "error", err)
+		return
+	}
+	count := 0
+	for _, sess := range sessions {
+		if ctx.Err() != nil {
+			return
+		}
+		if indexed[sess.ID] || !terminalStatuses[sess.Status] {
+			continue
+		}
+		x.indexLogged(ctx, sess.ID)
+		count++
+	}
+	if count > 0 {
+		slog.Info("embedded past sessions", "sessions", count)
+	}
+}
+
+// IndexSession embeds a session's summary, query, later user messages and
+// result, replacing any earlier embeddings of it
+func (x *Index) IndexSession(ctx context.Context, sessionID string) error {
+	ctx, cancel := context.WithTimeout(ctx, indexTimeout)
+	defer cancel()
+
+	sess, err := x.store.GetSession(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	events, err := x.store.GetSessionConversation(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to get conversation: %w", err)
+	}
+	chunks := sessionChunks(sess, events)
+	if len(chunks) == 0 {
+		return nil
+	}
+
+	texts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		texts[i] = chunk.Content
+	}
+	vectors, err := x.embedder.Embed(ctx, texts)
+	if err != nil {
+		return err
+	}
+	model := x.embedder.Model()
+	for i, chunk := range chunks {
+		chunk.Model = model
+		chunk.Vector = vectors[i]
+	}
+	return x.embeddings.ReplaceSessionEmbeddings(ctx, sessionID, chunks)
+}
+
+// sessionChunks picks the texts that say what a session was about
+func sessionChunks(sess *store.Session, events []*store.ConversationEvent) []*store.SessionEmbedding {
+	var chunks []*store.SessionEmbedding
+	add := func(source, text string) {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return
+		}
+		if len(text) > maxChunkLength {
+			text = text[:maxChunkLength]
+		}
+		chunks = append(chunks, &store.SessionEmbedding{Source: source, Content: text})
+	}
+
+	if s, err := summary.Decode(sess.CompletionSummary); err == nil && s != nil {
+		add(store.EmbeddingSourceSummary, summaryText(s))
+	}
+	add(store.EmbeddingSourceQuery, sess.Query)
+	messages := 0
+	for _, event := range events {
+		if event.EventType != store.EventTypeMessage || event.Role != "user" || messages >= maxMessages {
+			continue
+		}
+		// The first user message repeats the query
+		if strings.TrimSpace(event.Content) == strings.TrimSpace(sess.Query) {
+			continue
+		}
+		add(store.EmbeddingSourceMessage, event.Content)
+		messages++
+	}
+	add(store.EmbeddingSourceResult, sess.ResultContent)
+	return chunks
+}
+
+func summaryText(s *summary.Summary) string {
+	parts := []string{s.Asked}
+	if len(s.Changed) > 0 {
+		parts = append(parts, "Changed: "+strings.Join(s.Changed, "; "))
+	}
+	if len(s.OpenQuestions) > 0 {
+		parts = append(parts, "Open questions: "+strings.Join(s.OpenQuestions, "; "))
+	}
+	return strings.Join(parts, "\n")
+}
+
+// Match is a past session similar to a search query
+type Match struct {
+	SessionID string    `json:"session_id"`
+	Title     string    `json:"title,omitempty"`
+	Query     string    `json:"query"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	// Score is the cosine similarity of the best matching text, from -1 to 1
+	Score float64 `json:"score"`
+	// Source and Snippet describe the best matching text
+	Source  string `json:"source"`
+	Snippet string `json:"snippet"`
+}
+
+// Search returns up to limit sessions most similar to query, best first
+func (x *Index) Search(ctx context.Context, query string, limit int) ([]Match, error) {
+	vectors, err := x.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	embeddings, err := x.embeddings.ListSessionEmbeddings(ctx, x.embedder.Model())
+	if err != nil {
+		return nil, err
+	}
+
+	best := make(map[string]*store.SessionEmbedding)
+	scores := make(map[string]float64)
+	for _, e := range embeddings {
+		score := cosine(vectors[0], e.Vector)
+		if current, ok := scores[e.SessionID]; !ok || score > current {
+			scores[e.SessionID] = score
+			best[e.SessionID] = e
+		}
+	}
+	ids := make([]string, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if scores[ids[i]] != scores[ids[j]] {
+			return scores[ids[i]] > scores[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+
+	matches := []Match{}
+	for _, id := range ids {
+		if len(matches) >= limit {
+			break
+		}
+		sess, err := x.store.GetSession(ctx, id)
+		if errors.Is(err, store.ErrNotFound) {
+			continue // Deleted since it was indexed
+		}
+		if err != nil {
+			return nil, err
+		}
+		snippet := best[id].Content
+		if len(snippet) > snippetLength {
+			snippet = snippet[:snippetLength] + "..."
+		}
+		matches = append(matches, Match{
+			SessionID: id,
+			Title:     sess.Title,
+			Query:     sess.Query,
+			Status:    sess.Status,
+			CreatedAt: sess.CreatedAt,
+			Score:     scores[id],
+			Source:    best[id].Source,
+			Snippet:   snippet,
+		})
+	}
+	return matches, nil
+}
+
+// cosine returns the cosine similarity of two vectors, or 0 when their
+// lengths differ or either is zero
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
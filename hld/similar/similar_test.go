@@ -0,0 +1,94 @@
+package similar
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// wordEmbedder embeds texts as counts of a small vocabulary, so texts sharing
+// words are similar
+type wordEmbedder struct {
+	calls int
+}
+
+var vocabulary = []string{"parser", "panic", "login", "oauth", "flaky", "test"}
+
+func (e *wordEmbedder) Model() string { return "words" }
+
+func (e *wordEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	e.calls++
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = make([]float32, len(vocabulary))
+		for j, word := range vocabulary {
+			vectors[i][j] = float32(strings.Count(strings.ToLower(text), word))
+		}
+	}
+	return vectors, nil
+}
+
+func TestSearch(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	for _, sess := range []*store.Session{
+		{ID: "parser", RunID: "r1", ClaudeSessionID: "c1", Query: "Fix the parser panic", Status: store.SessionStatusCompleted,
+			CompletionSummary: `{"asked":"Fix a panic in the parser","changed":["parse.go"],"open_questions":[],"follow_ups":[]}`},
+		{ID: "login", RunID: "r2", ClaudeSessionID: "c2", Query: "Add oauth login", Status: store.SessionStatusCompleted},
+		{ID: "running", RunID: "r3", ClaudeSessionID: "c3", Query: "Parser panic again", Status: store.SessionStatusRunning},
+	} {
+		sess.CreatedAt = time.Now()
+		require.NoError(t, s.CreateSession(ctx, sess))
+	}
+	require.NoError(t, s.AddConversationEvent(ctx, &store.ConversationEvent{
+		SessionID: "login", ClaudeSessionID: "c2", EventType: store.EventTypeMessage, Role: "user",
+		Content: "Also fix the flaky login test",
+	}))
+
+	embedder := &wordEmbedder{}
+	index := NewIndex(s, s, embedder, nil, true)
+	require.NoError(t, index.IndexSession(ctx, "parser"))
+	require.NoError(t, index.IndexSession(ctx, "login"))
+
+	embeddings, err := s.ListSessionEmbeddings(ctx, "words")
+	require.NoError(t, err)
+	var sources []string
+	for _, e := range embeddings {
+		if e.SessionID == "login" {
+			sources = append(sources, e.Source)
+		}
+	}
+	assert.Equal(t, []string{store.EmbeddingSourceQuery, store.EmbeddingSourceMessage}, sources)
+
+	matches, err := index.Search(ctx, "parser panic", 5)
+	require.NoError(t, err)
+	require.Len(t, matches, 2, "unindexed sessions aren't searched")
+	assert.Equal(t, "parser", matches[0].SessionID)
+	assert.InDelta(t, 1.0, matches[0].Score, 1e-6)
+	assert.Equal(t, "login", matches[1].SessionID)
+
+	matches, err = index.Search(ctx, "flaky test", 1)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "login", matches[0].SessionID)
+	assert.Equal(t, store.EmbeddingSourceMessage, matches[0].Source)
+	assert.Equal(t, "Also fix the flaky login test", matches[0].Snippet)
+
+	// Reindexing replaces rather than duplicates
+	require.NoError(t, index.IndexSession(ctx, "parser"))
+	again, err := s.ListSessionEmbeddings(ctx, "words")
+	require.NoError(t, err)
+	assert.Len(t, again, len(embeddings))
+}
+
+func TestCosine(t *testing.T) {
+	assert.InDelta(t, 1.0, cosine([]float32{1, 2}, []float32{2, 4}), 1e-9)
+	assert.InDelta(t, 0.0, cosine([]float32{1, 0}, []float32{0, 1}), 1e-9)
+	assert.Equal(t, 0.0, cosine([]float32{1}, []float32{1, 0}), "mismatched dimensions")
+	assert.Equal(t, 0.0, cosine([]float32{0, 0}, []float32{1, 0}))
+}
@@ -15,6 +15,9 @@ var (
 
 	// ErrInvalidStatus is returned when an invalid status is provided
 	ErrInvalidStatus = errors.New("invalid status")
+
+	// ErrNonceUsed is returned when a single-use nonce is used again
+	ErrNonceUsed = errors.New("nonce already used")
 )
 
 // NotFoundError wraps ErrNotFound with additional context
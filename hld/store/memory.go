@@ -0,0 +1,2580 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryStore is a Store kept entirely in memory. It mirrors the
+// SQLite store's observable behavior closely enough for tests and for
+// embedding hld components without a database.
+type MemoryStore struct {
+	mu sync.RWMutex
+
+	sessions       map[string]*Session
+	events         []*ConversationEvent
+	nextEventID    int64
+	mcpServers     map[string][]MCPServer
+	nextMCPID      int64
+	rawEvents      map[string][]string
+	approvals      map[string]*Approval
+	approvalImages map[string][]string
+	toolResults    []*ToolResult
+	nextResultID   int64
+	shadow         map[string]*ShadowDecision
+	snapshots      []FileSnapshot
+	nextSnapshotID int64
+	userSettings   UserSettings
+	jobs           map[string]*Job
+	experiments    map[string]*Experiment
+	experimentRuns map[string]*ExperimentRun
+	annotations    []*EventAnnotation
+	nextAnnotation int64
+	aiOutputs      []*AIOutput
+	embeddings     []*SessionEmbedding
+	nextEmbedding  int64
+	decisions      map[string]*Decision
+	proposals      map[string]*MemoryFileProposal
+	contextPacks   map[string]*ContextPack
+	processes      map[string]*SessionProcess
+	credentials    map[string]*RemoteCredential
+	artifacts      map[string]*Artifact
+	pushSubs       map[string]*WebPushSubscription
+	watches        map[watchKey]*SessionWatch
+	inbox          map[inboxKey]*InboxState
+	canned         map[string]*CannedResponse
+	holds          map[string]*ApprovalHold
+	violations     []*ConstraintViolation
+	injections     []*InjectionDetection
+	moderation     []*ModerationBlock
+	nonces         map[string]time.Time
+	rejections     []*RejectedResolution
+	shareLinks     map[string]*ShareLink
+	worktrees      map[string]*SessionWorktree // keyed by session ID
+	cloudMirrors   map[string]*CloudMirror
+	githubTriggers map[string]*GitHubTrigger // keyed by session ID
+	sessionTickets map[string][]*SessionTicket
+	escalations    map[string]*ApprovalEscalation
+	commits        []*SessionCommit
+}
+
+// NewInMemoryStore creates an empty in-memory conversation store
+func NewInMemoryStore() *MemoryStore {
+	now := time.Now()
+	return &MemoryStore{
+		sessions:       make(map[string]*Session),
+		mcpServers:     make(map[string][]MCPServer),
+		rawEvents:      make(map[string][]string),
+		approvals:      make(map[string]*Approval),
+		approvalImages: make(map[string][]string),
+		shadow:         make(map[string]*ShadowDecision),
+		jobs:           make(map[string]*Job),
+		experiments:    make(map[string]*Experiment),
+		experimentRuns: make(map[string]*ExperimentRun),
+		decisions:      make(map[string]*Decision),
+		proposals:      make(map[string]*MemoryFileProposal),
+		contextPacks:   make(map[string]*ContextPack),
+		processes:      make(map[string]*SessionProcess),
+		credentials:    make(map[string]*RemoteCredential),
+		artifacts:      make(map[string]*Artifact),
+		pushSubs:       make(map[string]*WebPushSubscription),
+		watches:        make(map[watchKey]*SessionWatch),
+		inbox:          make(map[inboxKey]*InboxState),
+		canned:         make(map[string]*CannedResponse),
+		holds:          make(map[string]*ApprovalHold),
+		cloudMirrors:   make(map[string]*CloudMirror),
+		githubTriggers: make(map[string]*GitHubTrigger),
+		sessionTickets: make(map[string][]*SessionTicket),
+		escalations:    make(map[string]*ApprovalEscalation),
+		shareLinks:     make(map[string]*ShareLink),
+		worktrees:      make(map[string]*SessionWorktree),
+		userSettings:   UserSettings{CreatedAt: now, UpdatedAt: now},
+	}
+}
+
+// CreateSession creates a new session
+func (m *MemoryStore) CreateSession(ctx context.Context, session *Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.sessions[session.ID]; exists {
+		return fmt.Errorf("failed to create session: session %s already exists", session.ID)
+	}
+	copied := *session
+	m.sessions[session.ID] = &copied
+	return nil
+}
+
+// UpdateSession updates session fields
+func (m *MemoryStore) UpdateSession(ctx context.Context, sessionID string, updates SessionUpdate) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.sessions[sessionID]
+	if !ok {
+		return fmt.Errorf("session not found: %s", sessionID)
+	}
+
+	if updates.ClaudeSessionID != nil {
+		s.ClaudeSessionID = *updates.ClaudeSessionID
+	}
+	if updates.Query != nil {
+		s.Query = *updates.Query
+	}
+	if updates.Summary != nil {
+		s.Summary = *updates.Summary
+	}
+	if updates.Title != nil {
+		s.Title = *updates.Title
+	}
+	if updates.Status != nil {
+		s.Status = *updates.Status
+	}
+	if updates.LastActivityAt != nil {
+		s.LastActivityAt = *updates.LastActivityAt
+	}
+	if updates.CompletedAt != nil {
+		completedAt := *updates.CompletedAt
+		s.CompletedAt = &completedAt
+	}
+	if updates.CostUSD != nil {
+		cost := *updates.CostUSD
+		s.CostUSD = &cost
+	}
+	if updates.InputTokens != nil {
+		s.InputTokens = intPtr(*updates.InputTokens)
+	}
+	if updates.OutputTokens != nil {
+		s.OutputTokens = intPtr(*updates.OutputTokens)
+	}
+	if updates.CacheCreationInputTokens != nil {
+		s.CacheCreationInputTokens = intPtr(*updates.CacheCreationInputTokens)
+	}
+	if updates.CacheReadInputTokens != nil {
+		s.CacheReadInputTokens = intPtr(*updates.CacheReadInputTokens)
+	}
+	if updates.EffectiveContextTokens != nil {
+		s.EffectiveContextTokens = intPtr(*updates.EffectiveContextTokens)
+	}
+	if updates.DurationMS != nil {
+		s.DurationMS = intPtr(*updates.DurationMS)
+	}
+	if updates.NumTurns != nil {
+		s.NumTurns = intPtr(*updates.NumTurns)
+	}
+	if updates.ResultContent != nil {
+		s.ResultContent = *updates.ResultContent
+	}
+	if updates.ErrorMessage != nil {
+		s.ErrorMessage = *updates.ErrorMessage
+	}
+	if updates.AutoAcceptEdits != nil {
+		s.AutoAcceptEdits = *updates.AutoAcceptEdits
+	}
+	if updates.DangerouslySkipPermissions != nil {
+		s.DangerouslySkipPermissions = *updates.DangerouslySkipPermissions
+	}
+	if updates.DangerouslySkipPermissionsExpiresAt != nil {
+		s.DangerouslySkipPermissionsExpiresAt = *updates.DangerouslySkipPermissionsExpiresAt
+	}
+	if updates.DangerouslySkipPermissionsTimeoutMs != nil {
+		timeout := *updates.DangerouslySkipPermissionsTimeoutMs
+		s.DangerouslySkipPermissionsTimeoutMs = &timeout
+	}
+	if updates.Model != nil {
+		s.Model = *updates.Model
+	}
+	if updates.ModelID != nil {
+		s.ModelID = *updates.ModelID
+	}
+	if updates.Archived != nil {
+		s.Archived = *updates.Archived
+	}
+	if updates.Reviewed != nil {
+		s.Reviewed = *updates.Reviewed
+	}
+	if updates.AdditionalDirectories != nil {
+		s.AdditionalDirectories = *updates.AdditionalDirectories
+	}
+	if updates.ProxyEnabled != nil {
+		s.ProxyEnabled = *updates.ProxyEnabled
+	}
+	if updates.ProxyBaseURL != nil {
+		s.ProxyBaseURL = *updates.ProxyBaseURL
+	}
+	if updates.ProxyModelOverride != nil {
+		s.ProxyModelOverride = *updates.ProxyModelOverride
+	}
+	if updates.ProxyAPIKey != nil {
+		s.ProxyAPIKey = *updates.ProxyAPIKey
+	}
+	if updates.WorkingDir != nil {
+		s.WorkingDir = *updates.WorkingDir
+	}
+	if updates.EditorState != nil {
+		editorState := *updates.EditorState
+		s.EditorState = &editorState
+	}
+	if updates.AutoDenyAll != nil {
+		s.AutoDenyAll = *updates.AutoDenyAll
+	}
+	if updates.AutoDenyTools != nil {
+		s.AutoDenyTools = *updates.AutoDenyTools
+	}
+	if updates.Budget != nil {
+		s.Budget = *updates.Budget
+	}
+	if updates.SSHHost != nil {
+		s.SSHHost = *updates.SSHHost
+	}
+	if updates.ContainerImage != nil {
+		s.ContainerImage = *updates.ContainerImage
+	}
+	if updates.CompletionSummary != nil {
+		s.CompletionSummary = *updates.CompletionSummary
+	}
+	if updates.RetryOf != nil {
+		s.RetryOf = *updates.RetryOf
+	}
+	if updates.ContextPackID != nil {
+		s.ContextPackID = *updates.ContextPackID
+	}
+
+	return nil
+}
+
+// HardDeleteSession permanently deletes a session and its dependent records
+func (m *MemoryStore) HardDeleteSession(ctx context.Context, sessionID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.sessions[sessionID]; !ok {
+		return sql.ErrNoRows
+	}
+	delete(m.sessions, sessionID)
+	delete(m.mcpServers, sessionID)
+	delete(m.rawEvents, sessionID)
+
+	events := m.events[:0]
+	for _, e := range m.events {
+		if e.SessionID != sessionID {
+			events = append(events, e)
+		}
+	}
+	m.events = events
+
+	for id, a := range m.approvals {
+		if a.SessionID == sessionID {
+			delete(m.approvals, id)
+			delete(m.approvalImages, id)
+		}
+	}
+	return nil
+}
+
+// GetSession retrieves a session by ID
+func (m *MemoryStore) GetSession(ctx context.Context, sessionID string) (*Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	s, ok := m.sessions[sessionID]
+	if !ok {
+		return nil, fmt.Errorf("session not found: %s", sessionID)
+	}
+	copied := *s
+	return &copied, nil
+}
+
+// GetSessionByRunID retrieves a session by its run ID, returning nil when absent
+func (m *MemoryStore) GetSessionByRunID(ctx context.Context, runID string) (*Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, s := range m.sessions {
+		if s.RunID == runID {
+			copied := *s
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+// ListSessions retrieves all sessions, most recently active first
+func (m *MemoryStore) ListSessions(ctx context.Context) ([]*Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.sortedSessions(func(*Session) bool { return true }), nil
+}
+
+// SearchSessionsByTitle finds leaf sessions whose title, summary or query contains query
+func (m *MemoryStore) SearchSessionsByTitle(ctx context.Context, query string, limit int) ([]*Session, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 50 {
+		limit = 50
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	parents := make(map[string]bool)
+	for _, s := range m.sessions {
+		if s.ParentSessionID != "" {
+			parents[s.ParentSessionID] = true
+		}
+	}
+
+	needle := strings.ToLower(query)
+	matches := m.sortedSessions(func(s *Session) bool {
+		if parents[s.ID] || s.Archived || s.Status == SessionStatusDraft || s.Status == SessionStatusDiscarded {
+			return false
+		}
+		if needle == "" {
+			return true
+		}
+		return strings.Contains(strings.ToLower(s.Title), needle) ||
+			strings.Contains(strings.ToLower(s.Summary), needle) ||
+			strings.Contains(strings.ToLower(s.Query), needle)
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+// GetExpiredDangerousPermissionsSessions returns active sessions whose dangerous permissions have expired
+func (m *MemoryStore) GetExpiredDangerousPermissionsSessions(ctx context.Context) ([]*Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	expired := m.sortedSessions(func(s *Session) bool {
+		if !s.DangerouslySkipPermissions || s.DangerouslySkipPermissionsExpiresAt == nil {
+			return false
+		}
+		switch s.Status {
+		case SessionStatusRunning, SessionStatusWaitingInput, SessionStatusStarting:
+			return s.DangerouslySkipPermissionsExpiresAt.Before(now)
+		}
+		return false
+	})
+	sort.SliceStable(expired, func(i, j int) bool {
+		return expired[i].DangerouslySkipPermissionsExpiresAt.Before(*expired[j].DangerouslySkipPermissionsExpiresAt)
+	})
+	return expired, nil
+}
+
+// AddConversationEvent adds a new conversation event
+func (m *MemoryStore) AddConversationEvent(ctx context.Context, event *ConversationEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	maxSeq := 0
+	for _, e := range m.events {
+		if e.ClaudeSessionID == event.ClaudeSessionID && e.Sequence > maxSeq {
+			maxSeq = e.Sequence
+		}
+	}
+	event.Sequence = maxSeq + 1
+
+	m.nextEventID++
+	event.ID = m.nextEventID
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	copied := *event
+	m.events = append(m.events, &copied)
+	return nil
+}
+
+// GetConversation retrieves all events for a Claude session
+func (m *MemoryStore) GetConversation(ctx context.Context, claudeSessionID string) ([]*ConversationEvent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	events := m.filterEvents(func(e *ConversationEvent) bool { return e.ClaudeSessionID == claudeSessionID })
+	sortBySequence(events)
+	return events, nil
+}
+
+// GetLastEventTimes returns when each of sessionIDs last recorded a
+// conversation event, leaving out sessions without any
+func (m *MemoryStore) GetLastEventTimes(ctx context.Context, sessionIDs []string) (map[string]time.Time, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	wanted := make(map[string]bool, len(sessionIDs))
+	for _, id := range sessionIDs {
+		wanted[id] = true
+	}
+	times := make(map[string]time.Time, len(sessionIDs))
+	for _, e := range m.events {
+		if wanted[e.SessionID] && e.CreatedAt.After(times[e.SessionID]) {
+			times[e.SessionID] = e.CreatedAt
+		}
+	}
+	return times, nil
+}
+
+// GetSessionConversation retrieves all events for a session, including its parents
+func (m *MemoryStore) GetSessionConversation(ctx context.Context, sessionID string) ([]*ConversationEvent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, ok := m.sessions[sessionID]; !ok {
+		return nil, fmt.Errorf("session not found: %s", sessionID)
+	}
+
+	// Walk up the parent chain, oldest Claude session first
+	var claudeSessionIDs []string
+	for currentID := sessionID; currentID != ""; {
+		s, ok := m.sessions[currentID]
+		if !ok {
+			break
+		}
+		if s.ClaudeSessionID != "" {
+			claudeSessionIDs = append([]string{s.ClaudeSessionID}, claudeSessionIDs...)
+		}
+		currentID = s.ParentSessionID
+	}
+
+	events := []*ConversationEvent{}
+	for _, claudeSessionID := range claudeSessionIDs {
+		chunk := m.filterEvents(func(e *ConversationEvent) bool { return e.ClaudeSessionID == claudeSessionID })
+		sortBySequence(chunk)
+		events = append(events, chunk...)
+	}
+	return events, nil
+}
+
+// GetPendingToolCall finds the most recent uncompleted tool call for a tool
+func (m *MemoryStore) GetPendingToolCall(ctx context.Context, sessionID string, toolName string) (*ConversationEvent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.latestToolCall(func(e *ConversationEvent) bool {
+		return e.SessionID == sessionID && e.ToolName == toolName && !e.IsCompleted
+	}), nil
+}
+
+// GetUncorrelatedPendingToolCall finds the most recent uncompleted tool call without an approval
+func (m *MemoryStore) GetUncorrelatedPendingToolCall(ctx context.Context, sessionID string, toolName string) (*ConversationEvent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.latestToolCall(func(e *ConversationEvent) bool {
+		return e.SessionID == sessionID && e.ToolName == toolName && !e.IsCompleted && e.ApprovalStatus == ""
+	}), nil
+}
+
+// GetPendingToolCalls finds all uncompleted tool calls for a session, most recent first
+func (m *MemoryStore) GetPendingToolCalls(ctx context.Context, sessionID string) ([]*ConversationEvent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	events := m.filterEvents(func(e *ConversationEvent) bool {
+		return e.SessionID == sessionID && e.EventType == EventTypeToolCall && !e.IsCompleted
+	})
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Sequence > events[j].Sequence })
+	return events, nil
+}
+
+// GetToolCallByID retrieves a specific tool call by its ID
+func (m *MemoryStore) GetToolCallByID(ctx context.Context, toolID string) (*ConversationEvent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, e := range m.events {
+		if e.ToolID == toolID && e.EventType == EventTypeToolCall {
+			copied := *e
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+// MarkToolCallCompleted marks a tool call as completed when its result is received
+func (m *MemoryStore) MarkToolCallCompleted(ctx context.Context, toolID string, sessionID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, e := range m.events {
+		if e.ToolID == toolID && e.SessionID == sessionID && e.EventType == EventTypeToolCall {
+			e.IsCompleted = true
+		}
+	}
+	return nil
+}
+
+// CorrelateApproval correlates an approval with the most recent pending tool call
+func (m *MemoryStore) CorrelateApproval(ctx context.Context, sessionID string, toolName string, approvalID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var target *ConversationEvent
+	for _, e := range m.events {
+		if e.SessionID == sessionID && e.ToolName == toolName && e.EventType == EventTypeToolCall && !e.IsCompleted {
+			if target == nil || e.Sequence > target.Sequence {
+				target = e
+			}
+		}
+	}
+	if target == nil || target.ApprovalStatus != "" {
+		return nil
+	}
+	target.ApprovalStatus = ApprovalStatusPending
+	target.ApprovalID = approvalID
+	return nil
+}
+
+// LinkConversationEventToApprovalUsingToolID correlates an approval with a specific tool call
+func (m *MemoryStore) LinkConversationEventToApprovalUsingToolID(ctx context.Context, sessionID string, toolID string, approvalID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, e := range m.events {
+		if e.SessionID == sessionID && e.ToolID == toolID && e.EventType == EventTypeToolCall &&
+			!e.IsCompleted && e.ApprovalStatus == "" {
+			e.ApprovalStatus = ApprovalStatusPending
+			e.ApprovalID = approvalID
+		}
+	}
+	return nil
+}
+
+// UpdateApprovalStatus updates the approval status of correlated conversation events
+func (m *MemoryStore) UpdateApprovalStatus(ctx context.Context, approvalID string, status string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, e := range m.events {
+		if e.ApprovalID != approvalID {
+			continue
+		}
+		// Don't overwrite approved/denied with a generic resolution
+		if status == ApprovalStatusResolved && e.ApprovalStatus != ApprovalStatusPending {
+			continue
+		}
+		e.ApprovalStatus = status
+	}
+	return nil
+}
+
+// StoreMCPServers stores MCP server configurations
+func (m *MemoryStore) StoreMCPServers(ctx context.Context, sessionID string, servers []MCPServer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, server := range servers {
+		m.nextMCPID++
+		server.ID = m.nextMCPID
+		server.SessionID = sessionID
+		m.mcpServers[sessionID] = append(m.mcpServers[sessionID], server)
+	}
+	return nil
+}
+
+// GetMCPServers retrieves MCP servers for a session
+func (m *MemoryStore) GetMCPServers(ctx context.Context, sessionID string) ([]MCPServer, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return append([]MCPServer(nil), m.mcpServers[sessionID]...), nil
+}
+
+// StoreRawEvent stores a raw event for debugging
+func (m *MemoryStore) StoreRawEvent(ctx context.Context, sessionID string, eventJSON string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.rawEvents[sessionID] = append(m.rawEvents[sessionID], eventJSON)
+	return nil
+}
+
+// CreateApproval creates a new approval
+func (m *MemoryStore) CreateApproval(ctx context.Context, approval *Approval) error {
+	if !approval.Status.IsValid() {
+		return fmt.Errorf("invalid approval status: %s", approval.Status)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.approvals[approval.ID]; exists {
+		return fmt.Errorf("failed to create approval: approval %s already exists", approval.ID)
+	}
+	copied := *approval
+	m.approvals[approval.ID] = &copied
+	return nil
+}
+
+// GetApproval retrieves an approval by ID
+func (m *MemoryStore) GetApproval(ctx context.Context, id string) (*Approval, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	a, ok := m.approvals[id]
+	if !ok {
+		return nil, &NotFoundError{Type: "approval", ID: id}
+	}
+	copied := *a
+	return &copied, nil
+}
+
+// GetPendingApprovals retrieves all pending approvals for a session, oldest first
+func (m *MemoryStore) GetPendingApprovals(ctx context.Context, sessionID string) ([]*Approval, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var approvals []*Approval
+	for _, a := range m.approvals {
+		if a.SessionID == sessionID && a.Status == ApprovalStatusLocalPending {
+			copied := *a
+			approvals = append(approvals, &copied)
+		}
+	}
+	sort.SliceStable(approvals, func(i, j int) bool { return approvals[i].CreatedAt.Before(approvals[j].CreatedAt) })
+	return approvals, nil
+}
+
+// ListSessionApprovals retrieves a session's approvals whatever their
+// status, oldest first
+func (m *MemoryStore) ListSessionApprovals(ctx context.Context, sessionID string) ([]*Approval, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var approvals []*Approval
+	for _, a := range m.approvals {
+		if a.SessionID == sessionID {
+			copied := *a
+			approvals = append(approvals, &copied)
+		}
+	}
+	sort.SliceStable(approvals, func(i, j int) bool { return approvals[i].CreatedAt.Before(approvals[j].CreatedAt) })
+	return approvals, nil
+}
+
+// ListPendingApprovals retrieves the pending approvals of every session,
+// oldest first
+func (m *MemoryStore) ListPendingApprovals(ctx context.Context) ([]*Approval, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var approvals []*Approval
+	for _, a := range m.approvals {
+		if a.Status == ApprovalStatusLocalPending {
+			copied := *a
+			approvals = append(approvals, &copied)
+		}
+	}
+	sort.SliceStable(approvals, func(i, j int) bool { return approvals[i].CreatedAt.Before(approvals[j].CreatedAt) })
+	return approvals, nil
+}
+
+// UpdateApprovalResponse updates the status and comment of a pending approval
+func (m *MemoryStore) UpdateApprovalResponse(ctx context.Context, id string, status ApprovalStatus, comment string) error {
+	if !status.IsValid() {
+		return fmt.Errorf("invalid approval status: %s", status)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	a, ok := m.approvals[id]
+	if !ok {
+		return &NotFoundError{Type: "approval", ID: id}
+	}
+	if a.Status != ApprovalStatusLocalPending {
+		return &AlreadyDecidedError{ID: id, Status: a.Status.String()}
+	}
+
+	now := time.Now()
+	a.Status = status
+	a.Comment = comment
+	a.RespondedAt = &now
+	return nil
+}
+
+// SetApprovalCommentExpansion stores AI-written guidance expanding a pending
+// approval's denial comment
+func (m *MemoryStore) SetApprovalCommentExpansion(ctx context.Context, id string, expansion string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	a, ok := m.approvals[id]
+	if !ok {
+		return &NotFoundError{Type: "approval", ID: id}
+	}
+	a.CommentExpansion = expansion
+	return nil
+}
+
+// SetApprovalConstraints limits what a pending approval allows once it's
+// approved
+func (m *MemoryStore) SetApprovalConstraints(ctx context.Context, id string, constraints *ApprovalConstraints) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	a, ok := m.approvals[id]
+	if !ok {
+		return &NotFoundError{Type: "approval", ID: id}
+	}
+	if a.Status != ApprovalStatusLocalPending {
+		return &AlreadyDecidedError{ID: id, Status: a.Status.String()}
+	}
+	a.Constraints = constraints
+	return nil
+}
+
+// StoreApprovalImages stores image paths for an approval decision
+func (m *MemoryStore) StoreApprovalImages(ctx context.Context, approvalID string, imagePaths []string) error {
+	if len(imagePaths) == 0 {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.approvalImages[approvalID] = append(m.approvalImages[approvalID], imagePaths...)
+	return nil
+}
+
+// StoreToolResult records the output of an executed tool call, linking it to
+// the approval for the same tool_use_id when none is given
+func (m *MemoryStore) StoreToolResult(ctx context.Context, result *ToolResult) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if result.CreatedAt.IsZero() {
+		result.CreatedAt = time.Now()
+	}
+	if result.ApprovalID == "" {
+		var latest *Approval
+		for _, a := range m.approvals {
+			if a.SessionID == result.SessionID && a.ToolUseID != nil && *a.ToolUseID == result.ToolUseID {
+				if latest == nil || a.CreatedAt.After(latest.CreatedAt) {
+					latest = a
+				}
+			}
+		}
+		if latest != nil {
+			result.ApprovalID = latest.ID
+		}
+	}
+
+	m.nextResultID++
+	result.ID = m.nextResultID
+	copied := *result
+	m.toolResults = append(m.toolResults, &copied)
+	return nil
+}
+
+// GetToolResults retrieves captured tool results for a session in execution order
+func (m *MemoryStore) GetToolResults(ctx context.Context, sessionID string) ([]*ToolResult, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var results []*ToolResult
+	for _, r := range m.toolResults {
+		if r.SessionID == sessionID {
+			copied := *r
+			results = append(results, &copied)
+		}
+	}
+	return results, nil
+}
+
+// StoreShadowDecision records a shadow policy decision, replacing any earlier one for the approval
+func (m *MemoryStore) StoreShadowDecision(ctx context.Context, decision *ShadowDecision) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if decision.CreatedAt.IsZero() {
+		decision.CreatedAt = time.Now()
+	}
+	copied := *decision
+	m.shadow[decision.ApprovalID] = &copied
+	return nil
+}
+
+// ListShadowDecisions retrieves shadow decisions oldest first, for one session or all of them
+func (m *MemoryStore) ListShadowDecisions(ctx context.Context, sessionID string) ([]*ShadowDecision, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var decisions []*ShadowDecision
+	for _, d := range m.shadow {
+		if sessionID == "" || d.SessionID == sessionID {
+			copied := *d
+			decisions = append(decisions, &copied)
+		}
+	}
+	sort.Slice(decisions, func(i, j int) bool {
+		if !decisions[i].CreatedAt.Equal(decisions[j].CreatedAt) {
+			return decisions[i].CreatedAt.Before(decisions[j].CreatedAt)
+		}
+		return decisions[i].ApprovalID < decisions[j].ApprovalID
+	})
+	return decisions, nil
+}
+
+// CreateJob records a new job
+func (m *MemoryStore) CreateJob(ctx context.Context, job *Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.jobs[job.ID]; exists {
+		return fmt.Errorf("failed to create job: job %s already exists", job.ID)
+	}
+	if job.CreatedAt.IsZero() {
+		job.CreatedAt = time.Now()
+	}
+	copied := *job
+	m.jobs[job.ID] = &copied
+	return nil
+}
+
+// UpdateJob saves a job's status, result, error and timestamps
+func (m *MemoryStore) UpdateJob(ctx context.Context, job *Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.jobs[job.ID]
+	if !ok {
+		return &NotFoundError{Type: "job", ID: job.ID}
+	}
+	existing.Status = job.Status
+	existing.Result = job.Result
+	existing.Error = job.Error
+	existing.StartedAt = job.StartedAt
+	existing.CompletedAt = job.CompletedAt
+	return nil
+}
+
+// GetJob retrieves a job by ID
+func (m *MemoryStore) GetJob(ctx context.Context, id string) (*Job, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, &NotFoundError{Type: "job", ID: id}
+	}
+	copied := *job
+	return &copied, nil
+}
+
+// ListJobs retrieves jobs newest first, optionally only those with status
+func (m *MemoryStore) ListJobs(ctx context.Context, status string) ([]*Job, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var jobs []*Job
+	for _, job := range m.jobs {
+		if status == "" || job.Status == status {
+			copied := *job
+			jobs = append(jobs, &copied)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.After(jobs[j].CreatedAt)
+	})
+	return jobs, nil
+}
+
+// CreateExperiment records a new experiment
+func (m *MemoryStore) CreateExperiment(ctx context.Context, experiment *Experiment) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.experiments[experiment.ID]; exists {
+		return fmt.Errorf("failed to create experiment: experiment %s already exists", experiment.ID)
+	}
+	if experiment.CreatedAt.IsZero() {
+		experiment.CreatedAt = time.Now()
+	}
+	copied := *experiment
+	m.experiments[experiment.ID] = &copied
+	return nil
+}
+
+// GetExperiment retrieves an experiment by ID
+func (m *MemoryStore) GetExperiment(ctx context.Context, id string) (*Experiment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	experiment, ok := m.experiments[id]
+	if !ok {
+		return nil, &NotFoundError{Type: "experiment", ID: id}
+	}
+	copied := *experiment
+	return &copied, nil
+}
+
+// ListExperiments retrieves experiments newest first
+func (m *MemoryStore) ListExperiments(ctx context.Context) ([]*Experiment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	experiments := make([]*Experiment, 0, len(m.experiments))
+	for _, experiment := range m.experiments {
+		copied := *experiment
+		experiments = append(experiments, &copied)
+	}
+	sort.Slice(experiments, func(i, j int) bool {
+		return experiments[i].CreatedAt.After(experiments[j].CreatedAt)
+	})
+	return experiments, nil
+}
+
+// CreateExperimentRun records a session launched for an experiment
+func (m *MemoryStore) CreateExperimentRun(ctx context.Context, run *ExperimentRun) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.experimentRuns[run.SessionID]; exists {
+		return fmt.Errorf("failed to create experiment run: session %s already has a run", run.SessionID)
+	}
+	copied := *run
+	m.experimentRuns[run.SessionID] = &copied
+	return nil
+}
+
+// UpdateExperimentRun saves a run's outcome
+func (m *MemoryStore) UpdateExperimentRun(ctx context.Context, run *ExperimentRun) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.experimentRuns[run.SessionID]
+	if !ok {
+		return &NotFoundError{Type: "experiment run", ID: run.SessionID}
+	}
+	existing.FilesChanged = run.FilesChanged
+	existing.LinesAdded = run.LinesAdded
+	existing.LinesRemoved = run.LinesRemoved
+	existing.TestsPassed = run.TestsPassed
+	existing.TestOutput = run.TestOutput
+	existing.EvaluatedAt = run.EvaluatedAt
+	return nil
+}
+
+// GetExperimentRun retrieves the experiment run for a session
+func (m *MemoryStore) GetExperimentRun(ctx context.Context, sessionID string) (*ExperimentRun, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	run, ok := m.experimentRuns[sessionID]
+	if !ok {
+		return nil, &NotFoundError{Type: "experiment run", ID: sessionID}
+	}
+	copied := *run
+	return &copied, nil
+}
+
+// ListExperimentRuns retrieves an experiment's runs in variant order
+func (m *MemoryStore) ListExperimentRuns(ctx context.Context, experimentID string) ([]*ExperimentRun, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var runs []*ExperimentRun
+	for _, run := range m.experimentRuns {
+		if run.ExperimentID == experimentID {
+			copied := *run
+			runs = append(runs, &copied)
+		}
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		if runs[i].Variant != runs[j].Variant {
+			return runs[i].Variant < runs[j].Variant
+		}
+		return runs[i].Worktree < runs[j].Worktree
+	})
+	return runs, nil
+}
+
+// CreateEventAnnotation attaches a note or bookmark to a conversation event
+func (m *MemoryStore) CreateEventAnnotation(ctx context.Context, annotation *EventAnnotation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	found := false
+	for _, e := range m.events {
+		if e.ID == annotation.EventID && e.SessionID == annotation.SessionID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return &NotFoundError{Type: "conversation event", ID: fmt.Sprint(annotation.EventID)}
+	}
+	if annotation.CreatedAt.IsZero() {
+		annotation.CreatedAt = time.Now()
+	}
+	m.nextAnnotation++
+	annotation.ID = m.nextAnnotation
+	copied := *annotation
+	m.annotations = append(m.annotations, &copied)
+	return nil
+}
+
+// ListEventAnnotations retrieves annotations matching filter, oldest first
+func (m *MemoryStore) ListEventAnnotations(ctx context.Context, filter AnnotationFilter) ([]*EventAnnotation, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var annotations []*EventAnnotation
+	for _, a := range m.annotations {
+		if (filter.SessionID != "" && a.SessionID != filter.SessionID) ||
+			(filter.EventID != 0 && a.EventID != filter.EventID) ||
+			(filter.Kind != "" && a.Kind != filter.Kind) ||
+			(filter.Text != "" && !strings.Contains(strings.ToLower(a.Note), strings.ToLower(filter.Text))) {
+			continue
+		}
+		copied := *a
+		annotations = append(annotations, &copied)
+		if filter.Limit > 0 && len(annotations) == filter.Limit {
+			break
+		}
+	}
+	return annotations, nil
+}
+
+// DeleteEventAnnotation removes an annotation
+func (m *MemoryStore) DeleteEventAnnotation(ctx context.Context, id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, a := range m.annotations {
+		if a.ID == id {
+			m.annotations = append(m.annotations[:i], m.annotations[i+1:]...)
+			return nil
+		}
+	}
+	return &NotFoundError{Type: "event annotation", ID: fmt.Sprint(id)}
+}
+
+// CreateAIOutput records a model's answer
+func (m *MemoryStore) CreateAIOutput(ctx context.Context, output *AIOutput) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.aiOutputs {
+		if existing.ID == output.ID {
+			return fmt.Errorf("failed to create AI output: output %s already exists", output.ID)
+		}
+	}
+	if output.CreatedAt.IsZero() {
+		output.CreatedAt = time.Now()
+	}
+	copied := *output
+	m.aiOutputs = append(m.aiOutputs, &copied)
+	return nil
+}
+
+// GetAIOutput retrieves an AI output by ID
+func (m *MemoryStore) GetAIOutput(ctx context.Context, id string) (*AIOutput, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, output := range m.aiOutputs {
+		if output.ID == id {
+			copied := *output
+			return &copied, nil
+		}
+	}
+	return nil, &NotFoundError{Type: "AI output", ID: id}
+}
+
+// SetAIOutputFeedback replaces an output's rating and comment
+func (m *MemoryStore) SetAIOutputFeedback(ctx context.Context, id, rating, comment string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, output := range m.aiOutputs {
+		if output.ID == id {
+			now := time.Now()
+			output.Rating, output.Comment, output.RatedAt = rating, comment, &now
+			return nil
+		}
+	}
+	return &NotFoundError{Type: "AI output", ID: id}
+}
+
+// ListAIOutputs retrieves outputs matching filter, oldest first
+func (m *MemoryStore) ListAIOutputs(ctx context.Context, filter AIOutputFilter) ([]*AIOutput, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var outputs []*AIOutput
+	for _, output := range m.aiOutputs {
+		if (filter.Kind != "" && output.Kind != filter.Kind) ||
+			(filter.Template != "" && output.Template != filter.Template) ||
+			(!filter.Since.IsZero() && output.CreatedAt.Before(filter.Since)) ||
+			(filter.Rated && output.Rating == "") {
+			continue
+		}
+		copied := *output
+		outputs = append(outputs, &copied)
+	}
+	sort.SliceStable(outputs, func(i, j int) bool { return outputs[i].CreatedAt.Before(outputs[j].CreatedAt) })
+	return outputs, nil
+}
+
+// ReplaceSessionEmbeddings swaps a session's embeddings for the given ones
+func (m *MemoryStore) ReplaceSessionEmbeddings(ctx context.Context, sessionID string, embeddings []*SessionEmbedding) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	kept := m.embeddings[:0]
+	for _, e := range m.embeddings {
+		if e.SessionID != sessionID {
+			kept = append(kept, e)
+		}
+	}
+	m.embeddings = kept
+	now := time.Now()
+	for _, embedding := range embeddings {
+		m.nextEmbedding++
+		embedding.ID = m.nextEmbedding
+		embedding.SessionID = sessionID
+		if embedding.CreatedAt.IsZero() {
+			embedding.CreatedAt = now
+		}
+		copied := *embedding
+		copied.Vector = append([]float32(nil), embedding.Vector...)
+		m.embeddings = append(m.embeddings, &copied)
+	}
+	return nil
+}
+
+// ListSessionEmbeddings retrieves every embedding made with model
+func (m *MemoryStore) ListSessionEmbeddings(ctx context.Context, model string) ([]*SessionEmbedding, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var embeddings []*SessionEmbedding
+	for _, e := range m.embeddings {
+		if e.Model == model {
+			copied := *e
+			copied.Vector = append([]float32(nil), e.Vector...)
+			embeddings = append(embeddings, &copied)
+		}
+	}
+	return embeddings, nil
+}
+
+func copyDecision(decision *Decision) *Decision {
+	copied := *decision
+	copied.Alternatives = append([]string(nil), decision.Alternatives...)
+	return &copied
+}
+
+// CreateDecision adds a decision to the log
+func (m *MemoryStore) CreateDecision(ctx context.Context, decision *Decision) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.decisions[decision.ID]; exists {
+		return fmt.Errorf("failed to create decision: decision %s already exists", decision.ID)
+	}
+	if decision.CreatedAt.IsZero() {
+		decision.CreatedAt = time.Now()
+	}
+	decision.UpdatedAt = decision.CreatedAt
+	m.decisions[decision.ID] = copyDecision(decision)
+	return nil
+}
+
+// GetDecision retrieves a decision by ID
+func (m *MemoryStore) GetDecision(ctx context.Context, id string) (*Decision, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	decision, ok := m.decisions[id]
+	if !ok {
+		return nil, &NotFoundError{Type: "decision", ID: id}
+	}
+	return copyDecision(decision), nil
+}
+
+// UpdateDecision saves a decision's editable fields
+func (m *MemoryStore) UpdateDecision(ctx context.Context, decision *Decision) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.decisions[decision.ID]
+	if !ok {
+		return &NotFoundError{Type: "decision", ID: decision.ID}
+	}
+	decision.UpdatedAt = time.Now()
+	updated := copyDecision(decision)
+	updated.SessionID, updated.CreatedAt = existing.SessionID, existing.CreatedAt
+	m.decisions[decision.ID] = updated
+	return nil
+}
+
+// DeleteDecision removes a decision from the log
+func (m *MemoryStore) DeleteDecision(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.decisions[id]; !ok {
+		return &NotFoundError{Type: "decision", ID: id}
+	}
+	delete(m.decisions, id)
+	return nil
+}
+
+// ListDecisions retrieves decisions matching filter, newest first
+func (m *MemoryStore) ListDecisions(ctx context.Context, filter DecisionFilter) ([]*Decision, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var decisions []*Decision
+	for _, decision := range m.decisions {
+		if (filter.Repository != "" && decision.Repository != filter.Repository) ||
+			(filter.SessionID != "" && decision.SessionID != filter.SessionID) ||
+			(filter.Status != "" && decision.Status != filter.Status) {
+			continue
+		}
+		decisions = append(decisions, copyDecision(decision))
+	}
+	sort.Slice(decisions, func(i, j int) bool {
+		if !decisions[i].CreatedAt.Equal(decisions[j].CreatedAt) {
+			return decisions[i].CreatedAt.After(decisions[j].CreatedAt)
+		}
+		return decisions[i].ID < decisions[j].ID
+	})
+	if filter.Limit > 0 && len(decisions) > filter.Limit {
+		decisions = decisions[:filter.Limit]
+	}
+	return decisions, nil
+}
+
+// CreateContextPack stores an assembled context pack
+func (m *MemoryStore) CreateContextPack(ctx context.Context, pack *ContextPack) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.contextPacks[pack.ID]; exists {
+		return fmt.Errorf("failed to create context pack: pack %s already exists", pack.ID)
+	}
+	if pack.CreatedAt.IsZero() {
+		pack.CreatedAt = time.Now()
+	}
+	copied := *pack
+	copied.Content = append([]byte(nil), pack.Content...)
+	m.contextPacks[pack.ID] = &copied
+	return nil
+}
+
+// GetContextPack retrieves a context pack by ID
+func (m *MemoryStore) GetContextPack(ctx context.Context, id string) (*ContextPack, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	pack, ok := m.contextPacks[id]
+	if !ok {
+		return nil, &NotFoundError{Type: "context pack", ID: id}
+	}
+	copied := *pack
+	copied.Content = append([]byte(nil), pack.Content...)
+	return &copied, nil
+}
+
+// UpsertRemoteCredential stores a credential, replacing one for the same prefix
+func (m *MemoryStore) UpsertRemoteCredential(ctx context.Context, credential *RemoteCredential) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	copied := *credential
+	copied.Secret = append([]byte(nil), credential.Secret...)
+	copied.CreatedAt, copied.UpdatedAt = now, now
+	if existing, ok := m.credentials[credential.URLPrefix]; ok {
+		copied.CreatedAt = existing.CreatedAt
+	}
+	m.credentials[credential.URLPrefix] = &copied
+	credential.CreatedAt, credential.UpdatedAt = copied.CreatedAt, copied.UpdatedAt
+	return nil
+}
+
+// ListRemoteCredentials returns every credential by URL prefix
+func (m *MemoryStore) ListRemoteCredentials(ctx context.Context) ([]*RemoteCredential, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	credentials := make([]*RemoteCredential, 0, len(m.credentials))
+	for _, credential := range m.credentials {
+		copied := *credential
+		copied.Secret = append([]byte(nil), credential.Secret...)
+		credentials = append(credentials, &copied)
+	}
+	sort.Slice(credentials, func(i, j int) bool { return credentials[i].URLPrefix < credentials[j].URLPrefix })
+	return credentials, nil
+}
+
+// DeleteRemoteCredential removes the credential for urlPrefix
+func (m *MemoryStore) DeleteRemoteCredential(ctx context.Context, urlPrefix string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.credentials[urlPrefix]; !ok {
+		return &NotFoundError{Type: "remote credential", ID: urlPrefix}
+	}
+	delete(m.credentials, urlPrefix)
+	return nil
+}
+
+// CreateArtifact records an artifact whose content is already stored
+func (m *MemoryStore) CreateArtifact(ctx context.Context, artifact *Artifact) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if artifact.CreatedAt.IsZero() {
+		artifact.CreatedAt = time.Now()
+	}
+	copied := *artifact
+	m.artifacts[artifact.ID] = &copied
+	return nil
+}
+
+// GetArtifact returns an artifact by ID
+func (m *MemoryStore) GetArtifact(ctx context.Context, id string) (*Artifact, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	artifact, ok := m.artifacts[id]
+	if !ok {
+		return nil, &NotFoundError{Type: "artifact", ID: id}
+	}
+	copied := *artifact
+	return &copied, nil
+}
+
+func (m *MemoryStore) filterArtifacts(keep func(*Artifact) bool) []*Artifact {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	artifacts := []*Artifact{}
+	for _, artifact := range m.artifacts {
+		if keep(artifact) {
+			copied := *artifact
+			artifacts = append(artifacts, &copied)
+		}
+	}
+	sort.Slice(artifacts, func(i, j int) bool {
+		if !artifacts[i].CreatedAt.Equal(artifacts[j].CreatedAt) {
+			return artifacts[i].CreatedAt.Before(artifacts[j].CreatedAt)
+		}
+		return artifacts[i].ID < artifacts[j].ID
+	})
+	return artifacts
+}
+
+// ListSessionArtifacts returns a session's artifacts, oldest first
+func (m *MemoryStore) ListSessionArtifacts(ctx context.Context, sessionID string) ([]*Artifact, error) {
+	return m.filterArtifacts(func(a *Artifact) bool { return a.SessionID == sessionID }), nil
+}
+
+// ListExpiredArtifacts returns artifacts whose retention ended before a time
+func (m *MemoryStore) ListExpiredArtifacts(ctx context.Context, before time.Time) ([]*Artifact, error) {
+	return m.filterArtifacts(func(a *Artifact) bool { return a.ExpiresAt != nil && a.ExpiresAt.Before(before) }), nil
+}
+
+// DeleteArtifact removes an artifact's record
+func (m *MemoryStore) DeleteArtifact(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.artifacts[id]; !ok {
+		return &NotFoundError{Type: "artifact", ID: id}
+	}
+	delete(m.artifacts, id)
+	return nil
+}
+
+// SaveWebPushSubscription records a subscription, replacing any with the
+// same endpoint
+func (m *MemoryStore) SaveWebPushSubscription(ctx context.Context, sub *WebPushSubscription) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, existing := range m.pushSubs {
+		if existing.Endpoint == sub.Endpoint {
+			sub.ID = id
+			sub.CreatedAt = existing.CreatedAt
+		}
+	}
+	if sub.CreatedAt.IsZero() {
+		sub.CreatedAt = time.Now()
+	}
+	copied := *sub
+	m.pushSubs[sub.ID] = &copied
+	return nil
+}
+
+// ListWebPushSubscriptions returns every subscription, oldest first
+func (m *MemoryStore) ListWebPushSubscriptions(ctx context.Context) ([]*WebPushSubscription, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	subs := []*WebPushSubscription{}
+	for _, sub := range m.pushSubs {
+		copied := *sub
+		subs = append(subs, &copied)
+	}
+	sort.Slice(subs, func(i, j int) bool {
+		if !subs[i].CreatedAt.Equal(subs[j].CreatedAt) {
+			return subs[i].CreatedAt.Before(subs[j].CreatedAt)
+		}
+		return subs[i].ID < subs[j].ID
+	})
+	return subs, nil
+}
+
+// DeleteWebPushSubscription removes a subscription
+func (m *MemoryStore) DeleteWebPushSubscription(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.pushSubs[id]; !ok {
+		return &NotFoundError{Type: "web push subscription", ID: id}
+	}
+	delete(m.pushSubs, id)
+	return nil
+}
+
+// watchKey identifies a user's watch of a session
+type watchKey struct{ user, sessionID string }
+
+// WatchSessions makes a user watch sessions, keeping existing watches
+func (m *MemoryStore) WatchSessions(ctx context.Context, user string, sessionIDs []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for _, id := range sessionIDs {
+		key := watchKey{user, id}
+		if _, ok := m.watches[key]; !ok {
+			m.watches[key] = &SessionWatch{User: user, SessionID: id, CreatedAt: now}
+		}
+	}
+	return nil
+}
+
+// UnwatchSessions stops a user watching sessions, or every session when
+// sessionIDs is empty
+func (m *MemoryStore) UnwatchSessions(ctx context.Context, user string, sessionIDs []string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	removed := 0
+	for key := range m.watches {
+		if key.user == user && (len(sessionIDs) == 0 || slices.Contains(sessionIDs, key.sessionID)) {
+			delete(m.watches, key)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// ListWatches returns the sessions a user watches, oldest watch first
+func (m *MemoryStore) ListWatches(ctx context.Context, user string) ([]*SessionWatch, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	watches := []*SessionWatch{}
+	for key, watch := range m.watches {
+		if key.user == user {
+			copied := *watch
+			watches = append(watches, &copied)
+		}
+	}
+	sort.Slice(watches, func(i, j int) bool {
+		if !watches[i].CreatedAt.Equal(watches[j].CreatedAt) {
+			return watches[i].CreatedAt.Before(watches[j].CreatedAt)
+		}
+		return watches[i].SessionID < watches[j].SessionID
+	})
+	return watches, nil
+}
+
+// ListSessionWatchers returns the users watching a session, sorted
+func (m *MemoryStore) ListSessionWatchers(ctx context.Context, sessionID string) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	users := []string{}
+	for key := range m.watches {
+		if key.sessionID == sessionID {
+			users = append(users, key.user)
+		}
+	}
+	sort.Strings(users)
+	return users, nil
+}
+
+// CreateCannedResponse records a canned response
+func (m *MemoryStore) CreateCannedResponse(ctx context.Context, response *CannedResponse) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	response.CreatedAt, response.UpdatedAt = now, now
+	copied := *response
+	m.canned[response.ID] = &copied
+	return nil
+}
+
+// GetCannedResponse retrieves a canned response
+func (m *MemoryStore) GetCannedResponse(ctx context.Context, id string) (*CannedResponse, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	response, ok := m.canned[id]
+	if !ok {
+		return nil, &NotFoundError{Type: "canned response", ID: id}
+	}
+	copied := *response
+	return &copied, nil
+}
+
+// ListCannedResponses returns every canned response, most used first
+func (m *MemoryStore) ListCannedResponses(ctx context.Context) ([]*CannedResponse, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	responses := []*CannedResponse{}
+	for _, response := range m.canned {
+		copied := *response
+		responses = append(responses, &copied)
+	}
+	sort.Slice(responses, func(i, j int) bool {
+		if responses[i].UseCount != responses[j].UseCount {
+			return responses[i].UseCount > responses[j].UseCount
+		}
+		return responses[i].Title < responses[j].Title
+	})
+	return responses, nil
+}
+
+// UpdateCannedResponse changes a canned response's team, title and text
+func (m *MemoryStore) UpdateCannedResponse(ctx context.Context, response *CannedResponse) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.canned[response.ID]
+	if !ok {
+		return &NotFoundError{Type: "canned response", ID: response.ID}
+	}
+	existing.Team, existing.Title, existing.Text = response.Team, response.Title, response.Text
+	existing.UpdatedAt = time.Now()
+	*response = *existing
+	return nil
+}
+
+// DeleteCannedResponse removes a canned response
+func (m *MemoryStore) DeleteCannedResponse(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.canned[id]; !ok {
+		return &NotFoundError{Type: "canned response", ID: id}
+	}
+	delete(m.canned, id)
+	return nil
+}
+
+// RecordCannedResponseUse counts a decision made with a canned response
+func (m *MemoryStore) RecordCannedResponseUse(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	response, ok := m.canned[id]
+	if !ok {
+		return &NotFoundError{Type: "canned response", ID: id}
+	}
+	now := time.Now()
+	response.UseCount++
+	response.LastUsedAt = &now
+	return nil
+}
+
+// inboxKey identifies a user's state for an approval
+type inboxKey struct{ user, approvalID string }
+
+func (m *MemoryStore) inboxState(user, approvalID string) *InboxState {
+	key := inboxKey{user, approvalID}
+	state, ok := m.inbox[key]
+	if !ok {
+		state = &InboxState{User: user, ApprovalID: approvalID}
+		m.inbox[key] = state
+	}
+	return state
+}
+
+// MarkInboxRead marks approvals read or unread for a user
+func (m *MemoryStore) MarkInboxRead(ctx context.Context, user string, approvalIDs []string, read bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for _, id := range approvalIDs {
+		state := m.inboxState(user, id)
+		if !read {
+			state.ReadAt = nil
+		} else if state.ReadAt == nil {
+			state.ReadAt = &now
+		}
+	}
+	return nil
+}
+
+// SnoozeInboxItem hides an approval from a user until a time, or brings it
+// back when until is nil
+func (m *MemoryStore) SnoozeInboxItem(ctx context.Context, user, approvalID string, until *time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.inboxState(user, approvalID).SnoozedUntil = until
+	return nil
+}
+
+// ListInboxStates returns a user's state for each approval they have read
+// or snoozed
+func (m *MemoryStore) ListInboxStates(ctx context.Context, user string) ([]*InboxState, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	states := []*InboxState{}
+	for key, state := range m.inbox {
+		if key.user == user {
+			copied := *state
+			states = append(states, &copied)
+		}
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].ApprovalID < states[j].ApprovalID })
+	return states, nil
+}
+
+// ListDueSnoozes returns snoozes ending at or before a time
+func (m *MemoryStore) ListDueSnoozes(ctx context.Context, before time.Time) ([]*InboxState, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	states := []*InboxState{}
+	for _, state := range m.inbox {
+		if state.SnoozedUntil != nil && !state.SnoozedUntil.After(before) {
+			copied := *state
+			states = append(states, &copied)
+		}
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].SnoozedUntil.Before(*states[j].SnoozedUntil) })
+	return states, nil
+}
+
+// HoldApproval parks an approval, replacing any earlier hold
+func (m *MemoryStore) HoldApproval(ctx context.Context, hold *ApprovalHold) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	copied := *hold
+	m.holds[hold.ApprovalID] = &copied
+	return nil
+}
+
+// GetApprovalHold retrieves the hold on an approval
+func (m *MemoryStore) GetApprovalHold(ctx context.Context, approvalID string) (*ApprovalHold, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	hold, ok := m.holds[approvalID]
+	if !ok {
+		return nil, &NotFoundError{Type: "approval hold", ID: approvalID}
+	}
+	copied := *hold
+	return &copied, nil
+}
+
+// ListApprovalHolds returns every hold, oldest first
+func (m *MemoryStore) ListApprovalHolds(ctx context.Context) ([]*ApprovalHold, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	holds := make([]*ApprovalHold, 0, len(m.holds))
+	for _, hold := range m.holds {
+		copied := *hold
+		holds = append(holds, &copied)
+	}
+	sort.Slice(holds, func(i, j int) bool {
+		if !holds[i].CreatedAt.Equal(holds[j].CreatedAt) {
+			return holds[i].CreatedAt.Before(holds[j].CreatedAt)
+		}
+		return holds[i].ApprovalID < holds[j].ApprovalID
+	})
+	return holds, nil
+}
+
+// ReleaseApprovalHold unparks an approval, returning false if it wasn't held
+func (m *MemoryStore) ReleaseApprovalHold(ctx context.Context, approvalID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.holds[approvalID]
+	delete(m.holds, approvalID)
+	return ok, nil
+}
+
+// RecordConstraintViolation stores a tool call that broke its approval's
+// constraints
+func (m *MemoryStore) RecordConstraintViolation(ctx context.Context, violation *ConstraintViolation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if violation.CreatedAt.IsZero() {
+		violation.CreatedAt = time.Now()
+	}
+	violation.ID = int64(len(m.violations) + 1)
+	copied := *violation
+	m.violations = append(m.violations, &copied)
+	return nil
+}
+
+// ListConstraintViolations returns a session's violations, or every
+// violation for an empty session ID, oldest first
+func (m *MemoryStore) ListConstraintViolations(ctx context.Context, sessionID string) ([]*ConstraintViolation, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	violations := []*ConstraintViolation{}
+	for _, v := range m.violations {
+		if sessionID == "" || v.SessionID == sessionID {
+			copied := *v
+			violations = append(violations, &copied)
+		}
+	}
+	return violations, nil
+}
+
+// RecordInjectionDetection stores a suspected prompt injection
+func (m *MemoryStore) RecordInjectionDetection(ctx context.Context, detection *InjectionDetection) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if detection.CreatedAt.IsZero() {
+		detection.CreatedAt = time.Now()
+	}
+	detection.ID = int64(len(m.injections) + 1)
+	copied := *detection
+	m.injections = append(m.injections, &copied)
+	return nil
+}
+
+// ListInjectionDetections returns a session's detections, or every
+// detection for an empty session ID, oldest first
+func (m *MemoryStore) ListInjectionDetections(ctx context.Context, sessionID string) ([]*InjectionDetection, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	detections := []*InjectionDetection{}
+	for _, d := range m.injections {
+		if sessionID == "" || d.SessionID == sessionID {
+			copied := *d
+			detections = append(detections, &copied)
+		}
+	}
+	return detections, nil
+}
+
+// RecordModerationBlock stores text the content safety filter blocked
+func (m *MemoryStore) RecordModerationBlock(ctx context.Context, block *ModerationBlock) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if block.CreatedAt.IsZero() {
+		block.CreatedAt = time.Now()
+	}
+	block.ID = int64(len(m.moderation) + 1)
+	copied := *block
+	m.moderation = append(m.moderation, &copied)
+	return nil
+}
+
+// ListModerationBlocks returns blocks oldest first, only those not yet
+// reviewed when unreviewed is set
+func (m *MemoryStore) ListModerationBlocks(ctx context.Context, unreviewed bool) ([]*ModerationBlock, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	blocks := []*ModerationBlock{}
+	for _, b := range m.moderation {
+		if !unreviewed || b.ReviewedAt == nil {
+			copied := *b
+			blocks = append(blocks, &copied)
+		}
+	}
+	return blocks, nil
+}
+
+// ReviewModerationBlock marks a block reviewed with an optional note
+func (m *MemoryStore) ReviewModerationBlock(ctx context.Context, id int64, reviewer, note string) (*ModerationBlock, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, b := range m.moderation {
+		if b.ID == id {
+			now := time.Now()
+			b.ReviewedAt = &now
+			b.Reviewer = reviewer
+			b.ReviewNote = note
+			copied := *b
+			return &copied, nil
+		}
+	}
+	return nil, &NotFoundError{Type: "moderation block", ID: strconv.FormatInt(id, 10)}
+}
+
+// UseResolutionNonce marks a nonce used until it expires
+func (m *MemoryStore) UseResolutionNonce(ctx context.Context, nonce, approvalID string, expiresAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	for n, expires := range m.nonces {
+		if expires.Before(now) {
+			delete(m.nonces, n)
+		}
+	}
+	if _, ok := m.nonces[nonce]; ok {
+		return ErrNonceUsed
+	}
+	if m.nonces == nil {
+		m.nonces = make(map[string]time.Time)
+	}
+	m.nonces[nonce] = expiresAt
+	return nil
+}
+
+// ReleaseResolutionNonce lets a nonce be used again
+func (m *MemoryStore) ReleaseResolutionNonce(ctx context.Context, nonce string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.nonces, nonce)
+	return nil
+}
+
+// RecordRejectedResolution stores a refused approval resolution
+func (m *MemoryStore) RecordRejectedResolution(ctx context.Context, rejection *RejectedResolution) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if rejection.CreatedAt.IsZero() {
+		rejection.CreatedAt = time.Now()
+	}
+	rejection.ID = int64(len(m.rejections) + 1)
+	copied := *rejection
+	m.rejections = append(m.rejections, &copied)
+	return nil
+}
+
+// ListRejectedResolutions returns an approval's rejections, or every
+// rejection for an empty approval ID, oldest first
+func (m *MemoryStore) ListRejectedResolutions(ctx context.Context, approvalID string) ([]*RejectedResolution, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	rejections := []*RejectedResolution{}
+	for _, r := range m.rejections {
+		if approvalID == "" || r.ApprovalID == approvalID {
+			copied := *r
+			rejections = append(rejections, &copied)
+		}
+	}
+	return rejections, nil
+}
+
+// CreateShareLink records a share link
+func (m *MemoryStore) CreateShareLink(ctx context.Context, link *ShareLink) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.shareLinks[link.ID]; ok {
+		return fmt.Errorf("failed to create share link: %s already exists", link.ID)
+	}
+	if link.CreatedAt.IsZero() {
+		link.CreatedAt = time.Now()
+	}
+	copied := *link
+	m.shareLinks[link.ID] = &copied
+	return nil
+}
+
+// GetShareLink returns a share link by ID
+func (m *MemoryStore) GetShareLink(ctx context.Context, id string) (*ShareLink, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	link, ok := m.shareLinks[id]
+	if !ok {
+		return nil, &NotFoundError{Type: "share link", ID: id}
+	}
+	copied := *link
+	return &copied, nil
+}
+
+// ListShareLinks returns a session's links, oldest first
+func (m *MemoryStore) ListShareLinks(ctx context.Context, sessionID string) ([]*ShareLink, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	links := []*ShareLink{}
+	for _, link := range m.shareLinks {
+		if link.SessionID == sessionID {
+			copied := *link
+			links = append(links, &copied)
+		}
+	}
+	sort.Slice(links, func(i, j int) bool {
+		if !links[i].CreatedAt.Equal(links[j].CreatedAt) {
+			return links[i].CreatedAt.Before(links[j].CreatedAt)
+		}
+		return links[i].ID < links[j].ID
+	})
+	return links, nil
+}
+
+// RevokeShareLink stops a link working
+func (m *MemoryStore) RevokeShareLink(ctx context.Context, id string) (*ShareLink, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	link, ok := m.shareLinks[id]
+	if !ok {
+		return nil, &NotFoundError{Type: "share link", ID: id}
+	}
+	if link.RevokedAt == nil {
+		now := time.Now()
+		link.RevokedAt = &now
+	}
+	copied := *link
+	return &copied, nil
+}
+
+// CreateSessionWorktree records the worktree a session works in
+func (m *MemoryStore) CreateSessionWorktree(ctx context.Context, worktree *SessionWorktree) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.worktrees[worktree.SessionID]; ok {
+		return fmt.Errorf("failed to create session worktree: session %s already has one", worktree.SessionID)
+	}
+	if worktree.CreatedAt.IsZero() {
+		worktree.CreatedAt = time.Now()
+	}
+	copied := *worktree
+	m.worktrees[worktree.SessionID] = &copied
+	return nil
+}
+
+// GetSessionWorktree returns a session's worktree
+func (m *MemoryStore) GetSessionWorktree(ctx context.Context, sessionID string) (*SessionWorktree, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	worktree, ok := m.worktrees[sessionID]
+	if !ok {
+		return nil, &NotFoundError{Type: "session worktree", ID: sessionID}
+	}
+	copied := *worktree
+	return &copied, nil
+}
+
+// ListActiveSessionWorktrees returns the worktrees not yet removed, oldest first
+func (m *MemoryStore) ListActiveSessionWorktrees(ctx context.Context) ([]*SessionWorktree, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	worktrees := []*SessionWorktree{}
+	for _, worktree := range m.worktrees {
+		if worktree.RemovedAt == nil {
+			copied := *worktree
+			worktrees = append(worktrees, &copied)
+		}
+	}
+	sort.Slice(worktrees, func(i, j int) bool {
+		if !worktrees[i].CreatedAt.Equal(worktrees[j].CreatedAt) {
+			return worktrees[i].CreatedAt.Before(worktrees[j].CreatedAt)
+		}
+		return worktrees[i].SessionID < worktrees[j].SessionID
+	})
+	return worktrees, nil
+}
+
+// MarkSessionWorktreeRemoved sets removed_at once
+func (m *MemoryStore) MarkSessionWorktreeRemoved(ctx context.Context, sessionID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	worktree, ok := m.worktrees[sessionID]
+	if !ok || worktree.RemovedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	worktree.RemovedAt = &now
+	return true, nil
+}
+
+// CreateCloudMirror records an approval mirrored to HumanLayer cloud
+func (m *MemoryStore) CreateCloudMirror(ctx context.Context, mirror *CloudMirror) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.cloudMirrors[mirror.ApprovalID]; ok {
+		return fmt.Errorf("failed to create cloud mirror: approval %s is already mirrored", mirror.ApprovalID)
+	}
+	if mirror.State == "" {
+		mirror.State = CloudMirrorOpen
+	}
+	copied := *mirror
+	m.cloudMirrors[mirror.ApprovalID] = &copied
+	return nil
+}
+
+// GetCloudMirror retrieves the mirror of an approval
+func (m *MemoryStore) GetCloudMirror(ctx context.Context, approvalID string) (*CloudMirror, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	mirror, ok := m.cloudMirrors[approvalID]
+	if !ok {
+		return nil, &NotFoundError{Type: "cloud mirror", ID: approvalID}
+	}
+	copied := *mirror
+	return &copied, nil
+}
+
+// ListOpenCloudMirrors returns the mirrors not yet settled, oldest first
+func (m *MemoryStore) ListOpenCloudMirrors(ctx context.Context) ([]*CloudMirror, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	mirrors := []*CloudMirror{}
+	for _, mirror := range m.cloudMirrors {
+		if mirror.State == CloudMirrorOpen {
+			copied := *mirror
+			mirrors = append(mirrors, &copied)
+		}
+	}
+	sort.Slice(mirrors, func(i, j int) bool {
+		if !mirrors[i].CreatedAt.Equal(mirrors[j].CreatedAt) {
+			return mirrors[i].CreatedAt.Before(mirrors[j].CreatedAt)
+		}
+		return mirrors[i].ApprovalID < mirrors[j].ApprovalID
+	})
+	return mirrors, nil
+}
+
+// SettleCloudMirror records how an open mirror ended, returning false if it
+// was already settled
+func (m *MemoryStore) SettleCloudMirror(ctx context.Context, approvalID, state string, remoteApproved *bool, remoteComment string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mirror, ok := m.cloudMirrors[approvalID]
+	if !ok || mirror.State != CloudMirrorOpen {
+		return false, nil
+	}
+	now := time.Now()
+	mirror.State = state
+	mirror.RemoteApproved = remoteApproved
+	mirror.RemoteComment = remoteComment
+	mirror.SettledAt = &now
+	return true, nil
+}
+
+// CreateGitHubTrigger records the webhook delivery that launched a session
+func (m *MemoryStore) CreateGitHubTrigger(ctx context.Context, trigger *GitHubTrigger) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.githubTriggers {
+		if existing.DeliveryID == trigger.DeliveryID {
+			return fmt.Errorf("failed to create github trigger: delivery %s already exists", trigger.DeliveryID)
+		}
+	}
+	if trigger.CreatedAt.IsZero() {
+		trigger.CreatedAt = time.Now()
+	}
+	copied := *trigger
+	m.githubTriggers[trigger.SessionID] = &copied
+	return nil
+}
+
+// GetGitHubTriggerBySession returns the trigger that launched a session
+func (m *MemoryStore) GetGitHubTriggerBySession(ctx context.Context, sessionID string) (*GitHubTrigger, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	trigger, ok := m.githubTriggers[sessionID]
+	if !ok {
+		return nil, &NotFoundError{Type: "github trigger", ID: sessionID}
+	}
+	copied := *trigger
+	if trigger.ReportedAt != nil {
+		reportedAt := *trigger.ReportedAt
+		copied.ReportedAt = &reportedAt
+	}
+	return &copied, nil
+}
+
+// MarkGitHubTriggerReported sets reported_at once
+func (m *MemoryStore) MarkGitHubTriggerReported(ctx context.Context, sessionID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	trigger, ok := m.githubTriggers[sessionID]
+	if !ok || trigger.ReportedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	trigger.ReportedAt = &now
+	return true, nil
+}
+
+// CreateSessionTicket links a ticket to a session
+func (m *MemoryStore) CreateSessionTicket(ctx context.Context, ticket *SessionTicket) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.sessionTickets[ticket.SessionID] {
+		if existing.Tracker == ticket.Tracker && existing.Ref == ticket.Ref {
+			return nil
+		}
+	}
+	if ticket.CreatedAt.IsZero() {
+		ticket.CreatedAt = time.Now()
+	}
+	copied := *ticket
+	m.sessionTickets[ticket.SessionID] = append(m.sessionTickets[ticket.SessionID], &copied)
+	return nil
+}
+
+// ListSessionTickets returns the tickets linked to a session
+func (m *MemoryStore) ListSessionTickets(ctx context.Context, sessionID string) ([]*SessionTicket, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var tickets []*SessionTicket
+	for _, ticket := range m.sessionTickets[sessionID] {
+		copied := *ticket
+		if ticket.SyncedAt != nil {
+			syncedAt := *ticket.SyncedAt
+			copied.SyncedAt = &syncedAt
+		}
+		tickets = append(tickets, &copied)
+	}
+	return tickets, nil
+}
+
+// MarkSessionTicketSynced records the status a ticket was moved to
+func (m *MemoryStore) MarkSessionTicketSynced(ctx context.Context, sessionID, tracker, ref, status string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, ticket := range m.sessionTickets[sessionID] {
+		if ticket.Tracker == tracker && ticket.Ref == ref {
+			now := time.Now()
+			ticket.SyncedStatus = status
+			ticket.SyncedAt = &now
+			return nil
+		}
+	}
+	return &NotFoundError{Type: "session ticket", ID: sessionID + "/" + tracker + "/" + ref}
+}
+
+// CreateSessionCommit records a commit the daemon made
+func (m *MemoryStore) CreateSessionCommit(ctx context.Context, commit *SessionCommit) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.commits {
+		if existing.SessionID == commit.SessionID && existing.Hash == commit.Hash {
+			return nil
+		}
+	}
+	if commit.CreatedAt.IsZero() {
+		commit.CreatedAt = time.Now()
+	}
+	copied := *commit
+	m.commits = append(m.commits, &copied)
+	return nil
+}
+
+// ListSessionCommits returns the commits made since a time, oldest first
+func (m *MemoryStore) ListSessionCommits(ctx context.Context, since time.Time) ([]*SessionCommit, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var commits []*SessionCommit
+	for _, commit := range m.commits {
+		if !commit.CreatedAt.Before(since) {
+			copied := *commit
+			commits = append(commits, &copied)
+		}
+	}
+	sort.SliceStable(commits, func(i, j int) bool { return commits[i].CreatedAt.Before(commits[j].CreatedAt) })
+	return commits, nil
+}
+
+// CountApprovals counts approvals created since a time by session and status
+func (m *MemoryStore) CountApprovals(ctx context.Context, since time.Time) ([]ApprovalCount, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	type key struct {
+		sessionID string
+		status    ApprovalStatus
+	}
+	counts := make(map[key]int)
+	for _, approval := range m.approvals {
+		if !approval.CreatedAt.Before(since) {
+			counts[key{approval.SessionID, approval.Status}]++
+		}
+	}
+	result := make([]ApprovalCount, 0, len(counts))
+	for k, n := range counts {
+		result = append(result, ApprovalCount{SessionID: k.sessionID, Status: k.status, Count: n})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].SessionID != result[j].SessionID {
+			return result[i].SessionID < result[j].SessionID
+		}
+		return result[i].Status < result[j].Status
+	})
+	return result, nil
+}
+
+// CountPendingApprovals returns how many approvals each of sessionIDs has
+// pending, leaving out sessions without any
+func (m *MemoryStore) CountPendingApprovals(ctx context.Context, sessionIDs []string) (map[string]int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	wanted := make(map[string]bool, len(sessionIDs))
+	for _, id := range sessionIDs {
+		wanted[id] = true
+	}
+	counts := make(map[string]int)
+	for _, approval := range m.approvals {
+		if wanted[approval.SessionID] && approval.Status == ApprovalStatusLocalPending {
+			counts[approval.SessionID]++
+		}
+	}
+	return counts, nil
+}
+
+func copyEscalation(escalation *ApprovalEscalation) *ApprovalEscalation {
+	copied := *escalation
+	if escalation.TriggeredAt != nil {
+		triggeredAt := *escalation.TriggeredAt
+		copied.TriggeredAt = &triggeredAt
+	}
+	if escalation.ResolvedAt != nil {
+		resolvedAt := *escalation.ResolvedAt
+		copied.ResolvedAt = &resolvedAt
+	}
+	return &copied
+}
+
+// CreateApprovalEscalation records a critical approval
+func (m *MemoryStore) CreateApprovalEscalation(ctx context.Context, escalation *ApprovalEscalation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.escalations[escalation.ApprovalID]; exists {
+		return fmt.Errorf("failed to create approval escalation: %s already exists", escalation.ApprovalID)
+	}
+	if escalation.CreatedAt.IsZero() {
+		escalation.CreatedAt = time.Now()
+	}
+	m.escalations[escalation.ApprovalID] = copyEscalation(escalation)
+	return nil
+}
+
+// GetApprovalEscalation returns the escalation for an approval
+func (m *MemoryStore) GetApprovalEscalation(ctx context.Context, approvalID string) (*ApprovalEscalation, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	escalation, ok := m.escalations[approvalID]
+	if !ok {
+		return nil, &NotFoundError{Type: "approval escalation", ID: approvalID}
+	}
+	return copyEscalation(escalation), nil
+}
+
+// ListOpenApprovalEscalations returns the escalations not yet resolved, oldest first
+func (m *MemoryStore) ListOpenApprovalEscalations(ctx context.Context) ([]*ApprovalEscalation, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var open []*ApprovalEscalation
+	for _, escalation := range m.escalations {
+		if escalation.ResolvedAt == nil {
+			open = append(open, copyEscalation(escalation))
+		}
+	}
+	sort.Slice(open, func(i, j int) bool { return open[i].CreatedAt.Before(open[j].CreatedAt) })
+	return open, nil
+}
+
+// MarkApprovalEscalationTriggered sets triggered_at once
+func (m *MemoryStore) MarkApprovalEscalationTriggered(ctx context.Context, approvalID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	escalation, ok := m.escalations[approvalID]
+	if !ok || escalation.TriggeredAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	escalation.TriggeredAt = &now
+	return true, nil
+}
+
+// MarkApprovalEscalationResolved sets resolved_at once
+func (m *MemoryStore) MarkApprovalEscalationResolved(ctx context.Context, approvalID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	escalation, ok := m.escalations[approvalID]
+	if !ok || escalation.ResolvedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	escalation.ResolvedAt = &now
+	return true, nil
+}
+
+func copyProposal(proposal *MemoryFileProposal) *MemoryFileProposal {
+	copied := *proposal
+	copied.SessionIDs = append([]string(nil), proposal.SessionIDs...)
+	if proposal.ResolvedAt != nil {
+		resolvedAt := *proposal.ResolvedAt
+		copied.ResolvedAt = &resolvedAt
+	}
+	return &copied
+}
+
+// CreateMemoryFileProposal stores a proposed memory file update
+func (m *MemoryStore) CreateMemoryFileProposal(ctx context.Context, proposal *MemoryFileProposal) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.proposals[proposal.ID]; exists {
+		return fmt.Errorf("failed to create memory file proposal: proposal %s already exists", proposal.ID)
+	}
+	if proposal.CreatedAt.IsZero() {
+		proposal.CreatedAt = time.Now()
+	}
+	m.proposals[proposal.ID] = copyProposal(proposal)
+	return nil
+}
+
+// GetMemoryFileProposal retrieves a proposal by ID
+func (m *MemoryStore) GetMemoryFileProposal(ctx context.Context, id string) (*MemoryFileProposal, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	proposal, ok := m.proposals[id]
+	if !ok {
+		return nil, &NotFoundError{Type: "memory file proposal", ID: id}
+	}
+	return copyProposal(proposal), nil
+}
+
+// ResolveMemoryFileProposal records that a pending proposal was applied or rejected
+func (m *MemoryStore) ResolveMemoryFileProposal(ctx context.Context, id, status, commitHash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	proposal, ok := m.proposals[id]
+	if !ok || proposal.Status != ProposalStatusPending {
+		return &NotFoundError{Type: "pending memory file proposal", ID: id}
+	}
+	now := time.Now()
+	proposal.Status, proposal.CommitHash, proposal.ResolvedAt = status, commitHash, &now
+	return nil
+}
+
+// ListMemoryFileProposals retrieves proposals newest first
+func (m *MemoryStore) ListMemoryFileProposals(ctx context.Context, repository, status string) ([]*MemoryFileProposal, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var proposals []*MemoryFileProposal
+	for _, proposal := range m.proposals {
+		if (repository != "" && proposal.Repository != repository) || (status != "" && proposal.Status != status) {
+			continue
+		}
+		proposals = append(proposals, copyProposal(proposal))
+	}
+	sort.Slice(proposals, func(i, j int) bool {
+		if !proposals[i].CreatedAt.Equal(proposals[j].CreatedAt) {
+			return proposals[i].CreatedAt.After(proposals[j].CreatedAt)
+		}
+		return proposals[i].ID < proposals[j].ID
+	})
+	return proposals, nil
+}
+
+// CreateFileSnapshot stores a new file snapshot
+func (m *MemoryStore) CreateFileSnapshot(ctx context.Context, snapshot *FileSnapshot) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextSnapshotID++
+	copied := *snapshot
+	copied.ID = m.nextSnapshotID
+	if copied.CreatedAt.IsZero() {
+		copied.CreatedAt = time.Now()
+	}
+	m.snapshots = append(m.snapshots, copied)
+	return nil
+}
+
+// GetFileSnapshots retrieves all snapshots for a session, newest first
+func (m *MemoryStore) GetFileSnapshots(ctx context.Context, sessionID string) ([]FileSnapshot, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var snapshots []FileSnapshot
+	for i := len(m.snapshots) - 1; i >= 0; i-- {
+		if m.snapshots[i].SessionID == sessionID {
+			snapshots = append(snapshots, m.snapshots[i])
+		}
+	}
+	return snapshots, nil
+}
+
+// GetRecentWorkingDirs returns working directories ordered by most recent use
+func (m *MemoryStore) GetRecentWorkingDirs(ctx context.Context, limit int) ([]RecentPath, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	byPath := make(map[string]*RecentPath)
+	for _, s := range m.sessions {
+		if s.WorkingDir == "" || s.WorkingDir == "." {
+			continue
+		}
+		p, ok := byPath[s.WorkingDir]
+		if !ok {
+			p = &RecentPath{Path: s.WorkingDir}
+			byPath[s.WorkingDir] = p
+		}
+		p.UsageCount++
+		if s.LastActivityAt.After(p.LastUsed) {
+			p.LastUsed = s.LastActivityAt
+		}
+	}
+
+	paths := make([]RecentPath, 0, len(byPath))
+	for _, p := range byPath {
+		paths = append(paths, *p)
+	}
+	sort.Slice(paths, func(i, j int) bool { return paths[i].LastUsed.After(paths[j].LastUsed) })
+	if len(paths) > limit {
+		paths = paths[:limit]
+	}
+	return paths, nil
+}
+
+// GetUserSettings retrieves the user settings
+func (m *MemoryStore) GetUserSettings(ctx context.Context) (*UserSettings, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	settings := m.userSettings
+	return &settings, nil
+}
+
+// UpdateUserSettings updates the user settings
+func (m *MemoryStore) UpdateUserSettings(ctx context.Context, settings UserSettings) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.userSettings.AdvancedProviders = settings.AdvancedProviders
+	m.userSettings.OptInTelemetry = settings.OptInTelemetry
+	m.userSettings.UpdatedAt = time.Now()
+	return nil
+}
+
+// Close is a no-op for the in-memory store
+func (m *MemoryStore) Close() error {
+	return nil
+}
+
+// sortedSessions returns copies of the sessions matching keep, most recently active first.
+// Callers must hold m.mu.
+func (m *MemoryStore) sortedSessions(keep func(*Session) bool) []*Session {
+	var sessions []*Session
+	for _, s := range m.sessions {
+		if keep(s) {
+			copied := *s
+			sessions = append(sessions, &copied)
+		}
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].LastActivityAt.After(sessions[j].LastActivityAt)
+	})
+	return sessions
+}
+
+// filterEvents returns copies of the events matching keep. Callers must hold m.mu.
+func (m *MemoryStore) filterEvents(keep func(*ConversationEvent) bool) []*ConversationEvent {
+	var events []*ConversationEvent
+	for _, e := range m.events {
+		if keep(e) {
+			copied := *e
+			events = append(events, &copied)
+		}
+	}
+	return events
+}
+
+// latestToolCall returns a copy of the highest-sequence tool call matching keep.
+// Callers must hold m.mu.
+func (m *MemoryStore) latestToolCall(keep func(*ConversationEvent) bool) *ConversationEvent {
+	var latest *ConversationEvent
+	for _, e := range m.events {
+		if e.EventType == EventTypeToolCall && keep(e) && (latest == nil || e.Sequence > latest.Sequence) {
+			latest = e
+		}
+	}
+	if latest == nil {
+		return nil
+	}
+	copied := *latest
+	return &copied
+}
+
+func sortBySequence(events []*ConversationEvent) {
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Sequence < events[j].Sequence })
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+// RecordSessionProcess creates or replaces a session's process record
+func (m *MemoryStore) RecordSessionProcess(ctx context.Context, process *SessionProcess) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	copied := *process
+	m.processes[process.SessionID] = &copied
+	return nil
+}
+
+// GetSessionProcess retrieves a session's process record
+func (m *MemoryStore) GetSessionProcess(ctx context.Context, sessionID string) (*SessionProcess, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	process, ok := m.processes[sessionID]
+	if !ok {
+		return nil, &NotFoundError{Type: "session process", ID: sessionID}
+	}
+	copied := *process
+	return &copied, nil
+}
+
+// Compile-time check that MemoryStore implements every store
+var _ Store = (*MemoryStore)(nil)
@@ -0,0 +1,108 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMemoryStoreMatchesSQLite runs the same flow against both stores
+func TestMemoryStoreMatchesSQLite(t *testing.T) {
+	sqliteStore, err := NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sqliteStore.Close() })
+
+	stores := map[string]Store{
+		"memory": NewInMemoryStore(),
+		"sqlite": sqliteStore,
+	}
+
+	for name, s := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			now := time.Now()
+
+			parent := &Session{ID: "parent", RunID: "run-parent", ClaudeSessionID: "claude-1", Query: "fix the bug",
+				Status: SessionStatusCompleted, CreatedAt: now, LastActivityAt: now, WorkingDir: "/repo"}
+			child := &Session{ID: "child", RunID: "run-child", ParentSessionID: "parent", Query: "and add a test",
+				Status: SessionStatusRunning, CreatedAt: now, LastActivityAt: now.Add(time.Minute), WorkingDir: "/repo"}
+			require.NoError(t, s.CreateSession(ctx, parent))
+			require.NoError(t, s.CreateSession(ctx, child))
+
+			// Updates and lookups
+			claudeID := "claude-2"
+			title := "Bug fix"
+			require.NoError(t, s.UpdateSession(ctx, "child", SessionUpdate{ClaudeSessionID: &claudeID, Title: &title}))
+			require.Error(t, s.UpdateSession(ctx, "missing", SessionUpdate{Title: &title}))
+
+			got, err := s.GetSessionByRunID(ctx, "run-child")
+			require.NoError(t, err)
+			assert.Equal(t, "Bug fix", got.Title)
+			missing, err := s.GetSessionByRunID(ctx, "run-missing")
+			require.NoError(t, err)
+			assert.Nil(t, missing)
+
+			sessions, err := s.ListSessions(ctx)
+			require.NoError(t, err)
+			require.Len(t, sessions, 2)
+			assert.Equal(t, "child", sessions[0].ID)
+
+			found, err := s.SearchSessionsByTitle(ctx, "bug", 10)
+			require.NoError(t, err)
+			require.Len(t, found, 1, "only leaf sessions are searched")
+			assert.Equal(t, "child", found[0].ID)
+
+			// Conversation events across the parent chain
+			require.NoError(t, s.AddConversationEvent(ctx, &ConversationEvent{SessionID: "parent", ClaudeSessionID: "claude-1",
+				EventType: EventTypeMessage, Role: "user", Content: "fix the bug"}))
+			require.NoError(t, s.AddConversationEvent(ctx, &ConversationEvent{SessionID: "child", ClaudeSessionID: "claude-2",
+				EventType: EventTypeToolCall, ToolID: "toolu_1", ToolName: "Bash", ToolInputJSON: `{}`}))
+
+			events, err := s.GetSessionConversation(ctx, "child")
+			require.NoError(t, err)
+			require.Len(t, events, 2)
+			assert.Equal(t, "fix the bug", events[0].Content)
+			assert.Equal(t, 1, events[1].Sequence)
+
+			// Approval lifecycle
+			toolUseID := "toolu_1"
+			approval := &Approval{ID: "local-1", RunID: "run-child", SessionID: "child", ToolUseID: &toolUseID,
+				Status: ApprovalStatusLocalPending, CreatedAt: now, ToolName: "Bash", ToolInput: json.RawMessage(`{}`)}
+			require.NoError(t, s.CreateApproval(ctx, approval))
+			require.NoError(t, s.LinkConversationEventToApprovalUsingToolID(ctx, "child", "toolu_1", "local-1"))
+
+			pending, err := s.GetPendingApprovals(ctx, "child")
+			require.NoError(t, err)
+			require.Len(t, pending, 1)
+
+			require.NoError(t, s.UpdateApprovalResponse(ctx, "local-1", ApprovalStatusLocalApproved, "ok"))
+			var decided *AlreadyDecidedError
+			assert.ErrorAs(t, s.UpdateApprovalResponse(ctx, "local-1", ApprovalStatusLocalDenied, "no"), &decided)
+			require.NoError(t, s.UpdateApprovalStatus(ctx, "local-1", ApprovalStatusApproved))
+
+			call, err := s.GetToolCallByID(ctx, "toolu_1")
+			require.NoError(t, err)
+			assert.Equal(t, "local-1", call.ApprovalID)
+			assert.Equal(t, ApprovalStatusApproved, call.ApprovalStatus)
+
+			_, err = s.GetApproval(ctx, "local-missing")
+			var notFound *NotFoundError
+			assert.ErrorAs(t, err, &notFound)
+
+			// Tool results link back to the approval
+			result := &ToolResult{SessionID: "child", ToolUseID: "toolu_1", ToolName: "Bash", Content: "ok", Source: ToolResultSourceReported}
+			require.NoError(t, s.StoreToolResult(ctx, result))
+			assert.Equal(t, "local-1", result.ApprovalID)
+
+			dirs, err := s.GetRecentWorkingDirs(ctx, 10)
+			require.NoError(t, err)
+			require.Len(t, dirs, 1)
+			assert.Equal(t, 2, dirs[0].UsageCount)
+		})
+	}
+}
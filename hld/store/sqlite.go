@@ -16,11 +16,13 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 )
 
-// SQLiteStore implements ConversationStore using SQLite
+// SQLiteStore implements Store using SQLite
 type SQLiteStore struct {
 	db *sql.DB
 }
 
+var _ Store = (*SQLiteStore)(nil)
+
 // GetDB returns the underlying database connection for testing purposes
 func (s *SQLiteStore) GetDB() *sql.DB {
 	return s.db
@@ -960,265 +962,1487 @@ func (s *SQLiteStore) applyMigrations() error {
 		// Record migration
 		_, err = s.db.Exec(`
 			INSERT INTO schema_version (version, description)
-			VALUES (18, 'Healing migration to fix schema inconsistencies from migration reordering')
+			VALUES (18, 'Healing migration to fix schema inconsistencies from migration reordering')
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to record migration 18: %w", err)
+		}
+
+		slog.Info("Migration 18 applied successfully")
+	}
+
+	// Migration 19: Add opt_in_telemetry column for error reporting consent
+	if currentVersion < 19 {
+		slog.Info("Applying migration 19: Add opt_in_telemetry column for error reporting consent")
+
+		// Check if opt_in_telemetry column already exists for idempotency
+		var optInTelemetryExists int
+		err := s.db.QueryRow(`
+			SELECT COUNT(*) FROM pragma_table_info('user_settings')
+			WHERE name = 'opt_in_telemetry'
+		`).Scan(&optInTelemetryExists)
+		if err != nil {
+			return fmt.Errorf("failed to check opt_in_telemetry column: %w", err)
+		}
+
+		if optInTelemetryExists == 0 {
+			// Add opt_in_telemetry column with NULL default (unset state)
+			_, err := s.db.Exec(`
+				ALTER TABLE user_settings
+				ADD COLUMN opt_in_telemetry BOOLEAN DEFAULT NULL
+			`)
+			if err != nil {
+				return fmt.Errorf("failed to add opt_in_telemetry column: %w", err)
+			}
+		}
+
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (?, ?)
+		`, 19, "Add opt_in_telemetry column for error reporting consent")
+		if err != nil {
+			return fmt.Errorf("failed to record migration 19: %w", err)
+		}
+
+		slog.Info("Migration 19 applied successfully")
+	}
+
+	// Migration 20: Add editor_state column for draft sessions
+	if currentVersion < 20 {
+		slog.Info("Applying migration 20: Adding editor_state column to sessions table")
+
+		// Add editor_state column to sessions table
+		_, err := s.db.Exec(`
+			ALTER TABLE sessions ADD COLUMN editor_state TEXT
+		`)
+		if err != nil {
+			// Check if column already exists (for idempotency)
+			var columnCount int
+			err = s.db.QueryRow(`
+				SELECT COUNT(*) FROM pragma_table_info('sessions')
+				WHERE name = 'editor_state'
+			`).Scan(&columnCount)
+			if err != nil {
+				return fmt.Errorf("failed to check for editor_state column: %w", err)
+			}
+			if columnCount == 0 {
+				return fmt.Errorf("failed to add editor_state column: %w", err)
+			}
+		}
+
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (?, ?)
+		`, 20, "Add editor_state column for draft session editor persistence")
+		if err != nil {
+			return fmt.Errorf("failed to record migration 20: %w", err)
+		}
+
+		slog.Info("Migration 20 applied successfully")
+	}
+
+	// Migration 21: Add dangerously_skip_permissions_timeout_ms column to store timeout duration
+	if currentVersion < 21 {
+		slog.Info("Applying migration 21: Adding dangerously_skip_permissions_timeout_ms column to sessions table")
+
+		// Check if column already exists for idempotency
+		var columnExists int
+		err := s.db.QueryRow(`
+			SELECT COUNT(*) FROM pragma_table_info('sessions')
+			WHERE name = 'dangerously_skip_permissions_timeout_ms'
+		`).Scan(&columnExists)
+		if err != nil {
+			return fmt.Errorf("failed to check dangerously_skip_permissions_timeout_ms column: %w", err)
+		}
+
+		if columnExists == 0 {
+			// Add column to sessions table
+			_, err = s.db.Exec(`
+				ALTER TABLE sessions
+				ADD COLUMN dangerously_skip_permissions_timeout_ms INTEGER
+			`)
+			if err != nil {
+				return fmt.Errorf("failed to add dangerously_skip_permissions_timeout_ms column: %w", err)
+			}
+			slog.Info("Added dangerously_skip_permissions_timeout_ms column")
+		} else {
+			slog.Info("dangerously_skip_permissions_timeout_ms column already exists")
+		}
+
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (21, 'Add dangerously_skip_permissions_timeout_ms column to store bypass permissions timeout duration')
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to record migration 21: %w", err)
+		}
+
+		slog.Info("Migration 21 applied successfully")
+	}
+
+	// Migration 22: Add indexes for session search performance
+	if currentVersion < 22 {
+		slog.Info("Applying migration 22: Add indexes for session search performance")
+
+		// Add index on title for LIKE queries
+		// Using a regular B-tree index which helps with LIKE 'prefix%' patterns
+		_, err := s.db.Exec(`
+			CREATE INDEX IF NOT EXISTS idx_sessions_title
+			ON sessions(title)
+		`)
+		if err != nil {
+			return fmt.Errorf("migration 22 failed to create title index: %w", err)
+		}
+
+		// Also add index on last_activity_at if not exists
+		// This helps with ORDER BY performance
+		_, err = s.db.Exec(`
+			CREATE INDEX IF NOT EXISTS idx_sessions_last_activity
+			ON sessions(last_activity_at DESC)
+		`)
+		if err != nil {
+			return fmt.Errorf("migration 22 failed to create last_activity index: %w", err)
+		}
+
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (22, 'Add indexes for session search performance')
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to record migration 22: %w", err)
+		}
+
+		slog.Info("Migration 22 applied successfully")
+	}
+
+	// Migration 23: Add approval_images table and image_paths column to approvals
+	if currentVersion < 23 {
+		slog.Info("Applying migration 23: Add approval_images table and image_paths column")
+
+		// Create approval_images table
+		_, err := s.db.Exec(`
+			CREATE TABLE IF NOT EXISTS approval_images (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				approval_id TEXT NOT NULL,
+				file_path TEXT NOT NULL,
+				mime_type TEXT NOT NULL,
+				size_bytes INTEGER NOT NULL,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (approval_id) REFERENCES approvals(id) ON DELETE CASCADE
+			)
+		`)
+		if err != nil {
+			return fmt.Errorf("migration 23 failed to create approval_images table: %w", err)
+		}
+
+		// Add index on approval_id for faster lookups
+		_, err = s.db.Exec(`
+			CREATE INDEX IF NOT EXISTS idx_approval_images_approval_id
+			ON approval_images(approval_id)
+		`)
+		if err != nil {
+			return fmt.Errorf("migration 23 failed to create approval_images index: %w", err)
+		}
+
+		// Add image_paths column to approvals table (JSON array of file paths)
+		// Check if column already exists first
+		var exists int
+		err = s.db.QueryRow(`
+			SELECT COUNT(*) FROM pragma_table_info('approvals') WHERE name = 'image_paths'
+		`).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("migration 23 failed to check image_paths column: %w", err)
+		}
+
+		if exists == 0 {
+			_, err = s.db.Exec(`ALTER TABLE approvals ADD COLUMN image_paths TEXT`)
+			if err != nil {
+				return fmt.Errorf("migration 23 failed to add image_paths column: %w", err)
+			}
+		}
+
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (23, 'Add approval_images table and image_paths column')
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to record migration 23: %w", err)
+		}
+
+		slog.Info("Migration 23 applied successfully")
+	}
+
+	// Migration 24: Add reviewed field to sessions table for marking sessions as checked
+	if currentVersion < 24 {
+		slog.Info("Applying migration 24: Add reviewed field to sessions table")
+
+		// Check if column already exists
+		var columnExists int
+		err = s.db.QueryRow(`
+			SELECT COUNT(*) FROM pragma_table_info('sessions')
+			WHERE name = 'reviewed'
+		`).Scan(&columnExists)
+		if err != nil {
+			return fmt.Errorf("failed to check reviewed column: %w", err)
+		}
+
+		// Only add column if it doesn't exist
+		if columnExists == 0 {
+			_, err = s.db.Exec(`
+				ALTER TABLE sessions
+				ADD COLUMN reviewed BOOLEAN DEFAULT FALSE
+			`)
+			if err != nil {
+				return fmt.Errorf("failed to add reviewed column: %w", err)
+			}
+		}
+
+		// Add index for efficient filtering
+		_, err = s.db.Exec(`
+			CREATE INDEX IF NOT EXISTS idx_sessions_reviewed
+			ON sessions(reviewed)
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to create reviewed index: %w", err)
+		}
+
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (24, 'Add reviewed field to sessions table for marking sessions as checked')
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to record migration 24: %w", err)
+		}
+
+		slog.Info("Migration 24 applied successfully")
+	}
+
+	// Migration 25: Add tool_results table for executed tool output capture
+	if currentVersion < 25 {
+		slog.Info("Applying migration 25: Add tool_results table for executed tool output capture")
+
+		_, err = s.db.Exec(`
+			CREATE TABLE IF NOT EXISTS tool_results (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				session_id TEXT NOT NULL,
+				tool_use_id TEXT NOT NULL,
+				approval_id TEXT,
+				tool_name TEXT,
+				content TEXT NOT NULL,
+				is_error BOOLEAN DEFAULT FALSE,
+				source TEXT NOT NULL, -- 'report_tool_result' or 'mcp_downstream'
+				created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+				FOREIGN KEY (session_id) REFERENCES sessions(id)
+			);
+			CREATE INDEX IF NOT EXISTS idx_tool_results_session ON tool_results(session_id, created_at);
+			CREATE INDEX IF NOT EXISTS idx_tool_results_approval ON tool_results(approval_id);
+		`)
+		if err != nil {
+			return fmt.Errorf("migration 25 failed: %w", err)
+		}
+
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (25, 'Add tool_results table for executed tool output capture')
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to record migration 25: %w", err)
+		}
+
+		slog.Info("Migration 25 applied successfully")
+	}
+
+	// Migration 26: Add auto-deny configuration to sessions
+	if currentVersion < 26 {
+		slog.Info("Applying migration 26: Adding auto-deny columns to sessions table")
+
+		columns := []struct {
+			name       string
+			definition string
+		}{
+			{"auto_deny_all", "auto_deny_all BOOLEAN DEFAULT 0"},
+			{"auto_deny_tools", "auto_deny_tools TEXT"},
+		}
+		for _, col := range columns {
+			var columnCount int
+			err = s.db.QueryRow(`
+				SELECT COUNT(*) FROM pragma_table_info('sessions')
+				WHERE name = ?
+			`, col.name).Scan(&columnCount)
+			if err != nil {
+				return fmt.Errorf("failed to check for %s column: %w", col.name, err)
+			}
+			if columnCount > 0 {
+				continue
+			}
+			if _, err = s.db.Exec("ALTER TABLE sessions ADD COLUMN " + col.definition); err != nil {
+				return fmt.Errorf("failed to add %s column: %w", col.name, err)
+			}
+		}
+
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (?, ?)
+		`, 26, "Add auto_deny_all and auto_deny_tools columns to sessions")
+		if err != nil {
+			return fmt.Errorf("failed to record migration 26: %w", err)
+		}
+
+		slog.Info("Migration 26 applied successfully")
+	}
+
+	// Migration 27: Add approval_shadow_decisions table for shadow policy evaluation
+	if currentVersion < 27 {
+		slog.Info("Applying migration 27: Add approval_shadow_decisions table for shadow policy evaluation")
+
+		_, err = s.db.Exec(`
+			CREATE TABLE IF NOT EXISTS approval_shadow_decisions (
+				approval_id TEXT PRIMARY KEY,
+				session_id TEXT NOT NULL,
+				tool_name TEXT NOT NULL,
+				action TEXT,
+				rule TEXT,
+				reason TEXT,
+				error TEXT,
+				created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (approval_id) REFERENCES approvals(id)
+			);
+			CREATE INDEX IF NOT EXISTS idx_shadow_decisions_session ON approval_shadow_decisions(session_id, created_at);
+		`)
+		if err != nil {
+			return fmt.Errorf("migration 27 failed: %w", err)
+		}
+
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (27, 'Add approval_shadow_decisions table for shadow policy evaluation')
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to record migration 27: %w", err)
+		}
+
+		slog.Info("Migration 27 applied successfully")
+	}
+
+	// Migration 28: Add session resource limits
+	if currentVersion < 28 {
+		slog.Info("Applying migration 28: Adding budget column to sessions table")
+
+		var columnCount int
+		err = s.db.QueryRow(`
+			SELECT COUNT(*) FROM pragma_table_info('sessions')
+			WHERE name = 'budget'
+		`).Scan(&columnCount)
+		if err != nil {
+			return fmt.Errorf("failed to check for budget column: %w", err)
+		}
+		if columnCount == 0 {
+			if _, err = s.db.Exec("ALTER TABLE sessions ADD COLUMN budget TEXT"); err != nil {
+				return fmt.Errorf("failed to add budget column: %w", err)
+			}
+		}
+
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (?, ?)
+		`, 28, "Add budget column to sessions for resource limits")
+		if err != nil {
+			return fmt.Errorf("failed to record migration 28: %w", err)
+		}
+
+		slog.Info("Migration 28 applied successfully")
+	}
+
+	// Migration 29: Add session template label for usage reporting
+	if currentVersion < 29 {
+		slog.Info("Applying migration 29: Adding template column to sessions table")
+
+		var columnCount int
+		err = s.db.QueryRow(`
+			SELECT COUNT(*) FROM pragma_table_info('sessions')
+			WHERE name = 'template'
+		`).Scan(&columnCount)
+		if err != nil {
+			return fmt.Errorf("failed to check for template column: %w", err)
+		}
+		if columnCount == 0 {
+			if _, err = s.db.Exec("ALTER TABLE sessions ADD COLUMN template TEXT"); err != nil {
+				return fmt.Errorf("failed to add template column: %w", err)
+			}
+		}
+
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (?, ?)
+		`, 29, "Add template column to sessions for usage reporting")
+		if err != nil {
+			return fmt.Errorf("failed to record migration 29: %w", err)
+		}
+
+		slog.Info("Migration 29 applied successfully")
+	}
+
+	// Migration 30: Add jobs table for asynchronous operations
+	if currentVersion < 30 {
+		slog.Info("Applying migration 30: Add jobs table for asynchronous operations")
+
+		_, err = s.db.Exec(`
+			CREATE TABLE IF NOT EXISTS jobs (
+				id TEXT PRIMARY KEY,
+				kind TEXT NOT NULL,
+				session_id TEXT,
+				status TEXT NOT NULL,
+				result TEXT,
+				error TEXT,
+				created_at DATETIME NOT NULL,
+				started_at DATETIME,
+				completed_at DATETIME
+			);
+			CREATE INDEX IF NOT EXISTS idx_jobs_status ON jobs(status);
+			CREATE INDEX IF NOT EXISTS idx_jobs_session ON jobs(session_id, created_at);
+		`)
+		if err != nil {
+			return fmt.Errorf("migration 30 failed: %w", err)
+		}
+
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (30, 'Add jobs table for asynchronous operations')
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to record migration 30: %w", err)
+		}
+
+		slog.Info("Migration 30 applied successfully")
+	}
+
+	// Migration 31: Add ssh_host to sessions for remote working directories
+	if currentVersion < 31 {
+		slog.Info("Applying migration 31: Add ssh_host to sessions for remote working directories")
+
+		_, err = s.db.Exec(`
+			ALTER TABLE sessions ADD COLUMN ssh_host TEXT;
+		`)
+		if err != nil {
+			return fmt.Errorf("migration 31 failed: %w", err)
+		}
+
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (31, 'Add ssh_host to sessions for remote working directories')
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to record migration 31: %w", err)
+		}
+
+		slog.Info("Migration 31 applied successfully")
+	}
+
+	// Migration 32: Add container_image to sessions for container-isolated agents
+	if currentVersion < 32 {
+		slog.Info("Applying migration 32: Add container_image to sessions for container-isolated agents")
+
+		_, err = s.db.Exec(`
+			ALTER TABLE sessions ADD COLUMN container_image TEXT;
+		`)
+		if err != nil {
+			return fmt.Errorf("migration 32 failed: %w", err)
+		}
+
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (32, 'Add container_image to sessions for container-isolated agents')
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to record migration 32: %w", err)
+		}
+
+		slog.Info("Migration 32 applied successfully")
+	}
+
+	// Migration 33: Add completion_summary to sessions
+	if currentVersion < 33 {
+		slog.Info("Applying migration 33: Add completion_summary to sessions")
+
+		_, err = s.db.Exec(`
+			ALTER TABLE sessions ADD COLUMN completion_summary TEXT;
+		`)
+		if err != nil {
+			return fmt.Errorf("migration 33 failed: %w", err)
+		}
+
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (33, 'Add completion_summary to sessions')
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to record migration 33: %w", err)
+		}
+
+		slog.Info("Migration 33 applied successfully")
+	}
+
+	// Migration 34: Add experiments and experiment runs
+	if currentVersion < 34 {
+		slog.Info("Applying migration 34: Add experiments and experiment runs")
+
+		_, err = s.db.Exec(`
+			CREATE TABLE IF NOT EXISTS experiments (
+				id TEXT PRIMARY KEY,
+				name TEXT,
+				query TEXT NOT NULL,
+				working_dir TEXT NOT NULL,
+				base_commit TEXT NOT NULL,
+				test_command TEXT,
+				variants TEXT NOT NULL,
+				created_at DATETIME NOT NULL
+			);
+			CREATE TABLE IF NOT EXISTS experiment_runs (
+				session_id TEXT PRIMARY KEY,
+				experiment_id TEXT NOT NULL,
+				variant TEXT NOT NULL,
+				worktree TEXT NOT NULL,
+				files_changed INTEGER,
+				lines_added INTEGER,
+				lines_removed INTEGER,
+				tests_passed BOOLEAN,
+				test_output TEXT,
+				evaluated_at DATETIME
+			);
+			CREATE INDEX IF NOT EXISTS idx_experiment_runs_experiment ON experiment_runs(experiment_id);
+		`)
+		if err != nil {
+			return fmt.Errorf("migration 34 failed: %w", err)
+		}
+
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (34, 'Add experiments and experiment runs')
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to record migration 34: %w", err)
+		}
+
+		slog.Info("Migration 34 applied successfully")
+	}
+
+	// Migration 35: Add retry_of to sessions
+	if currentVersion < 35 {
+		slog.Info("Applying migration 35: Add retry_of to sessions")
+
+		_, err = s.db.Exec(`
+			ALTER TABLE sessions ADD COLUMN retry_of TEXT;
+			CREATE INDEX IF NOT EXISTS idx_sessions_retry_of ON sessions(retry_of);
+		`)
+		if err != nil {
+			return fmt.Errorf("migration 35 failed: %w", err)
+		}
+
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (35, 'Add retry_of to sessions')
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to record migration 35: %w", err)
+		}
+
+		slog.Info("Migration 35 applied successfully")
+	}
+
+	// Migration 36: Add conversation event annotations
+	if currentVersion < 36 {
+		slog.Info("Applying migration 36: Add conversation event annotations")
+
+		_, err = s.db.Exec(`
+			CREATE TABLE IF NOT EXISTS event_annotations (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				session_id TEXT NOT NULL,
+				event_id INTEGER NOT NULL,
+				kind TEXT NOT NULL,
+				note TEXT,
+				author TEXT,
+				created_at DATETIME NOT NULL
+			);
+			CREATE INDEX IF NOT EXISTS idx_event_annotations_session ON event_annotations(session_id, event_id);
+		`)
+		if err != nil {
+			return fmt.Errorf("migration 36 failed: %w", err)
+		}
+
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (36, 'Add conversation event annotations')
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to record migration 36: %w", err)
+		}
+
+		slog.Info("Migration 36 applied successfully")
+	}
+
+	// Migration 37: Add AI outputs and feedback
+	if currentVersion < 37 {
+		slog.Info("Applying migration 37: Add AI outputs and feedback")
+
+		_, err = s.db.Exec(`
+			CREATE TABLE IF NOT EXISTS ai_outputs (
+				id TEXT PRIMARY KEY,
+				kind TEXT NOT NULL,
+				session_id TEXT,
+				template TEXT NOT NULL,
+				template_version TEXT NOT NULL,
+				provider TEXT,
+				model TEXT,
+				prompt TEXT NOT NULL,
+				response TEXT NOT NULL,
+				created_at DATETIME NOT NULL,
+				rating TEXT,
+				comment TEXT,
+				rated_at DATETIME
+			);
+			CREATE INDEX IF NOT EXISTS idx_ai_outputs_template ON ai_outputs(kind, template, created_at);
+		`)
+		if err != nil {
+			return fmt.Errorf("migration 37 failed: %w", err)
+		}
+
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (37, 'Add AI outputs and feedback')
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to record migration 37: %w", err)
+		}
+
+		slog.Info("Migration 37 applied successfully")
+	}
+
+	// Migration 38: Add session embeddings
+	if currentVersion < 38 {
+		slog.Info("Applying migration 38: Add session embeddings")
+
+		_, err = s.db.Exec(`
+			CREATE TABLE IF NOT EXISTS session_embeddings (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				session_id TEXT NOT NULL,
+				source TEXT NOT NULL,
+				content TEXT NOT NULL,
+				model TEXT NOT NULL,
+				vector BLOB NOT NULL,
+				created_at DATETIME NOT NULL
+			);
+			CREATE INDEX IF NOT EXISTS idx_session_embeddings_model ON session_embeddings(model, session_id);
+			CREATE INDEX IF NOT EXISTS idx_session_embeddings_session ON session_embeddings(session_id);
+		`)
+		if err != nil {
+			return fmt.Errorf("migration 38 failed: %w", err)
+		}
+
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (38, 'Add session embeddings')
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to record migration 38: %w", err)
+		}
+
+		slog.Info("Migration 38 applied successfully")
+	}
+
+	// Migration 39: Add decision log
+	if currentVersion < 39 {
+		slog.Info("Applying migration 39: Add decision log")
+
+		_, err = s.db.Exec(`
+			CREATE TABLE IF NOT EXISTS decisions (
+				id TEXT PRIMARY KEY,
+				repository TEXT NOT NULL,
+				session_id TEXT,
+				title TEXT NOT NULL,
+				decision TEXT NOT NULL,
+				rationale TEXT,
+				alternatives TEXT,
+				status TEXT NOT NULL,
+				created_at DATETIME NOT NULL,
+				updated_at DATETIME NOT NULL
+			);
+			CREATE INDEX IF NOT EXISTS idx_decisions_repository ON decisions(repository, status, created_at);
+			CREATE INDEX IF NOT EXISTS idx_decisions_session ON decisions(session_id);
+		`)
+		if err != nil {
+			return fmt.Errorf("migration 39 failed: %w", err)
+		}
+
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (39, 'Add decision log')
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to record migration 39: %w", err)
+		}
+
+		slog.Info("Migration 39 applied successfully")
+	}
+
+	// Migration 40: Add memory file proposals
+	if currentVersion < 40 {
+		slog.Info("Applying migration 40: Add memory file proposals")
+
+		_, err = s.db.Exec(`
+			CREATE TABLE IF NOT EXISTS memory_file_proposals (
+				id TEXT PRIMARY KEY,
+				repository TEXT NOT NULL,
+				path TEXT NOT NULL,
+				base_content TEXT NOT NULL,
+				proposed_content TEXT NOT NULL,
+				diff TEXT NOT NULL,
+				rationale TEXT,
+				session_ids TEXT NOT NULL,
+				status TEXT NOT NULL,
+				commit_hash TEXT,
+				created_at DATETIME NOT NULL,
+				resolved_at DATETIME
+			);
+			CREATE INDEX IF NOT EXISTS idx_memory_file_proposals_repository ON memory_file_proposals(repository, status, created_at);
+		`)
+		if err != nil {
+			return fmt.Errorf("migration 40 failed: %w", err)
+		}
+
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (40, 'Add memory file proposals')
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to record migration 40: %w", err)
+		}
+
+		slog.Info("Migration 40 applied successfully")
+	}
+
+	// Migration 41: Add context packs
+	if currentVersion < 41 {
+		slog.Info("Applying migration 41: Add context packs")
+
+		_, err = s.db.Exec(`
+			CREATE TABLE IF NOT EXISTS context_packs (
+				id TEXT PRIMARY KEY,
+				query TEXT NOT NULL,
+				working_dir TEXT NOT NULL,
+				content TEXT NOT NULL,
+				created_at DATETIME NOT NULL
+			);
+			ALTER TABLE sessions ADD COLUMN context_pack_id TEXT;
+		`)
+		if err != nil {
+			return fmt.Errorf("migration 41 failed: %w", err)
+		}
+
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (41, 'Add context packs')
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to record migration 41: %w", err)
+		}
+
+		slog.Info("Migration 41 applied successfully")
+	}
+
+	// Migration 42: Add github_triggers table for webhook-launched sessions
+	if currentVersion < 42 {
+		slog.Info("Applying migration 42: Add github_triggers table for webhook-launched sessions")
+
+		_, err = s.db.Exec(`
+			CREATE TABLE IF NOT EXISTS github_triggers (
+				delivery_id TEXT PRIMARY KEY,
+				session_id TEXT NOT NULL,
+				repository TEXT NOT NULL,
+				number INTEGER NOT NULL,
+				kind TEXT NOT NULL,
+				worktree TEXT NOT NULL DEFAULT '',
+				created_at DATETIME NOT NULL,
+				reported_at DATETIME
+			);
+			CREATE INDEX IF NOT EXISTS idx_github_triggers_session ON github_triggers(session_id);
+		`)
+		if err != nil {
+			return fmt.Errorf("migration 42 failed: %w", err)
+		}
+
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (42, 'Add github_triggers table for webhook-launched sessions')
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to record migration 42: %w", err)
+		}
+
+		slog.Info("Migration 42 applied successfully")
+	}
+
+	// Migration 43: Add session_tickets table for issue tracker links
+	if currentVersion < 43 {
+		slog.Info("Applying migration 43: Add session_tickets table for issue tracker links")
+
+		_, err = s.db.Exec(`
+			CREATE TABLE IF NOT EXISTS session_tickets (
+				session_id TEXT NOT NULL,
+				tracker TEXT NOT NULL,
+				ref TEXT NOT NULL,
+				title TEXT NOT NULL DEFAULT '',
+				url TEXT NOT NULL DEFAULT '',
+				status TEXT NOT NULL DEFAULT '',
+				synced_status TEXT NOT NULL DEFAULT '',
+				synced_at DATETIME,
+				created_at DATETIME NOT NULL,
+				PRIMARY KEY (session_id, tracker, ref)
+			);
+		`)
+		if err != nil {
+			return fmt.Errorf("migration 43 failed: %w", err)
+		}
+
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (43, 'Add session_tickets table for issue tracker links')
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to record migration 43: %w", err)
+		}
+
+		slog.Info("Migration 43 applied successfully")
+	}
+
+	// Migration 44: Add approval_escalations table for critical approvals
+	if currentVersion < 44 {
+		slog.Info("Applying migration 44: Add approval_escalations table for critical approvals")
+
+		_, err = s.db.Exec(`
+			CREATE TABLE IF NOT EXISTS approval_escalations (
+				approval_id TEXT PRIMARY KEY,
+				session_id TEXT NOT NULL,
+				tool_name TEXT NOT NULL,
+				rule TEXT NOT NULL DEFAULT '',
+				created_at DATETIME NOT NULL,
+				triggered_at DATETIME,
+				resolved_at DATETIME
+			);
+			CREATE INDEX IF NOT EXISTS idx_approval_escalations_open
+				ON approval_escalations(resolved_at);
+		`)
+		if err != nil {
+			return fmt.Errorf("migration 44 failed: %w", err)
+		}
+
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (44, 'Add approval_escalations table for critical approvals')
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to record migration 44: %w", err)
+		}
+
+		slog.Info("Migration 44 applied successfully")
+	}
+
+	// Migration 45: Add session_commits table for repository activity
+	if currentVersion < 45 {
+		slog.Info("Applying migration 45: Add session_commits table for repository activity")
+
+		_, err = s.db.Exec(`
+			CREATE TABLE IF NOT EXISTS session_commits (
+				session_id TEXT NOT NULL,
+				repository TEXT NOT NULL,
+				hash TEXT NOT NULL,
+				additions INTEGER NOT NULL DEFAULT 0,
+				deletions INTEGER NOT NULL DEFAULT 0,
+				created_at DATETIME NOT NULL,
+				PRIMARY KEY (session_id, hash)
+			);
+			CREATE INDEX IF NOT EXISTS idx_session_commits_created
+				ON session_commits(created_at);
+		`)
+		if err != nil {
+			return fmt.Errorf("migration 45 failed: %w", err)
+		}
+
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (45, 'Add session_commits table for repository activity')
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to record migration 45: %w", err)
+		}
+
+		slog.Info("Migration 45 applied successfully")
+	}
+
+	// Migration 46: Add remote credentials
+	if currentVersion < 46 {
+		slog.Info("Applying migration 46: Add remote credentials")
+
+		_, err = s.db.Exec(`
+			CREATE TABLE IF NOT EXISTS remote_credentials (
+			    url_prefix TEXT PRIMARY KEY,
+			    username TEXT NOT NULL DEFAULT '',
+			    secret BLOB NOT NULL,
+			    created_at DATETIME NOT NULL,
+			    updated_at DATETIME NOT NULL
+			);
+		`)
+		if err != nil {
+			return fmt.Errorf("migration 46 failed: %w", err)
+		}
+
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (46, 'Add remote credentials')
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to record migration 46: %w", err)
+		}
+
+		slog.Info("Migration 46 applied successfully")
+	}
+
+	// Migration 47: Add session artifacts
+	if currentVersion < 47 {
+		slog.Info("Applying migration 47: Add session artifacts")
+
+		_, err = s.db.Exec(`
+			CREATE TABLE IF NOT EXISTS artifacts (
+			    id TEXT PRIMARY KEY,
+			    session_id TEXT NOT NULL,
+			    name TEXT NOT NULL,
+			    kind TEXT NOT NULL DEFAULT '',
+			    content_type TEXT NOT NULL,
+			    size INTEGER NOT NULL,
+			    sha256 TEXT NOT NULL,
+			    created_at DATETIME NOT NULL,
+			    expires_at DATETIME
+			);
+			CREATE INDEX IF NOT EXISTS idx_artifacts_session ON artifacts(session_id, created_at);
+			CREATE INDEX IF NOT EXISTS idx_artifacts_expires ON artifacts(expires_at);
+		`)
+		if err != nil {
+			return fmt.Errorf("migration 47 failed: %w", err)
+		}
+
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (47, 'Add session artifacts')
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to record migration 47: %w", err)
+		}
+
+		slog.Info("Migration 47 applied successfully")
+	}
+
+	// Migration 48: Add approval request attachments
+	if currentVersion < 48 {
+		slog.Info("Applying migration 48: Add approval request attachments")
+
+		_, err = s.db.Exec(`
+			ALTER TABLE approvals ADD COLUMN attachments TEXT;
+		`)
+		if err != nil {
+			return fmt.Errorf("migration 48 failed: %w", err)
+		}
+
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (48, 'Add approval request attachments')
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to record migration 48: %w", err)
+		}
+
+		slog.Info("Migration 48 applied successfully")
+	}
+
+	// Migration 49: Add web push subscriptions
+	if currentVersion < 49 {
+		slog.Info("Applying migration 49: Add web push subscriptions")
+
+		_, err = s.db.Exec(`
+			CREATE TABLE IF NOT EXISTS web_push_subscriptions (
+			    id TEXT PRIMARY KEY,
+			    endpoint TEXT NOT NULL UNIQUE,
+			    p256dh TEXT NOT NULL,
+			    auth TEXT NOT NULL,
+			    user_agent TEXT NOT NULL DEFAULT '',
+			    created_at TIMESTAMP NOT NULL
+			);
+		`)
+		if err != nil {
+			return fmt.Errorf("migration 49 failed: %w", err)
+		}
+
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (49, 'Add web push subscriptions')
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to record migration 49: %w", err)
+		}
+
+		slog.Info("Migration 49 applied successfully")
+	}
+
+	// Migration 50: Add session watches
+	if currentVersion < 50 {
+		slog.Info("Applying migration 50: Add session watches")
+
+		_, err = s.db.Exec(`
+			CREATE TABLE IF NOT EXISTS session_watches (
+			    watcher TEXT NOT NULL,
+			    session_id TEXT NOT NULL,
+			    created_at DATETIME NOT NULL,
+			    PRIMARY KEY (watcher, session_id)
+			);
+			CREATE INDEX IF NOT EXISTS idx_session_watches_session ON session_watches(session_id);
+		`)
+		if err != nil {
+			return fmt.Errorf("migration 50 failed: %w", err)
+		}
+
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (50, 'Add session watches')
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to record migration 50: %w", err)
+		}
+
+		slog.Info("Migration 50 applied successfully")
+	}
+
+	// Migration 51: Add approval inbox state
+	if currentVersion < 51 {
+		slog.Info("Applying migration 51: Add approval inbox state")
+
+		_, err = s.db.Exec(`
+			CREATE TABLE IF NOT EXISTS inbox_states (
+			    reader TEXT NOT NULL,
+			    approval_id TEXT NOT NULL,
+			    read_at DATETIME,
+			    snoozed_until DATETIME,
+			    PRIMARY KEY (reader, approval_id)
+			);
+			CREATE INDEX IF NOT EXISTS idx_inbox_states_snoozed ON inbox_states(snoozed_until) WHERE snoozed_until IS NOT NULL;
+		`)
+		if err != nil {
+			return fmt.Errorf("migration 51 failed: %w", err)
+		}
+
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (51, 'Add approval inbox state')
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to record migration 51: %w", err)
+		}
+
+		slog.Info("Migration 51 applied successfully")
+	}
+
+	// Migration 52: Add canned responses
+	if currentVersion < 52 {
+		slog.Info("Applying migration 52: Add canned responses")
+
+		_, err = s.db.Exec(`
+			CREATE TABLE IF NOT EXISTS canned_responses (
+			    id TEXT PRIMARY KEY,
+			    team TEXT NOT NULL DEFAULT '',
+			    title TEXT NOT NULL,
+			    text TEXT NOT NULL,
+			    use_count INTEGER NOT NULL DEFAULT 0,
+			    last_used_at DATETIME,
+			    created_at DATETIME NOT NULL,
+			    updated_at DATETIME NOT NULL
+			);
+		`)
+		if err != nil {
+			return fmt.Errorf("migration 52 failed: %w", err)
+		}
+
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (52, 'Add canned responses')
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to record migration 52: %w", err)
+		}
+
+		slog.Info("Migration 52 applied successfully")
+	}
+
+	// Migration 53: Add AI-expanded denial comments
+	if currentVersion < 53 {
+		slog.Info("Applying migration 53: Add AI-expanded denial comments")
+
+		_, err = s.db.Exec(`
+			    ALTER TABLE approvals ADD COLUMN comment_expansion TEXT;
+		`)
+		if err != nil {
+			return fmt.Errorf("migration 53 failed: %w", err)
+		}
+
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (53, 'Add AI-expanded denial comments')
 		`)
 		if err != nil {
-			return fmt.Errorf("failed to record migration 18: %w", err)
+			return fmt.Errorf("failed to record migration 53: %w", err)
 		}
 
-		slog.Info("Migration 18 applied successfully")
+		slog.Info("Migration 53 applied successfully")
 	}
 
-	// Migration 19: Add opt_in_telemetry column for error reporting consent
-	if currentVersion < 19 {
-		slog.Info("Applying migration 19: Add opt_in_telemetry column for error reporting consent")
+	// Migration 54: Add approval holds
+	if currentVersion < 54 {
+		slog.Info("Applying migration 54: Add approval holds")
 
-		// Check if opt_in_telemetry column already exists for idempotency
-		var optInTelemetryExists int
-		err := s.db.QueryRow(`
-			SELECT COUNT(*) FROM pragma_table_info('user_settings')
-			WHERE name = 'opt_in_telemetry'
-		`).Scan(&optInTelemetryExists)
+		_, err = s.db.Exec(`
+			    CREATE TABLE IF NOT EXISTS approval_holds (
+			        approval_id TEXT PRIMARY KEY,
+			        session_id TEXT NOT NULL,
+			        reason TEXT NOT NULL,
+			        resume_at DATETIME,
+			        held_by TEXT NOT NULL DEFAULT '',
+			        created_at DATETIME NOT NULL
+			    );
+		`)
 		if err != nil {
-			return fmt.Errorf("failed to check opt_in_telemetry column: %w", err)
-		}
-
-		if optInTelemetryExists == 0 {
-			// Add opt_in_telemetry column with NULL default (unset state)
-			_, err := s.db.Exec(`
-				ALTER TABLE user_settings
-				ADD COLUMN opt_in_telemetry BOOLEAN DEFAULT NULL
-			`)
-			if err != nil {
-				return fmt.Errorf("failed to add opt_in_telemetry column: %w", err)
-			}
+			return fmt.Errorf("migration 54 failed: %w", err)
 		}
 
 		// Record migration
 		_, err = s.db.Exec(`
 			INSERT INTO schema_version (version, description)
-			VALUES (?, ?)
-		`, 19, "Add opt_in_telemetry column for error reporting consent")
+			VALUES (54, 'Add approval holds')
+		`)
 		if err != nil {
-			return fmt.Errorf("failed to record migration 19: %w", err)
+			return fmt.Errorf("failed to record migration 54: %w", err)
 		}
 
-		slog.Info("Migration 19 applied successfully")
+		slog.Info("Migration 54 applied successfully")
 	}
 
-	// Migration 20: Add editor_state column for draft sessions
-	if currentVersion < 20 {
-		slog.Info("Applying migration 20: Adding editor_state column to sessions table")
+	// Migration 55: Add approval constraints and their violations
+	if currentVersion < 55 {
+		slog.Info("Applying migration 55: Add approval constraints and their violations")
 
-		// Add editor_state column to sessions table
-		_, err := s.db.Exec(`
-			ALTER TABLE sessions ADD COLUMN editor_state TEXT
+		_, err = s.db.Exec(`
+			    ALTER TABLE approvals ADD COLUMN constraints TEXT;
+
+			    CREATE TABLE IF NOT EXISTS approval_constraint_violations (
+			        id INTEGER PRIMARY KEY AUTOINCREMENT,
+			        approval_id TEXT NOT NULL,
+			        session_id TEXT NOT NULL,
+			        tool_use_id TEXT NOT NULL,
+			        kind TEXT NOT NULL,
+			        detail TEXT NOT NULL,
+			        created_at DATETIME NOT NULL,
+			        FOREIGN KEY (approval_id) REFERENCES approvals(id)
+			    );
+			    CREATE INDEX IF NOT EXISTS idx_constraint_violations_session
+			        ON approval_constraint_violations(session_id, created_at);
 		`)
 		if err != nil {
-			// Check if column already exists (for idempotency)
-			var columnCount int
-			err = s.db.QueryRow(`
-				SELECT COUNT(*) FROM pragma_table_info('sessions')
-				WHERE name = 'editor_state'
-			`).Scan(&columnCount)
-			if err != nil {
-				return fmt.Errorf("failed to check for editor_state column: %w", err)
-			}
-			if columnCount == 0 {
-				return fmt.Errorf("failed to add editor_state column: %w", err)
-			}
+			return fmt.Errorf("migration 55 failed: %w", err)
 		}
 
 		// Record migration
 		_, err = s.db.Exec(`
 			INSERT INTO schema_version (version, description)
-			VALUES (?, ?)
-		`, 20, "Add editor_state column for draft session editor persistence")
+			VALUES (55, 'Add approval constraints and their violations')
+		`)
 		if err != nil {
-			return fmt.Errorf("failed to record migration 20: %w", err)
+			return fmt.Errorf("failed to record migration 55: %w", err)
 		}
 
-		slog.Info("Migration 20 applied successfully")
+		slog.Info("Migration 55 applied successfully")
 	}
 
-	// Migration 21: Add dangerously_skip_permissions_timeout_ms column to store timeout duration
-	if currentVersion < 21 {
-		slog.Info("Applying migration 21: Adding dangerously_skip_permissions_timeout_ms column to sessions table")
+	// Migration 56: Add approvals mirrored to HumanLayer cloud
+	if currentVersion < 56 {
+		slog.Info("Applying migration 56: Add approvals mirrored to HumanLayer cloud")
 
-		// Check if column already exists for idempotency
-		var columnExists int
-		err := s.db.QueryRow(`
-			SELECT COUNT(*) FROM pragma_table_info('sessions')
-			WHERE name = 'dangerously_skip_permissions_timeout_ms'
-		`).Scan(&columnExists)
+		_, err = s.db.Exec(`
+			    CREATE TABLE IF NOT EXISTS cloud_mirrors (
+			        approval_id TEXT PRIMARY KEY,
+			        call_id TEXT NOT NULL,
+			        state TEXT NOT NULL DEFAULT 'open',
+			        remote_approved BOOLEAN,
+			        remote_comment TEXT NOT NULL DEFAULT '',
+			        created_at DATETIME NOT NULL,
+			        settled_at DATETIME
+			    );
+			    CREATE INDEX IF NOT EXISTS idx_cloud_mirrors_state ON cloud_mirrors(state);
+		`)
 		if err != nil {
-			return fmt.Errorf("failed to check dangerously_skip_permissions_timeout_ms column: %w", err)
-		}
-
-		if columnExists == 0 {
-			// Add column to sessions table
-			_, err = s.db.Exec(`
-				ALTER TABLE sessions
-				ADD COLUMN dangerously_skip_permissions_timeout_ms INTEGER
-			`)
-			if err != nil {
-				return fmt.Errorf("failed to add dangerously_skip_permissions_timeout_ms column: %w", err)
-			}
-			slog.Info("Added dangerously_skip_permissions_timeout_ms column")
-		} else {
-			slog.Info("dangerously_skip_permissions_timeout_ms column already exists")
+			return fmt.Errorf("migration 56 failed: %w", err)
 		}
 
 		// Record migration
 		_, err = s.db.Exec(`
 			INSERT INTO schema_version (version, description)
-			VALUES (21, 'Add dangerously_skip_permissions_timeout_ms column to store bypass permissions timeout duration')
+			VALUES (56, 'Add approvals mirrored to HumanLayer cloud')
 		`)
 		if err != nil {
-			return fmt.Errorf("failed to record migration 21: %w", err)
+			return fmt.Errorf("failed to record migration 56: %w", err)
 		}
 
-		slog.Info("Migration 21 applied successfully")
+		slog.Info("Migration 56 applied successfully")
 	}
 
-	// Migration 22: Add indexes for session search performance
-	if currentVersion < 22 {
-		slog.Info("Applying migration 22: Add indexes for session search performance")
+	// Migration 57: Add supervised session processes
+	if currentVersion < 57 {
+		slog.Info("Applying migration 57: Add supervised session processes")
 
-		// Add index on title for LIKE queries
-		// Using a regular B-tree index which helps with LIKE 'prefix%' patterns
-		_, err := s.db.Exec(`
-			CREATE INDEX IF NOT EXISTS idx_sessions_title
-			ON sessions(title)
+		_, err = s.db.Exec(`
+			CREATE TABLE IF NOT EXISTS session_processes (
+				session_id TEXT PRIMARY KEY,
+				pid INTEGER NOT NULL,
+				started_at DATETIME NOT NULL,
+				exited_at DATETIME,
+				exit_code INTEGER,
+				exit_signal TEXT NOT NULL DEFAULT '',
+				stderr_tail TEXT NOT NULL DEFAULT '',
+				rss_bytes INTEGER NOT NULL DEFAULT 0,
+				peak_rss_bytes INTEGER NOT NULL DEFAULT 0,
+				cpu_seconds REAL NOT NULL DEFAULT 0
+			);
 		`)
 		if err != nil {
-			return fmt.Errorf("migration 22 failed to create title index: %w", err)
+			return fmt.Errorf("migration 57 failed: %w", err)
 		}
 
-		// Also add index on last_activity_at if not exists
-		// This helps with ORDER BY performance
+		// Record migration
 		_, err = s.db.Exec(`
-			CREATE INDEX IF NOT EXISTS idx_sessions_last_activity
-			ON sessions(last_activity_at DESC)
+			INSERT INTO schema_version (version, description)
+			VALUES (57, 'Add supervised session processes')
 		`)
 		if err != nil {
-			return fmt.Errorf("migration 22 failed to create last_activity index: %w", err)
+			return fmt.Errorf("failed to record migration 57: %w", err)
+		}
+
+		slog.Info("Migration 57 applied successfully")
+	}
+
+	// Migration 58: Add suspected prompt injections
+	if currentVersion < 58 {
+		slog.Info("Applying migration 58: Add suspected prompt injections")
+
+		_, err = s.db.Exec(`
+			CREATE TABLE IF NOT EXISTS injection_detections (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				session_id TEXT NOT NULL,
+				prompt TEXT NOT NULL,
+				field TEXT NOT NULL,
+				rule TEXT NOT NULL,
+				excerpt TEXT NOT NULL,
+				stripped BOOLEAN NOT NULL DEFAULT 0,
+				created_at DATETIME NOT NULL
+			);
+			CREATE INDEX IF NOT EXISTS idx_injection_detections_session
+				ON injection_detections(session_id, created_at);
+		`)
+		if err != nil {
+			return fmt.Errorf("migration 58 failed: %w", err)
 		}
 
 		// Record migration
 		_, err = s.db.Exec(`
 			INSERT INTO schema_version (version, description)
-			VALUES (22, 'Add indexes for session search performance')
+			VALUES (58, 'Add suspected prompt injections')
 		`)
 		if err != nil {
-			return fmt.Errorf("failed to record migration 22: %w", err)
+			return fmt.Errorf("failed to record migration 58: %w", err)
 		}
 
-		slog.Info("Migration 22 applied successfully")
+		slog.Info("Migration 58 applied successfully")
 	}
 
-	// Migration 23: Add approval_images table and image_paths column to approvals
-	if currentVersion < 23 {
-		slog.Info("Applying migration 23: Add approval_images table and image_paths column")
+	// Migration 59: Add content safety blocks
+	if currentVersion < 59 {
+		slog.Info("Applying migration 59: Add content safety blocks")
 
-		// Create approval_images table
-		_, err := s.db.Exec(`
-			CREATE TABLE IF NOT EXISTS approval_images (
+		_, err = s.db.Exec(`
+			CREATE TABLE IF NOT EXISTS moderation_blocks (
 				id INTEGER PRIMARY KEY AUTOINCREMENT,
-				approval_id TEXT NOT NULL,
-				file_path TEXT NOT NULL,
-				mime_type TEXT NOT NULL,
-				size_bytes INTEGER NOT NULL,
-				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-				FOREIGN KEY (approval_id) REFERENCES approvals(id) ON DELETE CASCADE
-			)
+				kind TEXT NOT NULL,
+				session_id TEXT,
+				approval_id TEXT,
+				content TEXT NOT NULL,
+				source TEXT NOT NULL,
+				reason TEXT NOT NULL,
+				categories TEXT,
+				created_at DATETIME NOT NULL,
+				reviewed_at DATETIME,
+				reviewer TEXT,
+				review_note TEXT
+			);
+			CREATE INDEX IF NOT EXISTS idx_moderation_blocks_reviewed
+				ON moderation_blocks(reviewed_at, created_at);
 		`)
 		if err != nil {
-			return fmt.Errorf("migration 23 failed to create approval_images table: %w", err)
+			return fmt.Errorf("migration 59 failed: %w", err)
 		}
 
-		// Add index on approval_id for faster lookups
+		// Record migration
 		_, err = s.db.Exec(`
-			CREATE INDEX IF NOT EXISTS idx_approval_images_approval_id
-			ON approval_images(approval_id)
+			INSERT INTO schema_version (version, description)
+			VALUES (59, 'Add content safety blocks')
 		`)
 		if err != nil {
-			return fmt.Errorf("migration 23 failed to create approval_images index: %w", err)
+			return fmt.Errorf("failed to record migration 59: %w", err)
 		}
 
-		// Add image_paths column to approvals table (JSON array of file paths)
-		// Check if column already exists first
-		var exists int
-		err = s.db.QueryRow(`
-			SELECT COUNT(*) FROM pragma_table_info('approvals') WHERE name = 'image_paths'
-		`).Scan(&exists)
-		if err != nil {
-			return fmt.Errorf("migration 23 failed to check image_paths column: %w", err)
-		}
+		slog.Info("Migration 59 applied successfully")
+	}
 
-		if exists == 0 {
-			_, err = s.db.Exec(`ALTER TABLE approvals ADD COLUMN image_paths TEXT`)
-			if err != nil {
-				return fmt.Errorf("migration 23 failed to add image_paths column: %w", err)
-			}
+	// Migration 60: Add single-use approval resolution nonces
+	if currentVersion < 60 {
+		slog.Info("Applying migration 60: Add approval resolution nonces and rejections")
+
+		_, err = s.db.Exec(`
+			CREATE TABLE IF NOT EXISTS resolution_nonces (
+				nonce TEXT PRIMARY KEY,
+				approval_id TEXT NOT NULL,
+				used_at DATETIME NOT NULL,
+				expires_at DATETIME NOT NULL
+			);
+			CREATE INDEX IF NOT EXISTS idx_resolution_nonces_expires
+				ON resolution_nonces(expires_at);
+			CREATE TABLE IF NOT EXISTS rejected_resolutions (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				approval_id TEXT,
+				channel TEXT NOT NULL,
+				source TEXT,
+				reason TEXT NOT NULL,
+				created_at DATETIME NOT NULL
+			);
+			CREATE INDEX IF NOT EXISTS idx_rejected_resolutions_approval
+				ON rejected_resolutions(approval_id, created_at);
+		`)
+		if err != nil {
+			return fmt.Errorf("migration 60 failed: %w", err)
 		}
 
 		// Record migration
 		_, err = s.db.Exec(`
 			INSERT INTO schema_version (version, description)
-			VALUES (23, 'Add approval_images table and image_paths column')
+			VALUES (60, 'Add approval resolution nonces and rejections')
 		`)
 		if err != nil {
-			return fmt.Errorf("failed to record migration 23: %w", err)
+			return fmt.Errorf("failed to record migration 60: %w", err)
 		}
 
-		slog.Info("Migration 23 applied successfully")
+		slog.Info("Migration 60 applied successfully")
 	}
 
-	// Migration 24: Add reviewed field to sessions table for marking sessions as checked
-	if currentVersion < 24 {
-		slog.Info("Applying migration 24: Add reviewed field to sessions table")
+	// Migration 61: Add session share links
+	if currentVersion < 61 {
+		slog.Info("Applying migration 61: Add session share links")
 
-		// Check if column already exists
-		var columnExists int
-		err = s.db.QueryRow(`
-			SELECT COUNT(*) FROM pragma_table_info('sessions')
-			WHERE name = 'reviewed'
-		`).Scan(&columnExists)
+		_, err = s.db.Exec(`
+			CREATE TABLE IF NOT EXISTS share_links (
+				id TEXT PRIMARY KEY,
+				session_id TEXT NOT NULL,
+				scope TEXT NOT NULL,
+				label TEXT,
+				created_at DATETIME NOT NULL,
+				expires_at DATETIME NOT NULL,
+				revoked_at DATETIME
+			);
+			CREATE INDEX IF NOT EXISTS idx_share_links_session
+				ON share_links(session_id, created_at);
+		`)
 		if err != nil {
-			return fmt.Errorf("failed to check reviewed column: %w", err)
+			return fmt.Errorf("migration 61 failed: %w", err)
 		}
 
-		// Only add column if it doesn't exist
-		if columnExists == 0 {
-			_, err = s.db.Exec(`
-				ALTER TABLE sessions
-				ADD COLUMN reviewed BOOLEAN DEFAULT FALSE
-			`)
-			if err != nil {
-				return fmt.Errorf("failed to add reviewed column: %w", err)
-			}
+		// Record migration
+		_, err = s.db.Exec(`
+			INSERT INTO schema_version (version, description)
+			VALUES (61, 'Add session share links')
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to record migration 61: %w", err)
 		}
 
-		// Add index for efficient filtering
+		slog.Info("Migration 61 applied successfully")
+	}
+
+	// Migration 62: Add session worktrees
+	if currentVersion < 62 {
+		slog.Info("Applying migration 62: Add session worktrees")
+
 		_, err = s.db.Exec(`
-			CREATE INDEX IF NOT EXISTS idx_sessions_reviewed
-			ON sessions(reviewed)
+			CREATE TABLE IF NOT EXISTS session_worktrees (
+				session_id TEXT PRIMARY KEY,
+				repository TEXT NOT NULL,
+				path TEXT NOT NULL,
+				branch TEXT NOT NULL,
+				base_commit TEXT NOT NULL,
+				created_at DATETIME NOT NULL,
+				removed_at DATETIME
+			);
 		`)
 		if err != nil {
-			return fmt.Errorf("failed to create reviewed index: %w", err)
+			return fmt.Errorf("migration 62 failed: %w", err)
 		}
 
 		// Record migration
 		_, err = s.db.Exec(`
 			INSERT INTO schema_version (version, description)
-			VALUES (24, 'Add reviewed field to sessions table for marking sessions as checked')
+			VALUES (62, 'Add session worktrees')
 		`)
 		if err != nil {
-			return fmt.Errorf("failed to record migration 24: %w", err)
+			return fmt.Errorf("failed to record migration 62: %w", err)
 		}
 
-		slog.Info("Migration 24 applied successfully")
+		slog.Info("Migration 62 applied successfully")
 	}
 
 	return nil
@@ -1284,8 +2508,9 @@ func (s *SQLiteStore) CreateSession(ctx context.Context, session *Session) error
 			permission_prompt_tool, allowed_tools, disallowed_tools,
 			status, created_at, last_activity_at, auto_accept_edits, archived, dangerously_skip_permissions, dangerously_skip_permissions_expires_at,
 			dangerously_skip_permissions_timeout_ms,
-			proxy_enabled, proxy_base_url, proxy_model_override, proxy_api_key, additional_directories, editor_state
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			proxy_enabled, proxy_base_url, proxy_model_override, proxy_api_key, additional_directories, editor_state,
+			auto_deny_all, auto_deny_tools, budget, template, ssh_host, container_image, completion_summary, retry_of, context_pack_id
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	_, err := s.db.ExecContext(ctx, query,
@@ -1298,6 +2523,7 @@ func (s *SQLiteStore) CreateSession(ctx context.Context, session *Session) error
 		session.DangerouslySkipPermissionsTimeoutMs,
 		session.ProxyEnabled, session.ProxyBaseURL, session.ProxyModelOverride, session.ProxyAPIKey,
 		session.AdditionalDirectories, session.EditorState,
+		session.AutoDenyAll, session.AutoDenyTools, session.Budget, session.Template, session.SSHHost, session.ContainerImage, session.CompletionSummary, session.RetryOf, session.ContextPackID,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create session: %w", err)
@@ -1444,6 +2670,38 @@ func (s *SQLiteStore) UpdateSession(ctx context.Context, sessionID string, updat
 		setParts = append(setParts, "editor_state = ?")
 		args = append(args, *updates.EditorState)
 	}
+	if updates.AutoDenyAll != nil {
+		setParts = append(setParts, "auto_deny_all = ?")
+		args = append(args, *updates.AutoDenyAll)
+	}
+	if updates.AutoDenyTools != nil {
+		setParts = append(setParts, "auto_deny_tools = ?")
+		args = append(args, *updates.AutoDenyTools)
+	}
+	if updates.Budget != nil {
+		setParts = append(setParts, "budget = ?")
+		args = append(args, *updates.Budget)
+	}
+	if updates.SSHHost != nil {
+		setParts = append(setParts, "ssh_host = ?")
+		args = append(args, *updates.SSHHost)
+	}
+	if updates.ContainerImage != nil {
+		setParts = append(setParts, "container_image = ?")
+		args = append(args, *updates.ContainerImage)
+	}
+	if updates.CompletionSummary != nil {
+		setParts = append(setParts, "completion_summary = ?")
+		args = append(args, *updates.CompletionSummary)
+	}
+	if updates.RetryOf != nil {
+		setParts = append(setParts, "retry_of = ?")
+		args = append(args, *updates.RetryOf)
+	}
+	if updates.ContextPackID != nil {
+		setParts = append(setParts, "context_pack_id = ?")
+		args = append(args, *updates.ContextPackID)
+	}
 
 	if len(setParts) == 0 {
 		// No fields to update is OK - this is a no-op
@@ -1502,7 +2760,8 @@ func (s *SQLiteStore) GetSession(ctx context.Context, sessionID string) (*Sessio
 			cost_usd, input_tokens, output_tokens, cache_creation_input_tokens, cache_read_input_tokens, effective_context_tokens,
 			duration_ms, num_turns, result_content, error_message, auto_accept_edits, archived, reviewed,
 			dangerously_skip_permissions, dangerously_skip_permissions_expires_at, dangerously_skip_permissions_timeout_ms,
-			proxy_enabled, proxy_base_url, proxy_model_override, proxy_api_key, additional_directories, editor_state
+			proxy_enabled, proxy_base_url, proxy_model_override, proxy_api_key, additional_directories, editor_state,
+			auto_deny_all, auto_deny_tools, budget, template, ssh_host, container_image, completion_summary, retry_of, context_pack_id
 		FROM sessions WHERE id = ?
 	`
 
@@ -1522,6 +2781,14 @@ func (s *SQLiteStore) GetSession(ctx context.Context, sessionID string) (*Sessio
 	var proxyBaseURL, proxyModelOverride, proxyAPIKey sql.NullString
 	var additionalDirectories sql.NullString
 	var editorState sql.NullString
+	var autoDenyTools sql.NullString
+	var budget sql.NullString
+	var template sql.NullString
+	var sshHost sql.NullString
+	var containerImage sql.NullString
+	var completionSummary sql.NullString
+	var retryOf sql.NullString
+	var contextPackID sql.NullString
 
 	err := s.db.QueryRowContext(ctx, query, sessionID).Scan(
 		&session.ID, &session.RunID, &claudeSessionID, &parentSessionID,
@@ -1533,6 +2800,7 @@ func (s *SQLiteStore) GetSession(ctx context.Context, sessionID string) (*Sessio
 		&durationMS, &numTurns, &resultContent, &errorMessage, &session.AutoAcceptEdits,
 		&archived, &reviewed, &session.DangerouslySkipPermissions, &dangerouslySkipPermissionsExpiresAt, &dangerouslySkipPermissionsTimeoutMs,
 		&proxyEnabled, &proxyBaseURL, &proxyModelOverride, &proxyAPIKey, &additionalDirectories, &editorState,
+		&session.AutoDenyAll, &autoDenyTools, &budget, &template, &sshHost, &containerImage, &completionSummary, &retryOf, &contextPackID,
 	)
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("session not found: %s", sessionID)
@@ -1619,6 +2887,14 @@ func (s *SQLiteStore) GetSession(ctx context.Context, sessionID string) (*Sessio
 	if editorState.Valid {
 		session.EditorState = &editorState.String
 	}
+	session.AutoDenyTools = autoDenyTools.String
+	session.Budget = budget.String
+	session.Template = template.String
+	session.SSHHost = sshHost.String
+	session.ContainerImage = containerImage.String
+	session.CompletionSummary = completionSummary.String
+	session.RetryOf = retryOf.String
+	session.ContextPackID = contextPackID.String
 
 	return &session, nil
 }
@@ -1633,7 +2909,8 @@ func (s *SQLiteStore) GetSessionByRunID(ctx context.Context, runID string) (*Ses
 			cost_usd, input_tokens, output_tokens, cache_creation_input_tokens, cache_read_input_tokens, effective_context_tokens,
 			duration_ms, num_turns, result_content, error_message, auto_accept_edits, archived, reviewed,
 			dangerously_skip_permissions, dangerously_skip_permissions_expires_at, dangerously_skip_permissions_timeout_ms,
-			proxy_enabled, proxy_base_url, proxy_model_override, proxy_api_key, additional_directories, editor_state
+			proxy_enabled, proxy_base_url, proxy_model_override, proxy_api_key, additional_directories, editor_state,
+			auto_deny_all, auto_deny_tools, budget, template, ssh_host, container_image, completion_summary, retry_of, context_pack_id
 		FROM sessions
 		WHERE run_id = ?
 	`
@@ -1654,6 +2931,14 @@ func (s *SQLiteStore) GetSessionByRunID(ctx context.Context, runID string) (*Ses
 	var proxyBaseURL, proxyModelOverride, proxyAPIKey sql.NullString
 	var additionalDirectories sql.NullString
 	var editorState sql.NullString
+	var autoDenyTools sql.NullString
+	var budget sql.NullString
+	var template sql.NullString
+	var sshHost sql.NullString
+	var containerImage sql.NullString
+	var completionSummary sql.NullString
+	var retryOf sql.NullString
+	var contextPackID sql.NullString
 
 	err := s.db.QueryRowContext(ctx, query, runID).Scan(
 		&session.ID, &session.RunID, &claudeSessionID, &parentSessionID,
@@ -1665,6 +2950,7 @@ func (s *SQLiteStore) GetSessionByRunID(ctx context.Context, runID string) (*Ses
 		&durationMS, &numTurns, &resultContent, &errorMessage, &session.AutoAcceptEdits,
 		&archived, &reviewed, &session.DangerouslySkipPermissions, &dangerouslySkipPermissionsExpiresAt, &dangerouslySkipPermissionsTimeoutMs,
 		&proxyEnabled, &proxyBaseURL, &proxyModelOverride, &proxyAPIKey, &additionalDirectories, &editorState,
+		&session.AutoDenyAll, &autoDenyTools, &budget, &template, &sshHost, &containerImage, &completionSummary, &retryOf, &contextPackID,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil // No session found
@@ -1751,6 +3037,14 @@ func (s *SQLiteStore) GetSessionByRunID(ctx context.Context, runID string) (*Ses
 	if editorState.Valid {
 		session.EditorState = &editorState.String
 	}
+	session.AutoDenyTools = autoDenyTools.String
+	session.Budget = budget.String
+	session.Template = template.String
+	session.SSHHost = sshHost.String
+	session.ContainerImage = containerImage.String
+	session.CompletionSummary = completionSummary.String
+	session.RetryOf = retryOf.String
+	session.ContextPackID = contextPackID.String
 
 	return &session, nil
 }
@@ -1765,7 +3059,8 @@ func (s *SQLiteStore) ListSessions(ctx context.Context) ([]*Session, error) {
 			cost_usd, input_tokens, output_tokens, cache_creation_input_tokens, cache_read_input_tokens, effective_context_tokens,
 		duration_ms, num_turns, result_content, error_message, auto_accept_edits, archived, reviewed,
 			dangerously_skip_permissions, dangerously_skip_permissions_expires_at, dangerously_skip_permissions_timeout_ms,
-			proxy_enabled, proxy_base_url, proxy_model_override, proxy_api_key, additional_directories, editor_state
+			proxy_enabled, proxy_base_url, proxy_model_override, proxy_api_key, additional_directories, editor_state,
+			auto_deny_all, auto_deny_tools, budget, template, ssh_host, container_image, completion_summary, retry_of, context_pack_id
 		FROM sessions
 		ORDER BY last_activity_at DESC
 	`
@@ -1794,6 +3089,14 @@ func (s *SQLiteStore) ListSessions(ctx context.Context) ([]*Session, error) {
 		var proxyBaseURL, proxyModelOverride, proxyAPIKey sql.NullString
 		var additionalDirectories sql.NullString
 		var editorState sql.NullString
+		var autoDenyTools sql.NullString
+		var budget sql.NullString
+		var template sql.NullString
+		var sshHost sql.NullString
+		var containerImage sql.NullString
+		var completionSummary sql.NullString
+		var retryOf sql.NullString
+		var contextPackID sql.NullString
 
 		err := rows.Scan(
 			&session.ID, &session.RunID, &claudeSessionID, &parentSessionID,
@@ -1805,6 +3108,7 @@ func (s *SQLiteStore) ListSessions(ctx context.Context) ([]*Session, error) {
 			&durationMS, &numTurns, &resultContent, &errorMessage, &session.AutoAcceptEdits,
 			&archived, &reviewed, &session.DangerouslySkipPermissions, &dangerouslySkipPermissionsExpiresAt, &dangerouslySkipPermissionsTimeoutMs,
 			&proxyEnabled, &proxyBaseURL, &proxyModelOverride, &proxyAPIKey, &additionalDirectories, &editorState,
+			&session.AutoDenyAll, &autoDenyTools, &budget, &template, &sshHost, &containerImage, &completionSummary, &retryOf, &contextPackID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan session: %w", err)
@@ -1890,6 +3194,14 @@ func (s *SQLiteStore) ListSessions(ctx context.Context) ([]*Session, error) {
 		if editorState.Valid {
 			session.EditorState = &editorState.String
 		}
+		session.AutoDenyTools = autoDenyTools.String
+		session.Budget = budget.String
+		session.Template = template.String
+		session.SSHHost = sshHost.String
+		session.ContainerImage = containerImage.String
+		session.CompletionSummary = completionSummary.String
+		session.RetryOf = retryOf.String
+		session.ContextPackID = contextPackID.String
 
 		sessions = append(sessions, &session)
 	}
@@ -1918,7 +3230,8 @@ func (s *SQLiteStore) SearchSessionsByTitle(ctx context.Context, query string, l
 			cost_usd, input_tokens, output_tokens, cache_creation_input_tokens, cache_read_input_tokens, effective_context_tokens,
 			duration_ms, num_turns, result_content, error_message, auto_accept_edits, archived, reviewed,
 			dangerously_skip_permissions, dangerously_skip_permissions_expires_at, dangerously_skip_permissions_timeout_ms,
-			proxy_enabled, proxy_base_url, proxy_model_override, proxy_api_key, additional_directories, editor_state
+			proxy_enabled, proxy_base_url, proxy_model_override, proxy_api_key, additional_directories, editor_state,
+			auto_deny_all, auto_deny_tools, budget, template, ssh_host, container_image, completion_summary, retry_of, context_pack_id
 		FROM sessions
 		WHERE 1=1
 		AND NOT EXISTS (
@@ -1969,6 +3282,14 @@ func (s *SQLiteStore) SearchSessionsByTitle(ctx context.Context, query string, l
 		var proxyBaseURL, proxyModelOverride, proxyAPIKey sql.NullString
 		var additionalDirectories sql.NullString
 		var editorState sql.NullString
+		var autoDenyTools sql.NullString
+		var budget sql.NullString
+		var template sql.NullString
+		var sshHost sql.NullString
+		var containerImage sql.NullString
+		var completionSummary sql.NullString
+		var retryOf sql.NullString
+		var contextPackID sql.NullString
 
 		err := rows.Scan(
 			&session.ID, &session.RunID, &claudeSessionID, &parentSessionID,
@@ -1980,6 +3301,7 @@ func (s *SQLiteStore) SearchSessionsByTitle(ctx context.Context, query string, l
 			&durationMS, &numTurns, &resultContent, &errorMessage, &session.AutoAcceptEdits,
 			&archived, &reviewed, &session.DangerouslySkipPermissions, &dangerouslySkipPermissionsExpiresAt, &dangerouslySkipPermissionsTimeoutMs,
 			&proxyEnabled, &proxyBaseURL, &proxyModelOverride, &proxyAPIKey, &additionalDirectories, &editorState,
+			&session.AutoDenyAll, &autoDenyTools, &budget, &template, &sshHost, &containerImage, &completionSummary, &retryOf, &contextPackID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan session: %w", err)
@@ -2065,6 +3387,14 @@ func (s *SQLiteStore) SearchSessionsByTitle(ctx context.Context, query string, l
 		if editorState.Valid {
 			session.EditorState = &editorState.String
 		}
+		session.AutoDenyTools = autoDenyTools.String
+		session.Budget = budget.String
+		session.Template = template.String
+		session.SSHHost = sshHost.String
+		session.ContainerImage = containerImage.String
+		session.CompletionSummary = completionSummary.String
+		session.RetryOf = retryOf.String
+		session.ContextPackID = contextPackID.String
 
 		sessions = append(sessions, &session)
 	}
@@ -2092,7 +3422,8 @@ func (s *SQLiteStore) GetExpiredDangerousPermissionsSessions(ctx context.Context
 			cost_usd, input_tokens, output_tokens, cache_creation_input_tokens, cache_read_input_tokens, effective_context_tokens,
 		duration_ms, num_turns, result_content, error_message, auto_accept_edits, archived, reviewed,
 			dangerously_skip_permissions, dangerously_skip_permissions_expires_at, dangerously_skip_permissions_timeout_ms,
-			proxy_enabled, proxy_base_url, proxy_model_override, proxy_api_key, additional_directories, editor_state
+			proxy_enabled, proxy_base_url, proxy_model_override, proxy_api_key, additional_directories, editor_state,
+			auto_deny_all, auto_deny_tools, budget, template, ssh_host, container_image, completion_summary, retry_of, context_pack_id
 		FROM sessions
 		WHERE dangerously_skip_permissions = 1
 			AND dangerously_skip_permissions_expires_at IS NOT NULL
@@ -2125,6 +3456,14 @@ func (s *SQLiteStore) GetExpiredDangerousPermissionsSessions(ctx context.Context
 		var proxyBaseURL, proxyModelOverride, proxyAPIKey sql.NullString
 		var additionalDirectories sql.NullString
 		var editorState sql.NullString
+		var autoDenyTools sql.NullString
+		var budget sql.NullString
+		var template sql.NullString
+		var sshHost sql.NullString
+		var containerImage sql.NullString
+		var completionSummary sql.NullString
+		var retryOf sql.NullString
+		var contextPackID sql.NullString
 
 		err := rows.Scan(
 			&session.ID, &session.RunID, &claudeSessionID, &parentSessionID,
@@ -2136,6 +3475,7 @@ func (s *SQLiteStore) GetExpiredDangerousPermissionsSessions(ctx context.Context
 			&durationMS, &numTurns, &resultContent, &errorMessage, &session.AutoAcceptEdits,
 			&archived, &reviewed, &session.DangerouslySkipPermissions, &dangerouslySkipPermissionsExpiresAt, &dangerouslySkipPermissionsTimeoutMs,
 			&proxyEnabled, &proxyBaseURL, &proxyModelOverride, &proxyAPIKey, &additionalDirectories, &editorState,
+			&session.AutoDenyAll, &autoDenyTools, &budget, &template, &sshHost, &containerImage, &completionSummary, &retryOf, &contextPackID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan session: %w", err)
@@ -2221,6 +3561,14 @@ func (s *SQLiteStore) GetExpiredDangerousPermissionsSessions(ctx context.Context
 		if editorState.Valid {
 			session.EditorState = &editorState.String
 		}
+		session.AutoDenyTools = autoDenyTools.String
+		session.Budget = budget.String
+		session.Template = template.String
+		session.SSHHost = sshHost.String
+		session.ContainerImage = containerImage.String
+		session.CompletionSummary = completionSummary.String
+		session.RetryOf = retryOf.String
+		session.ContextPackID = contextPackID.String
 
 		sessions = append(sessions, &session)
 	}
@@ -2861,16 +4209,25 @@ func (s *SQLiteStore) CreateApproval(ctx context.Context, approval *Approval) er
 		return fmt.Errorf("invalid approval status: %s", approval.Status)
 	}
 
+	var attachments sql.NullString
+	if len(approval.Attachments) > 0 {
+		data, err := json.Marshal(approval.Attachments)
+		if err != nil {
+			return fmt.Errorf("failed to marshal attachments: %w", err)
+		}
+		attachments = sql.NullString{String: string(data), Valid: true}
+	}
+
 	query := `
 		INSERT INTO approvals (
 			id, run_id, session_id, tool_use_id, status, created_at,
-			tool_name, tool_input, comment
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+			tool_name, tool_input, comment, attachments
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	_, err := s.db.ExecContext(ctx, query,
 		approval.ID, approval.RunID, approval.SessionID, approval.ToolUseID, approval.Status.String(), approval.CreatedAt,
-		approval.ToolName, string(approval.ToolInput), approval.Comment,
+		approval.ToolName, string(approval.ToolInput), approval.Comment, attachments,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create approval: %w", err)
@@ -2882,7 +4239,7 @@ func (s *SQLiteStore) CreateApproval(ctx context.Context, approval *Approval) er
 func (s *SQLiteStore) GetApproval(ctx context.Context, id string) (*Approval, error) {
 	query := `
 		SELECT id, run_id, session_id, tool_use_id, status, created_at, responded_at,
-			tool_name, tool_input, comment
+			tool_name, tool_input, comment, comment_expansion, constraints, attachments
 		FROM approvals WHERE id = ?
 	`
 
@@ -2890,13 +4247,16 @@ func (s *SQLiteStore) GetApproval(ctx context.Context, id string) (*Approval, er
 	var toolUseID sql.NullString
 	var respondedAt sql.NullTime
 	var comment sql.NullString
+	var expansion sql.NullString
+	var constraints sql.NullString
 	var statusStr string
 	var toolInputStr string
+	var attachments sql.NullString
 
 	err := s.db.QueryRowContext(ctx, query, id).Scan(
 		&approval.ID, &approval.RunID, &approval.SessionID, &toolUseID, &statusStr,
 		&approval.CreatedAt, &respondedAt,
-		&approval.ToolName, &toolInputStr, &comment,
+		&approval.ToolName, &toolInputStr, &comment, &expansion, &constraints, &attachments,
 	)
 	if err == sql.ErrNoRows {
 		return nil, &NotFoundError{Type: "approval", ID: id}
@@ -2919,24 +4279,52 @@ func (s *SQLiteStore) GetApproval(ctx context.Context, id string) (*Approval, er
 		approval.RespondedAt = &respondedAt.Time
 	}
 	approval.Comment = comment.String
+	approval.CommentExpansion = expansion.String
 	approval.ToolInput = json.RawMessage(toolInputStr)
+	if constraints.Valid {
+		if err := json.Unmarshal([]byte(constraints.String), &approval.Constraints); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal approval constraints: %w", err)
+		}
+	}
+	if attachments.Valid {
+		if err := json.Unmarshal([]byte(attachments.String), &approval.Attachments); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal attachments: %w", err)
+		}
+	}
 
 	return &approval, nil
 }
 
 // GetPendingApprovals retrieves all pending approvals for a session
 func (s *SQLiteStore) GetPendingApprovals(ctx context.Context, sessionID string) ([]*Approval, error) {
+	return s.queryApprovals(ctx, `session_id = ? AND status = ?`, sessionID, ApprovalStatusLocalPending.String())
+}
+
+// ListPendingApprovals retrieves the pending approvals of every session,
+// oldest first
+func (s *SQLiteStore) ListPendingApprovals(ctx context.Context) ([]*Approval, error) {
+	return s.queryApprovals(ctx, `status = ?`, ApprovalStatusLocalPending.String())
+}
+
+// ListSessionApprovals retrieves a session's approvals whatever their
+// status, oldest first
+func (s *SQLiteStore) ListSessionApprovals(ctx context.Context, sessionID string) ([]*Approval, error) {
+	return s.queryApprovals(ctx, `session_id = ?`, sessionID)
+}
+
+// queryApprovals retrieves the approvals matching a WHERE clause, oldest first
+func (s *SQLiteStore) queryApprovals(ctx context.Context, where string, args ...interface{}) ([]*Approval, error) {
 	query := `
 		SELECT id, run_id, session_id, tool_use_id, status, created_at, responded_at,
-			tool_name, tool_input, comment
+			tool_name, tool_input, comment, comment_expansion, constraints, attachments
 		FROM approvals
-		WHERE session_id = ? AND status = ?
+		WHERE ` + where + `
 		ORDER BY created_at ASC
 	`
 
-	rows, err := s.db.QueryContext(ctx, query, sessionID, ApprovalStatusLocalPending.String())
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get pending approvals: %w", err)
+		return nil, fmt.Errorf("failed to get approvals: %w", err)
 	}
 	defer func() { _ = rows.Close() }()
 
@@ -2946,13 +4334,16 @@ func (s *SQLiteStore) GetPendingApprovals(ctx context.Context, sessionID string)
 		var toolUseID sql.NullString
 		var respondedAt sql.NullTime
 		var comment sql.NullString
+		var expansion sql.NullString
+		var constraints sql.NullString
 		var statusStr string
 		var toolInputStr string
+		var attachments sql.NullString
 
 		err := rows.Scan(
 			&approval.ID, &approval.RunID, &approval.SessionID, &toolUseID, &statusStr,
 			&approval.CreatedAt, &respondedAt,
-			&approval.ToolName, &toolInputStr, &comment,
+			&approval.ToolName, &toolInputStr, &comment, &expansion, &constraints, &attachments,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan approval: %w", err)
@@ -2972,7 +4363,18 @@ func (s *SQLiteStore) GetPendingApprovals(ctx context.Context, sessionID string)
 			approval.RespondedAt = &respondedAt.Time
 		}
 		approval.Comment = comment.String
+		approval.CommentExpansion = expansion.String
 		approval.ToolInput = json.RawMessage(toolInputStr)
+		if constraints.Valid {
+			if err := json.Unmarshal([]byte(constraints.String), &approval.Constraints); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal approval constraints: %w", err)
+			}
+		}
+		if attachments.Valid {
+			if err := json.Unmarshal([]byte(attachments.String), &approval.Attachments); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal attachments: %w", err)
+			}
+		}
 
 		approvals = append(approvals, &approval)
 	}
@@ -3022,6 +4424,44 @@ func (s *SQLiteStore) UpdateApprovalResponse(ctx context.Context, id string, sta
 	return nil
 }
 
+// SetApprovalCommentExpansion stores AI-written guidance expanding a pending
+// approval's denial comment; the approver's own comment is kept as it is
+func (s *SQLiteStore) SetApprovalCommentExpansion(ctx context.Context, id string, expansion string) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE approvals SET comment_expansion = ? WHERE id = ?
+	`, expansion, id)
+	if err != nil {
+		return fmt.Errorf("failed to store comment expansion: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return &NotFoundError{Type: "approval", ID: id}
+	}
+	return nil
+}
+
+// SetApprovalConstraints limits what a pending approval allows once it's
+// approved
+func (s *SQLiteStore) SetApprovalConstraints(ctx context.Context, id string, constraints *ApprovalConstraints) error {
+	approval, err := s.GetApproval(ctx, id)
+	if err != nil {
+		return err
+	}
+	if approval.Status != ApprovalStatusLocalPending {
+		return &AlreadyDecidedError{ID: id, Status: approval.Status.String()}
+	}
+	encoded, err := json.Marshal(constraints)
+	if err != nil {
+		return fmt.Errorf("failed to marshal approval constraints: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx, `
+		UPDATE approvals SET constraints = ? WHERE id = ? AND status = ?
+	`, string(encoded), id, ApprovalStatusLocalPending.String())
+	if err != nil {
+		return fmt.Errorf("failed to store approval constraints: %w", err)
+	}
+	return nil
+}
+
 // StoreApprovalImages stores image paths for an approval decision
 func (s *SQLiteStore) StoreApprovalImages(ctx context.Context, approvalID string, imagePaths []string) error {
 	if len(imagePaths) == 0 {
@@ -0,0 +1,117 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const aiOutputColumns = `id, kind, session_id, template, template_version, provider, model, prompt, response,
+		created_at, rating, comment, rated_at`
+
+// CreateAIOutput records a model's answer
+func (s *SQLiteStore) CreateAIOutput(ctx context.Context, output *AIOutput) error {
+	if output.CreatedAt.IsZero() {
+		output.CreatedAt = time.Now()
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO ai_outputs (`+aiOutputColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, output.ID, output.Kind, nullIfEmpty(output.SessionID), output.Template, output.TemplateVersion,
+		nullIfEmpty(output.Provider), nullIfEmpty(output.Model), output.Prompt, output.Response, output.CreatedAt,
+		nullIfEmpty(output.Rating), nullIfEmpty(output.Comment), output.RatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create AI output: %w", err)
+	}
+	return nil
+}
+
+// GetAIOutput retrieves an AI output by ID
+func (s *SQLiteStore) GetAIOutput(ctx context.Context, id string) (*AIOutput, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+aiOutputColumns+` FROM ai_outputs WHERE id = ?`, id)
+	output, err := scanAIOutput(row)
+	if err == sql.ErrNoRows {
+		return nil, &NotFoundError{Type: "AI output", ID: id}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get AI output: %w", err)
+	}
+	return output, nil
+}
+
+// SetAIOutputFeedback replaces an output's rating and comment
+func (s *SQLiteStore) SetAIOutputFeedback(ctx context.Context, id, rating, comment string) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE ai_outputs SET rating = ?, comment = ?, rated_at = ? WHERE id = ?
+	`, rating, nullIfEmpty(comment), time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to store feedback: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return &NotFoundError{Type: "AI output", ID: id}
+	}
+	return nil
+}
+
+// ListAIOutputs retrieves outputs matching filter, oldest first
+func (s *SQLiteStore) ListAIOutputs(ctx context.Context, filter AIOutputFilter) ([]*AIOutput, error) {
+	var where []string
+	var args []interface{}
+	if filter.Kind != "" {
+		where = append(where, "kind = ?")
+		args = append(args, filter.Kind)
+	}
+	if filter.Template != "" {
+		where = append(where, "template = ?")
+		args = append(args, filter.Template)
+	}
+	if !filter.Since.IsZero() {
+		where = append(where, "created_at >= ?")
+		args = append(args, filter.Since)
+	}
+	if filter.Rated {
+		where = append(where, "rating IS NOT NULL")
+	}
+	query := `SELECT ` + aiOutputColumns + ` FROM ai_outputs`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY created_at, id"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list AI outputs: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var outputs []*AIOutput
+	for rows.Next() {
+		output, err := scanAIOutput(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan AI output: %w", err)
+		}
+		outputs = append(outputs, output)
+	}
+	return outputs, rows.Err()
+}
+
+func scanAIOutput(row interface{ Scan(...any) error }) (*AIOutput, error) {
+	var output AIOutput
+	var sessionID, provider, model, rating, comment sql.NullString
+	var ratedAt sql.NullTime
+	if err := row.Scan(&output.ID, &output.Kind, &sessionID, &output.Template, &output.TemplateVersion,
+		&provider, &model, &output.Prompt, &output.Response, &output.CreatedAt, &rating, &comment, &ratedAt); err != nil {
+		return nil, err
+	}
+	output.SessionID = sessionID.String
+	output.Provider = provider.String
+	output.Model = model.String
+	output.Rating = rating.String
+	output.Comment = comment.String
+	if ratedAt.Valid {
+		output.RatedAt = &ratedAt.Time
+	}
+	return &output, nil
+}
@@ -0,0 +1,58 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAIOutputs(t *testing.T) {
+	dbPath := testutil.DatabasePath(t, "sqlite-ai-outputs")
+	store, err := NewSQLiteStore(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	old := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, store.CreateAIOutput(ctx, &AIOutput{
+		ID: "out-1", Kind: AIOutputCommitMessage, SessionID: "sess-1", Template: "commit-message", TemplateVersion: "abc",
+		Provider: "anthropic", Model: "claude-haiku", Prompt: "p", Response: "r", CreatedAt: old,
+	}))
+	require.NoError(t, store.CreateAIOutput(ctx, &AIOutput{
+		ID: "out-2", Kind: AIOutputEphemeralChat, Template: "ephemeral-chat", TemplateVersion: "def", Prompt: "p2", Response: "r2",
+	}))
+
+	output, err := store.GetAIOutput(ctx, "out-1")
+	require.NoError(t, err)
+	assert.Equal(t, "claude-haiku", output.Model)
+	assert.Empty(t, output.Rating)
+	assert.Nil(t, output.RatedAt)
+
+	require.NoError(t, store.SetAIOutputFeedback(ctx, "out-1", RatingDown, "wrong scope"))
+	assert.True(t, errors.Is(store.SetAIOutputFeedback(ctx, "missing", RatingUp, ""), ErrNotFound))
+
+	output, err = store.GetAIOutput(ctx, "out-1")
+	require.NoError(t, err)
+	assert.Equal(t, RatingDown, output.Rating)
+	assert.Equal(t, "wrong scope", output.Comment)
+	assert.NotNil(t, output.RatedAt)
+
+	rated, err := store.ListAIOutputs(ctx, AIOutputFilter{Rated: true})
+	require.NoError(t, err)
+	require.Len(t, rated, 1)
+	assert.Equal(t, "out-1", rated[0].ID)
+
+	recent, err := store.ListAIOutputs(ctx, AIOutputFilter{Since: time.Now().Add(-time.Hour)})
+	require.NoError(t, err)
+	require.Len(t, recent, 1)
+	assert.Equal(t, "out-2", recent[0].ID)
+
+	chat, err := store.ListAIOutputs(ctx, AIOutputFilter{Kind: AIOutputEphemeralChat})
+	require.NoError(t, err)
+	assert.Len(t, chat, 1)
+}
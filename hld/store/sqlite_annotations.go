@@ -0,0 +1,102 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CreateEventAnnotation attaches a note or bookmark to a conversation event
+func (s *SQLiteStore) CreateEventAnnotation(ctx context.Context, annotation *EventAnnotation) error {
+	var exists int
+	err := s.db.QueryRowContext(ctx, `
+		SELECT 1 FROM conversation_events WHERE id = ? AND session_id = ?
+	`, annotation.EventID, annotation.SessionID).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return &NotFoundError{Type: "conversation event", ID: fmt.Sprint(annotation.EventID)}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to look up conversation event: %w", err)
+	}
+
+	if annotation.CreatedAt.IsZero() {
+		annotation.CreatedAt = time.Now()
+	}
+	result, err := s.db.ExecContext(ctx, `
+		INSERT INTO event_annotations (session_id, event_id, kind, note, author, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, annotation.SessionID, annotation.EventID, annotation.Kind, nullIfEmpty(annotation.Note),
+		nullIfEmpty(annotation.Author), annotation.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create event annotation: %w", err)
+	}
+	annotation.ID, err = result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get annotation ID: %w", err)
+	}
+	return nil
+}
+
+// ListEventAnnotations retrieves annotations matching filter, oldest first
+func (s *SQLiteStore) ListEventAnnotations(ctx context.Context, filter AnnotationFilter) ([]*EventAnnotation, error) {
+	var where []string
+	var args []interface{}
+	if filter.SessionID != "" {
+		where = append(where, "session_id = ?")
+		args = append(args, filter.SessionID)
+	}
+	if filter.EventID != 0 {
+		where = append(where, "event_id = ?")
+		args = append(args, filter.EventID)
+	}
+	if filter.Kind != "" {
+		where = append(where, "kind = ?")
+		args = append(args, filter.Kind)
+	}
+	if filter.Text != "" {
+		where = append(where, "note LIKE ?")
+		args = append(args, "%"+filter.Text+"%")
+	}
+	query := `SELECT id, session_id, event_id, kind, note, author, created_at FROM event_annotations`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY created_at, id"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list event annotations: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var annotations []*EventAnnotation
+	for rows.Next() {
+		var a EventAnnotation
+		var note, author sql.NullString
+		if err := rows.Scan(&a.ID, &a.SessionID, &a.EventID, &a.Kind, &note, &author, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan event annotation: %w", err)
+		}
+		a.Note = note.String
+		a.Author = author.String
+		annotations = append(annotations, &a)
+	}
+	return annotations, rows.Err()
+}
+
+// DeleteEventAnnotation removes an annotation
+func (s *SQLiteStore) DeleteEventAnnotation(ctx context.Context, id int64) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM event_annotations WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete event annotation: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return &NotFoundError{Type: "event annotation", ID: fmt.Sprint(id)}
+	}
+	return nil
+}
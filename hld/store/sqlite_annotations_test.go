@@ -0,0 +1,53 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventAnnotations(t *testing.T) {
+	dbPath := testutil.DatabasePath(t, "sqlite-annotations")
+	store, err := NewSQLiteStore(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	require.NoError(t, store.CreateSession(ctx, &Session{
+		ID: "sess-1", RunID: "run-1", ClaudeSessionID: "claude-1", Query: "q",
+		Status: SessionStatusCompleted, CreatedAt: time.Now(), LastActivityAt: time.Now(),
+	}))
+	event := &ConversationEvent{SessionID: "sess-1", ClaudeSessionID: "claude-1", EventType: EventTypeMessage, Role: "assistant", Content: "hi"}
+	require.NoError(t, store.AddConversationEvent(ctx, event))
+
+	err = store.CreateEventAnnotation(ctx, &EventAnnotation{SessionID: "other", EventID: event.ID, Kind: AnnotationKindNote, Note: "x"})
+	assert.True(t, errors.Is(err, ErrNotFound), "event from another session: %v", err)
+
+	note := &EventAnnotation{SessionID: "sess-1", EventID: event.ID, Kind: AnnotationKindNote, Note: "Went wrong here", Author: "sam"}
+	require.NoError(t, store.CreateEventAnnotation(ctx, note))
+	assert.NotZero(t, note.ID)
+	require.NoError(t, store.CreateEventAnnotation(ctx, &EventAnnotation{SessionID: "sess-1", EventID: event.ID, Kind: AnnotationKindBookmark}))
+
+	all, err := store.ListEventAnnotations(ctx, AnnotationFilter{SessionID: "sess-1"})
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "sam", all[0].Author)
+
+	found, err := store.ListEventAnnotations(ctx, AnnotationFilter{Text: "went WRONG"})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, note.ID, found[0].ID)
+
+	bookmarks, err := store.ListEventAnnotations(ctx, AnnotationFilter{Kind: AnnotationKindBookmark})
+	require.NoError(t, err)
+	require.Len(t, bookmarks, 1)
+	assert.Empty(t, bookmarks[0].Note)
+
+	require.NoError(t, store.DeleteEventAnnotation(ctx, note.ID))
+	assert.True(t, errors.Is(store.DeleteEventAnnotation(ctx, note.ID), ErrNotFound))
+}
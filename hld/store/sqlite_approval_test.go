@@ -122,3 +122,64 @@ func TestApprovalErrors(t *testing.T) {
 		assert.Equal(t, ApprovalStatusLocalDenied.String(), alreadyDecidedErr.Status)
 	})
 }
+
+func TestApprovalAttachments(t *testing.T) {
+	store, err := NewSQLiteStore(testutil.DatabasePath(t, "sqlite-approval-attachments"))
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+	ctx := context.Background()
+	require.NoError(t, store.CreateSession(ctx, &Session{ID: "sess-1", RunID: "run-1", Status: SessionStatusRunning, CreatedAt: time.Now(), LastActivityAt: time.Now()}))
+
+	for _, approval := range []*Approval{
+		{ID: "with", Attachments: []string{"art-1", "art-2"}},
+		{ID: "without"},
+	} {
+		approval.RunID, approval.SessionID = "run-1", "sess-1"
+		approval.Status, approval.CreatedAt = ApprovalStatusLocalPending, time.Now()
+		approval.ToolName, approval.ToolInput = "Bash", json.RawMessage(`{}`)
+		require.NoError(t, store.CreateApproval(ctx, approval))
+	}
+
+	got, err := store.GetApproval(ctx, "with")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"art-1", "art-2"}, got.Attachments)
+
+	pending, err := store.GetPendingApprovals(ctx, "sess-1")
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	for _, approval := range pending {
+		if approval.ID == "without" {
+			assert.Nil(t, approval.Attachments)
+		}
+	}
+}
+
+func TestListSessionApprovals(t *testing.T) {
+	store, err := NewSQLiteStore(testutil.DatabasePath(t, "sqlite-approval-list"))
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+	ctx := context.Background()
+	for _, id := range []string{"sess-1", "sess-2"} {
+		require.NoError(t, store.CreateSession(ctx, &Session{ID: id, RunID: "run-" + id, Status: SessionStatusRunning, CreatedAt: time.Now(), LastActivityAt: time.Now()}))
+	}
+
+	start := time.Now()
+	for i, approval := range []*Approval{
+		{ID: "first", SessionID: "sess-1"},
+		{ID: "second", SessionID: "sess-1"},
+		{ID: "other", SessionID: "sess-2"},
+	} {
+		approval.RunID, approval.Status = "run-"+approval.SessionID, ApprovalStatusLocalPending
+		approval.CreatedAt = start.Add(time.Duration(i) * time.Second)
+		approval.ToolName, approval.ToolInput = "Bash", json.RawMessage(`{}`)
+		require.NoError(t, store.CreateApproval(ctx, approval))
+	}
+	require.NoError(t, store.UpdateApprovalResponse(ctx, "first", ApprovalStatusLocalDenied, "no"))
+
+	approvals, err := store.ListSessionApprovals(ctx, "sess-1")
+	require.NoError(t, err)
+	require.Len(t, approvals, 2)
+	assert.Equal(t, "first", approvals[0].ID)
+	assert.Equal(t, ApprovalStatusLocalDenied, approvals[0].Status)
+	assert.Equal(t, "second", approvals[1].ID)
+}
@@ -0,0 +1,92 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const artifactColumns = `id, session_id, name, kind, content_type, size, sha256, created_at, expires_at`
+
+// CreateArtifact records an artifact whose content is already stored
+func (s *SQLiteStore) CreateArtifact(ctx context.Context, artifact *Artifact) error {
+	if artifact.CreatedAt.IsZero() {
+		artifact.CreatedAt = time.Now()
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO artifacts (`+artifactColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, artifact.ID, artifact.SessionID, artifact.Name, artifact.Kind, artifact.ContentType,
+		artifact.Size, artifact.SHA256, artifact.CreatedAt, artifact.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to create artifact: %w", err)
+	}
+	return nil
+}
+
+func scanArtifact(row interface{ Scan(...any) error }) (*Artifact, error) {
+	var artifact Artifact
+	var expiresAt sql.NullTime
+	if err := row.Scan(&artifact.ID, &artifact.SessionID, &artifact.Name, &artifact.Kind, &artifact.ContentType,
+		&artifact.Size, &artifact.SHA256, &artifact.CreatedAt, &expiresAt); err != nil {
+		return nil, err
+	}
+	if expiresAt.Valid {
+		artifact.ExpiresAt = &expiresAt.Time
+	}
+	return &artifact, nil
+}
+
+// GetArtifact returns an artifact by ID
+func (s *SQLiteStore) GetArtifact(ctx context.Context, id string) (*Artifact, error) {
+	artifact, err := scanArtifact(s.db.QueryRowContext(ctx, `SELECT `+artifactColumns+` FROM artifacts WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &NotFoundError{Type: "artifact", ID: id}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get artifact: %w", err)
+	}
+	return artifact, nil
+}
+
+func (s *SQLiteStore) queryArtifacts(ctx context.Context, query string, args ...any) ([]*Artifact, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+artifactColumns+` FROM artifacts `+query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list artifacts: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	artifacts := []*Artifact{}
+	for rows.Next() {
+		artifact, err := scanArtifact(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan artifact: %w", err)
+		}
+		artifacts = append(artifacts, artifact)
+	}
+	return artifacts, rows.Err()
+}
+
+// ListSessionArtifacts returns a session's artifacts, oldest first
+func (s *SQLiteStore) ListSessionArtifacts(ctx context.Context, sessionID string) ([]*Artifact, error) {
+	return s.queryArtifacts(ctx, `WHERE session_id = ? ORDER BY created_at, id`, sessionID)
+}
+
+// ListExpiredArtifacts returns artifacts whose retention ended before a time
+func (s *SQLiteStore) ListExpiredArtifacts(ctx context.Context, before time.Time) ([]*Artifact, error) {
+	return s.queryArtifacts(ctx, `WHERE expires_at IS NOT NULL AND expires_at < ? ORDER BY expires_at`, before)
+}
+
+// DeleteArtifact removes an artifact's record
+func (s *SQLiteStore) DeleteArtifact(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM artifacts WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete artifact: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return &NotFoundError{Type: "artifact", ID: id}
+	}
+	return nil
+}
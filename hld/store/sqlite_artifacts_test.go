@@ -0,0 +1,49 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArtifacts(t *testing.T) {
+	dbPath := testutil.DatabasePath(t, "sqlite-artifacts")
+	store, err := NewSQLiteStore(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	now := time.Now()
+	expired := now.Add(-time.Hour)
+	later := now.Add(time.Hour)
+	require.NoError(t, store.CreateArtifact(ctx, &Artifact{ID: "a1", SessionID: "sess-1", Name: "app", Kind: "binary", ContentType: "application/octet-stream", Size: 10, SHA256: "x", CreatedAt: now.Add(-2 * time.Hour), ExpiresAt: &expired}))
+	require.NoError(t, store.CreateArtifact(ctx, &Artifact{ID: "a2", SessionID: "sess-1", Name: "coverage.html", ContentType: "text/html", Size: 20, SHA256: "y", ExpiresAt: &later}))
+	require.NoError(t, store.CreateArtifact(ctx, &Artifact{ID: "a3", SessionID: "sess-2", Name: "kept.png", ContentType: "image/png", Size: 30, SHA256: "z"}))
+
+	artifacts, err := store.ListSessionArtifacts(ctx, "sess-1")
+	require.NoError(t, err)
+	require.Len(t, artifacts, 2)
+	assert.Equal(t, "a1", artifacts[0].ID)
+	assert.Equal(t, "binary", artifacts[0].Kind)
+	require.NotNil(t, artifacts[0].ExpiresAt)
+
+	got, err := store.GetArtifact(ctx, "a3")
+	require.NoError(t, err)
+	assert.Nil(t, got.ExpiresAt)
+	assert.Equal(t, int64(30), got.Size)
+
+	expiredArtifacts, err := store.ListExpiredArtifacts(ctx, now)
+	require.NoError(t, err)
+	require.Len(t, expiredArtifacts, 1)
+	assert.Equal(t, "a1", expiredArtifacts[0].ID)
+
+	require.NoError(t, store.DeleteArtifact(ctx, "a1"))
+	assert.True(t, errors.Is(store.DeleteArtifact(ctx, "a1"), ErrNotFound))
+	_, err = store.GetArtifact(ctx, "a1")
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
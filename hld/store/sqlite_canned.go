@@ -0,0 +1,117 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CreateCannedResponse records a canned response
+func (s *SQLiteStore) CreateCannedResponse(ctx context.Context, response *CannedResponse) error {
+	now := time.Now()
+	response.CreatedAt, response.UpdatedAt = now, now
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO canned_responses (id, team, title, text, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, response.ID, response.Team, response.Title, response.Text, response.CreatedAt, response.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create canned response: %w", err)
+	}
+	return nil
+}
+
+const cannedResponseColumns = `id, team, title, text, use_count, last_used_at, created_at, updated_at`
+
+func scanCannedResponse(row interface{ Scan(...any) error }) (*CannedResponse, error) {
+	var response CannedResponse
+	var lastUsedAt sql.NullTime
+	err := row.Scan(&response.ID, &response.Team, &response.Title, &response.Text,
+		&response.UseCount, &lastUsedAt, &response.CreatedAt, &response.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if lastUsedAt.Valid {
+		response.LastUsedAt = &lastUsedAt.Time
+	}
+	return &response, nil
+}
+
+// GetCannedResponse retrieves a canned response
+func (s *SQLiteStore) GetCannedResponse(ctx context.Context, id string) (*CannedResponse, error) {
+	response, err := scanCannedResponse(s.db.QueryRowContext(ctx,
+		`SELECT `+cannedResponseColumns+` FROM canned_responses WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &NotFoundError{Type: "canned response", ID: id}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get canned response: %w", err)
+	}
+	return response, nil
+}
+
+// ListCannedResponses returns every canned response, most used first
+func (s *SQLiteStore) ListCannedResponses(ctx context.Context) ([]*CannedResponse, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+cannedResponseColumns+` FROM canned_responses ORDER BY use_count DESC, title`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list canned responses: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	responses := []*CannedResponse{}
+	for rows.Next() {
+		response, err := scanCannedResponse(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan canned response: %w", err)
+		}
+		responses = append(responses, response)
+	}
+	return responses, rows.Err()
+}
+
+// UpdateCannedResponse changes a canned response's team, title and text
+func (s *SQLiteStore) UpdateCannedResponse(ctx context.Context, response *CannedResponse) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE canned_responses SET team = ?, title = ?, text = ?, updated_at = ? WHERE id = ?
+	`, response.Team, response.Title, response.Text, time.Now(), response.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update canned response: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return &NotFoundError{Type: "canned response", ID: response.ID}
+	}
+	updated, err := s.GetCannedResponse(ctx, response.ID)
+	if err != nil {
+		return err
+	}
+	*response = *updated
+	return nil
+}
+
+// DeleteCannedResponse removes a canned response
+func (s *SQLiteStore) DeleteCannedResponse(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM canned_responses WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete canned response: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return &NotFoundError{Type: "canned response", ID: id}
+	}
+	return nil
+}
+
+// RecordCannedResponseUse counts a decision made with a canned response
+func (s *SQLiteStore) RecordCannedResponseUse(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE canned_responses SET use_count = use_count + 1, last_used_at = ? WHERE id = ?
+	`, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to record canned response use: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return &NotFoundError{Type: "canned response", ID: id}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/humanlayer/humanlayer/hld/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCannedResponses(t *testing.T) {
+	dbPath := testutil.DatabasePath(t, "sqlite-canned")
+	store, err := NewSQLiteStore(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	require.NoError(t, store.CreateCannedResponse(ctx, &CannedResponse{ID: "c1", Title: "Tests first", Text: "Needs tests first."}))
+	require.NoError(t, store.CreateCannedResponse(ctx, &CannedResponse{ID: "c2", Team: "web", Title: "Wrong directory", Text: "Use apps/web."}))
+
+	require.NoError(t, store.RecordCannedResponseUse(ctx, "c2"))
+	require.NoError(t, store.RecordCannedResponseUse(ctx, "c2"))
+	responses, err := store.ListCannedResponses(ctx)
+	require.NoError(t, err)
+	require.Len(t, responses, 2)
+	assert.Equal(t, "c2", responses[0].ID)
+	assert.Equal(t, 2, responses[0].UseCount)
+	assert.NotNil(t, responses[0].LastUsedAt)
+
+	updated := &CannedResponse{ID: "c2", Team: "frontend", Title: "Wrong directory", Text: "Use apps/frontend."}
+	require.NoError(t, store.UpdateCannedResponse(ctx, updated))
+	assert.Equal(t, 2, updated.UseCount, "updating keeps usage")
+	got, err := store.GetCannedResponse(ctx, "c2")
+	require.NoError(t, err)
+	assert.Equal(t, "frontend", got.Team)
+
+	require.NoError(t, store.DeleteCannedResponse(ctx, "c1"))
+	_, err = store.GetCannedResponse(ctx, "c1")
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.True(t, errors.Is(store.RecordCannedResponseUse(ctx, "c1"), ErrNotFound))
+}
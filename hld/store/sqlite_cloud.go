@@ -0,0 +1,94 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CreateCloudMirror records an approval mirrored to HumanLayer cloud
+func (s *SQLiteStore) CreateCloudMirror(ctx context.Context, mirror *CloudMirror) error {
+	if mirror.State == "" {
+		mirror.State = CloudMirrorOpen
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO cloud_mirrors (approval_id, call_id, state, created_at)
+		VALUES (?, ?, ?, ?)
+	`, mirror.ApprovalID, mirror.CallID, mirror.State, mirror.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create cloud mirror: %w", err)
+	}
+	return nil
+}
+
+// GetCloudMirror retrieves the mirror of an approval
+func (s *SQLiteStore) GetCloudMirror(ctx context.Context, approvalID string) (*CloudMirror, error) {
+	mirror, err := scanCloudMirror(s.db.QueryRowContext(ctx, `
+		SELECT approval_id, call_id, state, remote_approved, remote_comment, created_at, settled_at
+		FROM cloud_mirrors WHERE approval_id = ?
+	`, approvalID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &NotFoundError{Type: "cloud mirror", ID: approvalID}
+	}
+	return mirror, err
+}
+
+// ListOpenCloudMirrors returns the mirrors not yet settled, oldest first
+func (s *SQLiteStore) ListOpenCloudMirrors(ctx context.Context) ([]*CloudMirror, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT approval_id, call_id, state, remote_approved, remote_comment, created_at, settled_at
+		FROM cloud_mirrors WHERE state = ?
+		ORDER BY created_at, approval_id
+	`, CloudMirrorOpen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cloud mirrors: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	mirrors := []*CloudMirror{}
+	for rows.Next() {
+		mirror, err := scanCloudMirror(rows)
+		if err != nil {
+			return nil, err
+		}
+		mirrors = append(mirrors, mirror)
+	}
+	return mirrors, rows.Err()
+}
+
+// SettleCloudMirror records how an open mirror ended, returning false if it
+// was already settled
+func (s *SQLiteStore) SettleCloudMirror(ctx context.Context, approvalID, state string, remoteApproved *bool, remoteComment string) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE cloud_mirrors
+		SET state = ?, remote_approved = ?, remote_comment = ?, settled_at = ?
+		WHERE approval_id = ? AND state = ?
+	`, state, remoteApproved, remoteComment, time.Now(), approvalID, CloudMirrorOpen)
+	if err != nil {
+		return false, fmt.Errorf("failed to settle cloud mirror: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+func scanCloudMirror(row interface{ Scan(...any) error }) (*CloudMirror, error) {
+	mirror := &CloudMirror{}
+	var remoteApproved sql.NullBool
+	var settledAt sql.NullTime
+	if err := row.Scan(&mirror.ApprovalID, &mirror.CallID, &mirror.State, &remoteApproved,
+		&mirror.RemoteComment, &mirror.CreatedAt, &settledAt); err != nil {
+		return nil, err
+	}
+	if remoteApproved.Valid {
+		mirror.RemoteApproved = &remoteApproved.Bool
+	}
+	if settledAt.Valid {
+		mirror.SettledAt = &settledAt.Time
+	}
+	return mirror, nil
+}
@@ -0,0 +1,70 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// CreateSessionCommit records a commit the daemon made; recording it again is a no-op
+func (s *SQLiteStore) CreateSessionCommit(ctx context.Context, commit *SessionCommit) error {
+	if commit.CreatedAt.IsZero() {
+		commit.CreatedAt = time.Now()
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT OR IGNORE INTO session_commits (session_id, repository, hash, additions, deletions, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, commit.SessionID, commit.Repository, commit.Hash, commit.Additions, commit.Deletions, commit.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create session commit: %w", err)
+	}
+	return nil
+}
+
+// ListSessionCommits returns the commits made since a time, oldest first
+func (s *SQLiteStore) ListSessionCommits(ctx context.Context, since time.Time) ([]*SessionCommit, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT session_id, repository, hash, additions, deletions, created_at
+		FROM session_commits WHERE created_at >= ?
+		ORDER BY created_at, hash
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list session commits: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var commits []*SessionCommit
+	for rows.Next() {
+		var commit SessionCommit
+		if err := rows.Scan(&commit.SessionID, &commit.Repository, &commit.Hash,
+			&commit.Additions, &commit.Deletions, &commit.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan session commit: %w", err)
+		}
+		commits = append(commits, &commit)
+	}
+	return commits, rows.Err()
+}
+
+// CountApprovals counts approvals created since a time by session and status
+func (s *SQLiteStore) CountApprovals(ctx context.Context, since time.Time) ([]ApprovalCount, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT session_id, status, COUNT(*)
+		FROM approvals WHERE created_at >= ?
+		GROUP BY session_id, status
+		ORDER BY session_id, status
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count approvals: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var counts []ApprovalCount
+	for rows.Next() {
+		var count ApprovalCount
+		if err := rows.Scan(&count.SessionID, &count.Status, &count.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan approval count: %w", err)
+		}
+		counts = append(counts, count)
+	}
+	return counts, rows.Err()
+}
@@ -0,0 +1,55 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionCommitsAndApprovalCounts(t *testing.T) {
+	dbPath := testutil.DatabasePath(t, "sqlite-commits")
+	store, err := NewSQLiteStore(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	now := time.Now()
+	require.NoError(t, store.CreateSessionCommit(ctx, &SessionCommit{
+		SessionID: "s1", Repository: "/src/app", Hash: "old", CreatedAt: now.Add(-48 * time.Hour),
+	}))
+	require.NoError(t, store.CreateSessionCommit(ctx, &SessionCommit{
+		SessionID: "s1", Repository: "/src/app", Hash: "abc", Additions: 10, Deletions: 2,
+	}))
+	require.NoError(t, store.CreateSessionCommit(ctx, &SessionCommit{
+		SessionID: "s1", Repository: "/src/app", Hash: "abc", Additions: 99,
+	}), "recording twice is a no-op")
+
+	commits, err := store.ListSessionCommits(ctx, now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, commits, 1)
+	assert.Equal(t, "abc", commits[0].Hash)
+	assert.Equal(t, 10, commits[0].Additions)
+
+	require.NoError(t, store.CreateSession(ctx, &Session{ID: "s1", RunID: "r1", Status: SessionStatusRunning, CreatedAt: now, LastActivityAt: now}))
+	for i, status := range []ApprovalStatus{ApprovalStatusLocalPending, ApprovalStatusLocalPending, ApprovalStatusLocalApproved} {
+		approval := &Approval{
+			ID: "a" + string(rune('0'+i)), RunID: "r1", SessionID: "s1", Status: ApprovalStatusLocalPending,
+			CreatedAt: now, ToolName: "Bash", ToolInput: json.RawMessage(`{}`),
+		}
+		require.NoError(t, store.CreateApproval(ctx, approval))
+		if status != ApprovalStatusLocalPending {
+			require.NoError(t, store.UpdateApprovalResponse(ctx, approval.ID, status, ""))
+		}
+	}
+	counts, err := store.CountApprovals(ctx, now.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, []ApprovalCount{
+		{SessionID: "s1", Status: ApprovalStatusLocalApproved, Count: 1},
+		{SessionID: "s1", Status: ApprovalStatusLocalPending, Count: 2},
+	}, counts)
+}
@@ -0,0 +1,51 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RecordConstraintViolation stores a tool call that broke its approval's
+// constraints
+func (s *SQLiteStore) RecordConstraintViolation(ctx context.Context, violation *ConstraintViolation) error {
+	if violation.CreatedAt.IsZero() {
+		violation.CreatedAt = time.Now()
+	}
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO approval_constraint_violations (approval_id, session_id, tool_use_id, kind, detail, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, violation.ApprovalID, violation.SessionID, violation.ToolUseID, violation.Kind, violation.Detail, violation.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record constraint violation: %w", err)
+	}
+	if id, err := res.LastInsertId(); err == nil {
+		violation.ID = id
+	}
+	return nil
+}
+
+// ListConstraintViolations returns a session's violations, or every
+// violation for an empty session ID, oldest first
+func (s *SQLiteStore) ListConstraintViolations(ctx context.Context, sessionID string) ([]*ConstraintViolation, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, approval_id, session_id, tool_use_id, kind, detail, created_at
+		FROM approval_constraint_violations
+		WHERE ? = '' OR session_id = ?
+		ORDER BY created_at, id
+	`, sessionID, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list constraint violations: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	violations := []*ConstraintViolation{}
+	for rows.Next() {
+		v := &ConstraintViolation{}
+		if err := rows.Scan(&v.ID, &v.ApprovalID, &v.SessionID, &v.ToolUseID, &v.Kind, &v.Detail, &v.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan constraint violation: %w", err)
+		}
+		violations = append(violations, v)
+	}
+	return violations, rows.Err()
+}
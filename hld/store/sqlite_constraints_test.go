@@ -0,0 +1,52 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApprovalConstraints(t *testing.T) {
+	dbPath := testutil.DatabasePath(t, "sqlite-constraints")
+	store, err := NewSQLiteStore(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	require.NoError(t, store.CreateSession(ctx, &Session{ID: "sess-1", RunID: "run-1", Status: SessionStatusRunning, CreatedAt: time.Now(), LastActivityAt: time.Now()}))
+	require.NoError(t, store.CreateApproval(ctx, &Approval{ID: "appr-1", RunID: "run-1", SessionID: "sess-1", Status: ApprovalStatusLocalPending, CreatedAt: time.Now(), ToolName: "Edit", ToolInput: json.RawMessage(`{}`)}))
+
+	expiresAt := time.Now().Add(10 * time.Minute).UTC().Truncate(time.Second)
+	require.NoError(t, store.SetApprovalConstraints(ctx, "appr-1", &ApprovalConstraints{ExpiresAt: &expiresAt, Files: []string{"main.go"}}))
+	approval, err := store.GetApproval(ctx, "appr-1")
+	require.NoError(t, err)
+	require.NotNil(t, approval.Constraints)
+	assert.True(t, expiresAt.Equal(*approval.Constraints.ExpiresAt))
+	assert.Equal(t, []string{"main.go"}, approval.Constraints.Files)
+
+	require.NoError(t, store.UpdateApprovalResponse(ctx, "appr-1", ApprovalStatusLocalApproved, ""))
+	err = store.SetApprovalConstraints(ctx, "appr-1", &ApprovalConstraints{Files: []string{"other.go"}})
+	assert.True(t, errors.Is(err, ErrAlreadyDecided))
+	assert.True(t, errors.Is(store.SetApprovalConstraints(ctx, "missing", &ApprovalConstraints{}), ErrNotFound))
+
+	violation := &ConstraintViolation{ApprovalID: "appr-1", SessionID: "sess-1", ToolUseID: "toolu_1", Kind: ConstraintViolationFile, Detail: ".env is not in the approved file list"}
+	require.NoError(t, store.RecordConstraintViolation(ctx, violation))
+	assert.NotZero(t, violation.ID)
+
+	violations, err := store.ListConstraintViolations(ctx, "sess-1")
+	require.NoError(t, err)
+	require.Len(t, violations, 1)
+	assert.Equal(t, ConstraintViolationFile, violations[0].Kind)
+	all, err := store.ListConstraintViolations(ctx, "")
+	require.NoError(t, err)
+	assert.Len(t, all, 1)
+	none, err := store.ListConstraintViolations(ctx, "sess-2")
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
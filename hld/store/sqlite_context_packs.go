@@ -0,0 +1,40 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// CreateContextPack stores an assembled context pack
+func (s *SQLiteStore) CreateContextPack(ctx context.Context, pack *ContextPack) error {
+	if pack.CreatedAt.IsZero() {
+		pack.CreatedAt = time.Now()
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO context_packs (id, query, working_dir, content, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, pack.ID, pack.Query, pack.WorkingDir, string(pack.Content), pack.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create context pack: %w", err)
+	}
+	return nil
+}
+
+// GetContextPack retrieves a context pack by ID
+func (s *SQLiteStore) GetContextPack(ctx context.Context, id string) (*ContextPack, error) {
+	var pack ContextPack
+	var content string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, query, working_dir, content, created_at FROM context_packs WHERE id = ?
+	`, id).Scan(&pack.ID, &pack.Query, &pack.WorkingDir, &content, &pack.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, &NotFoundError{Type: "context pack", ID: id}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get context pack: %w", err)
+	}
+	pack.Content = []byte(content)
+	return &pack, nil
+}
@@ -0,0 +1,41 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextPacks(t *testing.T) {
+	dbPath := testutil.DatabasePath(t, "sqlite-context-packs")
+	store, err := NewSQLiteStore(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	require.NoError(t, store.CreateContextPack(ctx, &ContextPack{
+		ID: "pack-1", Query: "fix the parser", WorkingDir: "/repo",
+		Content: []byte(`{"files":[{"path":"parser.go"}]}`), CreatedAt: time.Now(),
+	}))
+
+	pack, err := store.GetContextPack(ctx, "pack-1")
+	require.NoError(t, err)
+	assert.Equal(t, "fix the parser", pack.Query)
+	assert.JSONEq(t, `{"files":[{"path":"parser.go"}]}`, string(pack.Content))
+
+	_, err = store.GetContextPack(ctx, "missing")
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	require.NoError(t, store.CreateSession(ctx, &Session{
+		ID: "sess-1", RunID: "run-1", Query: "fix the parser", WorkingDir: "/repo",
+		Status: SessionStatusStarting, ContextPackID: "pack-1", CreatedAt: time.Now(), LastActivityAt: time.Now(),
+	}))
+	sess, err := store.GetSession(ctx, "sess-1")
+	require.NoError(t, err)
+	assert.Equal(t, "pack-1", sess.ContextPackID)
+}
@@ -0,0 +1,60 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// UpsertRemoteCredential stores a credential, replacing one for the same prefix
+func (s *SQLiteStore) UpsertRemoteCredential(ctx context.Context, credential *RemoteCredential) error {
+	now := time.Now()
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO remote_credentials (url_prefix, username, secret, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(url_prefix) DO UPDATE SET
+			username = excluded.username,
+			secret = excluded.secret,
+			updated_at = excluded.updated_at
+	`, credential.URLPrefix, credential.Username, credential.Secret, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to store remote credential: %w", err)
+	}
+	return s.db.QueryRowContext(ctx, `
+		SELECT created_at, updated_at FROM remote_credentials WHERE url_prefix = ?
+	`, credential.URLPrefix).Scan(&credential.CreatedAt, &credential.UpdatedAt)
+}
+
+// ListRemoteCredentials returns every credential by URL prefix
+func (s *SQLiteStore) ListRemoteCredentials(ctx context.Context) ([]*RemoteCredential, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT url_prefix, username, secret, created_at, updated_at
+		FROM remote_credentials ORDER BY url_prefix
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remote credentials: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	credentials := []*RemoteCredential{}
+	for rows.Next() {
+		var credential RemoteCredential
+		if err := rows.Scan(&credential.URLPrefix, &credential.Username, &credential.Secret, &credential.CreatedAt, &credential.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan remote credential: %w", err)
+		}
+		credentials = append(credentials, &credential)
+	}
+	return credentials, rows.Err()
+}
+
+// DeleteRemoteCredential removes the credential for urlPrefix
+func (s *SQLiteStore) DeleteRemoteCredential(ctx context.Context, urlPrefix string) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM remote_credentials WHERE url_prefix = ?`, urlPrefix)
+	if err != nil {
+		return fmt.Errorf("failed to delete remote credential: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return &NotFoundError{Type: "remote credential", ID: urlPrefix}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/humanlayer/humanlayer/hld/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteCredentials(t *testing.T) {
+	dbPath := testutil.DatabasePath(t, "sqlite-credentials")
+	store, err := NewSQLiteStore(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	first := &RemoteCredential{URLPrefix: "https://github.com/acme", Username: "bot", Secret: []byte{1, 2, 3}}
+	require.NoError(t, store.UpsertRemoteCredential(ctx, first))
+	require.NoError(t, store.UpsertRemoteCredential(ctx, &RemoteCredential{URLPrefix: "https://gitlab.com", Secret: []byte{4}}))
+
+	// Replacing keeps when it was first stored
+	replaced := &RemoteCredential{URLPrefix: "https://github.com/acme", Username: "other", Secret: []byte{9}}
+	require.NoError(t, store.UpsertRemoteCredential(ctx, replaced))
+	assert.True(t, replaced.CreatedAt.Equal(first.CreatedAt))
+
+	credentials, err := store.ListRemoteCredentials(ctx)
+	require.NoError(t, err)
+	require.Len(t, credentials, 2)
+	assert.Equal(t, "https://github.com/acme", credentials[0].URLPrefix)
+	assert.Equal(t, "other", credentials[0].Username)
+	assert.Equal(t, []byte{9}, credentials[0].Secret)
+
+	require.NoError(t, store.DeleteRemoteCredential(ctx, "https://gitlab.com"))
+	err = store.DeleteRemoteCredential(ctx, "https://gitlab.com")
+	assert.True(t, errors.Is(err, ErrNotFound))
+	credentials, err = store.ListRemoteCredentials(ctx)
+	require.NoError(t, err)
+	assert.Len(t, credentials, 1)
+}
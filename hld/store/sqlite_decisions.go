@@ -0,0 +1,153 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const decisionColumns = `id, repository, session_id, title, decision, rationale, alternatives, status, created_at, updated_at`
+
+// encodeAlternatives stores alternatives as a JSON array, or NULL when empty
+func encodeAlternatives(alternatives []string) (interface{}, error) {
+	if len(alternatives) == 0 {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(alternatives)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode alternatives: %w", err)
+	}
+	return string(encoded), nil
+}
+
+// CreateDecision adds a decision to the log
+func (s *SQLiteStore) CreateDecision(ctx context.Context, decision *Decision) error {
+	now := time.Now()
+	if decision.CreatedAt.IsZero() {
+		decision.CreatedAt = now
+	}
+	decision.UpdatedAt = decision.CreatedAt
+	alternatives, err := encodeAlternatives(decision.Alternatives)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO decisions (`+decisionColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, decision.ID, decision.Repository, nullIfEmpty(decision.SessionID), decision.Title, decision.Decision,
+		nullIfEmpty(decision.Rationale), alternatives, decision.Status, decision.CreatedAt, decision.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create decision: %w", err)
+	}
+	return nil
+}
+
+// GetDecision retrieves a decision by ID
+func (s *SQLiteStore) GetDecision(ctx context.Context, id string) (*Decision, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+decisionColumns+` FROM decisions WHERE id = ?`, id)
+	decision, err := scanDecision(row)
+	if err == sql.ErrNoRows {
+		return nil, &NotFoundError{Type: "decision", ID: id}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get decision: %w", err)
+	}
+	return decision, nil
+}
+
+// UpdateDecision saves a decision's editable fields
+func (s *SQLiteStore) UpdateDecision(ctx context.Context, decision *Decision) error {
+	decision.UpdatedAt = time.Now()
+	alternatives, err := encodeAlternatives(decision.Alternatives)
+	if err != nil {
+		return err
+	}
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE decisions
+		SET repository = ?, title = ?, decision = ?, rationale = ?, alternatives = ?, status = ?, updated_at = ?
+		WHERE id = ?
+	`, decision.Repository, decision.Title, decision.Decision, nullIfEmpty(decision.Rationale), alternatives,
+		decision.Status, decision.UpdatedAt, decision.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update decision: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return &NotFoundError{Type: "decision", ID: decision.ID}
+	}
+	return nil
+}
+
+// DeleteDecision removes a decision from the log
+func (s *SQLiteStore) DeleteDecision(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM decisions WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete decision: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return &NotFoundError{Type: "decision", ID: id}
+	}
+	return nil
+}
+
+// ListDecisions retrieves decisions matching filter, newest first
+func (s *SQLiteStore) ListDecisions(ctx context.Context, filter DecisionFilter) ([]*Decision, error) {
+	var where []string
+	var args []interface{}
+	if filter.Repository != "" {
+		where = append(where, "repository = ?")
+		args = append(args, filter.Repository)
+	}
+	if filter.SessionID != "" {
+		where = append(where, "session_id = ?")
+		args = append(args, filter.SessionID)
+	}
+	if filter.Status != "" {
+		where = append(where, "status = ?")
+		args = append(args, filter.Status)
+	}
+	query := `SELECT ` + decisionColumns + ` FROM decisions`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY created_at DESC, id"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list decisions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var decisions []*Decision
+	for rows.Next() {
+		decision, err := scanDecision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan decision: %w", err)
+		}
+		decisions = append(decisions, decision)
+	}
+	return decisions, rows.Err()
+}
+
+func scanDecision(row interface{ Scan(...any) error }) (*Decision, error) {
+	var decision Decision
+	var sessionID, rationale, alternatives sql.NullString
+	if err := row.Scan(&decision.ID, &decision.Repository, &sessionID, &decision.Title, &decision.Decision,
+		&rationale, &alternatives, &decision.Status, &decision.CreatedAt, &decision.UpdatedAt); err != nil {
+		return nil, err
+	}
+	decision.SessionID = sessionID.String
+	decision.Rationale = rationale.String
+	if alternatives.String != "" {
+		if err := json.Unmarshal([]byte(alternatives.String), &decision.Alternatives); err != nil {
+			return nil, fmt.Errorf("failed to decode alternatives: %w", err)
+		}
+	}
+	return &decision, nil
+}
@@ -0,0 +1,62 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecisions(t *testing.T) {
+	dbPath := testutil.DatabasePath(t, "sqlite-decisions")
+	store, err := NewSQLiteStore(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	require.NoError(t, store.CreateDecision(ctx, &Decision{
+		ID: "d1", Repository: "/repo", SessionID: "sess-1", Title: "Config", Decision: "Use viper",
+		Rationale: "Already used", Alternatives: []string{"koanf", "envconfig"}, Status: DecisionStatusActive,
+		CreatedAt: time.Now().Add(-time.Hour),
+	}))
+	require.NoError(t, store.CreateDecision(ctx, &Decision{
+		ID: "d2", Repository: "/repo", Title: "Logging", Decision: "Use slog", Status: DecisionStatusActive,
+	}))
+	require.NoError(t, store.CreateDecision(ctx, &Decision{
+		ID: "d3", Repository: "/other", Title: "ORM", Decision: "None", Status: DecisionStatusActive,
+	}))
+
+	decision, err := store.GetDecision(ctx, "d1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"koanf", "envconfig"}, decision.Alternatives)
+	assert.Equal(t, "sess-1", decision.SessionID)
+
+	list, err := store.ListDecisions(ctx, DecisionFilter{Repository: "/repo"})
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "d2", list[0].ID, "newest first")
+	assert.Nil(t, list[0].Alternatives)
+
+	decision.Status = DecisionStatusSuperseded
+	decision.Alternatives = nil
+	require.NoError(t, store.UpdateDecision(ctx, decision))
+	active, err := store.ListDecisions(ctx, DecisionFilter{Repository: "/repo", Status: DecisionStatusActive})
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	assert.Equal(t, "d2", active[0].ID)
+
+	bySession, err := store.ListDecisions(ctx, DecisionFilter{SessionID: "sess-1"})
+	require.NoError(t, err)
+	require.Len(t, bySession, 1)
+	assert.Nil(t, bySession[0].Alternatives)
+
+	require.NoError(t, store.DeleteDecision(ctx, "d3"))
+	assert.True(t, errors.Is(store.DeleteDecision(ctx, "d3"), ErrNotFound))
+	_, err = store.GetDecision(ctx, "d3")
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.True(t, errors.Is(store.UpdateDecision(ctx, &Decision{ID: "missing"}), ErrNotFound))
+}
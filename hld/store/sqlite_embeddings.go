@@ -0,0 +1,88 @@
+package store
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+)
+
+// encodeVector packs a vector as little-endian float32s
+func encodeVector(vector []float32) []byte {
+	buf := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(v))
+	}
+	return buf
+}
+
+func decodeVector(buf []byte) ([]float32, error) {
+	if len(buf)%4 != 0 {
+		return nil, fmt.Errorf("invalid vector length %d", len(buf))
+	}
+	vector := make([]float32, len(buf)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return vector, nil
+}
+
+// ReplaceSessionEmbeddings swaps a session's embeddings for the given ones
+func (s *SQLiteStore) ReplaceSessionEmbeddings(ctx context.Context, sessionID string, embeddings []*SessionEmbedding) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM session_embeddings WHERE session_id = ?`, sessionID); err != nil {
+		return fmt.Errorf("failed to delete session embeddings: %w", err)
+	}
+	now := time.Now()
+	for _, embedding := range embeddings {
+		embedding.SessionID = sessionID
+		if embedding.CreatedAt.IsZero() {
+			embedding.CreatedAt = now
+		}
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO session_embeddings (session_id, source, content, model, vector, created_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, sessionID, embedding.Source, embedding.Content, embedding.Model, encodeVector(embedding.Vector), embedding.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to store session embedding: %w", err)
+		}
+		if embedding.ID, err = result.LastInsertId(); err != nil {
+			return fmt.Errorf("failed to get embedding ID: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// ListSessionEmbeddings retrieves every embedding made with model
+func (s *SQLiteStore) ListSessionEmbeddings(ctx context.Context, model string) ([]*SessionEmbedding, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, session_id, source, content, model, vector, created_at
+		FROM session_embeddings
+		WHERE model = ?
+		ORDER BY session_id, id
+	`, model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list session embeddings: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var embeddings []*SessionEmbedding
+	for rows.Next() {
+		var e SessionEmbedding
+		var vector []byte
+		if err := rows.Scan(&e.ID, &e.SessionID, &e.Source, &e.Content, &e.Model, &vector, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan session embedding: %w", err)
+		}
+		if e.Vector, err = decodeVector(vector); err != nil {
+			return nil, fmt.Errorf("failed to decode embedding %d: %w", e.ID, err)
+		}
+		embeddings = append(embeddings, &e)
+	}
+	return embeddings, rows.Err()
+}
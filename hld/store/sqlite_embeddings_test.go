@@ -0,0 +1,46 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/humanlayer/humanlayer/hld/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionEmbeddings(t *testing.T) {
+	dbPath := testutil.DatabasePath(t, "sqlite-embeddings")
+	store, err := NewSQLiteStore(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	require.NoError(t, store.ReplaceSessionEmbeddings(ctx, "sess-1", []*SessionEmbedding{
+		{Source: EmbeddingSourceQuery, Content: "Fix the parser", Model: "m1", Vector: []float32{0.5, -1.25, 3}},
+		{Source: EmbeddingSourceResult, Content: "Fixed", Model: "m1", Vector: []float32{1, 0, 0}},
+	}))
+	require.NoError(t, store.ReplaceSessionEmbeddings(ctx, "sess-2", []*SessionEmbedding{
+		{Source: EmbeddingSourceQuery, Content: "Other", Model: "m2", Vector: []float32{1}},
+	}))
+
+	embeddings, err := store.ListSessionEmbeddings(ctx, "m1")
+	require.NoError(t, err)
+	require.Len(t, embeddings, 2)
+	assert.Equal(t, "sess-1", embeddings[0].SessionID)
+	assert.Equal(t, []float32{0.5, -1.25, 3}, embeddings[0].Vector)
+	assert.Equal(t, EmbeddingSourceResult, embeddings[1].Source)
+
+	// Replacing drops the session's earlier embeddings
+	require.NoError(t, store.ReplaceSessionEmbeddings(ctx, "sess-1", []*SessionEmbedding{
+		{Source: EmbeddingSourceSummary, Content: "Summary", Model: "m1", Vector: []float32{0, 1, 0}},
+	}))
+	embeddings, err = store.ListSessionEmbeddings(ctx, "m1")
+	require.NoError(t, err)
+	require.Len(t, embeddings, 1)
+	assert.Equal(t, EmbeddingSourceSummary, embeddings[0].Source)
+
+	other, err := store.ListSessionEmbeddings(ctx, "m2")
+	require.NoError(t, err)
+	assert.Len(t, other, 1)
+}
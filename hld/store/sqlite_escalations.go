@@ -0,0 +1,99 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// CreateApprovalEscalation records a critical approval
+func (s *SQLiteStore) CreateApprovalEscalation(ctx context.Context, escalation *ApprovalEscalation) error {
+	if escalation.CreatedAt.IsZero() {
+		escalation.CreatedAt = time.Now()
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO approval_escalations (approval_id, session_id, tool_name, rule, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, escalation.ApprovalID, escalation.SessionID, escalation.ToolName, escalation.Rule, escalation.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create approval escalation: %w", err)
+	}
+	return nil
+}
+
+const escalationColumns = `approval_id, session_id, tool_name, rule, created_at, triggered_at, resolved_at`
+
+func scanEscalation(row interface{ Scan(...any) error }) (*ApprovalEscalation, error) {
+	var escalation ApprovalEscalation
+	var triggeredAt, resolvedAt sql.NullTime
+	if err := row.Scan(&escalation.ApprovalID, &escalation.SessionID, &escalation.ToolName, &escalation.Rule,
+		&escalation.CreatedAt, &triggeredAt, &resolvedAt); err != nil {
+		return nil, err
+	}
+	if triggeredAt.Valid {
+		escalation.TriggeredAt = &triggeredAt.Time
+	}
+	if resolvedAt.Valid {
+		escalation.ResolvedAt = &resolvedAt.Time
+	}
+	return &escalation, nil
+}
+
+// GetApprovalEscalation returns the escalation for an approval
+func (s *SQLiteStore) GetApprovalEscalation(ctx context.Context, approvalID string) (*ApprovalEscalation, error) {
+	escalation, err := scanEscalation(s.db.QueryRowContext(ctx,
+		`SELECT `+escalationColumns+` FROM approval_escalations WHERE approval_id = ?`, approvalID))
+	if err == sql.ErrNoRows {
+		return nil, &NotFoundError{Type: "approval escalation", ID: approvalID}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get approval escalation: %w", err)
+	}
+	return escalation, nil
+}
+
+// ListOpenApprovalEscalations returns the escalations not yet resolved, oldest first
+func (s *SQLiteStore) ListOpenApprovalEscalations(ctx context.Context) ([]*ApprovalEscalation, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+escalationColumns+` FROM approval_escalations WHERE resolved_at IS NULL ORDER BY created_at`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list approval escalations: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var escalations []*ApprovalEscalation
+	for rows.Next() {
+		escalation, err := scanEscalation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan approval escalation: %w", err)
+		}
+		escalations = append(escalations, escalation)
+	}
+	return escalations, rows.Err()
+}
+
+// MarkApprovalEscalationTriggered sets triggered_at once
+func (s *SQLiteStore) MarkApprovalEscalationTriggered(ctx context.Context, approvalID string) (bool, error) {
+	return s.markEscalation(ctx, approvalID, "triggered_at")
+}
+
+// MarkApprovalEscalationResolved sets resolved_at once
+func (s *SQLiteStore) MarkApprovalEscalationResolved(ctx context.Context, approvalID string) (bool, error) {
+	return s.markEscalation(ctx, approvalID, "resolved_at")
+}
+
+// markEscalation sets a timestamp column that is still NULL
+func (s *SQLiteStore) markEscalation(ctx context.Context, approvalID, column string) (bool, error) {
+	result, err := s.db.ExecContext(ctx,
+		`UPDATE approval_escalations SET `+column+` = ? WHERE approval_id = ? AND `+column+` IS NULL`,
+		time.Now(), approvalID)
+	if err != nil {
+		return false, fmt.Errorf("failed to update approval escalation: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to update approval escalation: %w", err)
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,58 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApprovalEscalations(t *testing.T) {
+	dbPath := testutil.DatabasePath(t, "sqlite-escalations")
+	store, err := NewSQLiteStore(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	require.NoError(t, store.CreateApprovalEscalation(ctx, &ApprovalEscalation{
+		ApprovalID: "a1", SessionID: "s1", ToolName: "Bash", Rule: "prod-deploy", CreatedAt: time.Now().Add(-time.Hour),
+	}))
+	require.NoError(t, store.CreateApprovalEscalation(ctx, &ApprovalEscalation{
+		ApprovalID: "a2", SessionID: "s1", ToolName: "Write",
+	}))
+	assert.Error(t, store.CreateApprovalEscalation(ctx, &ApprovalEscalation{ApprovalID: "a1", SessionID: "s1", ToolName: "Bash"}))
+
+	open, err := store.ListOpenApprovalEscalations(ctx)
+	require.NoError(t, err)
+	require.Len(t, open, 2)
+	assert.Equal(t, "a1", open[0].ApprovalID, "oldest first")
+	assert.Equal(t, "prod-deploy", open[0].Rule)
+
+	marked, err := store.MarkApprovalEscalationTriggered(ctx, "a1")
+	require.NoError(t, err)
+	assert.True(t, marked)
+	marked, err = store.MarkApprovalEscalationTriggered(ctx, "a1")
+	require.NoError(t, err)
+	assert.False(t, marked, "triggered once")
+
+	marked, err = store.MarkApprovalEscalationResolved(ctx, "a1")
+	require.NoError(t, err)
+	assert.True(t, marked)
+
+	escalation, err := store.GetApprovalEscalation(ctx, "a1")
+	require.NoError(t, err)
+	assert.NotNil(t, escalation.TriggeredAt)
+	assert.NotNil(t, escalation.ResolvedAt)
+
+	open, err = store.ListOpenApprovalEscalations(ctx)
+	require.NoError(t, err)
+	require.Len(t, open, 1)
+	assert.Equal(t, "a2", open[0].ApprovalID)
+
+	_, err = store.GetApprovalEscalation(ctx, "missing")
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
@@ -0,0 +1,179 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// CreateExperiment records a new experiment
+func (s *SQLiteStore) CreateExperiment(ctx context.Context, experiment *Experiment) error {
+	if experiment.CreatedAt.IsZero() {
+		experiment.CreatedAt = time.Now()
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO experiments (id, name, query, working_dir, base_commit, test_command, variants, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, experiment.ID, nullIfEmpty(experiment.Name), experiment.Query, experiment.WorkingDir, experiment.BaseCommit,
+		nullIfEmpty(experiment.TestCommand), string(experiment.Variants), experiment.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create experiment: %w", err)
+	}
+	return nil
+}
+
+// GetExperiment retrieves an experiment by ID
+func (s *SQLiteStore) GetExperiment(ctx context.Context, id string) (*Experiment, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT id, name, query, working_dir, base_commit, test_command, variants, created_at
+		FROM experiments WHERE id = ?
+	`, id)
+	experiment, err := scanExperiment(row)
+	if err == sql.ErrNoRows {
+		return nil, &NotFoundError{Type: "experiment", ID: id}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get experiment: %w", err)
+	}
+	return experiment, nil
+}
+
+// ListExperiments retrieves experiments newest first
+func (s *SQLiteStore) ListExperiments(ctx context.Context) ([]*Experiment, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, name, query, working_dir, base_commit, test_command, variants, created_at
+		FROM experiments
+		ORDER BY created_at DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list experiments: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var experiments []*Experiment
+	for rows.Next() {
+		experiment, err := scanExperiment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan experiment: %w", err)
+		}
+		experiments = append(experiments, experiment)
+	}
+	return experiments, rows.Err()
+}
+
+func scanExperiment(row interface{ Scan(...any) error }) (*Experiment, error) {
+	var experiment Experiment
+	var name, testCommand sql.NullString
+	var variants string
+	if err := row.Scan(&experiment.ID, &name, &experiment.Query, &experiment.WorkingDir, &experiment.BaseCommit,
+		&testCommand, &variants, &experiment.CreatedAt); err != nil {
+		return nil, err
+	}
+	experiment.Name = name.String
+	experiment.TestCommand = testCommand.String
+	experiment.Variants = []byte(variants)
+	return &experiment, nil
+}
+
+// CreateExperimentRun records a session launched for an experiment
+func (s *SQLiteStore) CreateExperimentRun(ctx context.Context, run *ExperimentRun) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO experiment_runs (session_id, experiment_id, variant, worktree)
+		VALUES (?, ?, ?, ?)
+	`, run.SessionID, run.ExperimentID, run.Variant, run.Worktree)
+	if err != nil {
+		return fmt.Errorf("failed to create experiment run: %w", err)
+	}
+	return nil
+}
+
+// UpdateExperimentRun saves a run's outcome
+func (s *SQLiteStore) UpdateExperimentRun(ctx context.Context, run *ExperimentRun) error {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE experiment_runs
+		SET files_changed = ?, lines_added = ?, lines_removed = ?, tests_passed = ?, test_output = ?, evaluated_at = ?
+		WHERE session_id = ?
+	`, run.FilesChanged, run.LinesAdded, run.LinesRemoved, run.TestsPassed, nullIfEmpty(run.TestOutput),
+		run.EvaluatedAt, run.SessionID)
+	if err != nil {
+		return fmt.Errorf("failed to update experiment run: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return &NotFoundError{Type: "experiment run", ID: run.SessionID}
+	}
+	return nil
+}
+
+// GetExperimentRun retrieves the experiment run for a session
+func (s *SQLiteStore) GetExperimentRun(ctx context.Context, sessionID string) (*ExperimentRun, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT session_id, experiment_id, variant, worktree, files_changed, lines_added, lines_removed,
+			tests_passed, test_output, evaluated_at
+		FROM experiment_runs WHERE session_id = ?
+	`, sessionID)
+	run, err := scanExperimentRun(row)
+	if err == sql.ErrNoRows {
+		return nil, &NotFoundError{Type: "experiment run", ID: sessionID}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get experiment run: %w", err)
+	}
+	return run, nil
+}
+
+// ListExperimentRuns retrieves an experiment's runs in variant order
+func (s *SQLiteStore) ListExperimentRuns(ctx context.Context, experimentID string) ([]*ExperimentRun, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT session_id, experiment_id, variant, worktree, files_changed, lines_added, lines_removed,
+			tests_passed, test_output, evaluated_at
+		FROM experiment_runs
+		WHERE experiment_id = ?
+		ORDER BY variant, worktree
+	`, experimentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list experiment runs: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var runs []*ExperimentRun
+	for rows.Next() {
+		run, err := scanExperimentRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan experiment run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+func scanExperimentRun(row interface{ Scan(...any) error }) (*ExperimentRun, error) {
+	var run ExperimentRun
+	var filesChanged, linesAdded, linesRemoved sql.NullInt64
+	var testsPassed sql.NullBool
+	var testOutput sql.NullString
+	var evaluatedAt sql.NullTime
+	if err := row.Scan(&run.SessionID, &run.ExperimentID, &run.Variant, &run.Worktree, &filesChanged,
+		&linesAdded, &linesRemoved, &testsPassed, &testOutput, &evaluatedAt); err != nil {
+		return nil, err
+	}
+	run.FilesChanged = nullIntPtr(filesChanged)
+	run.LinesAdded = nullIntPtr(linesAdded)
+	run.LinesRemoved = nullIntPtr(linesRemoved)
+	if testsPassed.Valid {
+		run.TestsPassed = &testsPassed.Bool
+	}
+	run.TestOutput = testOutput.String
+	if evaluatedAt.Valid {
+		run.EvaluatedAt = &evaluatedAt.Time
+	}
+	return &run, nil
+}
+
+func nullIntPtr(n sql.NullInt64) *int {
+	if !n.Valid {
+		return nil
+	}
+	v := int(n.Int64)
+	return &v
+}
@@ -0,0 +1,64 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExperiments(t *testing.T) {
+	dbPath := testutil.DatabasePath(t, "sqlite-experiments")
+	store, err := NewSQLiteStore(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	require.NoError(t, store.CreateExperiment(ctx, &Experiment{
+		ID: "exp-1", Query: "Fix the bug", WorkingDir: "/work/repo", BaseCommit: "abc123",
+		TestCommand: "make test", Variants: json.RawMessage(`[{"label":"a"},{"label":"b"}]`),
+	}))
+
+	experiment, err := store.GetExperiment(ctx, "exp-1")
+	require.NoError(t, err)
+	assert.Equal(t, "make test", experiment.TestCommand)
+	assert.JSONEq(t, `[{"label":"a"},{"label":"b"}]`, string(experiment.Variants))
+
+	list, err := store.ListExperiments(ctx)
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	for _, variant := range []string{"b", "a"} {
+		require.NoError(t, store.CreateExperimentRun(ctx, &ExperimentRun{
+			SessionID: "sess-" + variant, ExperimentID: "exp-1", Variant: variant, Worktree: "/tmp/" + variant,
+		}))
+	}
+
+	run, err := store.GetExperimentRun(ctx, "sess-a")
+	require.NoError(t, err)
+	assert.Nil(t, run.TestsPassed)
+	assert.Nil(t, run.EvaluatedAt)
+
+	files, passed, now := 3, true, time.Now()
+	run.FilesChanged = &files
+	run.TestsPassed = &passed
+	run.TestOutput = "ok"
+	run.EvaluatedAt = &now
+	require.NoError(t, store.UpdateExperimentRun(ctx, run))
+
+	runs, err := store.ListExperimentRuns(ctx, "exp-1")
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Equal(t, "a", runs[0].Variant)
+	assert.Equal(t, 3, *runs[0].FilesChanged)
+	assert.True(t, *runs[0].TestsPassed)
+	assert.Nil(t, runs[0].LinesAdded)
+	assert.Nil(t, runs[1].EvaluatedAt)
+
+	_, err = store.GetExperimentRun(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,59 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// CreateGitHubTrigger records the webhook delivery that launched a session
+func (s *SQLiteStore) CreateGitHubTrigger(ctx context.Context, trigger *GitHubTrigger) error {
+	if trigger.CreatedAt.IsZero() {
+		trigger.CreatedAt = time.Now()
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO github_triggers (delivery_id, session_id, repository, number, kind, worktree, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, trigger.DeliveryID, trigger.SessionID, trigger.Repository, trigger.Number, trigger.Kind, trigger.Worktree, trigger.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create github trigger: %w", err)
+	}
+	return nil
+}
+
+// GetGitHubTriggerBySession returns the trigger that launched a session
+func (s *SQLiteStore) GetGitHubTriggerBySession(ctx context.Context, sessionID string) (*GitHubTrigger, error) {
+	var trigger GitHubTrigger
+	var reportedAt sql.NullTime
+	err := s.db.QueryRowContext(ctx, `
+		SELECT delivery_id, session_id, repository, number, kind, worktree, created_at, reported_at
+		FROM github_triggers WHERE session_id = ?
+	`, sessionID).Scan(&trigger.DeliveryID, &trigger.SessionID, &trigger.Repository, &trigger.Number,
+		&trigger.Kind, &trigger.Worktree, &trigger.CreatedAt, &reportedAt)
+	if err == sql.ErrNoRows {
+		return nil, &NotFoundError{Type: "github trigger", ID: sessionID}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get github trigger: %w", err)
+	}
+	if reportedAt.Valid {
+		trigger.ReportedAt = &reportedAt.Time
+	}
+	return &trigger, nil
+}
+
+// MarkGitHubTriggerReported sets reported_at once
+func (s *SQLiteStore) MarkGitHubTriggerReported(ctx context.Context, sessionID string) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE github_triggers SET reported_at = ? WHERE session_id = ? AND reported_at IS NULL
+	`, time.Now(), sessionID)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark github trigger reported: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to mark github trigger reported: %w", err)
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,46 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/humanlayer/humanlayer/hld/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitHubTriggers(t *testing.T) {
+	dbPath := testutil.DatabasePath(t, "sqlite-github")
+	store, err := NewSQLiteStore(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	require.NoError(t, store.CreateGitHubTrigger(ctx, &GitHubTrigger{
+		DeliveryID: "d1", SessionID: "sess-1", Repository: "acme/app", Number: 12,
+		Kind: GitHubTriggerFix, Worktree: "/tmp/wt",
+	}))
+	assert.Error(t, store.CreateGitHubTrigger(ctx, &GitHubTrigger{
+		DeliveryID: "d1", SessionID: "sess-2", Repository: "acme/app", Number: 12, Kind: GitHubTriggerFix,
+	}), "a delivery launches one session")
+
+	trigger, err := store.GetGitHubTriggerBySession(ctx, "sess-1")
+	require.NoError(t, err)
+	assert.Equal(t, 12, trigger.Number)
+	assert.Nil(t, trigger.ReportedAt)
+
+	marked, err := store.MarkGitHubTriggerReported(ctx, "sess-1")
+	require.NoError(t, err)
+	assert.True(t, marked)
+	marked, err = store.MarkGitHubTriggerReported(ctx, "sess-1")
+	require.NoError(t, err)
+	assert.False(t, marked)
+
+	trigger, err = store.GetGitHubTriggerBySession(ctx, "sess-1")
+	require.NoError(t, err)
+	assert.NotNil(t, trigger.ReportedAt)
+
+	_, err = store.GetGitHubTriggerBySession(ctx, "missing")
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
@@ -0,0 +1,88 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// HoldApproval parks an approval, replacing any earlier hold
+func (s *SQLiteStore) HoldApproval(ctx context.Context, hold *ApprovalHold) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO approval_holds (approval_id, session_id, reason, resume_at, held_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(approval_id) DO UPDATE SET
+			reason = excluded.reason,
+			resume_at = excluded.resume_at,
+			held_by = excluded.held_by,
+			created_at = excluded.created_at
+	`, hold.ApprovalID, hold.SessionID, hold.Reason, hold.ResumeAt, hold.HeldBy, hold.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to hold approval: %w", err)
+	}
+	return nil
+}
+
+// GetApprovalHold retrieves the hold on an approval
+func (s *SQLiteStore) GetApprovalHold(ctx context.Context, approvalID string) (*ApprovalHold, error) {
+	hold, err := scanApprovalHold(s.db.QueryRowContext(ctx, `
+		SELECT approval_id, session_id, reason, resume_at, held_by, created_at
+		FROM approval_holds WHERE approval_id = ?
+	`, approvalID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &NotFoundError{Type: "approval hold", ID: approvalID}
+	}
+	return hold, err
+}
+
+// ListApprovalHolds returns every hold, oldest first
+func (s *SQLiteStore) ListApprovalHolds(ctx context.Context) ([]*ApprovalHold, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT approval_id, session_id, reason, resume_at, held_by, created_at
+		FROM approval_holds ORDER BY created_at, approval_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list approval holds: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	holds := []*ApprovalHold{}
+	for rows.Next() {
+		hold, err := scanApprovalHold(rows)
+		if err != nil {
+			return nil, err
+		}
+		holds = append(holds, hold)
+	}
+	return holds, rows.Err()
+}
+
+// ReleaseApprovalHold unparks an approval, returning false if it wasn't held
+func (s *SQLiteStore) ReleaseApprovalHold(ctx context.Context, approvalID string) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM approval_holds WHERE approval_id = ?`, approvalID)
+	if err != nil {
+		return false, fmt.Errorf("failed to release approval hold: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+func scanApprovalHold(row interface{ Scan(...any) error }) (*ApprovalHold, error) {
+	var hold ApprovalHold
+	var resumeAt sql.NullTime
+	err := row.Scan(&hold.ApprovalID, &hold.SessionID, &hold.Reason, &resumeAt, &hold.HeldBy, &hold.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan approval hold: %w", err)
+	}
+	if resumeAt.Valid {
+		hold.ResumeAt = &resumeAt.Time
+	}
+	return &hold, nil
+}
@@ -0,0 +1,88 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// MarkInboxRead marks approvals read or unread for a user. Approvals
+// already read keep when they were first read.
+func (s *SQLiteStore) MarkInboxRead(ctx context.Context, user string, approvalIDs []string, read bool) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var readAt *time.Time
+	if read {
+		now := time.Now()
+		readAt = &now
+	}
+	for _, id := range approvalIDs {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO inbox_states (reader, approval_id, read_at)
+			VALUES (?, ?, ?)
+			ON CONFLICT(reader, approval_id) DO UPDATE SET
+				read_at = CASE WHEN excluded.read_at IS NULL THEN NULL ELSE COALESCE(read_at, excluded.read_at) END
+		`, user, id, readAt)
+		if err != nil {
+			return fmt.Errorf("failed to mark inbox item: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// SnoozeInboxItem hides an approval from a user until a time, or brings it
+// back when until is nil
+func (s *SQLiteStore) SnoozeInboxItem(ctx context.Context, user, approvalID string, until *time.Time) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO inbox_states (reader, approval_id, snoozed_until)
+		VALUES (?, ?, ?)
+		ON CONFLICT(reader, approval_id) DO UPDATE SET snoozed_until = excluded.snoozed_until
+	`, user, approvalID, until)
+	if err != nil {
+		return fmt.Errorf("failed to snooze inbox item: %w", err)
+	}
+	return nil
+}
+
+// ListInboxStates returns a user's state for each approval they have read
+// or snoozed
+func (s *SQLiteStore) ListInboxStates(ctx context.Context, user string) ([]*InboxState, error) {
+	return s.queryInboxStates(ctx, `reader = ? ORDER BY approval_id`, user)
+}
+
+// ListDueSnoozes returns snoozes ending at or before a time
+func (s *SQLiteStore) ListDueSnoozes(ctx context.Context, before time.Time) ([]*InboxState, error) {
+	return s.queryInboxStates(ctx, `snoozed_until IS NOT NULL AND snoozed_until <= ? ORDER BY snoozed_until`, before)
+}
+
+func (s *SQLiteStore) queryInboxStates(ctx context.Context, where string, args ...interface{}) ([]*InboxState, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT reader, approval_id, read_at, snoozed_until
+		FROM inbox_states WHERE `+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list inbox states: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	states := []*InboxState{}
+	for rows.Next() {
+		var state InboxState
+		var readAt, snoozedUntil sql.NullTime
+		if err := rows.Scan(&state.User, &state.ApprovalID, &readAt, &snoozedUntil); err != nil {
+			return nil, fmt.Errorf("failed to scan inbox state: %w", err)
+		}
+		if readAt.Valid {
+			state.ReadAt = &readAt.Time
+		}
+		if snoozedUntil.Valid {
+			state.SnoozedUntil = &snoozedUntil.Time
+		}
+		states = append(states, &state)
+	}
+	return states, rows.Err()
+}
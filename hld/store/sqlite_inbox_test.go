@@ -0,0 +1,50 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInboxStates(t *testing.T) {
+	dbPath := testutil.DatabasePath(t, "sqlite-inbox")
+	store, err := NewSQLiteStore(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	require.NoError(t, store.MarkInboxRead(ctx, "alice", []string{"a1", "a2"}, true))
+	states, err := store.ListInboxStates(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, states, 2)
+	require.NotNil(t, states[0].ReadAt)
+	firstRead := *states[0].ReadAt
+
+	// Reading again keeps the first read time; unreading clears it
+	require.NoError(t, store.MarkInboxRead(ctx, "alice", []string{"a1"}, true))
+	require.NoError(t, store.MarkInboxRead(ctx, "alice", []string{"a2"}, false))
+	states, err = store.ListInboxStates(ctx, "alice")
+	require.NoError(t, err)
+	assert.True(t, firstRead.Equal(*states[0].ReadAt))
+	assert.Nil(t, states[1].ReadAt)
+
+	soon := time.Now().Add(time.Minute)
+	later := time.Now().Add(time.Hour)
+	require.NoError(t, store.SnoozeInboxItem(ctx, "alice", "a1", &soon))
+	require.NoError(t, store.SnoozeInboxItem(ctx, "bob", "a1", &later))
+	due, err := store.ListDueSnoozes(ctx, time.Now().Add(2*time.Minute))
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, "alice", due[0].User)
+	assert.NotNil(t, due[0].ReadAt, "snoozing keeps read state")
+
+	require.NoError(t, store.SnoozeInboxItem(ctx, "alice", "a1", nil))
+	due, err = store.ListDueSnoozes(ctx, time.Now().Add(2*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, "bob", due[0].User)
+}
@@ -0,0 +1,50 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RecordInjectionDetection stores a suspected prompt injection
+func (s *SQLiteStore) RecordInjectionDetection(ctx context.Context, detection *InjectionDetection) error {
+	if detection.CreatedAt.IsZero() {
+		detection.CreatedAt = time.Now()
+	}
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO injection_detections (session_id, prompt, field, rule, excerpt, stripped, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, detection.SessionID, detection.Prompt, detection.Field, detection.Rule, detection.Excerpt, detection.Stripped, detection.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record injection detection: %w", err)
+	}
+	if id, err := res.LastInsertId(); err == nil {
+		detection.ID = id
+	}
+	return nil
+}
+
+// ListInjectionDetections returns a session's detections, or every
+// detection for an empty session ID, oldest first
+func (s *SQLiteStore) ListInjectionDetections(ctx context.Context, sessionID string) ([]*InjectionDetection, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, session_id, prompt, field, rule, excerpt, stripped, created_at
+		FROM injection_detections
+		WHERE ? = '' OR session_id = ?
+		ORDER BY created_at, id
+	`, sessionID, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list injection detections: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	detections := []*InjectionDetection{}
+	for rows.Next() {
+		d := &InjectionDetection{}
+		if err := rows.Scan(&d.ID, &d.SessionID, &d.Prompt, &d.Field, &d.Rule, &d.Excerpt, &d.Stripped, &d.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan injection detection: %w", err)
+		}
+		detections = append(detections, d)
+	}
+	return detections, rows.Err()
+}
@@ -0,0 +1,41 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/humanlayer/humanlayer/hld/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInjectionDetections(t *testing.T) {
+	dbPath := testutil.DatabasePath(t, "sqlite-injections")
+	store, err := NewSQLiteStore(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	detection := &InjectionDetection{
+		SessionID: "sess-1", Prompt: "commit-message", Field: "recent_commits",
+		Rule: "ignore_instructions", Excerpt: "Ignore previous instructions", Stripped: true,
+	}
+	require.NoError(t, store.RecordInjectionDetection(ctx, detection))
+	assert.NotZero(t, detection.ID)
+	require.NoError(t, store.RecordInjectionDetection(ctx, &InjectionDetection{
+		SessionID: "sess-2", Prompt: "ephemeral-chat", Field: "conversation", Rule: "hidden_characters", Excerpt: "hi",
+	}))
+
+	detections, err := store.ListInjectionDetections(ctx, "sess-1")
+	require.NoError(t, err)
+	require.Len(t, detections, 1)
+	assert.Equal(t, "recent_commits", detections[0].Field)
+	assert.Equal(t, "Ignore previous instructions", detections[0].Excerpt)
+	assert.True(t, detections[0].Stripped)
+	assert.False(t, detections[0].CreatedAt.IsZero())
+
+	all, err := store.ListInjectionDetections(ctx, "")
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+	assert.False(t, all[1].Stripped)
+}
@@ -0,0 +1,101 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// CreateJob records a new job
+func (s *SQLiteStore) CreateJob(ctx context.Context, job *Job) error {
+	if job.CreatedAt.IsZero() {
+		job.CreatedAt = time.Now()
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO jobs (id, kind, session_id, status, result, error, created_at, started_at, completed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, job.ID, job.Kind, nullIfEmpty(job.SessionID), job.Status, nullIfEmpty(string(job.Result)),
+		nullIfEmpty(job.Error), job.CreatedAt, job.StartedAt, job.CompletedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create job: %w", err)
+	}
+	return nil
+}
+
+// UpdateJob saves a job's status, result, error and timestamps
+func (s *SQLiteStore) UpdateJob(ctx context.Context, job *Job) error {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE jobs SET status = ?, result = ?, error = ?, started_at = ?, completed_at = ?
+		WHERE id = ?
+	`, job.Status, nullIfEmpty(string(job.Result)), nullIfEmpty(job.Error), job.StartedAt, job.CompletedAt, job.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update job: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return &NotFoundError{Type: "job", ID: job.ID}
+	}
+	return nil
+}
+
+// GetJob retrieves a job by ID
+func (s *SQLiteStore) GetJob(ctx context.Context, id string) (*Job, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT id, kind, session_id, status, result, error, created_at, started_at, completed_at
+		FROM jobs WHERE id = ?
+	`, id)
+	job, err := scanJob(row)
+	if err == sql.ErrNoRows {
+		return nil, &NotFoundError{Type: "job", ID: id}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	return job, nil
+}
+
+// ListJobs retrieves jobs newest first, optionally only those with status
+func (s *SQLiteStore) ListJobs(ctx context.Context, status string) ([]*Job, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, kind, session_id, status, result, error, created_at, started_at, completed_at
+		FROM jobs
+		WHERE ? = '' OR status = ?
+		ORDER BY created_at DESC
+	`, status, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var jobs []*Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+func scanJob(row interface{ Scan(...any) error }) (*Job, error) {
+	var job Job
+	var sessionID, result, jobErr sql.NullString
+	var startedAt, completedAt sql.NullTime
+	if err := row.Scan(&job.ID, &job.Kind, &sessionID, &job.Status, &result, &jobErr,
+		&job.CreatedAt, &startedAt, &completedAt); err != nil {
+		return nil, err
+	}
+	job.SessionID = sessionID.String
+	if result.Valid {
+		job.Result = []byte(result.String)
+	}
+	job.Error = jobErr.String
+	if startedAt.Valid {
+		job.StartedAt = &startedAt.Time
+	}
+	if completedAt.Valid {
+		job.CompletedAt = &completedAt.Time
+	}
+	return &job, nil
+}
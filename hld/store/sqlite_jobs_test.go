@@ -0,0 +1,60 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobs(t *testing.T) {
+	dbPath := testutil.DatabasePath(t, "sqlite-jobs")
+	store, err := NewSQLiteStore(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	created := time.Now().Add(-time.Minute)
+
+	require.NoError(t, store.CreateJob(ctx, &Job{
+		ID: "job-1", Kind: "commit-message", SessionID: "sess-1", Status: JobStatusPending, CreatedAt: created,
+	}))
+	require.NoError(t, store.CreateJob(ctx, &Job{ID: "job-2", Kind: "ephemeral-chat", Status: JobStatusRunning}))
+
+	job, err := store.GetJob(ctx, "job-1")
+	require.NoError(t, err)
+	assert.Equal(t, "sess-1", job.SessionID)
+	assert.Nil(t, job.StartedAt)
+	assert.Empty(t, job.Result)
+
+	now := time.Now()
+	job.Status = JobStatusCompleted
+	job.Result = json.RawMessage(`{"ok":true}`)
+	job.StartedAt = &created
+	job.CompletedAt = &now
+	require.NoError(t, store.UpdateJob(ctx, job))
+
+	job, err = store.GetJob(ctx, "job-1")
+	require.NoError(t, err)
+	assert.Equal(t, JobStatusCompleted, job.Status)
+	assert.JSONEq(t, `{"ok":true}`, string(job.Result))
+	require.NotNil(t, job.CompletedAt)
+
+	jobs, err := store.ListJobs(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, "job-2", jobs[0].ID)
+
+	jobs, err = store.ListJobs(ctx, JobStatusRunning)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "job-2", jobs[0].ID)
+
+	_, err = store.GetJob(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, store.UpdateJob(ctx, &Job{ID: "missing"}), ErrNotFound)
+}
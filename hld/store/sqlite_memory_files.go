@@ -0,0 +1,118 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const proposalColumns = `id, repository, path, base_content, proposed_content, diff, rationale, session_ids,
+	status, commit_hash, created_at, resolved_at`
+
+// CreateMemoryFileProposal stores a proposed memory file update
+func (s *SQLiteStore) CreateMemoryFileProposal(ctx context.Context, proposal *MemoryFileProposal) error {
+	if proposal.CreatedAt.IsZero() {
+		proposal.CreatedAt = time.Now()
+	}
+	sessionIDs, err := json.Marshal(proposal.SessionIDs)
+	if err != nil {
+		return fmt.Errorf("failed to encode session IDs: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO memory_file_proposals (`+proposalColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, proposal.ID, proposal.Repository, proposal.Path, proposal.BaseContent, proposal.ProposedContent,
+		proposal.Diff, nullIfEmpty(proposal.Rationale), string(sessionIDs), proposal.Status,
+		nullIfEmpty(proposal.CommitHash), proposal.CreatedAt, proposal.ResolvedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create memory file proposal: %w", err)
+	}
+	return nil
+}
+
+// GetMemoryFileProposal retrieves a proposal by ID
+func (s *SQLiteStore) GetMemoryFileProposal(ctx context.Context, id string) (*MemoryFileProposal, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+proposalColumns+` FROM memory_file_proposals WHERE id = ?`, id)
+	proposal, err := scanProposal(row)
+	if err == sql.ErrNoRows {
+		return nil, &NotFoundError{Type: "memory file proposal", ID: id}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get memory file proposal: %w", err)
+	}
+	return proposal, nil
+}
+
+// ResolveMemoryFileProposal records that a pending proposal was applied or rejected
+func (s *SQLiteStore) ResolveMemoryFileProposal(ctx context.Context, id, status, commitHash string) error {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE memory_file_proposals
+		SET status = ?, commit_hash = ?, resolved_at = ?
+		WHERE id = ? AND status = ?
+	`, status, nullIfEmpty(commitHash), time.Now(), id, ProposalStatusPending)
+	if err != nil {
+		return fmt.Errorf("failed to resolve memory file proposal: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return &NotFoundError{Type: "pending memory file proposal", ID: id}
+	}
+	return nil
+}
+
+// ListMemoryFileProposals retrieves proposals newest first
+func (s *SQLiteStore) ListMemoryFileProposals(ctx context.Context, repository, status string) ([]*MemoryFileProposal, error) {
+	var where []string
+	var args []interface{}
+	if repository != "" {
+		where = append(where, "repository = ?")
+		args = append(args, repository)
+	}
+	if status != "" {
+		where = append(where, "status = ?")
+		args = append(args, status)
+	}
+	query := `SELECT ` + proposalColumns + ` FROM memory_file_proposals`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY created_at DESC, id"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list memory file proposals: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var proposals []*MemoryFileProposal
+	for rows.Next() {
+		proposal, err := scanProposal(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan memory file proposal: %w", err)
+		}
+		proposals = append(proposals, proposal)
+	}
+	return proposals, rows.Err()
+}
+
+func scanProposal(row interface{ Scan(...any) error }) (*MemoryFileProposal, error) {
+	var p MemoryFileProposal
+	var rationale, commitHash sql.NullString
+	var sessionIDs string
+	var resolvedAt sql.NullTime
+	if err := row.Scan(&p.ID, &p.Repository, &p.Path, &p.BaseContent, &p.ProposedContent, &p.Diff, &rationale,
+		&sessionIDs, &p.Status, &commitHash, &p.CreatedAt, &resolvedAt); err != nil {
+		return nil, err
+	}
+	p.Rationale = rationale.String
+	p.CommitHash = commitHash.String
+	if resolvedAt.Valid {
+		p.ResolvedAt = &resolvedAt.Time
+	}
+	if err := json.Unmarshal([]byte(sessionIDs), &p.SessionIDs); err != nil {
+		return nil, fmt.Errorf("failed to decode session IDs: %w", err)
+	}
+	return &p, nil
+}
@@ -0,0 +1,58 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryFileProposals(t *testing.T) {
+	dbPath := testutil.DatabasePath(t, "sqlite-memory-files")
+	store, err := NewSQLiteStore(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	require.NoError(t, store.CreateMemoryFileProposal(ctx, &MemoryFileProposal{
+		ID: "p1", Repository: "/repo", Path: "CLAUDE.md", BaseContent: "", ProposedContent: "# Repo\n",
+		Diff: "+# Repo", SessionIDs: []string{"s1", "s2"}, Status: ProposalStatusPending,
+		CreatedAt: time.Now().Add(-time.Hour),
+	}))
+	require.NoError(t, store.CreateMemoryFileProposal(ctx, &MemoryFileProposal{
+		ID: "p2", Repository: "/repo", Path: "CLAUDE.md", ProposedContent: "x", Diff: "d",
+		Rationale: "why", SessionIDs: []string{"s3"}, Status: ProposalStatusPending,
+	}))
+
+	proposal, err := store.GetMemoryFileProposal(ctx, "p1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"s1", "s2"}, proposal.SessionIDs)
+	assert.Nil(t, proposal.ResolvedAt)
+
+	require.NoError(t, store.ResolveMemoryFileProposal(ctx, "p1", ProposalStatusApplied, "abc123"))
+	assert.True(t, errors.Is(store.ResolveMemoryFileProposal(ctx, "p1", ProposalStatusRejected, ""), ErrNotFound),
+		"only pending proposals can be resolved")
+
+	proposal, err = store.GetMemoryFileProposal(ctx, "p1")
+	require.NoError(t, err)
+	assert.Equal(t, ProposalStatusApplied, proposal.Status)
+	assert.Equal(t, "abc123", proposal.CommitHash)
+	assert.NotNil(t, proposal.ResolvedAt)
+
+	all, err := store.ListMemoryFileProposals(ctx, "/repo", "")
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "p2", all[0].ID, "newest first")
+
+	pending, err := store.ListMemoryFileProposals(ctx, "", ProposalStatusPending)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "why", pending[0].Rationale)
+
+	_, err = store.GetMemoryFileProposal(ctx, "missing")
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
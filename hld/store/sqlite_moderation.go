@@ -0,0 +1,106 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const moderationBlockColumns = `id, kind, session_id, approval_id, content, source, reason, categories, created_at, reviewed_at, reviewer, review_note`
+
+// RecordModerationBlock stores text the content safety filter blocked
+func (s *SQLiteStore) RecordModerationBlock(ctx context.Context, block *ModerationBlock) error {
+	if block.CreatedAt.IsZero() {
+		block.CreatedAt = time.Now()
+	}
+	var categories interface{}
+	if len(block.Categories) > 0 {
+		encoded, err := json.Marshal(block.Categories)
+		if err != nil {
+			return fmt.Errorf("failed to encode categories: %w", err)
+		}
+		categories = string(encoded)
+	}
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO moderation_blocks (kind, session_id, approval_id, content, source, reason, categories, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, block.Kind, nullIfEmpty(block.SessionID), nullIfEmpty(block.ApprovalID), block.Content, block.Source, block.Reason,
+		categories, block.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record moderation block: %w", err)
+	}
+	if id, err := res.LastInsertId(); err == nil {
+		block.ID = id
+	}
+	return nil
+}
+
+// ListModerationBlocks returns blocks oldest first, only those not yet
+// reviewed when unreviewed is set
+func (s *SQLiteStore) ListModerationBlocks(ctx context.Context, unreviewed bool) ([]*ModerationBlock, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+moderationBlockColumns+`
+		FROM moderation_blocks
+		WHERE ? = 0 OR reviewed_at IS NULL
+		ORDER BY created_at, id
+	`, unreviewed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list moderation blocks: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	blocks := []*ModerationBlock{}
+	for rows.Next() {
+		block, err := scanModerationBlock(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan moderation block: %w", err)
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, rows.Err()
+}
+
+// ReviewModerationBlock marks a block reviewed with an optional note
+func (s *SQLiteStore) ReviewModerationBlock(ctx context.Context, id int64, reviewer, note string) (*ModerationBlock, error) {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE moderation_blocks SET reviewed_at = ?, reviewer = ?, review_note = ? WHERE id = ?
+	`, time.Now(), nullIfEmpty(reviewer), nullIfEmpty(note), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to review moderation block: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return nil, &NotFoundError{Type: "moderation block", ID: strconv.FormatInt(id, 10)}
+	}
+	block, err := scanModerationBlock(s.db.QueryRowContext(ctx,
+		`SELECT `+moderationBlockColumns+` FROM moderation_blocks WHERE id = ?`, id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get moderation block: %w", err)
+	}
+	return block, nil
+}
+
+func scanModerationBlock(row interface{ Scan(...any) error }) (*ModerationBlock, error) {
+	var block ModerationBlock
+	var sessionID, approvalID, categories, reviewer, note sql.NullString
+	var reviewedAt sql.NullTime
+	if err := row.Scan(&block.ID, &block.Kind, &sessionID, &approvalID, &block.Content, &block.Source, &block.Reason,
+		&categories, &block.CreatedAt, &reviewedAt, &reviewer, &note); err != nil {
+		return nil, err
+	}
+	block.SessionID = sessionID.String
+	block.ApprovalID = approvalID.String
+	block.Reviewer = reviewer.String
+	block.ReviewNote = note.String
+	if categories.Valid {
+		if err := json.Unmarshal([]byte(categories.String), &block.Categories); err != nil {
+			return nil, fmt.Errorf("failed to decode categories: %w", err)
+		}
+	}
+	if reviewedAt.Valid {
+		block.ReviewedAt = &reviewedAt.Time
+	}
+	return &block, nil
+}
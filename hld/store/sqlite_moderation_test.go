@@ -0,0 +1,54 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/humanlayer/humanlayer/hld/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModerationBlocks(t *testing.T) {
+	dbPath := testutil.DatabasePath(t, "sqlite-moderation")
+	store, err := NewSQLiteStore(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	block := &ModerationBlock{
+		Kind: ModerationApprovalComment, SessionID: "sess-1", ApprovalID: "local-1",
+		Content: "no, you idiot", Source: "pattern", Reason: `matched "idiot"`,
+	}
+	require.NoError(t, store.RecordModerationBlock(ctx, block))
+	assert.NotZero(t, block.ID)
+	require.NoError(t, store.RecordModerationBlock(ctx, &ModerationBlock{
+		Kind: ModerationSessionSummary, SessionID: "sess-2", Content: "...", Source: "model",
+		Reason: "insults the user", Categories: []string{"harassment"},
+	}))
+
+	blocks, err := store.ListModerationBlocks(ctx, true)
+	require.NoError(t, err)
+	require.Len(t, blocks, 2)
+	assert.Equal(t, "local-1", blocks[0].ApprovalID)
+	assert.Empty(t, blocks[0].Categories)
+	assert.Equal(t, []string{"harassment"}, blocks[1].Categories)
+	assert.Empty(t, blocks[1].ApprovalID)
+
+	reviewed, err := store.ReviewModerationBlock(ctx, block.ID, "ada", "false positive")
+	require.NoError(t, err)
+	require.NotNil(t, reviewed.ReviewedAt)
+	assert.Equal(t, "ada", reviewed.Reviewer)
+	assert.Equal(t, "false positive", reviewed.ReviewNote)
+
+	blocks, err = store.ListModerationBlocks(ctx, true)
+	require.NoError(t, err)
+	require.Len(t, blocks, 1)
+	assert.Equal(t, ModerationSessionSummary, blocks[0].Kind)
+	all, err := store.ListModerationBlocks(ctx, false)
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	_, err = store.ReviewModerationBlock(ctx, 99, "ada", "")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,49 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// RecordSessionProcess creates or replaces a session's process record
+func (s *SQLiteStore) RecordSessionProcess(ctx context.Context, p *SessionProcess) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT OR REPLACE INTO session_processes (
+			session_id, pid, started_at, exited_at, exit_code, exit_signal, stderr_tail,
+			rss_bytes, peak_rss_bytes, cpu_seconds
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, p.SessionID, p.PID, p.StartedAt, p.ExitedAt, p.ExitCode, p.ExitSignal, p.StderrTail,
+		p.RSSBytes, p.PeakRSSBytes, p.CPUSeconds)
+	if err != nil {
+		return fmt.Errorf("failed to record session process: %w", err)
+	}
+	return nil
+}
+
+// GetSessionProcess retrieves a session's process record
+func (s *SQLiteStore) GetSessionProcess(ctx context.Context, sessionID string) (*SessionProcess, error) {
+	var p SessionProcess
+	var exitedAt sql.NullTime
+	var exitCode sql.NullInt64
+	err := s.db.QueryRowContext(ctx, `
+		SELECT session_id, pid, started_at, exited_at, exit_code, exit_signal, stderr_tail,
+			rss_bytes, peak_rss_bytes, cpu_seconds
+		FROM session_processes WHERE session_id = ?
+	`, sessionID).Scan(&p.SessionID, &p.PID, &p.StartedAt, &exitedAt, &exitCode, &p.ExitSignal, &p.StderrTail,
+		&p.RSSBytes, &p.PeakRSSBytes, &p.CPUSeconds)
+	if err == sql.ErrNoRows {
+		return nil, &NotFoundError{Type: "session process", ID: sessionID}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session process: %w", err)
+	}
+	if exitedAt.Valid {
+		p.ExitedAt = &exitedAt.Time
+	}
+	if exitCode.Valid {
+		code := int(exitCode.Int64)
+		p.ExitCode = &code
+	}
+	return &p, nil
+}
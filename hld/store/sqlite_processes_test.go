@@ -0,0 +1,47 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionProcesses(t *testing.T) {
+	dbPath := testutil.DatabasePath(t, "sqlite-processes")
+	store, err := NewSQLiteStore(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	_, err = store.GetSessionProcess(ctx, "sess-1")
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	started := time.Now().Add(-time.Minute).UTC().Truncate(time.Second)
+	require.NoError(t, store.RecordSessionProcess(ctx, &SessionProcess{SessionID: "sess-1", PID: 4242, StartedAt: started}))
+	process, err := store.GetSessionProcess(ctx, "sess-1")
+	require.NoError(t, err)
+	assert.Equal(t, 4242, process.PID)
+	assert.Nil(t, process.ExitedAt)
+	assert.Nil(t, process.ExitCode, "still running")
+
+	// The record is replaced when the process exits
+	exited, code := time.Now().UTC().Truncate(time.Second), -1
+	require.NoError(t, store.RecordSessionProcess(ctx, &SessionProcess{
+		SessionID: "sess-1", PID: 4242, StartedAt: started, ExitedAt: &exited, ExitCode: &code, ExitSignal: "killed",
+		StderrTail: "FATAL ERROR: heap out of memory", RSSBytes: 1 << 30, PeakRSSBytes: 2 << 30, CPUSeconds: 12.5,
+	}))
+	process, err = store.GetSessionProcess(ctx, "sess-1")
+	require.NoError(t, err)
+	require.NotNil(t, process.ExitCode)
+	assert.Equal(t, -1, *process.ExitCode)
+	assert.Equal(t, "killed", process.ExitSignal)
+	assert.Equal(t, int64(2<<30), process.PeakRSSBytes)
+	assert.Equal(t, 12.5, process.CPUSeconds)
+	require.NotNil(t, process.ExitedAt)
+	assert.True(t, exited.Equal(*process.ExitedAt))
+}
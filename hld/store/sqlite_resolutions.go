@@ -0,0 +1,83 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// UseResolutionNonce marks a nonce used until it expires, returning
+// ErrNonceUsed if it already was
+func (s *SQLiteStore) UseResolutionNonce(ctx context.Context, nonce, approvalID string, expiresAt time.Time) error {
+	now := time.Now()
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM resolution_nonces WHERE expires_at < ?`, now); err != nil {
+		return fmt.Errorf("failed to forget expired resolution nonces: %w", err)
+	}
+	result, err := s.db.ExecContext(ctx, `
+		INSERT INTO resolution_nonces (nonce, approval_id, used_at, expires_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(nonce) DO NOTHING
+	`, nonce, approvalID, now, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to use resolution nonce: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrNonceUsed
+	}
+	return nil
+}
+
+// ReleaseResolutionNonce lets a nonce be used again
+func (s *SQLiteStore) ReleaseResolutionNonce(ctx context.Context, nonce string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM resolution_nonces WHERE nonce = ?`, nonce); err != nil {
+		return fmt.Errorf("failed to release resolution nonce: %w", err)
+	}
+	return nil
+}
+
+// RecordRejectedResolution stores a refused approval resolution
+func (s *SQLiteStore) RecordRejectedResolution(ctx context.Context, rejection *RejectedResolution) error {
+	if rejection.CreatedAt.IsZero() {
+		rejection.CreatedAt = time.Now()
+	}
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO rejected_resolutions (approval_id, channel, source, reason, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, nullIfEmpty(rejection.ApprovalID), rejection.Channel, nullIfEmpty(rejection.Source), rejection.Reason, rejection.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record rejected resolution: %w", err)
+	}
+	if id, err := res.LastInsertId(); err == nil {
+		rejection.ID = id
+	}
+	return nil
+}
+
+// ListRejectedResolutions returns an approval's rejections, or every
+// rejection for an empty approval ID, oldest first
+func (s *SQLiteStore) ListRejectedResolutions(ctx context.Context, approvalID string) ([]*RejectedResolution, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, approval_id, channel, source, reason, created_at
+		FROM rejected_resolutions
+		WHERE ? = '' OR approval_id = ?
+		ORDER BY created_at, id
+	`, approvalID, approvalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rejected resolutions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	rejections := []*RejectedResolution{}
+	for rows.Next() {
+		r := &RejectedResolution{}
+		var id, source sql.NullString
+		if err := rows.Scan(&r.ID, &id, &r.Channel, &source, &r.Reason, &r.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan rejected resolution: %w", err)
+		}
+		r.ApprovalID = id.String
+		r.Source = source.String
+		rejections = append(rejections, r)
+	}
+	return rejections, rows.Err()
+}
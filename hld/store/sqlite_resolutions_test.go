@@ -0,0 +1,45 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolutionNonces(t *testing.T) {
+	dbPath := testutil.DatabasePath(t, "sqlite-resolutions")
+	store, err := NewSQLiteStore(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	expires := time.Now().Add(time.Hour)
+	require.NoError(t, store.UseResolutionNonce(ctx, "n1", "appr-1", expires))
+	assert.ErrorIs(t, store.UseResolutionNonce(ctx, "n1", "appr-1", expires), ErrNonceUsed)
+
+	require.NoError(t, store.ReleaseResolutionNonce(ctx, "n1"))
+	require.NoError(t, store.UseResolutionNonce(ctx, "n1", "appr-1", expires), "a released nonce can be used again")
+
+	// Expired nonces are forgotten; their signatures have expired too
+	require.NoError(t, store.UseResolutionNonce(ctx, "n2", "appr-2", time.Now().Add(-time.Second)))
+	require.NoError(t, store.UseResolutionNonce(ctx, "n2", "appr-2", expires))
+
+	require.NoError(t, store.RecordRejectedResolution(ctx, &RejectedResolution{
+		ApprovalID: "appr-1", Channel: "email", Source: "ada@example.com", Reason: "resolution was already used",
+	}))
+	require.NoError(t, store.RecordRejectedResolution(ctx, &RejectedResolution{Channel: "federation", Reason: "resolution signature is invalid"}))
+
+	rejections, err := store.ListRejectedResolutions(ctx, "appr-1")
+	require.NoError(t, err)
+	require.Len(t, rejections, 1)
+	assert.Equal(t, "email", rejections[0].Channel)
+	assert.Equal(t, "ada@example.com", rejections[0].Source)
+	all, err := store.ListRejectedResolutions(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Empty(t, all[1].ApprovalID)
+}
@@ -0,0 +1,61 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// StoreShadowDecision records a shadow policy decision for an approval
+func (s *SQLiteStore) StoreShadowDecision(ctx context.Context, decision *ShadowDecision) error {
+	if decision.CreatedAt.IsZero() {
+		decision.CreatedAt = time.Now()
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT OR REPLACE INTO approval_shadow_decisions (
+			approval_id, session_id, tool_name, action, rule, reason, error, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, decision.ApprovalID, decision.SessionID, decision.ToolName, nullIfEmpty(decision.Action),
+		nullIfEmpty(decision.Rule), nullIfEmpty(decision.Reason), nullIfEmpty(decision.Error), decision.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to store shadow decision: %w", err)
+	}
+	return nil
+}
+
+// ListShadowDecisions retrieves shadow decisions oldest first, for one session or all of them
+func (s *SQLiteStore) ListShadowDecisions(ctx context.Context, sessionID string) ([]*ShadowDecision, error) {
+	query := `
+		SELECT approval_id, session_id, tool_name, action, rule, reason, error, created_at
+		FROM approval_shadow_decisions
+	`
+	var args []interface{}
+	if sessionID != "" {
+		query += " WHERE session_id = ?"
+		args = append(args, sessionID)
+	}
+	query += " ORDER BY created_at ASC, approval_id ASC"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shadow decisions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var decisions []*ShadowDecision
+	for rows.Next() {
+		var d ShadowDecision
+		var action, rule, reason, errMsg sql.NullString
+		if err := rows.Scan(&d.ApprovalID, &d.SessionID, &d.ToolName, &action, &rule, &reason, &errMsg, &d.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan shadow decision: %w", err)
+		}
+		d.Action = action.String
+		d.Rule = rule.String
+		d.Reason = reason.String
+		d.Error = errMsg.String
+		decisions = append(decisions, &d)
+	}
+	return decisions, rows.Err()
+}
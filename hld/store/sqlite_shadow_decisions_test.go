@@ -0,0 +1,76 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShadowDecisions(t *testing.T) {
+	dbPath := testutil.DatabasePath(t, "sqlite-shadow-decisions")
+	store, err := NewSQLiteStore(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+
+	for _, id := range []string{"session-a", "session-b"} {
+		require.NoError(t, store.CreateSession(ctx, &Session{
+			ID:             id,
+			RunID:          id + "-run",
+			Query:          "Test query",
+			Status:         SessionStatusRunning,
+			CreatedAt:      time.Now(),
+			LastActivityAt: time.Now(),
+		}))
+	}
+
+	base := time.Now().Add(-time.Minute)
+	for i, sessionID := range []string{"session-a", "session-b", "session-a"} {
+		approvalID := sessionID + "-approval-" + string(rune('0'+i))
+		require.NoError(t, store.CreateApproval(ctx, &Approval{
+			ID:        approvalID,
+			RunID:     sessionID + "-run",
+			SessionID: sessionID,
+			Status:    ApprovalStatusLocalPending,
+			CreatedAt: base,
+			ToolName:  "Bash",
+			ToolInput: json.RawMessage(`{}`),
+		}))
+		require.NoError(t, store.StoreShadowDecision(ctx, &ShadowDecision{
+			ApprovalID: approvalID,
+			SessionID:  sessionID,
+			ToolName:   "Bash",
+			Action:     "allow",
+			Rule:       "bash-ok",
+			CreatedAt:  base.Add(time.Duration(i) * time.Second),
+		}))
+	}
+
+	// Re-recording a decision replaces it
+	require.NoError(t, store.StoreShadowDecision(ctx, &ShadowDecision{
+		ApprovalID: "session-a-approval-0",
+		SessionID:  "session-a",
+		ToolName:   "Bash",
+		Error:      "rule \"bash-ok\": no such key: command",
+		CreatedAt:  base,
+	}))
+
+	all, err := store.ListShadowDecisions(ctx, "")
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+
+	sessionA, err := store.ListShadowDecisions(ctx, "session-a")
+	require.NoError(t, err)
+	require.Len(t, sessionA, 2)
+	assert.Equal(t, "session-a-approval-0", sessionA[0].ApprovalID)
+	assert.Empty(t, sessionA[0].Action)
+	assert.Contains(t, sessionA[0].Error, "no such key")
+	assert.Equal(t, "allow", sessionA[1].Action)
+	assert.Equal(t, "bash-ok", sessionA[1].Rule)
+}
@@ -0,0 +1,85 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const shareLinkColumns = `id, session_id, scope, label, created_at, expires_at, revoked_at`
+
+// CreateShareLink records a share link
+func (s *SQLiteStore) CreateShareLink(ctx context.Context, link *ShareLink) error {
+	if link.CreatedAt.IsZero() {
+		link.CreatedAt = time.Now()
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO share_links (`+shareLinkColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, link.ID, link.SessionID, link.Scope, nullIfEmpty(link.Label), link.CreatedAt, link.ExpiresAt, link.RevokedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create share link: %w", err)
+	}
+	return nil
+}
+
+func scanShareLink(row interface{ Scan(...any) error }) (*ShareLink, error) {
+	var link ShareLink
+	var label sql.NullString
+	var revokedAt sql.NullTime
+	if err := row.Scan(&link.ID, &link.SessionID, &link.Scope, &label, &link.CreatedAt, &link.ExpiresAt, &revokedAt); err != nil {
+		return nil, err
+	}
+	link.Label = label.String
+	if revokedAt.Valid {
+		link.RevokedAt = &revokedAt.Time
+	}
+	return &link, nil
+}
+
+// GetShareLink returns a share link by ID
+func (s *SQLiteStore) GetShareLink(ctx context.Context, id string) (*ShareLink, error) {
+	link, err := scanShareLink(s.db.QueryRowContext(ctx, `SELECT `+shareLinkColumns+` FROM share_links WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &NotFoundError{Type: "share link", ID: id}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get share link: %w", err)
+	}
+	return link, nil
+}
+
+// ListShareLinks returns a session's links, oldest first
+func (s *SQLiteStore) ListShareLinks(ctx context.Context, sessionID string) ([]*ShareLink, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+shareLinkColumns+` FROM share_links
+		WHERE session_id = ?
+		ORDER BY created_at, id
+	`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list share links: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	links := []*ShareLink{}
+	for rows.Next() {
+		link, err := scanShareLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan share link: %w", err)
+		}
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
+
+// RevokeShareLink stops a link working
+func (s *SQLiteStore) RevokeShareLink(ctx context.Context, id string) (*ShareLink, error) {
+	if _, err := s.db.ExecContext(ctx, `
+		UPDATE share_links SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL
+	`, time.Now(), id); err != nil {
+		return nil, fmt.Errorf("failed to revoke share link: %w", err)
+	}
+	return s.GetShareLink(ctx, id)
+}
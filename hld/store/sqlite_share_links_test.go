@@ -0,0 +1,53 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShareLinks(t *testing.T) {
+	dbPath := testutil.DatabasePath(t, "sqlite-share-links")
+	store, err := NewSQLiteStore(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+	require.NoError(t, store.CreateShareLink(ctx, &ShareLink{
+		ID: "share-1", SessionID: "sess-1", Scope: ShareScopeRead, Label: "for grace", CreatedAt: now, ExpiresAt: now.Add(time.Hour),
+	}))
+	require.NoError(t, store.CreateShareLink(ctx, &ShareLink{
+		ID: "share-2", SessionID: "sess-1", Scope: ShareScopeApprove, CreatedAt: now.Add(time.Second), ExpiresAt: now.Add(time.Hour),
+	}))
+	require.NoError(t, store.CreateShareLink(ctx, &ShareLink{ID: "share-3", SessionID: "sess-2", Scope: ShareScopeRead, ExpiresAt: now.Add(time.Hour)}))
+	assert.Error(t, store.CreateShareLink(ctx, &ShareLink{ID: "share-1", SessionID: "sess-2", Scope: ShareScopeRead, ExpiresAt: now}))
+
+	link, err := store.GetShareLink(ctx, "share-1")
+	require.NoError(t, err)
+	assert.Equal(t, "sess-1", link.SessionID)
+	assert.Equal(t, "for grace", link.Label)
+	assert.True(t, link.ExpiresAt.Equal(now.Add(time.Hour)))
+	assert.Nil(t, link.RevokedAt)
+	_, err = store.GetShareLink(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	links, err := store.ListShareLinks(ctx, "sess-1")
+	require.NoError(t, err)
+	require.Len(t, links, 2)
+	assert.Equal(t, "share-1", links[0].ID)
+	assert.Equal(t, ShareScopeApprove, links[1].Scope)
+
+	revoked, err := store.RevokeShareLink(ctx, "share-1")
+	require.NoError(t, err)
+	require.NotNil(t, revoked.RevokedAt)
+	again, err := store.RevokeShareLink(ctx, "share-1")
+	require.NoError(t, err)
+	assert.True(t, revoked.RevokedAt.Equal(*again.RevokedAt), "revoking again keeps the first time")
+	_, err = store.RevokeShareLink(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,80 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// GetLastEventTimes returns when each of sessionIDs last recorded a
+// conversation event, leaving out sessions without any
+func (s *SQLiteStore) GetLastEventTimes(ctx context.Context, sessionIDs []string) (map[string]time.Time, error) {
+	times := make(map[string]time.Time, len(sessionIDs))
+	if len(sessionIDs) == 0 {
+		return times, nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(sessionIDs)), ",")
+	args := make([]any, len(sessionIDs))
+	for i, id := range sessionIDs {
+		args[i] = id
+	}
+	// The latest event by id rather than MAX(created_at), which would lose
+	// the column's timestamp type
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT session_id, created_at FROM conversation_events
+		WHERE id IN (
+			SELECT MAX(id) FROM conversation_events
+			WHERE session_id IN (`+placeholders+`)
+			GROUP BY session_id
+		)
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last event times: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var sessionID string
+		var createdAt time.Time
+		if err := rows.Scan(&sessionID, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan last event time: %w", err)
+		}
+		times[sessionID] = createdAt
+	}
+	return times, rows.Err()
+}
+
+// CountPendingApprovals returns how many approvals each of sessionIDs has
+// pending, leaving out sessions without any
+func (s *SQLiteStore) CountPendingApprovals(ctx context.Context, sessionIDs []string) (map[string]int, error) {
+	counts := make(map[string]int)
+	if len(sessionIDs) == 0 {
+		return counts, nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(sessionIDs)), ",")
+	args := make([]any, 0, len(sessionIDs)+1)
+	args = append(args, ApprovalStatusLocalPending)
+	for _, id := range sessionIDs {
+		args = append(args, id)
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT session_id, COUNT(*) FROM approvals
+		WHERE status = ? AND session_id IN (`+placeholders+`)
+		GROUP BY session_id
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count pending approvals: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var sessionID string
+		var count int
+		if err := rows.Scan(&sessionID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan pending approval count: %w", err)
+		}
+		counts[sessionID] = count
+	}
+	return counts, rows.Err()
+}
@@ -0,0 +1,66 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLastEventTimes(t *testing.T) {
+	dbPath := testutil.DatabasePath(t, "sqlite-status")
+	store, err := NewSQLiteStore(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	for _, id := range []string{"s1", "s2", "s3"} {
+		require.NoError(t, store.CreateSession(ctx, &Session{ID: id, RunID: "r-" + id, ClaudeSessionID: "c-" + id, Status: SessionStatusRunning, CreatedAt: time.Now()}))
+	}
+	for _, id := range []string{"s1", "s1", "s2"} {
+		require.NoError(t, store.AddConversationEvent(ctx, &ConversationEvent{
+			SessionID: id, ClaudeSessionID: "c-" + id, EventType: EventTypeMessage, Role: "assistant", Content: "hi",
+		}))
+	}
+
+	times, err := store.GetLastEventTimes(ctx, []string{"s1", "s3", "missing"})
+	require.NoError(t, err)
+	require.Len(t, times, 1, "sessions without events are left out")
+	assert.WithinDuration(t, time.Now(), times["s1"], time.Minute)
+
+	times, err = store.GetLastEventTimes(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, times)
+}
+
+func TestCountPendingApprovals(t *testing.T) {
+	dbPath := testutil.DatabasePath(t, "sqlite-pending")
+	store, err := NewSQLiteStore(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	for _, id := range []string{"s1", "s2", "s3"} {
+		require.NoError(t, store.CreateSession(ctx, &Session{ID: id, RunID: "r-" + id, ClaudeSessionID: "c-" + id, Status: SessionStatusRunning, CreatedAt: time.Now()}))
+	}
+	for i, id := range []string{"s1", "s1", "s2", "s3"} {
+		require.NoError(t, store.CreateApproval(ctx, &Approval{
+			ID: fmt.Sprintf("a%d", i), RunID: "r-" + id, SessionID: id, Status: ApprovalStatusLocalPending,
+			CreatedAt: time.Now(), ToolName: "bash", ToolInput: json.RawMessage(`{}`),
+		}))
+	}
+	require.NoError(t, store.UpdateApprovalResponse(ctx, "a2", ApprovalStatusLocalApproved, ""))
+
+	counts, err := store.CountPendingApprovals(ctx, []string{"s1", "s2", "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"s1": 2}, counts, "decided approvals and sessions not asked about are left out")
+
+	counts, err = store.CountPendingApprovals(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, counts)
+}
@@ -0,0 +1,70 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// CreateSessionTicket links a ticket to a session; linking it again is a no-op
+func (s *SQLiteStore) CreateSessionTicket(ctx context.Context, ticket *SessionTicket) error {
+	if ticket.CreatedAt.IsZero() {
+		ticket.CreatedAt = time.Now()
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT OR IGNORE INTO session_tickets (session_id, tracker, ref, title, url, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, ticket.SessionID, ticket.Tracker, ticket.Ref, ticket.Title, ticket.URL, ticket.Status, ticket.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create session ticket: %w", err)
+	}
+	return nil
+}
+
+// ListSessionTickets returns the tickets linked to a session
+func (s *SQLiteStore) ListSessionTickets(ctx context.Context, sessionID string) ([]*SessionTicket, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT session_id, tracker, ref, title, url, status, synced_status, synced_at, created_at
+		FROM session_tickets WHERE session_id = ?
+		ORDER BY created_at, tracker, ref
+	`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list session tickets: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var tickets []*SessionTicket
+	for rows.Next() {
+		var ticket SessionTicket
+		var syncedAt sql.NullTime
+		if err := rows.Scan(&ticket.SessionID, &ticket.Tracker, &ticket.Ref, &ticket.Title, &ticket.URL,
+			&ticket.Status, &ticket.SyncedStatus, &syncedAt, &ticket.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan session ticket: %w", err)
+		}
+		if syncedAt.Valid {
+			ticket.SyncedAt = &syncedAt.Time
+		}
+		tickets = append(tickets, &ticket)
+	}
+	return tickets, rows.Err()
+}
+
+// MarkSessionTicketSynced records the status a ticket was moved to
+func (s *SQLiteStore) MarkSessionTicketSynced(ctx context.Context, sessionID, tracker, ref, status string) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE session_tickets SET synced_status = ?, synced_at = ?
+		WHERE session_id = ? AND tracker = ? AND ref = ?
+	`, status, time.Now(), sessionID, tracker, ref)
+	if err != nil {
+		return fmt.Errorf("failed to mark session ticket synced: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to mark session ticket synced: %w", err)
+	}
+	if n == 0 {
+		return &NotFoundError{Type: "session ticket", ID: sessionID + "/" + tracker + "/" + ref}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/humanlayer/humanlayer/hld/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionTickets(t *testing.T) {
+	dbPath := testutil.DatabasePath(t, "sqlite-tickets")
+	store, err := NewSQLiteStore(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	require.NoError(t, store.CreateSessionTicket(ctx, &SessionTicket{
+		SessionID: "s1", Tracker: "linear", Ref: "ENG-1", Title: "Fix login", URL: "https://linear.app/x/ENG-1", Status: "Todo",
+	}))
+	require.NoError(t, store.CreateSessionTicket(ctx, &SessionTicket{
+		SessionID: "s1", Tracker: "linear", Ref: "ENG-1", Title: "duplicate",
+	}), "linking twice is a no-op")
+
+	tickets, err := store.ListSessionTickets(ctx, "s1")
+	require.NoError(t, err)
+	require.Len(t, tickets, 1)
+	assert.Equal(t, "Fix login", tickets[0].Title)
+	assert.Nil(t, tickets[0].SyncedAt)
+
+	require.NoError(t, store.MarkSessionTicketSynced(ctx, "s1", "linear", "ENG-1", "In Review"))
+	tickets, err = store.ListSessionTickets(ctx, "s1")
+	require.NoError(t, err)
+	assert.Equal(t, "In Review", tickets[0].SyncedStatus)
+	assert.NotNil(t, tickets[0].SyncedAt)
+
+	err = store.MarkSessionTicketSynced(ctx, "s1", "asana", "1", "Review")
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	tickets, err = store.ListSessionTickets(ctx, "other")
+	require.NoError(t, err)
+	assert.Empty(t, tickets)
+}
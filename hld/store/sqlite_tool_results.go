@@ -0,0 +1,81 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// StoreToolResult records the output of an executed tool call. When no approval ID is
+// given, the approval created for the same tool_use_id (if any) is linked automatically.
+func (s *SQLiteStore) StoreToolResult(ctx context.Context, result *ToolResult) error {
+	if result.CreatedAt.IsZero() {
+		result.CreatedAt = time.Now()
+	}
+
+	if result.ApprovalID == "" {
+		var approvalID sql.NullString
+		err := s.db.QueryRowContext(ctx, `
+			SELECT id FROM approvals
+			WHERE session_id = ? AND tool_use_id = ?
+			ORDER BY created_at DESC LIMIT 1
+		`, result.SessionID, result.ToolUseID).Scan(&approvalID)
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("failed to look up approval for tool result: %w", err)
+		}
+		result.ApprovalID = approvalID.String
+	}
+
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO tool_results (
+			session_id, tool_use_id, approval_id, tool_name, content, is_error, source, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, result.SessionID, result.ToolUseID, nullIfEmpty(result.ApprovalID), result.ToolName,
+		result.Content, result.IsError, result.Source, result.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to store tool result: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err == nil {
+		result.ID = id
+	}
+	return nil
+}
+
+// GetToolResults retrieves captured tool results for a session in execution order
+func (s *SQLiteStore) GetToolResults(ctx context.Context, sessionID string) ([]*ToolResult, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, session_id, tool_use_id, approval_id, tool_name, content, is_error, source, created_at
+		FROM tool_results
+		WHERE session_id = ?
+		ORDER BY created_at ASC, id ASC
+	`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tool results: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var results []*ToolResult
+	for rows.Next() {
+		var r ToolResult
+		var approvalID, toolName sql.NullString
+		if err := rows.Scan(&r.ID, &r.SessionID, &r.ToolUseID, &approvalID, &toolName,
+			&r.Content, &r.IsError, &r.Source, &r.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan tool result: %w", err)
+		}
+		r.ApprovalID = approvalID.String
+		r.ToolName = toolName.String
+		results = append(results, &r)
+	}
+	return results, rows.Err()
+}
+
+// nullIfEmpty converts empty strings to NULL for optional columns
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
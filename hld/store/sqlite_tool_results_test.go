@@ -0,0 +1,75 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolResults(t *testing.T) {
+	dbPath := testutil.DatabasePath(t, "sqlite-tool-results")
+	store, err := NewSQLiteStore(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+
+	session := &Session{
+		ID:             "test-session",
+		RunID:          "test-run",
+		Query:          "Test query",
+		Status:         SessionStatusRunning,
+		CreatedAt:      time.Now(),
+		LastActivityAt: time.Now(),
+	}
+	require.NoError(t, store.CreateSession(ctx, session))
+
+	toolUseID := "toolu_123"
+	require.NoError(t, store.CreateApproval(ctx, &Approval{
+		ID:        "local-approval",
+		RunID:     session.RunID,
+		SessionID: session.ID,
+		ToolUseID: &toolUseID,
+		Status:    ApprovalStatusLocalApproved,
+		CreatedAt: time.Now(),
+		ToolName:  "Bash",
+		ToolInput: json.RawMessage(`{"command":"ls"}`),
+	}))
+
+	t.Run("links approval by tool_use_id", func(t *testing.T) {
+		result := &ToolResult{
+			SessionID: session.ID,
+			ToolUseID: toolUseID,
+			ToolName:  "Bash",
+			Content:   "README.md",
+			Source:    ToolResultSourceReported,
+		}
+		require.NoError(t, store.StoreToolResult(ctx, result))
+		assert.NotZero(t, result.ID)
+		assert.Equal(t, "local-approval", result.ApprovalID)
+	})
+
+	t.Run("stores results without approval", func(t *testing.T) {
+		require.NoError(t, store.StoreToolResult(ctx, &ToolResult{
+			SessionID: session.ID,
+			ToolUseID: "mcp-abc",
+			Content:   "boom",
+			IsError:   true,
+			Source:    ToolResultSourceDownstream,
+		}))
+	})
+
+	results, err := store.GetToolResults(ctx, session.ID)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "local-approval", results[0].ApprovalID)
+	assert.Equal(t, "README.md", results[0].Content)
+	assert.Empty(t, results[1].ApprovalID)
+	assert.True(t, results[1].IsError)
+	assert.Equal(t, ToolResultSourceDownstream, results[1].Source)
+}
@@ -0,0 +1,93 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WatchSessions makes a user watch sessions, keeping existing watches
+func (s *SQLiteStore) WatchSessions(ctx context.Context, user string, sessionIDs []string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now()
+	for _, id := range sessionIDs {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO session_watches (watcher, session_id, created_at)
+			VALUES (?, ?, ?)
+			ON CONFLICT(watcher, session_id) DO NOTHING
+		`, user, id, now)
+		if err != nil {
+			return fmt.Errorf("failed to watch session: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// UnwatchSessions stops a user watching sessions, or every session when
+// sessionIDs is empty
+func (s *SQLiteStore) UnwatchSessions(ctx context.Context, user string, sessionIDs []string) (int, error) {
+	query := `DELETE FROM session_watches WHERE watcher = ?`
+	args := []interface{}{user}
+	if len(sessionIDs) > 0 {
+		query += ` AND session_id IN (?` + strings.Repeat(", ?", len(sessionIDs)-1) + `)`
+		for _, id := range sessionIDs {
+			args = append(args, id)
+		}
+	}
+	result, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to unwatch sessions: %w", err)
+	}
+	removed, _ := result.RowsAffected()
+	return int(removed), nil
+}
+
+// ListWatches returns the sessions a user watches, oldest watch first
+func (s *SQLiteStore) ListWatches(ctx context.Context, user string) ([]*SessionWatch, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT watcher, session_id, created_at
+		FROM session_watches WHERE watcher = ?
+		ORDER BY created_at, session_id
+	`, user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list watches: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	watches := []*SessionWatch{}
+	for rows.Next() {
+		var watch SessionWatch
+		if err := rows.Scan(&watch.User, &watch.SessionID, &watch.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan watch: %w", err)
+		}
+		watches = append(watches, &watch)
+	}
+	return watches, rows.Err()
+}
+
+// ListSessionWatchers returns the users watching a session, sorted
+func (s *SQLiteStore) ListSessionWatchers(ctx context.Context, sessionID string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT watcher FROM session_watches WHERE session_id = ? ORDER BY watcher
+	`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list session watchers: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	users := []string{}
+	for rows.Next() {
+		var user string
+		if err := rows.Scan(&user); err != nil {
+			return nil, fmt.Errorf("failed to scan session watcher: %w", err)
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
@@ -0,0 +1,45 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/humanlayer/humanlayer/hld/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionWatches(t *testing.T) {
+	dbPath := testutil.DatabasePath(t, "sqlite-watches")
+	store, err := NewSQLiteStore(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	require.NoError(t, store.WatchSessions(ctx, "alice", []string{"s1", "s2", "s3"}))
+	require.NoError(t, store.WatchSessions(ctx, "alice", []string{"s1"}))
+	require.NoError(t, store.WatchSessions(ctx, "bob", []string{"s1"}))
+
+	watches, err := store.ListWatches(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, watches, 3)
+	assert.Equal(t, "s1", watches[0].SessionID)
+
+	watchers, err := store.ListSessionWatchers(ctx, "s1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice", "bob"}, watchers)
+
+	removed, err := store.UnwatchSessions(ctx, "alice", []string{"s1", "s9"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	watchers, err = store.ListSessionWatchers(ctx, "s1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bob"}, watchers)
+
+	removed, err = store.UnwatchSessions(ctx, "alice", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+	watches, err = store.ListWatches(ctx, "alice")
+	require.NoError(t, err)
+	assert.Empty(t, watches)
+}
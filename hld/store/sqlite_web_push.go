@@ -0,0 +1,63 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SaveWebPushSubscription records a subscription. A browser that subscribes
+// again keeps its endpoint, so the stored row's keys are replaced and its ID
+// returned.
+func (s *SQLiteStore) SaveWebPushSubscription(ctx context.Context, sub *WebPushSubscription) error {
+	if sub.CreatedAt.IsZero() {
+		sub.CreatedAt = time.Now()
+	}
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO web_push_subscriptions (id, endpoint, p256dh, auth, user_agent, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(endpoint) DO UPDATE SET
+			p256dh = excluded.p256dh,
+			auth = excluded.auth,
+			user_agent = excluded.user_agent
+		RETURNING id, created_at
+	`, sub.ID, sub.Endpoint, sub.P256DH, sub.Auth, sub.UserAgent, sub.CreatedAt).Scan(&sub.ID, &sub.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save web push subscription: %w", err)
+	}
+	return nil
+}
+
+// ListWebPushSubscriptions returns every subscription, oldest first
+func (s *SQLiteStore) ListWebPushSubscriptions(ctx context.Context) ([]*WebPushSubscription, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, endpoint, p256dh, auth, user_agent, created_at
+		FROM web_push_subscriptions ORDER BY created_at, id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list web push subscriptions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	subs := []*WebPushSubscription{}
+	for rows.Next() {
+		var sub WebPushSubscription
+		if err := rows.Scan(&sub.ID, &sub.Endpoint, &sub.P256DH, &sub.Auth, &sub.UserAgent, &sub.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan web push subscription: %w", err)
+		}
+		subs = append(subs, &sub)
+	}
+	return subs, rows.Err()
+}
+
+// DeleteWebPushSubscription removes a subscription
+func (s *SQLiteStore) DeleteWebPushSubscription(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM web_push_subscriptions WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete web push subscription: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return &NotFoundError{Type: "web push subscription", ID: id}
+	}
+	return nil
+}
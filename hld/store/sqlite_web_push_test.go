@@ -0,0 +1,40 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/humanlayer/humanlayer/hld/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebPushSubscriptions(t *testing.T) {
+	dbPath := testutil.DatabasePath(t, "sqlite-web-push")
+	store, err := NewSQLiteStore(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	first := &WebPushSubscription{ID: "w1", Endpoint: "https://push.example/a", P256DH: "key-1", Auth: "auth-1"}
+	require.NoError(t, store.SaveWebPushSubscription(ctx, first))
+	require.NoError(t, store.SaveWebPushSubscription(ctx, &WebPushSubscription{ID: "w2", Endpoint: "https://push.example/b", P256DH: "key-2", Auth: "auth-2"}))
+
+	// Subscribing again with the same endpoint updates the keys in place
+	again := &WebPushSubscription{ID: "w3", Endpoint: "https://push.example/a", P256DH: "key-3", Auth: "auth-3"}
+	require.NoError(t, store.SaveWebPushSubscription(ctx, again))
+	assert.Equal(t, "w1", again.ID)
+
+	subs, err := store.ListWebPushSubscriptions(ctx)
+	require.NoError(t, err)
+	require.Len(t, subs, 2)
+	assert.Equal(t, "w1", subs[0].ID)
+	assert.Equal(t, "key-3", subs[0].P256DH)
+
+	require.NoError(t, store.DeleteWebPushSubscription(ctx, "w1"))
+	assert.True(t, errors.Is(store.DeleteWebPushSubscription(ctx, "w1"), ErrNotFound))
+	subs, err = store.ListWebPushSubscriptions(ctx)
+	require.NoError(t, err)
+	assert.Len(t, subs, 1)
+}
@@ -0,0 +1,89 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const worktreeColumns = `session_id, repository, path, branch, base_commit, created_at, removed_at`
+
+// CreateSessionWorktree records the worktree a session works in
+func (s *SQLiteStore) CreateSessionWorktree(ctx context.Context, worktree *SessionWorktree) error {
+	if worktree.CreatedAt.IsZero() {
+		worktree.CreatedAt = time.Now()
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO session_worktrees (`+worktreeColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, worktree.SessionID, worktree.Repository, worktree.Path, worktree.Branch, worktree.BaseCommit, worktree.CreatedAt, worktree.RemovedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create session worktree: %w", err)
+	}
+	return nil
+}
+
+func scanWorktree(row interface{ Scan(...any) error }) (*SessionWorktree, error) {
+	var worktree SessionWorktree
+	var removedAt sql.NullTime
+	if err := row.Scan(&worktree.SessionID, &worktree.Repository, &worktree.Path, &worktree.Branch,
+		&worktree.BaseCommit, &worktree.CreatedAt, &removedAt); err != nil {
+		return nil, err
+	}
+	if removedAt.Valid {
+		worktree.RemovedAt = &removedAt.Time
+	}
+	return &worktree, nil
+}
+
+// GetSessionWorktree returns a session's worktree
+func (s *SQLiteStore) GetSessionWorktree(ctx context.Context, sessionID string) (*SessionWorktree, error) {
+	worktree, err := scanWorktree(s.db.QueryRowContext(ctx, `SELECT `+worktreeColumns+` FROM session_worktrees WHERE session_id = ?`, sessionID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &NotFoundError{Type: "session worktree", ID: sessionID}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session worktree: %w", err)
+	}
+	return worktree, nil
+}
+
+// ListActiveSessionWorktrees returns the worktrees not yet removed, oldest first
+func (s *SQLiteStore) ListActiveSessionWorktrees(ctx context.Context) ([]*SessionWorktree, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+worktreeColumns+` FROM session_worktrees
+		WHERE removed_at IS NULL
+		ORDER BY created_at, session_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list session worktrees: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	worktrees := []*SessionWorktree{}
+	for rows.Next() {
+		worktree, err := scanWorktree(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session worktree: %w", err)
+		}
+		worktrees = append(worktrees, worktree)
+	}
+	return worktrees, rows.Err()
+}
+
+// MarkSessionWorktreeRemoved sets removed_at once
+func (s *SQLiteStore) MarkSessionWorktreeRemoved(ctx context.Context, sessionID string) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE session_worktrees SET removed_at = ? WHERE session_id = ? AND removed_at IS NULL
+	`, time.Now(), sessionID)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark session worktree removed: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to mark session worktree removed: %w", err)
+	}
+	return n > 0, nil
+}
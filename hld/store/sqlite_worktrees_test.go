@@ -0,0 +1,60 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionWorktrees(t *testing.T) {
+	dbPath := testutil.DatabasePath(t, "sqlite-worktrees")
+	store, err := NewSQLiteStore(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+	require.NoError(t, store.CreateSessionWorktree(ctx, &SessionWorktree{
+		SessionID: "sess-2", Repository: "/src/app", Path: "/data/worktrees/app-2", Branch: "humanlayer/worktree-2", BaseCommit: "abc", CreatedAt: now.Add(time.Second),
+	}))
+	require.NoError(t, store.CreateSessionWorktree(ctx, &SessionWorktree{
+		SessionID: "sess-1", Repository: "/src/app", Path: "/data/worktrees/app-1", Branch: "humanlayer/worktree-1", BaseCommit: "abc", CreatedAt: now,
+	}))
+	assert.Error(t, store.CreateSessionWorktree(ctx, &SessionWorktree{SessionID: "sess-1", Repository: "/src/app", Path: "/elsewhere"}))
+
+	worktree, err := store.GetSessionWorktree(ctx, "sess-1")
+	require.NoError(t, err)
+	assert.Equal(t, "/data/worktrees/app-1", worktree.Path)
+	assert.Equal(t, "humanlayer/worktree-1", worktree.Branch)
+	assert.True(t, worktree.CreatedAt.Equal(now))
+	assert.Nil(t, worktree.RemovedAt)
+	_, err = store.GetSessionWorktree(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	active, err := store.ListActiveSessionWorktrees(ctx)
+	require.NoError(t, err)
+	require.Len(t, active, 2)
+	assert.Equal(t, "sess-1", active[0].SessionID)
+
+	removed, err := store.MarkSessionWorktreeRemoved(ctx, "sess-1")
+	require.NoError(t, err)
+	assert.True(t, removed)
+	removed, err = store.MarkSessionWorktreeRemoved(ctx, "sess-1")
+	require.NoError(t, err)
+	assert.False(t, removed, "already removed")
+	removed, err = store.MarkSessionWorktreeRemoved(ctx, "missing")
+	require.NoError(t, err)
+	assert.False(t, removed)
+
+	worktree, err = store.GetSessionWorktree(ctx, "sess-1")
+	require.NoError(t, err)
+	assert.NotNil(t, worktree.RemovedAt)
+	active, err = store.ListActiveSessionWorktrees(ctx)
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	assert.Equal(t, "sess-2", active[0].SessionID)
+}
@@ -8,7 +8,9 @@ import (
 	claudecode "github.com/humanlayer/humanlayer/claudecode-go"
 )
 
-// ConversationStore defines the interface for storing conversation data
+// ConversationStore defines the interface for storing conversation data.
+// Features that keep their own tables have separate interfaces below, which
+// the SQLite and in-memory stores also implement.
 type ConversationStore interface {
 	// Session operations
 	CreateSession(ctx context.Context, session *Session) error
@@ -25,6 +27,9 @@ type ConversationStore interface {
 	AddConversationEvent(ctx context.Context, event *ConversationEvent) error
 	GetConversation(ctx context.Context, claudeSessionID string) ([]*ConversationEvent, error)
 	GetSessionConversation(ctx context.Context, sessionID string) ([]*ConversationEvent, error)
+	// GetLastEventTimes returns when each of sessionIDs last recorded a
+	// conversation event, leaving out sessions without any
+	GetLastEventTimes(ctx context.Context, sessionIDs []string) (map[string]time.Time, error)
 
 	// Tool call operations
 	GetPendingToolCall(ctx context.Context, sessionID string, toolName string) (*ConversationEvent, error)
@@ -47,9 +52,20 @@ type ConversationStore interface {
 	CreateApproval(ctx context.Context, approval *Approval) error
 	GetApproval(ctx context.Context, id string) (*Approval, error)
 	GetPendingApprovals(ctx context.Context, sessionID string) ([]*Approval, error)
+	ListPendingApprovals(ctx context.Context) ([]*Approval, error)
+	// ListSessionApprovals returns every approval of a session, oldest first
+	ListSessionApprovals(ctx context.Context, sessionID string) ([]*Approval, error)
 	UpdateApprovalResponse(ctx context.Context, id string, status ApprovalStatus, comment string) error
+	SetApprovalCommentExpansion(ctx context.Context, id string, expansion string) error
+	// SetApprovalConstraints limits what a pending approval allows once approved
+	SetApprovalConstraints(ctx context.Context, id string, constraints *ApprovalConstraints) error
 	// StoreApprovalImages stores image paths for an approval decision
 	StoreApprovalImages(ctx context.Context, approvalID string, imagePaths []string) error
+	// CountApprovals counts approvals created since a time by session and status
+	CountApprovals(ctx context.Context, since time.Time) ([]ApprovalCount, error)
+	// CountPendingApprovals returns how many approvals each of sessionIDs has
+	// pending, leaving out sessions without any
+	CountPendingApprovals(ctx context.Context, sessionIDs []string) (map[string]int, error)
 
 	// File snapshot operations
 	CreateFileSnapshot(ctx context.Context, snapshot *FileSnapshot) error
@@ -65,6 +81,326 @@ type ConversationStore interface {
 	Close() error
 }
 
+// ToolResultStore keeps the output of executed tool calls
+type ToolResultStore interface {
+	StoreToolResult(ctx context.Context, result *ToolResult) error
+	GetToolResults(ctx context.Context, sessionID string) ([]*ToolResult, error)
+}
+
+// ShadowDecisionStore keeps what the shadow policy would have decided
+type ShadowDecisionStore interface {
+	StoreShadowDecision(ctx context.Context, decision *ShadowDecision) error
+	// ListShadowDecisions returns shadow decisions oldest first; an empty sessionID lists all sessions
+	ListShadowDecisions(ctx context.Context, sessionID string) ([]*ShadowDecision, error)
+}
+
+// JobStore keeps async jobs
+type JobStore interface {
+	CreateJob(ctx context.Context, job *Job) error
+	UpdateJob(ctx context.Context, job *Job) error
+	GetJob(ctx context.Context, id string) (*Job, error)
+	// ListJobs returns jobs newest first; an empty status lists every status
+	ListJobs(ctx context.Context, status string) ([]*Job, error)
+}
+
+// ExperimentStore keeps experiments and their runs
+type ExperimentStore interface {
+	CreateExperiment(ctx context.Context, experiment *Experiment) error
+	GetExperiment(ctx context.Context, id string) (*Experiment, error)
+	// ListExperiments returns experiments newest first
+	ListExperiments(ctx context.Context) ([]*Experiment, error)
+	CreateExperimentRun(ctx context.Context, run *ExperimentRun) error
+	UpdateExperimentRun(ctx context.Context, run *ExperimentRun) error
+	// GetExperimentRun returns the run for a session, or a NotFoundError
+	GetExperimentRun(ctx context.Context, sessionID string) (*ExperimentRun, error)
+	ListExperimentRuns(ctx context.Context, experimentID string) ([]*ExperimentRun, error)
+}
+
+// AnnotationStore keeps operators' notes on conversation events
+type AnnotationStore interface {
+	// CreateEventAnnotation returns a NotFoundError unless the event belongs to the session
+	CreateEventAnnotation(ctx context.Context, annotation *EventAnnotation) error
+	// ListEventAnnotations returns matching annotations oldest first
+	ListEventAnnotations(ctx context.Context, filter AnnotationFilter) ([]*EventAnnotation, error)
+	DeleteEventAnnotation(ctx context.Context, id int64) error
+}
+
+// AIOutputStore keeps model answers and the feedback given on them
+type AIOutputStore interface {
+	CreateAIOutput(ctx context.Context, output *AIOutput) error
+	GetAIOutput(ctx context.Context, id string) (*AIOutput, error)
+	// SetAIOutputFeedback replaces the output's rating and comment
+	SetAIOutputFeedback(ctx context.Context, id, rating, comment string) error
+	// ListAIOutputs returns matching outputs oldest first
+	ListAIOutputs(ctx context.Context, filter AIOutputFilter) ([]*AIOutput, error)
+}
+
+// EmbeddingStore keeps the vectors used to find similar sessions
+type EmbeddingStore interface {
+	// ReplaceSessionEmbeddings swaps a session's embeddings for the given ones
+	ReplaceSessionEmbeddings(ctx context.Context, sessionID string, embeddings []*SessionEmbedding) error
+	// ListSessionEmbeddings returns every embedding made with model
+	ListSessionEmbeddings(ctx context.Context, model string) ([]*SessionEmbedding, error)
+}
+
+// DecisionStore keeps the decision log
+type DecisionStore interface {
+	CreateDecision(ctx context.Context, decision *Decision) error
+	GetDecision(ctx context.Context, id string) (*Decision, error)
+	UpdateDecision(ctx context.Context, decision *Decision) error
+	DeleteDecision(ctx context.Context, id string) error
+	// ListDecisions returns matching decisions newest first
+	ListDecisions(ctx context.Context, filter DecisionFilter) ([]*Decision, error)
+}
+
+// ContextPackStore keeps context assembled for session launches
+type ContextPackStore interface {
+	CreateContextPack(ctx context.Context, pack *ContextPack) error
+	GetContextPack(ctx context.Context, id string) (*ContextPack, error)
+}
+
+// CredentialStore keeps git remote credentials. Secrets are stored as given,
+// so callers encrypt them first.
+type CredentialStore interface {
+	UpsertRemoteCredential(ctx context.Context, credential *RemoteCredential) error
+	// ListRemoteCredentials returns every credential by URL prefix
+	ListRemoteCredentials(ctx context.Context) ([]*RemoteCredential, error)
+	DeleteRemoteCredential(ctx context.Context, urlPrefix string) error
+}
+
+// ArtifactStore keeps files sessions publish, such as builds and reports
+type ArtifactStore interface {
+	CreateArtifact(ctx context.Context, artifact *Artifact) error
+	GetArtifact(ctx context.Context, id string) (*Artifact, error)
+	// ListSessionArtifacts returns a session's artifacts, oldest first
+	ListSessionArtifacts(ctx context.Context, sessionID string) ([]*Artifact, error)
+	// ListExpiredArtifacts returns artifacts whose retention ended before a time
+	ListExpiredArtifacts(ctx context.Context, before time.Time) ([]*Artifact, error)
+	DeleteArtifact(ctx context.Context, id string) error
+}
+
+// WebPushStore keeps the browsers notified of approvals
+type WebPushStore interface {
+	// SaveWebPushSubscription records a subscription, replacing any with the
+	// same endpoint, and sets its ID to the one stored
+	SaveWebPushSubscription(ctx context.Context, sub *WebPushSubscription) error
+	ListWebPushSubscriptions(ctx context.Context) ([]*WebPushSubscription, error)
+	DeleteWebPushSubscription(ctx context.Context, id string) error
+}
+
+// WatchStore keeps which users follow which sessions
+type WatchStore interface {
+	// WatchSessions makes a user watch sessions, keeping existing watches
+	WatchSessions(ctx context.Context, user string, sessionIDs []string) error
+	// UnwatchSessions stops a user watching sessions, or every session when
+	// sessionIDs is empty, and returns how many watches were removed
+	UnwatchSessions(ctx context.Context, user string, sessionIDs []string) (int, error)
+	ListWatches(ctx context.Context, user string) ([]*SessionWatch, error)
+	ListSessionWatchers(ctx context.Context, sessionID string) ([]string, error)
+}
+
+// CannedResponseStore keeps reusable decision comments
+type CannedResponseStore interface {
+	CreateCannedResponse(ctx context.Context, response *CannedResponse) error
+	GetCannedResponse(ctx context.Context, id string) (*CannedResponse, error)
+	// ListCannedResponses returns every canned response, most used first
+	ListCannedResponses(ctx context.Context) ([]*CannedResponse, error)
+	UpdateCannedResponse(ctx context.Context, response *CannedResponse) error
+	DeleteCannedResponse(ctx context.Context, id string) error
+	// RecordCannedResponseUse counts a decision made with a canned response
+	RecordCannedResponseUse(ctx context.Context, id string) error
+}
+
+// InboxStore keeps each user's read and snoozed approvals
+type InboxStore interface {
+	MarkInboxRead(ctx context.Context, user string, approvalIDs []string, read bool) error
+	// SnoozeInboxItem hides an approval from a user until a time, or brings it
+	// back when until is nil
+	SnoozeInboxItem(ctx context.Context, user, approvalID string, until *time.Time) error
+	ListInboxStates(ctx context.Context, user string) ([]*InboxState, error)
+	// ListDueSnoozes returns snoozes ending at or before a time
+	ListDueSnoozes(ctx context.Context, before time.Time) ([]*InboxState, error)
+}
+
+// HoldStore keeps pending approvals parked with a reason
+type HoldStore interface {
+	// HoldApproval parks an approval, replacing any earlier hold
+	HoldApproval(ctx context.Context, hold *ApprovalHold) error
+	GetApprovalHold(ctx context.Context, approvalID string) (*ApprovalHold, error)
+	// ListApprovalHolds returns every hold, oldest first
+	ListApprovalHolds(ctx context.Context) ([]*ApprovalHold, error)
+	// ReleaseApprovalHold unparks an approval, returning false if it wasn't held
+	ReleaseApprovalHold(ctx context.Context, approvalID string) (bool, error)
+}
+
+// ConstraintViolationStore keeps tool calls that broke an approval's constraints
+type ConstraintViolationStore interface {
+	RecordConstraintViolation(ctx context.Context, violation *ConstraintViolation) error
+	// ListConstraintViolations returns a session's violations, or every
+	// violation for an empty session ID, oldest first
+	ListConstraintViolations(ctx context.Context, sessionID string) ([]*ConstraintViolation, error)
+}
+
+// CloudMirrorStore keeps approvals relayed to HumanLayer cloud
+type CloudMirrorStore interface {
+	CreateCloudMirror(ctx context.Context, mirror *CloudMirror) error
+	GetCloudMirror(ctx context.Context, approvalID string) (*CloudMirror, error)
+	// ListOpenCloudMirrors returns the mirrors not yet settled, oldest first
+	ListOpenCloudMirrors(ctx context.Context) ([]*CloudMirror, error)
+	// SettleCloudMirror records how an open mirror ended, returning false if
+	// it was already settled
+	SettleCloudMirror(ctx context.Context, approvalID, state string, remoteApproved *bool, remoteComment string) (bool, error)
+}
+
+// GitHubTriggerStore keeps the GitHub events that launched sessions
+type GitHubTriggerStore interface {
+	CreateGitHubTrigger(ctx context.Context, trigger *GitHubTrigger) error
+	GetGitHubTriggerBySession(ctx context.Context, sessionID string) (*GitHubTrigger, error)
+	// MarkGitHubTriggerReported records that the session's outcome was posted;
+	// it returns false if it already was
+	MarkGitHubTriggerReported(ctx context.Context, sessionID string) (bool, error)
+}
+
+// EscalationStore keeps critical approvals and whether they were escalated
+type EscalationStore interface {
+	CreateApprovalEscalation(ctx context.Context, escalation *ApprovalEscalation) error
+	GetApprovalEscalation(ctx context.Context, approvalID string) (*ApprovalEscalation, error)
+	// ListOpenApprovalEscalations returns the escalations not yet resolved, oldest first
+	ListOpenApprovalEscalations(ctx context.Context) ([]*ApprovalEscalation, error)
+	// MarkApprovalEscalationTriggered and MarkApprovalEscalationResolved set
+	// their timestamp once, returning false if it already was
+	MarkApprovalEscalationTriggered(ctx context.Context, approvalID string) (bool, error)
+	MarkApprovalEscalationResolved(ctx context.Context, approvalID string) (bool, error)
+}
+
+// TicketStore keeps the tracker tickets linked to sessions
+type TicketStore interface {
+	CreateSessionTicket(ctx context.Context, ticket *SessionTicket) error
+	ListSessionTickets(ctx context.Context, sessionID string) ([]*SessionTicket, error)
+	// MarkSessionTicketSynced records the status a ticket was moved to
+	MarkSessionTicketSynced(ctx context.Context, sessionID, tracker, ref, status string) error
+}
+
+// CommitStore keeps the commits the daemon made
+type CommitStore interface {
+	// CreateSessionCommit records a commit the daemon made; recording it again is a no-op
+	CreateSessionCommit(ctx context.Context, commit *SessionCommit) error
+	// ListSessionCommits returns the commits made since a time, oldest first
+	ListSessionCommits(ctx context.Context, since time.Time) ([]*SessionCommit, error)
+}
+
+// MemoryFileStore keeps proposed updates to repositories' agent memory files
+type MemoryFileStore interface {
+	CreateMemoryFileProposal(ctx context.Context, proposal *MemoryFileProposal) error
+	GetMemoryFileProposal(ctx context.Context, id string) (*MemoryFileProposal, error)
+	// ResolveMemoryFileProposal records that a pending proposal was applied or rejected
+	ResolveMemoryFileProposal(ctx context.Context, id, status, commitHash string) error
+	// ListMemoryFileProposals returns proposals newest first; empty arguments match everything
+	ListMemoryFileProposals(ctx context.Context, repository, status string) ([]*MemoryFileProposal, error)
+}
+
+// ProcessStore keeps what is known about the Claude process each session ran
+type ProcessStore interface {
+	// RecordSessionProcess creates or replaces a session's process record
+	RecordSessionProcess(ctx context.Context, process *SessionProcess) error
+	GetSessionProcess(ctx context.Context, sessionID string) (*SessionProcess, error)
+}
+
+// InjectionStore keeps suspected prompt injections found in text bound for
+// the daemon's own prompts
+type InjectionStore interface {
+	RecordInjectionDetection(ctx context.Context, detection *InjectionDetection) error
+	// ListInjectionDetections returns a session's detections, or every
+	// detection for an empty session ID, oldest first
+	ListInjectionDetections(ctx context.Context, sessionID string) ([]*InjectionDetection, error)
+}
+
+// ModerationStore keeps text the content safety filter kept from agents and
+// notification channels, for admins to review
+type ModerationStore interface {
+	RecordModerationBlock(ctx context.Context, block *ModerationBlock) error
+	// ListModerationBlocks returns blocks oldest first, only those not yet
+	// reviewed when unreviewed is set
+	ListModerationBlocks(ctx context.Context, unreviewed bool) ([]*ModerationBlock, error)
+	// ReviewModerationBlock marks a block reviewed with an optional note
+	ReviewModerationBlock(ctx context.Context, id int64, reviewer, note string) (*ModerationBlock, error)
+}
+
+// ResolutionStore makes signed approval resolutions single-use and keeps
+// the ones that were rejected
+type ResolutionStore interface {
+	// UseResolutionNonce marks a nonce used until it expires, returning
+	// ErrNonceUsed if it already was. Expired nonces are forgotten.
+	UseResolutionNonce(ctx context.Context, nonce, approvalID string, expiresAt time.Time) error
+	// ReleaseResolutionNonce lets a nonce be used again, for resolutions
+	// that were refused before they decided anything
+	ReleaseResolutionNonce(ctx context.Context, nonce string) error
+	RecordRejectedResolution(ctx context.Context, rejection *RejectedResolution) error
+	// ListRejectedResolutions returns an approval's rejections, or every
+	// rejection for an empty approval ID, oldest first
+	ListRejectedResolutions(ctx context.Context, approvalID string) ([]*RejectedResolution, error)
+}
+
+// ShareLinkStore keeps the links that show a session to people without
+// daemon access
+type ShareLinkStore interface {
+	CreateShareLink(ctx context.Context, link *ShareLink) error
+	GetShareLink(ctx context.Context, id string) (*ShareLink, error)
+	// ListShareLinks returns a session's links, oldest first
+	ListShareLinks(ctx context.Context, sessionID string) ([]*ShareLink, error)
+	// RevokeShareLink stops a link working. Revoking a revoked link keeps
+	// the first time.
+	RevokeShareLink(ctx context.Context, id string) (*ShareLink, error)
+}
+
+// WorktreeStore keeps the git worktrees created for sessions launched with
+// use_worktree
+type WorktreeStore interface {
+	CreateSessionWorktree(ctx context.Context, worktree *SessionWorktree) error
+	GetSessionWorktree(ctx context.Context, sessionID string) (*SessionWorktree, error)
+	// ListActiveSessionWorktrees returns the worktrees not yet removed,
+	// oldest first
+	ListActiveSessionWorktrees(ctx context.Context) ([]*SessionWorktree, error)
+	// MarkSessionWorktreeRemoved records that a worktree was removed,
+	// returning false if it already was
+	MarkSessionWorktreeRemoved(ctx context.Context, sessionID string) (bool, error)
+}
+
+// Store is every store the daemon opens. Only the daemon's wiring should
+// depend on it; components take the interfaces above they actually use.
+type Store interface {
+	ConversationStore
+	ToolResultStore
+	ShadowDecisionStore
+	JobStore
+	ExperimentStore
+	AnnotationStore
+	AIOutputStore
+	EmbeddingStore
+	DecisionStore
+	ContextPackStore
+	CredentialStore
+	ArtifactStore
+	WebPushStore
+	WatchStore
+	CannedResponseStore
+	InboxStore
+	HoldStore
+	ConstraintViolationStore
+	CloudMirrorStore
+	GitHubTriggerStore
+	EscalationStore
+	TicketStore
+	CommitStore
+	MemoryFileStore
+	ProcessStore
+	InjectionStore
+	ModerationStore
+	ResolutionStore
+	ShareLinkStore
+	WorktreeStore
+}
+
 // UserSettings represents user preferences
 type UserSettings struct {
 	AdvancedProviders bool      `json:"advanced_providers"`
@@ -122,6 +458,32 @@ type Session struct {
 
 	// Editor state for draft sessions (JSON blob)
 	EditorState *string `db:"editor_state"`
+
+	// Auto-deny configuration for observation-only sessions
+	AutoDenyAll   bool   `db:"auto_deny_all"`
+	AutoDenyTools string `db:"auto_deny_tools"` // JSON array of tool names or prefixes ending in "*"
+
+	// Resource limits enforced on approvals (JSON object, empty when unlimited)
+	Budget string `db:"budget"`
+
+	// Label of the saved template the session was launched from, if any
+	Template string `db:"template"`
+
+	// SSH destination (host, user@host or ssh config alias) holding the
+	// working directory; empty when it is local
+	SSHHost string `db:"ssh_host"`
+
+	// Container image the agent runs in; empty when it runs on the host
+	ContainerImage string `db:"container_image"`
+
+	// Structured summary (JSON object) generated when the session finished
+	CompletionSummary string `db:"completion_summary"`
+
+	// ID of the failed session this one automatically retries, if any
+	RetryOf string `db:"retry_of"`
+
+	// ID of the context pack attached when the session was launched, if any
+	ContextPackID string `db:"context_pack_id"`
 }
 
 // SessionUpdate contains fields that can be updated
@@ -161,6 +523,18 @@ type SessionUpdate struct {
 	WorkingDir *string `db:"working_dir"`
 	// Editor state field (JSON blob)
 	EditorState *string `db:"editor_state"`
+	// Auto-deny fields
+	AutoDenyAll   *bool   `db:"auto_deny_all"`
+	AutoDenyTools *string `db:"auto_deny_tools"`
+	// Resource limits field (JSON object)
+	Budget *string `db:"budget"`
+	// SSH destination for the working directory ("" makes it local again)
+	SSHHost *string `db:"ssh_host"`
+	// Set once a devcontainer image has been prepared for the session
+	ContainerImage    *string `db:"container_image"`
+	CompletionSummary *string `db:"completion_summary"`
+	RetryOf           *string `db:"retry_of"`
+	ContextPackID     *string `db:"context_pack_id"`
 }
 
 // ConversationEvent represents a single event in a conversation
@@ -202,6 +576,535 @@ type FileSnapshot struct {
 	CreatedAt time.Time
 }
 
+// ToolResult is the output of an executed tool call, captured after approval
+type ToolResult struct {
+	ID         int64     `json:"id"`
+	SessionID  string    `json:"session_id"`
+	ToolUseID  string    `json:"tool_use_id"`
+	ApprovalID string    `json:"approval_id,omitempty"`
+	ToolName   string    `json:"tool_name,omitempty"`
+	Content    string    `json:"content"`
+	IsError    bool      `json:"is_error"`
+	Source     string    `json:"source"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ShadowDecision records what a shadow policy would have decided for an
+// approval, without that decision having been acted on
+type ShadowDecision struct {
+	ApprovalID string    `json:"approval_id"`
+	SessionID  string    `json:"session_id"`
+	ToolName   string    `json:"tool_name"`
+	Action     string    `json:"action,omitempty"` // allow, deny, ask, or empty when no rule matched
+	Rule       string    `json:"rule,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Job is a long-running operation (such as commit message generation) run
+// in the background, whose result is fetched once it completes
+type Job struct {
+	ID          string          `json:"id"`
+	Kind        string          `json:"kind"`
+	SessionID   string          `json:"session_id,omitempty"`
+	Status      string          `json:"status"`
+	Result      json.RawMessage `json:"result,omitempty"`
+	Error       string          `json:"error,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	StartedAt   *time.Time      `json:"started_at,omitempty"`
+	CompletedAt *time.Time      `json:"completed_at,omitempty"`
+}
+
+// Experiment launches one query as several sessions with varied parameters
+type Experiment struct {
+	ID          string          `json:"id"`
+	Name        string          `json:"name,omitempty"`
+	Query       string          `json:"query"`
+	WorkingDir  string          `json:"working_dir"`
+	BaseCommit  string          `json:"base_commit"`
+	TestCommand string          `json:"test_command,omitempty"`
+	Variants    json.RawMessage `json:"variants"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// ExperimentRun is one session of an experiment, run in its own worktree.
+// The outcome fields are filled in once the session finishes.
+type ExperimentRun struct {
+	SessionID    string     `json:"session_id"`
+	ExperimentID string     `json:"experiment_id"`
+	Variant      string     `json:"variant"`
+	Worktree     string     `json:"worktree"`
+	FilesChanged *int       `json:"files_changed,omitempty"`
+	LinesAdded   *int       `json:"lines_added,omitempty"`
+	LinesRemoved *int       `json:"lines_removed,omitempty"`
+	TestsPassed  *bool      `json:"tests_passed,omitempty"`
+	TestOutput   string     `json:"test_output,omitempty"`
+	EvaluatedAt  *time.Time `json:"evaluated_at,omitempty"`
+}
+
+// Kinds of AI output users can rate
+const (
+	AIOutputCommitMessage   = "commit_message"
+	AIOutputEphemeralChat   = "ephemeral_chat"
+	AIOutputDenialExpansion = "denial_expansion"
+)
+
+// Feedback ratings
+const (
+	RatingUp   = "up"
+	RatingDown = "down"
+)
+
+// AIOutput is a model's answer to a daemon prompt, kept with its redacted
+// prompt so users can rate it and templates can be compared
+type AIOutput struct {
+	ID        string `json:"id"`
+	Kind      string `json:"kind"`
+	SessionID string `json:"session_id,omitempty"`
+	// Prompt template name and a hash of the text in use, so overrides can be compared
+	Template        string     `json:"template"`
+	TemplateVersion string     `json:"template_version"`
+	Provider        string     `json:"provider,omitempty"`
+	Model           string     `json:"model,omitempty"`
+	Prompt          string     `json:"prompt"`
+	Response        string     `json:"response"`
+	CreatedAt       time.Time  `json:"created_at"`
+	Rating          string     `json:"rating,omitempty"` // RatingUp, RatingDown or empty when unrated
+	Comment         string     `json:"comment,omitempty"`
+	RatedAt         *time.Time `json:"rated_at,omitempty"`
+}
+
+// AIOutputFilter selects AI outputs; zero fields match everything
+type AIOutputFilter struct {
+	Kind     string
+	Template string
+	Since    time.Time
+	// Only outputs that have a rating
+	Rated bool
+}
+
+// Sources of the text behind a session embedding
+const (
+	EmbeddingSourceSummary = "summary"
+	EmbeddingSourceQuery   = "query"
+	EmbeddingSourceMessage = "message" // A later user message
+	EmbeddingSourceResult  = "result"
+)
+
+// Decision statuses; only active decisions are given to new sessions
+const (
+	DecisionStatusActive     = "active"
+	DecisionStatusSuperseded = "superseded"
+	DecisionStatusRejected   = "rejected"
+)
+
+// Decision is a durable choice made in a repository ("we chose library X
+// because ..."), mined from a completed session or recorded by hand
+type Decision struct {
+	ID string `json:"id"`
+	// Repository is the root of the git repository the decision applies to
+	Repository   string    `json:"repository"`
+	SessionID    string    `json:"session_id,omitempty"` // Session it was mined from
+	Title        string    `json:"title"`
+	Decision     string    `json:"decision"`
+	Rationale    string    `json:"rationale,omitempty"`
+	Alternatives []string  `json:"alternatives,omitempty"` // Options considered and not chosen
+	Status       string    `json:"status"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// DecisionFilter selects decisions; zero fields match everything
+type DecisionFilter struct {
+	Repository string
+	SessionID  string
+	Status     string
+	Limit      int
+}
+
+// ContextPack is context assembled for a session launch: relevant files,
+// related sessions and open issues. Content is the pack as built, including
+// the steps taken to assemble it.
+type ContextPack struct {
+	ID         string          `json:"id"`
+	Query      string          `json:"query"`
+	WorkingDir string          `json:"working_dir"`
+	Content    json.RawMessage `json:"content"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// RemoteCredential authenticates git over HTTPS to remotes under URLPrefix.
+// Secret is the encrypted token.
+type RemoteCredential struct {
+	URLPrefix string    `json:"url_prefix"`
+	Username  string    `json:"username"`
+	Secret    []byte    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Artifact is a file a session published, such as a built binary, a
+// coverage report or a screenshot. Its content is kept in blob storage.
+type Artifact struct {
+	ID        string `json:"id"`
+	SessionID string `json:"session_id"`
+	Name      string `json:"name"`
+	// Kind is free-form, such as binary, coverage or screenshot
+	Kind        string    `json:"kind,omitempty"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256"`
+	CreatedAt   time.Time `json:"created_at"`
+	// ExpiresAt is when retention ends; nil keeps the artifact
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// WebPushSubscription is a browser's push endpoint and the keys its
+// messages are encrypted for
+type WebPushSubscription struct {
+	ID       string `json:"id"`
+	Endpoint string `json:"endpoint"`
+	// P256DH and Auth are the browser's base64url keys
+	P256DH    string    `json:"p256dh"`
+	Auth      string    `json:"auth"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SessionWatch is a user following a session's approvals and completion
+type SessionWatch struct {
+	User      string    `json:"user"`
+	SessionID string    `json:"session_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CannedResponse is a reusable comment for approval decisions, usually a
+// denial's feedback to the agent
+type CannedResponse struct {
+	ID string `json:"id"`
+	// Team owns the response; empty means it's shared by everyone
+	Team       string     `json:"team,omitempty"`
+	Title      string     `json:"title"`
+	Text       string     `json:"text"`
+	UseCount   int        `json:"use_count"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// InboxState is a user's read and snooze state for an approval
+type InboxState struct {
+	User         string     `json:"user"`
+	ApprovalID   string     `json:"approval_id"`
+	ReadAt       *time.Time `json:"read_at,omitempty"`
+	SnoozedUntil *time.Time `json:"snoozed_until,omitempty"`
+}
+
+// ApprovalHold parks a pending approval: it stays pending, but the approver
+// has said why it's waiting and, optionally, when they'll come back to it
+type ApprovalHold struct {
+	ApprovalID string     `json:"approval_id"`
+	SessionID  string     `json:"session_id"`
+	Reason     string     `json:"reason"`
+	ResumeAt   *time.Time `json:"resume_at,omitempty"`
+	HeldBy     string     `json:"held_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// ApprovalConstraints limit what an approval allows. A tool call reported
+// after ExpiresAt, or touching a file that isn't in Files, violates them.
+type ApprovalConstraints struct {
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Files     []string   `json:"files,omitempty"`
+}
+
+// Constraint violation kinds
+const (
+	ConstraintViolationExpired = "expired"
+	ConstraintViolationFile    = "file_not_allowed"
+)
+
+// ConstraintViolation records a tool call that didn't keep to the
+// constraints it was approved with
+type ConstraintViolation struct {
+	ID         int64     `json:"id"`
+	ApprovalID string    `json:"approval_id"`
+	SessionID  string    `json:"session_id"`
+	ToolUseID  string    `json:"tool_use_id"`
+	Kind       string    `json:"kind"`
+	Detail     string    `json:"detail"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// InjectionDetection is a line of session text that looked like
+// instructions aimed at the model, found while building a prompt
+type InjectionDetection struct {
+	ID        int64  `json:"id"`
+	SessionID string `json:"session_id"`
+	// Prompt is what was being built, such as commit-message
+	Prompt string `json:"prompt"`
+	// Field is where the text came from, such as query or recent_commits
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Excerpt string `json:"excerpt"`
+	// Stripped is set when the line was left out of the prompt
+	Stripped  bool      `json:"stripped"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Kinds of text the content safety filter checks
+const (
+	ModerationApprovalComment = "approval_comment"
+	ModerationDenialExpansion = "denial_expansion"
+	ModerationSessionSummary  = "session_summary"
+)
+
+// ModerationBlock is text the content safety filter kept from being sent
+type ModerationBlock struct {
+	ID         int64  `json:"id"`
+	Kind       string `json:"kind"`
+	SessionID  string `json:"session_id,omitempty"`
+	ApprovalID string `json:"approval_id,omitempty"`
+	// Content is the blocked text with credentials redacted
+	Content string `json:"content"`
+	// Source is "pattern" or "model"
+	Source     string     `json:"source"`
+	Reason     string     `json:"reason"`
+	Categories []string   `json:"categories,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+	Reviewer   string     `json:"reviewer,omitempty"`
+	ReviewNote string     `json:"review_note,omitempty"`
+}
+
+// RejectedResolution is an approval resolution refused for a bad or
+// expired signature, or because it was already used
+type RejectedResolution struct {
+	ID int64 `json:"id"`
+	// ApprovalID is who the resolution claimed to be for; it may not exist
+	ApprovalID string `json:"approval_id,omitempty"`
+	// Channel is where it arrived, such as email or federation
+	Channel string `json:"channel"`
+	// Source is who sent it, such as an email address or remote address
+	Source    string    `json:"source,omitempty"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// What a share link lets its holder do
+const (
+	ShareScopeRead    = "read"    // See the transcript, diff and pending approvals
+	ShareScopeApprove = "approve" // Also decide the session's approvals
+)
+
+// ShareLink lets whoever holds its token see one session until it expires
+// or is revoked
+type ShareLink struct {
+	ID        string `json:"id"`
+	SessionID string `json:"session_id"`
+	Scope     string `json:"scope"`
+	// Label says who or what the link is for
+	Label     string     `json:"label,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// SessionWorktree is the git worktree a session works in instead of the
+// checkout it was launched in
+type SessionWorktree struct {
+	SessionID string `json:"session_id"`
+	// Repository is the main checkout the worktree belongs to
+	Repository string `json:"repository"`
+	Path       string `json:"path"`
+	Branch     string `json:"branch"`
+	// BaseCommit is the commit the branch started from
+	BaseCommit string     `json:"base_commit"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RemovedAt  *time.Time `json:"removed_at,omitempty"`
+}
+
+// Cloud mirror states
+const (
+	CloudMirrorOpen     = "open"
+	CloudMirrorSettled  = "settled"
+	CloudMirrorConflict = "conflict" // Decided differently locally and in the cloud
+)
+
+// CloudMirror is an approval mirrored to HumanLayer cloud as a function call
+type CloudMirror struct {
+	ApprovalID     string     `json:"approval_id"`
+	CallID         string     `json:"call_id"`
+	State          string     `json:"state"`
+	RemoteApproved *bool      `json:"remote_approved,omitempty"`
+	RemoteComment  string     `json:"remote_comment,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	SettledAt      *time.Time `json:"settled_at,omitempty"`
+}
+
+// GitHub trigger kinds
+const (
+	GitHubTriggerFix    = "fix"    // Issue labelled for a fix
+	GitHubTriggerReview = "review" // Review requested in a pull request comment
+)
+
+// GitHubTrigger links a session to the issue or pull request whose webhook
+// launched it, so its outcome can be reported back as a comment
+type GitHubTrigger struct {
+	DeliveryID string     `json:"delivery_id"`
+	SessionID  string     `json:"session_id"`
+	Repository string     `json:"repository"` // owner/name
+	Number     int        `json:"number"`
+	Kind       string     `json:"kind"`
+	Worktree   string     `json:"worktree"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ReportedAt *time.Time `json:"reported_at,omitempty"`
+}
+
+// ApprovalEscalation tracks a critical approval: when it was created, when
+// an incident was raised for it going unanswered, and when it was settled
+type ApprovalEscalation struct {
+	ApprovalID  string     `json:"approval_id"`
+	SessionID   string     `json:"session_id"`
+	ToolName    string     `json:"tool_name"`
+	Rule        string     `json:"rule"` // Policy rule that marked it critical
+	CreatedAt   time.Time  `json:"created_at"`
+	TriggeredAt *time.Time `json:"triggered_at,omitempty"`
+	ResolvedAt  *time.Time `json:"resolved_at,omitempty"`
+}
+
+// SessionTicket links a session to an issue tracker ticket referenced in its
+// query
+type SessionTicket struct {
+	SessionID    string     `json:"session_id"`
+	Tracker      string     `json:"tracker"` // Name of the configured tracker
+	Ref          string     `json:"ref"`     // Identifier such as ENG-123 or an Asana task gid
+	Title        string     `json:"title"`
+	URL          string     `json:"url"`
+	Status       string     `json:"status"` // Status when the session launched
+	SyncedStatus string     `json:"synced_status,omitempty"`
+	SyncedAt     *time.Time `json:"synced_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// SessionCommit is a commit the daemon made in a session's repository
+type SessionCommit struct {
+	SessionID string `json:"session_id"`
+	// Repository is the root of the git repository
+	Repository string    `json:"repository"`
+	Hash       string    `json:"hash"`
+	Additions  int       `json:"additions"`
+	Deletions  int       `json:"deletions"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ApprovalCount is how many of a session's approvals have a status
+type ApprovalCount struct {
+	SessionID string         `json:"session_id"`
+	Status    ApprovalStatus `json:"status"`
+	Count     int            `json:"count"`
+}
+
+// Memory file proposal statuses
+const (
+	ProposalStatusPending  = "pending"
+	ProposalStatusApplied  = "applied"
+	ProposalStatusRejected = "rejected"
+)
+
+// SessionProcess is the supervised Claude process of a session. Resource
+// figures are the last sampled while it ran.
+type SessionProcess struct {
+	SessionID string     `json:"session_id"`
+	PID       int        `json:"pid"`
+	StartedAt time.Time  `json:"started_at"`
+	ExitedAt  *time.Time `json:"exited_at,omitempty"`
+	// ExitCode is -1 when the process was killed by a signal
+	ExitCode   *int   `json:"exit_code,omitempty"`
+	ExitSignal string `json:"exit_signal,omitempty"`
+	// StderrTail is the end of what the process wrote to stderr
+	StderrTail   string  `json:"stderr_tail,omitempty"`
+	RSSBytes     int64   `json:"rss_bytes"`
+	PeakRSSBytes int64   `json:"peak_rss_bytes"`
+	CPUSeconds   float64 `json:"cpu_seconds"`
+}
+
+// MemoryFileProposal is a suggested update to a repository's agent memory
+// file (CLAUDE.md), drawn from lessons of completed sessions. It is only
+// written to the repository once a person approves it.
+type MemoryFileProposal struct {
+	ID         string `json:"id"`
+	Repository string `json:"repository"`
+	// Path of the memory file relative to the repository root
+	Path string `json:"path"`
+	// BaseContent is the file as it was when proposed; approval fails if it has changed since
+	BaseContent     string     `json:"base_content"`
+	ProposedContent string     `json:"proposed_content"`
+	Diff            string     `json:"diff"`
+	Rationale       string     `json:"rationale,omitempty"`
+	SessionIDs      []string   `json:"session_ids"` // Sessions the lessons came from
+	Status          string     `json:"status"`
+	CommitHash      string     `json:"commit_hash,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	ResolvedAt      *time.Time `json:"resolved_at,omitempty"`
+}
+
+// SessionEmbedding is the vector of one piece of a session's text, used to
+// find similar past sessions
+type SessionEmbedding struct {
+	ID        int64
+	SessionID string
+	Source    string
+	Content   string
+	Model     string
+	Vector    []float32
+	CreatedAt time.Time
+}
+
+// Annotation kinds
+const (
+	AnnotationKindNote     = "note"
+	AnnotationKindBookmark = "bookmark"
+)
+
+// EventAnnotation is an operator's note or bookmark on one conversation event
+type EventAnnotation struct {
+	ID        int64     `json:"id"`
+	SessionID string    `json:"session_id"`
+	EventID   int64     `json:"event_id"`
+	Kind      string    `json:"kind"`
+	Note      string    `json:"note,omitempty"`
+	Author    string    `json:"author,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AnnotationFilter selects annotations; zero fields match everything
+type AnnotationFilter struct {
+	SessionID string
+	EventID   int64
+	Kind      string
+	// Case-insensitive substring of the note
+	Text  string
+	Limit int
+}
+
+// Job statuses
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusFailed    = "failed"
+	// JobStatusInterrupted marks jobs that were unfinished when the daemon stopped
+	JobStatusInterrupted = "interrupted"
+)
+
+// ToolResult sources
+const (
+	ToolResultSourceReported   = "report_tool_result" // Reported by the agent through the MCP tool
+	ToolResultSourceDownstream = "mcp_downstream"     // Captured from a downstream MCP call proxied by the daemon
+)
+
 // MCPServer represents an MCP server configuration
 type MCPServer struct {
 	ID        int64
@@ -249,6 +1152,14 @@ type Approval struct {
 	ToolName    string          `json:"tool_name"`
 	ToolInput   json.RawMessage `json:"tool_input"`
 	Comment     string          `json:"comment,omitempty"`
+	// CommentExpansion is AI-written guidance expanding a terse denial
+	// comment, sent to the agent after the comment itself
+	CommentExpansion string `json:"comment_expansion,omitempty"`
+	// Constraints limit what the approval allows, set when it's approved
+	Constraints *ApprovalConstraints `json:"constraints,omitempty"`
+	// Attachments are the IDs of artifacts the request carries, such as a
+	// screenshot of the screen the tool call changes
+	Attachments []string `json:"attachments,omitempty"`
 }
 
 // EventType constants
@@ -278,6 +1189,7 @@ const (
 // SessionStatus constants
 const (
 	SessionStatusDraft        = "draft"
+	SessionStatusQueued       = "queued" // Waiting in the launch queue for a free slot
 	SessionStatusStarting     = "starting"
 	SessionStatusRunning      = "running"
 	SessionStatusCompleted    = "completed"
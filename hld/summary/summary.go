@@ -0,0 +1,300 @@
+// Package summary generates a structured summary of each session when it
+// reaches a terminal state and stores it on the session.
+package summary
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/i18n"
+	"github.com/humanlayer/humanlayer/hld/llm"
+	"github.com/humanlayer/humanlayer/hld/prompts"
+	"github.com/humanlayer/humanlayer/hld/scheduler"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// maxConversationLines keeps the prompt bounded for long sessions; the most
+// recent lines are kept since they best reflect the outcome
+const maxConversationLines = 200
+
+// maxMessageLength truncates individual messages in the prompt
+const maxMessageLength = 1000
+
+// generateTimeout bounds one summary, including model fallbacks
+const generateTimeout = 3 * time.Minute
+
+// Backfill looks hourly for sessions finished in the last day that have no
+// summary, at most 10 a run to bound model spend
+const (
+	backfillInterval = time.Hour
+	backfillWindow   = 24 * time.Hour
+	backfillLimit    = 10
+)
+
+// Summary describes what happened in a session
+type Summary struct {
+	Asked         string   `json:"asked"`
+	Changed       []string `json:"changed"`
+	OpenQuestions []string `json:"open_questions"`
+	FollowUps     []string `json:"follow_ups"`
+}
+
+var summarySchema = llm.Schema{
+	Name:        "session_summary",
+	Description: "Summarize a finished coding agent session",
+	Definition: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "asked": {"type": "string"},
+    "changed": {"type": "array", "items": {"type": "string"}},
+    "open_questions": {"type": "array", "items": {"type": "string"}},
+    "follow_ups": {"type": "array", "items": {"type": "string"}}
+  },
+  "required": ["asked", "changed", "open_questions", "follow_ups"]
+}`),
+}
+
+func (s *Summary) validate() error {
+	if strings.TrimSpace(s.Asked) == "" {
+		return errors.New("asked must not be empty")
+	}
+	return nil
+}
+
+// promptData are the variables of the session-summary template
+type promptData struct {
+	Query        string
+	Title        string
+	Status       string
+	WorkingDir   string
+	Error        string
+	Result       string
+	Conversation []string
+	Language     string
+}
+
+// terminalStatuses are the statuses that trigger a summary
+var terminalStatuses = map[string]bool{
+	store.SessionStatusCompleted:   true,
+	store.SessionStatusFailed:      true,
+	store.SessionStatusInterrupted: true,
+}
+
+// Generator writes completion summaries for sessions as they finish
+type Generator struct {
+	store     store.ConversationStore
+	router    *llm.Router
+	templates *prompts.Set
+	locale    string
+	eventBus  bus.EventBus
+
+	inFlight sync.Map // session ID -> struct{}
+}
+
+// NewGenerator creates a summary generator
+func NewGenerator(s store.ConversationStore, router *llm.Router, templates *prompts.Set, locale string, eventBus bus.EventBus) *Generator {
+	return &Generator{store: s, router: router, templates: templates, locale: locale, eventBus: eventBus}
+}
+
+// Run summarizes sessions as they reach a terminal state until ctx is cancelled
+func (g *Generator) Run(ctx context.Context) {
+	sub := g.eventBus.Subscribe(ctx, bus.EventFilter{
+		Types: []bus.EventType{bus.EventSessionStatusChanged},
+	})
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-sub.Channel:
+			if !ok {
+				return
+			}
+			status, _ := event.Data["new_status"].(string)
+			sessionID, _ := event.Data["session_id"].(string)
+			if !terminalStatuses[status] || sessionID == "" {
+				continue
+			}
+			if _, busy := g.inFlight.LoadOrStore(sessionID, struct{}{}); busy {
+				continue
+			}
+			go func() {
+				defer g.inFlight.Delete(sessionID)
+				if _, err := g.Generate(ctx, sessionID); err != nil {
+					slog.Warn("failed to generate session summary", "session_id", sessionID, "error", err)
+				}
+			}()
+		}
+	}
+}
+
+// Task is the maintenance task summarizing sessions that finished in the
+// last backfillWindow without a summary
+func (g *Generator) Task() scheduler.Task {
+	return scheduler.Task{
+		Name:        "summary-backfill",
+		Description: "Summarize sessions that finished in the last day without getting a summary",
+		Interval:    backfillInterval,
+		Run: func(ctx context.Context) error {
+			n, err := g.Backfill(ctx, time.Now().Add(-backfillWindow), backfillLimit)
+			if n > 0 {
+				slog.Info("backfilled session summaries", "count", n)
+			}
+			return err
+		},
+	}
+}
+
+// Backfill summarizes up to limit sessions that finished since a time
+// without getting a summary, such as while the daemon was down or when the
+// model failed, and returns how many it summarized
+func (g *Generator) Backfill(ctx context.Context, since time.Time, limit int) (int, error) {
+	sessions, err := g.store.ListSessions(ctx)
+	if err != nil {
+		return 0, err
+	}
+	summarized := 0
+	var errs []error
+	for _, session := range sessions {
+		if summarized+len(errs) >= limit {
+			break
+		}
+		if !terminalStatuses[session.Status] || session.CompletionSummary != "" ||
+			session.CompletedAt == nil || session.CompletedAt.Before(since) {
+			continue
+		}
+		if _, busy := g.inFlight.LoadOrStore(session.ID, struct{}{}); busy {
+			continue
+		}
+		_, err := g.Generate(ctx, session.ID)
+		g.inFlight.Delete(session.ID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("session %s: %w", session.ID, err))
+			continue
+		}
+		summarized++
+	}
+	return summarized, errors.Join(errs...)
+}
+
+// Generate summarizes a session, stores the summary and publishes
+// EventSessionSummaryReady. Sessions that already have a summary are skipped.
+func (g *Generator) Generate(ctx context.Context, sessionID string) (*Summary, error) {
+	ctx, cancel := context.WithTimeout(ctx, generateTimeout)
+	defer cancel()
+
+	session, err := g.store.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.CompletionSummary != "" {
+		return nil, nil
+	}
+	events, err := g.store.GetSessionConversation(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversation: %w", err)
+	}
+
+	prompt, err := g.templates.Render(prompts.SessionSummary, promptData{
+		Query:        session.Query,
+		Title:        session.Title,
+		Status:       session.Status,
+		WorkingDir:   session.WorkingDir,
+		Error:        session.ErrorMessage,
+		Result:       truncate(session.ResultContent),
+		Conversation: conversationLines(events),
+		Language:     i18n.LanguageName(g.locale),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var summary Summary
+	if _, err := g.router.CompleteJSON(ctx, llm.TaskSummarization, llm.Request{Prompt: prompt, Schema: &summarySchema}, &summary, summary.validate); err != nil {
+		return nil, err
+	}
+
+	encoded, err := json.Marshal(summary)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode summary: %w", err)
+	}
+	text := string(encoded)
+	if err := g.store.UpdateSession(ctx, sessionID, store.SessionUpdate{CompletionSummary: &text}); err != nil {
+		return nil, fmt.Errorf("failed to store summary: %w", err)
+	}
+
+	g.eventBus.Publish(bus.Event{
+		Type: bus.EventSessionSummaryReady,
+		Data: map[string]interface{}{
+			"session_id": sessionID,
+			"run_id":     session.RunID,
+			"status":     session.Status,
+			"summary":    &summary,
+		},
+	})
+	return &summary, nil
+}
+
+// conversationLines flattens messages and tool calls for the prompt. Tool
+// calls that touch files name the file, so the model can report changes.
+func conversationLines(events []*store.ConversationEvent) []string {
+	var lines []string
+	for _, event := range events {
+		switch event.EventType {
+		case store.EventTypeMessage:
+			if content := strings.TrimSpace(event.Content); content != "" {
+				role := "User"
+				if event.Role == "assistant" {
+					role = "Assistant"
+				}
+				lines = append(lines, fmt.Sprintf("%s: %s", role, truncate(content)))
+			}
+		case store.EventTypeToolCall:
+			line := "Tool Call: " + event.ToolName
+			var input struct {
+				FilePath string `json:"file_path"`
+				Command  string `json:"command"`
+			}
+			if json.Unmarshal([]byte(event.ToolInputJSON), &input) == nil {
+				if input.FilePath != "" {
+					line += " " + input.FilePath
+				} else if input.Command != "" {
+					line += " " + truncate(input.Command)
+				}
+			}
+			if event.ApprovalStatus == string(store.ApprovalStatusLocalDenied) {
+				line += " (denied)"
+			}
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > maxConversationLines {
+		lines = append([]string{"..."}, lines[len(lines)-maxConversationLines:]...)
+	}
+	return lines
+}
+
+func truncate(s string) string {
+	if len(s) > maxMessageLength {
+		return s[:maxMessageLength] + "..."
+	}
+	return s
+}
+
+// Decode parses a stored completion summary
+func Decode(text string) (*Summary, error) {
+	if text == "" {
+		return nil, nil
+	}
+	var s Summary
+	if err := json.Unmarshal([]byte(text), &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
@@ -0,0 +1,100 @@
+package summary
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/llm"
+	"github.com/humanlayer/humanlayer/hld/prompts"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	require.NoError(t, s.CreateSession(ctx, &store.Session{
+		ID: "sess-1", RunID: "run-1", ClaudeSessionID: "claude-1", Query: "Fix the parser",
+		Status: store.SessionStatusCompleted, CreatedAt: time.Now(),
+	}))
+	require.NoError(t, s.AddConversationEvent(ctx, &store.ConversationEvent{
+		SessionID: "sess-1", ClaudeSessionID: "claude-1", EventType: store.EventTypeToolCall,
+		ToolID: "tool-1", ToolName: "Edit", ToolInputJSON: `{"file_path":"/work/parse.go"}`,
+	}))
+
+	provider := &llm.FakeProvider{Response: `{"asked":"Fix the parser","changed":["Fixed parse.go"],"open_questions":[],"follow_ups":["Add a regression test"]}`}
+	router := llm.NewRouterWithProviders(map[string]llm.Provider{"p": provider}, map[llm.Task][]config.LLMRoute{
+		llm.TaskSummarization: {{Provider: "p", Model: "m"}},
+	})
+	eventBus := bus.NewEventBus()
+	sub := eventBus.Subscribe(ctx, bus.EventFilter{Types: []bus.EventType{bus.EventSessionSummaryReady}})
+	g := NewGenerator(s, router, prompts.Default(), "", eventBus)
+
+	summary, err := g.Generate(ctx, "sess-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Fixed parse.go"}, summary.Changed)
+	require.Len(t, provider.Prompts(), 1)
+	assert.Contains(t, provider.Prompts()[0], "Tool Call: Edit /work/parse.go")
+
+	session, err := s.GetSession(ctx, "sess-1")
+	require.NoError(t, err)
+	stored, err := Decode(session.CompletionSummary)
+	require.NoError(t, err)
+	assert.Equal(t, summary, stored)
+
+	select {
+	case event := <-sub.Channel:
+		assert.Equal(t, "sess-1", event.Data["session_id"])
+		assert.Equal(t, summary, event.Data["summary"])
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for summary event")
+	}
+
+	// A session is only summarized once
+	again, err := g.Generate(ctx, "sess-1")
+	require.NoError(t, err)
+	assert.Nil(t, again)
+	assert.Len(t, provider.Prompts(), 1)
+}
+
+func TestBackfill(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	hourAgo, weekAgo := time.Now().Add(-time.Hour), time.Now().Add(-7*24*time.Hour)
+	for _, session := range []*store.Session{
+		{ID: "missed", Status: store.SessionStatusCompleted, CompletedAt: &hourAgo},
+		{ID: "failed", Status: store.SessionStatusFailed, CompletedAt: &hourAgo},
+		{ID: "summarized", Status: store.SessionStatusCompleted, CompletedAt: &hourAgo, CompletionSummary: `{"asked":"x"}`},
+		{ID: "running", Status: store.SessionStatusRunning},
+		{ID: "old", Status: store.SessionStatusCompleted, CompletedAt: &weekAgo},
+	} {
+		session.RunID, session.Query, session.CreatedAt = "run-"+session.ID, "Fix the parser", time.Now()
+		require.NoError(t, s.CreateSession(ctx, session))
+	}
+
+	provider := &llm.FakeProvider{Response: `{"asked":"Fix the parser","changed":[],"open_questions":[],"follow_ups":[]}`}
+	router := llm.NewRouterWithProviders(map[string]llm.Provider{"p": provider}, map[llm.Task][]config.LLMRoute{
+		llm.TaskSummarization: {{Provider: "p", Model: "m"}},
+	})
+	g := NewGenerator(s, router, prompts.Default(), "", bus.NewEventBus())
+
+	n, err := g.Backfill(ctx, time.Now().Add(-24*time.Hour), 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n, "limited")
+	n, err = g.Backfill(ctx, time.Now().Add(-24*time.Hour), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Len(t, provider.Prompts(), 2)
+	for _, id := range []string{"missed", "failed"} {
+		session, err := s.GetSession(ctx, id)
+		require.NoError(t, err)
+		assert.NotEmpty(t, session.CompletionSummary, id)
+	}
+	old, err := s.GetSession(ctx, "old")
+	require.NoError(t, err)
+	assert.Empty(t, old.CompletionSummary)
+}
@@ -0,0 +1,159 @@
+package tracker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// DefaultAsanaURL is Asana's REST API base URL
+const DefaultAsanaURL = "https://app.asana.com/api/1.0"
+
+// Asana task links in both URL formats, and asana:<gid>
+var asanaRefs = []*regexp.Regexp{
+	regexp.MustCompile(`app\.asana\.com/0/[0-9]+/([0-9]+)`),
+	regexp.MustCompile(`app\.asana\.com/1/[0-9]+/(?:project/[0-9]+/)?task/([0-9]+)`),
+	regexp.MustCompile(`\basana:([0-9]+)\b`),
+}
+
+// Asana looks up tasks by gid. A task's status is the section it's in.
+type Asana struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// NewAsana creates an Asana tracker
+func NewAsana(baseURL, token string) *Asana {
+	if baseURL == "" {
+		baseURL = DefaultAsanaURL
+	}
+	return &Asana{baseURL: strings.TrimRight(baseURL, "/"), token: token, http: &http.Client{Timeout: requestTimeout}}
+}
+
+// Refs returns the gids of the tasks linked in text
+func (a *Asana) Refs(text string) []string {
+	var refs []string
+	seen := make(map[string]bool)
+	for _, re := range asanaRefs {
+		for _, match := range re.FindAllStringSubmatch(text, -1) {
+			if !seen[match[1]] {
+				seen[match[1]] = true
+				refs = append(refs, match[1])
+			}
+		}
+	}
+	return refs
+}
+
+type asanaTask struct {
+	GID          string `json:"gid"`
+	Name         string `json:"name"`
+	Notes        string `json:"notes"`
+	PermalinkURL string `json:"permalink_url"`
+	Memberships  []struct {
+		Project struct {
+			GID string `json:"gid"`
+		} `json:"project"`
+		Section struct {
+			Name string `json:"name"`
+		} `json:"section"`
+	} `json:"memberships"`
+}
+
+func (a *Asana) task(ctx context.Context, gid string) (*asanaTask, error) {
+	var task asanaTask
+	path := "/tasks/" + url.PathEscape(gid) + "?opt_fields=name,notes,permalink_url,memberships.project.gid,memberships.section.name"
+	if err := a.do(ctx, http.MethodGet, path, nil, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// Get fetches a task
+func (a *Asana) Get(ctx context.Context, ref string) (*Ticket, error) {
+	task, err := a.task(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	ticket := &Ticket{Ref: task.GID, Title: task.Name, Description: task.Notes, URL: task.PermalinkURL}
+	if len(task.Memberships) > 0 {
+		ticket.Status = task.Memberships[0].Section.Name
+	}
+	return ticket, nil
+}
+
+// SetStatus moves a task to the section with the given name in the first of
+// its projects that has one
+func (a *Asana) SetStatus(ctx context.Context, ref, status string) error {
+	task, err := a.task(ctx, ref)
+	if err != nil {
+		return err
+	}
+	for _, membership := range task.Memberships {
+		var sections []struct {
+			GID  string `json:"gid"`
+			Name string `json:"name"`
+		}
+		if err := a.do(ctx, http.MethodGet, "/projects/"+url.PathEscape(membership.Project.GID)+"/sections?opt_fields=name", nil, &sections); err != nil {
+			return err
+		}
+		for _, section := range sections {
+			if strings.EqualFold(section.Name, status) {
+				return a.do(ctx, http.MethodPost, "/sections/"+url.PathEscape(section.GID)+"/addTask",
+					map[string]string{"task": task.GID}, nil)
+			}
+		}
+	}
+	return fmt.Errorf("no project of asana task %s has a section %q", ref, status)
+}
+
+// do sends a request wrapped in Asana's {"data": ...} envelope and decodes
+// the response's data into out
+func (a *Asana) do(ctx context.Context, method, path string, body, out any) error {
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(map[string]any{"data": body})
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, a.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := a.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("asana %s %s: %s", method, path, resp.Status)
+	}
+	if out == nil {
+		return nil
+	}
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return err
+	}
+	return json.Unmarshal(envelope.Data, out)
+}
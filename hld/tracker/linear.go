@@ -0,0 +1,189 @@
+package tracker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// DefaultLinearURL is Linear's GraphQL endpoint
+const DefaultLinearURL = "https://api.linear.app/graphql"
+
+var linearIdentifier = regexp.MustCompile(`\b([A-Z][A-Z0-9]{0,9})-([0-9]+)\b`)
+
+// Linear looks up issues by identifier (e.g. ENG-123)
+type Linear struct {
+	url      string
+	apiKey   string
+	teamKeys map[string]bool // empty recognizes any team key
+	http     *http.Client
+}
+
+// NewLinear creates a Linear tracker recognizing identifiers with the given
+// team keys
+func NewLinear(url, apiKey string, teamKeys []string) *Linear {
+	if url == "" {
+		url = DefaultLinearURL
+	}
+	keys := make(map[string]bool)
+	for _, key := range teamKeys {
+		keys[strings.ToUpper(key)] = true
+	}
+	return &Linear{url: url, apiKey: apiKey, teamKeys: keys, http: &http.Client{Timeout: requestTimeout}}
+}
+
+// Refs returns the issue identifiers in text
+func (l *Linear) Refs(text string) []string {
+	var refs []string
+	seen := make(map[string]bool)
+	for _, match := range linearIdentifier.FindAllStringSubmatch(text, -1) {
+		if len(l.teamKeys) > 0 && !l.teamKeys[match[1]] {
+			continue
+		}
+		if !seen[match[0]] {
+			seen[match[0]] = true
+			refs = append(refs, match[0])
+		}
+	}
+	return refs
+}
+
+type linearIssue struct {
+	ID          string `json:"id"`
+	Identifier  string `json:"identifier"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+	State       struct {
+		Name string `json:"name"`
+	} `json:"state"`
+	Team struct {
+		ID string `json:"id"`
+	} `json:"team"`
+}
+
+func (l *Linear) issue(ctx context.Context, ref string) (*linearIssue, error) {
+	var data struct {
+		Issue *linearIssue `json:"issue"`
+	}
+	err := l.query(ctx, `query($id: String!) {
+  issue(id: $id) { id identifier title description url state { name } team { id } }
+}`, map[string]any{"id": ref}, &data)
+	if err != nil {
+		return nil, err
+	}
+	if data.Issue == nil {
+		return nil, ErrNotFound
+	}
+	return data.Issue, nil
+}
+
+// Get fetches an issue
+func (l *Linear) Get(ctx context.Context, ref string) (*Ticket, error) {
+	issue, err := l.issue(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	return &Ticket{
+		Ref:         issue.Identifier,
+		Title:       issue.Title,
+		Description: issue.Description,
+		URL:         issue.URL,
+		Status:      issue.State.Name,
+	}, nil
+}
+
+// SetStatus moves an issue to the workflow state of its team with the given
+// name
+func (l *Linear) SetStatus(ctx context.Context, ref, status string) error {
+	issue, err := l.issue(ctx, ref)
+	if err != nil {
+		return err
+	}
+	var states struct {
+		Team struct {
+			States struct {
+				Nodes []struct {
+					ID   string `json:"id"`
+					Name string `json:"name"`
+				} `json:"nodes"`
+			} `json:"states"`
+		} `json:"team"`
+	}
+	if err := l.query(ctx, `query($team: String!) {
+  team(id: $team) { states { nodes { id name } } }
+}`, map[string]any{"team": issue.Team.ID}, &states); err != nil {
+		return err
+	}
+	stateID := ""
+	for _, state := range states.Team.States.Nodes {
+		if strings.EqualFold(state.Name, status) {
+			stateID = state.ID
+			break
+		}
+	}
+	if stateID == "" {
+		return fmt.Errorf("linear team has no workflow state %q", status)
+	}
+	var result struct {
+		IssueUpdate struct {
+			Success bool `json:"success"`
+		} `json:"issueUpdate"`
+	}
+	if err := l.query(ctx, `mutation($id: String!, $state: String!) {
+  issueUpdate(id: $id, input: { stateId: $state }) { success }
+}`, map[string]any{"id": issue.ID, "state": stateID}, &result); err != nil {
+		return err
+	}
+	if !result.IssueUpdate.Success {
+		return fmt.Errorf("linear did not update %s", ref)
+	}
+	return nil
+}
+
+// query runs a GraphQL request, decoding its data into out
+func (l *Linear) query(ctx context.Context, query string, variables map[string]any, out any) error {
+	body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, l.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", l.apiKey)
+	resp, err := l.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var envelope struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message    string `json:"message"`
+			Extensions struct {
+				Code string `json:"code"`
+			} `json:"extensions"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("linear: %s: %w", resp.Status, err)
+	}
+	if len(envelope.Errors) > 0 {
+		if strings.Contains(strings.ToLower(envelope.Errors[0].Message), "not found") ||
+			envelope.Errors[0].Extensions.Code == "ENTITY_NOT_FOUND" {
+			return ErrNotFound
+		}
+		return fmt.Errorf("linear: %s", envelope.Errors[0].Message)
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("linear: %s", resp.Status)
+	}
+	return json.Unmarshal(envelope.Data, out)
+}
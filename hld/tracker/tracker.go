@@ -0,0 +1,305 @@
+// Package tracker connects sessions to issue tracker tickets. Tickets
+// referenced in a session's query are looked up and their descriptions added
+// to its context; when the session completes after opening a pull request,
+// the tickets are moved to the tracker's review status.
+package tracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+const (
+	requestTimeout = 30 * time.Second
+	// maxDescription keeps a long ticket from crowding out the rest of the prompt
+	maxDescription = 4000
+	// maxParents bounds the walk up a chain of continued sessions
+	maxParents = 20
+)
+
+// ErrNotFound is returned for references the tracker doesn't know
+var ErrNotFound = errors.New("ticket not found")
+
+// Ticket is a tracker's view of an issue or task
+type Ticket struct {
+	Ref         string
+	Title       string
+	Description string
+	URL         string
+	Status      string
+}
+
+// Tracker is implemented by each issue tracker integration
+type Tracker interface {
+	// Refs returns the references to this tracker's tickets found in text
+	Refs(text string) []string
+	Get(ctx context.Context, ref string) (*Ticket, error)
+	// SetStatus moves a ticket to the named status
+	SetStatus(ctx context.Context, ref, status string) error
+}
+
+// Linked is a ticket resolved from a session query
+type Linked struct {
+	Tracker string // Name of the configured tracker
+	Ticket
+}
+
+// Set holds the configured trackers by name
+type Set struct {
+	trackers     map[string]Tracker
+	reviewStatus map[string]string
+}
+
+// New creates trackers from the configuration. Trackers whose API key
+// environment variable is unset are skipped; nil is returned if none remain.
+func New(cfg map[string]config.TrackerConfig) *Set {
+	trackers := make(map[string]Tracker)
+	reviewStatus := make(map[string]string)
+	for name, tc := range cfg {
+		key := os.Getenv(tc.APIKeyEnv)
+		if key == "" {
+			slog.Warn("tracker API key not set, skipping", "tracker", name, "env", tc.APIKeyEnv)
+			continue
+		}
+		switch tc.Type {
+		case config.TrackerLinear:
+			trackers[name] = NewLinear(tc.BaseURL, key, tc.TeamKeys)
+		case config.TrackerAsana:
+			trackers[name] = NewAsana(tc.BaseURL, key)
+		default:
+			continue
+		}
+		reviewStatus[name] = tc.ReviewStatus
+	}
+	if len(trackers) == 0 {
+		return nil
+	}
+	return NewSet(trackers, reviewStatus)
+}
+
+// NewSet creates a set from trackers and the status each moves tickets to
+// on review
+func NewSet(trackers map[string]Tracker, reviewStatus map[string]string) *Set {
+	return &Set{trackers: trackers, reviewStatus: reviewStatus}
+}
+
+func (s *Set) names() []string {
+	names := make([]string, 0, len(s.trackers))
+	for name := range s.trackers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Resolve looks up the tickets referenced in text. References a tracker
+// can't find are skipped, since identifiers like ABC-1 may belong to another
+// tracker or not be tickets at all.
+func (s *Set) Resolve(ctx context.Context, text string) []Linked {
+	var linked []Linked
+	for _, name := range s.names() {
+		tracker := s.trackers[name]
+		for _, ref := range tracker.Refs(text) {
+			ticket, err := tracker.Get(ctx, ref)
+			if err != nil {
+				if !errors.Is(err, ErrNotFound) {
+					slog.Warn("failed to fetch ticket", "tracker", name, "ref", ref, "error", err)
+				}
+				continue
+			}
+			linked = append(linked, Linked{Tracker: name, Ticket: *ticket})
+		}
+	}
+	return linked
+}
+
+// Render formats tickets for a system prompt
+func Render(linked []Linked) string {
+	if len(linked) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("# Referenced tickets\n\nThe task refers to these tickets.")
+	for _, l := range linked {
+		fmt.Fprintf(&b, "\n\n## %s: %s\n", l.Ref, l.Title)
+		if l.Status != "" {
+			fmt.Fprintf(&b, "Status: %s\n", l.Status)
+		}
+		if l.URL != "" {
+			fmt.Fprintf(&b, "URL: %s\n", l.URL)
+		}
+		description := strings.TrimSpace(l.Description)
+		if len(description) > maxDescription {
+			description = description[:maxDescription] + "\n[truncated]"
+		}
+		if description != "" {
+			b.WriteString("\n" + description + "\n")
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// Link records the tickets against a session so their status can be synced
+// when it completes
+func Link(ctx context.Context, s store.TicketStore, sessionID string, linked []Linked) error {
+	for _, l := range linked {
+		if err := s.CreateSessionTicket(ctx, &store.SessionTicket{
+			SessionID: sessionID,
+			Tracker:   l.Tracker,
+			Ref:       l.Ref,
+			Title:     l.Title,
+			URL:       l.URL,
+			Status:    l.Status,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var pullRequestURL = regexp.MustCompile(`https?://[^\s/]+/[\w.-]+/[\w.-]+/pull/\d+`)
+
+// PullRequestURL returns the last pull request URL that appears in a
+// session's tool results, messages or result, or "" if it opened none
+func PullRequestURL(ctx context.Context, s store.ConversationStore, sess *store.Session) (string, error) {
+	events, err := s.GetSessionConversation(ctx, sess.ID)
+	if err != nil {
+		return "", err
+	}
+	var found string
+	for _, event := range events {
+		text := event.ToolResultContent
+		if event.EventType == store.EventTypeMessage && event.Role == "assistant" {
+			text = event.Content
+		}
+		if urls := pullRequestURL.FindAllString(text, -1); len(urls) > 0 {
+			found = urls[len(urls)-1]
+		}
+	}
+	if urls := pullRequestURL.FindAllString(sess.ResultContent, -1); len(urls) > 0 {
+		found = urls[len(urls)-1]
+	}
+	return found, nil
+}
+
+// Syncer moves linked tickets to their review status when a session
+// completes with a pull request
+type Syncer struct {
+	store    store.ConversationStore
+	tickets  store.TicketStore
+	trackers *Set
+	eventBus bus.EventBus
+}
+
+// NewSyncer creates a syncer
+func NewSyncer(s store.ConversationStore, tickets store.TicketStore, trackers *Set, eventBus bus.EventBus) *Syncer {
+	return &Syncer{store: s, tickets: tickets, trackers: trackers, eventBus: eventBus}
+}
+
+// Run syncs tickets as sessions complete, until ctx is done
+func (s *Syncer) Run(ctx context.Context) {
+	sub := s.eventBus.Subscribe(ctx, bus.EventFilter{
+		Types: []bus.EventType{bus.EventSessionStatusChanged},
+	})
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-sub.Channel:
+			if !ok {
+				return
+			}
+			status, _ := event.Data["new_status"].(string)
+			sessionID, _ := event.Data["session_id"].(string)
+			if status != store.SessionStatusCompleted {
+				continue
+			}
+			go func() {
+				if err := s.Sync(ctx, sessionID); err != nil {
+					slog.Warn("failed to sync tickets", "session_id", sessionID, "error", err)
+				}
+			}()
+		}
+	}
+}
+
+// Sync moves the tickets linked to a completed session, or to the sessions
+// it continues, to their review status if the session opened a pull request.
+// Tickets already moved are left alone.
+func (s *Syncer) Sync(ctx context.Context, sessionID string) error {
+	sess, err := s.store.GetSession(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if sess.Status != store.SessionStatusCompleted {
+		return nil
+	}
+	tickets, err := s.linkedTickets(ctx, sess)
+	if err != nil || len(tickets) == 0 {
+		return err
+	}
+	prURL, err := PullRequestURL(ctx, s.store, sess)
+	if err != nil || prURL == "" {
+		return err
+	}
+
+	var errs []error
+	for _, ticket := range tickets {
+		tracker, ok := s.trackers.trackers[ticket.Tracker]
+		status := s.trackers.reviewStatus[ticket.Tracker]
+		if !ok || status == "" || ticket.SyncedAt != nil {
+			continue
+		}
+		if err := tracker.SetStatus(ctx, ticket.Ref, status); err != nil {
+			errs = append(errs, fmt.Errorf("%s %s: %w", ticket.Tracker, ticket.Ref, err))
+			continue
+		}
+		if err := s.tickets.MarkSessionTicketSynced(ctx, ticket.SessionID, ticket.Tracker, ticket.Ref, status); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		slog.Info("moved ticket for pull request", "tracker", ticket.Tracker, "ref", ticket.Ref,
+			"status", status, "pull_request", prURL, "session_id", sessionID)
+	}
+	return errors.Join(errs...)
+}
+
+// linkedTickets returns the tickets linked to a session and its parents
+func (s *Syncer) linkedTickets(ctx context.Context, sess *store.Session) ([]*store.SessionTicket, error) {
+	var all []*store.SessionTicket
+	seen := make(map[string]bool)
+	for i := 0; sess != nil && i < maxParents; i++ {
+		tickets, err := s.tickets.ListSessionTickets(ctx, sess.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, ticket := range tickets {
+			key := ticket.Tracker + "/" + ticket.Ref
+			if !seen[key] {
+				seen[key] = true
+				all = append(all, ticket)
+			}
+		}
+		if sess.ParentSessionID == "" {
+			break
+		}
+		if sess, err = s.store.GetSession(ctx, sess.ParentSessionID); err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				break
+			}
+			return nil, err
+		}
+	}
+	return all, nil
+}
@@ -0,0 +1,189 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTracker serves fixed tickets and records status changes
+type fakeTracker struct {
+	tickets map[string]*Ticket
+	moved   map[string]string
+}
+
+func (f *fakeTracker) Refs(text string) []string {
+	var refs []string
+	for ref := range f.tickets {
+		if strings.Contains(text, ref) {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+func (f *fakeTracker) Get(ctx context.Context, ref string) (*Ticket, error) {
+	ticket, ok := f.tickets[ref]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return ticket, nil
+}
+
+func (f *fakeTracker) SetStatus(ctx context.Context, ref, status string) error {
+	f.moved[ref] = status
+	return nil
+}
+
+func TestLinearRefs(t *testing.T) {
+	l := NewLinear("", "key", []string{"eng"})
+	assert.Equal(t, []string{"ENG-12", "ENG-7"}, l.Refs("Fix ENG-12 and ENG-7 (see ENG-12, OPS-3, UTF-8)"))
+	assert.Equal(t, []string{"OPS-3", "UTF-8"}, NewLinear("", "key", nil).Refs("OPS-3 and UTF-8"))
+}
+
+func TestLinear(t *testing.T) {
+	var updated map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "lin_key", r.Header.Get("Authorization"))
+		var req struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		switch {
+		case strings.Contains(req.Query, "issueUpdate"):
+			updated = req.Variables
+			_, _ = w.Write([]byte(`{"data":{"issueUpdate":{"success":true}}}`))
+		case strings.Contains(req.Query, "team(id"):
+			_, _ = w.Write([]byte(`{"data":{"team":{"states":{"nodes":[{"id":"st-1","name":"Todo"},{"id":"st-2","name":"In Review"}]}}}}`))
+		case req.Variables["id"] == "ENG-1":
+			_, _ = w.Write([]byte(`{"data":{"issue":{"id":"uuid-1","identifier":"ENG-1","title":"Fix login","description":"Users can't log in","url":"https://linear.app/x/issue/ENG-1","state":{"name":"Todo"},"team":{"id":"team-1"}}}}`))
+		default:
+			_, _ = w.Write([]byte(`{"errors":[{"message":"Entity not found: Issue","extensions":{"code":"ENTITY_NOT_FOUND"}}],"data":null}`))
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	l := NewLinear(server.URL, "lin_key", nil)
+	ticket, err := l.Get(ctx, "ENG-1")
+	require.NoError(t, err)
+	assert.Equal(t, &Ticket{Ref: "ENG-1", Title: "Fix login", Description: "Users can't log in",
+		URL: "https://linear.app/x/issue/ENG-1", Status: "Todo"}, ticket)
+
+	_, err = l.Get(ctx, "ENG-2")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, l.SetStatus(ctx, "ENG-1", "in review"))
+	assert.Equal(t, map[string]any{"id": "uuid-1", "state": "st-2"}, updated)
+	assert.Error(t, l.SetStatus(ctx, "ENG-1", "Shipped"))
+}
+
+func TestAsana(t *testing.T) {
+	var added string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer asana_token", r.Header.Get("Authorization"))
+		switch {
+		case r.URL.Path == "/tasks/111":
+			_, _ = w.Write([]byte(`{"data":{"gid":"111","name":"Update docs","notes":"Docs are stale","permalink_url":"https://app.asana.com/0/9/111",
+				"memberships":[{"project":{"gid":"9"},"section":{"name":"Doing"}}]}}`))
+		case r.URL.Path == "/projects/9/sections":
+			_, _ = w.Write([]byte(`{"data":[{"gid":"s1","name":"Doing"},{"gid":"s2","name":"Review"}]}`))
+		case r.URL.Path == "/sections/s2/addTask" && r.Method == http.MethodPost:
+			var body struct {
+				Data struct {
+					Task string `json:"task"`
+				} `json:"data"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			added = body.Data.Task
+			_, _ = w.Write([]byte(`{"data":{}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	a := NewAsana(server.URL, "asana_token")
+	assert.Equal(t, []string{"111", "222", "333"}, a.Refs(
+		"See https://app.asana.com/0/9/111/f and https://app.asana.com/1/5/project/9/task/222 and asana:333, asana:111"))
+
+	ticket, err := a.Get(ctx, "111")
+	require.NoError(t, err)
+	assert.Equal(t, "Update docs", ticket.Title)
+	assert.Equal(t, "Doing", ticket.Status)
+
+	_, err = a.Get(ctx, "404")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, a.SetStatus(ctx, "111", "review"))
+	assert.Equal(t, "111", added)
+	assert.Error(t, a.SetStatus(ctx, "111", "Done"))
+}
+
+func TestResolveAndRender(t *testing.T) {
+	fake := &fakeTracker{tickets: map[string]*Ticket{
+		"ENG-1": {Ref: "ENG-1", Title: "Fix login", Description: "Steps to reproduce", Status: "Todo", URL: "https://linear.app/ENG-1"},
+	}}
+	set := NewSet(map[string]Tracker{"linear": fake}, nil)
+
+	linked := set.Resolve(context.Background(), "Please fix ENG-1")
+	require.Len(t, linked, 1)
+	assert.Equal(t, "linear", linked[0].Tracker)
+
+	text := Render(linked)
+	assert.Contains(t, text, "## ENG-1: Fix login")
+	assert.Contains(t, text, "Status: Todo")
+	assert.Contains(t, text, "Steps to reproduce")
+	assert.Empty(t, Render(nil))
+}
+
+func TestSync(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	fake := &fakeTracker{
+		tickets: map[string]*Ticket{"ENG-1": {Ref: "ENG-1", Title: "Fix login"}},
+		moved:   make(map[string]string),
+	}
+	syncer := NewSyncer(s, s, NewSet(map[string]Tracker{"linear": fake}, map[string]string{"linear": "In Review"}), nil)
+
+	now := time.Now()
+	require.NoError(t, s.CreateSession(ctx, &store.Session{
+		ID: "parent", RunID: "r1", Query: "Fix ENG-1", Status: store.SessionStatusCompleted, CreatedAt: now, CompletedAt: &now,
+	}))
+	require.NoError(t, Link(ctx, s, "parent", []Linked{{Tracker: "linear", Ticket: *fake.tickets["ENG-1"]}}))
+
+	// No pull request yet
+	require.NoError(t, syncer.Sync(ctx, "parent"))
+	assert.Empty(t, fake.moved)
+
+	// A continuation opens one
+	require.NoError(t, s.CreateSession(ctx, &store.Session{
+		ID: "child", RunID: "r2", ParentSessionID: "parent", ClaudeSessionID: "c2", Query: "Open a PR",
+		Status: store.SessionStatusCompleted, CreatedAt: now, CompletedAt: &now,
+	}))
+	require.NoError(t, s.AddConversationEvent(ctx, &store.ConversationEvent{
+		SessionID: "child", ClaudeSessionID: "c2", EventType: store.EventTypeToolResult,
+		ToolResultContent: "https://github.com/acme/app/pull/42\n",
+	}))
+	require.NoError(t, syncer.Sync(ctx, "child"))
+	assert.Equal(t, map[string]string{"ENG-1": "In Review"}, fake.moved)
+
+	tickets, err := s.ListSessionTickets(ctx, "parent")
+	require.NoError(t, err)
+	assert.Equal(t, "In Review", tickets[0].SyncedStatus)
+
+	// Moved once
+	fake.moved = make(map[string]string)
+	require.NoError(t, syncer.Sync(ctx, "child"))
+	assert.Empty(t, fake.moved)
+}
@@ -0,0 +1,218 @@
+package transcript
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// Markdown renders the transcript as GitHub-flavored Markdown. Tool inputs
+// and results sit in <details> blocks so they render collapsed.
+func (t *Transcript) Markdown() string {
+	var b strings.Builder
+	_ = t.WriteMarkdown(&b)
+	return b.String()
+}
+
+// WriteMarkdown renders the transcript as Markdown to w, without holding
+// the whole document in memory
+func (t *Transcript) WriteMarkdown(w io.Writer) error {
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, "# %s\n\n", t.Title())
+	for _, field := range t.metadata() {
+		fmt.Fprintf(b, "- **%s:** %s\n", field[0], field[1])
+	}
+	b.WriteString("\n---\n")
+
+	for _, entry := range t.Entries {
+		b.WriteString("\n")
+		switch entry.Kind {
+		case store.EventTypeMessage:
+			fmt.Fprintf(b, "**%s** · %s\n\n%s\n", roleLabel(entry.Role), entry.Time.Format(time.Kitchen), entry.Content)
+		case store.EventTypeSystem:
+			fmt.Fprintf(b, "> _%s_\n", strings.ReplaceAll(entry.Content, "\n", "\n> "))
+		case store.EventTypeToolCall:
+			fmt.Fprintf(b, "**Tool: %s**%s\n\n", entry.ToolName, approvalSuffix(entry))
+			writeDetails(b, "Input", "json", entry.ToolInput)
+			if entry.ToolResult != "" {
+				writeDetails(b, resultSummary(entry), "", entry.ToolResult)
+			}
+		}
+		for _, annotation := range entry.Annotations {
+			line := "**" + annotationLabel(annotation) + "**"
+			if annotation.Note != "" {
+				line += ": " + annotation.Note
+			}
+			fmt.Fprintf(b, "\n> %s\n", strings.ReplaceAll(line, "\n", "\n> "))
+		}
+	}
+
+	if t.Diff != "" {
+		b.WriteString("\n## Final diff\n\n")
+		fence := codeFence(t.Diff)
+		fmt.Fprintf(b, "%sdiff\n%s\n%s\n", fence, strings.TrimRight(t.Diff, "\n"), fence)
+	}
+	return b.Flush()
+}
+
+// HTML renders the transcript as a standalone page
+func (t *Transcript) HTML() (string, error) {
+	var b bytes.Buffer
+	if err := t.WriteHTML(&b); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// WriteHTML renders the transcript as a standalone page to w
+func (t *Transcript) WriteHTML(w io.Writer) error {
+	b := bufio.NewWriter(w)
+	err := htmlTemplate.Execute(b, map[string]interface{}{
+		"Title":    t.Title(),
+		"Metadata": t.metadata(),
+		"Entries":  t.Entries,
+		"Diff":     t.Diff,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to render transcript: %w", err)
+	}
+	return b.Flush()
+}
+
+// metadata lists the session fields shown under the title
+func (t *Transcript) metadata() [][2]string {
+	s := t.Session
+	fields := [][2]string{{"Session", s.ID}, {"Status", s.Status}}
+	if s.Model != "" {
+		fields = append(fields, [2]string{"Model", s.Model})
+	}
+	if s.WorkingDir != "" {
+		fields = append(fields, [2]string{"Working directory", s.WorkingDir})
+	}
+	fields = append(fields, [2]string{"Started", s.CreatedAt.Format(time.RFC1123)})
+	if s.CostUSD != nil {
+		fields = append(fields, [2]string{"Cost", fmt.Sprintf("$%.2f", *s.CostUSD)})
+	}
+	return fields
+}
+
+func roleLabel(role string) string {
+	switch role {
+	case "user":
+		return "User"
+	case "assistant":
+		return "Assistant"
+	default:
+		return role
+	}
+}
+
+// approvalSuffix describes how an approval for the call was resolved
+func approvalSuffix(entry Entry) string {
+	if entry.ApprovalStatus == "" {
+		return ""
+	}
+	suffix := " — " + entry.ApprovalStatus
+	if entry.ApprovalComment != "" {
+		suffix += ": " + entry.ApprovalComment
+	}
+	return suffix
+}
+
+// annotationLabel names an annotation's kind and author
+func annotationLabel(a *store.EventAnnotation) string {
+	label := "Note"
+	if a.Kind == store.AnnotationKindBookmark {
+		label = "Bookmark"
+	}
+	if a.Author != "" {
+		label += " (" + a.Author + ")"
+	}
+	return label
+}
+
+func resultSummary(entry Entry) string {
+	if entry.ResultTruncated {
+		return "Result (truncated)"
+	}
+	return "Result"
+}
+
+func writeDetails(b io.Writer, summary, lang, content string) {
+	fence := codeFence(content)
+	fmt.Fprintf(b, "<details><summary>%s</summary>\n\n%s%s\n%s\n%s\n\n</details>\n\n",
+		summary, fence, lang, strings.TrimRight(content, "\n"), fence)
+}
+
+// codeFence returns a backtick fence longer than any run inside content
+func codeFence(content string) string {
+	longest, run := 0, 0
+	for _, ch := range content {
+		if ch == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
+
+var htmlTemplate = template.Must(template.New("transcript").Funcs(template.FuncMap{
+	"role":     roleLabel,
+	"approval": approvalSuffix,
+	"result":   resultSummary,
+	"label":    annotationLabel,
+	"time":     func(t time.Time) string { return t.Format(time.Kitchen) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 56rem; margin: 2rem auto; padding: 0 1rem; line-height: 1.5; color: #1f2328; }
+dl { display: grid; grid-template-columns: max-content auto; gap: 0.25rem 1rem; }
+dt { font-weight: 600; }
+.entry { border-top: 1px solid #d0d7de; padding: 0.75rem 0; }
+.meta { font-weight: 600; }
+.meta time { font-weight: 400; color: #656d76; margin-left: 0.5rem; }
+.content, pre { white-space: pre-wrap; word-break: break-word; }
+.system { color: #656d76; font-style: italic; }
+pre { background: #f6f8fa; padding: 0.75rem; border-radius: 6px; overflow-x: auto; }
+summary { cursor: pointer; color: #0969da; }
+.annotation { margin-top: 0.5rem; padding: 0.5rem 0.75rem; border-left: 4px solid #bf8700; background: #fff8c5; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<dl>{{range .Metadata}}<dt>{{index . 0}}</dt><dd>{{index . 1}}</dd>{{end}}</dl>
+{{range .Entries}}<div class="entry">
+{{- if eq .Kind "message"}}
+<div class="meta">{{role .Role}}<time>{{time .Time}}</time></div>
+<div class="content">{{.Content}}</div>
+{{- else if eq .Kind "system"}}
+<div class="system content">{{.Content}}</div>
+{{- else if eq .Kind "tool_call"}}
+<div class="meta">Tool: {{.ToolName}}{{approval .}}</div>
+<details><summary>Input</summary><pre>{{.ToolInput}}</pre></details>
+{{- if .ToolResult}}
+<details><summary>{{result .}}</summary><pre>{{.ToolResult}}</pre></details>
+{{- end}}
+{{- end}}
+{{- range .Annotations}}
+<div class="annotation content"><strong>{{label .}}</strong>{{if .Note}}: {{.Note}}{{end}}</div>
+{{- end}}
+</div>
+{{end}}
+{{- if .Diff}}<h2>Final diff</h2>
+<pre>{{.Diff}}</pre>
+{{end -}}
+</body>
+</html>
+`))
@@ -0,0 +1,165 @@
+// Package transcript renders a session's conversation as a shareable
+// Markdown or HTML document.
+package transcript
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// Formats a transcript can be rendered in
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+)
+
+// maxResultLength caps each tool result so one large file read doesn't
+// swamp the document
+const maxResultLength = 4000
+
+// redactedInput replaces tool inputs when Options.RedactToolInputs is set
+const redactedInput = "(redacted)"
+
+// Options controls what a transcript includes
+type Options struct {
+	// Replace tool call inputs, which often hold file contents or secrets
+	RedactToolInputs bool
+	// Uncommitted changes in the working directory, shown at the end
+	Diff string
+}
+
+// Transcript is a session's conversation prepared for rendering
+type Transcript struct {
+	Session *store.Session
+	Entries []Entry
+	Diff    string
+}
+
+// Entry is one message, tool call or system note in a transcript
+type Entry struct {
+	Kind    string // store.EventTypeMessage, EventTypeToolCall or EventTypeSystem
+	Time    time.Time
+	Role    string
+	Content string
+
+	ToolName        string
+	ToolInput       string
+	ToolResult      string
+	ResultTruncated bool
+	ApprovalStatus  string
+	ApprovalComment string
+
+	// Operator notes and bookmarks on the event, including its tool result
+	Annotations []*store.EventAnnotation
+}
+
+// Title is the document heading: the session title, its summary, or its ID
+func (t *Transcript) Title() string {
+	switch {
+	case t.Session.Title != "":
+		return t.Session.Title
+	case t.Session.Summary != "":
+		return t.Session.Summary
+	default:
+		return "Session " + t.Session.ID
+	}
+}
+
+// Build loads a session's conversation and approvals into a transcript.
+// Thinking blocks are left out; tool results are attached to their calls.
+func Build(ctx context.Context, s store.ConversationStore, annotationStore store.AnnotationStore, sessionID string, opts Options) (*Transcript, error) {
+	session, err := s.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	events, err := s.GetSessionConversation(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversation: %w", err)
+	}
+
+	results := make(map[string]string)
+	for _, event := range events {
+		if event.EventType == store.EventTypeToolResult {
+			results[event.ToolResultForID] = event.ToolResultContent
+		}
+	}
+
+	annotations, err := annotationStore.ListEventAnnotations(ctx, store.AnnotationFilter{SessionID: sessionID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get annotations: %w", err)
+	}
+	byEvent := make(map[int64][]*store.EventAnnotation)
+	for _, a := range annotations {
+		byEvent[a.EventID] = append(byEvent[a.EventID], a)
+	}
+
+	t := &Transcript{Session: session, Diff: opts.Diff}
+	toolEntries := make(map[string]int) // tool ID -> index in t.Entries
+	for _, event := range events {
+		// Annotations on events left out of the transcript move to the
+		// tool call they answer, or else to the preceding entry
+		if skipped(event) {
+			if notes := byEvent[event.ID]; len(notes) > 0 {
+				if i, ok := toolEntries[event.ToolResultForID]; ok && event.EventType == store.EventTypeToolResult {
+					t.Entries[i].Annotations = append(t.Entries[i].Annotations, notes...)
+				} else if len(t.Entries) > 0 {
+					last := &t.Entries[len(t.Entries)-1]
+					last.Annotations = append(last.Annotations, notes...)
+				}
+			}
+			continue
+		}
+
+		entry := Entry{Kind: event.EventType, Time: event.CreatedAt, Role: event.Role, Content: event.Content, Annotations: byEvent[event.ID]}
+		if event.EventType == store.EventTypeToolCall {
+			entry.ToolName = event.ToolName
+			entry.ToolInput = formatInput(event.ToolInputJSON)
+			if opts.RedactToolInputs {
+				entry.ToolInput = redactedInput
+			}
+			entry.ToolResult = results[event.ToolID]
+			if len(entry.ToolResult) > maxResultLength {
+				entry.ToolResult = entry.ToolResult[:maxResultLength]
+				entry.ResultTruncated = true
+			}
+			entry.ApprovalStatus = event.ApprovalStatus
+			if event.ApprovalID != "" {
+				if a, err := s.GetApproval(ctx, event.ApprovalID); err == nil {
+					entry.ApprovalStatus = string(a.Status)
+					entry.ApprovalComment = a.Comment
+				}
+			}
+			toolEntries[event.ToolID] = len(t.Entries)
+		}
+		t.Entries = append(t.Entries, entry)
+	}
+	return t, nil
+}
+
+// skipped reports whether an event is left out of the transcript: tool
+// results (shown with their call), thinking, and empty messages
+func skipped(event *store.ConversationEvent) bool {
+	switch event.EventType {
+	case store.EventTypeMessage, store.EventTypeSystem:
+		return strings.TrimSpace(event.Content) == ""
+	case store.EventTypeToolCall:
+		return false
+	default:
+		return true
+	}
+}
+
+// formatInput pretty-prints a tool call's JSON input
+func formatInput(input string) string {
+	var out bytes.Buffer
+	if err := json.Indent(&out, []byte(input), "", "  "); err != nil {
+		return input
+	}
+	return out.String()
+}
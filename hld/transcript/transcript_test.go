@@ -0,0 +1,101 @@
+package transcript
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSession(t *testing.T) *store.MemoryStore {
+	t.Helper()
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	require.NoError(t, s.CreateSession(ctx, &store.Session{
+		ID: "sess-1", RunID: "run-1", ClaudeSessionID: "claude-1", Title: "Fix the parser",
+		WorkingDir: "/work/repo", Status: store.SessionStatusCompleted, CreatedAt: time.Now(),
+	}))
+	require.NoError(t, s.CreateApproval(ctx, &store.Approval{
+		ID: "appr-1", RunID: "run-1", SessionID: "sess-1", Status: store.ApprovalStatusLocalDenied,
+		ToolName: "Bash", Comment: "use the make target", CreatedAt: time.Now(),
+	}))
+
+	events := []*store.ConversationEvent{
+		{EventType: store.EventTypeMessage, Role: "user", Content: "Fix <parser> bug"},
+		{EventType: store.EventTypeThinking, Role: "assistant", Content: "private reasoning"},
+		{EventType: store.EventTypeToolCall, ToolID: "tool-1", ToolName: "Read", ToolInputJSON: `{"file_path":"/work/repo/parse.go"}`},
+		{EventType: store.EventTypeToolResult, ToolResultForID: "tool-1", ToolResultContent: "package main\n```go\n```"},
+		{EventType: store.EventTypeToolCall, ToolID: "tool-2", ToolName: "Bash", ToolInputJSON: `{"command":"go test"}`, ApprovalID: "appr-1"},
+		{EventType: store.EventTypeMessage, Role: "assistant", Content: "Done."},
+	}
+	for _, event := range events {
+		event.SessionID = "sess-1"
+		event.ClaudeSessionID = "claude-1"
+		require.NoError(t, s.AddConversationEvent(ctx, event))
+	}
+	return s
+}
+
+func TestMarkdown(t *testing.T) {
+	s := newSession(t)
+	tr, err := Build(context.Background(), s, s, "sess-1", Options{Diff: "+fixed\n"})
+	require.NoError(t, err)
+	require.Len(t, tr.Entries, 4)
+
+	md := tr.Markdown()
+	assert.True(t, strings.HasPrefix(md, "# Fix the parser\n"))
+	assert.Contains(t, md, "Fix <parser> bug")
+	assert.NotContains(t, md, "private reasoning")
+	assert.Contains(t, md, "**Tool: Bash** — denied: use the make target")
+	assert.Contains(t, md, "\"file_path\": \"/work/repo/parse.go\"")
+	// The result contains a fence, so it must be wrapped in a longer one
+	assert.Contains(t, md, "````\npackage main")
+	assert.Contains(t, md, "## Final diff\n\n```diff\n+fixed\n```")
+}
+
+func TestRedactionAndHTML(t *testing.T) {
+	s := newSession(t)
+	tr, err := Build(context.Background(), s, s, "sess-1", Options{RedactToolInputs: true})
+	require.NoError(t, err)
+
+	md := tr.Markdown()
+	assert.NotContains(t, md, "go test")
+	assert.Contains(t, md, redactedInput)
+	assert.NotContains(t, md, "Final diff")
+
+	page, err := tr.HTML()
+	require.NoError(t, err)
+	assert.Contains(t, page, "<title>Fix the parser</title>")
+	assert.Contains(t, page, "Fix &lt;parser&gt; bug")
+	assert.Contains(t, page, "Tool: Bash — denied: use the make target")
+	assert.NotContains(t, page, "go test")
+}
+
+func TestAnnotations(t *testing.T) {
+	ctx := context.Background()
+	s := newSession(t)
+	// Event 4 is the Read result, which renders with its call
+	require.NoError(t, s.CreateEventAnnotation(ctx, &store.EventAnnotation{
+		SessionID: "sess-1", EventID: 4, Kind: store.AnnotationKindBookmark, Author: "sam",
+	}))
+	require.NoError(t, s.CreateEventAnnotation(ctx, &store.EventAnnotation{
+		SessionID: "sess-1", EventID: 6, Kind: store.AnnotationKindNote, Note: "this is where it went wrong",
+	}))
+
+	tr, err := Build(ctx, s, s, "sess-1", Options{})
+	require.NoError(t, err)
+	require.Len(t, tr.Entries[1].Annotations, 1)
+	assert.Equal(t, "Read", tr.Entries[1].ToolName)
+
+	md := tr.Markdown()
+	assert.Contains(t, md, "> **Bookmark (sam)**\n")
+	assert.Contains(t, md, "Done.\n\n> **Note**: this is where it went wrong\n")
+
+	page, err := tr.HTML()
+	require.NoError(t, err)
+	assert.Contains(t, page, `<div class="annotation content"><strong>Note</strong>: this is where it went wrong</div>`)
+}
@@ -0,0 +1,185 @@
+package twilio
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+const (
+	// maxInput keeps the tool input quoted in a message to about one SMS
+	// segment
+	maxInput = 120
+
+	requestTimeout = 15 * time.Second
+)
+
+// Notifier texts approvals to the configured users through Twilio's
+// Messages API
+type Notifier struct {
+	store      store.ConversationStore
+	eventBus   bus.EventBus
+	codes      *Codes
+	cfg        config.TwilioConfig
+	httpClient *http.Client
+}
+
+// NewNotifier creates a notifier sending with httpClient
+func NewNotifier(s store.ConversationStore, eventBus bus.EventBus, codes *Codes, cfg config.TwilioConfig, httpClient *http.Client) *Notifier {
+	if cfg.AuthTokenEnv == "" {
+		cfg.AuthTokenEnv = config.DefaultTwilioAuthTokenEnv
+	}
+	if cfg.APIBaseURL == "" {
+		cfg.APIBaseURL = config.DefaultTwilioAPIBaseURL
+	}
+	return &Notifier{store: s, eventBus: eventBus, codes: codes, cfg: cfg, httpClient: httpClient}
+}
+
+// FromConfig creates the daemon's notifier, or nil when Twilio isn't
+// configured
+func FromConfig(cfg *config.Config, s store.ConversationStore, eventBus bus.EventBus, codes *Codes) *Notifier {
+	if cfg.Twilio.AccountSID == "" || len(cfg.Twilio.Users) == 0 {
+		return nil
+	}
+	return NewNotifier(s, eventBus, codes, cfg.Twilio, &http.Client{Timeout: requestTimeout})
+}
+
+// Run texts each new critical approval, or every new approval with
+// all_approvals, until ctx is done
+func (n *Notifier) Run(ctx context.Context) {
+	sub := n.eventBus.Subscribe(ctx, bus.EventFilter{
+		Types: []bus.EventType{bus.EventNewApproval},
+	})
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-sub.Channel:
+			if !ok {
+				return
+			}
+			if critical, _ := event.Data["critical"].(bool); !critical && !n.cfg.AllApprovals {
+				continue
+			}
+			approvalID, _ := event.Data["approval_id"].(string)
+			go func() {
+				if err := n.Notify(ctx, approvalID); err != nil {
+					slog.Warn("failed to text approval", "approval_id", approvalID, "error", err)
+				}
+			}()
+		}
+	}
+}
+
+// Notify texts a pending approval to every configured user, returning the
+// errors of those it couldn't reach
+func (n *Notifier) Notify(ctx context.Context, approvalID string) error {
+	body, err := n.Compose(ctx, approvalID)
+	if err != nil {
+		return err
+	}
+	numbers := make([]string, 0, len(n.cfg.Users))
+	for number := range n.cfg.Users {
+		numbers = append(numbers, number)
+	}
+	sort.Strings(numbers)
+
+	var errs []error
+	for _, number := range numbers {
+		if err := n.Send(ctx, number, body); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", n.cfg.Users[number], err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Compose writes the message for an approval, ending with how to answer it
+func (n *Notifier) Compose(ctx context.Context, approvalID string) (string, error) {
+	approval, err := n.store.GetApproval(ctx, approvalID)
+	if err != nil {
+		return "", err
+	}
+	code, err := n.codes.Code(approval.ID)
+	if err != nil {
+		return "", err
+	}
+	name := approval.SessionID
+	if session, err := n.store.GetSession(ctx, approval.SessionID); err == nil {
+		switch {
+		case session.Title != "":
+			name = session.Title
+		case session.Summary != "":
+			name = session.Summary
+		case session.WorkingDir != "":
+			name = filepath.Base(session.WorkingDir)
+		}
+	}
+
+	input := string(approval.ToolInput)
+	var fields map[string]any
+	if json.Unmarshal(approval.ToolInput, &fields) == nil {
+		// A command or path says more than the JSON around it
+		for _, key := range []string{"command", "file_path", "url"} {
+			if value, ok := fields[key].(string); ok {
+				input = value
+				break
+			}
+		}
+	}
+	if len(input) > maxInput {
+		input = strings.ToValidUTF8(input[:maxInput], "") + "…"
+	}
+
+	return fmt.Sprintf("Approval needed: %s wants to run %s\n%s\n\nReply YES %s or NO %s <reason>",
+		name, approval.ToolName, input, code, code), nil
+}
+
+// Send texts body to a number, over WhatsApp when it has the whatsapp:
+// prefix
+func (n *Notifier) Send(ctx context.Context, to, body string) error {
+	from := n.cfg.From
+	if strings.HasPrefix(to, "whatsapp:") {
+		from = n.cfg.WhatsAppFrom
+	}
+	form := url.Values{"To": {to}, "From": {from}, "Body": {body}}
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json",
+		strings.TrimSuffix(n.cfg.APIBaseURL, "/"), url.PathEscape(n.cfg.AccountSID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(n.cfg.AccountSID, os.Getenv(n.cfg.AuthTokenEnv))
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 300 {
+		return nil
+	}
+	var apiErr struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+		return fmt.Errorf("twilio error %d: %s", apiErr.Code, apiErr.Message)
+	}
+	return fmt.Errorf("twilio returned %s", resp.Status)
+}
@@ -0,0 +1,160 @@
+// Package twilio sends critical approvals as SMS or WhatsApp messages
+// through Twilio and decides them from replies such as "YES K7QD2M" or
+// "NO K7QD2M use staging".
+package twilio
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/humanlayer/humanlayer/hld/internal/keyfile"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// CodeLength is the number of characters in an approval's reply code
+const CodeLength = 6
+
+// ErrUnknownCode means no pending approval has the code a reply gave
+var ErrUnknownCode = errors.New("no pending approval has that code")
+
+// Pending lists approvals waiting on a decision; the store in the daemon
+type Pending interface {
+	ListPendingApprovals(ctx context.Context) ([]*store.Approval, error)
+}
+
+// Codes derives the short code a reply quotes from an approval's ID, so
+// codes don't need storing and can't be guessed without the key, which is
+// kept in a file beside the database
+type Codes struct {
+	keyFile string
+
+	mu  sync.Mutex
+	key []byte // loaded from keyFile on first use
+}
+
+// NewCodes creates codes derived with key
+func NewCodes(key []byte) *Codes {
+	return &Codes{key: key}
+}
+
+// CodesFromConfig creates the daemon's codes, with the key in twilio.key
+// beside the database
+func CodesFromConfig(databasePath string) *Codes {
+	return &Codes{keyFile: filepath.Join(filepath.Dir(databasePath), "twilio.key")}
+}
+
+// Code returns the reply code for an approval
+func (c *Codes) Code(approvalID string) (string, error) {
+	c.mu.Lock()
+	if c.key == nil {
+		key, err := keyfile.Load(c.keyFile, 32)
+		if err != nil {
+			c.mu.Unlock()
+			return "", fmt.Errorf("twilio key: %w", err)
+		}
+		c.key = key
+	}
+	key := c.key
+	c.mu.Unlock()
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("approval:" + approvalID))
+	return base32.StdEncoding.EncodeToString(mac.Sum(nil))[:CodeLength], nil
+}
+
+// Find returns the pending approval with a code
+func (c *Codes) Find(ctx context.Context, pending Pending, code string) (*store.Approval, error) {
+	code = strings.ToUpper(code)
+	approvals, err := pending.ListPendingApprovals(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, approval := range approvals {
+		want, err := c.Code(approval.ID)
+		if err != nil {
+			return nil, err
+		}
+		if hmac.Equal([]byte(want), []byte(code)) {
+			return approval, nil
+		}
+	}
+	return nil, ErrUnknownCode
+}
+
+// Reply decisions
+const (
+	DecisionApprove = "approve"
+	DecisionDeny    = "deny"
+)
+
+// replyPattern splits a reply into its decision word, code and comment
+var replyPattern = regexp.MustCompile(`(?s)^\s*([A-Za-z]+)[.,!:]?\s+([A-Za-z2-7]{6})\b[\s.,:\-–—]*(.*)$`)
+
+// ParseReply reads "YES <code> [comment]" or "NO <code> [reason]". Y,
+// APPROVE, N and DENY work too, in any case.
+func ParseReply(text string) (decision, code, comment string, ok bool) {
+	match := replyPattern.FindStringSubmatch(text)
+	if match == nil {
+		return "", "", "", false
+	}
+	switch strings.ToUpper(match[1]) {
+	case "YES", "Y", "APPROVE":
+		decision = DecisionApprove
+	case "NO", "N", "DENY":
+		decision = DecisionDeny
+	default:
+		return "", "", "", false
+	}
+	return decision, strings.ToUpper(match[2]), strings.TrimSpace(match[3]), true
+}
+
+// Signature computes X-Twilio-Signature for a form webhook: the base64
+// HMAC-SHA1 of its URL followed by each parameter's name and value, sorted
+// by name
+func Signature(authToken, webhookURL string, form url.Values) string {
+	keys := make([]string, 0, len(form))
+	for key := range form {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	mac := hmac.New(sha1.New, []byte(authToken))
+	mac.Write([]byte(webhookURL))
+	for _, key := range keys {
+		for _, value := range form[key] {
+			mac.Write([]byte(key + value))
+		}
+	}
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is Twilio's for a webhook
+func Verify(authToken, webhookURL string, form url.Values, signature string) bool {
+	if authToken == "" || signature == "" {
+		return false
+	}
+	return hmac.Equal([]byte(Signature(authToken, webhookURL, form)), []byte(signature))
+}
+
+// TwiML is a webhook response, replying with message when it isn't empty
+func TwiML(message string) []byte {
+	type response struct {
+		XMLName xml.Name `xml:"Response"`
+		Message string   `xml:"Message,omitempty"`
+	}
+	out, _ := xml.Marshal(response{Message: message})
+	return append([]byte(xml.Header), out...)
+}
@@ -0,0 +1,140 @@
+package twilio
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodes(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	for _, id := range []string{"local-1", "local-2"} {
+		require.NoError(t, s.CreateApproval(ctx, &store.Approval{
+			ID: id, RunID: "run-1", SessionID: "sess-1", Status: store.ApprovalStatusLocalPending,
+			ToolName: "Bash", ToolInput: json.RawMessage(`{}`), CreatedAt: time.Now(),
+		}))
+	}
+
+	codes := NewCodes(bytes.Repeat([]byte{1}, 32))
+	code, err := codes.Code("local-2")
+	require.NoError(t, err)
+	assert.Regexp(t, `^[A-Z2-7]{6}$`, code)
+	other, err := NewCodes(bytes.Repeat([]byte{2}, 32)).Code("local-2")
+	require.NoError(t, err)
+	assert.NotEqual(t, code, other, "codes depend on the key")
+
+	found, err := codes.Find(ctx, s, code)
+	require.NoError(t, err)
+	assert.Equal(t, "local-2", found.ID)
+
+	_, err = codes.Find(ctx, s, "AAAAAA")
+	assert.ErrorIs(t, err, ErrUnknownCode)
+}
+
+func TestParseReply(t *testing.T) {
+	for _, tc := range []struct {
+		text, decision, code, comment string
+		ok                            bool
+	}{
+		{"YES K7QD2M", DecisionApprove, "K7QD2M", "", true},
+		{"yes k7qd2m", DecisionApprove, "K7QD2M", "", true},
+		{"Y K7QD2M  ran it locally", DecisionApprove, "K7QD2M", "ran it locally", true},
+		{"NO K7QD2M use the staging database", DecisionDeny, "K7QD2M", "use the staging database", true},
+		{"No, K7QD2M - not on a Friday", DecisionDeny, "K7QD2M", "not on a Friday", true},
+		{"YES", "", "", "", false},
+		{"YES K7QD2", "", "", "", false},
+		{"MAYBE K7QD2M", "", "", "", false},
+	} {
+		decision, code, comment, ok := ParseReply(tc.text)
+		assert.Equal(t, tc.ok, ok, tc.text)
+		assert.Equal(t, tc.decision, decision, tc.text)
+		assert.Equal(t, tc.code, code, tc.text)
+		assert.Equal(t, tc.comment, comment, tc.text)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	webhook := "https://hld.example.com/api/v1/twilio/inbound"
+	form := url.Values{"From": {"+15550123"}, "Body": {"YES K7QD2M"}, "MessageSid": {"SM1"}}
+	signature := Signature("token", webhook, form)
+
+	assert.True(t, Verify("token", webhook, form, signature))
+	assert.False(t, Verify("other", webhook, form, signature))
+	assert.False(t, Verify("token", webhook+"?x=1", form, signature))
+	assert.False(t, Verify("", webhook, form, Signature("", webhook, form)), "an unset token accepts nothing")
+
+	form.Set("Body", "YES AAAAAA")
+	assert.False(t, Verify("token", webhook, form, signature))
+}
+
+func TestTwiML(t *testing.T) {
+	assert.Equal(t, xmlHeader+`<Response><Message>Approved &lt;Bash&gt;</Message></Response>`, string(TwiML("Approved <Bash>")))
+	assert.Equal(t, xmlHeader+`<Response></Response>`, string(TwiML("")))
+}
+
+const xmlHeader = `<?xml version="1.0" encoding="UTF-8"?>` + "\n"
+
+func TestNotify(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	require.NoError(t, s.CreateSession(ctx, &store.Session{ID: "sess-1", RunID: "run-1", Title: "Deploy", WorkingDir: "/src/app"}))
+	require.NoError(t, s.CreateApproval(ctx, &store.Approval{
+		ID: "local-1", RunID: "run-1", SessionID: "sess-1", Status: store.ApprovalStatusLocalPending,
+		ToolName: "Bash", ToolInput: json.RawMessage(`{"command":"make deploy","timeout":600}`), CreatedAt: time.Now(),
+	}))
+
+	var mu sync.Mutex
+	sent := map[string]url.Values{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, token, _ := r.BasicAuth()
+		body, _ := io.ReadAll(r.Body)
+		form, _ := url.ParseQuery(string(body))
+		if r.URL.Path != "/2010-04-01/Accounts/AC1/Messages.json" || user != "AC1" || token != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if form.Get("To") == "+15550199" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"code":21211,"message":"Invalid 'To' Phone Number"}`))
+			return
+		}
+		mu.Lock()
+		sent[form.Get("To")] = form
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+	t.Setenv("TEST_TWILIO_TOKEN", "secret")
+
+	codes := NewCodes(bytes.Repeat([]byte{1}, 32))
+	n := NewNotifier(s, nil, codes, config.TwilioConfig{
+		AccountSID: "AC1", AuthTokenEnv: "TEST_TWILIO_TOKEN", APIBaseURL: server.URL,
+		From: "+15550100", WhatsAppFrom: "whatsapp:+15550100",
+		Users: map[string]string{"+15550123": "ada", "whatsapp:+15550124": "grace", "+15550199": "bob"},
+	}, server.Client())
+
+	err := n.Notify(ctx, "local-1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bob: twilio error 21211: Invalid 'To' Phone Number")
+
+	code, err := codes.Code("local-1")
+	require.NoError(t, err)
+	require.Len(t, sent, 2)
+	assert.Equal(t, "+15550100", sent["+15550123"].Get("From"))
+	assert.Equal(t, "whatsapp:+15550100", sent["whatsapp:+15550124"].Get("From"))
+	assert.Equal(t, "Approval needed: Deploy wants to run Bash\nmake deploy\n\nReply YES "+code+" or NO "+code+" <reason>",
+		sent["+15550123"].Get("Body"))
+}
@@ -0,0 +1,160 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// Thresholds are the percentages of a cost budget that trigger an alert
+var Thresholds = []int{50, 80, 100}
+
+// BudgetStatus is the spend against one cost budget in its current period
+type BudgetStatus struct {
+	Name        string    `json:"name"`
+	Period      string    `json:"period"`
+	PeriodStart time.Time `json:"period_start"`
+	WorkingDir  string    `json:"working_dir,omitempty"`
+	LimitUSD    float64   `json:"limit_usd"`
+	SpentUSD    float64   `json:"spent_usd"`
+	Percent     float64   `json:"percent"`
+}
+
+// Monitor publishes an event the first time spend crosses each threshold of
+// a cost budget in a period. Spend is rechecked whenever a session changes
+// status, which is when its final cost is recorded.
+type Monitor struct {
+	store    store.ConversationStore
+	eventBus bus.EventBus
+	budgets  map[string]config.CostBudget
+	now      func() time.Time
+
+	mu      sync.Mutex
+	alerted map[string]int // budget name + period start -> highest threshold alerted
+}
+
+// NewMonitor creates a cost budget monitor
+func NewMonitor(s store.ConversationStore, eventBus bus.EventBus, budgets map[string]config.CostBudget) *Monitor {
+	return &Monitor{
+		store:    s,
+		eventBus: eventBus,
+		budgets:  budgets,
+		now:      time.Now,
+		alerted:  make(map[string]int),
+	}
+}
+
+// Status returns the current spend against every budget, sorted by name
+func (m *Monitor) Status(ctx context.Context) ([]BudgetStatus, error) {
+	now := m.now()
+	names := make([]string, 0, len(m.budgets))
+	for name := range m.budgets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	statuses := make([]BudgetStatus, 0, len(names))
+	for _, name := range names {
+		budget := m.budgets[name]
+		start, err := PeriodStart(budget.Period, now)
+		if err != nil {
+			return nil, fmt.Errorf("cost budget %q: %w", name, err)
+		}
+		spent, err := Spend(ctx, m.store, start, budget.WorkingDir)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, BudgetStatus{
+			Name:        name,
+			Period:      budget.Period,
+			PeriodStart: start,
+			WorkingDir:  budget.WorkingDir,
+			LimitUSD:    budget.LimitUSD,
+			SpentUSD:    spent,
+			Percent:     spent / budget.LimitUSD * 100,
+		})
+	}
+	return statuses, nil
+}
+
+// Run checks budgets until ctx is cancelled. Thresholds already crossed when
+// the daemon starts are not alerted again.
+func (m *Monitor) Run(ctx context.Context) {
+	if len(m.budgets) == 0 {
+		return
+	}
+	if err := m.check(ctx, false); err != nil {
+		slog.Warn("failed to check cost budgets", "error", err)
+	}
+
+	sub := m.eventBus.Subscribe(ctx, bus.EventFilter{
+		Types: []bus.EventType{bus.EventSessionStatusChanged},
+	})
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-sub.Channel:
+			if !ok {
+				return
+			}
+			if err := m.check(ctx, true); err != nil {
+				slog.Warn("failed to check cost budgets", "error", err)
+			}
+		}
+	}
+}
+
+// check records the highest threshold crossed by each budget, publishing an
+// event for newly crossed thresholds when notify is set
+func (m *Monitor) check(ctx context.Context, notify bool) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, status := range statuses {
+		key := status.Name + "@" + status.PeriodStart.Format(time.RFC3339)
+		crossed := 0
+		for _, threshold := range Thresholds {
+			if status.Percent >= float64(threshold) {
+				crossed = threshold
+			}
+		}
+		if crossed <= m.alerted[key] {
+			continue
+		}
+		m.alerted[key] = crossed
+
+		if !notify || m.eventBus == nil {
+			continue
+		}
+		slog.Info("cost budget threshold crossed",
+			"budget", status.Name,
+			"threshold", crossed,
+			"spent_usd", status.SpentUSD,
+			"limit_usd", status.LimitUSD)
+		m.eventBus.Publish(bus.Event{
+			Type: bus.EventCostBudgetThreshold,
+			Data: map[string]interface{}{
+				"budget":       status.Name,
+				"period":       status.Period,
+				"period_start": status.PeriodStart,
+				"threshold":    crossed,
+				"spent_usd":    status.SpentUSD,
+				"limit_usd":    status.LimitUSD,
+			},
+		})
+	}
+	return nil
+}
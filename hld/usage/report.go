@@ -0,0 +1,174 @@
+// Package usage aggregates session spend for reporting and daemon-wide cost budgets.
+package usage
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// noneKey groups sessions without a template or model
+const noneKey = "(none)"
+
+// Group is the spend of the sessions sharing a workspace, template or model
+type Group struct {
+	Key          string  `json:"key"`
+	Sessions     int     `json:"sessions"`
+	CostUSD      float64 `json:"cost_usd"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+}
+
+// Report is the spend of sessions started within a period
+type Report struct {
+	Period      string    `json:"period"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Sessions    int       `json:"sessions"`
+	CostUSD     float64   `json:"cost_usd"`
+	ByWorkspace []Group   `json:"by_workspace"`
+	ByTemplate  []Group   `json:"by_template"`
+	ByModel     []Group   `json:"by_model"`
+}
+
+// PeriodStart returns the start of the calendar period containing now
+func PeriodStart(period string, now time.Time) (time.Time, error) {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch period {
+	case config.CostBudgetPeriodDay:
+		return day, nil
+	case config.CostBudgetPeriodWeek:
+		// Weeks start on Monday
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset), nil
+	case config.CostBudgetPeriodMonth:
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()), nil
+	default:
+		return time.Time{}, fmt.Errorf("unknown period %q", period)
+	}
+}
+
+// BuildReport sums the cost of sessions created in the current period,
+// grouped by working directory, template and model
+func BuildReport(ctx context.Context, s store.ConversationStore, period string, now time.Time) (*Report, error) {
+	start, err := PeriodStart(period, now)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := sessionsSince(ctx, s, start, "")
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Period: period, Start: start, End: now}
+	workspaces := make(map[string]*Group)
+	templates := make(map[string]*Group)
+	models := make(map[string]*Group)
+
+	for _, session := range sessions {
+		report.Sessions++
+		report.CostUSD += sessionCost(session)
+
+		template := session.Template
+		if template == "" {
+			template = noneKey
+		}
+		model := session.ModelID
+		if model == "" {
+			model = session.Model
+		}
+		if model == "" {
+			model = noneKey
+		}
+
+		addToGroup(workspaces, session.WorkingDir, session)
+		addToGroup(templates, template, session)
+		addToGroup(models, model, session)
+	}
+
+	report.ByWorkspace = sortedGroups(workspaces)
+	report.ByTemplate = sortedGroups(templates)
+	report.ByModel = sortedGroups(models)
+	return report, nil
+}
+
+// Spend sums the cost of sessions created since start, limited to sessions
+// under workingDir when it is set
+func Spend(ctx context.Context, s store.ConversationStore, start time.Time, workingDir string) (float64, error) {
+	sessions, err := sessionsSince(ctx, s, start, workingDir)
+	if err != nil {
+		return 0, err
+	}
+	var total float64
+	for _, session := range sessions {
+		total += sessionCost(session)
+	}
+	return total, nil
+}
+
+func sessionsSince(ctx context.Context, s store.ConversationStore, start time.Time, workingDir string) ([]*store.Session, error) {
+	all, err := s.ListSessions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	var matched []*store.Session
+	for _, session := range all {
+		if session.CreatedAt.Before(start) {
+			continue
+		}
+		if workingDir != "" && !underDir(session.WorkingDir, workingDir) {
+			continue
+		}
+		matched = append(matched, session)
+	}
+	return matched, nil
+}
+
+func underDir(path, dir string) bool {
+	rel, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(path))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func sessionCost(session *store.Session) float64 {
+	if session.CostUSD == nil {
+		return 0
+	}
+	return *session.CostUSD
+}
+
+func addToGroup(groups map[string]*Group, key string, session *store.Session) {
+	g, ok := groups[key]
+	if !ok {
+		g = &Group{Key: key}
+		groups[key] = g
+	}
+	g.Sessions++
+	g.CostUSD += sessionCost(session)
+	if session.InputTokens != nil {
+		g.InputTokens += *session.InputTokens
+	}
+	if session.OutputTokens != nil {
+		g.OutputTokens += *session.OutputTokens
+	}
+}
+
+// sortedGroups orders groups by cost, most expensive first
+func sortedGroups(groups map[string]*Group) []Group {
+	result := make([]Group, 0, len(groups))
+	for _, g := range groups {
+		result = append(result, *g)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].CostUSD != result[j].CostUSD {
+			return result[i].CostUSD > result[j].CostUSD
+		}
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
@@ -0,0 +1,102 @@
+package usage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func addSession(t *testing.T, s store.ConversationStore, id, dir, model, template string, cost float64, created time.Time) {
+	t.Helper()
+	require.NoError(t, s.CreateSession(context.Background(), &store.Session{
+		ID:         id,
+		RunID:      id + "-run",
+		WorkingDir: dir,
+		Model:      model,
+		Template:   template,
+		CostUSD:    &cost,
+		CreatedAt:  created,
+	}))
+}
+
+func TestPeriodStart(t *testing.T) {
+	now := time.Date(2026, 10, 15, 14, 30, 0, 0, time.UTC) // Thursday
+
+	day, err := PeriodStart(config.CostBudgetPeriodDay, now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC), day)
+
+	week, err := PeriodStart(config.CostBudgetPeriodWeek, now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC), week)
+
+	month, err := PeriodStart(config.CostBudgetPeriodMonth, now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), month)
+
+	_, err = PeriodStart("year", now)
+	assert.Error(t, err)
+}
+
+func TestBuildReport(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+
+	addSession(t, s, "a", "/repo/api", "opus", "bugfix", 3, now.Add(-time.Hour))
+	addSession(t, s, "b", "/repo/api", "sonnet", "", 1, now.Add(-48*time.Hour))
+	addSession(t, s, "c", "/repo/web", "opus", "bugfix", 2, now.Add(-24*time.Hour))
+	addSession(t, s, "old", "/repo/api", "opus", "bugfix", 100, time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC))
+
+	report, err := BuildReport(ctx, s, config.CostBudgetPeriodMonth, now)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, report.Sessions)
+	assert.InDelta(t, 6.0, report.CostUSD, 0.001)
+	assert.Equal(t, []Group{
+		{Key: "/repo/api", Sessions: 2, CostUSD: 4},
+		{Key: "/repo/web", Sessions: 1, CostUSD: 2},
+	}, report.ByWorkspace)
+	assert.Equal(t, []Group{
+		{Key: "bugfix", Sessions: 2, CostUSD: 5},
+		{Key: "(none)", Sessions: 1, CostUSD: 1},
+	}, report.ByTemplate)
+	assert.Equal(t, "opus", report.ByModel[0].Key)
+}
+
+func TestMonitorThresholds(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	eventBus := bus.NewInMemoryBus()
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+
+	m := NewMonitor(s, eventBus, map[string]config.CostBudget{
+		"api": {Period: config.CostBudgetPeriodMonth, LimitUSD: 10, WorkingDir: "/repo/api"},
+	})
+	m.now = func() time.Time { return now }
+
+	// Spend already over 50% at startup is not alerted
+	addSession(t, s, "a", "/repo/api", "opus", "", 6, now)
+	addSession(t, s, "other", "/repo/web", "opus", "", 50, now)
+	require.NoError(t, m.check(ctx, false))
+
+	addSession(t, s, "b", "/repo/api/sub", "opus", "", 3, now)
+	require.NoError(t, m.check(ctx, true))
+	require.NoError(t, m.check(ctx, true))
+
+	addSession(t, s, "c", "/repo/api", "opus", "", 2, now)
+	require.NoError(t, m.check(ctx, true))
+
+	events := eventBus.EventsOfType(bus.EventCostBudgetThreshold)
+	require.Len(t, events, 2)
+	assert.Equal(t, 80, events[0].Data["threshold"])
+	assert.Equal(t, 100, events[1].Data["threshold"])
+	assert.Equal(t, "api", events[1].Data["budget"])
+	assert.InDelta(t, 11.0, events[1].Data["spent_usd"], 0.001)
+}
@@ -0,0 +1,308 @@
+// Package webpush notifies subscribed browsers of approvals through the
+// Web Push protocol, so the web UI hears about them with its tab in the
+// background or closed. Messages are encrypted for each browser (RFC 8291)
+// and signed with the daemon's VAPID key (RFC 8292).
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/humanlayer/humanlayer/hld/bus"
+	"github.com/humanlayer/humanlayer/hld/config"
+	"github.com/humanlayer/humanlayer/hld/internal/keyfile"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+const (
+	requestTimeout = 30 * time.Second
+	// messageTTL is how long a push service holds a message for a browser
+	// that's offline; approvals often wait that long
+	messageTTL = 24 * time.Hour
+	// maxBody keeps the notification text, and the encrypted payload, small
+	maxBody = 200
+)
+
+var (
+	// ErrInvalidSubscription is returned for subscriptions that can't be
+	// pushed to
+	ErrInvalidSubscription = errors.New("invalid subscription")
+	// ErrGone is returned when the push service no longer knows the
+	// subscription, as after the user revokes permission
+	ErrGone = errors.New("subscription expired")
+)
+
+// Action is a notification button
+type Action struct {
+	Action string `json:"action"`
+	Title  string `json:"title"`
+	// URL is opened when the button is clicked, relative to the web UI
+	URL string `json:"url"`
+}
+
+// Notification is the payload the web UI's service worker shows
+type Notification struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	// Tag replaces an earlier notification about the same thing
+	Tag string `json:"tag"`
+	// URL is opened when the notification itself is clicked
+	URL        string   `json:"url"`
+	ApprovalID string   `json:"approval_id,omitempty"`
+	SessionID  string   `json:"session_id,omitempty"`
+	Actions    []Action `json:"actions,omitempty"`
+}
+
+// Service manages subscriptions and sends notifications to them
+type Service struct {
+	store      store.ConversationStore
+	subs       store.WebPushStore
+	eventBus   bus.EventBus
+	subject    string
+	httpClient *http.Client
+	now        func() time.Time
+	keyFile    string
+
+	mu  sync.Mutex
+	key *vapidKey // loaded from keyFile on first use
+}
+
+// New creates a service identifying itself to push services with subject, a
+// mailto: or https: contact, and signing with the 32-byte VAPID key
+func New(s store.ConversationStore, subs store.WebPushStore, eventBus bus.EventBus, subject string, key []byte) (*Service, error) {
+	svc := &Service{
+		store:      s,
+		subs:       subs,
+		eventBus:   eventBus,
+		subject:    subject,
+		httpClient: &http.Client{Timeout: requestTimeout},
+		now:        time.Now,
+	}
+	if key != nil {
+		var err error
+		if svc.key, err = newVAPIDKey(key); err != nil {
+			return nil, err
+		}
+	}
+	return svc, nil
+}
+
+// FromConfig creates the daemon's service, with its VAPID key in
+// webpush.key beside the database
+func FromConfig(cfg *config.Config, s store.ConversationStore, subs store.WebPushStore, eventBus bus.EventBus) *Service {
+	svc, _ := New(s, subs, eventBus, cfg.WebPush.Subject, nil)
+	svc.keyFile = filepath.Join(filepath.Dir(cfg.DatabasePath), "webpush.key")
+	return svc
+}
+
+func (s *Service) vapid() (*vapidKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.key == nil {
+		raw, err := keyfile.Load(s.keyFile, 32)
+		if err != nil {
+			return nil, fmt.Errorf("web push key: %w", err)
+		}
+		if s.key, err = newVAPIDKey(raw); err != nil {
+			return nil, err
+		}
+	}
+	return s.key, nil
+}
+
+// PublicKey returns the applicationServerKey browsers subscribe with
+func (s *Service) PublicKey() (string, error) {
+	key, err := s.vapid()
+	if err != nil {
+		return "", err
+	}
+	return key.PublicKey(), nil
+}
+
+// Subscribe checks and records a browser's subscription. A browser
+// subscribing again keeps its ID.
+func (s *Service) Subscribe(ctx context.Context, sub *store.WebPushSubscription) error {
+	u, err := url.Parse(sub.Endpoint)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("%w: endpoint must be an http or https URL", ErrInvalidSubscription)
+	}
+	if _, _, err := subscriptionKeys(sub.P256DH, sub.Auth); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSubscription, err)
+	}
+	sub.ID = uuid.New().String()
+	return s.subs.SaveWebPushSubscription(ctx, sub)
+}
+
+// Send pushes a notification to one subscription
+func (s *Service) Send(ctx context.Context, sub *store.WebPushSubscription, notification Notification) error {
+	key, err := s.vapid()
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	body, err := encrypt(sub.P256DH, sub.Auth, payload)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSubscription, err)
+	}
+	authorization, err := key.authorization(sub.Endpoint, s.subject, s.now())
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSubscription, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSubscription, err)
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", strconv.Itoa(int(messageTTL.Seconds())))
+	req.Header.Set("Urgency", "high")
+	if notification.Tag != "" {
+		// Topic must be base64url and at most 32 characters
+		req.Header.Set("Topic", topic(notification.Tag))
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrGone
+	case resp.StatusCode >= 300:
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("push service returned %d: %s", resp.StatusCode, bytes.TrimSpace(detail))
+	}
+	return nil
+}
+
+// Notify pushes a notification to every subscription, removing those the
+// push service reports gone
+func (s *Service) Notify(ctx context.Context, notification Notification) error {
+	subs, err := s.subs.ListWebPushSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, sub := range subs {
+		err := s.Send(ctx, sub, notification)
+		if errors.Is(err, ErrGone) {
+			slog.Info("removing expired web push subscription", "id", sub.ID)
+			err = s.subs.DeleteWebPushSubscription(ctx, sub.ID)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("subscription %s: %w", sub.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run notifies subscribers of each new approval, and again when a snooze of
+// one ends, until ctx is done
+func (s *Service) Run(ctx context.Context) {
+	sub := s.eventBus.Subscribe(ctx, bus.EventFilter{
+		Types: []bus.EventType{bus.EventNewApproval, bus.EventApprovalReminder},
+	})
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-sub.Channel:
+			if !ok {
+				return
+			}
+			approvalID, _ := event.Data["approval_id"].(string)
+			go func() {
+				notification, err := s.ApprovalNotification(ctx, approvalID)
+				if err == nil {
+					err = s.Notify(ctx, notification)
+				}
+				if err != nil {
+					slog.Warn("failed to send web push notifications", "approval_id", approvalID, "error", err)
+				}
+			}()
+		}
+	}
+}
+
+// ApprovalNotification describes a pending approval, with buttons linking
+// to it and to its session in the web UI
+func (s *Service) ApprovalNotification(ctx context.Context, approvalID string) (Notification, error) {
+	approval, err := s.store.GetApproval(ctx, approvalID)
+	if err != nil {
+		return Notification{}, err
+	}
+	name := approval.SessionID
+	if session, err := s.store.GetSession(ctx, approval.SessionID); err == nil {
+		switch {
+		case session.Title != "":
+			name = session.Title
+		case session.Summary != "":
+			name = session.Summary
+		case session.WorkingDir != "":
+			name = filepath.Base(session.WorkingDir)
+		}
+	}
+
+	body := approval.ToolName
+	var input struct {
+		Command  string `json:"command"`
+		FilePath string `json:"file_path"`
+	}
+	if json.Unmarshal(approval.ToolInput, &input) == nil {
+		if detail := input.Command + input.FilePath; detail != "" {
+			body += ": " + detail
+		}
+	}
+	if len(body) > maxBody {
+		body = strings.ToValidUTF8(body[:maxBody], "") + "…"
+	}
+
+	sessionURL := "/#/sessions/" + approval.SessionID
+	approvalURL := sessionURL + "?approval=" + url.QueryEscape(approval.ID)
+	return Notification{
+		Title:      "Approval needed: " + name,
+		Body:       body,
+		Tag:        "approval-" + approval.ID,
+		URL:        approvalURL,
+		ApprovalID: approval.ID,
+		SessionID:  approval.SessionID,
+		Actions: []Action{
+			{Action: "review", Title: "Review", URL: approvalURL},
+			{Action: "session", Title: "Open session", URL: sessionURL},
+		},
+	}, nil
+}
+
+// topic derives a Topic header from a tag, so a push service holding an
+// undelivered message replaces it rather than queueing another
+func topic(tag string) string {
+	t := []byte(tag)
+	for i, c := range t {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			t[i] = '_'
+		}
+	}
+	if len(t) > 32 {
+		t = t[len(t)-32:]
+	}
+	return string(t)
+}
@@ -0,0 +1,188 @@
+package webpush
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// browser is the receiving side of a subscription
+type browser struct {
+	private *ecdh.PrivateKey
+	auth    []byte
+}
+
+func newBrowser(t *testing.T) *browser {
+	t.Helper()
+	private, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	auth := make([]byte, 16)
+	_, _ = rand.Read(auth)
+	return &browser{private: private, auth: auth}
+}
+
+func (b *browser) subscription(endpoint string) *store.WebPushSubscription {
+	return &store.WebPushSubscription{
+		Endpoint: endpoint,
+		P256DH:   base64.RawURLEncoding.EncodeToString(b.private.PublicKey().Bytes()),
+		Auth:     base64.RawURLEncoding.EncodeToString(b.auth),
+	}
+}
+
+// decrypt undoes encrypt as a browser would
+func (b *browser) decrypt(t *testing.T, body []byte) []byte {
+	t.Helper()
+	salt := body[:16]
+	require.Equal(t, uint32(recordSize), binary.BigEndian.Uint32(body[16:20]))
+	idLen := int(body[20])
+	asPublic, err := ecdh.P256().NewPublicKey(body[21 : 21+idLen])
+	require.NoError(t, err)
+	shared, err := b.private.ECDH(asPublic)
+	require.NoError(t, err)
+
+	prkKey, _ := hkdf.Extract(sha256.New, shared, b.auth)
+	ikm, _ := hkdf.Expand(sha256.New, prkKey, "WebPush: info\x00"+string(b.private.PublicKey().Bytes())+string(asPublic.Bytes()), 32)
+	prk, _ := hkdf.Extract(sha256.New, ikm, salt)
+	cek, _ := hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16)
+	nonce, _ := hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12)
+	block, _ := aes.NewCipher(cek)
+	gcm, _ := cipher.NewGCM(block)
+	record, err := gcm.Open(nil, nonce, body[21+idLen:], nil)
+	require.NoError(t, err)
+	require.Equal(t, byte(0x02), record[len(record)-1])
+	return record[:len(record)-1]
+}
+
+func newService(t *testing.T) (*Service, store.Store) {
+	t.Helper()
+	s := store.NewInMemoryStore()
+	svc, err := New(s, s, nil, "mailto:ops@example.com", []byte("0123456789abcdef0123456789abcdef"))
+	require.NoError(t, err)
+	return svc, s
+}
+
+// verifyVAPID checks the Authorization header's token against its key
+func verifyVAPID(t *testing.T, header, audience string) {
+	t.Helper()
+	var token, key string
+	for _, part := range strings.Split(strings.TrimPrefix(header, "vapid "), ", ") {
+		if v, ok := strings.CutPrefix(part, "t="); ok {
+			token = v
+		} else if v, ok := strings.CutPrefix(part, "k="); ok {
+			key = v
+		}
+	}
+	public, err := base64.RawURLEncoding.DecodeString(key)
+	require.NoError(t, err)
+	parts := strings.Split(token, ".")
+	require.Len(t, parts, 3)
+
+	claims, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	var decoded struct {
+		Aud string `json:"aud"`
+		Sub string `json:"sub"`
+		Exp int64  `json:"exp"`
+	}
+	require.NoError(t, json.Unmarshal(claims, &decoded))
+	assert.Equal(t, audience, decoded.Aud)
+	assert.Equal(t, "mailto:ops@example.com", decoded.Sub)
+	assert.Greater(t, decoded.Exp, time.Now().Unix())
+
+	signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(public[1:33]), Y: new(big.Int).SetBytes(public[33:])}
+	assert.True(t, ecdsa.Verify(pub, digest[:], new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])), "VAPID signature")
+}
+
+func TestSend(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newService(t)
+	b := newBrowser(t)
+
+	var got []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "aes128gcm", r.Header.Get("Content-Encoding"))
+		assert.Equal(t, "86400", r.Header.Get("TTL"))
+		assert.LessOrEqual(t, len(r.Header.Get("Topic")), 32)
+		verifyVAPID(t, r.Header.Get("Authorization"), "http://"+r.Host)
+		body, _ := io.ReadAll(r.Body)
+		got = b.decrypt(t, body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	notification := Notification{Title: "Approval needed", Body: "Bash: make deploy", Tag: "approval-6f1c2d3e-0000-4000-8000-000000000000"}
+	require.NoError(t, svc.Send(ctx, b.subscription(server.URL+"/push/abc"), notification))
+	var decoded Notification
+	require.NoError(t, json.Unmarshal(got, &decoded))
+	assert.Equal(t, notification, decoded)
+}
+
+func TestNotifyRemovesGoneSubscriptions(t *testing.T) {
+	ctx := context.Background()
+	svc, s := newService(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/gone") {
+			w.WriteHeader(http.StatusGone)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	require.NoError(t, svc.Subscribe(ctx, newBrowser(t).subscription(server.URL+"/live")))
+	require.NoError(t, svc.Subscribe(ctx, newBrowser(t).subscription(server.URL+"/gone")))
+	require.NoError(t, svc.Notify(ctx, Notification{Title: "hi"}))
+
+	subs, err := s.ListWebPushSubscriptions(ctx)
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	assert.Equal(t, server.URL+"/live", subs[0].Endpoint)
+}
+
+func TestSubscribeRefusesBadKeys(t *testing.T) {
+	svc, _ := newService(t)
+	sub := newBrowser(t).subscription("https://push.example/abc")
+	sub.Auth = "c2hvcnQ"
+	assert.ErrorIs(t, svc.Subscribe(context.Background(), sub), ErrInvalidSubscription)
+	sub = newBrowser(t).subscription("ftp://push.example/abc")
+	assert.ErrorIs(t, svc.Subscribe(context.Background(), sub), ErrInvalidSubscription)
+}
+
+func TestApprovalNotification(t *testing.T) {
+	ctx := context.Background()
+	svc, s := newService(t)
+	require.NoError(t, s.CreateSession(ctx, &store.Session{ID: "sess-1", RunID: "run-1", Title: "payments-fix"}))
+	require.NoError(t, s.CreateApproval(ctx, &store.Approval{
+		ID: "appr-1", RunID: "run-1", SessionID: "sess-1", Status: store.ApprovalStatusLocalPending,
+		ToolName: "Bash", ToolInput: json.RawMessage(`{"command":"make migrate"}`),
+	}))
+
+	notification, err := svc.ApprovalNotification(ctx, "appr-1")
+	require.NoError(t, err)
+	assert.Equal(t, "Approval needed: payments-fix", notification.Title)
+	assert.Equal(t, "Bash: make migrate", notification.Body)
+	assert.Equal(t, "/#/sessions/sess-1?approval=appr-1", notification.URL)
+	require.Len(t, notification.Actions, 2)
+	assert.Equal(t, notification.URL, notification.Actions[0].URL)
+}
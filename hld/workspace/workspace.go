@@ -0,0 +1,185 @@
+// Package workspace runs commands and reads files in a session's working
+// directory, which is either local or on a remote host reached over SSH.
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"strings"
+	"sync/atomic"
+
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// ErrTooLarge is returned by ReadFile when a file exceeds the size limit
+var ErrTooLarge = errors.New("file too large")
+
+// Host gives access to the machine holding a working directory
+type Host interface {
+	// Command prepares name with args to run in dir on the host
+	Command(ctx context.Context, dir, name string, args ...string) *exec.Cmd
+	// ReadFile reads path, failing with ErrTooLarge if it is over limit bytes
+	ReadFile(ctx context.Context, path string, limit int64) ([]byte, error)
+	// IsDir reports whether path is an existing directory
+	IsDir(ctx context.Context, path string) (bool, error)
+}
+
+// ForSession returns the host holding the session's working directory
+func ForSession(session *store.Session) Host {
+	if session.SSHHost == "" {
+		return Local{}
+	}
+	return NewSSH(session.SSHHost)
+}
+
+// Local is the machine the daemon runs on
+type Local struct{}
+
+// Command runs name directly in dir
+func (Local) Command(ctx context.Context, dir, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	return cmd
+}
+
+// ReadFile reads a local file
+func (Local) ReadFile(ctx context.Context, path string, limit int64) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > limit {
+		return nil, ErrTooLarge
+	}
+	return os.ReadFile(path)
+}
+
+// IsDir stats a local path
+func (Local) IsDir(ctx context.Context, path string) (bool, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return info.IsDir(), nil
+}
+
+// SSH is a remote host reached with the system ssh client, so keys, agents
+// and ~/.ssh/config settings apply as they do in a terminal
+type SSH struct {
+	Destination string
+}
+
+// sshOptions stop ssh from prompting, which would hang the daemon
+var sshOptions = []string{"-o", "BatchMode=yes", "-o", "ConnectTimeout=10"}
+
+// sshAgent configures the agent remote commands may use; see SetSSHAgent
+var sshAgent atomic.Pointer[agentSettings]
+
+type agentSettings struct {
+	authSock string
+	forward  bool
+}
+
+// SetSSHAgent sets the SSH agent socket ssh uses, when it isn't the
+// daemon's SSH_AUTH_SOCK, and whether the agent is forwarded so git in
+// remote working directories can authenticate with its keys
+func SetSSHAgent(authSock string, forward bool) {
+	sshAgent.Store(&agentSettings{authSock: authSock, forward: forward})
+}
+
+// NewSSH creates a host for destination (host, user@host or an ssh config alias)
+func NewSSH(destination string) *SSH {
+	return &SSH{Destination: destination}
+}
+
+// Command runs name in dir through a remote shell
+func (h *SSH) Command(ctx context.Context, dir, name string, args ...string) *exec.Cmd {
+	script := shellQuote(name)
+	for _, arg := range args {
+		script += " " + shellQuote(arg)
+	}
+	if dir != "" {
+		script = "cd " + shellQuote(dir) + " && " + script
+	}
+	return h.shell(ctx, script)
+}
+
+// ReadFile reads at most limit+1 bytes of a remote file, so an oversized
+// file is detected without transferring all of it
+func (h *SSH) ReadFile(ctx context.Context, path string, limit int64) ([]byte, error) {
+	quoted := shellQuote(path)
+	cmd := h.shell(ctx, fmt.Sprintf("test -f %s || exit 3; head -c %d %s", quoted, limit+1, quoted))
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("ssh %s: %w", h.Destination, err)
+	}
+	data, readErr := io.ReadAll(stdout)
+	if err := cmd.Wait(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 3 {
+			return nil, &fs.PathError{Op: "open", Path: path, Err: fs.ErrNotExist}
+		}
+		return nil, fmt.Errorf("ssh %s: %w: %s", h.Destination, err, strings.TrimSpace(stderr.String()))
+	}
+	if readErr != nil {
+		return nil, readErr
+	}
+	if int64(len(data)) > limit {
+		return nil, ErrTooLarge
+	}
+	return data, nil
+}
+
+// IsDir tests a remote path
+func (h *SSH) IsDir(ctx context.Context, path string) (bool, error) {
+	cmd := h.shell(ctx, "test -d "+shellQuote(path))
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+		return false, nil
+	default:
+		return false, fmt.Errorf("ssh %s: %w: %s", h.Destination, err, strings.TrimSpace(stderr.String()))
+	}
+}
+
+func (h *SSH) shell(ctx context.Context, script string) *exec.Cmd {
+	args := append([]string{}, sshOptions...)
+	agent := sshAgent.Load()
+	if agent != nil && agent.forward {
+		args = append(args, "-A")
+	}
+	args = append(args, "--", h.Destination, script)
+	cmd := exec.CommandContext(ctx, "ssh", args...)
+	if agent != nil && agent.authSock != "" {
+		cmd.Env = append(os.Environ(), "SSH_AUTH_SOCK="+agent.authSock)
+	}
+	return cmd
+}
+
+// shellQuote quotes s for a POSIX shell
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./=:@%+,", r))
+	}) == -1 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
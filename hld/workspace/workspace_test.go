@@ -0,0 +1,60 @@
+package workspace
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, "git", shellQuote("git"))
+	assert.Equal(t, "--pretty=format:%s", shellQuote("--pretty=format:%s"))
+	assert.Equal(t, "''", shellQuote(""))
+	assert.Equal(t, "'my repo'", shellQuote("my repo"))
+	assert.Equal(t, `'it'\''s; rm -rf /'`, shellQuote("it's; rm -rf /"))
+}
+
+func TestSSHCommand(t *testing.T) {
+	host := ForSession(&store.Session{SSHHost: "build@ci"})
+	cmd := host.Command(context.Background(), "/srv/my repo", "git", "commit", "-m", "fix: it's done")
+	assert.Equal(t, "ssh", filepath.Base(cmd.Path))
+	assert.Equal(t, []string{
+		"ssh", "-o", "BatchMode=yes", "-o", "ConnectTimeout=10", "--", "build@ci",
+		`cd '/srv/my repo' && git commit -m 'fix: it'\''s done'`,
+	}, cmd.Args)
+	assert.Empty(t, cmd.Dir)
+}
+
+func TestLocal(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file.txt")
+	require.NoError(t, os.WriteFile(path, []byte("hello"), 0644))
+
+	host := ForSession(&store.Session{})
+	data, err := host.ReadFile(ctx, path, 5)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	_, err = host.ReadFile(ctx, path, 4)
+	assert.ErrorIs(t, err, ErrTooLarge)
+	_, err = host.ReadFile(ctx, filepath.Join(dir, "missing"), 4)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+
+	isDir, err := host.IsDir(ctx, dir)
+	require.NoError(t, err)
+	assert.True(t, isDir)
+	isDir, err = host.IsDir(ctx, filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.False(t, isDir)
+
+	out, err := host.Command(ctx, dir, "ls").Output()
+	require.NoError(t, err)
+	assert.Equal(t, "file.txt\n", string(out))
+}
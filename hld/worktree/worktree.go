@@ -0,0 +1,298 @@
+// Package worktree gives sessions launched with use_worktree a git worktree
+// of their own, on a new branch, so sessions in the same repository don't
+// edit one checkout. A session's worktree is removed once it's archived;
+// its branch, and so its commits, are kept.
+package worktree
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/humanlayer/humanlayer/hld/gitcmd"
+	"github.com/humanlayer/humanlayer/hld/scheduler"
+	"github.com/humanlayer/humanlayer/hld/store"
+)
+
+// sweepInterval is how often worktrees of sessions archived outside the
+// REST API are looked for
+const sweepInterval = 10 * time.Minute
+
+var (
+	// ErrNotRepository is returned for creating a worktree of a directory
+	// that isn't in a git repository with at least one commit
+	ErrNotRepository = errors.New("working directory is not in a git repository with commits")
+	// ErrUncommittedChanges is returned for removing a worktree with
+	// changes that would be lost; it is kept until they're committed or
+	// discarded
+	ErrUncommittedChanges = errors.New("worktree has uncommitted changes")
+	// ErrInUse is returned for releasing a worktree that a session is still
+	// running in, or that a session not yet archived continues from
+	ErrInUse = errors.New("worktree is still in use")
+)
+
+// terminalStatuses are the statuses in which no agent runs in a session's
+// working directory. Drafts haven't launched one yet.
+var terminalStatuses = map[string]bool{
+	store.SessionStatusCompleted:   true,
+	store.SessionStatusFailed:      true,
+	store.SessionStatusInterrupted: true,
+	store.SessionStatusDiscarded:   true,
+	store.SessionStatusDraft:       true,
+}
+
+// Dir is where session worktrees are kept, next to the database
+func Dir(databasePath string) string {
+	return filepath.Join(filepath.Dir(databasePath), "worktrees")
+}
+
+// Checkout is a worktree created for a session about to launch
+type Checkout struct {
+	Repository string
+	Path       string
+	Branch     string
+	BaseCommit string
+	// WorkingDir is the launch directory's counterpart in the worktree
+	WorkingDir string
+}
+
+// Manager creates session worktrees and removes them once their sessions
+// are archived
+type Manager struct {
+	worktrees store.WorktreeStore
+	sessions  store.ConversationStore
+	dir       string // worktrees are created under dir/<repository>-<key>
+
+	mu sync.Mutex // one removal at a time
+}
+
+// NewManager creates a worktree manager that keeps worktrees under dir
+func NewManager(worktrees store.WorktreeStore, sessions store.ConversationStore, dir string) *Manager {
+	return &Manager{worktrees: worktrees, sessions: sessions, dir: dir}
+}
+
+// Create adds a worktree of the repository workingDir is in, on a new
+// branch from its HEAD. Uncommitted changes in workingDir are not included.
+func (m *Manager) Create(ctx context.Context, workingDir string) (*Checkout, error) {
+	root, err := git(ctx, workingDir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotRepository, workingDir)
+	}
+	prefix, err := git(ctx, workingDir, "rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotRepository, workingDir)
+	}
+	base, err := git(ctx, root, "rev-parse", "--verify", "HEAD^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotRepository, workingDir)
+	}
+
+	key := uuid.NewString()[:8]
+	checkout := &Checkout{
+		Repository: root,
+		Path:       filepath.Join(m.dir, filepath.Base(root)+"-"+key),
+		Branch:     "humanlayer/worktree-" + key,
+		BaseCommit: base,
+	}
+	checkout.WorkingDir = filepath.Join(checkout.Path, prefix)
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create worktree directory: %w", err)
+	}
+	if _, err := git(ctx, root, "worktree", "add", "-b", checkout.Branch, checkout.Path, base); err != nil {
+		return nil, fmt.Errorf("failed to create worktree: %w", err)
+	}
+	// The launch directory may hold nothing git tracks
+	if err := os.MkdirAll(checkout.WorkingDir, 0755); err != nil {
+		m.Discard(ctx, checkout)
+		return nil, fmt.Errorf("failed to create working directory in worktree: %w", err)
+	}
+	return checkout, nil
+}
+
+// Attach records the session a checkout was created for
+func (m *Manager) Attach(ctx context.Context, sessionID string, checkout *Checkout) error {
+	return m.worktrees.CreateSessionWorktree(ctx, &store.SessionWorktree{
+		SessionID:  sessionID,
+		Repository: checkout.Repository,
+		Path:       checkout.Path,
+		Branch:     checkout.Branch,
+		BaseCommit: checkout.BaseCommit,
+	})
+}
+
+// Discard removes a checkout whose session didn't launch, with its branch
+func (m *Manager) Discard(ctx context.Context, checkout *Checkout) {
+	if _, err := git(ctx, checkout.Repository, "worktree", "remove", "--force", checkout.Path); err != nil {
+		slog.Warn("failed to remove unused worktree", "path", checkout.Path, "error", err)
+		return
+	}
+	if _, err := git(ctx, checkout.Repository, "branch", "-D", checkout.Branch); err != nil {
+		slog.Warn("failed to delete unused worktree branch", "branch", checkout.Branch, "error", err)
+	}
+}
+
+// Release removes the worktree a session works in, if it has one still
+// checked out. Sessions continued from one launched with use_worktree work
+// in their ancestor's worktree. It returns ErrInUse while any session in the
+// worktree is running or the latest of them isn't archived.
+func (m *Manager) Release(ctx context.Context, sessionID string) error {
+	worktree, err := m.find(ctx, sessionID)
+	if err != nil || worktree == nil || worktree.RemovedAt != nil {
+		return err
+	}
+	inUse, err := m.inUse(ctx, worktree)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return ErrInUse
+	}
+	return m.remove(ctx, worktree)
+}
+
+// find returns the worktree of a session or of the nearest of its
+// ancestors, or nil if none has one
+func (m *Manager) find(ctx context.Context, sessionID string) (*store.SessionWorktree, error) {
+	seen := make(map[string]bool)
+	for sessionID != "" && !seen[sessionID] {
+		seen[sessionID] = true
+		worktree, err := m.worktrees.GetSessionWorktree(ctx, sessionID)
+		if err == nil {
+			return worktree, nil
+		}
+		if !errors.Is(err, store.ErrNotFound) {
+			return nil, err
+		}
+		session, err := m.sessions.GetSession(ctx, sessionID)
+		if err != nil {
+			return nil, nil
+		}
+		sessionID = session.ParentSessionID
+	}
+	return nil, nil
+}
+
+// inUse reports whether any session working in the worktree is running, or
+// is a latest continuation that isn't archived. Worktrees no session works
+// in, such as those of deleted sessions, count as in use, and are left for
+// whoever deleted the sessions to clean up.
+func (m *Manager) inUse(ctx context.Context, worktree *store.SessionWorktree) (bool, error) {
+	sessions, err := m.sessions.ListSessions(ctx)
+	if err != nil {
+		return false, err
+	}
+	var users []*store.Session
+	hasChildren := make(map[string]bool)
+	for _, session := range sessions {
+		if session.WorkingDir == worktree.Path || strings.HasPrefix(session.WorkingDir, worktree.Path+string(filepath.Separator)) {
+			users = append(users, session)
+		}
+		if session.ParentSessionID != "" {
+			hasChildren[session.ParentSessionID] = true
+		}
+	}
+	if len(users) == 0 {
+		return true, nil
+	}
+	for _, session := range users {
+		if !terminalStatuses[session.Status] || (!session.Archived && !hasChildren[session.ID]) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// remove deletes a clean worktree. Its branch is deleted too if nothing was
+// committed on it.
+func (m *Manager) remove(ctx context.Context, worktree *store.SessionWorktree) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, err := os.Stat(worktree.Path); errors.Is(err, os.ErrNotExist) {
+		// Deleted by hand; let git forget it too
+		if _, err := git(ctx, worktree.Repository, "worktree", "prune"); err != nil {
+			slog.Warn("failed to prune worktrees", "repository", worktree.Repository, "error", err)
+		}
+	} else {
+		status, err := git(ctx, worktree.Path, "status", "--porcelain")
+		if err != nil {
+			return fmt.Errorf("failed to check worktree: %w", err)
+		}
+		if status != "" {
+			return ErrUncommittedChanges
+		}
+		if _, err := git(ctx, worktree.Repository, "worktree", "remove", worktree.Path); err != nil {
+			return fmt.Errorf("failed to remove worktree: %w", err)
+		}
+	}
+	if head, err := git(ctx, worktree.Repository, "rev-parse", "--verify", "refs/heads/"+worktree.Branch); err == nil && head == worktree.BaseCommit {
+		if _, err := git(ctx, worktree.Repository, "branch", "-D", worktree.Branch); err != nil {
+			slog.Warn("failed to delete worktree branch", "branch", worktree.Branch, "error", err)
+		}
+	}
+	if _, err := m.worktrees.MarkSessionWorktreeRemoved(ctx, worktree.SessionID); err != nil {
+		return err
+	}
+	slog.Info("removed session worktree", "session_id", worktree.SessionID, "path", worktree.Path, "branch", worktree.Branch)
+	return nil
+}
+
+// Task removes the worktrees of archived sessions, for sessions archived
+// while running or without going through the REST API, and worktrees kept
+// back for uncommitted changes
+func (m *Manager) Task() scheduler.Task {
+	return scheduler.Task{
+		Name:        "worktree-cleanup",
+		Description: "Remove the worktrees of archived sessions",
+		Interval:    sweepInterval,
+		RunAtStart:  true,
+		Run: func(ctx context.Context) error {
+			n, err := m.Sweep(ctx)
+			if n > 0 {
+				slog.Info("removed worktrees of archived sessions", "count", n)
+			}
+			return err
+		},
+	}
+}
+
+// Sweep removes the worktrees no longer in use, returning how many.
+// Worktrees with uncommitted changes are left for a later sweep.
+func (m *Manager) Sweep(ctx context.Context) (int, error) {
+	worktrees, err := m.worktrees.ListActiveSessionWorktrees(ctx)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	var errs []error
+	for _, worktree := range worktrees {
+		inUse, err := m.inUse(ctx, worktree)
+		if err != nil {
+			return removed, err
+		}
+		if inUse {
+			continue
+		}
+		switch err := m.remove(ctx, worktree); {
+		case err == nil:
+			removed++
+		case errors.Is(err, ErrUncommittedChanges):
+			slog.Debug("keeping worktree with uncommitted changes", "session_id", worktree.SessionID, "path", worktree.Path)
+		default:
+			errs = append(errs, fmt.Errorf("session %s: %w", worktree.SessionID, err))
+		}
+	}
+	return removed, errors.Join(errs...)
+}
+
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	out, _, err := gitcmd.Run(ctx, gitcmd.Cmd{Dir: dir, Args: args})
+	return strings.TrimSpace(out), err
+}
@@ -0,0 +1,159 @@
+package worktree
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/humanlayer/humanlayer/hld/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func initRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "web"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "web", "app.js"), []byte("run()\n"), 0644))
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test"},
+		{"add", "."},
+		{"commit", "-q", "-m", "initial"},
+	} {
+		runGit(t, dir, args...)
+	}
+	return dir
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	require.NoError(t, err, string(out))
+	return strings.TrimSpace(string(out))
+}
+
+func hasBranch(dir, branch string) bool {
+	return exec.Command("git", "-C", dir, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch).Run() == nil
+}
+
+func TestWorktreeLifecycle(t *testing.T) {
+	ctx := context.Background()
+	repo := initRepo(t)
+	s := store.NewInMemoryStore()
+	m := NewManager(s, s, t.TempDir())
+
+	_, err := m.Create(ctx, t.TempDir())
+	assert.ErrorIs(t, err, ErrNotRepository)
+
+	// Uncommitted changes in the launch directory stay out of the worktree
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "web", "app.js"), []byte("edited\n"), 0644))
+	checkout, err := m.Create(ctx, filepath.Join(repo, "web"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(checkout.Path, "web"), checkout.WorkingDir, "keeps the subdirectory")
+	assert.Equal(t, runGit(t, repo, "rev-parse", "HEAD"), checkout.BaseCommit)
+	content, err := os.ReadFile(filepath.Join(checkout.WorkingDir, "app.js"))
+	require.NoError(t, err)
+	assert.Equal(t, "run()\n", string(content))
+	assert.Equal(t, checkout.Branch, runGit(t, checkout.Path, "rev-parse", "--abbrev-ref", "HEAD"))
+
+	require.NoError(t, m.Attach(ctx, "sess-1", checkout))
+	require.NoError(t, s.CreateSession(ctx, &store.Session{
+		ID: "sess-1", RunID: "run-1", WorkingDir: checkout.WorkingDir, Status: store.SessionStatusRunning, CreatedAt: time.Now(),
+	}))
+	archive := true
+	require.NoError(t, s.UpdateSession(ctx, "sess-1", store.SessionUpdate{Archived: &archive}))
+	assert.ErrorIs(t, m.Release(ctx, "sess-1"), ErrInUse, "the agent is still running")
+	assert.DirExists(t, checkout.Path)
+	completed := store.SessionStatusCompleted
+	require.NoError(t, s.UpdateSession(ctx, "sess-1", store.SessionUpdate{Status: &completed}))
+
+	// A continuation works in the same worktree, so it's kept until the
+	// continuation is archived too
+	require.NoError(t, s.CreateSession(ctx, &store.Session{
+		ID: "sess-1b", RunID: "run-1b", ParentSessionID: "sess-1", WorkingDir: checkout.WorkingDir, Status: store.SessionStatusCompleted, CreatedAt: time.Now(),
+	}))
+	assert.ErrorIs(t, m.Release(ctx, "sess-1"), ErrInUse)
+	require.NoError(t, s.UpdateSession(ctx, "sess-1b", store.SessionUpdate{Archived: &archive}))
+
+	// Archiving keeps a worktree with changes that would be lost
+	require.NoError(t, os.WriteFile(filepath.Join(checkout.WorkingDir, "new.js"), []byte("wip\n"), 0644))
+	assert.ErrorIs(t, m.Release(ctx, "sess-1b"), ErrUncommittedChanges, "found through the parent")
+	assert.DirExists(t, checkout.Path)
+	active, err := s.ListActiveSessionWorktrees(ctx)
+	require.NoError(t, err)
+	assert.Len(t, active, 1)
+
+	// Nothing was committed, so the branch goes with the worktree
+	require.NoError(t, os.Remove(filepath.Join(checkout.WorkingDir, "new.js")))
+	require.NoError(t, m.Release(ctx, "sess-1"))
+	assert.NoDirExists(t, checkout.Path)
+	assert.False(t, hasBranch(repo, checkout.Branch))
+	assert.NotContains(t, runGit(t, repo, "worktree", "list"), checkout.Path)
+	worktree, err := s.GetSessionWorktree(ctx, "sess-1")
+	require.NoError(t, err)
+	assert.NotNil(t, worktree.RemovedAt)
+
+	assert.NoError(t, m.Release(ctx, "sess-1"), "already removed")
+	assert.NoError(t, m.Release(ctx, "sess-without-worktree"))
+}
+
+func TestSweepKeepsCommittedWork(t *testing.T) {
+	ctx := context.Background()
+	repo := initRepo(t)
+	s := store.NewInMemoryStore()
+	m := NewManager(s, s, t.TempDir())
+
+	launch := func(sessionID string) *Checkout {
+		t.Helper()
+		checkout, err := m.Create(ctx, repo)
+		require.NoError(t, err)
+		require.NoError(t, m.Attach(ctx, sessionID, checkout))
+		require.NoError(t, s.CreateSession(ctx, &store.Session{
+			ID: sessionID, RunID: sessionID, WorkingDir: checkout.WorkingDir, Status: store.SessionStatusCompleted, CreatedAt: time.Now(),
+		}))
+		return checkout
+	}
+	archived := launch("sess-archived")
+	running := launch("sess-running")
+	assert.NotEqual(t, archived.Path, running.Path)
+	assert.NotEqual(t, archived.Branch, running.Branch)
+
+	require.NoError(t, os.WriteFile(filepath.Join(archived.Path, "feature.go"), []byte("package main\n"), 0644))
+	runGit(t, archived.Path, "add", ".")
+	runGit(t, archived.Path, "commit", "-q", "-m", "feature")
+	archive := true
+	require.NoError(t, s.UpdateSession(ctx, "sess-archived", store.SessionUpdate{Archived: &archive}))
+
+	n, err := m.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.NoDirExists(t, archived.Path)
+	assert.True(t, hasBranch(repo, archived.Branch), "the branch keeps the session's commits")
+	assert.DirExists(t, running.Path)
+
+	// A worktree deleted by hand is forgotten
+	require.NoError(t, os.RemoveAll(running.Path))
+	require.NoError(t, s.UpdateSession(ctx, "sess-running", store.SessionUpdate{Archived: &archive}))
+	n, err = m.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.NotContains(t, runGit(t, repo, "worktree", "list"), running.Path)
+}
+
+func TestDiscard(t *testing.T) {
+	ctx := context.Background()
+	repo := initRepo(t)
+	m := NewManager(store.NewInMemoryStore(), store.NewInMemoryStore(), t.TempDir())
+
+	checkout, err := m.Create(ctx, repo)
+	require.NoError(t, err)
+	m.Discard(ctx, checkout)
+	assert.NoDirExists(t, checkout.Path)
+	assert.False(t, hasBranch(repo, checkout.Branch))
+}
@@ -0,0 +1,36 @@
+// Service worker for approval notifications pushed by the daemon (Web Push).
+// Payloads are JSON built by hld/webpush: title, body, tag, url and actions.
+
+self.addEventListener('push', event => {
+  if (!event.data) return
+  const data = event.data.json()
+  event.waitUntil(
+    self.registration.showNotification(data.title, {
+      body: data.body,
+      tag: data.tag,
+      renotify: true,
+      requireInteraction: Boolean(data.approval_id),
+      data,
+      actions: (data.actions || []).map(({ action, title }) => ({ action, title })),
+    }),
+  )
+})
+
+self.addEventListener('notificationclick', event => {
+  event.notification.close()
+  const data = event.notification.data || {}
+  const action = (data.actions || []).find(a => a.action === event.action)
+  const target = new URL((action && action.url) || data.url || '/', self.location.origin).href
+
+  event.waitUntil(
+    self.clients.matchAll({ type: 'window', includeUncontrolled: true }).then(windows => {
+      // Reuse an open UI tab rather than opening another
+      for (const client of windows) {
+        if (new URL(client.url).origin === self.location.origin && 'focus' in client) {
+          return client.navigate(target).then(c => (c || client).focus())
+        }
+      }
+      return self.clients.openWindow(target)
+    }),
+  )
+})
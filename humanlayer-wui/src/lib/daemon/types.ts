@@ -582,6 +582,33 @@ export interface ConversationContext {
 export interface GenerateCommitMessageRequest {
   conversationContext?: ConversationContext
   includeUntracked?: boolean
+  locale?: string
+  stream?: boolean // Respond immediately with a requestId and report progress as events
+}
+
+export interface GenerateCommitMessageAccepted {
+  requestId: string
+}
+
+export type CommitMessageProgressStage =
+  | 'context_gathered'
+  | 'diff_summarized'
+  | 'files_processed'
+  | 'drafting'
+  | 'completed'
+  | 'failed'
+
+// Data of a commit_message_progress event
+export interface CommitMessageProgress {
+  request_id: string
+  session_id: string
+  stage: CommitMessageProgressStage
+  changed_files?: number
+  files_processed?: number
+  files_total?: number
+  result?: GenerateCommitMessageResponse
+  status?: number
+  error?: string
 }
 
 export interface GenerateCommitMessageResponse {
@@ -594,11 +621,33 @@ export interface GenerateCommitMessageResponse {
   }
 }
 
+// Returned with 202 by endpoints called with ?async=true
+export interface JobAccepted {
+  job_id: string
+  status_url: string
+}
+
+export type JobStatus = 'pending' | 'running' | 'completed' | 'failed' | 'interrupted'
+
+export interface Job<T = unknown> {
+  id: string
+  kind: string
+  session_id?: string
+  status: JobStatus
+  result?: T
+  error?: string
+  created_at: string
+  started_at?: string
+  completed_at?: string
+}
+
 export interface CommitRequest {
   commits: CommitMessage[]
   createBranch?: string
   stageUntracked?: boolean
   stageFiles?: string[] // Specific files to stage (if not all)
+  force?: boolean // Commit even while the session is running (recorded in the conversation)
+  operationId?: string // Tags the command_output events of hooks and analyzers; generated if unset
 }
 
 export interface CommitResponse {
@@ -606,4 +655,5 @@ export interface CommitResponse {
   commitHashes: string[]
   branchCreated?: string
   error?: string
+  operationId: string
 }
@@ -0,0 +1,77 @@
+import { getDaemonUrl } from '@/lib/daemon/http-config'
+import { logger } from '@/lib/logging'
+
+const SERVICE_WORKER_URL = '/push-sw.js'
+
+// Web Push needs a service worker and the Push API, which the desktop app's
+// webview lacks; it works when the UI is opened in a browser
+export function isPushSupported(): boolean {
+  return (
+    typeof window !== 'undefined' &&
+    'serviceWorker' in navigator &&
+    'PushManager' in window &&
+    'Notification' in window
+  )
+}
+
+function base64UrlToBytes(value: string): Uint8Array {
+  const base64 = value.replace(/-/g, '+').replace(/_/g, '/')
+  const padded = base64 + '='.repeat((4 - (base64.length % 4)) % 4)
+  return Uint8Array.from(atob(padded), c => c.charCodeAt(0))
+}
+
+async function daemonFetch(path: string, init?: RequestInit): Promise<Response> {
+  const response = await fetch(`${await getDaemonUrl()}/api/v1${path}`, init)
+  if (!response.ok) {
+    const body = await response.json().catch(() => ({}))
+    throw new Error(body.error || `Request failed with ${response.status}`)
+  }
+  return response
+}
+
+// Ask for permission and subscribe this browser to approval notifications.
+// Returns false if the user refuses permission.
+export async function subscribeToPush(): Promise<boolean> {
+  if (!isPushSupported()) {
+    throw new Error('Push notifications are not supported here')
+  }
+  if ((await Notification.requestPermission()) !== 'granted') {
+    return false
+  }
+
+  const registration = await navigator.serviceWorker.register(SERVICE_WORKER_URL)
+  await navigator.serviceWorker.ready
+  const { public_key } = await (await daemonFetch('/push/public-key')).json()
+
+  let subscription = await registration.pushManager.getSubscription()
+  if (!subscription) {
+    subscription = await registration.pushManager.subscribe({
+      userVisibleOnly: true,
+      applicationServerKey: base64UrlToBytes(public_key),
+    })
+  }
+  await daemonFetch('/push/subscriptions', {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify(subscription.toJSON()),
+  })
+  logger.log('Subscribed to push notifications')
+  return true
+}
+
+// Stop push notifications for this browser
+export async function unsubscribeFromPush(): Promise<void> {
+  if (!isPushSupported()) return
+  const registration = await navigator.serviceWorker.getRegistration(SERVICE_WORKER_URL)
+  const subscription = await registration?.pushManager.getSubscription()
+  if (!subscription) return
+
+  const { data } = await (await daemonFetch('/push/subscriptions')).json()
+  const stored = (data as { id: string; endpoint: string }[]).find(
+    s => s.endpoint === subscription.endpoint,
+  )
+  if (stored) {
+    await daemonFetch(`/push/subscriptions/${stored.id}`, { method: 'DELETE' })
+  }
+  await subscription.unsubscribe()
+}